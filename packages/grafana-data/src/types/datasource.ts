@@ -570,6 +570,11 @@ export interface DataSourceInstanceSettings<T extends DataSourceJsonData = DataS
   isDefault?: boolean;
   access: 'direct' | 'proxy'; // Currently we support 2 options - direct (browser) and proxy (server)
 
+  /** The group the data source is listed in by the data source pickers */
+  group?: string;
+  /** The position of the data source in the data source pickers, when the organization orders them */
+  sortIndex?: number;
+
   /**
    * This is the full Authorization header if basic auth is enabled.
    * Only available here when access is Browser (direct), when access is Server (proxy)
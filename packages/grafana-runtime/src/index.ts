@@ -11,6 +11,8 @@ export { reportMetaAnalytics, reportInteraction, reportPageview, reportExperimen
 export { featureEnabled } from './utils/licensing';
 export { logInfo, logDebug, logWarning, logError } from './utils/logging';
 export {
+  DataSourceMetadata,
+  DataSourceMetadataItem,
   DataSourceWithBackend,
   HealthCheckResult,
   HealthCheckResultDetails,
@@ -92,6 +92,27 @@ export interface HealthCheckResult {
   details: HealthCheckResultDetails;
 }
 
+/**
+ * Describes an item of the metadata of a data source, such as a table or a label key.
+ *
+ * @public
+ */
+export interface DataSourceMetadataItem {
+  value: string;
+  text?: string;
+  description?: string;
+}
+
+/**
+ * Describes the payload returned when getting the metadata of a data source.
+ *
+ * @public
+ */
+export interface DataSourceMetadata {
+  items: DataSourceMetadataItem[];
+  fetchedAt: string;
+}
+
 /**
  * Extend this class to implement a data source plugin that is depending on the Grafana
  * backend API.
@@ -168,12 +189,22 @@ class DataSourceWithBackend<
       });
     }
 
+    // Identify the dashboard and panel of the queries, for the query policy of the organization
+    const headers: Record<string, string> = {};
+    if (request.dashboardId) {
+      headers['X-Dashboard-Id'] = String(request.dashboardId);
+    }
+    if (request.panelId) {
+      headers['X-Panel-Id'] = String(request.panelId);
+    }
+
     return getBackendSrv()
       .fetch<BackendDataSourceResponse>({
         url: '/api/ds/query',
         method: 'POST',
         data: body,
         requestId,
+        ...(Object.keys(headers).length ? { headers } : {}),
       })
       .pipe(
         switchMap((raw) => {
@@ -222,6 +253,14 @@ class DataSourceWithBackend<
     return getBackendSrv().get(`/api/datasources/${this.id}/resources/${path}`, params);
   }
 
+  /**
+   * Get metadata of the datasource, e.g. its tables, served by the plugin on the metadata/{kind}
+   * resource path. The metadata is cached by Grafana, and refreshed in the background.
+   */
+  async getMetadata(kind: string, params?: Record<string, string>): Promise<DataSourceMetadata> {
+    return getBackendSrv().get(`/api/datasources/uid/${this.uid}/metadata/${kind}`, params);
+  }
+
   /**
    * Send a POST request to the datasource resource path
    */
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetAccessGrants returns the time-limited access grants that haven't expired.
+// GET /api/access-control/grants
+func (hs *HTTPServer) GetAccessGrants(c *models.ReqContext) response.Response {
+	grants, err := hs.accessGrants.GetActive(c.Req.Context(), &accessgrants.GetActiveGrantsQuery{
+		OrgID:  c.QueryInt64("orgId"),
+		UserID: c.QueryInt64("userId"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get access grants", err)
+	}
+	return response.JSON(http.StatusOK, grants)
+}
+
+// CreateAccessGrant grants a user a basic role or a permission for a limited time.
+// POST /api/access-control/grants
+func (hs *HTTPServer) CreateAccessGrant(c *models.ReqContext) response.Response {
+	cmd := accessgrants.CreateGrantCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.OrgID == 0 {
+		cmd.OrgID = c.OrgId
+	}
+	cmd.GrantedBy = c.UserId
+
+	grant, err := hs.accessGrants.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, accessgrants.ErrInvalidGrant),
+			errors.Is(err, accessgrants.ErrInvalidRole),
+			errors.Is(err, accessgrants.ErrInvalidDuration),
+			errors.Is(err, accessgrants.ErrReasonRequired),
+			errors.Is(err, accessgrants.ErrUserNotInOrg):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to create access grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// RevokeAccessGrant removes an access grant before it expires.
+// DELETE /api/access-control/grants/:id
+func (hs *HTTPServer) RevokeAccessGrant(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.accessGrants.Revoke(c.Req.Context(), &accessgrants.RevokeGrantCommand{ID: id, RevokedBy: c.UserId}); err != nil {
+		if errors.Is(err, accessgrants.ErrGrantNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to revoke access grant", err)
+	}
+	return response.Success("Access grant revoked")
+}
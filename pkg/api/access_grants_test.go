@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/services/accessgrants/accessgrantstest"
+)
+
+func TestAPIEndpoint_AccessGrants(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+	grants := accessgrantstest.NewAccessGrantsServiceFake()
+	sc.hs.accessGrants = grants
+
+	t.Run("Org admins can't grant access", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPost, "/api/access-control/grants", strings.NewReader(`{"userId":2,"role":"Admin","duration":"1h","reason":"incident"}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	sc.initCtx.SignedInUser.IsGrafanaAdmin = true
+
+	t.Run("Returns the created grant", func(t *testing.T) {
+		grants.ExpectedGrant = &accessgrants.Grant{ID: 1, OrgID: 1, UserID: 2, Role: models.ROLE_ADMIN, Reason: "incident"}
+		grants.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/access-control/grants", strings.NewReader(`{"userId":2,"role":"Admin","duration":"1h","reason":"incident"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var grant accessgrants.Grant
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &grant))
+		assert.Equal(t, int64(1), grant.ID)
+		assert.Equal(t, models.ROLE_ADMIN, grant.Role)
+	})
+
+	t.Run("Returns 400 for invalid grants", func(t *testing.T) {
+		grants.ExpectedError = accessgrants.ErrInvalidDuration
+		response := callAPI(sc.server, http.MethodPost, "/api/access-control/grants", strings.NewReader(`{"userId":2,"role":"Admin","duration":"1y","reason":"incident"}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Returns the active grants", func(t *testing.T) {
+		grants.ExpectedGrants = []*accessgrants.Grant{{ID: 1}, {ID: 2}}
+		grants.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/access-control/grants?userId=2", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []accessgrants.Grant
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("Returns 404 when revoking an unknown grant", func(t *testing.T) {
+		grants.ExpectedError = accessgrants.ErrGrantNotFound
+		response := callAPI(sc.server, http.MethodDelete, "/api/access-control/grants/3", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Revokes the grant", func(t *testing.T) {
+		grants.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodDelete, "/api/access-control/grants/1", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/setting"
@@ -408,6 +409,35 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	dashboardTemplatesReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Version:     1,
+			Name:        "fixed:dashboards.templates:reader",
+			DisplayName: "Dashboard template reader",
+			Description: "Read all dashboard templates.",
+			Group:       "Dashboards",
+			Permissions: []ac.Permission{
+				{Action: dashboardtemplates.ActionTemplatesRead, Scope: dashboardtemplates.ScopeTemplatesAll},
+			},
+		},
+		Grants: []string{"Editor"},
+	}
+
+	dashboardTemplatesWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Version:     1,
+			Name:        "fixed:dashboards.templates:writer",
+			DisplayName: "Dashboard template writer",
+			Description: "Create, read, write or delete all dashboard templates.",
+			Group:       "Dashboards",
+			Permissions: ac.ConcatPermissions(dashboardTemplatesReaderRole.Role.Permissions, []ac.Permission{
+				{Action: dashboardtemplates.ActionTemplatesWrite, Scope: dashboardtemplates.ScopeTemplatesAll},
+				{Action: dashboardtemplates.ActionTemplatesDelete, Scope: dashboardtemplates.ScopeTemplatesAll},
+			}),
+		},
+		Grants: []string{"Admin"},
+	}
+
 	return hs.AccessControl.DeclareFixedRoles(
 		provisioningWriterRole, datasourcesReaderRole, datasourcesWriterRole,
 		datasourcesIdReaderRole, orgReaderRole, orgWriterRole,
@@ -415,6 +445,7 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		annotationsReaderRole, dashboardAnnotationsWriterRole, annotationsWriterRole,
 		dashboardsCreatorRole, dashboardsReaderRole, dashboardsWriterRole,
 		foldersCreatorRole, foldersReaderRole, foldersWriterRole, apikeyReaderRole, apikeyWriterRole,
+		dashboardTemplatesReaderRole, dashboardTemplatesWriterRole,
 	)
 }
 
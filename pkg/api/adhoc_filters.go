@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adhocfilters"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetAdHocFilterKeys returns the keys suggested for ad hoc filters on a data source.
+// GET /api/datasources/uid/:uid/adhoc-filters/keys
+func (hs *HTTPServer) GetAdHocFilterKeys(c *models.ReqContext) response.Response {
+	ds, rsp := hs.getDataSourceByUIDParam(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	keys, err := hs.adHocFilters.GetKeys(c.Req.Context(), c.SignedInUser, ds, adhocfilters.KeysQuery{
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		SkipCache: c.SkipCache,
+	})
+	if err != nil {
+		return adHocFiltersErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, keys)
+}
+
+// GetAdHocFilterValues returns the values suggested for an ad hoc filter key on a data source.
+// GET /api/datasources/uid/:uid/adhoc-filters/values?key=
+func (hs *HTTPServer) GetAdHocFilterValues(c *models.ReqContext) response.Response {
+	ds, rsp := hs.getDataSourceByUIDParam(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	values, err := hs.adHocFilters.GetValues(c.Req.Context(), c.SignedInUser, ds, adhocfilters.ValuesQuery{
+		Key:       c.Query("key"),
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		SkipCache: c.SkipCache,
+	})
+	if err != nil {
+		return adHocFiltersErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, values)
+}
+
+// getDataSourceByUIDParam returns the data source of the :uid route parameter, or the error response.
+func (hs *HTTPServer) getDataSourceByUIDParam(c *models.ReqContext) (*models.DataSource, response.Response) {
+	dsUID := web.Params(c.Req)[":uid"]
+	if !util.IsValidShortUID(dsUID) {
+		return nil, response.Error(http.StatusBadRequest, "UID is invalid", nil)
+	}
+
+	ds, err := hs.DataSourceCache.GetDatasourceByUID(c.Req.Context(), dsUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return nil, response.Error(http.StatusForbidden, "Access denied to datasource", err)
+		}
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Unable to load datasource metadata", err)
+	}
+	return ds, nil
+}
+
+func adHocFiltersErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, adhocfilters.ErrUnsupportedDataSource):
+		return response.Error(http.StatusNotImplemented, err.Error(), err)
+	case errors.Is(err, adhocfilters.ErrKeyRequired):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, adhocfilters.ErrKeyNotAllowed), errors.Is(err, querypolicy.ErrDataSourceCallNotAllowed):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to get ad hoc filter suggestions", err)
+}
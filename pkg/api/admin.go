@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
@@ -28,6 +29,24 @@ func (hs *HTTPServer) AdminGetStats(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, statsQuery.Result)
 }
 
+// AdminGetStatsHistory returns the daily snapshots of the stats, optionally between
+// the from and to days formatted as YYYY-MM-DD.
+// GET /api/admin/stats/history
+func (hs *HTTPServer) AdminGetStatsHistory(c *models.ReqContext) response.Response {
+	query := models.GetStatsHistoryQuery{From: c.Query("from"), To: c.Query("to")}
+	for _, day := range []string{query.From, query.To} {
+		if _, err := time.Parse("2006-01-02", day); day != "" && err != nil {
+			return response.Error(http.StatusBadRequest, "from and to must be days formatted as YYYY-MM-DD", err)
+		}
+	}
+
+	if err := hs.SQLStore.GetStatsHistory(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get stats history from database", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
 func (hs *HTTPServer) getAuthorizedSettings(ctx context.Context, user *models.SignedInUser, bag setting.SettingsBag) (setting.SettingsBag, error) {
 	if hs.AccessControl.IsDisabled() {
 		return bag, nil
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
@@ -14,3 +15,34 @@ func (hs *HTTPServer) AdminRotateDataEncryptionKeys(c *models.ReqContext) respon
 
 	return response.Respond(http.StatusNoContent, "")
 }
+
+type encryptionProviderStatus struct {
+	ID      string `json:"id"`
+	Current bool   `json:"current"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GET /api/admin/encryption/providers
+func (hs *HTTPServer) AdminGetEncryptionProviders(c *models.ReqContext) response.Response {
+	current := hs.SecretsService.CurrentProviderID()
+	health := hs.SecretsService.CheckProvidersHealth(c.Req.Context())
+
+	result := make([]encryptionProviderStatus, 0, len(health))
+	for id, err := range health {
+		status := encryptionProviderStatus{
+			ID:      string(id),
+			Current: id == current,
+			Healthy: err == nil,
+		}
+		if err != nil {
+			status.Error = err.Error()
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return response.JSON(http.StatusOK, result)
+}
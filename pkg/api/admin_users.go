@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -34,8 +35,11 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext) response.Response {
 		}
 	}
 
-	if len(cmd.Password) < 4 {
-		return response.Error(400, "Password is missing or too short", nil)
+	if len(cmd.Password) == 0 {
+		return response.Error(400, "Password is missing", nil)
+	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), cmd.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
 	}
 
 	user, err := hs.Login.CreateUser(cmd)
@@ -72,8 +76,8 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *models.ReqContext) response.Res
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
-	if len(form.Password) < 4 {
-		return response.Error(400, "New password too short", nil)
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), form.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
 	}
 
 	userQuery := models.GetUserByIdQuery{Id: userID}
@@ -99,6 +103,23 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *models.ReqContext) response.Res
 	return response.Success("User password updated")
 }
 
+// GET /api/admin/users/stale-passwords
+func (hs *HTTPServer) AdminGetStalePasswords(c *models.ReqContext) response.Response {
+	result, err := hs.passwordPolicy.GetStalePasswords(c.Req.Context(), &passwordpolicy.GetStalePasswordsQuery{
+		OlderThanDays: c.QueryInt("olderThanDays"),
+		Page:          c.QueryInt("page"),
+		Limit:         c.QueryInt("perpage"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, passwordpolicy.ErrMaxAgeNotSet), errors.Is(err, passwordpolicy.ErrInvalidOlderThan):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get stale passwords", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 // PUT /api/admin/users/:id/permissions
 func (hs *HTTPServer) AdminUpdateUserPermissions(c *models.ReqContext) response.Response {
 	form := dtos.AdminUpdateUserPermissionsForm{}
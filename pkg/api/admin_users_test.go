@@ -1,8 +1,12 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -12,6 +16,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
 	"github.com/grafana/grafana/pkg/services/login/logintest"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy/passwordpolicytest"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -397,6 +403,54 @@ func adminDeleteUserScenario(t *testing.T, desc string, url string, routePattern
 	})
 }
 
+func TestAdminAPIEndpoint_PasswordPolicy(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInUser(sc.initCtx, models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN, IsGrafanaAdmin: true})
+	passwordPolicy := passwordpolicytest.NewPasswordPolicyServiceFake()
+	sc.hs.passwordPolicy = passwordPolicy
+
+	t.Run("Rejects the passwords that don't satisfy the policy", func(t *testing.T) {
+		passwordPolicy.ExpectedError = fmt.Errorf("%w: it must contain a digit", passwordpolicy.ErrPasswordPolicy)
+		response := callAPI(sc.server, http.MethodPut, "/api/admin/users/2/password", strings.NewReader(`{"password":"no digits"}`), t)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Contains(t, response.Body.String(), "it must contain a digit")
+	})
+
+	t.Run("Returns the stale passwords", func(t *testing.T) {
+		changed := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		passwordPolicy.ExpectedError = nil
+		passwordPolicy.ExpectedStalePasswords = &passwordpolicy.StalePasswordsResult{
+			TotalCount: 1,
+			MaxAgeDays: 90,
+			Users:      []*passwordpolicy.StalePassword{{UserID: 2, Login: "stale", PasswordChanged: changed, AgeDays: 200}},
+			Page:       1,
+			PerPage:    100,
+		}
+		response := callAPI(sc.server, http.MethodGet, "/api/admin/users/stale-passwords", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result passwordpolicy.StalePasswordsResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		require.Len(t, result.Users, 1)
+		assert.Equal(t, "stale", result.Users[0].Login)
+		assert.Equal(t, 200, result.Users[0].AgeDays)
+		assert.True(t, changed.Equal(result.Users[0].PasswordChanged))
+	})
+
+	t.Run("Returns 400 when the maximum age isn't configured", func(t *testing.T) {
+		passwordPolicy.ExpectedError = passwordpolicy.ErrMaxAgeNotSet
+		response := callAPI(sc.server, http.MethodGet, "/api/admin/users/stale-passwords", nil, t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Is only available to server admins", func(t *testing.T) {
+		setInitCtxSignedInOrgAdmin(sc.initCtx)
+		passwordPolicy.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/admin/users/stale-passwords", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
+
 func adminCreateUserScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.AdminCreateUserForm, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		hs := HTTPServer{
@@ -406,6 +460,7 @@ func adminCreateUserScenario(t *testing.T, desc string, url string, routePattern
 				AlreadyExitingLogin: existingTestLogin,
 				GeneratedUserId:     testUserID,
 			},
+			passwordPolicy: passwordpolicytest.NewPasswordPolicyServiceFake(),
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/classicmigration"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// MigrateClassicCondition converts the classic condition of an alert rule into reduce and math expressions.
+// POST /api/alerting/classic-conditions/migrate/:uid
+func (hs *HTTPServer) MigrateClassicCondition(c *models.ReqContext) response.Response {
+	svc, rsp := hs.classicConditions()
+	if rsp != nil {
+		return rsp
+	}
+	cmd := classicmigration.MigrateRuleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.User = c.SignedInUser
+
+	result, err := svc.Migrate(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+			return response.Error(http.StatusNotFound, "Alert rule not found", err)
+		case errors.Is(err, classicmigration.ErrRuleAccessDenied):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		case errors.Is(err, classicmigration.ErrNoClassicCondition),
+			errors.Is(err, classicmigration.ErrUnsupportedCondition),
+			errors.Is(err, classicmigration.ErrRuleProvisioned):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to migrate classic condition", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// MigrateAllClassicConditions converts all the alert rules of the organization using classic conditions that the user can update.
+// POST /api/alerting/classic-conditions/migrate
+func (hs *HTTPServer) MigrateAllClassicConditions(c *models.ReqContext) response.Response {
+	svc, rsp := hs.classicConditions()
+	if rsp != nil {
+		return rsp
+	}
+	cmd := classicmigration.MigrateAllRulesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.User = c.SignedInUser
+
+	result, err := svc.MigrateAll(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to migrate classic conditions", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetClassicConditionReport returns the alert rules of the organization that use a classic condition and that the user can read.
+// GET /api/alerting/classic-conditions/report
+func (hs *HTTPServer) GetClassicConditionReport(c *models.ReqContext) response.Response {
+	svc, rsp := hs.classicConditions()
+	if rsp != nil {
+		return rsp
+	}
+	result, err := svc.Report(c.Req.Context(), &classicmigration.ReportQuery{OrgID: c.OrgId, User: c.SignedInUser})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get classic condition report", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) classicConditions() (*classicmigration.Service, response.Response) {
+	if hs.AlertNG == nil || hs.AlertNG.ClassicConditions == nil {
+		return nil, response.Error(http.StatusNotFound, "Unified alerting is not enabled", nil)
+	}
+	return hs.AlertNG.ClassicConditions, nil
+}
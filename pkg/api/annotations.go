@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/util"
@@ -62,9 +64,71 @@ func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
 		}
 	}
 
+	if sources := c.QueryStrings("source"); len(sources) > 0 {
+		items, err = hs.addSourceAnnotations(c, query, sources, items)
+		if err != nil {
+			if errors.Is(err, annotationsource.ErrSourceNotFound) {
+				return response.Error(http.StatusBadRequest, err.Error(), err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
+		}
+	}
+
 	return response.JSON(http.StatusOK, items)
 }
 
+// addSourceAnnotations queries the annotation sources on demand and merges their annotations
+// with the stored ones. Annotations of sources are organization annotations, they are skipped
+// when the query is limited to dashboards, users or alerts.
+func (hs *HTTPServer) addSourceAnnotations(c *models.ReqContext, query *annotations.ItemQuery, sources []string, items []*annotations.ItemDTO) ([]*annotations.ItemDTO, error) {
+	if query.DashboardId != 0 || query.PanelId != 0 || query.UserId != 0 || query.AlertId != 0 || query.Type == "alert" {
+		return items, nil
+	}
+	if !hs.AccessControl.IsDisabled() {
+		canRead, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(accesscontrol.ActionAnnotationsRead, accesscontrol.ScopeAnnotationsTypeOrganization))
+		if err != nil || !canRead {
+			return items, err
+		}
+	}
+
+	found, err := hs.annotationSources.Query(c.Req.Context(), &annotationsource.Query{
+		OrgID:        c.OrgId,
+		PluginIDs:    sources,
+		From:         query.From,
+		To:           query.To,
+		Tags:         query.Tags,
+		Limit:        query.Limit,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return items, nil
+	}
+
+	items = append(items, found...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Time > items[j].Time })
+	limit := query.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	if int64(len(items)) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// GetAnnotationSources returns the annotation source plugins enabled in the organization.
+// GET /api/annotations/sources
+func (hs *HTTPServer) GetAnnotationSources(c *models.ReqContext) response.Response {
+	sources, err := hs.annotationSources.Sources(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get annotation sources", err)
+	}
+	return response.JSON(http.StatusOK, sources)
+}
+
 type AnnotationError struct {
 	message string
 }
@@ -112,7 +176,7 @@ func (hs *HTTPServer) PostAnnotation(c *models.ReqContext) response.Response {
 	}
 
 	if err := repo.Save(&item); err != nil {
-		if errors.Is(err, annotations.ErrTimerangeMissing) {
+		if errors.Is(err, annotations.ErrTimerangeMissing) || errors.Is(err, annotations.ErrTimeInFuture) {
 			return response.Error(400, "Failed to save annotation", err)
 		}
 		return response.Error(500, "Failed to save annotation", err)
@@ -180,6 +244,9 @@ func (hs *HTTPServer) PostGraphiteAnnotation(c *models.ReqContext) response.Resp
 	}
 
 	if err := repo.Save(&item); err != nil {
+		if errors.Is(err, annotations.ErrTimeInFuture) {
+			return response.Error(400, "Failed to save Graphite annotation", err)
+		}
 		return response.Error(500, "Failed to save Graphite annotation", err)
 	}
 
@@ -222,6 +289,9 @@ func (hs *HTTPServer) UpdateAnnotation(c *models.ReqContext) response.Response {
 	}
 
 	if err := repo.Update(c.Req.Context(), &item); err != nil {
+		if errors.Is(err, annotations.ErrTimeInFuture) {
+			return response.Error(400, "Failed to update annotation", err)
+		}
 		return response.Error(500, "Failed to update annotation", err)
 	}
 
@@ -276,6 +346,9 @@ func (hs *HTTPServer) PatchAnnotation(c *models.ReqContext) response.Response {
 	}
 
 	if err := repo.Update(c.Req.Context(), &existing); err != nil {
+		if errors.Is(err, annotations.ErrTimeInFuture) {
+			return response.Error(400, "Failed to update annotation", err)
+		}
 		return response.Error(500, "Failed to update annotation", err)
 	}
 
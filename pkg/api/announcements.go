@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminListAnnouncements lists all announcements, including the ones that have ended.
+// GET /api/admin/announcements
+func (hs *HTTPServer) AdminListAnnouncements(c *models.ReqContext) response.Response {
+	result, err := hs.announcements.List(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list announcements", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// AdminGetAnnouncement returns an announcement.
+// GET /api/admin/announcements/:uid
+func (hs *HTTPServer) AdminGetAnnouncement(c *models.ReqContext) response.Response {
+	announcement, err := hs.announcements.Get(c.Req.Context(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return announcementsErrorResponse(err, "Failed to get announcement")
+	}
+	return response.JSON(http.StatusOK, announcement)
+}
+
+// AdminCreateAnnouncement creates an announcement.
+// POST /api/admin/announcements
+func (hs *HTTPServer) AdminCreateAnnouncement(c *models.ReqContext) response.Response {
+	cmd := announcements.CreateAnnouncementCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	announcement, err := hs.announcements.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return announcementsErrorResponse(err, "Failed to create announcement")
+	}
+	return response.JSON(http.StatusOK, announcement)
+}
+
+// AdminUpdateAnnouncement replaces the message, time range and targets of an announcement.
+// PUT /api/admin/announcements/:uid
+func (hs *HTTPServer) AdminUpdateAnnouncement(c *models.ReqContext) response.Response {
+	cmd := announcements.UpdateAnnouncementCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.Uid = web.Params(c.Req)[":uid"]
+
+	announcement, err := hs.announcements.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return announcementsErrorResponse(err, "Failed to update announcement")
+	}
+	return response.JSON(http.StatusOK, announcement)
+}
+
+// AdminDeleteAnnouncement deletes an announcement.
+// DELETE /api/admin/announcements/:uid
+func (hs *HTTPServer) AdminDeleteAnnouncement(c *models.ReqContext) response.Response {
+	if err := hs.announcements.Delete(c.Req.Context(), web.Params(c.Req)[":uid"]); err != nil {
+		return announcementsErrorResponse(err, "Failed to delete announcement")
+	}
+	return response.Success("Announcement deleted")
+}
+
+// DismissAnnouncement hides an announcement for the signed in user.
+// POST /api/user/announcements/:uid/dismiss
+func (hs *HTTPServer) DismissAnnouncement(c *models.ReqContext) response.Response {
+	err := hs.announcements.Dismiss(c.Req.Context(), &announcements.DismissAnnouncementCommand{
+		OrgID:  c.OrgId,
+		UserID: c.UserId,
+		Uid:    web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return announcementsErrorResponse(err, "Failed to dismiss announcement")
+	}
+	return response.Success("Announcement dismissed")
+}
+
+func announcementsErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, announcements.ErrAnnouncementNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, announcements.ErrAnnouncementUIDTaken):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, announcements.ErrAnnouncementInvalid), errors.Is(err, announcements.ErrAnnouncementInvalidUID),
+		errors.Is(err, announcements.ErrAnnouncementMessageRequired):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
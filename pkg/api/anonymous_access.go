@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/org/anonymous-access
+func (hs *HTTPServer) GetOrgAnonymousAccess(c *models.ReqContext) response.Response {
+	result, err := hs.anonymousAccess.GetAllowlist(c.Req.Context(), &anonymousaccess.GetAllowlistQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get anonymous access allowlist", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/org/anonymous-access
+func (hs *HTTPServer) UpdateOrgAnonymousAccess(c *models.ReqContext) response.Response {
+	cmd := anonymousaccess.SaveAllowlistCommand{}
+	if err := web.Bind(c.Req, &cmd.Allowlist); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+
+	if err := hs.anonymousAccess.SaveAllowlist(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, anonymousaccess.ErrItemNotFound) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save anonymous access allowlist", err)
+	}
+
+	return response.Success("Anonymous access allowlist updated")
+}
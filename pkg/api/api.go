@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
@@ -51,6 +52,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/profile/password", reqSignedInNoAnonymous, hs.Index)
 	r.Get("/.well-known/change-password", redirectToChangePassword)
 	r.Get("/profile/switch-org/:id", reqSignedInNoAnonymous, hs.ChangeActiveOrgAndRedirectToHome)
+	r.Get("/invite-link/:code", reqSignedInNoAnonymous, hs.Index)
 	r.Get("/org/", authorize(reqOrgAdmin, orgPreferencesAccessEvaluator), hs.Index)
 	r.Get("/org/new", authorizeInOrg(reqGrafanaAdmin, ac.UseGlobalOrg, orgsCreateAccessEvaluator), hs.Index)
 	r.Get("/datasources/", authorize(reqOrgAdmin, datasources.ConfigurationPageAccess), hs.Index)
@@ -91,7 +93,7 @@ func (hs *HTTPServer) registerRoutes() {
 
 	//pubdash
 	if hs.Features.IsEnabled(featuremgmt.FlagPublicDashboards) {
-		r.Get("/public-dashboards/:uid", middleware.SetPublicDashboardFlag(), hs.Index)
+		r.Get("/public-dashboards/:accessToken", middleware.SetPublicDashboardFlag(), hs.Index)
 	}
 
 	r.Get("/d/:uid/:slug", reqSignedIn, redirectFromLegacyPanelEditURL, hs.Index)
@@ -137,6 +139,9 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Post("/api/user/password/send-reset-email", routing.Wrap(hs.SendResetPasswordEmail))
 	r.Post("/api/user/password/reset", routing.Wrap(hs.ResetPassword))
 
+	// bounce notifications of the mail providers, authenticated by the token of the webhook
+	r.Post("/api/email/bounces/:provider", routing.Wrap(hs.PostEmailBounces))
+
 	// dashboard snapshots
 	r.Get("/dashboard/snapshot/*", reqNoAuth, hs.Index)
 	r.Get("/dashboard/snapshots/", reqSignedIn, hs.Index)
@@ -164,6 +169,22 @@ func (hs *HTTPServer) registerRoutes() {
 			userRoute.Get("/stars", routing.Wrap(hs.GetStars))
 			userRoute.Post("/stars/dashboard/:id", routing.Wrap(hs.StarDashboard))
 			userRoute.Delete("/stars/dashboard/:id", routing.Wrap(hs.UnstarDashboard))
+			userRoute.Post("/stars/:kind/uid/:uid", routing.Wrap(hs.StarItem))
+			userRoute.Delete("/stars/:kind/uid/:uid", routing.Wrap(hs.UnstarItem))
+
+			userRoute.Get("/inbox", routing.Wrap(hs.SearchInboxNotifications))
+			userRoute.Get("/inbox/unread-count", routing.Wrap(hs.GetInboxUnreadCount))
+			userRoute.Post("/inbox/read", routing.Wrap(hs.MarkInboxNotificationsRead))
+			userRoute.Delete("/inbox/:id", routing.Wrap(hs.DeleteInboxNotification))
+
+			userRoute.Post("/announcements/:uid/dismiss", routing.Wrap(hs.DismissAnnouncement))
+
+			userRoute.Get("/invite-links/:code", routing.Wrap(hs.GetOrgInviteLinkInfo))
+			userRoute.Post("/invite-links/:code/join", routing.Wrap(hs.JoinOrgWithInviteLink))
+
+			userRoute.Get("/onboarding", routing.Wrap(hs.GetOnboardingState))
+			userRoute.Post("/onboarding", routing.Wrap(hs.UpdateOnboardingState))
+			userRoute.Delete("/onboarding", routing.Wrap(hs.ResetOnboardingState))
 
 			userRoute.Put("/password", routing.Wrap(hs.ChangeUserPassword))
 			userRoute.Get("/quotas", routing.Wrap(hs.GetUserQuotas))
@@ -203,6 +224,7 @@ func (hs *HTTPServer) registerRoutes() {
 			teamsRoute.Delete("/:teamId/members/:userId", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsID)), routing.Wrap(hs.RemoveTeamMember))
 			teamsRoute.Get("/:teamId/preferences", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsRead, ac.ScopeTeamsID)), routing.Wrap(hs.GetTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsWrite, ac.ScopeTeamsID)), routing.Wrap(hs.UpdateTeamPreferences))
+			teamsRoute.Put("/:teamId/preferences/home-dashboard", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsWrite, ac.ScopeTeamsID)), routing.Wrap(hs.SetTeamHomeDashboard))
 		})
 
 		// team without requirement of user to be org admin
@@ -245,11 +267,38 @@ func (hs *HTTPServer) registerRoutes() {
 			orgRoute.Get("/invites", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.GetPendingOrgInvites))
 			orgRoute.Post("/invites", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), quota("user"), routing.Wrap(hs.AddOrgInvite))
 			orgRoute.Patch("/invites/:code/revoke", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.RevokeInvite))
+			orgRoute.Get("/invites/links", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.GetOrgInviteLinks))
+			orgRoute.Post("/invites/links", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.CreateOrgInviteLink))
+			orgRoute.Delete("/invites/links/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.DeleteOrgInviteLink))
+			orgRoute.Get("/invites/requests", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.GetOrgAccessRequests))
+			orgRoute.Post("/invites/requests/:id/approve", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), quota("user"), routing.Wrap(hs.ApproveOrgAccessRequest))
+			orgRoute.Post("/invites/requests/:id/reject", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.RejectOrgAccessRequest))
 
 			// prefs
 			orgRoute.Get("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgPreferences))
 			orgRoute.Put("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgPreferences))
 			orgRoute.Patch("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.PatchOrgPreferences))
+
+			// branding
+			orgRoute.Get("/branding", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgBranding))
+			orgRoute.Put("/branding", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgBranding))
+			orgRoute.Delete("/branding", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.DeleteOrgBranding))
+
+			// email templates
+			orgRoute.Get("/email-templates", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgEmailTemplates))
+			orgRoute.Get("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgEmailTemplate))
+			orgRoute.Put("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgEmailTemplate))
+			orgRoute.Delete("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.DeleteOrgEmailTemplate))
+			orgRoute.Post("/email-templates/:name/preview", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.PreviewOrgEmailTemplate))
+			orgRoute.Post("/email-templates/:name/test", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.SendTestOrgEmailTemplate))
+
+			// current org query policy
+			orgRoute.Get("/query-policy", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsRead)), routing.Wrap(hs.GetOrgQueryPolicy))
+			orgRoute.Put("/query-policy", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgQueryPolicy))
+			orgRoute.Get("/egress-policy", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsRead)), routing.Wrap(hs.GetOrgEgressPolicy))
+			orgRoute.Put("/egress-policy", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgEgressPolicy))
+			orgRoute.Get("/anonymous-access", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsRead)), routing.Wrap(hs.GetOrgAnonymousAccess))
+			orgRoute.Put("/anonymous-access", authorize(reqOrgAdmin, ac.EvalPermission(ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgAnonymousAccess))
 		})
 
 		// current org without requirement of user to be org admin
@@ -288,6 +337,13 @@ func (hs *HTTPServer) registerRoutes() {
 			orgsRoute.Delete("/users/:userId", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgUsersRemove, userIDScope)), routing.Wrap(hs.RemoveOrgUser))
 			orgsRoute.Get("/quotas", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsQuotasRead)), routing.Wrap(hs.GetOrgQuotas))
 			orgsRoute.Put("/quotas/:target", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsQuotasWrite)), routing.Wrap(hs.UpdateOrgQuota))
+			orgsRoute.Get("/security-headers", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsRead)), routing.Wrap(hs.GetOrgSecurityHeaders))
+			orgsRoute.Put("/security-headers", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgSecurityHeaders))
+			orgsRoute.Delete("/security-headers", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsWrite)), routing.Wrap(hs.DeleteOrgSecurityHeaders))
+			orgsRoute.Patch("/preferences/users", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.AdminPatchOrgUsersPreferences))
+			orgsRoute.Delete("/preferences/users", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.AdminResetOrgUsersPreferences))
+			orgsRoute.Get("/preferences/export", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsPreferencesRead)), routing.Wrap(hs.AdminExportOrgPreferences))
+			orgsRoute.Post("/preferences/import", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ActionOrgsPreferencesWrite)), routing.Wrap(hs.AdminImportOrgPreferences))
 		})
 
 		// orgs (admin routes)
@@ -313,6 +369,13 @@ func (hs *HTTPServer) registerRoutes() {
 			nameScope := datasources.ScopeProvider.GetResourceScopeName(ac.Parameter(":name"))
 			datasourceRoute.Get("/", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(hs.GetDataSources))
 			datasourceRoute.Post("/", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionCreate)), quota("data_source"), routing.Wrap(hs.AddDataSource))
+			datasourceRoute.Get("/order", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(hs.GetDataSourceOrder))
+			datasourceRoute.Put("/order", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, datasources.ScopeAll)), routing.Wrap(hs.SetDataSourceOrder))
+			datasourceRoute.Get("/templates", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(hs.GetDataSourceTemplates))
+			datasourceRoute.Get("/templates/:uid", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(hs.GetDataSourceTemplate))
+			datasourceRoute.Put("/templates/:uid", authorize(reqOrgAdmin, ac.EvalAll(ac.EvalPermission(datasources.ActionCreate), ac.EvalPermission(datasources.ActionWrite, datasources.ScopeAll))), routing.Wrap(hs.UpdateDataSourceTemplate))
+			datasourceRoute.Delete("/templates/:uid", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionDelete, datasources.ScopeAll)), routing.Wrap(hs.DeleteDataSourceTemplate))
+			datasourceRoute.Post("/templates/:uid/rotate-credentials", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, datasources.ScopeAll)), routing.Wrap(hs.RotateDataSourceTemplateCredentials))
 			datasourceRoute.Put("/:id", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, idScope)), routing.Wrap(hs.UpdateDataSourceByID))
 			datasourceRoute.Put("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.UpdateDataSourceByUID))
 			datasourceRoute.Delete("/:id", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionDelete, idScope)), routing.Wrap(hs.DeleteDataSourceById))
@@ -320,6 +383,14 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Delete("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionDelete, nameScope)), routing.Wrap(hs.DeleteDataSourceByName))
 			datasourceRoute.Get("/:id", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, idScope)), routing.Wrap(hs.GetDataSourceById))
 			datasourceRoute.Get("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(hs.GetDataSourceByUID))
+			datasourceRoute.Get("/uid/:uid/tls", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(hs.GetDataSourceCertificates))
+			datasourceRoute.Put("/uid/:uid/tls", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.UpdateDataSourceCertificates))
+			datasourceRoute.Delete("/uid/:uid/tls", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.DeleteDataSourceCertificates))
+			datasourceRoute.Post("/uid/:uid/unlock", authorize(reqGrafanaAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.UnlockDataSource))
+			datasourceRoute.Post("/uid/:uid/default", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.SetDefaultDataSource))
+			datasourceRoute.Post("/uid/:uid/lock", authorize(reqGrafanaAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.LockDataSource))
+			datasourceRoute.Get("/uid/:uid/lock/history", authorize(reqGrafanaAdmin, ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(hs.GetDataSourceLockHistory))
+			datasourceRoute.Get("/uid/:uid/secrets/access", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.GetDataSourceSecretsAccess))
 			datasourceRoute.Get("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, nameScope)), routing.Wrap(hs.GetDataSourceByName))
 			datasourceRoute.Get("/id/:name", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionIDRead, nameScope)), routing.Wrap(hs.GetDataSourceIdByName))
 		})
@@ -357,6 +428,9 @@ func (hs *HTTPServer) registerRoutes() {
 		// Deprecated: use /datasources/uid/:uid/health API instead.
 		apiRoute.Any("/datasources/:id/health", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery)), routing.Wrap(hs.CheckDatasourceHealth))
 		apiRoute.Any("/datasources/uid/:uid/health", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery)), routing.Wrap(hs.CheckDatasourceHealthWithUID))
+		apiRoute.Get("/datasources/uid/:uid/adhoc-filters/keys", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid")))), routing.Wrap(hs.GetAdHocFilterKeys))
+		apiRoute.Get("/datasources/uid/:uid/adhoc-filters/values", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid")))), routing.Wrap(hs.GetAdHocFilterValues))
+		apiRoute.Get("/datasources/uid/:uid/metadata/:kind", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid")))), routing.Wrap(hs.GetDataSourceMetadata))
 
 		// Folders
 		apiRoute.Group("/folders", func(folderRoute routing.RouteRegister) {
@@ -364,12 +438,14 @@ func (hs *HTTPServer) registerRoutes() {
 			uidScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":uid"))
 			folderRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead)), routing.Wrap(hs.GetFolders))
 			folderRoute.Get("/id/:id", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, idScope)), routing.Wrap(hs.GetFolderByID))
-			folderRoute.Post("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersCreate)), routing.Wrap(hs.CreateFolder))
+			folderRoute.Post("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersCreate)), routing.WrapInTransaction(hs.SQLStore, hs.CreateFolder))
 
 			folderRoute.Group("/:uid", func(folderUidRoute routing.RouteRegister) {
 				folderUidRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderByUID))
 				folderUidRoute.Put("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersDelete, uidScope)), routing.Wrap(hs.DeleteFolder))
+				folderUidRoute.Post("/archive", authorize(reqEditorRole, ac.EvalPermission(dashboards.ActionFoldersDelete, uidScope)), routing.Wrap(hs.ArchiveFolder))
+				folderUidRoute.Get("/export", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.ExportFolder))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersPermissionsRead, uidScope)), routing.Wrap(hs.GetFolderPermissionList))
@@ -378,14 +454,58 @@ func (hs *HTTPServer) registerRoutes() {
 			})
 		})
 
+		// Labels of dashboards, folders, data sources, alert rules and teams
+		apiRoute.Group("/resources", func(resourcesRoute routing.RouteRegister) {
+			resourcesRoute.Get("/", routing.Wrap(hs.SearchLabeledResources))
+			resourcesRoute.Get("/:kind/:uid/labels", routing.Wrap(hs.GetResourceLabels))
+			resourcesRoute.Put("/:kind/:uid/labels", routing.Wrap(hs.SetResourceLabels))
+		})
+
+		// Ownership of dashboards and alert rules
+		apiRoute.Group("/ownership", func(ownershipRoute routing.RouteRegister) {
+			ownershipRoute.Get("/search", routing.Wrap(hs.SearchOwnedResources))
+			ownershipRoute.Get("/orphaned", reqOrgAdmin, routing.Wrap(hs.GetOrphanedResources))
+			ownershipRoute.Get("/:kind/:uid", routing.Wrap(hs.GetResourceOwnership))
+			ownershipRoute.Put("/:kind/:uid", routing.Wrap(hs.SetResourceOwnership))
+			ownershipRoute.Delete("/:kind/:uid", routing.Wrap(hs.DeleteResourceOwnership))
+		})
+
 		// Dashboard
 		apiRoute.Group("/dashboards", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/uid/:uid", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/uid/:uid", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsDelete)), routing.Wrap(hs.DeleteDashboardByUID))
+			dashboardRoute.Post("/uid/:uid/variables/resolve", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(hs.ResolveDashboardVariables))
+			dashboardRoute.Post("/uid/:uid/panels/:panelId/snapshot", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(hs.CreatePanelSnapshot))
+			dashboardRoute.Post("/uid/:uid/recordings", authorize(reqEditorRole, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.RecordDashboard))
+			dashboardRoute.Post("/uid/:uid/migrate-schema", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid")))), routing.Wrap(hs.MigrateDashboardSchema))
+			dashboardRoute.Post("/migrate-schema", reqOrgAdmin, routing.Wrap(hs.MigrateAllDashboardSchemas))
+			dashboardRoute.Get("/schema-report", reqOrgAdmin, routing.Wrap(hs.GetDashboardSchemaReport))
+			dashboardRoute.Post("/repair-refs", reqOrgAdmin, routing.Wrap(hs.RepairDashboardRefs))
+			dashboardRoute.Get("/link-check", reqOrgAdmin, routing.Wrap(hs.GetDashboardLinkReport))
+			dashboardRoute.Post("/link-check", reqOrgAdmin, routing.Wrap(hs.CheckDashboardLinks))
+			dashboardRoute.Get("/query-schemas/drift", reqOrgAdmin, routing.Wrap(hs.GetQuerySchemaDrift))
+			dashboardRoute.Get("/uid/:uid/query-schemas", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(hs.GetDashboardQuerySchemas))
+			dashboardRoute.Get("/uid/:uid/query-schemas/drift", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(hs.GetDashboardQuerySchemaDrift))
+			dashboardRoute.Post("/uid/:uid/archive", authorize(reqEditorRole, ac.EvalPermission(dashboards.ActionDashboardsDelete)), routing.Wrap(hs.ArchiveDashboard))
+			dashboardRoute.Get("/archive", reqOrgAdmin, routing.Wrap(hs.SearchArchivedDashboards))
+			dashboardRoute.Post("/archive/:uid/restore", reqOrgAdmin, routing.Wrap(hs.RestoreArchivedDashboard))
+			dashboardRoute.Get("/trash", reqOrgAdmin, routing.Wrap(hs.SearchTrashedDashboards))
+			dashboardRoute.Post("/trash/:uid/restore", reqOrgAdmin, routing.Wrap(hs.RestoreTrashedDashboard))
+			dashboardRoute.Get("/unused", reqOrgAdmin, routing.Wrap(hs.GetUnusedDashboards))
+
+			dashboardRoute.Group("/templates", func(templatesRoute routing.RouteRegister) {
+				uidScope := dashboardtemplates.ScopeTemplatesProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+				templatesRoute.Get("/", authorize(reqEditorRole, ac.EvalPermission(dashboardtemplates.ActionTemplatesRead)), routing.Wrap(hs.GetDashboardTemplates))
+				templatesRoute.Get("/:uid", authorize(reqEditorRole, ac.EvalPermission(dashboardtemplates.ActionTemplatesRead, uidScope)), routing.Wrap(hs.GetDashboardTemplate))
+				templatesRoute.Put("/:uid", authorize(reqOrgAdmin, ac.EvalPermission(dashboardtemplates.ActionTemplatesWrite, uidScope)), routing.Wrap(hs.UpdateDashboardTemplate))
+				templatesRoute.Delete("/:uid", authorize(reqOrgAdmin, ac.EvalPermission(dashboardtemplates.ActionTemplatesDelete, uidScope)), routing.Wrap(hs.DeleteDashboardTemplate))
+				templatesRoute.Post("/:uid/instantiate", authorize(reqEditorRole, ac.EvalAll(ac.EvalPermission(dashboardtemplates.ActionTemplatesRead, uidScope), ac.EvalPermission(dashboards.ActionDashboardsCreate))), routing.Wrap(hs.InstantiateDashboardTemplate))
+			})
 			dashboardRoute.Group("/uid/:uid", func(dashUidRoute routing.RouteRegister) {
 				dashUidRoute.Get("/versions", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.GetDashboardVersions))
 				dashUidRoute.Post("/restore", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.RestoreDashboardVersion))
 				dashUidRoute.Get("/versions/:id", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.GetDashboardVersion))
+				dashUidRoute.Get("/versions/:id/diff", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.GetDashboardVersionPatch))
 				dashUidRoute.Group("/permissions", func(dashboardPermissionRoute routing.RouteRegister) {
 					dashboardPermissionRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsPermissionsRead)), routing.Wrap(hs.GetDashboardPermissionList))
 					dashboardPermissionRoute.Post("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsPermissionsWrite)), routing.Wrap(hs.UpdateDashboardPermissions))
@@ -396,6 +516,7 @@ func (hs *HTTPServer) registerRoutes() {
 				if hs.Features.IsEnabled(featuremgmt.FlagPublicDashboards) {
 					dashUidRoute.Get("/public-config", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.GetPublicDashboardConfig))
 					dashUidRoute.Post("/public-config", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.SavePublicDashboardConfig))
+					dashUidRoute.Post("/public-config/rotate-token", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.RotatePublicDashboardAccessToken))
 				}
 
 				if hs.ThumbService != nil {
@@ -411,6 +532,7 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Post("/trim", routing.Wrap(hs.TrimDashboard))
 
 			dashboardRoute.Post("/db", authorize(reqSignedIn, ac.EvalAny(ac.EvalPermission(dashboards.ActionDashboardsCreate), ac.EvalPermission(dashboards.ActionDashboardsWrite))), routing.Wrap(hs.PostDashboard))
+			dashboardRoute.Post("/apply", authorize(reqSignedIn, ac.EvalAny(ac.EvalPermission(dashboards.ActionDashboardsCreate), ac.EvalPermission(dashboards.ActionDashboardsWrite))), routing.Wrap(hs.ApplyDashboards))
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", hs.GetDashboardTags)
 
@@ -446,6 +568,17 @@ func (hs *HTTPServer) registerRoutes() {
 			playlistRoute.Post("/", reqEditorRole, routing.Wrap(hs.CreatePlaylist))
 		})
 
+		// Time region calendars
+		apiRoute.Group("/time-regions/calendars", func(calendarRoute routing.RouteRegister) {
+			calendarRoute.Get("/", routing.Wrap(hs.SearchTimeRegionCalendars))
+			calendarRoute.Post("/", reqEditorRole, routing.Wrap(hs.CreateTimeRegionCalendar))
+			calendarRoute.Get("/:uid", routing.Wrap(hs.GetTimeRegionCalendar))
+			calendarRoute.Put("/:uid", reqEditorRole, routing.Wrap(hs.UpdateTimeRegionCalendar))
+			calendarRoute.Delete("/:uid", reqEditorRole, routing.Wrap(hs.DeleteTimeRegionCalendar))
+			calendarRoute.Get("/:uid/windows", routing.Wrap(hs.GetTimeRegionCalendarWindows))
+			calendarRoute.Get("/:uid/active", routing.Wrap(hs.GetTimeRegionCalendarActive))
+		})
+
 		// Search
 		apiRoute.Get("/search/sorting", routing.Wrap(hs.ListSortOptions))
 		apiRoute.Get("/search/", routing.Wrap(hs.Search))
@@ -462,6 +595,12 @@ func (hs *HTTPServer) registerRoutes() {
 			alertsRoute.Get("/states-for-dashboard", routing.Wrap(hs.GetAlertStatesForDashboard))
 		})
 
+		apiRoute.Group("/alerting/classic-conditions", func(classicRoute routing.RouteRegister) {
+			classicRoute.Get("/report", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAlertingRuleRead)), routing.Wrap(hs.GetClassicConditionReport))
+			classicRoute.Post("/migrate", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAlertingRuleUpdate)), routing.Wrap(hs.MigrateAllClassicConditions))
+			classicRoute.Post("/migrate/:uid", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAlertingRuleUpdate)), routing.Wrap(hs.MigrateClassicCondition))
+		})
+
 		var notifiersAuthHandler web.Handler
 		if hs.Cfg.UnifiedAlerting.IsEnabled() {
 			notifiersAuthHandler = reqSignedIn
@@ -501,6 +640,7 @@ func (hs *HTTPServer) registerRoutes() {
 			annotationsRoute.Patch("/:annotationId", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsWrite, ac.ScopeAnnotationsID)), routing.Wrap(hs.PatchAnnotation))
 			annotationsRoute.Post("/graphite", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization)), routing.Wrap(hs.PostGraphiteAnnotation))
 			annotationsRoute.Get("/tags", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.GetAnnotationTags))
+			annotationsRoute.Get("/sources", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.GetAnnotationSources))
 		})
 
 		apiRoute.Post("/frontend-metrics", routing.Wrap(hs.PostFrontendMetrics))
@@ -536,20 +676,26 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// short urls
 		apiRoute.Post("/short-urls", routing.Wrap(hs.createShortURL))
+		apiRoute.Get("/short-urls", reqOrgAdmin, routing.Wrap(hs.searchShortURLs))
+		apiRoute.Post("/short-urls/prune", reqOrgAdmin, routing.Wrap(hs.pruneShortURLs))
+		apiRoute.Delete("/short-urls/:uid", reqOrgAdmin, routing.Wrap(hs.deleteShortURL))
 
 		apiRoute.Group("/comments", func(commentRoute routing.RouteRegister) {
 			commentRoute.Post("/get", routing.Wrap(hs.commentsGet))
 			commentRoute.Post("/create", routing.Wrap(hs.commentsCreate))
+			commentRoute.Get("/activity", routing.Wrap(hs.commentsActivity))
 		})
 	}, reqSignedIn)
 
 	// admin api
 	r.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Get("/settings", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSettings))
+		adminRoute.Get("/config-drift", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetConfigDrift))
 		if hs.Features.IsEnabled(featuremgmt.FlagShowFeatureFlagsInUI) {
 			adminRoute.Get("/settings/features", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), hs.Features.HandleGetSettings)
 		}
 		adminRoute.Get("/stats", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetStats))
+		adminRoute.Get("/stats/history", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetStatsHistory))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, routing.Wrap(hs.PauseAllAlerts))
 
 		if hs.ThumbService != nil && hs.Features.IsEnabled(featuremgmt.FlagDashboardPreviewsAdmin) {
@@ -563,7 +709,29 @@ func (hs *HTTPServer) registerRoutes() {
 			adminRoute.Post("/export", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleRequestExport))
 		}
 
+		adminRoute.Get("/feature-toggles", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetFeatureToggles))
+		adminRoute.Put("/feature-toggles", reqGrafanaAdmin, routing.Wrap(hs.AdminUpdateFeatureToggles))
+
+		adminRoute.Get("/preferences", reqGrafanaAdmin, routing.Wrap(hs.AdminGetInstanceDefaults))
+		adminRoute.Put("/preferences", reqGrafanaAdmin, routing.Wrap(hs.AdminUpdateInstanceDefaults))
+		adminRoute.Delete("/preferences", reqGrafanaAdmin, routing.Wrap(hs.AdminResetInstanceDefaults))
+
+		adminRoute.Get("/maintenance", reqGrafanaAdmin, routing.Wrap(hs.AdminGetMaintenance))
+		adminRoute.Post("/maintenance", reqGrafanaAdmin, routing.Wrap(hs.AdminEnableMaintenance))
+		adminRoute.Delete("/maintenance", reqGrafanaAdmin, routing.Wrap(hs.AdminDisableMaintenance))
+
+		adminRoute.Get("/announcements", reqGrafanaAdmin, routing.Wrap(hs.AdminListAnnouncements))
+		adminRoute.Post("/announcements", reqGrafanaAdmin, routing.Wrap(hs.AdminCreateAnnouncement))
+		adminRoute.Get("/announcements/:uid", reqGrafanaAdmin, routing.Wrap(hs.AdminGetAnnouncement))
+		adminRoute.Put("/announcements/:uid", reqGrafanaAdmin, routing.Wrap(hs.AdminUpdateAnnouncement))
+		adminRoute.Delete("/announcements/:uid", reqGrafanaAdmin, routing.Wrap(hs.AdminDeleteAnnouncement))
+
+		adminRoute.Post("/dashboards/copy", reqGrafanaAdmin, routing.Wrap(hs.AdminCopyDashboard))
+
 		adminRoute.Post("/encryption/rotate-data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminRotateDataEncryptionKeys))
+		adminRoute.Get("/encryption/providers", reqGrafanaAdmin, routing.Wrap(hs.AdminGetEncryptionProviders))
+
+		adminRoute.Get("/email/deliveries", reqGrafanaAdmin, routing.Wrap(hs.AdminGetEmailDeliveries))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
@@ -576,11 +744,19 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Get("/ldap/status", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(hs.GetLDAPStatus))
 	})
 
+	// Time-limited access grants
+	r.Group("/api/access-control/grants", func(grantsRoute routing.RouteRegister) {
+		grantsRoute.Get("/", reqGrafanaAdmin, routing.Wrap(hs.GetAccessGrants))
+		grantsRoute.Post("/", reqGrafanaAdmin, routing.Wrap(hs.CreateAccessGrant))
+		grantsRoute.Delete("/:id", reqGrafanaAdmin, routing.Wrap(hs.RevokeAccessGrant))
+	})
+
 	// Administering users
 	r.Group("/api/admin/users", func(adminUserRoute routing.RouteRegister) {
 		userIDScope := ac.Scope("global.users", "id", ac.Parameter(":id"))
 
 		adminUserRoute.Post("/", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersCreate)), routing.Wrap(hs.AdminCreateUser))
+		adminUserRoute.Get("/stale-passwords", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersRead, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminGetStalePasswords))
 		adminUserRoute.Put("/:id/password", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersPasswordUpdate, userIDScope)), routing.Wrap(hs.AdminUpdateUserPassword))
 		adminUserRoute.Put("/:id/permissions", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersPermissionsUpdate, userIDScope)), routing.Wrap(hs.AdminUpdateUserPermissions))
 		adminUserRoute.Delete("/:id", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersDelete, userIDScope)), routing.Wrap(hs.AdminDeleteUser))
@@ -612,8 +788,9 @@ func (hs *HTTPServer) registerRoutes() {
 
 	// Public API
 	if hs.Features.IsEnabled(featuremgmt.FlagPublicDashboards) {
-		r.Get("/api/public/dashboards/:uid", routing.Wrap(hs.GetPublicDashboard))
-		r.Post("/api/public/dashboards/:uid/panels/:panelId/query", routing.Wrap(hs.QueryPublicDashboard))
+		publicDashboardAccessToken := middleware.PublicDashboardAccessToken(hs.dashboardService)
+		r.Get("/api/public/dashboards/:accessToken", publicDashboardAccessToken, routing.Wrap(hs.GetPublicDashboard))
+		r.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", publicDashboardAccessToken, routing.Wrap(hs.QueryPublicDashboard))
 	}
 
 	// Frontend logs
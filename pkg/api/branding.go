@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/org/branding
+func (hs *HTTPServer) GetOrgBranding(c *models.ReqContext) response.Response {
+	result, err := hs.brandingService.Get(c.Req.Context(), &branding.GetBrandingQuery{OrgID: c.OrgId})
+	if err != nil {
+		if errors.Is(err, branding.ErrBrandingNotFound) {
+			return response.JSON(http.StatusOK, &branding.OrgBranding{})
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get branding", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/org/branding
+func (hs *HTTPServer) UpdateOrgBranding(c *models.ReqContext) response.Response {
+	cmd := branding.SaveBrandingCommand{}
+	if err := web.Bind(c.Req, &cmd.Branding); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+
+	if err := hs.brandingService.Save(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, branding.ErrInvalidBrandingURL) ||
+			errors.Is(err, branding.ErrInvalidFooterLink) ||
+			errors.Is(err, branding.ErrTooManyFooterLinks) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save branding", err)
+	}
+
+	return response.Success("Branding updated")
+}
+
+// DELETE /api/org/branding
+func (hs *HTTPServer) DeleteOrgBranding(c *models.ReqContext) response.Response {
+	if err := hs.brandingService.Delete(c.Req.Context(), &branding.DeleteBrandingCommand{OrgID: c.OrgId}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset branding", err)
+	}
+
+	return response.Success("Branding reset")
+}
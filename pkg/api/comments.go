@@ -41,9 +41,28 @@ func (hs *HTTPServer) commentsCreate(c *models.ReqContext) response.Response {
 		if errors.Is(err, comments.ErrPermissionDenied) {
 			return response.Error(http.StatusForbidden, "permission denied", err)
 		}
+		if errors.Is(err, comments.ErrParentNotFound) {
+			return response.Error(http.StatusBadRequest, "parent comment not found", err)
+		}
 		return response.Error(http.StatusInternalServerError, "internal error", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"comment": comment,
 	})
 }
+
+func (hs *HTTPServer) commentsActivity(c *models.ReqContext) response.Response {
+	cmd := comments.ActivityCmd{
+		Limit:        uint(c.QueryInt("limit")),
+		BeforeId:     c.QueryInt64("beforeId"),
+		DashboardUID: c.Query("dashboardUid"),
+		MentionsOnly: c.QueryBool("mentionsOnly"),
+	}
+	items, err := hs.commentsService.Activity(c.Req.Context(), c.OrgId, c.SignedInUser, cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "internal error", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"activity": items,
+	})
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/configdrift"
+)
+
+// AdminGetConfigDrift compares the configuration of the instances of a highly
+// available setup and flags the instances whose configuration differs.
+// GET /api/admin/config-drift
+func (hs *HTTPServer) AdminGetConfigDrift(c *models.ReqContext) response.Response {
+	report, err := hs.configDrift.GetReport(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, configdrift.ErrDisabled) {
+			return response.Error(http.StatusNotFound, "Configuration drift detection is disabled", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get configuration drift report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
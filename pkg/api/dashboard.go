@@ -10,19 +10,24 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/jsonschema"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/coremodel/dashboard"
 	"github.com/grafana/grafana/pkg/cuectx"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
@@ -178,8 +183,22 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		}
 	}
 
+	managed, err := hs.getManagedDashboard(c, dash.Id, dash.Uid)
+	if err != nil {
+		return response.Error(500, "Error while checking if dashboard is managed as code", err)
+	}
+	if managed != nil {
+		meta.ManagedBy = managed.ManagedBy
+		// only the manifest can change the dashboard, unless it is the user applying it
+		if !managed.IsOwner(c.SignedInUser) {
+			meta.CanSave = false
+			meta.CanDelete = false
+		}
+	}
+
 	// make sure db version is in sync with json model version
 	dash.Data.Set("version", dash.Version)
+	dashboards.RemoveHiddenPanels(c.SignedInUser, dash.Data)
 
 	// load library panels JSON for this dashboard
 	err = hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c.Req.Context(), dash)
@@ -192,6 +211,13 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		Meta:      meta,
 	}
 
+	hs.publishDashboardUsage(c.Req.Context(), &events.DashboardViewed{
+		Timestamp: time.Now(),
+		ID:        dash.Id,
+		OrgID:     c.OrgId,
+		UserID:    c.UserId,
+	})
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(http.StatusOK, dto)
 }
@@ -256,6 +282,9 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 	if canDelete, err := guardian.CanDelete(); err != nil || !canDelete {
 		return dashboardGuardianResponse(err)
 	}
+	if rsp := hs.checkManagedDashboard(c, dash.Id, dash.Uid); rsp != nil {
+		return rsp
+	}
 
 	// disconnect all library elements for this dashboard
 	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
@@ -263,8 +292,17 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
 	}
 
-	err = hs.dashboardService.DeleteDashboard(c.Req.Context(), dash.Id, c.OrgId)
-	if err != nil {
+	// the dashboard is moved to the trash, unless the trash is disabled
+	if hs.Cfg.DashboardTrash.RetentionDays > 0 {
+		if _, err := hs.dashboardArchive.Archive(c.Req.Context(), &dashboardarchive.ArchiveCommand{
+			OrgID:  c.OrgId,
+			UID:    dash.Uid,
+			User:   c.SignedInUser,
+			Reason: dashboardarchive.ReasonDeleted,
+		}); err != nil {
+			return hs.dashboardArchiveErrorResponse(c, err)
+		}
+	} else if err = hs.dashboardService.DeleteDashboard(c.Req.Context(), dash.Id, c.OrgId); err != nil {
 		var dashboardErr models.DashboardErr
 		if ok := errors.As(err, &dashboardErr); ok {
 			if errors.Is(err, models.ErrDashboardCannotDeleteProvisionedDashboard) {
@@ -323,10 +361,35 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext) response.Response {
 		}
 	}
 
-	return hs.postDashboard(c, cmd)
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "bad request data", errors.New("dashboard is required"))
+	}
+	validation, err := dashboardschema.Validate(cmd.Dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to validate dashboard", err)
+	}
+	// validate=true checks the dashboard without saving it
+	if c.QueryBool("validate") {
+		return response.JSON(http.StatusOK, validation)
+	}
+	if cmd.DryRun {
+		return hs.dryRunPostDashboard(c, cmd, validation)
+	}
+	if !validation.Valid {
+		return response.JSON(http.StatusBadRequest, util.DynMap{
+			"status":   "invalid-dashboard",
+			"message":  dashboardschema.ErrInvalidDashboard.Error(),
+			"errors":   validation.Errors,
+			"warnings": validation.Warnings,
+		})
+	}
+
+	return hs.postDashboard(c, cmd, validation.Warnings)
 }
 
-func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboardCommand) response.Response {
+// postDashboard saves the dashboard, the warnings of its validation are returned
+// with the saved dashboard.
+func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboardCommand, warnings []jsonschema.FieldError) response.Response {
 	ctx := c.Req.Context()
 	var err error
 	cmd.OrgId = c.OrgId
@@ -343,6 +406,20 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	}
 
 	dash := cmd.GetDashboardModel()
+	if rsp := hs.checkManagedDashboard(c, dash.Id, dash.Uid); rsp != nil {
+		return rsp
+	}
+	// the panels the user can't see were removed from the dashboard they loaded
+	if !dashboards.CanViewAllPanels(c.SignedInUser) && (dash.Id != 0 || dash.Uid != "") {
+		existing := models.GetDashboardQuery{OrgId: c.OrgId, Id: dash.Id, Uid: dash.Uid}
+		err := hs.dashboardService.GetDashboard(c.Req.Context(), &existing)
+		if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+			return response.Error(500, "Error while checking existing dashboard", err)
+		}
+		if err == nil {
+			dashboards.RestoreHiddenPanels(c.SignedInUser, existing.Result.Data, dash.Data)
+		}
+	}
 	newDashboard := dash.Id == 0
 	if newDashboard {
 		limitReached, err := hs.QuotaService.QuotaReached(c, "dashboard")
@@ -354,24 +431,9 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		}
 	}
 
-	var provisioningData *models.DashboardProvisioning
-	if dash.Id != 0 {
-		data, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardID(dash.Id)
-		if err != nil {
-			return response.Error(500, "Error while checking if dashboard is provisioned using ID", err)
-		}
-		provisioningData = data
-	} else if dash.Uid != "" {
-		data, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardUID(dash.OrgId, dash.Uid)
-		if err != nil && !errors.Is(err, models.ErrProvisionedDashboardNotFound) && !errors.Is(err, models.ErrDashboardNotFound) {
-			return response.Error(500, "Error while checking if dashboard is provisioned", err)
-		}
-		provisioningData = data
-	}
-
-	allowUiUpdate := true
-	if provisioningData != nil {
-		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
+	allowUiUpdate, provisioningData, err := hs.allowDashboardUIUpdate(dash)
+	if err != nil {
+		return response.Error(500, "Error while checking if dashboard is provisioned", err)
 	}
 
 	// clean up all unnecessary library panels JSON properties so we store a minimum JSON
@@ -433,15 +495,60 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return response.Error(500, "Error while connecting library panels", err)
 	}
 
+	if provisioningData != nil {
+		go hs.pushProvisionedDashboard(provisioningData, dashboard, c.SignedInUser, cmd.Message)
+	}
+
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":  "success",
 		"slug":    dashboard.Slug,
 		"version": dashboard.Version,
 		"id":      dashboard.Id,
 		"uid":     dashboard.Uid,
 		"url":     dashboard.GetUrl(),
-	})
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// allowDashboardUIUpdate returns false if the dashboard is provisioned and its
+// provisioning source doesn't allow updates from the UI. It also returns the
+// provisioning data of the dashboard, nil if it isn't provisioned.
+func (hs *HTTPServer) allowDashboardUIUpdate(dash *models.Dashboard) (bool, *models.DashboardProvisioning, error) {
+	var provisioningData *models.DashboardProvisioning
+	if dash.Id != 0 {
+		data, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardID(dash.Id)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to get provisioning data by dashboard ID: %w", err)
+		}
+		provisioningData = data
+	} else if dash.Uid != "" {
+		data, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardUID(dash.OrgId, dash.Uid)
+		if err != nil && !errors.Is(err, models.ErrProvisionedDashboardNotFound) && !errors.Is(err, models.ErrDashboardNotFound) {
+			return false, nil, err
+		}
+		provisioningData = data
+	}
+
+	if provisioningData == nil {
+		return true, nil, nil
+	}
+	return hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name), provisioningData, nil
+}
+
+// pushProvisionedDashboard pushes a provisioned dashboard updated from the UI
+// back to the Git repository it's provisioned from. It runs in the background,
+// the dashboard being saved regardless of the outcome.
+func (hs *HTTPServer) pushProvisionedDashboard(provisioningData *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := hs.ProvisioningService.PushDashboardUIUpdate(ctx, provisioningData, dash, user, message); err != nil {
+		hs.log.Error("Failed to push dashboard updated from the UI to its provisioning repository", "uid", dash.Uid, "provisioner", provisioningData.Name, "error", err)
+	}
 }
 
 // GetHomeDashboard returns the home dashboard.
@@ -470,7 +577,7 @@ func (hs *HTTPServer) GetHomeDashboard(c *models.ReqContext) response.Response {
 		hs.log.Warn("Failed to get slug from database", "err", err)
 	}
 
-	filePath := hs.Cfg.DefaultHomeDashboardPath
+	filePath := hs.preferenceService.GetInstanceDefaults().HomeDashboardPath
 	if filePath == "" {
 		filePath = filepath.Join(hs.Cfg.StaticRootPath, "dashboards/home.json")
 	}
@@ -509,7 +616,7 @@ func (hs *HTTPServer) addGettingStartedPanelToHomeDashboard(c *models.ReqContext
 	// and if a custom default home dashboard hasn't been configured
 	if !c.HasUserRole(models.ROLE_ADMIN) ||
 		c.HasHelpFlag(models.HelpFlagGettingStartedPanelDismissed) ||
-		hs.Cfg.DefaultHomeDashboardPath != "" {
+		hs.preferenceService.GetInstanceDefaults().HomeDashboardPath != "" {
 		return
 	}
 
@@ -629,6 +736,8 @@ func (hs *HTTPServer) GetDashboardVersion(c *models.ReqContext) response.Respons
 		return response.Error(500, fmt.Sprintf("Dashboard version %d not found for dashboardId %d", query.Version, dashID), err)
 	}
 
+	dashboards.RemoveHiddenPanels(c.SignedInUser, res.Data)
+
 	creator := anonString
 	if res.CreatedBy > 0 {
 		creator = hs.getUserLogin(c.Req.Context(), res.CreatedBy)
@@ -713,6 +822,8 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Resp
 
 	baseData := baseVersionRes.Data
 	newData := newVersionRes.Data
+	dashboards.RemoveHiddenPanels(c.SignedInUser, baseData)
+	dashboards.RemoveHiddenPanels(c.SignedInUser, newData)
 
 	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &options, baseData, newData)
 
@@ -730,6 +841,79 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Resp
 	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
 }
 
+// GET /api/dashboards/uid/:uid/versions/:id/diff returns the JSON patch from
+// the base version, the parent version by default, to the version.
+func (hs *HTTPServer) GetDashboardVersionPatch(c *models.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	version, err := strconv.Atoi(web.Params(c.Req)[":id"])
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	newVersion, err := hs.dashboardVersionService.Get(c.Req.Context(), &dashver.GetDashboardVersionQuery{
+		OrgID:       c.OrgId,
+		DashboardID: dash.Id,
+		Version:     version,
+	})
+	if err != nil {
+		return dashboardVersionError(err)
+	}
+
+	result := dtos.DashboardVersionPatch{
+		BaseVersion: newVersion.ParentVersion,
+		NewVersion:  newVersion.Version,
+	}
+	if c.Query("base") != "" {
+		if result.BaseVersion, err = strconv.Atoi(c.Query("base")); err != nil {
+			return response.Error(http.StatusBadRequest, "base is invalid", err)
+		}
+	}
+
+	// the first version is compared with an empty dashboard
+	var baseData *simplejson.Json
+	if result.BaseVersion > 0 {
+		baseVersion, err := hs.dashboardVersionService.Get(c.Req.Context(), &dashver.GetDashboardVersionQuery{
+			OrgID:       c.OrgId,
+			DashboardID: dash.Id,
+			Version:     result.BaseVersion,
+		})
+		if err != nil {
+			return dashboardVersionError(err)
+		}
+		baseData = baseVersion.Data
+		dashboards.RemoveHiddenPanels(c.SignedInUser, baseData)
+	}
+	dashboards.RemoveHiddenPanels(c.SignedInUser, newVersion.Data)
+
+	ignore := c.QueryStrings("ignore")
+	if c.QueryBool("ignoreVolatile") {
+		ignore = append(ignore, dashdiffs.VolatilePaths...)
+	}
+
+	result.Patch, err = dashdiffs.CalculatePatch(baseData, newVersion.Data, ignore)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func dashboardVersionError(err error) response.Response {
+	if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+		return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+	}
+	return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+}
+
 // RestoreDashboardVersion restores a dashboard to the given version.
 func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Response {
 	var dashID int64
@@ -778,7 +962,7 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Res
 	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
 	saveCmd.FolderId = dash.FolderId
 
-	return hs.postDashboard(c, saveCmd)
+	return hs.postDashboard(c, saveCmd, nil)
 }
 
 func (hs *HTTPServer) GetDashboardTags(c *models.ReqContext) {
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ApplyDashboards applies a manifest of dashboards managed as code. Dashboards
+// of the manifest are created or updated, dashboards that were applied with
+// the same managedBy before but are no longer part of it are deleted.
+// POST /api/dashboards/apply
+func (hs *HTTPServer) ApplyDashboards(c *models.ReqContext) response.Response {
+	cmd := dashboardapply.ApplyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.User = c.SignedInUser
+
+	result, err := hs.dashboardApply.Apply(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboardapply.ErrManagedByRequired),
+			errors.Is(err, dashboardapply.ErrManagedByTooLong),
+			errors.Is(err, dashboardapply.ErrDashboardMissing),
+			errors.Is(err, dashboardapply.ErrDashboardUIDMissing),
+			errors.Is(err, dashboardapply.ErrDuplicateUID):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to apply dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// getManagedDashboard returns how the dashboard is managed as code, or nil if it isn't.
+func (hs *HTTPServer) getManagedDashboard(c *models.ReqContext, dashboardID int64, dashboardUID string) (*dashboardapply.ManagedDashboard, error) {
+	if hs.dashboardApply == nil {
+		return nil, nil
+	}
+	if dashboardUID == "" {
+		if dashboardID == 0 {
+			return nil, nil
+		}
+		query := models.GetDashboardRefByIdQuery{Id: dashboardID}
+		if err := hs.dashboardService.GetDashboardUIDById(c.Req.Context(), &query); err != nil {
+			if errors.Is(err, models.ErrDashboardNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		dashboardUID = query.Result.Uid
+	}
+
+	m, err := hs.dashboardApply.GetManaged(c.Req.Context(), c.OrgId, dashboardUID)
+	if err != nil {
+		if errors.Is(err, dashboardapply.ErrNotManaged) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkManagedDashboard returns an error response if the dashboard is managed
+// as code and the signed in user is not the one applying it.
+func (hs *HTTPServer) checkManagedDashboard(c *models.ReqContext, dashboardID int64, dashboardUID string) response.Response {
+	m, err := hs.getManagedDashboard(c, dashboardID, dashboardUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking if dashboard is managed as code", err)
+	}
+	if m == nil || m.IsOwner(c.SignedInUser) {
+		return nil
+	}
+	return response.Error(http.StatusForbidden, managedDashboardMessage(m), dashboardapply.ErrDashboardManaged)
+}
+
+func managedDashboardMessage(m *dashboardapply.ManagedDashboard) string {
+	return fmt.Sprintf("Dashboard is managed as code by %s and can only be changed by applying its manifest", m.ManagedBy)
+}
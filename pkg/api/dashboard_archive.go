@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ArchiveDashboard moves a dashboard to the archive.
+// POST /api/dashboards/uid/:uid/archive
+func (hs *HTTPServer) ArchiveDashboard(c *models.ReqContext) response.Response {
+	return hs.archive(c)
+}
+
+// ArchiveFolder moves a folder, with its subfolders and dashboards, to the archive.
+// POST /api/folders/:uid/archive
+func (hs *HTTPServer) ArchiveFolder(c *models.ReqContext) response.Response {
+	return hs.archive(c)
+}
+
+func (hs *HTTPServer) archive(c *models.ReqContext) response.Response {
+	archived, err := hs.dashboardArchive.Archive(c.Req.Context(), &dashboardarchive.ArchiveCommand{
+		OrgID:  c.OrgId,
+		UID:    web.Params(c.Req)[":uid"],
+		User:   c.SignedInUser,
+		Reason: dashboardarchive.ReasonManual,
+	})
+	if err != nil {
+		return hs.dashboardArchiveErrorResponse(c, err)
+	}
+	return response.JSON(http.StatusOK, archived)
+}
+
+// SearchArchivedDashboards returns the archived dashboards and folders of the
+// organization, the most recently archived first.
+// GET /api/dashboards/archive
+func (hs *HTTPServer) SearchArchivedDashboards(c *models.ReqContext) response.Response {
+	result, err := hs.dashboardArchive.Search(c.Req.Context(), &dashboardarchive.SearchQuery{
+		OrgID:     c.OrgId,
+		FolderUID: c.Query("folderUid"),
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search archived dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RestoreArchivedDashboard restores an archived dashboard, or an archived
+// folder with its subfolders and dashboards.
+// POST /api/dashboards/archive/:uid/restore
+func (hs *HTTPServer) RestoreArchivedDashboard(c *models.ReqContext) response.Response {
+	restored, err := hs.dashboardArchive.Restore(c.Req.Context(), &dashboardarchive.RestoreCommand{
+		OrgID: c.OrgId,
+		UID:   web.Params(c.Req)[":uid"],
+		User:  c.SignedInUser,
+	})
+	if err != nil {
+		return hs.dashboardArchiveErrorResponse(c, err)
+	}
+	return response.JSON(http.StatusOK, restored)
+}
+
+// SearchTrashedDashboards returns the deleted dashboards of the organization
+// that are still in the trash, the most recently deleted first.
+// GET /api/dashboards/trash
+func (hs *HTTPServer) SearchTrashedDashboards(c *models.ReqContext) response.Response {
+	result, err := hs.dashboardArchive.Search(c.Req.Context(), &dashboardarchive.SearchQuery{
+		OrgID:     c.OrgId,
+		FolderUID: c.Query("folderUid"),
+		Trashed:   true,
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search trashed dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RestoreTrashedDashboard restores a deleted dashboard from the trash.
+// POST /api/dashboards/trash/:uid/restore
+func (hs *HTTPServer) RestoreTrashedDashboard(c *models.ReqContext) response.Response {
+	restored, err := hs.dashboardArchive.Restore(c.Req.Context(), &dashboardarchive.RestoreCommand{
+		OrgID:   c.OrgId,
+		UID:     web.Params(c.Req)[":uid"],
+		User:    c.SignedInUser,
+		Trashed: true,
+	})
+	if err != nil {
+		return hs.dashboardArchiveErrorResponse(c, err)
+	}
+	return response.JSON(http.StatusOK, restored)
+}
+
+func (hs *HTTPServer) dashboardArchiveErrorResponse(c *models.ReqContext, err error) response.Response {
+	switch {
+	case errors.Is(err, dashboardarchive.ErrAccessDenied),
+		errors.Is(err, models.ErrFolderAccessDenied):
+		return response.Error(http.StatusForbidden, "Access denied", err)
+	case errors.Is(err, dashboardarchive.ErrArchiveNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, dashboardarchive.ErrAlreadyArchived),
+		errors.Is(err, models.ErrFolderWithSameUIDExists),
+		errors.Is(err, models.ErrFolderSameNameExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, dashboardarchive.ErrFolderArchived),
+		errors.Is(err, models.ErrFolderContainsAlertRules):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return apierrors.ToDashboardErrorResponse(c.Req.Context(), hs.pluginStore, err)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive/dashboardarchivetest"
+)
+
+func TestAPIEndpoint_DashboardArchive(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+	archive := dashboardarchivetest.NewDashboardArchiveServiceFake()
+	sc.hs.dashboardArchive = archive
+
+	t.Run("Archives the dashboard as the signed in user", func(t *testing.T) {
+		archive.ExpectedArchived = []*dashboardarchive.ArchivedDashboard{{ID: 1, OrgID: 1, UID: "cpu", Title: "CPU"}}
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/cpu/archive", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []dashboardarchive.ArchivedDashboard
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		require.Len(t, result, 1)
+		assert.Equal(t, "cpu", result[0].UID)
+
+		cmd := archive.ArchiveCommands[len(archive.ArchiveCommands)-1]
+		assert.Equal(t, "cpu", cmd.UID)
+		assert.Equal(t, dashboardarchive.ReasonManual, cmd.Reason)
+		require.NotNil(t, cmd.User)
+		assert.Equal(t, sc.initCtx.SignedInUser.UserId, cmd.User.UserId)
+	})
+
+	t.Run("Archives the folder", func(t *testing.T) {
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/folders/team/archive", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "team", archive.ArchiveCommands[len(archive.ArchiveCommands)-1].UID)
+	})
+
+	t.Run("Maps the archive errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			err    error
+			status int
+		}{
+			{err: dashboardarchive.ErrAccessDenied, status: http.StatusForbidden},
+			{err: dashboardarchive.ErrAlreadyArchived, status: http.StatusConflict},
+			{err: models.ErrDashboardNotFound, status: http.StatusNotFound},
+			{err: models.ErrDashboardCannotDeleteProvisionedDashboard, status: http.StatusBadRequest},
+			{err: models.ErrFolderContainsAlertRules, status: http.StatusBadRequest},
+		} {
+			archive.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/cpu/archive", nil, t)
+			assert.Equal(t, tc.status, response.Code, tc.err.Error())
+		}
+	})
+
+	t.Run("Returns the archived dashboards", func(t *testing.T) {
+		archive.ExpectedArchived = []*dashboardarchive.ArchivedDashboard{{ID: 1, UID: "cpu"}, {ID: 2, UID: "memory"}}
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/dashboards/archive?folderUid=team", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []dashboardarchive.ArchivedDashboard
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("Restores the archived dashboard", func(t *testing.T) {
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/archive/cpu/restore", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "cpu", archive.RestoreCommands[len(archive.RestoreCommands)-1].UID)
+	})
+
+	t.Run("Returns 400 when the folder of the dashboard is archived", func(t *testing.T) {
+		archive.ExpectedError = dashboardarchive.ErrFolderArchived
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/archive/cpu/restore", nil, t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Returns 404 for dashboards that aren't archived", func(t *testing.T) {
+		archive.ExpectedError = dashboardarchive.ErrArchiveNotFound
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/archive/cpu/restore", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Returns the trashed dashboards", func(t *testing.T) {
+		archive.ExpectedArchived = []*dashboardarchive.ArchivedDashboard{{ID: 3, UID: "disk", Reason: dashboardarchive.ReasonDeleted}}
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/dashboards/trash?limit=10", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []dashboardarchive.ArchivedDashboard
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Len(t, result, 1)
+		query := archive.SearchQueries[len(archive.SearchQueries)-1]
+		assert.True(t, query.Trashed)
+		assert.Equal(t, 10, query.Limit)
+	})
+
+	t.Run("Searches the archive without the trash", func(t *testing.T) {
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/dashboards/archive", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.False(t, archive.SearchQueries[len(archive.SearchQueries)-1].Trashed)
+	})
+
+	t.Run("Restores the trashed dashboard", func(t *testing.T) {
+		archive.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/trash/disk/restore", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		cmd := archive.RestoreCommands[len(archive.RestoreCommands)-1]
+		assert.Equal(t, "disk", cmd.UID)
+		assert.True(t, cmd.Trashed)
+	})
+
+	t.Run("Returns 404 for dashboards that aren't in the trash", func(t *testing.T) {
+		archive.ExpectedError = dashboardarchive.ErrArchiveNotFound
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/trash/cpu/restore", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Editors can't restore dashboards", func(t *testing.T) {
+		sc.initCtx.SignedInUser.OrgRole = models.ROLE_EDITOR
+		t.Cleanup(func() { sc.initCtx.SignedInUser.OrgRole = models.ROLE_ADMIN })
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/archive/cpu/restore", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminCopyDashboard copies a dashboard from an organization to another one.
+// POST /api/admin/dashboards/copy
+func (hs *HTTPServer) AdminCopyDashboard(c *models.ReqContext) response.Response {
+	cmd := dashboardcopy.CopyDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.User = c.SignedInUser
+
+	limitReached, err := hs.QuotaService.CheckQuotaReached(c.Req.Context(), "dashboard", &quota.ScopeParameters{OrgId: cmd.TargetOrgID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get quota", err)
+	}
+	if limitReached {
+		return response.Error(http.StatusForbidden, "Quota reached", nil)
+	}
+
+	result, err := hs.dashboardCopy.Copy(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboardcopy.ErrInvalidCopy):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, models.ErrOrgNotFound):
+			return response.Error(http.StatusNotFound, "Organization not found", err)
+		case errors.Is(err, models.ErrFolderNotFound):
+			return response.Error(http.StatusBadRequest, "Folder not found", err)
+		}
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), hs.pluginStore, err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy/dashboardcopytest"
+)
+
+func TestAPIEndpoint_AdminCopyDashboard(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	copies := dashboardcopytest.NewDashboardCopyServiceFake()
+	sc.hs.dashboardCopy = copies
+	body := `{"sourceOrgId":1,"uid":"service","targetOrgId":2,"copyFolder":true,"copyPermissions":true}`
+
+	t.Run("Requires the Grafana Admin role", func(t *testing.T) {
+		setInitCtxSignedInOrgAdmin(sc.initCtx)
+		response := callAPI(sc.server, http.MethodPost, "/api/admin/dashboards/copy", strings.NewReader(body), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.Nil(t, copies.CopyCommand)
+	})
+
+	setInitCtxSignedInUser(sc.initCtx, models.SignedInUser{UserId: testUserID, OrgId: 1, OrgRole: models.ROLE_VIEWER, Login: testUserLogin, IsGrafanaAdmin: true})
+
+	t.Run("Copies the dashboard as the signed in user", func(t *testing.T) {
+		copies.ExpectedResult = &dashboardcopy.CopyDashboardResult{
+			UID:                 "service",
+			URL:                 "/d/service/service",
+			DataSources:         []dashboardcopy.DataSourceMapping{{Name: "Prometheus", SourceUID: "prom-1", TargetUID: "prom-2", Type: "prometheus"}},
+			UnmappedDataSources: []string{"Loki"},
+		}
+		copies.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/admin/dashboards/copy", strings.NewReader(body), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result dashboardcopy.CopyDashboardResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, "prom-2", result.DataSources[0].TargetUID)
+		assert.Equal(t, []string{"Loki"}, result.UnmappedDataSources)
+
+		cmd := copies.CopyCommand
+		assert.Equal(t, int64(1), cmd.SourceOrgID)
+		assert.Equal(t, int64(2), cmd.TargetOrgID)
+		assert.Equal(t, "service", cmd.UID)
+		assert.True(t, cmd.CopyFolder)
+		assert.True(t, cmd.CopyPermissions)
+		assert.Equal(t, testUserID, cmd.User.UserId)
+	})
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{err: fmt.Errorf("%w: uid is required", dashboardcopy.ErrInvalidCopy), code: http.StatusBadRequest},
+		{err: models.ErrOrgNotFound, code: http.StatusNotFound},
+		{err: models.ErrDashboardNotFound, code: http.StatusNotFound},
+		{err: models.ErrFolderNotFound, code: http.StatusBadRequest},
+		{err: models.ErrDashboardWithSameUIDExists, code: http.StatusBadRequest},
+		{err: models.ErrDashboardWithSameNameInFolderExists, code: http.StatusPreconditionFailed},
+	} {
+		t.Run(fmt.Sprintf("Returns %d for %q", tc.code, tc.err), func(t *testing.T) {
+			copies.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPost, "/api/admin/dashboards/copy", strings.NewReader(body), t)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+}
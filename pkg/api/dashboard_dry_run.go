@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// dashboardSaveError is a check that fails when saving a dashboard.
+type dashboardSaveError struct {
+	// Check is the kind of check failing: schema, folder, managed, quota,
+	// provisioned, permissions, conflict, alerts, plugin or dashboard.
+	Check string `json:"check"`
+	// StatusCode is the status code of the response saving the dashboard.
+	StatusCode int    `json:"statusCode"`
+	Status     string `json:"status,omitempty"`
+	// Field is the path of the invalid value for the schema check.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// dryRunPostDashboard runs the checks of postDashboard without saving the
+// dashboard. All the failing checks are reported, not only the first one, with
+// the status code of the save.
+func (hs *HTTPServer) dryRunPostDashboard(c *models.ReqContext, cmd models.SaveDashboardCommand, validation *dashboardschema.ValidationResult) response.Response {
+	ctx := c.Req.Context()
+	failed := make([]dashboardSaveError, 0)
+	for _, e := range validation.Errors {
+		failed = append(failed, dashboardSaveError{
+			Check:      "schema",
+			StatusCode: http.StatusBadRequest,
+			Status:     "invalid-dashboard",
+			Field:      e.Field,
+			Message:    e.Message,
+		})
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	if cmd.FolderUid != "" {
+		folder, err := hs.folderService.GetFolderByUID(ctx, c.SignedInUser, c.OrgId, cmd.FolderUid)
+		switch {
+		case errors.Is(err, models.ErrFolderNotFound):
+			failed = append(failed, dashboardSaveError{Check: "folder", StatusCode: http.StatusBadRequest, Message: "Folder not found"})
+		case err != nil:
+			return response.Error(500, "Error while checking folder ID", err)
+		default:
+			cmd.FolderId = folder.Id
+		}
+	}
+
+	dash := cmd.GetDashboardModel()
+	managed, err := hs.getManagedDashboard(c, dash.Id, dash.Uid)
+	if err != nil {
+		return response.Error(500, "Error while checking if dashboard is managed as code", err)
+	}
+	if managed != nil && !managed.IsOwner(c.SignedInUser) {
+		failed = append(failed, dashboardSaveError{Check: "managed", StatusCode: http.StatusForbidden, Message: managedDashboardMessage(managed)})
+	}
+
+	if dash.Id == 0 {
+		limitReached, err := hs.QuotaService.QuotaReached(c, "dashboard")
+		if err != nil {
+			return response.Error(500, "failed to get quota", err)
+		}
+		if limitReached {
+			failed = append(failed, dashboardSaveError{Check: "quota", StatusCode: http.StatusForbidden, Message: "Quota reached"})
+		}
+	}
+
+	allowUiUpdate, _, err := hs.allowDashboardUIUpdate(dash)
+	if err != nil {
+		return response.Error(500, "Error while checking if dashboard is provisioned", err)
+	}
+	if !allowUiUpdate {
+		failed = append(failed, dashboardSaveError{
+			Check:      "provisioned",
+			StatusCode: models.ErrDashboardCannotSaveProvisionedDashboard.StatusCode,
+			Message:    models.ErrDashboardCannotSaveProvisionedDashboard.Error(),
+		})
+	}
+
+	// the provisioned check is reported above, the service stops at the first
+	// failing check otherwise
+	_, err = hs.dashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled()), &dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   cmd.Message,
+		OrgId:     c.OrgId,
+		User:      c.SignedInUser,
+		Overwrite: cmd.Overwrite,
+		DryRun:    true,
+	}, true)
+	if err != nil {
+		saveErr, ok := hs.toDashboardSaveError(c, err)
+		if !ok {
+			return response.Error(http.StatusInternalServerError, "Failed to check dashboard", err)
+		}
+		failed = append(failed, saveErr)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"dryRun":   true,
+		"valid":    len(failed) == 0,
+		"errors":   failed,
+		"warnings": validation.Warnings,
+	})
+}
+
+// toDashboardSaveError returns the failing check of an error of the dashboard
+// service, the same way apierrors.ToDashboardErrorResponse returns its response.
+// It returns false for internal errors.
+func (hs *HTTPServer) toDashboardSaveError(c *models.ReqContext, err error) (dashboardSaveError, bool) {
+	var dashboardErr models.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		if dashboardErr.StatusCode >= http.StatusInternalServerError {
+			return dashboardSaveError{}, false
+		}
+		check := "dashboard"
+		switch dashboardErr {
+		case models.ErrDashboardUpdateAccessDenied:
+			check = "permissions"
+		case models.ErrDashboardWithSameUIDExists,
+			models.ErrDashboardWithSameNameInFolderExists,
+			models.ErrDashboardVersionMismatch,
+			models.ErrDashboardsWithSameSlugExists,
+			models.ErrDashboardWithSameNameAsFolder,
+			models.ErrDashboardFolderWithSameNameAsDashboard,
+			models.ErrDashboardFolderNameExists:
+			check = "conflict"
+		}
+		return dashboardSaveError{Check: check, StatusCode: dashboardErr.StatusCode, Status: dashboardErr.Status, Message: dashboardErr.Error()}, true
+	}
+
+	if errors.Is(err, models.ErrFolderNotFound) {
+		return dashboardSaveError{Check: "folder", StatusCode: http.StatusBadRequest, Message: err.Error()}, true
+	}
+
+	var validationErr alerting.ValidationError
+	if errors.As(err, &validationErr) {
+		return dashboardSaveError{Check: "alerts", StatusCode: http.StatusUnprocessableEntity, Message: validationErr.Error()}, true
+	}
+
+	var pluginErr models.UpdatePluginDashboardError
+	if errors.As(err, &pluginErr) {
+		message := fmt.Sprintf("The dashboard belongs to plugin %s.", pluginErr.PluginId)
+		if plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), pluginErr.PluginId); exists {
+			message = fmt.Sprintf("The dashboard belongs to plugin %s.", plugin.Name)
+		}
+		return dashboardSaveError{Check: "plugin", StatusCode: http.StatusPreconditionFailed, Status: "plugin-dashboard", Message: message}, true
+	}
+
+	return dashboardSaveError{}, false
+}
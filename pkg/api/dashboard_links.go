@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+)
+
+// GetDashboardLinkReport returns the broken links found by the last check of
+// the dashboards of the organization.
+// GET /api/dashboards/link-check
+func (hs *HTTPServer) GetDashboardLinkReport(c *models.ReqContext) response.Response {
+	report, err := hs.dashboardLinks.GetReport(c.Req.Context(), &dashboardlinks.GetReportQuery{OrgID: c.OrgId})
+	if err != nil {
+		if errors.Is(err, dashboardlinks.ErrReportNotFound) {
+			return response.Error(http.StatusNotFound, "The dashboards haven't been checked yet", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard link report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// CheckDashboardLinks checks the dashboards of the organization for broken
+// links right away and returns the report.
+// POST /api/dashboards/link-check
+func (hs *HTTPServer) CheckDashboardLinks(c *models.ReqContext) response.Response {
+	report, err := hs.dashboardLinks.Check(c.Req.Context(), &dashboardlinks.CheckCommand{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard links", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
@@ -12,15 +12,13 @@ import (
 	"github.com/grafana/grafana/pkg/web"
 )
 
-// gets public dashboard
+// gets public dashboard authenticated by its access token
+// GET /api/public/dashboards/:accessToken
 func (hs *HTTPServer) GetPublicDashboard(c *models.ReqContext) response.Response {
-	publicDashboardUid := web.Params(c.Req)[":uid"]
-
-	dash, err := hs.dashboardService.GetPublicDashboard(c.Req.Context(), publicDashboardUid)
-	if err != nil {
-		return handleDashboardErr(http.StatusInternalServerError, "Failed to get public dashboard", err)
-	}
+	dash := c.PublicDashboardAccess.Dashboard
+	dashboards.RemoveRestrictedPanels(dash.Data)
 
+	// the frontend requests the panel data with the access token set as the public dashboard uid
 	meta := dtos.DashboardMeta{
 		Slug:               dash.Slug,
 		Type:               models.DashTypeDB,
@@ -35,7 +33,7 @@ func (hs *HTTPServer) GetPublicDashboard(c *models.ReqContext) response.Response
 		IsFolder:           false,
 		FolderId:           dash.FolderId,
 		IsPublic:           dash.IsPublic,
-		PublicDashboardUid: publicDashboardUid,
+		PublicDashboardUid: c.PublicDashboardAccess.PublicDashboard.AccessToken,
 	}
 
 	dto := dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}
@@ -73,18 +71,34 @@ func (hs *HTTPServer) SavePublicDashboardConfig(c *models.ReqContext) response.R
 	return response.JSON(http.StatusOK, pdc)
 }
 
+// replaces the access token of the public dashboard configuration of a dashboard
+func (hs *HTTPServer) RotatePublicDashboardAccessToken(c *models.ReqContext) response.Response {
+	pdc, err := hs.dashboardService.RotatePublicDashboardAccessToken(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return handleDashboardErr(http.StatusInternalServerError, "Failed to rotate public dashboard access token", err)
+	}
+
+	return response.JSON(http.StatusOK, pdc)
+}
+
 // QueryPublicDashboard returns all results for a given panel on a public dashboard
-// POST /api/public/dashboard/:uid/panels/:panelId/query
+// POST /api/public/dashboards/:accessToken/panels/:panelId/query
 func (hs *HTTPServer) QueryPublicDashboard(c *models.ReqContext) response.Response {
 	panelId, err := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "invalid panel ID", err)
 	}
 
+	query := &dashboards.PublicDashboardQueryDTO{}
+	if err := web.Bind(c.Req, query); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
 	reqDTO, err := hs.dashboardService.BuildPublicDashboardMetricRequest(
 		c.Req.Context(),
-		web.Params(c.Req)[":uid"],
+		c.PublicDashboardAccess,
 		panelId,
+		query,
 	)
 	if err != nil {
 		return handleDashboardErr(http.StatusInternalServerError, "Failed to get queries for public dashboard", err)
@@ -32,7 +32,7 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 		sc := setupHTTPServerWithMockDb(t, false, false, featuremgmt.WithFeatures())
 		dashSvc := dashboards.NewFakeDashboardService(t)
 		dashSvc.On("GetPublicDashboard", mock.Anything, mock.AnythingOfType("string")).
-			Return(&models.Dashboard{}, nil).Maybe()
+			Return(&models.PublicDashboard{}, &models.Dashboard{}, nil).Maybe()
 		sc.hs.dashboardService = dashSvc
 
 		setInitCtxSignedInViewer(sc.initCtx)
@@ -55,22 +55,26 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 	})
 
 	dashboardUid := "dashboard-abcd1234"
-	pubdashUid := "pubdash-abcd1234"
+	accessToken := "pubdash-access-token"
 
 	testCases := []struct {
 		name                  string
-		uid                   string
+		accessToken           string
 		expectedHttpResponse  int
 		publicDashboardResult *models.Dashboard
 		publicDashboardErr    error
 	}{
 		{
 			name:                 "It gets a public dashboard",
-			uid:                  pubdashUid,
+			accessToken:          accessToken,
 			expectedHttpResponse: http.StatusOK,
 			publicDashboardResult: &models.Dashboard{
 				Data: simplejson.NewFromAny(map[string]interface{}{
 					"Uid": dashboardUid,
+					"panels": []interface{}{
+						map[string]interface{}{"id": 1},
+						map[string]interface{}{"id": 2, "visibility": map[string]interface{}{"roles": []interface{}{"Viewer"}}},
+					},
 				}),
 				IsPublic: true,
 			},
@@ -78,35 +82,55 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 		},
 		{
 			name:                  "It should return 404 if isPublicDashboard is false",
-			uid:                   pubdashUid,
+			accessToken:           accessToken,
+			expectedHttpResponse:  http.StatusNotFound,
+			publicDashboardResult: nil,
+			publicDashboardErr:    models.ErrPublicDashboardNotFound,
+		},
+		{
+			name:                  "It should return 404 if the access token is unknown",
+			accessToken:           "unknown",
 			expectedHttpResponse:  http.StatusNotFound,
 			publicDashboardResult: nil,
 			publicDashboardErr:    models.ErrPublicDashboardNotFound,
 		},
+		{
+			name:                  "It should return 500 if the public dashboard can't be retrieved",
+			accessToken:           accessToken,
+			expectedHttpResponse:  http.StatusInternalServerError,
+			publicDashboardResult: nil,
+			publicDashboardErr:    errors.New("database broken"),
+		},
 	}
 
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
-			sc := setupHTTPServerWithMockDb(t, false, false, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards))
 			dashSvc := dashboards.NewFakeDashboardService(t)
-			dashSvc.On("GetPublicDashboard", mock.Anything, mock.AnythingOfType("string")).
-				Return(test.publicDashboardResult, test.publicDashboardErr)
-			sc.hs.dashboardService = dashSvc
+			var pd *models.PublicDashboard
+			if test.publicDashboardResult != nil {
+				pd = &models.PublicDashboard{AccessToken: test.accessToken, DashboardUid: dashboardUid}
+			}
+			dashSvc.On("GetPublicDashboard", mock.Anything, test.accessToken).
+				Return(pd, test.publicDashboardResult, test.publicDashboardErr)
 
-			setInitCtxSignedInViewer(sc.initCtx)
-			response := callAPI(
-				sc.server,
-				http.MethodGet,
-				fmt.Sprintf("/api/public/dashboards/%v", test.uid),
-				nil,
-				t,
-			)
+			// the access token middleware is set up with the dashboard service when registering the routes
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.Features = featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+				hs.dashboardService = dashSvc
+			})
 
-			assert.Equal(t, test.expectedHttpResponse, response.Code)
+			req := server.NewGetRequest(fmt.Sprintf("/api/public/dashboards/%v", test.accessToken))
+			resp, err := server.Send(req)
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedHttpResponse, resp.StatusCode)
 
 			if test.publicDashboardErr == nil {
 				var dashResp dtos.DashboardFullWithMeta
-				err := json.Unmarshal(response.Body.Bytes(), &dashResp)
+				err := json.Unmarshal(body, &dashResp)
 				require.NoError(t, err)
 
 				assert.Equal(t, dashboardUid, dashResp.Dashboard.Get("Uid").MustString())
@@ -114,13 +138,15 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 				assert.Equal(t, false, dashResp.Meta.CanEdit)
 				assert.Equal(t, false, dashResp.Meta.CanDelete)
 				assert.Equal(t, false, dashResp.Meta.CanSave)
-			} else {
+				assert.Equal(t, test.accessToken, dashResp.Meta.PublicDashboardUid)
+				assert.Equal(t, []interface{}{map[string]interface{}{"id": json.Number("1")}}, dashResp.Dashboard.Get("panels").MustArray())
+			} else if test.expectedHttpResponse == http.StatusNotFound {
 				var errResp struct {
-					Error string `json:"error"`
+					Message string `json:"message"`
 				}
-				err := json.Unmarshal(response.Body.Bytes(), &errResp)
+				err := json.Unmarshal(body, &errResp)
 				require.NoError(t, err)
-				assert.Equal(t, test.publicDashboardErr.Error(), errResp.Error)
+				assert.Equal(t, models.ErrPublicDashboardNotFound.Error(), errResp.Message)
 			}
 		})
 	}
@@ -247,7 +273,58 @@ func TestApiSavePublicDashboardConfig(t *testing.T) {
 	}
 }
 
-// `/public/dashboards/:uid/query`` endpoint test
+func TestApiRotatePublicDashboardAccessToken(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		expectedHttpResponse int
+		rotateError          error
+	}{
+		{
+			name:                 "returns 200 with the new access token",
+			expectedHttpResponse: http.StatusOK,
+		},
+		{
+			name:                 "returns 404 when the dashboard isn't public",
+			expectedHttpResponse: http.StatusNotFound,
+			rotateError:          models.ErrPublicDashboardNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			sc := setupHTTPServerWithMockDb(t, false, false, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards))
+
+			pdc := &models.PublicDashboardConfig{IsPublic: true, PublicDashboard: models.PublicDashboard{AccessToken: "rotated"}}
+			if test.rotateError != nil {
+				pdc = nil
+			}
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("RotatePublicDashboardAccessToken", mock.Anything, mock.AnythingOfType("int64"), "1").
+				Return(pdc, test.rotateError)
+			sc.hs.dashboardService = dashSvc
+
+			setInitCtxSignedInViewer(sc.initCtx)
+			response := callAPI(
+				sc.server,
+				http.MethodPost,
+				"/api/dashboards/uid/1/public-config/rotate-token",
+				nil,
+				t,
+			)
+
+			assert.Equal(t, test.expectedHttpResponse, response.Code)
+
+			if response.Code == http.StatusOK {
+				var pdcResp models.PublicDashboardConfig
+				err := json.Unmarshal(response.Body.Bytes(), &pdcResp)
+				require.NoError(t, err)
+				assert.Equal(t, "rotated", pdcResp.PublicDashboard.AccessToken)
+			}
+		})
+	}
+}
+
+// `/public/dashboards/:accessToken/panels/:panelId/query` endpoint test
 func TestAPIQueryPublicDashboard(t *testing.T) {
 	queryReturnsError := false
 
@@ -287,8 +364,17 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		&fakeOAuthTokenService{},
 	)
 
+	access := &models.PublicDashboardAccess{
+		PublicDashboard: &models.PublicDashboard{AccessToken: "abc123"},
+		Dashboard:       &models.Dashboard{IsPublic: true},
+	}
+
 	setup := func(enabled bool) (*webtest.Server, *dashboards.FakeDashboardService) {
 		fakeDashboardService := &dashboards.FakeDashboardService{}
+		fakeDashboardService.On("GetPublicDashboard", mock.Anything, "abc123").
+			Return(access.PublicDashboard, access.Dashboard, nil).Maybe()
+		fakeDashboardService.On("GetPublicDashboard", mock.Anything, mock.AnythingOfType("string")).
+			Return(nil, nil, models.ErrPublicDashboardNotFound).Maybe()
 
 		return SetupAPITestServer(t, func(hs *HTTPServer) {
 			hs.queryDataService = qds
@@ -310,6 +396,39 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		require.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
 
+	t.Run("Status code is 404 when the access token is unknown", func(t *testing.T) {
+		server, _ := setup(true)
+
+		req := server.NewPostRequest(
+			"/api/public/dashboards/unknown/panels/2/query",
+			strings.NewReader("{}"),
+		)
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Passes the requested time range and variables", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+
+		fakeDashboardService.On(
+			"BuildPublicDashboardMetricRequest",
+			mock.Anything,
+			access,
+			int64(2),
+			&dashboards.PublicDashboardQueryDTO{From: "1000", To: "2000", Variables: map[string][]string{"job": {"api"}}},
+		).Return(dtos.MetricRequest{}, models.ErrPublicDashboardVariableNotAllowed)
+		req := server.NewPostRequest(
+			"/api/public/dashboards/abc123/panels/2/query",
+			strings.NewReader(`{"from": "1000", "to": "2000", "variables": {"job": ["api"]}}`),
+		)
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("Status code is 400 when the panel ID is invalid", func(t *testing.T) {
 		server, _ := setup(true)
 
@@ -329,8 +448,9 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		fakeDashboardService.On(
 			"BuildPublicDashboardMetricRequest",
 			mock.Anything,
-			"abc123",
+			access,
 			int64(2),
+			mock.AnythingOfType("*dashboards.PublicDashboardQueryDTO"),
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
@@ -388,8 +508,9 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		fakeDashboardService.On(
 			"BuildPublicDashboardMetricRequest",
 			mock.Anything,
-			"abc123",
+			access,
 			int64(2),
+			mock.AnythingOfType("*dashboards.PublicDashboardQueryDTO"),
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
@@ -425,8 +546,9 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		fakeDashboardService.On(
 			"BuildPublicDashboardMetricRequest",
 			mock.Anything,
-			"abc123",
+			access,
 			int64(2),
+			mock.AnythingOfType("*dashboards.PublicDashboardQueryDTO"),
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
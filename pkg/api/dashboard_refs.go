@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardrefs"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RepairDashboardRefs looks for alert rules, library panel connections and
+// annotations referencing dashboards or folders of the organization that no
+// longer exist, and repairs them unless it's a dry run.
+// POST /api/dashboards/repair-refs
+func (hs *HTTPServer) RepairDashboardRefs(c *models.ReqContext) response.Response {
+	cmd := dashboardrefs.ScanCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+
+	result, err := hs.dashboardRefs.Scan(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to repair dashboard references", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// MigrateDashboardSchema upgrades a dashboard to the latest schema version.
+// POST /api/dashboards/uid/:uid/migrate-schema
+func (hs *HTTPServer) MigrateDashboardSchema(c *models.ReqContext) response.Response {
+	cmd := dashboardschema.MigrateDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+	g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := g.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd.OrgID = c.OrgId
+	cmd.UID = dash.Uid
+	cmd.User = c.SignedInUser
+	result, err := hs.dashboardSchema.Migrate(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboardschema.ErrSchemaVersionNotSupported), errors.Is(err, dashboardschema.ErrDashboardProvisioned):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, models.ErrDashboardNotFound):
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), hs.pluginStore, err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// MigrateAllDashboardSchemas upgrades all the dashboards of the organization to the latest schema version.
+// POST /api/dashboards/migrate-schema
+func (hs *HTTPServer) MigrateAllDashboardSchemas(c *models.ReqContext) response.Response {
+	cmd := dashboardschema.MigrateAllDashboardsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.User = c.SignedInUser
+
+	result, err := hs.dashboardSchema.MigrateAll(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to migrate dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetDashboardSchemaReport returns the dashboards of the organization that are not on the latest schema version.
+// GET /api/dashboards/schema-report
+func (hs *HTTPServer) GetDashboardSchemaReport(c *models.ReqContext) response.Response {
+	result, err := hs.dashboardSchema.Report(c.Req.Context(), &dashboardschema.ReportQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard schema report", err)
+	}
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"latestVersion":       dashboardschema.LatestVersion,
+		"minSupportedVersion": dashboardschema.MinSupportedVersion,
+		"dashboards":          result,
+	})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/dashboards/templates
+func (hs *HTTPServer) GetDashboardTemplates(c *models.ReqContext) response.Response {
+	result, err := hs.dashboardTemplates.List(c.Req.Context(), &dashboardtemplates.ListDashboardTemplatesQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard templates", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// GET /api/dashboards/templates/:uid
+func (hs *HTTPServer) GetDashboardTemplate(c *models.ReqContext) response.Response {
+	result, err := hs.dashboardTemplates.Get(c.Req.Context(), &dashboardtemplates.GetDashboardTemplateQuery{
+		OrgID: c.OrgId,
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return dashboardTemplateError(err, "Failed to get dashboard template")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/dashboards/templates/:uid
+func (hs *HTTPServer) UpdateDashboardTemplate(c *models.ReqContext) response.Response {
+	form := dtos.UpdateDashboardTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := dashboardtemplates.SaveDashboardTemplateCommand{
+		OrgID: c.OrgId,
+		Template: dashboardtemplates.DashboardTemplate{
+			UID:         web.Params(c.Req)[":uid"],
+			Name:        form.Name,
+			Description: form.Description,
+			Inputs:      form.Inputs,
+			Dashboard:   form.Dashboard,
+		},
+	}
+	if err := hs.dashboardTemplates.Save(c.Req.Context(), &cmd); err != nil {
+		return dashboardTemplateError(err, "Failed to save dashboard template")
+	}
+
+	return response.JSON(http.StatusOK, cmd.Template)
+}
+
+// DELETE /api/dashboards/templates/:uid
+func (hs *HTTPServer) DeleteDashboardTemplate(c *models.ReqContext) response.Response {
+	err := hs.dashboardTemplates.Delete(c.Req.Context(), &dashboardtemplates.DeleteDashboardTemplateCommand{
+		OrgID: c.OrgId,
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return dashboardTemplateError(err, "Failed to delete dashboard template")
+	}
+
+	return response.Success("Dashboard template deleted")
+}
+
+// POST /api/dashboards/templates/:uid/instantiate
+func (hs *HTTPServer) InstantiateDashboardTemplate(c *models.ReqContext) response.Response {
+	form := dtos.InstantiateDashboardTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	limitReached, err := hs.QuotaService.QuotaReached(c, "dashboard")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get quota", err)
+	}
+	if limitReached {
+		return response.Error(http.StatusForbidden, "Quota reached", nil)
+	}
+
+	result, err := hs.dashboardTemplates.Instantiate(c.Req.Context(), &dashboardtemplates.InstantiateCommand{
+		OrgID:     c.OrgId,
+		UID:       web.Params(c.Req)[":uid"],
+		User:      c.SignedInUser,
+		Inputs:    form.Inputs,
+		FolderUID: form.FolderUid,
+		Title:     form.Title,
+		Overwrite: form.Overwrite,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboardtemplates.ErrDashboardTemplateNotFound),
+			errors.Is(err, dashboardtemplates.ErrMissingInput),
+			errors.Is(err, dashboardtemplates.ErrInvalidInput):
+			return dashboardTemplateError(err, "Failed to create dashboard from template")
+		case errors.Is(err, models.ErrFolderAccessDenied):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), hs.pluginStore, err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func dashboardTemplateError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, dashboardtemplates.ErrDashboardTemplateNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, dashboardtemplates.ErrInvalidDashboardTemplate),
+		errors.Is(err, dashboardtemplates.ErrMissingInput),
+		errors.Is(err, dashboardtemplates.ErrInvalidInput):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates/dashboardtemplatestest"
+)
+
+func TestAPIEndpoint_DashboardTemplates(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	setInitCtxSignedInViewer(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{
+		{Action: dashboardtemplates.ActionTemplatesRead, Scope: dashboardtemplates.ScopeTemplatesAll},
+		{Action: dashboardtemplates.ActionTemplatesWrite, Scope: dashboardtemplates.ScopeTemplatesAll},
+		{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll},
+	}, sc.initCtx.OrgId)
+
+	templates := dashboardtemplatestest.NewDashboardTemplatesServiceFake()
+	sc.hs.dashboardTemplates = templates
+
+	t.Run("Lists the templates", func(t *testing.T) {
+		templates.ExpectedTemplates = []*dashboardtemplates.DashboardTemplate{{UID: "service", Name: "Service overview", Dashboard: simplejson.New()}}
+		templates.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/dashboards/templates", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []dashboardtemplates.DashboardTemplate
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		require.Len(t, result, 1)
+		assert.Equal(t, "service", result[0].UID)
+	})
+
+	t.Run("Saves the template with the uid of the path", func(t *testing.T) {
+		templates.ExpectedError = nil
+		body := `{"name":"Service overview","inputs":[{"name":"SERVICE","type":"constant"}],"dashboard":{"title":"${SERVICE}"}}`
+		response := callAPI(sc.server, http.MethodPut, "/api/dashboards/templates/service", strings.NewReader(body), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result dashboardtemplates.DashboardTemplate
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, "service", result.UID)
+		assert.Equal(t, "${SERVICE}", result.Dashboard.Get("title").MustString())
+	})
+
+	t.Run("Creates a dashboard from the template", func(t *testing.T) {
+		templates.ExpectedInstantiation = &dashboardtemplates.InstantiateResult{UID: "new-uid", Title: "checkout", URL: "/d/new-uid/checkout"}
+		templates.ExpectedError = nil
+		body := `{"inputs":{"SERVICE":"checkout"},"folderUid":"team-folder"}`
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/templates/service/instantiate", strings.NewReader(body), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result dashboardtemplates.InstantiateResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, "new-uid", result.UID)
+		assert.Equal(t, "service", templates.InstantiateCommand.UID)
+		assert.Equal(t, "team-folder", templates.InstantiateCommand.FolderUID)
+		assert.Equal(t, map[string]string{"SERVICE": "checkout"}, templates.InstantiateCommand.Inputs)
+	})
+
+	t.Run("Requires the permission to delete templates to delete a template", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodDelete, "/api/dashboards/templates/service", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{err: dashboardtemplates.ErrDashboardTemplateNotFound, code: http.StatusNotFound},
+		{err: fmt.Errorf("%w: %q is required", dashboardtemplates.ErrMissingInput, "SERVICE"), code: http.StatusBadRequest},
+		{err: fmt.Errorf("%w: data source %q of %q not found", dashboardtemplates.ErrInvalidInput, "prom", "DS"), code: http.StatusBadRequest},
+		{err: models.ErrDashboardWithSameNameInFolderExists, code: http.StatusPreconditionFailed},
+		{err: models.ErrFolderAccessDenied, code: http.StatusForbidden},
+	} {
+		t.Run(fmt.Sprintf("Returns %d for %q", tc.code, tc.err), func(t *testing.T) {
+			templates.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPost, "/api/dashboards/templates/service/instantiate", strings.NewReader(`{}`), t)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/coremodel/dashboard"
 	"github.com/grafana/grafana/pkg/cuectx"
@@ -24,6 +25,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive/dashboardarchivetest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
@@ -79,7 +82,7 @@ func TestGetHomeDashboard(t *testing.T) {
 
 			homeDashJSON, err := ioutil.ReadFile(tc.expectedDashboardPath)
 			require.NoError(t, err, "must be able to read expected dashboard file")
-			hs.Cfg.DefaultHomeDashboardPath = tc.defaultSetting
+			prefService.ExpectedInstanceDefaults = pref.InstanceDefaults{HomeDashboardPath: tc.defaultSetting}
 			bytes, err := simplejson.NewJson(homeDashJSON)
 			require.NoError(t, err, "must be able to encode file as JSON")
 
@@ -404,6 +407,30 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				assert.Equal(t, 200, sc.resp.Code)
 			}, mockSQLStore)
 
+			loggedInUserScenarioWithRole(t, "When calling DELETE on with the trash enabled", "DELETE", "/api/dashboards/uid/abcdefghi", "/api/dashboards/uid/:uid", role, func(sc *scenarioContext) {
+				setUpInner()
+				cfg.DashboardTrash.RetentionDays = 30
+				archive := dashboardarchivetest.NewDashboardArchiveServiceFake()
+				hs.dashboardArchive = archive
+				t.Cleanup(func() {
+					cfg.DashboardTrash.RetentionDays = 0
+					hs.dashboardArchive = nil
+				})
+				dashboardService := dashboards.NewFakeDashboardService(t)
+				dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+					q := args.Get(1).(*models.GetDashboardQuery)
+					q.Result = models.NewDashboard("test")
+					q.Result.Uid = "abcdefghi"
+				}).Return(nil)
+
+				hs.callDeleteDashboardByUID(t, sc, dashboardService)
+
+				assert.Equal(t, 200, sc.resp.Code)
+				require.Len(t, archive.ArchiveCommands, 1)
+				assert.Equal(t, "abcdefghi", archive.ArchiveCommands[0].UID)
+				assert.Equal(t, dashboardarchive.ReasonDeleted, archive.ArchiveCommands[0].Reason)
+			}, mockSQLStore)
+
 			loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/id/2/versions/1", "/api/dashboards/id/:dashboardId/versions/:id", role, func(sc *scenarioContext) {
 				setUpInner()
 				sc.sqlStore = mockSQLStore
@@ -687,7 +714,8 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				{SaveError: models.ErrDashboardWithSameNameInFolderExists, ExpectedStatusCode: 412},
 				{SaveError: models.ErrDashboardVersionMismatch, ExpectedStatusCode: 412},
 				{SaveError: models.ErrDashboardTitleEmpty, ExpectedStatusCode: 400},
-				{SaveError: models.ErrDashboardFolderCannotHaveParent, ExpectedStatusCode: 400},
+				{SaveError: models.ErrDashboardFolderCyclicParent, ExpectedStatusCode: 400},
+				{SaveError: models.ErrDashboardFolderMaxDepthExceeded, ExpectedStatusCode: 400},
 				{SaveError: alerting.ValidationError{Reason: "Mu"}, ExpectedStatusCode: 422},
 				{SaveError: models.ErrDashboardFailedGenerateUniqueUid, ExpectedStatusCode: 500},
 				{SaveError: models.ErrDashboardTypeMismatch, ExpectedStatusCode: 400},
@@ -719,6 +747,105 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					})
 			}
 		})
+
+		t.Run("Given a request for creating a dashboard not matching the schema", func(t *testing.T) {
+			cmd := models.SaveDashboardCommand{
+				OrgId:  1,
+				UserId: 5,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":  "Dash",
+					"tags":   "tag",
+					"panels": []interface{}{map[string]interface{}{"id": 1, "type": "graph"}},
+				}),
+			}
+
+			postDashboardScenario(t, "When calling POST on", "/api/dashboards", "/api/dashboards", cmd, dashboards.NewFakeDashboardService(t), nil, func(sc *scenarioContext) {
+				callPostDashboard(sc)
+				assert.Equal(t, 400, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.Equal(t, "invalid-dashboard", result.Get("status").MustString())
+				assert.Equal(t, "tags", result.Get("errors").GetIndex(0).Get("field").MustString())
+				assert.Equal(t, "panels[0].type", result.Get("warnings").GetIndex(0).Get("field").MustString())
+			})
+
+			postDashboardScenario(t, "When validating it with POST on", "/api/dashboards", "/api/dashboards", cmd, dashboards.NewFakeDashboardService(t), nil, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{"validate": "true"}).exec()
+				assert.Equal(t, 200, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.False(t, result.Get("valid").MustBool(true))
+				assert.Len(t, result.Get("errors").MustArray(), 1)
+				assert.Len(t, result.Get("warnings").MustArray(), 1)
+			})
+		})
+
+		t.Run("Given a request for creating a dashboard with deprecated options", func(t *testing.T) {
+			cmd := models.SaveDashboardCommand{
+				OrgId:  1,
+				UserId: 5,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":        "Dash",
+					"hideControls": true,
+				}),
+			}
+
+			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+				Return(&models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 1}, nil)
+
+			postDashboardScenario(t, "When calling POST on", "/api/dashboards", "/api/dashboards", cmd, dashboardService, nil, func(sc *scenarioContext) {
+				callPostDashboardShouldReturnSuccess(sc)
+
+				result := sc.ToJSON()
+				assert.Equal(t, "success", result.Get("status").MustString())
+				assert.Equal(t, "hideControls", result.Get("warnings").GetIndex(0).Get("field").MustString())
+			})
+		})
+
+		t.Run("Given a dry run request for saving a dashboard", func(t *testing.T) {
+			cmd := models.SaveDashboardCommand{
+				OrgId:  1,
+				UserId: 5,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title": "Dash",
+					"tags":  "tag",
+				}),
+				DryRun: true,
+			}
+			isDryRun := mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool { return dto.DryRun })
+
+			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("SaveDashboard", mock.Anything, isDryRun, true).Return(nil, models.ErrDashboardUpdateAccessDenied).Once()
+
+			postDashboardScenario(t, "When failing with POST on", "/api/dashboards", "/api/dashboards", cmd, dashboardService, nil, func(sc *scenarioContext) {
+				callPostDashboard(sc)
+				assert.Equal(t, 200, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.True(t, result.Get("dryRun").MustBool())
+				assert.False(t, result.Get("valid").MustBool(true))
+				errs := result.Get("errors")
+				require.Len(t, errs.MustArray(), 2)
+				assert.Equal(t, "schema", errs.GetIndex(0).Get("check").MustString())
+				assert.Equal(t, "tags", errs.GetIndex(0).Get("field").MustString())
+				assert.Equal(t, "permissions", errs.GetIndex(1).Get("check").MustString())
+				assert.Equal(t, 403, errs.GetIndex(1).Get("statusCode").MustInt())
+			})
+
+			cmd.Dashboard.Del("tags")
+			dashboardService = dashboards.NewFakeDashboardService(t)
+			dashboardService.On("SaveDashboard", mock.Anything, isDryRun, true).Return(&models.Dashboard{Title: "Dash"}, nil).Once()
+
+			postDashboardScenario(t, "When passing with POST on", "/api/dashboards", "/api/dashboards", cmd, dashboardService, nil, func(sc *scenarioContext) {
+				callPostDashboard(sc)
+				assert.Equal(t, 200, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.True(t, result.Get("valid").MustBool())
+				assert.Empty(t, result.Get("errors").MustArray())
+			})
+		})
 	})
 
 	t.Run("Given two dashboards being compared", func(t *testing.T) {
@@ -779,6 +906,67 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 		})
 	})
 
+	t.Run("Given versions of a dashboard being compared", func(t *testing.T) {
+		fakeDash := models.NewDashboard("Dash")
+		fakeDash.Id = 1
+		fakeDash.Uid = "dash"
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = fakeDash
+		}).Return(nil)
+		mockSQLStore := mockstore.NewSQLStoreMock()
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+
+		hs := &HTTPServer{
+			Cfg:                     setting.NewCfg(),
+			SQLStore:                mockSQLStore,
+			dashboardService:        dashboardService,
+			dashboardVersionService: fakeDashboardVersionService,
+		}
+
+		loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/uid/dash/versions/3/diff",
+			"/api/dashboards/uid/:uid/versions/:id/diff", models.ROLE_ADMIN, func(sc *scenarioContext) {
+				guardian.InitLegacyGuardian(mockSQLStore, dashboardService)
+				fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersion{
+					{
+						DashboardID:   1,
+						Version:       3,
+						ParentVersion: 2,
+						Data:          simplejson.NewFromAny(map[string]interface{}{"title": "Dash2", "version": 3}),
+					},
+					{
+						DashboardID:   1,
+						Version:       2,
+						ParentVersion: 1,
+						Data:          simplejson.NewFromAny(map[string]interface{}{"title": "Dash1", "version": 2}),
+					},
+				}
+
+				sc.handlerFunc = hs.GetDashboardVersionPatch
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"ignoreVolatile": "true"}).exec()
+				require.Equal(t, http.StatusOK, sc.resp.Code)
+
+				var result dtos.DashboardVersionPatch
+				require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+				assert.Equal(t, 2, result.BaseVersion)
+				assert.Equal(t, 3, result.NewVersion)
+				require.Len(t, result.Patch, 1)
+				assert.Equal(t, dashdiffs.PatchOperation{Op: dashdiffs.OpReplace, Path: "/title", Value: "Dash2"}, result.Patch[0])
+			}, mockSQLStore)
+
+		loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/uid/dash/versions/3/diff",
+			"/api/dashboards/uid/:uid/versions/:id/diff", models.ROLE_ADMIN, func(sc *scenarioContext) {
+				guardian.InitLegacyGuardian(mockSQLStore, dashboardService)
+				fakeDashboardVersionService.ExpectedDashboardVersions = nil
+				fakeDashboardVersionService.ExpectedError = dashver.ErrDashboardVersionNotFound
+
+				sc.handlerFunc = hs.GetDashboardVersionPatch
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				assert.Equal(t, http.StatusNotFound, sc.resp.Code)
+			}, mockSQLStore)
+	})
+
 	t.Run("Given dashboard in folder being restored should restore to folder", func(t *testing.T) {
 		const folderID int64 = 1
 		fakeDash := models.NewDashboard("Child dash")
@@ -1181,6 +1369,11 @@ func (l *mockLibraryElementService) GetElementsForDashboard(c context.Context, d
 	return map[string]libraryelements.LibraryElementDTO{}, nil
 }
 
+// GetElementsInFolder gets all elements of a kind in a specific folder.
+func (l *mockLibraryElementService) GetElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderID int64, kind models.LibraryElementKind) ([]libraryelements.LibraryElementDTO, error) {
+	return []libraryelements.LibraryElementDTO{}, nil
+}
+
 // ConnectElementsToDashboard connects elements to a specific dashboard.
 func (l *mockLibraryElementService) ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error {
 	return nil
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+// defaultUnusedDays is the number of days without views or queries after which
+// a dashboard is reported as unused.
+const defaultUnusedDays = 30
+
+// GetUnusedDashboards returns the dashboards of the organization that haven't
+// been viewed or queried for a number of days.
+// GET /api/dashboards/unused
+func (hs *HTTPServer) GetUnusedDashboards(c *models.ReqContext) response.Response {
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultUnusedDays
+	}
+	if days < 0 {
+		return response.Error(http.StatusBadRequest, "days must be a positive number", nil)
+	}
+
+	query := &dashboardusage.GetUnusedQuery{
+		OrgID: c.OrgId,
+		Since: time.Now().AddDate(0, 0, -days),
+		Limit: c.QueryInt("limit"),
+	}
+	result, err := hs.dashboardUsage.GetUnused(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get unused dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// publishDashboardUsage publishes the view of a dashboard or the queries of its
+// panels. The usage is only used for insights, failing to count it doesn't fail
+// the request.
+func (hs *HTTPServer) publishDashboardUsage(ctx context.Context, evt interface{}) {
+	if hs.bus == nil {
+		return
+	}
+	if err := hs.bus.Publish(ctx, evt); err != nil {
+		hs.log.Warn("Failed to publish the usage of the dashboard", "error", err)
+	}
+}
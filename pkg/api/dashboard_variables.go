@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ResolveDashboardVariables evaluates the template variables of a dashboard.
+// POST /api/dashboards/uid/:uid/variables/resolve
+func (hs *HTTPServer) ResolveDashboardVariables(c *models.ReqContext) response.Response {
+	cmd := dashboardvariables.ResolveVariablesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.SkipCache = c.SkipCache
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	variables, err := hs.dashboardVariables.ResolveVariables(c.Req.Context(), c.SignedInUser, dash, cmd)
+	if err != nil {
+		if errors.Is(err, dashboardvariables.ErrVariableCycle) || errors.Is(err, dashboardvariables.ErrInvalidTemplate) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to resolve dashboard variables", err)
+	}
+
+	return response.JSON(http.StatusOK, dashboardvariables.ResolveVariablesResponse{Variables: variables})
+}
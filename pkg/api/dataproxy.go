@@ -3,9 +3,15 @@ package api
 import "github.com/grafana/grafana/pkg/models"
 
 func (hs *HTTPServer) ProxyDataSourceRequest(c *models.ReqContext) {
+	if !hs.validateDataSourceCallPolicy(c) {
+		return
+	}
 	hs.DataProxy.ProxyDataSourceRequest(c)
 }
 
 func (hs *HTTPServer) ProxyDataSourceRequestWithUID(c *models.ReqContext) {
+	if !hs.validateDataSourceCallPolicy(c) {
+		return
+	}
 	hs.DataProxy.ProxyDatasourceRequestWithUID(c, "")
 }
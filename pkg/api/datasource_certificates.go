@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources/tlscerts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/datasources/uid/:uid/tls
+func (hs *HTTPServer) GetDataSourceCertificates(c *models.ReqContext) response.Response {
+	ds, resp := hs.getDataSourceForCertificates(c)
+	if resp != nil {
+		return resp
+	}
+
+	certs, err := hs.dataSourceCertificates.GetCertificates(c.Req.Context(), ds)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to read data source certificates", err)
+	}
+
+	return response.JSON(http.StatusOK, certs)
+}
+
+// PUT /api/datasources/uid/:uid/tls
+func (hs *HTTPServer) UpdateDataSourceCertificates(c *models.ReqContext) response.Response {
+	cmd := tlscerts.UpdateCertificatesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	ds, resp := hs.getDataSourceForCertificates(c)
+	if resp != nil {
+		return resp
+	}
+	if ds.ReadOnly {
+		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
+	}
+
+	if err := hs.dataSourceCertificates.UpdateCertificates(c.Req.Context(), ds, &cmd); err != nil {
+		if errors.Is(err, tlscerts.ErrInvalidCertificate) || errors.Is(err, tlscerts.ErrInvalidKeyPair) || errors.Is(err, tlscerts.ErrMissingKeyPair) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+			return response.Error(http.StatusConflict, "Datasource has already been updated by someone else. Please reload and try again", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update data source certificates", err)
+	}
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, ds.Uid)
+
+	return response.Success("Data source certificates updated")
+}
+
+// DELETE /api/datasources/uid/:uid/tls
+func (hs *HTTPServer) DeleteDataSourceCertificates(c *models.ReqContext) response.Response {
+	ds, resp := hs.getDataSourceForCertificates(c)
+	if resp != nil {
+		return resp
+	}
+	if ds.ReadOnly {
+		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
+	}
+
+	if err := hs.dataSourceCertificates.DeleteCertificates(c.Req.Context(), ds); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete data source certificates", err)
+	}
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, ds.Uid)
+
+	return response.Success("Data source certificates deleted")
+}
+
+func (hs *HTTPServer) getDataSourceForCertificates(c *models.ReqContext) (*models.DataSource, response.Response) {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+	return ds, nil
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetDataSourceMetadata returns metadata of a data source for query builders, e.g. its tables.
+// The query parameters are passed on to the data source plugin.
+// GET /api/datasources/uid/:uid/metadata/:kind
+func (hs *HTTPServer) GetDataSourceMetadata(c *models.ReqContext) response.Response {
+	ds, rsp := hs.getDataSourceByUIDParam(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	metadata, err := hs.dataSourceMetadata.GetMetadata(c.Req.Context(), c.SignedInUser, ds, datasourcemetadata.MetadataQuery{
+		Kind:      web.Params(c.Req)[":kind"],
+		Params:    c.Req.URL.Query(),
+		SkipCache: c.SkipCache,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, datasourcemetadata.ErrUnsupportedDataSource):
+			return response.Error(http.StatusNotImplemented, err.Error(), err)
+		case errors.Is(err, datasourcemetadata.ErrInvalidKind):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, querypolicy.ErrDataSourceCallNotAllowed):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get data source metadata", err)
+	}
+	return response.JSON(http.StatusOK, metadata)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/datasources/templates
+func (hs *HTTPServer) GetDataSourceTemplates(c *models.ReqContext) response.Response {
+	result, err := hs.dataSourceTemplates.List(c.Req.Context(), &datasourcetemplates.ListDataSourceTemplatesQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source templates", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// GET /api/datasources/templates/:uid
+func (hs *HTTPServer) GetDataSourceTemplate(c *models.ReqContext) response.Response {
+	result, err := hs.dataSourceTemplates.Get(c.Req.Context(), &datasourcetemplates.GetDataSourceTemplateQuery{
+		OrgID: c.OrgId,
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return dataSourceTemplateError(err, "Failed to get data source template")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/datasources/templates/:uid
+func (hs *HTTPServer) UpdateDataSourceTemplate(c *models.ReqContext) response.Response {
+	form := dtos.UpdateDataSourceTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := datasourcetemplates.SaveDataSourceTemplateCommand{
+		OrgID:  c.OrgId,
+		UserID: c.UserId,
+		Template: datasourcetemplates.DataSourceTemplate{
+			UID:        web.Params(c.Req)[":uid"],
+			Name:       form.Name,
+			Definition: form.Definition,
+			Instances:  form.Instances,
+		},
+		SecureJSONData: form.SecureJsonData,
+	}
+	if err := hs.dataSourceTemplates.Save(c.Req.Context(), &cmd); err != nil {
+		return dataSourceTemplateError(err, "Failed to save data source template")
+	}
+
+	return response.JSON(http.StatusOK, cmd.Template)
+}
+
+// DELETE /api/datasources/templates/:uid
+func (hs *HTTPServer) DeleteDataSourceTemplate(c *models.ReqContext) response.Response {
+	err := hs.dataSourceTemplates.Delete(c.Req.Context(), &datasourcetemplates.DeleteDataSourceTemplateCommand{
+		OrgID: c.OrgId,
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return dataSourceTemplateError(err, "Failed to delete data source template")
+	}
+
+	return response.Success("Data source template and its data sources deleted")
+}
+
+// POST /api/datasources/templates/:uid/rotate-credentials
+func (hs *HTTPServer) RotateDataSourceTemplateCredentials(c *models.ReqContext) response.Response {
+	form := dtos.RotateDataSourceTemplateCredentialsCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := hs.dataSourceTemplates.RotateCredentials(c.Req.Context(), &datasourcetemplates.RotateCredentialsCommand{
+		OrgID:          c.OrgId,
+		UID:            web.Params(c.Req)[":uid"],
+		SecureJSONData: form.SecureJsonData,
+	})
+	if err != nil {
+		return dataSourceTemplateError(err, "Failed to rotate data source credentials")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func dataSourceTemplateError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, datasourcetemplates.ErrDataSourceTemplateNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, datasourcetemplates.ErrInvalidDataSourceTemplate),
+		errors.Is(err, datasourcetemplates.ErrNoCredentials):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, datasourcetemplates.ErrDataSourceNotManaged),
+		errors.Is(err, models.ErrDataSourceNameExists),
+		errors.Is(err, models.ErrDataSourceUidExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
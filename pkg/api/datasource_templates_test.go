@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates/datasourcetemplatestest"
+)
+
+func TestAPIEndpoint_DataSourceTemplates(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	setInitCtxSignedInViewer(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{
+		{Action: datasources.ActionRead, Scope: datasources.ScopeAll},
+		{Action: datasources.ActionCreate},
+		{Action: datasources.ActionWrite, Scope: datasources.ScopeAll},
+	}, sc.initCtx.OrgId)
+
+	templates := datasourcetemplatestest.NewDataSourceTemplatesServiceFake()
+	sc.hs.dataSourceTemplates = templates
+
+	t.Run("Lists the templates", func(t *testing.T) {
+		templates.ExpectedTemplates = []*datasourcetemplates.DataSourceTemplate{{UID: "prom", Name: "Prometheus"}}
+		templates.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/datasources/templates", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []datasourcetemplates.DataSourceTemplate
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		require.Len(t, result, 1)
+		assert.Equal(t, "prom", result[0].UID)
+	})
+
+	t.Run("Saves the template with the uid of the path", func(t *testing.T) {
+		templates.ExpectedError = nil
+		body := `{"name":"Prometheus","definition":{"name":"Prometheus ${region}","type":"prometheus","access":"proxy"},"instances":[{"key":"eu","variables":{"region":"eu"}}]}`
+		response := callAPI(sc.server, http.MethodPut, "/api/datasources/templates/prom", strings.NewReader(body), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result datasourcetemplates.DataSourceTemplate
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, "prom", result.UID)
+		assert.Equal(t, "Prometheus ${region}", result.Definition.Name)
+	})
+
+	t.Run("Returns the result of the rotation", func(t *testing.T) {
+		templates.ExpectedRotation = &datasourcetemplates.RotateCredentialsResult{Rotated: []string{"prom-eu"}, Failed: map[string]string{}}
+		templates.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/datasources/templates/prom/rotate-credentials", strings.NewReader(`{"secureJsonData":{"basicAuthPassword":"new"}}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result datasourcetemplates.RotateCredentialsResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, []string{"prom-eu"}, result.Rotated)
+	})
+
+	t.Run("Requires the permission to delete data sources to delete a template", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodDelete, "/api/datasources/templates/prom", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{err: datasourcetemplates.ErrDataSourceTemplateNotFound, code: http.StatusNotFound},
+		{err: fmt.Errorf("%w: name is required", datasourcetemplates.ErrInvalidDataSourceTemplate), code: http.StatusBadRequest},
+		{err: fmt.Errorf("failed to apply instance %q: %w", "eu", datasourcetemplates.ErrDataSourceNotManaged), code: http.StatusConflict},
+		{err: models.ErrDataSourceNameExists, code: http.StatusConflict},
+	} {
+		t.Run(fmt.Sprintf("Returns %d for %q", tc.code, tc.err), func(t *testing.T) {
+			templates.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPut, "/api/datasources/templates/prom", strings.NewReader(`{}`), t)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+}
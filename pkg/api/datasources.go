@@ -18,6 +18,8 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
 	"github.com/grafana/grafana/pkg/web"
@@ -54,6 +56,7 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 			IsDefault: ds.IsDefault,
 			JsonData:  ds.JsonData,
 			ReadOnly:  ds.ReadOnly,
+			Origin:    ds.Origin,
 		}
 
 		if plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), ds.Type); exists {
@@ -154,6 +157,125 @@ func (hs *HTTPServer) GetDataSourceByUID(c *models.ReqContext) response.Response
 	return response.JSON(http.StatusOK, &dto)
 }
 
+// GET /api/datasources/uid/:uid/secrets/access
+func (hs *HTTPServer) GetDataSourceSecretsAccess(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	report, err := hs.secretsAuditService.GetLastAccess(c.Req.Context(), &audit.GetLastAccessQuery{
+		OrgID: c.OrgId,
+		Kind:  audit.KindDatasource,
+		UID:   ds.Uid,
+	})
+	if err != nil {
+		if errors.Is(err, audit.ErrNoAccessRecorded) {
+			return response.Error(http.StatusNotFound, "No access to the data source secrets has been recorded", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get data source secrets access report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// POST /api/datasources/uid/:uid/unlock
+func (hs *HTTPServer) UnlockDataSource(c *models.ReqContext) response.Response {
+	return hs.setDataSourceReadOnly(c, false)
+}
+
+// POST /api/datasources/uid/:uid/lock
+func (hs *HTTPServer) LockDataSource(c *models.ReqContext) response.Response {
+	return hs.setDataSourceReadOnly(c, true)
+}
+
+func (hs *HTTPServer) setDataSourceReadOnly(c *models.ReqContext, readOnly bool) response.Response {
+	dto := dtos.SetDataSourceLockCmd{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := &models.SetDataSourceReadOnlyCommand{
+		OrgId:    c.OrgId,
+		Uid:      web.Params(c.Req)[":uid"],
+		ReadOnly: readOnly,
+		Reason:   dto.Reason,
+		User:     c.SignedInUser,
+	}
+	if err := hs.DataSourcesService.SetDataSourceReadOnly(c.Req.Context(), cmd); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDataSourceNotFound):
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		case errors.Is(err, models.ErrDataSourceNotProvisioned), errors.Is(err, models.ErrDataSourceUnlockReasonRequired):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to change the data source lock", err)
+	}
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, cmd.Uid)
+	return response.JSON(http.StatusOK, hs.convertModelToDtos(c.Req.Context(), cmd.Result))
+}
+
+// POST /api/datasources/uid/:uid/default
+func (hs *HTTPServer) SetDefaultDataSource(c *models.ReqContext) response.Response {
+	cmd := &models.SetDefaultDataSourceCommand{OrgId: c.OrgId, Uid: web.Params(c.Req)[":uid"]}
+	if err := hs.DataSourcesService.SetDefaultDataSource(c.Req.Context(), cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set the default data source", err)
+	}
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, cmd.Uid)
+	return response.JSON(http.StatusOK, hs.convertModelToDtos(c.Req.Context(), cmd.Result))
+}
+
+// GET /api/datasources/order
+func (hs *HTTPServer) GetDataSourceOrder(c *models.ReqContext) response.Response {
+	query := &models.GetDataSourceOrderQuery{OrgId: c.OrgId}
+	if err := hs.DataSourcesService.GetDataSourceOrder(c.Req.Context(), query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the data source order", err)
+	}
+	return response.JSON(http.StatusOK, dtos.DataSourceOrder{Groups: query.Result})
+}
+
+// PUT /api/datasources/order
+func (hs *HTTPServer) SetDataSourceOrder(c *models.ReqContext) response.Response {
+	dto := dtos.DataSourceOrder{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := &models.SetDataSourceOrderCommand{OrgId: c.OrgId, Groups: dto.Groups}
+	if err := hs.DataSourcesService.SetDataSourceOrder(c.Req.Context(), cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceOrderInvalid) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set the data source order", err)
+	}
+	return response.Success("Data source order saved")
+}
+
+// GET /api/datasources/uid/:uid/lock/history
+func (hs *HTTPServer) GetDataSourceLockHistory(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	query := &models.GetDataSourceLockEventsQuery{OrgId: c.OrgId, Uid: ds.Uid}
+	if err := hs.DataSourcesService.GetDataSourceLockEvents(c.Req.Context(), query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source lock history", err)
+	}
+	return response.JSON(http.StatusOK, query.Result)
+}
+
 // DELETE /api/datasources/uid/:uid
 func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Response {
 	uid := web.Params(c.Req)[":uid"]
@@ -231,6 +353,19 @@ func validateURL(cmdType string, url string) response.Response {
 	return nil
 }
 
+// dataSourceValidationErrorResponse returns the settings that don't match the schema
+// of the plugin, or nil if the error isn't a validation error.
+func dataSourceValidationErrorResponse(err error) response.Response {
+	var validationErr *models.DataSourceValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+	return response.JSON(http.StatusBadRequest, util.DynMap{
+		"message": models.ErrDataSourceInvalidSettings.Error(),
+		"errors":  validationErr.Errors,
+	})
+}
+
 // POST /api/datasources/
 func (hs *HTTPServer) AddDataSource(c *models.ReqContext) response.Response {
 	cmd := models.AddDataSourceCommand{}
@@ -241,6 +376,7 @@ func (hs *HTTPServer) AddDataSource(c *models.ReqContext) response.Response {
 	datasourcesLogger.Debug("Received command to add data source", "url", cmd.Url)
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.UserId
+	cmd.Origin = models.DsOriginAPI
 	if cmd.Url != "" {
 		if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
 			return resp
@@ -251,6 +387,12 @@ func (hs *HTTPServer) AddDataSource(c *models.ReqContext) response.Response {
 		if errors.Is(err, models.ErrDataSourceNameExists) || errors.Is(err, models.ErrDataSourceUidExists) {
 			return response.Error(409, err.Error(), err)
 		}
+		if resp := dataSourceValidationErrorResponse(err); resp != nil {
+			return resp
+		}
+		if errors.Is(err, secretref.ErrReferenceNotAllowed) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 
 		return response.Error(500, "Failed to add datasource", err)
 	}
@@ -323,6 +465,12 @@ func (hs *HTTPServer) updateDataSourceByID(c *models.ReqContext, ds *models.Data
 		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
 			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
 		}
+		if resp := dataSourceValidationErrorResponse(err); resp != nil {
+			return resp
+		}
+		if errors.Is(err, secretref.ErrReferenceNotAllowed) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to update datasource", err)
 	}
 
@@ -412,6 +560,9 @@ func (hs *HTTPServer) GetDataSourceIdByName(c *models.ReqContext) response.Respo
 
 // /api/datasources/:id/resources/*
 func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
+	if !hs.validateDataSourceCallPolicy(c) {
+		return
+	}
 	datasourceID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
 	if err != nil {
 		c.JsonApiErr(http.StatusBadRequest, "id is invalid", err)
@@ -438,6 +589,9 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 
 // /api/datasources/uid/:uid/resources/*
 func (hs *HTTPServer) CallDatasourceResourceWithUID(c *models.ReqContext) {
+	if !hs.validateDataSourceCallPolicy(c) {
+		return
+	}
 	dsUID := web.Params(c.Req)[":uid"]
 	if !util.IsValidShortUID(dsUID) {
 		c.JsonApiErr(http.StatusBadRequest, "UID is invalid", nil)
@@ -482,6 +636,7 @@ func (hs *HTTPServer) convertModelToDtos(ctx context.Context, ds *models.DataSou
 		SecureJsonFields: map[string]bool{},
 		Version:          ds.Version,
 		ReadOnly:         ds.ReadOnly,
+		Origin:           ds.Origin,
 	}
 
 	secrets, err := hs.DataSourcesService.DecryptedValues(ctx, ds)
@@ -13,14 +13,19 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/querypolicy/querypolicytest"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
 const (
@@ -78,6 +83,23 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 }
 
 // Adding data sources with invalid URLs should lead to an error.
+func TestDataSourceCalls_QueryPolicy(t *testing.T) {
+	queryPolicy := querypolicytest.NewQueryPolicyServiceFake()
+	queryPolicy.ExpectedDataSourceCallError = querypolicy.ErrDataSourceCallNotAllowed
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.queryPolicy = queryPolicy
+	})
+
+	for _, target := range []string{"/api/datasources/proxy/uid/abc/api/v1/labels", "/api/datasources/uid/abc/resources/labels"} {
+		req := server.NewGetRequest(target)
+		webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+		resp, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusForbidden, resp.StatusCode, target)
+	}
+}
+
 func TestAddDataSource_InvalidURL(t *testing.T) {
 	sc := setupScenarioContext(t, "/api/datasources")
 	hs := &HTTPServer{
@@ -177,6 +199,79 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 	assert.Equal(t, 200, sc.resp.Code)
 }
 
+// Only provisioned data sources can be unlocked.
+func TestUnlockDataSource_NotProvisioned(t *testing.T) {
+	hs := &HTTPServer{
+		DataSourcesService: &dataSourcesServiceMock{
+			expectedError: models.ErrDataSourceNotProvisioned,
+		},
+	}
+	sc := setupScenarioContext(t, "/api/datasources/uid/abc/unlock")
+
+	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
+		c.Req.Body = mockRequestBody(dtos.SetDataSourceLockCmd{Reason: "rotated credentials"})
+		return hs.UnlockDataSource(c)
+	}))
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+	assert.Equal(t, 400, sc.resp.Code)
+}
+
+func TestSetDefaultDataSource_NotFound(t *testing.T) {
+	hs := &HTTPServer{
+		DataSourcesService: &dataSourcesServiceMock{
+			expectedError: models.ErrDataSourceNotFound,
+		},
+	}
+	sc := setupScenarioContext(t, "/api/datasources/uid/abc/default")
+
+	sc.m.Post(sc.url, routing.Wrap(hs.SetDefaultDataSource))
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+	assert.Equal(t, 404, sc.resp.Code)
+}
+
+func TestSetDataSourceOrder(t *testing.T) {
+	t.Run("Saves the order of the data sources", func(t *testing.T) {
+		dsService := &fakeDatasources.FakeDataSourceService{}
+		hs := &HTTPServer{DataSourcesService: dsService}
+		sc := setupScenarioContext(t, "/api/datasources/order")
+
+		sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(dtos.DataSourceOrder{Groups: []*models.DataSourceGroup{
+				{Name: "Production", Uids: []string{"prom-prod", "loki-prod"}},
+			}})
+			return hs.SetDataSourceOrder(c)
+		}))
+
+		sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+		assert.Equal(t, 200, sc.resp.Code)
+		require.Len(t, dsService.DataSourceOrder, 1)
+		assert.Equal(t, []string{"prom-prod", "loki-prod"}, dsService.DataSourceOrder[0].Uids)
+	})
+
+	t.Run("Returns 400 for an invalid order", func(t *testing.T) {
+		hs := &HTTPServer{
+			DataSourcesService: &dataSourcesServiceMock{
+				expectedError: fmt.Errorf("%w: data source %q is listed twice", models.ErrDataSourceOrderInvalid, "prom-prod"),
+			},
+		}
+		sc := setupScenarioContext(t, "/api/datasources/order")
+
+		sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(dtos.DataSourceOrder{})
+			return hs.SetDataSourceOrder(c)
+		}))
+
+		sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+}
+
 func TestAPI_Datasources_AccessControl(t *testing.T) {
 	testDatasource := models.DataSource{
 		Id:     3,
@@ -584,6 +679,28 @@ func (m *dataSourcesServiceMock) UpdateDataSource(ctx context.Context, cmd *mode
 	return m.expectedError
 }
 
+func (m *dataSourcesServiceMock) SetDataSourceReadOnly(ctx context.Context, cmd *models.SetDataSourceReadOnlyCommand) error {
+	cmd.Result = m.expectedDatasource
+	return m.expectedError
+}
+
+func (m *dataSourcesServiceMock) GetDataSourceLockEvents(ctx context.Context, query *models.GetDataSourceLockEventsQuery) error {
+	return m.expectedError
+}
+
+func (m *dataSourcesServiceMock) SetDefaultDataSource(ctx context.Context, cmd *models.SetDefaultDataSourceCommand) error {
+	cmd.Result = m.expectedDatasource
+	return m.expectedError
+}
+
+func (m *dataSourcesServiceMock) GetDataSourceOrder(ctx context.Context, query *models.GetDataSourceOrderQuery) error {
+	return m.expectedError
+}
+
+func (m *dataSourcesServiceMock) SetDataSourceOrder(ctx context.Context, cmd *models.SetDataSourceOrderCommand) error {
+	return m.expectedError
+}
+
 func (m *dataSourcesServiceMock) DecryptedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error) {
 	decryptedValues := make(map[string]string)
 	return decryptedValues, m.expectedError
@@ -0,0 +1,82 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+)
+
+// swagger:route GET /access-control/grants access_control getAccessGrants
+//
+// Get the access grants that haven't expired.
+//
+// Only works with Basic Authentication (username and password). Requires Grafana Admin permissions.
+//
+// Responses:
+// 200: getAccessGrantsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /access-control/grants access_control createAccessGrant
+//
+// Grant a user a basic role or a permission for a limited time.
+//
+// Grants either raise the basic role of the user in the organization or add a permission (an action and an optional scope) to the user.
+// They expire after the given duration, which can't be longer than 7 days, and must have a reason which is recorded in the audit log.
+// Only works with Basic Authentication (username and password). Requires Grafana Admin permissions.
+//
+// Responses:
+// 200: createAccessGrantResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /access-control/grants/{grant_id} access_control revokeAccessGrant
+//
+// Revoke an access grant before it expires.
+//
+// Only works with Basic Authentication (username and password). Requires Grafana Admin permissions.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters getAccessGrants
+type GetAccessGrantsParams struct {
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+}
+
+// swagger:parameters createAccessGrant
+type CreateAccessGrantParams struct {
+	// in:body
+	// required:true
+	Body accessgrants.CreateGrantCommand `json:"body"`
+}
+
+// swagger:parameters revokeAccessGrant
+type RevokeAccessGrantParams struct {
+	// in:path
+	// required:true
+	GrantID int64 `json:"grant_id"`
+}
+
+// swagger:response getAccessGrantsResponse
+type GetAccessGrantsResponse struct {
+	// in:body
+	Body []*accessgrants.Grant `json:"body"`
+}
+
+// swagger:response createAccessGrantResponse
+type CreateAccessGrantResponse struct {
+	// in:body
+	Body *accessgrants.Grant `json:"body"`
+}
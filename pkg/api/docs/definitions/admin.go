@@ -2,6 +2,8 @@ package definitions
 
 import (
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/configdrift"
 )
 
 // swagger:route GET /admin/settings admin getSettings
@@ -18,6 +20,20 @@ import (
 // 401: unauthorisedError
 // 403: forbiddenError
 
+// swagger:route GET /admin/config-drift admin getConfigDrift
+//
+// Compare the configuration of the instances of a highly available setup.
+//
+// Flags the instances whose ini configuration, settings stored in the database or feature toggles differ from the configuration of most instances.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `settings:read`.
+//
+// Responses:
+// 200: getConfigDriftResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
 // swagger:route GET /admin/stats admin getStats
 //
 // Fetch Grafana Stats.
@@ -31,6 +47,20 @@ import (
 // 403: forbiddenError
 // 500: internalServerError
 
+// swagger:route GET /admin/stats/history admin getStatsHistory
+//
+// Fetch the daily history of Grafana Stats.
+//
+// Returns the daily snapshots of key stats, oldest first, taken when `stats_history_enabled` is set in the `analytics` section.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `server:stats:read`.
+//
+// Responses:
+// 200: getStatsHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
 // swagger:route POST /admin/pause-all-alerts admin pauseAllAlerts
 //
 // Pause/unpause all (legacy) alerts.
@@ -65,3 +95,27 @@ type PauseAllAlertsResponse struct {
 		State string `json:"state"`
 	} `json:"body"`
 }
+
+// swagger:parameters getStatsHistory
+type GetStatsHistoryParams struct {
+	// First day of the history, formatted as YYYY-MM-DD.
+	// in:query
+	// required:false
+	From string `json:"from"`
+	// Last day of the history, formatted as YYYY-MM-DD.
+	// in:query
+	// required:false
+	To string `json:"to"`
+}
+
+// swagger:response getStatsHistoryResponse
+type GetStatsHistoryResponse struct {
+	// in:body
+	Body []*models.StatsHistory `json:"body"`
+}
+
+// swagger:response getConfigDriftResponse
+type GetConfigDriftResponse struct {
+	// in:body
+	Body configdrift.Report `json:"body"`
+}
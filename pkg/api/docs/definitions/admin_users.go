@@ -3,6 +3,7 @@ package definitions
 import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -182,6 +183,23 @@ import (
 // 404: notFoundError
 // 500: internalServerError
 
+// swagger:route GET /admin/users/stale-passwords admin_users getStalePasswords
+//
+// Get the users whose password is older than the maximum age.
+//
+// Returns the users signing in with a Grafana password that hasn't been changed for longer than the maximum age of the password policy, oldest first.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getStalePasswordsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
 // swagger:parameters setPassword
 type SetPasswordParams struct {
 	// in:body
@@ -307,3 +325,25 @@ type GetQuotaResponseResponse struct {
 	// in:body
 	Body []*models.UserQuotaDTO `json:"body"`
 }
+
+// swagger:parameters getStalePasswords
+type GetStalePasswordsParams struct {
+	// The number of days after which passwords are stale, the configured maximum age by default.
+	// in:query
+	// required:false
+	OlderThanDays int `json:"olderThanDays"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response getStalePasswordsResponse
+type GetStalePasswordsResponse struct {
+	// in:body
+	Body passwordpolicy.StalePasswordsResult `json:"body"`
+}
@@ -0,0 +1,82 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/ngalert/classicmigration"
+)
+
+// swagger:route POST /alerting/classic-conditions/migrate/{uid} alerting migrateClassicCondition
+//
+// Migrate the classic condition of an alert rule.
+//
+// Replaces the classic condition of the alert rule with reduce and math expressions and saves it as a new version.
+// Dry runs return the queries and expressions before and after the migration.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: migrateClassicConditionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /alerting/classic-conditions/migrate alerting migrateAllClassicConditions
+//
+// Migrate all classic conditions.
+//
+// Migrates all the alert rules of the organization that use a classic condition.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: migrateAllClassicConditionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /alerting/classic-conditions/report alerting getClassicConditionReport
+//
+// Get classic condition report.
+//
+// Returns the alert rules of the organization that use a classic condition.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: getClassicConditionReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters migrateClassicCondition
+type MigrateClassicConditionParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	Body classicmigration.MigrateRuleCommand `json:"body"`
+}
+
+// swagger:parameters migrateAllClassicConditions
+type MigrateAllClassicConditionsParams struct {
+	// in:body
+	Body classicmigration.MigrateAllRulesCommand `json:"body"`
+}
+
+// swagger:response migrateClassicConditionResponse
+type MigrateClassicConditionResponse struct {
+	// in: body
+	Body classicmigration.MigrationResult `json:"body"`
+}
+
+// swagger:response migrateAllClassicConditionsResponse
+type MigrateAllClassicConditionsResponse struct {
+	// in: body
+	Body classicmigration.BulkMigrationResult `json:"body"`
+}
+
+// swagger:response getClassicConditionReportResponse
+type GetClassicConditionReportResponse struct {
+	// in: body
+	Body []*classicmigration.ClassicRule `json:"body"`
+}
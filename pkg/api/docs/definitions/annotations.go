@@ -3,6 +3,7 @@ package definitions
 import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
 )
 
 // swagger:route GET /annotations annotations getAnnotations
@@ -113,6 +114,17 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 
+// swagger:route GET /annotations/sources annotations getAnnotationSources
+//
+// Get annotation sources.
+//
+// Returns the annotation source plugins enabled in the organization.
+//
+// Responses:
+// 200: getAnnotationSourcesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
 // swagger:parameters getAnnotation
 type GetAnnotationParams struct {
 	// in:path
@@ -175,6 +187,12 @@ type GetAnnotationsParams struct {
 	// in:query
 	// required:false
 	MatchAny bool `json:"matchAny"`
+	// Plugin IDs of annotation sources to query on demand. Their annotations are merged with the stored annotations.
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: multi
+	Source []string `json:"source"`
 }
 
 // swagger:parameters getAnnotationTags
@@ -238,6 +256,12 @@ type GetAnnotationsResponse struct {
 	Body []*annotations.ItemDTO `json:"body"`
 }
 
+// swagger:response getAnnotationSourcesResponse
+type GetAnnotationSourcesResponse struct {
+	// in: body
+	Body []*annotationsource.Source `json:"body"`
+}
+
 // swagger:response getAnnotationResponse
 type GetAnnotationResponse struct {
 	// The response message
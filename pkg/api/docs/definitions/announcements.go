@@ -0,0 +1,113 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/announcements"
+)
+
+// swagger:route GET /admin/announcements admin adminListAnnouncements
+//
+// List announcements.
+//
+// Returns all announcement banners, including the ones that have not started or have ended.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminListAnnouncementsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /admin/announcements admin adminCreateAnnouncement
+//
+// Create announcement.
+//
+// Announcements are shown as banners to the users of the targeted organizations and roles between their start and end time.
+// They are returned in the `announcements` field of the frontend settings.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminGetAnnouncementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route GET /admin/announcements/{announcement_uid} admin adminGetAnnouncement
+//
+// Get announcement by UID.
+//
+// Responses:
+// 200: adminGetAnnouncementResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /admin/announcements/{announcement_uid} admin adminUpdateAnnouncement
+//
+// Update announcement.
+//
+// Responses:
+// 200: adminGetAnnouncementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route DELETE /admin/announcements/{announcement_uid} admin adminDeleteAnnouncement
+//
+// Delete announcement.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /user/announcements/{announcement_uid}/dismiss signed_in_user dismissAnnouncement
+//
+// Dismiss announcement.
+//
+// Hides the announcement for the signed in user. The dismissal is stored in the preferences of the user.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters adminGetAnnouncement adminUpdateAnnouncement adminDeleteAnnouncement dismissAnnouncement
+type AnnouncementByUID struct {
+	// in:path
+	// required:true
+	UID string `json:"announcement_uid"`
+}
+
+// swagger:parameters adminCreateAnnouncement
+type AdminCreateAnnouncementParams struct {
+	// in:body
+	// required:true
+	Body announcements.CreateAnnouncementCommand `json:"body"`
+}
+
+// swagger:parameters adminUpdateAnnouncement
+type AdminUpdateAnnouncementParams struct {
+	// in:body
+	// required:true
+	Body announcements.UpdateAnnouncementCommand `json:"body"`
+}
+
+// swagger:response adminListAnnouncementsResponse
+type AdminListAnnouncementsResponse struct {
+	// in: body
+	Body []announcements.Announcement `json:"body"`
+}
+
+// swagger:response adminGetAnnouncementResponse
+type AdminGetAnnouncementResponse struct {
+	// in: body
+	Body announcements.Announcement `json:"body"`
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
 )
 
 // swagger:route GET /dashboards/uid/{uid} dashboards getDashboardByUID
@@ -24,12 +25,29 @@ import (
 // Delete dashboard by uid.
 //
 // Will delete the dashboard given the specified unique identifier (uid).
+// The dashboard is moved to the trash, from where it can be restored until the retention period of the trash expires.
 //
 // Responses:
 // 200: deleteDashboardResponse
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/uid/{uid}/variables/resolve dashboards resolveDashboardVariables
+//
+// Resolve dashboard template variables.
+//
+// Evaluates the template variables of the dashboard given the unique identifier (uid), in dependency order.
+// Query results are cached per variable.
+//
+// Responses:
+// 200: resolveDashboardVariablesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
 // 500: internalServerError
 
 // swagger:route POST /dashboards/calculate-diff dashboards calcDashboardDiff
@@ -116,7 +134,7 @@ type DeleteDashboardByUIDParams struct {
 }
 
 // swagger:parameters getDashboardPermissionsWithUid postDashboardPermissionsWithUid getDashboardVersionByUID
-// swagger:parameters getDashboardVersionsByUID restoreDashboardVersionByUID
+// swagger:parameters getDashboardVersionsByUID restoreDashboardVersionByUID resolveDashboardVariables
 type UID struct {
 	// in:path
 	// required:true
@@ -160,6 +178,19 @@ type ImportDashboardParam struct {
 	Body dashboardimport.ImportDashboardRequest
 }
 
+// swagger:parameters resolveDashboardVariables
+type ResolveDashboardVariablesParams struct {
+	// in:body
+	// required:true
+	Body dashboardvariables.ResolveVariablesCommand
+}
+
+// swagger:response resolveDashboardVariablesResponse
+type ResolveDashboardVariablesResponse struct {
+	// in: body
+	Body dashboardvariables.ResolveVariablesResponse `json:"body"`
+}
+
 // swagger:response dashboardResponse
 type DashboardResponse struct {
 	// The response message
@@ -0,0 +1,33 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+)
+
+// swagger:route POST /dashboards/apply dashboards applyDashboards
+//
+// Apply a manifest of dashboards.
+//
+// Creates or updates the dashboards of the manifest and deletes the dashboards that were applied
+// with the same `managedBy` before but are no longer part of the manifest. Dashboards managed as code
+// can only be changed by the user or service account applying them.
+//
+// Responses:
+// 200: applyDashboardsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters applyDashboards
+type ApplyDashboardsParams struct {
+	// in:body
+	// required:true
+	Body dashboardapply.ApplyCommand `json:"body"`
+}
+
+// swagger:response applyDashboardsResponse
+type ApplyDashboardsResponse struct {
+	// in: body
+	Body dashboardapply.ApplyResult `json:"body"`
+}
@@ -0,0 +1,130 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+)
+
+// swagger:route POST /dashboards/uid/{uid}/archive dashboards archiveDashboard
+//
+// Archive a dashboard.
+//
+// Moves the dashboard to the archive, which removes it from the search and the home dashboards until it's restored.
+// The version history, the annotations and the permissions of the dashboard are not kept.
+// Provisioned dashboards can't be archived. You need to be allowed to delete the dashboard.
+//
+// Responses:
+// 200: archiveDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route POST /folders/{uid}/archive folders archiveFolder
+//
+// Archive a folder.
+//
+// Moves the folder, with its subfolders and dashboards, to the archive.
+// Folders with provisioned dashboards or alert rules can't be archived. You need to be allowed to delete the folder.
+//
+// Responses:
+// 200: archiveDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/archive dashboards searchArchivedDashboards
+//
+// Search archived dashboards.
+//
+// Returns the archived dashboards and folders of the organization, the most recently archived first.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: searchArchivedDashboardsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/archive/{uid}/restore dashboards restoreArchivedDashboard
+//
+// Restore an archived dashboard.
+//
+// Restores the dashboard in the folder it was archived from, or in the General folder when that folder no longer exists.
+// Restoring a folder restores its subfolders and dashboards. Dashboards of an archived folder can't be restored before the folder.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: archiveDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/trash dashboards searchTrashedDashboards
+//
+// Search trashed dashboards.
+//
+// Returns the deleted dashboards of the organization that are still in the trash, the most recently deleted first.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: searchArchivedDashboardsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/trash/{uid}/restore dashboards restoreTrashedDashboard
+//
+// Restore a deleted dashboard.
+//
+// Restores the dashboard from the trash in the folder it was deleted from, or in the General folder when that folder no longer exists.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: archiveDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+
+// swagger:parameters archiveDashboard archiveFolder restoreArchivedDashboard restoreTrashedDashboard
+type ArchiveDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters searchArchivedDashboards searchTrashedDashboards
+type SearchArchivedDashboardsParams struct {
+	// Only return the dashboards and folders archived from this folder
+	// in:query
+	// required: false
+	FolderUID string `json:"folderUid"`
+	// Limit the number of returned dashboards
+	// in:query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:response archiveDashboardResponse
+type ArchiveDashboardResponse struct {
+	// The archived or restored dashboards and folders.
+	// in: body
+	Body []*dashboardarchive.ArchivedDashboard `json:"body"`
+}
+
+// swagger:response searchArchivedDashboardsResponse
+type SearchArchivedDashboardsResponse struct {
+	// in: body
+	Body []*dashboardarchive.ArchivedDashboard `json:"body"`
+}
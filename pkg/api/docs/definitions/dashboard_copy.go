@@ -0,0 +1,36 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+)
+
+// swagger:route POST /admin/dashboards/copy admin adminCopyDashboard
+//
+// Copy dashboard to another organization.
+//
+// Copies a dashboard, and optionally its folder and permissions, from an organization to another one with the same uid.
+// The data sources the dashboard uses are replaced with the data sources of the target organization with the same name and type.
+// The library panels the dashboard uses are copied when they don't exist in the target organization.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminCopyDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+
+// swagger:parameters adminCopyDashboard
+type AdminCopyDashboardParams struct {
+	// in:body
+	// required:true
+	Body dashboardcopy.CopyDashboardCommand `json:"body"`
+}
+
+// swagger:response adminCopyDashboardResponse
+type AdminCopyDashboardResponse struct {
+	// in: body
+	Body dashboardcopy.CopyDashboardResult `json:"body"`
+}
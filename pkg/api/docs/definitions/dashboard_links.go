@@ -0,0 +1,40 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+)
+
+// swagger:route GET /dashboards/link-check dashboards getDashboardLinkReport
+//
+// Get the broken links of dashboards.
+//
+// Returns the broken links found by the last check of the dashboards of the organization, grouped by severity.
+// Errors are references to data sources that no longer exist and panels of plugins that aren't installed.
+// Warnings are dashboard, panel and data links to dashboards and folders that no longer exist.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: dashboardLinkReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/link-check dashboards checkDashboardLinks
+//
+// Check the links of dashboards.
+//
+// Checks the dashboards of the organization for broken links right away, saves and returns the report.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: dashboardLinkReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:response dashboardLinkReportResponse
+type DashboardLinkReportResponse struct {
+	// in: body
+	Body dashboardlinks.Report `json:"body"`
+}
@@ -0,0 +1,34 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardrefs"
+)
+
+// swagger:route POST /dashboards/repair-refs dashboards repairDashboardRefs
+//
+// Repair references to missing dashboards.
+//
+// Looks for alert rules, library panel connections and annotations referencing dashboards or folders
+// of the organization that no longer exist. Alert rules are unlinked from the missing dashboard,
+// library panel connections and annotations of the missing dashboard are deleted. Alert rules stored
+// in a missing folder are reported but need to be moved by hand.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: repairDashboardRefsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters repairDashboardRefs
+type RepairDashboardRefsParams struct {
+	// in:body
+	Body dashboardrefs.ScanCommand `json:"body"`
+}
+
+// swagger:response repairDashboardRefsResponse
+type RepairDashboardRefsResponse struct {
+	// in: body
+	Body dashboardrefs.ScanResult `json:"body"`
+}
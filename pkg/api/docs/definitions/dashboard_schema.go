@@ -0,0 +1,83 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+)
+
+// swagger:route POST /dashboards/uid/{uid}/migrate-schema dashboards migrateDashboardSchema
+//
+// Migrate dashboard schema.
+//
+// Upgrades the stored JSON of the dashboard to the latest schema version and saves it as a new version.
+// Dashboards older than schema version 27 and provisioned dashboards cannot be migrated.
+//
+// Responses:
+// 200: migrateDashboardSchemaResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/migrate-schema dashboards migrateAllDashboardSchemas
+//
+// Migrate the schema of all dashboards.
+//
+// Migrates all the dashboards of the organization that are not on the latest schema version.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: migrateAllDashboardSchemasResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/schema-report dashboards getDashboardSchemaReport
+//
+// Get dashboard schema report.
+//
+// Returns the dashboards of the organization that are not on the latest schema version.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: getDashboardSchemaReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters migrateDashboardSchema
+type MigrateDashboardSchemaParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	Body dashboardschema.MigrateDashboardCommand `json:"body"`
+}
+
+// swagger:parameters migrateAllDashboardSchemas
+type MigrateAllDashboardSchemasParams struct {
+	// in:body
+	Body dashboardschema.MigrateAllDashboardsCommand `json:"body"`
+}
+
+// swagger:response migrateDashboardSchemaResponse
+type MigrateDashboardSchemaResponse struct {
+	// in: body
+	Body dashboardschema.MigrationResult `json:"body"`
+}
+
+// swagger:response migrateAllDashboardSchemasResponse
+type MigrateAllDashboardSchemasResponse struct {
+	// in: body
+	Body dashboardschema.BulkMigrationResult `json:"body"`
+}
+
+// swagger:response getDashboardSchemaReportResponse
+type GetDashboardSchemaReportResponse struct {
+	// in: body
+	Body struct {
+		LatestVersion       int                                  `json:"latestVersion"`
+		MinSupportedVersion int                                  `json:"minSupportedVersion"`
+		Dashboards          []*dashboardschema.OutdatedDashboard `json:"dashboards"`
+	} `json:"body"`
+}
@@ -0,0 +1,115 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+)
+
+// swagger:route GET /dashboards/templates dashboard_templates getDashboardTemplates
+//
+// Get all dashboard templates.
+//
+// Returns the dashboard templates of the current organization, sorted by name.
+//
+// Responses:
+// 200: getDashboardTemplatesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/templates/{template_uid} dashboard_templates getDashboardTemplate
+//
+// Get a dashboard template.
+//
+// Responses:
+// 200: dashboardTemplateResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /dashboards/templates/{template_uid} dashboard_templates updateDashboardTemplate
+//
+// Create or update a dashboard template.
+//
+// The dashboard of the template references its inputs as ${name}. The dashboards created from the
+// template are not changed by the update.
+//
+// Responses:
+// 200: dashboardTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /dashboards/templates/{template_uid} dashboard_templates deleteDashboardTemplate
+//
+// Delete a dashboard template.
+//
+// The dashboards created from the template are kept.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/templates/{template_uid}/instantiate dashboard_templates instantiateDashboardTemplate
+//
+// Create a dashboard from a template.
+//
+// Creates a new dashboard in the folder with the inputs of the template replaced by their values. The
+// inputs that aren't set get their default value. The data source of a data source input must be of
+// the plugin of the input.
+//
+// You need to have the permission to create dashboards in the folder.
+//
+// Responses:
+// 200: instantiateDashboardTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+
+// swagger:parameters getDashboardTemplate updateDashboardTemplate deleteDashboardTemplate
+// swagger:parameters instantiateDashboardTemplate
+type DashboardTemplateByUID struct {
+	// in:path
+	// required:true
+	UID string `json:"template_uid"`
+}
+
+// swagger:parameters updateDashboardTemplate
+type UpdateDashboardTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.UpdateDashboardTemplateCmd `json:"body"`
+}
+
+// swagger:parameters instantiateDashboardTemplate
+type InstantiateDashboardTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.InstantiateDashboardTemplateCmd `json:"body"`
+}
+
+// swagger:response getDashboardTemplatesResponse
+type GetDashboardTemplatesResponse struct {
+	// in: body
+	Body []*dashboardtemplates.DashboardTemplate `json:"body"`
+}
+
+// swagger:response dashboardTemplateResponse
+type DashboardTemplateResponse struct {
+	// in: body
+	Body dashboardtemplates.DashboardTemplate `json:"body"`
+}
+
+// swagger:response instantiateDashboardTemplateResponse
+type InstantiateDashboardTemplateResponse struct {
+	// in: body
+	Body dashboardtemplates.InstantiateResult `json:"body"`
+}
@@ -0,0 +1,39 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+// swagger:route GET /dashboards/unused dashboards getUnusedDashboards
+//
+// Get unused dashboards.
+//
+// Returns the dashboards of the organization that haven't been viewed or queried for a number of days,
+// the least recently viewed first. The dashboards created during that time are not returned.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: getUnusedDashboardsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters getUnusedDashboards
+type GetUnusedDashboardsParams struct {
+	// Number of days without views or queries
+	// in:query
+	// required: false
+	// default: 30
+	Days int `json:"days"`
+	// Limit the number of returned dashboards
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:response getUnusedDashboardsResponse
+type GetUnusedDashboardsResponse struct {
+	// in: body
+	Body []*dashboardusage.UnusedDashboard `json:"body"`
+}
@@ -0,0 +1,113 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+)
+
+// swagger:route GET /datasources/templates datasource_templates getDataSourceTemplates
+//
+// Get all data source templates.
+//
+// Returns the data source templates of the current organization, sorted by name.
+//
+// Responses:
+// 200: getDataSourceTemplatesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /datasources/templates/{template_uid} datasource_templates getDataSourceTemplate
+//
+// Get a data source template.
+//
+// Responses:
+// 200: dataSourceTemplateResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /datasources/templates/{template_uid} datasource_templates updateDataSourceTemplate
+//
+// Create or update a data source template.
+//
+// Creates or updates the data source of each instance of the template, and deletes the data sources
+// of the instances the template no longer has. The secure settings are only used for the data sources
+// the update creates.
+//
+// You need to have the permissions to create data sources and to write all data sources.
+//
+// Responses:
+// 200: dataSourceTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route DELETE /datasources/templates/{template_uid} datasource_templates deleteDataSourceTemplate
+//
+// Delete a data source template with its data sources.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /datasources/templates/{template_uid}/rotate-credentials datasource_templates rotateDataSourceTemplateCredentials
+//
+// Rotate the credentials of the data sources of a template.
+//
+// Sets the secure settings of all the data sources of the template. The rotation carries on when a
+// data source fails, and the response lists the rotated and the failed data sources.
+//
+// Responses:
+// 200: rotateDataSourceTemplateCredentialsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters getDataSourceTemplate updateDataSourceTemplate deleteDataSourceTemplate
+// swagger:parameters rotateDataSourceTemplateCredentials
+type DataSourceTemplateByUID struct {
+	// in:path
+	// required:true
+	UID string `json:"template_uid"`
+}
+
+// swagger:parameters updateDataSourceTemplate
+type UpdateDataSourceTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.UpdateDataSourceTemplateCmd `json:"body"`
+}
+
+// swagger:parameters rotateDataSourceTemplateCredentials
+type RotateDataSourceTemplateCredentialsParams struct {
+	// in:body
+	// required:true
+	Body dtos.RotateDataSourceTemplateCredentialsCmd `json:"body"`
+}
+
+// swagger:response getDataSourceTemplatesResponse
+type GetDataSourceTemplatesResponse struct {
+	// in: body
+	Body []*datasourcetemplates.DataSourceTemplate `json:"body"`
+}
+
+// swagger:response dataSourceTemplateResponse
+type DataSourceTemplateResponse struct {
+	// in: body
+	Body datasourcetemplates.DataSourceTemplate `json:"body"`
+}
+
+// swagger:response rotateDataSourceTemplateCredentialsResponse
+type RotateDataSourceTemplateCredentialsResponse struct {
+	// in: body
+	Body datasourcetemplates.RotateCredentialsResult `json:"body"`
+}
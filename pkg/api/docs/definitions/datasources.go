@@ -3,6 +3,8 @@ package definitions
 import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adhocfilters"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata"
 )
 
 // swagger:route GET /datasources datasources getDatasources
@@ -26,11 +28,15 @@ import (
 // Grafana encrypts them securely as an encrypted blob in the database.
 // The response then lists the encrypted fields under secureJsonFields.
 //
+// When the plugin defines a settings schema, `jsonData` and `secureJsonData` are validated
+// against it and the fields that don't match are returned with a 400 response.
+//
 // If you are running Grafana Enterprise and have Fine-grained access control enabled
 // you need to have a permission with action: `datasources:create`
 //
 // Responses:
 // 200: createOrUpdateDatasourceResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 409: conflictError
@@ -53,6 +59,7 @@ import (
 //
 // Responses:
 // 200: createOrUpdateDatasourceResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 500: internalServerError
@@ -70,6 +77,7 @@ import (
 //
 // Responses:
 // 200: createOrUpdateDatasourceResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 500: internalServerError
@@ -120,6 +128,51 @@ import (
 // 404: notFoundError
 // 500: internalServerError
 
+// swagger:route POST /datasources/uid/{uid}/default datasources setDefaultDatasource
+//
+// Set the default data source of the organization.
+//
+// Makes the data source the default data source of the organization, in place of the current default data source.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:write` and scopes: `datasources:*`, `datasources:uid:*` and `datasources:uid:kLtEtcRGk` (single data source).
+//
+// Responses:
+// 200: getDatasourceResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /datasources/order datasources getDatasourceOrder
+//
+// Get the order of the data sources in the data source pickers.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:read` and scope: `datasources:*`.
+//
+// Responses:
+// 200: getDatasourceOrderResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route PUT /datasources/order datasources setDatasourceOrder
+//
+// Set the order of the data sources in the data source pickers.
+//
+// The data sources are listed in the order of the groups, and the data sources that aren't in any group after them, by name.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:write` and scope: `datasources:*`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
 // swagger:route GET /datasources/{id} datasources getDatasourceByID
 //
 // Get a single data source by Id.
@@ -180,6 +233,58 @@ import (
 // 403: forbiddenError
 // 500: internalServerError
 
+// swagger:route GET /datasources/uid/{uid}/adhoc-filters/keys datasources getAdHocFilterKeys
+//
+// Get the keys suggested for ad hoc filters.
+//
+// Returns the label names of the data source that are not restricted by the `adHocFilters` settings of the data source.
+// Supported for Prometheus and Loki data sources.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:query` and scope: `datasources:uid:<uid>`.
+//
+// Responses:
+// 200: getAdHocFilterSuggestionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /datasources/uid/{uid}/adhoc-filters/values datasources getAdHocFilterValues
+//
+// Get the values suggested for an ad hoc filter key.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:query` and scope: `datasources:uid:<uid>`.
+//
+// Responses:
+// 200: getAdHocFilterSuggestionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /datasources/uid/{uid}/metadata/{kind} datasources getDatasourceMetadata
+//
+// Get metadata of a data source for query builders.
+//
+// Returns metadata such as tables, metrics or label keys, served by the data source plugin on its `metadata/{kind}` resource.
+// The query parameters are passed on to the plugin. Metadata is cached, and refreshed in the background once it is older
+// than the refresh interval. Set the `X-Grafana-NoCache: true` header to fetch it from the data source.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:query` and scope: `datasources:uid:<uid>`.
+//
+// Responses:
+// 200: getDatasourceMetadataResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
 // swagger:route GET /datasources/{id}/resources/{datasource_proxy_route} datasources fetchDatasourceResourcesByID
 //
 // Fetch data source resources by Id.
@@ -367,6 +472,7 @@ type DatasourceProxyGETcallsParams struct {
 
 // swagger:parameters datasourceProxyDELETEByUIDcalls
 // swagger:parameters checkDatasourceHealth fetchDatasourceResources
+// swagger:parameters getAdHocFilterKeys getAdHocFilterValues getDatasourceMetadata
 type DatasourceUID struct {
 	// in:path
 	// required:true
@@ -480,6 +586,26 @@ type UpdateDatasourceByUIDParams struct {
 	DatasourceUID string `json:"uid"`
 }
 
+// swagger:parameters setDefaultDatasource
+type SetDefaultDatasourceParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"uid"`
+}
+
+// swagger:parameters setDatasourceOrder
+type SetDatasourceOrderParams struct {
+	// in:body
+	// required:true
+	Body dtos.DataSourceOrder
+}
+
+// swagger:response getDatasourceOrderResponse
+type GetDatasourceOrderResponse struct {
+	// in: body
+	Body dtos.DataSourceOrder `json:"body"`
+}
+
 // swagger:response getDatasourcesResponse
 type GetDatasourcesResponse struct {
 	// The response message
@@ -548,3 +674,42 @@ type DeleteDatasourceByNameResponse struct {
 		Message string `json:"message"`
 	} `json:"body"`
 }
+
+// swagger:parameters getAdHocFilterKeys getAdHocFilterValues
+type AdHocFilterTimeRangeParams struct {
+	// Start of the time range the labels are looked up in, defaults to now-1h.
+	// in:query
+	// required:false
+	From string `json:"from"`
+	// End of the time range the labels are looked up in, defaults to now.
+	// in:query
+	// required:false
+	To string `json:"to"`
+}
+
+// swagger:parameters getAdHocFilterValues
+type GetAdHocFilterValuesParams struct {
+	// in:query
+	// required:true
+	Key string `json:"key"`
+}
+
+// swagger:response getAdHocFilterSuggestionsResponse
+type GetAdHocFilterSuggestionsResponse struct {
+	// in: body
+	Body []adhocfilters.Suggestion `json:"body"`
+}
+
+// swagger:parameters getDatasourceMetadata
+type GetDatasourceMetadataParams struct {
+	// The kind of metadata, e.g. tables.
+	// in:path
+	// required:true
+	Kind string `json:"kind"`
+}
+
+// swagger:response getDatasourceMetadataResponse
+type GetDatasourceMetadataResponse struct {
+	// in: body
+	Body datasourcemetadata.Metadata `json:"body"`
+}
@@ -12,6 +12,10 @@ import (
 // If you are running Grafana Enterprise and have Fine-grained access control enabled
 // you need to have a permission with action: `datasources:query`.
 //
+// When the query policy of the organization restricts viewers to saved queries, the queries of
+// viewers must match the queries saved in the dashboard identified by the X-Dashboard-Uid or
+// X-Dashboard-Id header, and the panel identified by the X-Panel-Id header.
+//
 // Responses:
 // 200: queryDataResponse
 // 207: queryDataResponse
@@ -25,6 +29,18 @@ type QueryMetricsWithExpressionsBodyParam struct {
 	// in:body
 	// required:true
 	Body dtos.MetricRequest `json:"body"`
+	// UID of the dashboard the queries are saved in.
+	// in:header
+	// required:false
+	DashboardUID string `json:"X-Dashboard-Uid"`
+	// ID of the dashboard the queries are saved in.
+	// in:header
+	// required:false
+	DashboardID int64 `json:"X-Dashboard-Id"`
+	// ID of the panel the queries are saved in.
+	// in:header
+	// required:false
+	PanelID int64 `json:"X-Panel-Id"`
 }
 
 // swagger:response queryDataResponse
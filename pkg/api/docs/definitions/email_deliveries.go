@@ -0,0 +1,77 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+)
+
+// swagger:route GET /admin/email/deliveries admin adminGetEmailDeliveries
+//
+// Get the last deliveries of the email delivery log.
+//
+// Every attempt to send an email with a mail provider is logged, including the attempts that failed over to the next provider.
+// Only works with Basic Authentication (username and password). Requires Grafana Admin permissions.
+//
+// Responses:
+// 200: getEmailDeliveriesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /email/bounces/{provider} email_deliveries postEmailBounces
+//
+// Receive the bounce notifications of a mail provider.
+//
+// Marks the bounced deliveries of the delivery log as bounced. The webhook doesn't require a Grafana user, it requires the token set in `bounce_webhook_token` of the `smtp` section.
+//
+// Responses:
+// 200: postEmailBouncesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters adminGetEmailDeliveries
+type AdminGetEmailDeliveriesParams struct {
+	// Only return the deliveries with this status.
+	// in:query
+	// required:false
+	// enum: sent,failed,bounced
+	Status string `json:"status"`
+	// in:query
+	// required:false
+	Recipient string `json:"recipient"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters postEmailBounces
+type PostEmailBouncesParams struct {
+	// in:path
+	// required:true
+	// enum: sendgrid,ses,mailgun
+	Provider string `json:"provider"`
+	// in:query
+	// required:true
+	Token string `json:"token"`
+	// The notification, in the format of the mail provider.
+	// in:body
+	// required:true
+	Body interface{} `json:"body"`
+}
+
+// swagger:response getEmailDeliveriesResponse
+type GetEmailDeliveriesResponse struct {
+	// in:body
+	Body []*emaildelivery.Delivery `json:"body"`
+}
+
+// swagger:response postEmailBouncesResponse
+type PostEmailBouncesResponse struct {
+	// in:body
+	Body struct {
+		// The number of deliveries marked as bounced.
+		Bounced int64 `json:"bounced"`
+	} `json:"body"`
+}
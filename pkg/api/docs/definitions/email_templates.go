@@ -0,0 +1,130 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+)
+
+// swagger:route GET /org/email-templates email_templates getOrgEmailTemplates
+//
+// Get the email templates of the current Organization.
+//
+// Returns the email templates the organization can customize, with their variables and the templates the organization saved.
+//
+// Responses:
+// 200: getOrgEmailTemplatesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /org/email-templates/{template_name} email_templates getOrgEmailTemplate
+//
+// Get an email template of the current Organization.
+//
+// Responses:
+// 200: getOrgEmailTemplateResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /org/email-templates/{template_name} email_templates updateOrgEmailTemplate
+//
+// Update an email template of the current Organization.
+//
+// The empty parts of the template use the default template. The template is rendered with example data
+// before it is saved, and is rejected if it fails to render.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route DELETE /org/email-templates/{template_name} email_templates deleteOrgEmailTemplate
+//
+// Reset an email template of the current Organization to the default template.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /org/email-templates/{template_name}/preview email_templates previewOrgEmailTemplate
+//
+// Preview an email template of the current Organization.
+//
+// Renders the template of the organization, or the draft, with example data.
+//
+// Responses:
+// 200: previewOrgEmailTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /org/email-templates/{template_name}/test email_templates sendTestOrgEmailTemplate
+//
+// Send a test email with an email template of the current Organization.
+//
+// Renders the template like the preview, and sends it to the recipients, or to the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+
+// swagger:parameters getOrgEmailTemplate updateOrgEmailTemplate deleteOrgEmailTemplate
+// swagger:parameters previewOrgEmailTemplate sendTestOrgEmailTemplate
+type EmailTemplateByName struct {
+	// in:path
+	// required:true
+	Name string `json:"template_name"`
+}
+
+// swagger:parameters updateOrgEmailTemplate
+type UpdateOrgEmailTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.UpdateEmailTemplateCmd `json:"body"`
+}
+
+// swagger:parameters previewOrgEmailTemplate
+type PreviewOrgEmailTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.PreviewEmailTemplateCmd `json:"body"`
+}
+
+// swagger:parameters sendTestOrgEmailTemplate
+type SendTestOrgEmailTemplateParams struct {
+	// in:body
+	// required:true
+	Body dtos.TestEmailTemplateCmd `json:"body"`
+}
+
+// swagger:response getOrgEmailTemplatesResponse
+type GetOrgEmailTemplatesResponse struct {
+	// in: body
+	Body dtos.EmailTemplates `json:"body"`
+}
+
+// swagger:response getOrgEmailTemplateResponse
+type GetOrgEmailTemplateResponse struct {
+	// in: body
+	Body dtos.EmailTemplate `json:"body"`
+}
+
+// swagger:response previewOrgEmailTemplateResponse
+type PreviewOrgEmailTemplateResponse struct {
+	// in: body
+	Body dtos.EmailTemplatePreview `json:"body"`
+}
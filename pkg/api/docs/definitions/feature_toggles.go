@@ -0,0 +1,45 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/featuremgmt/featureoverrides"
+)
+
+// swagger:route GET /admin/feature-toggles admin adminGetFeatureToggles
+//
+// List feature toggles.
+//
+// Returns all feature toggles with their description, stage and current value, including the values they were changed to at runtime.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminFeatureTogglesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+
+// swagger:route PUT /admin/feature-toggles admin adminUpdateFeatureToggles
+//
+// Update feature toggles.
+//
+// Changes feature toggles at runtime. The values are stored in the database and take precedence over the configuration.
+// Toggles that require a restart cannot be changed, and only toggles that are only used by the frontend can be changed per organization.
+// A null value removes the override. You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminFeatureTogglesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters adminUpdateFeatureToggles
+type AdminUpdateFeatureTogglesParams struct {
+	// in:body
+	// required:true
+	Body featureoverrides.UpdateTogglesCommand `json:"body"`
+}
+
+// swagger:response adminFeatureTogglesResponse
+type AdminFeatureTogglesResponse struct {
+	// in: body
+	Body []featureoverrides.Toggle `json:"body"`
+}
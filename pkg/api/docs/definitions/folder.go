@@ -80,6 +80,23 @@ import (
 // 404: notFoundError
 // 500: internalServerError
 
+// swagger:route GET /folders/{folder_uid}/export folders exportFolder
+//
+// Export folder.
+//
+// Streams a zip archive with the dashboards of the folder and a dashboard provisioning file reading them,
+// optionally with the library panels and the alert rules of the folder.
+//
+// Produces:
+// - application/zip
+//
+// Responses:
+// 200: exportFolderResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
 // swagger:parameters getFolders
 type GetFoldersParams struct {
 	// Limit the maximum number of folders to return
@@ -94,7 +111,7 @@ type GetFoldersParams struct {
 	Page int64 `json:"page"`
 }
 
-// swagger:parameters getFolderByUID updateFolder deleteFolder
+// swagger:parameters getFolderByUID updateFolder deleteFolder exportFolder
 // swagger:parameters getFolderPermissions
 type FolderUIDParam struct {
 	// in:path
@@ -112,6 +129,25 @@ type PostDashboardPermissionsParam struct {
 	Body dtos.UpdateDashboardAclCommand
 }
 
+// swagger:parameters exportFolder
+type ExportFolderParams struct {
+	// Add the library panels of the folder and the ones its dashboards use.
+	// in:query
+	// required:false
+	// default:false
+	LibraryPanels bool `json:"libraryPanels"`
+	// Add the alert rules of the folder. Requires the permission to read the alert rules of the folder.
+	// in:query
+	// required:false
+	// default:false
+	AlertRules bool `json:"alertRules"`
+	// Directory the dashboards of the archive are extracted to, used by the dashboard provisioning file.
+	// in:query
+	// required:false
+	// default:/var/lib/grafana/dashboards
+	Path string `json:"path"`
+}
+
 // swagger:parameters getFolderByID
 type FolderIDParam struct {
 	// in:path
@@ -182,3 +218,9 @@ type DeleteFolderResponse struct {
 		Message string `json:"message"`
 	} `json:"body"`
 }
+
+// swagger:response exportFolderResponse
+type ExportFolderResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
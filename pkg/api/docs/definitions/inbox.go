@@ -0,0 +1,92 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/inbox"
+)
+
+// swagger:route GET /user/inbox signed_in_user searchInboxNotifications
+//
+// Get the notifications in the inbox of the actual user.
+//
+// Notifications are returned newest first. New notifications are also pushed to the `grafana/inbox/{user_id}` Live channel.
+//
+// Responses:
+// 200: searchInboxNotificationsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route GET /user/inbox/unread-count signed_in_user getInboxUnreadCount
+//
+// Get the number of unread notifications of the actual user.
+//
+// Responses:
+// 200: getInboxUnreadCountResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route POST /user/inbox/read signed_in_user markInboxNotificationsRead
+//
+// Mark notifications as read or unread.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route DELETE /user/inbox/{notification_id} signed_in_user deleteInboxNotification
+//
+// Delete a notification from the inbox of the actual user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters searchInboxNotifications
+type SearchInboxNotificationsParams struct {
+	// in:query
+	// required: false
+	// enum: comment_mention,alert_rule_error,report_failure,token_expiry,certificate_expiry
+	Kind string `json:"kind"`
+	// in:query
+	// required: false
+	UnreadOnly bool `json:"unreadOnly"`
+	// in:query
+	// required: false
+	// default: 1
+	Page int `json:"page"`
+	// in:query
+	// required: false
+	// default: 50
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters markInboxNotificationsRead
+type MarkInboxNotificationsReadParams struct {
+	// in:body
+	// required:true
+	Body dtos.MarkInboxNotificationsReadCmd `json:"body"`
+}
+
+// swagger:parameters deleteInboxNotification
+type DeleteInboxNotificationParams struct {
+	// in:path
+	// required:true
+	NotificationID int64 `json:"notification_id"`
+}
+
+// swagger:response searchInboxNotificationsResponse
+type SearchInboxNotificationsResponse struct {
+	// in: body
+	Body inbox.SearchResult `json:"body"`
+}
+
+// swagger:response getInboxUnreadCountResponse
+type GetInboxUnreadCountResponse struct {
+	// in: body
+	Body dtos.InboxUnreadCount `json:"body"`
+}
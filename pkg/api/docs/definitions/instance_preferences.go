@@ -0,0 +1,182 @@
+package definitions
+
+import (
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+// swagger:route GET /admin/preferences admin adminGetInstanceDefaults
+//
+// Get instance default preferences.
+//
+// Returns the theme, timezone, week start and home dashboard path used for everyone who has not set them on the user,
+// team or organization level.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminInstanceDefaultsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+
+// swagger:route PUT /admin/preferences admin adminUpdateInstanceDefaults
+//
+// Update instance default preferences.
+//
+// Overrides the defaults from the configuration file without a restart. Empty values fall back to the configuration
+// file. The home dashboard path must be an absolute path to a `.json` file on the Grafana server.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminInstanceDefaultsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /admin/preferences admin adminResetInstanceDefaults
+//
+// Reset instance default preferences.
+//
+// Removes all overrides, the defaults from the configuration file are used again.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminInstanceDefaultsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters adminUpdateInstanceDefaults
+type AdminUpdateInstanceDefaultsParams struct {
+	// in:body
+	// required:true
+	Body pref.UpdateInstanceDefaultsCommand `json:"body"`
+}
+
+// swagger:response adminInstanceDefaultsResponse
+type AdminInstanceDefaultsResponse struct {
+	// in: body
+	Body pref.InstanceDefaults `json:"body"`
+}
+
+// swagger:route PATCH /orgs/{org_id}/preferences/users orgs adminPatchOrgUsersPreferences
+//
+// Patch the preferences of all the users of an organization.
+//
+// Sets the preferences of all the users of the organization in one transaction. Users who have no preferences yet
+// get them. Empty values reset the preference for all the users.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminPatchOrgUsersPreferencesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route DELETE /orgs/{org_id}/preferences/users orgs adminResetOrgUsersPreferences
+//
+// Reset the preferences of all the users of an organization.
+//
+// Resets the theme, timezone, week start, home dashboard, language, navbar, query history, default data source and
+// custom preferences of all the users of the organization.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminPatchOrgUsersPreferencesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters adminPatchOrgUsersPreferences
+type AdminPatchOrgUsersPreferencesParams struct {
+	// in:body
+	// required:true
+	Body pref.PatchOrgUsersPreferencesCommand `json:"body"`
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters adminResetOrgUsersPreferences
+type AdminResetOrgUsersPreferencesParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:response adminPatchOrgUsersPreferencesResponse
+type AdminPatchOrgUsersPreferencesResponse struct {
+	// in: body
+	Body struct {
+		Message string `json:"message"`
+		// The number of users whose preferences were updated.
+		UpdatedUsers int64 `json:"updatedUsers"`
+	} `json:"body"`
+}
+
+// swagger:route GET /orgs/{org_id}/preferences/export orgs adminExportOrgPreferences
+//
+// Export the preferences of an organization.
+//
+// Returns the preferences of the organization, of its teams and of its users. Teams are identified by name, users by login
+// and home dashboards by uid, so that the export can be imported in an organization of another instance.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminExportOrgPreferencesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /orgs/{org_id}/preferences/import orgs adminImportOrgPreferences
+//
+// Import the preferences of an organization.
+//
+// Replaces the preferences of the organization, and of the teams and users of the export, in one transaction.
+// Teams and users that don't exist in the organization are skipped, and home dashboards that don't exist are reset.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminImportOrgPreferencesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters adminExportOrgPreferences
+type AdminExportOrgPreferencesParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters adminImportOrgPreferences
+type AdminImportOrgPreferencesParams struct {
+	// in:body
+	// required:true
+	Body pref.PreferencesExport `json:"body"`
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:response adminExportOrgPreferencesResponse
+type AdminExportOrgPreferencesResponse struct {
+	// in: body
+	Body pref.PreferencesExport `json:"body"`
+}
+
+// swagger:response adminImportOrgPreferencesResponse
+type AdminImportOrgPreferencesResponse struct {
+	// in: body
+	Body pref.ImportPreferencesResult `json:"body"`
+}
@@ -0,0 +1,57 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/maintenance"
+)
+
+// swagger:route GET /admin/maintenance admin adminGetMaintenance
+//
+// Get maintenance mode.
+//
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminMaintenanceResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+
+// swagger:route POST /admin/maintenance admin adminEnableMaintenance
+//
+// Enable maintenance mode.
+//
+// Puts the instance into read-only mode, e.g. during database migrations and backups. API requests that change data
+// are rejected with 503 and the maintenance message until the maintenance mode is disabled. Alert rule evaluation is
+// paused if `pauseAlerting` is set. The maintenance mode survives restarts.
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminMaintenanceResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /admin/maintenance admin adminDisableMaintenance
+//
+// Disable maintenance mode.
+//
+// You need to have a permission with the Grafana Admin role.
+//
+// Responses:
+// 200: adminMaintenanceResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters adminEnableMaintenance
+type AdminEnableMaintenanceParams struct {
+	// in:body
+	// required:true
+	Body maintenance.EnableCommand `json:"body"`
+}
+
+// swagger:response adminMaintenanceResponse
+type AdminMaintenanceResponse struct {
+	// in: body
+	Body maintenance.Status `json:"body"`
+}
@@ -0,0 +1,51 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/onboarding"
+)
+
+// swagger:route GET /user/onboarding signed_in_user getOnboardingState
+//
+// Get onboarding state.
+//
+// Returns the dismissed panels, completed steps and discovered features of the signed in user.
+//
+// Responses:
+// 200: getOnboardingStateResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route POST /user/onboarding signed_in_user updateOnboardingState
+//
+// Update onboarding state.
+//
+// Adds dismissed panels, completed steps and discovered features to the onboarding state of the signed in user.
+// Ids may only contain letters, digits and the characters `_.:-`.
+//
+// Responses:
+// 200: getOnboardingStateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route DELETE /user/onboarding signed_in_user resetOnboardingState
+//
+// Reset onboarding state.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:parameters updateOnboardingState
+type UpdateOnboardingStateParams struct {
+	// in:body
+	// required:true
+	Body onboarding.UpdateStateCommand `json:"body"`
+}
+
+// swagger:response getOnboardingStateResponse
+type GetOnboardingStateResponse struct {
+	// in: body
+	Body onboarding.State `json:"body"`
+}
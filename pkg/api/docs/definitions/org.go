@@ -3,6 +3,8 @@ package definitions
 import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
 )
 
 // swagger:route GET /org current_org_details getOrg
@@ -82,6 +84,56 @@ import (
 // 403: forbiddenError
 // 500: internalServerError
 
+// swagger:route GET /org/query-policy current_org_details getOrgQueryPolicy
+//
+// Get the query policy of the current Organization.
+//
+// Responses:
+// 200: getOrgQueryPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route PUT /org/query-policy current_org_details updateOrgQueryPolicy
+//
+// Update the query policy of the current Organization.
+//
+// Restricting viewers to saved queries only lets viewers run the queries saved in the panels,
+// template variables and annotations of the dashboards they can view.
+// Data source proxy and resource calls are not covered by it, they are only rejected for
+// viewers when denying data source calls of viewers.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /org/anonymous-access current_org_details getOrgAnonymousAccess
+//
+// Get the anonymous access allowlist of the current Organization.
+//
+// Responses:
+// 200: getOrgAnonymousAccessResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route PUT /org/anonymous-access current_org_details updateOrgAnonymousAccess
+//
+// Update the anonymous access allowlist of the current Organization.
+//
+// When anonymous access is restricted to the allowlist, anonymous users can only view the
+// folders and dashboards of the allowlist.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
 // swagger:route PUT /org/address current_org_details updateOrgAddress
 //
 // Update current Organization's address.
@@ -193,3 +245,29 @@ type AddOrgUser struct {
 		Message string `json:"message"`
 	} `json:"body"`
 }
+
+// swagger:parameters updateOrgQueryPolicy
+type UpdateOrgQueryPolicyParams struct {
+	// in:body
+	// required:true
+	Body querypolicy.OrgQueryPolicy `json:"body"`
+}
+
+// swagger:response getOrgQueryPolicyResponse
+type GetOrgQueryPolicyResponse struct {
+	// in: body
+	Body querypolicy.OrgQueryPolicy `json:"body"`
+}
+
+// swagger:parameters updateOrgAnonymousAccess
+type UpdateOrgAnonymousAccessParams struct {
+	// in:body
+	// required:true
+	Body anonymousaccess.Allowlist `json:"body"`
+}
+
+// swagger:response getOrgAnonymousAccessResponse
+type GetOrgAnonymousAccessResponse struct {
+	// in: body
+	Body anonymousaccess.Allowlist `json:"body"`
+}
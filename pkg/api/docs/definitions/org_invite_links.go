@@ -0,0 +1,185 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/orginvites"
+)
+
+// swagger:route GET /org/invites/links org getOrgInviteLinks
+//
+// Get the invite links of the current organization that haven't expired.
+//
+// Responses:
+// 200: getOrgInviteLinksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /org/invites/links org createOrgInviteLink
+//
+// Create an invite link to the current organization.
+//
+// Users joining with the link get its role, or request access to the organization when the link requires approval.
+// Links expire after the given duration, between 15 minutes and 30 days, and can be used a maximum number of times unless it's 0.
+//
+// Responses:
+// 200: createOrgInviteLinkResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /org/invites/links/{link_uid} org deleteOrgInviteLink
+//
+// Delete an invite link of the current organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /org/invites/requests org getOrgAccessRequests
+//
+// Get the requests to join the current organization, the most recent first.
+//
+// Responses:
+// 200: getOrgAccessRequestsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route POST /org/invites/requests/{request_id}/approve org approveOrgAccessRequest
+//
+// Approve a pending access request.
+//
+// The user is added to the organization with the role of the link the request was made with, or with the role of the body.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route POST /org/invites/requests/{request_id}/reject org rejectOrgAccessRequest
+//
+// Reject a pending access request.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /user/invite-links/{code} signed_in_user getOrgInviteLinkInfo
+//
+// Get the organization and role an invite link invites to.
+//
+// Responses:
+// 200: getOrgInviteLinkInfoResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 410: genericError
+// 500: internalServerError
+
+// swagger:route POST /user/invite-links/{code}/join signed_in_user joinOrgWithInviteLink
+//
+// Join the organization of an invite link, or request access to it when the link requires approval.
+//
+// Responses:
+// 200: joinOrgWithInviteLinkResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 410: genericError
+// 500: internalServerError
+
+// swagger:parameters createOrgInviteLink
+type CreateOrgInviteLinkParams struct {
+	// in:body
+	// required:true
+	Body orginvites.CreateLinkCommand `json:"body"`
+}
+
+// swagger:parameters deleteOrgInviteLink
+type DeleteOrgInviteLinkParams struct {
+	// in:path
+	// required:true
+	LinkUID string `json:"link_uid"`
+}
+
+// swagger:parameters getOrgAccessRequests
+type GetOrgAccessRequestsParams struct {
+	// in:query
+	// required:false
+	// enum: pending,approved,rejected
+	Status string `json:"status"`
+}
+
+// swagger:parameters approveOrgAccessRequest
+type ApproveOrgAccessRequestParams struct {
+	// in:path
+	// required:true
+	RequestID int64 `json:"request_id"`
+	// in:body
+	// required:false
+	Body orginvites.ApproveRequestCommand `json:"body"`
+}
+
+// swagger:parameters rejectOrgAccessRequest
+type RejectOrgAccessRequestParams struct {
+	// in:path
+	// required:true
+	RequestID int64 `json:"request_id"`
+}
+
+// swagger:parameters getOrgInviteLinkInfo
+type GetOrgInviteLinkInfoParams struct {
+	// in:path
+	// required:true
+	Code string `json:"code"`
+}
+
+// swagger:parameters joinOrgWithInviteLink
+type JoinOrgWithInviteLinkParams struct {
+	// in:path
+	// required:true
+	Code string `json:"code"`
+	// in:body
+	// required:false
+	Body orginvites.JoinCommand `json:"body"`
+}
+
+// swagger:response getOrgInviteLinksResponse
+type GetOrgInviteLinksResponse struct {
+	// in:body
+	Body []*orginvites.Link `json:"body"`
+}
+
+// swagger:response createOrgInviteLinkResponse
+type CreateOrgInviteLinkResponse struct {
+	// in:body
+	Body *orginvites.Link `json:"body"`
+}
+
+// swagger:response getOrgAccessRequestsResponse
+type GetOrgAccessRequestsResponse struct {
+	// in:body
+	Body []*orginvites.AccessRequestDTO `json:"body"`
+}
+
+// swagger:response getOrgInviteLinkInfoResponse
+type GetOrgInviteLinkInfoResponse struct {
+	// in:body
+	Body *orginvites.LinkInfo `json:"body"`
+}
+
+// swagger:response joinOrgWithInviteLinkResponse
+type JoinOrgWithInviteLinkResponse struct {
+	// in:body
+	Body *orginvites.JoinResult `json:"body"`
+}
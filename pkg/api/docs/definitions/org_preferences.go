@@ -21,6 +21,7 @@ import "github.com/grafana/grafana/pkg/api/dtos"
 // 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
+// 412: preconditionFailedError
 // 500: internalServerError
 
 // swagger:route PATCH /org/preferences org_preferences patchOrgPreferences
@@ -32,6 +33,7 @@ import "github.com/grafana/grafana/pkg/api/dtos"
 // 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
+// 412: preconditionFailedError
 // 500: internalServerError
 
 // swagger:parameters updateOrgPreferences
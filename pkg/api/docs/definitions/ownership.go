@@ -0,0 +1,108 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/ownership"
+)
+
+// swagger:route GET /ownership/{resource_kind}/{resource_uid} ownership getResourceOwnership
+//
+// Get the owner of a dashboard or an alert rule.
+//
+// The permissions of alert rules are the ones of their folder.
+//
+// Responses:
+// 200: getResourceOwnershipResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /ownership/{resource_kind}/{resource_uid} ownership setResourceOwnership
+//
+// Set the owner of a dashboard or an alert rule.
+//
+// The owner is a team or a user of the organization. Links must be http or https URLs, such as runbooks or repositories.
+//
+// Responses:
+// 200: getResourceOwnershipResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route DELETE /ownership/{resource_kind}/{resource_uid} ownership deleteResourceOwnership
+//
+// Remove the owner of a dashboard or an alert rule.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /ownership/search ownership searchOwnedResources
+//
+// Search the resources owned by a team or a user.
+//
+// Only the resources the signed in user can view are returned.
+//
+// Responses:
+// 200: searchOwnedResourcesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route GET /ownership/orphaned ownership getOrphanedResources
+//
+// Get orphaned resources.
+//
+// Returns the dashboards and alert rules whose owning team has been deleted, or whose owning user has been removed from the organization.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: searchOwnedResourcesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters getResourceOwnership setResourceOwnership deleteResourceOwnership
+type ResourceOwnershipParams struct {
+	// in:path
+	// required:true
+	// enum: dashboard,alert-rule
+	ResourceKind string `json:"resource_kind"`
+	// in:path
+	// required:true
+	ResourceUID string `json:"resource_uid"`
+}
+
+// swagger:parameters setResourceOwnership
+type SetResourceOwnershipParams struct {
+	// in:body
+	// required:true
+	Body ownership.SetOwnershipCommand `json:"body"`
+}
+
+// swagger:parameters searchOwnedResources
+type SearchOwnedResourcesParams struct {
+	// in:query
+	// required:false
+	TeamID int64 `json:"teamId"`
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+}
+
+// swagger:response getResourceOwnershipResponse
+type GetResourceOwnershipResponse struct {
+	// in: body
+	Body ownership.Ownership `json:"body"`
+}
+
+// swagger:response searchOwnedResourcesResponse
+type SearchOwnedResourcesResponse struct {
+	// in: body
+	Body []ownership.OwnedResource `json:"body"`
+}
@@ -86,7 +86,70 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 
-// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery
+// swagger:route POST /query-history/share/{query_history_uid} query_history shareQuery
+//
+// Share query in query history with a team.
+//
+// Stars query in query history as specified by the UID on behalf of a team, so that it is available to all team members.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route DELETE /query-history/share/{query_history_uid}/{team_id} query_history unshareQuery
+//
+// Stop sharing query in query history with a team.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /query-history/retention query_history getQueryHistoryRetention
+//
+// Get query history retention.
+//
+// Returns for how many days unstarred and unshared queries are kept in the current organization.
+//
+// Responses:
+// 200: getQueryHistoryRetentionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route PUT /query-history/retention query_history setQueryHistoryRetention
+//
+// Set query history retention.
+//
+// Overrides the instance wide query history retention for the current organization.
+//
+// Responses:
+// 200: getQueryHistoryRetentionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route DELETE /query-history/retention query_history resetQueryHistoryRetention
+//
+// Reset query history retention.
+//
+// Removes the organization override so that the instance wide query history retention applies.
+//
+// Responses:
+// 200: getQueryHistoryRetentionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery shareQuery unshareQuery
 type QueryHistoryByUID struct {
 	// in:path
 	// required:true
@@ -109,6 +172,10 @@ type SearchQueriesParams struct {
 	// in:query
 	// required: false
 	OnlyStarred bool `json:"onlyStarred"`
+	// Flag indicating if only queries shared with the teams of the user should be returned
+	// in:query
+	// required: false
+	OnlyShared bool `json:"onlyShared"`
 	// Sort method
 	// in:query
 	// required: false
@@ -147,6 +214,27 @@ type PatchQueryCommentParams struct {
 	Body queryhistory.PatchQueryCommentInQueryHistoryCommand `json:"body"`
 }
 
+// swagger:parameters unshareQuery
+type UnshareQueryParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"team_id"`
+}
+
+// swagger:parameters shareQuery
+type ShareQueryParams struct {
+	// in:body
+	// required:true
+	Body queryhistory.ShareQueryInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters setQueryHistoryRetention
+type SetQueryHistoryRetentionParams struct {
+	// in:body
+	// required:true
+	Body queryhistory.SetRetentionPolicyCommand `json:"body"`
+}
+
 // swagger:parameters migrateQueries
 type MigrateQueriesParams struct {
 	// in:body
@@ -177,3 +265,9 @@ type GetQueryHistoryMigrationResponse struct {
 	// in: body
 	Body queryhistory.QueryHistoryMigrationResponse `json:"body"`
 }
+
+// swagger:response getQueryHistoryRetentionResponse
+type GetQueryHistoryRetentionResponse struct {
+	// in: body
+	Body queryhistory.QueryHistoryRetentionResponse `json:"body"`
+}
@@ -0,0 +1,110 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/queryschema"
+)
+
+// swagger:route GET /dashboards/query-schemas/drift dashboards getQuerySchemaDrift
+//
+// Get the schema drift of the queries of dashboards.
+//
+// Returns the changes of the fields returned by the queries of the dashboards of the organization,
+// the most recent first. Removed, renamed and retyped fields are breaking changes.
+// You need to be an organization admin.
+//
+// Responses:
+// 200: querySchemaDriftResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/uid/{uid}/query-schemas/drift dashboards getDashboardQuerySchemaDrift
+//
+// Get the schema drift of the queries of a dashboard.
+//
+// Returns the changes of the fields returned by the queries of the panels of the dashboard, the most recent first.
+//
+// Responses:
+// 200: querySchemaDriftResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /dashboards/uid/{uid}/query-schemas dashboards getDashboardQuerySchemas
+//
+// Get the schemas of the queries of a dashboard.
+//
+// Returns the fields recorded for the queries of the panels of the dashboard, every version of the
+// fields of a query the oldest first.
+//
+// Responses:
+// 200: querySchemasResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters getQuerySchemaDrift
+type GetQuerySchemaDriftParams struct {
+	// Filter the drift of the queries of a dashboard
+	// in:query
+	// required: false
+	DashboardUID string `json:"dashboardUid"`
+	// Number of days to return the drift for
+	// in:query
+	// required: false
+	// default: 7
+	Days int `json:"days"`
+	// Only return removed, renamed and retyped fields
+	// in:query
+	// required: false
+	// default: false
+	BreakingOnly bool `json:"breakingOnly"`
+}
+
+// swagger:parameters getDashboardQuerySchemaDrift
+type GetDashboardQuerySchemaDriftParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Number of days to return the drift for
+	// in:query
+	// required: false
+	// default: 7
+	Days int `json:"days"`
+	// Only return removed, renamed and retyped fields
+	// in:query
+	// required: false
+	// default: false
+	BreakingOnly bool `json:"breakingOnly"`
+}
+
+// swagger:parameters getDashboardQuerySchemas
+type GetDashboardQuerySchemasParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Filter the schemas of the queries of a panel
+	// in:query
+	// required: false
+	PanelID int64 `json:"panelId"`
+	// Filter the schemas of a query by ref id
+	// in:query
+	// required: false
+	RefID string `json:"refId"`
+}
+
+// swagger:response querySchemaDriftResponse
+type QuerySchemaDriftResponse struct {
+	// in: body
+	Body []*queryschema.Drift `json:"body"`
+}
+
+// swagger:response querySchemasResponse
+type QuerySchemasResponse struct {
+	// in: body
+	Body []*queryschema.Schema `json:"body"`
+}
@@ -0,0 +1,91 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
+)
+
+// swagger:route GET /resources resource_labels searchLabeledResources
+//
+// Search resources by label.
+//
+// Returns the dashboards, folders, data sources, alert rules and teams having all the labels.
+// Only the resources the signed in user can view are returned.
+//
+// Responses:
+// 200: searchLabeledResourcesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route GET /resources/{resource_kind}/{resource_uid}/labels resource_labels getResourceLabels
+//
+// Get the labels of a resource.
+//
+// The permissions of alert rules are the ones of their folder. Teams are identified by their id.
+//
+// Responses:
+// 200: getResourceLabelsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /resources/{resource_kind}/{resource_uid}/labels resource_labels setResourceLabels
+//
+// Replace the labels of a resource.
+//
+// Label names contain letters, digits, `_`, `.`, `/` and `-`. A resource can have at most 20 labels.
+//
+// Responses:
+// 200: getResourceLabelsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters searchLabeledResources
+type SearchLabeledResourcesParams struct {
+	// Label selectors of the form name:value, or name to match any value. Resources must match all the selectors.
+	// in:query
+	// required:true
+	Label []string `json:"label"`
+	// in:query
+	// required:false
+	Kind []string `json:"kind"`
+	// in:query
+	// required:false
+	// default:1000
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters getResourceLabels setResourceLabels
+type ResourceLabelsParams struct {
+	// in:path
+	// required:true
+	// enum: dashboard,folder,datasource,alert-rule,team
+	ResourceKind string `json:"resource_kind"`
+	// in:path
+	// required:true
+	ResourceUID string `json:"resource_uid"`
+}
+
+// swagger:parameters setResourceLabels
+type SetResourceLabelsParams struct {
+	// in:body
+	// required:true
+	Body resourcelabels.SetLabelsCommand `json:"body"`
+}
+
+// swagger:response getResourceLabelsResponse
+type GetResourceLabelsResponse struct {
+	// in: body
+	Body resourcelabels.Resource `json:"body"`
+}
+
+// swagger:response searchLabeledResourcesResponse
+type SearchLabeledResourcesResponse struct {
+	// in: body
+	Body []resourcelabels.Resource `json:"body"`
+}
@@ -68,7 +68,7 @@ type SearchParams struct {
 	// in:query
 	// required: false
 	// default: alpha-asc
-	// Enum: alpha-asc,alpha-desc
+	// Enum: alpha-asc,alpha-desc,views-desc,views-asc,queries-desc
 	Sort string `json:"sort"`
 }
 
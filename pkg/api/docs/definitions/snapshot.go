@@ -2,6 +2,7 @@ package definitions
 
 import (
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
 )
 
 // swagger:route POST /snapshots snapshots createSnapshot
@@ -16,6 +17,22 @@ import (
 // 403: forbiddenError
 // 500: internalServerError
 
+// swagger:route POST /dashboards/uid/{uid}/panels/{panelId}/snapshot snapshots createPanelSnapshot
+//
+// Create panel snapshot.
+//
+// Runs the queries of a panel of the dashboard given the unique identifier (uid) and returns their results, with the model of the panel.
+// The results are limited to `panel_snapshot_max_bytes` of the `snapshots` section of the configuration.
+// When `store` is true, the panel and its data are also saved as a dashboard snapshot.
+//
+// Responses:
+// 200: createPanelSnapshotResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
 // swagger:route GET /dashboard/snapshots snapshots getSnapshots
 //
 // List snapshots.
@@ -71,6 +88,19 @@ type CreateSnapshotParams struct {
 	Body models.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters createPanelSnapshot
+type CreatePanelSnapshotParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	PanelID int64 `json:"panelId"`
+	// in:body
+	// required:true
+	Body panelsnapshot.CreatePanelSnapshotCommand `json:"body"`
+}
+
 // swagger:parameters getSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -115,6 +145,12 @@ type CreateSnapshotResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response createPanelSnapshotResponse
+type CreatePanelSnapshotResponse struct {
+	// in:body
+	Body panelsnapshot.PanelSnapshot `json:"body"`
+}
+
 // swagger:response getSnapshotsResponse
 type GetSnapshotsResponse struct {
 	// in:body
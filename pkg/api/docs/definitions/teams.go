@@ -121,6 +121,21 @@ import (
 // 200: okResponse
 // 400: badRequestError
 // 401: unauthorisedError
+// 412: preconditionFailedError
+// 500: internalServerError
+
+// swagger:route PUT /teams/{team_id}/preferences/home-dashboard teams setTeamHomeDashboard
+//
+// Set the home dashboard of a team.
+//
+// The team must have permission to view the dashboard.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
 // 500: internalServerError
 
 // swagger:parameters updateTeamPreferences
@@ -133,6 +148,16 @@ type UpdateTeamPreferencesParams struct {
 	Body dtos.UpdatePrefsCmd `json:"body"`
 }
 
+// swagger:parameters setTeamHomeDashboard
+type SetTeamHomeDashboardParams struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+	// in:body
+	// required:true
+	Body dtos.SetTeamHomeDashboardCmd `json:"body"`
+}
+
 // swagger:parameters getTeam
 type GetTeamParams struct {
 	// in:path
@@ -0,0 +1,39 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+)
+
+// swagger:route POST /dashboards/uid/{uid}/recordings dashboards recordDashboard
+//
+// Record dashboard data.
+//
+// Runs the queries of the panels of the dashboard given the unique identifier (uid) and stores their results as a recording,
+// to replay them with the `Recorded data` scenario of the TestData data source.
+// Returns a copy of the dashboard whose recorded panels replay the recording, to be imported.
+// The results of each panel are limited to `panel_snapshot_max_bytes` of the `snapshots` section of the configuration.
+// You need to be able to edit the dashboard.
+//
+// Responses:
+// 200: recordDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters recordDashboard
+type RecordDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body testdatarecording.RecordCommand `json:"body"`
+}
+
+// swagger:response recordDashboardResponse
+type RecordDashboardResponse struct {
+	// in:body
+	Body testdatarecording.RecordResult `json:"body"`
+}
@@ -0,0 +1,172 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/timeregions"
+)
+
+// swagger:route GET /time-regions/calendars time_regions searchTimeRegionCalendars
+//
+// Search time region calendars.
+//
+// Returns the time region calendars of the organization, such as business hours and on-call windows.
+//
+// Responses:
+// 200: searchTimeRegionCalendarsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+
+// swagger:route POST /time-regions/calendars time_regions createTimeRegionCalendar
+//
+// Create time region calendar.
+//
+// Regions are recurring periods of the day on the given weekdays, in the timezone of the calendar.
+// Holidays are dates on which none of the regions apply.
+//
+// Responses:
+// 200: getTimeRegionCalendarResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route GET /time-regions/calendars/{calendar_uid} time_regions getTimeRegionCalendar
+//
+// Get time region calendar by UID.
+//
+// Responses:
+// 200: getTimeRegionCalendarResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /time-regions/calendars/{calendar_uid} time_regions updateTimeRegionCalendar
+//
+// Update time region calendar.
+//
+// Responses:
+// 200: getTimeRegionCalendarResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route DELETE /time-regions/calendars/{calendar_uid} time_regions deleteTimeRegionCalendar
+//
+// Delete time region calendar.
+//
+// Alert rules that reference a deleted calendar are evaluated at all times.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /time-regions/calendars/{calendar_uid}/windows time_regions getTimeRegionCalendarWindows
+//
+// Get the periods where a time region calendar is active.
+//
+// Returns the periods between `from` and `to` where the calendar is active, e.g. to show them as time regions in panels.
+// The time range cannot be longer than a year.
+//
+// Responses:
+// 200: getTimeRegionCalendarWindowsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route GET /time-regions/calendars/{calendar_uid}/active time_regions getTimeRegionCalendarActive
+//
+// Check whether a time region calendar is active.
+//
+// Responses:
+// 200: getTimeRegionCalendarActiveResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:parameters getTimeRegionCalendar updateTimeRegionCalendar deleteTimeRegionCalendar
+// swagger:parameters getTimeRegionCalendarWindows getTimeRegionCalendarActive
+type TimeRegionCalendarByUID struct {
+	// in:path
+	// required:true
+	UID string `json:"calendar_uid"`
+}
+
+// swagger:parameters searchTimeRegionCalendars
+type SearchTimeRegionCalendarsParams struct {
+	// Part of the name of the calendars
+	// in:query
+	// required: false
+	Query string `json:"query"`
+	// in:query
+	// required: false
+	// default: 1000
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters createTimeRegionCalendar
+type CreateTimeRegionCalendarParams struct {
+	// in:body
+	// required:true
+	Body timeregions.CreateCalendarCommand `json:"body"`
+}
+
+// swagger:parameters updateTimeRegionCalendar
+type UpdateTimeRegionCalendarParams struct {
+	// in:body
+	// required:true
+	Body timeregions.UpdateCalendarCommand `json:"body"`
+}
+
+// swagger:parameters getTimeRegionCalendarWindows
+type GetTimeRegionCalendarWindowsParams struct {
+	// in:query
+	// required: false
+	// default: now-24h
+	From string `json:"from"`
+	// in:query
+	// required: false
+	// default: now
+	To string `json:"to"`
+}
+
+// swagger:parameters getTimeRegionCalendarActive
+type GetTimeRegionCalendarActiveParams struct {
+	// Point in time to check, now by default
+	// in:query
+	// required: false
+	Time string `json:"time"`
+}
+
+// swagger:response searchTimeRegionCalendarsResponse
+type SearchTimeRegionCalendarsResponse struct {
+	// in: body
+	Body []timeregions.Calendar `json:"body"`
+}
+
+// swagger:response getTimeRegionCalendarResponse
+type GetTimeRegionCalendarResponse struct {
+	// in: body
+	Body timeregions.Calendar `json:"body"`
+}
+
+// swagger:response getTimeRegionCalendarWindowsResponse
+type GetTimeRegionCalendarWindowsResponse struct {
+	// in: body
+	Body []timeregions.Window `json:"body"`
+}
+
+// swagger:response getTimeRegionCalendarActiveResponse
+type GetTimeRegionCalendarActiveResponse struct {
+	// in: body
+	Body struct {
+		Active bool `json:"active"`
+	} `json:"body"`
+}
@@ -17,10 +17,13 @@ import "github.com/grafana/grafana/pkg/api/dtos"
 //
 // Omitting a key (`theme`, `homeDashboardId`, `timezone`) will cause the current value to be replaced with the system default value.
 //
+// Send the `version` returned with the preferences to not overwrite changes made since they were read.
+//
 // Responses:
 // 200: okResponse
 // 400: badRequestError
 // 401: unauthorisedError
+// 412: preconditionFailedError
 // 500: internalServerError
 
 // swagger:route PATCH /user/preferences user_preferences patchUserPreferences
@@ -31,6 +34,7 @@ import "github.com/grafana/grafana/pkg/api/dtos"
 // 200: okResponse
 // 400: badRequestError
 // 401: unauthorisedError
+// 412: preconditionFailedError
 // 500: internalServerError
 
 // swagger:parameters updateUserPreferences updateOrgPreferences updateTeamPreferences
@@ -3,6 +3,7 @@ package dtos
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -32,6 +33,7 @@ type DashboardMeta struct {
 	FolderUrl              string                `json:"folderUrl"`
 	Provisioned            bool                  `json:"provisioned"`
 	ProvisionedExternalId  string                `json:"provisionedExternalId"`
+	ManagedBy              string                `json:"managedBy,omitempty"`
 	AnnotationsPermissions *AnnotationPermission `json:"annotationsPermissions"`
 	IsPublic               bool                  `json:"isPublic"`
 	PublicDashboardUid     string                `json:"publicDashboardUid"`
@@ -73,6 +75,12 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+type DashboardVersionPatch struct {
+	BaseVersion int                        `json:"baseVersion"`
+	NewVersion  int                        `json:"newVersion"`
+	Patch       []dashdiffs.PatchOperation `json:"patch"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
@@ -0,0 +1,25 @@
+package dtos
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+)
+
+// swagger:model
+type UpdateDashboardTemplateCmd struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Inputs      []dashboardtemplates.Input `json:"inputs"`
+	Dashboard   *simplejson.Json           `json:"dashboard"`
+}
+
+// swagger:model
+type InstantiateDashboardTemplateCmd struct {
+	// Inputs are the values of the inputs of the template by name, the inputs
+	// that aren't set get their default value.
+	Inputs    map[string]string `json:"inputs"`
+	FolderUid string            `json:"folderUid"`
+	// Title replaces the title of the dashboard of the template.
+	Title     string `json:"title"`
+	Overwrite bool   `json:"overwrite"`
+}
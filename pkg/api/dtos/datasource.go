@@ -27,6 +27,7 @@ type DataSource struct {
 	SecureJsonFields map[string]bool        `json:"secureJsonFields"`
 	Version          int                    `json:"version"`
 	ReadOnly         bool                   `json:"readOnly"`
+	Origin           models.DsOrigin        `json:"origin,omitempty"`
 	AccessControl    accesscontrol.Metadata `json:"accessControl,omitempty"`
 }
 
@@ -46,6 +47,7 @@ type DataSourceListItemDTO struct {
 	IsDefault   bool             `json:"isDefault"`
 	JsonData    *simplejson.Json `json:"jsonData,omitempty"`
 	ReadOnly    bool             `json:"readOnly"`
+	Origin      models.DsOrigin  `json:"origin,omitempty"`
 }
 
 type DataSourceList []DataSourceListItemDTO
@@ -61,3 +63,13 @@ func (slice DataSourceList) Less(i, j int) bool {
 func (slice DataSourceList) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
+
+// SetDataSourceLockCmd is the body of the requests that lock or unlock a data source.
+type SetDataSourceLockCmd struct {
+	Reason string `json:"reason"`
+}
+
+// DataSourceOrder is the order of the data sources in the data source pickers.
+type DataSourceOrder struct {
+	Groups []*models.DataSourceGroup `json:"groups"`
+}
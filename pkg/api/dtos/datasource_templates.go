@@ -0,0 +1,20 @@
+package dtos
+
+import (
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+)
+
+// swagger:model
+type UpdateDataSourceTemplateCmd struct {
+	Name       string                         `json:"name"`
+	Definition datasourcetemplates.Definition `json:"definition"`
+	Instances  []datasourcetemplates.Instance `json:"instances"`
+	// SecureJsonData are the secure settings of the data sources the update
+	// creates, the data sources that exist already keep theirs.
+	SecureJsonData map[string]string `json:"secureJsonData,omitempty"`
+}
+
+// swagger:model
+type RotateDataSourceTemplateCredentialsCmd struct {
+	SecureJsonData map[string]string `json:"secureJsonData"`
+}
@@ -0,0 +1,45 @@
+package dtos
+
+import (
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+)
+
+type EmailTemplates struct {
+	// Variables all the email templates have.
+	Variables []emailtemplates.Variable `json:"variables"`
+	Templates []EmailTemplate           `json:"templates"`
+}
+
+type EmailTemplate struct {
+	emailtemplates.Definition
+	// Custom is the template the organization uses instead of the default template.
+	Custom *emailtemplates.EmailTemplate `json:"custom,omitempty"`
+}
+
+// swagger:model
+type UpdateEmailTemplateCmd struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// swagger:model
+type PreviewEmailTemplateCmd struct {
+	// Draft is rendered instead of the template of the organization.
+	Draft *UpdateEmailTemplateCmd `json:"draft,omitempty"`
+	// Data overrides the example data of the template.
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// swagger:model
+type TestEmailTemplateCmd struct {
+	PreviewEmailTemplateCmd
+	// The recipients of the email, the signed in user by default.
+	To []string `json:"to,omitempty"`
+}
+
+type EmailTemplatePreview struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
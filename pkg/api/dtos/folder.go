@@ -17,6 +17,16 @@ type Folder struct {
 	UpdatedBy string    `json:"updatedBy"`
 	Updated   time.Time `json:"updated"`
 	Version   int       `json:"version"`
+	// Parents are the folders containing the folder that the user can view,
+	// from the folder in the root to its parent.
+	Parents []FolderBreadcrumb `json:"parents"`
+}
+
+type FolderBreadcrumb struct {
+	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
 }
 
 type FolderSearchHit struct {
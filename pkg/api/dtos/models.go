@@ -8,8 +8,10 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr/transformations"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -27,25 +29,30 @@ type LoginCommand struct {
 }
 
 type CurrentUser struct {
-	IsSignedIn                 bool               `json:"isSignedIn"`
-	Id                         int64              `json:"id"`
-	ExternalUserId             string             `json:"externalUserId"`
-	Login                      string             `json:"login"`
-	Email                      string             `json:"email"`
-	Name                       string             `json:"name"`
-	LightTheme                 bool               `json:"lightTheme"`
-	OrgCount                   int                `json:"orgCount"`
-	OrgId                      int64              `json:"orgId"`
-	OrgName                    string             `json:"orgName"`
-	OrgRole                    models.RoleType    `json:"orgRole"`
-	IsGrafanaAdmin             bool               `json:"isGrafanaAdmin"`
-	GravatarUrl                string             `json:"gravatarUrl"`
-	Timezone                   string             `json:"timezone"`
-	WeekStart                  string             `json:"weekStart"`
-	Locale                     string             `json:"locale"`
-	HelpFlags1                 models.HelpFlags1  `json:"helpFlags1"`
-	HasEditPermissionInFolders bool               `json:"hasEditPermissionInFolders"`
-	Permissions                UserPermissionsMap `json:"permissions,omitempty"`
+	IsSignedIn        bool                   `json:"isSignedIn"`
+	Id                int64                  `json:"id"`
+	ExternalUserId    string                 `json:"externalUserId"`
+	Login             string                 `json:"login"`
+	Email             string                 `json:"email"`
+	Name              string                 `json:"name"`
+	LightTheme        bool                   `json:"lightTheme"`
+	OrgCount          int                    `json:"orgCount"`
+	OrgId             int64                  `json:"orgId"`
+	OrgName           string                 `json:"orgName"`
+	OrgRole           models.RoleType        `json:"orgRole"`
+	IsGrafanaAdmin    bool                   `json:"isGrafanaAdmin"`
+	GravatarUrl       string                 `json:"gravatarUrl"`
+	Timezone          string                 `json:"timezone"`
+	WeekStart         string                 `json:"weekStart"`
+	Locale            string                 `json:"locale"`
+	CustomPreferences map[string]interface{} `json:"customPreferences,omitempty"`
+	// PreferenceSources are the levels the theme, timezone, week start and the
+	// other preferences of the user are set on, e.g. "org" when the user gets the
+	// one of their organization.
+	PreferenceSources          map[string]pref.PreferenceSource `json:"preferenceSources,omitempty"`
+	HelpFlags1                 models.HelpFlags1                `json:"helpFlags1"`
+	HasEditPermissionInFolders bool                             `json:"hasEditPermissionInFolders"`
+	Permissions                UserPermissionsMap               `json:"permissions,omitempty"`
 }
 
 type UserPermissionsMap map[string]bool
@@ -69,6 +76,10 @@ type MetricRequest struct {
 	Queries []*simplejson.Json `json:"queries"`
 	// required: false
 	Debug bool `json:"debug"`
+	// Transformations to run on the query results before they are returned, in the same format as panel transformations.
+	// Supported transformations are merge, organize and filterByValue.
+	// required: false
+	Transformations []transformations.Transformation `json:"transformations,omitempty"`
 
 	HTTPRequest *http.Request `json:"-"`
 }
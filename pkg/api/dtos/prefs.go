@@ -13,6 +13,11 @@ type Prefs struct {
 	Locale           string                      `json:"locale"`
 	Navbar           pref.NavbarPreference       `json:"navbar,omitempty"`
 	QueryHistory     pref.QueryHistoryPreference `json:"queryHistory,omitempty"`
+	// The uid of the data source used instead of the default data source of the organization
+	DefaultDatasourceUID string                 `json:"defaultDatasourceUid,omitempty"`
+	Custom               map[string]interface{} `json:"custom,omitempty"`
+	// The version to send back when updating the preferences, to not overwrite changes made since
+	Version int `json:"version"`
 }
 
 // swagger:model
@@ -29,6 +34,12 @@ type UpdatePrefsCmd struct {
 	Navbar       *pref.NavbarPreference       `json:"navbar,omitempty"`
 	QueryHistory *pref.QueryHistoryPreference `json:"queryHistory,omitempty"`
 	Locale       string                       `json:"locale"`
+	// The uid of the data source used instead of the default data source of the organization
+	DefaultDatasourceUID string `json:"defaultDatasourceUid,omitempty"`
+	// Custom replaces the custom preferences of plugins and frontend features, they are kept when it is not set
+	Custom map[string]interface{} `json:"custom,omitempty"`
+	// The version of the preferences that are updated, the update fails with 412 if they have been changed since
+	Version *int `json:"version,omitempty"`
 }
 
 // swagger:model
@@ -45,4 +56,14 @@ type PatchPrefsCmd struct {
 	Navbar           *pref.NavbarPreference       `json:"navbar,omitempty"`
 	QueryHistory     *pref.QueryHistoryPreference `json:"queryHistory,omitempty"`
 	HomeDashboardUID *string                      `json:"homeDashboardUID,omitempty"`
+	// The uid of the data source used instead of the default data source of the organization, empty resets it
+	DefaultDatasourceUID *string `json:"defaultDatasourceUid,omitempty"`
+	// Custom is merged key by key into the custom preferences, keys set to null are removed
+	Custom map[string]interface{} `json:"custom,omitempty"`
+}
+
+// swagger:model
+type SetTeamHomeDashboardCmd struct {
+	// The uid of a dashboard the team can view, empty resets the home dashboard of the team
+	HomeDashboardUID string `json:"homeDashboardUID"`
 }
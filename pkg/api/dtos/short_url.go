@@ -1,10 +1,41 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
-	UID string `json:"uid"`
-	URL string `json:"url"`
+	UID       string     `json:"uid"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// Slug is an optional custom uid, e.g. a vanity name for the link
+	Slug string `json:"slug"`
+	// ExpiresAt is optional, the short URL stops redirecting after it
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type ShortURLDetails struct {
+	UID        string     `json:"uid"`
+	URL        string     `json:"url"`
+	Path       string     `json:"path"`
+	CreatedBy  int64      `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Expired    bool       `json:"expired"`
+	Hits       int64      `json:"hits"`
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64             `json:"totalCount"`
+	ShortURLs  []ShortURLDetails `json:"shortUrls"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"perPage"`
+}
+
+type PruneShortURLsCmd struct {
+	// NotSeenFor is optional, e.g. 90d, short URLs not visited for this long are deleted together with the expired ones
+	NotSeenFor string `json:"notSeenFor"`
 }
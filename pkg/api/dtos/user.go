@@ -44,3 +44,22 @@ type UserLookupDTO struct {
 	Login     string `json:"login"`
 	AvatarURL string `json:"avatarUrl"`
 }
+
+// UserStars holds the uids of the items starred by the signed in user, grouped by kind.
+type UserStars struct {
+	Dashboards  []string `json:"dashboards"`
+	Folders     []string `json:"folders"`
+	DataSources []string `json:"datasources"`
+	Queries     []string `json:"queries"`
+}
+
+type InboxUnreadCount struct {
+	Count int64 `json:"count"`
+}
+
+type MarkInboxNotificationsReadCmd struct {
+	// IDs of the notifications to update, all notifications of the user if empty.
+	IDs []int64 `json:"ids"`
+	// Read marks the notifications as read, or as unread if false. Defaults to true.
+	Read *bool `json:"read"`
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/org/egress-policy
+func (hs *HTTPServer) GetOrgEgressPolicy(c *models.ReqContext) response.Response {
+	result, err := hs.egressPolicy.Get(c.Req.Context(), &egresspolicy.GetEgressPolicyQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get egress policy", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/org/egress-policy
+func (hs *HTTPServer) UpdateOrgEgressPolicy(c *models.ReqContext) response.Response {
+	cmd := egresspolicy.SaveEgressPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd.Policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+
+	if err := hs.egressPolicy.Save(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, egresspolicy.ErrInvalidRule) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save egress policy", err)
+	}
+
+	return response.Success("Egress policy updated")
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+	"github.com/grafana/grafana/pkg/services/egresspolicy/egresspolicytest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPIUpdateOrgEgressPolicy(t *testing.T) {
+	svc := egresspolicytest.NewEgressPolicyServiceFake()
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.egressPolicy = svc
+	})
+
+	send := func() *http.Response {
+		req := server.NewRequest(http.MethodPut, "/api/org/egress-policy", strings.NewReader(`{"allowedHosts": ["*.example.com"], "deniedHosts": ["10.0.0.0/8"]}`))
+		webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 2, OrgRole: models.ROLE_ADMIN})
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	resp := send()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int64(2), svc.SavedCommand.OrgID)
+	require.Equal(t, []string{"*.example.com"}, svc.SavedCommand.Policy.AllowedHosts)
+	require.Equal(t, []string{"10.0.0.0/8"}, svc.SavedCommand.Policy.DeniedHosts)
+
+	svc.ExpectedError = fmt.Errorf("%w: bad CIDR", egresspolicy.ErrInvalidRule)
+	resp = send()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
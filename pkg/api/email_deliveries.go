@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxBounceNotificationSize limits the body of the notifications of the mail
+// providers, the webhook doesn't require authentication.
+const maxBounceNotificationSize = 1 << 20
+
+// AdminGetEmailDeliveries returns the last deliveries of the delivery log.
+// GET /api/admin/email/deliveries
+func (hs *HTTPServer) AdminGetEmailDeliveries(c *models.ReqContext) response.Response {
+	deliveries, err := hs.emailDeliveries.Search(c.Req.Context(), &emaildelivery.SearchQuery{
+		Status:    emaildelivery.Status(c.Query("status")),
+		Recipient: c.Query("recipient"),
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get email deliveries", err)
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// PostEmailBounces receives the bounce notifications of a mail provider and
+// marks the bounced deliveries in the delivery log.
+// POST /api/email/bounces/:provider
+func (hs *HTTPServer) PostEmailBounces(c *models.ReqContext) response.Response {
+	token := hs.Cfg.Smtp.BounceWebhookToken
+	if token == "" {
+		return response.Error(http.StatusNotFound, "Not found", nil)
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(token)) != 1 {
+		return response.Error(http.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxBounceNotificationSize))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read notification", err)
+	}
+	bounces, err := notifications.ParseBounces(c.Req.Context(), hs.Cfg.Smtp, web.Params(c.Req)[":provider"], body)
+	if err != nil {
+		if errors.Is(err, emaildelivery.ErrUnknownProvider) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	var bounced int64
+	for _, bounce := range bounces {
+		count, err := hs.emailDeliveries.Bounce(c.Req.Context(), bounce)
+		if err != nil && !errors.Is(err, emaildelivery.ErrInvalidBounce) {
+			return response.Error(http.StatusInternalServerError, "Failed to record bounce", err)
+		}
+		bounced += count
+	}
+	return response.JSON(http.StatusOK, map[string]interface{}{"bounced": bounced})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/emaildelivery/emaildeliverytest"
+)
+
+func TestAPIEndpoint_EmailDeliveries(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+	deliveries := emaildeliverytest.NewEmailDeliveryServiceFake()
+	sc.hs.emailDeliveries = deliveries
+
+	t.Run("Org admins can't read the delivery log", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodGet, "/api/admin/email/deliveries", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Server admins read the delivery log", func(t *testing.T) {
+		sc.initCtx.SignedInUser.IsGrafanaAdmin = true
+		t.Cleanup(func() { sc.initCtx.SignedInUser.IsGrafanaAdmin = false })
+		deliveries.ExpectedDeliveries = []*emaildelivery.Delivery{{ID: 1, Provider: "ses", Status: emaildelivery.StatusBounced}}
+
+		response := callAPI(sc.server, http.MethodGet, "/api/admin/email/deliveries?status=bounced", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result []emaildelivery.Delivery
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		require.Len(t, result, 1)
+		assert.Equal(t, "ses", result[0].Provider)
+	})
+
+	bounce := `[{"email":"a@example.com","event":"bounce","sg_message_id":"id1.filter0001"}]`
+
+	t.Run("The bounce webhook is disabled without token", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPost, "/api/email/bounces/sendgrid?token=", strings.NewReader(bounce), t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	sc.hs.Cfg.Smtp.BounceWebhookToken = "secret"
+
+	t.Run("The bounce webhook requires the token", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPost, "/api/email/bounces/sendgrid?token=guess", strings.NewReader(bounce), t)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		response = callAPI(sc.server, http.MethodPost, "/api/email/bounces/sendgrid", strings.NewReader(bounce), t)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Empty(t, deliveries.Bounced)
+	})
+
+	t.Run("The bounce webhook records the bounces", func(t *testing.T) {
+		deliveries.ExpectedBounced = 1
+		response := callAPI(sc.server, http.MethodPost, "/api/email/bounces/sendgrid?token=secret", strings.NewReader(bounce), t)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Len(t, deliveries.Bounced, 1)
+		assert.Equal(t, "id1", deliveries.Bounced[0].MessageID)
+		assert.JSONEq(t, `{"bounced":1}`, response.Body.String())
+	})
+
+	t.Run("The bounce webhook rejects unknown providers", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPost, "/api/email/bounces/postmark?token=secret", strings.NewReader(bounce), t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
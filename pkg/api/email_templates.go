@@ -0,0 +1,192 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/org/email-templates
+func (hs *HTTPServer) GetOrgEmailTemplates(c *models.ReqContext) response.Response {
+	saved, err := hs.emailTemplatesService.List(c.Req.Context(), &emailtemplates.ListEmailTemplatesQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get email templates", err)
+	}
+
+	customs := make(map[string]*emailtemplates.EmailTemplate, len(saved))
+	for _, tmpl := range saved {
+		customs[tmpl.Name] = tmpl
+	}
+
+	result := dtos.EmailTemplates{
+		Variables: emailtemplates.CommonVariables(),
+		Templates: make([]dtos.EmailTemplate, 0, len(emailtemplates.Definitions())),
+	}
+	for _, definition := range emailtemplates.Definitions() {
+		result.Templates = append(result.Templates, dtos.EmailTemplate{
+			Definition: definition,
+			Custom:     customs[definition.Name],
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// GET /api/org/email-templates/:name
+func (hs *HTTPServer) GetOrgEmailTemplate(c *models.ReqContext) response.Response {
+	name := web.Params(c.Req)[":name"]
+	definition, ok := emailtemplates.GetDefinition(name)
+	if !ok {
+		return response.Error(http.StatusNotFound, emailtemplates.ErrUnknownEmailTemplate.Error(), nil)
+	}
+
+	result := dtos.EmailTemplate{Definition: definition}
+	custom, err := hs.emailTemplatesService.Get(c.Req.Context(), &emailtemplates.GetEmailTemplateQuery{OrgID: c.OrgId, Name: name})
+	if err != nil && !errors.Is(err, emailtemplates.ErrEmailTemplateNotFound) {
+		return response.Error(http.StatusInternalServerError, "Failed to get email template", err)
+	}
+	result.Custom = custom
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/org/email-templates/:name
+func (hs *HTTPServer) UpdateOrgEmailTemplate(c *models.ReqContext) response.Response {
+	form := dtos.UpdateEmailTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	name := web.Params(c.Req)[":name"]
+	if _, ok := emailtemplates.GetDefinition(name); !ok {
+		return response.Error(http.StatusNotFound, emailtemplates.ErrUnknownEmailTemplate.Error(), nil)
+	}
+
+	cmd := emailtemplates.SaveEmailTemplateCommand{
+		OrgID: c.OrgId,
+		Template: emailtemplates.EmailTemplate{
+			Name:    name,
+			Subject: form.Subject,
+			HTML:    form.HTML,
+			Text:    form.Text,
+		},
+	}
+	if err := cmd.Template.Validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	// templates that fail to render with the example data would fail to render
+	// the emails too, so they are rejected before they are saved
+	if _, err := hs.NotificationService.RenderEmail(c.Req.Context(), &notifications.RenderEmailCommand{
+		OrgId:    c.OrgId,
+		Template: name,
+		Draft:    &cmd.Template,
+	}); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to render email template: "+err.Error(), err)
+	}
+
+	if err := hs.emailTemplatesService.Save(c.Req.Context(), &cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save email template", err)
+	}
+
+	return response.Success("Email template updated")
+}
+
+// DELETE /api/org/email-templates/:name
+func (hs *HTTPServer) DeleteOrgEmailTemplate(c *models.ReqContext) response.Response {
+	name := web.Params(c.Req)[":name"]
+	if _, ok := emailtemplates.GetDefinition(name); !ok {
+		return response.Error(http.StatusNotFound, emailtemplates.ErrUnknownEmailTemplate.Error(), nil)
+	}
+
+	if err := hs.emailTemplatesService.Delete(c.Req.Context(), &emailtemplates.DeleteEmailTemplateCommand{OrgID: c.OrgId, Name: name}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset email template", err)
+	}
+
+	return response.Success("Email template reset")
+}
+
+// POST /api/org/email-templates/:name/preview
+func (hs *HTTPServer) PreviewOrgEmailTemplate(c *models.ReqContext) response.Response {
+	form := dtos.PreviewEmailTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	message, err := hs.NotificationService.RenderEmail(c.Req.Context(), renderEmailCommand(c, form))
+	if err != nil {
+		return emailTemplateRenderError(err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.EmailTemplatePreview{
+		Subject: message.Subject,
+		HTML:    message.Body["text/html"],
+		Text:    message.Body["text/plain"],
+	})
+}
+
+// POST /api/org/email-templates/:name/test
+func (hs *HTTPServer) SendTestOrgEmailTemplate(c *models.ReqContext) response.Response {
+	form := dtos.TestEmailTemplateCmd{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	to := form.To
+	if len(to) == 0 {
+		to = []string{c.Email}
+	}
+	for _, address := range to {
+		if !util.IsEmail(address) {
+			return response.Error(http.StatusBadRequest, "Invalid email address: "+address, nil)
+		}
+	}
+
+	err := hs.NotificationService.SendTestEmail(c.Req.Context(), &notifications.SendTestEmailCommand{
+		RenderEmailCommand: *renderEmailCommand(c, form.PreviewEmailTemplateCmd),
+		To:                 to,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrSmtpNotEnabled) {
+			return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+		}
+		return emailTemplateRenderError(err)
+	}
+
+	return response.Success("Test email sent")
+}
+
+func renderEmailCommand(c *models.ReqContext, form dtos.PreviewEmailTemplateCmd) *notifications.RenderEmailCommand {
+	name := web.Params(c.Req)[":name"]
+	cmd := &notifications.RenderEmailCommand{
+		OrgId:    c.OrgId,
+		Template: name,
+		Data:     form.Data,
+	}
+	if form.Draft != nil {
+		cmd.Draft = &emailtemplates.EmailTemplate{
+			Name:    name,
+			Subject: form.Draft.Subject,
+			HTML:    form.Draft.HTML,
+			Text:    form.Draft.Text,
+		}
+	}
+	return cmd
+}
+
+func emailTemplateRenderError(err error) response.Response {
+	if errors.Is(err, emailtemplates.ErrUnknownEmailTemplate) {
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	}
+	if errors.Is(err, notifications.ErrInvalidEmailTemplate) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to render email template", err)
+}
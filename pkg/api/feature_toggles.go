@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/featureoverrides"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminGetFeatureToggles lists the feature toggles with their descriptions, stages and values.
+// GET /api/admin/feature-toggles
+func (hs *HTTPServer) AdminGetFeatureToggles(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.featureOverrides.List(c.Req.Context()))
+}
+
+// AdminUpdateFeatureToggles changes feature toggles at runtime, instance wide or per organization.
+// PUT /api/admin/feature-toggles
+func (hs *HTTPServer) AdminUpdateFeatureToggles(c *models.ReqContext) response.Response {
+	cmd := featureoverrides.UpdateTogglesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.featureOverrides.Update(c.Req.Context(), &cmd); err != nil {
+		switch {
+		case errors.Is(err, featureoverrides.ErrUnknownFeatureToggle), errors.Is(err, featureoverrides.ErrFeatureToggleNeedsRestart),
+			errors.Is(err, featureoverrides.ErrOrgOverrideNotSupported), errors.Is(err, featureoverrides.ErrFeatureToggleNameRequired):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update feature toggles", err)
+	}
+	return response.JSON(http.StatusOK, hs.featureOverrides.List(c.Req.Context()))
+}
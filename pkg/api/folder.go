@@ -1,7 +1,6 @@
 package api
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -45,7 +44,7 @@ func (hs *HTTPServer) GetFolderByUID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, folder))
 }
 
 func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
@@ -59,7 +58,7 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, folder))
 }
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext) response.Response {
@@ -67,7 +66,7 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext) response.Response {
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
-	folder, err := hs.folderService.CreateFolder(c.Req.Context(), c.SignedInUser, c.OrgId, cmd.Title, cmd.Uid)
+	folder, err := hs.folderService.CreateFolder(c.Req.Context(), c.SignedInUser, c.OrgId, cmd.Title, cmd.Uid, cmd.ParentUid)
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
@@ -81,7 +80,7 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.toFolderDto(c.Req.Context(), g, folder))
+	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, folder))
 }
 
 func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
@@ -103,19 +102,31 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), cmd.Result.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.toFolderDto(c.Req.Context(), g, cmd.Result))
+	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, cmd.Result))
 }
 
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
-	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	uid := web.Params(c.Req)[":uid"]
+	subfolders, err := hs.folderService.GetFolderDescendants(c.Req.Context(), c.SignedInUser, c.OrgId, uid)
 	if err != nil {
-		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
-			return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
-		}
 		return apierrors.ToFolderErrorResponse(err)
 	}
 
-	uid := web.Params(c.Req)[":uid"]
+	// the subfolders are deleted with the folder
+	folderUIDs := []string{uid}
+	for _, subfolder := range subfolders {
+		folderUIDs = append(folderUIDs, subfolder.Uid)
+	}
+	for _, folderUID := range folderUIDs {
+		err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, folderUID)
+		if err != nil {
+			if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
+				return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
+			}
+			return apierrors.ToFolderErrorResponse(err)
+		}
+	}
+
 	f, err := hs.folderService.DeleteFolder(c.Req.Context(), c.SignedInUser, c.OrgId, uid, c.QueryBool("forceDeleteRules"))
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
@@ -136,7 +147,8 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	})
 }
 
-func (hs *HTTPServer) toFolderDto(ctx context.Context, g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
+func (hs *HTTPServer) toFolderDto(c *models.ReqContext, g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
+	ctx := c.Req.Context()
 	canEdit, _ := g.CanEdit()
 	canSave, _ := g.CanSave()
 	canAdmin, _ := g.CanAdmin()
@@ -151,6 +163,17 @@ func (hs *HTTPServer) toFolderDto(ctx context.Context, g guardian.DashboardGuard
 		updater = hs.getUserLogin(ctx, folder.UpdatedBy)
 	}
 
+	parents := make([]dtos.FolderBreadcrumb, 0)
+	if folder.ParentId > 0 {
+		folders, err := hs.folderService.GetFolderParents(ctx, c.SignedInUser, c.OrgId, folder.Uid)
+		if err != nil {
+			hs.log.Warn("failed to get the parents of the folder", "uid", folder.Uid, "error", err)
+		}
+		for _, parent := range folders {
+			parents = append(parents, dtos.FolderBreadcrumb{Id: parent.Id, Uid: parent.Uid, Title: parent.Title, Url: parent.Url})
+		}
+	}
+
 	return dtos.Folder{
 		Id:        folder.Id,
 		Uid:       folder.Uid,
@@ -166,5 +189,6 @@ func (hs *HTTPServer) toFolderDto(ctx context.Context, g guardian.DashboardGuard
 		UpdatedBy: updater,
 		Updated:   folder.Updated,
 		Version:   folder.Version,
+		Parents:   parents,
 	}
 }
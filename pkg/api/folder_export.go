@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folderexport"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (hs *HTTPServer) ExportFolder(c *models.ReqContext) response.Response {
+	folder, err := hs.folderService.GetFolderByUID(c.Req.Context(), c.SignedInUser, c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	query := &folderexport.ExportFolderQuery{
+		OrgID:                c.OrgId,
+		Folder:               folder,
+		SignedInUser:         c.SignedInUser,
+		DashboardsPath:       c.Query("path"),
+		IncludeLibraryPanels: c.QueryBool("libraryPanels"),
+		IncludeAlertRules:    c.QueryBool("alertRules"),
+	}
+
+	if query.IncludeAlertRules {
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)
+		canRead, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ac.ActionAlertingRuleRead, scope))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !canRead {
+			return response.Error(http.StatusForbidden, "Permission denied to export the alert rules of the folder", nil)
+		}
+	}
+
+	// the archive is written to the response as it is generated, so errors
+	// past this point can only abort it
+	c.Resp.Header().Set("Content-Type", "application/zip")
+	c.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, folder.Uid))
+	c.Resp.WriteHeader(http.StatusOK)
+	if err := hs.folderExport.Export(c.Req.Context(), c.Resp, query); err != nil {
+		hs.log.Error("Failed to export folder", "uid", folder.Uid, "error", err)
+	}
+	return nil
+}
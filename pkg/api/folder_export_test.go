@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folderexport/folderexporttest"
+)
+
+func TestAPIEndpoint_ExportFolder(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	setInitCtxSignedInViewer(sc.initCtx)
+	folderService := dashboards.NewFakeFolderService(t)
+	folderService.On("GetFolderByUID", mock.Anything, mock.Anything, mock.Anything, "folder").Return(&models.Folder{Id: 1, Uid: "folder", Title: "Folder"}, nil)
+	sc.hs.folderService = folderService
+	folderExport := folderexporttest.NewFolderExportServiceFake()
+	folderExport.ExpectedArchive = []byte("archive")
+	sc.hs.folderExport = folderExport
+
+	folderScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID("folder")
+
+	t.Run("Returns 403 without permission to read the folder", func(t *testing.T) {
+		setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("other")}}, sc.initCtx.OrgId)
+		response := callAPI(sc.server, http.MethodGet, "/api/folders/folder/export", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Streams the archive of the folder", func(t *testing.T) {
+		setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: dashboards.ActionFoldersRead, Scope: folderScope}}, sc.initCtx.OrgId)
+		response := callAPI(sc.server, http.MethodGet, "/api/folders/folder/export", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/zip", response.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="folder.zip"`, response.Header().Get("Content-Disposition"))
+		assert.Equal(t, "archive", response.Body.String())
+	})
+
+	t.Run("Returns 403 when exporting alert rules without permission to read them", func(t *testing.T) {
+		setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: dashboards.ActionFoldersRead, Scope: folderScope}}, sc.initCtx.OrgId)
+		response := callAPI(sc.server, http.MethodGet, "/api/folders/folder/export?alertRules=true", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Exports the alert rules with permission to read them", func(t *testing.T) {
+		setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersRead, Scope: folderScope},
+			{Action: accesscontrol.ActionAlertingRuleRead, Scope: folderScope},
+		}, sc.initCtx.OrgId)
+		response := callAPI(sc.server, http.MethodGet, "/api/folders/folder/export?alertRules=true", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
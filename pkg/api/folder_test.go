@@ -29,7 +29,7 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 		}
 
 		folderResult := &models.Folder{Id: 1, Uid: "uid", Title: "Folder"}
-		folderService.On("CreateFolder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(folderResult, nil).Once()
+		folderService.On("CreateFolder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(folderResult, nil).Once()
 
 		createFolderScenario(t, "When calling POST on", "/api/folders", "/api/folders", folderService, cmd,
 			func(sc *scenarioContext) {
@@ -41,6 +41,36 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 				assert.Equal(t, int64(1), folder.Id)
 				assert.Equal(t, "uid", folder.Uid)
 				assert.Equal(t, "Folder", folder.Title)
+				assert.Empty(t, folder.Parents)
+			})
+	})
+
+	t.Run("Given a correct request for creating a subfolder", func(t *testing.T) {
+		cmd := models.CreateFolderCommand{
+			Uid:       "sub",
+			Title:     "Subfolder",
+			ParentUid: "parent",
+		}
+
+		folderResult := &models.Folder{Id: 3, Uid: "sub", Title: "Subfolder", ParentId: 2}
+		folderService.On("CreateFolder", mock.Anything, mock.Anything, mock.Anything, "Subfolder", "sub", "parent").Return(folderResult, nil).Once()
+		folderService.On("GetFolderParents", mock.Anything, mock.Anything, mock.Anything, "sub").Return([]*models.Folder{
+			{Id: 1, Uid: "root", Title: "Root", Url: "/dashboards/f/root/root"},
+			{Id: 2, Uid: "parent", Title: "Parent", Url: "/dashboards/f/parent/parent", ParentId: 1},
+		}, nil).Once()
+
+		createFolderScenario(t, "When calling POST on", "/api/folders", "/api/folders", folderService, cmd,
+			func(sc *scenarioContext) {
+				callCreateFolder(sc)
+
+				folder := dtos.Folder{}
+				err := json.NewDecoder(sc.resp.Body).Decode(&folder)
+				require.NoError(t, err)
+				assert.Equal(t, "sub", folder.Uid)
+				assert.Equal(t, []dtos.FolderBreadcrumb{
+					{Id: 1, Uid: "root", Title: "Root", Url: "/dashboards/f/root/root"},
+					{Id: 2, Uid: "parent", Title: "Parent", Url: "/dashboards/f/parent/parent"},
+				}, folder.Parents)
 			})
 	})
 
@@ -66,7 +96,7 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 		}
 
 		for _, tc := range testCases {
-			folderService.On("CreateFolder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.Error).Once()
+			folderService.On("CreateFolder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.Error).Once()
 
 			createFolderScenario(t, fmt.Sprintf("Expect '%s' error when calling POST on", tc.Error.Error()),
 				"/api/folders", "/api/folders", folderService, cmd, func(sc *scenarioContext) {
@@ -217,7 +247,7 @@ func (s *fakeFolderService) GetFolderByUID(ctx context.Context, user *models.Sig
 	return s.GetFolderByUIDResult, s.GetFolderByUIDError
 }
 
-func (s *fakeFolderService) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid string) (*models.Folder, error) {
+func (s *fakeFolderService) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error) {
 	return s.CreateFolderResult, s.CreateFolderError
 }
 
@@ -2,14 +2,19 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/services/branding"
 	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 	"github.com/grafana/grafana/pkg/util"
@@ -27,10 +32,11 @@ func (hs *HTTPServer) GetFrontendSettings(c *models.ReqContext) {
 
 // getFrontendSettingsMap returns a json object with all the settings needed for front end initialisation.
 func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]interface{}, error) {
-	enabledPlugins, err := hs.enabledPlugins(c.Req.Context(), c.OrgId)
+	orgSettings, err := hs.orgFrontendSettings(c.Req.Context(), c.OrgId)
 	if err != nil {
 		return nil, err
 	}
+	enabledPlugins := orgSettings.enabledPlugins
 
 	pluginsToPreload := make([]*plugins.PreloadPlugin, 0)
 	for _, app := range enabledPlugins[plugins.App] {
@@ -42,7 +48,7 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 		}
 	}
 
-	dataSources, err := hs.getFSDataSources(c, enabledPlugins)
+	dataSources, err := hs.getFSDataSources(c, enabledPlugins, orgSettings.dataSources)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +59,20 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 			defaultDS = n
 		}
 	}
+	// the default data source the user, their teams or organization prefer
+	// replaces the one of the organization when the user can query it
+	prefsQuery := pref.GetPreferenceWithDefaultsQuery{UserID: c.UserId, OrgID: c.OrgId, Teams: c.Teams}
+	preference, err := hs.preferenceService.GetWithDefaults(c.Req.Context(), &prefsQuery)
+	if err != nil {
+		return nil, err
+	}
+	if uid := preference.JSONData.DefaultDatasourceUID; uid != "" {
+		for n, ds := range dataSources {
+			if ds.UID == uid {
+				defaultDS = n
+			}
+		}
+	}
 
 	panels := make(map[string]plugins.PanelDTO)
 	for _, panel := range enabledPlugins[plugins.Panel] {
@@ -148,7 +168,7 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 			"edition":         hs.License.Edition(),
 			"enabledFeatures": hs.License.EnabledFeatures(),
 		},
-		"featureToggles":                   hs.Features.GetEnabled(c.Req.Context()),
+		"featureToggles":                   hs.Features.GetEnabledForOrg(c.Req.Context(), c.OrgId),
 		"rendererAvailable":                hs.RenderService.IsAvailable(),
 		"rendererVersion":                  hs.RenderService.Version(),
 		"http2Enabled":                     hs.Cfg.Protocol == setting.HTTP2Scheme,
@@ -180,6 +200,46 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 		jsonObj["dashboardPreviews"] = hs.ThumbService.GetDashboardPreviewsSetupSettings(c)
 	}
 
+	if hs.brandingService != nil {
+		orgID := c.OrgId
+		if orgID == 0 {
+			// users who are not signed in get the login branding of the
+			// organization they sign in to
+			orgID = c.QueryInt64("orgId")
+			if orgID <= 0 {
+				orgID = int64(hs.Cfg.AutoAssignOrgId)
+			}
+		}
+		orgBranding, err := hs.brandingService.Get(c.Req.Context(), &branding.GetBrandingQuery{OrgID: orgID})
+		if err != nil && !errors.Is(err, branding.ErrBrandingNotFound) {
+			return nil, err
+		}
+		if orgBranding != nil {
+			if c.OrgId == 0 {
+				orgBranding = orgBranding.LoginBranding()
+			}
+			jsonObj["branding"] = orgBranding
+		}
+	}
+
+	if hs.maintenance != nil {
+		if status := hs.maintenance.Status(); status.Enabled {
+			jsonObj["maintenance"] = status
+		}
+	}
+
+	if hs.announcements != nil && c.OrgId != 0 {
+		active, err := hs.announcements.Active(c.Req.Context(), &announcements.ActiveAnnouncementsQuery{
+			OrgID:  c.OrgId,
+			UserID: c.UserId,
+			Role:   c.OrgRole,
+		})
+		if err != nil {
+			return nil, err
+		}
+		jsonObj["announcements"] = active
+	}
+
 	if hs.Cfg.GeomapDefaultBaseLayerConfig != nil {
 		jsonObj["geomapDefaultBaseLayerConfig"] = hs.Cfg.GeomapDefaultBaseLayerConfig
 	}
@@ -190,18 +250,52 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 	return jsonObj, nil
 }
 
-func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins EnabledPlugins) (map[string]plugins.DataSourceDTO, error) {
-	orgDataSources := make([]*models.DataSource, 0)
+// orgFrontendSettings returns the enabled plugins and the data sources of the
+// organization, from the cache if possible. Users that are not part of an
+// organization get neither, as they can't access any of them.
+func (hs *HTTPServer) orgFrontendSettings(ctx context.Context, orgID int64) (*frontendSettingsCacheEntry, error) {
+	if orgID == 0 {
+		return &frontendSettingsCacheEntry{enabledPlugins: EnabledPlugins{}}, nil
+	}
 
-	if c.OrgId != 0 {
-		query := models.GetDataSourcesQuery{OrgId: c.OrgId, DataSourceLimit: hs.Cfg.DataSourceLimit}
-		err := hs.SQLStore.GetDataSources(c.Req.Context(), &query)
+	if entry, ok := hs.frontendSettingsCache.get(orgID); ok {
+		return entry, nil
+	}
 
-		if err != nil {
-			return nil, err
+	enabledPlugins, err := hs.enabledPlugins(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetDataSourcesQuery{OrgId: orgID, DataSourceLimit: hs.Cfg.DataSourceLimit}
+	if err := hs.SQLStore.GetDataSources(ctx, &query); err != nil {
+		return nil, err
+	}
+	for _, ds := range query.Result {
+		if ds.Type == models.DS_PROMETHEUS {
+			if ds.JsonData == nil {
+				ds.JsonData = simplejson.New()
+			}
+			// add unproxied server URL for link to Prometheus web UI
+			ds.JsonData.Set("directUrl", ds.Url)
 		}
+	}
 
-		filtered, err := hs.filterDatasourcesByQueryPermission(c.Req.Context(), c.SignedInUser, query.Result)
+	entry := &frontendSettingsCacheEntry{
+		enabledPlugins: enabledPlugins,
+		dataSources:    query.Result,
+	}
+	hs.frontendSettingsCache.set(orgID, entry)
+	return entry, nil
+}
+
+// getFSDataSources returns the data sources of the organization that the user
+// may query. The data sources are shared between requests and must not be modified.
+func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins EnabledPlugins, allDataSources []*models.DataSource) (map[string]plugins.DataSourceDTO, error) {
+	orgDataSources := make([]*models.DataSource, 0)
+
+	if c.OrgId != 0 && len(allDataSources) > 0 {
+		filtered, err := hs.filterDatasourcesByQueryPermission(c.Req.Context(), c.SignedInUser, allDataSources)
 		if err != nil {
 			return nil, err
 		}
@@ -211,6 +305,23 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins Enab
 
 	dataSources := make(map[string]plugins.DataSourceDTO)
 
+	type position struct {
+		group string
+		index int
+	}
+	positions := make(map[string]position)
+	if c.OrgId != 0 && len(orgDataSources) > 0 {
+		orderQuery := &models.GetDataSourceOrderQuery{OrgId: c.OrgId}
+		if err := hs.DataSourcesService.GetDataSourceOrder(c.Req.Context(), orderQuery); err != nil {
+			return nil, err
+		}
+		for _, group := range orderQuery.Result {
+			for _, uid := range group.Uids {
+				positions[uid] = position{group: group.Name, index: len(positions) + 1}
+			}
+		}
+	}
+
 	for _, ds := range orgDataSources {
 		url := ds.Url
 
@@ -226,6 +337,8 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins Enab
 			URL:       url,
 			IsDefault: ds.IsDefault,
 			Access:    string(ds.Access),
+			Group:     positions[ds.Uid].group,
+			SortIndex: positions[ds.Uid].index,
 		}
 
 		plugin, exists := enabledPlugins.Get(plugins.DataSource, ds.Type)
@@ -291,11 +404,6 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins Enab
 			dsDTO.Database = ds.Database
 		}
 
-		if ds.Type == models.DS_PROMETHEUS {
-			// add unproxied server URL for link to Prometheus web UI
-			ds.JsonData.Set("directUrl", ds.Url)
-		}
-
 		dataSources[ds.Name] = dsDTO
 	}
 
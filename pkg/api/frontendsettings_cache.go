@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// frontendSettingsCacheTTL bounds how long changes made by other Grafana
+// instances, which this instance is not notified about, take to show up.
+const frontendSettingsCacheTTL = time.Minute
+
+// frontendSettingsCache caches the parts of the frontend settings that are
+// expensive to build and the same for all users of an organization: the
+// enabled plugins and the data sources. Entries are invalidated when data
+// sources or plugin settings of the organization change. Everything specific
+// to the user, such as data source permissions, is applied per request.
+type frontendSettingsCache struct {
+	mu      sync.RWMutex
+	entries map[int64]*frontendSettingsCacheEntry
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+type frontendSettingsCacheEntry struct {
+	enabledPlugins EnabledPlugins
+	dataSources    []*models.DataSource
+	expires        time.Time
+}
+
+func newFrontendSettingsCache(bus bus.Bus) *frontendSettingsCache {
+	c := &frontendSettingsCache{
+		entries: map[int64]*frontendSettingsCacheEntry{},
+		ttl:     frontendSettingsCacheTTL,
+		now:     time.Now,
+	}
+	bus.AddEventListener(c.handleDataSourceCreated)
+	bus.AddEventListener(c.handleDataSourceUpdated)
+	bus.AddEventListener(c.handleDataSourceDeleted)
+	bus.AddEventListener(c.handlePluginStateChanged)
+	return c
+}
+
+func (c *frontendSettingsCache) get(orgID int64) (*frontendSettingsCacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[orgID]
+	if !ok || c.now().After(entry.expires) {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *frontendSettingsCache) set(orgID int64, entry *frontendSettingsCacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.expires = c.now().Add(c.ttl)
+	c.entries[orgID] = entry
+}
+
+func (c *frontendSettingsCache) invalidate(orgID int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, orgID)
+}
+
+func (c *frontendSettingsCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[int64]*frontendSettingsCacheEntry{}
+}
+
+func (c *frontendSettingsCache) handleDataSourceCreated(_ context.Context, e *events.DataSourceCreated) error {
+	c.invalidate(e.OrgID)
+	return nil
+}
+
+func (c *frontendSettingsCache) handleDataSourceUpdated(_ context.Context, e *events.DataSourceUpdated) error {
+	c.invalidate(e.OrgID)
+	return nil
+}
+
+func (c *frontendSettingsCache) handleDataSourceDeleted(_ context.Context, e *events.DataSourceDeleted) error {
+	c.invalidate(e.OrgID)
+	return nil
+}
+
+func (c *frontendSettingsCache) handlePluginStateChanged(_ context.Context, e *models.PluginStateChangedEvent) error {
+	c.invalidate(e.OrgId)
+	return nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestFrontendSettingsCache(t *testing.T) {
+	setup := func(t *testing.T) (*frontendSettingsCache, bus.Bus) {
+		tracer, err := tracing.InitializeTracerForTest()
+		require.NoError(t, err)
+		b := bus.ProvideBus(tracer)
+		c := newFrontendSettingsCache(b)
+		c.set(1, &frontendSettingsCacheEntry{})
+		c.set(2, &frontendSettingsCacheEntry{})
+		return c, b
+	}
+
+	t.Run("should return cached entries until they expire", func(t *testing.T) {
+		c, _ := setup(t)
+		_, ok := c.get(1)
+		require.True(t, ok)
+
+		c.now = func() time.Time { return time.Now().Add(2 * frontendSettingsCacheTTL) }
+		_, ok = c.get(1)
+		require.False(t, ok)
+	})
+
+	t.Run("should invalidate the organization of changed data sources", func(t *testing.T) {
+		for _, event := range []bus.Msg{
+			&events.DataSourceCreated{OrgID: 1},
+			&events.DataSourceUpdated{OrgID: 1},
+			&events.DataSourceDeleted{OrgID: 1},
+			&models.PluginStateChangedEvent{OrgId: 1},
+		} {
+			c, b := setup(t)
+			require.NoError(t, b.Publish(context.Background(), event))
+
+			_, ok := c.get(1)
+			require.False(t, ok)
+			_, ok = c.get(2)
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("should invalidate all organizations", func(t *testing.T) {
+		c, _ := setup(t)
+		c.invalidateAll()
+		_, ok := c.get(1)
+		require.False(t, ok)
+		_, ok = c.get(2)
+		require.False(t, ok)
+	})
+
+	t.Run("should not cache without a cache", func(t *testing.T) {
+		var c *frontendSettingsCache
+		c.set(1, &frontendSettingsCacheEntry{})
+		_, ok := c.get(1)
+		require.False(t, ok)
+	})
+}
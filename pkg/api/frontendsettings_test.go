@@ -8,9 +8,13 @@ import (
 	"testing"
 
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/branding/brandingtest"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings/service"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -55,6 +59,7 @@ func setupTestEnvironment(t *testing.T, cfg *setting.Cfg, features *featuremgmt.
 		grafanaUpdateChecker: &updatechecker.GrafanaService{},
 		AccessControl:        accesscontrolmock.New().WithDisabled(),
 		PluginSettings:       pluginSettings.ProvideService(sqlStore, secretsService),
+		preferenceService:    &preftest.FakePreferenceService{ExpectedPreference: &pref.Preference{JSONData: &pref.PreferenceJSONData{}}},
 	}
 
 	m := web.New()
@@ -134,3 +139,25 @@ func TestHTTPServer_GetFrontendSettings_hideVersionAnonymous(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPServer_GetFrontendSettings_loginBranding(t *testing.T) {
+	cfg := setting.NewCfg()
+	m, hs := setupTestEnvironment(t, cfg, featuremgmt.WithFeatures())
+	brandingService := brandingtest.NewBrandingServiceFake()
+	brandingService.ExpectedBranding = &branding.OrgBranding{
+		LoginTitle:      "ACME Monitoring",
+		MenuLogoURL:     "/public/acme/menu.svg",
+		EmailFooterText: "ACME Corp",
+	}
+	hs.brandingService = brandingService
+
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/frontend/settings", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got struct {
+		Branding branding.OrgBranding `json:"branding"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Equal(t, branding.OrgBranding{LoginTitle: "ACME Monitoring"}, got.Branding, "users who are not signed in only get the login branding")
+}
@@ -35,46 +35,85 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/services/adhocfilters"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/branding"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/comments"
+	"github.com/grafana/grafana/pkg/services/configdrift"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+	"github.com/grafana/grafana/pkg/services/dashboardrefs"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/datasources/tlscerts"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/featureoverrides"
+	"github.com/grafana/grafana/pkg/services/folderexport"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/inbox"
 	"github.com/grafana/grafana/pkg/services/ldap"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/loadshedding"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/maintenance"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/onboarding"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/services/ownership"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings/service"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/queryschema"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchusers"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/teamguardian"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
 	"github.com/grafana/grafana/pkg/services/thumbs"
+	"github.com/grafana/grafana/pkg/services/timeregions"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -140,6 +179,7 @@ type HTTPServer struct {
 	ldapGroups                   ldap.Groups
 	teamGuardian                 teamguardian.TeamGuardian
 	queryDataService             *query.Service
+	panelCache                   *query.PanelCache
 	serviceAccountsService       serviceaccounts.Service
 	authInfoService              login.AuthInfoService
 	authenticator                loginpkg.Authenticator
@@ -162,6 +202,46 @@ type HTTPServer struct {
 	dashboardVersionService      dashver.Service
 	starService                  star.Service
 	CoremodelRegistry            *coremodel.Registry
+	brandingService              branding.Service
+	secretsAuditService          audit.Service
+	dataSourceCertificates       *tlscerts.Service
+	securityHeaders              securityheaders.Service
+	dashboardVariables           dashboardvariables.Service
+	adHocFilters                 adhocfilters.Service
+	timeRegions                  timeregions.Service
+	inboxService                 inbox.Service
+	announcements                announcements.Service
+	maintenance                  maintenance.Service
+	featureOverrides             *featureoverrides.Service
+	onboarding                   onboarding.Service
+	ownership                    ownership.Service
+	dashboardSchema              dashboardschema.Service
+	dashboardApply               dashboardapply.Service
+	dashboardRefs                dashboardrefs.Service
+	resourceLabels               resourcelabels.Service
+	annotationSources            annotationsource.Service
+	queryPolicy                  querypolicy.Service
+	egressPolicy                 egresspolicy.Service
+	dataSourceMetadata           datasourcemetadata.Service
+	loadShedding                 loadshedding.Service
+	accessGrants                 accessgrants.Service
+	emailTemplatesService        emailtemplates.Service
+	emailDeliveries              emaildelivery.Service
+	dashboardArchive             dashboardarchive.Service
+	folderExport                 folderexport.Service
+	panelSnapshot                panelsnapshot.Service
+	orgInvites                   orginvites.Service
+	dataSourceTemplates          datasourcetemplates.Service
+	passwordPolicy               passwordpolicy.Service
+	dashboardLinks               dashboardlinks.Service
+	dashboardUsage               dashboardusage.Service
+	dashboardTemplates           dashboardtemplates.Service
+	querySchemas                 queryschema.Service
+	anonymousAccess              anonymousaccess.Service
+	dashboardCopy                dashboardcopy.Service
+	configDrift                  configdrift.Service
+	testDataRecording            testdatarecording.Service
+	frontendSettingsCache        *frontendSettingsCache
 }
 
 type ServerOptions struct {
@@ -196,6 +276,32 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	teamsPermissionsService accesscontrol.TeamPermissionsService, folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, dashboardVersionService dashver.Service,
 	starService star.Service, coremodelRegistry *coremodel.Registry, csrfService csrf.Service,
+	brandingService branding.Service, secretsAuditService audit.Service, dataSourceCertificates *tlscerts.Service,
+	securityHeaders securityheaders.Service, dashboardVariables dashboardvariables.Service, adHocFilters adhocfilters.Service,
+	timeRegions timeregions.Service, inboxService inbox.Service, announcementsService announcements.Service,
+	maintenanceService maintenance.Service, featureOverrides *featureoverrides.Service, onboardingService onboarding.Service,
+	ownershipService ownership.Service, dashboardSchema dashboardschema.Service, dashboardApply dashboardapply.Service,
+	dashboardRefs dashboardrefs.Service, resourceLabels resourcelabels.Service, annotationSources annotationsource.Service,
+	queryPolicy querypolicy.Service, dataSourceMetadata datasourcemetadata.Service, loadSheddingService loadshedding.Service,
+	accessGrants accessgrants.Service,
+	emailTemplatesService emailtemplates.Service,
+	emailDeliveries emaildelivery.Service,
+	dashboardArchive dashboardarchive.Service,
+	folderExport folderexport.Service,
+	panelSnapshot panelsnapshot.Service,
+	orgInvites orginvites.Service,
+	dataSourceTemplates datasourcetemplates.Service,
+	passwordPolicy passwordpolicy.Service,
+	dashboardLinks dashboardlinks.Service,
+	dashboardUsage dashboardusage.Service,
+	dashboardTemplates dashboardtemplates.Service,
+	querySchemas queryschema.Service,
+	anonymousAccess anonymousaccess.Service,
+	dashboardCopy dashboardcopy.Service,
+	configDrift configdrift.Service,
+	testDataRecording testdatarecording.Service,
+	egressPolicy egresspolicy.Service,
+	panelCache *query.PanelCache,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -277,6 +383,47 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		dashboardVersionService:      dashboardVersionService,
 		starService:                  starService,
 		CoremodelRegistry:            coremodelRegistry,
+		brandingService:              brandingService,
+		secretsAuditService:          secretsAuditService,
+		dataSourceCertificates:       dataSourceCertificates,
+		securityHeaders:              securityHeaders,
+		dashboardVariables:           dashboardVariables,
+		adHocFilters:                 adHocFilters,
+		timeRegions:                  timeRegions,
+		inboxService:                 inboxService,
+		announcements:                announcementsService,
+		maintenance:                  maintenanceService,
+		featureOverrides:             featureOverrides,
+		onboarding:                   onboardingService,
+		ownership:                    ownershipService,
+		dashboardSchema:              dashboardSchema,
+		dashboardApply:               dashboardApply,
+		dashboardRefs:                dashboardRefs,
+		resourceLabels:               resourceLabels,
+		annotationSources:            annotationSources,
+		queryPolicy:                  queryPolicy,
+		egressPolicy:                 egressPolicy,
+		panelCache:                   panelCache,
+		dataSourceMetadata:           dataSourceMetadata,
+		loadShedding:                 loadSheddingService,
+		accessGrants:                 accessGrants,
+		emailTemplatesService:        emailTemplatesService,
+		emailDeliveries:              emailDeliveries,
+		dashboardArchive:             dashboardArchive,
+		folderExport:                 folderExport,
+		panelSnapshot:                panelSnapshot,
+		orgInvites:                   orgInvites,
+		dataSourceTemplates:          dataSourceTemplates,
+		passwordPolicy:               passwordPolicy,
+		dashboardLinks:               dashboardLinks,
+		dashboardUsage:               dashboardUsage,
+		dashboardTemplates:           dashboardTemplates,
+		querySchemas:                 querySchemas,
+		anonymousAccess:              anonymousAccess,
+		dashboardCopy:                dashboardCopy,
+		configDrift:                  configDrift,
+		testDataRecording:            testDataRecording,
+		frontendSettingsCache:        newFrontendSettingsCache(bus),
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
@@ -500,7 +647,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.Use(middleware.Logger(hs.Cfg))
 
 	if hs.Cfg.EnableGzip {
-		m.UseMiddleware(middleware.Gziper())
+		m.UseMiddleware(middleware.Compressor(hs.Cfg))
 	}
 
 	m.Use(middleware.Recovery(hs.Cfg))
@@ -514,7 +661,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		hs.mapStatic(m, hs.Cfg.ImagesDir, "", "/public/img/attachments")
 	}
 
-	m.Use(middleware.AddDefaultResponseHeaders(hs.Cfg))
+	m.Use(middleware.AddDefaultResponseHeaders(hs.Cfg, hs.securityHeaders))
 
 	if hs.Cfg.ServeFromSubPath && hs.Cfg.AppSubURL != "" {
 		m.SetURLPrefix(hs.Cfg.AppSubURL)
@@ -530,16 +677,27 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.Use(hs.pluginMetricsEndpoint)
 
 	m.Use(hs.ContextHandler.Middleware)
+	if hs.loadShedding != nil && hs.Cfg.LoadSheddingEnabled {
+		retryAfter := int(hs.Cfg.LoadSheddingCheckInterval.Seconds())
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		m.Use(middleware.LoadShedding(hs.loadShedding, retryAfter))
+	}
 	m.Use(middleware.OrgRedirect(hs.Cfg, hs.SQLStore))
 	m.Use(accesscontrol.LoadPermissionsMiddleware(hs.AccessControl))
 
+	if hs.maintenance != nil {
+		m.Use(middleware.MaintenanceMode(hs.maintenance))
+	}
+
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
 		m.Use(middleware.ValidateHostHeader(hs.Cfg))
 	}
 
 	m.Use(middleware.HandleNoCacheHeader)
-	m.UseMiddleware(middleware.AddCSPHeader(hs.Cfg, hs.log))
+	m.UseMiddleware(middleware.AddCSPHeader(hs.Cfg, hs.securityHeaders, hs.log))
 
 	for _, mw := range hs.middlewares {
 		m.Use(mw)
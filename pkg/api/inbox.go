@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SearchInboxNotifications returns the notifications in the inbox of the signed in user, newest first.
+// GET /api/user/inbox
+func (hs *HTTPServer) SearchInboxNotifications(c *models.ReqContext) response.Response {
+	result, err := hs.inboxService.Search(c.Req.Context(), &inbox.SearchQuery{
+		OrgID:      c.OrgId,
+		UserID:     c.UserId,
+		Kind:       inbox.Kind(c.Query("kind")),
+		UnreadOnly: c.QueryBool("unreadOnly"),
+		Page:       c.QueryInt("page"),
+		Limit:      c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get notifications", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetInboxUnreadCount returns the number of unread notifications of the signed in user.
+// GET /api/user/inbox/unread-count
+func (hs *HTTPServer) GetInboxUnreadCount(c *models.ReqContext) response.Response {
+	count, err := hs.inboxService.CountUnread(c.Req.Context(), c.OrgId, c.UserId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to count unread notifications", err)
+	}
+	return response.JSON(http.StatusOK, dtos.InboxUnreadCount{Count: count})
+}
+
+// MarkInboxNotificationsRead marks notifications of the signed in user as read or unread.
+// POST /api/user/inbox/read
+func (hs *HTTPServer) MarkInboxNotificationsRead(c *models.ReqContext) response.Response {
+	cmd := dtos.MarkInboxNotificationsReadCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	read := true
+	if cmd.Read != nil {
+		read = *cmd.Read
+	}
+	err := hs.inboxService.MarkRead(c.Req.Context(), &inbox.MarkReadCommand{
+		OrgID:  c.OrgId,
+		UserID: c.UserId,
+		IDs:    cmd.IDs,
+		Read:   read,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update notifications", err)
+	}
+	return response.Success("Notifications updated")
+}
+
+// DeleteInboxNotification removes a notification from the inbox of the signed in user.
+// DELETE /api/user/inbox/:id
+func (hs *HTTPServer) DeleteInboxNotification(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	err = hs.inboxService.Delete(c.Req.Context(), &inbox.DeleteCommand{OrgID: c.OrgId, UserID: c.UserId, ID: id})
+	if err != nil {
+		if errors.Is(err, inbox.ErrNotificationNotFound) {
+			return response.Error(http.StatusNotFound, "Notification not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete notification", err)
+	}
+	return response.Success("Notification deleted")
+}
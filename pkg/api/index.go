@@ -456,6 +456,40 @@ func (hs *HTTPServer) buildStarredItemsNavLinks(c *models.ReqContext, prefs *pre
 		}
 	}
 
+	starredItems, err := hs.starService.GetItemsByUser(c.Req.Context(), &star.GetUserStarredItemsQuery{
+		UserID: c.SignedInUser.UserId,
+		OrgID:  c.OrgId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Items the user can no longer access are left out
+	for _, item := range starredItems.Items {
+		switch item.Kind {
+		case star.KindFolder:
+			folder, err := hs.folderService.GetFolderByUID(c.Req.Context(), c.SignedInUser, c.OrgId, item.UID)
+			if err == nil {
+				starredItemsChildNavs = append(starredItemsChildNavs, &dtos.NavLink{
+					Id:   folder.Uid,
+					Text: folder.Title,
+					Icon: "folder",
+					Url:  folder.Url,
+				})
+			}
+		case star.KindDataSource:
+			ds, err := hs.DataSourceCache.GetDatasourceByUID(c.Req.Context(), item.UID, c.SignedInUser, false)
+			if err == nil {
+				starredItemsChildNavs = append(starredItemsChildNavs, &dtos.NavLink{
+					Id:   ds.Uid,
+					Text: ds.Name,
+					Icon: "database",
+					Url:  hs.Cfg.AppSubURL + "/datasources/edit/" + ds.Uid,
+				})
+			}
+		}
+	}
+
 	return starredItemsChildNavs, nil
 }
 
@@ -761,11 +795,14 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 		return nil, err
 	}
 
-	// Read locale from accept-language
+	// The locale saved in the preferences takes precedence over the one of the
+	// browser read from accept-language
 	acceptLang := c.Req.Header.Get("Accept-Language")
 	locale := "en-US"
 
-	if len(acceptLang) > 0 {
+	if prefs.JSONData != nil && prefs.JSONData.Locale != "" {
+		locale = prefs.JSONData.Locale
+	} else if len(acceptLang) > 0 {
 		parts := strings.Split(acceptLang, ",")
 		locale = parts[0]
 	}
@@ -832,6 +869,11 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 		LoadingLogo:             "public/img/grafana_icon.svg",
 	}
 
+	if prefs.JSONData != nil {
+		data.User.CustomPreferences = prefs.JSONData.Custom
+	}
+	data.User.PreferenceSources = prefs.Sources
+
 	if !hs.AccessControl.IsDisabled() {
 		userPermissions, err := hs.AccessControl.GetUserPermissions(c.Req.Context(), c.SignedInUser, ac.Options{ReloadCache: false})
 		if err != nil {
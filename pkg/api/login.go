@@ -255,6 +255,12 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext) response.Response {
 		cookies.DeleteCookie(c.Resp, "redirect_to", hs.CookieOptionsFromCfg)
 	}
 
+	// users signing in with an expired Grafana password are sent to change it first
+	if authModule == "grafana" && hs.passwordPolicy.IsExpired(user) {
+		result["passwordExpired"] = true
+		result["redirectUrl"] = hs.Cfg.AppSubURL + "/profile/password"
+	}
+
 	metrics.MApiLoginPost.Inc()
 	resp = response.JSON(http.StatusOK, result)
 	return resp
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy/passwordpolicytest"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -467,6 +468,52 @@ func TestLoginPostRedirect(t *testing.T) {
 	}
 }
 
+func TestLoginPostExpiredPassword(t *testing.T) {
+	sc := setupScenarioContext(t, "/login")
+	passwordPolicy := passwordpolicytest.NewPasswordPolicyServiceFake()
+	hs := &HTTPServer{
+		log:              log.NewNopLogger(),
+		Cfg:              setting.NewCfg(),
+		HooksService:     &hooks.HooksService{},
+		License:          &licensing.OSSLicensingService{},
+		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		passwordPolicy:   passwordPolicy,
+	}
+	hs.Cfg.AppSubURL = "/grafana"
+
+	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+		c.Req.Header.Set("Content-Type", "application/json")
+		c.Req.Body = io.NopCloser(bytes.NewBufferString(`{"user":"admin","password":"admin"}`))
+		return hs.LoginPost(c)
+	})
+	sc.m.Post(sc.url, sc.defaultHandler)
+
+	testCases := []struct {
+		desc       string
+		authModule string
+		expired    bool
+		redirect   string
+	}{
+		{desc: "expired Grafana password", authModule: "grafana", expired: true, redirect: "/grafana/profile/password"},
+		{desc: "Grafana password", authModule: "grafana"},
+		{desc: "LDAP user", authModule: models.AuthModuleLDAP, expired: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			hs.authenticator = &fakeAuthenticator{&models.User{Id: 42}, tc.authModule, nil}
+			passwordPolicy.ExpectedExpired = tc.expired
+
+			sc.fakeReqNoAssertions("POST", sc.url).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, tc.redirect, respJSON.Get("redirectUrl").MustString())
+			assert.Equal(t, tc.redirect != "", respJSON.Get("passwordExpired").MustBool())
+		})
+	}
+}
+
 func TestLoginOAuthRedirect(t *testing.T) {
 	fakeSetIndexViewData(t)
 
@@ -602,6 +649,7 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 		License:          &licensing.OSSLicensingService{},
 		AuthTokenService: auth.NewFakeUserAuthTokenService(),
 		HooksService:     hookService,
+		passwordPolicy:   passwordpolicytest.NewPasswordPolicyServiceFake(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
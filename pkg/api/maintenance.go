@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminGetMaintenance returns the maintenance mode of the instance.
+// GET /api/admin/maintenance
+func (hs *HTTPServer) AdminGetMaintenance(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.maintenance.Status())
+}
+
+// AdminEnableMaintenance puts the instance into read-only mode, API requests
+// that change data are rejected until the maintenance mode is disabled.
+// POST /api/admin/maintenance
+func (hs *HTTPServer) AdminEnableMaintenance(c *models.ReqContext) response.Response {
+	cmd := maintenance.EnableCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.EnabledBy = c.Login
+
+	status, err := hs.maintenance.Enable(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to enable maintenance mode", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// AdminDisableMaintenance ends the maintenance mode.
+// DELETE /api/admin/maintenance
+func (hs *HTTPServer) AdminDisableMaintenance(c *models.ReqContext) response.Response {
+	status, err := hs.maintenance.Disable(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to disable maintenance mode", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
@@ -1,18 +1,31 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/queryschema"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -32,6 +45,13 @@ func (hs *HTTPServer) handleQueryMetricsError(err error) *response.NormalRespons
 		return response.Error(http.StatusNotFound, "Plugin not found", err)
 	}
 
+	if errors.Is(err, query.ErrQueryTimeout) {
+		return response.Error(http.StatusGatewayTimeout, "Query exceeded the data source's max query duration", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return response.Error(proxyutil.StatusClientClosedRequest, "Query cancelled", err)
+	}
+
 	return response.Error(http.StatusInternalServerError, "Query data error", err)
 }
 
@@ -45,13 +65,245 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext) response.Response {
 
 	reqDTO.HTTPRequest = c.Req
 
+	if err := hs.validatePanelVisibility(c, reqDTO); err != nil {
+		if errors.Is(err, dashboards.ErrPanelHidden) {
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to validate queries", err)
+	}
+	if err := hs.validateQueryPolicy(c, reqDTO); err != nil {
+		if errors.Is(err, querypolicy.ErrQueryNotAllowed) || errors.Is(err, querypolicy.ErrDashboardRequired) {
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to validate queries", err)
+	}
+
+	if resp, ok := hs.cachedPanelResponse(c, reqDTO); ok {
+		hs.publishDashboardQueried(c)
+		return hs.toJsonStreamingResponse(resp)
+	}
+
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipCache, reqDTO, true)
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
+	hs.publishDashboardQueried(c)
+	hs.observeQuerySchemas(c, reqDTO, resp)
 	return hs.toJsonStreamingResponse(resp)
 }
 
+// cachedPanelResponse returns the warmed up response of the queries of a
+// dashboard panel, identified by the same headers as in validateQueryPolicy.
+// Responses are warmed up for a panel of a dashboard, so they are only served
+// when the user can view the panel and query its data sources.
+func (hs *HTTPServer) cachedPanelResponse(c *models.ReqContext, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, bool) {
+	if hs.panelCache == nil || c.SkipCache {
+		return nil, false
+	}
+	dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+	dashboardUID := c.Req.Header.Get("X-Dashboard-Uid")
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	if panelID == 0 || (dashboardID == 0 && dashboardUID == "") {
+		return nil, false
+	}
+
+	dashboard, err := hs.viewablePanelDashboard(c, dashboardID, dashboardUID, panelID)
+	if err != nil || dashboard == nil {
+		return nil, false
+	}
+	resp, ok := hs.panelCache.Get(query.PanelCacheKey{
+		OrgID:            c.OrgId,
+		DashboardUID:     dashboard.Uid,
+		DashboardVersion: dashboard.Version,
+		PanelID:          panelID,
+	}, reqDTO)
+	if !ok {
+		return nil, false
+	}
+	for _, q := range reqDTO.Queries {
+		uid := q.Get("datasource").Get("uid").MustString()
+		if expr.IsDataSource(uid) {
+			continue
+		}
+		evaluator := ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid))
+		if allowed, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !allowed {
+			return nil, false
+		}
+	}
+	return resp, true
+}
+
+// publishDashboardQueried counts the queries of a dashboard panel, identified
+// by the same headers as in validateQueryPolicy.
+func (hs *HTTPServer) publishDashboardQueried(c *models.ReqContext) {
+	dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+	dashboardUID := c.Req.Header.Get("X-Dashboard-Uid")
+	if dashboardID == 0 && dashboardUID == "" {
+		return
+	}
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	hs.publishDashboardUsage(c.Req.Context(), &events.DashboardQueried{
+		Timestamp: time.Now(),
+		ID:        dashboardID,
+		UID:       dashboardUID,
+		OrgID:     c.OrgId,
+		PanelID:   panelID,
+	})
+}
+
+// observeQuerySchemas records the fields returned by the queries of a dashboard
+// panel, identified by the same headers as in validateQueryPolicy. The headers
+// are set by the client, so the schemas are only recorded when the user can view
+// the panel and the queries are saved in it. Otherwise any user could record
+// schemas, and report their drift, for dashboards they didn't query.
+func (hs *HTTPServer) observeQuerySchemas(c *models.ReqContext, reqDTO dtos.MetricRequest, resp *backend.QueryDataResponse) {
+	if hs.querySchemas == nil || hs.querySchemas.IsDisabled() {
+		return
+	}
+	dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+	dashboardUID := c.Req.Header.Get("X-Dashboard-Uid")
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	if panelID == 0 || (dashboardID == 0 && dashboardUID == "") {
+		return
+	}
+
+	dashboard, err := hs.savedPanelQueriesDashboard(c, reqDTO, dashboardID, dashboardUID, panelID)
+	if err != nil {
+		hs.log.Warn("Failed to match the queries with the saved queries of the panel", "error", err)
+		return
+	}
+	if dashboard == nil {
+		return
+	}
+	hs.querySchemas.Observe(&queryschema.ObserveCommand{
+		OrgID:        c.OrgId,
+		DashboardID:  dashboard.Id,
+		DashboardUID: dashboard.Uid,
+		PanelID:      panelID,
+		Queries:      reqDTO.Queries,
+		Response:     resp,
+	})
+}
+
+// savedPanelQueriesDashboard returns the dashboard of the panel if the user can
+// view the panel and the queries are saved in it, nil otherwise.
+func (hs *HTTPServer) savedPanelQueriesDashboard(c *models.ReqContext, reqDTO dtos.MetricRequest, dashboardID int64, dashboardUID string, panelID int64) (*models.Dashboard, error) {
+	dashboard, err := hs.viewablePanelDashboard(c, dashboardID, dashboardUID, panelID)
+	if err != nil || dashboard == nil {
+		return nil, err
+	}
+
+	matches, err := hs.queryPolicy.MatchQueries(c.Req.Context(), c.SignedInUser, &querypolicy.MatchQueriesCommand{
+		Dashboard: dashboard,
+		From:      reqDTO.From,
+		To:        reqDTO.To,
+		Queries:   reqDTO.Queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		if !match.InPanel(panelID) {
+			return nil, nil
+		}
+	}
+	return dashboard, nil
+}
+
+// viewablePanelDashboard returns the dashboard of the panel if the user can view
+// the panel, nil otherwise.
+func (hs *HTTPServer) viewablePanelDashboard(c *models.ReqContext, dashboardID int64, dashboardUID string, panelID int64) (*models.Dashboard, error) {
+	query := models.GetDashboardQuery{OrgId: c.OrgId, Id: dashboardID, Uid: dashboardUID}
+	if err := hs.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	dashboard := query.Result
+	canView, err := guardian.New(c.Req.Context(), dashboard.Id, c.OrgId, c.SignedInUser).CanView()
+	if err != nil || !canView {
+		return nil, err
+	}
+	if !dashboards.CanViewPanel(c.SignedInUser, dashboard.Data, panelID) {
+		return nil, nil
+	}
+	return dashboard, nil
+}
+
+// validateQueryPolicy enforces the query policy of the organization. The frontend
+// identifies the dashboard and panel of the queries with the X-Dashboard-Id and
+// X-Panel-Id headers, API clients can use X-Dashboard-Uid instead.
+func (hs *HTTPServer) validateQueryPolicy(c *models.ReqContext, reqDTO dtos.MetricRequest) error {
+	dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	return hs.queryPolicy.ValidateQueries(c.Req.Context(), c.SignedInUser, &querypolicy.ValidateQueriesCommand{
+		DashboardUID: c.Req.Header.Get("X-Dashboard-Uid"),
+		DashboardID:  dashboardID,
+		PanelID:      panelID,
+		From:         reqDTO.From,
+		To:           reqDTO.To,
+		Queries:      reqDTO.Queries,
+	})
+}
+
+// validatePanelVisibility rejects the queries of the panels that are hidden from
+// the user. The dashboard of the queries is identified by the same headers as for
+// the query policy. The panel header is set by the client, so the queries are
+// also matched against the queries saved in the dashboard: a query that is only
+// saved in hidden panels is rejected whatever panel it is sent for. Queries sent
+// without the dashboard headers, e.g. from Explore, aren't run for a dashboard,
+// the query policy restricting viewers to saved queries rejects them.
+func (hs *HTTPServer) validatePanelVisibility(c *models.ReqContext, reqDTO dtos.MetricRequest) error {
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+	dashboardUID := c.Req.Header.Get("X-Dashboard-Uid")
+	if (dashboardID == 0 && dashboardUID == "") || dashboards.CanViewAllPanels(c.SignedInUser) {
+		return nil
+	}
+
+	query := models.GetDashboardQuery{OrgId: c.OrgId, Id: dashboardID, Uid: dashboardUID}
+	if err := hs.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil
+		}
+		return err
+	}
+	hidden := dashboards.HiddenPanelIDs(c.SignedInUser, query.Result.Data)
+	if len(hidden) == 0 {
+		return nil
+	}
+	if hidden[panelID] {
+		return dashboards.ErrPanelHidden
+	}
+
+	matches, err := hs.queryPolicy.MatchQueries(c.Req.Context(), c.SignedInUser, &querypolicy.MatchQueriesCommand{
+		Dashboard: query.Result,
+		From:      reqDTO.From,
+		To:        reqDTO.To,
+		Queries:   reqDTO.Queries,
+	})
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if match.Other || len(match.PanelIDs) == 0 {
+			continue
+		}
+		visible := false
+		for _, id := range match.PanelIDs {
+			if !hidden[id] {
+				visible = true
+				break
+			}
+		}
+		if !visible {
+			return fmt.Errorf("%w: refId %s", dashboards.ErrPanelHidden, match.RefID)
+		}
+	}
+	return nil
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
 	if hs.Features.IsEnabled(featuremgmt.FlagDatasourceQueryMultiStatus) {
@@ -6,17 +6,30 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/web/webtest"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"golang.org/x/oauth2"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/querypolicy/querypolicytest"
+	"github.com/grafana/grafana/pkg/services/queryschema"
 )
 
 var queryDatasourceInput = `{
@@ -101,4 +114,192 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 		require.NoError(t, resp.Body.Close())
 		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
 	})
+
+	t.Run("Status code is 403 when the query policy does not allow the queries", func(t *testing.T) {
+		queryPolicy := querypolicytest.NewQueryPolicyServiceFake()
+		queryPolicy.ExpectedValidationError = querypolicy.ErrQueryNotAllowed
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.queryDataService = qds
+			hs.queryPolicy = queryPolicy
+		})
+		req := server.NewPostRequest("/api/ds/query", strings.NewReader(queryDatasourceInput))
+		webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Status code is 403 when the queries are only saved in hidden panels", func(t *testing.T) {
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = &models.Dashboard{Id: 3, Uid: "dash", Data: simplejson.NewFromAny(map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1},
+					map[string]interface{}{"id": 2, "visibility": map[string]interface{}{"roles": []interface{}{"Editor"}}},
+				},
+			})}
+		}).Return(nil)
+		queryPolicy := querypolicytest.NewQueryPolicyServiceFake()
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.queryDataService = qds
+			hs.queryPolicy = queryPolicy
+			hs.dashboardService = dashSvc
+		})
+
+		send := func(panelID string) int {
+			req := server.NewPostRequest("/api/ds/query", strings.NewReader(queryDatasourceInput))
+			req.Header.Set("X-Dashboard-Uid", "dash")
+			if panelID != "" {
+				req.Header.Set("X-Panel-Id", panelID)
+			}
+			webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+			resp, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			return resp.StatusCode
+		}
+
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", PanelIDs: []int64{1}}}
+		require.Equal(t, http.StatusForbidden, send("2"))
+		require.NotEqual(t, http.StatusForbidden, send("1"))
+
+		// the panel header is optional, and can name another panel
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", PanelIDs: []int64{2}}}
+		require.Equal(t, http.StatusForbidden, send(""))
+		require.Equal(t, http.StatusForbidden, send("1"))
+
+		// the query is also saved in a visible panel
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", PanelIDs: []int64{1, 2}}}
+		require.NotEqual(t, http.StatusForbidden, send(""))
+	})
+
+	t.Run("Schemas are only recorded for the saved queries of panels the user can view", func(t *testing.T) {
+		origNewGuardian := guardian.New
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = &models.Dashboard{Id: 3, Uid: "dash", Data: simplejson.NewFromAny(map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1},
+					map[string]interface{}{"id": 2, "visibility": map[string]interface{}{"roles": []interface{}{"Editor"}}},
+				},
+			})}
+		}).Return(nil)
+		queryPolicy := querypolicytest.NewQueryPolicyServiceFake()
+		schemas := &fakeQuerySchemaService{}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.queryDataService = qds
+			hs.queryPolicy = queryPolicy
+			hs.dashboardService = dashSvc
+			hs.querySchemas = schemas
+		})
+
+		send := func(panelID string) {
+			req := server.NewPostRequest("/api/ds/query", strings.NewReader(queryDatasourceInput))
+			req.Header.Set("X-Dashboard-Uid", "dash")
+			req.Header.Set("X-Panel-Id", panelID)
+			webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+			resp, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		}
+
+		// the query isn't saved in the panel
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", Other: true}}
+		send("1")
+		require.Empty(t, schemas.observed)
+
+		// the panel is hidden from the user
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", PanelIDs: []int64{2}}}
+		send("2")
+		require.Empty(t, schemas.observed)
+
+		queryPolicy.ExpectedMatches = []*querypolicy.QueryMatch{{RefID: "A", PanelIDs: []int64{1}}}
+		send("1")
+		require.Len(t, schemas.observed, 1)
+		require.Equal(t, int64(3), schemas.observed[0].DashboardID)
+		require.Equal(t, int64(1), schemas.observed[0].PanelID)
+	})
+}
+
+func TestAPIEndpoint_Metrics_QueryMetricsV2_panelCache(t *testing.T) {
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+	dashSvc := dashboards.NewFakeDashboardService(t)
+	dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 3, Uid: "dash", Version: 2, Data: simplejson.NewFromAny(map[string]interface{}{
+			"panels": []interface{}{map[string]interface{}{"id": 1}},
+		})}
+	}).Return(nil)
+
+	input := `{"from": "now-6h", "to": "now", "queries": [{"refId": "A", "expr": "up", "datasource": {"uid": "prom"}}]}`
+	reqDTO := dtos.MetricRequest{From: "now-6h", To: "now", Queries: []*simplejson.Json{
+		simplejson.NewFromAny(map[string]interface{}{"refId": "A", "expr": "up", "datasource": map[string]interface{}{"uid": "prom", "type": "prometheus"}}),
+	}}
+	panelCache := query.ProvidePanelCache(localcache.New(0, 0))
+	require.NoError(t, panelCache.Set(query.PanelCacheKey{OrgID: 1, DashboardUID: "dash", DashboardVersion: 2, PanelID: 1}, reqDTO, backend.NewQueryDataResponse(), time.Hour))
+
+	acMock := accesscontrolmock.New().WithDisabled()
+	acMock.EvaluateFunc = func(_ context.Context, user *models.SignedInUser, evaluator accesscontrol.Evaluator) (bool, error) {
+		return evaluator.Evaluate(user.Permissions[user.OrgId]), nil
+	}
+	// the data source doesn't exist, so the queries fail unless the response is cached
+	qds := query.ProvideService(nil, &fakeDatasources.FakeCacheService{}, nil, &fakePluginRequestValidator{}, &fakeDatasources.FakeDataSourceService{},
+		&fakePluginClient{}, &fakeOAuthTokenService{})
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.queryDataService = qds
+		hs.dashboardService = dashSvc
+		hs.panelCache = panelCache
+		hs.AccessControl = acMock
+	})
+
+	send := func(panelID string, scopes ...string) int {
+		req := server.NewPostRequest("/api/ds/query", strings.NewReader(input))
+		req.Header.Set("X-Dashboard-Uid", "dash")
+		req.Header.Set("X-Panel-Id", panelID)
+		webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER,
+			Permissions: map[int64]map[string][]string{1: {datasources.ActionQuery: scopes}}})
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, send("1", "datasources:uid:prom"))
+	// the user can't query the data source of the panel
+	require.NotEqual(t, http.StatusOK, send("1", "datasources:uid:other"))
+	// the response is cached for another panel
+	require.NotEqual(t, http.StatusOK, send("2", "datasources:uid:prom"))
+}
+
+type fakeQuerySchemaService struct {
+	observed []*queryschema.ObserveCommand
+}
+
+func (f *fakeQuerySchemaService) IsDisabled() bool {
+	return false
+}
+
+func (f *fakeQuerySchemaService) Observe(cmd *queryschema.ObserveCommand) {
+	f.observed = append(f.observed, cmd)
+}
+
+func (f *fakeQuerySchemaService) GetHistory(ctx context.Context, query *queryschema.GetHistoryQuery) ([]*queryschema.Schema, error) {
+	return nil, nil
+}
+
+func (f *fakeQuerySchemaService) GetDrift(ctx context.Context, query *queryschema.GetDriftQuery) ([]*queryschema.Drift, error) {
+	return nil, nil
 }
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/onboarding"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetOnboardingState returns the onboarding progress of the signed in user.
+// GET /api/user/onboarding
+func (hs *HTTPServer) GetOnboardingState(c *models.ReqContext) response.Response {
+	state, err := hs.onboarding.Get(c.Req.Context(), &onboarding.GetStateQuery{OrgID: c.OrgId, UserID: c.UserId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get onboarding state", err)
+	}
+	return response.JSON(http.StatusOK, state)
+}
+
+// UpdateOnboardingState adds dismissed panels, completed steps and discovered features
+// to the onboarding progress of the signed in user.
+// POST /api/user/onboarding
+func (hs *HTTPServer) UpdateOnboardingState(c *models.ReqContext) response.Response {
+	cmd := onboarding.UpdateStateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.UserID = c.UserId
+
+	state, err := hs.onboarding.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrInvalidID) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update onboarding state", err)
+	}
+	return response.JSON(http.StatusOK, state)
+}
+
+// ResetOnboardingState clears the onboarding progress of the signed in user.
+// DELETE /api/user/onboarding
+func (hs *HTTPServer) ResetOnboardingState(c *models.ReqContext) response.Response {
+	if err := hs.onboarding.Reset(c.Req.Context(), &onboarding.ResetStateCommand{OrgID: c.OrgId, UserID: c.UserId}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset onboarding state", err)
+	}
+	return response.Success("Onboarding state reset")
+}
@@ -76,6 +76,7 @@ func (hs *HTTPServer) AddOrgInvite(c *models.ReqContext) response.Response {
 		emailCmd := models.SendEmailCommand{
 			To:       []string{inviteDto.LoginOrEmail},
 			Template: "new_user_invite",
+			OrgId:    c.OrgId,
 			Data: map[string]interface{}{
 				"Name":      util.StringsFallback2(cmd.Name, cmd.Email),
 				"OrgName":   c.OrgName,
@@ -118,6 +119,7 @@ func (hs *HTTPServer) inviteExistingUserToOrg(c *models.ReqContext, user *models
 		emailCmd := models.SendEmailCommand{
 			To:       []string{user.Email},
 			Template: "invited_to_org",
+			OrgId:    c.OrgId,
 			Data: map[string]interface{}{
 				"Name":      user.NameOrFallback(),
 				"OrgName":   c.OrgName,
@@ -188,6 +190,10 @@ func (hs *HTTPServer) CompleteInvite(c *models.ReqContext) response.Response {
 		return response.Error(412, fmt.Sprintf("Invite cannot be used in status %s", invite.Status), nil)
 	}
 
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), completeInvite.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
 	cmd := models.CreateUserCommand{
 		Email:        completeInvite.Email,
 		Name:         completeInvite.Name,
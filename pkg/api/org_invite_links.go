@@ -0,0 +1,171 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetOrgInviteLinks returns the invite links of the current organization that haven't expired.
+// GET /api/org/invites/links
+func (hs *HTTPServer) GetOrgInviteLinks(c *models.ReqContext) response.Response {
+	links, err := hs.orgInvites.GetLinks(c.Req.Context(), &orginvites.GetLinksQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get invite links", err)
+	}
+	return response.JSON(http.StatusOK, links)
+}
+
+// CreateOrgInviteLink creates an invite link to the current organization.
+// POST /api/org/invites/links
+func (hs *HTTPServer) CreateOrgInviteLink(c *models.ReqContext) response.Response {
+	cmd := orginvites.CreateLinkCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.CreatedBy = c.UserId
+	if !c.OrgRole.Includes(cmd.Role) && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Cannot invite users with a role higher than your own", nil)
+	}
+
+	link, err := hs.orgInvites.CreateLink(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, orginvites.ErrInvalidRole),
+			errors.Is(err, orginvites.ErrInvalidMaxUses),
+			errors.Is(err, orginvites.ErrInvalidExpiration):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to create invite link", err)
+	}
+	return response.JSON(http.StatusOK, link)
+}
+
+// DeleteOrgInviteLink deletes an invite link of the current organization, the
+// pending access requests made with it are kept.
+// DELETE /api/org/invites/links/:uid
+func (hs *HTTPServer) DeleteOrgInviteLink(c *models.ReqContext) response.Response {
+	err := hs.orgInvites.DeleteLink(c.Req.Context(), &orginvites.DeleteLinkCommand{OrgID: c.OrgId, UID: web.Params(c.Req)[":uid"]})
+	if err != nil {
+		if errors.Is(err, orginvites.ErrLinkNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete invite link", err)
+	}
+	return response.Success("Invite link deleted")
+}
+
+// GetOrgAccessRequests returns the requests to join the current organization,
+// the most recent first.
+// GET /api/org/invites/requests
+func (hs *HTTPServer) GetOrgAccessRequests(c *models.ReqContext) response.Response {
+	requests, err := hs.orgInvites.GetRequests(c.Req.Context(), &orginvites.GetRequestsQuery{
+		OrgID:  c.OrgId,
+		Status: orginvites.RequestStatus(c.Query("status")),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get access requests", err)
+	}
+	return response.JSON(http.StatusOK, requests)
+}
+
+// ApproveOrgAccessRequest adds the user of a pending access request to the current organization.
+// POST /api/org/invites/requests/:id/approve
+func (hs *HTTPServer) ApproveOrgAccessRequest(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd := orginvites.ApproveRequestCommand{}
+	if c.Req.ContentLength != 0 {
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+	}
+	cmd.OrgID = c.OrgId
+	cmd.ID = id
+	cmd.ReviewedBy = c.UserId
+	if cmd.Role != "" && !c.OrgRole.Includes(cmd.Role) && !c.IsGrafanaAdmin {
+		return response.Error(http.StatusForbidden, "Cannot grant a role higher than your own", nil)
+	}
+
+	if err := hs.orgInvites.ApproveRequest(c.Req.Context(), &cmd); err != nil {
+		return orgAccessRequestErrorResponse(err, "Failed to approve access request")
+	}
+	return response.Success("Access request approved")
+}
+
+// RejectOrgAccessRequest rejects a pending access request to the current organization.
+// POST /api/org/invites/requests/:id/reject
+func (hs *HTTPServer) RejectOrgAccessRequest(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	err = hs.orgInvites.RejectRequest(c.Req.Context(), &orginvites.RejectRequestCommand{OrgID: c.OrgId, ID: id, ReviewedBy: c.UserId})
+	if err != nil {
+		return orgAccessRequestErrorResponse(err, "Failed to reject access request")
+	}
+	return response.Success("Access request rejected")
+}
+
+func orgAccessRequestErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, orginvites.ErrRequestNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, orginvites.ErrInvalidRole):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// GetOrgInviteLinkInfo returns the organization and role an invite link invites the signed in user to.
+// GET /api/user/invite-links/:code
+func (hs *HTTPServer) GetOrgInviteLinkInfo(c *models.ReqContext) response.Response {
+	info, err := hs.orgInvites.GetLinkInfo(c.Req.Context(), &orginvites.GetLinkInfoQuery{Code: web.Params(c.Req)[":code"]})
+	if err != nil {
+		return orgInviteLinkErrorResponse(err, "Failed to get invite link")
+	}
+	return response.JSON(http.StatusOK, info)
+}
+
+// JoinOrgWithInviteLink adds the signed in user to the organization of an
+// invite link, or requests access to it when the link requires approval.
+// POST /api/user/invite-links/:code/join
+func (hs *HTTPServer) JoinOrgWithInviteLink(c *models.ReqContext) response.Response {
+	cmd := orginvites.JoinCommand{}
+	if c.Req.ContentLength != 0 {
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+	}
+	cmd.Code = web.Params(c.Req)[":code"]
+	cmd.UserID = c.UserId
+
+	result, err := hs.orgInvites.Join(c.Req.Context(), &cmd)
+	if err != nil {
+		return orgInviteLinkErrorResponse(err, "Failed to join organization")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func orgInviteLinkErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, orginvites.ErrLinkNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, orginvites.ErrLinkExpired),
+		errors.Is(err, orginvites.ErrLinkUsedUp):
+		return response.Error(http.StatusGone, err.Error(), err)
+	case errors.Is(err, orginvites.ErrAlreadyMember),
+		errors.Is(err, orginvites.ErrRequestPending):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
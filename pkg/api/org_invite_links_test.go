@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/services/orginvites/orginvitestest"
+)
+
+func TestAPIEndpoint_OrgInviteLinks(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+	invites := orginvitestest.NewOrgInvitesServiceFake()
+	sc.hs.orgInvites = invites
+
+	t.Run("Returns the created link", func(t *testing.T) {
+		invites.ExpectedLink = &orginvites.Link{UID: "abc", Role: models.ROLE_EDITOR, MaxUses: 5, URL: "http://localhost:3000/invite-link/secret"}
+		invites.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/org/invites/links", strings.NewReader(`{"role":"Editor","maxUses":5}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var link map[string]interface{}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &link))
+		assert.Equal(t, "abc", link["uid"])
+		assert.Equal(t, "http://localhost:3000/invite-link/secret", link["url"])
+		assert.NotContains(t, link, "code")
+	})
+
+	t.Run("Returns 400 for invalid links", func(t *testing.T) {
+		invites.ExpectedError = orginvites.ErrInvalidExpiration
+		response := callAPI(sc.server, http.MethodPost, "/api/org/invites/links", strings.NewReader(`{"role":"Viewer","expiresIn":"1y"}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Returns 404 when deleting an unknown link", func(t *testing.T) {
+		invites.ExpectedError = orginvites.ErrLinkNotFound
+		response := callAPI(sc.server, http.MethodDelete, "/api/org/invites/links/unknown", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Returns the access requests", func(t *testing.T) {
+		invites.ExpectedRequests = []*orginvites.AccessRequestDTO{{ID: 1, Login: "oncall", Status: orginvites.RequestPending}}
+		invites.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodGet, "/api/org/invites/requests?status=pending", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var requests []orginvites.AccessRequestDTO
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &requests))
+		require.Len(t, requests, 1)
+		assert.Equal(t, "oncall", requests[0].Login)
+	})
+
+	t.Run("Approves requests with or without a role", func(t *testing.T) {
+		invites.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/org/invites/requests/1/approve", strings.NewReader(`{"role":"Editor"}`), t)
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = callAPI(sc.server, http.MethodPost, "/api/org/invites/requests/1/approve", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Returns 404 when reviewing requests that aren't pending", func(t *testing.T) {
+		invites.ExpectedError = orginvites.ErrRequestNotFound
+		response := callAPI(sc.server, http.MethodPost, "/api/org/invites/requests/1/reject", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Returns 410 when joining with a used up link", func(t *testing.T) {
+		invites.ExpectedError = orginvites.ErrLinkUsedUp
+		response := callAPI(sc.server, http.MethodPost, "/api/user/invite-links/secret/join", nil, t)
+		assert.Equal(t, http.StatusGone, response.Code)
+	})
+
+	t.Run("Returns the pending request when joining", func(t *testing.T) {
+		invites.ExpectedJoinResult = &orginvites.JoinResult{OrgID: 2, RequestID: 3}
+		invites.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/user/invite-links/secret/join", strings.NewReader(`{"message":"I'm on call"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result orginvites.JoinResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.False(t, result.Joined)
+		assert.Equal(t, int64(3), result.RequestID)
+	})
+
+	setInitCtxSignedInViewer(sc.initCtx)
+
+	t.Run("Viewers can't manage the links", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodGet, "/api/org/invites/links", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		response = callAPI(sc.server, http.MethodPost, "/api/org/invites/requests/1/approve", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
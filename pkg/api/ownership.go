@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/ownership"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetResourceOwnership returns the owner and links of a dashboard or an alert rule.
+// GET /api/ownership/:kind/:uid
+func (hs *HTTPServer) GetResourceOwnership(c *models.ReqContext) response.Response {
+	resource, rsp := hs.getOwnedResource(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	o, err := hs.ownership.Get(c.Req.Context(), &ownership.GetOwnershipQuery{OrgID: c.OrgId, ResourceKind: resource.Kind, ResourceUID: resource.UID})
+	if err != nil {
+		return ownershipErrorResponse(err, "Failed to get ownership")
+	}
+	return response.JSON(http.StatusOK, o)
+}
+
+// SetResourceOwnership sets the owner and links of a dashboard or an alert rule.
+// PUT /api/ownership/:kind/:uid
+func (hs *HTTPServer) SetResourceOwnership(c *models.ReqContext) response.Response {
+	cmd := ownership.SetOwnershipCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	resource, rsp := hs.getOwnedResource(c, true)
+	if rsp != nil {
+		return rsp
+	}
+	cmd.OrgID = c.OrgId
+	cmd.ResourceKind = resource.Kind
+	cmd.ResourceUID = resource.UID
+
+	o, err := hs.ownership.Set(c.Req.Context(), &cmd)
+	if err != nil {
+		return ownershipErrorResponse(err, "Failed to set ownership")
+	}
+	return response.JSON(http.StatusOK, o)
+}
+
+// DeleteResourceOwnership removes the owner and links of a dashboard or an alert rule.
+// DELETE /api/ownership/:kind/:uid
+func (hs *HTTPServer) DeleteResourceOwnership(c *models.ReqContext) response.Response {
+	resource, rsp := hs.getOwnedResource(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	err := hs.ownership.Delete(c.Req.Context(), &ownership.DeleteOwnershipCommand{OrgID: c.OrgId, ResourceKind: resource.Kind, ResourceUID: resource.UID})
+	if err != nil {
+		return ownershipErrorResponse(err, "Failed to delete ownership")
+	}
+	return response.Success("Ownership deleted")
+}
+
+// SearchOwnedResources returns the dashboards and alert rules owned by a team or a user
+// that the signed in user can view.
+// GET /api/ownership/search?teamId=1 or GET /api/ownership/search?userId=1
+func (hs *HTTPServer) SearchOwnedResources(c *models.ReqContext) response.Response {
+	query := ownership.SearchOwnedResourcesQuery{OrgID: c.OrgId}
+	switch {
+	case c.QueryInt64("teamId") > 0:
+		query.OwnerKind = ownership.OwnerKindTeam
+		query.OwnerID = c.QueryInt64("teamId")
+	case c.QueryInt64("userId") > 0:
+		query.OwnerKind = ownership.OwnerKindUser
+		query.OwnerID = c.QueryInt64("userId")
+	default:
+		return response.Error(http.StatusBadRequest, "teamId or userId is required", nil)
+	}
+
+	owned, err := hs.ownership.SearchOwned(c.Req.Context(), &query)
+	if err != nil {
+		return ownershipErrorResponse(err, "Failed to search owned resources")
+	}
+
+	result := make([]*ownership.OwnedResource, 0, len(owned))
+	for _, o := range owned {
+		g := guardian.New(c.Req.Context(), o.DashboardId, c.OrgId, c.SignedInUser)
+		if canView, err := g.CanView(); err != nil {
+			return dashboardGuardianResponse(err)
+		} else if canView {
+			result = append(result, o)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetOrphanedResources returns the dashboards and alert rules whose owner has been deleted.
+// GET /api/ownership/orphaned
+func (hs *HTTPServer) GetOrphanedResources(c *models.ReqContext) response.Response {
+	orphaned, err := hs.ownership.SearchOrphaned(c.Req.Context(), &ownership.SearchOrphanedResourcesQuery{OrgID: c.OrgId})
+	if err != nil {
+		return ownershipErrorResponse(err, "Failed to search orphaned resources")
+	}
+	return response.JSON(http.StatusOK, orphaned)
+}
+
+// getOwnedResource returns the resource of the request if the signed in user can view it, or
+// edit it if write is set. The permissions of alert rules are the ones of their folder.
+func (hs *HTTPServer) getOwnedResource(c *models.ReqContext, write bool) (*ownership.Resource, response.Response) {
+	resource, err := hs.ownership.GetResource(c.Req.Context(), &ownership.GetResourceQuery{
+		OrgID: c.OrgId,
+		Kind:  ownership.ResourceKind(web.Params(c.Req)[":kind"]),
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return nil, ownershipErrorResponse(err, "Failed to get resource")
+	}
+
+	g := guardian.New(c.Req.Context(), resource.DashboardID, c.OrgId, c.SignedInUser)
+	var allowed bool
+	if write {
+		allowed, err = g.CanEdit()
+	} else {
+		allowed, err = g.CanView()
+	}
+	if err != nil || !allowed {
+		return nil, dashboardGuardianResponse(err)
+	}
+	return resource, nil
+}
+
+func ownershipErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ownership.ErrOwnershipNotFound), errors.Is(err, ownership.ErrResourceNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ownership.ErrOwnerNotFound), errors.Is(err, ownership.ErrInvalidResourceKind),
+		errors.Is(err, ownership.ErrInvalidOwnerKind), errors.Is(err, ownership.ErrInvalidLink):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
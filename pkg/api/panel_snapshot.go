@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// CreatePanelSnapshot runs the queries of a panel and returns their results.
+// POST /api/dashboards/uid/:uid/panels/:panelId/snapshot
+func (hs *HTTPServer) CreatePanelSnapshot(c *models.ReqContext) response.Response {
+	cmd := panelsnapshot.CreatePanelSnapshotCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	panelID, err := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "panelId is invalid", err)
+	}
+	cmd.PanelID = panelID
+	cmd.SkipCache = c.SkipCache
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	snapshot, err := hs.panelSnapshot.Create(c.Req.Context(), c.SignedInUser, dash, &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboards.ErrPanelHidden):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		case errors.Is(err, panelsnapshot.ErrPanelNotFound):
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, panelsnapshot.ErrPanelWithoutQueries),
+			errors.Is(err, panelsnapshot.ErrPanelSnapshotTooLarge),
+			errors.Is(err, dashboardvariables.ErrVariableCycle),
+			errors.Is(err, dashboardvariables.ErrInvalidTemplate):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return hs.handleQueryMetricsError(err)
+	}
+
+	return response.JSON(http.StatusOK, snapshot)
+}
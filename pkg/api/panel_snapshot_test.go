@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot/panelsnapshottest"
+)
+
+func TestAPIEndpoint_CreatePanelSnapshot(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	setInitCtxSignedInViewer(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsAll}}, sc.initCtx.OrgId)
+
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 1, Uid: q.Uid, Data: simplejson.New()}
+	}).Return(nil)
+	sc.hs.dashboardService = dashboardService
+	panelSnapshot := panelsnapshottest.NewPanelSnapshotServiceFake()
+	sc.hs.panelSnapshot = panelSnapshot
+
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+	t.Run("Returns the snapshot of the panel", func(t *testing.T) {
+		panelSnapshot.ExpectedSnapshot = &panelsnapshot.PanelSnapshot{DashboardUID: "dash", PanelID: 2, Data: json.RawMessage(`{"results":{}}`), Size: 14}
+		panelSnapshot.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/panels/2/snapshot", strings.NewReader(`{"from":"now-1h","to":"now"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var snapshot panelsnapshot.PanelSnapshot
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &snapshot))
+		assert.Equal(t, "dash", snapshot.DashboardUID)
+		assert.Equal(t, int64(2), snapshot.PanelID)
+		assert.JSONEq(t, `{"results":{}}`, string(snapshot.Data))
+	})
+
+	t.Run("Returns 400 for an invalid panel id", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/panels/abc/snapshot", strings.NewReader(`{}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{err: panelsnapshot.ErrPanelNotFound, code: http.StatusNotFound},
+		{err: dashboards.ErrPanelHidden, code: http.StatusForbidden},
+		{err: panelsnapshot.ErrPanelWithoutQueries, code: http.StatusBadRequest},
+		{err: fmt.Errorf("%w: 20 bytes", panelsnapshot.ErrPanelSnapshotTooLarge), code: http.StatusBadRequest},
+		{err: models.ErrDataSourceAccessDenied, code: http.StatusForbidden},
+	} {
+		t.Run(fmt.Sprintf("Returns %d for %q", tc.code, tc.err), func(t *testing.T) {
+			panelSnapshot.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/panels/2/snapshot", strings.NewReader(`{}`), t)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+}
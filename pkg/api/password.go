@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -63,6 +64,9 @@ func (hs *HTTPServer) ResetPassword(c *models.ReqContext) response.Response {
 	if form.NewPassword != form.ConfirmPassword {
 		return response.Error(400, "Passwords do not match", nil)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), form.NewPassword); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
 
 	cmd := models.ChangeUserPasswordCommand{}
 	cmd.UserId = query.Result.Id
@@ -78,3 +82,11 @@ func (hs *HTTPServer) ResetPassword(c *models.ReqContext) response.Response {
 
 	return response.Success("User password changed")
 }
+
+// passwordPolicyErrorResponse returns the response to a password that can't be set.
+func passwordPolicyErrorResponse(err error) response.Response {
+	if errors.Is(err, passwordpolicy.ErrPasswordPolicy) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to validate password", err)
+}
@@ -2,6 +2,7 @@ package pluginproxy
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/datasource"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	glog "github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -102,7 +104,21 @@ func (proxy *DataSourceProxy) HandleRequest() {
 		return
 	}
 
+	responseLimit := proxy.responseLimit()
 	modifyResponse := func(resp *http.Response) error {
+		// Responses are streamed to the client, so only a response announcing
+		// its size can be rejected before anything has been written.
+		if responseLimit > 0 && resp.ContentLength > responseLimit {
+			metrics.MDataSourceProxyResponsesTooLarge.WithLabelValues(proxy.ds.Type, "content_length").Inc()
+			proxyErrorLogger.Warn("Data source response exceeds the response limit", "contentLength", resp.ContentLength,
+				"responseLimit", responseLimit)
+			return fmt.Errorf("%w: content length %d exceeds %d bytes", httpclient.ErrResponseBodyTooLarge,
+				resp.ContentLength, responseLimit)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			resp.Body = newResponseBody(resp.Body, responseLimit, proxy.ds.Type, proxyErrorLogger)
+		}
+
 		if resp.StatusCode == 401 {
 			// The data source rejected the request as unauthorized, convert to 400 (bad request)
 			body, err := ioutil.ReadAll(resp.Body)
@@ -135,6 +151,13 @@ func (proxy *DataSourceProxy) HandleRequest() {
 	proxy.logRequest()
 	ctx, span := proxy.tracer.Start(proxy.ctx.Req.Context(), "datasource reverse proxy")
 	defer span.End()
+	ctx = httpclient.WithEgressOrgID(ctx, proxy.ds.OrgId)
+
+	if d := proxy.ds.MaxQueryDuration(); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
 
 	proxy.ctx.Req = proxy.ctx.Req.WithContext(ctx)
 
@@ -149,6 +172,23 @@ func (proxy *DataSourceProxy) HandleRequest() {
 	proxy.tracer.Inject(ctx, proxy.ctx.Req.Header, span)
 
 	reverseProxy.ServeHTTP(proxy.ctx.Resp, proxy.ctx.Req)
+
+	switch err := ctx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		metrics.MDataSourceQueriesCancelled.WithLabelValues(proxy.ds.Type, "timeout").Inc()
+	case errors.Is(err, context.Canceled):
+		metrics.MDataSourceQueriesCancelled.WithLabelValues(proxy.ds.Type, "client").Inc()
+	}
+}
+
+// responseLimit returns the maximum size of a response of the data source,
+// the smallest of the data source and the [dataproxy] response_limit.
+func (proxy *DataSourceProxy) responseLimit() int64 {
+	limit := proxy.ds.MaxResponseSize()
+	if proxy.cfg.ResponseLimit > 0 && (limit <= 0 || proxy.cfg.ResponseLimit < limit) {
+		limit = proxy.cfg.ResponseLimit
+	}
+	return limit
 }
 
 func (proxy *DataSourceProxy) addTraceFromHeaderValue(span tracing.Span, headerName string, tagName string) {
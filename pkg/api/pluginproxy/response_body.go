@@ -0,0 +1,54 @@
+package pluginproxy
+
+import (
+	"errors"
+	"io"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	glog "github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// responseBody wraps the body of a data source response while it is streamed
+// to the client. It aborts the response once it exceeds the limit and records
+// the size of the response when it is closed.
+type responseBody struct {
+	body           io.ReadCloser
+	limit          int64
+	datasourceType string
+	logger         glog.Logger
+	size           int64
+	exceeded       bool
+	closed         bool
+}
+
+func newResponseBody(body io.ReadCloser, limit int64, datasourceType string, logger glog.Logger) io.ReadCloser {
+	if limit > 0 {
+		body = httpclient.MaxBytesReader(body, limit)
+	}
+	return &responseBody{
+		body:           body,
+		limit:          limit,
+		datasourceType: datasourceType,
+		logger:         logger,
+	}
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.size += int64(n)
+	if err != nil && !b.exceeded && errors.Is(err, httpclient.ErrResponseBodyTooLarge) {
+		b.exceeded = true
+		metrics.MDataSourceProxyResponsesTooLarge.WithLabelValues(b.datasourceType, "stream").Inc()
+		b.logger.Warn("Data source response exceeded the response limit, aborting it", "responseLimit", b.limit)
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		metrics.MDataSourceProxyResponseSize.WithLabelValues(b.datasourceType).Observe(float64(b.size))
+	}
+	return b.body.Close()
+}
@@ -382,6 +382,7 @@ func (hs *HTTPServer) InstallPlugin(c *models.ReqContext) response.Response {
 
 		return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
 	}
+	hs.frontendSettingsCache.invalidateAll()
 
 	return response.JSON(http.StatusOK, []byte{})
 }
@@ -403,6 +404,8 @@ func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 
 		return response.Error(http.StatusInternalServerError, "Failed to uninstall plugin", err)
 	}
+	hs.frontendSettingsCache.invalidateAll()
+
 	return response.JSON(http.StatusOK, []byte{})
 }
 
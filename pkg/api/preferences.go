@@ -2,12 +2,17 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"golang.org/x/text/language"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -77,12 +82,15 @@ func (hs *HTTPServer) getPreferencesFor(ctx context.Context, orgID, userID, team
 		HomeDashboardUID: dashboardUID,
 		Timezone:         preference.Timezone,
 		WeekStart:        preference.WeekStart,
+		Version:          preference.Version,
 	}
 
 	if preference.JSONData != nil {
 		dto.Locale = preference.JSONData.Locale
 		dto.Navbar = preference.JSONData.Navbar
 		dto.QueryHistory = preference.JSONData.QueryHistory
+		dto.DefaultDatasourceUID = preference.JSONData.DefaultDatasourceUID
+		dto.Custom = preference.JSONData.Custom
 	}
 
 	return response.JSON(http.StatusOK, &dto)
@@ -101,6 +109,9 @@ func (hs *HTTPServer) updatePreferencesFor(ctx context.Context, orgID, userID, t
 	if dtoCmd.Theme != lightTheme && dtoCmd.Theme != darkTheme && dtoCmd.Theme != defaultTheme {
 		return response.Error(400, "Invalid theme", nil)
 	}
+	if !isValidLocale(dtoCmd.Locale) {
+		return response.Error(400, "Invalid locale", nil)
+	}
 
 	dashboardID := dtoCmd.HomeDashboardID
 	if dtoCmd.HomeDashboardUID != nil {
@@ -113,20 +124,34 @@ func (hs *HTTPServer) updatePreferencesFor(ctx context.Context, orgID, userID, t
 	}
 	dtoCmd.HomeDashboardID = dashboardID
 
+	if rsp := hs.validateDefaultDatasourceUID(ctx, orgID, dtoCmd.DefaultDatasourceUID); rsp != nil {
+		return rsp
+	}
+
 	saveCmd := pref.SavePreferenceCommand{
-		UserID:          userID,
-		OrgID:           orgID,
-		TeamID:          teamId,
-		Theme:           dtoCmd.Theme,
-		Locale:          dtoCmd.Locale,
-		Timezone:        dtoCmd.Timezone,
-		WeekStart:       dtoCmd.WeekStart,
-		HomeDashboardID: dtoCmd.HomeDashboardID,
-		QueryHistory:    dtoCmd.QueryHistory,
-		Navbar:          dtoCmd.Navbar,
+		UserID:               userID,
+		OrgID:                orgID,
+		TeamID:               teamId,
+		Theme:                dtoCmd.Theme,
+		Locale:               dtoCmd.Locale,
+		Timezone:             dtoCmd.Timezone,
+		WeekStart:            dtoCmd.WeekStart,
+		HomeDashboardID:      dtoCmd.HomeDashboardID,
+		QueryHistory:         dtoCmd.QueryHistory,
+		Navbar:               dtoCmd.Navbar,
+		Custom:               dtoCmd.Custom,
+		DefaultDatasourceUID: dtoCmd.DefaultDatasourceUID,
+		Version:              dtoCmd.Version,
 	}
 
 	if err := hs.preferenceService.Save(ctx, &saveCmd); err != nil {
+		if errors.Is(err, pref.ErrCustomTooLarge) || errors.Is(err, pref.ErrInvalidNavbarItem) ||
+			errors.Is(err, pref.ErrInvalidQueryHistoryHomeTab) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, pref.ErrPreferencesVersionMismatch) {
+			return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+		}
 		return response.Error(500, "Failed to save preferences", err)
 	}
 
@@ -148,6 +173,10 @@ func (hs *HTTPServer) patchPreferencesFor(ctx context.Context, orgID, userID, te
 	}
 
 	// convert dashboard UID to ID in order to store internally if it exists in the query, otherwise take the id from query
+	if dtoCmd.Locale != nil && !isValidLocale(*dtoCmd.Locale) {
+		return response.Error(400, "Invalid locale", nil)
+	}
+
 	dashboardID := dtoCmd.HomeDashboardID
 	if dtoCmd.HomeDashboardUID != nil {
 		query := models.GetDashboardQuery{Uid: *dtoCmd.HomeDashboardUID, OrgId: orgID}
@@ -159,26 +188,67 @@ func (hs *HTTPServer) patchPreferencesFor(ctx context.Context, orgID, userID, te
 	}
 	dtoCmd.HomeDashboardID = dashboardID
 
+	if dtoCmd.DefaultDatasourceUID != nil {
+		if rsp := hs.validateDefaultDatasourceUID(ctx, orgID, *dtoCmd.DefaultDatasourceUID); rsp != nil {
+			return rsp
+		}
+	}
+
 	patchCmd := pref.PatchPreferenceCommand{
-		UserID:          userID,
-		OrgID:           orgID,
-		TeamID:          teamId,
-		Theme:           dtoCmd.Theme,
-		Timezone:        dtoCmd.Timezone,
-		WeekStart:       dtoCmd.WeekStart,
-		HomeDashboardID: dtoCmd.HomeDashboardID,
-		Locale:          dtoCmd.Locale,
-		Navbar:          dtoCmd.Navbar,
-		QueryHistory:    dtoCmd.QueryHistory,
+		UserID:               userID,
+		OrgID:                orgID,
+		TeamID:               teamId,
+		Theme:                dtoCmd.Theme,
+		Timezone:             dtoCmd.Timezone,
+		WeekStart:            dtoCmd.WeekStart,
+		HomeDashboardID:      dtoCmd.HomeDashboardID,
+		Locale:               dtoCmd.Locale,
+		Navbar:               dtoCmd.Navbar,
+		QueryHistory:         dtoCmd.QueryHistory,
+		Custom:               dtoCmd.Custom,
+		DefaultDatasourceUID: dtoCmd.DefaultDatasourceUID,
 	}
 
 	if err := hs.preferenceService.Patch(ctx, &patchCmd); err != nil {
+		if errors.Is(err, pref.ErrCustomTooLarge) || errors.Is(err, pref.ErrInvalidNavbarItem) ||
+			errors.Is(err, pref.ErrInvalidQueryHistoryHomeTab) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, pref.ErrPreferencesVersionMismatch) {
+			return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+		}
 		return response.Error(500, "Failed to save preferences", err)
 	}
 
 	return response.Success("Preferences updated")
 }
 
+// isValidLocale checks that the locale is a BCP 47 language tag, such as en-US.
+// An empty locale resets the preference.
+func isValidLocale(locale string) bool {
+	if locale == "" {
+		return true
+	}
+	_, err := language.Parse(locale)
+	return err == nil
+}
+
+// validateDefaultDatasourceUID checks that the preferred default data source
+// exists in the organization, an empty uid resets the preference.
+func (hs *HTTPServer) validateDefaultDatasourceUID(ctx context.Context, orgID int64, uid string) response.Response {
+	if uid == "" {
+		return nil
+	}
+	query := models.GetDataSourceQuery{Uid: uid, OrgId: orgID}
+	if err := hs.DataSourcesService.GetDataSource(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get data source", err)
+	}
+	return nil
+}
+
 // GET /api/org/preferences
 func (hs *HTTPServer) GetOrgPreferences(c *models.ReqContext) response.Response {
 	return hs.getPreferencesFor(c.Req.Context(), c.OrgId, 0, 0)
@@ -202,3 +272,132 @@ func (hs *HTTPServer) PatchOrgPreferences(c *models.ReqContext) response.Respons
 	}
 	return hs.patchPreferencesFor(c.Req.Context(), c.OrgId, 0, 0, &dtoCmd)
 }
+
+// PATCH /api/orgs/:orgId/preferences/users
+func (hs *HTTPServer) AdminPatchOrgUsersPreferences(c *models.ReqContext) response.Response {
+	cmd := pref.PatchOrgUsersPreferencesCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	cmd.OrgID = orgID
+
+	if cmd.HomeDashboardUID != nil {
+		dashboardID := int64(0)
+		if *cmd.HomeDashboardUID != "" {
+			query := models.GetDashboardQuery{Uid: *cmd.HomeDashboardUID, OrgId: orgID}
+			if err := hs.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+				return response.Error(http.StatusNotFound, "Dashboard not found", err)
+			}
+			dashboardID = query.Result.Id
+		}
+		cmd.HomeDashboardID = &dashboardID
+	}
+
+	return hs.patchOrgUsersPreferences(c.Req.Context(), &cmd)
+}
+
+// DELETE /api/orgs/:orgId/preferences/users
+func (hs *HTTPServer) AdminResetOrgUsersPreferences(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	var homeDashboardID int64
+	var empty string
+	return hs.patchOrgUsersPreferences(c.Req.Context(), &pref.PatchOrgUsersPreferencesCommand{
+		OrgID:           orgID,
+		HomeDashboardID: &homeDashboardID,
+		Timezone:        &empty,
+		WeekStart:       &empty,
+		Theme:           &empty,
+		ResetJSONData:   true,
+	})
+}
+
+// GET /api/orgs/:orgId/preferences/export
+func (hs *HTTPServer) AdminExportOrgPreferences(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	export, err := hs.preferenceService.Export(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export preferences", err)
+	}
+	return response.JSON(http.StatusOK, export)
+}
+
+// POST /api/orgs/:orgId/preferences/import
+func (hs *HTTPServer) AdminImportOrgPreferences(c *models.ReqContext) response.Response {
+	cmd := pref.ImportPreferencesCommand{}
+	if err := web.Bind(c.Req, &cmd.Export); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	cmd.OrgID = orgID
+
+	result, err := hs.preferenceService.Import(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, pref.ErrInvalidTheme) || errors.Is(err, pref.ErrInvalidWeekStart) ||
+			errors.Is(err, pref.ErrInvalidNavbarItem) || errors.Is(err, pref.ErrInvalidQueryHistoryHomeTab) ||
+			errors.Is(err, pref.ErrCustomTooLarge) {
+			return response.Error(http.StatusBadRequest, util.Capitalize(err.Error()), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to import preferences", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) patchOrgUsersPreferences(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) response.Response {
+	updated, err := hs.preferenceService.PatchOrgUsers(ctx, cmd)
+	if err != nil {
+		if errors.Is(err, pref.ErrInvalidTheme) || errors.Is(err, pref.ErrInvalidWeekStart) {
+			return response.Error(http.StatusBadRequest, util.Capitalize(err.Error()), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update users preferences", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":      "Users preferences updated",
+		"updatedUsers": updated,
+	})
+}
+
+// GET /api/admin/preferences
+func (hs *HTTPServer) AdminGetInstanceDefaults(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.preferenceService.GetInstanceDefaults())
+}
+
+// PUT /api/admin/preferences
+func (hs *HTTPServer) AdminUpdateInstanceDefaults(c *models.ReqContext) response.Response {
+	cmd := pref.UpdateInstanceDefaultsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return hs.updateInstanceDefaults(c.Req.Context(), &cmd)
+}
+
+// DELETE /api/admin/preferences
+func (hs *HTTPServer) AdminResetInstanceDefaults(c *models.ReqContext) response.Response {
+	return hs.updateInstanceDefaults(c.Req.Context(), &pref.UpdateInstanceDefaultsCommand{})
+}
+
+func (hs *HTTPServer) updateInstanceDefaults(ctx context.Context, cmd *pref.UpdateInstanceDefaultsCommand) response.Response {
+	defaults, err := hs.preferenceService.UpdateInstanceDefaults(ctx, cmd)
+	if err != nil {
+		if errors.Is(err, pref.ErrInvalidTheme) || errors.Is(err, pref.ErrInvalidWeekStart) ||
+			errors.Is(err, pref.ErrInvalidHomeDashboardPath) {
+			return response.Error(http.StatusBadRequest, util.Capitalize(err.Error()), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update default preferences", err)
+	}
+	return response.JSON(http.StatusOK, defaults)
+}
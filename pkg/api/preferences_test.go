@@ -11,9 +11,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/preference/preftest"
 )
@@ -176,6 +178,141 @@ func TestAPIEndpoint_PatchUserPreferences(t *testing.T) {
 	})
 }
 
+func TestAPIEndpoint_UpdateUserPreferences_Locale(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInViewer(sc.initCtx)
+	sc.hs.preferenceService = preftest.NewPreferenceServiceFake()
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method+" returns 200 with a language tag", func(t *testing.T) {
+			response := callAPI(sc.server, method, patchUserPreferencesUrl, strings.NewReader(`{"locale":"fr-FR"}`), t)
+			assert.Equal(t, http.StatusOK, response.Code)
+		})
+
+		t.Run(method+" returns 400 with an invalid locale", func(t *testing.T) {
+			response := callAPI(sc.server, method, patchUserPreferencesUrl, strings.NewReader(`{"locale":"not a locale"}`), t)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
+func TestAPIEndpoint_UpdateUserPreferences_Navbar(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInViewer(sc.initCtx)
+	prefService := preftest.NewPreferenceServiceFake()
+	sc.hs.preferenceService = prefService
+
+	t.Run("Returns the pinned and hidden items", func(t *testing.T) {
+		prefService.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{
+			Navbar: pref.NavbarPreference{PinnedItems: []string{"explore"}, HiddenItems: []string{"live"}},
+		}}
+		response := callAPI(sc.server, http.MethodGet, patchUserPreferencesUrl, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		var prefs dtos.Prefs
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &prefs))
+		assert.Equal(t, []string{"explore"}, prefs.Navbar.PinnedItems)
+		assert.Equal(t, []string{"live"}, prefs.Navbar.HiddenItems)
+	})
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method+" returns 400 with invalid items", func(t *testing.T) {
+			prefService.ExpectedError = pref.ErrInvalidNavbarItem
+			response := callAPI(sc.server, method, patchUserPreferencesUrl, strings.NewReader(`{"navbar":{"pinnedItems":["unknown"]}}`), t)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
+func TestAPIEndpoint_UpdateUserPreferences_QueryHistory(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInViewer(sc.initCtx)
+	prefService := preftest.NewPreferenceServiceFake()
+	sc.hs.preferenceService = prefService
+
+	t.Run("Returns the query history preferences", func(t *testing.T) {
+		optIn := false
+		prefService.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{
+			QueryHistory: pref.QueryHistoryPreference{HomeTab: "starred", RetentionOptIn: &optIn},
+		}}
+		response := callAPI(sc.server, http.MethodGet, patchUserPreferencesUrl, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"homeTab":"starred","retentionOptIn":false}`, string(jsonField(t, response.Body.Bytes(), "queryHistory")))
+	})
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method+" returns 400 with an invalid home tab", func(t *testing.T) {
+			prefService.ExpectedError = pref.ErrInvalidQueryHistoryHomeTab
+			response := callAPI(sc.server, method, patchUserPreferencesUrl, strings.NewReader(`{"queryHistory":{"homeTab":"settings"}}`), t)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
+func jsonField(t *testing.T, body []byte, field string) []byte {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &fields))
+	return fields[field]
+}
+
+func TestAPIEndpoint_UpdateUserPreferences_Version(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInViewer(sc.initCtx)
+	prefService := preftest.NewPreferenceServiceFake()
+	sc.hs.preferenceService = prefService
+
+	t.Run("Returns the version with the preferences", func(t *testing.T) {
+		prefService.ExpectedPreference = &pref.Preference{Theme: "dark", Version: 3}
+		response := callAPI(sc.server, http.MethodGet, patchUserPreferencesUrl, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+		assert.Equal(t, float64(3), resp["version"])
+	})
+
+	t.Run("Returns 412 when the preferences have been changed since", func(t *testing.T) {
+		prefService.ExpectedError = pref.ErrPreferencesVersionMismatch
+		response := callAPI(sc.server, http.MethodPut, patchUserPreferencesUrl, strings.NewReader(`{"theme":"light","version":2}`), t)
+		assert.Equal(t, http.StatusPreconditionFailed, response.Code)
+
+		response = callAPI(sc.server, http.MethodPatch, patchUserPreferencesUrl, strings.NewReader(`{"theme":"light"}`), t)
+		assert.Equal(t, http.StatusPreconditionFailed, response.Code)
+	})
+}
+
+func TestAPIEndpoint_PatchUserPreferences_DefaultDatasource(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	setInitCtxSignedInViewer(sc.initCtx)
+	sc.hs.DataSourcesService = &fakeDatasources.FakeDataSourceService{DataSources: []*models.DataSource{{Id: 1, OrgId: 1, Uid: "loki"}}}
+
+	t.Run("Returns 200 for a data source of the organization", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPatch, patchUserPreferencesUrl, strings.NewReader(`{"defaultDatasourceUid":"loki"}`), t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Returns 404 for a missing data source", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPatch, patchUserPreferencesUrl, strings.NewReader(`{"defaultDatasourceUid":"missing"}`), t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Returns 200 when resetting the data source", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPatch, patchUserPreferencesUrl, strings.NewReader(`{"defaultDatasourceUid":""}`), t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Returns the data source with the preferences", func(t *testing.T) {
+		prefService := preftest.NewPreferenceServiceFake()
+		prefService.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{DefaultDatasourceUID: "loki"}}
+		sc.hs.preferenceService = prefService
+
+		response := callAPI(sc.server, http.MethodGet, patchUserPreferencesUrl, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+		assert.Equal(t, "loki", resp["defaultDatasourceUid"])
+	})
+}
+
 func TestAPIEndpoint_PatchOrgPreferences(t *testing.T) {
 	sc := setupHTTPServer(t, true, false)
 
@@ -195,3 +332,77 @@ func TestAPIEndpoint_PatchOrgPreferences(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, response.Code)
 	})
 }
+
+func TestAPIEndpoint_PatchOrgUsersPreferences(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	prefService := preftest.NewPreferenceServiceFake()
+	prefService.ExpectedPatchedUsers = 3
+	sc.hs.preferenceService = prefService
+
+	t.Run("Returns 403 for org admins", func(t *testing.T) {
+		setInitCtxSignedInOrgAdmin(sc.initCtx)
+		response := callAPI(sc.server, http.MethodPatch, "/api/orgs/1/preferences/users", strings.NewReader(`{"theme": "dark"}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	setInitCtxSignedInUser(sc.initCtx, models.SignedInUser{UserId: testUserID, OrgId: 1, OrgRole: models.ROLE_ADMIN, IsGrafanaAdmin: true, Login: testUserLogin})
+	t.Run("Returns the number of updated users", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodPatch, "/api/orgs/1/preferences/users", strings.NewReader(`{"theme": "dark"}`), t)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		var body struct {
+			UpdatedUsers int64 `json:"updatedUsers"`
+		}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		assert.Equal(t, int64(3), body.UpdatedUsers)
+	})
+
+	t.Run("Returns 400 with an invalid theme", func(t *testing.T) {
+		prefService.ExpectedError = pref.ErrInvalidTheme
+		t.Cleanup(func() { prefService.ExpectedError = nil })
+		response := callAPI(sc.server, http.MethodPatch, "/api/orgs/1/preferences/users", strings.NewReader(`{"theme": "pink"}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Returns 200 when resetting the preferences", func(t *testing.T) {
+		response := callAPI(sc.server, http.MethodDelete, "/api/orgs/1/preferences/users", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+func TestAPIEndpoint_ExportImportOrgPreferences(t *testing.T) {
+	sc := setupHTTPServer(t, true, false)
+	prefService := preftest.NewPreferenceServiceFake()
+	sc.hs.preferenceService = prefService
+
+	t.Run("Returns 403 for org admins", func(t *testing.T) {
+		setInitCtxSignedInOrgAdmin(sc.initCtx)
+		response := callAPI(sc.server, http.MethodGet, "/api/orgs/1/preferences/export", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	setInitCtxSignedInUser(sc.initCtx, models.SignedInUser{UserId: testUserID, OrgId: 1, OrgRole: models.ROLE_ADMIN, IsGrafanaAdmin: true, Login: testUserLogin})
+	t.Run("Returns the export", func(t *testing.T) {
+		prefService.ExpectedExport = &pref.PreferencesExport{
+			Org:   &pref.ExportedPreferences{Theme: "dark"},
+			Users: []pref.ExportedUserPreferences{{Login: "alice", ExportedPreferences: pref.ExportedPreferences{HomeDashboardUID: "home"}}},
+		}
+		response := callAPI(sc.server, http.MethodGet, "/api/orgs/1/preferences/export", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"org":{"theme":"dark"},"teams":null,"users":[{"login":"alice","homeDashboardUID":"home"}]}`, response.Body.String())
+	})
+
+	t.Run("Returns what couldn't be imported", func(t *testing.T) {
+		prefService.ExpectedImportResult = &pref.ImportPreferencesResult{Imported: 1, MissingUsers: []string{"bob"}}
+		response := callAPI(sc.server, http.MethodPost, "/api/orgs/1/preferences/import", strings.NewReader(`{"users":[{"login":"bob","theme":"dark"}]}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `["bob"]`, string(jsonField(t, response.Body.Bytes(), "missingUsers")))
+	})
+
+	t.Run("Returns 400 with invalid preferences", func(t *testing.T) {
+		prefService.ExpectedError = pref.ErrInvalidWeekStart
+		t.Cleanup(func() { prefService.ExpectedError = nil })
+		response := callAPI(sc.server, http.MethodPost, "/api/orgs/1/preferences/import", strings.NewReader(`{"org":{"weekStart":"friday"}}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
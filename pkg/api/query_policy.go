@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/org/query-policy
+func (hs *HTTPServer) GetOrgQueryPolicy(c *models.ReqContext) response.Response {
+	result, err := hs.queryPolicy.Get(c.Req.Context(), &querypolicy.GetQueryPolicyQuery{OrgID: c.OrgId})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query policy", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/org/query-policy
+func (hs *HTTPServer) UpdateOrgQueryPolicy(c *models.ReqContext) response.Response {
+	cmd := querypolicy.SaveQueryPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd.Policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+
+	if err := hs.queryPolicy.Save(c.Req.Context(), &cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save query policy", err)
+	}
+
+	return response.Success("Query policy updated")
+}
+
+// validateDataSourceCallPolicy writes an error and returns false when the query
+// policy of the organization doesn't allow the data source proxy or resource call.
+func (hs *HTTPServer) validateDataSourceCallPolicy(c *models.ReqContext) bool {
+	err := hs.queryPolicy.ValidateDataSourceCall(c.Req.Context(), c.SignedInUser)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, querypolicy.ErrDataSourceCallNotAllowed) {
+		c.JsonApiErr(http.StatusForbidden, err.Error(), err)
+		return false
+	}
+	c.JsonApiErr(http.StatusInternalServerError, "Failed to validate data source call", err)
+	return false
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/queryschema"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// defaultDriftDays is the number of days the changes of the schemas of the
+// queries are returned for.
+const defaultDriftDays = 7
+
+// GetQuerySchemaDrift returns the changes of the fields returned by the
+// queries of the dashboards of the organization.
+// GET /api/dashboards/query-schemas/drift
+func (hs *HTTPServer) GetQuerySchemaDrift(c *models.ReqContext) response.Response {
+	return hs.getQuerySchemaDrift(c, c.Query("dashboardUid"))
+}
+
+// GetDashboardQuerySchemaDrift returns the changes of the fields returned by
+// the queries of a dashboard.
+// GET /api/dashboards/uid/:uid/query-schemas/drift
+func (hs *HTTPServer) GetDashboardQuerySchemaDrift(c *models.ReqContext) response.Response {
+	dash, rsp := hs.getViewableDashboardByUID(c, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+	return hs.getQuerySchemaDrift(c, dash.Uid)
+}
+
+func (hs *HTTPServer) getQuerySchemaDrift(c *models.ReqContext, dashboardUID string) response.Response {
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultDriftDays
+	}
+	if days < 0 {
+		return response.Error(http.StatusBadRequest, "days must be a positive number", nil)
+	}
+
+	result, err := hs.querySchemas.GetDrift(c.Req.Context(), &queryschema.GetDriftQuery{
+		OrgID:        c.OrgId,
+		DashboardUID: dashboardUID,
+		Since:        time.Now().AddDate(0, 0, -days),
+		BreakingOnly: c.QueryBool("breakingOnly"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query schema drift", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetDashboardQuerySchemas returns the schemas recorded for the queries of a
+// dashboard, optionally of a panel and query.
+// GET /api/dashboards/uid/:uid/query-schemas
+func (hs *HTTPServer) GetDashboardQuerySchemas(c *models.ReqContext) response.Response {
+	dash, rsp := hs.getViewableDashboardByUID(c, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	result, err := hs.querySchemas.GetHistory(c.Req.Context(), &queryschema.GetHistoryQuery{
+		OrgID:        c.OrgId,
+		DashboardUID: dash.Uid,
+		PanelID:      c.QueryInt64("panelId"),
+		RefID:        c.Query("refId"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query schemas", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
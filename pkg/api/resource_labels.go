@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SearchLabeledResources returns the dashboards, folders, data sources, alert rules and teams
+// having all the labels that the signed in user can view.
+// GET /api/resources?label=env:prod&label=tier&kind=dashboard
+func (hs *HTTPServer) SearchLabeledResources(c *models.ReqContext) response.Response {
+	query := resourcelabels.SearchResourcesQuery{OrgID: c.OrgId, Limit: c.QueryInt("limit")}
+	for _, l := range c.QueryStrings("label") {
+		selector, err := resourcelabels.ParseSelector(l)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		query.Selectors = append(query.Selectors, selector)
+	}
+	if len(query.Selectors) == 0 {
+		return response.Error(http.StatusBadRequest, "at least one label is required", nil)
+	}
+	for _, kind := range c.QueryStrings("kind") {
+		query.Kinds = append(query.Kinds, resourcelabels.ResourceKind(kind))
+	}
+
+	found, err := hs.resourceLabels.Search(c.Req.Context(), &query)
+	if err != nil {
+		return resourceLabelsErrorResponse(err, "Failed to search resources")
+	}
+
+	result := make([]*resourcelabels.Resource, 0, len(found))
+	for _, r := range found {
+		canView, err := hs.canAccessLabeledResource(c, r, false)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to check permissions", err)
+		}
+		if canView {
+			result = append(result, r)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetResourceLabels returns a resource and its labels.
+// GET /api/resources/:kind/:uid/labels
+func (hs *HTTPServer) GetResourceLabels(c *models.ReqContext) response.Response {
+	resource, rsp := hs.getLabeledResource(c, false)
+	if rsp != nil {
+		return rsp
+	}
+	return response.JSON(http.StatusOK, resource)
+}
+
+// SetResourceLabels replaces the labels of a resource.
+// PUT /api/resources/:kind/:uid/labels
+func (hs *HTTPServer) SetResourceLabels(c *models.ReqContext) response.Response {
+	cmd := resourcelabels.SetLabelsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	resource, rsp := hs.getLabeledResource(c, true)
+	if rsp != nil {
+		return rsp
+	}
+	cmd.OrgID = c.OrgId
+	cmd.ResourceKind = resource.Kind
+	cmd.ResourceUID = resource.UID
+
+	resource, err := hs.resourceLabels.SetLabels(c.Req.Context(), &cmd)
+	if err != nil {
+		return resourceLabelsErrorResponse(err, "Failed to set labels")
+	}
+	return response.JSON(http.StatusOK, resource)
+}
+
+// getLabeledResource returns the resource of the request if the signed in user can view it,
+// or edit it if write is set.
+func (hs *HTTPServer) getLabeledResource(c *models.ReqContext, write bool) (*resourcelabels.Resource, response.Response) {
+	resource, err := hs.resourceLabels.GetResource(c.Req.Context(), &resourcelabels.GetResourceQuery{
+		OrgID: c.OrgId,
+		Kind:  resourcelabels.ResourceKind(web.Params(c.Req)[":kind"]),
+		UID:   web.Params(c.Req)[":uid"],
+	})
+	if err != nil {
+		return nil, resourceLabelsErrorResponse(err, "Failed to get resource")
+	}
+
+	allowed, err := hs.canAccessLabeledResource(c, resource, write)
+	if err != nil {
+		return nil, response.Error(http.StatusInternalServerError, "Failed to check permissions", err)
+	}
+	if !allowed {
+		return nil, response.Error(http.StatusForbidden, "Access denied to this resource", nil)
+	}
+	return resource, nil
+}
+
+// canAccessLabeledResource checks that the signed in user can view a resource, or edit it if write is set.
+// The permissions of alert rules are the ones of their folder.
+func (hs *HTTPServer) canAccessLabeledResource(c *models.ReqContext, resource *resourcelabels.Resource, write bool) (bool, error) {
+	ctx := c.Req.Context()
+	switch resource.Kind {
+	case resourcelabels.ResourceKindDashboard, resourcelabels.ResourceKindFolder, resourcelabels.ResourceKindAlertRule:
+		g := guardian.New(ctx, resource.DashboardID, c.OrgId, c.SignedInUser)
+		if write {
+			return g.CanEdit()
+		}
+		return g.CanView()
+	case resourcelabels.ResourceKindDatasource:
+		if hs.AccessControl.IsDisabled() {
+			return !write || c.OrgRole == models.ROLE_ADMIN, nil
+		}
+		action := datasources.ActionRead
+		if write {
+			action = datasources.ActionWrite
+		}
+		return hs.AccessControl.Evaluate(ctx, c.SignedInUser, ac.EvalPermission(action, datasources.ScopeProvider.GetResourceScopeUID(resource.UID)))
+	case resourcelabels.ResourceKindTeam:
+		if hs.AccessControl.IsDisabled() {
+			if !write {
+				return c.OrgRole == models.ROLE_ADMIN || (hs.Cfg.EditorsCanAdmin && c.OrgRole == models.ROLE_EDITOR), nil
+			}
+			teamID, err := strconv.ParseInt(resource.UID, 10, 64)
+			if err != nil {
+				return false, err
+			}
+			return hs.teamGuardian.CanAdmin(ctx, c.OrgId, teamID, c.SignedInUser) == nil, nil
+		}
+		action := ac.ActionTeamsRead
+		if write {
+			action = ac.ActionTeamsWrite
+		}
+		return hs.AccessControl.Evaluate(ctx, c.SignedInUser, ac.EvalPermission(action, ac.Scope("teams", "id", resource.UID)))
+	}
+	return false, nil
+}
+
+func resourceLabelsErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, resourcelabels.ErrResourceNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, resourcelabels.ErrInvalidResourceKind), errors.Is(err, resourcelabels.ErrInvalidLabel):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -1,6 +1,9 @@
 package routing
 
 import (
+	"context"
+	"errors"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/web"
@@ -19,3 +22,39 @@ func Wrap(handler func(c *models.ReqContext) response.Response) web.Handler {
 		}
 	}
 }
+
+// TransactionManager runs a function within a database transaction. The
+// stores called with the context passed to fn join the transaction.
+type TransactionManager interface {
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// errRollback rolls back the transaction of a handler that responded with an error.
+var errRollback = errors.New("rolling back the transaction of a failed request")
+
+// WrapInTransaction is like Wrap, but runs the handler within a single
+// database transaction. The writes of all service calls made with the request
+// context are committed together when the handler responds with a status
+// below 400, and rolled back otherwise or when the handler panics.
+func WrapInTransaction(tm TransactionManager, handler func(c *models.ReqContext) response.Response) web.Handler {
+	return func(c *models.ReqContext) {
+		req := c.Req
+		var res response.Response
+		err := tm.InTransaction(req.Context(), func(ctx context.Context) error {
+			c.Req = req.WithContext(ctx)
+			defer func() { c.Req = req }()
+
+			res = handler(c)
+			if res != nil && res.Status() >= 400 {
+				return errRollback
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errRollback) {
+			res = ServerError(err)
+		}
+		if res != nil {
+			res.WriteTo(c)
+		}
+	}
+}
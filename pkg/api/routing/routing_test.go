@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type txKey struct{}
+
+type fakeTransactionManager struct {
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (tm *fakeTransactionManager) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, tm)); err != nil {
+		tm.rolledBack = true
+		return err
+	}
+	if tm.commitErr != nil {
+		return tm.commitErr
+	}
+	tm.committed = true
+	return nil
+}
+
+func TestWrapInTransaction(t *testing.T) {
+	run := func(tm *fakeTransactionManager, handler func(c *models.ReqContext) response.Response) (*models.ReqContext, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := &models.ReqContext{Context: &web.Context{
+			Req:  httptest.NewRequest(http.MethodPost, "/api/folders", nil),
+			Resp: web.NewResponseWriter(http.MethodPost, rec),
+		}, Logger: log.New("test")}
+		WrapInTransaction(tm, handler).(func(c *models.ReqContext))(c)
+		return c, rec
+	}
+
+	t.Run("commits when the handler succeeds", func(t *testing.T) {
+		tm := &fakeTransactionManager{}
+		c, rec := run(tm, func(c *models.ReqContext) response.Response {
+			require.Equal(t, tm, c.Req.Context().Value(txKey{}))
+			return response.Success("created")
+		})
+		require.True(t, tm.committed)
+		require.False(t, tm.rolledBack)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Nil(t, c.Req.Context().Value(txKey{}))
+	})
+
+	t.Run("rolls back when the handler fails", func(t *testing.T) {
+		tm := &fakeTransactionManager{}
+		_, rec := run(tm, func(c *models.ReqContext) response.Response {
+			return response.Error(http.StatusConflict, "conflict", nil)
+		})
+		require.False(t, tm.committed)
+		require.True(t, tm.rolledBack)
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("responds with a server error when the commit fails", func(t *testing.T) {
+		tm := &fakeTransactionManager{commitErr: errors.New("connection lost")}
+		_, rec := run(tm, func(c *models.ReqContext) response.Response {
+			return response.Success("created")
+		})
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
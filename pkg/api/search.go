@@ -54,19 +54,20 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 	}
 
 	searchQuery := search.Query{
-		Title:         query,
-		Tags:          tags,
-		SignedInUser:  c.SignedInUser,
-		Limit:         limit,
-		Page:          page,
-		IsStarred:     starred == "true",
-		OrgId:         c.OrgId,
-		DashboardIds:  dbIDs,
-		DashboardUIDs: dbUIDs,
-		Type:          dashboardType,
-		FolderIds:     folderIDs,
-		Permission:    permission,
-		Sort:          sort,
+		Title:            query,
+		Tags:             tags,
+		SignedInUser:     c.SignedInUser,
+		Limit:            limit,
+		Page:             page,
+		IsStarred:        starred == "true",
+		OrgId:            c.OrgId,
+		DashboardIds:     dbIDs,
+		DashboardUIDs:    dbUIDs,
+		Type:             dashboardType,
+		FolderIds:        folderIDs,
+		SubtreeFolderUID: c.Query("subtreeFolderUid"),
+		Permission:       permission,
+		Sort:             sort,
 	}
 
 	err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
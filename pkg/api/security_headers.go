@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/orgs/:orgId/security-headers
+func (hs *HTTPServer) GetOrgSecurityHeaders(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	result, err := hs.securityHeaders.Get(c.Req.Context(), &securityheaders.GetSecurityHeadersQuery{OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, securityheaders.ErrSecurityHeadersNotFound) {
+			return response.JSON(http.StatusOK, &securityheaders.OrgSecurityHeaders{})
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get security headers", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/orgs/:orgId/security-headers
+func (hs *HTTPServer) UpdateOrgSecurityHeaders(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	cmd := securityheaders.SaveSecurityHeadersCommand{OrgID: orgID}
+	if err := web.Bind(c.Req, &cmd.Headers); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.securityHeaders.Save(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, securityheaders.ErrInvalidFrameAncestor) ||
+			errors.Is(err, securityheaders.ErrInvalidHSTSMaxAge) ||
+			errors.Is(err, securityheaders.ErrMissingCSPTemplate) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save security headers", err)
+	}
+
+	return response.Success("Security headers updated")
+}
+
+// DELETE /api/orgs/:orgId/security-headers
+func (hs *HTTPServer) DeleteOrgSecurityHeaders(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	if err := hs.securityHeaders.Delete(c.Req.Context(), &securityheaders.DeleteSecurityHeadersCommand{OrgID: orgID}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset security headers", err)
+	}
+
+	return response.Success("Security headers reset")
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -34,17 +37,32 @@ func (hs *HTTPServer) createShortURL(c *models.ReqContext) response.Response {
 		return response.Error(400, "Invalid path", nil)
 	}
 
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+	createCmd := &models.CreateShortUrlCommand{
+		Path: cmd.Path,
+		Slug: strings.TrimSpace(cmd.Slug),
+	}
+	if cmd.ExpiresAt != nil {
+		createCmd.ExpiresAt = *cmd.ExpiresAt
+	}
+
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, createCmd)
 	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrShortURLInvalidSlug), errors.Is(err, models.ErrShortURLInvalidExpiry):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, models.ErrShortURLSlugTaken):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
 		return response.Error(500, "Failed to create short URL", err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.OrgId)
+	url := shortURLToAbsURL(shortURL)
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
-		UID: shortURL.Uid,
-		URL: url,
+		UID:       shortURL.Uid,
+		URL:       url,
+		ExpiresAt: unixToTimePtr(shortURL.ExpiresAt),
 	}
 
 	return response.JSON(http.StatusOK, dto)
@@ -63,6 +81,10 @@ func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 			hs.log.Debug("Not redirecting short URL since not found")
 			return
 		}
+		if errors.Is(err, models.ErrShortURLExpired) {
+			hs.log.Debug("Not redirecting short URL since it has expired", "uid", shortURLUID)
+			return
+		}
 
 		hs.log.Error("Short URL redirection error", "err", err)
 		return
@@ -76,3 +98,102 @@ func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 	hs.log.Debug("Redirecting short URL", "path", shortURL.Path)
 	c.Redirect(setting.ToAbsUrl(shortURL.Path), 302)
 }
+
+// searchShortURLs lists the short URLs of the organization with their visit statistics.
+// GET /api/short-urls
+func (hs *HTTPServer) searchShortURLs(c *models.ReqContext) response.Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), &models.SearchShortUrlsQuery{
+		OrgId:       c.OrgId,
+		Query:       c.Query("query"),
+		OnlyExpired: c.QueryBool("expired"),
+		Sort:        c.Query("sort"),
+		Page:        page,
+		Limit:       perPage,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search short URLs", err)
+	}
+
+	now := time.Now()
+	dto := dtos.SearchShortURLsResult{
+		TotalCount: result.TotalCount,
+		ShortURLs:  make([]dtos.ShortURLDetails, 0, len(result.ShortUrls)),
+		Page:       page,
+		PerPage:    perPage,
+	}
+	for _, s := range result.ShortUrls {
+		dto.ShortURLs = append(dto.ShortURLs, dtos.ShortURLDetails{
+			UID:        s.Uid,
+			URL:        shortURLToAbsURL(s),
+			Path:       s.Path,
+			CreatedBy:  s.CreatedBy,
+			CreatedAt:  time.Unix(s.CreatedAt, 0),
+			LastSeenAt: unixToTimePtr(s.LastSeenAt),
+			ExpiresAt:  unixToTimePtr(s.ExpiresAt),
+			Expired:    s.Expired(now),
+			Hits:       s.Hits,
+		})
+	}
+	return response.JSON(http.StatusOK, dto)
+}
+
+// deleteShortURL deletes a short URL, e.g. to free its slug.
+// DELETE /api/short-urls/:uid
+func (hs *HTTPServer) deleteShortURL(c *models.ReqContext) response.Response {
+	err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, models.ErrShortURLNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete short URL", err)
+	}
+	return response.Success("Short URL deleted")
+}
+
+// pruneShortURLs deletes the expired short URLs of the organization and,
+// optionally, the ones that have not been visited for a while.
+// POST /api/short-urls/prune
+func (hs *HTTPServer) pruneShortURLs(c *models.ReqContext) response.Response {
+	cmd := dtos.PruneShortURLsCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	pruneCmd := &models.PruneShortUrlsCommand{OrgId: c.OrgId}
+	if cmd.NotSeenFor != "" {
+		notSeenFor, err := gtime.ParseDuration(cmd.NotSeenFor)
+		if err != nil || notSeenFor <= 0 {
+			return response.Error(http.StatusBadRequest, "notSeenFor must be a positive duration, e.g. 90d", err)
+		}
+		pruneCmd.NotSeenSince = time.Now().Add(-notSeenFor)
+	}
+
+	if err := hs.ShortURLService.PruneShortURLs(c.Req.Context(), pruneCmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete short URLs", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Short URLs deleted",
+		"deleted": pruneCmd.NumDeleted,
+	})
+}
+
+func shortURLToAbsURL(shortURL *models.ShortUrl) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, shortURL.OrgId)
+}
+
+func unixToTimePtr(unix int64) *time.Time {
+	if unix <= 0 {
+		return nil
+	}
+	t := time.Unix(unix, 0)
+	return &t
+}
@@ -29,7 +29,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -76,16 +76,16 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *models.SignedInUser, uid string) (*models.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
@@ -98,3 +98,15 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *mo
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error {
 	return nil
 }
+
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) (*models.SearchShortUrlsResult, error) {
+	return &models.SearchShortUrlsResult{}, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, orgID int64, uid string) error {
+	return nil
+}
+
+func (s *fakeShortURLService) PruneShortURLs(ctx context.Context, cmd *models.PruneShortUrlsCommand) error {
+	return nil
+}
@@ -74,6 +74,9 @@ func (hs *HTTPServer) SignUpStep2(c *models.ReqContext) response.Response {
 	if !setting.AllowUserSignUp {
 		return response.Error(401, "User signup is disabled", nil)
 	}
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), form.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
 
 	createUserCmd := models.CreateUserCommand{
 		Email:    form.Email,
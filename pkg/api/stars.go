@@ -1,23 +1,63 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/web"
 )
 
 func (hs *HTTPServer) GetStars(c *models.ReqContext) response.Response {
+	dashboards, err := hs.getStarredDashboardUIDs(c)
+	if err != nil {
+		return response.Error(500, "Failed to get user stars", err)
+	}
+
+	items, err := hs.starService.GetItemsByUser(c.Req.Context(), &star.GetUserStarredItemsQuery{
+		UserID: c.SignedInUser.UserId,
+		OrgID:  c.OrgId,
+	})
+	if err != nil {
+		return response.Error(500, "Failed to get user stars", err)
+	}
+
+	result, err := hs.QueryHistoryService.SearchInQueryHistory(c.Req.Context(), c.SignedInUser, queryhistory.SearchInQueryHistoryQuery{
+		OnlyStarred: true,
+		To:          time.Now().Unix(),
+	})
+	if err != nil {
+		return response.Error(500, "Failed to get starred queries", err)
+	}
+	queries := make([]string, 0, len(result.QueryHistory))
+	for _, q := range result.QueryHistory {
+		queries = append(queries, q.UID)
+	}
+
+	return response.JSON(200, dtos.UserStars{
+		Dashboards:  dashboards,
+		Folders:     items.UIDs(star.KindFolder),
+		DataSources: items.UIDs(star.KindDataSource),
+		Queries:     queries,
+	})
+}
+
+func (hs *HTTPServer) getStarredDashboardUIDs(c *models.ReqContext) ([]string, error) {
 	query := star.GetUserStarsQuery{
 		UserID: c.SignedInUser.UserId,
 	}
 
 	iuserstars, err := hs.starService.GetByUser(c.Req.Context(), &query)
 	if err != nil {
-		return response.Error(500, "Failed to get user stars", err)
+		return nil, err
 	}
 
 	uids := []string{}
@@ -33,7 +73,100 @@ func (hs *HTTPServer) GetStars(c *models.ReqContext) response.Response {
 			uids = append(uids, query.Result.Uid)
 		}
 	}
-	return response.JSON(200, uids)
+	return uids, nil
+}
+
+// StarItem stars a dashboard, folder, data source or query history entry by uid.
+func (hs *HTTPServer) StarItem(c *models.ReqContext) response.Response {
+	kind := star.Kind(web.Params(c.Req)[":kind"])
+	uid := web.Params(c.Req)[":uid"]
+	ctx := c.Req.Context()
+
+	switch kind {
+	case star.KindDashboard:
+		dash, rsp := hs.getViewableDashboardByUID(c, uid)
+		if rsp != nil {
+			return rsp
+		}
+		if err := hs.starService.Add(ctx, &star.StarDashboardCommand{UserID: c.UserId, DashboardID: dash.Id}); err != nil {
+			return response.Error(500, "Failed to star dashboard", err)
+		}
+	case star.KindFolder:
+		if _, err := hs.folderService.GetFolderByUID(ctx, c.SignedInUser, c.OrgId, uid); err != nil {
+			return apierrors.ToFolderErrorResponse(err)
+		}
+		if err := hs.starService.AddItem(ctx, &star.StarItemCommand{UserID: c.UserId, OrgID: c.OrgId, Kind: kind, UID: uid}); err != nil {
+			return response.Error(500, "Failed to star folder", err)
+		}
+	case star.KindDataSource:
+		if _, err := hs.DataSourceCache.GetDatasourceByUID(ctx, uid, c.SignedInUser, c.SkipCache); err != nil {
+			if errors.Is(err, models.ErrDataSourceAccessDenied) {
+				return response.Error(http.StatusForbidden, "Access denied to datasource", err)
+			}
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				return response.Error(http.StatusNotFound, "Data source not found", err)
+			}
+			return response.Error(500, "Failed to query datasource", err)
+		}
+		if err := hs.starService.AddItem(ctx, &star.StarItemCommand{UserID: c.UserId, OrgID: c.OrgId, Kind: kind, UID: uid}); err != nil {
+			return response.Error(500, "Failed to star data source", err)
+		}
+	case star.KindQuery:
+		if _, err := hs.QueryHistoryService.StarQueryInQueryHistory(ctx, c.SignedInUser, uid); err != nil && !errors.Is(err, queryhistory.ErrQueryAlreadyStarred) {
+			if errors.Is(err, queryhistory.ErrQueryNotFound) {
+				return response.Error(http.StatusNotFound, "Query not found", err)
+			}
+			return response.Error(500, "Failed to star query", err)
+		}
+	default:
+		return response.Error(http.StatusBadRequest, "Unknown star kind", star.ErrUnknownKind)
+	}
+
+	return response.Success("Item starred")
+}
+
+// UnstarItem removes the star of a dashboard, folder, data source or query history entry.
+// Folders and data sources do not have to exist anymore to be unstarred.
+func (hs *HTTPServer) UnstarItem(c *models.ReqContext) response.Response {
+	kind := star.Kind(web.Params(c.Req)[":kind"])
+	uid := web.Params(c.Req)[":uid"]
+	ctx := c.Req.Context()
+
+	switch kind {
+	case star.KindDashboard:
+		query := &models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+		if err := hs.dashboardService.GetDashboard(ctx, query); err != nil {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		if err := hs.starService.Delete(ctx, &star.UnstarDashboardCommand{UserID: c.UserId, DashboardID: query.Result.Id}); err != nil {
+			return response.Error(500, "Failed to unstar dashboard", err)
+		}
+	case star.KindFolder, star.KindDataSource:
+		if err := hs.starService.DeleteItem(ctx, &star.UnstarItemCommand{UserID: c.UserId, OrgID: c.OrgId, Kind: kind, UID: uid}); err != nil {
+			return response.Error(500, "Failed to unstar item", err)
+		}
+	case star.KindQuery:
+		if _, err := hs.QueryHistoryService.UnstarQueryInQueryHistory(ctx, c.SignedInUser, uid); err != nil && !errors.Is(err, queryhistory.ErrStarredQueryNotFound) {
+			return response.Error(500, "Failed to unstar query", err)
+		}
+	default:
+		return response.Error(http.StatusBadRequest, "Unknown star kind", star.ErrUnknownKind)
+	}
+
+	return response.Success("Item unstarred")
+}
+
+func (hs *HTTPServer) getViewableDashboardByUID(c *models.ReqContext, uid string) (*models.Dashboard, response.Response) {
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+	if err := hs.dashboardService.GetDashboard(c.Req.Context(), query); err != nil {
+		return nil, response.Error(http.StatusNotFound, "Dashboard not found", err)
+	}
+
+	g := guardian.New(c.Req.Context(), query.Result.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return nil, dashboardGuardianResponse(err)
+	}
+	return query.Result, nil
 }
 
 func (hs *HTTPServer) StarDashboard(c *models.ReqContext) response.Response {
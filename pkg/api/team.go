@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -8,6 +9,8 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -197,9 +200,48 @@ func (hs *HTTPServer) GetTeamByID(c *models.ReqContext) response.Response {
 	query.Result.AccessControl = hs.getAccessControlMetadata(c, c.OrgId, "teams:id:", strconv.FormatInt(query.Result.Id, 10))
 
 	query.Result.AvatarUrl = dtos.GetGravatarUrlWithDefault(query.Result.Email, query.Result.Name)
+
+	homeDashboard, err := hs.getTeamHomeDashboard(c, teamId)
+	if err != nil {
+		return response.Error(500, "Failed to get team home dashboard", err)
+	}
+	query.Result.HomeDashboard = homeDashboard
+
 	return response.JSON(http.StatusOK, &query.Result)
 }
 
+// getTeamHomeDashboard returns the home dashboard in the preferences of the team, or nil
+// if the team has none or the user can't view it.
+func (hs *HTTPServer) getTeamHomeDashboard(c *models.ReqContext, teamID int64) (*models.TeamHomeDashboard, error) {
+	preference, err := hs.preferenceService.Get(c.Req.Context(), &pref.GetPreferenceQuery{OrgID: c.OrgId, TeamID: teamID})
+	if err != nil {
+		return nil, err
+	}
+	if preference == nil || preference.HomeDashboardID == 0 {
+		return nil, nil
+	}
+
+	query := models.GetDashboardQuery{Id: preference.HomeDashboardID, OrgId: c.OrgId}
+	if err := hs.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	g := guardian.New(c.Req.Context(), query.Result.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return nil, err
+	}
+
+	return &models.TeamHomeDashboard{
+		Id:    query.Result.Id,
+		Uid:   query.Result.Uid,
+		Title: query.Result.Title,
+		Url:   query.Result.GetUrl(),
+	}, nil
+}
+
 // GET /api/teams/:teamId/preferences
 func (hs *HTTPServer) GetTeamPreferences(c *models.ReqContext) response.Response {
 	teamId, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
@@ -240,3 +282,61 @@ func (hs *HTTPServer) UpdateTeamPreferences(c *models.ReqContext) response.Respo
 
 	return hs.updatePreferencesFor(c.Req.Context(), orgId, 0, teamId, &dtoCmd)
 }
+
+// PUT /api/teams/:teamId/preferences/home-dashboard
+func (hs *HTTPServer) SetTeamHomeDashboard(c *models.ReqContext) response.Response {
+	cmd := dtos.SetTeamHomeDashboardCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	teamId, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	orgId := c.OrgId
+
+	if hs.AccessControl.IsDisabled() {
+		if err := hs.teamGuardian.CanAdmin(c.Req.Context(), orgId, teamId, c.SignedInUser); err != nil {
+			return response.Error(403, "Not allowed to update team preferences.", err)
+		}
+	}
+
+	var dashboardID int64
+	if cmd.HomeDashboardUID != "" {
+		query := models.GetDashboardQuery{Uid: cmd.HomeDashboardUID, OrgId: orgId}
+		if err := hs.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+			return response.Error(404, "Dashboard not found", err)
+		}
+
+		canView, err := teamCanViewDashboard(c.Req.Context(), query.Result, teamId, c.SignedInUser)
+		if err != nil {
+			return response.Error(500, "Failed to check dashboard permissions", err)
+		}
+		if !canView {
+			return response.Error(400, "The team doesn't have permission to view the dashboard", nil)
+		}
+		dashboardID = query.Result.Id
+	}
+
+	return hs.patchPreferencesFor(c.Req.Context(), orgId, 0, teamId, &dtos.PatchPrefsCmd{HomeDashboardID: &dashboardID})
+}
+
+// teamCanViewDashboard returns true when the members of the team can view the dashboard,
+// through a permission of the team or of the Viewer role every member has.
+func teamCanViewDashboard(ctx context.Context, dash *models.Dashboard, teamID int64, user *models.SignedInUser) (bool, error) {
+	acl, err := guardian.New(ctx, dash.Id, dash.OrgId, user).GetAcl()
+	if err != nil {
+		return false, err
+	}
+	for _, item := range acl {
+		if item.Permission < models.PERMISSION_VIEW {
+			continue
+		}
+		if item.TeamId == teamID || (item.Role != nil && *item.Role == models.ROLE_VIEWER) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
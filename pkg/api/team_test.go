@@ -11,10 +11,13 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log/logtest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -435,3 +438,107 @@ func TestTeamAPIEndpoint_UpdateTeamPreferences_RBAC(t *testing.T) {
 		assert.Equal(t, "dark", preference.Theme)
 	})
 }
+
+func TestTeamAPIEndpoint_SetTeamHomeDashboard_RBAC(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	sc.db = sqlstore.InitTestDB(t)
+	_, err := sc.db.CreateTeam("team1", "", 1)
+	require.NoError(t, err)
+
+	sc.hs.preferenceService = preftest.NewPreferenceServiceFake()
+	dashSvc := dashboards.NewFakeDashboardService(t)
+	dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 3, Uid: q.Uid, OrgId: 1, Title: "Home"}
+	}).Return(nil).Maybe()
+	sc.hs.dashboardService = dashSvc
+
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	editor := models.ROLE_EDITOR
+	viewer := models.ROLE_VIEWER
+
+	setInitCtxSignedInViewer(sc.initCtx)
+
+	setHomeDashboard := func(body string) int {
+		return callAPI(sc.server, http.MethodPut, fmt.Sprintf(detailTeamPreferenceURL+"/home-dashboard", 1), strings.NewReader(body), t).Code
+	}
+
+	t.Run("Access control prevents setting the home dashboard with the incorrect permissions", func(t *testing.T) {
+		setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsWrite, Scope: "teams:id:2"}}, 1)
+		assert.Equal(t, http.StatusForbidden, setHomeDashboard(`{"homeDashboardUID": "home"}`))
+	})
+
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsWrite, Scope: "teams:id:1"}}, 1)
+
+	t.Run("Sets a dashboard the team can view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{GetAclValue: []*models.DashboardAclInfoDTO{
+			{TeamId: 1, Permission: models.PERMISSION_VIEW},
+		}})
+		assert.Equal(t, http.StatusOK, setHomeDashboard(`{"homeDashboardUID": "home"}`))
+	})
+
+	t.Run("Sets a dashboard every viewer can view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{GetAclValue: []*models.DashboardAclInfoDTO{
+			{Role: &viewer, Permission: models.PERMISSION_VIEW},
+		}})
+		assert.Equal(t, http.StatusOK, setHomeDashboard(`{"homeDashboardUID": "home"}`))
+	})
+
+	t.Run("Returns 400 for a dashboard the team can't view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{GetAclValue: []*models.DashboardAclInfoDTO{
+			{TeamId: 2, Permission: models.PERMISSION_ADMIN},
+			{Role: &editor, Permission: models.PERMISSION_EDIT},
+		}})
+		assert.Equal(t, http.StatusBadRequest, setHomeDashboard(`{"homeDashboardUID": "home"}`))
+	})
+
+	t.Run("Resets the home dashboard", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, setHomeDashboard(`{"homeDashboardUID": ""}`))
+	})
+}
+
+func TestTeamAPIEndpoint_GetTeamByID_HomeDashboard(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	sc.db = sqlstore.InitTestDB(t)
+	_, err := sc.db.CreateTeam("team1", "", 1)
+	require.NoError(t, err)
+
+	prefService := preftest.NewPreferenceServiceFake()
+	prefService.ExpectedPreference = &pref.Preference{TeamID: 1, HomeDashboardID: 3}
+	sc.hs.preferenceService = prefService
+	dashSvc := dashboards.NewFakeDashboardService(t)
+	dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 3, Uid: "home", Slug: "home", OrgId: 1, Title: "Home"}
+	}).Return(nil)
+	sc.hs.dashboardService = dashSvc
+
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	setInitCtxSignedInViewer(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsRead, Scope: "teams:id:1"}}, 1)
+
+	getTeam := func(t *testing.T) *models.TeamDTO {
+		response := callAPI(sc.server, http.MethodGet, fmt.Sprintf(detailTeamURL, 1), http.NoBody, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		res := &models.TeamDTO{}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), res))
+		return res
+	}
+
+	t.Run("Returns the home dashboard of the team", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+		assert.Equal(t, &models.TeamHomeDashboard{Id: 3, Uid: "home", Title: "Home", Url: "/d/home/home"}, getTeam(t).HomeDashboard)
+	})
+
+	t.Run("Leaves out a home dashboard the user can't view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+		assert.Nil(t, getTeam(t).HomeDashboard)
+	})
+}
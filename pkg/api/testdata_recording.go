@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RecordDashboard records the results of the queries of the panels of a
+// dashboard, to replay them with the TestData data source.
+// POST /api/dashboards/uid/:uid/recordings
+func (hs *HTTPServer) RecordDashboard(c *models.ReqContext) response.Response {
+	cmd := testdatarecording.RecordCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.SkipCache = c.SkipCache
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	// recording runs every query of the dashboard and stores the results on the
+	// server, so it's reserved to the users who can edit the dashboard
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	result, err := hs.testDataRecording.Record(c.Req.Context(), c.SignedInUser, dash, &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, testdatarecording.ErrInvalidRecordingName),
+			errors.Is(err, testdatarecording.ErrTestDataNotFound),
+			errors.Is(err, testdatarecording.ErrNothingRecorded),
+			errors.Is(err, dashboardvariables.ErrVariableCycle),
+			errors.Is(err, dashboardvariables.ErrInvalidTemplate):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return hs.handleQueryMetricsError(err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
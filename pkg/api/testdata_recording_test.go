@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+	"github.com/grafana/grafana/pkg/services/testdatarecording/testdatarecordingtest"
+)
+
+func TestAPIEndpoint_RecordDashboard(t *testing.T) {
+	sc := setupHTTPServer(t, true, true)
+	setInitCtxSignedInEditor(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeDashboardsAll}}, sc.initCtx.OrgId)
+
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 1, Uid: q.Uid, Data: simplejson.New()}
+	}).Return(nil)
+	sc.hs.dashboardService = dashboardService
+	recording := testdatarecordingtest.NewTestDataRecordingServiceFake()
+	sc.hs.testDataRecording = recording
+
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: true})
+
+	t.Run("Returns the recording of the dashboard", func(t *testing.T) {
+		recording.ExpectedResult = &testdatarecording.RecordResult{Name: "demo", Panels: []int64{2}, Failed: map[int64]string{3: "timeout"}}
+		recording.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/recordings", strings.NewReader(`{"name":"demo","from":"now-1h","to":"now"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var result testdatarecording.RecordResult
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+		assert.Equal(t, "demo", result.Name)
+		assert.Equal(t, []int64{2}, result.Panels)
+		assert.Equal(t, map[int64]string{3: "timeout"}, result.Failed)
+	})
+
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{err: testdatarecording.ErrInvalidRecordingName, code: http.StatusBadRequest},
+		{err: testdatarecording.ErrTestDataNotFound, code: http.StatusBadRequest},
+		{err: testdatarecording.ErrNothingRecorded, code: http.StatusBadRequest},
+		{err: models.ErrDataSourceAccessDenied, code: http.StatusForbidden},
+	} {
+		t.Run(fmt.Sprintf("Returns %d for %q", tc.code, tc.err), func(t *testing.T) {
+			recording.ExpectedError = tc.err
+			response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/recordings", strings.NewReader(`{"name":"demo"}`), t)
+			assert.Equal(t, tc.code, response.Code)
+		})
+	}
+
+	t.Run("Returns 403 when the user can't edit the dashboard", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: false})
+		recording.ExpectedError = nil
+		response := callAPI(sc.server, http.MethodPost, "/api/dashboards/uid/dash/recordings", strings.NewReader(`{"name":"demo"}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/timeregions"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SearchTimeRegionCalendars lists the time region calendars of the organization.
+// GET /api/time-regions/calendars
+func (hs *HTTPServer) SearchTimeRegionCalendars(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit == 0 {
+		limit = 1000
+	}
+
+	calendars, err := hs.timeRegions.Search(c.Req.Context(), &timeregions.SearchCalendarsQuery{
+		OrgId: c.OrgId,
+		Name:  c.Query("query"),
+		Limit: limit,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Search failed", err)
+	}
+	return response.JSON(http.StatusOK, calendars)
+}
+
+// GetTimeRegionCalendar returns a time region calendar.
+// GET /api/time-regions/calendars/:uid
+func (hs *HTTPServer) GetTimeRegionCalendar(c *models.ReqContext) response.Response {
+	calendar, err := hs.timeRegions.Get(c.Req.Context(), &timeregions.GetCalendarQuery{OrgId: c.OrgId, Uid: web.Params(c.Req)[":uid"]})
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to get time region calendar")
+	}
+	return response.JSON(http.StatusOK, calendar)
+}
+
+// CreateTimeRegionCalendar creates a time region calendar.
+// POST /api/time-regions/calendars
+func (hs *HTTPServer) CreateTimeRegionCalendar(c *models.ReqContext) response.Response {
+	cmd := timeregions.CreateCalendarCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgId = c.OrgId
+
+	calendar, err := hs.timeRegions.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to create time region calendar")
+	}
+	return response.JSON(http.StatusOK, calendar)
+}
+
+// UpdateTimeRegionCalendar replaces the regions and holidays of a time region calendar.
+// PUT /api/time-regions/calendars/:uid
+func (hs *HTTPServer) UpdateTimeRegionCalendar(c *models.ReqContext) response.Response {
+	cmd := timeregions.UpdateCalendarCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgId = c.OrgId
+	cmd.Uid = web.Params(c.Req)[":uid"]
+
+	calendar, err := hs.timeRegions.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to update time region calendar")
+	}
+	return response.JSON(http.StatusOK, calendar)
+}
+
+// DeleteTimeRegionCalendar deletes a time region calendar.
+// DELETE /api/time-regions/calendars/:uid
+func (hs *HTTPServer) DeleteTimeRegionCalendar(c *models.ReqContext) response.Response {
+	err := hs.timeRegions.Delete(c.Req.Context(), &timeregions.DeleteCalendarCommand{OrgId: c.OrgId, Uid: web.Params(c.Req)[":uid"]})
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to delete time region calendar")
+	}
+	return response.Success("Time region calendar deleted")
+}
+
+// GetTimeRegionCalendarWindows returns the periods in the time range where the
+// calendar is active, e.g. to show them as time regions in panels.
+// GET /api/time-regions/calendars/:uid/windows?from=now-7d&to=now
+func (hs *HTTPServer) GetTimeRegionCalendarWindows(c *models.ReqContext) response.Response {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" {
+		from = "now-24h"
+	}
+	if to == "" {
+		to = "now"
+	}
+	timeRange := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid from", err)
+	}
+	toTime, err := timeRange.ParseTo()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid to", err)
+	}
+
+	windows, err := hs.timeRegions.Windows(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"], fromTime, toTime)
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to evaluate time region calendar")
+	}
+	return response.JSON(http.StatusOK, windows)
+}
+
+// GetTimeRegionCalendarActive returns whether the calendar is active at a point in
+// time, now by default.
+// GET /api/time-regions/calendars/:uid/active?time=
+func (hs *HTTPServer) GetTimeRegionCalendarActive(c *models.ReqContext) response.Response {
+	t := time.Now()
+	if value := c.Query("time"); value != "" {
+		parsed, err := legacydata.NewDataTimeRange(value, value).ParseFrom()
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid time", err)
+		}
+		t = parsed
+	}
+
+	active, err := hs.timeRegions.IsActive(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"], t)
+	if err != nil {
+		return timeRegionsErrorResponse(err, "Failed to evaluate time region calendar")
+	}
+	return response.JSON(http.StatusOK, map[string]interface{}{"active": active})
+}
+
+func timeRegionsErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, timeregions.ErrCalendarNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, timeregions.ErrCalendarNameTaken), errors.Is(err, timeregions.ErrCalendarUIDTaken):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, timeregions.ErrCalendarInvalid), errors.Is(err, timeregions.ErrCalendarNameRequired),
+		errors.Is(err, timeregions.ErrCalendarInvalidUID), errors.Is(err, timeregions.ErrCalendarRangeTooLarge):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -280,9 +280,8 @@ func (hs *HTTPServer) ChangeUserPassword(c *models.ReqContext) response.Response
 		return response.Error(401, "Invalid old password", nil)
 	}
 
-	password := models.Password(cmd.NewPassword)
-	if password.IsWeak() {
-		return response.Error(400, "New password is too short", nil)
+	if err := hs.passwordPolicy.Validate(c.Req.Context(), cmd.NewPassword); err != nil {
+		return passwordPolicyErrorResponse(err)
 	}
 
 	cmd.UserId = c.UserId
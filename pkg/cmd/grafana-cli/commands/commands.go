@@ -169,6 +169,18 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "migrate-dashboard-bodies",
+				Usage:  "Moves the bodies of the dashboards from the database to the storage configured in [dashboards.storage], or back with --to-database. Safe to execute multiple times.",
+				Action: runDbCommand(datamigrations.MigrateDashboardBodies),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "to-database",
+						Usage: "Move the bodies from the configured storage back to the database",
+						Value: false,
+					},
+				},
+			},
 		},
 	},
 	{
@@ -190,6 +202,16 @@ var adminCommands = []*cli.Command{
 				Usage:  "Rotates persisted data encryption keys. Returns ok unless there is an error. Safe to execute multiple times.",
 				Action: runRunnerCommand(secretsmigrations.ReEncryptDEKS),
 			},
+			{
+				Name:   "upgrade-algorithm",
+				Usage:  "Re-encrypts the secrets that are not encrypted with the algorithm configured in [security.encryption] yet, reporting progress and failures. Safe to execute multiple times.",
+				Action: runRunnerCommand(secretsmigrations.UpgradeAlgorithm),
+			},
+			{
+				Name:   "fips-check",
+				Usage:  "Reports the secrets encrypted with algorithms that are not allowed in FIPS mode. Returns an error if there are any.",
+				Action: runRunnerCommand(secretsmigrations.CheckFIPSPolicy),
+			},
 		},
 	},
 }
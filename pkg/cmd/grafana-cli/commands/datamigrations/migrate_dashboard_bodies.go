@@ -0,0 +1,35 @@
+package datamigrations
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// MigrateDashboardBodies moves the bodies of the dashboards kept in the database
+// to the storage configured in [dashboards.storage], or back to the database
+// with the to-database flag.
+func MigrateDashboardBodies(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	toDatabase := c.Bool("to-database")
+	moved, err := database.ProvideDashboardStore(sqlStore).MigrateDashboardBodies(context.Background(), toDatabase)
+	if moved > 0 {
+		destination := sqlStore.Cfg.DashboardStorage.Type
+		if toDatabase {
+			destination = "database"
+		}
+		logger.Infof("%s Moved %d bodies of dashboards and dashboard versions to the %s storage\n", color.GreenString("✔"), moved, destination)
+	}
+	if err != nil {
+		return err
+	}
+
+	if moved == 0 {
+		logger.Infof("%s All dashboard bodies are already migrated\n", color.GreenString("✔"))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package secretsmigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/runner"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/fips"
+)
+
+// CheckFIPSPolicy reports the secrets that need to be re-encrypted before
+// Grafana can start in FIPS mode.
+func CheckFIPSPolicy(_ utils.CommandLine, runner runner.Runner) error {
+	violations, err := fips.ProvideService(runner.Cfg, runner.SQLStore).Check(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(violations) == 0 {
+		logger.Info("All secrets comply with the FIPS policy")
+		return nil
+	}
+
+	for _, v := range violations {
+		logger.Warn("FIPS policy violation", "component", v.Component, "description", v.Description)
+	}
+	return fmt.Errorf("found %d FIPS policy violations, enable FIPS mode and run upgrade-algorithm to fix them", len(violations))
+}
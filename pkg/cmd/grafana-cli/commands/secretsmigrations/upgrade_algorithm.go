@@ -0,0 +1,29 @@
+package secretsmigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/runner"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/secrets/migrator"
+)
+
+// UpgradeAlgorithm re-encrypts the secrets that are not encrypted with the
+// algorithm configured in [security.encryption] yet.
+func UpgradeAlgorithm(_ utils.CommandLine, runner runner.Runner) error {
+	m := migrator.ProvideSecretsMigrator(runner.Cfg, runner.SQLStore, runner.SecretsService, runner.Features, nil)
+	report, err := m.UpgradeSecrets(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, t := range report.Tables {
+		logger.Info("Secrets re-encryption report", "table", t.Table, "total", t.Total, "upToDate", t.UpToDate, "upgraded", t.Upgraded, "failed", t.Failed)
+	}
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d secrets could not be re-encrypted", failed)
+	}
+	return nil
+}
@@ -26,6 +26,6 @@ var wireExtsSet = wire.NewSet(
 	wire.Bind(new(setting.Provider), new(*setting.OSSImpl)),
 	osskmsproviders.ProvideService,
 	wire.Bind(new(kmsproviders.Service), new(osskmsproviders.Service)),
-	ossencryption.ProvideService,
+	ossencryption.ProvideServiceFromConfig,
 	wire.Bind(new(encryption.Internal), new(*ossencryption.Service)),
 )
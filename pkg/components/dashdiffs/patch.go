@@ -0,0 +1,213 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// VolatilePaths are the paths of the fields changing with every save, or
+// without the dashboard being edited.
+var VolatilePaths = []string{"/version", "/iteration", "/time", "/refresh"}
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// PatchOperation is an operation of a JSON patch, as defined by RFC 6902.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves out the value of remove operations.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(map[string]interface{}{"op": o.Op, "path": o.Path})
+	}
+	return json.Marshal(map[string]interface{}{"op": o.Op, "path": o.Path, "value": o.Value})
+}
+
+// CalculatePatch returns the JSON patch turning baseData into newData. The
+// operations are meant to be applied in order: the elements removed from an
+// array are removed from the last one.
+//
+// The changes to the paths to ignore, or the paths under them, are left out of
+// the patch. The paths are JSON pointers, where * matches any key or index.
+func CalculatePatch(baseData, newData *simplejson.Json, ignore []string) ([]PatchOperation, error) {
+	base, err := decode(baseData)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := decode(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &patcher{ops: []PatchOperation{}}
+	for _, path := range ignore {
+		if path == "" {
+			continue
+		}
+		p.ignore = append(p.ignore, parsePointer(path))
+	}
+	p.diff(nil, base, updated)
+	return p.ops, nil
+}
+
+// decode decodes the JSON again, so that the numbers of both sides are of the
+// same type.
+func decode(data *simplejson.Json) (interface{}, error) {
+	if data == nil {
+		return map[string]interface{}{}, nil
+	}
+	raw, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type patcher struct {
+	ignore [][]string
+	ops    []PatchOperation
+}
+
+func (p *patcher) diff(path []string, base, updated interface{}) {
+	if p.ignored(path) {
+		return
+	}
+
+	switch b := base.(type) {
+	case map[string]interface{}:
+		if u, ok := updated.(map[string]interface{}); ok {
+			p.diffObjects(path, b, u)
+			return
+		}
+	case []interface{}:
+		if u, ok := updated.([]interface{}); ok {
+			p.diffArrays(path, b, u)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(base, updated) {
+		p.add(OpReplace, path, updated)
+	}
+}
+
+func (p *patcher) diffObjects(path []string, base, updated map[string]interface{}) {
+	keys := make([]string, 0, len(base)+len(updated))
+	for k := range base {
+		keys = append(keys, k)
+	}
+	for k := range updated {
+		if _, ok := base[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := appendPath(path, k)
+		b, inBase := base[k]
+		u, inUpdated := updated[k]
+		switch {
+		case !inUpdated:
+			p.add(OpRemove, child, nil)
+		case !inBase:
+			p.add(OpAdd, child, u)
+		default:
+			p.diff(child, b, u)
+		}
+	}
+}
+
+func (p *patcher) diffArrays(path []string, base, updated []interface{}) {
+	common := len(base)
+	if len(updated) < common {
+		common = len(updated)
+	}
+
+	for i := 0; i < common; i++ {
+		p.diff(appendPath(path, strconv.Itoa(i)), base[i], updated[i])
+	}
+	for i := len(base) - 1; i >= common; i-- {
+		p.add(OpRemove, appendPath(path, strconv.Itoa(i)), nil)
+	}
+	for i := common; i < len(updated); i++ {
+		p.add(OpAdd, appendPath(path, strconv.Itoa(i)), updated[i])
+	}
+}
+
+func (p *patcher) add(op string, path []string, value interface{}) {
+	if p.ignored(path) {
+		return
+	}
+	p.ops = append(p.ops, PatchOperation{Op: op, Path: formatPointer(path), Value: value})
+}
+
+func (p *patcher) ignored(path []string) bool {
+	for _, ignored := range p.ignore {
+		if matchPrefix(ignored, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix returns whether the path is the pattern or is under it.
+func matchPrefix(pattern, path []string) bool {
+	if len(pattern) > len(path) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func appendPath(path []string, segment string) []string {
+	child := make([]string, len(path), len(path)+1)
+	copy(child, path)
+	return append(child, segment)
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func formatPointer(path []string) string {
+	var sb strings.Builder
+	for _, segment := range path {
+		sb.WriteString("/")
+		sb.WriteString(pointerEscaper.Replace(segment))
+	}
+	return sb.String()
+}
+
+func parsePointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return []string{}
+	}
+	segments := strings.Split(pointer, "/")
+	for i, segment := range segments {
+		segments[i] = pointerUnescaper.Replace(segment)
+	}
+	return segments
+}
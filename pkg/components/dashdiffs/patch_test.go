@@ -0,0 +1,109 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculatePatch(t *testing.T) {
+	base := mustJSON(t, `{
+		"title": "Dashboard",
+		"version": 3,
+		"iteration": 1651000000000,
+		"time": {"from": "now-6h", "to": "now"},
+		"editable": true,
+		"tags": ["a", "b", "c"],
+		"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 0}},
+			{"id": 2, "title": "Memory", "gridPos": {"x": 12, "y": 0}}
+		],
+		"annotations/list": [],
+		"description": "removed"
+	}`)
+	updated := mustJSON(t, `{
+		"title": "Renamed",
+		"version": 4,
+		"iteration": 1652000000000,
+		"time": {"from": "now-1h", "to": "now"},
+		"editable": false,
+		"tags": ["a"],
+		"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 8}},
+			{"id": 2, "title": "Memory", "gridPos": {"x": 12, "y": 8}, "description": null},
+			{"id": 3, "title": "Disk"}
+		],
+		"annotations/list": [{"name": "Deploys"}]
+	}`)
+
+	t.Run("returns the operations turning the base into the new version", func(t *testing.T) {
+		patch, err := CalculatePatch(base, updated, nil)
+		require.NoError(t, err)
+		require.Equal(t, []PatchOperation{
+			{Op: OpAdd, Path: "/annotations~1list/0", Value: map[string]interface{}{"name": "Deploys"}},
+			{Op: OpRemove, Path: "/description"},
+			{Op: OpReplace, Path: "/editable", Value: false},
+			{Op: OpReplace, Path: "/iteration", Value: float64(1652000000000)},
+			{Op: OpReplace, Path: "/panels/0/gridPos/y", Value: float64(8)},
+			{Op: OpAdd, Path: "/panels/1/description", Value: nil},
+			{Op: OpReplace, Path: "/panels/1/gridPos/y", Value: float64(8)},
+			{Op: OpAdd, Path: "/panels/2", Value: map[string]interface{}{"id": float64(3), "title": "Disk"}},
+			{Op: OpRemove, Path: "/tags/2"},
+			{Op: OpRemove, Path: "/tags/1"},
+			{Op: OpReplace, Path: "/time/from", Value: "now-1h"},
+			{Op: OpReplace, Path: "/title", Value: "Renamed"},
+			{Op: OpReplace, Path: "/version", Value: float64(4)},
+		}, patch)
+	})
+
+	t.Run("leaves out the ignored paths", func(t *testing.T) {
+		patch, err := CalculatePatch(base, updated, append([]string{"/panels/*/gridPos", "/annotations~1list", ""}, VolatilePaths...))
+		require.NoError(t, err)
+		require.Equal(t, []PatchOperation{
+			{Op: OpRemove, Path: "/description"},
+			{Op: OpReplace, Path: "/editable", Value: false},
+			{Op: OpAdd, Path: "/panels/1/description", Value: nil},
+			{Op: OpAdd, Path: "/panels/2", Value: map[string]interface{}{"id": float64(3), "title": "Disk"}},
+			{Op: OpRemove, Path: "/tags/2"},
+			{Op: OpRemove, Path: "/tags/1"},
+			{Op: OpReplace, Path: "/title", Value: "Renamed"},
+		}, patch)
+	})
+
+	t.Run("returns an empty patch for the same dashboard", func(t *testing.T) {
+		patch, err := CalculatePatch(base, base, nil)
+		require.NoError(t, err)
+		require.Empty(t, patch)
+		require.NotNil(t, patch)
+	})
+
+	t.Run("compares with an empty dashboard when there is no base", func(t *testing.T) {
+		patch, err := CalculatePatch(nil, mustJSON(t, `{"title": "New"}`), nil)
+		require.NoError(t, err)
+		require.Equal(t, []PatchOperation{{Op: OpAdd, Path: "/title", Value: "New"}}, patch)
+	})
+
+	t.Run("keeps the values of add and replace operations only", func(t *testing.T) {
+		raw, err := json.Marshal([]PatchOperation{
+			{Op: OpReplace, Path: "/editable", Value: false},
+			{Op: OpAdd, Path: "/description", Value: nil},
+			{Op: OpRemove, Path: "/tags/1"},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `[
+			{"op": "replace", "path": "/editable", "value": false},
+			{"op": "add", "path": "/description", "value": null},
+			{"op": "remove", "path": "/tags/1"}
+		]`, string(raw))
+	})
+}
+
+func mustJSON(t *testing.T, raw string) *simplejson.Json {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(raw))
+	require.NoError(t, err)
+	return data
+}
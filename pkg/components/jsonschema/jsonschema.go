@@ -0,0 +1,248 @@
+// Package jsonschema validates JSON values against a subset of JSON Schema, which
+// is enough to describe the settings of plugins: types, properties, required
+// properties, enums, string length and patterns, number ranges and array items.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a JSON Schema. Keywords that aren't supported are ignored.
+type Schema struct {
+	Type        Types              `json:"type,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	// AdditionalProperties rejects properties that aren't listed when false.
+	AdditionalProperties *bool         `json:"additionalProperties,omitempty"`
+	Items                *Schema       `json:"items,omitempty"`
+	Enum                 []interface{} `json:"enum,omitempty"`
+	Pattern              string        `json:"pattern,omitempty"`
+	MinLength            *int          `json:"minLength,omitempty"`
+	MaxLength            *int          `json:"maxLength,omitempty"`
+	Minimum              *float64      `json:"minimum,omitempty"`
+	Maximum              *float64      `json:"maximum,omitempty"`
+}
+
+// Types are the types a value can have, a schema can have a single type or a list.
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// FieldError is a value that doesn't match its schema.
+type FieldError struct {
+	// Field is the path of the value, e.g. jsonData.timeout or jsonData.headers[0].
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate returns the errors of the value, which is decoded from JSON, with the
+// fields prefixed by path. It returns an error if the schema is invalid.
+func (s *Schema) Validate(path string, value interface{}) ([]FieldError, error) {
+	v := &validator{errors: []FieldError{}}
+	if err := v.validate(s, path, value); err != nil {
+		return nil, err
+	}
+	return v.errors, nil
+}
+
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s *Schema, path string, value interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	if len(s.Type) > 0 && !matchesType(s.Type, value) {
+		v.addf(path, "must be of type %s", strings.Join(s.Type, " or "))
+		return nil
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		v.addf(path, "must be one of %s", strings.Join(allowed, ", "))
+	}
+
+	switch t := value.(type) {
+	case string:
+		return v.validateString(s, path, t)
+	case map[string]interface{}:
+		return v.validateObject(s, path, t)
+	case []interface{}:
+		for i, item := range t {
+			if err := v.validate(s.Items, fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	default:
+		if n, ok := toNumber(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				v.addf(path, "must be greater than or equal to %v", *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				v.addf(path, "must be less than or equal to %v", *s.Maximum)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateString(s *Schema, path, value string) error {
+	length := len([]rune(value))
+	if s.MinLength != nil && length < *s.MinLength {
+		v.addf(path, "must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.addf(path, "must be at most %d characters long", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern of %s: %w", path, err)
+		}
+		if !re.MatchString(value) {
+			v.addf(path, "must match the pattern %s", s.Pattern)
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateObject(s *Schema, path string, value map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			v.addf(join(path, name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				v.addf(join(path, name), "is not allowed")
+			}
+			continue
+		}
+		if err := v.validate(property, join(path, name), value[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesType(types Types, value interface{}) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "number":
+			if _, ok := toNumber(value); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := toNumber(value); ok && n == math.Trunc(n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if en, ok := toNumber(e); ok {
+			if vn, ok := toNumber(value); ok && en == vn {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,69 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["url", "timeout"],
+	"additionalProperties": false,
+	"properties": {
+		"url": {"type": "string", "pattern": "^https?://"},
+		"timeout": {"type": "integer", "minimum": 1, "maximum": 300},
+		"mode": {"type": "string", "enum": ["proxy", "direct"]},
+		"database": {"type": ["string", "null"], "minLength": 1, "maxLength": 8},
+		"headers": {"type": "array", "items": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	var schema Schema
+	require.NoError(t, json.Unmarshal([]byte(testSchema), &schema))
+
+	validate := func(value string) []FieldError {
+		t.Helper()
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+		var decoded interface{}
+		require.NoError(t, decoder.Decode(&decoded))
+		errs, err := schema.Validate("jsonData", decoded)
+		require.NoError(t, err)
+		return errs
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		require.Empty(t, validate(`{"url": "http://localhost", "timeout": 30, "mode": "proxy", "database": null, "headers": [{"name": "X-Org"}]}`))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.Equal(t, []FieldError{
+			{Field: "jsonData.timeout", Message: "is required"},
+			{Field: "jsonData.database", Message: "must be at most 8 characters long"},
+			{Field: "jsonData.headers[0].name", Message: "is required"},
+			{Field: "jsonData.mode", Message: "must be one of proxy, direct"},
+			{Field: "jsonData.other", Message: "is not allowed"},
+			{Field: "jsonData.url", Message: "must match the pattern ^https?://"},
+		}, validate(`{"url": "localhost", "mode": "server", "database": "production", "headers": [{}], "other": true}`))
+	})
+
+	t.Run("types", func(t *testing.T) {
+		require.Equal(t, []FieldError{
+			{Field: "jsonData.timeout", Message: "must be of type integer"},
+			{Field: "jsonData.url", Message: "must be of type string"},
+		}, validate(`{"url": 1, "timeout": 1.5}`))
+		require.Equal(t, []FieldError{
+			{Field: "jsonData", Message: "must be of type object"},
+		}, validate(`[]`))
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		s := &Schema{Properties: map[string]*Schema{"url": {Pattern: "("}}}
+		_, err := s.Validate("", map[string]interface{}{"url": "x"})
+		require.Error(t, err)
+	})
+}
@@ -19,6 +19,11 @@ type OrgUpdated struct {
 	Name      string    `json:"name"`
 }
 
+type OrgDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+}
+
 type UserCreated struct {
 	Timestamp time.Time `json:"timestamp"`
 	Id        int64     `json:"id"`
@@ -47,6 +52,20 @@ type UserUpdated struct {
 	Email     string    `json:"email"`
 }
 
+// UserDeleted is published when a user is deleted. OrgIds are the
+// organizations the user was a member of.
+type UserDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	OrgIds    []int64   `json:"org_ids"`
+}
+
+type TeamDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	OrgId     int64     `json:"org_id"`
+}
+
 type DataSourceDeleted struct {
 	Timestamp time.Time `json:"timestamp"`
 	Name      string    `json:"name"`
@@ -62,3 +81,73 @@ type DataSourceCreated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+type DataSourceUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+// DashboardMoved is published when a dashboard is moved to another folder.
+type DashboardMoved struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ID          int64     `json:"id"`
+	UID         string    `json:"uid"`
+	OrgID       int64     `json:"org_id"`
+	OldFolderID int64     `json:"old_folder_id"`
+	NewFolderID int64     `json:"new_folder_id"`
+}
+
+// DashboardUIDChanged is published when the uid of a dashboard or a folder is changed.
+type DashboardUIDChanged struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"org_id"`
+	IsFolder  bool      `json:"is_folder"`
+	OldUID    string    `json:"old_uid"`
+	NewUID    string    `json:"new_uid"`
+}
+
+// PreferencesUpdated is published when the preferences of an organization, a
+// team or a user are saved. Scope is org, team or user, and Old is nil when
+// the preferences are saved for the first time.
+type PreferencesUpdated struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Scope     string             `json:"scope"`
+	OrgID     int64              `json:"org_id"`
+	TeamID    int64              `json:"team_id"`
+	UserID    int64              `json:"user_id"`
+	Old       *PreferencesValues `json:"old"`
+	New       *PreferencesValues `json:"new"`
+}
+
+// PreferencesValues are the values of the preferences in a PreferencesUpdated
+// event.
+type PreferencesValues struct {
+	HomeDashboardID      int64  `json:"home_dashboard_id"`
+	Timezone             string `json:"timezone"`
+	WeekStart            string `json:"week_start"`
+	Theme                string `json:"theme"`
+	Locale               string `json:"locale"`
+	DefaultDatasourceUID string `json:"default_datasource_uid"`
+}
+
+// DashboardViewed is published when a dashboard is loaded.
+type DashboardViewed struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"org_id"`
+	UserID    int64     `json:"user_id"`
+}
+
+// DashboardQueried is published when the queries of a dashboard panel are run,
+// the dashboard is identified by id or by uid.
+type DashboardQueried struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	PanelID   int64     `json:"panel_id"`
+}
@@ -0,0 +1,280 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// calculateOperators are the binary operators supported by a CalculateFieldCommand.
+var calculateOperators = []string{"+", "-", "*", "/", "%", "**"}
+
+// CalculateFieldCommand is an expression command that adds a field calculated
+// with a binary operation between two fields of the same query, or a field and
+// a number. Fields are the series and numbers of the input, matched by their
+// name, and the operation is applied to the fields that share the same labels.
+type CalculateFieldCommand struct {
+	VarToCalculate string
+	// Left and Right are the names of the fields to calculate with, or numbers.
+	Left     string
+	Operator string
+	Right    string
+	// Alias is the name of the calculated field.
+	Alias string
+	// KeepFields returns the fields of the input along with the calculated ones.
+	KeepFields bool
+}
+
+// NewCalculateFieldCommand creates a new CalculateFieldCommand.
+func NewCalculateFieldCommand(refID, varToCalculate, left, operator, right, alias string, keepFields bool) (*CalculateFieldCommand, error) {
+	if left == "" || right == "" {
+		return nil, fmt.Errorf("calculate field for refId %v needs a left and a right operand", refID)
+	}
+	if isNumber(left) && isNumber(right) {
+		return nil, fmt.Errorf("calculate field for refId %v needs at least one field as operand, got two numbers", refID)
+	}
+	supported := false
+	for _, op := range calculateOperators {
+		if op == operator {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("calculate field for refId %v has unsupported operator '%v'. Supported only: [%v]", refID, operator, strings.Join(calculateOperators, ","))
+	}
+	if alias == "" {
+		alias = fmt.Sprintf("%v %v %v", left, operator, right)
+	}
+
+	return &CalculateFieldCommand{
+		VarToCalculate: varToCalculate,
+		Left:           left,
+		Operator:       operator,
+		Right:          right,
+		Alias:          alias,
+		KeepFields:     keepFields,
+	}, nil
+}
+
+// UnmarshalCalculateFieldCommand creates a CalculateFieldCommand from Grafana's frontend query.
+func UnmarshalCalculateFieldCommand(rn *rawNode) (*CalculateFieldCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to calculate a field from for refId %v", rn.RefID)
+	}
+	exprString, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected calculate_field expression to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToCalculate := strings.TrimPrefix(exprString, "$")
+
+	operands := map[string]string{"left": "", "operator": "", "right": "", "alias": ""}
+	for key := range operands {
+		raw, ok := rn.Query[key]
+		if !ok {
+			continue
+		}
+		switch v := raw.(type) {
+		case string:
+			operands[key] = v
+		case float64:
+			if key != "left" && key != "right" {
+				return nil, fmt.Errorf("expected %v to be a string, got %T for refId %v", key, raw, rn.RefID)
+			}
+			operands[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("expected %v to be a string, got %T for refId %v", key, raw, rn.RefID)
+		}
+	}
+
+	keepFields := false
+	if rawKeep, ok := rn.Query["keepFields"]; ok {
+		keepFields, ok = rawKeep.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected keepFields to be a boolean, got %T for refId %v", rawKeep, rn.RefID)
+		}
+	}
+
+	return NewCalculateFieldCommand(rn.RefID, varToCalculate, operands["left"], operands["operator"], operands["right"], operands["alias"], keepFields)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gc *CalculateFieldCommand) NeedsVars() []string {
+	return []string{gc.VarToCalculate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Labels that don't have both operands don't get a
+// calculated field.
+func (gc *CalculateFieldCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	input := vars[gc.VarToCalculate].Values
+
+	// fields sharing the same labels come from the same row or frame of the query
+	var order []string
+	fields := map[string]map[string]mathexp.Value{}
+	for _, val := range input {
+		var name string
+		switch v := val.(type) {
+		case mathexp.Series:
+			name = v.GetName()
+		case mathexp.Number:
+			name = v.Frame.Fields[0].Name
+		default:
+			return newRes, fmt.Errorf("can only calculate fields of type series or numbers, got type %v from %v", val.Type(), gc.VarToCalculate)
+		}
+		key := val.GetLabels().String()
+		if _, ok := fields[key]; !ok {
+			fields[key] = map[string]mathexp.Value{}
+			order = append(order, key)
+		}
+		fields[key][name] = val
+	}
+
+	if gc.KeepFields {
+		newRes.Values = append(newRes.Values, input...)
+	}
+	for _, key := range order {
+		left, ok := findOperand(gc.Left, fields[key])
+		if !ok {
+			continue
+		}
+		right, ok := findOperand(gc.Right, fields[key])
+		if !ok {
+			continue
+		}
+		newRes.Values = append(newRes.Values, gc.calculate(left, right))
+	}
+
+	return newRes, nil
+}
+
+// calculateOperand is a series or number of the input, or a constant number when val is nil.
+type calculateOperand struct {
+	val    mathexp.Value
+	number float64
+}
+
+// findOperand returns the field called name, or name as a number when there is no
+// such field.
+func findOperand(name string, fields map[string]mathexp.Value) (calculateOperand, bool) {
+	if val, ok := fields[name]; ok {
+		return calculateOperand{val: val}, true
+	}
+	f, err := strconv.ParseFloat(name, 64)
+	if err != nil {
+		return calculateOperand{}, false
+	}
+	return calculateOperand{number: f}, true
+}
+
+// pointsByTime returns the value of the operand at each time, or nil if the
+// operand isn't a series.
+func (o calculateOperand) pointsByTime() map[int64]*float64 {
+	s, ok := o.val.(mathexp.Series)
+	if !ok {
+		return nil
+	}
+	points := make(map[int64]*float64, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		points[t.UnixNano()] = f
+	}
+	return points
+}
+
+func (o calculateOperand) value() *float64 {
+	switch v := o.val.(type) {
+	case mathexp.Number:
+		return v.GetFloat64Value()
+	default:
+		return &o.number
+	}
+}
+
+// calculate applies the operator to the operands. When one of the operands is
+// a series, the result is a series with the points of the left series (or the
+// right one if the left operand isn't a series) that have a value in both
+// operands. Otherwise the result is a number.
+func (gc *CalculateFieldCommand) calculate(left, right calculateOperand) mathexp.Value {
+	labels := data.Labels{}
+	for _, o := range []calculateOperand{left, right} {
+		if o.val != nil {
+			labels = o.val.GetLabels().Copy()
+			break
+		}
+	}
+
+	leftPoints, rightPoints := left.pointsByTime(), right.pointsByTime()
+	if leftPoints == nil && rightPoints == nil {
+		n := mathexp.NewNumber(gc.Alias, labels)
+		n.SetValue(gc.apply(left.value(), right.value()))
+		return n
+	}
+
+	var timestamps mathexp.Series
+	if s, ok := left.val.(mathexp.Series); ok {
+		timestamps = s
+	} else {
+		timestamps = right.val.(mathexp.Series)
+	}
+	at := func(o calculateOperand, points map[int64]*float64, t time.Time) (*float64, bool) {
+		if points == nil {
+			return o.value(), true
+		}
+		f, ok := points[t.UnixNano()]
+		return f, ok
+	}
+
+	s := mathexp.NewSeries(gc.Alias, labels, 0)
+	for i := 0; i < timestamps.Len(); i++ {
+		t := timestamps.GetTime(i)
+		l, ok := at(left, leftPoints, t)
+		if !ok {
+			continue
+		}
+		r, ok := at(right, rightPoints, t)
+		if !ok {
+			continue
+		}
+		s.AppendPoint(t, gc.apply(l, r))
+	}
+	return s
+}
+
+func (gc *CalculateFieldCommand) apply(left, right *float64) *float64 {
+	if left == nil || right == nil {
+		return nil
+	}
+	a, b := *left, *right
+	var r float64
+	switch gc.Operator {
+	case "+":
+		r = a + b
+	case "-":
+		r = a - b
+	case "*":
+		r = a * b
+	case "/":
+		r = a / b
+	case "%":
+		r = math.Mod(a, b)
+	case "**":
+		r = math.Pow(a, b)
+	}
+	return &r
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
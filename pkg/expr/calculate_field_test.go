@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalCalculateFieldCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		isError bool
+		expect  *CalculateFieldCommand
+	}{
+		{
+			name:  "calculates between two fields",
+			query: `{ "type": "calculate_field", "expression": "$A", "left": "used", "operator": "/", "right": "total", "alias": "usage", "keepFields": true }`,
+			expect: &CalculateFieldCommand{
+				VarToCalculate: "A", Left: "used", Operator: "/", Right: "total", Alias: "usage", KeepFields: true,
+			},
+		},
+		{
+			name:  "numbers as operands and default alias",
+			query: `{ "type": "calculate_field", "expression": "A", "left": "used", "operator": "*", "right": 100 }`,
+			expect: &CalculateFieldCommand{
+				VarToCalculate: "A", Left: "used", Operator: "*", Right: "100", Alias: "used * 100",
+			},
+		},
+		{
+			name:    "error without expression",
+			query:   `{ "type": "calculate_field", "left": "used", "operator": "/", "right": "total" }`,
+			isError: true,
+		},
+		{
+			name:    "error without right operand",
+			query:   `{ "type": "calculate_field", "expression": "A", "left": "used", "operator": "/" }`,
+			isError: true,
+		},
+		{
+			name:    "error with two numbers",
+			query:   `{ "type": "calculate_field", "expression": "A", "left": 1, "operator": "/", "right": 2 }`,
+			isError: true,
+		},
+		{
+			name:    "error with unknown operator",
+			query:   `{ "type": "calculate_field", "expression": "A", "left": "used", "operator": "&&", "right": "total" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalCalculateFieldCommand(&rawNode{RefID: "B", Query: qmap})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expect, cmd)
+		})
+	}
+}
+
+func TestCalculateFieldCommand_Execute(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeCalculateSeries("used", data.Labels{"host": "a"}, 1, 2, 3),
+			makeCalculateSeries("total", data.Labels{"host": "a"}, 4, 4),
+			makeCalculateSeries("used", data.Labels{"host": "b"}, 5),
+		}},
+	}
+
+	t.Run("calculates between fields with the same labels", func(t *testing.T) {
+		cmd, err := NewCalculateFieldCommand("B", "A", "used", "/", "total", "usage", false)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, "usage", s.GetName())
+		require.Equal(t, data.Labels{"host": "a"}, s.GetLabels())
+		require.Equal(t, []int64{0, 1}, seriesSeconds(s))
+		require.Equal(t, ptr.Float64(0.25), s.GetValue(0))
+		require.Equal(t, ptr.Float64(0.5), s.GetValue(1))
+	})
+
+	t.Run("calculates between a field and a number", func(t *testing.T) {
+		cmd, err := NewCalculateFieldCommand("B", "A", "1000", "*", "used", "", true)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 5)
+
+		s := res.Values[4].(mathexp.Series)
+		require.Equal(t, "1000 * used", s.GetName())
+		require.Equal(t, data.Labels{"host": "b"}, s.GetLabels())
+		require.Equal(t, ptr.Float64(5000), s.GetValue(0))
+	})
+
+	t.Run("calculates between numbers", func(t *testing.T) {
+		cmd, err := NewCalculateFieldCommand("B", "A", "used", "-", "free", "", false)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{
+				makeJoinNumber("used", data.Labels{"disk": "sda"}, 30),
+				makeJoinNumber("free", data.Labels{"disk": "sda"}, 10),
+			}},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, ptr.Float64(20), res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+}
+
+func makeCalculateSeries(name string, labels data.Labels, values ...float64) mathexp.Series {
+	s := mathexp.NewSeries(name, labels, len(values))
+	for i, v := range values {
+		s.SetPoint(i, time.Unix(int64(i), 0), ptr.Float64(v))
+	}
+	return s
+}
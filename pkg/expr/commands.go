@@ -286,6 +286,12 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeJoin is the CMDType for joining the results of multiple queries.
+	TypeJoin
+	// TypeConvertUnit is the CMDType for converting values to another unit.
+	TypeConvertUnit
+	// TypeCalculateField is the CMDType for calculating a field from two fields of a query.
+	TypeCalculateField
 )
 
 func (gt CommandType) String() string {
@@ -298,6 +304,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeJoin:
+		return "join"
+	case TypeConvertUnit:
+		return "convert_unit"
+	case TypeCalculateField:
+		return "calculate_field"
 	default:
 		return "unknown"
 	}
@@ -314,6 +326,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "join":
+		return TypeJoin, nil
+	case "convert_unit":
+		return TypeConvertUnit, nil
+	case "calculate_field":
+		return TypeCalculateField, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,232 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// unit is a unit that can be converted to and from the base unit of its family,
+// with base = value*factor + offset.
+type unit struct {
+	family string
+	factor float64
+	offset float64
+}
+
+const (
+	kibi = 1 << 10
+	mebi = 1 << 20
+	gibi = 1 << 30
+	tebi = 1 << 40
+	pebi = 1 << 50
+)
+
+// units are the convertible units, keyed by the unit IDs used in the field config.
+var units = map[string]unit{
+	// data, base unit is the byte
+	"bits":      {family: "data", factor: 1.0 / 8},
+	"decbits":   {family: "data", factor: 1.0 / 8},
+	"bytes":     {family: "data", factor: 1},
+	"decbytes":  {family: "data", factor: 1},
+	"kbytes":    {family: "data", factor: kibi},
+	"mbytes":    {family: "data", factor: mebi},
+	"gbytes":    {family: "data", factor: gibi},
+	"tbytes":    {family: "data", factor: tebi},
+	"pbytes":    {family: "data", factor: pebi},
+	"deckbytes": {family: "data", factor: 1e3},
+	"decmbytes": {family: "data", factor: 1e6},
+	"decgbytes": {family: "data", factor: 1e9},
+	"dectbytes": {family: "data", factor: 1e12},
+	"decpbytes": {family: "data", factor: 1e15},
+	// data rate, base unit is the byte per second
+	"bps":    {family: "data rate", factor: 1.0 / 8},
+	"binbps": {family: "data rate", factor: 1.0 / 8},
+	"Bps":    {family: "data rate", factor: 1},
+	"binBps": {family: "data rate", factor: 1},
+	"Kbits":  {family: "data rate", factor: 1e3 / 8},
+	"Kibits": {family: "data rate", factor: kibi / 8},
+	"KBs":    {family: "data rate", factor: 1e3},
+	"KiBs":   {family: "data rate", factor: kibi},
+	"Mbits":  {family: "data rate", factor: 1e6 / 8},
+	"Mibits": {family: "data rate", factor: mebi / 8},
+	"MBs":    {family: "data rate", factor: 1e6},
+	"MiBs":   {family: "data rate", factor: mebi},
+	"Gbits":  {family: "data rate", factor: 1e9 / 8},
+	"Gibits": {family: "data rate", factor: gibi / 8},
+	"GBs":    {family: "data rate", factor: 1e9},
+	"GiBs":   {family: "data rate", factor: gibi},
+	"Tbits":  {family: "data rate", factor: 1e12 / 8},
+	"Tibits": {family: "data rate", factor: tebi / 8},
+	"TBs":    {family: "data rate", factor: 1e12},
+	"TiBs":   {family: "data rate", factor: tebi},
+	// time, base unit is the second
+	"ns": {family: "time", factor: 1e-9},
+	"µs": {family: "time", factor: 1e-6},
+	"ms": {family: "time", factor: 1e-3},
+	"s":  {family: "time", factor: 1},
+	"m":  {family: "time", factor: 60},
+	"h":  {family: "time", factor: 3600},
+	"d":  {family: "time", factor: 86400},
+	// percentage, base unit is the percent
+	"percent":     {family: "percentage", factor: 1},
+	"percentunit": {family: "percentage", factor: 100},
+	// temperature, base unit is the degree Celsius
+	"celsius":    {family: "temperature", factor: 1},
+	"fahrenheit": {family: "temperature", factor: 5.0 / 9, offset: -32 * 5.0 / 9},
+	"kelvin":     {family: "temperature", factor: 1, offset: -273.15},
+}
+
+// ConvertUnitCommand is an expression command that converts the values of
+// series and numbers from one unit to another unit of the same family.
+type ConvertUnitCommand struct {
+	VarToConvert string
+	// FromUnit is the unit of the input values. When empty, the unit set in the
+	// field config of each value is used.
+	FromUnit string
+	ToUnit   string
+}
+
+// NewConvertUnitCommand creates a new ConvertUnitCommand.
+func NewConvertUnitCommand(refID, varToConvert, fromUnit, toUnit string) (*ConvertUnitCommand, error) {
+	to, ok := units[toUnit]
+	if !ok {
+		return nil, fmt.Errorf("unit conversion for refId %v has unsupported unit '%v'. Supported only: [%v]", refID, toUnit, supportedUnits())
+	}
+	if fromUnit != "" {
+		from, ok := units[fromUnit]
+		if !ok {
+			return nil, fmt.Errorf("unit conversion for refId %v has unsupported unit '%v'. Supported only: [%v]", refID, fromUnit, supportedUnits())
+		}
+		if from.family != to.family {
+			return nil, fmt.Errorf("unit conversion for refId %v cannot convert %v (%v) to %v (%v)", refID, fromUnit, from.family, toUnit, to.family)
+		}
+	}
+
+	return &ConvertUnitCommand{
+		VarToConvert: varToConvert,
+		FromUnit:     fromUnit,
+		ToUnit:       toUnit,
+	}, nil
+}
+
+// UnmarshalConvertUnitCommand creates a ConvertUnitCommand from Grafana's frontend query.
+func UnmarshalConvertUnitCommand(rn *rawNode) (*ConvertUnitCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to convert for refId %v", rn.RefID)
+	}
+	exprString, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected convert_unit expression to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToConvert := strings.TrimPrefix(exprString, "$")
+
+	fromUnit := ""
+	if rawFrom, ok := rn.Query["fromUnit"]; ok {
+		fromUnit, ok = rawFrom.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected fromUnit to be a string, got %T for refId %v", rawFrom, rn.RefID)
+		}
+	}
+
+	rawTo, ok := rn.Query["toUnit"]
+	if !ok {
+		return nil, fmt.Errorf("no unit specified to convert to for refId %v", rn.RefID)
+	}
+	toUnit, ok := rawTo.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected toUnit to be a string, got %T for refId %v", rawTo, rn.RefID)
+	}
+
+	return NewConvertUnitCommand(rn.RefID, varToConvert, fromUnit, toUnit)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gc *ConvertUnitCommand) NeedsVars() []string {
+	return []string{gc.VarToConvert}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gc *ConvertUnitCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	to := units[gc.ToUnit]
+
+	for _, val := range vars[gc.VarToConvert].Values {
+		fromUnit := gc.FromUnit
+		if fromUnit == "" {
+			fromUnit = fieldUnit(val)
+			if fromUnit == "" {
+				return newRes, fmt.Errorf("cannot convert %v to %v, it has no unit and no unit to convert from is set", gc.VarToConvert, gc.ToUnit)
+			}
+		}
+		from, ok := units[fromUnit]
+		if !ok {
+			return newRes, fmt.Errorf("cannot convert %v from unsupported unit '%v'", gc.VarToConvert, fromUnit)
+		}
+		if from.family != to.family {
+			return newRes, fmt.Errorf("cannot convert %v from %v (%v) to %v (%v)", gc.VarToConvert, fromUnit, from.family, gc.ToUnit, to.family)
+		}
+
+		convert := func(f *float64) *float64 {
+			if f == nil {
+				return nil
+			}
+			v := ((*f)*from.factor + from.offset - to.offset) / to.factor
+			return &v
+		}
+
+		switch v := val.(type) {
+		case mathexp.Series:
+			s := mathexp.NewSeries(v.GetName(), v.GetLabels(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				s.SetPoint(i, t, convert(f))
+			}
+			s.Frame.Fields[1].Config = &data.FieldConfig{Unit: gc.ToUnit}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.Number:
+			n := mathexp.NewNumber(v.Frame.Fields[0].Name, v.GetLabels())
+			n.SetValue(convert(v.GetFloat64Value()))
+			n.Frame.Fields[0].Config = &data.FieldConfig{Unit: gc.ToUnit}
+			newRes.Values = append(newRes.Values, n)
+		default:
+			return newRes, fmt.Errorf("can only convert the unit of type series or numbers, got type %v from %v", val.Type(), gc.VarToConvert)
+		}
+	}
+
+	return newRes, nil
+}
+
+// fieldUnit returns the unit set in the field config of the value, if any.
+func fieldUnit(val mathexp.Value) string {
+	var field *data.Field
+	switch v := val.(type) {
+	case mathexp.Series:
+		field = v.Frame.Fields[1]
+	case mathexp.Number:
+		field = v.Frame.Fields[0]
+	default:
+		return ""
+	}
+	if field.Config == nil {
+		return ""
+	}
+	return field.Config.Unit
+}
+
+func supportedUnits() string {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
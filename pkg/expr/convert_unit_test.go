@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalConvertUnitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		isError bool
+		expect  *ConvertUnitCommand
+	}{
+		{
+			name:   "converts between units",
+			query:  `{ "type": "convert_unit", "expression": "$A", "fromUnit": "bytes", "toUnit": "gbytes" }`,
+			expect: &ConvertUnitCommand{VarToConvert: "A", FromUnit: "bytes", ToUnit: "gbytes"},
+		},
+		{
+			name:   "fromUnit is optional",
+			query:  `{ "type": "convert_unit", "expression": "A", "toUnit": "s" }`,
+			expect: &ConvertUnitCommand{VarToConvert: "A", ToUnit: "s"},
+		},
+		{
+			name:    "error without expression",
+			query:   `{ "type": "convert_unit", "toUnit": "s" }`,
+			isError: true,
+		},
+		{
+			name:    "error without toUnit",
+			query:   `{ "type": "convert_unit", "expression": "A" }`,
+			isError: true,
+		},
+		{
+			name:    "error with unknown unit",
+			query:   `{ "type": "convert_unit", "expression": "A", "fromUnit": "bytes", "toUnit": "furlong" }`,
+			isError: true,
+		},
+		{
+			name:    "error with units of different families",
+			query:   `{ "type": "convert_unit", "expression": "A", "fromUnit": "bytes", "toUnit": "ms" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalConvertUnitCommand(&rawNode{RefID: "B", Query: qmap})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expect, cmd)
+		})
+	}
+}
+
+func TestConvertUnitCommand_Execute(t *testing.T) {
+	t.Run("converts series and numbers", func(t *testing.T) {
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, 2)
+		s.SetPoint(0, time.Unix(1, 0), ptr.Float64(2*gibi))
+		s.SetPoint(1, time.Unix(2, 0), nil)
+		n := makeJoinNumber("A", data.Labels{"host": "b"}, 512*mebi)
+
+		cmd, err := NewConvertUnitCommand("B", "A", "bytes", "gbytes")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{s, n}},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		converted := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a"}, converted.GetLabels())
+		require.Equal(t, ptr.Float64(2), converted.GetValue(0))
+		require.Nil(t, converted.GetValue(1))
+		require.Equal(t, "gbytes", converted.Frame.Fields[1].Config.Unit)
+		require.Equal(t, ptr.Float64(0.5), res.Values[1].(mathexp.Number).GetFloat64Value())
+
+		// the input is left untouched
+		require.Equal(t, ptr.Float64(2*gibi), s.GetValue(0))
+	})
+
+	t.Run("converts units with an offset", func(t *testing.T) {
+		cmd, err := NewConvertUnitCommand("B", "A", "fahrenheit", "celsius")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{makeJoinNumber("A", nil, 212)}},
+		})
+		require.NoError(t, err)
+		require.InDelta(t, 100, *res.Values[0].(mathexp.Number).GetFloat64Value(), 1e-9)
+	})
+
+	t.Run("uses the unit of the field config without fromUnit", func(t *testing.T) {
+		n := makeJoinNumber("A", nil, 1500)
+		n.Frame.Fields[0].Config = &data.FieldConfig{Unit: "ms"}
+
+		cmd, err := NewConvertUnitCommand("B", "A", "", "s")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{n}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, ptr.Float64(1.5), res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("error when the field config has no or an incompatible unit", func(t *testing.T) {
+		cmd, err := NewConvertUnitCommand("B", "A", "", "s")
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{makeJoinNumber("A", nil, 1)}},
+		})
+		require.Error(t, err)
+
+		n := makeJoinNumber("A", nil, 1)
+		n.Frame.Fields[0].Config = &data.FieldConfig{Unit: "bytes"}
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{n}},
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,329 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// JoinBy is what values of different inputs are matched on by a JoinCommand.
+type JoinBy string
+
+const (
+	// JoinByTime aligns series of all inputs to a common set of timestamps.
+	JoinByTime JoinBy = "time"
+	// JoinByLabels matches series and numbers of all inputs by their labels.
+	JoinByLabels JoinBy = "labels"
+)
+
+// JoinMode determines what happens to values without a match in every input.
+type JoinMode string
+
+const (
+	// JoinModeInner drops timestamps or values that are not present in every input.
+	JoinModeInner JoinMode = "inner"
+	// JoinModeOuter keeps everything, filling missing points with null.
+	JoinModeOuter JoinMode = "outer"
+)
+
+// JoinCommand is an expression command that joins the results of several
+// queries or expressions, typically coming from different data sources.
+type JoinCommand struct {
+	VarsToJoin []string
+	By         JoinBy
+	Mode       JoinMode
+	// Labels are the label keys used to match values when joining by labels.
+	// When empty, the label keys shared by all values are used.
+	Labels []string
+}
+
+// NewJoinCommand creates a new JoinCommand.
+func NewJoinCommand(refID string, varsToJoin []string, by JoinBy, mode JoinMode, labels []string) (*JoinCommand, error) {
+	if len(varsToJoin) < 2 {
+		return nil, fmt.Errorf("join for refId %v needs at least two inputs, got %d", refID, len(varsToJoin))
+	}
+	switch by {
+	case JoinByTime, JoinByLabels:
+	default:
+		return nil, fmt.Errorf("join for refId %v has unsupported joinBy '%v'. Supported only: [time,labels]", refID, by)
+	}
+	switch mode {
+	case JoinModeInner, JoinModeOuter:
+	default:
+		return nil, fmt.Errorf("join for refId %v has unsupported joinMode '%v'. Supported only: [inner,outer]", refID, mode)
+	}
+
+	return &JoinCommand{
+		VarsToJoin: varsToJoin,
+		By:         by,
+		Mode:       mode,
+		Labels:     labels,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	rawVars, ok := rn.Query["expressions"]
+	if !ok {
+		return nil, fmt.Errorf("no inputs specified to join for refId %v", rn.RefID)
+	}
+	varsToJoin, err := toStringSlice(rawVars)
+	if err != nil {
+		return nil, fmt.Errorf("expected join expressions to be an array of strings for refId %v: %w", rn.RefID, err)
+	}
+	for i, v := range varsToJoin {
+		varsToJoin[i] = strings.TrimPrefix(v, "$")
+	}
+
+	by := JoinByTime
+	if rawBy, ok := rn.Query["joinBy"]; ok {
+		s, ok := rawBy.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected joinBy to be a string, got %T for refId %v", rawBy, rn.RefID)
+		}
+		by = JoinBy(s)
+	}
+
+	mode := JoinModeOuter
+	if rawMode, ok := rn.Query["joinMode"]; ok {
+		s, ok := rawMode.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected joinMode to be a string, got %T for refId %v", rawMode, rn.RefID)
+		}
+		mode = JoinMode(s)
+	}
+
+	var labels []string
+	if rawLabels, ok := rn.Query["joinLabels"]; ok {
+		labels, err = toStringSlice(rawLabels)
+		if err != nil {
+			return nil, fmt.Errorf("expected joinLabels to be an array of strings for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	return NewJoinCommand(rn.RefID, varsToJoin, by, mode, labels)
+}
+
+func toStringSlice(raw interface{}) ([]string, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("got %T", raw)
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("got item of type %T", item)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gj *JoinCommand) NeedsVars() []string {
+	return gj.VarsToJoin
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gj *JoinCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	if gj.By == JoinByLabels {
+		return gj.joinByLabels(vars), nil
+	}
+	return gj.joinByTime(vars)
+}
+
+// joinByTime aligns all series of all inputs to the same timestamps. For an
+// inner join only timestamps present in every series are kept, for an outer
+// join all timestamps are kept and missing points are set to null.
+func (gj *JoinCommand) joinByTime(vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+
+	var series []mathexp.Series
+	for _, name := range gj.VarsToJoin {
+		for _, val := range vars[name].Values {
+			s, ok := val.(mathexp.Series)
+			if !ok {
+				return newRes, fmt.Errorf("can only join type series by time, got type %v from %v", val.Type(), name)
+			}
+			series = append(series, s)
+		}
+	}
+
+	// count how many series have a point at each timestamp
+	seen := map[int64]int{}
+	for _, s := range series {
+		for _, ts := range uniqueTimestamps(s) {
+			seen[ts]++
+		}
+	}
+	timestamps := make([]int64, 0, len(seen))
+	for ts, count := range seen {
+		if gj.Mode == JoinModeInner && count < len(series) {
+			continue
+		}
+		timestamps = append(timestamps, ts)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	for _, s := range series {
+		values := make(map[int64]*float64, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			values[t.UnixNano()] = f
+		}
+		joined := mathexp.NewSeries(s.GetName(), s.GetLabels(), len(timestamps))
+		for i, ts := range timestamps {
+			joined.SetPoint(i, time.Unix(0, ts).UTC(), values[ts])
+		}
+		newRes.Values = append(newRes.Values, joined)
+	}
+
+	return newRes, nil
+}
+
+func uniqueTimestamps(s mathexp.Series) []int64 {
+	seen := make(map[int64]struct{}, s.Len())
+	timestamps := make([]int64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		ts := s.GetTime(i).UnixNano()
+		if _, ok := seen[ts]; ok {
+			continue
+		}
+		seen[ts] = struct{}{}
+		timestamps = append(timestamps, ts)
+	}
+	return timestamps
+}
+
+// joinGroup holds the values of all inputs that share the same join key.
+type joinGroup struct {
+	labels  data.Labels
+	members map[string][]mathexp.Value
+}
+
+// joinByLabels matches the values of all inputs by the join labels. Every
+// matched value gets the labels of all values it was matched with, so for
+// example a series from one data source can be enriched with labels from a
+// table of another one. For an inner join values without a match in every
+// input are dropped.
+func (gj *JoinCommand) joinByLabels(vars mathexp.Vars) mathexp.Results {
+	newRes := mathexp.Results{}
+
+	keys := gj.Labels
+	if len(keys) == 0 {
+		keys = gj.sharedLabelKeys(vars)
+	}
+
+	var order []string
+	groups := map[string]*joinGroup{}
+	unmatched := 0
+	for _, name := range gj.VarsToJoin {
+		for _, val := range vars[name].Values {
+			key, ok := joinKey(val.GetLabels(), keys)
+			if !ok {
+				// values missing one of the join labels never match anything
+				key = fmt.Sprintf("\x00unmatched-%d", unmatched)
+				unmatched++
+			}
+			g, ok := groups[key]
+			if !ok {
+				g = &joinGroup{labels: data.Labels{}, members: map[string][]mathexp.Value{}}
+				groups[key] = g
+				order = append(order, key)
+			}
+			for k, v := range val.GetLabels() {
+				if _, exists := g.labels[k]; !exists {
+					g.labels[k] = v
+				}
+			}
+			g.members[name] = append(g.members[name], val)
+		}
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		if gj.Mode == JoinModeInner && len(g.members) < len(gj.VarsToJoin) {
+			continue
+		}
+		for _, name := range gj.VarsToJoin {
+			for _, val := range g.members[name] {
+				newRes.Values = append(newRes.Values, relabel(name, val, g.labels.Copy()))
+			}
+		}
+	}
+
+	return newRes
+}
+
+// sharedLabelKeys returns the label keys present on every value of every input.
+func (gj *JoinCommand) sharedLabelKeys(vars mathexp.Vars) []string {
+	var shared map[string]struct{}
+	for _, name := range gj.VarsToJoin {
+		for _, val := range vars[name].Values {
+			labels := val.GetLabels()
+			if shared == nil {
+				shared = make(map[string]struct{}, len(labels))
+				for k := range labels {
+					shared[k] = struct{}{}
+				}
+				continue
+			}
+			for k := range shared {
+				if _, ok := labels[k]; !ok {
+					delete(shared, k)
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(shared))
+	for k := range shared {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinKey(labels data.Labels, keys []string) (string, bool) {
+	var sb strings.Builder
+	for _, k := range keys {
+		v, ok := labels[k]
+		if !ok {
+			return "", false
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(v)
+		sb.WriteByte(0)
+	}
+	return sb.String(), true
+}
+
+// relabel copies val into a new value named after the input it came from and
+// carrying the given labels.
+func relabel(name string, val mathexp.Value, labels data.Labels) mathexp.Value {
+	switch v := val.(type) {
+	case mathexp.Series:
+		s := mathexp.NewSeries(name, labels, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			t, f := v.GetPoint(i)
+			s.SetPoint(i, t, f)
+		}
+		return s
+	case mathexp.Number:
+		n := mathexp.NewNumber(name, labels)
+		n.SetValue(v.GetFloat64Value())
+		return n
+	default:
+		return val
+	}
+}
@@ -0,0 +1,195 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		isError bool
+		expect  *JoinCommand
+	}{
+		{
+			name:   "defaults to an outer join by time",
+			query:  `{ "type": "join", "expressions": ["$A", "B"] }`,
+			expect: &JoinCommand{VarsToJoin: []string{"A", "B"}, By: JoinByTime, Mode: JoinModeOuter},
+		},
+		{
+			name:   "inner join by labels",
+			query:  `{ "type": "join", "expressions": ["A", "B"], "joinBy": "labels", "joinMode": "inner", "joinLabels": ["host"] }`,
+			expect: &JoinCommand{VarsToJoin: []string{"A", "B"}, By: JoinByLabels, Mode: JoinModeInner, Labels: []string{"host"}},
+		},
+		{
+			name:    "error without expressions",
+			query:   `{ "type": "join" }`,
+			isError: true,
+		},
+		{
+			name:    "error with a single input",
+			query:   `{ "type": "join", "expressions": ["A"] }`,
+			isError: true,
+		},
+		{
+			name:    "error with unknown joinBy",
+			query:   `{ "type": "join", "expressions": ["A", "B"], "joinBy": "name" }`,
+			isError: true,
+		},
+		{
+			name:    "error with unknown joinMode",
+			query:   `{ "type": "join", "expressions": ["A", "B"], "joinMode": "left" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: qmap})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expect, cmd)
+		})
+	}
+}
+
+func TestJoinCommand_ByTime(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeJoinSeries("A", data.Labels{"host": "a"}, 1, 2, 3),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			makeJoinSeries("B", nil, 2, 3, 4),
+		}},
+	}
+
+	t.Run("inner join keeps shared timestamps", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, JoinByTime, JoinModeInner, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for _, v := range res.Values {
+			s := v.(mathexp.Series)
+			require.Equal(t, []int64{2, 3}, seriesSeconds(s))
+		}
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+	})
+
+	t.Run("outer join fills missing points with null", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, JoinByTime, JoinModeOuter, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		a := res.Values[0].(mathexp.Series)
+		require.Equal(t, []int64{1, 2, 3, 4}, seriesSeconds(a))
+		require.Equal(t, ptr.Float64(3), a.GetValue(2))
+		require.Nil(t, a.GetValue(3))
+
+		b := res.Values[1].(mathexp.Series)
+		require.Nil(t, b.GetValue(0))
+		require.Equal(t, ptr.Float64(4), b.GetValue(3))
+	})
+
+	t.Run("error when an input is not a series", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "N"}, JoinByTime, JoinModeOuter, nil)
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{
+			"A": vars["A"],
+			"N": mathexp.Results{Values: mathexp.Values{makeJoinNumber("N", nil, 1)}},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestJoinCommand_ByLabels(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeJoinSeries("A", data.Labels{"host": "a", "job": "node"}, 1, 2),
+			makeJoinSeries("A", data.Labels{"host": "b", "job": "node"}, 1, 2),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			makeJoinNumber("B", data.Labels{"host": "a", "customer": "acme"}, 10),
+			makeJoinNumber("B", data.Labels{"host": "c", "customer": "initech"}, 20),
+		}},
+	}
+
+	t.Run("inner join enriches matched values with labels", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, JoinByLabels, JoinModeInner, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		expected := data.Labels{"host": "a", "job": "node", "customer": "acme"}
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, "A", s.GetName())
+		require.Equal(t, expected, s.GetLabels())
+		n := res.Values[1].(mathexp.Number)
+		require.Equal(t, expected, n.GetLabels())
+		require.Equal(t, ptr.Float64(10), n.GetFloat64Value())
+	})
+
+	t.Run("outer join keeps unmatched values", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, JoinByLabels, JoinModeOuter, []string{"host"})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 4)
+		require.Equal(t, data.Labels{"host": "b", "job": "node"}, res.Values[2].GetLabels())
+		require.Equal(t, data.Labels{"host": "c", "customer": "initech"}, res.Values[3].GetLabels())
+	})
+
+	t.Run("values missing a join label never match", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, JoinByLabels, JoinModeInner, []string{"customer"})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Empty(t, res.Values)
+	})
+}
+
+func makeJoinSeries(name string, labels data.Labels, seconds ...int64) mathexp.Series {
+	s := mathexp.NewSeries(name, labels, len(seconds))
+	for i, sec := range seconds {
+		s.SetPoint(i, time.Unix(sec, 0), ptr.Float64(float64(sec)))
+	}
+	return s
+}
+
+func makeJoinNumber(name string, labels data.Labels, value float64) mathexp.Number {
+	n := mathexp.NewNumber(name, labels)
+	n.SetValue(ptr.Float64(value))
+	return n
+}
+
+func seriesSeconds(s mathexp.Series) []int64 {
+	seconds := make([]int64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		seconds = append(seconds, s.GetTime(i).Unix())
+	}
+	return seconds
+}
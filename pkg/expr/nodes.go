@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/transformations"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
@@ -119,6 +120,12 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeJoin:
+		node.Command, err = UnmarshalJoinCommand(rn)
+	case TypeConvertUnit:
+		node.Command, err = UnmarshalConvertUnitCommand(rn)
+	case TypeCalculateField:
+		node.Command, err = UnmarshalCalculateFieldCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -146,6 +153,9 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// transformations are run on the frames of the query before they are converted
+	transformations []transformations.Transformation
 }
 
 // NodeType returns the data pipeline node type.
@@ -190,6 +200,19 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		dsNode.maxDP = int64(floatMaxDP)
 	}
 
+	if rawTransformations, ok := rn.Query["transformations"]; ok {
+		encoded, err := json.Marshal(rawTransformations)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &dsNode.transformations); err != nil {
+			return nil, fmt.Errorf("failed to parse transformations for refId %v: %w", rn.RefID, err)
+		}
+		if err := transformations.Validate(dsNode.transformations); err != nil {
+			return nil, fmt.Errorf("refId %v: %w", rn.RefID, err)
+		}
+	}
+
 	return dsNode, nil
 }
 
@@ -236,6 +259,13 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 			return mathexp.Results{}, QueryError{RefID: refID, Err: qr.Error}
 		}
 
+		if len(dn.transformations) > 0 {
+			qr.Frames, err = transformations.Apply(qr.Frames, dn.transformations)
+			if err != nil {
+				return mathexp.Results{}, QueryError{RefID: refID, Err: err}
+			}
+		}
+
 		dataSource := dn.datasource.Type
 		if isAllFrameVectors(dataSource, qr.Frames) {
 			vals, err = framesToNumbers(qr.Frames)
@@ -49,6 +49,14 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, dataSourceSer
 	}
 }
 
+// WithQueryDataHandler returns a copy of the service that queries data sources
+// through the handler returned by wrap, which is given the current handler.
+func (s *Service) WithQueryDataHandler(wrap func(next backend.QueryDataHandler) backend.QueryDataHandler) *Service {
+	clone := *s
+	clone.dataService = wrap(s.dataService)
+	return &clone
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
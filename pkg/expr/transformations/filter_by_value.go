@@ -0,0 +1,199 @@
+package transformations
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type filterByValueOptions struct {
+	// Type is either include or exclude, defaults to include.
+	Type string `json:"type"`
+	// Match is either any or all, defaults to any.
+	Match   string        `json:"match"`
+	Filters []valueFilter `json:"filters"`
+}
+
+type valueFilter struct {
+	FieldName string `json:"fieldName"`
+	Config    struct {
+		ID      string `json:"id"`
+		Options struct {
+			Value interface{} `json:"value"`
+			From  interface{} `json:"from"`
+			To    interface{} `json:"to"`
+		} `json:"options"`
+	} `json:"config"`
+}
+
+type valueMatcher func(v interface{}, ok bool) bool
+
+// filterByValue keeps or drops rows depending on the values of one or more fields.
+// Filters on fields a frame does not have are ignored for that frame.
+func filterByValue(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	opts := filterByValueOptions{}
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if len(opts.Filters) == 0 {
+		return frames, nil
+	}
+	include := opts.Type != "exclude"
+	matchAll := opts.Match == "all"
+
+	matchers := make([]valueMatcher, 0, len(opts.Filters))
+	for _, filter := range opts.Filters {
+		matcher, err := newValueMatcher(filter)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		fieldIdx := make([]int, 0, len(opts.Filters))
+		frameMatchers := make([]valueMatcher, 0, len(opts.Filters))
+		for i, filter := range opts.Filters {
+			field, idx := frame.FieldByName(filter.FieldName)
+			if field == nil {
+				continue
+			}
+			fieldIdx = append(fieldIdx, idx)
+			frameMatchers = append(frameMatchers, matchers[i])
+		}
+		if len(frameMatchers) == 0 {
+			result = append(result, frame)
+			continue
+		}
+
+		filtered := frame.EmptyCopy()
+		for row := 0; row < frame.Rows(); row++ {
+			matched := matchAll
+			for i, matcher := range frameMatchers {
+				m := matcher(frame.Fields[fieldIdx[i]].ConcreteAt(row))
+				if matchAll && !m {
+					matched = false
+					break
+				}
+				if !matchAll && m {
+					matched = true
+					break
+				}
+			}
+			if matched == include {
+				filtered.AppendRow(frame.RowCopy(row)...)
+			}
+		}
+		result = append(result, filtered)
+	}
+
+	return result, nil
+}
+
+func newValueMatcher(filter valueFilter) (valueMatcher, error) {
+	opts := filter.Config.Options
+	switch filter.Config.ID {
+	case "isNull":
+		return func(_ interface{}, ok bool) bool { return !ok }, nil
+	case "isNotNull":
+		return func(_ interface{}, ok bool) bool { return ok }, nil
+	case "greater", "greaterOrEqual", "lower", "lowerOrEqual":
+		expected, ok := toFloat(opts.Value)
+		if !ok {
+			return nil, fmt.Errorf("filter on field %q requires a numeric value", filter.FieldName)
+		}
+		id := filter.Config.ID
+		return func(v interface{}, ok bool) bool {
+			f, isNumber := toFloat(v)
+			if !ok || !isNumber {
+				return false
+			}
+			switch id {
+			case "greater":
+				return f > expected
+			case "greaterOrEqual":
+				return f >= expected
+			case "lower":
+				return f < expected
+			default:
+				return f <= expected
+			}
+		}, nil
+	case "range":
+		from, fromOk := toFloat(opts.From)
+		to, toOk := toFloat(opts.To)
+		if !fromOk || !toOk {
+			return nil, fmt.Errorf("range filter on field %q requires numeric from and to", filter.FieldName)
+		}
+		return func(v interface{}, ok bool) bool {
+			f, isNumber := toFloat(v)
+			return ok && isNumber && f > from && f < to
+		}, nil
+	case "equal", "notEqual":
+		equal := filter.Config.ID == "equal"
+		return func(v interface{}, ok bool) bool {
+			return ok && valuesEqual(v, opts.Value) == equal
+		}, nil
+	case "regex":
+		re, err := regexp.Compile(fmt.Sprint(opts.Value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for filter on field %q: %w", filter.FieldName, err)
+		}
+		return func(v interface{}, ok bool) bool {
+			return ok && re.MatchString(fmt.Sprint(v))
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported value matcher %q", filter.Config.ID)
+}
+
+func valuesEqual(v interface{}, expected interface{}) bool {
+	f, vOk := toFloat(v)
+	e, eOk := toFloat(expected)
+	if vOk && eOk {
+		return f == e
+	}
+	return fmt.Sprint(v) == fmt.Sprint(expected)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case int:
+		return float64(t), true
+	case int8:
+		return float64(t), true
+	case int16:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint8:
+		return float64(t), true
+	case uint16:
+		return float64(t), true
+	case uint32:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case time.Time:
+		return float64(t.UnixNano() / int64(time.Millisecond)), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,121 @@
+package transformations
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// merge combines all frames into a single table like the frontend merge
+// transformation. Fields with the same name, labels and type end up in the
+// same column. Fields present in every frame are the join key: rows with the
+// same key values are combined into one row, unless they hold different
+// values for another column. Cells a row has no value for are left empty.
+func merge(frames data.Frames, _ *simplejson.Json) (data.Frames, error) {
+	if len(frames) < 2 {
+		return frames, nil
+	}
+
+	columnIndex := map[string]int{}
+	columns := []*data.Field{}
+	occurrences := map[int]int{}
+	for _, frame := range frames {
+		seen := map[int]bool{}
+		for _, field := range frame.Fields {
+			key := mergeKey(field)
+			idx, ok := columnIndex[key]
+			if !ok {
+				column := data.NewFieldFromFieldType(field.Type().NullableType(), 0)
+				column.Name = field.Name
+				column.Labels = field.Labels.Copy()
+				column.Config = field.Config
+				idx = len(columns)
+				columnIndex[key] = idx
+				columns = append(columns, column)
+			}
+			if !seen[idx] {
+				seen[idx] = true
+				occurrences[idx]++
+			}
+		}
+	}
+
+	var keyColumns []int
+	for idx := range columns {
+		if occurrences[idx] == len(frames) {
+			keyColumns = append(keyColumns, idx)
+		}
+	}
+
+	// rows holds the concrete values of every merged row by column index.
+	var rows []map[int]interface{}
+	rowsByKey := map[string][]int{}
+	for _, frame := range frames {
+		for i := 0; i < frame.Rows(); i++ {
+			row := map[int]interface{}{}
+			for _, field := range frame.Fields {
+				if v, ok := field.ConcreteAt(i); ok {
+					row[columnIndex[mergeKey(field)]] = v
+				}
+			}
+
+			key := rowKey(row, keyColumns)
+			merged := false
+			for _, existing := range rowsByKey[key] {
+				if mergeable(rows[existing], row) {
+					for idx, v := range row {
+						rows[existing][idx] = v
+					}
+					merged = true
+				}
+			}
+			if !merged {
+				rowsByKey[key] = append(rowsByKey[key], len(rows))
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	for _, column := range columns {
+		column.Extend(len(rows))
+	}
+	for i, row := range rows {
+		for idx, v := range row {
+			columns[idx].SetConcrete(i, v)
+		}
+	}
+
+	merged := data.NewFrame("", columns...)
+	merged.RefID = frames[0].RefID
+	return data.Frames{merged}, nil
+}
+
+func mergeKey(field *data.Field) string {
+	return field.Name + field.Labels.String() + field.Type().NonNullableType().ItemTypeString()
+}
+
+func rowKey(row map[int]interface{}, keyColumns []int) string {
+	parts := make([]string, 0, len(keyColumns))
+	for _, idx := range keyColumns {
+		if v, ok := row[idx]; ok {
+			parts = append(parts, fmt.Sprint(v))
+		} else {
+			parts = append(parts, "\x01")
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// mergeable returns whether row has no value conflicting with existing.
+func mergeable(existing, row map[int]interface{}) bool {
+	for idx, v := range row {
+		if current, ok := existing[idx]; ok && !reflect.DeepEqual(current, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package transformations
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type organizeOptions struct {
+	ExcludeByName map[string]bool   `json:"excludeByName"`
+	IndexByName   map[string]int    `json:"indexByName"`
+	RenameByName  map[string]string `json:"renameByName"`
+}
+
+// organize removes, reorders and renames fields by name. Fields without an
+// index keep their relative order after the indexed ones. The input frames
+// are left untouched, since they may be shared with other consumers of the
+// query response.
+func organize(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	opts := organizeOptions{}
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	organized := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			if opts.ExcludeByName[field.Name] {
+				continue
+			}
+			fields = append(fields, field)
+		}
+
+		sort.SliceStable(fields, func(i, j int) bool {
+			iIdx, iOk := opts.IndexByName[fields[i].Name]
+			jIdx, jOk := opts.IndexByName[fields[j].Name]
+			if iOk && jOk {
+				return iIdx < jIdx
+			}
+			return iOk && !jOk
+		})
+
+		for i, field := range fields {
+			if name, ok := opts.RenameByName[field.Name]; ok && name != "" {
+				renamed := *field
+				renamed.Name = name
+				fields[i] = &renamed
+			}
+		}
+
+		copied := *frame
+		copied.Fields = fields
+		organized = append(organized, &copied)
+	}
+
+	return organized, nil
+}
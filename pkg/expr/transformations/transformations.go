@@ -0,0 +1,130 @@
+// Package transformations runs a subset of the panel transformations that are
+// otherwise applied by the frontend, so that frames can be reduced before they
+// are sent to the browser and consumers such as alert rules see the same data
+// as the panels.
+package transformations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var ErrUnsupportedTransformation = errors.New("unsupported transformation")
+
+// Transformation has the same shape as the transformations stored in the panel model.
+type Transformation struct {
+	ID       string           `json:"id"`
+	Options  *simplejson.Json `json:"options,omitempty"`
+	Disabled bool             `json:"disabled,omitempty"`
+}
+
+type transformer func(frames data.Frames, options *simplejson.Json) (data.Frames, error)
+
+var transformers = map[string]transformer{
+	"merge":         merge,
+	"organize":      organize,
+	"filterByValue": filterByValue,
+}
+
+// Validate returns an error if any of the enabled transformations can not be
+// executed server side.
+func Validate(transformations []Transformation) error {
+	for _, t := range transformations {
+		if t.Disabled {
+			continue
+		}
+		if _, ok := transformers[t.ID]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnsupportedTransformation, t.ID)
+		}
+	}
+	return nil
+}
+
+// Apply runs the transformations in order on frames. Disabled transformations are skipped.
+func Apply(frames data.Frames, transformations []Transformation) (data.Frames, error) {
+	var err error
+	for _, t := range transformations {
+		if t.Disabled {
+			continue
+		}
+		fn, ok := transformers[t.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedTransformation, t.ID)
+		}
+		frames, err = fn(frames, t.Options)
+		if err != nil {
+			return nil, fmt.Errorf("transformation %q failed: %w", t.ID, err)
+		}
+	}
+	return frames, nil
+}
+
+// ApplyToResponse runs the transformations on the frames of all successful
+// responses, in the order of refIDs, like the frontend does for a panel.
+// Resulting frames are put back under their RefID. Frames without a known
+// RefID, e.g. the result of a merge, end up in the first successful response.
+func ApplyToResponse(resp *backend.QueryDataResponse, refIDs []string, transformations []Transformation) error {
+	if resp == nil || len(transformations) == 0 {
+		return nil
+	}
+
+	var frames data.Frames
+	var included []string
+	for _, refID := range refIDs {
+		dr, ok := resp.Responses[refID]
+		if !ok || dr.Error != nil {
+			continue
+		}
+		for _, frame := range dr.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+		}
+		frames = append(frames, dr.Frames...)
+		included = append(included, refID)
+	}
+	if len(included) == 0 {
+		return nil
+	}
+
+	frames, err := Apply(frames, transformations)
+	if err != nil {
+		return err
+	}
+
+	byRefID := make(map[string]data.Frames, len(included))
+	for _, refID := range included {
+		byRefID[refID] = data.Frames{}
+	}
+	for _, frame := range frames {
+		refID := frame.RefID
+		if _, ok := byRefID[refID]; !ok {
+			refID = included[0]
+		}
+		byRefID[refID] = append(byRefID[refID], frame)
+	}
+	for refID, refFrames := range byRefID {
+		dr := resp.Responses[refID]
+		dr.Frames = refFrames
+		resp.Responses[refID] = dr
+	}
+
+	return nil
+}
+
+func decodeOptions(options *simplejson.Json, v interface{}) error {
+	if options == nil {
+		return nil
+	}
+	b, err := options.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,218 @@
+package transformations
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	t1 := time.Unix(1, 0)
+	t2 := time.Unix(2, 0)
+	frames := data.Frames{
+		data.NewFrame("A",
+			data.NewField("time", nil, []time.Time{t1}),
+			data.NewField("cpu", nil, []float64{1}),
+		),
+		data.NewFrame("B",
+			data.NewField("time", nil, []time.Time{t2}),
+			data.NewField("mem", nil, []float64{2}),
+		),
+	}
+
+	result, err := Apply(frames, []Transformation{{ID: "merge"}})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+
+	merged := result[0]
+	require.Len(t, merged.Fields, 3)
+	require.Equal(t, 2, merged.Rows())
+	require.Equal(t, []string{"time", "cpu", "mem"}, fieldNames(merged))
+
+	v, ok := merged.Fields[1].ConcreteAt(0)
+	require.True(t, ok)
+	require.Equal(t, float64(1), v)
+	_, ok = merged.Fields[1].ConcreteAt(1)
+	require.False(t, ok)
+	v, ok = merged.Fields[2].ConcreteAt(1)
+	require.True(t, ok)
+	require.Equal(t, float64(2), v)
+}
+
+func TestMerge_joinsOnSharedFields(t *testing.T) {
+	t1 := time.Unix(1, 0)
+	t2 := time.Unix(2, 0)
+	frames := data.Frames{
+		data.NewFrame("A",
+			data.NewField("time", nil, []time.Time{t1, t2}),
+			data.NewField("cpu", nil, []float64{1, 2}),
+		),
+		data.NewFrame("B",
+			data.NewField("time", nil, []time.Time{t1, t2}),
+			data.NewField("mem", nil, []float64{3, 4}),
+		),
+		data.NewFrame("C",
+			data.NewField("time", nil, []time.Time{t1}),
+			data.NewField("cpu", nil, []float64{5}),
+		),
+	}
+
+	result, err := Apply(frames, []Transformation{{ID: "merge"}})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+
+	merged := result[0]
+	require.Equal(t, []string{"time", "cpu", "mem"}, fieldNames(merged))
+	// Rows of A and B are joined on time, C conflicts on cpu and gets its own row.
+	require.Equal(t, 3, merged.Rows())
+	require.Equal(t, []interface{}{t1, float64(1), float64(3)}, concreteRow(merged, 0))
+	require.Equal(t, []interface{}{t2, float64(2), float64(4)}, concreteRow(merged, 1))
+	require.Equal(t, []interface{}{t1, float64(5), nil}, concreteRow(merged, 2))
+}
+
+func TestOrganize(t *testing.T) {
+	frames := data.Frames{
+		data.NewFrame("A",
+			data.NewField("a", nil, []float64{1}),
+			data.NewField("b", nil, []float64{2}),
+			data.NewField("c", nil, []float64{3}),
+			data.NewField("d", nil, []float64{4}),
+		),
+	}
+
+	result, err := Apply(frames, []Transformation{{
+		ID: "organize",
+		Options: simplejson.NewFromAny(map[string]interface{}{
+			"excludeByName": map[string]interface{}{"b": true},
+			"indexByName":   map[string]interface{}{"c": 0, "a": 1},
+			"renameByName":  map[string]interface{}{"c": "renamed"},
+		}),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"renamed", "a", "d"}, fieldNames(result[0]))
+	require.Equal(t, []string{"a", "b", "c", "d"}, fieldNames(frames[0]), "input frames must not be modified")
+}
+
+func TestFilterByValue(t *testing.T) {
+	newFrames := func() data.Frames {
+		return data.Frames{
+			data.NewFrame("A",
+				data.NewField("host", nil, []string{"a", "b", "c"}),
+				data.NewField("value", nil, []*float64{floatPtr(1), floatPtr(5), nil}),
+			),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "include greater",
+			options: map[string]interface{}{
+				"type": "include",
+				"filters": []interface{}{
+					map[string]interface{}{"fieldName": "value", "config": map[string]interface{}{"id": "greater", "options": map[string]interface{}{"value": 2}}},
+				},
+			},
+			expected: []string{"b"},
+		},
+		{
+			name: "exclude null",
+			options: map[string]interface{}{
+				"type": "exclude",
+				"filters": []interface{}{
+					map[string]interface{}{"fieldName": "value", "config": map[string]interface{}{"id": "isNull"}},
+				},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "include all must match",
+			options: map[string]interface{}{
+				"match": "all",
+				"filters": []interface{}{
+					map[string]interface{}{"fieldName": "value", "config": map[string]interface{}{"id": "isNotNull"}},
+					map[string]interface{}{"fieldName": "host", "config": map[string]interface{}{"id": "regex", "options": map[string]interface{}{"value": "^a$"}}},
+				},
+			},
+			expected: []string{"a"},
+		},
+		{
+			name: "unknown field is ignored",
+			options: map[string]interface{}{
+				"filters": []interface{}{
+					map[string]interface{}{"fieldName": "missing", "config": map[string]interface{}{"id": "equal", "options": map[string]interface{}{"value": 1}}},
+				},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(newFrames(), []Transformation{{ID: "filterByValue", Options: simplejson.NewFromAny(tt.options)}})
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+
+			hosts := []string{}
+			for i := 0; i < result[0].Rows(); i++ {
+				hosts = append(hosts, result[0].Fields[0].At(i).(string))
+			}
+			require.Equal(t, tt.expected, hosts)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate([]Transformation{{ID: "merge"}, {ID: "calculateField", Disabled: true}}))
+	require.ErrorIs(t, Validate([]Transformation{{ID: "calculateField"}}), ErrUnsupportedTransformation)
+}
+
+func TestApplyToResponse(t *testing.T) {
+	resp := backend.NewQueryDataResponse()
+	resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}}
+	resp.Responses["B"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{2}))}}
+	resp.Responses["C"] = backend.DataResponse{Error: errTest}
+
+	err := ApplyToResponse(resp, []string{"A", "B", "C"}, []Transformation{{ID: "merge"}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Responses["A"].Frames, 1)
+	require.Equal(t, 2, resp.Responses["A"].Frames[0].Rows())
+	require.Len(t, resp.Responses["B"].Frames, 0)
+	require.Equal(t, errTest, resp.Responses["C"].Error)
+}
+
+var errTest = errors.New("query failed")
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func concreteRow(frame *data.Frame, i int) []interface{} {
+	row := make([]interface{}, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			v = nil
+		}
+		row = append(row, v)
+	}
+	return row
+}
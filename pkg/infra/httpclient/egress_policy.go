@@ -0,0 +1,365 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrEgressDenied is returned when connecting to a host that is not allowed by the egress policy.
+var ErrEgressDenied = errors.New("connection denied by egress policy")
+
+// DefaultDeniedHosts are the link-local ranges and the cloud instance metadata
+// endpoints, which expose credentials of the machine Grafana runs on and must
+// not be reachable through data sources or alert notifiers. The link-local
+// ranges include the metadata endpoints of most clouds, e.g. 169.254.169.254,
+// and the ECS task metadata endpoint 169.254.170.2.
+var DefaultDeniedHosts = []string{
+	"169.254.0.0/16",
+	"fe80::/10",
+	"fd00:ec2::254",
+	"100.100.100.200",
+	"metadata.google.internal",
+}
+
+// DialContextFunc is the signature of net.Dialer.DialContext.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// ProxyFunc is the signature of http.Transport.Proxy.
+type ProxyFunc func(req *http.Request) (*url.URL, error)
+
+// EgressPolicy restricts the hosts outgoing connections may be made to. Rules
+// are host names, optionally starting with a "*." wildcard, IP addresses or
+// CIDRs. Denied rules always win. If there are allowed rules, only hosts
+// matching one of them may be connected to. The policy of the instance applies
+// to all connections, the policy of an organization, see SetOrgEgressPolicyFunc,
+// further restricts the connections made for it.
+type EgressPolicy struct {
+	allowedHosts []string
+	allowedNets  []*net.IPNet
+	deniedHosts  []string
+	deniedNets   []*net.IPNet
+
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewEgressPolicy creates an EgressPolicy from allowed and denied rules.
+func NewEgressPolicy(allowed, denied []string) (*EgressPolicy, error) {
+	p := &EgressPolicy{lookupIPAddr: net.DefaultResolver.LookupIPAddr}
+
+	var err error
+	if p.allowedHosts, p.allowedNets, err = parseEgressRules(allowed); err != nil {
+		return nil, err
+	}
+	if p.deniedHosts, p.deniedNets, err = parseEgressRules(denied); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func parseEgressRules(rules []string) ([]string, []*net.IPNet, error) {
+	var hosts []string
+	var nets []*net.IPNet
+	for _, rule := range rules {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		switch {
+		case rule == "":
+			continue
+		case strings.Contains(rule, "/"):
+			_, ipNet, err := net.ParseCIDR(rule)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid egress CIDR %q: %w", rule, err)
+			}
+			nets = append(nets, ipNet)
+		case net.ParseIP(rule) != nil:
+			ip := net.ParseIP(rule)
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		default:
+			hosts = append(hosts, strings.TrimSuffix(rule, "."))
+		}
+	}
+	return hosts, nets, nil
+}
+
+// DialContext wraps dial so that only connections allowed by the policy, and
+// by the policy of the organization of the context, are made. Host names are
+// resolved before connecting and only the allowed addresses are dialed, so host
+// names pointing to denied addresses, e.g. through DNS rebinding, are rejected
+// as well.
+func (p *EgressPolicy) DialContext(dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if p == nil {
+			return dial(ctx, network, address)
+		}
+		policies := egressPolicies{p}
+		orgPolicy, err := orgEgressPolicy(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if orgPolicy != nil {
+			policies = append(policies, orgPolicy)
+		}
+
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+		if ip := net.ParseIP(host); ip != nil {
+			if !policies.allowsIP(ip, nil) {
+				return nil, fmt.Errorf("%w: %s", ErrEgressDenied, host)
+			}
+			return dial(ctx, network, address)
+		}
+
+		hostAllowed, ok := policies.allowsHost(host)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEgressDenied, host)
+		}
+
+		addrs, err := p.lookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		lastErr := fmt.Errorf("%w: %s", ErrEgressDenied, host)
+		for _, addr := range addrs {
+			if !policies.allowsIP(addr.IP, hostAllowed) {
+				continue
+			}
+			conn, err := dial(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// Proxy wraps proxy so that the hosts of the requests sent through a proxy are
+// checked as well, since the connections of these requests are made to the proxy
+// and DialContext only sees the proxy host. The hosts of all the requests are
+// checked against the policy of the organization of the request, since their
+// connections may have been dialed for another organization when the transport
+// is shared, as the ones of the alert notifiers.
+func (p *EgressPolicy) Proxy(proxy ProxyFunc) ProxyFunc {
+	return func(req *http.Request) (*url.URL, error) {
+		var proxyURL *url.URL
+		if proxy != nil {
+			var err error
+			if proxyURL, err = proxy(req); err != nil {
+				return nil, err
+			}
+		}
+		if p == nil {
+			return proxyURL, nil
+		}
+
+		var policies egressPolicies
+		if proxyURL != nil {
+			policies = append(policies, p)
+		}
+		orgPolicy, err := orgEgressPolicy(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		if orgPolicy != nil {
+			policies = append(policies, orgPolicy)
+		}
+		if len(policies) == 0 {
+			return proxyURL, nil
+		}
+		if err := p.checkRequestHost(req.Context(), policies, req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}
+}
+
+// checkRequestHost returns ErrEgressDenied if the host of a request is not
+// allowed by the policies. The connection of the request may be made by a proxy,
+// or may already be open, so all of the addresses of the host must be allowed.
+// Hosts that can only be resolved by the proxy are checked against the host name
+// rules.
+func (p *EgressPolicy) checkRequestHost(ctx context.Context, policies egressPolicies, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		if !policies.allowsIP(ip, nil) {
+			return fmt.Errorf("%w: %s", ErrEgressDenied, host)
+		}
+		return nil
+	}
+
+	hostAllowed, ok := policies.allowsHost(host)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrEgressDenied, host)
+	}
+	addrs, err := p.lookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		for i, policy := range policies {
+			if policy.hasAllowRules() && !hostAllowed[i] {
+				return fmt.Errorf("%w: %s", ErrEgressDenied, host)
+			}
+		}
+		return nil
+	}
+	for _, addr := range addrs {
+		if !policies.allowsIP(addr.IP, hostAllowed) {
+			return fmt.Errorf("%w: %s", ErrEgressDenied, host)
+		}
+	}
+	return nil
+}
+
+// egressPolicies are the policies a connection must be allowed by.
+type egressPolicies []*EgressPolicy
+
+// allowsHost returns false if a host name is denied by one of the policies.
+// Otherwise it returns, for each policy, whether the host name matches one of
+// its allowed rules.
+func (ps egressPolicies) allowsHost(host string) ([]bool, bool) {
+	hostAllowed := make([]bool, len(ps))
+	for i, p := range ps {
+		if matchesHost(p.deniedHosts, host) {
+			return nil, false
+		}
+		hostAllowed[i] = matchesHost(p.allowedHosts, host)
+		// the host can't resolve to an allowed address
+		if p.hasAllowRules() && !hostAllowed[i] && len(p.allowedNets) == 0 {
+			return nil, false
+		}
+	}
+	return hostAllowed, true
+}
+
+// allowsIP returns true if all the policies allow connections to ip, see
+// EgressPolicy.allowsIP. hostAllowed is nil when ip isn't resolved from a host name.
+func (ps egressPolicies) allowsIP(ip net.IP, hostAllowed []bool) bool {
+	for i, p := range ps {
+		if !p.allowsIP(ip, hostAllowed != nil && hostAllowed[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *EgressPolicy) hasAllowRules() bool {
+	return len(p.allowedHosts) > 0 || len(p.allowedNets) > 0
+}
+
+// allowsIP returns true if connections to ip are allowed, hostAllowed is true
+// when the host name ip was resolved from matches an allowed rule.
+func (p *EgressPolicy) allowsIP(ip net.IP, hostAllowed bool) bool {
+	if containsIP(p.deniedNets, ip) {
+		return false
+	}
+	if !p.hasAllowRules() || hostAllowed {
+		return true
+	}
+	return containsIP(p.allowedNets, ip)
+}
+
+func matchesHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if pattern == host {
+			return true
+		}
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrgEgressPolicyFunc returns the egress policy of an organization, or nil if
+// it doesn't restrict the connections made for it.
+type OrgEgressPolicyFunc func(ctx context.Context, orgID int64) (*EgressPolicy, error)
+
+type egressOrgIDKey struct{}
+
+// WithEgressOrgID returns a context for the outgoing requests made for the
+// organization, e.g. by its data sources and alert notifiers, so that they are
+// restricted by its egress policy.
+func WithEgressOrgID(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, egressOrgIDKey{}, orgID)
+}
+
+var (
+	defaultEgressPolicyMu sync.RWMutex
+	defaultEgressPolicy   *EgressPolicy
+	orgEgressPolicyFunc   OrgEgressPolicyFunc
+)
+
+// SetOrgEgressPolicyFunc sets the function that returns the egress policy of
+// the organization of the outgoing requests, see WithEgressOrgID.
+func SetOrgEgressPolicyFunc(fn OrgEgressPolicyFunc) {
+	defaultEgressPolicyMu.Lock()
+	defer defaultEgressPolicyMu.Unlock()
+	orgEgressPolicyFunc = fn
+}
+
+// orgEgressPolicy returns the egress policy of the organization of the context.
+func orgEgressPolicy(ctx context.Context) (*EgressPolicy, error) {
+	orgID, ok := ctx.Value(egressOrgIDKey{}).(int64)
+	if !ok || orgID == 0 {
+		return nil, nil
+	}
+	defaultEgressPolicyMu.RLock()
+	fn := orgEgressPolicyFunc
+	defaultEgressPolicyMu.RUnlock()
+	if fn == nil {
+		return nil, nil
+	}
+	return fn(ctx, orgID)
+}
+
+// SetDefaultEgressPolicy sets the egress policy enforced by EgressDialContext and EgressProxy.
+func SetDefaultEgressPolicy(p *EgressPolicy) {
+	defaultEgressPolicyMu.Lock()
+	defer defaultEgressPolicyMu.Unlock()
+	defaultEgressPolicy = p
+}
+
+// EgressDialContext wraps dial with the default egress policy, for outgoing
+// connections that are not made by clients of the HTTP client provider, such
+// as the ones of alert notifiers.
+func EgressDialContext(dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		defaultEgressPolicyMu.RLock()
+		p := defaultEgressPolicy
+		defaultEgressPolicyMu.RUnlock()
+		return p.DialContext(dial)(ctx, network, address)
+	}
+}
+
+// EgressProxy wraps proxy with the default egress policy, for the transports
+// that use EgressDialContext.
+func EgressProxy(proxy ProxyFunc) ProxyFunc {
+	return func(req *http.Request) (*url.URL, error) {
+		defaultEgressPolicyMu.RLock()
+		p := defaultEgressPolicy
+		defaultEgressPolicyMu.RUnlock()
+		return p.Proxy(proxy)(req)
+	}
+}
@@ -0,0 +1,235 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicy(t *testing.T) {
+	resolved := map[string][]string{
+		"grafana.com":         {"34.120.177.193"},
+		"api.grafana.net":     {"34.120.177.194"},
+		"rebind.example.com":  {"169.254.169.254"},
+		"internal.example":    {"10.0.0.5"},
+		"mixed.example.com":   {"169.254.169.254", "34.120.177.195"},
+		"metadata.google.com": {"34.120.177.196"},
+	}
+
+	tcs := []struct {
+		desc         string
+		allowed      []string
+		denied       []string
+		address      string
+		expectedDial string
+		expectedErr  error
+	}{
+		{
+			desc:        "should deny metadata IP by default",
+			denied:      DefaultDeniedHosts,
+			address:     "169.254.169.254:80",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:        "should deny metadata host name by default",
+			denied:      DefaultDeniedHosts,
+			address:     "metadata.google.internal:80",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:        "should deny the ECS task metadata endpoint by default",
+			denied:      DefaultDeniedHosts,
+			address:     "169.254.170.2:80",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:        "should deny IPv6 link-local addresses by default",
+			denied:      DefaultDeniedHosts,
+			address:     "[fe80::1]:80",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:        "should deny host resolving to a denied IP",
+			denied:      DefaultDeniedHosts,
+			address:     "rebind.example.com:80",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:         "should only dial allowed addresses of a host",
+			denied:       DefaultDeniedHosts,
+			address:      "mixed.example.com:443",
+			expectedDial: "34.120.177.195:443",
+		},
+		{
+			desc:         "should allow any other host without allow rules",
+			denied:       DefaultDeniedHosts,
+			address:      "grafana.com:443",
+			expectedDial: "34.120.177.193:443",
+		},
+		{
+			desc:         "should allow host matching wildcard",
+			allowed:      []string{"*.grafana.net"},
+			address:      "api.grafana.net:443",
+			expectedDial: "34.120.177.194:443",
+		},
+		{
+			desc:        "should deny host not matching allow rules",
+			allowed:     []string{"*.grafana.net"},
+			address:     "grafana.com:443",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:         "should allow host resolving into allowed CIDR",
+			allowed:      []string{"10.0.0.0/8"},
+			address:      "internal.example:9090",
+			expectedDial: "10.0.0.5:9090",
+		},
+		{
+			desc:        "should deny IP outside allowed CIDR",
+			allowed:     []string{"10.0.0.0/8"},
+			address:     "192.168.1.1:9090",
+			expectedErr: ErrEgressDenied,
+		},
+		{
+			desc:        "should prefer denied over allowed rules",
+			allowed:     []string{"*.example.com"},
+			denied:      []string{"169.254.0.0/16"},
+			address:     "rebind.example.com:80",
+			expectedErr: ErrEgressDenied,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			p, err := NewEgressPolicy(tc.allowed, tc.denied)
+			require.NoError(t, err)
+			p.lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+				var addrs []net.IPAddr
+				for _, ip := range resolved[host] {
+					addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+				}
+				return addrs, nil
+			}
+
+			dialed := ""
+			dial := p.DialContext(func(_ context.Context, _, address string) (net.Conn, error) {
+				dialed = address
+				return nil, nil
+			})
+
+			_, err = dial(context.Background(), "tcp", tc.address)
+			if tc.expectedErr != nil {
+				require.True(t, errors.Is(err, tc.expectedErr), "expected %v, got %v", tc.expectedErr, err)
+				require.Empty(t, dialed)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDial, dialed)
+		})
+	}
+
+	t.Run("should check the host of requests sent through a proxy", func(t *testing.T) {
+		p, err := NewEgressPolicy([]string{"*.example.com", "34.120.177.0/24"}, DefaultDeniedHosts)
+		require.NoError(t, err)
+		p.lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+			ips, ok := resolved[host]
+			if !ok {
+				return nil, errors.New("no such host")
+			}
+			var addrs []net.IPAddr
+			for _, ip := range ips {
+				addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+			}
+			return addrs, nil
+		}
+		proxyURL, _ := url.Parse("http://proxy.internal:3128")
+		proxy := p.Proxy(func(*http.Request) (*url.URL, error) { return proxyURL, nil })
+
+		for target, expectedErr := range map[string]error{
+			"http://169.254.169.254/latest/meta-data": ErrEgressDenied,
+			"http://rebind.example.com/":              ErrEgressDenied,
+			"http://mixed.example.com/":               ErrEgressDenied,
+			"http://internal.example/":                ErrEgressDenied,
+			"https://grafana.com/":                    nil,
+			"https://only-proxy.example.com/":         nil,
+			"https://only-proxy.example.org/":         ErrEgressDenied,
+		} {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			u, err := proxy(req)
+			if expectedErr != nil {
+				require.True(t, errors.Is(err, expectedErr), "%s: expected %v, got %v", target, expectedErr, err)
+				continue
+			}
+			require.NoError(t, err, target)
+			require.Equal(t, proxyURL, u)
+		}
+
+		// requests that don't use a proxy are checked when dialing
+		direct := p.Proxy(func(*http.Request) (*url.URL, error) { return nil, nil })
+		req, err := http.NewRequest(http.MethodGet, "http://169.254.169.254/", nil)
+		require.NoError(t, err)
+		u, err := direct(req)
+		require.NoError(t, err)
+		require.Nil(t, u)
+	})
+
+	t.Run("should check the connections made for an organization against its policy", func(t *testing.T) {
+		p, err := NewEgressPolicy(nil, DefaultDeniedHosts)
+		require.NoError(t, err)
+		p.lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+			var addrs []net.IPAddr
+			for _, ip := range resolved[host] {
+				addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+			}
+			return addrs, nil
+		}
+		orgPolicy, err := NewEgressPolicy([]string{"*.grafana.net", "10.0.0.0/8"}, []string{"10.0.0.5"})
+		require.NoError(t, err)
+		SetOrgEgressPolicyFunc(func(_ context.Context, orgID int64) (*EgressPolicy, error) {
+			if orgID == 2 {
+				return orgPolicy, nil
+			}
+			return nil, nil
+		})
+		t.Cleanup(func() { SetOrgEgressPolicyFunc(nil) })
+
+		dial := p.DialContext(func(_ context.Context, _, address string) (net.Conn, error) {
+			return nil, nil
+		})
+		for address, expectedErr := range map[string]error{
+			"api.grafana.net:443": nil,
+			"grafana.com:443":     ErrEgressDenied,
+			"10.0.0.6:9090":       nil,
+			"10.0.0.5:9090":       ErrEgressDenied,
+			"169.254.170.2:80":    ErrEgressDenied,
+		} {
+			_, err := dial(WithEgressOrgID(context.Background(), 2), "tcp", address)
+			require.ErrorIs(t, err, expectedErr, address)
+		}
+		_, err = dial(WithEgressOrgID(context.Background(), 1), "tcp", "grafana.com:443")
+		require.NoError(t, err)
+
+		// requests that don't use a proxy are checked as well, since their
+		// connection may have been dialed for another organization
+		direct := p.Proxy(nil)
+		req, err := http.NewRequestWithContext(WithEgressOrgID(context.Background(), 2), http.MethodGet, "https://grafana.com/", nil)
+		require.NoError(t, err)
+		_, err = direct(req)
+		require.ErrorIs(t, err, ErrEgressDenied)
+		req, err = http.NewRequestWithContext(WithEgressOrgID(context.Background(), 2), http.MethodGet, "https://api.grafana.net/", nil)
+		require.NoError(t, err)
+		_, err = direct(req)
+		require.NoError(t, err)
+	})
+
+	t.Run("should return error for invalid CIDR", func(t *testing.T) {
+		_, err := NewEgressPolicy(nil, []string{"10.0.0.0/99"})
+		require.Error(t, err)
+	})
+}
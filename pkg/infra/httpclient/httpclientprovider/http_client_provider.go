@@ -6,6 +6,7 @@ import (
 	"time"
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics/metricutil"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -37,10 +38,15 @@ func New(cfg *setting.Cfg, validator models.PluginRequestValidator, tracer traci
 	}
 
 	setDefaultTimeoutOptions(cfg)
+	egressPolicy := newEgressPolicy(cfg, logger)
+	httpclient.SetDefaultEgressPolicy(egressPolicy)
 
 	return newProviderFunc(sdkhttpclient.ProviderOptions{
 		Middlewares: middlewares,
 		ConfigureTransport: func(opts sdkhttpclient.Options, transport *http.Transport) {
+			transport.DialContext = egressPolicy.DialContext(transport.DialContext)
+			transport.Proxy = egressPolicy.Proxy(transport.Proxy)
+
 			datasourceName, exists := opts.Labels["datasource_name"]
 			if !exists {
 				return
@@ -55,6 +61,25 @@ func New(cfg *setting.Cfg, validator models.PluginRequestValidator, tracer traci
 	})
 }
 
+// newEgressPolicy creates the policy restricting the hosts outgoing requests
+// may connect to. The link-local ranges and the cloud metadata endpoints are
+// denied unless configured otherwise.
+func newEgressPolicy(cfg *setting.Cfg, logger log.Logger) *httpclient.EgressPolicy {
+	// An empty denied_hosts still denies the default hosts, they are never
+	// meant to be reached through data sources or notifiers.
+	denied := cfg.EgressDeniedHosts
+	if len(denied) == 0 {
+		denied = httpclient.DefaultDeniedHosts
+	}
+	policy, err := httpclient.NewEgressPolicy(cfg.EgressAllowedHosts, denied)
+	if err != nil {
+		// The rules are validated when the configuration is read, so this is not expected
+		logger.Error("Invalid egress policy, only denying the default denied hosts", "error", err)
+		policy, _ = httpclient.NewEgressPolicy(nil, httpclient.DefaultDeniedHosts)
+	}
+	return policy
+}
+
 // newConntrackRoundTripper takes a http.DefaultTransport and adds the Conntrack Dialer
 // so we can instrument outbound connections
 func newConntrackRoundTripper(name string, transport *http.Transport) *http.Transport {
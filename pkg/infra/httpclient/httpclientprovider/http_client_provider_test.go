@@ -1,11 +1,15 @@
 package httpclientprovider
 
 import (
+	"context"
+	"net"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/services/validations"
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -63,3 +67,15 @@ func TestHTTPClientProvider(t *testing.T) {
 		require.Equal(t, SigV4MiddlewareName, o.Middlewares[8].(sdkhttpclient.MiddlewareName).MiddlewareName())
 	})
 }
+
+func TestNewEgressPolicy(t *testing.T) {
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, nil
+	}
+
+	for _, denied := range [][]string{nil, {}} {
+		policy := newEgressPolicy(&setting.Cfg{EgressDeniedHosts: denied}, log.New("test"))
+		_, err := policy.DialContext(dial)(context.Background(), "tcp", "169.254.169.254:80")
+		require.ErrorIs(t, err, httpclient.ErrEgressDenied)
+	}
+}
@@ -99,8 +99,20 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingFailoverTotal is a metric counter for renders failed over from a renderer to the next one
+	MRenderingFailoverTotal *prometheus.CounterVec
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
+
+	// MDataSourceQueriesCancelled is a metric counter for data source queries cancelled by the client or a timeout
+	MDataSourceQueriesCancelled *prometheus.CounterVec
+
+	// MDataSourceProxyResponsesTooLarge is a metric counter for data source proxy responses exceeding the maximum response size
+	MDataSourceProxyResponsesTooLarge *prometheus.CounterVec
+
+	// MDataSourceQueriesCoarsened is a metric counter for data source queries whose interval was coarsened by the aggregation policy of the data source
+	MDataSourceQueriesCoarsened *prometheus.CounterVec
 )
 
 // Timers
@@ -119,6 +131,9 @@ var (
 
 	// MAccessEvaluationsSummary is a metric summary for loading permissions request duration when evaluating access
 	MAccessEvaluationsSummary prometheus.Histogram
+
+	// MDataSourceProxyResponseSize is a metric histogram for the size of data source proxy responses
+	MDataSourceProxyResponseSize *prometheus.HistogramVec
 )
 
 // StatTotals
@@ -387,6 +402,15 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingFailoverTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "rendering_failover_total",
+			Help:      "counter for renders failed over from a renderer to the next one",
+			Namespace: ExporterName,
+		},
+		[]string{"renderer"},
+	)
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -533,6 +557,31 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MDataSourceQueriesCancelled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "datasource_queries_cancelled_total",
+		Help:      "counter for data source queries cancelled because the client went away or the query timed out",
+		Namespace: ExporterName,
+	}, []string{"datasource_type", "reason"})
+
+	MDataSourceProxyResponsesTooLarge = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "datasource_proxy_responses_too_large_total",
+		Help:      "counter for data source proxy responses rejected or aborted because they exceeded the maximum response size",
+		Namespace: ExporterName,
+	}, []string{"datasource_type", "reason"})
+
+	MDataSourceQueriesCoarsened = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "datasource_queries_coarsened_total",
+		Help:      "counter for data source queries over wide time ranges whose interval was coarsened by the aggregation policy of the data source",
+		Namespace: ExporterName,
+	}, []string{"datasource_type"})
+
+	MDataSourceProxyResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "datasource_proxy_response_size_bytes",
+		Help:      "histogram of the size of data source proxy responses",
+		Namespace: ExporterName,
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+	}, []string{"datasource_type"})
+
 	StatsTotalLibraryPanels = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_library_panels",
 		Help:      "total amount of library panels in the database",
@@ -620,6 +669,7 @@ func initMetricVars() {
 		MRenderingRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
+		MRenderingFailoverTotal,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
@@ -641,6 +691,10 @@ func initMetricVars() {
 		StatsTotalDashboardVersions,
 		StatsTotalAnnotations,
 		MAccessEvaluationCount,
+		MDataSourceQueriesCancelled,
+		MDataSourceProxyResponsesTooLarge,
+		MDataSourceQueriesCoarsened,
+		MDataSourceProxyResponseSize,
 		StatsTotalLibraryPanels,
 		StatsTotalLibraryVariables,
 		StatsTotalDataKeys,
@@ -22,6 +22,8 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+const statsHistoryDayFormat = "2006-01-02"
+
 type Service struct {
 	cfg                *setting.Cfg
 	sqlstore           sqlstore.Store
@@ -35,6 +37,7 @@ type Service struct {
 	log log.Logger
 
 	startTime                time.Time
+	lastHistoryDay           string
 	concurrentUserStatsCache memoConcurrentUserStats
 	promFlavorCache          memoPrometheusFlavor
 	usageStatProviders       []registry.ProvidesUsageStats
@@ -89,6 +92,7 @@ func (s *Service) RegisterProviders(usageStatProviders []registry.ProvidesUsageS
 
 func (s *Service) Run(ctx context.Context) error {
 	s.updateTotalStats(ctx)
+	s.updateStatsHistory(ctx)
 	updateStatsTicker := time.NewTicker(time.Minute * 30)
 	defer updateStatsTicker.Stop()
 
@@ -96,6 +100,7 @@ func (s *Service) Run(ctx context.Context) error {
 		select {
 		case <-updateStatsTicker.C:
 			s.updateTotalStats(ctx)
+			s.updateStatsHistory(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -356,6 +361,56 @@ func (s *Service) updateTotalStats(ctx context.Context) bool {
 	return true
 }
 
+// updateStatsHistory saves a snapshot of the system stats once a day and deletes
+// the snapshots older than the retention.
+func (s *Service) updateStatsHistory(ctx context.Context) bool {
+	if !s.cfg.StatsHistoryEnabled {
+		return false
+	}
+	now := time.Now().UTC()
+	day := now.Format(statsHistoryDayFormat)
+	if day == s.lastHistoryDay {
+		return false
+	}
+
+	statsQuery := models.GetSystemStatsQuery{}
+	if err := s.sqlstore.GetSystemStats(ctx, &statsQuery); err != nil {
+		s.log.Error("Failed to get system stats", "error", err)
+		return false
+	}
+	stats := statsQuery.Result
+	cmd := models.SaveStatsHistoryCommand{History: &models.StatsHistory{
+		Day:              day,
+		Users:            stats.Users,
+		ActiveUsers:      stats.ActiveUsers,
+		DailyActiveUsers: stats.DailyActiveUsers,
+		Orgs:             stats.Orgs,
+		Dashboards:       stats.Dashboards,
+		Folders:          stats.Folders,
+		Datasources:      stats.Datasources,
+		Alerts:           stats.Alerts,
+		AlertRules:       stats.AlertRules,
+		Created:          now,
+	}}
+	if err := s.sqlstore.SaveStatsHistory(ctx, &cmd); err != nil {
+		s.log.Error("Failed to save stats history", "error", err)
+		return false
+	}
+	s.lastHistoryDay = day
+
+	if s.cfg.StatsHistoryRetentionDays > 0 {
+		deleteCmd := models.DeleteExpiredStatsHistoryCommand{
+			OlderThan: now.AddDate(0, 0, -s.cfg.StatsHistoryRetentionDays).Format(statsHistoryDayFormat),
+		}
+		if err := s.sqlstore.DeleteExpiredStatsHistory(ctx, &deleteCmd); err != nil {
+			s.log.Error("Failed to delete expired stats history", "error", err)
+		} else if deleteCmd.DeletedRows > 0 {
+			s.log.Debug("Deleted expired stats history", "rows", deleteCmd.DeletedRows)
+		}
+	}
+	return true
+}
+
 func (s *Service) appCount(ctx context.Context) int {
 	return len(s.plugins.Plugins(ctx, plugins.App))
 }
@@ -79,6 +79,22 @@ func TestTotalStatsUpdate(t *testing.T) {
 	}
 }
 
+func TestStatsHistoryUpdate(t *testing.T) {
+	sqlStore := mockstore.NewSQLStoreMock()
+	mockSystemStats(sqlStore)
+	s := createService(t, setting.NewCfg(), sqlStore)
+
+	s.cfg.StatsHistoryEnabled = false
+	assert.False(t, s.updateStatsHistory(context.Background()))
+
+	s.cfg.StatsHistoryEnabled = true
+	assert.True(t, s.updateStatsHistory(context.Background()))
+	assert.Equal(t, time.Now().UTC().Format(statsHistoryDayFormat), s.lastHistoryDay)
+
+	// the snapshot of the day is only saved once
+	assert.False(t, s.updateStatsHistory(context.Background()))
+}
+
 var _ registry.ProvidesUsageStats = (*dummyUsageStatProvider)(nil)
 
 type dummyUsageStatProvider struct {
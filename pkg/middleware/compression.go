@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+
+	// brotliCompressionLevel trades some compression ratio for speed, as
+	// responses are compressed on the fly.
+	brotliCompressionLevel = 4
+)
+
+// compressWriter is implemented by both the gzip and the brotli writer.
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var compressEncoders = map[string]func(w io.Writer) compressWriter{
+	encodingGzip:   func(w io.Writer) compressWriter { return gzip.NewWriter(w) },
+	encodingBrotli: func(w io.Writer) compressWriter { return brotli.NewWriterLevel(w, brotliCompressionLevel) },
+}
+
+type matcher func(s string) bool
+
+func prefix(p string) matcher { return func(s string) bool { return strings.HasPrefix(s, p) } }
+func substr(p string) matcher { return func(s string) bool { return strings.Contains(s, p) } }
+
+var compressionIgnoredPaths = []matcher{
+	prefix("/api/datasources"),
+	prefix("/api/plugins"),
+	prefix("/api/plugin-proxy/"),
+	prefix("/metrics"),
+	prefix("/api/live/ws"),   // WebSocket does not support gzip compression.
+	prefix("/api/live/push"), // WebSocket does not support gzip compression.
+	substr("/resources"),
+}
+
+// incompressibleContentTypes are already compressed or streamed, so
+// compressing them again only costs CPU.
+var incompressibleContentTypes = []matcher{
+	prefix("image/"),
+	prefix("video/"),
+	prefix("audio/"),
+	prefix("font/woff"),
+	prefix("application/zip"),
+	prefix("application/gzip"),
+	prefix("application/x-gzip"),
+	prefix("application/x-brotli"),
+	prefix("application/pdf"),
+	prefix("text/event-stream"),
+}
+
+// Compressor returns a middleware compressing responses with the first of the
+// configured encodings that the client accepts. Responses smaller than the
+// configured minimum size, already compressed content, streamed responses and
+// the responses of excluded paths are sent uncompressed. The encodings and the
+// minimum size of a path are the ones of its most specific compression route,
+// or the ones of the server.
+func Compressor(cfg *setting.Cfg) func(http.Handler) http.Handler {
+	ignoredPaths := compressionIgnoredPaths
+	for _, p := range cfg.CompressionExcludedPaths {
+		ignoredPaths = append(ignoredPaths, prefix(p))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requestPath := req.URL.RequestURI()
+
+			for _, pathMatcher := range ignoredPaths {
+				if pathMatcher(requestPath) {
+					next.ServeHTTP(rw, req)
+					return
+				}
+			}
+
+			if req.Method == http.MethodHead {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			algorithms, minSize := compressionSettings(cfg, req.URL.Path)
+			encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"), algorithms)
+			rw.Header().Add("Vary", "Accept-Encoding")
+			if encoding == "" {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			crw := &compressResponseWriter{
+				ResponseWriter: rw.(web.ResponseWriter),
+				encoding:       encoding,
+				minSize:        minSize,
+			}
+
+			next.ServeHTTP(crw, req)
+			// We can't really handle close errors at this point and we can't report them to the caller
+			_ = crw.close()
+		})
+	}
+}
+
+// compressionSettings returns the encodings and the minimum size of compressed
+// responses for a path.
+func compressionSettings(cfg *setting.Cfg, path string) ([]string, int) {
+	algorithms, minSize := cfg.CompressionAlgorithms, cfg.CompressionMinSize
+	matched := ""
+	for _, route := range cfg.CompressionRoutes {
+		if !strings.HasPrefix(path, route.PathPrefix) || len(route.PathPrefix) <= len(matched) {
+			continue
+		}
+		matched = route.PathPrefix
+		minSize = route.MinSize
+		algorithms = route.Algorithms
+		if len(algorithms) == 0 {
+			algorithms = cfg.CompressionAlgorithms
+		}
+	}
+	return algorithms, minSize
+}
+
+// negotiateEncoding returns the first of the supported encodings that is
+// accepted according to the Accept-Encoding header, or an empty string.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[name] = q > 0
+	}
+
+	for _, encoding := range supported {
+		if ok, exists := accepted[encoding]; ok || (!exists && accepted["*"]) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressResponseWriter buffers the beginning of a response until it knows
+// whether the response is worth compressing, i.e. whether it is at least
+// minSize bytes and has a compressible content type.
+type compressResponseWriter struct {
+	web.ResponseWriter
+
+	encoding string
+	minSize  int
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder compressWriter
+}
+
+func (crw *compressResponseWriter) WriteHeader(status int) {
+	if crw.decided {
+		crw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	crw.status = status
+}
+
+func (crw *compressResponseWriter) Write(p []byte) (int, error) {
+	if !crw.decided {
+		if crw.Header().Get("Content-Type") == "" {
+			crw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		if !crw.compressible() {
+			if err := crw.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			crw.buf = append(crw.buf, p...)
+			if len(crw.buf) < crw.minSize {
+				return len(p), nil
+			}
+			if err := crw.decide(true); err != nil {
+				return 0, err
+			}
+			return len(p), nil
+		}
+	}
+
+	if crw.encoder != nil {
+		return crw.encoder.Write(p)
+	}
+	return crw.ResponseWriter.Write(p)
+}
+
+// Flush sends buffered data to the client. Responses that are flushed before
+// reaching the minimum size are streamed and therefore not compressed.
+func (crw *compressResponseWriter) Flush() {
+	if !crw.decided {
+		if err := crw.decide(false); err != nil {
+			return
+		}
+	}
+	if crw.encoder != nil {
+		_ = crw.encoder.Flush()
+	}
+	crw.ResponseWriter.Flush()
+}
+
+func (crw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := crw.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("compress ResponseWriter doesn't implement the Hijacker interface")
+}
+
+func (crw *compressResponseWriter) compressible() bool {
+	if crw.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+	if crw.status == http.StatusNoContent || crw.status == http.StatusNotModified {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(crw.Header().Get("Content-Type"))
+	if err != nil {
+		return true
+	}
+	for _, m := range incompressibleContentTypes {
+		if m(mediaType) {
+			return false
+		}
+	}
+	return true
+}
+
+// decide writes the status and headers and the buffered data, compressed or not.
+func (crw *compressResponseWriter) decide(compress bool) error {
+	crw.decided = true
+
+	if compress {
+		crw.Header().Set("Content-Encoding", crw.encoding)
+		crw.Header().Del("Content-Length")
+		crw.encoder = compressEncoders[crw.encoding](crw.ResponseWriter)
+	}
+
+	if crw.status != 0 {
+		crw.ResponseWriter.WriteHeader(crw.status)
+	}
+
+	if len(crw.buf) == 0 {
+		return nil
+	}
+	buf := crw.buf
+	crw.buf = nil
+	if crw.encoder != nil {
+		_, err := crw.encoder.Write(buf)
+		return err
+	}
+	_, err := crw.ResponseWriter.Write(buf)
+	return err
+}
+
+func (crw *compressResponseWriter) close() error {
+	if !crw.decided {
+		if err := crw.decide(false); err != nil {
+			return err
+		}
+	}
+	if crw.encoder != nil {
+		return crw.encoder.Close()
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tcs := []struct {
+		acceptEncoding string
+		supported      []string
+		expected       string
+	}{
+		{acceptEncoding: "gzip, deflate, br", supported: []string{"br", "gzip"}, expected: "br"},
+		{acceptEncoding: "gzip, deflate, br", supported: []string{"gzip", "br"}, expected: "gzip"},
+		{acceptEncoding: "gzip, br;q=0", supported: []string{"br", "gzip"}, expected: "gzip"},
+		{acceptEncoding: "*", supported: []string{"br"}, expected: "br"},
+		{acceptEncoding: "*, br;q=0", supported: []string{"br"}, expected: ""},
+		{acceptEncoding: "deflate", supported: []string{"gzip", "br"}, expected: ""},
+		{acceptEncoding: "", supported: []string{"gzip"}, expected: ""},
+	}
+
+	for _, tc := range tcs {
+		require.Equal(t, tc.expected, negotiateEncoding(tc.acceptEncoding, tc.supported), tc.acceptEncoding)
+	}
+}
+
+func TestCompressor(t *testing.T) {
+	largeBody := strings.Repeat(`{"dashboard":"json"}`, 100)
+
+	cfg := setting.NewCfg()
+	cfg.CompressionAlgorithms = []string{"br", "gzip"}
+	cfg.CompressionMinSize = 1024
+	cfg.CompressionExcludedPaths = []string{"/api/excluded"}
+	cfg.CompressionRoutes = []setting.CompressionRoute{
+		{PathPrefix: "/api/dashboards", Algorithms: []string{"gzip"}, MinSize: 10},
+		{PathPrefix: "/api/dashboards/home", MinSize: 4096},
+	}
+
+	serve := func(t *testing.T, path, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		Compressor(cfg)(handler).ServeHTTP(web.NewResponseWriter(req.Method, rec), req)
+		return rec
+	}
+
+	writeJSON := func(body string) http.HandlerFunc {
+		return func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte(body))
+		}
+	}
+
+	t.Run("should compress large responses with the preferred encoding", func(t *testing.T) {
+		rec := serve(t, "/api/search", "gzip, br", writeJSON(largeBody))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+		body, err := ioutil.ReadAll(brotli.NewReader(rec.Body))
+		require.NoError(t, err)
+		require.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("should fall back to gzip", func(t *testing.T) {
+		rec := serve(t, "/api/search", "gzip", writeJSON(largeBody))
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+		r, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("should not compress responses smaller than the minimum size", func(t *testing.T) {
+		rec := serve(t, "/api/search", "gzip, br", writeJSON(`{"small":true}`))
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, `{"small":true}`, rec.Body.String())
+	})
+
+	t.Run("should use the algorithms and minimum size of the route", func(t *testing.T) {
+		rec := serve(t, "/api/dashboards/uid/abc", "gzip, br", writeJSON(`{"small":true}`))
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("should use the most specific route and fall back to the server algorithms", func(t *testing.T) {
+		rec := serve(t, "/api/dashboards/home", "gzip, br", writeJSON(largeBody))
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, largeBody, rec.Body.String())
+
+		rec = serve(t, "/api/dashboards/home", "gzip, br", writeJSON(strings.Repeat(largeBody, 3)))
+		require.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("should not compress excluded paths", func(t *testing.T) {
+		rec := serve(t, "/api/excluded/stuff", "gzip, br", writeJSON(largeBody))
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, largeBody, rec.Body.String())
+	})
+
+	t.Run("should not compress already compressed content", func(t *testing.T) {
+		image := bytes.Repeat([]byte{0xff}, 2048)
+		rec := serve(t, "/avatar/abc", "gzip, br", func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Type", "image/jpeg")
+			_, _ = rw.Write(image)
+		})
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, image, rec.Body.Bytes())
+	})
+
+	t.Run("should not compress flushed responses", func(t *testing.T) {
+		rec := serve(t, "/api/stream", "gzip, br", func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write([]byte("first"))
+			rw.(http.Flusher).Flush()
+			_, _ = rw.Write([]byte(largeBody))
+		})
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "first"+largeBody, rec.Body.String())
+	})
+
+	t.Run("should keep the status of responses without a body", func(t *testing.T) {
+		rec := serve(t, "/api/search", "gzip, br", func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusNoContent)
+		})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+}
@@ -11,14 +11,20 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-// AddCSPHeader adds the Content Security Policy header.
-func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) func(http.Handler) http.Handler {
+// AddCSPHeader adds the Content Security Policy header, using the settings
+// of the organization of the request when securityHeaders is set.
+func AddCSPHeader(cfg *setting.Cfg, securityHeaders securityheaders.Service, logger log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-			if !cfg.CSPEnabled {
+			settings := securitySettings(req, cfg, securityHeaders)
+			if !settings.CSPEnabled {
+				if len(settings.FrameAncestors) > 0 {
+					rw.Header().Set("Content-Security-Policy", frameAncestorsDirective(settings.FrameAncestors))
+				}
 				next.ServeHTTP(rw, req)
 				return
 			}
@@ -26,7 +32,7 @@ func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) func(http.Handler) http.H
 			logger.Debug("Adding CSP header to response", "cfg", fmt.Sprintf("%p", cfg))
 
 			ctx := contexthandler.FromContext(req.Context())
-			if cfg.CSPTemplate == "" {
+			if settings.CSPTemplate == "" {
 				logger.Debug("CSP template not configured, so returning 500")
 				ctx.JsonApiErr(500, "CSP template has to be configured", nil)
 				return
@@ -39,11 +45,14 @@ func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) func(http.Handler) http.H
 			}
 
 			nonce := base64.RawStdEncoding.EncodeToString(buf[:])
-			val := strings.ReplaceAll(cfg.CSPTemplate, "$NONCE", fmt.Sprintf("'nonce-%s'", nonce))
+			val := strings.ReplaceAll(settings.CSPTemplate, "$NONCE", fmt.Sprintf("'nonce-%s'", nonce))
 
 			re := regexp.MustCompile(`^\w+:(//)?`)
 			rootPath := re.ReplaceAllString(cfg.AppURL, "")
 			val = strings.ReplaceAll(val, "$ROOT_PATH", rootPath)
+			if len(settings.FrameAncestors) > 0 {
+				val = strings.TrimRight(strings.TrimSpace(val), ";") + "; " + frameAncestorsDirective(settings.FrameAncestors)
+			}
 			rw.Header().Set("Content-Security-Policy", val)
 			ctx.RequestNonce = nonce
 			logger.Debug("Successfully generated CSP nonce", "nonce", nonce)
@@ -51,3 +60,21 @@ func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) func(http.Handler) http.H
 		})
 	}
 }
+
+// securitySettings returns the security header settings for the organization
+// of the request, falling back to the global settings for requests outside
+// of an organization.
+func securitySettings(req *http.Request, cfg *setting.Cfg, securityHeaders securityheaders.Service) securityheaders.Settings {
+	if securityHeaders == nil {
+		return securityheaders.GlobalSettings(cfg)
+	}
+	ctx := contexthandler.FromContext(req.Context())
+	if ctx == nil || ctx.SignedInUser == nil || ctx.OrgId <= 0 {
+		return securityheaders.GlobalSettings(cfg)
+	}
+	return securityHeaders.GetEffectiveSettings(req.Context(), ctx.OrgId)
+}
+
+func frameAncestorsDirective(ancestors []string) string {
+	return "frame-ancestors " + strings.Join(ancestors, " ")
+}
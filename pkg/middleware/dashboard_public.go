@@ -1,7 +1,12 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func SetPublicDashboardFlag() func(c *models.ReqContext) {
@@ -9,3 +14,26 @@ func SetPublicDashboardFlag() func(c *models.ReqContext) {
 		c.IsPublicDashboardView = true
 	}
 }
+
+// PublicDashboardAccessToken authenticates requests with the access token of a
+// public dashboard in the :accessToken route parameter. It only gives access to
+// the dashboard of the access token, so it must only be used by the routes of
+// that dashboard and its queries.
+func PublicDashboardAccessToken(dashboardService dashboards.DashboardService) web.Handler {
+	return func(c *models.ReqContext) {
+		pd, dash, err := dashboardService.GetPublicDashboard(c.Req.Context(), web.Params(c.Req)[":accessToken"])
+		if err != nil {
+			var dashboardErr models.DashboardErr
+			if errors.As(err, &dashboardErr) && dashboardErr.StatusCode < http.StatusInternalServerError {
+				// don't tell whether the access token exists
+				c.JsonApiErr(http.StatusNotFound, models.ErrPublicDashboardNotFound.Error(), nil)
+				return
+			}
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to get public dashboard", err)
+			return
+		}
+
+		c.IsPublicDashboardView = true
+		c.PublicDashboardAccess = &models.PublicDashboardAccess{PublicDashboard: pd, Dashboard: dash}
+	}
+}
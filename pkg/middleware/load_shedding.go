@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// alertingPaths are the APIs of legacy and unified alerting, they have the highest
+// priority so that alerting keeps working when the instance is saturated.
+var alertingPaths = []string{
+	"/api/alerting/",
+	"/api/ruler/",
+	"/api/prometheus/",
+	"/api/alertmanager/",
+	"/api/v1/eval",
+	"/api/v1/rule/test",
+	"/api/alerts",
+	"/api/alert-notifications",
+}
+
+// LoadShedding rejects or queues the requests of low priority classes while the
+// instance is saturated. It needs to be after the context handler, since requests
+// are classified by how they are authenticated.
+func LoadShedding(loadShedding loadshedding.Service, retryAfterSeconds int) web.Handler {
+	return func(c *models.ReqContext) {
+		release, err := loadShedding.Admit(c.Req.Context(), requestClass(c))
+		if err != nil {
+			if errors.Is(err, loadshedding.ErrRequestShed) {
+				c.Resp.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+			}
+			c.JsonApiErr(http.StatusServiceUnavailable, loadshedding.ErrRequestShed.Error(), err)
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+func requestClass(c *models.ReqContext) loadshedding.Class {
+	path := c.Req.URL.Path
+	for _, prefix := range alertingPaths {
+		if strings.HasPrefix(path, prefix) {
+			return loadshedding.ClassAlerting
+		}
+	}
+	if c.IsRenderCall || strings.HasPrefix(path, "/render/") {
+		return loadshedding.ClassRendering
+	}
+	if c.SignedInUser != nil && c.ApiKeyId != 0 {
+		return loadshedding.ClassAPI
+	}
+	// Service account tokens and basic auth, requests of the UI are authenticated
+	// with the session cookie.
+	if c.UserToken == nil {
+		header := c.Req.Header.Get("Authorization")
+		if strings.HasPrefix(header, "Bearer ") || strings.HasPrefix(header, "Basic ") {
+			return loadshedding.ClassAPI
+		}
+	}
+	return loadshedding.ClassInteractive
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+	"github.com/grafana/grafana/pkg/services/loadshedding/loadsheddingtest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	fake := loadsheddingtest.NewFakeService()
+
+	m := web.New()
+	m.UseMiddleware(web.Renderer("../../public/views", "[[", "]]"))
+	m.Use(getContextHandler(t, setting.NewCfg(), nil, nil).Middleware)
+	m.Use(LoadShedding(fake, 5))
+	okHandler := func(c *models.ReqContext) {
+		c.JSON(http.StatusOK, map[string]interface{}{"message": "OK"})
+	}
+	m.Get("/api/search", okHandler)
+	m.Get("/api/prometheus/grafana/api/v1/rules", okHandler)
+	m.Get("/render/d-solo/abc", okHandler)
+
+	doReq := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		m.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("requests are classified", func(t *testing.T) {
+		fake.Admitted = nil
+		doReq("/api/search")
+		doReq("/api/prometheus/grafana/api/v1/rules")
+		doReq("/render/d-solo/abc")
+		assert.Equal(t, []loadshedding.Class{
+			loadshedding.ClassInteractive,
+			loadshedding.ClassAlerting,
+			loadshedding.ClassRendering,
+		}, fake.Admitted)
+		assert.Equal(t, 3, fake.Released)
+	})
+
+	fake.Shed[loadshedding.ClassInteractive] = true
+
+	t.Run("shed requests are rejected", func(t *testing.T) {
+		resp := doReq("/api/search")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Equal(t, "5", resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("other classes are admitted", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doReq("/api/prometheus/grafana/api/v1/rules").Code)
+	})
+}
+
+func TestRequestClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		ctx      models.ReqContext
+		expected loadshedding.Class
+	}{
+		{
+			name:     "session",
+			path:     "/api/search",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{UserId: 1}, UserToken: &models.UserToken{}},
+			expected: loadshedding.ClassInteractive,
+		},
+		{
+			name:     "api key",
+			path:     "/api/search",
+			header:   "Bearer eyJrIjoi",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{ApiKeyId: 1}},
+			expected: loadshedding.ClassAPI,
+		},
+		{
+			name:     "service account token",
+			path:     "/api/search",
+			header:   "Bearer glsa_abc",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{UserId: 2}},
+			expected: loadshedding.ClassAPI,
+		},
+		{
+			name:     "basic auth",
+			path:     "/api/dashboards/uid/abc",
+			header:   "Basic YWRtaW46YWRtaW4=",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{UserId: 1}},
+			expected: loadshedding.ClassAPI,
+		},
+		{
+			name:     "alerting with api key",
+			path:     "/api/ruler/grafana/api/v1/rules",
+			header:   "Bearer eyJrIjoi",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{ApiKeyId: 1}},
+			expected: loadshedding.ClassAlerting,
+		},
+		{
+			name:     "image renderer",
+			path:     "/d-solo/abc",
+			ctx:      models.ReqContext{SignedInUser: &models.SignedInUser{UserId: 1}, IsRenderCall: true},
+			expected: loadshedding.ClassRendering,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			c := tt.ctx
+			c.Context = &web.Context{Req: req}
+			assert.Equal(t, tt.expected, requestClass(&c))
+		})
+	}
+}
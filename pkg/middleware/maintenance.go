@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maintenanceAllowedPaths are API endpoints that accept writes during maintenance,
+// because they only read data or are needed to end the maintenance.
+var maintenanceAllowedPaths = []string{
+	"/api/admin/maintenance",
+	"/api/ds/query",
+	"/api/tsdb/query",
+	"/api/datasources/proxy/",
+	"/api/dashboards/calculate-diff",
+	"/api/frontend-metrics",
+	"/api/search",
+}
+
+// MaintenanceMode rejects API requests that change data with 503 while the
+// instance is in maintenance mode.
+func MaintenanceMode(maintenanceService maintenance.Service) web.Handler {
+	return func(c *models.ReqContext) {
+		status := maintenanceService.Status()
+		if !status.Enabled || !isMaintenanceBlocked(c.Req) {
+			return
+		}
+
+		message := status.Message
+		if message == "" {
+			message = "Grafana is in maintenance mode"
+		}
+		c.JsonApiErr(http.StatusServiceUnavailable, message, nil)
+	}
+}
+
+func isMaintenanceBlocked(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	path := req.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	for _, allowed := range maintenanceAllowedPaths {
+		if strings.HasPrefix(path, allowed) {
+			return false
+		}
+	}
+	// Resource calls of data source and app plugins, e.g. label lookups
+	if strings.Contains(path, "/resources") &&
+		(strings.HasPrefix(path, "/api/datasources/") || strings.HasPrefix(path, "/api/plugins/")) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/services/maintenance/maintenancetest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestMaintenanceModeMiddleware(t *testing.T) {
+	fake := maintenancetest.NewFakeService()
+
+	m := web.New()
+	m.UseMiddleware(web.Renderer("../../public/views", "[[", "]]"))
+	m.Use(getContextHandler(t, setting.NewCfg(), nil, nil).Middleware)
+	m.Use(MaintenanceMode(fake))
+	okHandler := func(c *models.ReqContext) {
+		c.JSON(http.StatusOK, map[string]interface{}{"message": "OK"})
+	}
+	m.Get("/api/dashboards/uid/abc", okHandler)
+	m.Post("/api/dashboards/db", okHandler)
+	m.Post("/api/ds/query", okHandler)
+	m.Delete("/api/admin/maintenance", okHandler)
+	m.Post("/api/datasources/uid/abc/resources/labels", okHandler)
+
+	doReq := func(method, path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(t, err)
+		m.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("writes are allowed when maintenance mode is disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doReq(http.MethodPost, "/api/dashboards/db").Code)
+	})
+
+	fake.ExpectedStatus = maintenance.Status{Enabled: true, Message: "Database upgrade"}
+
+	t.Run("writes are rejected during maintenance", func(t *testing.T) {
+		resp := doReq(http.MethodPost, "/api/dashboards/db")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Database upgrade")
+	})
+
+	t.Run("reads are allowed during maintenance", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doReq(http.MethodGet, "/api/dashboards/uid/abc").Code)
+		assert.Equal(t, http.StatusOK, doReq(http.MethodPost, "/api/ds/query").Code)
+		assert.Equal(t, http.StatusOK, doReq(http.MethodPost, "/api/datasources/uid/abc/resources/labels").Code)
+	})
+
+	t.Run("maintenance mode can be disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doReq(http.MethodDelete, "/api/admin/maintenance").Code)
+	})
+}
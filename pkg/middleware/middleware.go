@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -24,7 +25,7 @@ func HandleNoCacheHeader(ctx *models.ReqContext) {
 	ctx.SkipCache = ctx.Req.Header.Get("X-Grafana-NoCache") == "true"
 }
 
-func AddDefaultResponseHeaders(cfg *setting.Cfg) web.Handler {
+func AddDefaultResponseHeaders(cfg *setting.Cfg, securityHeaders securityheaders.Service) web.Handler {
 	return func(c *web.Context) {
 		c.Resp.Before(func(w web.ResponseWriter) {
 			// if response has already been written, skip.
@@ -36,23 +37,24 @@ func AddDefaultResponseHeaders(cfg *setting.Cfg) web.Handler {
 				addNoCacheHeaders(c.Resp)
 			}
 
-			if !cfg.AllowEmbedding {
+			settings := securitySettings(c.Req, cfg, securityHeaders)
+			if !settings.AllowEmbedding && len(settings.FrameAncestors) == 0 {
 				addXFrameOptionsDenyHeader(w)
 			}
 
-			addSecurityHeaders(w, cfg)
+			addSecurityHeaders(w, cfg, settings)
 		})
 	}
 }
 
 // addSecurityHeaders adds HTTP(S) response headers that enable various security protections in the client's browser.
-func addSecurityHeaders(w web.ResponseWriter, cfg *setting.Cfg) {
-	if cfg.StrictTransportSecurity {
-		strictHeaderValues := []string{fmt.Sprintf("max-age=%v", cfg.StrictTransportSecurityMaxAge)}
-		if cfg.StrictTransportSecurityPreload {
+func addSecurityHeaders(w web.ResponseWriter, cfg *setting.Cfg, settings securityheaders.Settings) {
+	if settings.StrictTransportSecurity {
+		strictHeaderValues := []string{fmt.Sprintf("max-age=%v", settings.StrictTransportSecurityMaxAge)}
+		if settings.StrictTransportSecurityPreload {
 			strictHeaderValues = append(strictHeaderValues, "preload")
 		}
-		if cfg.StrictTransportSecuritySubDomains {
+		if settings.StrictTransportSecuritySubDomains {
 			strictHeaderValues = append(strictHeaderValues, "includeSubDomains")
 		}
 		w.Header().Set("Strict-Transport-Security", strings.Join(strictHeaderValues, "; "))
@@ -61,7 +61,7 @@ func recoveryScenario(t *testing.T, desc string, url string, fn scenarioFunc) {
 		sc.m = web.New()
 		sc.m.Use(Recovery(cfg))
 
-		sc.m.Use(AddDefaultResponseHeaders(cfg))
+		sc.m.Use(AddDefaultResponseHeaders(cfg, nil))
 		sc.m.UseMiddleware(web.Renderer(viewsPath, "[[", "]]"))
 
 		sc.userAuthTokenService = auth.NewFakeUserAuthTokenService()
@@ -23,6 +23,9 @@ type ReqContext struct {
 	// RequestNonce is a cryptographic request identifier for use with Content Security Policy.
 	RequestNonce          string
 	IsPublicDashboardView bool
+	// PublicDashboardAccess is set on requests authenticated with the access
+	// token of a public dashboard.
+	PublicDashboardAccess *PublicDashboardAccess
 
 	PerfmonTimer   prometheus.Summary
 	LookupTokenErr error
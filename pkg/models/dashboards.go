@@ -59,8 +59,12 @@ var (
 		StatusCode: 400,
 		Status:     "empty-name",
 	}
-	ErrDashboardFolderCannotHaveParent = DashboardErr{
-		Reason:     "A Dashboard Folder cannot be added to another folder",
+	ErrDashboardFolderCyclicParent = DashboardErr{
+		Reason:     "A folder cannot be moved into itself or one of its subfolders",
+		StatusCode: 400,
+	}
+	ErrDashboardFolderMaxDepthExceeded = DashboardErr{
+		Reason:     "Folders cannot be nested deeper than the maximum depth",
 		StatusCode: 400,
 	}
 	ErrDashboardsWithSameSlugExists = DashboardErr{
@@ -382,6 +386,9 @@ type SaveDashboardCommand struct {
 	FolderId     int64            `json:"folderId"`
 	FolderUid    string           `json:"folderUid"`
 	IsFolder     bool             `json:"isFolder"`
+	// DryRun runs the checks of the save and reports the ones that fail
+	// without saving the dashboard.
+	DryRun bool `json:"dryRun"`
 
 	UpdatedAt time.Time
 
@@ -5,6 +5,10 @@ var (
 		Reason:     "Failed to generate unique dashboard id",
 		StatusCode: 500,
 	}
+	ErrPublicDashboardFailedGenerateAccessToken = DashboardErr{
+		Reason:     "Failed to generate unique access token for public dashboard",
+		StatusCode: 500,
+	}
 	ErrPublicDashboardNotFound = DashboardErr{
 		Reason:     "Public dashboard not found",
 		StatusCode: 404,
@@ -16,7 +20,23 @@ var (
 		Status:     "not-found",
 	}
 	ErrPublicDashboardIdentifierNotSet = DashboardErr{
-		Reason:     "No Uid for public dashboard specified",
+		Reason:     "No access token for public dashboard specified",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardInvalidTimeRange = DashboardErr{
+		Reason:     "Invalid time range for public dashboard",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardTimeRangeNotAllowed = DashboardErr{
+		Reason:     "Time range is outside of the time range of the public dashboard",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardInvalidTemplateVariables = DashboardErr{
+		Reason:     "Template variables of public dashboard must map variable names to lists of allowed values",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardVariableNotAllowed = DashboardErr{
+		Reason:     "Template variable value not allowed for public dashboard",
 		StatusCode: 400,
 	}
 )
@@ -30,13 +50,26 @@ type PublicDashboard struct {
 	Uid          string `json:"uid" xorm:"uid"`
 	DashboardUid string `json:"dashboardUid" xorm:"dashboard_uid"`
 	OrgId        int64  `json:"orgId" xorm:"org_id"`
+	// AccessToken is the secret anonymous users access the public dashboard with.
+	// It is generated on the first save and can only be changed by rotating it.
+	AccessToken  string `json:"accessToken" xorm:"access_token"`
 	TimeSettings string `json:"timeSettings" xorm:"time_settings"`
+	// TemplateVariables is a JSON object mapping the names of the variables
+	// anonymous users can change to the values they can choose from.
+	TemplateVariables string `json:"templateVariables" xorm:"template_variables"`
 }
 
 func (pd PublicDashboard) TableName() string {
 	return "dashboard_public_config"
 }
 
+// PublicDashboardAccess is the public dashboard a request was authenticated
+// for with an access token.
+type PublicDashboardAccess struct {
+	PublicDashboard *PublicDashboard
+	Dashboard       *Dashboard
+}
+
 //
 // COMMANDS
 //
@@ -46,3 +79,8 @@ type SavePublicDashboardConfigCommand struct {
 	OrgId                 int64
 	PublicDashboardConfig PublicDashboardConfig
 }
+
+type RotatePublicDashboardAccessTokenCommand struct {
+	DashboardUid string
+	OrgId        int64
+}
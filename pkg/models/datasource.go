@@ -2,8 +2,14 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/jsonschema"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -28,6 +34,15 @@ const (
 	DS_ES_OPENSEARCH  = "grafana-opensearch-datasource"
 )
 
+const (
+	// DsOriginAPI is the origin of the data sources created with the HTTP API.
+	DsOriginAPI DsOrigin = "api"
+	// DsOriginFile is the origin of the data sources provisioned from configuration files.
+	DsOriginFile DsOrigin = "file"
+	// DsOriginTemplate is the origin of the data sources expanded from a data source template.
+	DsOriginTemplate DsOrigin = "template"
+)
+
 var (
 	ErrDataSourceNotFound                = errors.New("data source not found")
 	ErrDataSourceNameExists              = errors.New("data source with the same name already exists")
@@ -37,10 +52,35 @@ var (
 	ErrDataSourceAccessDenied            = errors.New("data source access denied")
 	ErrDataSourceFailedGenerateUniqueUid = errors.New("failed to generate unique datasource ID")
 	ErrDataSourceIdentifierNotSet        = errors.New("unique identifier and org id are needed to be able to get or delete a datasource")
+	ErrDataSourceInvalidSettings         = errors.New("data source settings do not match the schema of the plugin")
+	ErrDataSourceNotProvisioned          = errors.New("only provisioned data sources can be locked or unlocked")
+	ErrDataSourceUnlockReasonRequired    = errors.New("a reason is required to unlock a data source")
+	ErrDataSourceOrderInvalid            = errors.New("invalid data source order")
 )
 
+// DataSourceValidationError lists the settings of a data source that don't match
+// the schema published by its plugin.
+type DataSourceValidationError struct {
+	Errors []jsonschema.FieldError
+}
+
+func (e *DataSourceValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.String())
+	}
+	return fmt.Sprintf("%s: %s", ErrDataSourceInvalidSettings, strings.Join(messages, ", "))
+}
+
+func (e *DataSourceValidationError) Unwrap() error {
+	return ErrDataSourceInvalidSettings
+}
+
 type DsAccess string
 
+// DsOrigin is where a data source is managed from.
+type DsOrigin string
+
 type DataSource struct {
 	Id      int64 `json:"id"`
 	OrgId   int64 `json:"orgId"`
@@ -63,6 +103,7 @@ type DataSource struct {
 	JsonData          *simplejson.Json  `json:"jsonData"`
 	SecureJsonData    map[string][]byte `json:"secureJsonData"`
 	ReadOnly          bool              `json:"readOnly"`
+	Origin            DsOrigin          `json:"origin"`
 	Uid               string            `json:"uid"`
 
 	Created time.Time `json:"created"`
@@ -81,6 +122,86 @@ func (ds DataSource) AllowedCookies() []string {
 	return []string{}
 }
 
+// MaxQueryDuration parses the jsondata.maxQueryDuration and returns the
+// maximum time a single query to the data source may run. The value may be a
+// duration string such as "30s" or a number of seconds. Zero means no limit.
+func (ds DataSource) MaxQueryDuration() time.Duration {
+	if ds.JsonData == nil {
+		return 0
+	}
+
+	value := ds.JsonData.Get("maxQueryDuration")
+	if s, err := value.String(); err == nil {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0
+		}
+		return d
+	}
+	if seconds, err := value.Float64(); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	return 0
+}
+
+// MaxResponseSize parses the jsondata.maxResponseSize and returns the maximum
+// size in bytes of a response proxied from the data source. Zero means no
+// limit other than the [dataproxy] response_limit.
+func (ds DataSource) MaxResponseSize() int64 {
+	if ds.JsonData == nil {
+		return 0
+	}
+
+	value := ds.JsonData.Get("maxResponseSize")
+	if size, err := value.Int64(); err == nil && size > 0 {
+		return size
+	}
+	if s, err := value.String(); err == nil {
+		if size, err := strconv.ParseInt(s, 10, 64); err == nil && size > 0 {
+			return size
+		}
+	}
+
+	return 0
+}
+
+// QueryAggregationPolicy is the policy of a data source for coarsening the
+// interval of queries over wide time ranges, see DataSource.QueryAggregationPolicy.
+type QueryAggregationPolicy struct {
+	// MinRange is the width of the time ranges from which queries are coarsened,
+	// zero disables coarsening.
+	MinRange time.Duration
+	// MaxDataPoints caps the max data points of the coarsened queries, zero keeps
+	// the max data points of the query.
+	MaxDataPoints int64
+}
+
+// QueryAggregationPolicy parses the jsondata.aggregationMinRange and
+// jsondata.aggregationMaxDataPoints. The range may be a duration string such as
+// "7d" or a number of seconds.
+func (ds DataSource) QueryAggregationPolicy() QueryAggregationPolicy {
+	policy := QueryAggregationPolicy{}
+	if ds.JsonData == nil {
+		return policy
+	}
+
+	value := ds.JsonData.Get("aggregationMinRange")
+	if s, err := value.String(); err == nil {
+		if d, err := gtime.ParseDuration(s); err == nil && d > 0 {
+			policy.MinRange = d
+		}
+	} else if seconds, err := value.Float64(); err == nil && seconds > 0 {
+		policy.MinRange = time.Duration(seconds * float64(time.Second))
+	}
+
+	if points, err := ds.JsonData.Get("aggregationMaxDataPoints").Int64(); err == nil && points > 0 {
+		policy.MaxDataPoints = points
+	}
+
+	return policy
+}
+
 // ----------------------
 // COMMANDS
 
@@ -103,6 +224,7 @@ type AddDataSourceCommand struct {
 	OrgId                   int64             `json:"-"`
 	UserId                  int64             `json:"-"`
 	ReadOnly                bool              `json:"-"`
+	Origin                  DsOrigin          `json:"-"`
 	EncryptedSecureJsonData map[string][]byte `json:"-"`
 
 	Result *DataSource `json:"-"`
@@ -128,6 +250,7 @@ type UpdateDataSourceCommand struct {
 	OrgId                   int64             `json:"-"`
 	Id                      int64             `json:"-"`
 	ReadOnly                bool              `json:"-"`
+	Origin                  DsOrigin          `json:"-"`
 	EncryptedSecureJsonData map[string][]byte `json:"-"`
 
 	Result *DataSource `json:"-"`
@@ -145,6 +268,66 @@ type DeleteDataSourceCommand struct {
 	DeletedDatasourcesCount int64
 }
 
+// SetDataSourceReadOnlyCommand locks or unlocks a provisioned data source, so
+// that it can be fixed with the API until it is provisioned again.
+type SetDataSourceReadOnlyCommand struct {
+	OrgId    int64
+	Uid      string
+	ReadOnly bool
+	Reason   string
+	User     *SignedInUser
+
+	Result *DataSource
+}
+
+// SetDefaultDataSourceCommand makes a data source the default data source of
+// its organization, in place of the current one.
+type SetDefaultDataSourceCommand struct {
+	OrgId int64
+	Uid   string
+
+	Result *DataSource
+}
+
+// DataSourceGroup is a group of data sources listed together in the data
+// source pickers, in the order of their uids.
+type DataSourceGroup struct {
+	// Name is the heading of the group, empty for the data sources listed
+	// without a heading.
+	Name string   `json:"name"`
+	Uids []string `json:"uids"`
+}
+
+// DataSourceOrder is the position of a data source in the data source pickers
+// of its organization.
+type DataSourceOrder struct {
+	Id            int64
+	OrgId         int64
+	DataSourceUid string
+	GroupName     string
+	Position      int
+}
+
+// SetDataSourceOrderCommand replaces the order of the data sources of an
+// organization. The data sources that aren't in any group are listed after
+// the groups, by name.
+type SetDataSourceOrderCommand struct {
+	OrgId  int64
+	Groups []*DataSourceGroup
+}
+
+// DataSourceLockEvent records that a data source was locked or unlocked.
+type DataSourceLockEvent struct {
+	Id            int64     `json:"id"`
+	OrgId         int64     `json:"orgId"`
+	DataSourceUid string    `json:"dataSourceUid"`
+	ReadOnly      bool      `json:"readOnly"`
+	Reason        string    `json:"reason"`
+	UserId        int64     `json:"userId"`
+	UserLogin     string    `json:"userLogin"`
+	Created       time.Time `json:"created"`
+}
+
 // ---------------------
 // QUERIES
 
@@ -155,6 +338,10 @@ type GetDataSourcesQuery struct {
 	Result          []*DataSource
 }
 
+type GetAllDataSourcesQuery struct {
+	Result []*DataSource
+}
+
 type GetDataSourcesByTypeQuery struct {
 	Type   string
 	Result []*DataSource
@@ -166,6 +353,22 @@ type GetDefaultDataSourceQuery struct {
 	Result *DataSource
 }
 
+// GetDataSourceOrderQuery gets the groups of data sources of an organization,
+// in the order they're listed in the data source pickers.
+type GetDataSourceOrderQuery struct {
+	OrgId int64
+
+	Result []*DataSourceGroup
+}
+
+// GetDataSourceLockEventsQuery gets the lock events of a data source, the most recent first.
+type GetDataSourceLockEventsQuery struct {
+	OrgId int64
+	Uid   string
+
+	Result []*DataSourceLockEvent
+}
+
 // GetDataSourceQuery will get a DataSource based on OrgID as well as the UID (preferred), ID, or Name.
 // At least one of the UID, ID, or Name properties must be set in addition to OrgID.
 type GetDataSourceQuery struct {
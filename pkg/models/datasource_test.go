@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestDataSource_MaxQueryDuration(t *testing.T) {
+	tcs := []struct {
+		desc     string
+		jsonData map[string]interface{}
+		expected time.Duration
+	}{
+		{desc: "no json data", expected: 0},
+		{desc: "unset", jsonData: map[string]interface{}{}, expected: 0},
+		{desc: "duration string", jsonData: map[string]interface{}{"maxQueryDuration": "1m30s"}, expected: 90 * time.Second},
+		{desc: "seconds", jsonData: map[string]interface{}{"maxQueryDuration": 45}, expected: 45 * time.Second},
+		{desc: "invalid string", jsonData: map[string]interface{}{"maxQueryDuration": "soon"}, expected: 0},
+		{desc: "negative", jsonData: map[string]interface{}{"maxQueryDuration": "-5s"}, expected: 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ds := DataSource{}
+			if tc.jsonData != nil {
+				ds.JsonData = simplejson.NewFromAny(tc.jsonData)
+			}
+			require.Equal(t, tc.expected, ds.MaxQueryDuration())
+		})
+	}
+}
+
+func TestDataSource_MaxResponseSize(t *testing.T) {
+	tcs := []struct {
+		desc     string
+		jsonData map[string]interface{}
+		expected int64
+	}{
+		{desc: "no json data", expected: 0},
+		{desc: "unset", jsonData: map[string]interface{}{}, expected: 0},
+		{desc: "bytes", jsonData: map[string]interface{}{"maxResponseSize": 1048576}, expected: 1048576},
+		{desc: "bytes string", jsonData: map[string]interface{}{"maxResponseSize": "2048"}, expected: 2048},
+		{desc: "invalid string", jsonData: map[string]interface{}{"maxResponseSize": "big"}, expected: 0},
+		{desc: "negative", jsonData: map[string]interface{}{"maxResponseSize": -1}, expected: 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ds := DataSource{}
+			if tc.jsonData != nil {
+				ds.JsonData = simplejson.NewFromAny(tc.jsonData)
+			}
+			require.Equal(t, tc.expected, ds.MaxResponseSize())
+		})
+	}
+}
+
+func TestDataSource_QueryAggregationPolicy(t *testing.T) {
+	tcs := []struct {
+		desc     string
+		jsonData map[string]interface{}
+		expected QueryAggregationPolicy
+	}{
+		{desc: "no json data", expected: QueryAggregationPolicy{}},
+		{desc: "unset", jsonData: map[string]interface{}{}, expected: QueryAggregationPolicy{}},
+		{desc: "duration string", jsonData: map[string]interface{}{"aggregationMinRange": "7d"}, expected: QueryAggregationPolicy{MinRange: 7 * 24 * time.Hour}},
+		{desc: "seconds", jsonData: map[string]interface{}{"aggregationMinRange": 3600}, expected: QueryAggregationPolicy{MinRange: time.Hour}},
+		{desc: "invalid string", jsonData: map[string]interface{}{"aggregationMinRange": "wide"}, expected: QueryAggregationPolicy{}},
+		{
+			desc:     "max data points",
+			jsonData: map[string]interface{}{"aggregationMinRange": "30d", "aggregationMaxDataPoints": 500},
+			expected: QueryAggregationPolicy{MinRange: 30 * 24 * time.Hour, MaxDataPoints: 500},
+		},
+		{
+			desc:     "negative max data points",
+			jsonData: map[string]interface{}{"aggregationMinRange": "30d", "aggregationMaxDataPoints": -1},
+			expected: QueryAggregationPolicy{MinRange: 30 * 24 * time.Hour},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ds := DataSource{}
+			if tc.jsonData != nil {
+				ds.JsonData = simplejson.NewFromAny(tc.jsonData)
+			}
+			require.Equal(t, tc.expected, ds.QueryAggregationPolicy())
+		})
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// MaxNestedFolderDepth is the maximum number of levels of nested folders,
+// counting the folders in the root.
+const MaxNestedFolderDepth = 8
+
 // Typed errors
 var (
 	ErrFolderNotFound                = errors.New("folder not found")
@@ -26,6 +30,10 @@ type Folder struct {
 	Url     string
 	Version int
 
+	// ParentId is the id of the folder containing this folder, 0 for the
+	// folders in the root.
+	ParentId int64
+
 	Created time.Time
 	Updated time.Time
 
@@ -49,6 +57,7 @@ func DashboardToFolder(dash *Dashboard) *Folder {
 		Id:        dash.Id,
 		Uid:       dash.Uid,
 		Title:     dash.Title,
+		ParentId:  dash.FolderId,
 		HasAcl:    dash.HasAcl,
 		Url:       dash.GetUrl(),
 		Version:   dash.Version,
@@ -85,8 +94,9 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder `json:"-"`
 }
@@ -96,6 +106,9 @@ type UpdateFolderCommand struct {
 	Title     string `json:"title"`
 	Version   int    `json:"version"`
 	Overwrite bool   `json:"overwrite"`
+	// ParentUid moves the folder into the folder with the uid, or into the
+	// root when it's empty. The folder is kept where it is when it's nil.
+	ParentUid *string `json:"parentUid"`
 
 	Result *Folder `json:"-"`
 }
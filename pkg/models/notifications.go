@@ -22,6 +22,8 @@ type SendEmailCommand struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*SendEmailAttachFile
+	// OrgId selects the email templates of the organization, if it customized them.
+	OrgId int64
 }
 
 // SendEmailCommandSync is the command for sending emails synchronously
@@ -28,11 +28,13 @@ type FindPersistedDashboardsQuery struct {
 	DashboardUIDs []string
 	Type          string
 	FolderIds     []int64
-	Tags          []string
-	Limit         int64
-	Page          int64
-	Permission    PermissionType
-	Sort          SortOption
+	// SubtreeFolderUID limits the result to the dashboards and folders nested in the folder, at any depth.
+	SubtreeFolderUID string
+	Tags             []string
+	Limit            int64
+	Page             int64
+	Permission       PermissionType
+	Sort             SortOption
 
 	Filters []interface{}
 
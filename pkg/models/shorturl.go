@@ -6,7 +6,11 @@ import (
 )
 
 var (
-	ErrShortURLNotFound = errors.New("short URL not found")
+	ErrShortURLNotFound      = errors.New("short URL not found")
+	ErrShortURLExpired       = errors.New("short URL has expired")
+	ErrShortURLSlugTaken     = errors.New("short URL slug is already in use")
+	ErrShortURLInvalidSlug   = errors.New("short URL slug may only contain letters, numbers, dashes and underscores and be at most 40 characters")
+	ErrShortURLInvalidExpiry = errors.New("short URL expiry must be in the future")
 )
 
 type ShortUrl struct {
@@ -17,6 +21,23 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix time after which the short URL no longer redirects, 0 means never
+	ExpiresAt int64
+	// Hits is the number of times the short URL has been visited
+	Hits int64
+}
+
+// Expired returns true if the short URL has an expiry that is in the past.
+func (s *ShortUrl) Expired(now time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+type CreateShortUrlCommand struct {
+	Path string
+	// Slug is a custom uid for the short URL, a random uid is generated when empty
+	Slug string
+	// ExpiresAt is optional, the short URL never expires when it is the zero time
+	ExpiresAt time.Time
 }
 
 type DeleteShortUrlCommand struct {
@@ -24,3 +45,29 @@ type DeleteShortUrlCommand struct {
 
 	NumDeleted int64
 }
+
+type SearchShortUrlsQuery struct {
+	OrgId int64
+	// Query matches the slug or the path of the short URLs
+	Query       string
+	OnlyExpired bool
+	// Sort is one of hits, lastSeen and created, the default
+	Sort  string
+	Page  int
+	Limit int
+}
+
+type SearchShortUrlsResult struct {
+	TotalCount int64
+	ShortUrls  []*ShortUrl
+}
+
+// PruneShortUrlsCommand deletes the short URLs of an organization that have expired
+// or have not been visited since NotSeenSince.
+type PruneShortUrlsCommand struct {
+	OrgId int64
+	// NotSeenSince is optional, when it is the zero time only expired short URLs are deleted
+	NotSeenSince time.Time
+
+	NumDeleted int64
+}
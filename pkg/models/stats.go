@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SystemStats struct {
 	Dashboards                int64
 	Datasources               int64
@@ -119,3 +121,41 @@ type UserStats struct {
 	Editors int64
 	Viewers int64
 }
+
+// StatsHistory is a daily snapshot of key system stats, so that the growth of
+// the instance can be tracked over time.
+type StatsHistory struct {
+	Id int64 `json:"-"`
+	// Day is the UTC date the snapshot was taken on, formatted as YYYY-MM-DD.
+	Day              string    `json:"day"`
+	Users            int64     `json:"users"`
+	ActiveUsers      int64     `json:"activeUsers"`
+	DailyActiveUsers int64     `json:"dailyActiveUsers"`
+	Orgs             int64     `json:"orgs"`
+	Dashboards       int64     `json:"dashboards"`
+	Folders          int64     `json:"folders"`
+	Datasources      int64     `json:"datasources"`
+	Alerts           int64     `json:"alerts"`
+	AlertRules       int64     `json:"alertRules"`
+	Created          time.Time `json:"created"`
+}
+
+// SaveStatsHistoryCommand saves the snapshot of the day, unless one was already saved.
+type SaveStatsHistoryCommand struct {
+	History *StatsHistory
+}
+
+// GetStatsHistoryQuery returns the snapshots between the From and To days, which
+// are inclusive and formatted as YYYY-MM-DD.
+type GetStatsHistoryQuery struct {
+	From string
+	To   string
+
+	Result []*StatsHistory
+}
+
+type DeleteExpiredStatsHistoryCommand struct {
+	OlderThan string
+
+	DeletedRows int64
+}
@@ -21,6 +21,9 @@ type Team struct {
 	OrgId int64  `json:"orgId"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// Priority orders the preferences of the teams of a user, the preferences of
+	// the team with the highest priority take precedence.
+	Priority int64 `json:"priority"`
 
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
@@ -41,7 +44,9 @@ type UpdateTeamCommand struct {
 	Id    int64
 	Name  string
 	Email string
-	OrgId int64 `json:"-"`
+	// Priority is kept when it isn't set.
+	Priority *int64 `json:"priority"`
+	OrgId    int64  `json:"-"`
 }
 
 type DeleteTeamCommand struct {
@@ -86,10 +91,20 @@ type TeamDTO struct {
 	OrgId         int64           `json:"orgId"`
 	Name          string          `json:"name"`
 	Email         string          `json:"email"`
+	Priority      int64           `json:"priority"`
 	AvatarUrl     string          `json:"avatarUrl"`
 	MemberCount   int64           `json:"memberCount"`
 	Permission    PermissionType  `json:"permission"`
 	AccessControl map[string]bool `json:"accessControl"`
+	// HomeDashboard is the home dashboard set in the preferences of the team
+	HomeDashboard *TeamHomeDashboard `json:"homeDashboard,omitempty"`
+}
+
+type TeamHomeDashboard struct {
+	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
 }
 
 type SearchTeamQueryResult struct {
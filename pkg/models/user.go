@@ -41,6 +41,9 @@ type User struct {
 	Created    time.Time
 	Updated    time.Time
 	LastSeenAt time.Time
+	// PasswordChanged is when the password was last set, it is zero for
+	// the users whose password was set before it was tracked.
+	PasswordChanged time.Time
 }
 
 func (u *User) NameOrFallback() string {
@@ -0,0 +1,21 @@
+package adapters
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// ResourceResponse collects the streamed response of a resource call, for
+// callers that need the whole response rather than forwarding it.
+type ResourceResponse struct {
+	// Status is the status of the first part of the response.
+	Status int
+	Body   []byte
+}
+
+func (r *ResourceResponse) Send(resp *backend.CallResourceResponse) error {
+	if r.Status == 0 {
+		r.Status = resp.Status
+	}
+	r.Body = append(r.Body, resp.Body...)
+	return nil
+}
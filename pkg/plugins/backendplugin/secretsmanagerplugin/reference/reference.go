@@ -0,0 +1,108 @@
+// Package reference is a reference implementation of a secrets manager
+// plugin. It keeps secrets in memory and is meant as a starting point for
+// plugins backed by an actual secret manager, and for testing.
+//
+// A plugin binary only needs to serve it:
+//
+//	func main() {
+//		secretsmanagerplugin.Serve(reference.NewServer())
+//	}
+package reference
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	smp "github.com/grafana/grafana/pkg/plugins/backendplugin/secretsmanagerplugin"
+)
+
+type key struct {
+	orgID     int64
+	namespace string
+	typ       string
+}
+
+type Server struct {
+	smp.UnimplementedSecretsManagerServer
+
+	mu      sync.RWMutex
+	secrets map[key]string
+}
+
+var _ smp.SecretsManagerServer = (*Server)(nil)
+
+func NewServer() *Server {
+	return &Server{secrets: map[key]string{}}
+}
+
+func toKey(k *smp.Key) key {
+	if k == nil {
+		return key{}
+	}
+	return key{orgID: k.OrgId, namespace: k.Namespace, typ: k.Type}
+}
+
+func (s *Server) GetSecret(_ context.Context, req *smp.GetSecretRequest) (*smp.GetSecretResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, exists := s.secrets[toKey(req.KeyDescriptor)]
+	return &smp.GetSecretResponse{DecryptedValue: value, Exists: exists}, nil
+}
+
+func (s *Server) SetSecret(_ context.Context, req *smp.SetSecretRequest) (*smp.SetSecretResponse, error) {
+	if req.KeyDescriptor == nil {
+		return &smp.SetSecretResponse{UserFriendlyError: "missing key"}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets[toKey(req.KeyDescriptor)] = req.Value
+	return &smp.SetSecretResponse{}, nil
+}
+
+func (s *Server) DeleteSecret(_ context.Context, req *smp.DeleteSecretRequest) (*smp.DeleteSecretResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, toKey(req.KeyDescriptor))
+	return &smp.DeleteSecretResponse{}, nil
+}
+
+func (s *Server) ListSecrets(_ context.Context, req *smp.ListSecretsRequest) (*smp.ListSecretsResponse, error) {
+	filter := toKey(req.KeyDescriptor)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*smp.Key, 0)
+	for k := range s.secrets {
+		if k.namespace != filter.namespace || k.typ != filter.typ {
+			continue
+		}
+		if !req.AllOrganizations && k.orgID != filter.orgID {
+			continue
+		}
+		keys = append(keys, &smp.Key{OrgId: k.orgID, Namespace: k.namespace, Type: k.typ})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].OrgId < keys[j].OrgId })
+
+	return &smp.ListSecretsResponse{Keys: keys}, nil
+}
+
+func (s *Server) RenameSecret(_ context.Context, req *smp.RenameSecretRequest) (*smp.RenameSecretResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := toKey(req.KeyDescriptor)
+	value, exists := s.secrets[old]
+	if !exists {
+		return &smp.RenameSecretResponse{}, nil
+	}
+
+	delete(s.secrets, old)
+	s.secrets[key{orgID: old.orgID, namespace: req.NewNamespace, typ: old.typ}] = value
+	return &smp.RenameSecretResponse{}, nil
+}
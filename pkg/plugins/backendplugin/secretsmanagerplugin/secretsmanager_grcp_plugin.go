@@ -13,9 +13,15 @@ type SecretsManagerPlugin interface {
 
 type SecretsManagerGRPCPlugin struct {
 	plugin.NetRPCUnsupportedPlugin
+	// Impl is the secrets manager served by the plugin process. It is only
+	// required on the plugin side.
+	Impl SecretsManagerServer
 }
 
 func (p *SecretsManagerGRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	if p.Impl != nil {
+		RegisterSecretsManagerServer(s, p.Impl)
+	}
 	return nil
 }
 
@@ -0,0 +1,24 @@
+package secretsmanagerplugin
+
+import (
+	sdkgrpcplugin "github.com/grafana/grafana-plugin-sdk-go/backend/grpcplugin"
+	"github.com/hashicorp/go-plugin"
+)
+
+// Serve starts serving a secrets manager implementation over gRPC. It is
+// meant to be called from the main function of a secretsmanager plugin.
+func Serve(impl SecretsManagerServer) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugin.HandshakeConfig{
+			ProtocolVersion:  sdkgrpcplugin.ProtocolVersion,
+			MagicCookieKey:   sdkgrpcplugin.MagicCookieKey,
+			MagicCookieValue: sdkgrpcplugin.MagicCookieValue,
+		},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			sdkgrpcplugin.ProtocolVersion: {
+				"secretsmanager": &SecretsManagerGRPCPlugin{Impl: impl},
+			},
+		},
+		GRPCServer: plugin.DefaultGRPCServer,
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
 )
@@ -17,6 +18,8 @@ func (m *PluginManager) QueryData(ctx context.Context, req *backend.QueryDataReq
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
 
+	// the data source connects to its hosts with the egress policy of its organization
+	ctx = httpclient.WithEgressOrgID(ctx, req.PluginContext.OrgID)
 	var resp *backend.QueryDataResponse
 	err := instrumentation.InstrumentQueryDataRequest(req.PluginContext.PluginID, func() (innerErr error) {
 		resp, innerErr = plugin.QueryData(ctx, req)
@@ -52,6 +55,7 @@ func (m *PluginManager) CallResource(ctx context.Context, req *backend.CallResou
 	if !exists {
 		return backendplugin.ErrPluginNotRegistered
 	}
+	ctx = httpclient.WithEgressOrgID(ctx, req.PluginContext.OrgID)
 	err := instrumentation.InstrumentCallResourceRequest(p.PluginID(), func() error {
 		if err := p.CallResource(ctx, req, sender); err != nil {
 			return err
@@ -90,6 +94,7 @@ func (m *PluginManager) CheckHealth(ctx context.Context, req *backend.CheckHealt
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
 
+	ctx = httpclient.WithEgressOrgID(ctx, req.PluginContext.OrgID)
 	var resp *backend.CheckHealthResult
 	err := instrumentation.InstrumentCheckHealthRequest(p.PluginID(), func() (innerErr error) {
 		resp, innerErr = p.CheckHealth(ctx, req)
@@ -135,5 +140,5 @@ func (m *PluginManager) RunStream(ctx context.Context, req *backend.RunStreamReq
 		return backendplugin.ErrPluginNotRegistered
 	}
 
-	return plugin.RunStream(ctx, req, sender)
+	return plugin.RunStream(httpclient.WithEgressOrgID(ctx, req.PluginContext.OrgID), req, sender)
 }
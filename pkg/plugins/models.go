@@ -210,6 +210,11 @@ type DataSourceDTO struct {
 	Preload    bool                   `json:"preload"`
 	Module     string                 `json:"module,omitempty"`
 	JSONData   map[string]interface{} `json:"jsonData"`
+	// Group is the group the data source is listed in by the data source pickers.
+	Group string `json:"group,omitempty"`
+	// SortIndex is the position of the data source in the data source pickers,
+	// starting at 1. It is 0 for the data sources listed after the ordered ones.
+	SortIndex int `json:"sortIndex,omitempty"`
 
 	BasicAuth       string `json:"basicAuth,omitempty"`
 	WithCredentials bool   `json:"withCredentials,omitempty"`
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/components/jsonschema"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -119,6 +120,8 @@ type JSONData struct {
 
 	// App settings
 	AutoEnabled bool `json:"autoEnabled"`
+	// AnnotationSource is set by backend app plugins that provide annotations
+	AnnotationSource bool `json:"annotationSource"`
 
 	// Datasource settings
 	Annotations  bool            `json:"annotations"`
@@ -133,11 +136,21 @@ type JSONData struct {
 	Mixed        bool            `json:"mixed,omitempty"`
 	Streaming    bool            `json:"streaming"`
 	SDK          bool            `json:"sdk,omitempty"`
+	// SettingsSchema describes the settings of the data sources of the plugin,
+	// which are validated when data sources are created or updated.
+	SettingsSchema *SettingsSchema `json:"settingsSchema,omitempty"`
 
 	// Backend (Datasource + Renderer + SecretsManager)
 	Executable string `json:"executable,omitempty"`
 }
 
+// SettingsSchema are the JSON schemas of the jsonData and secureJsonData settings
+// of a data source.
+type SettingsSchema struct {
+	JSONData       *jsonschema.Schema `json:"jsonData,omitempty"`
+	SecureJSONData *jsonschema.Schema `json:"secureJsonData,omitempty"`
+}
+
 func (d JSONData) DashboardIncludes() []*Includes {
 	result := []*Includes{}
 	for _, include := range d.Includes {
@@ -11,18 +11,32 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/annotationsource/annotationsourceimpl"
 	"github.com/grafana/grafana/pkg/services/cleanup"
+	"github.com/grafana/grafana/pkg/services/configdrift/configdriftimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks/dashboardlinksimpl"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardusage/dashboardusageimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables/varsimpl"
+	"github.com/grafana/grafana/pkg/services/datasources/tlscerts"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/featureoverrides"
+	"github.com/grafana/grafana/pkg/services/fips"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/loadshedding/loadsheddingimpl"
+	"github.com/grafana/grafana/pkg/services/maintenance/maintenanceimpl"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
+	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/queryschema/queryschemaimpl"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	secretsStore "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	secretsMigrator "github.com/grafana/grafana/pkg/services/secrets/migrator"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/thumbs"
@@ -38,6 +52,16 @@ func ProvideBackgroundServiceRegistry(
 	pluginsUpdateChecker *updatechecker.PluginsService, metrics *metrics.InternalMetricsService,
 	secretsService *secretsManager.SecretsService, remoteCache *remotecache.RemoteCache,
 	thumbnailsService thumbs.Service, StorageService store.StorageService, searchService searchV2.SearchService, entityEventsService store.EntityEventsService,
+	dataSourceCertificates *tlscerts.Service, secretsMigrateToPlugin *secretsStore.MigrateToPluginService,
+	fipsService *fips.Service, secretsMigratorService *secretsMigrator.SecretsMigrator,
+	maintenanceService *maintenanceimpl.Service, featureOverrides *featureoverrides.Service,
+	preferenceService *prefimpl.Service, annotationSources *annotationsourceimpl.Service,
+	loadSheddingService *loadsheddingimpl.Service,
+	dashboardLinks *dashboardlinksimpl.Service,
+	dashboardUsage *dashboardusageimpl.Service,
+	querySchemas *queryschemaimpl.Service,
+	configDrift *configdriftimpl.Service,
+	variableCacheWarmer *varsimpl.CacheWarmer,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ *dashboardsnapshots.Service, _ *alerting.AlertNotificationService,
 	_ serviceaccounts.Service, _ *guardian.Provider,
@@ -67,6 +91,20 @@ func ProvideBackgroundServiceRegistry(
 		thumbnailsService,
 		searchService,
 		entityEventsService,
+		dataSourceCertificates,
+		secretsMigrateToPlugin,
+		fipsService,
+		secretsMigratorService,
+		maintenanceService,
+		featureOverrides,
+		preferenceService,
+		annotationSources,
+		loadSheddingService,
+		dashboardLinks,
+		dashboardUsage,
+		querySchemas,
+		configDrift,
+		variableCacheWarmer,
 	)
 }
 
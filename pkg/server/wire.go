@@ -37,53 +37,102 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/ossaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/accessgrants/accessgrantsimpl"
+	"github.com/grafana/grafana/pkg/services/adhocfilters/adhocfiltersimpl"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/annotationsource/annotationsourceimpl"
+	"github.com/grafana/grafana/pkg/services/announcements/announcementsimpl"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess/anonymousaccessimpl"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/services/branding/brandingimpl"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/comments"
+	"github.com/grafana/grafana/pkg/services/configdrift"
+	"github.com/grafana/grafana/pkg/services/configdrift/configdriftimpl"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/authproxy"
+	"github.com/grafana/grafana/pkg/services/dashboardapply/dashboardapplyimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive/dashboardarchiveimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy/dashboardcopyimpl"
 	"github.com/grafana/grafana/pkg/services/dashboardimport"
 	dashboardimportservice "github.com/grafana/grafana/pkg/services/dashboardimport/service"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks/dashboardlinksimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardrefs/dashboardrefsimpl"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardstore "github.com/grafana/grafana/pkg/services/dashboards/database"
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
+	"github.com/grafana/grafana/pkg/services/dashboardschema/dashboardschemaimpl"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates/dashboardtemplatesimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/dashboardusage/dashboardusageimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables/varsimpl"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata/datasourcemetadataimpl"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/datasources/tlscerts"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates/datasourcetemplatesimpl"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+	"github.com/grafana/grafana/pkg/services/egresspolicy/egresspolicyimpl"
+	"github.com/grafana/grafana/pkg/services/emaildelivery/emaildeliveryimpl"
+	"github.com/grafana/grafana/pkg/services/emailtemplates/emailtemplatesimpl"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/featureoverrides"
+	"github.com/grafana/grafana/pkg/services/fips"
+	"github.com/grafana/grafana/pkg/services/folderexport/folderexportimpl"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/inbox/inboximpl"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+	"github.com/grafana/grafana/pkg/services/loadshedding/loadsheddingimpl"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoservice"
 	authinfodatabase "github.com/grafana/grafana/pkg/services/login/authinfoservice/database"
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/services/maintenance/maintenanceimpl"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/onboarding/onboardingimpl"
+	"github.com/grafana/grafana/pkg/services/orginvites/orginvitesimpl"
+	"github.com/grafana/grafana/pkg/services/ownership/ownershipimpl"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot/panelsnapshotimpl"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy/passwordpolicyimpl"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings/service"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/querypolicy/querypolicyimpl"
+	"github.com/grafana/grafana/pkg/services/queryschema"
+	"github.com/grafana/grafana/pkg/services/queryschema/queryschemaimpl"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/resourcelabels/resourcelabelsimpl"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/audit/auditimpl"
 	secretsDatabase "github.com/grafana/grafana/pkg/services/secrets/database"
 	secretsStore "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	secretsMigrator "github.com/grafana/grafana/pkg/services/secrets/migrator"
+	"github.com/grafana/grafana/pkg/services/securityheaders/securityheadersimpl"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	serviceaccountsmanager "github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -95,7 +144,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/teamguardian"
 	teamguardianDatabase "github.com/grafana/grafana/pkg/services/teamguardian/database"
 	teamguardianManager "github.com/grafana/grafana/pkg/services/teamguardian/manager"
+	"github.com/grafana/grafana/pkg/services/testdatarecording/testdatarecordingimpl"
 	"github.com/grafana/grafana/pkg/services/thumbs"
+	"github.com/grafana/grafana/pkg/services/timeregions/timeregionsimpl"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor"
@@ -126,6 +177,7 @@ var wireBasicSet = wire.NewSet(
 	New,
 	api.ProvideHTTPServer,
 	query.ProvideService,
+	query.ProvidePanelCache,
 	bus.ProvideBus,
 	wire.Bind(new(bus.Bus), new(*bus.InProcBus)),
 	thumbs.ProvideService,
@@ -219,6 +271,8 @@ var wireBasicSet = wire.NewSet(
 	grafanads.ProvideService,
 	dashboardsnapshots.ProvideService,
 	datasourceservice.ProvideService,
+	secretref.ProvideService,
+	tlscerts.ProvideService,
 	wire.Bind(new(datasources.DataSourceService), new(*datasourceservice.Service)),
 	pluginSettings.ProvideService,
 	wire.Bind(new(pluginsettings.Service), new(*pluginSettings.Service)),
@@ -250,6 +304,10 @@ var wireBasicSet = wire.NewSet(
 	comments.ProvideService,
 	guardian.ProvideService,
 	secretsStore.ProvideService,
+	secretsStore.ProvideMigrateToPluginService,
+	fips.ProvideService,
+	secretsMigrator.ProvideSecretsMigrator,
+	auditimpl.ProvideService,
 	avatar.ProvideAvatarCacheServer,
 	authproxy.ProvideAuthProxy,
 	statscollector.ProvideService,
@@ -266,6 +324,52 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(accesscontrol.DashboardPermissionsService), new(*ossaccesscontrol.DashboardPermissionsService)),
 	starimpl.ProvideService,
 	dashverimpl.ProvideService,
+	brandingimpl.ProvideService,
+	accessgrantsimpl.ProvideService,
+	emailtemplatesimpl.ProvideService,
+	emaildeliveryimpl.ProvideService,
+	dashboardarchiveimpl.ProvideService,
+	folderexportimpl.ProvideService,
+	panelsnapshotimpl.ProvideService,
+	orginvitesimpl.ProvideService,
+	datasourcetemplatesimpl.ProvideService,
+	passwordpolicyimpl.ProvideService,
+	dashboardlinksimpl.ProvideService,
+	wire.Bind(new(dashboardlinks.Service), new(*dashboardlinksimpl.Service)),
+	dashboardtemplatesimpl.ProvideService,
+	queryschemaimpl.ProvideService,
+	anonymousaccessimpl.ProvideService,
+	configdriftimpl.ProvideService,
+	wire.Bind(new(configdrift.Service), new(*configdriftimpl.Service)),
+	wire.Bind(new(queryschema.Service), new(*queryschemaimpl.Service)),
+	dashboardcopyimpl.ProvideService,
+	testdatarecordingimpl.ProvideService,
+	securityheadersimpl.ProvideService,
+	varsimpl.ProvideService,
+	varsimpl.ProvideCacheWarmer,
+	adhocfiltersimpl.ProvideService,
+	timeregionsimpl.ProvideService,
+	inboximpl.ProvideService,
+	announcementsimpl.ProvideService,
+	maintenanceimpl.ProvideService,
+	featureoverrides.ProvideService,
+	onboardingimpl.ProvideService,
+	ownershipimpl.ProvideService,
+	dashboardschemaimpl.ProvideService,
+	dashboardapplyimpl.ProvideService,
+	dashboardrefsimpl.ProvideService,
+	resourcelabelsimpl.ProvideService,
+	annotationsourceimpl.ProvideService,
+	querypolicyimpl.ProvideService,
+	egresspolicyimpl.ProvideService,
+	wire.Bind(new(egresspolicy.Service), new(*egresspolicyimpl.Service)),
+	datasourcemetadataimpl.ProvideService,
+	wire.Bind(new(maintenance.Service), new(*maintenanceimpl.Service)),
+	loadsheddingimpl.ProvideService,
+	wire.Bind(new(loadshedding.Service), new(*loadsheddingimpl.Service)),
+	wire.Bind(new(annotationsource.Service), new(*annotationsourceimpl.Service)),
+	dashboardusageimpl.ProvideService,
+	wire.Bind(new(dashboardusage.Service), new(*dashboardusageimpl.Service)),
 )
 
 var wireSet = wire.NewSet(
@@ -281,6 +385,7 @@ var wireSet = wire.NewSet(
 	wire.Bind(new(sqlstore.Store), new(*sqlstore.SQLStore)),
 	wire.Bind(new(db.DB), new(*sqlstore.SQLStore)),
 	prefimpl.ProvideService,
+	wire.Bind(new(pref.Service), new(*prefimpl.Service)),
 )
 
 var wireTestSet = wire.NewSet(
@@ -300,6 +405,7 @@ var wireTestSet = wire.NewSet(
 	wire.Bind(new(sqlstore.Store), new(*sqlstore.SQLStore)),
 	wire.Bind(new(db.DB), new(*sqlstore.SQLStore)),
 	prefimpl.ProvideService,
+	wire.Bind(new(pref.Service), new(*prefimpl.Service)),
 )
 
 func Initialize(cla setting.CommandLineArgs, opts Options, apiOpts api.ServerOptions) (*Server, error) {
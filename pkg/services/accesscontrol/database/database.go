@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -52,12 +53,36 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 			return err
 		}
 
-		return nil
+		return s.getGrantedPermissions(sess, query, &result)
 	})
 
 	return result, err
 }
 
+// getGrantedPermissions appends the permissions granted to the user that haven't expired yet.
+func (s *AccessControlStore) getGrantedPermissions(sess *sqlstore.DBSession, query accesscontrol.GetUserPermissionsQuery, result *[]accesscontrol.Permission) error {
+	q := `SELECT action, scope FROM access_grant
+		WHERE org_id = ? AND user_id = ? AND action <> '' AND expires > ?`
+	params := []interface{}{query.OrgID, query.UserID, time.Now()}
+
+	if query.Actions != nil {
+		if len(query.Actions) == 0 {
+			return nil
+		}
+		q += " AND action IN(?" + strings.Repeat(",?", len(query.Actions)-1) + ")"
+		for _, a := range query.Actions {
+			params = append(params, a)
+		}
+	}
+
+	granted := make([]accesscontrol.Permission, 0)
+	if err := sess.SQL(q, params...).Find(&granted); err != nil {
+		return err
+	}
+	*result = append(*result, granted...)
+	return nil
+}
+
 func userRolesFilter(orgID, userID int64, roles []string) (string, []interface{}) {
 	q := `
 	WHERE role.id IN (
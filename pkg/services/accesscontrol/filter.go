@@ -9,15 +9,16 @@ import (
 )
 
 var sqlIDAcceptList = map[string]struct{}{
-	"id":               {},
-	"org_user.user_id": {},
-	"role.uid":         {},
-	"t.id":             {},
-	"team.id":          {},
-	"u.id":             {},
-	"\"user\".\"id\"":  {}, // For Postgres
-	"`user`.`id`":      {}, // For MySQL and SQLite
-	"dashboard.uid":    {},
+	"id":                 {},
+	"org_user.user_id":   {},
+	"role.uid":           {},
+	"t.id":               {},
+	"team.id":            {},
+	"u.id":               {},
+	"\"user\".\"id\"":    {}, // For Postgres
+	"`user`.`id`":        {}, // For MySQL and SQLite
+	"dashboard.uid":      {},
+	"dashboard_tag.term": {},
 }
 
 var (
@@ -115,7 +116,8 @@ func ParseScopes(prefix string, scopes []string) (ids map[interface{}]struct{},
 				continue
 			}
 
-			if id, err := parser(scope); err == nil {
+			// the attribute can contain colons, e.g. the tag of "dashboards:tag:team:payments"
+			if id, err := parser(scope[len(prefix):]); err == nil {
 				ids[id] = struct{}{}
 			}
 		}
@@ -123,12 +125,12 @@ func ParseScopes(prefix string, scopes []string) (ids map[interface{}]struct{},
 	return ids, false
 }
 
-func parseIntAttribute(scope string) (interface{}, error) {
-	return strconv.ParseInt(scope[strings.LastIndex(scope, ":")+1:], 10, 64)
+func parseIntAttribute(attribute string) (interface{}, error) {
+	return strconv.ParseInt(attribute, 10, 64)
 }
 
-func parseStringAttribute(scope string) (interface{}, error) {
-	return scope[strings.LastIndex(scope, ":")+1:], nil
+func parseStringAttribute(attribute string) (interface{}, error) {
+	return attribute, nil
 }
 
 // SetAcceptListForTest allow us to mutate the list for blackbox testing
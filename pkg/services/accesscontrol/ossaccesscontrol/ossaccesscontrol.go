@@ -2,6 +2,8 @@ package ossaccesscontrol
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -9,6 +11,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/api"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,9 +21,16 @@ import (
 func ProvideService(
 	features featuremgmt.FeatureToggles, cfg *setting.Cfg,
 	store accesscontrol.PermissionsStore, routeRegister routing.RouteRegister,
+	anonymousAccess anonymousaccess.Service,
 ) (*OSSAccessControlService, error) {
 	var errDeclareRoles error
 	s := ProvideOSSAccessControl(cfg, store)
+	s.anonymousAccess = anonymousAccess
+	if s.IsDisabled() && anonymousAccess.IsEnabled() {
+		// the allowlist is enforced through the permissions of anonymous users,
+		// they would be able to view everything their role allows without RBAC
+		return nil, errors.New("restricting anonymous access to an allowlist requires RBAC to be enabled")
+	}
 	if !s.IsDisabled() {
 		api := api.AccessControlAPI{
 			RouteRegister: routeRegister,
@@ -53,6 +64,9 @@ type OSSAccessControlService struct {
 	store          accesscontrol.PermissionsStore
 	registrations  accesscontrol.RegistrationList
 	roles          map[string]*accesscontrol.RoleDTO
+	// anonymousAccess restricts the folders and dashboards anonymous users can
+	// view when set.
+	anonymousAccess anonymousaccess.Service
 }
 
 func (ac *OSSAccessControlService) IsDisabled() bool {
@@ -120,6 +134,13 @@ func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user
 	}
 
 	permissions = append(permissions, dbPermissions...)
+	if user.IsAnonymous && ac.anonymousAccess != nil && ac.anonymousAccess.IsEnabled() {
+		permissions, err = ac.restrictAnonymousPermissions(ctx, user, permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	keywordMutator := ac.scopeResolvers.GetScopeKeywordMutator(user)
 	for i := range permissions {
 		// if the permission has a keyword in its scope it will be resolved
@@ -132,6 +153,39 @@ func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user
 	return permissions, nil
 }
 
+// restrictAnonymousPermissions replaces the folder and dashboard permissions of
+// an anonymous user by read permissions on the folders and dashboards of the
+// allowlist of its organization. Search, the dashboard guardian and the
+// annotation filters all rely on these permissions.
+func (ac *OSSAccessControlService) restrictAnonymousPermissions(ctx context.Context, user *models.SignedInUser, permissions []accesscontrol.Permission) ([]accesscontrol.Permission, error) {
+	allowlist, err := ac.anonymousAccess.GetAllowlist(ctx, &anonymousaccess.GetAllowlistQuery{OrgID: user.OrgId})
+	if err != nil {
+		return nil, err
+	}
+
+	restricted := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		if strings.HasPrefix(p.Action, "dashboards") || strings.HasPrefix(p.Action, "folders") {
+			continue
+		}
+		restricted = append(restricted, p)
+	}
+	for _, uid := range allowlist.Folders {
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(uid)
+		restricted = append(restricted,
+			accesscontrol.Permission{Action: dashboards.ActionFoldersRead, Scope: scope},
+			accesscontrol.Permission{Action: dashboards.ActionDashboardsRead, Scope: scope},
+		)
+	}
+	for _, uid := range allowlist.Dashboards {
+		restricted = append(restricted, accesscontrol.Permission{
+			Action: dashboards.ActionDashboardsRead,
+			Scope:  dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid),
+		})
+	}
+	return restricted, nil
+}
+
 func (ac *OSSAccessControlService) getFixedPermissions(ctx context.Context, user *models.SignedInUser) []accesscontrol.Permission {
 	permissions := make([]accesscontrol.Permission, 0)
 
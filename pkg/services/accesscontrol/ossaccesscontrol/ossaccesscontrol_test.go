@@ -12,6 +12,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess/anonymousaccesstest"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -145,6 +147,7 @@ func TestUsageMetrics(t *testing.T) {
 				cfg,
 				database.ProvideService(sqlstore.InitTestDB(t)),
 				routing.NewRouteRegister(),
+				anonymousaccesstest.NewAnonymousAccessServiceFake(),
 			)
 			require.NoError(t, errInitAc)
 			assert.Equal(t, tt.expectedValue, s.GetUsageStats(context.Background())["stats.oss.accesscontrol.enabled.count"])
@@ -504,3 +507,70 @@ func TestOSSAccessControlService_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestOSSAccessControlService_GetUserPermissions_AnonymousAllowlist(t *testing.T) {
+	anonymousAccess := anonymousaccesstest.NewAnonymousAccessServiceFake()
+	anonymousAccess.ExpectedAllowlist = &anonymousaccess.Allowlist{
+		Folders:    []string{"public-folder"},
+		Dashboards: []string{"public-dash"},
+	}
+
+	getPermissions := func(t *testing.T, user *models.SignedInUser) []accesscontrol.Permission {
+		ac := setupTestEnv(t)
+		ac.anonymousAccess = anonymousAccess
+		require.NoError(t, ac.DeclareFixedRoles(accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Version: 1,
+				UID:     "fixed:test:viewer",
+				Name:    "fixed:test:viewer",
+				Permissions: []accesscontrol.Permission{
+					{Action: "dashboards:read", Scope: "dashboards:*"},
+					{Action: "folders:read", Scope: "folders:*"},
+					{Action: "annotations:read", Scope: "annotations:type:*"},
+				},
+			},
+			Grants: []string{"Viewer"},
+		}))
+		require.NoError(t, ac.RegisterFixedRoles(context.Background()))
+		permissions, err := ac.GetUserPermissions(context.Background(), user, accesscontrol.Options{})
+		require.NoError(t, err)
+		return extractRawPermissionsHelper(permissions)
+	}
+
+	t.Run("anonymous users can only view the allowlist", func(t *testing.T) {
+		anonymousAccess.ExpectedEnabled = true
+		permissions := getPermissions(t, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER, IsAnonymous: true})
+
+		assert.NotContains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:*"})
+		assert.NotContains(t, permissions, accesscontrol.Permission{Action: "folders:read", Scope: "folders:*"})
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "folders:read", Scope: "folders:uid:public-folder"})
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "folders:uid:public-folder"})
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:uid:public-dash"})
+		// permissions on other resources are kept
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "annotations:read", Scope: "annotations:type:*"})
+	})
+
+	t.Run("signed in users are not restricted", func(t *testing.T) {
+		anonymousAccess.ExpectedEnabled = true
+		permissions := getPermissions(t, &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:*"})
+		assert.NotContains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:uid:public-dash"})
+	})
+
+	t.Run("anonymous users are not restricted when the allowlist is disabled", func(t *testing.T) {
+		anonymousAccess.ExpectedEnabled = false
+		permissions := getPermissions(t, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER, IsAnonymous: true})
+
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "dashboards:read", Scope: "dashboards:*"})
+	})
+}
+
+func TestProvideService_AnonymousAllowlistRequiresRBAC(t *testing.T) {
+	anonymousAccess := anonymousaccesstest.NewAnonymousAccessServiceFake()
+	anonymousAccess.ExpectedEnabled = true
+
+	_, err := ProvideService(featuremgmt.WithFeatures(), setting.NewCfg(), database.ProvideService(sqlstore.InitTestDB(t)),
+		routing.NewRouteRegister(), anonymousAccess)
+	require.Error(t, err)
+}
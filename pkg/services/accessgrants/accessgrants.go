@@ -0,0 +1,18 @@
+package accessgrants
+
+import (
+	"context"
+)
+
+// Service manages time-limited grants of a basic role or of a permission to a
+// user, e.g. to give an on-call engineer admin access during an incident. Grants
+// stop applying when they expire and every change is written to the audit log.
+type Service interface {
+	Create(ctx context.Context, cmd *CreateGrantCommand) (*Grant, error)
+	// GetActive returns the grants that haven't expired yet, soonest to expire first.
+	GetActive(ctx context.Context, query *GetActiveGrantsQuery) ([]*Grant, error)
+	Revoke(ctx context.Context, cmd *RevokeGrantCommand) error
+
+	// DeleteExpired removes the expired grants.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
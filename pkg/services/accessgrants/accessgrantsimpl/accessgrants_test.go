@@ -0,0 +1,127 @@
+package accessgrantsimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationAccessGrants(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	_, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "admin", OrgId: 1})
+	require.NoError(t, err)
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "oncall", OrgId: 1, SkipOrgSetup: true})
+	require.NoError(t, err)
+	require.NoError(t, ss.AddOrgUser(ctx, &models.AddOrgUserCommand{Role: models.ROLE_VIEWER, OrgId: 1, UserId: user.Id}))
+
+	now := time.Now()
+	s := &Service{store: &sqlStore{db: ss}, audit: log.New("test"), now: func() time.Time { return now }}
+
+	orgRole := func(t *testing.T) models.RoleType {
+		t.Helper()
+		query := &models.GetSignedInUserQuery{OrgId: 1, UserId: user.Id}
+		require.NoError(t, ss.GetSignedInUser(ctx, query))
+		return query.Result.OrgRole
+	}
+
+	t.Run("Validates the grants", func(t *testing.T) {
+		for _, tc := range []struct {
+			cmd accessgrants.CreateGrantCommand
+			err error
+		}{
+			{cmd: accessgrants.CreateGrantCommand{Duration: "1h", Reason: "incident"}, err: accessgrants.ErrInvalidGrant},
+			{cmd: accessgrants.CreateGrantCommand{Role: models.ROLE_ADMIN, Action: "users:read", Duration: "1h", Reason: "incident"}, err: accessgrants.ErrInvalidGrant},
+			{cmd: accessgrants.CreateGrantCommand{Role: "Owner", Duration: "1h", Reason: "incident"}, err: accessgrants.ErrInvalidRole},
+			{cmd: accessgrants.CreateGrantCommand{Role: models.ROLE_ADMIN, Duration: "8d", Reason: "incident"}, err: accessgrants.ErrInvalidDuration},
+			{cmd: accessgrants.CreateGrantCommand{Role: models.ROLE_ADMIN, Duration: "soon", Reason: "incident"}, err: accessgrants.ErrInvalidDuration},
+			{cmd: accessgrants.CreateGrantCommand{Role: models.ROLE_ADMIN, Duration: "1h"}, err: accessgrants.ErrReasonRequired},
+		} {
+			cmd := tc.cmd
+			cmd.OrgID, cmd.UserID = 1, user.Id
+			_, err := s.Create(ctx, &cmd)
+			require.ErrorIs(t, err, tc.err)
+		}
+
+		_, err := s.Create(ctx, &accessgrants.CreateGrantCommand{OrgID: 2, UserID: user.Id, Role: models.ROLE_ADMIN, Duration: "1h", Reason: "incident"})
+		require.ErrorIs(t, err, accessgrants.ErrUserNotInOrg)
+	})
+
+	t.Run("Granted roles raise the role of the user until they expire", func(t *testing.T) {
+		require.Equal(t, models.ROLE_VIEWER, orgRole(t))
+
+		grant, err := s.Create(ctx, &accessgrants.CreateGrantCommand{OrgID: 1, UserID: user.Id, Role: models.ROLE_ADMIN, Duration: "1h", Reason: "incident", GrantedBy: 1})
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), grant.Expires)
+		require.Equal(t, models.ROLE_ADMIN, orgRole(t))
+
+		active, err := s.GetActive(ctx, &accessgrants.GetActiveGrantsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		assert.Equal(t, grant.ID, active[0].ID)
+
+		require.NoError(t, s.Revoke(ctx, &accessgrants.RevokeGrantCommand{ID: grant.ID, RevokedBy: 1}))
+		require.Equal(t, models.ROLE_VIEWER, orgRole(t))
+		require.ErrorIs(t, s.Revoke(ctx, &accessgrants.RevokeGrantCommand{ID: grant.ID}), accessgrants.ErrGrantNotFound)
+	})
+
+	t.Run("Granted roles don't lower the role of the user", func(t *testing.T) {
+		require.NoError(t, ss.UpdateOrgUser(ctx, &models.UpdateOrgUserCommand{Role: models.ROLE_EDITOR, OrgId: 1, UserId: user.Id}))
+		t.Cleanup(func() {
+			require.NoError(t, ss.UpdateOrgUser(ctx, &models.UpdateOrgUserCommand{Role: models.ROLE_VIEWER, OrgId: 1, UserId: user.Id}))
+		})
+
+		grant, err := s.Create(ctx, &accessgrants.CreateGrantCommand{OrgID: 1, UserID: user.Id, Role: models.ROLE_VIEWER, Duration: "1h", Reason: "incident"})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = s.Revoke(ctx, &accessgrants.RevokeGrantCommand{ID: grant.ID}) })
+		require.Equal(t, models.ROLE_EDITOR, orgRole(t))
+	})
+
+	t.Run("Granted permissions are added to the permissions of the user", func(t *testing.T) {
+		acStore := database.ProvideService(ss)
+		query := accesscontrol.GetUserPermissionsQuery{OrgID: 1, UserID: user.Id}
+
+		grant, err := s.Create(ctx, &accessgrants.CreateGrantCommand{OrgID: 1, UserID: user.Id, Action: "datasources:write", Scope: "datasources:uid:prom", Duration: "30m", Reason: "fix the data source"})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = s.Revoke(ctx, &accessgrants.RevokeGrantCommand{ID: grant.ID}) })
+
+		permissions, err := acStore.GetUserPermissions(ctx, query)
+		require.NoError(t, err)
+		assert.Contains(t, permissions, accesscontrol.Permission{Action: "datasources:write", Scope: "datasources:uid:prom"})
+
+		query.Actions = []string{"teams:read"}
+		permissions, err = acStore.GetUserPermissions(ctx, query)
+		require.NoError(t, err)
+		assert.NotContains(t, permissions, accesscontrol.Permission{Action: "datasources:write", Scope: "datasources:uid:prom"})
+	})
+
+	t.Run("Expired grants don't apply and are deleted", func(t *testing.T) {
+		past := now.Add(-2 * time.Hour)
+		expiring := &Service{store: s.store, audit: s.audit, now: func() time.Time { return past }}
+		_, err := expiring.Create(ctx, &accessgrants.CreateGrantCommand{OrgID: 1, UserID: user.Id, Role: models.ROLE_ADMIN, Duration: "1h", Reason: "incident"})
+		require.NoError(t, err)
+		require.Equal(t, models.ROLE_VIEWER, orgRole(t))
+
+		active, err := s.GetActive(ctx, &accessgrants.GetActiveGrantsQuery{UserID: user.Id, OrgID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, active)
+
+		deleted, err := s.DeleteExpired(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
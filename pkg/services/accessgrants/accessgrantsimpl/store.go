@@ -0,0 +1,95 @@
+package accessgrantsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, grant *accessgrants.Grant) error
+	Get(ctx context.Context, id int64) (*accessgrants.Grant, error)
+	GetActive(ctx context.Context, query *accessgrants.GetActiveGrantsQuery, now time.Time) ([]*accessgrants.Grant, error)
+	Delete(ctx context.Context, id int64) error
+	DeleteExpired(ctx context.Context, now time.Time) ([]*accessgrants.Grant, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, grant *accessgrants.Grant) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		member, err := sess.Where("org_id=? AND user_id=?", grant.OrgID, grant.UserID).Exist(&models.OrgUser{})
+		if err != nil {
+			return err
+		}
+		if !member {
+			return accessgrants.ErrUserNotInOrg
+		}
+		_, err = sess.Insert(grant)
+		return err
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, id int64) (*accessgrants.Grant, error) {
+	grant := &accessgrants.Grant{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.ID(id).Get(grant)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return accessgrants.ErrGrantNotFound
+		}
+		return nil
+	})
+	return grant, err
+}
+
+func (s *sqlStore) GetActive(ctx context.Context, query *accessgrants.GetActiveGrantsQuery, now time.Time) ([]*accessgrants.Grant, error) {
+	grants := make([]*accessgrants.Grant, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("expires > ?", now)
+		if query.OrgID != 0 {
+			q = q.And("org_id=?", query.OrgID)
+		}
+		if query.UserID != 0 {
+			q = q.And("user_id=?", query.UserID)
+		}
+		return q.Asc("expires").Find(&grants)
+	})
+	return grants, err
+}
+
+func (s *sqlStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.ID(id).Delete(&accessgrants.Grant{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return accessgrants.ErrGrantNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) DeleteExpired(ctx context.Context, now time.Time) ([]*accessgrants.Grant, error) {
+	expired := make([]*accessgrants.Grant, 0)
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := sess.Where("expires <= ?", now).Find(&expired); err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		_, err := sess.Where("expires <= ?", now).Delete(&accessgrants.Grant{})
+		return err
+	})
+	return expired, err
+}
@@ -0,0 +1,33 @@
+package accessgrantstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+)
+
+type FakeAccessGrantsService struct {
+	ExpectedGrant  *accessgrants.Grant
+	ExpectedGrants []*accessgrants.Grant
+	ExpectedError  error
+}
+
+func NewAccessGrantsServiceFake() *FakeAccessGrantsService {
+	return &FakeAccessGrantsService{}
+}
+
+func (f *FakeAccessGrantsService) Create(ctx context.Context, cmd *accessgrants.CreateGrantCommand) (*accessgrants.Grant, error) {
+	return f.ExpectedGrant, f.ExpectedError
+}
+
+func (f *FakeAccessGrantsService) GetActive(ctx context.Context, query *accessgrants.GetActiveGrantsQuery) ([]*accessgrants.Grant, error) {
+	return f.ExpectedGrants, f.ExpectedError
+}
+
+func (f *FakeAccessGrantsService) Revoke(ctx context.Context, cmd *accessgrants.RevokeGrantCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeAccessGrantsService) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, f.ExpectedError
+}
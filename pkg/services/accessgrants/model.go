@@ -0,0 +1,78 @@
+package accessgrants
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// MaxDuration is the longest a grant can last.
+const MaxDuration = 7 * 24 * time.Hour
+
+var (
+	ErrGrantNotFound   = errors.New("access grant not found")
+	ErrUserNotInOrg    = errors.New("user isn't a member of the organization")
+	ErrInvalidGrant    = errors.New("a grant needs either a basic role or a permission action")
+	ErrInvalidRole     = errors.New("role must be Viewer, Editor or Admin")
+	ErrInvalidDuration = errors.New("duration must be positive and at most 7 days")
+	ErrReasonRequired  = errors.New("reason is required")
+)
+
+// Grant gives a user either a basic role or a permission in an organization
+// until it expires.
+type Grant struct {
+	ID     int64 `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID  int64 `xorm:"org_id" json:"orgId"`
+	UserID int64 `xorm:"user_id" json:"userId"`
+	// Role is the basic role granted, the user gets the higher of it and of their role in the organization.
+	Role models.RoleType `xorm:"role" json:"role,omitempty"`
+	// Action and Scope are the permission granted, they only apply when role based access control is enabled.
+	Action    string    `xorm:"action" json:"action,omitempty"`
+	Scope     string    `xorm:"scope" json:"scope,omitempty"`
+	Reason    string    `xorm:"reason" json:"reason"`
+	GrantedBy int64     `xorm:"granted_by" json:"grantedBy"`
+	Created   time.Time `xorm:"created" json:"created"`
+	Expires   time.Time `xorm:"expires" json:"expires"`
+}
+
+func (g Grant) TableName() string {
+	return "access_grant"
+}
+
+// ----------------------
+// COMMANDS
+
+// CreateGrantCommand is the command for granting a user a role or a permission for a limited time
+// swagger:model
+type CreateGrantCommand struct {
+	// OrgID is the organization of the grant. Defaults to the current organization.
+	OrgID  int64 `json:"orgId"`
+	UserID int64 `json:"userId"`
+	// Role is the basic role to grant, Viewer, Editor or Admin. Either role or action must be set.
+	Role models.RoleType `json:"role"`
+	// Action and Scope are the permission to grant.
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// Duration of the grant using Grafana time units, at most 7 days.
+	// example: 2h
+	Duration string `json:"duration"`
+	// Reason is the justification of the grant, written to the audit log.
+	Reason string `json:"reason"`
+
+	GrantedBy int64 `json:"-"`
+}
+
+type RevokeGrantCommand struct {
+	ID        int64
+	RevokedBy int64
+}
+
+// ---------------------
+// QUERIES
+
+type GetActiveGrantsQuery struct {
+	// OrgID and UserID filter the grants when set.
+	OrgID  int64
+	UserID int64
+}
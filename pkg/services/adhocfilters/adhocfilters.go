@@ -0,0 +1,14 @@
+package adhocfilters
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service suggests keys and values for ad hoc filters by asking the data source
+// for its labels, so that the frontend does not have to issue raw metadata queries.
+type Service interface {
+	GetKeys(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query KeysQuery) ([]Suggestion, error)
+	GetValues(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query ValuesQuery) ([]Suggestion, error)
+}
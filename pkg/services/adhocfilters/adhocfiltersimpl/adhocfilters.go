@@ -0,0 +1,175 @@
+package adhocfiltersimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/adhocfilters"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pluginContextProvider *plugincontext.Provider,
+	oauthTokenService oauthtoken.OAuthTokenService, cacheService *localcache.CacheService,
+	queryPolicy querypolicy.Service) adhocfilters.Service {
+	return &Service{
+		cfg:                   cfg,
+		pluginClient:          pluginClient,
+		pluginContextProvider: pluginContextProvider,
+		oauthTokenService:     oauthTokenService,
+		cache:                 cacheService,
+		queryPolicy:           queryPolicy,
+		log:                   log.New("adhocfilters"),
+	}
+}
+
+type Service struct {
+	cfg                   *setting.Cfg
+	pluginClient          plugins.Client
+	pluginContextProvider *plugincontext.Provider
+	oauthTokenService     oauthtoken.OAuthTokenService
+	cache                 *localcache.CacheService
+	queryPolicy           querypolicy.Service
+	log                   log.Logger
+}
+
+func (s *Service) GetKeys(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query adhocfilters.KeysQuery) ([]adhocfilters.Suggestion, error) {
+	source, ok := sources[ds.Type]
+	if !ok {
+		return nil, adhocfilters.ErrUnsupportedDataSource
+	}
+
+	timeRange := newTimeRange(query.From, query.To)
+	keys, err := s.fetch(ctx, user, ds, source.keysURL(timeRange), query.SkipCache)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictions := adhocfilters.RestrictionsFromJSONData(ds.JsonData)
+	allowed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		// Internal labels such as __name__ cannot be used as ad hoc filters
+		if strings.HasPrefix(key, "__") || !restrictions.Allowed(key) {
+			continue
+		}
+		allowed = append(allowed, key)
+	}
+	return toSuggestions(allowed), nil
+}
+
+func (s *Service) GetValues(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query adhocfilters.ValuesQuery) ([]adhocfilters.Suggestion, error) {
+	source, ok := sources[ds.Type]
+	if !ok {
+		return nil, adhocfilters.ErrUnsupportedDataSource
+	}
+	if query.Key == "" {
+		return nil, adhocfilters.ErrKeyRequired
+	}
+	if !adhocfilters.RestrictionsFromJSONData(ds.JsonData).Allowed(query.Key) {
+		return nil, adhocfilters.ErrKeyNotAllowed
+	}
+
+	timeRange := newTimeRange(query.From, query.To)
+	values, err := s.fetch(ctx, user, ds, source.valuesURL(query.Key, timeRange), query.SkipCache)
+	if err != nil {
+		return nil, err
+	}
+	return toSuggestions(values), nil
+}
+
+// fetch calls the resource URL of the data source plugin and returns the labels in the
+// response. Responses are cached per data source version, so that changing the data
+// source settings invalidates them, and per user if the user's OAuth token is forwarded.
+// The query policy of the organization applies to cached responses too.
+func (s *Service) fetch(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, resourceURL string, skipCache bool) ([]string, error) {
+	if err := s.queryPolicy.ValidateDataSourceCall(ctx, user); err != nil {
+		return nil, err
+	}
+	oauthPassThru := s.oauthTokenService.IsOAuthPassThruEnabled(ds)
+
+	cacheKey := fmt.Sprintf("adhoc-filters-%d-%s-%d-%s", ds.OrgId, ds.Uid, ds.Version, resourceURL)
+	if oauthPassThru {
+		cacheKey += fmt.Sprintf("-%d", user.UserId)
+	}
+	if cached, ok := s.cache.Get(cacheKey); ok && !skipCache {
+		return cached.([]string), nil
+	}
+
+	pCtx, found, err := s.pluginContextProvider.GetWithDataSource(ctx, ds.Type, user, ds)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, adhocfilters.ErrUnsupportedDataSource
+	}
+
+	headers := map[string][]string{}
+	if oauthPassThru {
+		if token := s.oauthTokenService.GetCurrentOAuthToken(ctx, user); token != nil {
+			headers["Authorization"] = []string{fmt.Sprintf("%s %s", token.Type(), token.AccessToken)}
+		}
+	}
+
+	sender := &adapters.ResourceResponse{}
+	err = s.pluginClient.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          strings.SplitN(resourceURL, "?", 2)[0],
+		Method:        http.MethodGet,
+		URL:           resourceURL,
+		Headers:       headers,
+	}, sender)
+	if err != nil {
+		return nil, err
+	}
+	if sender.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("data source responded with status %d: %s", sender.Status, sender.Body)
+	}
+
+	var resp labelsResponse
+	if err := json.Unmarshal(sender.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse labels response: %w", err)
+	}
+	sort.Strings(resp.Data)
+
+	if s.cfg.AdHocFiltersCacheTTL > 0 {
+		s.cache.Set(cacheKey, resp.Data, s.cfg.AdHocFiltersCacheTTL)
+	}
+	return resp.Data, nil
+}
+
+// labelsResponse is the response of the label APIs of Prometheus and Loki.
+type labelsResponse struct {
+	Data []string `json:"data"`
+}
+
+func newTimeRange(from, to string) legacydata.DataTimeRange {
+	if from == "" {
+		from = "now-1h"
+	}
+	if to == "" {
+		to = "now"
+	}
+	return legacydata.NewDataTimeRange(from, to)
+}
+
+func toSuggestions(labels []string) []adhocfilters.Suggestion {
+	suggestions := make([]adhocfilters.Suggestion, 0, len(labels))
+	for _, l := range labels {
+		suggestions = append(suggestions, adhocfilters.Suggestion{Text: l})
+	}
+	return suggestions
+}
@@ -0,0 +1,119 @@
+package adhocfiltersimpl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adhocfilters"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/querypolicy/querypolicytest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	testFrom = "1650000000000"
+	testTo   = "1650003600000"
+)
+
+func TestGetKeys(t *testing.T) {
+	svc := newTestService()
+	user := &models.SignedInUser{OrgId: 1, UserId: 2}
+	ds := &models.DataSource{OrgId: 1, Uid: "prom", Type: "prometheus", Version: 1, JsonData: simplejson.New()}
+
+	svc.cache.Set(cacheKeyFor(ds, sources["prometheus"].keysURL(newTimeRange(testFrom, testTo))), []string{"__name__", "env", "instance", "job"}, time.Minute)
+
+	t.Run("internal labels are not suggested", func(t *testing.T) {
+		keys, err := svc.GetKeys(context.Background(), user, ds, adhocfilters.KeysQuery{From: testFrom, To: testTo})
+		require.NoError(t, err)
+		require.Equal(t, []adhocfilters.Suggestion{{Text: "env"}, {Text: "instance"}, {Text: "job"}}, keys)
+	})
+
+	t.Run("restricted keys are not suggested", func(t *testing.T) {
+		ds.JsonData = simplejson.NewFromAny(map[string]interface{}{
+			"adHocFilters": map[string]interface{}{
+				"allowedKeys": []interface{}{"env", "job"},
+				"deniedKeys":  []interface{}{"job"},
+			},
+		})
+		keys, err := svc.GetKeys(context.Background(), user, ds, adhocfilters.KeysQuery{From: testFrom, To: testTo})
+		require.NoError(t, err)
+		require.Equal(t, []adhocfilters.Suggestion{{Text: "env"}}, keys)
+	})
+
+	t.Run("unsupported data source", func(t *testing.T) {
+		_, err := svc.GetKeys(context.Background(), user, &models.DataSource{Type: "graphite"}, adhocfilters.KeysQuery{})
+		require.ErrorIs(t, err, adhocfilters.ErrUnsupportedDataSource)
+	})
+
+	t.Run("cached keys are not returned when the query policy denies data source calls", func(t *testing.T) {
+		svc.queryPolicy = &querypolicytest.FakeQueryPolicyService{ExpectedDataSourceCallError: querypolicy.ErrDataSourceCallNotAllowed}
+		t.Cleanup(func() { svc.queryPolicy = querypolicytest.NewQueryPolicyServiceFake() })
+
+		_, err := svc.GetKeys(context.Background(), user, ds, adhocfilters.KeysQuery{From: testFrom, To: testTo})
+		require.ErrorIs(t, err, querypolicy.ErrDataSourceCallNotAllowed)
+	})
+}
+
+func TestGetValues(t *testing.T) {
+	svc := newTestService()
+	user := &models.SignedInUser{OrgId: 1, UserId: 2}
+	ds := &models.DataSource{
+		OrgId: 1, Uid: "loki", Type: "loki", Version: 3,
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"adHocFilters": map[string]interface{}{"deniedKeys": []interface{}{"tenant"}},
+		}),
+	}
+
+	svc.cache.Set(cacheKeyFor(ds, sources["loki"].valuesURL("env", newTimeRange(testFrom, testTo))), []string{"dev", "prod"}, time.Minute)
+
+	values, err := svc.GetValues(context.Background(), user, ds, adhocfilters.ValuesQuery{Key: "env", From: testFrom, To: testTo})
+	require.NoError(t, err)
+	require.Equal(t, []adhocfilters.Suggestion{{Text: "dev"}, {Text: "prod"}}, values)
+
+	_, err = svc.GetValues(context.Background(), user, ds, adhocfilters.ValuesQuery{Key: "tenant"})
+	require.ErrorIs(t, err, adhocfilters.ErrKeyNotAllowed)
+
+	_, err = svc.GetValues(context.Background(), user, ds, adhocfilters.ValuesQuery{})
+	require.ErrorIs(t, err, adhocfilters.ErrKeyRequired)
+}
+
+func TestSourceURLs(t *testing.T) {
+	tr := newTimeRange(testFrom, testTo)
+
+	require.Equal(t, "api/v1/labels?end=1650003660&start=1650000000", sources["prometheus"].keysURL(tr))
+	require.Equal(t, "api/v1/label/env/values?end=1650003660&start=1650000000", sources["prometheus"].valuesURL("env", tr))
+	require.Equal(t, "label/env/values?end=1650003660000000000&start=1650000000000000000", sources["loki"].valuesURL("env", tr))
+}
+
+func newTestService() *Service {
+	return &Service{
+		cfg:               setting.NewCfg(),
+		oauthTokenService: &fakeOAuthTokenService{},
+		queryPolicy:       querypolicytest.NewQueryPolicyServiceFake(),
+		cache:             localcache.New(time.Minute, time.Minute),
+		log:               log.New("test"),
+	}
+}
+
+func cacheKeyFor(ds *models.DataSource, resourceURL string) string {
+	return fmt.Sprintf("adhoc-filters-%d-%s-%d-%s", ds.OrgId, ds.Uid, ds.Version, resourceURL)
+}
+
+type fakeOAuthTokenService struct{}
+
+func (ts *fakeOAuthTokenService) GetCurrentOAuthToken(context.Context, *models.SignedInUser) *oauth2.Token {
+	return nil
+}
+
+func (ts *fakeOAuthTokenService) IsOAuthPassThruEnabled(*models.DataSource) bool {
+	return false
+}
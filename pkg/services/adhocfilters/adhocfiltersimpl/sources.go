@@ -0,0 +1,48 @@
+package adhocfiltersimpl
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// source describes the resource URLs a data source plugin serves its label names and values on.
+type source struct {
+	keysURL   func(timeRange legacydata.DataTimeRange) string
+	valuesURL func(key string, timeRange legacydata.DataTimeRange) string
+}
+
+var sources = map[string]source{
+	"prometheus": {
+		keysURL: func(tr legacydata.DataTimeRange) string {
+			return "api/v1/labels?" + rangeParams(tr, time.Second)
+		},
+		valuesURL: func(key string, tr legacydata.DataTimeRange) string {
+			return fmt.Sprintf("api/v1/label/%s/values?%s", url.PathEscape(key), rangeParams(tr, time.Second))
+		},
+	},
+	"loki": {
+		keysURL: func(tr legacydata.DataTimeRange) string {
+			return "labels?" + rangeParams(tr, time.Nanosecond)
+		},
+		valuesURL: func(key string, tr legacydata.DataTimeRange) string {
+			return fmt.Sprintf("label/%s/values?%s", url.PathEscape(key), rangeParams(tr, time.Nanosecond))
+		},
+	},
+}
+
+// rangeParams encodes the time range in the unit the data source expects. The range
+// is truncated to the minute so that the cache is shared by requests for relative
+// time ranges such as now-1h.
+func rangeParams(tr legacydata.DataTimeRange, unit time.Duration) string {
+	from := tr.GetFromAsTimeUTC().Truncate(time.Minute)
+	to := tr.GetToAsTimeUTC().Truncate(time.Minute).Add(time.Minute)
+
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(from.UnixNano()/int64(unit), 10))
+	params.Set("end", strconv.FormatInt(to.UnixNano()/int64(unit), 10))
+	return params.Encode()
+}
@@ -0,0 +1,70 @@
+package adhocfilters
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrUnsupportedDataSource = errors.New("data source does not support ad hoc filter suggestions")
+	ErrKeyRequired           = errors.New("ad hoc filter key is required")
+	ErrKeyNotAllowed         = errors.New("ad hoc filter key is not allowed")
+)
+
+// KeysQuery limits the suggested keys to the labels seen in the time range.
+type KeysQuery struct {
+	From      string
+	To        string
+	SkipCache bool
+}
+
+// ValuesQuery limits the suggested values of the key to the time range.
+type ValuesQuery struct {
+	Key       string
+	From      string
+	To        string
+	SkipCache bool
+}
+
+type Suggestion struct {
+	Text string `json:"text"`
+}
+
+// Restrictions limit which keys are suggested and can be filtered on. They are
+// configured in the adHocFilters object of the data source JSON data.
+type Restrictions struct {
+	// AllowedKeys, when not empty, are the only keys that are suggested.
+	AllowedKeys []string
+	// DeniedKeys are never suggested.
+	DeniedKeys []string
+}
+
+func RestrictionsFromJSONData(jsonData *simplejson.Json) Restrictions {
+	if jsonData == nil {
+		return Restrictions{}
+	}
+	settings := jsonData.Get("adHocFilters")
+	return Restrictions{
+		AllowedKeys: settings.Get("allowedKeys").MustStringArray(),
+		DeniedKeys:  settings.Get("deniedKeys").MustStringArray(),
+	}
+}
+
+// Allowed returns true if suggestions for the key can be returned.
+func (r Restrictions) Allowed(key string) bool {
+	for _, denied := range r.DeniedKeys {
+		if denied == key {
+			return false
+		}
+	}
+	if len(r.AllowedKeys) == 0 {
+		return true
+	}
+	for _, allowed := range r.AllowedKeys {
+		if allowed == key {
+			return true
+		}
+	}
+	return false
+}
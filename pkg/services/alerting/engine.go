@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting/metrics"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/maintenance"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
@@ -58,6 +59,7 @@ type AlertEngine struct {
 	sqlStore           AlertStore
 	dashAlertExtractor DashAlertExtractor
 	dashboardService   dashboards.DashboardService
+	maintenance        maintenance.Service
 }
 
 // IsDisabled returns true if the alerting service is disabled for this instance.
@@ -69,7 +71,7 @@ func (e *AlertEngine) IsDisabled() bool {
 func ProvideAlertEngine(renderer rendering.Service, requestValidator models.PluginRequestValidator,
 	dataService legacydata.RequestHandler, usageStatsService usagestats.Service, encryptionService encryption.Internal,
 	notificationService *notifications.NotificationService, tracer tracing.Tracer, sqlStore AlertStore, cfg *setting.Cfg,
-	dashAlertExtractor DashAlertExtractor, dashboardService dashboards.DashboardService) *AlertEngine {
+	dashAlertExtractor DashAlertExtractor, dashboardService dashboards.DashboardService, maintenanceService maintenance.Service) *AlertEngine {
 	e := &AlertEngine{
 		Cfg:                cfg,
 		RenderService:      renderer,
@@ -80,6 +82,7 @@ func ProvideAlertEngine(renderer rendering.Service, requestValidator models.Plug
 		sqlStore:           sqlStore,
 		dashAlertExtractor: dashAlertExtractor,
 		dashboardService:   dashboardService,
+		maintenance:        maintenanceService,
 	}
 	e.execQueue = make(chan *Job, 1000)
 	e.scheduler = newScheduler()
@@ -125,7 +128,10 @@ func (e *AlertEngine) alertingTicker(grafanaCtx context.Context) error {
 				e.scheduler.Update(e.ruleReader.fetch(grafanaCtx))
 			}
 
-			e.scheduler.Tick(tick, e.execQueue)
+			// No jobs are queued while alerting is paused for maintenance
+			if e.maintenance == nil || !e.maintenance.AlertingPaused() {
+				e.scheduler.Tick(tick, e.execQueue)
+			}
 			tickIndex++
 		}
 	}
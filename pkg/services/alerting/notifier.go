@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
@@ -186,6 +187,8 @@ func (n *notificationService) sendNotification(evalContext *EvalContext, notifie
 }
 
 func (n *notificationService) sendNotifications(evalContext *EvalContext, notifierStates notifierStateSlice) error {
+	// the notifiers connect to their hosts with the egress policy of the organization
+	evalContext.Ctx = httpclient.WithEgressOrgID(evalContext.Ctx, evalContext.Rule.OrgID)
 	for _, notifierState := range notifierStates {
 		err := n.sendNotification(evalContext, notifierState)
 		if err != nil {
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -350,10 +351,10 @@ func (sn *SlackNotifier) sendRequest(ctx context.Context, data []byte) error {
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
 		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
+		Proxy: httpclient.EgressProxy(http.ProxyFromEnvironment),
+		DialContext: httpclient.EgressDialContext((&net.Dialer{
 			Timeout: 30 * time.Second,
-		}).DialContext,
+		}).DialContext),
 		TLSHandshakeTimeout: 5 * time.Second,
 	}
 	netClient := &http.Client{
@@ -49,6 +49,7 @@ func (s *AlertNotificationService) HandleNotificationTestCommand(ctx context.Con
 
 func createTestEvalContext(cmd *NotificationTestCommand) *EvalContext {
 	testRule := &Rule{
+		OrgID:       cmd.OrgID,
 		DashboardID: 1,
 		PanelID:     1,
 		Name:        "Test notification",
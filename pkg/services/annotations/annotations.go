@@ -11,6 +11,9 @@ import (
 
 var (
 	ErrTimerangeMissing = errors.New("missing timerange")
+	// ErrTimeInFuture is returned when the time of an annotation is further in the
+	// future than the clock skew tolerance.
+	ErrTimeInFuture = errors.New("annotation time is too far in the future, check the clock of the client")
 )
 
 type Repository interface {
@@ -24,6 +27,10 @@ type Repository interface {
 // AnnotationCleaner is responsible for cleaning up old annotations
 type AnnotationCleaner interface {
 	CleanAnnotations(ctx context.Context, cfg *setting.Cfg) (int64, int64, error)
+	// RepairSkewedAnnotations moves the annotations saved further in the future than the
+	// clock skew tolerance back to the time they were created, and returns the number of
+	// annotations repaired.
+	RepairSkewedAnnotations(ctx context.Context, cfg *setting.Cfg) (int64, error)
 }
 
 type ItemQuery struct {
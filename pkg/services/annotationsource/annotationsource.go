@@ -0,0 +1,17 @@
+package annotationsource
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/annotations"
+)
+
+// Service lets backend app plugins provide annotations, such as incidents or
+// deployments, that are queried on demand by the annotations API or copied
+// into the annotations table on a schedule.
+type Service interface {
+	// Sources returns the annotation sources enabled in the organization.
+	Sources(ctx context.Context, orgID int64) ([]*Source, error)
+	// Query returns the annotations of the sources, most recent first.
+	Query(ctx context.Context, query *Query) ([]*annotations.ItemDTO, error)
+}
@@ -0,0 +1,199 @@
+package annotationsourceimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// queryTimeout bounds how long an annotation source can take to respond, so that
+// a slow source does not block the annotations of the other sources.
+const queryTimeout = 10 * time.Second
+
+func ProvideService(cfg *setting.Cfg, db db.DB, pluginStore plugins.Store, pluginClient plugins.Client,
+	pluginContextProvider *plugincontext.Provider, pluginSettings pluginsettings.Service,
+	serverLock *serverlock.ServerLockService) *Service {
+	return &Service{
+		cfg:                   cfg,
+		store:                 &sqlStore{db: db},
+		pluginStore:           pluginStore,
+		pluginClient:          pluginClient,
+		pluginContextProvider: pluginContextProvider,
+		pluginSettings:        pluginSettings,
+		serverLock:            serverLock,
+		log:                   log.New("annotationsource"),
+		now:                   time.Now,
+	}
+}
+
+type Service struct {
+	cfg                   *setting.Cfg
+	store                 store
+	pluginStore           plugins.Store
+	pluginClient          plugins.Client
+	pluginContextProvider *plugincontext.Provider
+	pluginSettings        pluginsettings.Service
+	serverLock            *serverlock.ServerLockService
+	log                   log.Logger
+	now                   func() time.Time
+}
+
+func (s *Service) Sources(ctx context.Context, orgID int64) ([]*annotationsource.Source, error) {
+	settings, err := s.pluginSettings.GetPluginSettings(ctx, &pluginsettings.GetArgs{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	enabled := map[string]bool{}
+	for _, ps := range settings {
+		enabled[ps.PluginID] = ps.Enabled
+	}
+
+	result := make([]*annotationsource.Source, 0)
+	for _, p := range s.pluginStore.Plugins(ctx, plugins.App) {
+		if !isSource(p) {
+			continue
+		}
+		isEnabled, ok := enabled[p.ID]
+		if !ok {
+			isEnabled = p.AutoEnabled
+		}
+		if !isEnabled {
+			continue
+		}
+		result = append(result, &annotationsource.Source{
+			PluginID:     p.ID,
+			Name:         p.Name,
+			Materialized: s.isMaterialized(p.ID),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Query queries the sources concurrently. Sources failing to respond are logged
+// and skipped, so that they don't prevent the other annotations from showing.
+func (s *Service) Query(ctx context.Context, query *annotationsource.Query) ([]*annotations.ItemDTO, error) {
+	sources, err := s.Sources(ctx, query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*annotationsource.Source, len(sources))
+	for _, source := range sources {
+		byID[source.PluginID] = source
+	}
+
+	pluginIDs := make([]string, 0, len(query.PluginIDs))
+	for _, pluginID := range query.PluginIDs {
+		source, ok := byID[pluginID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", annotationsource.ErrSourceNotFound, pluginID)
+		}
+		if !source.Materialized {
+			pluginIDs = append(pluginIDs, pluginID)
+		}
+	}
+
+	results := make([][]*annotations.ItemDTO, len(pluginIDs))
+	done := make(chan struct{}, len(pluginIDs))
+	for i, pluginID := range pluginIDs {
+		go func(i int, pluginID string) {
+			defer func() { done <- struct{}{} }()
+			ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+			defer cancel()
+			found, err := s.fetch(ctx, query.SignedInUser, pluginID, query.From, query.To, query.Limit, query.Tags)
+			if err != nil {
+				s.log.Warn("Failed to query annotation source", "pluginId", pluginID, "error", err)
+				return
+			}
+			items := make([]*annotations.ItemDTO, 0, len(found))
+			for _, a := range found {
+				items = append(items, a.ToItemDTO(pluginID))
+			}
+			results[i] = items
+		}(i, pluginID)
+	}
+	for range pluginIDs {
+		<-done
+	}
+
+	items := make([]*annotations.ItemDTO, 0)
+	for _, r := range results {
+		items = append(items, r...)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Time > items[j].Time })
+	return items, nil
+}
+
+// fetch calls the annotations resource of the plugin.
+func (s *Service) fetch(ctx context.Context, user *models.SignedInUser, pluginID string, from, to, limit int64, tags []string) ([]*annotationsource.Annotation, error) {
+	pCtx, found, err := s.pluginContextProvider.Get(ctx, pluginID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, annotationsource.ErrSourceNotFound
+	}
+
+	params := url.Values{}
+	params.Set("from", strconv.FormatInt(from, 10))
+	params.Set("to", strconv.FormatInt(to, 10))
+	if limit > 0 {
+		params.Set("limit", strconv.FormatInt(limit, 10))
+	}
+	for _, tag := range tags {
+		params.Add("tags", tag)
+	}
+
+	sender := &adapters.ResourceResponse{}
+	err = s.pluginClient.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          annotationsource.ResourcePath,
+		Method:        http.MethodGet,
+		URL:           annotationsource.ResourcePath + "?" + params.Encode(),
+		Headers:       map[string][]string{},
+	}, sender)
+	if err != nil {
+		return nil, err
+	}
+	if sender.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("annotation source responded with status %d: %s", sender.Status, sender.Body)
+	}
+
+	result := make([]*annotationsource.Annotation, 0)
+	if err := json.Unmarshal(sender.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Service) isMaterialized(pluginID string) bool {
+	for _, id := range s.cfg.AnnotationSourceMaterialize {
+		if id == pluginID {
+			return true
+		}
+	}
+	return false
+}
+
+func isSource(p plugins.PluginDTO) bool {
+	return p.IsApp() && p.Backend && p.AnnotationSource
+}
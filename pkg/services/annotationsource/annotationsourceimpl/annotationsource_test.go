@@ -0,0 +1,75 @@
+package annotationsourceimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationMaterialize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := &Service{store: &sqlStore{db: ss}, log: log.New("annotationsource.test")}
+	ctx := context.Background()
+	now := time.Now()
+	repo := annotations.GetRepository()
+	user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN, Permissions: map[int64]map[string][]string{
+		1: {accesscontrol.ActionAnnotationsRead: {accesscontrol.ScopeAnnotationsTypeOrganization}},
+	}}
+
+	find := func(t *testing.T) []*annotations.ItemDTO {
+		t.Helper()
+		items, err := repo.Find(ctx, &annotations.ItemQuery{OrgId: 1, SignedInUser: user})
+		require.NoError(t, err)
+		return items
+	}
+
+	t.Run("annotations are saved once", func(t *testing.T) {
+		found := []*annotationsource.Annotation{
+			{ID: "1", Time: 1000, TimeEnd: 2000, Text: "Incident", Tags: []string{"incident"}},
+			{Time: 3000, Text: "Without id"},
+		}
+		require.NoError(t, svc.materialize(ctx, 1, "pagerduty-app", found, now))
+		require.NoError(t, svc.materialize(ctx, 1, "pagerduty-app", found, now))
+
+		items := find(t)
+		require.Len(t, items, 1)
+		require.Equal(t, "Incident", items[0].Text)
+		require.Equal(t, int64(2000), items[0].TimeEnd)
+		require.Equal(t, []string{"incident"}, items[0].Tags)
+		require.Equal(t, "pagerduty-app", items[0].Data.Get("source").MustString())
+		require.Equal(t, "1", items[0].Data.Get("sourceId").MustString())
+	})
+
+	t.Run("changed annotations are updated", func(t *testing.T) {
+		found := []*annotationsource.Annotation{
+			{ID: "1", Time: 1000, TimeEnd: 4000, Text: "Incident resolved"},
+		}
+		require.NoError(t, svc.materialize(ctx, 1, "pagerduty-app", found, now))
+
+		items := find(t)
+		require.Len(t, items, 1)
+		require.Equal(t, "Incident resolved", items[0].Text)
+		require.Equal(t, int64(4000), items[0].TimeEnd)
+		require.Empty(t, items[0].Tags)
+	})
+
+	t.Run("ids are scoped to the plugin", func(t *testing.T) {
+		found := []*annotationsource.Annotation{
+			{ID: "1", Time: 5000, Text: "Deployment"},
+		}
+		require.NoError(t, svc.materialize(ctx, 1, "github-app", found, now))
+		require.Len(t, find(t), 2)
+	})
+}
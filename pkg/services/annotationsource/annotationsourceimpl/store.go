@@ -0,0 +1,55 @@
+package annotationsourceimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// GetItem returns nil if the annotation of the source wasn't materialized.
+	GetItem(ctx context.Context, orgID int64, pluginID, externalID string) (*annotationsource.SourceItem, error)
+	SaveItem(ctx context.Context, item *annotationsource.SourceItem) error
+	GetOrgIDs(ctx context.Context) ([]int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) GetItem(ctx context.Context, orgID int64, pluginID, externalID string) (*annotationsource.SourceItem, error) {
+	var result *annotationsource.SourceItem
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		item := &annotationsource.SourceItem{}
+		has, err := sess.Where("org_id = ? AND plugin_id = ? AND external_id = ?", orgID, pluginID, externalID).Get(item)
+		if err != nil {
+			return err
+		}
+		if has {
+			result = item
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *sqlStore) SaveItem(ctx context.Context, item *annotationsource.SourceItem) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if item.Id == 0 {
+			_, err := sess.Insert(item)
+			return err
+		}
+		_, err := sess.ID(item.Id).Cols("annotation_id", "hash", "updated").Update(item)
+		return err
+	})
+}
+
+func (s *sqlStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	orgIDs := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT id FROM org").Find(&orgIDs)
+	})
+	return orgIDs, err
+}
@@ -0,0 +1,179 @@
+package annotationsourceimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotationsource"
+	"github.com/grafana/grafana/pkg/services/pluginsettings"
+)
+
+// IsDisabled returns true when no annotation source is materialized.
+func (s *Service) IsDisabled() bool {
+	return len(s.cfg.AnnotationSourceMaterialize) == 0
+}
+
+// Run copies the annotations of the materialized sources into the annotations
+// table on a schedule. A single server of a cluster runs the synchronization.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.AnnotationSourceSyncInterval)
+	defer ticker.Stop()
+	for {
+		err := s.serverLock.LockAndExecute(ctx, "sync annotation sources", s.cfg.AnnotationSourceSyncInterval, func(ctx context.Context) {
+			s.sync(ctx)
+		})
+		if err != nil {
+			s.log.Error("Failed to lock and execute annotation sources sync", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) sync(ctx context.Context) {
+	for _, pluginID := range s.cfg.AnnotationSourceMaterialize {
+		p, exists := s.pluginStore.Plugin(ctx, pluginID)
+		if !exists || !isSource(p) {
+			s.log.Warn("Skipping materialized annotation source, it is not an annotation source plugin", "pluginId", pluginID)
+			continue
+		}
+		orgIDs, err := s.enabledOrgIDs(ctx, p)
+		if err != nil {
+			s.log.Error("Failed to get the organizations of annotation source", "pluginId", pluginID, "error", err)
+			continue
+		}
+		for _, orgID := range orgIDs {
+			if err := s.syncSource(ctx, orgID, pluginID); err != nil {
+				s.log.Error("Failed to sync annotation source", "pluginId", pluginID, "orgId", orgID, "error", err)
+			}
+		}
+	}
+}
+
+// enabledOrgIDs returns the organizations where the plugin is enabled.
+func (s *Service) enabledOrgIDs(ctx context.Context, p plugins.PluginDTO) ([]int64, error) {
+	settings, err := s.pluginSettings.GetPluginSettings(ctx, &pluginsettings.GetArgs{})
+	if err != nil {
+		return nil, err
+	}
+	enabled := map[int64]bool{}
+	for _, ps := range settings {
+		if ps.PluginID == p.ID {
+			enabled[ps.OrgID] = ps.Enabled
+		}
+	}
+
+	if !p.AutoEnabled {
+		orgIDs := make([]int64, 0, len(enabled))
+		for orgID, isEnabled := range enabled {
+			if isEnabled {
+				orgIDs = append(orgIDs, orgID)
+			}
+		}
+		return orgIDs, nil
+	}
+
+	all, err := s.store.GetOrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orgIDs := make([]int64, 0, len(all))
+	for _, orgID := range all {
+		if isEnabled, ok := enabled[orgID]; !ok || isEnabled {
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	return orgIDs, nil
+}
+
+func (s *Service) syncSource(ctx context.Context, orgID int64, pluginID string) error {
+	now := s.now()
+	user := &models.SignedInUser{OrgId: orgID, OrgRole: models.ROLE_ADMIN, Login: "annotation-source-sync"}
+	found, err := s.fetch(ctx, user, pluginID, now.Add(-s.cfg.AnnotationSourceSyncLookback).UnixMilli(), now.UnixMilli(), 0, nil)
+	if err != nil {
+		return err
+	}
+	return s.materialize(ctx, orgID, pluginID, found, now)
+}
+
+// materialize saves the annotations of a source in the annotations table, or
+// updates the annotations copied by a previous synchronization if they changed.
+// Annotations removed from the source are kept.
+func (s *Service) materialize(ctx context.Context, orgID int64, pluginID string, found []*annotationsource.Annotation, now time.Time) error {
+	repo := annotations.GetRepository()
+	for _, a := range found {
+		if a.ID == "" {
+			s.log.Debug("Skipping annotation without id", "pluginId", pluginID, "time", a.Time)
+			continue
+		}
+		hash, err := hashAnnotation(a)
+		if err != nil {
+			return err
+		}
+		existing, err := s.store.GetItem(ctx, orgID, pluginID, a.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Hash == hash {
+			continue
+		}
+
+		tags := a.Tags
+		if tags == nil {
+			// an empty list removes the tags of updated annotations
+			tags = []string{}
+		}
+		item := &annotations.Item{
+			OrgId:    orgID,
+			Epoch:    a.Time,
+			EpochEnd: a.TimeEnd,
+			Text:     a.Text,
+			Tags:     tags,
+			Data:     a.ItemData(pluginID),
+		}
+		if existing != nil {
+			item.Id = existing.AnnotationId
+			if err := repo.Update(ctx, item); err != nil {
+				s.log.Warn("Failed to update annotation of annotation source", "pluginId", pluginID, "id", a.ID, "error", err)
+				continue
+			}
+		} else {
+			if err := repo.Save(item); err != nil {
+				s.log.Warn("Failed to save annotation of annotation source", "pluginId", pluginID, "id", a.ID, "error", err)
+				continue
+			}
+			existing = &annotationsource.SourceItem{
+				OrgId:        orgID,
+				PluginId:     pluginID,
+				ExternalId:   a.ID,
+				AnnotationId: item.Id,
+			}
+		}
+
+		existing.Hash = hash
+		existing.Updated = now
+		if err := s.store.SaveItem(ctx, existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashAnnotation(a *annotationsource.Annotation) (string, error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
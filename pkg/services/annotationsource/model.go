@@ -0,0 +1,106 @@
+package annotationsource
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+)
+
+var (
+	ErrSourceNotFound = errors.New("annotation source not found")
+)
+
+// ResourcePath is the resource that annotation source plugins serve their annotations on.
+// The plugin receives the from, to and limit query parameters, and a tags parameter per
+// tag, and responds with a JSON array of annotations.
+const ResourcePath = "annotations"
+
+// Annotation is an annotation returned by an annotation source plugin.
+type Annotation struct {
+	// ID identifies the annotation in the source. Annotations without an ID are
+	// not materialized, since they could not be updated.
+	ID      string           `json:"id"`
+	Time    int64            `json:"time"`
+	TimeEnd int64            `json:"timeEnd"`
+	Text    string           `json:"text"`
+	Tags    []string         `json:"tags"`
+	Data    *simplejson.Json `json:"data"`
+}
+
+// ItemData returns the data of the annotation with the plugin and the ID of
+// the annotation in the source, so that clients can link back to the source.
+func (a *Annotation) ItemData(pluginID string) *simplejson.Json {
+	// Copy the map so that the data of the annotation is not modified.
+	fields := map[string]interface{}{}
+	if a.Data != nil {
+		if m, err := a.Data.Map(); err == nil {
+			for k, v := range m {
+				fields[k] = v
+			}
+		}
+	}
+	data := simplejson.NewFromAny(fields)
+	data.Set("source", pluginID)
+	data.Set("sourceId", a.ID)
+	return data
+}
+
+// ToItemDTO converts an annotation queried on demand to the annotations returned
+// by the annotations API. It has no ID since it isn't stored.
+func (a *Annotation) ToItemDTO(pluginID string) *annotations.ItemDTO {
+	tags := a.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &annotations.ItemDTO{
+		Time:    a.Time,
+		TimeEnd: a.TimeEnd,
+		Text:    a.Text,
+		Tags:    tags,
+		Data:    a.ItemData(pluginID),
+	}
+}
+
+// Source is a backend app plugin that provides annotations.
+type Source struct {
+	PluginID string `json:"pluginId"`
+	Name     string `json:"name"`
+	// Materialized sources are copied into the annotations table on a schedule
+	// instead of being queried on demand.
+	Materialized bool `json:"materialized"`
+}
+
+// SourceItem links an annotation copied from a materialized source to the
+// annotation in the source.
+type SourceItem struct {
+	Id           int64
+	OrgId        int64
+	PluginId     string
+	ExternalId   string
+	AnnotationId int64
+	// Hash of the annotation in the source, to only update changed annotations
+	Hash    string
+	Updated time.Time
+}
+
+func (SourceItem) TableName() string {
+	return "annotation_source_item"
+}
+
+// ---------------------
+// QUERIES
+
+// Query queries annotation sources on demand. Materialized sources are skipped,
+// their annotations are found in the annotations table.
+type Query struct {
+	OrgID        int64
+	PluginIDs    []string
+	From         int64
+	To           int64
+	Tags         []string
+	Limit        int64
+	SignedInUser *models.SignedInUser
+}
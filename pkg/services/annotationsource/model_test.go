@@ -0,0 +1,36 @@
+package annotationsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestAnnotation_ToItemDTO(t *testing.T) {
+	t.Run("data links back to the source", func(t *testing.T) {
+		a := &Annotation{
+			ID:   "PT4KHLK",
+			Time: 1000, TimeEnd: 2000,
+			Text: "Database unavailable",
+			Tags: []string{"incident"},
+			Data: simplejson.NewFromAny(map[string]interface{}{"url": "https://example.com/PT4KHLK"}),
+		}
+		item := a.ToItemDTO("pagerduty-app")
+		require.Equal(t, int64(0), item.Id)
+		require.Equal(t, int64(1000), item.Time)
+		require.Equal(t, int64(2000), item.TimeEnd)
+		require.Equal(t, []string{"incident"}, item.Tags)
+		require.Equal(t, "pagerduty-app", item.Data.Get("source").MustString())
+		require.Equal(t, "PT4KHLK", item.Data.Get("sourceId").MustString())
+		require.Equal(t, "https://example.com/PT4KHLK", item.Data.Get("url").MustString())
+		require.Nil(t, a.Data.Get("source").Interface(), "the data of the annotation is not modified")
+	})
+
+	t.Run("missing tags and data", func(t *testing.T) {
+		item := (&Annotation{ID: "1", Time: 1000}).ToItemDTO("github-app")
+		require.Equal(t, []string{}, item.Tags)
+		require.Equal(t, "github-app", item.Data.Get("source").MustString())
+	})
+}
@@ -0,0 +1,20 @@
+package announcements
+
+import (
+	"context"
+)
+
+// Service manages the announcement banners, such as maintenance notices, shown to
+// the users of the targeted organizations and roles.
+type Service interface {
+	Create(ctx context.Context, cmd *CreateAnnouncementCommand) (*Announcement, error)
+	Update(ctx context.Context, cmd *UpdateAnnouncementCommand) (*Announcement, error)
+	Delete(ctx context.Context, uid string) error
+	Get(ctx context.Context, uid string) (*Announcement, error)
+	List(ctx context.Context) ([]*Announcement, error)
+
+	// Active returns the announcements that are shown to a user now and that the user has not dismissed.
+	Active(ctx context.Context, query *ActiveAnnouncementsQuery) ([]*Announcement, error)
+	// Dismiss hides an announcement for a user, the dismissal is stored in the preferences of the user.
+	Dismiss(ctx context.Context, cmd *DismissAnnouncementCommand) error
+}
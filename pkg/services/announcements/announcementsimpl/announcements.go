@@ -0,0 +1,171 @@
+package announcementsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/announcements"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service struct {
+	store       store
+	prefService pref.Service
+	now         func() time.Time
+}
+
+func ProvideService(db db.DB, prefService pref.Service) announcements.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		prefService: prefService,
+		now:         time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, cmd *announcements.CreateAnnouncementCommand) (*announcements.Announcement, error) {
+	if cmd.Uid == "" {
+		cmd.Uid = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(cmd.Uid) {
+		return nil, announcements.ErrAnnouncementInvalidUID
+	}
+
+	now := s.now()
+	announcement := &announcements.Announcement{
+		Uid:      cmd.Uid,
+		Message:  cmd.Message,
+		Severity: cmd.Severity,
+		StartsAt: cmd.StartsAt,
+		EndsAt:   cmd.EndsAt,
+		OrgIds:   cmd.OrgIds,
+		Roles:    cmd.Roles,
+		Created:  now,
+		Updated:  now,
+	}
+	if err := announcement.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.Insert(ctx, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *announcements.UpdateAnnouncementCommand) (*announcements.Announcement, error) {
+	announcement, err := s.store.Get(ctx, cmd.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement.Message = cmd.Message
+	announcement.Severity = cmd.Severity
+	announcement.StartsAt = cmd.StartsAt
+	announcement.EndsAt = cmd.EndsAt
+	announcement.OrgIds = cmd.OrgIds
+	announcement.Roles = cmd.Roles
+	announcement.Updated = s.now()
+	if err := announcement.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(ctx, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+func (s *Service) Delete(ctx context.Context, uid string) error {
+	return s.store.Delete(ctx, uid)
+}
+
+func (s *Service) Get(ctx context.Context, uid string) (*announcements.Announcement, error) {
+	return s.store.Get(ctx, uid)
+}
+
+func (s *Service) List(ctx context.Context) ([]*announcements.Announcement, error) {
+	return s.store.List(ctx)
+}
+
+func (s *Service) Active(ctx context.Context, query *announcements.ActiveAnnouncementsQuery) ([]*announcements.Announcement, error) {
+	all, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	shown := make([]*announcements.Announcement, 0)
+	for _, a := range all {
+		if a.ShownTo(query.OrgID, query.Role, now) {
+			shown = append(shown, a)
+		}
+	}
+	if len(shown) == 0 || query.UserID == 0 {
+		return shown, nil
+	}
+
+	dismissed, err := s.dismissed(ctx, query.OrgID, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*announcements.Announcement, 0, len(shown))
+	for _, a := range shown {
+		if !dismissed[a.Uid] {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+// Dismiss adds the announcement to the dismissed announcements in the preferences of
+// the user. Announcements that no longer exist are dropped from the list at the same
+// time, so that it does not grow forever.
+func (s *Service) Dismiss(ctx context.Context, cmd *announcements.DismissAnnouncementCommand) error {
+	all, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(all))
+	for _, a := range all {
+		exists[a.Uid] = true
+	}
+	if !exists[cmd.Uid] {
+		return announcements.ErrAnnouncementNotFound
+	}
+
+	dismissed, err := s.dismissed(ctx, cmd.OrgID, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if dismissed[cmd.Uid] {
+		return nil
+	}
+	dismissed[cmd.Uid] = true
+
+	uids := make([]string, 0, len(dismissed))
+	for _, a := range all {
+		if dismissed[a.Uid] {
+			uids = append(uids, a.Uid)
+		}
+	}
+	return s.prefService.Patch(ctx, &pref.PatchPreferenceCommand{
+		OrgID:                  cmd.OrgID,
+		UserID:                 cmd.UserID,
+		DismissedAnnouncements: &uids,
+	})
+}
+
+func (s *Service) dismissed(ctx context.Context, orgID, userID int64) (map[string]bool, error) {
+	preference, err := s.prefService.Get(ctx, &pref.GetPreferenceQuery{OrgID: orgID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	dismissed := map[string]bool{}
+	if preference.JSONData != nil {
+		for _, uid := range preference.JSONData.DismissedAnnouncements {
+			dismissed[uid] = true
+		}
+	}
+	return dismissed, nil
+}
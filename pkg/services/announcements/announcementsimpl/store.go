@@ -0,0 +1,85 @@
+package announcementsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, announcement *announcements.Announcement) error
+	Update(ctx context.Context, announcement *announcements.Announcement) error
+	Delete(ctx context.Context, uid string) error
+	Get(ctx context.Context, uid string) (*announcements.Announcement, error)
+	List(ctx context.Context) ([]*announcements.Announcement, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, announcement *announcements.Announcement) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if exists, err := sess.Where("uid=?", announcement.Uid).Exist(&announcements.Announcement{}); err != nil {
+			return err
+		} else if exists {
+			return announcements.ErrAnnouncementUIDTaken
+		}
+
+		_, err := sess.Insert(announcement)
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, announcement *announcements.Announcement) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("uid=?", announcement.Uid).
+			Cols("message", "severity", "starts_at", "ends_at", "org_ids", "roles", "updated").
+			Update(announcement)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return announcements.ErrAnnouncementNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("uid=?", uid).Delete(&announcements.Announcement{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return announcements.ErrAnnouncementNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, uid string) (*announcements.Announcement, error) {
+	announcement := &announcements.Announcement{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("uid=?", uid).Get(announcement)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return announcements.ErrAnnouncementNotFound
+		}
+		return nil
+	})
+	return announcement, err
+}
+
+func (s *sqlStore) List(ctx context.Context) ([]*announcements.Announcement, error) {
+	result := make([]*announcements.Announcement, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Asc("id").Find(&result)
+	})
+	return result, err
+}
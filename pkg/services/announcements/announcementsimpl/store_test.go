@@ -0,0 +1,111 @@
+package announcementsimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationAnnouncements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(ss, prefimpl.ProvideService(ss, ss.Cfg, kvstore.ProvideService(ss), nil, nil)).(*Service)
+	now := time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	end := now.Add(time.Hour)
+	maintenance, err := svc.Create(ctx, &announcements.CreateAnnouncementCommand{
+		Message:  "Grafana will be upgraded tonight",
+		Severity: announcements.SeverityWarning,
+		EndsAt:   &end,
+		OrgIds:   []int64{1},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, maintenance.Uid)
+
+	adminsOnly, err := svc.Create(ctx, &announcements.CreateAnnouncementCommand{
+		Uid:     "admins",
+		Message: "Please review the data source permissions",
+		Roles:   []models.RoleType{models.ROLE_ADMIN},
+	})
+	require.NoError(t, err)
+
+	t.Run("uid must be unique", func(t *testing.T) {
+		_, err := svc.Create(ctx, &announcements.CreateAnnouncementCommand{Uid: "admins", Message: "again"})
+		require.ErrorIs(t, err, announcements.ErrAnnouncementUIDTaken)
+	})
+
+	t.Run("get returns the targets", func(t *testing.T) {
+		a, err := svc.Get(ctx, maintenance.Uid)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1}, a.OrgIds)
+		require.Nil(t, a.StartsAt)
+		require.True(t, end.Equal(*a.EndsAt))
+
+		_, err = svc.Get(ctx, "missing")
+		require.ErrorIs(t, err, announcements.ErrAnnouncementNotFound)
+	})
+
+	t.Run("active announcements are filtered by org and role", func(t *testing.T) {
+		active, err := svc.Active(ctx, &announcements.ActiveAnnouncementsQuery{OrgID: 1, UserID: 1, Role: models.ROLE_ADMIN})
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+
+		active, err = svc.Active(ctx, &announcements.ActiveAnnouncementsQuery{OrgID: 2, UserID: 1, Role: models.ROLE_VIEWER})
+		require.NoError(t, err)
+		require.Len(t, active, 0)
+	})
+
+	t.Run("dismissed announcements are not active", func(t *testing.T) {
+		err := svc.Dismiss(ctx, &announcements.DismissAnnouncementCommand{OrgID: 1, UserID: 1, Uid: maintenance.Uid})
+		require.NoError(t, err)
+
+		active, err := svc.Active(ctx, &announcements.ActiveAnnouncementsQuery{OrgID: 1, UserID: 1, Role: models.ROLE_ADMIN})
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		require.Equal(t, adminsOnly.Uid, active[0].Uid)
+
+		active, err = svc.Active(ctx, &announcements.ActiveAnnouncementsQuery{OrgID: 1, UserID: 2, Role: models.ROLE_ADMIN})
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+
+		err = svc.Dismiss(ctx, &announcements.DismissAnnouncementCommand{OrgID: 1, UserID: 1, Uid: "missing"})
+		require.ErrorIs(t, err, announcements.ErrAnnouncementNotFound)
+	})
+
+	t.Run("announcements end", func(t *testing.T) {
+		now = end
+		active, err := svc.Active(ctx, &announcements.ActiveAnnouncementsQuery{OrgID: 1, UserID: 2, Role: models.ROLE_EDITOR})
+		require.NoError(t, err)
+		require.Len(t, active, 0)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		updated, err := svc.Update(ctx, &announcements.UpdateAnnouncementCommand{
+			Uid:      adminsOnly.Uid,
+			Message:  "Please review the folder permissions",
+			Severity: announcements.SeverityError,
+		})
+		require.NoError(t, err)
+		require.Empty(t, updated.Roles)
+
+		a, err := svc.Get(ctx, adminsOnly.Uid)
+		require.NoError(t, err)
+		require.Equal(t, "Please review the folder permissions", a.Message)
+		require.Equal(t, announcements.SeverityError, a.Severity)
+
+		require.NoError(t, svc.Delete(ctx, adminsOnly.Uid))
+		require.ErrorIs(t, svc.Delete(ctx, adminsOnly.Uid), announcements.ErrAnnouncementNotFound)
+	})
+}
@@ -0,0 +1,153 @@
+package announcements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrAnnouncementNotFound        = errors.New("announcement not found")
+	ErrAnnouncementUIDTaken        = errors.New("an announcement with the same uid already exists")
+	ErrAnnouncementInvalidUID      = errors.New("announcement uid contains illegal characters")
+	ErrAnnouncementInvalid         = errors.New("invalid announcement")
+	ErrAnnouncementMessageRequired = errors.New("announcement message is required")
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) IsValid() bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityError:
+		return true
+	}
+	return false
+}
+
+// Announcement is a message shown as a banner to the users of the targeted organizations
+// and roles between its start and end time.
+type Announcement struct {
+	Id       int64    `json:"id"`
+	Uid      string   `json:"uid"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+	// StartsAt and EndsAt are optional, an announcement without them is shown until it is deleted.
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+	// OrgIds are the organizations the announcement is shown in, all organizations if empty.
+	OrgIds []int64 `json:"orgIds"`
+	// Roles are the organization roles the announcement is shown to, all roles if empty.
+	Roles   []models.RoleType `json:"roles"`
+	Created time.Time         `json:"created"`
+	Updated time.Time         `json:"updated"`
+}
+
+func (a Announcement) TableName() string {
+	return "announcement"
+}
+
+// ----------------------
+// COMMANDS
+
+type CreateAnnouncementCommand struct {
+	Uid      string            `json:"uid"`
+	Message  string            `json:"message"`
+	Severity Severity          `json:"severity"`
+	StartsAt *time.Time        `json:"startsAt"`
+	EndsAt   *time.Time        `json:"endsAt"`
+	OrgIds   []int64           `json:"orgIds"`
+	Roles    []models.RoleType `json:"roles"`
+}
+
+type UpdateAnnouncementCommand struct {
+	Uid      string            `json:"-"`
+	Message  string            `json:"message"`
+	Severity Severity          `json:"severity"`
+	StartsAt *time.Time        `json:"startsAt"`
+	EndsAt   *time.Time        `json:"endsAt"`
+	OrgIds   []int64           `json:"orgIds"`
+	Roles    []models.RoleType `json:"roles"`
+}
+
+type DismissAnnouncementCommand struct {
+	OrgID  int64
+	UserID int64
+	Uid    string
+}
+
+// ---------------------
+// QUERIES
+
+type ActiveAnnouncementsQuery struct {
+	OrgID int64
+	// UserID is used to skip the dismissed announcements, anonymous users have none.
+	UserID int64
+	Role   models.RoleType
+}
+
+// Validate checks the severity, time range and targets of the announcement.
+// An empty severity defaults to info.
+func (a *Announcement) Validate() error {
+	if strings.TrimSpace(a.Message) == "" {
+		return ErrAnnouncementMessageRequired
+	}
+	if a.Severity == "" {
+		a.Severity = SeverityInfo
+	}
+	if !a.Severity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %q", ErrAnnouncementInvalid, a.Severity)
+	}
+	if a.StartsAt != nil && a.EndsAt != nil && !a.EndsAt.After(*a.StartsAt) {
+		return fmt.Errorf("%w: end time must be after start time", ErrAnnouncementInvalid)
+	}
+	for _, r := range a.Roles {
+		if !r.IsValid() {
+			return fmt.Errorf("%w: unknown role %q", ErrAnnouncementInvalid, r)
+		}
+	}
+	return nil
+}
+
+// ShownTo returns true if the announcement is shown at t to users with the role in the organization.
+func (a *Announcement) ShownTo(orgID int64, role models.RoleType, t time.Time) bool {
+	if a.StartsAt != nil && t.Before(*a.StartsAt) {
+		return false
+	}
+	if a.EndsAt != nil && !t.Before(*a.EndsAt) {
+		return false
+	}
+	if len(a.OrgIds) > 0 && !containsOrg(a.OrgIds, orgID) {
+		return false
+	}
+	if len(a.Roles) > 0 && !containsRole(a.Roles, role) {
+		return false
+	}
+	return true
+}
+
+func containsOrg(orgIDs []int64, orgID int64) bool {
+	for _, id := range orgIDs {
+		if id == orgID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRole(roles []models.RoleType, role models.RoleType) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package announcements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestAnnouncementShownTo(t *testing.T) {
+	start := time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	a := &Announcement{
+		Message:  "Maintenance",
+		StartsAt: &start,
+		EndsAt:   &end,
+		OrgIds:   []int64{1},
+		Roles:    []models.RoleType{models.ROLE_ADMIN},
+	}
+
+	require.True(t, a.ShownTo(1, models.ROLE_ADMIN, start))
+	require.True(t, a.ShownTo(1, models.ROLE_ADMIN, end.Add(-time.Second)))
+	require.False(t, a.ShownTo(1, models.ROLE_ADMIN, start.Add(-time.Second)))
+	require.False(t, a.ShownTo(1, models.ROLE_ADMIN, end))
+	require.False(t, a.ShownTo(2, models.ROLE_ADMIN, start))
+	require.False(t, a.ShownTo(1, models.ROLE_VIEWER, start))
+
+	unbounded := &Announcement{Message: "Welcome"}
+	require.True(t, unbounded.ShownTo(5, models.ROLE_VIEWER, start))
+}
+
+func TestAnnouncementValidate(t *testing.T) {
+	start := time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	a := &Announcement{Message: "Maintenance"}
+	require.NoError(t, a.Validate())
+	require.Equal(t, SeverityInfo, a.Severity)
+
+	require.ErrorIs(t, (&Announcement{Message: " "}).Validate(), ErrAnnouncementMessageRequired)
+	require.ErrorIs(t, (&Announcement{Message: "m", Severity: "critical"}).Validate(), ErrAnnouncementInvalid)
+	require.ErrorIs(t, (&Announcement{Message: "m", StartsAt: &start, EndsAt: &start}).Validate(), ErrAnnouncementInvalid)
+	require.ErrorIs(t, (&Announcement{Message: "m", Roles: []models.RoleType{"Owner"}}).Validate(), ErrAnnouncementInvalid)
+}
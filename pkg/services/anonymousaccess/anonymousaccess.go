@@ -0,0 +1,19 @@
+package anonymousaccess
+
+import (
+	"context"
+)
+
+// Service manages the folders and dashboards anonymous users can view in each
+// organization when anonymous access is restricted to an allowlist.
+type Service interface {
+	// IsEnabled returns true when anonymous users can only view the folders
+	// and dashboards of the allowlist of their organization.
+	IsEnabled() bool
+	// GetAllowlist returns the allowlist of the organization, which is empty
+	// unless it was changed.
+	GetAllowlist(ctx context.Context, query *GetAllowlistQuery) (*Allowlist, error)
+	// SaveAllowlist replaces the allowlist of the organization. It returns
+	// ErrItemNotFound if a folder or dashboard doesn't exist in the organization.
+	SaveAllowlist(ctx context.Context, cmd *SaveAllowlistCommand) error
+}
@@ -0,0 +1,96 @@
+package anonymousaccessimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// cacheTTL bounds how long other instances keep enforcing an outdated
+// allowlist after a change.
+const cacheTTL = time.Minute
+
+// Service stores the allowlists in the anonymous_access_item table and caches
+// them, since they are read whenever the permissions of an anonymous user are
+// loaded.
+type Service struct {
+	cfg   *setting.Cfg
+	store store
+	cache *localcache.CacheService
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, cache *localcache.CacheService) anonymousaccess.Service {
+	return &Service{
+		cfg:   cfg,
+		store: &sqlStore{db: db},
+		cache: cache,
+	}
+}
+
+func (s *Service) IsEnabled() bool {
+	return s.cfg.AnonymousEnabled && s.cfg.AnonymousRestrictToAllowlist
+}
+
+func (s *Service) GetAllowlist(ctx context.Context, query *anonymousaccess.GetAllowlistQuery) (*anonymousaccess.Allowlist, error) {
+	key := cacheKey(query.OrgID)
+	if cached, ok := s.cache.Get(key); ok {
+		return copyAllowlist(cached.(*anonymousaccess.Allowlist)), nil
+	}
+
+	items, err := s.store.List(ctx, query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	allowlist := &anonymousaccess.Allowlist{Folders: []string{}, Dashboards: []string{}}
+	for _, item := range items {
+		switch item.Kind {
+		case anonymousaccess.KindFolder:
+			allowlist.Folders = append(allowlist.Folders, item.UID)
+		case anonymousaccess.KindDashboard:
+			allowlist.Dashboards = append(allowlist.Dashboards, item.UID)
+		}
+	}
+
+	s.cache.Set(key, allowlist, cacheTTL)
+	return copyAllowlist(allowlist), nil
+}
+
+func (s *Service) SaveAllowlist(ctx context.Context, cmd *anonymousaccess.SaveAllowlistCommand) error {
+	cmd.Allowlist.Folders = uniqueUIDs(cmd.Allowlist.Folders)
+	cmd.Allowlist.Dashboards = uniqueUIDs(cmd.Allowlist.Dashboards)
+	if err := s.store.Replace(ctx, cmd); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	return nil
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("anonymous-access-allowlist-%d", orgID)
+}
+
+func copyAllowlist(allowlist *anonymousaccess.Allowlist) *anonymousaccess.Allowlist {
+	return &anonymousaccess.Allowlist{
+		Folders:    append([]string{}, allowlist.Folders...),
+		Dashboards: append([]string{}, allowlist.Dashboards...),
+	}
+}
+
+// uniqueUIDs removes the empty and duplicated uids, keeping the order.
+func uniqueUIDs(uids []string) []string {
+	result := make([]string, 0, len(uids))
+	seen := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		if uid == "" || seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		result = append(result, uid)
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package anonymousaccessimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	List(ctx context.Context, orgID int64) ([]anonymousaccess.Item, error)
+	// Replace replaces the items of the organization, after checking the
+	// folders and dashboards exist.
+	Replace(ctx context.Context, cmd *anonymousaccess.SaveAllowlistCommand) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64) ([]anonymousaccess.Item, error) {
+	items := make([]anonymousaccess.Item, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("id").Find(&items)
+	})
+	return items, err
+}
+
+func (s *sqlStore) Replace(ctx context.Context, cmd *anonymousaccess.SaveAllowlistCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		kinds := []struct {
+			kind anonymousaccess.Kind
+			uids []string
+		}{
+			{kind: anonymousaccess.KindFolder, uids: cmd.Allowlist.Folders},
+			{kind: anonymousaccess.KindDashboard, uids: cmd.Allowlist.Dashboards},
+		}
+		for _, k := range kinds {
+			if err := s.checkExist(sess, cmd.OrgID, k.kind, k.uids); err != nil {
+				return err
+			}
+		}
+
+		if _, err := sess.Exec("DELETE FROM anonymous_access_item WHERE org_id=?", cmd.OrgID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, k := range kinds {
+			for _, uid := range k.uids {
+				item := anonymousaccess.Item{OrgID: cmd.OrgID, Kind: k.kind, UID: uid, Created: now}
+				if _, err := sess.Insert(&item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) checkExist(sess *sqlstore.DBSession, orgID int64, kind anonymousaccess.Kind, uids []string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	found := make([]string, 0, len(uids))
+	err := sess.Table("dashboard").Cols("uid").
+		Where("org_id=? AND is_folder=?", orgID, s.db.GetDialect().BooleanStr(kind == anonymousaccess.KindFolder)).
+		In("uid", uids).Find(&found)
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool, len(found))
+	for _, uid := range found {
+		exists[uid] = true
+	}
+	for _, uid := range uids {
+		if !exists[uid] {
+			return fmt.Errorf("%w: %s %s", anonymousaccess.ErrItemNotFound, kind, uid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package anonymousaccessimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationAnonymousAccessStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	store := &sqlStore{db: ss}
+	ctx := context.Background()
+
+	folder := insertDashboard(t, ss, "folder", 0, true)
+	dash := insertDashboard(t, ss, "dash", folder.Id, false)
+
+	t.Run("replaces the items of the organization", func(t *testing.T) {
+		err := store.Replace(ctx, &anonymousaccess.SaveAllowlistCommand{OrgID: 1, Allowlist: anonymousaccess.Allowlist{
+			Folders: []string{folder.Uid}, Dashboards: []string{dash.Uid},
+		}})
+		require.NoError(t, err)
+
+		err = store.Replace(ctx, &anonymousaccess.SaveAllowlistCommand{OrgID: 1, Allowlist: anonymousaccess.Allowlist{
+			Dashboards: []string{dash.Uid},
+		}})
+		require.NoError(t, err)
+
+		items, err := store.List(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, anonymousaccess.KindDashboard, items[0].Kind)
+		require.Equal(t, dash.Uid, items[0].UID)
+
+		items, err = store.List(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("returns an error for unknown folders and dashboards", func(t *testing.T) {
+		for _, allowlist := range []anonymousaccess.Allowlist{
+			{Folders: []string{"missing"}},
+			// a dashboard isn't a folder
+			{Folders: []string{dash.Uid}},
+			{Dashboards: []string{folder.Uid}},
+		} {
+			err := store.Replace(ctx, &anonymousaccess.SaveAllowlistCommand{OrgID: 1, Allowlist: allowlist})
+			require.ErrorIs(t, err, anonymousaccess.ErrItemNotFound)
+		}
+
+		// the other organizations can't allow the dashboards of the organization
+		err := store.Replace(ctx, &anonymousaccess.SaveAllowlistCommand{OrgID: 2, Allowlist: anonymousaccess.Allowlist{
+			Dashboards: []string{dash.Uid},
+		}})
+		require.ErrorIs(t, err, anonymousaccess.ErrItemNotFound)
+
+		items, err := store.List(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+	})
+}
+
+func insertDashboard(t *testing.T, ss *sqlstore.SQLStore, uid string, folderID int64, isFolder bool) *models.Dashboard {
+	t.Helper()
+	dash := &models.Dashboard{
+		OrgId:    1,
+		Uid:      uid,
+		Title:    uid,
+		Slug:     uid,
+		FolderId: folderID,
+		IsFolder: isFolder,
+		Data:     simplejson.NewFromAny(map[string]interface{}{"title": uid}),
+		Version:  1,
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+	return dash
+}
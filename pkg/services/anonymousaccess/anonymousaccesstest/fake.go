@@ -0,0 +1,29 @@
+package anonymousaccesstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/anonymousaccess"
+)
+
+type FakeAnonymousAccessService struct {
+	ExpectedEnabled   bool
+	ExpectedAllowlist *anonymousaccess.Allowlist
+	ExpectedError     error
+}
+
+func NewAnonymousAccessServiceFake() *FakeAnonymousAccessService {
+	return &FakeAnonymousAccessService{ExpectedAllowlist: &anonymousaccess.Allowlist{Folders: []string{}, Dashboards: []string{}}}
+}
+
+func (f *FakeAnonymousAccessService) IsEnabled() bool {
+	return f.ExpectedEnabled
+}
+
+func (f *FakeAnonymousAccessService) GetAllowlist(ctx context.Context, query *anonymousaccess.GetAllowlistQuery) (*anonymousaccess.Allowlist, error) {
+	return f.ExpectedAllowlist, f.ExpectedError
+}
+
+func (f *FakeAnonymousAccessService) SaveAllowlist(ctx context.Context, cmd *anonymousaccess.SaveAllowlistCommand) error {
+	return f.ExpectedError
+}
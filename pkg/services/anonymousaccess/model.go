@@ -0,0 +1,45 @@
+package anonymousaccess
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrItemNotFound = errors.New("folder or dashboard not found")
+
+// Kind is the type of resource of an allowlist item.
+type Kind string
+
+const (
+	KindFolder    Kind = "folder"
+	KindDashboard Kind = "dashboard"
+)
+
+// Item is a folder or dashboard of an allowlist.
+type Item struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	OrgID   int64     `xorm:"org_id"`
+	Kind    Kind      `xorm:"kind"`
+	UID     string    `xorm:"uid"`
+	Created time.Time `xorm:"created"`
+}
+
+func (i Item) TableName() string {
+	return "anonymous_access_item"
+}
+
+// Allowlist holds the uids of the folders and dashboards anonymous users can
+// view. Allowing a folder allows all the dashboards of the folder.
+type Allowlist struct {
+	Folders    []string `json:"folders"`
+	Dashboards []string `json:"dashboards"`
+}
+
+type GetAllowlistQuery struct {
+	OrgID int64
+}
+
+type SaveAllowlistCommand struct {
+	OrgID     int64
+	Allowlist Allowlist
+}
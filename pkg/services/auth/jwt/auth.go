@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/fips"
 	"github.com/grafana/grafana/pkg/setting"
 	"gopkg.in/square/go-jose.v2/jwt"
 )
@@ -74,6 +76,10 @@ func (s *AuthService) Verify(ctx context.Context, strToken string) (models.JWTCl
 		return nil, err
 	}
 
+	if s.Cfg.FIPSMode && !fips.IsApprovedJWTAlgorithm(token.Headers[0].Algorithm) {
+		return nil, fmt.Errorf("JWT signing algorithm %q is not allowed in FIPS mode", token.Headers[0].Algorithm)
+	}
+
 	keys, err := s.keySet.Key(ctx, token.Headers[0].KeyID)
 	if err != nil {
 		return nil, err
@@ -185,6 +185,26 @@ func TestSignatureWithNoneAlgorithm(t *testing.T) {
 	}, configurePKIXPublicKeyFile)
 }
 
+func TestFIPSMode(t *testing.T) {
+	scenario(t, "verifies a token signed with an approved algorithm", func(t *testing.T, sc scenarioContext) {
+		token := sign(t, rsaKeys[0], jwt.Claims{Subject: subject})
+		verifiedClaims, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedClaims["sub"], subject)
+	}, configurePKIXPublicKeyFile, func(t *testing.T, cfg *setting.Cfg) {
+		cfg.FIPSMode = true
+	})
+
+	scenario(t, "rejects a token signed with \"none\" algorithm", func(t *testing.T, sc scenarioContext) {
+		token := signNone(t, jwt.Claims{Subject: subject})
+		_, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not allowed in FIPS mode")
+	}, configurePKIXPublicKeyFile, func(t *testing.T, cfg *setting.Cfg) {
+		cfg.FIPSMode = true
+	})
+}
+
 func TestClaimValidation(t *testing.T) {
 	key := rsaKeys[0]
 
@@ -0,0 +1,13 @@
+package branding
+
+import (
+	"context"
+)
+
+// Service manages the per-organization branding settings that are
+// exposed to the frontend through /api/frontend/settings.
+type Service interface {
+	Get(ctx context.Context, query *GetBrandingQuery) (*OrgBranding, error)
+	Save(ctx context.Context, cmd *SaveBrandingCommand) error
+	Delete(ctx context.Context, cmd *DeleteBrandingCommand) error
+}
@@ -0,0 +1,59 @@
+package brandingimpl
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/branding"
+)
+
+const (
+	kvNamespace = "branding"
+	kvKey       = "org"
+)
+
+// Service stores the branding of each organization as a JSON document
+// in the key-value store, so that no schema migration is needed when
+// new branding fields are added.
+type Service struct {
+	kv kvstore.KVStore
+}
+
+func ProvideService(kv kvstore.KVStore) branding.Service {
+	return &Service{
+		kv: kv,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *branding.GetBrandingQuery) (*branding.OrgBranding, error) {
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, branding.ErrBrandingNotFound
+	}
+
+	var result branding.OrgBranding
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *branding.SaveBrandingCommand) error {
+	if err := cmd.Branding.Validate(); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(cmd.Branding)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, cmd.OrgID, kvNamespace, kvKey, string(raw))
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *branding.DeleteBrandingCommand) error {
+	return s.kv.Del(ctx, cmd.OrgID, kvNamespace, kvKey)
+}
@@ -0,0 +1,70 @@
+package brandingimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationBranding(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	brandingService := ProvideService(kvstore.ProvideService(ss))
+
+	t.Run("Get without saved branding returns not found", func(t *testing.T) {
+		_, err := brandingService.Get(context.Background(), &branding.GetBrandingQuery{OrgID: 1})
+		require.ErrorIs(t, err, branding.ErrBrandingNotFound)
+	})
+
+	t.Run("Save and get branding is scoped per org", func(t *testing.T) {
+		err := brandingService.Save(context.Background(), &branding.SaveBrandingCommand{
+			OrgID: 1,
+			Branding: branding.OrgBranding{
+				AppTitle:     "ACME Observability",
+				LoginLogoURL: "https://cdn.acme.com/logo.svg",
+				MenuLogoURL:  "/public/img/acme.svg",
+				FooterLinks: []branding.FooterLink{
+					{Text: "Support", URL: "https://acme.com/support"},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := brandingService.Get(context.Background(), &branding.GetBrandingQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, "ACME Observability", res.AppTitle)
+		require.Len(t, res.FooterLinks, 1)
+
+		_, err = brandingService.Get(context.Background(), &branding.GetBrandingQuery{OrgID: 2})
+		require.ErrorIs(t, err, branding.ErrBrandingNotFound)
+	})
+
+	t.Run("Save rejects invalid URLs", func(t *testing.T) {
+		err := brandingService.Save(context.Background(), &branding.SaveBrandingCommand{
+			OrgID:    1,
+			Branding: branding.OrgBranding{LoginLogoURL: "javascript:alert(1)"},
+		})
+		require.ErrorIs(t, err, branding.ErrInvalidBrandingURL)
+
+		err = brandingService.Save(context.Background(), &branding.SaveBrandingCommand{
+			OrgID:    1,
+			Branding: branding.OrgBranding{FooterLinks: []branding.FooterLink{{Text: "Docs"}}},
+		})
+		require.ErrorIs(t, err, branding.ErrInvalidFooterLink)
+	})
+
+	t.Run("Delete removes the branding", func(t *testing.T) {
+		err := brandingService.Delete(context.Background(), &branding.DeleteBrandingCommand{OrgID: 1})
+		require.NoError(t, err)
+
+		_, err = brandingService.Get(context.Background(), &branding.GetBrandingQuery{OrgID: 1})
+		require.ErrorIs(t, err, branding.ErrBrandingNotFound)
+	})
+}
@@ -0,0 +1,28 @@
+package brandingtest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/branding"
+)
+
+type FakeBrandingService struct {
+	ExpectedBranding *branding.OrgBranding
+	ExpectedError    error
+}
+
+func NewBrandingServiceFake() *FakeBrandingService {
+	return &FakeBrandingService{}
+}
+
+func (f *FakeBrandingService) Get(ctx context.Context, query *branding.GetBrandingQuery) (*branding.OrgBranding, error) {
+	return f.ExpectedBranding, f.ExpectedError
+}
+
+func (f *FakeBrandingService) Save(ctx context.Context, cmd *branding.SaveBrandingCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeBrandingService) Delete(ctx context.Context, cmd *branding.DeleteBrandingCommand) error {
+	return f.ExpectedError
+}
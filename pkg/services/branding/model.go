@@ -0,0 +1,104 @@
+package branding
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrBrandingNotFound   = errors.New("branding not found")
+	ErrInvalidBrandingURL = errors.New("branding URLs must be absolute http(s) URLs or paths starting with /")
+	ErrInvalidFooterLink  = errors.New("footer links must have a text and a URL")
+	ErrTooManyFooterLinks = errors.New("too many footer links")
+)
+
+// MaxFooterLinks is the maximum number of custom footer links an
+// organization can configure.
+const MaxFooterLinks = 10
+
+// OrgBranding holds the branding an organization admin can apply on
+// top of the stock Grafana look. Empty fields fall back to the default
+// frontend values.
+type OrgBranding struct {
+	AppTitle           string       `json:"appTitle,omitempty"`
+	LoginTitle         string       `json:"loginTitle,omitempty"`
+	LoginSubtitle      string       `json:"loginSubtitle,omitempty"`
+	LoginLogoURL       string       `json:"loginLogoUrl,omitempty"`
+	LoginBackgroundURL string       `json:"loginBackgroundUrl,omitempty"`
+	MenuLogoURL        string       `json:"menuLogoUrl,omitempty"`
+	FaviconURL         string       `json:"faviconUrl,omitempty"`
+	FooterLinks        []FooterLink `json:"footerLinks,omitempty"`
+	EmailHeaderURL     string       `json:"emailHeaderUrl,omitempty"`
+	EmailFooterText    string       `json:"emailFooterText,omitempty"`
+}
+
+type FooterLink struct {
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+	Target string `json:"target,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+}
+
+type GetBrandingQuery struct {
+	OrgID int64
+}
+
+type SaveBrandingCommand struct {
+	OrgID    int64
+	Branding OrgBranding
+}
+
+type DeleteBrandingCommand struct {
+	OrgID int64
+}
+
+// Validate checks that all URLs are either absolute http(s) URLs or
+// paths relative to the Grafana root, and that footer links are complete.
+func (b *OrgBranding) Validate() error {
+	for _, u := range []string{b.LoginLogoURL, b.LoginBackgroundURL, b.MenuLogoURL, b.FaviconURL, b.EmailHeaderURL} {
+		if !isValidBrandingURL(u) {
+			return ErrInvalidBrandingURL
+		}
+	}
+
+	if len(b.FooterLinks) > MaxFooterLinks {
+		return ErrTooManyFooterLinks
+	}
+	for _, l := range b.FooterLinks {
+		if l.Text == "" || l.URL == "" {
+			return ErrInvalidFooterLink
+		}
+		if !isValidBrandingURL(l.URL) {
+			return ErrInvalidBrandingURL
+		}
+	}
+	return nil
+}
+
+// LoginBranding returns the part of the branding that is shown on the login
+// page, to users who are not signed in yet.
+func (b *OrgBranding) LoginBranding() *OrgBranding {
+	return &OrgBranding{
+		AppTitle:           b.AppTitle,
+		LoginTitle:         b.LoginTitle,
+		LoginSubtitle:      b.LoginSubtitle,
+		LoginLogoURL:       b.LoginLogoURL,
+		LoginBackgroundURL: b.LoginBackgroundURL,
+		FaviconURL:         b.FaviconURL,
+	}
+}
+
+func isValidBrandingURL(raw string) bool {
+	if raw == "" {
+		return true
+	}
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
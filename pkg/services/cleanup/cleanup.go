@@ -8,7 +8,11 @@ import (
 	"path"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/accessgrants"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/inbox"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -22,7 +26,8 @@ import (
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, store sqlstore.Store, queryHistoryService queryhistory.Service,
-	dashboardVersionService dashver.Service) *CleanUpService {
+	dashboardVersionService dashver.Service, inboxService inbox.Service, accessGrants accessgrants.Service,
+	emailDeliveries emaildelivery.Service, dashboardArchive dashboardarchive.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                     cfg,
 		ServerLockService:       serverLockService,
@@ -31,6 +36,10 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		store:                   store,
 		log:                     log.New("cleanup"),
 		dashboardVersionService: dashboardVersionService,
+		inboxService:            inboxService,
+		accessGrants:            accessGrants,
+		emailDeliveries:         emailDeliveries,
+		dashboardArchive:        dashboardArchive,
 	}
 	return s
 }
@@ -43,6 +52,10 @@ type CleanUpService struct {
 	ShortURLService         shorturls.Service
 	QueryHistoryService     queryhistory.Service
 	dashboardVersionService dashver.Service
+	inboxService            inbox.Service
+	accessGrants            accessgrants.Service
+	emailDeliveries         emaildelivery.Service
+	dashboardArchive        dashboardarchive.Service
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -69,6 +82,31 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			if err != nil {
 				srv.log.Error("failed to lock and execute cleanup of old login attempts", "error", err)
 			}
+			srv.deleteExpiredInboxNotifications(ctx)
+			srv.deleteExpiredAccessGrants(ctx)
+			srv.deleteExpiredEmailDeliveries(ctx)
+			// Only one instance should warn about expiring tokens so that admins are notified once
+			err = srv.ServerLockService.LockAndExecute(ctx, "warn about expiring tokens",
+				time.Minute*10, func(context.Context) {
+					srv.warnExpiringTokens(ctx)
+				})
+			if err != nil {
+				srv.log.Error("failed to lock and execute warnings about expiring tokens", "error", err)
+			}
+			err = srv.ServerLockService.LockAndExecute(ctx, "archive unused dashboards",
+				time.Minute*10, func(context.Context) {
+					srv.archiveUnusedDashboards(ctx)
+				})
+			if err != nil {
+				srv.log.Error("failed to lock and execute archiving of unused dashboards", "error", err)
+			}
+			err = srv.ServerLockService.LockAndExecute(ctx, "purge dashboard trash",
+				time.Minute*10, func(context.Context) {
+					srv.purgeDashboardTrash(ctx)
+				})
+			if err != nil {
+				srv.log.Error("failed to lock and execute purge of the dashboard trash", "error", err)
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -83,6 +121,13 @@ func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
 	} else {
 		srv.log.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
 	}
+
+	repaired, err := cleaner.RepairSkewedAnnotations(ctx, srv.Cfg)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		srv.log.Error("failed to repair annotations with a skewed time", "error", err)
+	} else if repaired > 0 {
+		srv.log.Info("Repaired annotations with a skewed time", "annotations affected", repaired)
+	}
 }
 
 func (srv *CleanUpService) cleanUpTmpFiles() {
@@ -193,10 +238,8 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 }
 
 func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
-	// Delete query history from 14+ days ago with exception of starred queries
-	maxQueryHistoryLifetime := time.Hour * 24 * 14
-	olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
-	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
+	// Delete query history older than the retention of its organization with exception of starred and shared queries
+	rowsCount, err := srv.QueryHistoryService.ApplyRetentionPoliciesInQueryHistory(ctx)
 	if err != nil {
 		srv.log.Error("Problem deleting stale query history", "error", err.Error())
 	} else {
@@ -221,3 +264,57 @@ func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 		srv.log.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredInboxNotifications(ctx context.Context) {
+	rowsCount, err := srv.inboxService.DeleteExpired(ctx)
+	if err != nil {
+		srv.log.Error("Problem deleting expired inbox notifications", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired inbox notifications", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredAccessGrants(ctx context.Context) {
+	rowsCount, err := srv.accessGrants.DeleteExpired(ctx)
+	if err != nil {
+		srv.log.Error("Problem deleting expired access grants", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired access grants", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredEmailDeliveries(ctx context.Context) {
+	rowsCount, err := srv.emailDeliveries.DeleteExpired(ctx)
+	if err != nil {
+		srv.log.Error("Problem deleting expired email deliveries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired email deliveries", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) archiveUnusedDashboards(ctx context.Context) {
+	count, err := srv.dashboardArchive.ArchiveUnused(ctx)
+	if err != nil {
+		srv.log.Error("Problem archiving unused dashboards", "error", err.Error())
+	} else {
+		srv.log.Debug("Archived unused dashboards", "dashboards", count)
+	}
+}
+
+func (srv *CleanUpService) purgeDashboardTrash(ctx context.Context) {
+	count, err := srv.dashboardArchive.PurgeTrash(ctx)
+	if err != nil {
+		srv.log.Error("Problem purging the dashboard trash", "error", err.Error())
+	} else {
+		srv.log.Debug("Purged the dashboard trash", "dashboards", count)
+	}
+}
+
+func (srv *CleanUpService) warnExpiringTokens(ctx context.Context) {
+	count, err := srv.inboxService.WarnExpiringTokens(ctx)
+	if err != nil {
+		srv.log.Error("Problem warning about expiring tokens", "error", err.Error())
+	} else {
+		srv.log.Debug("Warned about expiring tokens", "tokens", count)
+	}
+}
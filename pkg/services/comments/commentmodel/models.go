@@ -5,6 +5,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -14,12 +16,34 @@ const (
 	ObjectTypeDashboard = "dashboard"
 	// ObjectTypeAnnotation used for annotation comments.
 	ObjectTypeAnnotation = "annotation"
+	// ObjectTypePanel used for comments on a single panel of a dashboard, see PanelObjectID.
+	ObjectTypePanel = "panel"
 )
 
 var RegisteredObjectTypes = map[string]struct{}{
 	ObjectTypeOrg:        {},
 	ObjectTypeDashboard:  {},
 	ObjectTypeAnnotation: {},
+	ObjectTypePanel:      {},
+}
+
+// PanelObjectID returns the object id of the comments of a panel. Dashboard uids
+// cannot contain dots, so the id can be split again unambiguously.
+func PanelObjectID(dashboardUID string, panelID int64) string {
+	return dashboardUID + "." + strconv.FormatInt(panelID, 10)
+}
+
+// ParsePanelObjectID returns the dashboard uid and panel id of a panel object id.
+func ParsePanelObjectID(objectID string) (string, int64, bool) {
+	idx := strings.LastIndex(objectID, ".")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	panelID, err := strconv.ParseInt(objectID[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return objectID[:idx], panelID, true
 }
 
 type CommentGroup struct {
@@ -67,11 +91,29 @@ func (s *Settings) Scan(value interface{}) error {
 type Comment struct {
 	Id      int64
 	GroupId int64
-	UserId  int64
-	Content string
+	// ParentId is the id of the comment this comment replies to, zero for top level comments.
+	ParentId int64
+	UserId   int64
+	Content  string
 
 	Created int64
 	Updated int64
+
+	// Mentions are the ids of the users mentioned in the content, stored in the comment_mention table.
+	Mentions []int64 `xorm:"-"`
+}
+
+// CommentMention records that a user was mentioned with @login in a comment.
+type CommentMention struct {
+	Id        int64
+	CommentId int64
+	OrgId     int64
+	UserId    int64
+	Created   int64
+}
+
+func (i CommentMention) TableName() string {
+	return "comment_mention"
 }
 
 type CommentUser struct {
@@ -83,23 +125,40 @@ type CommentUser struct {
 }
 
 type CommentDto struct {
-	Id      int64        `json:"id"`
-	UserId  int64        `json:"userId"`
-	Content string       `json:"content"`
-	Created int64        `json:"created"`
-	User    *CommentUser `json:"user,omitempty"`
+	Id       int64        `json:"id"`
+	ParentId int64        `json:"parentId,omitempty"`
+	UserId   int64        `json:"userId"`
+	Content  string       `json:"content"`
+	Created  int64        `json:"created"`
+	User     *CommentUser `json:"user,omitempty"`
+	Mentions []int64      `json:"mentions,omitempty"`
 }
 
 func (i Comment) ToDTO(user *CommentUser) *CommentDto {
 	return &CommentDto{
-		Id:      i.Id,
-		UserId:  i.UserId,
-		Content: i.Content,
-		Created: i.Created,
-		User:    user,
+		Id:       i.Id,
+		ParentId: i.ParentId,
+		UserId:   i.UserId,
+		Content:  i.Content,
+		Created:  i.Created,
+		User:     user,
+		Mentions: i.Mentions,
 	}
 }
 
+// ActivityItem is a comment together with the object it was made on.
+type ActivityItem struct {
+	Comment    `xorm:"extends"`
+	ObjectType string
+	ObjectId   string
+}
+
+type ActivityItemDto struct {
+	*CommentDto
+	ObjectType string `json:"objectType"`
+	ObjectId   string `json:"objectId"`
+}
+
 func (i Comment) TableName() string {
 	return "comment"
 }
@@ -0,0 +1,22 @@
+package commentmodel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanelObjectID(t *testing.T) {
+	objectID := PanelObjectID("abc-123_x", 4)
+	require.Equal(t, "abc-123_x.4", objectID)
+
+	dashboardUID, panelID, ok := ParsePanelObjectID(objectID)
+	require.True(t, ok)
+	require.Equal(t, "abc-123_x", dashboardUID)
+	require.Equal(t, int64(4), panelID)
+
+	for _, invalid := range []string{"", "abc", ".4", "abc.x"} {
+		_, _, ok := ParsePanelObjectID(invalid)
+		require.False(t, ok, invalid)
+	}
+}
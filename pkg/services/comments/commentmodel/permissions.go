@@ -23,7 +23,7 @@ type PermissionChecker struct {
 func NewPermissionChecker(sqlStore *sqlstore.SQLStore, features featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService,
 ) *PermissionChecker {
-	return &PermissionChecker{sqlStore: sqlStore, features: features, accessControl: accessControl}
+	return &PermissionChecker{sqlStore: sqlStore, features: features, accessControl: accessControl, dashboardService: dashboardService}
 }
 
 func (c *PermissionChecker) getDashboardByUid(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
@@ -46,11 +46,15 @@ func (c *PermissionChecker) CheckReadPermissions(ctx context.Context, orgId int6
 	switch objectType {
 	case ObjectTypeOrg:
 		return false, nil
-	case ObjectTypeDashboard:
+	case ObjectTypeDashboard, ObjectTypePanel:
 		if !c.features.IsEnabled(featuremgmt.FlagDashboardComments) {
 			return false, nil
 		}
-		dash, err := c.getDashboardByUid(ctx, orgId, objectID)
+		dashboardUID, ok := dashboardUIDFromObject(objectType, objectID)
+		if !ok {
+			return false, nil
+		}
+		dash, err := c.getDashboardByUid(ctx, orgId, dashboardUID)
 		if err != nil {
 			return false, err
 		}
@@ -93,11 +97,15 @@ func (c *PermissionChecker) CheckWritePermissions(ctx context.Context, orgId int
 	switch objectType {
 	case ObjectTypeOrg:
 		return false, nil
-	case ObjectTypeDashboard:
+	case ObjectTypeDashboard, ObjectTypePanel:
 		if !c.features.IsEnabled(featuremgmt.FlagDashboardComments) {
 			return false, nil
 		}
-		dash, err := c.getDashboardByUid(ctx, orgId, objectID)
+		dashboardUID, ok := dashboardUIDFromObject(objectType, objectID)
+		if !ok {
+			return false, nil
+		}
+		dash, err := c.getDashboardByUid(ctx, orgId, dashboardUID)
 		if err != nil {
 			return false, err
 		}
@@ -141,3 +149,12 @@ func (c *PermissionChecker) CheckWritePermissions(ctx context.Context, orgId int
 	}
 	return true, nil
 }
+
+// dashboardUIDFromObject returns the uid of the dashboard that dashboard and panel comments belong to.
+func dashboardUIDFromObject(objectType string, objectID string) (string, bool) {
+	if objectType == ObjectTypePanel {
+		dashboardUID, _, ok := ParsePanelObjectID(objectID)
+		return dashboardUID, ok
+	}
+	return objectID, true
+}
@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
+	"github.com/grafana/grafana/pkg/services/inbox"
 )
 
+var logger = log.New("comments")
+
 func commentsToDto(items []*commentmodel.Comment, userMap map[int64]*commentmodel.CommentUser) []*commentmodel.CommentDto {
 	result := make([]*commentmodel.CommentDto, 0, len(items))
 	for _, m := range items {
@@ -82,10 +88,40 @@ type CreateCmd struct {
 	ObjectType string `json:"objectType"`
 	ObjectID   string `json:"objectId"`
 	Content    string `json:"content"`
+	// ParentId makes the comment a reply to another comment on the same object.
+	ParentId int64 `json:"parentId"`
+}
+
+type ActivityCmd struct {
+	Limit        uint   `json:"limit"`
+	BeforeId     int64  `json:"beforeId"`
+	DashboardUID string `json:"dashboardUid"`
+	MentionsOnly bool   `json:"mentionsOnly"`
 }
 
 var ErrPermissionDenied = errors.New("permission denied")
 
+// ErrParentNotFound is returned when replying to a comment that does not belong to the object.
+var ErrParentNotFound = errParentNotFound
+
+var mentionRegex = regexp.MustCompile(`(?:^|[^\w])@([\w.\-]+)`)
+
+// parseMentions returns the distinct logins mentioned with @login in the content.
+func parseMentions(content string) []string {
+	seen := map[string]bool{}
+	var logins []string
+	for _, m := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		// A trailing dot is the end of a sentence rather than part of the login
+		login := strings.TrimRight(m[1], ".")
+		if login == "" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		logins = append(logins, login)
+	}
+	return logins
+}
+
 func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd CreateCmd) (*commentmodel.CommentDto, error) {
 	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
 	if err != nil {
@@ -106,7 +142,10 @@ func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.
 		}
 	}
 
-	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserId, cmd.Content)
+	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserId, cmd.Content, CreateOptions{
+		ParentID: cmd.ParentId,
+		Mentions: parseMentions(cmd.Content),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -117,9 +156,53 @@ func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.
 	}
 	eventJSON, _ := json.Marshal(e)
 	_ = s.live.Publish(orgID, fmt.Sprintf("grafana/comment/%s/%s", cmd.ObjectType, cmd.ObjectID), eventJSON)
+	s.notifyMentions(ctx, orgID, signedInUser, cmd, m)
 	return mDto, nil
 }
 
+// notifyMentions adds a notification to the inbox of the users mentioned in the comment.
+func (s *Service) notifyMentions(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd CreateCmd, comment *commentmodel.Comment) {
+	userIDs := make([]int64, 0, len(comment.Mentions))
+	for _, id := range comment.Mentions {
+		if id != signedInUser.UserId {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	author := signedInUser.Name
+	if author == "" {
+		author = signedInUser.Login
+	}
+	err := s.inbox.Send(ctx, &inbox.SendCommand{
+		OrgID:   orgID,
+		UserIDs: userIDs,
+		Kind:    inbox.KindCommentMention,
+		Title:   fmt.Sprintf("%s mentioned you in a comment", author),
+		Body:    comment.Content,
+		URL:     commentURL(cmd.ObjectType, cmd.ObjectID),
+	})
+	if err != nil {
+		// The comment is already saved, a missing notification should not fail the request
+		logger.Warn("Failed to notify mentioned users", "comment", comment.Id, "error", err)
+	}
+}
+
+// commentURL returns the page the comments of the object are shown on.
+func commentURL(objectType string, objectID string) string {
+	switch objectType {
+	case commentmodel.ObjectTypeDashboard:
+		return "/d/" + objectID
+	case commentmodel.ObjectTypePanel:
+		if dashboardUID, panelID, ok := commentmodel.ParsePanelObjectID(objectID); ok {
+			return fmt.Sprintf("/d/%s?viewPanel=%d", dashboardUID, panelID)
+		}
+	}
+	return ""
+}
+
 func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd GetCmd) ([]*commentmodel.CommentDto, error) {
 	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
 	if err != nil {
@@ -137,6 +220,74 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *models.Sig
 		return nil, err
 	}
 
+	userMap, err := s.commentUsers(ctx, signedInUser, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := commentsToDto(messages, userMap)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+// Activity returns the latest comments in the organization on objects the user can read, newest first.
+func (s *Service) Activity(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd ActivityCmd) ([]*commentmodel.ActivityItemDto, error) {
+	filter := ActivityFilter{
+		Limit:        cmd.Limit,
+		BeforeID:     cmd.BeforeId,
+		DashboardUID: cmd.DashboardUID,
+	}
+	if cmd.MentionsOnly {
+		filter.MentionedUserID = signedInUser.UserId
+	}
+
+	items, err := s.storage.Activity(ctx, orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Permissions are checked once per object as many comments usually belong to the same one
+	canRead := map[string]bool{}
+	readable := make([]*commentmodel.ActivityItem, 0, len(items))
+	for _, item := range items {
+		key := item.ObjectType + "/" + item.ObjectId
+		ok, checked := canRead[key]
+		if !checked {
+			ok, err = s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, item.ObjectType, item.ObjectId)
+			if err != nil {
+				// The object may have been deleted since the comment was made
+				ok = false
+			}
+			canRead[key] = ok
+		}
+		if ok {
+			readable = append(readable, item)
+		}
+	}
+
+	messages := make([]*commentmodel.Comment, 0, len(readable))
+	for _, item := range readable {
+		messages = append(messages, &item.Comment)
+	}
+	userMap, err := s.commentUsers(ctx, signedInUser, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*commentmodel.ActivityItemDto, 0, len(readable))
+	for _, item := range readable {
+		result = append(result, &commentmodel.ActivityItemDto{
+			CommentDto: commentToDto(&item.Comment, userMap),
+			ObjectType: item.ObjectType,
+			ObjectId:   item.ObjectId,
+		})
+	}
+	return result, nil
+}
+
+func (s *Service) commentUsers(ctx context.Context, signedInUser *models.SignedInUser, messages []*commentmodel.Comment) (map[int64]*commentmodel.CommentUser, error) {
 	userIds := make([]int64, 0, len(messages))
 	for _, m := range messages {
 		if m.UserId <= 0 {
@@ -144,6 +295,9 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *models.Sig
 		}
 		userIds = append(userIds, m.UserId)
 	}
+	if len(userIds) == 0 {
+		return map[int64]*commentmodel.CommentUser{}, nil
+	}
 
 	// NOTE: probably replace with comment and user table join.
 	query := &models.SearchUsersQuery{
@@ -161,10 +315,5 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *models.Sig
 	for _, v := range query.Result.Users {
 		userMap[v.Id] = searchUserToCommentUser(v)
 	}
-
-	result := commentsToDto(messages, userMap)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Id < result[j].Id
-	})
-	return result, nil
+	return userMap, nil
 }
@@ -0,0 +1,25 @@
+package comments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{content: "no mentions", expected: nil},
+		{content: "@alice look at this", expected: []string{"alice"}},
+		{content: "cc @alice, @bob.smith and @alice again.", expected: []string{"alice", "bob.smith"}},
+		{content: "ping @bob.", expected: []string{"bob"}},
+		{content: "mail alice@example.com", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			require.Equal(t, tt.expected, parseMentions(tt.content))
+		})
+	}
+}
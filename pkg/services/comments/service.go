@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/inbox"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -18,11 +19,12 @@ type Service struct {
 	sqlStore    *sqlstore.SQLStore
 	storage     Storage
 	permissions *commentmodel.PermissionChecker
+	inbox       inbox.Service
 }
 
 func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.GrafanaLive,
 	features featuremgmt.FeatureToggles, accessControl accesscontrol.AccessControl,
-	dashboardService dashboards.DashboardService) *Service {
+	dashboardService dashboards.DashboardService, inboxService inbox.Service) *Service {
 	s := &Service{
 		cfg:      cfg,
 		live:     live,
@@ -31,6 +33,7 @@ func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.Grafa
 			sql: store,
 		},
 		permissions: commentmodel.NewPermissionChecker(store, features, accessControl, dashboardService),
+		inbox:       inboxService,
 	}
 	return s
 }
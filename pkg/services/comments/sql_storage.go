@@ -2,6 +2,7 @@ package comments
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
@@ -21,7 +22,7 @@ func checkObjectID(objectID string) bool {
 	return objectID != ""
 }
 
-func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error) {
+func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string, opts CreateOptions) (*commentmodel.Comment, error) {
 	if !checkObjectType(objectType) {
 		return nil, errUnknownObjectType
 	}
@@ -60,22 +61,96 @@ func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string,
 			}
 			groupID = group.Id
 		}
+
+		if opts.ParentID > 0 {
+			parentExists, err := dbSession.Where("id=? AND group_id=?", opts.ParentID, groupID).Exist(&commentmodel.Comment{})
+			if err != nil {
+				return err
+			}
+			if !parentExists {
+				return errParentNotFound
+			}
+		}
+
 		message := commentmodel.Comment{
-			GroupId: groupID,
-			UserId:  userID,
-			Content: content,
-			Created: nowUnix,
-			Updated: nowUnix,
+			GroupId:  groupID,
+			ParentId: opts.ParentID,
+			UserId:   userID,
+			Content:  content,
+			Created:  nowUnix,
+			Updated:  nowUnix,
 		}
 		_, err = dbSession.Insert(&message)
 		if err != nil {
 			return err
 		}
+
+		mentions, err := s.insertMentions(dbSession, orgID, message.Id, opts.Mentions, nowUnix)
+		if err != nil {
+			return err
+		}
+		message.Mentions = mentions
 		result = &message
 		return nil
 	})
 }
 
+// insertMentions resolves the logins to the users of the organization and records the mentions.
+func (s *sqlStorage) insertMentions(dbSession *sqlstore.DBSession, orgID int64, commentID int64, logins []string, nowUnix int64) ([]int64, error) {
+	if len(logins) == 0 {
+		return nil, nil
+	}
+
+	params := []interface{}{orgID}
+	for _, login := range logins {
+		params = append(params, login)
+	}
+	var userIDs []int64
+	rawSQL := "SELECT u.id FROM " + s.sql.Dialect.Quote("user") + " AS u INNER JOIN org_user ON org_user.user_id = u.id " +
+		"WHERE org_user.org_id = ? AND u.login IN (?" + strings.Repeat(",?", len(logins)-1) + ") ORDER BY u.id"
+	if err := dbSession.SQL(rawSQL, params...).Find(&userIDs); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		mention := commentmodel.CommentMention{
+			CommentId: commentID,
+			OrgId:     orgID,
+			UserId:    userID,
+			Created:   nowUnix,
+		}
+		if _, err := dbSession.Insert(&mention); err != nil {
+			return nil, err
+		}
+	}
+	return userIDs, nil
+}
+
+// loadMentions sets the mentioned users of the comments.
+func loadMentions(dbSession *sqlstore.DBSession, comments []*commentmodel.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*commentmodel.Comment, len(comments))
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		byID[c.Id] = c
+		ids = append(ids, c.Id)
+	}
+
+	var mentions []*commentmodel.CommentMention
+	if err := dbSession.In("comment_id", ids).OrderBy("id").Find(&mentions); err != nil {
+		return err
+	}
+	for _, m := range mentions {
+		if c, ok := byID[m.CommentId]; ok {
+			c.Mentions = append(c.Mentions, m.UserId)
+		}
+	}
+	return nil
+}
+
 const maxLimit = 300
 
 func (s *sqlStorage) Get(ctx context.Context, orgID int64, objectType string, objectID string, filter GetFilter) ([]*commentmodel.Comment, error) {
@@ -112,6 +187,62 @@ func (s *sqlStorage) Get(ctx context.Context, orgID int64, objectType string, ob
 		if filter.BeforeID > 0 {
 			clause.Where("id < ?", filter.BeforeID)
 		}
-		return clause.OrderBy("id desc").Limit(limit).Find(&result)
+		if err := clause.OrderBy("id desc").Limit(limit).Find(&result); err != nil {
+			return err
+		}
+		return loadMentions(dbSession, result)
+	})
+}
+
+func (s *sqlStorage) Activity(ctx context.Context, orgID int64, filter ActivityFilter) ([]*commentmodel.ActivityItem, error) {
+	var result []*commentmodel.ActivityItem
+
+	limit := 100
+	if filter.Limit > 0 {
+		limit = int(filter.Limit)
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+	}
+
+	err := s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sb := sqlstore.SQLBuilder{}
+		sb.Write(`SELECT comment.*, comment_group.object_type, comment_group.object_id
+			FROM comment
+			INNER JOIN comment_group ON comment_group.id = comment.group_id
+			WHERE comment_group.org_id = ?`, orgID)
+		if filter.DashboardUID != "" {
+			sb.Write(` AND ((comment_group.object_type = ? AND comment_group.object_id = ?) OR (comment_group.object_type = ? AND comment_group.object_id `+s.sql.Dialect.LikeStr()+` ?))`,
+				commentmodel.ObjectTypeDashboard, filter.DashboardUID, commentmodel.ObjectTypePanel, filter.DashboardUID+".%")
+		}
+		if filter.MentionedUserID > 0 {
+			sb.Write(` AND comment.id IN (SELECT comment_id FROM comment_mention WHERE org_id = ? AND user_id = ?)`, orgID, filter.MentionedUserID)
+		}
+		if filter.BeforeID > 0 {
+			sb.Write(` AND comment.id < ?`, filter.BeforeID)
+		}
+		sb.Write(` ORDER BY comment.id DESC ` + s.sql.Dialect.Limit(int64(limit)))
+
+		if err := dbSession.SQL(sb.GetSQLString(), sb.GetParams()...).Find(&result); err != nil {
+			return err
+		}
+
+		// LIKE treats underscores in the uid as wildcards
+		if filter.DashboardUID != "" {
+			filtered := result[:0]
+			for _, item := range result {
+				if item.ObjectType == commentmodel.ObjectTypeDashboard || strings.HasPrefix(item.ObjectId, filter.DashboardUID+".") {
+					filtered = append(filtered, item)
+				}
+			}
+			result = filtered
+		}
+
+		comments := make([]*commentmodel.Comment, 0, len(result))
+		for _, item := range result {
+			comments = append(comments, &item.Comment)
+		}
+		return loadMentions(dbSession, comments)
 	})
+	return result, err
 }
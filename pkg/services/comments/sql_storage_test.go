@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 
@@ -29,7 +30,7 @@ func TestSqlStorage(t *testing.T) {
 	numComments := 10
 
 	for i := 0; i < numComments; i++ {
-		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "test"+strconv.Itoa(i))
+		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "test"+strconv.Itoa(i), CreateOptions{})
 		require.NoError(t, err)
 		require.NotNil(t, comment)
 		require.True(t, comment.Id > 0)
@@ -74,3 +75,72 @@ func TestSqlStorage(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
+
+func TestSqlStorageThreadsAndMentions(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	s := &sqlStorage{sql: sqlStore}
+	ctx := context.Background()
+
+	alice, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	orgID := alice.OrgId
+	bob, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "bob", Email: "bob@example.com", SkipOrgSetup: true})
+	require.NoError(t, err)
+	err = sqlStore.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: orgID, UserId: bob.Id, Role: models.ROLE_VIEWER})
+	require.NoError(t, err)
+	_, err = sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "carol", Email: "carol@example.com", SkipOrgSetup: true})
+	require.NoError(t, err)
+
+	dashboardComment, err := s.Create(ctx, orgID, commentmodel.ObjectTypeDashboard, "dash", alice.Id, "hi @bob and @carol", CreateOptions{
+		Mentions: []string{"bob", "carol", "unknown"},
+	})
+	require.NoError(t, err)
+	// carol is not a member of the organization
+	require.Equal(t, []int64{bob.Id}, dashboardComment.Mentions)
+
+	t.Run("replies must belong to the same object", func(t *testing.T) {
+		reply, err := s.Create(ctx, orgID, commentmodel.ObjectTypeDashboard, "dash", bob.Id, "thanks", CreateOptions{ParentID: dashboardComment.Id})
+		require.NoError(t, err)
+		require.Equal(t, dashboardComment.Id, reply.ParentId)
+
+		_, err = s.Create(ctx, orgID, commentmodel.ObjectTypeDashboard, "other", bob.Id, "thanks", CreateOptions{ParentID: dashboardComment.Id})
+		require.ErrorIs(t, err, errParentNotFound)
+	})
+
+	_, err = s.Create(ctx, orgID, commentmodel.ObjectTypePanel, commentmodel.PanelObjectID("dash", 2), alice.Id, "panel", CreateOptions{})
+	require.NoError(t, err)
+	_, err = s.Create(ctx, orgID, commentmodel.ObjectTypePanel, commentmodel.PanelObjectID("dash2", 2), alice.Id, "other dashboard", CreateOptions{})
+	require.NoError(t, err)
+
+	t.Run("Get returns mentions", func(t *testing.T) {
+		items, err := s.Get(ctx, orgID, commentmodel.ObjectTypeDashboard, "dash", GetFilter{})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, "hi @bob and @carol", items[1].Content)
+		require.Equal(t, []int64{bob.Id}, items[1].Mentions)
+	})
+
+	t.Run("Activity returns the comments of the org newest first", func(t *testing.T) {
+		items, err := s.Activity(ctx, orgID, ActivityFilter{})
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		require.Equal(t, "other dashboard", items[0].Content)
+		require.Equal(t, commentmodel.ObjectTypePanel, items[0].ObjectType)
+		require.Equal(t, "dash2.2", items[0].ObjectId)
+	})
+
+	t.Run("Activity filters by dashboard including its panels", func(t *testing.T) {
+		items, err := s.Activity(ctx, orgID, ActivityFilter{DashboardUID: "dash"})
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		require.Equal(t, "panel", items[0].Content)
+	})
+
+	t.Run("Activity filters by mentioned user", func(t *testing.T) {
+		items, err := s.Activity(ctx, orgID, ActivityFilter{MentionedUserID: bob.Id})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, dashboardComment.Id, items[0].Id)
+		require.Equal(t, []int64{bob.Id}, items[0].Mentions)
+	})
+}
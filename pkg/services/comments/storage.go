@@ -12,13 +12,34 @@ type GetFilter struct {
 	BeforeID int64
 }
 
+// CreateOptions holds the optional parts of a new comment.
+type CreateOptions struct {
+	// ParentID is the comment that is replied to, it has to belong to the same object.
+	ParentID int64
+	// Mentions are the logins of the mentioned users. Logins of users who are not
+	// members of the organization are ignored.
+	Mentions []string
+}
+
+// ActivityFilter narrows down the comments of an organization returned by Activity.
+type ActivityFilter struct {
+	Limit    uint
+	BeforeID int64
+	// DashboardUID limits the result to the comments on a dashboard and its panels.
+	DashboardUID string
+	// MentionedUserID limits the result to the comments the user was mentioned in.
+	MentionedUserID int64
+}
+
 var (
 	errUnknownObjectType = errors.New("unknown object type")
 	errEmptyObjectID     = errors.New("empty object id")
 	errEmptyContent      = errors.New("empty comment content")
+	errParentNotFound    = errors.New("parent comment not found")
 )
 
 type Storage interface {
 	Get(ctx context.Context, orgID int64, objectType string, objectID string, filter GetFilter) ([]*commentmodel.Comment, error)
-	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error)
+	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string, opts CreateOptions) (*commentmodel.Comment, error)
+	Activity(ctx context.Context, orgID int64, filter ActivityFilter) ([]*commentmodel.ActivityItem, error)
 }
@@ -0,0 +1,13 @@
+package configdrift
+
+import (
+	"context"
+)
+
+// Service reports the instances of a highly available setup whose effective
+// configuration differs from the configuration of the other instances.
+type Service interface {
+	// GetReport compares the configurations the instances stored in the database.
+	// It returns ErrDisabled when the detection is disabled.
+	GetReport(ctx context.Context) (*Report, error)
+}
@@ -0,0 +1,304 @@
+package configdriftimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/configdrift"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "config-drift"
+	// staleIntervals is the number of report intervals after which an instance
+	// that didn't store its configuration is considered stopped.
+	staleIntervals = 3
+	// removeAfter is how long the configurations of stopped instances are kept.
+	removeAfter = 24 * time.Hour
+)
+
+// settingsNamespaces are the namespaces of the key-value store holding the
+// settings changed at runtime, which are hashed with the ini configuration.
+var settingsNamespaces = []string{"feature-toggles", "security-headers", "branding", "preferences"}
+
+// Service stores the hashes of the configuration of each instance in the
+// key-value store, under the instance name.
+type Service struct {
+	cfg      *setting.Cfg
+	kv       kvstore.KVStore
+	features *featuremgmt.FeatureManager
+	log      log.Logger
+	now      func() time.Time
+
+	// differing are the sections of this instance that differed from the
+	// other instances at the last report, to only log changes.
+	differing string
+}
+
+func ProvideService(cfg *setting.Cfg, kv kvstore.KVStore, features *featuremgmt.FeatureManager) *Service {
+	return &Service{
+		cfg:      cfg,
+		kv:       kv,
+		features: features,
+		log:      log.New("configdrift"),
+		now:      time.Now,
+	}
+}
+
+// IsDisabled returns true when the instances don't store their configuration.
+func (s *Service) IsDisabled() bool {
+	return s.cfg.ConfigDrift.ReportInterval <= 0
+}
+
+// Run stores the configuration of the instance on a schedule and logs when it
+// starts or stops differing from the configuration of the other instances.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.ConfigDrift.ReportInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.report(ctx); err != nil {
+			s.log.Error("Failed to report configuration", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) report(ctx context.Context) error {
+	config, err := s.nodeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, 0, kvNamespace, config.Node, string(data)); err != nil {
+		return err
+	}
+
+	report, err := s.GetReport(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range report.Nodes {
+		if !node.Current {
+			continue
+		}
+		differing := strings.Join(node.DifferingSections, ", ")
+		if differing != s.differing {
+			if differing != "" {
+				s.log.Warn("Configuration differs from the other instances", "sections", differing)
+			} else {
+				s.log.Info("Configuration matches the other instances")
+			}
+			s.differing = differing
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetReport(ctx context.Context) (*configdrift.Report, error) {
+	if s.IsDisabled() {
+		return nil, configdrift.ErrDisabled
+	}
+
+	keys, err := s.kv.Keys(ctx, 0, kvNamespace, "")
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	staleBefore := now.Add(-staleIntervals * s.cfg.ConfigDrift.ReportInterval)
+	nodes := make([]*configdrift.NodeStatus, 0, len(keys))
+	for _, key := range keys {
+		data, ok, err := s.kv.Get(ctx, 0, kvNamespace, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		node := &configdrift.NodeStatus{DifferingSections: []string{}}
+		if err := json.Unmarshal([]byte(data), &node.NodeConfig); err != nil {
+			s.log.Warn("Ignoring invalid configuration", "node", key.Key, "error", err)
+			continue
+		}
+		if node.Updated.Before(now.Add(-removeAfter)) {
+			if err := s.kv.Del(ctx, 0, kvNamespace, key.Key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		node.Current = node.Node == setting.InstanceName
+		node.Stale = node.Updated.Before(staleBefore)
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node < nodes[j].Node })
+	return compare(nodes), nil
+}
+
+// compare flags the instances whose configuration differs from the configuration
+// of most instances, and the sections that differ. When there is no majority,
+// all the instances are flagged.
+func compare(nodes []*configdrift.NodeStatus) *configdrift.Report {
+	report := &configdrift.Report{Nodes: nodes}
+	active := make([]*configdrift.NodeStatus, 0, len(nodes))
+	sections := map[string]bool{}
+	for _, node := range nodes {
+		if node.Stale {
+			continue
+		}
+		active = append(active, node)
+		for name := range node.Sections {
+			sections[name] = true
+		}
+	}
+
+	hashes := make([]string, 0, len(active))
+	for _, node := range active {
+		hashes = append(hashes, node.Hash)
+	}
+	report.Hash = majority(hashes)
+	sectionMajority := make(map[string]string, len(sections))
+	for name := range sections {
+		values := make([]string, 0, len(active))
+		for _, node := range active {
+			values = append(values, node.Sections[name])
+		}
+		sectionMajority[name] = majority(values)
+	}
+
+	for _, node := range active {
+		if node.Hash == report.Hash {
+			continue
+		}
+		node.Drift = true
+		report.Drift = true
+		for name := range sections {
+			ref := sectionMajority[name]
+			if ref == "" || node.Sections[name] != ref {
+				node.DifferingSections = append(node.DifferingSections, name)
+			}
+		}
+		sort.Strings(node.DifferingSections)
+	}
+	return report
+}
+
+// majority returns the value shared by most of the values, or an empty string
+// when the most shared values are tied.
+func majority(values []string) string {
+	counts := map[string]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+	result, best, tied := "", 0, false
+	for v, count := range counts {
+		switch {
+		case count > best:
+			result, best, tied = v, count, false
+		case count == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return result
+}
+
+// nodeConfig hashes the ini configuration, the settings stored in the database
+// and the enabled feature toggles, which include the toggles changed at runtime.
+func (s *Service) nodeConfig(ctx context.Context) (*configdrift.NodeConfig, error) {
+	ignored := make(map[string]bool, len(s.cfg.ConfigDrift.IgnoredKeys))
+	for _, key := range s.cfg.ConfigDrift.IgnoredKeys {
+		ignored[key] = true
+	}
+
+	sections := map[string]string{}
+	for _, section := range s.cfg.Raw.Sections() {
+		if ignored[section.Name()] {
+			continue
+		}
+		lines := make([]string, 0, len(section.Keys()))
+		for _, key := range section.Keys() {
+			name := key.Name()
+			if section.Name() != ini.DefaultSection {
+				name = section.Name() + "." + name
+			}
+			if ignored[name] {
+				continue
+			}
+			lines = append(lines, key.Name()+"="+key.Value())
+		}
+		if len(lines) > 0 {
+			sections["ini."+section.Name()] = hashLines(lines)
+		}
+	}
+
+	for _, namespace := range settingsNamespaces {
+		keys, err := s.kv.Keys(ctx, kvstore.AllOrganizations, namespace, "")
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, 0, len(keys))
+		for _, key := range keys {
+			value, ok, err := s.kv.Get(ctx, key.OrgId, namespace, key.Key)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				lines = append(lines, fmt.Sprintf("%d/%s=%s", key.OrgId, key.Key, value))
+			}
+		}
+		sections["db."+namespace] = hashLines(lines)
+	}
+
+	enabled := s.features.GetEnabled(ctx)
+	toggles := make([]string, 0, len(enabled))
+	for name, on := range enabled {
+		if on {
+			toggles = append(toggles, name)
+		}
+	}
+	sections["featureToggles"] = hashLines(toggles)
+
+	names := make([]string, 0, len(sections))
+	for name, hash := range sections {
+		names = append(names, name+"="+hash)
+	}
+	return &configdrift.NodeConfig{
+		Node:     setting.InstanceName,
+		Version:  setting.BuildVersion,
+		Hash:     hashLines(names),
+		Sections: sections,
+		Updated:  s.now(),
+	}, nil
+}
+
+// hashLines returns the hash of the lines, whatever their order.
+func hashLines(lines []string) string {
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
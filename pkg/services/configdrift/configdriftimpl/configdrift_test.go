@@ -0,0 +1,140 @@
+package configdriftimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/configdrift"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCompare(t *testing.T) {
+	node := func(name, hash string, stale bool, sections map[string]string) *configdrift.NodeStatus {
+		return &configdrift.NodeStatus{
+			NodeConfig:        configdrift.NodeConfig{Node: name, Hash: hash, Sections: sections},
+			Stale:             stale,
+			DifferingSections: []string{},
+		}
+	}
+
+	t.Run("flags the instances differing from most instances", func(t *testing.T) {
+		nodes := []*configdrift.NodeStatus{
+			node("a", "1", false, map[string]string{"ini.server": "s1", "featureToggles": "f1"}),
+			node("b", "1", false, map[string]string{"ini.server": "s1", "featureToggles": "f1"}),
+			node("c", "2", false, map[string]string{"ini.server": "s1", "featureToggles": "f2", "ini.smtp": "m1"}),
+			// stopped instances aren't compared
+			node("d", "3", true, map[string]string{"ini.server": "s3"}),
+			node("e", "3", true, map[string]string{"ini.server": "s3"}),
+		}
+
+		report := compare(nodes)
+		require.True(t, report.Drift)
+		require.Equal(t, "1", report.Hash)
+		require.False(t, nodes[0].Drift)
+		require.False(t, nodes[1].Drift)
+		require.True(t, nodes[2].Drift)
+		require.Equal(t, []string{"featureToggles", "ini.smtp"}, nodes[2].DifferingSections)
+		require.False(t, nodes[3].Drift)
+	})
+
+	t.Run("flags all instances without majority", func(t *testing.T) {
+		nodes := []*configdrift.NodeStatus{
+			node("a", "1", false, map[string]string{"ini.server": "s1", "featureToggles": "f1"}),
+			node("b", "2", false, map[string]string{"ini.server": "s1", "featureToggles": "f2"}),
+		}
+
+		report := compare(nodes)
+		require.True(t, report.Drift)
+		require.Empty(t, report.Hash)
+		for _, n := range nodes {
+			require.True(t, n.Drift)
+			require.Equal(t, []string{"featureToggles"}, n.DifferingSections)
+		}
+	})
+
+	t.Run("no drift with a single instance", func(t *testing.T) {
+		report := compare([]*configdrift.NodeStatus{node("a", "1", false, nil)})
+		require.False(t, report.Drift)
+		require.Equal(t, "1", report.Hash)
+	})
+}
+
+func TestIntegrationConfigDrift(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := kvstore.ProvideService(sqlstore.InitTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	newService := func(t *testing.T, name string, config string) *Service {
+		t.Helper()
+		raw, err := ini.Load([]byte(config))
+		require.NoError(t, err)
+		cfg := setting.NewCfg()
+		cfg.Raw = raw
+		cfg.ConfigDrift = setting.ConfigDriftSettings{ReportInterval: time.Minute, IgnoredKeys: []string{"instance_name", "paths.data"}}
+		s := ProvideService(cfg, kv, featuremgmt.WithFeatures())
+		s.now = func() time.Time { return now }
+
+		origName := setting.InstanceName
+		setting.InstanceName = name
+		t.Cleanup(func() { setting.InstanceName = origName })
+		require.NoError(t, s.report(ctx))
+		return s
+	}
+
+	newService(t, "a", "instance_name = a\n[server]\nhttp_port = 3000\n[paths]\ndata = /a\n")
+	newService(t, "b", "instance_name = b\n[server]\nhttp_port = 3000\n[paths]\ndata = /b\n")
+	s := newService(t, "c", "instance_name = c\n[server]\nhttp_port = 3001\n[paths]\ndata = /c\n")
+
+	report, err := s.GetReport(ctx)
+	require.NoError(t, err)
+	require.True(t, report.Drift)
+	require.Len(t, report.Nodes, 3)
+	require.Equal(t, report.Nodes[0].Hash, report.Nodes[1].Hash)
+	require.False(t, report.Nodes[0].Drift)
+	require.True(t, report.Nodes[2].Drift)
+	require.True(t, report.Nodes[2].Current)
+	require.Equal(t, []string{"ini.server"}, report.Nodes[2].DifferingSections)
+	require.Equal(t, "c", report.Nodes[2].Node)
+	require.Equal(t, "ini.server", s.differing)
+
+	t.Run("ignores stale instances and removes stopped instances", func(t *testing.T) {
+		for name, updated := range map[string]time.Time{"a": now.Add(-5 * time.Minute), "b": now.Add(-25 * time.Hour)} {
+			stored, _, err := kv.Get(ctx, 0, kvNamespace, name)
+			require.NoError(t, err)
+			config := configdrift.NodeConfig{}
+			require.NoError(t, json.Unmarshal([]byte(stored), &config))
+			config.Updated = updated
+			data, err := json.Marshal(config)
+			require.NoError(t, err)
+			require.NoError(t, kv.Set(ctx, 0, kvNamespace, name, string(data)))
+		}
+
+		report, err := s.GetReport(ctx)
+		require.NoError(t, err)
+		require.False(t, report.Drift)
+		require.Len(t, report.Nodes, 2)
+		require.True(t, report.Nodes[0].Stale)
+		require.False(t, report.Nodes[1].Drift)
+
+		_, exists, err := kv.Get(ctx, 0, kvNamespace, "b")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("returns an error when disabled", func(t *testing.T) {
+		s.cfg.ConfigDrift.ReportInterval = 0
+		_, err := s.GetReport(ctx)
+		require.ErrorIs(t, err, configdrift.ErrDisabled)
+	})
+}
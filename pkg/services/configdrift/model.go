@@ -0,0 +1,47 @@
+package configdrift
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrDisabled = errors.New("configuration drift detection is disabled")
+
+// NodeConfig holds the hashes of the effective configuration of an instance.
+// Only hashes are stored, the values of the settings are never exposed.
+type NodeConfig struct {
+	// Node is the instance_name of the instance.
+	Node    string `json:"node"`
+	Version string `json:"version"`
+	// Hash is the hash of all the sections.
+	Hash string `json:"hash"`
+	// Sections maps the ini sections, prefixed with "ini.", the namespaces of
+	// the settings stored in the database, prefixed with "db.", and the enabled
+	// feature toggles, as "featureToggles", to their hash.
+	Sections map[string]string `json:"sections"`
+	Updated  time.Time         `json:"updated"`
+}
+
+// NodeStatus is the configuration of an instance compared to the other instances.
+type NodeStatus struct {
+	NodeConfig
+	// Current is true for the instance that handled the request.
+	Current bool `json:"current"`
+	// Stale is true when the instance didn't store its configuration for several
+	// intervals, it is likely stopped. Stale instances aren't compared.
+	Stale bool `json:"stale"`
+	// Drift is true when the configuration differs from the configuration of
+	// most instances.
+	Drift bool `json:"drift"`
+	// DifferingSections are the sections that differ from the configuration of
+	// most instances.
+	DifferingSections []string `json:"differingSections"`
+}
+
+type Report struct {
+	// Drift is true when the configuration of an instance differs.
+	Drift bool `json:"drift"`
+	// Hash is the configuration hash of most instances.
+	Hash  string        `json:"hash"`
+	Nodes []*NodeStatus `json:"nodes"`
+}
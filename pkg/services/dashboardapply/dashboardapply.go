@@ -0,0 +1,14 @@
+package dashboardapply
+
+import (
+	"context"
+)
+
+// Service applies manifests of dashboards managed as code, e.g. from a CI
+// pipeline, and tracks which dashboards belong to which manifest.
+type Service interface {
+	// Apply creates, updates and prunes the dashboards of a manifest.
+	Apply(ctx context.Context, cmd *ApplyCommand) (*ApplyResult, error)
+	// GetManaged returns how a dashboard is managed, or ErrNotManaged.
+	GetManaged(ctx context.Context, orgID int64, dashboardUID string) (*ManagedDashboard, error)
+}
@@ -0,0 +1,231 @@
+package dashboardapplyimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type Service struct {
+	store            store
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(db db.DB, dashboardService dashboards.DashboardService) dashboardapply.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		dashboardService: dashboardService,
+		log:              log.New("dashboardapply"),
+		now:              time.Now,
+	}
+}
+
+func (s *Service) GetManaged(ctx context.Context, orgID int64, dashboardUID string) (*dashboardapply.ManagedDashboard, error) {
+	return s.store.Get(ctx, orgID, dashboardUID)
+}
+
+func (s *Service) Apply(ctx context.Context, cmd *dashboardapply.ApplyCommand) (*dashboardapply.ApplyResult, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	managed, err := s.store.List(ctx, cmd.OrgID, cmd.ManagedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dashboardapply.ApplyResult{
+		ManagedBy:  cmd.ManagedBy,
+		DryRun:     cmd.DryRun,
+		Dashboards: make([]*dashboardapply.DashboardResult, 0, len(cmd.Dashboards)),
+	}
+
+	inManifest := make(map[string]struct{}, len(cmd.Dashboards))
+	for _, d := range cmd.Dashboards {
+		inManifest[d.UID()] = struct{}{}
+		r, err := s.applyDashboard(ctx, cmd, d)
+		if err != nil {
+			s.failed(cmd, r, err)
+		}
+		result.Add(r)
+	}
+
+	for _, m := range managed {
+		if _, ok := inManifest[m.DashboardUid]; ok {
+			continue
+		}
+		r, err := s.prune(ctx, cmd, m)
+		if err != nil {
+			s.failed(cmd, r, err)
+		}
+		result.Add(r)
+	}
+
+	return result, nil
+}
+
+func (s *Service) failed(cmd *dashboardapply.ApplyCommand, r *dashboardapply.DashboardResult, err error) {
+	r.Status = dashboardapply.ApplyStatusFailed
+	r.Error = err.Error()
+	s.log.Warn("Failed to apply dashboard", "orgId", cmd.OrgID, "managedBy", cmd.ManagedBy, "uid", r.UID, "error", err)
+}
+
+func (s *Service) applyDashboard(ctx context.Context, cmd *dashboardapply.ApplyCommand, d dashboardapply.ManifestDashboard) (*dashboardapply.DashboardResult, error) {
+	r := &dashboardapply.DashboardResult{
+		UID:   d.UID(),
+		Title: d.Dashboard.Get("title").MustString(),
+	}
+
+	checksum, err := d.Checksum()
+	if err != nil {
+		return r, err
+	}
+
+	stored, err := s.getDashboard(ctx, cmd.OrgID, r.UID)
+	if err != nil {
+		return r, err
+	}
+	existing, err := s.store.Get(ctx, cmd.OrgID, r.UID)
+	if err != nil && !errors.Is(err, dashboardapply.ErrNotManaged) {
+		return r, err
+	}
+
+	// a record of a dashboard that no longer exists is stale and doesn't prevent other manifests from creating it
+	if stored != nil && existing != nil {
+		if existing.ManagedBy != cmd.ManagedBy {
+			return r, fmt.Errorf("%w: %s", dashboardapply.ErrManagedByOther, existing.ManagedBy)
+		}
+		if stored.Version != existing.AppliedVersion {
+			r.Drift = &dashboardapply.Drift{AppliedVersion: existing.AppliedVersion, StoredVersion: stored.Version}
+		} else if existing.Checksum == checksum {
+			r.Status = dashboardapply.ApplyStatusUnchanged
+			r.Version = stored.Version
+			return r, nil
+		}
+	}
+
+	r.Status = dashboardapply.ApplyStatusCreated
+	if stored != nil {
+		r.Status = dashboardapply.ApplyStatusUpdated
+		r.Version = stored.Version
+	}
+	if cmd.DryRun {
+		return r, nil
+	}
+
+	folderID, err := s.getFolderID(ctx, cmd.OrgID, d.FolderUID)
+	if err != nil {
+		return r, err
+	}
+
+	dash := models.NewDashboardFromJson(d.Dashboard)
+	dash.FolderId = folderID
+	if stored != nil {
+		dash.SetId(stored.Id)
+	} else {
+		dash.SetId(0)
+	}
+
+	saved, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     cmd.OrgID,
+		User:      cmd.User,
+		Dashboard: dash,
+		Message:   fmt.Sprintf("Applied by %s", cmd.ManagedBy),
+		Overwrite: true,
+	}, false)
+	if err != nil {
+		return r, err
+	}
+	r.Title = saved.Title
+	r.Version = saved.Version
+
+	now := s.now()
+	err = s.store.Upsert(ctx, &dashboardapply.ManagedDashboard{
+		OrgId:          cmd.OrgID,
+		DashboardUid:   saved.Uid,
+		ManagedBy:      cmd.ManagedBy,
+		AppliedBy:      cmd.User.UserId,
+		AppliedVersion: saved.Version,
+		Checksum:       checksum,
+		Created:        now,
+		Updated:        now,
+	})
+	return r, err
+}
+
+// prune deletes a dashboard that was removed from the manifest.
+func (s *Service) prune(ctx context.Context, cmd *dashboardapply.ApplyCommand, m *dashboardapply.ManagedDashboard) (*dashboardapply.DashboardResult, error) {
+	r := &dashboardapply.DashboardResult{
+		UID:    m.DashboardUid,
+		Status: dashboardapply.ApplyStatusPruned,
+	}
+
+	stored, err := s.getDashboard(ctx, cmd.OrgID, m.DashboardUid)
+	if err != nil {
+		return r, err
+	}
+	if stored != nil {
+		r.Title = stored.Title
+		r.Version = stored.Version
+		if stored.Version != m.AppliedVersion {
+			r.Drift = &dashboardapply.Drift{AppliedVersion: m.AppliedVersion, StoredVersion: stored.Version}
+		}
+
+		g := guardian.New(ctx, stored.Id, cmd.OrgID, cmd.User)
+		canDelete, err := g.CanDelete()
+		if err != nil {
+			return r, err
+		}
+		if !canDelete {
+			return r, dashboardapply.ErrPruneDenied
+		}
+	}
+	if cmd.DryRun {
+		return r, nil
+	}
+
+	if stored != nil {
+		if err := s.dashboardService.DeleteDashboard(ctx, stored.Id, cmd.OrgID); err != nil {
+			return r, err
+		}
+	}
+	return r, s.store.Delete(ctx, cmd.OrgID, m.DashboardUid)
+}
+
+// getDashboard returns the dashboard with the uid, or nil if it doesn't exist.
+func (s *Service) getDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *Service) getFolderID(ctx context.Context, orgID int64, folderUID string) (int64, error) {
+	if folderUID == "" {
+		return 0, nil
+	}
+	folder, err := s.getDashboard(ctx, orgID, folderUID)
+	if err != nil {
+		return 0, err
+	}
+	if folder == nil || !folder.IsFolder {
+		return 0, models.ErrFolderNotFound
+	}
+	return folder.Id, nil
+}
@@ -0,0 +1,72 @@
+package dashboardapplyimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, dashboardUID string) (*dashboardapply.ManagedDashboard, error)
+	List(ctx context.Context, orgID int64, managedBy string) ([]*dashboardapply.ManagedDashboard, error)
+	Upsert(ctx context.Context, m *dashboardapply.ManagedDashboard) error
+	Delete(ctx context.Context, orgID int64, dashboardUID string) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, dashboardUID string) (*dashboardapply.ManagedDashboard, error) {
+	m := &dashboardapply.ManagedDashboard{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND dashboard_uid=?", orgID, dashboardUID).Get(m)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return dashboardapply.ErrNotManaged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64, managedBy string) ([]*dashboardapply.ManagedDashboard, error) {
+	result := make([]*dashboardapply.ManagedDashboard, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=? AND managed_by=?", orgID, managedBy).Asc("dashboard_uid").Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) Upsert(ctx context.Context, m *dashboardapply.ManagedDashboard) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := &dashboardapply.ManagedDashboard{}
+		exists, err := sess.Where("org_id=? AND dashboard_uid=?", m.OrgId, m.DashboardUid).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(m)
+			return err
+		}
+
+		m.Id = existing.Id
+		m.Created = existing.Created
+		_, err = sess.ID(m.Id).Cols("managed_by", "applied_by", "applied_version", "checksum", "updated").Update(m)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Where("org_id=? AND dashboard_uid=?", orgID, dashboardUID).Delete(&dashboardapply.ManagedDashboard{})
+		return err
+	})
+}
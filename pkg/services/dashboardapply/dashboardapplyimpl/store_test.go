@@ -0,0 +1,70 @@
+package dashboardapplyimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboardapply"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDashboardApplyStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	store := &sqlStore{db: ss}
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	managed := func(uid, managedBy string, version int) *dashboardapply.ManagedDashboard {
+		return &dashboardapply.ManagedDashboard{
+			OrgId:          1,
+			DashboardUid:   uid,
+			ManagedBy:      managedBy,
+			AppliedBy:      1,
+			AppliedVersion: version,
+			Checksum:       "checksum",
+			Created:        now,
+			Updated:        now,
+		}
+	}
+
+	t.Run("get returns ErrNotManaged for unknown dashboards", func(t *testing.T) {
+		_, err := store.Get(ctx, 1, "missing")
+		require.ErrorIs(t, err, dashboardapply.ErrNotManaged)
+	})
+
+	t.Run("upsert inserts and updates", func(t *testing.T) {
+		require.NoError(t, store.Upsert(ctx, managed("a", "repo", 1)))
+		require.NoError(t, store.Upsert(ctx, managed("b", "repo", 1)))
+		require.NoError(t, store.Upsert(ctx, managed("c", "other", 1)))
+		require.NoError(t, store.Upsert(ctx, managed("a", "repo", 2)))
+
+		m, err := store.Get(ctx, 1, "a")
+		require.NoError(t, err)
+		require.Equal(t, 2, m.AppliedVersion)
+		require.Equal(t, "repo", m.ManagedBy)
+	})
+
+	t.Run("list returns the dashboards of a manifest", func(t *testing.T) {
+		list, err := store.List(ctx, 1, "repo")
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		require.Equal(t, "a", list[0].DashboardUid)
+		require.Equal(t, "b", list[1].DashboardUid)
+
+		list, err = store.List(ctx, 2, "repo")
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+
+	t.Run("delete removes the record", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, 1, "b"))
+		_, err := store.Get(ctx, 1, "b")
+		require.ErrorIs(t, err, dashboardapply.ErrNotManaged)
+	})
+}
@@ -0,0 +1,185 @@
+package dashboardapply
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrManagedByRequired   = errors.New("managedBy is required")
+	ErrManagedByTooLong    = errors.New("managedBy must be at most 100 characters")
+	ErrDashboardMissing    = errors.New("every manifest entry needs a dashboard")
+	ErrDashboardUIDMissing = errors.New("every dashboard of the manifest needs a uid")
+	ErrDuplicateUID        = errors.New("dashboard uid is used more than once in the manifest")
+	ErrNotManaged          = errors.New("dashboard is not managed as code")
+	ErrManagedByOther      = errors.New("dashboard is managed by another manifest")
+	ErrDashboardManaged    = errors.New("dashboard is managed as code and can only be changed by applying its manifest")
+	ErrPruneDenied         = errors.New("not allowed to delete the dashboard removed from the manifest")
+)
+
+// MaxManagedByLength is the maximum length of the name of the manifest managing dashboards.
+const MaxManagedByLength = 100
+
+// ManagedDashboard records that a dashboard is managed as code and the
+// version that was last applied, to detect changes made outside of applies.
+type ManagedDashboard struct {
+	Id           int64  `json:"-"`
+	OrgId        int64  `json:"-"`
+	DashboardUid string `json:"dashboardUid"`
+	ManagedBy    string `json:"managedBy"`
+	// AppliedBy is the user or service account that applied the dashboard, the
+	// only one that may change it outside of applies.
+	AppliedBy      int64     `json:"appliedBy"`
+	AppliedVersion int       `json:"appliedVersion"`
+	Checksum       string    `json:"checksum"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+}
+
+func (m ManagedDashboard) TableName() string {
+	return "dashboard_managed"
+}
+
+// IsOwner returns true if the user may change the dashboard outside of applies.
+func (m ManagedDashboard) IsOwner(user *models.SignedInUser) bool {
+	return user != nil && user.UserId != 0 && user.UserId == m.AppliedBy
+}
+
+type ApplyStatus string
+
+const (
+	ApplyStatusCreated   ApplyStatus = "created"
+	ApplyStatusUpdated   ApplyStatus = "updated"
+	ApplyStatusUnchanged ApplyStatus = "unchanged"
+	ApplyStatusPruned    ApplyStatus = "pruned"
+	ApplyStatusFailed    ApplyStatus = "failed"
+)
+
+// Drift describes a dashboard that was changed since it was last applied.
+type Drift struct {
+	AppliedVersion int `json:"appliedVersion"`
+	StoredVersion  int `json:"storedVersion"`
+}
+
+type DashboardResult struct {
+	UID     string      `json:"uid"`
+	Title   string      `json:"title"`
+	Status  ApplyStatus `json:"status"`
+	Version int         `json:"version,omitempty"`
+	// Drift is set when the stored dashboard was changed outside of applies,
+	// those changes are overwritten by the apply.
+	Drift *Drift `json:"drift,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type ApplyResult struct {
+	ManagedBy  string             `json:"managedBy"`
+	DryRun     bool               `json:"dryRun"`
+	Created    int                `json:"created"`
+	Updated    int                `json:"updated"`
+	Unchanged  int                `json:"unchanged"`
+	Pruned     int                `json:"pruned"`
+	Failed     int                `json:"failed"`
+	Drifted    int                `json:"drifted"`
+	Dashboards []*DashboardResult `json:"dashboards"`
+}
+
+// Add adds the result of a dashboard to the totals.
+func (r *ApplyResult) Add(d *DashboardResult) {
+	switch d.Status {
+	case ApplyStatusCreated:
+		r.Created++
+	case ApplyStatusUpdated:
+		r.Updated++
+	case ApplyStatusUnchanged:
+		r.Unchanged++
+	case ApplyStatusPruned:
+		r.Pruned++
+	case ApplyStatusFailed:
+		r.Failed++
+	}
+	if d.Drift != nil {
+		r.Drifted++
+	}
+	r.Dashboards = append(r.Dashboards, d)
+}
+
+// ManifestDashboard is a dashboard of a manifest.
+type ManifestDashboard struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	FolderUID string           `json:"folderUid"`
+}
+
+// UID returns the uid of the dashboard.
+func (d ManifestDashboard) UID() string {
+	if d.Dashboard == nil {
+		return ""
+	}
+	return d.Dashboard.Get("uid").MustString()
+}
+
+// Checksum returns a checksum of the dashboard and its folder. The id and the
+// version are ignored, as they are assigned by Grafana.
+func (d ManifestDashboard) Checksum() (string, error) {
+	data := map[string]interface{}{}
+	for k, v := range d.Dashboard.MustMap() {
+		if k == "id" || k == "version" {
+			continue
+		}
+		data[k] = v
+	}
+	// encoding/json sorts map keys, so equal dashboards have equal checksums
+	b, err := json.Marshal(map[string]interface{}{"dashboard": data, "folderUid": d.FolderUID})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ----------------------
+// COMMANDS
+
+// ApplyCommand applies a manifest of dashboards. Dashboards of the manifest
+// are created or updated, dashboards that were applied with the same managedBy
+// before but are no longer part of the manifest are deleted.
+type ApplyCommand struct {
+	OrgID int64                `json:"-"`
+	User  *models.SignedInUser `json:"-"`
+
+	ManagedBy  string              `json:"managedBy"`
+	Dashboards []ManifestDashboard `json:"dashboards"`
+	// DryRun reports what would change without changing anything.
+	DryRun bool `json:"dryRun"`
+}
+
+func (cmd *ApplyCommand) Validate() error {
+	if cmd.ManagedBy == "" {
+		return ErrManagedByRequired
+	}
+	if len(cmd.ManagedBy) > MaxManagedByLength {
+		return ErrManagedByTooLong
+	}
+	uids := make(map[string]struct{}, len(cmd.Dashboards))
+	for _, d := range cmd.Dashboards {
+		if d.Dashboard == nil {
+			return ErrDashboardMissing
+		}
+		uid := d.UID()
+		if uid == "" {
+			return ErrDashboardUIDMissing
+		}
+		if _, ok := uids[uid]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateUID, uid)
+		}
+		uids[uid] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package dashboardapply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestApplyCommand_Validate(t *testing.T) {
+	dashboard := func(uid string) ManifestDashboard {
+		return ManifestDashboard{Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": uid, "title": uid})}
+	}
+
+	tcs := []struct {
+		desc        string
+		cmd         ApplyCommand
+		expectedErr error
+	}{
+		{desc: "valid", cmd: ApplyCommand{ManagedBy: "repo", Dashboards: []ManifestDashboard{dashboard("a"), dashboard("b")}}},
+		{desc: "empty manifest prunes everything", cmd: ApplyCommand{ManagedBy: "repo"}},
+		{desc: "missing managedBy", cmd: ApplyCommand{Dashboards: []ManifestDashboard{dashboard("a")}}, expectedErr: ErrManagedByRequired},
+		{desc: "missing dashboard", cmd: ApplyCommand{ManagedBy: "repo", Dashboards: []ManifestDashboard{{FolderUID: "f"}}}, expectedErr: ErrDashboardMissing},
+		{desc: "missing uid", cmd: ApplyCommand{ManagedBy: "repo", Dashboards: []ManifestDashboard{dashboard("")}}, expectedErr: ErrDashboardUIDMissing},
+		{desc: "duplicate uid", cmd: ApplyCommand{ManagedBy: "repo", Dashboards: []ManifestDashboard{dashboard("a"), dashboard("a")}}, expectedErr: ErrDuplicateUID},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.cmd.Validate()
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestManifestDashboard_Checksum(t *testing.T) {
+	checksum := func(data string, folderUID string) string {
+		t.Helper()
+		json, err := simplejson.NewJson([]byte(data))
+		require.NoError(t, err)
+		sum, err := ManifestDashboard{Dashboard: json, FolderUID: folderUID}.Checksum()
+		require.NoError(t, err)
+		return sum
+	}
+
+	base := checksum(`{"uid": "a", "title": "A", "panels": [{"id": 1}]}`, "")
+	require.Equal(t, base, checksum(`{"panels": [{"id": 1}], "title": "A", "uid": "a"}`, ""), "key order is ignored")
+	require.Equal(t, base, checksum(`{"id": 12, "version": 3, "uid": "a", "title": "A", "panels": [{"id": 1}]}`, ""), "id and version are ignored")
+	require.NotEqual(t, base, checksum(`{"uid": "a", "title": "B", "panels": [{"id": 1}]}`, ""))
+	require.NotEqual(t, base, checksum(`{"uid": "a", "title": "A", "panels": [{"id": 1}]}`, "folder"))
+}
+
+func TestManagedDashboard_IsOwner(t *testing.T) {
+	m := ManagedDashboard{AppliedBy: 10}
+	require.True(t, m.IsOwner(&models.SignedInUser{UserId: 10}))
+	require.False(t, m.IsOwner(&models.SignedInUser{UserId: 11}))
+	require.False(t, m.IsOwner(nil))
+	require.False(t, ManagedDashboard{}.IsOwner(&models.SignedInUser{}))
+}
@@ -0,0 +1,28 @@
+package dashboardarchive
+
+import (
+	"context"
+)
+
+// Service moves dashboards and folders out of the dashboards of an
+// organization, to the archive where they can be restored from. The bodies of
+// the archived dashboards can be offloaded to an object storage.
+//
+// The deleted dashboards are kept in the archive as well, in the trash, until
+// the retention period of the trash expires.
+type Service interface {
+	// Archive archives a dashboard, or a folder with its subfolders and
+	// dashboards, and returns the archived dashboards and folders.
+	Archive(ctx context.Context, cmd *ArchiveCommand) ([]*ArchivedDashboard, error)
+	// Restore restores an archived dashboard, or an archived folder with its
+	// archived subfolders and dashboards, and returns the restored ones.
+	Restore(ctx context.Context, cmd *RestoreCommand) ([]*ArchivedDashboard, error)
+	Search(ctx context.Context, query *SearchQuery) ([]*ArchivedDashboard, error)
+
+	// ArchiveUnused archives the dashboards that haven't been used for the
+	// configured number of days, and returns the number of archived dashboards.
+	ArchiveUnused(ctx context.Context) (int64, error)
+	// PurgeTrash permanently deletes the dashboards that have been in the trash
+	// for longer than the retention period, and returns their number.
+	PurgeTrash(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,464 @@
+package dashboardarchiveimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	// unusedBatchSize is the number of unused dashboards archived per organization
+	// each time the unused dashboards are archived.
+	unusedBatchSize = 100
+	// purgeBatchSize is the number of expired dashboards loaded at once when the
+	// trash is purged.
+	purgeBatchSize = 100
+)
+
+type Service struct {
+	cfg              *setting.Cfg
+	store            store
+	dashboardService dashboards.DashboardService
+	folderService    dashboards.FolderService
+	usage            dashboardusage.Service
+	// bodies is nil when the bodies of archived dashboards are kept in the database.
+	bodies bodystorage.Backend
+	log    log.Logger
+	now    func() time.Time
+}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, dashboardService dashboards.DashboardService,
+	folderService dashboards.FolderService, usage dashboardusage.Service) dashboardarchive.Service {
+	return &Service{
+		cfg: cfg,
+		store: &sqlStore{
+			db: db,
+		},
+		dashboardService: dashboardService,
+		folderService:    folderService,
+		usage:            usage,
+		bodies:           bodystorage.New(cfg.DashboardArchive.Storage),
+		log:              log.New("dashboardarchive"),
+		now:              time.Now,
+	}
+}
+
+func (s *Service) Archive(ctx context.Context, cmd *dashboardarchive.ArchiveCommand) ([]*dashboardarchive.ArchivedDashboard, error) {
+	query := &models.GetDashboardQuery{OrgId: cmd.OrgID, Uid: cmd.UID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	root := query.Result
+
+	if cmd.User != nil {
+		canDelete, err := guardian.New(ctx, root.Id, cmd.OrgID, cmd.User).CanDelete()
+		if err != nil {
+			return nil, err
+		}
+		if !canDelete {
+			return nil, dashboardarchive.ErrAccessDenied
+		}
+	}
+
+	parentUID := ""
+	if root.FolderId != 0 {
+		parentQuery := &models.GetDashboardRefByIdQuery{Id: root.FolderId}
+		if err := s.dashboardService.GetDashboardUIDById(ctx, parentQuery); err != nil {
+			return nil, err
+		}
+		parentUID = parentQuery.Result.Uid
+	}
+
+	// the dashboards are archived before the folders containing them, and
+	// the subfolders before their parents
+	refs := []dashboardRef{{ID: root.Id, UID: root.Uid, FolderID: root.FolderId}}
+	folderUIDs := map[int64]string{root.FolderId: parentUID}
+	if root.IsFolder {
+		folders, dashboards, err := s.store.FolderContents(ctx, cmd.OrgID, root.Id)
+		if err != nil {
+			return nil, err
+		}
+		folderUIDs[root.Id] = root.Uid
+		for _, f := range folders {
+			folderUIDs[f.ID] = f.UID
+		}
+		contents := dashboards
+		for i := len(folders) - 1; i >= 0; i-- {
+			contents = append(contents, folders[i])
+		}
+		refs = append(contents, refs...)
+	}
+
+	superseded, err := s.checkArchivable(ctx, cmd.OrgID, refs, root.IsFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]*dashboardarchive.ArchivedDashboard, 0, len(refs))
+	for _, ref := range refs {
+		dash := root
+		if ref.ID != root.Id {
+			query := &models.GetDashboardQuery{OrgId: cmd.OrgID, Uid: ref.UID}
+			if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+				return archived, err
+			}
+			dash = query.Result
+		}
+
+		a, err := s.archiveDashboard(ctx, cmd, dash, folderUIDs[dash.FolderId], superseded[dash.Uid])
+		if err != nil {
+			return archived, err
+		}
+		archived = append(archived, a)
+	}
+	return archived, nil
+}
+
+// checkArchivable fails when the dashboards and folders can't be deleted, so
+// that folders are either archived with all their content or not at all. It
+// returns the dashboards in the trash with the same uids, which are replaced,
+// by uid.
+func (s *Service) checkArchivable(ctx context.Context, orgID int64, refs []dashboardRef, isFolder bool) (map[string]*dashboardarchive.ArchivedDashboard, error) {
+	ids := make([]int64, 0, len(refs))
+	uids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+		uids = append(uids, ref.UID)
+	}
+
+	archivedUIDs, err := s.store.ArchivedUIDs(ctx, orgID, uids)
+	if err != nil {
+		return nil, err
+	}
+	superseded := make(map[string]*dashboardarchive.ArchivedDashboard, len(archivedUIDs))
+	for _, uid := range archivedUIDs {
+		a, err := s.store.Get(ctx, orgID, uid)
+		if err != nil {
+			return nil, err
+		}
+		if !a.IsTrashed() {
+			return nil, fmt.Errorf("%w: %s", dashboardarchive.ErrAlreadyArchived, uid)
+		}
+		superseded[uid] = a
+	}
+
+	provisioned, err := s.store.HasProvisionedDashboards(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if provisioned {
+		return nil, models.ErrDashboardCannotDeleteProvisionedDashboard
+	}
+
+	if isFolder {
+		hasRules, err := s.store.HasAlertRules(ctx, orgID, uids)
+		if err != nil {
+			return nil, err
+		}
+		if hasRules {
+			return nil, models.ErrFolderContainsAlertRules
+		}
+	}
+	return superseded, nil
+}
+
+// archiveDashboard adds the dashboard to the archive and then deletes it. The
+// dashboard is removed from the archive when it can't be deleted. The
+// superseded dashboard in the trash, if any, is replaced only once the
+// dashboard is deleted, and kept otherwise.
+func (s *Service) archiveDashboard(ctx context.Context, cmd *dashboardarchive.ArchiveCommand, dash *models.Dashboard, folderUID string,
+	superseded *dashboardarchive.ArchivedDashboard) (*dashboardarchive.ArchivedDashboard, error) {
+	body, err := dash.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &dashboardarchive.ArchivedDashboard{
+		OrgID:     cmd.OrgID,
+		UID:       dash.Uid,
+		Title:     dash.Title,
+		IsFolder:  dash.IsFolder,
+		FolderUID: folderUID,
+		Reason:    cmd.Reason,
+		Archived:  s.now(),
+	}
+	if a.Reason == "" {
+		a.Reason = dashboardarchive.ReasonManual
+	}
+	if cmd.User != nil {
+		a.ArchivedBy = cmd.User.UserId
+	}
+
+	// the offloaded body of the superseded dashboard is overwritten, it's
+	// kept to be put back when the dashboard can't be archived
+	var supersededBody []byte
+	if superseded != nil && superseded.Offloaded && s.bodies != nil {
+		supersededBody, err = s.loadBody(ctx, superseded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the body of the dashboard in the trash: %w", err)
+		}
+	}
+
+	if s.bodies != nil {
+		if err := s.bodies.Put(ctx, bodystorage.Key(cmd.OrgID, dash.Uid), body); err != nil {
+			return nil, fmt.Errorf("failed to offload the body of the dashboard: %w", err)
+		}
+		a.Offloaded = true
+	} else {
+		a.Data = string(body)
+	}
+
+	if superseded == nil {
+		err = s.store.Insert(ctx, a)
+	} else {
+		err = s.store.Replace(ctx, superseded.ID, a)
+	}
+	if err != nil {
+		s.restoreSuperseded(ctx, a, superseded, supersededBody)
+		return nil, err
+	}
+	if err := s.dashboardService.DeleteDashboard(ctx, dash.Id, cmd.OrgID); err != nil {
+		if superseded == nil {
+			s.remove(ctx, a)
+			return nil, err
+		}
+		// the superseded dashboard gets a new id when it's added back
+		superseded.ID = 0
+		if err := s.store.Replace(ctx, a.ID, superseded); err != nil {
+			s.log.Error("Failed to put dashboard back in the trash", "orgId", cmd.OrgID, "uid", dash.Uid, "error", err)
+		}
+		s.restoreSuperseded(ctx, a, superseded, supersededBody)
+		return nil, err
+	}
+
+	s.log.Info("Archived dashboard", "orgId", cmd.OrgID, "uid", dash.Uid, "isFolder", dash.IsFolder, "reason", a.Reason, "offloaded", a.Offloaded)
+	return a, nil
+}
+
+// restoreSuperseded puts back the offloaded body of the superseded dashboard
+// in the trash, or deletes the offloaded body of the dashboard when none is
+// superseded, after the dashboard failed to be archived.
+func (s *Service) restoreSuperseded(ctx context.Context, a, superseded *dashboardarchive.ArchivedDashboard, supersededBody []byte) {
+	if supersededBody == nil {
+		if superseded == nil || !superseded.Offloaded {
+			s.deleteBody(ctx, a)
+		}
+		return
+	}
+	if err := s.bodies.Put(ctx, bodystorage.Key(superseded.OrgID, superseded.UID), supersededBody); err != nil {
+		s.log.Error("Failed to put back the offloaded body of dashboard in the trash", "orgId", superseded.OrgID, "uid", superseded.UID, "error", err)
+	}
+}
+
+func (s *Service) Restore(ctx context.Context, cmd *dashboardarchive.RestoreCommand) ([]*dashboardarchive.ArchivedDashboard, error) {
+	a, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if a.IsTrashed() != cmd.Trashed {
+		return nil, dashboardarchive.ErrArchiveNotFound
+	}
+
+	restored := make([]*dashboardarchive.ArchivedDashboard, 0)
+	return restored, s.restore(ctx, cmd, a, &restored)
+}
+
+func (s *Service) restore(ctx context.Context, cmd *dashboardarchive.RestoreCommand, a *dashboardarchive.ArchivedDashboard, restored *[]*dashboardarchive.ArchivedDashboard) error {
+	folderID, folderUID, err := s.restoreFolder(ctx, cmd, a.FolderUID)
+	if err != nil {
+		return err
+	}
+
+	if a.IsFolder {
+		if _, err := s.folderService.CreateFolder(ctx, cmd.User, cmd.OrgID, a.Title, a.UID, folderUID); err != nil {
+			return err
+		}
+	} else {
+		body, err := s.loadBody(ctx, a)
+		if err != nil {
+			return err
+		}
+		data, err := simplejson.NewJson(body)
+		if err != nil {
+			return err
+		}
+		data.Del("id")
+		dash := models.NewDashboardFromJson(data)
+		dash.OrgId = cmd.OrgID
+		dash.FolderId = folderID
+		message := "Restored from the archive"
+		if a.IsTrashed() {
+			message = "Restored from the trash"
+		}
+		if _, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+			Dashboard: dash,
+			OrgId:     cmd.OrgID,
+			User:      cmd.User,
+			Message:   message,
+		}, false); err != nil {
+			return err
+		}
+	}
+
+	s.remove(ctx, a)
+	*restored = append(*restored, a)
+	s.log.Info("Restored dashboard", "orgId", cmd.OrgID, "uid", a.UID, "isFolder", a.IsFolder)
+
+	if !a.IsFolder {
+		return nil
+	}
+	contents, err := s.store.Search(ctx, &dashboardarchive.SearchQuery{OrgID: cmd.OrgID, FolderUID: a.UID, Trashed: a.IsTrashed()})
+	if err != nil {
+		return err
+	}
+	for _, content := range contents {
+		// the search doesn't return the bodies
+		c, err := s.store.Get(ctx, cmd.OrgID, content.UID)
+		if err != nil {
+			return err
+		}
+		if err := s.restore(ctx, cmd, c, restored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreFolder returns the id and uid of the folder the dashboard is restored
+// in, the General folder when its folder no longer exists.
+func (s *Service) restoreFolder(ctx context.Context, cmd *dashboardarchive.RestoreCommand, folderUID string) (int64, string, error) {
+	if folderUID == "" {
+		return 0, "", nil
+	}
+
+	_, err := s.store.Get(ctx, cmd.OrgID, folderUID)
+	if err == nil {
+		return 0, "", dashboardarchive.ErrFolderArchived
+	}
+	if !errors.Is(err, dashboardarchive.ErrArchiveNotFound) {
+		return 0, "", err
+	}
+
+	folder, err := s.folderService.GetFolderByUID(ctx, cmd.User, cmd.OrgID, folderUID)
+	if err != nil {
+		if errors.Is(err, models.ErrFolderNotFound) {
+			return 0, "", nil
+		}
+		return 0, "", err
+	}
+	return folder.Id, folder.Uid, nil
+}
+
+func (s *Service) loadBody(ctx context.Context, a *dashboardarchive.ArchivedDashboard) ([]byte, error) {
+	if !a.Offloaded {
+		return []byte(a.Data), nil
+	}
+	if s.bodies == nil {
+		return nil, fmt.Errorf("the body of dashboard %s is offloaded, but no archive storage is configured", a.UID)
+	}
+	return s.bodies.Get(ctx, bodystorage.Key(a.OrgID, a.UID))
+}
+
+// remove deletes the dashboard from the archive.
+func (s *Service) remove(ctx context.Context, a *dashboardarchive.ArchivedDashboard) {
+	if err := s.store.Delete(ctx, a.ID); err != nil {
+		s.log.Error("Failed to remove dashboard from the archive", "orgId", a.OrgID, "uid", a.UID, "error", err)
+		return
+	}
+	s.deleteBody(ctx, a)
+}
+
+func (s *Service) deleteBody(ctx context.Context, a *dashboardarchive.ArchivedDashboard) {
+	if !a.Offloaded || s.bodies == nil {
+		return
+	}
+	if err := s.bodies.Delete(ctx, bodystorage.Key(a.OrgID, a.UID)); err != nil {
+		s.log.Warn("Failed to delete the offloaded body of archived dashboard", "orgId", a.OrgID, "uid", a.UID, "error", err)
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *dashboardarchive.SearchQuery) ([]*dashboardarchive.ArchivedDashboard, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) ArchiveUnused(ctx context.Context) (int64, error) {
+	days := s.cfg.DashboardArchive.UnusedDays
+	if days <= 0 {
+		return 0, nil
+	}
+
+	orgIDs, err := s.store.OrgIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	since := s.now().AddDate(0, 0, -days)
+
+	var count int64
+	for _, orgID := range orgIDs {
+		unused, err := s.usage.GetUnused(ctx, &dashboardusage.GetUnusedQuery{
+			OrgID:                 orgID,
+			Since:                 since,
+			Limit:                 unusedBatchSize,
+			ExcludeProvisioned:    true,
+			ExcludeHomeDashboards: true,
+		})
+		if err != nil {
+			return count, err
+		}
+		for _, dash := range unused {
+			_, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: orgID, UID: dash.UID, Reason: dashboardarchive.ReasonUnused})
+			if err != nil {
+				s.log.Warn("Failed to archive unused dashboard", "orgId", orgID, "uid", dash.UID, "error", err)
+				continue
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Service) PurgeTrash(ctx context.Context) (int64, error) {
+	days := s.cfg.DashboardTrash.RetentionDays
+	if days <= 0 {
+		return 0, nil
+	}
+	before := s.now().AddDate(0, 0, -days)
+
+	var count int64
+	for {
+		expired, err := s.store.TrashedBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, a := range expired {
+			if err := s.store.Delete(ctx, a.ID); err != nil {
+				return count, err
+			}
+			s.deleteBody(ctx, a)
+			count++
+		}
+		if len(expired) < purgeBatchSize {
+			return count, nil
+		}
+	}
+}
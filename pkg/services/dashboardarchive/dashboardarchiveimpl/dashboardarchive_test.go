@@ -0,0 +1,339 @@
+package dashboardarchiveimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeUsage struct {
+	dashboardusage.Service
+	unused []*dashboardusage.UnusedDashboard
+	query  *dashboardusage.GetUnusedQuery
+}
+
+func (f *fakeUsage) GetUnused(_ context.Context, query *dashboardusage.GetUnusedQuery) ([]*dashboardusage.UnusedDashboard, error) {
+	f.query = query
+	return f.unused, nil
+}
+
+type failingDelete struct {
+	dashboards.DashboardService
+}
+
+func (failingDelete) DeleteDashboard(context.Context, int64, int64) error {
+	return errors.New("delete failed")
+}
+
+func TestIntegrationDashboardArchive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	fakeGuardian := &guardian.FakeDashboardGuardian{CanSaveValue: true, CanViewValue: true, CanEditValue: true}
+	guardian.MockDashboardGuardian(fakeGuardian)
+
+	cfg := setting.NewCfg()
+	cfg.IsFeatureToggleEnabled = featuremgmt.WithFeatures().IsEnabled
+	features := featuremgmt.WithFeatures()
+	ac := acmock.New()
+	dashboardStore := database.ProvideDashboardStore(ss)
+	dashboardService := dashboardservice.ProvideDashboardService(cfg, dashboardStore, alerting.ProvideDashAlertExtractorService(nil, nil, nil), features,
+		acmock.NewMockedPermissionsService(), acmock.NewMockedPermissionsService(), ac)
+	folderService := dashboardservice.ProvideFolderService(cfg, dashboardService, dashboardStore, nil, features,
+		acmock.NewMockedPermissionsService(), ac, ss)
+
+	usage := &fakeUsage{}
+	now := time.Now().Truncate(time.Second)
+	s := &Service{
+		cfg:              cfg,
+		store:            &sqlStore{db: ss},
+		dashboardService: dashboardService,
+		folderService:    folderService,
+		usage:            usage,
+		log:              log.New("test"),
+		now:              func() time.Time { return now },
+	}
+
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+	saveDashboard := func(t *testing.T, uid string, folderID int64) *models.Dashboard {
+		t.Helper()
+		dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+			"uid":    uid,
+			"title":  "Dashboard " + uid,
+			"panels": []interface{}{map[string]interface{}{"id": 1, "type": "graph"}},
+		}))
+		dash.FolderId = folderID
+		saved, err := dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{Dashboard: dash, OrgId: 1, User: user}, false)
+		require.NoError(t, err)
+		return saved
+	}
+	exists := func(t *testing.T, uid string) bool {
+		t.Helper()
+		err := dashboardService.GetDashboard(ctx, &models.GetDashboardQuery{OrgId: 1, Uid: uid})
+		if err == models.ErrDashboardNotFound {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	t.Run("Archives and restores a dashboard", func(t *testing.T) {
+		saveDashboard(t, "cpu", 0)
+
+		archived, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "cpu", User: user})
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		assert.Equal(t, "Dashboard cpu", archived[0].Title)
+		assert.Equal(t, dashboardarchive.ReasonManual, archived[0].Reason)
+		assert.Equal(t, int64(1), archived[0].ArchivedBy)
+		assert.False(t, exists(t, "cpu"))
+
+		_, err = s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "cpu", User: user})
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+
+		result, err := s.Search(ctx, &dashboardarchive.SearchQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Empty(t, result[0].Data, "the search doesn't return the bodies")
+
+		restored, err := s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "cpu", User: user})
+		require.NoError(t, err)
+		require.Len(t, restored, 1)
+
+		query := &models.GetDashboardQuery{OrgId: 1, Uid: "cpu"}
+		require.NoError(t, dashboardService.GetDashboard(ctx, query))
+		assert.Equal(t, "Dashboard cpu", query.Result.Title)
+		assert.Len(t, query.Result.Data.Get("panels").MustArray(), 1)
+
+		_, err = s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "cpu", User: user})
+		require.ErrorIs(t, err, dashboardarchive.ErrArchiveNotFound)
+	})
+
+	t.Run("Archives folders with their subfolders and dashboards", func(t *testing.T) {
+		team, err := folderService.CreateFolder(ctx, user, 1, "Team", "team", "")
+		require.NoError(t, err)
+		services, err := folderService.CreateFolder(ctx, user, 1, "Services", "services", "team")
+		require.NoError(t, err)
+		saveDashboard(t, "overview", team.Id)
+		saveDashboard(t, "api", services.Id)
+
+		archived, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "team", User: user})
+		require.NoError(t, err)
+		uids := make([]string, 0, len(archived))
+		for _, a := range archived {
+			uids = append(uids, a.UID)
+		}
+		assert.Equal(t, []string{"overview", "api", "services", "team"}, uids)
+		for _, uid := range uids {
+			assert.False(t, exists(t, uid), uid)
+		}
+
+		_, err = s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "api", User: user})
+		require.ErrorIs(t, err, dashboardarchive.ErrFolderArchived)
+
+		restored, err := s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "team", User: user})
+		require.NoError(t, err)
+		assert.Len(t, restored, 4)
+
+		folder, err := folderService.GetFolderByUID(ctx, user, 1, "services")
+		require.NoError(t, err)
+		query := &models.GetDashboardQuery{OrgId: 1, Uid: "api"}
+		require.NoError(t, dashboardService.GetDashboard(ctx, query))
+		assert.Equal(t, folder.Id, query.Result.FolderId)
+
+		result, err := s.Search(ctx, &dashboardarchive.SearchQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("Restores dashboards in the General folder when their folder was deleted", func(t *testing.T) {
+		folder, err := folderService.CreateFolder(ctx, user, 1, "Temporary", "temporary", "")
+		require.NoError(t, err)
+		saveDashboard(t, "memory", folder.Id)
+
+		_, err = s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "memory", User: user})
+		require.NoError(t, err)
+		_, err = folderService.DeleteFolder(ctx, user, 1, "temporary", false)
+		require.NoError(t, err)
+
+		_, err = s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "memory", User: user})
+		require.NoError(t, err)
+		query := &models.GetDashboardQuery{OrgId: 1, Uid: "memory"}
+		require.NoError(t, dashboardService.GetDashboard(ctx, query))
+		assert.Equal(t, int64(0), query.Result.FolderId)
+	})
+
+	t.Run("Doesn't archive provisioned dashboards", func(t *testing.T) {
+		dash := saveDashboard(t, "provisioned", 0)
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(&models.DashboardProvisioning{DashboardId: dash.Id, Name: "default", ExternalId: "provisioned.json"})
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "provisioned", User: user})
+		require.ErrorIs(t, err, models.ErrDashboardCannotDeleteProvisionedDashboard)
+		assert.True(t, exists(t, "provisioned"))
+	})
+
+	t.Run("Requires the permission to delete the dashboard", func(t *testing.T) {
+		saveDashboard(t, "protected", 0)
+		fakeGuardian.CanSaveValue = false
+		t.Cleanup(func() { fakeGuardian.CanSaveValue = true })
+
+		_, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "protected", User: user})
+		require.ErrorIs(t, err, dashboardarchive.ErrAccessDenied)
+	})
+
+	t.Run("Offloads the bodies to the archive storage", func(t *testing.T) {
+		offloading := *s
+		offloading.bodies = bodystorage.New(setting.DashboardStorageSettings{Type: setting.DashboardStorageFilesystem, FilesystemPath: t.TempDir()})
+		saveDashboard(t, "disk", 0)
+
+		archived, err := offloading.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "disk", User: user})
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		assert.True(t, archived[0].Offloaded)
+		assert.Empty(t, archived[0].Data)
+
+		body, err := offloading.bodies.Get(ctx, bodystorage.Key(1, "disk"))
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Dashboard disk")
+
+		_, err = offloading.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "disk", User: user})
+		require.NoError(t, err)
+		assert.True(t, exists(t, "disk"))
+		_, err = offloading.bodies.Get(ctx, bodystorage.Key(1, "disk"))
+		require.ErrorIs(t, err, bodystorage.ErrBodyNotFound)
+	})
+
+	t.Run("Moves deleted dashboards to the trash", func(t *testing.T) {
+		saveDashboard(t, "network", 0)
+
+		_, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "network", User: user, Reason: dashboardarchive.ReasonDeleted})
+		require.NoError(t, err)
+		assert.False(t, exists(t, "network"))
+
+		trashed, err := s.Search(ctx, &dashboardarchive.SearchQuery{OrgID: 1, Trashed: true})
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, "network", trashed[0].UID)
+		archived, err := s.Search(ctx, &dashboardarchive.SearchQuery{OrgID: 1})
+		require.NoError(t, err)
+		for _, a := range archived {
+			assert.NotEqual(t, "network", a.UID, "the trash isn't part of the archive search")
+		}
+
+		// a dashboard deleted again replaces the one in the trash
+		saveDashboard(t, "network", 0)
+		_, err = s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "network", User: user, Reason: dashboardarchive.ReasonDeleted})
+		require.NoError(t, err)
+
+		_, err = s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "network", User: user})
+		require.ErrorIs(t, err, dashboardarchive.ErrArchiveNotFound, "trashed dashboards are restored from the trash")
+		restored, err := s.Restore(ctx, &dashboardarchive.RestoreCommand{OrgID: 1, UID: "network", User: user, Trashed: true})
+		require.NoError(t, err)
+		require.Len(t, restored, 1)
+		assert.True(t, exists(t, "network"))
+	})
+
+	t.Run("Keeps the dashboard in the trash when the dashboard replacing it can't be deleted", func(t *testing.T) {
+		offloading := *s
+		offloading.bodies = bodystorage.New(setting.DashboardStorageSettings{Type: setting.DashboardStorageFilesystem, FilesystemPath: t.TempDir()})
+		saveDashboard(t, "errors", 0)
+		_, err := offloading.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "errors", User: user, Reason: dashboardarchive.ReasonDeleted})
+		require.NoError(t, err)
+		trashed, err := offloading.store.Get(ctx, 1, "errors")
+		require.NoError(t, err)
+
+		dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{"uid": "errors", "title": "Replacing"}))
+		_, err = dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{Dashboard: dash, OrgId: 1, User: user}, false)
+		require.NoError(t, err)
+		failing := offloading
+		failing.dashboardService = failingDelete{dashboardService}
+		failing.now = func() time.Time { return now.Add(time.Hour) }
+		_, err = failing.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "errors", User: user, Reason: dashboardarchive.ReasonDeleted})
+		require.Error(t, err)
+		assert.True(t, exists(t, "errors"))
+
+		kept, err := offloading.store.Get(ctx, 1, "errors")
+		require.NoError(t, err)
+		assert.Equal(t, trashed.Title, kept.Title)
+		assert.Equal(t, trashed.Archived.Unix(), kept.Archived.Unix())
+		body, err := offloading.bodies.Get(ctx, bodystorage.Key(1, "errors"))
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Dashboard errors")
+		offloading.remove(ctx, kept)
+	})
+
+	t.Run("Purges the expired dashboards from the trash", func(t *testing.T) {
+		saveDashboard(t, "latency", 0)
+		_, err := s.Archive(ctx, &dashboardarchive.ArchiveCommand{OrgID: 1, UID: "latency", User: user, Reason: dashboardarchive.ReasonDeleted})
+		require.NoError(t, err)
+
+		count, err := s.PurgeTrash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count, "disabled without retention")
+
+		cfg.DashboardTrash.RetentionDays = 7
+		t.Cleanup(func() { cfg.DashboardTrash.RetentionDays = 0 })
+
+		count, err = s.PurgeTrash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count, "the retention period hasn't expired")
+
+		s.now = func() time.Time { return now.AddDate(0, 0, 8) }
+		t.Cleanup(func() { s.now = func() time.Time { return now } })
+
+		count, err = s.PurgeTrash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		_, err = s.store.Get(ctx, 1, "latency")
+		require.ErrorIs(t, err, dashboardarchive.ErrArchiveNotFound)
+	})
+
+	t.Run("Archives the unused dashboards", func(t *testing.T) {
+		count, err := s.ArchiveUnused(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count, "disabled by default")
+
+		cfg.DashboardArchive.UnusedDays = 30
+		t.Cleanup(func() { cfg.DashboardArchive.UnusedDays = 0 })
+		usage.unused = []*dashboardusage.UnusedDashboard{{UID: "memory"}, {UID: "unknown"}}
+
+		count, err = s.ArchiveUnused(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.Equal(t, now.AddDate(0, 0, -30), usage.query.Since)
+		assert.True(t, usage.query.ExcludeProvisioned)
+		assert.True(t, usage.query.ExcludeHomeDashboards)
+
+		a, err := s.store.Get(ctx, 1, "memory")
+		require.NoError(t, err)
+		assert.Equal(t, dashboardarchive.ReasonUnused, a.Reason)
+		assert.Equal(t, int64(0), a.ArchivedBy)
+	})
+}
@@ -0,0 +1,193 @@
+package dashboardarchiveimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, archived *dashboardarchive.ArchivedDashboard) error
+	// Get returns the archived dashboard or folder with the uid, or ErrArchiveNotFound.
+	Get(ctx context.Context, orgID int64, uid string) (*dashboardarchive.ArchivedDashboard, error)
+	// Search returns the archived dashboards and folders without their body.
+	Search(ctx context.Context, query *dashboardarchive.SearchQuery) ([]*dashboardarchive.ArchivedDashboard, error)
+	// TrashedBefore returns the dashboards moved to the trash before the time,
+	// of all the organizations, without their body.
+	TrashedBefore(ctx context.Context, before time.Time, limit int) ([]*dashboardarchive.ArchivedDashboard, error)
+	// Replace deletes the archived dashboard with the id and adds the other one
+	// in a single transaction.
+	Replace(ctx context.Context, id int64, archived *dashboardarchive.ArchivedDashboard) error
+	Delete(ctx context.Context, id int64) error
+	// ArchivedUIDs returns the uids that are already archived.
+	ArchivedUIDs(ctx context.Context, orgID int64, uids []string) ([]string, error)
+	// FolderContents returns the subfolders nested in the folder, the parents
+	// before their subfolders, and the dashboards in the folder and its subfolders.
+	FolderContents(ctx context.Context, orgID int64, folderID int64) ([]dashboardRef, []dashboardRef, error)
+	HasProvisionedDashboards(ctx context.Context, ids []int64) (bool, error)
+	HasAlertRules(ctx context.Context, orgID int64, folderUIDs []string) (bool, error)
+	// OrgIDs returns the ids of the organizations that have dashboards.
+	OrgIDs(ctx context.Context) ([]int64, error)
+}
+
+type dashboardRef struct {
+	ID       int64  `xorm:"id"`
+	UID      string `xorm:"uid"`
+	FolderID int64  `xorm:"folder_id"`
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, archived *dashboardarchive.ArchivedDashboard) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(archived)
+		return err
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, uid string) (*dashboardarchive.ArchivedDashboard, error) {
+	var archived dashboardarchive.ArchivedDashboard
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND uid=?", orgID, uid).Get(&archived)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return dashboardarchive.ErrArchiveNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *dashboardarchive.SearchQuery) ([]*dashboardarchive.ArchivedDashboard, error) {
+	result := make([]*dashboardarchive.ArchivedDashboard, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id=?", query.OrgID).Omit("data")
+		if query.Trashed {
+			q = q.And("reason=?", dashboardarchive.ReasonDeleted)
+		} else {
+			q = q.And("reason<>?", dashboardarchive.ReasonDeleted)
+		}
+		if query.FolderUID != "" {
+			q = q.And("folder_uid=?", query.FolderUID)
+		}
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Desc("archived").Asc("title").Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) TrashedBefore(ctx context.Context, before time.Time, limit int) ([]*dashboardarchive.ArchivedDashboard, error) {
+	result := make([]*dashboardarchive.ArchivedDashboard, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("reason=? AND archived<?", dashboardarchive.ReasonDeleted, before).
+			Omit("data").Asc("archived").Limit(limit).Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) Replace(ctx context.Context, id int64, archived *dashboardarchive.ArchivedDashboard) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.ID(id).Delete(&dashboardarchive.ArchivedDashboard{}); err != nil {
+			return err
+		}
+		_, err := sess.Insert(archived)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.ID(id).Delete(&dashboardarchive.ArchivedDashboard{})
+		return err
+	})
+}
+
+func (s *sqlStore) ArchivedUIDs(ctx context.Context, orgID int64, uids []string) ([]string, error) {
+	archived := make([]string, 0)
+	if len(uids) == 0 {
+		return archived, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("dashboard_archive").Cols("uid").Where("org_id=?", orgID).In("uid", uids).Find(&archived)
+	})
+	return archived, err
+}
+
+func (s *sqlStore) FolderContents(ctx context.Context, orgID int64, folderID int64) ([]dashboardRef, []dashboardRef, error) {
+	folders := make([]dashboardRef, 0)
+	dashboards := make([]dashboardRef, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		parents := []int64{folderID}
+		for len(parents) > 0 {
+			children := make([]struct {
+				ID       int64  `xorm:"id"`
+				UID      string `xorm:"uid"`
+				FolderID int64  `xorm:"folder_id"`
+				IsFolder bool   `xorm:"is_folder"`
+			}, 0)
+			if err := sess.Table("dashboard").Cols("id", "uid", "folder_id", "is_folder").
+				Where("org_id=?", orgID).In("folder_id", parents).Asc("id").Find(&children); err != nil {
+				return err
+			}
+
+			parents = nil
+			for _, child := range children {
+				ref := dashboardRef{ID: child.ID, UID: child.UID, FolderID: child.FolderID}
+				if child.IsFolder {
+					folders = append(folders, ref)
+					parents = append(parents, child.ID)
+				} else {
+					dashboards = append(dashboards, ref)
+				}
+			}
+		}
+		return nil
+	})
+	return folders, dashboards, err
+}
+
+func (s *sqlStore) HasProvisionedDashboards(ctx context.Context, ids []int64) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		count, err = sess.Table("dashboard_provisioning").In("dashboard_id", ids).Count()
+		return err
+	})
+	return count > 0, err
+}
+
+func (s *sqlStore) HasAlertRules(ctx context.Context, orgID int64, folderUIDs []string) (bool, error) {
+	if len(folderUIDs) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		count, err = sess.Table("alert_rule").Where("org_id=?", orgID).In("namespace_uid", folderUIDs).Count()
+		return err
+	})
+	return count > 0, err
+}
+
+func (s *sqlStore) OrgIDs(ctx context.Context) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT DISTINCT org_id FROM dashboard ORDER BY org_id").Find(&ids)
+	})
+	return ids, err
+}
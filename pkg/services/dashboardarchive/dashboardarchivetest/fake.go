@@ -0,0 +1,44 @@
+package dashboardarchivetest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboardarchive"
+)
+
+type FakeDashboardArchiveService struct {
+	ExpectedArchived []*dashboardarchive.ArchivedDashboard
+	ExpectedCount    int64
+	ExpectedError    error
+
+	ArchiveCommands []*dashboardarchive.ArchiveCommand
+	RestoreCommands []*dashboardarchive.RestoreCommand
+	SearchQueries   []*dashboardarchive.SearchQuery
+}
+
+func NewDashboardArchiveServiceFake() *FakeDashboardArchiveService {
+	return &FakeDashboardArchiveService{}
+}
+
+func (f *FakeDashboardArchiveService) Archive(ctx context.Context, cmd *dashboardarchive.ArchiveCommand) ([]*dashboardarchive.ArchivedDashboard, error) {
+	f.ArchiveCommands = append(f.ArchiveCommands, cmd)
+	return f.ExpectedArchived, f.ExpectedError
+}
+
+func (f *FakeDashboardArchiveService) Restore(ctx context.Context, cmd *dashboardarchive.RestoreCommand) ([]*dashboardarchive.ArchivedDashboard, error) {
+	f.RestoreCommands = append(f.RestoreCommands, cmd)
+	return f.ExpectedArchived, f.ExpectedError
+}
+
+func (f *FakeDashboardArchiveService) Search(ctx context.Context, query *dashboardarchive.SearchQuery) ([]*dashboardarchive.ArchivedDashboard, error) {
+	f.SearchQueries = append(f.SearchQueries, query)
+	return f.ExpectedArchived, f.ExpectedError
+}
+
+func (f *FakeDashboardArchiveService) ArchiveUnused(ctx context.Context) (int64, error) {
+	return f.ExpectedCount, f.ExpectedError
+}
+
+func (f *FakeDashboardArchiveService) PurgeTrash(ctx context.Context) (int64, error) {
+	return f.ExpectedCount, f.ExpectedError
+}
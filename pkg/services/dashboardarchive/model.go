@@ -0,0 +1,92 @@
+package dashboardarchive
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrArchiveNotFound = errors.New("archived dashboard not found")
+	ErrAlreadyArchived = errors.New("a dashboard or folder with the same uid is already archived")
+	ErrFolderArchived  = errors.New("the folder of the dashboard is archived, restore the folder first")
+	ErrAccessDenied    = errors.New("access denied")
+)
+
+// Reason is why a dashboard was archived.
+type Reason string
+
+const (
+	ReasonManual Reason = "manual"
+	// ReasonUnused is for the dashboards archived because they weren't viewed
+	// or queried for the configured number of days.
+	ReasonUnused Reason = "unused"
+	// ReasonDeleted is for the deleted dashboards, which are kept in the trash
+	// for the configured retention period.
+	ReasonDeleted Reason = "deleted"
+)
+
+// ArchivedDashboard is an archived dashboard or folder. Its body is kept in
+// Data, or offloaded to the archive storage.
+type ArchivedDashboard struct {
+	ID       int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID    int64  `xorm:"org_id" json:"orgId"`
+	UID      string `xorm:"uid" json:"uid"`
+	Title    string `xorm:"title" json:"title"`
+	IsFolder bool   `xorm:"is_folder" json:"isFolder"`
+	// FolderUID is the uid of the folder the dashboard or folder was in, empty
+	// for the General folder.
+	FolderUID  string    `xorm:"folder_uid" json:"folderUid"`
+	Data       string    `xorm:"data" json:"-"`
+	Offloaded  bool      `xorm:"offloaded" json:"offloaded"`
+	Reason     Reason    `xorm:"reason" json:"reason"`
+	ArchivedBy int64     `xorm:"archived_by" json:"archivedBy"`
+	Archived   time.Time `xorm:"archived" json:"archived"`
+}
+
+func (a ArchivedDashboard) TableName() string {
+	return "dashboard_archive"
+}
+
+// IsTrashed returns whether the dashboard is in the trash rather than archived.
+func (a ArchivedDashboard) IsTrashed() bool {
+	return a.Reason == ReasonDeleted
+}
+
+// ---------------------
+// COMMANDS
+
+type ArchiveCommand struct {
+	OrgID int64
+	UID   string
+	// User is the user archiving the dashboard, who must be allowed to delete
+	// it. It is nil for the dashboards archived automatically.
+	User   *models.SignedInUser
+	Reason Reason
+}
+
+// RestoreCommand restores the dashboard in the folder it was archived from,
+// or in the General folder when that folder no longer exists.
+type RestoreCommand struct {
+	OrgID int64
+	UID   string
+	// User is the user restoring the dashboard, who must be allowed to create
+	// dashboards in its folder.
+	User *models.SignedInUser
+	// Trashed restores the dashboard from the trash instead of the archive.
+	Trashed bool
+}
+
+// ---------------------
+// QUERIES
+
+type SearchQuery struct {
+	OrgID int64
+	// FolderUID returns the dashboards and folders archived from the folder
+	// when it is set.
+	FolderUID string
+	// Trashed searches the trash instead of the archive.
+	Trashed bool
+	Limit   int
+}
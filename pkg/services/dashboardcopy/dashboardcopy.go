@@ -0,0 +1,14 @@
+package dashboardcopy
+
+import (
+	"context"
+)
+
+// Service copies dashboards between organizations, for the server admins of
+// instances where each tenant is an organization.
+type Service interface {
+	// Copy copies the dashboard to the target organization with the same uid,
+	// the data sources it uses being replaced with the ones of the target
+	// organization with the same name.
+	Copy(ctx context.Context, cmd *CopyDashboardCommand) (*CopyDashboardResult, error)
+}
@@ -0,0 +1,435 @@
+package dashboardcopyimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// copyPermissions are the permissions of the server admin in the source and
+// target organizations, to read the dashboard, its library panels and its
+// permissions, and to create their copies.
+var copyPermissions = map[string][]string{
+	dashboards.ActionFoldersCreate:             {},
+	dashboards.ActionFoldersRead:               {dashboards.ScopeFoldersAll},
+	dashboards.ActionFoldersWrite:              {dashboards.ScopeFoldersAll},
+	dashboards.ActionFoldersPermissionsRead:    {dashboards.ScopeFoldersAll},
+	dashboards.ActionDashboardsCreate:          {dashboards.ScopeFoldersAll},
+	dashboards.ActionDashboardsRead:            {dashboards.ScopeFoldersAll, dashboards.ScopeDashboardsAll},
+	dashboards.ActionDashboardsWrite:           {dashboards.ScopeFoldersAll, dashboards.ScopeDashboardsAll},
+	dashboards.ActionDashboardsPermissionsRead: {dashboards.ScopeFoldersAll, dashboards.ScopeDashboardsAll},
+	accesscontrol.ActionOrgUsersRead:           {accesscontrol.ScopeUsersAll},
+	accesscontrol.ActionTeamsRead:              {accesscontrol.ScopeTeamsAll},
+}
+
+// Service copies the dashboards as the server admin acting as an admin of the
+// source and target organizations. The dashboards are copied like imported
+// dashboards, so that the library panels they use are connected to the copy.
+type Service struct {
+	sqlStore             sqlstore.Store
+	dashboardService     dashboards.DashboardService
+	folderService        dashboards.FolderService
+	dataSources          datasources.DataSourceService
+	libraryElements      libraryelements.Service
+	dashboardImport      dashboardimport.Service
+	dashboardPermissions accesscontrol.DashboardPermissionsService
+	folderPermissions    accesscontrol.FolderPermissionsService
+	ac                   accesscontrol.AccessControl
+}
+
+func ProvideService(sqlStore sqlstore.Store, dashboardService dashboards.DashboardService, folderService dashboards.FolderService,
+	dataSources datasources.DataSourceService, libraryElements libraryelements.Service, dashboardImport dashboardimport.Service,
+	dashboardPermissions accesscontrol.DashboardPermissionsService, folderPermissions accesscontrol.FolderPermissionsService,
+	ac accesscontrol.AccessControl) dashboardcopy.Service {
+	return &Service{
+		sqlStore:             sqlStore,
+		dashboardService:     dashboardService,
+		folderService:        folderService,
+		dataSources:          dataSources,
+		libraryElements:      libraryElements,
+		dashboardImport:      dashboardImport,
+		dashboardPermissions: dashboardPermissions,
+		folderPermissions:    folderPermissions,
+		ac:                   ac,
+	}
+}
+
+type copier struct {
+	*Service
+	cmd    *dashboardcopy.CopyDashboardCommand
+	source *models.SignedInUser
+	target *models.SignedInUser
+	result *dashboardcopy.CopyDashboardResult
+	// folders are the folders created by the copy.
+	folders []copiedFolder
+}
+
+type copiedFolder struct {
+	source *models.Folder
+	target *models.Folder
+}
+
+func (s *Service) Copy(ctx context.Context, cmd *dashboardcopy.CopyDashboardCommand) (*dashboardcopy.CopyDashboardResult, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	for _, orgID := range []int64{cmd.SourceOrgID, cmd.TargetOrgID} {
+		if err := s.sqlStore.GetOrgById(ctx, &models.GetOrgByIdQuery{Id: orgID}); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &copier{
+		Service: s,
+		cmd:     cmd,
+		source:  orgAdmin(cmd.User, cmd.SourceOrgID),
+		target:  orgAdmin(cmd.User, cmd.TargetOrgID),
+		result: &dashboardcopy.CopyDashboardResult{
+			CreatedFolders:      []string{},
+			LibraryPanels:       []string{},
+			DataSources:         []dashboardcopy.DataSourceMapping{},
+			UnmappedDataSources: []string{},
+			SkippedPermissions:  []dashboardcopy.SkippedPermission{},
+		},
+	}
+
+	query := &models.GetDashboardQuery{OrgId: cmd.SourceOrgID, Uid: cmd.UID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	dash := query.Result
+	if dash.IsFolder {
+		return nil, fmt.Errorf("%w: %q is a folder", dashboardcopy.ErrInvalidCopy, cmd.UID)
+	}
+
+	folder, err := c.targetFolder(ctx, dash)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, err := c.newDataSourceMapper(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := copyJSON(dash.Data)
+	if err != nil {
+		return nil, err
+	}
+	data.Del("id")
+	mapper.remap(data.Interface())
+
+	if err := c.copyLibraryPanels(ctx, data, mapper, folder.Id); err != nil {
+		return nil, err
+	}
+
+	imported, err := s.dashboardImport.ImportDashboard(ctx, &dashboardimport.ImportDashboardRequest{
+		Dashboard: data,
+		FolderId:  folder.Id,
+		Overwrite: cmd.Overwrite,
+		User:      c.target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.CopyPermissions {
+		for _, f := range c.folders {
+			err := c.copyPermissions(ctx,
+				&models.Dashboard{Id: f.source.Id, Uid: f.source.Uid, OrgId: cmd.SourceOrgID, HasAcl: f.source.HasAcl, IsFolder: true},
+				f.target.Id, f.target.Uid)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := c.copyPermissions(ctx, dash, imported.DashboardId, imported.UID); err != nil {
+			return nil, err
+		}
+	}
+
+	c.result.UID = imported.UID
+	c.result.Title = imported.Title
+	c.result.URL = imported.ImportedUrl
+	c.result.FolderUID = folder.Uid
+	c.result.DataSources, c.result.UnmappedDataSources = mapper.mappings()
+	return c.result, nil
+}
+
+// targetFolder returns the folder of the target organization the dashboard is
+// copied to. The folder of the dashboard, and the folders containing it, are
+// created when copying the folder.
+func (c *copier) targetFolder(ctx context.Context, dash *models.Dashboard) (*models.Folder, error) {
+	switch {
+	case c.cmd.FolderUID != "":
+		return c.folderService.GetFolderByUID(ctx, c.target, c.cmd.TargetOrgID, c.cmd.FolderUID)
+	case !c.cmd.CopyFolder || dash.FolderId == 0:
+		return &models.Folder{Title: "General"}, nil
+	}
+
+	folder, err := c.folderService.GetFolderByID(ctx, c.source, dash.FolderId, c.cmd.SourceOrgID)
+	if err != nil {
+		return nil, err
+	}
+	parents, err := c.folderService.GetFolderParents(ctx, c.source, c.cmd.SourceOrgID, folder.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied *models.Folder
+	parentUID := ""
+	for _, f := range append(parents, folder) {
+		copied, err = c.folderService.GetFolderByUID(ctx, c.target, c.cmd.TargetOrgID, f.Uid)
+		if errors.Is(err, models.ErrFolderNotFound) {
+			copied, err = c.folderService.CreateFolder(ctx, c.target, c.cmd.TargetOrgID, f.Title, f.Uid, parentUID)
+			if err == nil {
+				c.folders = append(c.folders, copiedFolder{source: f, target: copied})
+				c.result.CreatedFolders = append(c.result.CreatedFolders, copied.Uid)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		parentUID = copied.Uid
+	}
+	return copied, nil
+}
+
+// copyLibraryPanels creates the library panels of the dashboard missing from
+// the target organization in the folder, with the same uid. The import would
+// create them from the panels of the dashboard otherwise, which only keep the
+// reference to the library panel.
+func (c *copier) copyLibraryPanels(ctx context.Context, data *simplejson.Json, mapper *dataSourceMapper, folderID int64) error {
+	for _, uid := range libraryPanelUIDs(data) {
+		_, err := c.libraryElements.GetElement(ctx, c.target, uid)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+			return err
+		}
+
+		element, err := c.libraryElements.GetElement(ctx, c.source, uid)
+		if errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		model, err := simplejson.NewJson(element.Model)
+		if err != nil {
+			return err
+		}
+		mapper.remap(model.Interface())
+		raw, err := model.Encode()
+		if err != nil {
+			return err
+		}
+
+		_, err = c.libraryElements.CreateElement(ctx, c.target, libraryelements.CreateLibraryElementCommand{
+			FolderID: folderID,
+			Name:     element.Name,
+			Model:    raw,
+			Kind:     element.Kind,
+			UID:      uid,
+		})
+		if err != nil {
+			return err
+		}
+		c.result.LibraryPanels = append(c.result.LibraryPanels, uid)
+	}
+	return nil
+}
+
+// copyPermissions replaces the permissions of the copy with the permissions
+// of the source dashboard or folder. The inherited permissions are left to
+// the folder of the copy.
+func (c *copier) copyPermissions(ctx context.Context, source *models.Dashboard, targetID int64, targetUID string) error {
+	acl, err := guardian.New(ctx, source.Id, source.OrgId, c.source).GetAcl()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	items := make([]*models.DashboardAcl, 0, len(acl))
+	for _, p := range acl {
+		// the default permissions are listed with the dashboard id -1
+		if p.Inherited || p.DashboardId != source.Id {
+			continue
+		}
+
+		item := &models.DashboardAcl{
+			OrgID:       c.cmd.TargetOrgID,
+			DashboardID: targetID,
+			Role:        p.Role,
+			Permission:  p.Permission,
+			Created:     now,
+			Updated:     now,
+		}
+		switch {
+		case p.UserId > 0:
+			member, err := c.isMember(ctx, p.UserId)
+			if err != nil {
+				return err
+			}
+			if !member {
+				c.skipPermission(source.Uid, p)
+				continue
+			}
+			item.UserID = p.UserId
+		case p.TeamId > 0:
+			teamID, err := c.teamByName(ctx, p.Team)
+			if err != nil {
+				return err
+			}
+			if teamID == 0 {
+				c.skipPermission(source.Uid, p)
+				continue
+			}
+			item.TeamID = teamID
+		}
+		items = append(items, item)
+	}
+
+	if c.ac.IsDisabled() {
+		// the copy keeps the default permissions as the source does
+		if !source.HasAcl {
+			return nil
+		}
+		return c.dashboardService.UpdateDashboardACL(ctx, targetID, items)
+	}
+
+	old, err := guardian.New(ctx, targetID, c.cmd.TargetOrgID, c.target).GetAcl()
+	if err != nil {
+		return err
+	}
+	var svc accesscontrol.PermissionsService = c.dashboardPermissions
+	if source.IsFolder {
+		svc = c.folderPermissions
+	}
+	_, err = svc.SetPermissions(ctx, c.cmd.TargetOrgID, targetUID, permissionCommands(items, old)...)
+	return err
+}
+
+func (c *copier) skipPermission(uid string, p *models.DashboardAclInfoDTO) {
+	c.result.SkippedPermissions = append(c.result.SkippedPermissions, dashboardcopy.SkippedPermission{
+		UID:        uid,
+		UserLogin:  p.UserLogin,
+		Team:       p.Team,
+		Permission: p.Permission.String(),
+	})
+}
+
+func (c *copier) isMember(ctx context.Context, userID int64) (bool, error) {
+	query := &models.GetOrgUsersQuery{
+		OrgId:                    c.cmd.TargetOrgID,
+		UserID:                   userID,
+		User:                     c.target,
+		DontEnforceAccessControl: true,
+	}
+	if err := c.sqlStore.GetOrgUsers(ctx, query); err != nil {
+		return false, err
+	}
+	return len(query.Result) > 0, nil
+}
+
+// teamByName returns the id of the team of the target organization with the
+// name, 0 if there is none.
+func (c *copier) teamByName(ctx context.Context, name string) (int64, error) {
+	query := &models.SearchTeamsQuery{
+		OrgId:        c.cmd.TargetOrgID,
+		Name:         name,
+		UserIdFilter: models.FilterIgnoreUser,
+		SignedInUser: c.target,
+	}
+	if err := c.sqlStore.SearchTeams(ctx, query); err != nil {
+		return 0, err
+	}
+	if len(query.Result.Teams) == 0 {
+		return 0, nil
+	}
+	return query.Result.Teams[0].Id, nil
+}
+
+// permissionCommands returns the commands setting the permissions of the
+// items, and removing the other permissions.
+func permissionCommands(items []*models.DashboardAcl, old []*models.DashboardAclInfoDTO) []accesscontrol.SetResourcePermissionCommand {
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(items)+len(old))
+	set := map[accesscontrol.SetResourcePermissionCommand]bool{}
+	for _, item := range items {
+		cmd := accesscontrol.SetResourcePermissionCommand{UserID: item.UserID, TeamID: item.TeamID}
+		if item.Role != nil {
+			cmd.BuiltinRole = string(*item.Role)
+		}
+		set[cmd] = true
+		cmd.Permission = item.Permission.String()
+		commands = append(commands, cmd)
+	}
+	for _, o := range old {
+		cmd := accesscontrol.SetResourcePermissionCommand{UserID: o.UserId, TeamID: o.TeamId}
+		if o.Role != nil {
+			cmd.BuiltinRole = string(*o.Role)
+		}
+		if !set[cmd] {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// orgAdmin returns the server admin acting as an admin of the organization.
+func orgAdmin(user *models.SignedInUser, orgID int64) *models.SignedInUser {
+	return &models.SignedInUser{
+		UserId:         user.UserId,
+		Login:          user.Login,
+		OrgId:          orgID,
+		OrgRole:        models.ROLE_ADMIN,
+		IsGrafanaAdmin: user.IsGrafanaAdmin,
+		Permissions:    map[int64]map[string][]string{orgID: copyPermissions},
+	}
+}
+
+// copyJSON returns a deep copy of the dashboard JSON, with the numbers kept as
+// json.Number as the import expects.
+func copyJSON(data *simplejson.Json) (*simplejson.Json, error) {
+	raw, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(raw)
+}
+
+// libraryPanelUIDs returns the uids of the library panels of the dashboard,
+// including the ones in rows.
+func libraryPanelUIDs(data *simplejson.Json) []string {
+	var uids []string
+	seen := map[string]bool{}
+	var walk func(parent *simplejson.Json)
+	walk = func(parent *simplejson.Json) {
+		for _, panel := range parent.Get("panels").MustArray() {
+			panelJSON := simplejson.NewFromAny(panel)
+			if panelJSON.Get("type").MustString() == "row" {
+				walk(panelJSON)
+				continue
+			}
+			uid := panelJSON.GetPath("libraryPanel", "uid").MustString()
+			if uid != "" && !seen[uid] {
+				seen[uid] = true
+				uids = append(uids, uid)
+			}
+		}
+	}
+	walk(data)
+	return uids
+}
@@ -0,0 +1,343 @@
+package dashboardcopyimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type fakeLibraryElements struct {
+	libraryelements.Service
+	elements map[int64]map[string]libraryelements.LibraryElementDTO
+	created  []libraryelements.CreateLibraryElementCommand
+}
+
+func (f *fakeLibraryElements) GetElement(c context.Context, signedInUser *models.SignedInUser, UID string) (libraryelements.LibraryElementDTO, error) {
+	element, ok := f.elements[signedInUser.OrgId][UID]
+	if !ok {
+		return libraryelements.LibraryElementDTO{}, libraryelements.ErrLibraryElementNotFound
+	}
+	return element, nil
+}
+
+func (f *fakeLibraryElements) CreateElement(c context.Context, signedInUser *models.SignedInUser, cmd libraryelements.CreateLibraryElementCommand) (libraryelements.LibraryElementDTO, error) {
+	f.created = append(f.created, cmd)
+	return libraryelements.LibraryElementDTO{UID: cmd.UID, Name: cmd.Name, Model: cmd.Model}, nil
+}
+
+type recordingImportService struct {
+	request *dashboardimport.ImportDashboardRequest
+}
+
+func (r *recordingImportService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
+	r.request = req
+	uid := req.Dashboard.Get("uid").MustString()
+	return &dashboardimport.ImportDashboardResponse{
+		UID:         uid,
+		Title:       req.Dashboard.Get("title").MustString(),
+		ImportedUrl: "/d/" + uid + "/service",
+		DashboardId: 42,
+	}, nil
+}
+
+type copyFixture struct {
+	ss               *sqlstore.SQLStore
+	admin            *models.SignedInUser
+	sourceOrg        int64
+	targetOrg        int64
+	alice            int64
+	sreTargetTeam    int64
+	dashboardService *dashboards.FakeDashboardService
+	folderService    *dashboards.FakeFolderService
+	libraryElements  *fakeLibraryElements
+	imports          *recordingImportService
+	dashPermissions  *accesscontrolmock.MockPermissionsService
+	folderPerms      *accesscontrolmock.MockPermissionsService
+	// acls are the permissions returned by the guardian, by organization and dashboard id.
+	acls map[int64]map[int64][]*models.DashboardAclInfoDTO
+}
+
+func (f *copyFixture) service(ac accesscontrol.AccessControl) *Service {
+	dataSources := &datasourcesfakes.FakeDataSourceService{DataSources: []*models.DataSource{
+		{OrgId: f.sourceOrg, Uid: "prom-src", Name: "Prometheus", Type: "prometheus"},
+		{OrgId: f.sourceOrg, Uid: "loki-src", Name: "Loki", Type: "loki"},
+		{OrgId: f.sourceOrg, Uid: "graphite-src", Name: "Graphite", Type: "graphite"},
+		{OrgId: f.targetOrg, Uid: "prom-dst", Name: "Prometheus", Type: "prometheus"},
+		{OrgId: f.targetOrg, Uid: "graphite-dst", Name: "Graphite", Type: "graphite"},
+	}}
+	return ProvideService(f.ss, f.dashboardService, f.folderService, dataSources, f.libraryElements, f.imports,
+		f.dashPermissions, f.folderPerms, ac).(*Service)
+}
+
+func setupCopyFixture(t *testing.T) *copyFixture {
+	t.Helper()
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	createUser := func(login string, isAdmin bool) int64 {
+		user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: login, Email: login + "@example.com", IsAdmin: isAdmin, SkipOrgSetup: true})
+		require.NoError(t, err)
+		return user.Id
+	}
+	adminID := createUser("admin", true)
+	source, err := ss.CreateOrgWithMember("source", adminID)
+	require.NoError(t, err)
+	target, err := ss.CreateOrgWithMember("target", adminID)
+	require.NoError(t, err)
+
+	alice := createUser("alice", false)
+	bob := createUser("bob", false)
+	for _, cmd := range []*models.AddOrgUserCommand{
+		{OrgId: source.Id, UserId: alice, Role: models.ROLE_VIEWER},
+		{OrgId: target.Id, UserId: alice, Role: models.ROLE_VIEWER},
+		{OrgId: source.Id, UserId: bob, Role: models.ROLE_VIEWER},
+	} {
+		require.NoError(t, ss.AddOrgUser(ctx, cmd))
+	}
+
+	sreSource, err := ss.CreateTeam("sre", "", source.Id)
+	require.NoError(t, err)
+	sreTarget, err := ss.CreateTeam("sre", "", target.Id)
+	require.NoError(t, err)
+	qa, err := ss.CreateTeam("qa", "", source.Id)
+	require.NoError(t, err)
+
+	data, err := simplejson.NewJson([]byte(`{
+		"id": 5,
+		"uid": "service",
+		"title": "Service",
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom-src"}, "targets": [{"refId": "A", "datasource": {"type": "prometheus", "uid": "prom-src"}}]},
+			{"id": 2, "type": "logs", "datasource": {"type": "loki", "uid": "loki-src"}},
+			{"id": 3, "type": "stat", "datasource": "Graphite"},
+			{"id": 4, "type": "table", "datasource": {"type": "datasource", "uid": "-- Mixed --"}, "targets": [{"datasource": {"type": "prometheus", "uid": "${ds}"}}]},
+			{"id": 5, "type": "row", "panels": [{"id": 6, "gridPos": {"h": 8, "w": 12}, "libraryPanel": {"uid": "errors", "name": "Errors"}}]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Return(func(ctx context.Context, q *models.GetDashboardQuery) error {
+		switch {
+		case q.OrgId == source.Id && q.Uid == "service":
+			q.Result = &models.Dashboard{Id: 5, Uid: "service", OrgId: source.Id, FolderId: 10, HasAcl: true, Data: data}
+		case q.OrgId == source.Id && q.Uid == "team":
+			q.Result = &models.Dashboard{Id: 10, Uid: "team", OrgId: source.Id, IsFolder: true}
+		default:
+			return models.ErrDashboardNotFound
+		}
+		return nil
+	})
+
+	viewer := models.ROLE_VIEWER
+	editor := models.ROLE_EDITOR
+	f := &copyFixture{
+		ss:               ss,
+		admin:            &models.SignedInUser{UserId: adminID, Login: "admin", OrgId: source.Id, IsGrafanaAdmin: true},
+		sourceOrg:        source.Id,
+		targetOrg:        target.Id,
+		alice:            alice,
+		sreTargetTeam:    sreTarget.Id,
+		dashboardService: dashboardService,
+		folderService:    dashboards.NewFakeFolderService(t),
+		libraryElements: &fakeLibraryElements{elements: map[int64]map[string]libraryelements.LibraryElementDTO{
+			source.Id: {"errors": {UID: "errors", Name: "Errors", Kind: int64(models.PanelElement), Model: json.RawMessage(`{"type":"timeseries","datasource":{"type":"prometheus","uid":"prom-src"}}`)}},
+		}},
+		imports:         &recordingImportService{},
+		dashPermissions: accesscontrolmock.NewMockedPermissionsService(),
+		folderPerms:     accesscontrolmock.NewMockedPermissionsService(),
+		acls: map[int64]map[int64][]*models.DashboardAclInfoDTO{
+			source.Id: {
+				5: {
+					{DashboardId: 5, UserId: alice, UserLogin: "alice", Permission: models.PERMISSION_VIEW},
+					{DashboardId: 5, UserId: bob, UserLogin: "bob", Permission: models.PERMISSION_EDIT},
+					{DashboardId: 5, TeamId: sreSource.Id, Team: "sre", Permission: models.PERMISSION_EDIT},
+					{DashboardId: 5, TeamId: qa.Id, Team: "qa", Permission: models.PERMISSION_VIEW},
+					{DashboardId: 5, Role: &viewer, Permission: models.PERMISSION_VIEW},
+					{DashboardId: 10, Role: &editor, Permission: models.PERMISSION_EDIT, Inherited: true},
+				},
+				10: {
+					{DashboardId: 10, Role: &editor, Permission: models.PERMISSION_EDIT},
+				},
+			},
+			target.Id: {
+				42: {
+					{DashboardId: 42, UserId: adminID, UserLogin: "admin", Permission: models.PERMISSION_ADMIN},
+					{DashboardId: 42, Role: &editor, Permission: models.PERMISSION_EDIT},
+				},
+			},
+		},
+	}
+
+	origNewGuardian := guardian.New
+	guardian.New = func(ctx context.Context, dashID int64, orgID int64, user *models.SignedInUser) guardian.DashboardGuardian {
+		return &guardian.FakeDashboardGuardian{DashId: dashID, OrgId: orgID, User: user, GetAclValue: f.acls[orgID][dashID]}
+	}
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	return f
+}
+
+func TestIntegrationCopyDashboard(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	t.Run("Copies the dashboard with its folder, library panels and permissions", func(t *testing.T) {
+		f := setupCopyFixture(t)
+		svc := f.service(accesscontrolmock.New())
+
+		f.folderService.On("GetFolderByID", mock.Anything, mock.Anything, int64(10), f.sourceOrg).Return(&models.Folder{Id: 10, Uid: "team", Title: "Team", HasAcl: true}, nil)
+		f.folderService.On("GetFolderParents", mock.Anything, mock.Anything, f.sourceOrg, "team").Return([]*models.Folder{{Id: 9, Uid: "tenants", Title: "Tenants"}}, nil)
+		f.folderService.On("GetFolderByUID", mock.Anything, mock.Anything, f.targetOrg, "tenants").Return(&models.Folder{Id: 20, Uid: "tenants", Title: "Tenants"}, nil)
+		f.folderService.On("GetFolderByUID", mock.Anything, mock.Anything, f.targetOrg, "team").Return(nil, models.ErrFolderNotFound)
+		f.folderService.On("CreateFolder", mock.Anything, mock.Anything, f.targetOrg, "Team", "team", "tenants").Return(&models.Folder{Id: 21, Uid: "team", Title: "Team"}, nil)
+		f.dashPermissions.On("SetPermissions", mock.Anything, f.targetOrg, "service", mock.Anything).Return([]accesscontrol.ResourcePermission{}, nil)
+		f.folderPerms.On("SetPermissions", mock.Anything, f.targetOrg, "team", mock.Anything).Return([]accesscontrol.ResourcePermission{}, nil)
+
+		result, err := svc.Copy(ctx, &dashboardcopy.CopyDashboardCommand{
+			SourceOrgID:     f.sourceOrg,
+			UID:             "service",
+			TargetOrgID:     f.targetOrg,
+			CopyFolder:      true,
+			CopyPermissions: true,
+			User:            f.admin,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "service", result.UID)
+		require.Equal(t, "team", result.FolderUID)
+		require.Equal(t, []string{"team"}, result.CreatedFolders)
+		require.Equal(t, []string{"errors"}, result.LibraryPanels)
+		require.Equal(t, []dashboardcopy.DataSourceMapping{
+			{Name: "Graphite", SourceUID: "graphite-src", TargetUID: "graphite-dst", Type: "graphite"},
+			{Name: "Prometheus", SourceUID: "prom-src", TargetUID: "prom-dst", Type: "prometheus"},
+		}, result.DataSources)
+		require.Equal(t, []string{"Loki"}, result.UnmappedDataSources)
+		require.Equal(t, []dashboardcopy.SkippedPermission{
+			{UID: "service", UserLogin: "bob", Permission: "Edit"},
+			{UID: "service", Team: "qa", Permission: "View"},
+		}, result.SkippedPermissions)
+
+		req := f.imports.request
+		require.Equal(t, f.targetOrg, req.User.OrgId)
+		require.Equal(t, int64(21), req.FolderId)
+		require.Nil(t, req.Dashboard.Get("id").Interface())
+		panels := req.Dashboard.Get("panels")
+		require.Equal(t, "prom-dst", panels.GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "prom-dst", panels.GetIndex(0).Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "loki-src", panels.GetIndex(1).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "Graphite", panels.GetIndex(2).Get("datasource").MustString())
+		require.Equal(t, "-- Mixed --", panels.GetIndex(3).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "${ds}", panels.GetIndex(3).Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+		require.Len(t, f.libraryElements.created, 1)
+		created := f.libraryElements.created[0]
+		require.Equal(t, "errors", created.UID)
+		require.Equal(t, int64(21), created.FolderID)
+		require.JSONEq(t, `{"type":"timeseries","datasource":{"type":"prometheus","uid":"prom-dst"}}`, string(created.Model))
+
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{
+			{UserID: f.alice, Permission: "View"},
+			{TeamID: f.sreTargetTeam, Permission: "Edit"},
+			{BuiltinRole: "Viewer", Permission: "View"},
+			{UserID: f.admin.UserId},
+			{BuiltinRole: "Editor"},
+		}, f.dashPermissions.Calls[0].Arguments.Get(3))
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{
+			{BuiltinRole: "Editor", Permission: "Edit"},
+		}, f.folderPerms.Calls[0].Arguments.Get(3))
+	})
+
+	t.Run("Replaces the legacy permissions of the copy", func(t *testing.T) {
+		f := setupCopyFixture(t)
+		svc := f.service(accesscontrolmock.New().WithDisabled())
+		f.folderService.On("GetFolderByUID", mock.Anything, mock.Anything, f.targetOrg, "shared").Return(&models.Folder{Id: 30, Uid: "shared", Title: "Shared"}, nil)
+		f.libraryElements.elements[f.targetOrg] = map[string]libraryelements.LibraryElementDTO{"errors": {UID: "errors"}}
+		f.dashboardService.On("UpdateDashboardACL", mock.Anything, int64(42), mock.Anything).Return(nil)
+
+		result, err := svc.Copy(ctx, &dashboardcopy.CopyDashboardCommand{
+			SourceOrgID:     f.sourceOrg,
+			UID:             "service",
+			TargetOrgID:     f.targetOrg,
+			FolderUID:       "shared",
+			CopyPermissions: true,
+			User:            f.admin,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "shared", result.FolderUID)
+		require.Empty(t, result.CreatedFolders)
+		// the library panel already exists in the target organization
+		require.Empty(t, result.LibraryPanels)
+		require.Empty(t, f.libraryElements.created)
+		require.Equal(t, int64(30), f.imports.request.FolderId)
+
+		items := f.dashboardService.Calls[1].Arguments.Get(2).([]*models.DashboardAcl)
+		require.Len(t, items, 3)
+		for _, item := range items {
+			require.Equal(t, f.targetOrg, item.OrgID)
+			require.Equal(t, int64(42), item.DashboardID)
+		}
+		require.Equal(t, f.alice, items[0].UserID)
+		require.Equal(t, f.sreTargetTeam, items[1].TeamID)
+		require.Equal(t, models.ROLE_VIEWER, *items[2].Role)
+	})
+
+	t.Run("Copies the dashboard to the General folder without its permissions", func(t *testing.T) {
+		f := setupCopyFixture(t)
+		svc := f.service(accesscontrolmock.New())
+
+		result, err := svc.Copy(ctx, &dashboardcopy.CopyDashboardCommand{
+			SourceOrgID: f.sourceOrg,
+			UID:         "service",
+			TargetOrgID: f.targetOrg,
+			Overwrite:   true,
+			User:        f.admin,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "", result.FolderUID)
+		require.Equal(t, int64(0), f.imports.request.FolderId)
+		require.True(t, f.imports.request.Overwrite)
+		require.Empty(t, result.SkippedPermissions)
+		f.dashPermissions.AssertNotCalled(t, "SetPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejects invalid copies", func(t *testing.T) {
+		f := setupCopyFixture(t)
+		svc := f.service(accesscontrolmock.New())
+
+		for _, cmd := range []dashboardcopy.CopyDashboardCommand{
+			{SourceOrgID: f.sourceOrg, UID: "service", TargetOrgID: f.sourceOrg},
+			{SourceOrgID: f.sourceOrg, TargetOrgID: f.targetOrg},
+			{SourceOrgID: f.sourceOrg, UID: "service", TargetOrgID: f.targetOrg, FolderUID: "shared", CopyFolder: true},
+			{SourceOrgID: f.sourceOrg, UID: "team", TargetOrgID: f.targetOrg},
+		} {
+			cmd.User = f.admin
+			_, err := svc.Copy(ctx, &cmd)
+			require.ErrorIs(t, err, dashboardcopy.ErrInvalidCopy)
+		}
+
+		_, err := svc.Copy(ctx, &dashboardcopy.CopyDashboardCommand{SourceOrgID: f.sourceOrg, UID: "service", TargetOrgID: 999, User: f.admin})
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+
+		_, err = svc.Copy(ctx, &dashboardcopy.CopyDashboardCommand{SourceOrgID: f.sourceOrg, UID: "missing", TargetOrgID: f.targetOrg, User: f.admin})
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+	})
+}
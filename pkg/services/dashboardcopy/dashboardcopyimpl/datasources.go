@@ -0,0 +1,129 @@
+package dashboardcopyimpl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+)
+
+// dataSourceMapper replaces the references to the data sources of the source
+// organization with references to the data sources of the target organization
+// with the same name and type. The references to special data sources, such as
+// the mixed data source, and to template variables are kept as they are.
+type dataSourceMapper struct {
+	sourceByUID  map[string]*models.DataSource
+	sourceByName map[string]*models.DataSource
+	targetByName map[string]*models.DataSource
+
+	mapped   map[string]dashboardcopy.DataSourceMapping
+	unmapped map[string]bool
+}
+
+func (c *copier) newDataSourceMapper(ctx context.Context) (*dataSourceMapper, error) {
+	source := &models.GetDataSourcesQuery{OrgId: c.cmd.SourceOrgID, User: c.source}
+	if err := c.dataSources.GetDataSources(ctx, source); err != nil {
+		return nil, err
+	}
+	target := &models.GetDataSourcesQuery{OrgId: c.cmd.TargetOrgID, User: c.target}
+	if err := c.dataSources.GetDataSources(ctx, target); err != nil {
+		return nil, err
+	}
+
+	m := &dataSourceMapper{
+		sourceByUID:  make(map[string]*models.DataSource, len(source.Result)),
+		sourceByName: make(map[string]*models.DataSource, len(source.Result)),
+		targetByName: make(map[string]*models.DataSource, len(target.Result)),
+		mapped:       map[string]dashboardcopy.DataSourceMapping{},
+		unmapped:     map[string]bool{},
+	}
+	for _, ds := range source.Result {
+		m.sourceByUID[ds.Uid] = ds
+		m.sourceByName[ds.Name] = ds
+	}
+	for _, ds := range target.Result {
+		m.targetByName[ds.Name] = ds
+	}
+	return m, nil
+}
+
+// remap replaces the data source references of the JSON value in place.
+func (m *dataSourceMapper) remap(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if key == "datasource" {
+				v[key] = m.remapRef(child)
+				continue
+			}
+			m.remap(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			m.remap(child)
+		}
+	}
+}
+
+// remapRef returns the reference to the data source of the target
+// organization, or the reference as it is when it can't be mapped. The
+// references are objects with the uid and type of the data source, or the name
+// of the data source in older dashboards.
+func (m *dataSourceMapper) remapRef(ref interface{}) interface{} {
+	switch r := ref.(type) {
+	case map[string]interface{}:
+		uid, _ := r["uid"].(string)
+		if target := m.target(m.sourceByUID[uid]); target != nil {
+			r["uid"] = target.Uid
+			r["type"] = target.Type
+		}
+	case string:
+		if source, ok := m.sourceByName[r]; ok {
+			// the data source has the same name in the target organization
+			m.target(source)
+		} else if target := m.target(m.sourceByUID[r]); target != nil {
+			return target.Uid
+		}
+	}
+	return ref
+}
+
+// target returns the data source of the target organization with the name and
+// type of the data source of the source organization, nil if there is none.
+func (m *dataSourceMapper) target(source *models.DataSource) *models.DataSource {
+	if source == nil {
+		return nil
+	}
+	target, ok := m.targetByName[source.Name]
+	if !ok || target.Type != source.Type {
+		m.unmapped[source.Name] = true
+		return nil
+	}
+	m.mapped[source.Name] = dashboardcopy.DataSourceMapping{
+		Name:      source.Name,
+		SourceUID: source.Uid,
+		TargetUID: target.Uid,
+		Type:      target.Type,
+	}
+	return target
+}
+
+// mappings returns the mapped data sources and the names of the unmapped ones,
+// sorted by name.
+func (m *dataSourceMapper) mappings() ([]dashboardcopy.DataSourceMapping, []string) {
+	mapped := make([]dashboardcopy.DataSourceMapping, 0, len(m.mapped))
+	for _, mapping := range m.mapped {
+		mapped = append(mapped, mapping)
+	}
+	sort.Slice(mapped, func(i, j int) bool {
+		return mapped[i].Name < mapped[j].Name
+	})
+
+	unmapped := make([]string, 0, len(m.unmapped))
+	for name := range m.unmapped {
+		unmapped = append(unmapped, name)
+	}
+	sort.Strings(unmapped)
+	return mapped, unmapped
+}
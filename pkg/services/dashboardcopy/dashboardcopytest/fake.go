@@ -0,0 +1,23 @@
+package dashboardcopytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboardcopy"
+)
+
+type FakeDashboardCopyService struct {
+	ExpectedResult *dashboardcopy.CopyDashboardResult
+	ExpectedError  error
+
+	CopyCommand *dashboardcopy.CopyDashboardCommand
+}
+
+func NewDashboardCopyServiceFake() *FakeDashboardCopyService {
+	return &FakeDashboardCopyService{}
+}
+
+func (f *FakeDashboardCopyService) Copy(ctx context.Context, cmd *dashboardcopy.CopyDashboardCommand) (*dashboardcopy.CopyDashboardResult, error) {
+	f.CopyCommand = cmd
+	return f.ExpectedResult, f.ExpectedError
+}
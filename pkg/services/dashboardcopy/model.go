@@ -0,0 +1,87 @@
+package dashboardcopy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrInvalidCopy = errors.New("invalid dashboard copy")
+)
+
+type CopyDashboardCommand struct {
+	SourceOrgID int64  `json:"sourceOrgId"`
+	UID         string `json:"uid"`
+	TargetOrgID int64  `json:"targetOrgId"`
+	// FolderUID is the folder of the target organization the dashboard is
+	// copied to, the General folder when it isn't set.
+	FolderUID string `json:"folderUid"`
+	// CopyFolder copies the dashboard to the folder with the same uid in the
+	// target organization. The folder, and the folders containing it, are
+	// created when they don't exist.
+	CopyFolder bool `json:"copyFolder"`
+	// CopyPermissions copies the permissions of the dashboard, and of the
+	// folders created by the copy. The permissions of users who aren't members
+	// of the target organization, and of teams without a team of the same name
+	// in the target organization, are skipped.
+	CopyPermissions bool `json:"copyPermissions"`
+	// Overwrite replaces the dashboard of the target organization with the
+	// same uid or the same title in the folder.
+	Overwrite bool `json:"overwrite"`
+
+	User *models.SignedInUser `json:"-"`
+}
+
+type CopyDashboardResult struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	FolderUID string `json:"folderUid"`
+	// CreatedFolders are the uids of the folders created by the copy.
+	CreatedFolders []string `json:"createdFolders"`
+	// LibraryPanels are the uids of the library panels created by the copy.
+	LibraryPanels []string            `json:"libraryPanels"`
+	DataSources   []DataSourceMapping `json:"dataSources"`
+	// UnmappedDataSources are the names of the data sources without a data
+	// source of the same name in the target organization, their references
+	// are kept as they are.
+	UnmappedDataSources []string            `json:"unmappedDataSources"`
+	SkippedPermissions  []SkippedPermission `json:"skippedPermissions"`
+}
+
+// DataSourceMapping is a data source of the source organization replaced with
+// the data source of the same name of the target organization.
+type DataSourceMapping struct {
+	Name      string `json:"name"`
+	SourceUID string `json:"sourceUid"`
+	TargetUID string `json:"targetUid"`
+	Type      string `json:"type"`
+}
+
+// SkippedPermission is a permission that has no user or team to be granted to
+// in the target organization.
+type SkippedPermission struct {
+	// UID is the uid of the dashboard or folder of the permission.
+	UID        string `json:"uid"`
+	UserLogin  string `json:"userLogin,omitempty"`
+	Team       string `json:"team,omitempty"`
+	Permission string `json:"permission"`
+}
+
+func (cmd *CopyDashboardCommand) Validate() error {
+	if cmd.SourceOrgID <= 0 || cmd.TargetOrgID <= 0 {
+		return fmt.Errorf("%w: sourceOrgId and targetOrgId are required", ErrInvalidCopy)
+	}
+	if cmd.SourceOrgID == cmd.TargetOrgID {
+		return fmt.Errorf("%w: the target organization must be different from the source organization", ErrInvalidCopy)
+	}
+	if cmd.UID == "" {
+		return fmt.Errorf("%w: uid is required", ErrInvalidCopy)
+	}
+	if cmd.FolderUID != "" && cmd.CopyFolder {
+		return fmt.Errorf("%w: folderUid and copyFolder cannot be used together", ErrInvalidCopy)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package dashboardlinks
+
+import (
+	"context"
+)
+
+// Service finds the links of dashboards that are broken: links to dashboards and
+// folders that no longer exist, references to data sources that no longer exist
+// and panels of plugins that aren't installed.
+type Service interface {
+	// Check checks the dashboards of the organization and saves the report.
+	Check(ctx context.Context, cmd *CheckCommand) (*Report, error)
+	// GetReport returns the report of the last check of the organization.
+	GetReport(ctx context.Context, query *GetReportQuery) (*Report, error)
+}
@@ -0,0 +1,190 @@
+package dashboardlinksimpl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+)
+
+// builtInDataSources are the data sources that don't exist in the data_source
+// table: mixed, the built-in Grafana and Dashboard data sources, and expressions.
+var builtInDataSources = map[string]bool{
+	"-- Mixed --":     true,
+	"-- Grafana --":   true,
+	"-- Dashboard --": true,
+	"grafana":         true,
+	"__expr__":        true,
+	"-100":            true,
+}
+
+// references are the resources of an organization dashboards can link to.
+type references struct {
+	// dashboards maps the uids of the dashboards and folders to whether they are a folder.
+	dashboards      map[string]bool
+	dataSourceUIDs  map[string]bool
+	dataSourceNames map[string]bool
+	// panelPlugins is empty when the installed plugins aren't known, panels
+	// are not checked then.
+	panelPlugins map[string]bool
+	// appURL and appSubURL are used to recognize absolute links to this instance.
+	appURL    *url.URL
+	appSubURL string
+}
+
+// checker finds the broken links of a dashboard.
+type checker struct {
+	refs   *references
+	dash   *models.Dashboard
+	report *dashboardlinks.Report
+	// seen deduplicates the broken links to the same target in a panel, such as
+	// all queries of a panel referencing the same missing data source.
+	seen map[string]bool
+}
+
+func checkDashboard(refs *references, dash *models.Dashboard, report *dashboardlinks.Report) {
+	c := &checker{refs: refs, dash: dash, report: report, seen: map[string]bool{}}
+	data := dash.Data
+
+	for i := range data.Get("links").MustArray() {
+		link := data.Get("links").GetIndex(i)
+		if link.Get("type").MustString() == "link" {
+			c.checkURL(nil, fmt.Sprintf("links[%d].url", i), link.Get("url").MustString())
+		}
+	}
+	c.checkPanels(data.Get("panels"), "panels")
+	for i := range data.GetPath("templating", "list").MustArray() {
+		c.checkDataSource(nil, fmt.Sprintf("templating.list[%d].datasource", i), data.GetPath("templating", "list").GetIndex(i).Get("datasource"))
+	}
+	for i := range data.GetPath("annotations", "list").MustArray() {
+		c.checkDataSource(nil, fmt.Sprintf("annotations.list[%d].datasource", i), data.GetPath("annotations", "list").GetIndex(i).Get("datasource"))
+	}
+}
+
+func (c *checker) checkPanels(panels *simplejson.Json, path string) {
+	for i := range panels.MustArray() {
+		panel := panels.GetIndex(i)
+		panelPath := fmt.Sprintf("%s[%d]", path, i)
+
+		panelType := panel.Get("type").MustString()
+		if panelType == "row" {
+			// the panels of collapsed rows are nested in the row
+			c.checkPanels(panel.Get("panels"), panelPath+".panels")
+			continue
+		}
+		if len(c.refs.panelPlugins) > 0 && panelType != "" && !c.refs.panelPlugins[panelType] {
+			c.add(panel, dashboardlinks.ProblemKindPanelPlugin, panelPath+".type", panelType)
+		}
+
+		c.checkDataSource(panel, panelPath+".datasource", panel.Get("datasource"))
+		for j := range panel.Get("targets").MustArray() {
+			c.checkDataSource(panel, fmt.Sprintf("%s.targets[%d].datasource", panelPath, j), panel.Get("targets").GetIndex(j).Get("datasource"))
+		}
+
+		c.checkLinks(panel, panelPath+".links", panel.Get("links"))
+		c.checkLinks(panel, panelPath+".fieldConfig.defaults.links", panel.GetPath("fieldConfig", "defaults", "links"))
+		overrides := panel.GetPath("fieldConfig", "overrides")
+		for j := range overrides.MustArray() {
+			properties := overrides.GetIndex(j).Get("properties")
+			for k := range properties.MustArray() {
+				property := properties.GetIndex(k)
+				if property.Get("id").MustString() == "links" {
+					c.checkLinks(panel, fmt.Sprintf("%s.fieldConfig.overrides[%d].properties[%d].value", panelPath, j, k), property.Get("value"))
+				}
+			}
+		}
+	}
+}
+
+func (c *checker) checkLinks(panel *simplejson.Json, path string, links *simplejson.Json) {
+	for i := range links.MustArray() {
+		c.checkURL(panel, fmt.Sprintf("%s[%d].url", path, i), links.GetIndex(i).Get("url").MustString())
+	}
+}
+
+// checkURL checks links to dashboards (/d/<uid>) and folders (/dashboards/f/<uid>)
+// of this instance. Links with a uid set by a variable can't be checked.
+func (c *checker) checkURL(panel *simplejson.Json, path string, raw string) {
+	if raw == "" {
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return
+	}
+	if u.Host != "" && (c.refs.appURL == nil || !strings.EqualFold(u.Host, c.refs.appURL.Host)) {
+		return
+	}
+
+	p := u.Path
+	if c.refs.appSubURL != "" {
+		p = strings.TrimPrefix(p, c.refs.appSubURL)
+	}
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+
+	var kind dashboardlinks.ProblemKind
+	var uid string
+	switch {
+	case len(segments) >= 2 && (segments[0] == "d" || segments[0] == "d-solo"):
+		kind, uid = dashboardlinks.ProblemKindDashboardLink, segments[1]
+	case len(segments) >= 3 && segments[0] == "dashboards" && segments[1] == "f":
+		kind, uid = dashboardlinks.ProblemKindFolderLink, segments[2]
+	default:
+		return
+	}
+	if uid == "" || strings.Contains(uid, "$") {
+		return
+	}
+
+	isFolder, exists := c.refs.dashboards[uid]
+	if exists && isFolder == (kind == dashboardlinks.ProblemKindFolderLink) {
+		return
+	}
+	c.add(panel, kind, path, uid)
+}
+
+// checkDataSource checks a reference to a data source by uid, or by name
+// before schema version 33. References set by a variable can't be checked.
+func (c *checker) checkDataSource(panel *simplejson.Json, path string, ref *simplejson.Json) {
+	if name, err := ref.String(); err == nil {
+		if name == "" || name == "default" || strings.HasPrefix(name, "$") || builtInDataSources[name] {
+			return
+		}
+		if !c.refs.dataSourceNames[name] {
+			c.add(panel, dashboardlinks.ProblemKindDataSource, path, name)
+		}
+		return
+	}
+
+	uid := ref.Get("uid").MustString()
+	if uid == "" || strings.HasPrefix(uid, "$") || builtInDataSources[uid] {
+		return
+	}
+	if !c.refs.dataSourceUIDs[uid] {
+		c.add(panel, dashboardlinks.ProblemKindDataSource, path, uid)
+	}
+}
+
+func (c *checker) add(panel *simplejson.Json, kind dashboardlinks.ProblemKind, path, target string) {
+	p := &dashboardlinks.Problem{
+		Kind:           kind,
+		DashboardUID:   c.dash.Uid,
+		DashboardTitle: c.dash.Title,
+		Path:           path,
+		Target:         target,
+	}
+	if panel != nil {
+		p.PanelID = panel.Get("id").MustInt64()
+		p.PanelTitle = panel.Get("title").MustString()
+	}
+
+	key := fmt.Sprintf("%s/%d/%s", kind, p.PanelID, target)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.report.Add(p)
+}
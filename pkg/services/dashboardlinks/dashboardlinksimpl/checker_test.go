@@ -0,0 +1,114 @@
+package dashboardlinksimpl
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+)
+
+func TestCheckDashboard(t *testing.T) {
+	appURL, err := url.Parse("https://grafana.example.com/grafana/")
+	require.NoError(t, err)
+	refs := &references{
+		dashboards:      map[string]bool{"existing": false, "folder": true},
+		dataSourceUIDs:  map[string]bool{"prom": true},
+		dataSourceNames: map[string]bool{"Prometheus": true},
+		panelPlugins:    map[string]bool{"timeseries": true, "text": true},
+		appURL:          appURL,
+		appSubURL:       "/grafana",
+	}
+
+	data, err := simplejson.NewJson([]byte(`{
+		"links": [
+			{ "type": "link", "url": "/grafana/d/existing/ok" },
+			{ "type": "link", "url": "/grafana/d/deleted/gone" },
+			{ "type": "link", "url": "https://grafana.example.com/grafana/dashboards/f/missing-folder/" },
+			{ "type": "link", "url": "https://other.example.com/d/deleted" },
+			{ "type": "dashboards", "tags": ["team"] }
+		],
+		"panels": [
+			{
+				"id": 1, "title": "Requests", "type": "timeseries",
+				"datasource": { "type": "prometheus", "uid": "deleted-ds" },
+				"targets": [
+					{ "refId": "A", "datasource": { "type": "prometheus", "uid": "deleted-ds" } },
+					{ "refId": "B", "datasource": { "type": "__expr__", "uid": "__expr__" } }
+				],
+				"fieldConfig": {
+					"defaults": { "links": [ { "url": "/d/${__data.fields.uid}" }, { "url": "/d/existing" } ] },
+					"overrides": [
+						{ "properties": [ { "id": "links", "value": [ { "url": "/dashboards/f/existing" } ] } ] }
+					]
+				}
+			},
+			{
+				"type": "row", "collapsed": true,
+				"panels": [
+					{ "id": 2, "title": "Legacy", "type": "grafana-worldmap-panel", "datasource": "Removed" },
+					{ "id": 3, "type": "text", "datasource": "$ds" }
+				]
+			}
+		],
+		"templating": { "list": [ { "name": "ds", "type": "datasource", "query": "prometheus" }, { "name": "job", "type": "query", "datasource": { "uid": "prom" } } ] },
+		"annotations": { "list": [ { "builtIn": 1, "datasource": { "type": "grafana", "uid": "-- Grafana --" } } ] }
+	}`))
+	require.NoError(t, err)
+
+	report := &dashboardlinks.Report{}
+	checkDashboard(refs, &models.Dashboard{Uid: "dash", Title: "Dash", Data: data}, report)
+
+	require.Equal(t, []*dashboardlinks.Problem{
+		{
+			Kind: dashboardlinks.ProblemKindDataSource, Severity: dashboardlinks.SeverityError,
+			DashboardUID: "dash", DashboardTitle: "Dash", PanelID: 1, PanelTitle: "Requests",
+			Path: "panels[0].datasource", Target: "deleted-ds",
+		},
+		{
+			Kind: dashboardlinks.ProblemKindPanelPlugin, Severity: dashboardlinks.SeverityError,
+			DashboardUID: "dash", DashboardTitle: "Dash", PanelID: 2, PanelTitle: "Legacy",
+			Path: "panels[1].panels[0].type", Target: "grafana-worldmap-panel",
+		},
+		{
+			Kind: dashboardlinks.ProblemKindDataSource, Severity: dashboardlinks.SeverityError,
+			DashboardUID: "dash", DashboardTitle: "Dash", PanelID: 2, PanelTitle: "Legacy",
+			Path: "panels[1].panels[0].datasource", Target: "Removed",
+		},
+	}, report.Errors)
+
+	require.Equal(t, []*dashboardlinks.Problem{
+		{
+			Kind: dashboardlinks.ProblemKindDashboardLink, Severity: dashboardlinks.SeverityWarning,
+			DashboardUID: "dash", DashboardTitle: "Dash",
+			Path: "links[1].url", Target: "deleted",
+		},
+		{
+			Kind: dashboardlinks.ProblemKindFolderLink, Severity: dashboardlinks.SeverityWarning,
+			DashboardUID: "dash", DashboardTitle: "Dash",
+			Path: "links[2].url", Target: "missing-folder",
+		},
+		{
+			// a dashboard isn't a folder
+			Kind: dashboardlinks.ProblemKindFolderLink, Severity: dashboardlinks.SeverityWarning,
+			DashboardUID: "dash", DashboardTitle: "Dash", PanelID: 1, PanelTitle: "Requests",
+			Path: "panels[0].fieldConfig.overrides[0].properties[0].value[0].url", Target: "existing",
+		},
+	}, report.Warnings)
+}
+
+func TestCheckDashboard_UnknownPanelPlugins(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{"id": 1, "type": "anything"},
+		},
+	})
+
+	report := &dashboardlinks.Report{}
+	checkDashboard(&references{}, &models.Dashboard{Uid: "dash", Data: data}, report)
+	require.Empty(t, report.Errors)
+	require.Empty(t, report.Warnings)
+}
@@ -0,0 +1,198 @@
+package dashboardlinksimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/dashboardlinks"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "dashboardlinks"
+	reportKey   = "report"
+	// batchSize is the number of dashboards loaded at once by a check.
+	batchSize = 100
+)
+
+type Service struct {
+	cfg               *setting.Cfg
+	store             store
+	kv                kvstore.KVStore
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	pluginStore       plugins.Store
+	serverLock        *serverlock.ServerLockService
+	log               log.Logger
+	now               func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, kv kvstore.KVStore, dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService, pluginStore plugins.Store,
+	serverLock *serverlock.ServerLockService) *Service {
+	return &Service{
+		cfg:               cfg,
+		store:             &sqlStore{db: db},
+		kv:                kv,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		pluginStore:       pluginStore,
+		serverLock:        serverLock,
+		log:               log.New("dashboardlinks"),
+		now:               time.Now,
+	}
+}
+
+// IsDisabled returns true when the dashboards aren't checked in the background.
+func (s *Service) IsDisabled() bool {
+	return s.cfg.DashboardLinkCheck.Interval <= 0
+}
+
+// Run checks the dashboards of every organization on a schedule. A single
+// server of a cluster runs the check, the reports are shared through the
+// database.
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.cfg.DashboardLinkCheck.Interval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := s.serverLock.LockAndExecute(ctx, "check dashboard links", interval, func(ctx context.Context) {
+			s.checkAll(ctx)
+		})
+		if err != nil {
+			s.log.Error("Failed to lock and execute dashboard link check", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) checkAll(ctx context.Context) {
+	orgIDs, err := s.store.GetOrgIDs(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the organizations to check the dashboard links of", "error", err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		report, err := s.Check(ctx, &dashboardlinks.CheckCommand{OrgID: orgID})
+		if err != nil {
+			s.log.Error("Failed to check dashboard links", "orgId", orgID, "error", err)
+			continue
+		}
+		if len(report.Errors) > 0 || len(report.Warnings) > 0 {
+			s.log.Info("Found broken dashboard links", "orgId", orgID, "errors", len(report.Errors), "warnings", len(report.Warnings))
+		}
+	}
+}
+
+func (s *Service) Check(ctx context.Context, cmd *dashboardlinks.CheckCommand) (*dashboardlinks.Report, error) {
+	refs, err := s.references(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dashboardlinks.Report{
+		CheckedAt: s.now(),
+		Errors:    make([]*dashboardlinks.Problem, 0),
+		Warnings:  make([]*dashboardlinks.Problem, 0),
+	}
+	var afterID int64
+	for {
+		ids, err := s.store.ListDashboardIDs(ctx, cmd.OrgID, afterID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		afterID = ids[len(ids)-1]
+
+		// the dashboard service loads the bodies kept in the dashboard storage
+		query := models.GetDashboardsQuery{DashboardIds: ids}
+		if err := s.dashboardService.GetDashboards(ctx, &query); err != nil {
+			return nil, err
+		}
+		for _, dash := range query.Result {
+			if dash.Data == nil {
+				continue
+			}
+			checkDashboard(refs, dash, report)
+			report.Dashboards++
+		}
+		if len(ids) < batchSize {
+			break
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.kv.Set(ctx, cmd.OrgID, kvNamespace, reportKey, string(data)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) GetReport(ctx context.Context, query *dashboardlinks.GetReportQuery) (*dashboardlinks.Report, error) {
+	data, exists, err := s.kv.Get(ctx, query.OrgID, kvNamespace, reportKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, dashboardlinks.ErrReportNotFound
+	}
+	report := &dashboardlinks.Report{}
+	if err := json.Unmarshal([]byte(data), report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// references returns the dashboards, folders, data sources and panel plugins
+// the dashboards of the organization can link to.
+func (s *Service) references(ctx context.Context, orgID int64) (*references, error) {
+	uids, err := s.store.GetDashboardUIDs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	dsQuery := models.GetDataSourcesQuery{OrgId: orgID}
+	if err := s.dataSourceService.GetDataSources(ctx, &dsQuery); err != nil {
+		return nil, err
+	}
+
+	refs := &references{
+		dashboards:      uids,
+		dataSourceUIDs:  make(map[string]bool, len(dsQuery.Result)),
+		dataSourceNames: make(map[string]bool, len(dsQuery.Result)),
+		panelPlugins:    map[string]bool{},
+		appSubURL:       strings.TrimSuffix(s.cfg.AppSubURL, "/"),
+	}
+	for _, ds := range dsQuery.Result {
+		refs.dataSourceUIDs[ds.Uid] = true
+		refs.dataSourceNames[ds.Name] = true
+	}
+	for _, p := range s.pluginStore.Plugins(ctx, plugins.Panel) {
+		refs.panelPlugins[p.ID] = true
+	}
+	if appURL, err := url.Parse(s.cfg.AppURL); err == nil && appURL.Host != "" {
+		refs.appURL = appURL
+	}
+	return refs, nil
+}
@@ -0,0 +1,59 @@
+package dashboardlinksimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// ListDashboardIDs returns the ids of the dashboards of the organization
+	// greater than afterID, ordered by id. Folders aren't returned.
+	ListDashboardIDs(ctx context.Context, orgID int64, afterID int64, limit int) ([]int64, error)
+	// GetDashboardUIDs returns the uids of the dashboards and folders of the
+	// organization, mapped to whether they are a folder.
+	GetDashboardUIDs(ctx context.Context, orgID int64) (map[string]bool, error)
+	GetOrgIDs(ctx context.Context) ([]int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) ListDashboardIDs(ctx context.Context, orgID int64, afterID int64, limit int) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT id FROM dashboard
+			WHERE org_id = ? AND is_folder = ? AND id > ?
+			ORDER BY id
+			LIMIT ?`, orgID, s.db.GetDialect().BooleanStr(false), afterID, limit).Find(&ids)
+	})
+	return ids, err
+}
+
+func (s *sqlStore) GetDashboardUIDs(ctx context.Context, orgID int64) (map[string]bool, error) {
+	var rows []struct {
+		Uid      string
+		IsFolder bool
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT uid, is_folder FROM dashboard WHERE org_id = ?", orgID).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	uids := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		uids[row.Uid] = row.IsFolder
+	}
+	return uids, nil
+}
+
+func (s *sqlStore) GetOrgIDs(ctx context.Context) ([]int64, error) {
+	orgIDs := make([]int64, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL("SELECT id FROM org").Find(&orgIDs)
+	})
+	return orgIDs, err
+}
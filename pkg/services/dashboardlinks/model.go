@@ -0,0 +1,93 @@
+package dashboardlinks
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrReportNotFound = errors.New("the dashboards of the organization haven't been checked yet")
+
+// Severity is how much a broken link affects a dashboard.
+type Severity string
+
+const (
+	// SeverityError is for broken links that prevent panels from showing data.
+	SeverityError Severity = "error"
+	// SeverityWarning is for broken links users can navigate to.
+	SeverityWarning Severity = "warning"
+)
+
+// ProblemKind is the kind of resource a broken link points to.
+type ProblemKind string
+
+const (
+	// ProblemKindDashboardLink is a dashboard, panel or data link to a dashboard that doesn't exist.
+	ProblemKindDashboardLink ProblemKind = "dashboardLink"
+	// ProblemKindFolderLink is a dashboard, panel or data link to a folder that doesn't exist.
+	ProblemKindFolderLink ProblemKind = "folderLink"
+	// ProblemKindDataSource is a panel, query, variable or annotation referencing
+	// a data source that doesn't exist.
+	ProblemKindDataSource ProblemKind = "dataSource"
+	// ProblemKindPanelPlugin is a panel of a plugin that isn't installed.
+	ProblemKindPanelPlugin ProblemKind = "panelPlugin"
+)
+
+// Severity returns the severity of the broken links of the kind.
+func (k ProblemKind) Severity() Severity {
+	switch k {
+	case ProblemKindDataSource, ProblemKindPanelPlugin:
+		return SeverityError
+	default:
+		return SeverityWarning
+	}
+}
+
+// Problem is a broken link of a dashboard.
+type Problem struct {
+	Kind           ProblemKind `json:"kind"`
+	Severity       Severity    `json:"severity"`
+	DashboardUID   string      `json:"dashboardUid"`
+	DashboardTitle string      `json:"dashboardTitle"`
+	PanelID        int64       `json:"panelId,omitempty"`
+	PanelTitle     string      `json:"panelTitle,omitempty"`
+	// Path is where the link is in the JSON model of the dashboard, such as
+	// panels[2].targets[0].datasource.
+	Path string `json:"path"`
+	// Target is the uid of the missing dashboard, folder or data source, the
+	// name of the missing data source, or the id of the missing panel plugin.
+	Target string `json:"target"`
+}
+
+// Report lists the broken links of the dashboards of an organization, grouped by severity.
+type Report struct {
+	CheckedAt  time.Time  `json:"checkedAt"`
+	Dashboards int        `json:"dashboards"`
+	Errors     []*Problem `json:"errors"`
+	Warnings   []*Problem `json:"warnings"`
+}
+
+// Add adds a broken link to the group of its severity.
+func (r *Report) Add(p *Problem) {
+	p.Severity = p.Kind.Severity()
+	if p.Severity == SeverityError {
+		r.Errors = append(r.Errors, p)
+		return
+	}
+	r.Warnings = append(r.Warnings, p)
+}
+
+// ----------------------
+// COMMANDS
+
+// CheckCommand checks the links of the dashboards of an organization.
+type CheckCommand struct {
+	OrgID int64
+}
+
+// ----------------------
+// QUERIES
+
+// GetReportQuery returns the report of the last check of an organization.
+type GetReportQuery struct {
+	OrgID int64
+}
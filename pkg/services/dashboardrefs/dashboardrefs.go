@@ -0,0 +1,13 @@
+package dashboardrefs
+
+import (
+	"context"
+)
+
+// Service keeps the resources referencing dashboards and folders, such as
+// alert rules, in sync when the uid of a dashboard or a folder changes, and
+// repairs references that were left broken.
+type Service interface {
+	// Scan looks for references to missing dashboards and folders and repairs them.
+	Scan(ctx context.Context, cmd *ScanCommand) (*ScanResult, error)
+}
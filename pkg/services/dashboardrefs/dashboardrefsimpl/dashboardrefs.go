@@ -0,0 +1,153 @@
+package dashboardrefsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardrefs"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type Service struct {
+	store store
+	log   log.Logger
+}
+
+func ProvideService(db db.DB, bus bus.Bus) dashboardrefs.Service {
+	s := &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		log: log.New("dashboardrefs"),
+	}
+	bus.AddEventListener(s.handleDashboardUIDChanged)
+	return s
+}
+
+// handleDashboardUIDChanged updates the alert rules referencing the dashboard
+// or the folder by uid, the library panel connections and annotations
+// reference dashboards by id and don't need to be updated.
+func (s *Service) handleDashboardUIDChanged(ctx context.Context, evt *events.DashboardUIDChanged) error {
+	if evt.IsFolder {
+		moved, err := s.store.MoveAlertRules(ctx, evt.OrgID, evt.OldUID, evt.NewUID)
+		if err != nil {
+			s.log.Error("Failed to move alert rules to the new folder uid", "orgId", evt.OrgID, "oldUid", evt.OldUID, "newUid", evt.NewUID, "error", err)
+			return err
+		}
+		if moved > 0 {
+			s.log.Info("Moved alert rules to the new folder uid", "orgId", evt.OrgID, "oldUid", evt.OldUID, "newUid", evt.NewUID, "rules", moved)
+		}
+		return nil
+	}
+
+	relinked, err := s.store.RelinkAlertRules(ctx, evt.OrgID, evt.OldUID, evt.NewUID)
+	if err != nil {
+		s.log.Error("Failed to link alert rules to the new dashboard uid", "orgId", evt.OrgID, "oldUid", evt.OldUID, "newUid", evt.NewUID, "error", err)
+		return err
+	}
+	if relinked > 0 {
+		s.log.Info("Linked alert rules to the new dashboard uid", "orgId", evt.OrgID, "oldUid", evt.OldUID, "newUid", evt.NewUID, "rules", relinked)
+	}
+	return nil
+}
+
+func (s *Service) Scan(ctx context.Context, cmd *dashboardrefs.ScanCommand) (*dashboardrefs.ScanResult, error) {
+	result := &dashboardrefs.ScanResult{
+		DryRun: cmd.DryRun,
+		Refs:   make([]*dashboardrefs.BrokenRef, 0),
+	}
+
+	if err := s.scanAlertRules(ctx, cmd, result); err != nil {
+		return nil, err
+	}
+	if err := s.scanLibraryPanelConnections(ctx, cmd, result); err != nil {
+		return nil, err
+	}
+	if err := s.scanAnnotations(ctx, cmd, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) scanAlertRules(ctx context.Context, cmd *dashboardrefs.ScanCommand, result *dashboardrefs.ScanResult) error {
+	unlinked, err := s.store.ListUnlinkedAlertRules(ctx, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range unlinked {
+		result.Add(&dashboardrefs.BrokenRef{
+			Kind:         dashboardrefs.RefKindAlertRule,
+			UID:          rule.UID,
+			Title:        rule.Title,
+			DashboardUID: *rule.DashboardUID,
+			Repairable:   true,
+			Repaired:     !cmd.DryRun,
+		})
+	}
+	if len(unlinked) > 0 && !cmd.DryRun {
+		if err := s.store.UnlinkAlertRules(ctx, unlinked); err != nil {
+			return err
+		}
+	}
+
+	orphaned, err := s.store.ListOrphanedAlertRules(ctx, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range orphaned {
+		result.Add(&dashboardrefs.BrokenRef{
+			Kind:         dashboardrefs.RefKindAlertRuleFolder,
+			UID:          rule.UID,
+			Title:        rule.Title,
+			DashboardUID: rule.NamespaceUID,
+		})
+	}
+	return nil
+}
+
+func (s *Service) scanLibraryPanelConnections(ctx context.Context, cmd *dashboardrefs.ScanCommand, result *dashboardrefs.ScanResult) error {
+	connections, err := s.store.ListBrokenLibraryPanelConnections(ctx, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	if len(connections) > 0 && !cmd.DryRun {
+		if err := s.store.DeleteLibraryPanelConnections(ctx, connections); err != nil {
+			return err
+		}
+	}
+	for _, c := range connections {
+		result.Add(&dashboardrefs.BrokenRef{
+			Kind:        dashboardrefs.RefKindLibraryPanelConnection,
+			UID:         c.Uid,
+			Title:       c.Name,
+			DashboardID: c.ConnectionId,
+			Repairable:  true,
+			Repaired:    !cmd.DryRun,
+		})
+	}
+	return nil
+}
+
+func (s *Service) scanAnnotations(ctx context.Context, cmd *dashboardrefs.ScanCommand, result *dashboardrefs.ScanResult) error {
+	counts, err := s.store.ListBrokenAnnotations(ctx, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, c := range counts {
+		if !cmd.DryRun {
+			if err := s.store.DeleteAnnotations(ctx, cmd.OrgID, c.DashboardId); err != nil {
+				return err
+			}
+		}
+		result.Add(&dashboardrefs.BrokenRef{
+			Kind:        dashboardrefs.RefKindAnnotation,
+			DashboardID: c.DashboardId,
+			Count:       c.Count,
+			Repairable:  true,
+			Repaired:    !cmd.DryRun,
+		})
+	}
+	return nil
+}
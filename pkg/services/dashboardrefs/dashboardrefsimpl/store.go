@@ -0,0 +1,209 @@
+package dashboardrefsimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// RelinkAlertRules links the alert rules of a dashboard to its new uid.
+	RelinkAlertRules(ctx context.Context, orgID int64, oldUID, newUID string) (int, error)
+	// MoveAlertRules moves the alert rules of a folder to its new uid.
+	MoveAlertRules(ctx context.Context, orgID int64, oldUID, newUID string) (int, error)
+
+	// ListUnlinkedAlertRules returns the alert rules linked to a dashboard that doesn't exist.
+	ListUnlinkedAlertRules(ctx context.Context, orgID int64) ([]*ngmodels.AlertRule, error)
+	// UnlinkAlertRules removes the dashboard and panel of the alert rules.
+	UnlinkAlertRules(ctx context.Context, rules []*ngmodels.AlertRule) error
+	// ListOrphanedAlertRules returns the alert rules stored in a folder that doesn't exist.
+	ListOrphanedAlertRules(ctx context.Context, orgID int64) ([]*ngmodels.AlertRule, error)
+
+	// ListBrokenLibraryPanelConnections returns the connections of library panels to dashboards that don't exist.
+	ListBrokenLibraryPanelConnections(ctx context.Context, orgID int64) ([]*libraryPanelConnection, error)
+	DeleteLibraryPanelConnections(ctx context.Context, connections []*libraryPanelConnection) error
+
+	// ListBrokenAnnotations returns the number of annotations per dashboard that doesn't exist.
+	ListBrokenAnnotations(ctx context.Context, orgID int64) ([]*annotationCount, error)
+	DeleteAnnotations(ctx context.Context, orgID int64, dashboardID int64) error
+}
+
+type libraryPanelConnection struct {
+	Id           int64
+	ConnectionId int64
+	Uid          string
+	Name         string
+}
+
+type annotationCount struct {
+	DashboardId int64
+	Count       int64
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) RelinkAlertRules(ctx context.Context, orgID int64, oldUID, newUID string) (int, error) {
+	var updated int
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rules := make([]*ngmodels.AlertRule, 0)
+		if err := sess.Table("alert_rule").Where("org_id = ? AND dashboard_uid = ?", orgID, oldUID).Find(&rules); err != nil {
+			return err
+		}
+		updated = len(rules)
+		return updateAlertRules(sess, rules, func(rule *ngmodels.AlertRule) {
+			rule.DashboardUID = &newUID
+			if _, ok := rule.Annotations[ngmodels.DashboardUIDAnnotation]; ok {
+				rule.Annotations[ngmodels.DashboardUIDAnnotation] = newUID
+			}
+		})
+	})
+	return updated, err
+}
+
+func (s *sqlStore) MoveAlertRules(ctx context.Context, orgID int64, oldUID, newUID string) (int, error) {
+	var updated int
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rules := make([]*ngmodels.AlertRule, 0)
+		if err := sess.Table("alert_rule").Where("org_id = ? AND namespace_uid = ?", orgID, oldUID).Find(&rules); err != nil {
+			return err
+		}
+		updated = len(rules)
+		return updateAlertRules(sess, rules, func(rule *ngmodels.AlertRule) {
+			rule.NamespaceUID = newUID
+		})
+	})
+	return updated, err
+}
+
+func (s *sqlStore) ListUnlinkedAlertRules(ctx context.Context, orgID int64) ([]*ngmodels.AlertRule, error) {
+	rules := make([]*ngmodels.AlertRule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_rule").
+			Where("org_id = ? AND dashboard_uid IS NOT NULL AND dashboard_uid <> ''", orgID).
+			Where("NOT EXISTS (SELECT 1 FROM dashboard WHERE dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.dashboard_uid)").
+			OrderBy("id ASC").
+			Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *sqlStore) UnlinkAlertRules(ctx context.Context, rules []*ngmodels.AlertRule) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return updateAlertRules(sess, rules, func(rule *ngmodels.AlertRule) {
+			rule.DashboardUID = nil
+			rule.PanelID = nil
+			delete(rule.Annotations, ngmodels.DashboardUIDAnnotation)
+			delete(rule.Annotations, ngmodels.PanelIDAnnotation)
+		})
+	})
+}
+
+func (s *sqlStore) ListOrphanedAlertRules(ctx context.Context, orgID int64) ([]*ngmodels.AlertRule, error) {
+	rules := make([]*ngmodels.AlertRule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_rule").
+			Where("org_id = ?", orgID).
+			Where("NOT EXISTS (SELECT 1 FROM dashboard WHERE dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.namespace_uid AND dashboard.is_folder = ?)",
+				s.db.GetDialect().BooleanStr(true)).
+			OrderBy("id ASC").
+			Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *sqlStore) ListBrokenLibraryPanelConnections(ctx context.Context, orgID int64) ([]*libraryPanelConnection, error) {
+	result := make([]*libraryPanelConnection, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT lec.id, lec.connection_id, le.uid, le.name
+			FROM `+models.LibraryElementConnectionTableName+` AS lec
+			INNER JOIN library_element AS le ON le.id = lec.element_id
+			WHERE le.org_id = ? AND lec.kind = 1
+			AND NOT EXISTS (SELECT 1 FROM dashboard WHERE dashboard.id = lec.connection_id)
+			ORDER BY lec.id`, orgID).Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) DeleteLibraryPanelConnections(ctx context.Context, connections []*libraryPanelConnection) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, c := range connections {
+			if _, err := sess.Exec("DELETE FROM "+models.LibraryElementConnectionTableName+" WHERE id = ?", c.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) ListBrokenAnnotations(ctx context.Context, orgID int64) ([]*annotationCount, error) {
+	result := make([]*annotationCount, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT annotation.dashboard_id, COUNT(*) AS count
+			FROM annotation
+			WHERE annotation.org_id = ? AND annotation.dashboard_id > 0
+			AND NOT EXISTS (SELECT 1 FROM dashboard WHERE dashboard.id = annotation.dashboard_id)
+			GROUP BY annotation.dashboard_id
+			ORDER BY annotation.dashboard_id`, orgID).Find(&result)
+	})
+	return result, err
+}
+
+func (s *sqlStore) DeleteAnnotations(ctx context.Context, orgID int64, dashboardID int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM annotation_tag WHERE annotation_id IN (SELECT id FROM annotation WHERE org_id = ? AND dashboard_id = ?)", orgID, dashboardID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM annotation WHERE org_id = ? AND dashboard_id = ?", orgID, dashboardID)
+		return err
+	})
+}
+
+// updateAlertRules changes the alert rules and saves them as new versions, so
+// that the scheduler picks up the change.
+func updateAlertRules(sess *sqlstore.DBSession, rules []*ngmodels.AlertRule, change func(rule *ngmodels.AlertRule)) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	for _, rule := range rules {
+		parentVersion := rule.Version
+		change(rule)
+		rule.Updated = time.Now()
+		// xorm increases the version and fails the update if the rule was changed in between
+		if updated, err := sess.ID(rule.ID).AllCols().Update(rule); err != nil || updated == 0 {
+			if err != nil {
+				return fmt.Errorf("failed to update rule [%s] %s: %w", rule.UID, rule.Title, err)
+			}
+			return fmt.Errorf("alert rule %s was changed concurrently", rule.UID)
+		}
+		ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
+			RuleOrgID:        rule.OrgID,
+			RuleUID:          rule.UID,
+			RuleNamespaceUID: rule.NamespaceUID,
+			RuleGroup:        rule.RuleGroup,
+			ParentVersion:    parentVersion,
+			Version:          parentVersion + 1,
+			Created:          rule.Updated,
+			Condition:        rule.Condition,
+			Title:            rule.Title,
+			Data:             rule.Data,
+			IntervalSeconds:  rule.IntervalSeconds,
+			NoDataState:      rule.NoDataState,
+			ExecErrState:     rule.ExecErrState,
+			For:              rule.For,
+			Annotations:      rule.Annotations,
+			Labels:           rule.Labels,
+		})
+	}
+	if _, err := sess.Insert(&ruleVersions); err != nil {
+		return fmt.Errorf("failed to create new rule versions: %w", err)
+	}
+	return nil
+}
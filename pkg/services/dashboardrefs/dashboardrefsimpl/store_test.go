@@ -0,0 +1,211 @@
+package dashboardrefsimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDashboardRefsStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	store := &sqlStore{db: ss}
+	ctx := context.Background()
+
+	folder := insertDashboard(t, ss, "folder-uid", true)
+	dash := insertDashboard(t, ss, "dash-uid", false)
+
+	linked := insertAlertRule(t, ss, "linked", folder.Uid, dash.Uid)
+	unlinked := insertAlertRule(t, ss, "unlinked", folder.Uid, "missing-dash")
+	orphaned := insertAlertRule(t, ss, "orphaned", "missing-folder", "")
+
+	t.Run("lists alert rules linked to missing dashboards", func(t *testing.T) {
+		rules, err := store.ListUnlinkedAlertRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, unlinked.UID, rules[0].UID)
+	})
+
+	t.Run("lists alert rules in missing folders", func(t *testing.T) {
+		rules, err := store.ListOrphanedAlertRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, orphaned.UID, rules[0].UID)
+	})
+
+	t.Run("relinks alert rules to the new dashboard uid", func(t *testing.T) {
+		updated, err := store.RelinkAlertRules(ctx, 1, dash.Uid, "new-dash-uid")
+		require.NoError(t, err)
+		require.Equal(t, 1, updated)
+
+		rule := getAlertRule(t, ss, linked.ID)
+		require.Equal(t, "new-dash-uid", *rule.DashboardUID)
+		require.Equal(t, "new-dash-uid", rule.Annotations[ngmodels.DashboardUIDAnnotation])
+		require.Equal(t, linked.Version+1, rule.Version)
+		require.Equal(t, int64(1), countAlertRuleVersions(t, ss, linked.UID))
+	})
+
+	t.Run("moves alert rules to the new folder uid", func(t *testing.T) {
+		updated, err := store.MoveAlertRules(ctx, 1, folder.Uid, "new-folder-uid")
+		require.NoError(t, err)
+		require.Equal(t, 2, updated)
+
+		rule := getAlertRule(t, ss, unlinked.ID)
+		require.Equal(t, "new-folder-uid", rule.NamespaceUID)
+	})
+
+	t.Run("unlinks alert rules", func(t *testing.T) {
+		rules, err := store.ListUnlinkedAlertRules(ctx, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.UnlinkAlertRules(ctx, rules))
+
+		rule := getAlertRule(t, ss, unlinked.ID)
+		require.Nil(t, rule.DashboardUID)
+		require.Nil(t, rule.PanelID)
+		require.NotContains(t, rule.Annotations, ngmodels.DashboardUIDAnnotation)
+		require.NotContains(t, rule.Annotations, ngmodels.PanelIDAnnotation)
+		require.Equal(t, "keep", rule.Annotations["summary"])
+
+		rules, err = store.ListUnlinkedAlertRules(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+	})
+
+	t.Run("lists and deletes library panel connections of missing dashboards", func(t *testing.T) {
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			if _, err := sess.Exec(`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, created, created_by, updated, updated_by, version)
+				VALUES (1, 0, 'panel-uid', 'Panel', 1, 'graph', '', '{}', ?, 1, ?, 1, 1)`, time.Now(), time.Now()); err != nil {
+				return err
+			}
+			for _, dashboardID := range []int64{dash.Id, 999} {
+				if _, err := sess.Exec(`INSERT INTO `+models.LibraryElementConnectionTableName+` (element_id, kind, connection_id, created, created_by)
+					VALUES ((SELECT id FROM library_element WHERE uid = 'panel-uid'), 1, ?, ?, 1)`, dashboardID, time.Now()); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		connections, err := store.ListBrokenLibraryPanelConnections(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, connections, 1)
+		require.Equal(t, int64(999), connections[0].ConnectionId)
+		require.Equal(t, "panel-uid", connections[0].Uid)
+
+		require.NoError(t, store.DeleteLibraryPanelConnections(ctx, connections))
+		connections, err = store.ListBrokenLibraryPanelConnections(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, connections)
+	})
+
+	t.Run("lists and deletes annotations of missing dashboards", func(t *testing.T) {
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			for _, dashboardID := range []int64{0, dash.Id, 999, 999} {
+				if _, err := sess.Exec(`INSERT INTO annotation (org_id, dashboard_id, panel_id, type, title, text, prev_state, new_state, data, epoch, epoch_end)
+					VALUES (1, ?, 0, '', '', 'text', '', '', '{}', 1, 1)`, dashboardID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		counts, err := store.ListBrokenAnnotations(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, counts, 1)
+		require.Equal(t, int64(999), counts[0].DashboardId)
+		require.Equal(t, int64(2), counts[0].Count)
+
+		require.NoError(t, store.DeleteAnnotations(ctx, 1, 999))
+		counts, err = store.ListBrokenAnnotations(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, counts)
+	})
+}
+
+func insertDashboard(t *testing.T, ss *sqlstore.SQLStore, uid string, isFolder bool) *models.Dashboard {
+	t.Helper()
+	dash := &models.Dashboard{
+		OrgId:    1,
+		Uid:      uid,
+		Title:    uid,
+		Slug:     uid,
+		IsFolder: isFolder,
+		Data:     simplejson.NewFromAny(map[string]interface{}{"title": uid}),
+		Version:  1,
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+	return dash
+}
+
+func insertAlertRule(t *testing.T, ss *sqlstore.SQLStore, uid, namespaceUID, dashboardUID string) *ngmodels.AlertRule {
+	t.Helper()
+	rule := &ngmodels.AlertRule{
+		OrgID:           1,
+		UID:             uid,
+		Title:           uid,
+		Condition:       "A",
+		Data:            []ngmodels.AlertQuery{},
+		Updated:         time.Now(),
+		IntervalSeconds: 60,
+		NamespaceUID:    namespaceUID,
+		RuleGroup:       "group",
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+		Annotations:     map[string]string{"summary": "keep"},
+		Labels:          map[string]string{},
+	}
+	if dashboardUID != "" {
+		panelID := int64(1)
+		rule.DashboardUID = &dashboardUID
+		rule.PanelID = &panelID
+		rule.Annotations[ngmodels.DashboardUIDAnnotation] = dashboardUID
+		rule.Annotations[ngmodels.PanelIDAnnotation] = "1"
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("alert_rule").Insert(rule)
+		return err
+	})
+	require.NoError(t, err)
+	return rule
+}
+
+func getAlertRule(t *testing.T, ss *sqlstore.SQLStore, id int64) *ngmodels.AlertRule {
+	t.Helper()
+	rule := &ngmodels.AlertRule{}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		has, err := sess.Table("alert_rule").ID(id).Get(rule)
+		require.True(t, has)
+		return err
+	})
+	require.NoError(t, err)
+	return rule
+}
+
+func countAlertRuleVersions(t *testing.T, ss *sqlstore.SQLStore, uid string) int64 {
+	t.Helper()
+	var count int64
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var err error
+		count, err = sess.Table("alert_rule_version").Where("rule_uid = ?", uid).Count()
+		return err
+	})
+	require.NoError(t, err)
+	return count
+}
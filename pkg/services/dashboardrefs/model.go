@@ -0,0 +1,63 @@
+package dashboardrefs
+
+// RefKind is the kind of resource referencing a dashboard or a folder.
+type RefKind string
+
+const (
+	// RefKindAlertRule is an alert rule linked to a dashboard panel.
+	RefKindAlertRule RefKind = "alertRule"
+	// RefKindAlertRuleFolder is an alert rule stored in a folder.
+	RefKindAlertRuleFolder RefKind = "alertRuleFolder"
+	// RefKindLibraryPanelConnection is a library panel used by a dashboard.
+	RefKindLibraryPanelConnection RefKind = "libraryPanelConnection"
+	// RefKindAnnotation is an annotation of a dashboard.
+	RefKindAnnotation RefKind = "annotation"
+)
+
+// BrokenRef is a reference to a dashboard or a folder that doesn't exist.
+type BrokenRef struct {
+	Kind RefKind `json:"kind"`
+	// UID is the uid of the alert rule or the library panel with the broken reference.
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title,omitempty"`
+	// DashboardUID is set for references by uid: alert rules and their folders.
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	// DashboardID is set for references by id: library panel connections and annotations.
+	DashboardID int64 `json:"dashboardId,omitempty"`
+	// Count is the number of annotations of the missing dashboard.
+	Count int64 `json:"count,omitempty"`
+	// Repairable is false for references that need to be fixed by hand, such as
+	// alert rules stored in a folder that no longer exists.
+	Repairable bool `json:"repairable"`
+	Repaired   bool `json:"repaired"`
+}
+
+// ScanResult lists the broken references found by a scan.
+type ScanResult struct {
+	DryRun   bool         `json:"dryRun"`
+	Found    int          `json:"found"`
+	Repaired int          `json:"repaired"`
+	Refs     []*BrokenRef `json:"refs"`
+}
+
+// Add adds a broken reference to the totals.
+func (r *ScanResult) Add(ref *BrokenRef) {
+	r.Found++
+	if ref.Repaired {
+		r.Repaired++
+	}
+	r.Refs = append(r.Refs, ref)
+}
+
+// ----------------------
+// COMMANDS
+
+// ScanCommand looks for references to dashboards and folders of an
+// organization that no longer exist and repairs them. Alert rules linked to a
+// missing dashboard are unlinked, library panel connections and annotations
+// of missing dashboards are deleted.
+type ScanCommand struct {
+	OrgID int64 `json:"-"`
+	// DryRun reports the broken references without repairing them.
+	DryRun bool `json:"dryRun"`
+}
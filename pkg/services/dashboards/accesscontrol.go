@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -19,8 +20,9 @@ const (
 	ActionFoldersPermissionsRead  = "folders.permissions:read"
 	ActionFoldersPermissionsWrite = "folders.permissions:write"
 
-	ScopeDashboardsRoot   = "dashboards"
-	ScopeDashboardsPrefix = "dashboards:uid:"
+	ScopeDashboardsRoot      = "dashboards"
+	ScopeDashboardsPrefix    = "dashboards:uid:"
+	ScopeDashboardsTagPrefix = "dashboards:tag:"
 
 	ActionDashboardsCreate           = "dashboards:create"
 	ActionDashboardsRead             = "dashboards:read"
@@ -52,7 +54,7 @@ func NewFolderNameScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
 		if err != nil {
 			return nil, err
 		}
-		return []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}, nil
+		return resolveFolderScope(ctx, db, orgID, folder)
 	})
 }
 
@@ -78,10 +80,59 @@ func NewFolderIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
 			return nil, err
 		}
 
-		return []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}, nil
+		return resolveFolderScope(ctx, db, orgID, folder)
 	})
 }
 
+// NewFolderUIDScopeResolver provides an ScopeAttributeResolver that is able to convert a scope prefixed with "folders:uid:"
+// into uid based scopes for the folder and the folders containing it.
+func NewFolderUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
+	prefix := ScopeFoldersProvider.GetResourceScopeUID("")
+	return prefix, ac.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, scope string) ([]string, error) {
+		if !strings.HasPrefix(scope, prefix) {
+			return nil, ac.ErrInvalidScope
+		}
+
+		uid, err := ac.ParseScopeUID(scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if uid == ac.GeneralFolderUID || uid == "*" {
+			return []string{scope}, nil
+		}
+
+		folder, err := db.GetFolderByUID(ctx, orgID, uid)
+		if err != nil {
+			// the scope of a folder that doesn't exist (anymore) is evaluated as is
+			if errors.Is(err, models.ErrFolderNotFound) {
+				return []string{scope}, nil
+			}
+			return nil, err
+		}
+
+		return resolveFolderScope(ctx, db, orgID, folder)
+	})
+}
+
+// resolveFolderScope returns the scopes of the folder and of the folders containing it, the permissions on a folder
+// apply to the folders nested in it.
+func resolveFolderScope(ctx context.Context, db Store, orgID int64, folder *models.Folder) ([]string, error) {
+	scopes := []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}
+	if folder.ParentId == 0 {
+		return scopes, nil
+	}
+
+	ancestors, err := db.GetFolderAncestors(ctx, orgID, folder.Id)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		scopes = append(scopes, ScopeFoldersProvider.GetResourceScopeUID(ancestors[i].Uid))
+	}
+	return scopes, nil
+}
+
 // NewDashboardIDScopeResolver provides an ScopeAttributeResolver that is able to convert a scope prefixed with "dashboards:id:"
 // into uid based scopes for both dashboard and folder
 func NewDashboardIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
@@ -129,18 +180,23 @@ func NewDashboardUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver)
 }
 
 func resolveDashboardScope(ctx context.Context, db Store, orgID int64, dashboard *models.Dashboard) ([]string, error) {
-	var folderUID string
-	if dashboard.FolderId == 0 {
-		folderUID = ac.GeneralFolderUID
-	} else {
+	folderScopes := []string{ScopeFoldersProvider.GetResourceScopeUID(ac.GeneralFolderUID)}
+	if dashboard.FolderId != 0 {
 		folder, err := db.GetFolderByID(ctx, orgID, dashboard.FolderId)
 		if err != nil {
 			return nil, err
 		}
-		folderUID = folder.Uid
+		folderScopes, err = resolveFolderScope(ctx, db, orgID, folder)
+		if err != nil {
+			return nil, err
+		}
+	}
+	scopes := append([]string{ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid)}, folderScopes...)
+	// permissions scoped to a tag of the dashboard, e.g. "dashboards:tag:payments", apply to it
+	if dashboard.Data != nil {
+		for _, tag := range dashboard.GetTags() {
+			scopes = append(scopes, ScopeDashboardsTagPrefix+tag)
+		}
 	}
-	return []string{
-		ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid),
-		ScopeFoldersProvider.GetResourceScopeUID(folderUID),
-	}, nil
+	return scopes, nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/util"
@@ -145,6 +146,53 @@ func TestNewFolderIDScopeResolver(t *testing.T) {
 	})
 }
 
+func TestNewFolderUIDScopeResolver(t *testing.T) {
+	t.Run("prefix should be expected", func(t *testing.T) {
+		prefix, _ := NewFolderUIDScopeResolver(&FakeDashboardStore{})
+		require.Equal(t, "folders:uid:", prefix)
+	})
+
+	t.Run("resolver should include the folders containing the folder", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderUIDScopeResolver(dashboardStore)
+
+		orgId := rand.Int63()
+		folder := &models.Folder{Id: 3, Uid: "grandchild", ParentId: 2}
+		dashboardStore.On("GetFolderByUID", mock.Anything, orgId, folder.Uid).Return(folder, nil).Once()
+		dashboardStore.On("GetFolderAncestors", mock.Anything, orgId, folder.Id).Return([]*models.Folder{
+			{Id: 1, Uid: "root"},
+			{Id: 2, Uid: "child", ParentId: 1},
+		}, nil).Once()
+
+		resolved, err := resolver.Resolve(context.Background(), orgId, "folders:uid:grandchild")
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:grandchild", "folders:uid:child", "folders:uid:root"}, resolved)
+	})
+
+	t.Run("resolver should not look up folders in the root", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderUIDScopeResolver(dashboardStore)
+
+		dashboardStore.On("GetFolderByUID", mock.Anything, mock.Anything, "root").Return(&models.Folder{Id: 1, Uid: "root"}, nil).Once()
+
+		resolved, err := resolver.Resolve(context.Background(), rand.Int63(), "folders:uid:root")
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:root"}, resolved)
+		dashboardStore.AssertNotCalled(t, "GetFolderAncestors", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resolver should keep the scope of folders that don't exist", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderUIDScopeResolver(dashboardStore)
+
+		dashboardStore.On("GetFolderByUID", mock.Anything, mock.Anything, "deleted").Return(nil, models.ErrFolderNotFound).Once()
+
+		resolved, err := resolver.Resolve(context.Background(), rand.Int63(), "folders:uid:deleted")
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:deleted"}, resolved)
+	})
+}
+
 func TestNewDashboardIDScopeResolver(t *testing.T) {
 	t.Run("prefix should be expected", func(t *testing.T) {
 		prefix, _ := NewDashboardIDScopeResolver(&FakeDashboardStore{})
@@ -237,3 +285,31 @@ func TestNewDashboardUIDScopeResolver(t *testing.T) {
 		require.Equal(t, "folders:uid:general", resolved[1])
 	})
 }
+
+func TestDashboardTagScopes(t *testing.T) {
+	store := &FakeDashboardStore{}
+	_, resolver := NewDashboardUIDScopeResolver(store)
+
+	dashboard := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"uid":  "1",
+		"tags": []interface{}{"payments", "team:billing"},
+	}))
+	store.On("GetDashboard", mock.Anything, mock.Anything).Return(dashboard, nil)
+
+	resolved, err := resolver.Resolve(context.Background(), 1, ac.Scope("dashboards", "uid", "1"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"dashboards:uid:1", "folders:uid:general", "dashboards:tag:payments", "dashboards:tag:team:billing"}, resolved)
+
+	t.Run("permissions scoped to a tag of the dashboard apply to it", func(t *testing.T) {
+		permissions := map[string][]string{ActionDashboardsRead: {"dashboards:tag:payments"}}
+		evaluator := ac.EvalPermission(ActionDashboardsRead, "dashboards:uid:1")
+		require.False(t, evaluator.Evaluate(permissions))
+
+		evaluator, err := evaluator.MutateScopes(context.Background(), func(ctx context.Context, scope string) ([]string, error) {
+			return resolver.Resolve(ctx, 1, scope)
+		})
+		require.NoError(t, err)
+		require.True(t, evaluator.Evaluate(permissions))
+		require.False(t, evaluator.Evaluate(map[string][]string{ActionDashboardsRead: {"dashboards:tag:team"}}))
+	})
+}
@@ -0,0 +1,96 @@
+// Package bodystorage stores the JSON bodies of dashboards outside of the
+// database, e.g. in an object store, for instances with many or large
+// dashboards.
+package bodystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrBodyNotFound  = errors.New("dashboard body not found")
+	ErrNotConfigured = errors.New("the body of the dashboard is kept in the dashboard storage, but [dashboards.storage] isn't configured")
+)
+
+// StubField is the field of the data column of a dashboard or dashboard version
+// referencing its body kept in the body storage. The data column then only
+// holds a stub with the metadata other services read from it directly.
+const StubField = "__storage"
+
+// Backend stores dashboard bodies by key. Keys are relative slash separated
+// paths, see Key.
+type Backend interface {
+	// Type returns the type of the backend as configured in [dashboards.storage].
+	Type() string
+	// Get returns the body stored with the key, or ErrBodyNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores the body with the key, replacing the body stored with it before.
+	Put(ctx context.Context, key string, body []byte) error
+	// Delete deletes the body stored with the key. It doesn't fail when there is
+	// no body stored with the key.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the backend configured in [dashboards.storage], or nil when the
+// bodies are stored in the database.
+func New(cfg setting.DashboardStorageSettings) Backend {
+	switch cfg.Type {
+	case setting.DashboardStorageFilesystem:
+		return newFilesystem(cfg.FilesystemPath)
+	case setting.DashboardStorageS3:
+		return newS3(cfg)
+	case setting.DashboardStorageGCS:
+		return newGCS(cfg)
+	default:
+		return nil
+	}
+}
+
+// Key returns the key of a body of the dashboard of the organization with the
+// uid that isn't versioned, e.g. the body of an archived dashboard.
+func Key(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s.json", orgID, uid)
+}
+
+// VersionKey returns the key the body of the version of the dashboard of the
+// organization with the uid is stored with. Every version has its own key, so
+// that saving a dashboard never replaces a body committed rows reference.
+func VersionKey(orgID int64, uid string, version int) string {
+	return fmt.Sprintf("%d/%s/%d.json", orgID, uid, version)
+}
+
+// StubKey returns the key of the body the stub references, false if data is
+// the body itself.
+func StubKey(data *simplejson.Json) (string, bool) {
+	if data == nil {
+		return "", false
+	}
+	ref, ok := data.CheckGet(StubField)
+	if !ok {
+		return "", false
+	}
+	key := ref.Get("key").MustString()
+	return key, key != ""
+}
+
+// Load returns the body data references when it's a stub, otherwise data. The
+// backend is nil when [dashboards.storage] isn't configured.
+func Load(ctx context.Context, backend Backend, data *simplejson.Json) (*simplejson.Json, error) {
+	key, ok := StubKey(data)
+	if !ok {
+		return data, nil
+	}
+	if backend == nil {
+		return nil, ErrNotConfigured
+	}
+	body, err := backend.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard body %q: %w", key, err)
+	}
+	return simplejson.NewJson(body)
+}
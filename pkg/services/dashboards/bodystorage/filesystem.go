@@ -0,0 +1,89 @@
+package bodystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// filesystem stores the bodies as files in a directory, e.g. on a volume
+// shared by the instances of a highly available setup.
+type filesystem struct {
+	root string
+}
+
+func newFilesystem(root string) *filesystem {
+	return &filesystem{root: root}
+}
+
+func (f *filesystem) Type() string {
+	return setting.DashboardStorageFilesystem
+}
+
+func (f *filesystem) path(key string) (string, error) {
+	p := filepath.Join(f.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(p, filepath.Clean(f.root)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid dashboard body key %q", key)
+	}
+	return p, nil
+}
+
+func (f *filesystem) Get(_ context.Context, key string) ([]byte, error) {
+	p, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	// nolint:gosec
+	// The path is made of the organization id and the uid of the dashboard.
+	body, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBodyNotFound
+	}
+	return body, err
+}
+
+func (f *filesystem) Put(_ context.Context, key string, body []byte) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never see a partial body
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (f *filesystem) Delete(_ context.Context, key string) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package bodystorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestFilesystem(t *testing.T) {
+	ctx := context.Background()
+	backend := New(setting.DashboardStorageSettings{Type: setting.DashboardStorageFilesystem, FilesystemPath: t.TempDir()})
+	key := Key(1, "service")
+
+	_, err := backend.Get(ctx, key)
+	require.ErrorIs(t, err, ErrBodyNotFound)
+
+	require.NoError(t, backend.Put(ctx, key, []byte(`{"title":"Service"}`)))
+	require.NoError(t, backend.Put(ctx, key, []byte(`{"title":"Service v2"}`)))
+	body, err := backend.Get(ctx, key)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Service v2"}`, string(body))
+
+	require.NoError(t, backend.Delete(ctx, key))
+	_, err = backend.Get(ctx, key)
+	require.ErrorIs(t, err, ErrBodyNotFound)
+	// deleting a missing body succeeds
+	require.NoError(t, backend.Delete(ctx, key))
+
+	err = backend.Put(ctx, "../escape.json", []byte(`{}`))
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(setting.DashboardStorageSettings{Type: setting.DashboardStorageDatabase}))
+	assert.Equal(t, setting.DashboardStorageS3, New(setting.DashboardStorageSettings{Type: setting.DashboardStorageS3}).Type())
+	assert.Equal(t, setting.DashboardStorageGCS, New(setting.DashboardStorageSettings{Type: setting.DashboardStorageGCS}).Type())
+}
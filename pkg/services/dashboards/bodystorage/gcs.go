@@ -0,0 +1,101 @@
+package bodystorage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// gcsBackend stores the bodies as objects of a Google Cloud Storage bucket.
+type gcsBackend struct {
+	cfg setting.DashboardStorageSettings
+
+	mu     sync.Mutex
+	client *storage.Client
+}
+
+func newGCS(cfg setting.DashboardStorageSettings) *gcsBackend {
+	return &gcsBackend{cfg: cfg}
+}
+
+func (b *gcsBackend) Type() string {
+	return setting.DashboardStorageGCS
+}
+
+// getClient returns the GCS client, creating it on first use. The default
+// application credentials are used when no key file is configured.
+func (b *gcsBackend) getClient() (*storage.Client, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.client != nil {
+		return b.client, nil
+	}
+
+	opts := []option.ClientOption{option.WithScopes(storage.ScopeReadWrite)}
+	if b.cfg.GCSKeyFile != "" {
+		opts = append(opts, option.WithCredentialsFile(b.cfg.GCSKeyFile))
+	}
+	// the client outlives the request it's created for
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.client = client
+	return b.client, nil
+}
+
+func (b *gcsBackend) object(key string) (*storage.ObjectHandle, error) {
+	client, err := b.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Bucket(b.cfg.GCSBucket).Object(path.Join(b.cfg.GCSPath, key)), nil
+}
+
+func (b *gcsBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	obj, err := b.object(key)
+	if err != nil {
+		return nil, err
+	}
+	r, err := obj.NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrBodyNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+func (b *gcsBackend) Put(ctx context.Context, key string, body []byte) error {
+	obj, err := b.object(key)
+	if err != nil {
+		return err
+	}
+	w := obj.NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(body); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *gcsBackend) Delete(ctx context.Context, key string) error {
+	obj, err := b.object(key)
+	if err != nil {
+		return err
+	}
+	if err := obj.Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return err
+	}
+	return nil
+}
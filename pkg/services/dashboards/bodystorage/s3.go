@@ -0,0 +1,129 @@
+package bodystorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// s3Backend stores the bodies as objects of an S3 bucket, or of a bucket of an
+// S3 compatible object store when the endpoint is set.
+type s3Backend struct {
+	cfg setting.DashboardStorageSettings
+
+	mu     sync.Mutex
+	client *s3.S3
+}
+
+func newS3(cfg setting.DashboardStorageSettings) *s3Backend {
+	return &s3Backend{cfg: cfg}
+}
+
+func (b *s3Backend) Type() string {
+	return setting.DashboardStorageS3
+}
+
+// getClient returns the S3 client, creating it on first use.
+func (b *s3Backend) getClient() (*s3.S3, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.client != nil {
+		return b.client, nil
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	providers := []credentials.Provider{}
+	if b.cfg.S3AccessKey != "" {
+		providers = append(providers, &credentials.StaticProvider{Value: credentials.Value{
+			AccessKeyID:     b.cfg.S3AccessKey,
+			SecretAccessKey: b.cfg.S3SecretKey,
+		}})
+	}
+	providers = append(providers,
+		&credentials.EnvProvider{},
+		// nolint:staticcheck
+		stscreds.NewWebIdentityRoleProvider(sts.New(sess), os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_ROLE_SESSION_NAME"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")),
+		defaults.RemoteCredProvider(*defaults.Config(), defaults.Handlers()),
+	)
+
+	cfg := &aws.Config{
+		Region:           aws.String(b.cfg.S3Region),
+		S3ForcePathStyle: aws.Bool(b.cfg.S3PathStyleAccess),
+		Credentials:      credentials.NewChainCredentials(providers),
+	}
+	if b.cfg.S3Endpoint != "" {
+		cfg.Endpoint = aws.String(b.cfg.S3Endpoint)
+	}
+	sess, err = session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b.client = s3.New(sess)
+	return b.client, nil
+}
+
+func (b *s3Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	client, err := b.getClient()
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.cfg.S3Bucket),
+		Key:    aws.String(path.Join(b.cfg.S3Path, key)),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrBodyNotFound
+		}
+		return nil, err
+	}
+	defer func() { _ = out.Body.Close() }()
+	return io.ReadAll(out.Body)
+}
+
+func (b *s3Backend) Put(ctx context.Context, key string, body []byte) error {
+	client, err := b.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.cfg.S3Bucket),
+		Key:         aws.String(path.Join(b.cfg.S3Path, key)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	client, err := b.getClient()
+	if err != nil {
+		return err
+	}
+	// deleting an object that doesn't exist succeeds
+	_, err = client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.cfg.S3Bucket),
+		Key:    aws.String(path.Join(b.cfg.S3Path, key)),
+	})
+	return err
+}
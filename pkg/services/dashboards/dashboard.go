@@ -10,7 +10,7 @@ import (
 //go:generate mockery --name DashboardService --structname FakeDashboardService --inpackage --filename dashboard_service_mock.go
 // DashboardService is a service for operating on dashboards.
 type DashboardService interface {
-	BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error)
+	BuildPublicDashboardMetricRequest(ctx context.Context, access *models.PublicDashboardAccess, panelId int64, query *PublicDashboardQueryDTO) (dtos.MetricRequest, error)
 	BuildSaveDashboardCommand(ctx context.Context, dto *SaveDashboardDTO, shouldValidateAlerts bool, validateProvisionedDashboard bool) (*models.SaveDashboardCommand, error)
 	DeleteDashboard(ctx context.Context, dashboardId int64, orgId int64) error
 	FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error)
@@ -19,12 +19,13 @@ type DashboardService interface {
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
 	GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error
-	GetPublicDashboard(ctx context.Context, publicDashboardUid string) (*models.Dashboard, error)
+	GetPublicDashboard(ctx context.Context, accessToken string) (*models.PublicDashboard, *models.Dashboard, error)
 	GetPublicDashboardConfig(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error)
 	HasAdminPermissionInFolders(ctx context.Context, query *models.HasAdminPermissionInFoldersQuery) error
 	HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermissionInFoldersQuery) error
 	ImportDashboard(ctx context.Context, dto *SaveDashboardDTO) (*models.Dashboard, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, dashboardID int64, setViewAndEditPermissions bool) error
+	RotatePublicDashboardAccessToken(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error)
 	SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error)
 	SavePublicDashboardConfig(ctx context.Context, dto *SavePublicDashboardConfigDTO) (*models.PublicDashboardConfig, error)
 	SearchDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) error
@@ -66,14 +67,15 @@ type Store interface {
 	GetProvisionedDataByDashboardID(dashboardID int64) (*models.DashboardProvisioning, error)
 	GetProvisionedDataByDashboardUID(orgID int64, dashboardUID string) (*models.DashboardProvisioning, error)
 	GetPublicDashboardConfig(orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error)
-	GetPublicDashboard(uid string) (*models.PublicDashboard, *models.Dashboard, error)
+	GetPublicDashboard(accessToken string) (*models.PublicDashboard, *models.Dashboard, error)
 	HasAdminPermissionInFolders(ctx context.Context, query *models.HasAdminPermissionInFoldersQuery) error
 	HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermissionInFoldersQuery) error
+	RotatePublicDashboardAccessToken(cmd models.RotatePublicDashboardAccessTokenCommand) (*models.PublicDashboard, error)
 	// SaveAlerts saves dashboard alerts.
 	SaveAlerts(ctx context.Context, dashID int64, alerts []*models.Alert) error
-	SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dashboard, error)
-	SaveProvisionedDashboard(cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error)
-	SavePublicDashboardConfig(cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error)
+	SaveDashboard(ctx context.Context, cmd models.SaveDashboardCommand) (*models.Dashboard, error)
+	SaveProvisionedDashboard(ctx context.Context, cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error)
+	SavePublicDashboardConfig(ctx context.Context, cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error)
 	UnprovisionDashboard(ctx context.Context, id int64) error
 	UpdateDashboardACL(ctx context.Context, uid int64, items []*models.DashboardAcl) error
 	// ValidateDashboardBeforeSave validates a dashboard before save.
@@ -91,4 +93,10 @@ type FolderStore interface {
 	GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error)
 	// GetFolderByID retrieves a folder by its ID
 	GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error)
+	// GetFolderAncestors retrieves the folders containing a folder, from the
+	// folder in the root to its parent.
+	GetFolderAncestors(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error)
+	// GetFolderDescendants retrieves the folders nested in a folder, the
+	// parents before their subfolders.
+	GetFolderDescendants(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error)
 }
@@ -18,20 +18,20 @@ type FakeDashboardService struct {
 	mock.Mock
 }
 
-// BuildPublicDashboardMetricRequest provides a mock function with given fields: ctx, publicDashboardUid, panelId
-func (_m *FakeDashboardService) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error) {
-	ret := _m.Called(ctx, publicDashboardUid, panelId)
+// BuildPublicDashboardMetricRequest provides a mock function with given fields: ctx, access, panelId, query
+func (_m *FakeDashboardService) BuildPublicDashboardMetricRequest(ctx context.Context, access *models.PublicDashboardAccess, panelId int64, query *PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
+	ret := _m.Called(ctx, access, panelId, query)
 
 	var r0 dtos.MetricRequest
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) dtos.MetricRequest); ok {
-		r0 = rf(ctx, publicDashboardUid, panelId)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardAccess, int64, *PublicDashboardQueryDTO) dtos.MetricRequest); ok {
+		r0 = rf(ctx, access, panelId, query)
 	} else {
 		r0 = ret.Get(0).(dtos.MetricRequest)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
-		r1 = rf(ctx, publicDashboardUid, panelId)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicDashboardAccess, int64, *PublicDashboardQueryDTO) error); ok {
+		r1 = rf(ctx, access, panelId, query)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -169,27 +169,36 @@ func (_m *FakeDashboardService) GetDashboards(ctx context.Context, query *models
 	return r0
 }
 
-// GetPublicDashboard provides a mock function with given fields: ctx, publicDashboardUid
-func (_m *FakeDashboardService) GetPublicDashboard(ctx context.Context, publicDashboardUid string) (*models.Dashboard, error) {
-	ret := _m.Called(ctx, publicDashboardUid)
+// GetPublicDashboard provides a mock function with given fields: ctx, accessToken
+func (_m *FakeDashboardService) GetPublicDashboard(ctx context.Context, accessToken string) (*models.PublicDashboard, *models.Dashboard, error) {
+	ret := _m.Called(ctx, accessToken)
 
-	var r0 *models.Dashboard
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Dashboard); ok {
-		r0 = rf(ctx, publicDashboardUid)
+	var r0 *models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicDashboard); ok {
+		r0 = rf(ctx, accessToken)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Dashboard)
+			r0 = ret.Get(0).(*models.PublicDashboard)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, publicDashboardUid)
+	var r1 *models.Dashboard
+	if rf, ok := ret.Get(1).(func(context.Context, string) *models.Dashboard); ok {
+		r1 = rf(ctx, accessToken)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.Dashboard)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, accessToken)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // GetPublicDashboardConfig provides a mock function with given fields: ctx, orgId, dashboardUid
@@ -280,6 +289,29 @@ func (_m *FakeDashboardService) MakeUserAdmin(ctx context.Context, orgID int64,
 	return r0
 }
 
+// RotatePublicDashboardAccessToken provides a mock function with given fields: ctx, orgId, dashboardUid
+func (_m *FakeDashboardService) RotatePublicDashboardAccessToken(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error) {
+	ret := _m.Called(ctx, orgId, dashboardUid)
+
+	var r0 *models.PublicDashboardConfig
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *models.PublicDashboardConfig); ok {
+		r0 = rf(ctx, orgId, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardConfig)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveDashboard provides a mock function with given fields: ctx, dto, allowUiUpdate
 func (_m *FakeDashboardService) SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error) {
 	ret := _m.Called(ctx, dto, allowUiUpdate)
@@ -5,13 +5,14 @@ import (
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/permissions"
 )
 
 // GetDashboardAclInfoList returns a list of permissions for a dashboard. They can be fetched from three
 // different places.
 // 1) Permissions for the dashboard
-// 2) permissions for its parent folder
-// 3) if no specific permissions have been set for the dashboard or its parent folder then get the default permissions
+// 2) permissions for the folders containing it, up to the folder in the root
+// 3) if no specific permissions have been set for the dashboard or the folders containing it then get the default permissions
 func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *models.GetDashboardAclInfoListQuery) error {
 	outerErr := d.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		query.Result = make([]*models.DashboardAclInfoDTO, 0)
@@ -41,8 +42,13 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 			return dbSession.SQL(sql).Find(&query.Result)
 		}
 
+		folderDepth, err := getFolderDepth(dbSession, d.dialect, query.OrgID)
+		if err != nil {
+			return err
+		}
+		folderJoins, folderIDs, defaultPermissions := permissions.FolderAncestorsSQL("d", d.dialect, folderDepth)
 		rawSQL := `
-			-- get permissions for the dashboard and its parent folder
+			-- get permissions for the dashboard and the folders containing it
 			SELECT
 				da.id,
 				da.org_id,
@@ -61,18 +67,14 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 				d.slug,
 				d.uid,
 				d.is_folder,
-				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR da.dashboard_id = d.folder_id THEN ` + d.dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
-			FROM dashboard as d
-				LEFT JOIN dashboard folder on folder.id = d.folder_id
+				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR da.dashboard_id IN (` + folderIDs + `) THEN ` + d.dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
+			FROM dashboard as d` + folderJoins + `
 				LEFT JOIN dashboard_acl AS da ON
 				da.dashboard_id = d.id OR
-				da.dashboard_id = d.folder_id OR
+				da.dashboard_id IN (` + folderIDs + `) OR
 				(
 					-- include default permissions -->
-					da.org_id = -1 AND (
-					  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-					  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
-					)
+					da.org_id = -1 AND (` + defaultPermissions + `)
 				)
 				LEFT JOIN ` + d.dialect.Quote("user") + ` AS u ON u.id = da.user_id
 				LEFT JOIN team ug on ug.id = da.team_id
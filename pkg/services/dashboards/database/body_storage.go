@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// stubFields are the fields of the body kept in the stub.
+var stubFields = []string{"uid", "title", "tags", "version", "schemaVersion"}
+
+const bodyMigrationBatchSize = 100
+
+// storesBody returns whether the body of the dashboard is kept in the body
+// storage when saving it. The bodies of folders are always kept in the
+// database.
+func (d *DashboardStore) storesBody(dash *models.Dashboard) bool {
+	return d.bodies != nil && !dash.IsFolder
+}
+
+func (d *DashboardStore) bodyStub(body *simplejson.Json, key string) *simplejson.Json {
+	stub := simplejson.New()
+	for _, field := range stubFields {
+		if value, ok := body.CheckGet(field); ok {
+			stub.Set(field, value.Interface())
+		}
+	}
+	stub.Set(bodystorage.StubField, map[string]interface{}{
+		"type": d.bodies.Type(),
+		"key":  key,
+	})
+	return stub
+}
+
+func (d *DashboardStore) putBody(ctx context.Context, key string, body *simplejson.Json) error {
+	data, err := body.Encode()
+	if err != nil {
+		return err
+	}
+	if err := d.bodies.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("failed to store dashboard body %q: %w", key, err)
+	}
+	return nil
+}
+
+// loadBodies replaces the stubs of the dashboards whose body is kept in the
+// body storage with their body.
+func (d *DashboardStore) loadBodies(ctx context.Context, dashboards ...*models.Dashboard) error {
+	for _, dash := range dashboards {
+		body, err := bodystorage.Load(ctx, d.bodies, dash.Data)
+		if err != nil {
+			return err
+		}
+		dash.Data = body
+	}
+	return nil
+}
+
+// storedBodyKeys returns the keys of the bodies of the dashboards with the ids
+// and of their versions kept in the body storage.
+func (d *DashboardStore) storedBodyKeys(sess *sqlstore.DBSession, dashboardIDs ...int64) ([]string, error) {
+	keys := []string{}
+	if d.bodies == nil || len(dashboardIDs) == 0 {
+		return keys, nil
+	}
+	var rows []*models.Dashboard
+	if err := sess.In("id", dashboardIDs).Cols("data").Find(&rows); err != nil {
+		return nil, err
+	}
+	var versions []*dashver.DashboardVersion
+	if err := sess.In("dashboard_id", dashboardIDs).Cols("data").Find(&versions); err != nil {
+		return nil, err
+	}
+	data := make([]*simplejson.Json, 0, len(rows)+len(versions))
+	for _, row := range rows {
+		data = append(data, row.Data)
+	}
+	for _, version := range versions {
+		data = append(data, version.Data)
+	}
+
+	// the current version of a dashboard and its row reference the same body
+	seen := map[string]bool{}
+	for _, d := range data {
+		if key, ok := bodystorage.StubKey(d); ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// deleteBodiesAfterCommit deletes the bodies with the keys from the body storage
+// once the transaction of the session is committed, so that the bodies of
+// dashboards whose deletion is rolled back are kept.
+func (d *DashboardStore) deleteBodiesAfterCommit(sess *sqlstore.DBSession, keys []string) {
+	if d.bodies == nil || len(keys) == 0 {
+		return
+	}
+	sess.AfterCommit(func() {
+		for _, key := range keys {
+			if err := d.bodies.Delete(context.Background(), key); err != nil {
+				d.log.Warn("Failed to delete dashboard body", "key", key, "error", err)
+			}
+		}
+	})
+}
+
+// versionBody is a version of a dashboard whose body is migrated.
+type versionBody struct {
+	ID      int64            `xorm:"id"`
+	OrgID   int64            `xorm:"org_id"`
+	UID     string           `xorm:"uid"`
+	Version int              `xorm:"version"`
+	Data    *simplejson.Json `xorm:"data"`
+}
+
+// MigrateDashboardBodies moves the bodies of the dashboards and their versions
+// kept in the database to the body storage configured in [dashboards.storage],
+// or the bodies kept in the body storage back to the database when toDatabase
+// is set. It returns the number of moved bodies. It's meant to be run while
+// Grafana is stopped, the dashboards saved while migrating are skipped.
+func (d *DashboardStore) MigrateDashboardBodies(ctx context.Context, toDatabase bool) (int, error) {
+	if d.bodies == nil {
+		return 0, errors.New("[dashboards.storage] isn't configured, the dashboard bodies are kept in the database")
+	}
+
+	moved, err := d.migrateDashboardBodies(ctx, toDatabase)
+	if err != nil {
+		return moved, err
+	}
+	// the bodies are deleted from the body storage when the versions are moved
+	// to the database, the current version of a dashboard references the same
+	// body as its row
+	movedVersions, err := d.migrateVersionBodies(ctx, toDatabase)
+	return moved + movedVersions, err
+}
+
+func (d *DashboardStore) migrateDashboardBodies(ctx context.Context, toDatabase bool) (int, error) {
+	moved := 0
+	var lastID int64
+	for {
+		dashboards := make([]*models.Dashboard, 0, bodyMigrationBatchSize)
+		err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("id > ? AND is_folder = ?", lastID, d.dialect.BooleanStr(false)).
+				OrderBy("id").Limit(bodyMigrationBatchSize).Find(&dashboards)
+		})
+		if err != nil {
+			return moved, err
+		}
+		if len(dashboards) == 0 {
+			return moved, nil
+		}
+
+		for _, dash := range dashboards {
+			lastID = dash.Id
+			data, err := d.migratedBody(ctx, dash.Data, bodystorage.VersionKey(dash.OrgId, dash.Uid, dash.Version), toDatabase)
+			if err != nil {
+				return moved, fmt.Errorf("failed to migrate the body of dashboard %q of org %d: %w", dash.Uid, dash.OrgId, err)
+			}
+			if data == nil {
+				continue
+			}
+
+			var affected int64
+			err = d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				var err error
+				affected, err = sess.ID(dash.Id).Where("version = ?", dash.Version).Cols("data").Update(&models.Dashboard{Data: data})
+				return err
+			})
+			if err != nil {
+				return moved, err
+			}
+			if affected > 0 {
+				moved++
+			}
+		}
+	}
+}
+
+func (d *DashboardStore) migrateVersionBodies(ctx context.Context, toDatabase bool) (int, error) {
+	moved := 0
+	var lastID int64
+	for {
+		versions := make([]*versionBody, 0, bodyMigrationBatchSize)
+		err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.SQL(`SELECT dashboard_version.id, dashboard.org_id, dashboard.uid, dashboard_version.version, dashboard_version.data
+				FROM dashboard_version INNER JOIN dashboard ON dashboard.id = dashboard_version.dashboard_id
+				WHERE dashboard_version.id > ? AND dashboard.is_folder = ?
+				ORDER BY dashboard_version.id`+d.dialect.Limit(bodyMigrationBatchSize),
+				lastID, d.dialect.BooleanStr(false)).Find(&versions)
+		})
+		if err != nil {
+			return moved, err
+		}
+		if len(versions) == 0 {
+			return moved, nil
+		}
+
+		for _, version := range versions {
+			lastID = version.ID
+			key, stored := bodystorage.StubKey(version.Data)
+			data, err := d.migratedBody(ctx, version.Data, bodystorage.VersionKey(version.OrgID, version.UID, version.Version), toDatabase)
+			if err != nil {
+				return moved, fmt.Errorf("failed to migrate the body of version %d of dashboard %q of org %d: %w", version.Version, version.UID, version.OrgID, err)
+			}
+			if data == nil {
+				continue
+			}
+
+			err = d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				_, err := sess.ID(version.ID).Cols("data").Update(&dashver.DashboardVersion{Data: data})
+				return err
+			})
+			if err != nil {
+				return moved, err
+			}
+			moved++
+
+			if toDatabase && stored {
+				if err := d.bodies.Delete(ctx, key); err != nil {
+					d.log.Warn("Failed to delete dashboard body moved to the database", "key", key, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// migratedBody returns the data a dashboard or version row holds once its body
+// is migrated, nil if it's already migrated. A body moved to the body storage is
+// stored with the key.
+func (d *DashboardStore) migratedBody(ctx context.Context, data *simplejson.Json, key string, toDatabase bool) (*simplejson.Json, error) {
+	_, stored := bodystorage.StubKey(data)
+	switch {
+	case toDatabase && stored:
+		return bodystorage.Load(ctx, d.bodies, data)
+	case !toDatabase && !stored:
+		if err := d.putBody(ctx, key, data); err != nil {
+			return nil, err
+		}
+		return d.bodyStub(data, key), nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationDashboardBodyStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var sqlStore *sqlstore.SQLStore
+	var dashboardStore *DashboardStore
+	var root string
+
+	setup := func() {
+		sqlStore = sqlstore.InitTestDB(t)
+		root = t.TempDir()
+		dashboardStore = ProvideDashboardStore(sqlStore)
+		dashboardStore.bodies = bodystorage.New(setting.DashboardStorageSettings{
+			Type:           setting.DashboardStorageFilesystem,
+			FilesystemPath: root,
+		})
+	}
+
+	save := func(t *testing.T, dash *models.Dashboard, folderID int64) *models.Dashboard {
+		t.Helper()
+		data := simplejson.NewFromAny(map[string]interface{}{
+			"title":  "Service",
+			"tags":   []interface{}{"prod"},
+			"panels": []interface{}{map[string]interface{}{"id": 1, "type": "timeseries"}},
+		})
+		if dash != nil {
+			data = dash.Data
+		}
+		saved, err := dashboardStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{OrgId: 1, FolderId: folderID, Dashboard: data})
+		require.NoError(t, err)
+		return saved
+	}
+
+	rowData := func(t *testing.T, id int64) *simplejson.Json {
+		t.Helper()
+		row := models.Dashboard{Id: id}
+		err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Get(&row)
+			return err
+		})
+		require.NoError(t, err)
+		return row.Data
+	}
+
+	versionData := func(t *testing.T, dashboardID int64, version int) *simplejson.Json {
+		t.Helper()
+		row := dashver.DashboardVersion{}
+		err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Where("dashboard_id = ? AND version = ?", dashboardID, version).Get(&row)
+			return err
+		})
+		require.NoError(t, err)
+		return row.Data
+	}
+
+	t.Run("Keeps a stub in the database and the body in the storage", func(t *testing.T) {
+		setup()
+		saved := save(t, nil, 0)
+
+		stub := rowData(t, saved.Id)
+		key, ok := bodystorage.StubKey(stub)
+		require.True(t, ok)
+		assert.Equal(t, bodystorage.VersionKey(1, saved.Uid, 1), key)
+		assert.Equal(t, "Service", stub.Get("title").MustString())
+		assert.Equal(t, []string{"prod"}, stub.Get("tags").MustStringArray())
+		_, hasPanels := stub.CheckGet("panels")
+		assert.False(t, hasPanels)
+		assert.FileExists(t, filepath.Join(root, "1", saved.Uid, "1.json"))
+
+		// the version only holds the stub too
+		versionKey, ok := bodystorage.StubKey(versionData(t, saved.Id, 1))
+		require.True(t, ok)
+		assert.Equal(t, key, versionKey)
+
+		// the result of the save has the body
+		assert.Len(t, saved.Data.Get("panels").MustArray(), 1)
+
+		dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{OrgId: 1, Uid: saved.Uid})
+		require.NoError(t, err)
+		assert.Len(t, dash.Data.Get("panels").MustArray(), 1)
+		assert.Equal(t, saved.Id, dash.Data.Get("id").MustInt64())
+
+		query := &models.GetDashboardsQuery{DashboardUIds: []string{saved.Uid}}
+		require.NoError(t, dashboardStore.GetDashboards(context.Background(), query))
+		require.Len(t, query.Result, 1)
+		assert.Len(t, query.Result[0].Data.Get("panels").MustArray(), 1)
+	})
+
+	t.Run("Keeps the bodies of folders in the database", func(t *testing.T) {
+		setup()
+		folder := insertTestDashboard(t, dashboardStore, "Folder", 1, 0, true)
+
+		_, ok := bodystorage.StubKey(rowData(t, folder.Id))
+		assert.False(t, ok)
+	})
+
+	t.Run("Stores the body of every version with its own key", func(t *testing.T) {
+		setup()
+		saved := save(t, nil, 0)
+		oldUID := saved.Uid
+
+		saved.Data.Set("id", saved.Id)
+		saved.Data.Set("uid", "renamed")
+		saved.Data.Set("panels", []interface{}{})
+		saved = save(t, saved, 0)
+
+		assert.Equal(t, "renamed", saved.Uid)
+		key, ok := bodystorage.StubKey(rowData(t, saved.Id))
+		require.True(t, ok)
+		assert.Equal(t, bodystorage.VersionKey(1, "renamed", 2), key)
+
+		// the first version still references its body
+		key, ok = bodystorage.StubKey(versionData(t, saved.Id, 1))
+		require.True(t, ok)
+		assert.Equal(t, bodystorage.VersionKey(1, oldUID, 1), key)
+		body, err := bodystorage.Load(context.Background(), dashboardStore.bodies, versionData(t, saved.Id, 1))
+		require.NoError(t, err)
+		assert.Len(t, body.Get("panels").MustArray(), 1)
+	})
+
+	t.Run("Keeps the body of the current version when a save is rolled back", func(t *testing.T) {
+		setup()
+		saved := save(t, nil, 0)
+
+		errRollback := errors.New("rollback")
+		err := sqlStore.InTransaction(context.Background(), func(ctx context.Context) error {
+			saved.Data.Set("id", saved.Id)
+			saved.Data.Set("panels", []interface{}{})
+			if _, err := dashboardStore.SaveDashboard(ctx, models.SaveDashboardCommand{OrgId: 1, Dashboard: saved.Data}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+
+		dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{OrgId: 1, Uid: saved.Uid})
+		require.NoError(t, err)
+		assert.Equal(t, 1, dash.Version)
+		assert.Len(t, dash.Data.Get("panels").MustArray(), 1)
+	})
+
+	t.Run("Deletes the bodies with the dashboards", func(t *testing.T) {
+		setup()
+		folder := insertTestDashboard(t, dashboardStore, "Folder", 1, 0, true)
+		inFolder := save(t, nil, folder.Id)
+		dash := insertTestDashboard(t, dashboardStore, "Other", 1, 0, false)
+
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1})
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(root, "1", dash.Uid, "1.json"))
+
+		inFolder.Data.Set("id", inFolder.Id)
+		save(t, inFolder, folder.Id)
+		assert.FileExists(t, filepath.Join(root, "1", inFolder.Uid, "2.json"))
+
+		errRollback := errors.New("rollback")
+		err = sqlStore.InTransaction(context.Background(), func(ctx context.Context) error {
+			if err := dashboardStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+		assert.FileExists(t, filepath.Join(root, "1", inFolder.Uid, "2.json"))
+
+		err = dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1})
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(root, "1", inFolder.Uid, "1.json"))
+		assert.NoFileExists(t, filepath.Join(root, "1", inFolder.Uid, "2.json"))
+	})
+
+	t.Run("Fails to get a dashboard whose body is missing", func(t *testing.T) {
+		setup()
+		saved := save(t, nil, 0)
+		require.NoError(t, os.Remove(filepath.Join(root, "1", saved.Uid, "1.json")))
+
+		_, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{OrgId: 1, Uid: saved.Uid})
+		require.ErrorIs(t, err, bodystorage.ErrBodyNotFound)
+	})
+
+	t.Run("Migrates the bodies to the storage and back", func(t *testing.T) {
+		setup()
+		backend := dashboardStore.bodies
+		dashboardStore.bodies = nil
+		first := insertTestDashboard(t, dashboardStore, "First", 1, 0, false)
+		second := insertTestDashboard(t, dashboardStore, "Second", 2, 0, false)
+		dashboardStore.bodies = backend
+
+		// the dashboards and their versions are moved
+		moved, err := dashboardStore.MigrateDashboardBodies(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 4, moved)
+		for _, dash := range []*models.Dashboard{first, second} {
+			_, ok := bodystorage.StubKey(rowData(t, dash.Id))
+			assert.True(t, ok)
+			_, ok = bodystorage.StubKey(versionData(t, dash.Id, 1))
+			assert.True(t, ok)
+			assert.FileExists(t, filepath.Join(root, filepath.FromSlash(bodystorage.VersionKey(dash.OrgId, dash.Uid, 1))))
+		}
+
+		// running it again doesn't move anything
+		moved, err = dashboardStore.MigrateDashboardBodies(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, moved)
+
+		moved, err = dashboardStore.MigrateDashboardBodies(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, 4, moved)
+		for _, dash := range []*models.Dashboard{first, second} {
+			data := rowData(t, dash.Id)
+			_, ok := bodystorage.StubKey(data)
+			assert.False(t, ok)
+			assert.Equal(t, dash.Title, data.Get("title").MustString())
+			data = versionData(t, dash.Id, 1)
+			_, ok = bodystorage.StubKey(data)
+			assert.False(t, ok)
+			assert.Equal(t, dash.Title, data.Get("title").MustString())
+			assert.NoFileExists(t, filepath.Join(root, filepath.FromSlash(bodystorage.VersionKey(dash.OrgId, dash.Uid, 1))))
+		}
+	})
+
+	t.Run("Fails to get a dashboard kept in the storage when it isn't configured", func(t *testing.T) {
+		setup()
+		saved := save(t, nil, 0)
+		dashboardStore.bodies = nil
+
+		_, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{OrgId: 1, Uid: saved.Uid})
+		require.ErrorIs(t, err, bodystorage.ErrNotConfigured)
+	})
+}
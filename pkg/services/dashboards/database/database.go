@@ -8,11 +8,13 @@ import (
 
 	"xorm.io/xorm"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
@@ -25,13 +27,19 @@ type DashboardStore struct {
 	sqlStore *sqlstore.SQLStore
 	log      log.Logger
 	dialect  migrator.Dialect
+	// bodies is nil when the dashboard bodies are kept in the database.
+	bodies bodystorage.Backend
 }
 
 // DashboardStore implements the Store interface
 var _ dashboards.Store = (*DashboardStore)(nil)
 
 func ProvideDashboardStore(sqlStore *sqlstore.SQLStore) *DashboardStore {
-	return &DashboardStore{sqlStore: sqlStore, log: log.New("dashboard-store"), dialect: sqlStore.Dialect}
+	store := &DashboardStore{sqlStore: sqlStore, log: log.New("dashboard-store"), dialect: sqlStore.Dialect}
+	if sqlStore.Cfg != nil {
+		store.bodies = bodystorage.New(sqlStore.Cfg.DashboardStorage)
+	}
+	return store
 }
 
 func (d *DashboardStore) ValidateDashboardBeforeSave(dashboard *models.Dashboard, overwrite bool) (bool, error) {
@@ -49,6 +57,10 @@ func (d *DashboardStore) ValidateDashboardBeforeSave(dashboard *models.Dashboard
 			return err
 		}
 
+		if dashboard.IsFolder && dashboard.FolderId > 0 {
+			return validateFolderParent(sess, dashboard, d.sqlStore.Dialect)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -63,11 +75,10 @@ func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, titl
 		return nil, models.ErrFolderTitleEmpty
 	}
 
-	// there is a unique constraint on org_id, folder_id, title
-	// there are no nested folders so the parent folder id is always 0
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
+	// the titles of the folders are unique in an organization, whatever their parent
+	dashboard := models.Dashboard{OrgId: orgID, Title: title}
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -82,9 +93,9 @@ func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, titl
 }
 
 func (d *DashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Id: id}
+	dashboard := models.Dashboard{OrgId: orgID, Id: id}
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -106,9 +117,9 @@ func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid st
 		return nil, models.ErrDashboardIdentifierNotSet
 	}
 
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Uid: uid}
+	dashboard := models.Dashboard{OrgId: orgID, Uid: uid}
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -125,6 +136,137 @@ func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid st
 	return models.DashboardToFolder(&dashboard), nil
 }
 
+func (d *DashboardStore) GetFolderAncestors(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	var ancestors []*models.Folder
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		ancestors, err = getFolderAncestors(sess, d.sqlStore.Dialect, orgID, id)
+		return err
+	})
+	return ancestors, err
+}
+
+func (d *DashboardStore) GetFolderDescendants(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	var descendants []*models.Folder
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		descendants, err = getFolderDescendants(sess, d.sqlStore.Dialect, orgID, id)
+		return err
+	})
+	return descendants, err
+}
+
+func getFolderAncestors(sess *sqlstore.DBSession, dialect migrator.Dialect, orgID int64, id int64) ([]*models.Folder, error) {
+	var folder models.Dashboard
+	has, err := sess.Where("org_id=? AND id=? AND is_folder=?", orgID, id, dialect.BooleanStr(true)).Get(&folder)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, models.ErrFolderNotFound
+	}
+
+	ancestors := make([]*models.Folder, 0)
+	for parentID := folder.FolderId; parentID > 0; {
+		// the nesting is validated when saving folders, this only guards against looping forever
+		if len(ancestors) >= models.MaxNestedFolderDepth {
+			return nil, fmt.Errorf("folder %d is nested deeper than %d levels", id, models.MaxNestedFolderDepth)
+		}
+
+		var parent models.Dashboard
+		has, err := sess.Where("org_id=? AND id=? AND is_folder=?", orgID, parentID, dialect.BooleanStr(true)).Get(&parent)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, models.ErrFolderNotFound
+		}
+		ancestors = append([]*models.Folder{models.DashboardToFolder(&parent)}, ancestors...)
+		parentID = parent.FolderId
+	}
+	return ancestors, nil
+}
+
+func getFolderDescendants(sess *sqlstore.DBSession, dialect migrator.Dialect, orgID int64, id int64) ([]*models.Folder, error) {
+	descendants := make([]*models.Folder, 0)
+	parentIDs := []int64{id}
+	for depth := 0; len(parentIDs) > 0; depth++ {
+		if depth >= models.MaxNestedFolderDepth {
+			return nil, fmt.Errorf("folder %d has subfolders nested deeper than %d levels", id, models.MaxNestedFolderDepth)
+		}
+
+		var children []*models.Dashboard
+		err := sess.Where("org_id=? AND is_folder=?", orgID, dialect.BooleanStr(true)).In("folder_id", parentIDs).Asc("id").Find(&children)
+		if err != nil {
+			return nil, err
+		}
+
+		parentIDs = make([]int64, 0, len(children))
+		for _, child := range children {
+			descendants = append(descendants, models.DashboardToFolder(child))
+			parentIDs = append(parentIDs, child.Id)
+		}
+	}
+	return descendants, nil
+}
+
+// getFolderDepth returns the number of levels of folders whose permissions
+// apply to the dashboards of the organization: 1 when no folder is nested, so
+// that the queries on the permissions only join the folder of the dashboards,
+// and models.MaxNestedFolderDepth otherwise.
+func getFolderDepth(sess *sqlstore.DBSession, dialect migrator.Dialect, orgID int64) (int, error) {
+	nested, err := sess.Where("org_id=? AND is_folder=? AND folder_id > 0", orgID, dialect.BooleanStr(true)).Exist(&models.Dashboard{})
+	if err != nil {
+		return 0, err
+	}
+	if !nested {
+		return 1, nil
+	}
+	return models.MaxNestedFolderDepth, nil
+}
+
+// validateFolderParent checks that a folder is not moved into itself or one of
+// its subfolders, and that neither the folder nor its subfolders end up nested
+// deeper than the maximum depth.
+func validateFolderParent(sess *sqlstore.DBSession, folder *models.Dashboard, dialect migrator.Dialect) error {
+	ancestors, err := getFolderAncestors(sess, dialect, folder.OrgId, folder.FolderId)
+	if err != nil {
+		return err
+	}
+
+	// the parent, and the folder itself
+	depth := len(ancestors) + 2
+	if folder.Id > 0 {
+		if folder.FolderId == folder.Id {
+			return models.ErrDashboardFolderCyclicParent
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.Id == folder.Id {
+				return models.ErrDashboardFolderCyclicParent
+			}
+		}
+
+		descendants, err := getFolderDescendants(sess, dialect, folder.OrgId, folder.Id)
+		if err != nil {
+			return err
+		}
+		levels := map[int64]int{folder.Id: 0}
+		height := 0
+		for _, descendant := range descendants {
+			levels[descendant.Id] = levels[descendant.ParentId] + 1
+			if levels[descendant.Id] > height {
+				height = levels[descendant.Id]
+			}
+		}
+		depth += height
+	}
+
+	if depth > models.MaxNestedFolderDepth {
+		return models.ErrDashboardFolderMaxDepthExceeded
+	}
+	return nil
+}
+
 func (d *DashboardStore) GetProvisionedDataByDashboardID(dashboardID int64) (*models.DashboardProvisioning, error) {
 	var data models.DashboardProvisioning
 	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
@@ -170,9 +312,9 @@ func (d *DashboardStore) GetProvisionedDashboardData(name string) ([]*models.Das
 	return result, err
 }
 
-func (d *DashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error) {
-	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		if err := saveDashboard(sess, &cmd); err != nil {
+func (d *DashboardStore) SaveProvisionedDashboard(ctx context.Context, cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error) {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := d.saveDashboard(sess, &cmd); err != nil {
 			return err
 		}
 
@@ -186,9 +328,9 @@ func (d *DashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardComman
 	return cmd.Result, err
 }
 
-func (d *DashboardStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dashboard, error) {
-	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		return saveDashboard(sess, &cmd)
+func (d *DashboardStore) SaveDashboard(ctx context.Context, cmd models.SaveDashboardCommand) (*models.Dashboard, error) {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return d.saveDashboard(sess, &cmd)
 	})
 	return cmd.Result, err
 }
@@ -342,7 +484,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *sqlstore.DBSession, dash *mode
 		return isParentFolderChanged, models.ErrDashboardTypeMismatch
 	}
 
-	if !dash.IsFolder && dash.FolderId != existing.FolderId {
+	if dash.FolderId != existing.FolderId {
 		isParentFolderChanged = true
 	}
 
@@ -397,7 +539,7 @@ func getExistingDashboardByTitleAndFolder(sess *sqlstore.DBSession, dash *models
 	return isParentFolderChanged, nil
 }
 
-func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) error {
+func (d *DashboardStore) saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) error {
 	dash := cmd.GetDashboardModel()
 
 	userId := cmd.UserId
@@ -406,8 +548,8 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 		userId = -1
 	}
 
+	var existing models.Dashboard
 	if dash.Id > 0 {
-		var existing models.Dashboard
 		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
 		if err != nil {
 			return err
@@ -431,6 +573,16 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 		}
 	}
 
+	// the nesting of folders is validated before saving, it's checked again in
+	// the transaction of the save so that concurrent moves can't nest folders
+	// deeper than the permission queries join. Missing parents are reported by
+	// the validation before saving.
+	if dash.IsFolder && dash.FolderId > 0 && (dash.Id == 0 || dash.FolderId != existing.FolderId) {
+		if err := validateFolderParent(sess, dash, d.sqlStore.Dialect); err != nil && !errors.Is(err, models.ErrFolderNotFound) {
+			return err
+		}
+	}
+
 	if dash.Uid == "" {
 		uid, err := generateNewDashboardUid(sess, dash.OrgId)
 		if err != nil {
@@ -443,6 +595,12 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 	var affectedRows int64
 	var err error
 
+	// the dashboard and version rows only hold a stub when the body is kept in
+	// the body storage, every version is stored with a key of its own
+	body := dash.Data
+	storesBody := d.storesBody(dash)
+	bodyKey := ""
+
 	if dash.Id == 0 {
 		dash.SetVersion(1)
 		dash.Created = time.Now()
@@ -450,6 +608,10 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 		dash.Updated = time.Now()
 		dash.UpdatedBy = userId
 		metrics.MApiDashboardInsert.Inc()
+		if storesBody {
+			bodyKey = bodystorage.VersionKey(dash.OrgId, dash.Uid, dash.Version)
+			dash.Data = d.bodyStub(body, bodyKey)
+		}
 		affectedRows, err = sess.Insert(dash)
 	} else {
 		dash.SetVersion(dash.Version + 1)
@@ -462,8 +624,14 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 
 		dash.UpdatedBy = userId
 
+		if storesBody {
+			bodyKey = bodystorage.VersionKey(dash.OrgId, dash.Uid, dash.Version)
+			dash.Data = d.bodyStub(body, bodyKey)
+		}
 		affectedRows, err = sess.MustCols("folder_id").ID(dash.Id).Update(dash)
 	}
+	rowData := dash.Data
+	dash.Data = body
 
 	if err != nil {
 		return err
@@ -481,7 +649,7 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 		Created:       time.Now(),
 		CreatedBy:     dash.UpdatedBy,
 		Message:       cmd.Message,
-		Data:          dash.Data,
+		Data:          rowData,
 	}
 
 	// insert version entry
@@ -506,11 +674,50 @@ func saveDashboard(sess *sqlstore.DBSession, cmd *models.SaveDashboardCommand) e
 		}
 	}
 
+	// the body is stored last, so that it's only stored when the dashboard can be
+	// saved. It doesn't replace the body of any committed version, the rows only
+	// reference it once the transaction is committed.
+	if bodyKey != "" {
+		if err := d.putBody(context.Background(), bodyKey, body); err != nil {
+			return err
+		}
+	}
+
+	if existing.Id > 0 {
+		publishDashboardChanges(sess, &existing, dash)
+	}
+
 	cmd.Result = dash
 
 	return nil
 }
 
+// publishDashboardChanges publishes the events for changes of a dashboard that
+// other resources referencing it, e.g. alert rules, need to follow.
+func publishDashboardChanges(sess *sqlstore.DBSession, existing *models.Dashboard, dash *models.Dashboard) {
+	if existing.Uid != dash.Uid {
+		sess.PublishAfterCommit(&events.DashboardUIDChanged{
+			Timestamp: dash.Updated,
+			ID:        dash.Id,
+			OrgID:     dash.OrgId,
+			IsFolder:  dash.IsFolder,
+			OldUID:    existing.Uid,
+			NewUID:    dash.Uid,
+		})
+	}
+
+	if !dash.IsFolder && existing.FolderId != dash.FolderId {
+		sess.PublishAfterCommit(&events.DashboardMoved{
+			Timestamp:   dash.Updated,
+			ID:          dash.Id,
+			UID:         dash.Uid,
+			OrgID:       dash.OrgId,
+			OldFolderID: existing.FolderId,
+			NewFolderID: dash.FolderId,
+		})
+	}
+}
+
 func generateNewDashboardUid(sess *sqlstore.DBSession, orgId int64) (string, error) {
 	for i := 0; i < 3; i++ {
 		uid := util.GenerateShortUID()
@@ -691,9 +898,11 @@ func (d *DashboardStore) GetDashboardsByPluginID(ctx context.Context, query *mod
 		var dashboards = make([]*models.Dashboard, 0)
 		whereExpr := "org_id=? AND plugin_id=? AND is_folder=" + d.sqlStore.Dialect.BooleanStr(false)
 
-		err := dbSession.Where(whereExpr, query.OrgId, query.PluginId).Find(&dashboards)
+		if err := dbSession.Where(whereExpr, query.OrgId, query.PluginId).Find(&dashboards); err != nil {
+			return err
+		}
 		query.Result = dashboards
-		return err
+		return d.loadBodies(ctx, dashboards...)
 	})
 }
 
@@ -712,6 +921,14 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		return models.ErrDashboardNotFound
 	}
 
+	// the keys of the bodies are read before the rows referencing them are deleted
+	var bodyKeys []string
+	if !dashboard.IsFolder {
+		if bodyKeys, err = d.storedBodyKeys(sess, dashboard.Id); err != nil {
+			return err
+		}
+	}
+
 	deletes := []string{
 		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
 		"DELETE FROM star WHERE dashboard_id = ? ",
@@ -721,6 +938,7 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		"DELETE FROM annotation WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_usage WHERE dashboard_id = ?",
 	}
 
 	if dashboard.IsFolder {
@@ -735,10 +953,15 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 			return err
 		}
 
+		childIDs := make([]int64, 0, len(dashIds))
 		for _, id := range dashIds {
 			if err := d.deleteAlertDefinition(id.Id, sess); err != nil {
 				return err
 			}
+			childIDs = append(childIDs, id.Id)
+		}
+		if bodyKeys, err = d.storedBodyKeys(sess, childIDs...); err != nil {
+			return err
 		}
 
 		// remove all access control permission with folder scope
@@ -747,6 +970,16 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 			return err
 		}
 
+		_, err = sess.Exec("DELETE FROM star_item WHERE org_id = ? AND kind = 'folder' AND uid = ?", dashboard.OrgId, dashboard.Uid)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM anonymous_access_item WHERE org_id = ? AND kind = 'folder' AND uid = ?", dashboard.OrgId, dashboard.Uid)
+		if err != nil {
+			return err
+		}
+
 		for _, dash := range dashIds {
 			// remove all access control permission with child dashboard scopes
 			_, err = sess.Exec("DELETE FROM permission WHERE scope = ?", ac.GetResourceScopeUID("dashboards", dash.Uid))
@@ -763,6 +996,8 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_usage WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM anonymous_access_item WHERE kind = 'dashboard' AND EXISTS (SELECT 1 FROM dashboard WHERE org_id = ? AND folder_id = ? AND dashboard.org_id = anonymous_access_item.org_id AND dashboard.uid = anonymous_access_item.uid)",
 			}
 			for _, sql := range childrenDeletes {
 				_, err := sess.Exec(sql, dashboard.OrgId, dashboard.Id)
@@ -800,6 +1035,11 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		if err != nil {
 			return err
 		}
+
+		_, err = sess.Exec("DELETE FROM anonymous_access_item WHERE org_id = ? AND kind = 'dashboard' AND uid = ?", dashboard.OrgId, dashboard.Uid)
+		if err != nil {
+			return err
+		}
 	}
 
 	if err := d.deleteAlertDefinition(dashboard.Id, sess); err != nil {
@@ -813,6 +1053,7 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		}
 	}
 
+	d.deleteBodiesAfterCommit(sess, bodyKeys)
 	return nil
 }
 
@@ -848,6 +1089,10 @@ func (d *DashboardStore) GetDashboard(ctx context.Context, query *models.GetDash
 			return models.ErrDashboardNotFound
 		}
 
+		if err := d.loadBodies(ctx, &dashboard); err != nil {
+			return err
+		}
+
 		dashboard.SetId(dashboard.Id)
 		dashboard.SetUid(dashboard.Uid)
 		query.Result = &dashboard
@@ -886,13 +1131,29 @@ func (d *DashboardStore) GetDashboards(ctx context.Context, query *models.GetDas
 			session = sess.In("uid", query.DashboardUIds)
 		}
 
-		err := session.Find(&dashboards)
+		if err := session.Find(&dashboards); err != nil {
+			return err
+		}
 		query.Result = dashboards
-		return err
+		return d.loadBodies(ctx, dashboards...)
 	})
 }
 
 func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]dashboards.DashboardSearchProjection, error) {
+	orgID := query.OrgId
+	if orgID == 0 {
+		orgID = query.SignedInUser.OrgId
+	}
+	var folderDepth int
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		folderDepth, err = getFolderDepth(sess, d.dialect, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	filters := []interface{}{
 		permissions.DashboardPermissionFilter{
 			OrgRole:         query.SignedInUser.OrgRole,
@@ -900,14 +1161,15 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 			Dialect:         d.dialect,
 			UserId:          query.SignedInUser.UserId,
 			PermissionLevel: query.Permission,
+			FolderDepth:     folderDepth,
 		},
 	}
 
 	if !ac.IsDisabled(d.sqlStore.Cfg) {
 		// if access control is enabled, overwrite the filters so far
-		filters = []interface{}{
-			permissions.NewAccessControlDashboardPermissionFilter(query.SignedInUser, query.Permission, query.Type),
-		}
+		filter := permissions.NewAccessControlDashboardPermissionFilter(query.SignedInUser, query.Permission, query.Type)
+		filter.FolderDepth = folderDepth
+		filters = []interface{}{filter}
 	}
 
 	for _, filter := range query.Sort.Filter {
@@ -948,6 +1210,10 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 		filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
 	}
 
+	if query.SubtreeFolderUID != "" {
+		filters = append(filters, searchstore.FolderSubtreeFilter{OrgId: orgID, UID: query.SubtreeFolderUID, Depth: folderDepth})
+	}
+
 	var res []dashboards.DashboardSearchProjection
 	sb := &searchstore.Builder{Dialect: d.dialect, Filters: filters}
 
@@ -963,7 +1229,7 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 
 	sql, params := sb.ToSQL(limit, page)
 
-	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+	err = d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		return sess.SQL(sql, params...).Find(&res)
 	})
 
@@ -9,14 +9,14 @@ import (
 	"github.com/grafana/grafana/pkg/util"
 )
 
-// retrieves public dashboard configuration
-func (d *DashboardStore) GetPublicDashboard(uid string) (*models.PublicDashboard, *models.Dashboard, error) {
-	if uid == "" {
+// retrieves public dashboard configuration and dashboard by access token
+func (d *DashboardStore) GetPublicDashboard(accessToken string) (*models.PublicDashboard, *models.Dashboard, error) {
+	if accessToken == "" {
 		return nil, nil, models.ErrPublicDashboardIdentifierNotSet
 	}
 
 	// get public dashboard
-	pdRes := &models.PublicDashboard{Uid: uid}
+	pdRes := &models.PublicDashboard{AccessToken: accessToken}
 	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		has, err := sess.Get(pdRes)
 		if err != nil {
@@ -42,7 +42,7 @@ func (d *DashboardStore) GetPublicDashboard(uid string) (*models.PublicDashboard
 		if !has {
 			return models.ErrPublicDashboardNotFound
 		}
-		return nil
+		return d.loadBodies(context.Background(), dashRes)
 	})
 
 	if err != nil {
@@ -70,6 +70,27 @@ func generateNewPublicDashboardUid(sess *sqlstore.DBSession) (string, error) {
 	return "", models.ErrPublicDashboardFailedGenerateUniqueUid
 }
 
+// generates a new unique access token for anonymous users to access a public dashboard with
+func generateNewPublicDashboardAccessToken(sess *sqlstore.DBSession) (string, error) {
+	for i := 0; i < 3; i++ {
+		token, err := util.GetRandomString(32)
+		if err != nil {
+			return "", err
+		}
+
+		exists, err := sess.Get(&models.PublicDashboard{AccessToken: token})
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return token, nil
+		}
+	}
+
+	return "", models.ErrPublicDashboardFailedGenerateAccessToken
+}
+
 // retrieves public dashboard configuration
 func (d *DashboardStore) GetPublicDashboardConfig(orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error) {
 	if dashboardUid == "" {
@@ -111,12 +132,12 @@ func (d *DashboardStore) GetPublicDashboardConfig(orgId int64, dashboardUid stri
 }
 
 // persists public dashboard configuration
-func (d *DashboardStore) SavePublicDashboardConfig(cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error) {
-	if len(cmd.PublicDashboardConfig.PublicDashboard.DashboardUid) == 0 {
+func (d *DashboardStore) SavePublicDashboardConfig(ctx context.Context, cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error) {
+	if len(cmd.DashboardUid) == 0 {
 		return nil, models.ErrDashboardIdentifierNotSet
 	}
 
-	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		// update isPublic on dashboard entry
 		affectedRowCount, err := sess.Table("dashboard").Where("org_id = ? AND uid = ?", cmd.OrgId, cmd.DashboardUid).Update(map[string]interface{}{"is_public": cmd.PublicDashboardConfig.IsPublic})
 		if err != nil {
@@ -128,17 +149,39 @@ func (d *DashboardStore) SavePublicDashboardConfig(cmd models.SavePublicDashboar
 		}
 
 		// update dashboard_public_config
-		// if we have a uid, public dashboard config exists. delete it otherwise generate a uid
-		if cmd.PublicDashboardConfig.PublicDashboard.Uid != "" {
-			if _, err = sess.Exec("DELETE FROM dashboard_public_config WHERE uid=?", cmd.PublicDashboardConfig.PublicDashboard.Uid); err != nil {
+		// the uid and access token of an existing public dashboard are kept, they
+		// can't be set by the request. otherwise generate them
+		pd := &cmd.PublicDashboardConfig.PublicDashboard
+		pd.OrgId = cmd.OrgId
+		pd.DashboardUid = cmd.DashboardUid
+
+		existing := &models.PublicDashboard{OrgId: cmd.OrgId, DashboardUid: cmd.DashboardUid}
+		exists, err := sess.Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			if _, err = sess.Exec("DELETE FROM dashboard_public_config WHERE org_id=? AND dashboard_uid=?", cmd.OrgId, cmd.DashboardUid); err != nil {
 				return err
 			}
+			pd.Uid = existing.Uid
+			pd.AccessToken = existing.AccessToken
 		} else {
 			uid, err := generateNewPublicDashboardUid(sess)
 			if err != nil {
 				return fmt.Errorf("failed to generate UID for public dashboard: %w", err)
 			}
-			cmd.PublicDashboardConfig.PublicDashboard.Uid = uid
+			pd.Uid = uid
+			pd.AccessToken = ""
+		}
+
+		if pd.AccessToken == "" {
+			accessToken, err := generateNewPublicDashboardAccessToken(sess)
+			if err != nil {
+				return fmt.Errorf("failed to generate access token for public dashboard: %w", err)
+			}
+			pd.AccessToken = accessToken
 		}
 
 		_, err = sess.Insert(&cmd.PublicDashboardConfig.PublicDashboard)
@@ -155,3 +198,37 @@ func (d *DashboardStore) SavePublicDashboardConfig(cmd models.SavePublicDashboar
 
 	return &cmd.PublicDashboardConfig, nil
 }
+
+// replaces the access token of a public dashboard, the links with the previous
+// access token stop working
+func (d *DashboardStore) RotatePublicDashboardAccessToken(cmd models.RotatePublicDashboardAccessTokenCommand) (*models.PublicDashboard, error) {
+	if cmd.DashboardUid == "" {
+		return nil, models.ErrDashboardIdentifierNotSet
+	}
+
+	pdRes := &models.PublicDashboard{OrgId: cmd.OrgId, DashboardUid: cmd.DashboardUid}
+	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		has, err := sess.Get(pdRes)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrPublicDashboardNotFound
+		}
+
+		accessToken, err := generateNewPublicDashboardAccessToken(sess)
+		if err != nil {
+			return fmt.Errorf("failed to generate access token for public dashboard: %w", err)
+		}
+		pdRes.AccessToken = accessToken
+
+		_, err = sess.Where("org_id = ? AND uid = ?", pdRes.OrgId, pdRes.Uid).Cols("access_token").Update(pdRes)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pdRes, nil
+}
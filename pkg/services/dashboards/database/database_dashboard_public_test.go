@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -25,7 +26,7 @@ func TestIntegrationGetPublicDashboard(t *testing.T) {
 
 	t.Run("returns PublicDashboard and Dashboard", func(t *testing.T) {
 		setup()
-		pdc, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		pdc, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
@@ -39,7 +40,7 @@ func TestIntegrationGetPublicDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		pd, d, err := dashboardStore.GetPublicDashboard("abc1234")
+		pd, d, err := dashboardStore.GetPublicDashboard(pdc.PublicDashboard.AccessToken)
 		require.NoError(t, err)
 		assert.Equal(t, pd, &pdc.PublicDashboard)
 		assert.Equal(t, d.Uid, pdc.PublicDashboard.DashboardUid)
@@ -57,23 +58,28 @@ func TestIntegrationGetPublicDashboard(t *testing.T) {
 		require.Error(t, models.ErrPublicDashboardNotFound, err)
 	})
 
-	t.Run("returns ErrDashboardNotFound when Dashboard not found", func(t *testing.T) {
+	t.Run("returns ErrPublicDashboardNotFound when Dashboard not found", func(t *testing.T) {
 		setup()
-		_, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		pdc, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
 				IsPublic: true,
 				PublicDashboard: models.PublicDashboard{
-					Uid:          "abc1234",
-					DashboardUid: "nevergonnafindme",
+					DashboardUid: savedDashboard.Uid,
 					OrgId:        savedDashboard.OrgId,
 				},
 			},
 		})
 		require.NoError(t, err)
-		_, _, err = dashboardStore.GetPublicDashboard("abc1234")
-		require.Error(t, models.ErrDashboardNotFound, err)
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("DELETE FROM dashboard WHERE id=?", savedDashboard.Id)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, _, err = dashboardStore.GetPublicDashboard(pdc.PublicDashboard.AccessToken)
+		require.ErrorIs(t, err, models.ErrPublicDashboardNotFound)
 	})
 }
 
@@ -105,7 +111,7 @@ func TestIntegrationGetPublicDashboardConfig(t *testing.T) {
 	t.Run("returns isPublic along with public dashboard when exists", func(t *testing.T) {
 		setup()
 		// insert test public dashboard
-		resp, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		resp, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
@@ -142,7 +148,7 @@ func TestIntegrationSavePublicDashboardConfig(t *testing.T) {
 
 	t.Run("saves new public dashboard", func(t *testing.T) {
 		setup()
-		resp, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		resp, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
@@ -162,8 +168,9 @@ func TestIntegrationSavePublicDashboardConfig(t *testing.T) {
 		//verify saved response and queried response are the same
 		assert.Equal(t, resp, pdc)
 
-		// verify we have a valid uid
+		// verify we have a valid uid and an access token
 		assert.True(t, util.IsValidShortUID(pdc.PublicDashboard.Uid))
+		assert.Len(t, pdc.PublicDashboard.AccessToken, 32)
 
 		// verify we didn't update all dashboards
 		pdc2, err := dashboardStore.GetPublicDashboardConfig(savedDashboard2.OrgId, savedDashboard2.Uid)
@@ -173,8 +180,8 @@ func TestIntegrationSavePublicDashboardConfig(t *testing.T) {
 
 	t.Run("returns ErrDashboardIdentifierNotSet", func(t *testing.T) {
 		setup()
-		_, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
-			DashboardUid: savedDashboard.Uid,
+		_, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
+			DashboardUid: "",
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
 				IsPublic: true,
@@ -190,31 +197,31 @@ func TestIntegrationSavePublicDashboardConfig(t *testing.T) {
 	t.Run("overwrites existing public dashboard", func(t *testing.T) {
 		setup()
 
-		pdUid := util.GenerateShortUID()
-
 		// insert initial record
-		_, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		initial, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
 				IsPublic: true,
 				PublicDashboard: models.PublicDashboard{
-					Uid:          pdUid,
 					DashboardUid: savedDashboard.Uid,
 					OrgId:        savedDashboard.OrgId,
 				},
 			},
 		})
 		require.NoError(t, err)
+		initialUid := initial.PublicDashboard.Uid
+		initialAccessToken := initial.PublicDashboard.AccessToken
 
-		// update initial record
-		resp, err := dashboardStore.SavePublicDashboardConfig(models.SavePublicDashboardConfigCommand{
+		// update initial record, the uid and access token can't be changed
+		resp, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
 			DashboardUid: savedDashboard.Uid,
 			OrgId:        savedDashboard.OrgId,
 			PublicDashboardConfig: models.PublicDashboardConfig{
 				IsPublic: false,
 				PublicDashboard: models.PublicDashboard{
-					Uid:          pdUid,
+					Uid:          util.GenerateShortUID(),
+					AccessToken:  "chosen-by-the-client",
 					DashboardUid: savedDashboard.Uid,
 					OrgId:        savedDashboard.OrgId,
 					TimeSettings: "{}",
@@ -222,9 +229,62 @@ func TestIntegrationSavePublicDashboardConfig(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
+		assert.Equal(t, initialUid, resp.PublicDashboard.Uid)
+		assert.Equal(t, initialAccessToken, resp.PublicDashboard.AccessToken)
 
 		pdc, err := dashboardStore.GetPublicDashboardConfig(savedDashboard.OrgId, savedDashboard.Uid)
 		require.NoError(t, err)
 		assert.Equal(t, resp, pdc)
 	})
 }
+
+// RotatePublicDashboardAccessToken
+func TestIntegrationRotatePublicDashboardAccessToken(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	dashboardStore := ProvideDashboardStore(sqlStore)
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+
+	t.Run("returns ErrPublicDashboardNotFound when dashboard isn't shared", func(t *testing.T) {
+		_, err := dashboardStore.RotatePublicDashboardAccessToken(models.RotatePublicDashboardAccessTokenCommand{
+			DashboardUid: savedDashboard.Uid,
+			OrgId:        savedDashboard.OrgId,
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardNotFound)
+	})
+
+	t.Run("replaces the access token", func(t *testing.T) {
+		pdc, err := dashboardStore.SavePublicDashboardConfig(context.Background(), models.SavePublicDashboardConfigCommand{
+			DashboardUid: savedDashboard.Uid,
+			OrgId:        savedDashboard.OrgId,
+			PublicDashboardConfig: models.PublicDashboardConfig{
+				IsPublic:        true,
+				PublicDashboard: models.PublicDashboard{DashboardUid: savedDashboard.Uid, OrgId: savedDashboard.OrgId},
+			},
+		})
+		require.NoError(t, err)
+
+		pd, err := dashboardStore.RotatePublicDashboardAccessToken(models.RotatePublicDashboardAccessTokenCommand{
+			DashboardUid: savedDashboard.Uid,
+			OrgId:        savedDashboard.OrgId,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, pdc.PublicDashboard.Uid, pd.Uid)
+		assert.Len(t, pd.AccessToken, 32)
+		assert.NotEqual(t, pdc.PublicDashboard.AccessToken, pd.AccessToken)
+
+		_, _, err = dashboardStore.GetPublicDashboard(pdc.PublicDashboard.AccessToken)
+		require.ErrorIs(t, err, models.ErrPublicDashboardNotFound)
+
+		_, d, err := dashboardStore.GetPublicDashboard(pd.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, savedDashboard.Uid, d.Uid)
+	})
+
+	t.Run("doesn't rotate the access token of another organization", func(t *testing.T) {
+		_, err := dashboardStore.RotatePublicDashboardAccessToken(models.RotatePublicDashboardAccessTokenCommand{
+			DashboardUid: savedDashboard.Uid,
+			OrgId:        savedDashboard.OrgId + 1,
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardNotFound)
+	})
+}
@@ -2,12 +2,14 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
@@ -526,6 +528,183 @@ func TestIntegrationDashboardFolderDataAccess(t *testing.T) {
 	})
 }
 
+func TestIntegrationNestedFolderDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var sqlStore *sqlstore.SQLStore
+	var dashboardStore *DashboardStore
+	var root, child, grandchild, dash, other *models.Dashboard
+	var currentUser models.User
+
+	setup := func() {
+		sqlStore = sqlstore.InitTestDB(t)
+		sqlStore.Cfg.RBACEnabled = false
+		dashboardStore = ProvideDashboardStore(sqlStore)
+		root = insertTestDashboard(t, dashboardStore, "root", 1, 0, true)
+		child = insertTestDashboard(t, dashboardStore, "child", 1, root.Id, true)
+		grandchild = insertTestDashboard(t, dashboardStore, "grandchild", 1, child.Id, true)
+		dash = insertTestDashboard(t, dashboardStore, "dashboard", 1, grandchild.Id, false)
+		other = insertTestDashboard(t, dashboardStore, "other", 1, 0, true)
+		currentUser = CreateUser(t, sqlStore, "viewer", "Viewer", false)
+	}
+
+	searchIDs := func(t *testing.T, query *models.FindPersistedDashboardsQuery) []int64 {
+		t.Helper()
+		require.NoError(t, testSearchDashboards(dashboardStore, query))
+		ids := make([]int64, 0, len(query.Result))
+		for _, hit := range query.Result {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	folderIDs := func(folders []*models.Folder) []int64 {
+		ids := make([]int64, 0, len(folders))
+		for _, f := range folders {
+			ids = append(ids, f.Id)
+		}
+		return ids
+	}
+
+	t.Run("Should get the ancestors and descendants of a folder", func(t *testing.T) {
+		setup()
+
+		ancestors, err := dashboardStore.GetFolderAncestors(context.Background(), 1, grandchild.Id)
+		require.NoError(t, err)
+		require.Equal(t, []int64{root.Id, child.Id}, folderIDs(ancestors))
+
+		ancestors, err = dashboardStore.GetFolderAncestors(context.Background(), 1, root.Id)
+		require.NoError(t, err)
+		require.Empty(t, ancestors)
+
+		descendants, err := dashboardStore.GetFolderDescendants(context.Background(), 1, root.Id)
+		require.NoError(t, err)
+		require.Equal(t, []int64{child.Id, grandchild.Id}, folderIDs(descendants))
+
+		folder, err := dashboardStore.GetFolderByUID(context.Background(), 1, grandchild.Uid)
+		require.NoError(t, err)
+		require.Equal(t, child.Id, folder.ParentId)
+	})
+
+	t.Run("Should not move a folder into itself or one of its subfolders", func(t *testing.T) {
+		setup()
+
+		for _, parent := range []*models.Dashboard{root, grandchild} {
+			moved := models.NewDashboardFromJson(root.Data)
+			moved.OrgId = 1
+			moved.IsFolder = true
+			moved.FolderId = parent.Id
+			_, err := dashboardStore.ValidateDashboardBeforeSave(moved, true)
+			require.ErrorIs(t, err, models.ErrDashboardFolderCyclicParent)
+		}
+	})
+
+	t.Run("Should not nest folders deeper than the maximum depth", func(t *testing.T) {
+		setup()
+
+		// the grandchild is at depth 3
+		parent := grandchild
+		for depth := 4; depth <= models.MaxNestedFolderDepth; depth++ {
+			parent = insertTestDashboard(t, dashboardStore, fmt.Sprintf("depth %d", depth), 1, parent.Id, true)
+		}
+
+		tooDeep := models.NewDashboardFolder("too deep")
+		tooDeep.OrgId = 1
+		tooDeep.FolderId = parent.Id
+		_, err := dashboardStore.ValidateDashboardBeforeSave(tooDeep, false)
+		require.ErrorIs(t, err, models.ErrDashboardFolderMaxDepthExceeded)
+
+		// moving the child moves its subfolders too
+		moved := models.NewDashboardFromJson(child.Data)
+		moved.OrgId = 1
+		moved.IsFolder = true
+		moved.FolderId = insertTestDashboard(t, dashboardStore, "new parent", 1, other.Id, true).Id
+		_, err = dashboardStore.ValidateDashboardBeforeSave(moved, true)
+		require.ErrorIs(t, err, models.ErrDashboardFolderMaxDepthExceeded)
+	})
+
+	t.Run("Should inherit the permissions of folders nested at the maximum depth", func(t *testing.T) {
+		setup()
+		viewer := &models.SignedInUser{UserId: currentUser.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+		// the grandchild is at depth 3
+		parent := grandchild
+		for depth := 4; depth <= models.MaxNestedFolderDepth; depth++ {
+			parent = insertTestDashboard(t, dashboardStore, fmt.Sprintf("depth %d", depth), 1, parent.Id, true)
+		}
+		deepest := insertTestDashboard(t, dashboardStore, "deepest dashboard", 1, parent.Id, false)
+
+		// saving bypassing the validation still can't nest folders deeper
+		_, err := dashboardStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
+			OrgId:     1,
+			FolderId:  parent.Id,
+			IsFolder:  true,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "too deep"}),
+		})
+		require.ErrorIs(t, err, models.ErrDashboardFolderMaxDepthExceeded)
+
+		err = updateDashboardAcl(t, dashboardStore, root.Id, models.DashboardAcl{
+			DashboardID: root.Id, OrgID: 1, UserID: 999, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+		require.Empty(t, searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: viewer, OrgId: 1, DashboardIds: []int64{parent.Id, deepest.Id}}))
+
+		err = updateDashboardAcl(t, dashboardStore, root.Id, models.DashboardAcl{
+			DashboardID: root.Id, OrgID: 1, UserID: currentUser.Id, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{parent.Id, deepest.Id}, searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: viewer, OrgId: 1, DashboardIds: []int64{parent.Id, deepest.Id}}))
+	})
+
+	t.Run("Should inherit the permissions of all the folders containing a dashboard", func(t *testing.T) {
+		setup()
+		viewer := &models.SignedInUser{UserId: currentUser.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		nested := []int64{child.Id, grandchild.Id, dash.Id}
+
+		err := updateDashboardAcl(t, dashboardStore, root.Id, models.DashboardAcl{
+			DashboardID: root.Id, OrgID: 1, UserID: 999, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+		require.Empty(t, searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: viewer, OrgId: 1, DashboardIds: nested}))
+
+		err = updateDashboardAcl(t, dashboardStore, root.Id, models.DashboardAcl{
+			DashboardID: root.Id, OrgID: 1, UserID: currentUser.Id, Permission: models.PERMISSION_VIEW,
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, nested, searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: viewer, OrgId: 1, DashboardIds: nested}))
+
+		query := models.GetDashboardAclInfoListQuery{OrgID: 1, DashboardID: dash.Id}
+		require.NoError(t, dashboardStore.GetDashboardAclInfoList(context.Background(), &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, root.Id, query.Result[0].DashboardId)
+		require.True(t, query.Result[0].Inherited)
+	})
+
+	t.Run("Should inherit the permissions of the folders with access control", func(t *testing.T) {
+		setup()
+		sqlStore.Cfg.RBACEnabled = true
+		user := &models.SignedInUser{UserId: currentUser.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER, Permissions: map[int64]map[string][]string{1: {
+			dashboards.ActionFoldersRead:    {dashboards.ScopeFoldersProvider.GetResourceScopeUID(child.Uid)},
+			dashboards.ActionDashboardsRead: {dashboards.ScopeFoldersProvider.GetResourceScopeUID(child.Uid)},
+		}}}
+
+		ids := searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: user, OrgId: 1})
+		require.ElementsMatch(t, []int64{child.Id, grandchild.Id, dash.Id}, ids)
+	})
+
+	t.Run("Should search the dashboards and folders nested in a folder", func(t *testing.T) {
+		setup()
+		admin := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+		ids := searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, SubtreeFolderUID: root.Uid})
+		require.ElementsMatch(t, []int64{child.Id, grandchild.Id, dash.Id}, ids)
+
+		ids = searchIDs(t, &models.FindPersistedDashboardsQuery{SignedInUser: admin, OrgId: 1, SubtreeFolderUID: child.Uid, Type: "dash-db"})
+		require.Equal(t, []int64{dash.Id}, ids)
+	})
+}
+
 func moveDashboard(t *testing.T, dashboardStore *DashboardStore, orgId int64, dashboard *simplejson.Json,
 	newFolderId int64) *models.Dashboard {
 	t.Helper()
@@ -536,7 +715,7 @@ func moveDashboard(t *testing.T, dashboardStore *DashboardStore, orgId int64, da
 		Dashboard: dashboard,
 		Overwrite: true,
 	}
-	dash, err := dashboardStore.SaveDashboard(cmd)
+	dash, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 	require.NoError(t, err)
 
 	return dash
@@ -30,7 +30,7 @@ func TestIntegrationDashboardProvisioningTest(t *testing.T) {
 		}),
 	}
 
-	dash, err := dashboardStore.SaveDashboard(folderCmd)
+	dash, err := dashboardStore.SaveDashboard(context.Background(), folderCmd)
 	require.Nil(t, err)
 
 	saveDashboardCmd := models.SaveDashboardCommand{
@@ -52,7 +52,7 @@ func TestIntegrationDashboardProvisioningTest(t *testing.T) {
 			Updated:    now.Unix(),
 		}
 
-		dash, err := dashboardStore.SaveProvisionedDashboard(saveDashboardCmd, provisioning)
+		dash, err := dashboardStore.SaveProvisionedDashboard(context.Background(), saveDashboardCmd, provisioning)
 		require.Nil(t, err)
 		require.NotNil(t, dash)
 		require.NotEqual(t, 0, dash.Id)
@@ -74,7 +74,7 @@ func TestIntegrationDashboardProvisioningTest(t *testing.T) {
 				Updated:    now.Unix(),
 			}
 
-			anotherDash, err := dashboardStore.SaveProvisionedDashboard(saveCmd, provisioning)
+			anotherDash, err := dashboardStore.SaveProvisionedDashboard(context.Background(), saveCmd, provisioning)
 			require.Nil(t, err)
 
 			query := &models.GetDashboardsQuery{DashboardIds: []int64{anotherDash.Id}}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	benchFolders             = 30
+	benchDashboardsPerFolder = 100
+)
+
+func BenchmarkFindDashboards_Flat(b *testing.B) { benchmarkFindDashboards(b, 1, false) }
+
+func BenchmarkFindDashboards_Nested(b *testing.B) { benchmarkFindDashboards(b, 3, false) }
+
+func BenchmarkFindDashboards_FlatRBAC(b *testing.B) { benchmarkFindDashboards(b, 1, true) }
+
+func BenchmarkFindDashboards_NestedRBAC(b *testing.B) { benchmarkFindDashboards(b, 3, true) }
+
+// benchmarkFindDashboards searches the dashboards as a viewer, with the
+// folders nested up to depth levels.
+func benchmarkFindDashboards(b *testing.B, depth int, rbac bool) {
+	sqlStore := sqlstore.InitTestDB(b)
+	sqlStore.Cfg.RBACEnabled = rbac
+	dashboardStore := ProvideDashboardStore(sqlStore)
+	setupSearchBenchmark(b, dashboardStore, depth)
+
+	query := &models.FindPersistedDashboardsQuery{
+		SignedInUser: &models.SignedInUser{
+			OrgId:   1,
+			UserId:  1,
+			OrgRole: models.ROLE_VIEWER,
+			Permissions: map[int64]map[string][]string{
+				1: {
+					dashboards.ActionDashboardsRead: []string{dashboards.ScopeFoldersAll},
+					dashboards.ActionFoldersRead:    []string{dashboards.ScopeFoldersAll},
+				},
+			},
+		},
+		Permission: models.PERMISSION_VIEW,
+		Limit:      1000,
+	}
+	// We don't want to measure DB initialization
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		res, err := dashboardStore.FindDashboards(context.Background(), query)
+		require.NoError(b, err)
+		require.Len(b, res, 1000)
+	}
+}
+
+// setupSearchBenchmark saves benchFolders folders, each nested in the one
+// before up to depth levels, with benchDashboardsPerFolder dashboards each.
+func setupSearchBenchmark(b *testing.B, dashboardStore *DashboardStore, depth int) {
+	save := func(title string, folderID int64, isFolder bool) *models.Dashboard {
+		dash, err := dashboardStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
+			OrgId:    1,
+			FolderId: folderID,
+			IsFolder: isFolder,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title": title,
+			}),
+		})
+		require.NoError(b, err)
+		return dash
+	}
+
+	var parentID int64
+	for f := 0; f < benchFolders; f++ {
+		if f%depth == 0 {
+			parentID = 0
+		}
+		folder := save(fmt.Sprintf("folder %d", f), parentID, true)
+		parentID = folder.Id
+		for d := 0; d < benchDashboardsPerFolder; d++ {
+			save(fmt.Sprintf("dashboard %d-%d", f, d), folder.Id, false)
+		}
+	}
+}
@@ -160,7 +160,7 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 			}),
 			UserId: 100,
 		}
-		dashboard, err := dashboardStore.SaveDashboard(cmd)
+		dashboard, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 		require.NoError(t, err)
 		require.EqualValues(t, dashboard.CreatedBy, 100)
 		require.False(t, dashboard.Created.IsZero())
@@ -181,7 +181,7 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 			FolderId:  2,
 			UserId:    100,
 		}
-		dash, err := dashboardStore.SaveDashboard(cmd)
+		dash, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 		require.NoError(t, err)
 		require.EqualValues(t, dash.FolderId, 2)
 
@@ -196,7 +196,7 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 			Overwrite: true,
 			UserId:    100,
 		}
-		_, err = dashboardStore.SaveDashboard(cmd)
+		_, err = dashboardStore.SaveDashboard(context.Background(), cmd)
 		require.NoError(t, err)
 
 		query := models.GetDashboardQuery{
@@ -272,7 +272,7 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 			}),
 		}
 
-		_, err := dashboardStore.SaveDashboard(cmd)
+		_, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 		require.Equal(t, err, models.ErrDashboardNotFound)
 	})
 
@@ -286,7 +286,7 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 				"tags":  []interface{}{},
 			}),
 		}
-		_, err := dashboardStore.SaveDashboard(cmd)
+		_, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 		require.NoError(t, err)
 	})
 
@@ -694,7 +694,7 @@ func insertTestDashboard(t *testing.T, dashboardStore *DashboardStore, title str
 			"tags":  tags,
 		}),
 	}
-	dash, err := dashboardStore.SaveDashboard(cmd)
+	dash, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 	require.NoError(t, err)
 	require.NotNil(t, dash)
 	dash.Data.Set("id", dash.Id)
@@ -716,7 +716,7 @@ func insertTestDashboardForPlugin(t *testing.T, dashboardStore *DashboardStore,
 		PluginId: pluginId,
 	}
 
-	dash, err := dashboardStore.SaveDashboard(cmd)
+	dash, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 	require.NoError(t, err)
 
 	return dash
@@ -13,7 +13,12 @@ type FolderService interface {
 	GetFolderByID(ctx context.Context, user *models.SignedInUser, id int64, orgID int64) (*models.Folder, error)
 	GetFolderByUID(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) (*models.Folder, error)
 	GetFolderByTitle(ctx context.Context, user *models.SignedInUser, orgID int64, title string) (*models.Folder, error)
-	CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid string) (*models.Folder, error)
+	// GetFolderParents returns the folders containing the folder, from the folder in the root to its parent.
+	GetFolderParents(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error)
+	// GetFolderDescendants returns the folders nested in the folder, the parents before their subfolders.
+	GetFolderDescendants(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error)
+	// CreateFolder creates a folder in the folder with parentUID, or in the root when it's empty.
+	CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error)
 	UpdateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, existingUid string, cmd *models.UpdateFolderCommand) error
 	DeleteFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, folderID int64, setViewAndEditPermissions bool) error
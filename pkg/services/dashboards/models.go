@@ -13,6 +13,8 @@ type SaveDashboardDTO struct {
 	Message   string
 	Overwrite bool
 	Dashboard *models.Dashboard
+	// DryRun validates the save without saving the dashboard.
+	DryRun bool
 }
 
 type SavePublicDashboardConfigDTO struct {
@@ -21,6 +23,16 @@ type SavePublicDashboardConfigDTO struct {
 	PublicDashboardConfig *models.PublicDashboardConfig
 }
 
+// PublicDashboardQueryDTO is the time range and the template variable values
+// anonymous users request the data of a public dashboard panel for.
+type PublicDashboardQueryDTO struct {
+	// From and To default to the time range of the public dashboard.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Variables can only set values allowed by the public dashboard.
+	Variables map[string][]string `json:"variables"`
+}
+
 type DashboardSearchProjection struct {
 	ID          int64  `xorm:"id"`
 	UID         string `xorm:"uid"`
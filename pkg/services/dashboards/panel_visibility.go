@@ -0,0 +1,239 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// ErrPanelHidden is returned when a user runs the queries of a panel they can't see.
+var ErrPanelHidden = errors.New("panel is not visible to the user")
+
+// hiddenPanel is a panel the user can't see, with the id of its collapsed row
+// when it is nested in one.
+type hiddenPanel struct {
+	panel map[string]interface{}
+	rowID int64
+}
+
+// CanViewAllPanels returns true if the panel visibility rules don't apply to the
+// user: org admins and Grafana admins see all the panels.
+func CanViewAllPanels(user *models.SignedInUser) bool {
+	return user.IsGrafanaAdmin || user.OrgRole == models.ROLE_ADMIN
+}
+
+// RemoveHiddenPanels removes the panels the user can't see from the dashboard data.
+func RemoveHiddenPanels(user *models.SignedInUser, data *simplejson.Json) {
+	if data == nil || CanViewAllPanels(user) {
+		return
+	}
+	visible, hidden := splitPanels(user, data.Get("panels").MustArray())
+	if len(hidden) > 0 {
+		data.Set("panels", visible)
+	}
+}
+
+// publicViewer is the user of the requests authenticated with the access token
+// of a public dashboard. It has no role and no teams, so the panels with
+// visibility rules are hidden from it.
+var publicViewer = &models.SignedInUser{}
+
+// RemoveRestrictedPanels removes the panels with visibility rules from the data
+// of a public dashboard.
+func RemoveRestrictedPanels(data *simplejson.Json) {
+	RemoveHiddenPanels(publicViewer, data)
+}
+
+// IsPanelRestricted returns true if the panel of a public dashboard has
+// visibility rules, or is in a row that has some.
+func IsPanelRestricted(data *simplejson.Json, panelID int64) bool {
+	return !CanViewPanel(publicViewer, data, panelID)
+}
+
+// CanViewPanel returns false if the panel is hidden from the user.
+func CanViewPanel(user *models.SignedInUser, data *simplejson.Json, panelID int64) bool {
+	return !HiddenPanelIDs(user, data)[panelID]
+}
+
+// HiddenPanelIDs returns the ids of the panels hidden from the user, including
+// the panels of the hidden rows.
+func HiddenPanelIDs(user *models.SignedInUser, data *simplejson.Json) map[int64]bool {
+	if data == nil || CanViewAllPanels(user) {
+		return nil
+	}
+	_, hidden := splitPanels(user, data.Get("panels").MustArray())
+	ids := make(map[int64]bool, len(hidden))
+	for _, h := range hidden {
+		forEachPanel([]interface{}{h.panel}, func(panel map[string]interface{}) {
+			if id, ok := toInt64(panel["id"]); ok {
+				ids[id] = true
+			}
+		})
+	}
+	return ids
+}
+
+// RestoreHiddenPanels adds the panels of the existing dashboard the user can't
+// see to the dashboard they save, since they were removed from the dashboard the
+// user loaded. Saved panels that reuse the id of a hidden panel are new panels,
+// they get another id so that the hidden panels keep theirs.
+func RestoreHiddenPanels(user *models.SignedInUser, existing, saved *simplejson.Json) {
+	if existing == nil || saved == nil || CanViewAllPanels(user) {
+		return
+	}
+	_, hidden := splitPanels(user, existing.Get("panels").MustArray())
+	if len(hidden) == 0 {
+		return
+	}
+
+	hiddenIDs := map[int64]bool{}
+	maxID := int64(0)
+	for _, h := range hidden {
+		id, _ := toInt64(h.panel["id"])
+		hiddenIDs[id] = true
+		if id > maxID {
+			maxID = id
+		}
+	}
+	panels := saved.Get("panels").MustArray()
+	forEachPanel(panels, func(panel map[string]interface{}) {
+		if id, _ := toInt64(panel["id"]); id > maxID {
+			maxID = id
+		}
+	})
+	rows := map[int64]map[string]interface{}{}
+	forEachPanel(panels, func(panel map[string]interface{}) {
+		id, _ := toInt64(panel["id"])
+		if hiddenIDs[id] {
+			maxID++
+			panel["id"] = maxID
+			return
+		}
+		if _, collapsed := panel["panels"].([]interface{}); collapsed && panel["type"] == "row" {
+			rows[id] = panel
+		}
+	})
+
+	for _, h := range hidden {
+		if row, ok := rows[h.rowID]; ok && h.rowID != 0 {
+			row["panels"] = append(row["panels"].([]interface{}), h.panel)
+			continue
+		}
+		panels = append(panels, h.panel)
+	}
+	saved.Set("panels", panels)
+}
+
+// splitPanels returns the panels the user can see, and the panels they can't.
+// Collapsed rows are copied when some of their panels are hidden.
+func splitPanels(user *models.SignedInUser, panels []interface{}) ([]interface{}, []hiddenPanel) {
+	visible := make([]interface{}, 0, len(panels))
+	var hidden []hiddenPanel
+	// the panels that follow an expanded row belong to it until the next row
+	inHiddenRow := false
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			visible = append(visible, p)
+			continue
+		}
+
+		if panel["type"] == "row" {
+			inHiddenRow = !isPanelVisible(user, panel)
+			if inHiddenRow {
+				hidden = append(hidden, hiddenPanel{panel: panel})
+				continue
+			}
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				rowID, _ := toInt64(panel["id"])
+				nestedVisible, nestedHidden := splitPanels(user, nested)
+				if len(nestedHidden) > 0 {
+					row := make(map[string]interface{}, len(panel))
+					for k, v := range panel {
+						row[k] = v
+					}
+					row["panels"] = nestedVisible
+					panel = row
+					for _, h := range nestedHidden {
+						hidden = append(hidden, hiddenPanel{panel: h.panel, rowID: rowID})
+					}
+				}
+			}
+			visible = append(visible, panel)
+			continue
+		}
+
+		if inHiddenRow || !isPanelVisible(user, panel) {
+			hidden = append(hidden, hiddenPanel{panel: panel})
+			continue
+		}
+		visible = append(visible, panel)
+	}
+	return visible, hidden
+}
+
+// isPanelVisible checks the visibility property of the panel, which restricts who
+// can see it, e.g. {"roles": ["Editor"], "teams": [1]}. A restricted panel is
+// visible to the users that have one of the roles, or a role that includes it,
+// and to the members of one of the teams. The rules of a row apply to the panels
+// of the row.
+func isPanelVisible(user *models.SignedInUser, panel map[string]interface{}) bool {
+	rules, ok := panel["visibility"].(map[string]interface{})
+	if !ok {
+		return true
+	}
+	roles, _ := rules["roles"].([]interface{})
+	teams, _ := rules["teams"].([]interface{})
+	if len(roles) == 0 && len(teams) == 0 {
+		return true
+	}
+
+	for _, r := range roles {
+		if role, ok := r.(string); ok && user.OrgRole.Includes(models.RoleType(role)) {
+			return true
+		}
+	}
+	for _, t := range teams {
+		teamID, ok := toInt64(t)
+		if !ok {
+			continue
+		}
+		for _, userTeam := range user.Teams {
+			if userTeam == teamID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forEachPanel calls fn for the panels, and the panels of collapsed rows.
+func forEachPanel(panels []interface{}, fn func(panel map[string]interface{})) {
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fn(panel)
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			forEachPanel(nested, fn)
+		}
+	}
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case float64:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,140 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const panelVisibilityDashboard = `{
+	"panels": [
+		{"id": 1, "type": "graph"},
+		{"id": 2, "type": "graph", "visibility": {"roles": ["Editor"]}},
+		{"id": 3, "type": "graph", "visibility": {"teams": [5]}},
+		{"id": 4, "type": "row", "visibility": {"roles": ["Admin"]}},
+		{"id": 5, "type": "graph"},
+		{"id": 6, "type": "row"},
+		{"id": 7, "type": "graph"},
+		{"id": 8, "type": "row", "collapsed": true, "panels": [
+			{"id": 9, "type": "graph"},
+			{"id": 10, "type": "graph", "visibility": {"roles": ["Admin"]}}
+		]}
+	]
+}`
+
+func panelIDs(t *testing.T, data *simplejson.Json) []int64 {
+	t.Helper()
+	var ids []int64
+	forEachPanel(data.Get("panels").MustArray(), func(panel map[string]interface{}) {
+		id, ok := toInt64(panel["id"])
+		require.True(t, ok)
+		ids = append(ids, id)
+	})
+	return ids
+}
+
+func TestRemoveHiddenPanels(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *models.SignedInUser
+		expected []int64
+	}{
+		{
+			name:     "viewer",
+			user:     &models.SignedInUser{OrgRole: models.ROLE_VIEWER},
+			expected: []int64{1, 6, 7, 8, 9},
+		},
+		{
+			name:     "editor in team",
+			user:     &models.SignedInUser{OrgRole: models.ROLE_EDITOR, Teams: []int64{5}},
+			expected: []int64{1, 2, 3, 6, 7, 8, 9},
+		},
+		{
+			name:     "admin",
+			user:     &models.SignedInUser{OrgRole: models.ROLE_ADMIN},
+			expected: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:     "grafana admin",
+			user:     &models.SignedInUser{OrgRole: models.ROLE_VIEWER, IsGrafanaAdmin: true},
+			expected: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := simplejson.NewJson([]byte(panelVisibilityDashboard))
+			require.NoError(t, err)
+			RemoveHiddenPanels(tt.user, data)
+			require.Equal(t, tt.expected, panelIDs(t, data))
+		})
+	}
+}
+
+func TestCanViewPanel(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(panelVisibilityDashboard))
+	require.NoError(t, err)
+	viewer := &models.SignedInUser{OrgRole: models.ROLE_VIEWER}
+
+	require.True(t, CanViewPanel(viewer, data, 1))
+	require.False(t, CanViewPanel(viewer, data, 2))
+	require.False(t, CanViewPanel(viewer, data, 5))
+	require.False(t, CanViewPanel(viewer, data, 10))
+	require.True(t, CanViewPanel(viewer, data, 42))
+	// the dashboard is not changed
+	require.Len(t, panelIDs(t, data), 10)
+}
+
+func TestHiddenPanelIDs(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(panelVisibilityDashboard))
+	require.NoError(t, err)
+	data.Set("panels", append(data.Get("panels").MustArray(), map[string]interface{}{
+		"id": 11, "type": "row", "collapsed": true, "visibility": map[string]interface{}{"teams": []interface{}{5}},
+		"panels": []interface{}{map[string]interface{}{"id": 12, "type": "graph"}},
+	}))
+
+	viewer := &models.SignedInUser{OrgRole: models.ROLE_VIEWER}
+	require.Equal(t, map[int64]bool{2: true, 3: true, 4: true, 5: true, 10: true, 11: true, 12: true}, HiddenPanelIDs(viewer, data))
+	require.False(t, CanViewPanel(viewer, data, 12))
+
+	require.Empty(t, HiddenPanelIDs(&models.SignedInUser{OrgRole: models.ROLE_ADMIN}, data))
+}
+
+func TestRemoveRestrictedPanels(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(panelVisibilityDashboard))
+	require.NoError(t, err)
+
+	require.False(t, IsPanelRestricted(data, 1))
+	require.True(t, IsPanelRestricted(data, 2))
+	require.True(t, IsPanelRestricted(data, 5))
+	require.True(t, IsPanelRestricted(data, 10))
+
+	RemoveRestrictedPanels(data)
+	require.Equal(t, []int64{1, 6, 7, 8, 9}, panelIDs(t, data))
+}
+
+func TestRestoreHiddenPanels(t *testing.T) {
+	existing, err := simplejson.NewJson([]byte(panelVisibilityDashboard))
+	require.NoError(t, err)
+	viewer := &models.SignedInUser{OrgRole: models.ROLE_VIEWER}
+
+	// the viewer removed panel 1, and added a panel that reuses the id of a hidden panel
+	saved, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 2, "type": "text"},
+			{"id": 6, "type": "row"},
+			{"id": 7, "type": "graph"},
+			{"id": 8, "type": "row", "collapsed": true, "panels": [
+				{"id": 9, "type": "graph"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	RestoreHiddenPanels(viewer, existing, saved)
+	require.Equal(t, []int64{11, 6, 7, 8, 9, 10, 2, 3, 4, 5}, panelIDs(t, saved))
+	require.Equal(t, "text", saved.Get("panels").GetIndex(0).Get("type").MustString())
+}
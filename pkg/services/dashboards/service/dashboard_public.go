@@ -3,26 +3,34 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 )
 
-// Gets public dashboard via generated Uid
-func (dr *DashboardServiceImpl) GetPublicDashboard(ctx context.Context, dashboardUid string) (*models.Dashboard, error) {
-	pdc, d, err := dr.dashboardStore.GetPublicDashboard(dashboardUid)
+// allValue is the value of a template variable with all of its options selected.
+const allValue = "$__all"
+
+// Gets public dashboard and its configuration via access token
+func (dr *DashboardServiceImpl) GetPublicDashboard(ctx context.Context, accessToken string) (*models.PublicDashboard, *models.Dashboard, error) {
+	pdc, d, err := dr.dashboardStore.GetPublicDashboard(accessToken)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if pdc == nil || d == nil {
-		return nil, models.ErrPublicDashboardNotFound
+		return nil, nil, models.ErrPublicDashboardNotFound
 	}
 
 	if !d.IsPublic {
-		return nil, models.ErrPublicDashboardNotFound
+		return nil, nil, models.ErrPublicDashboardNotFound
 	}
 
 	// Replace dashboard time range with pubdash time range
@@ -30,13 +38,13 @@ func (dr *DashboardServiceImpl) GetPublicDashboard(ctx context.Context, dashboar
 		var pdcTimeSettings map[string]interface{}
 		err = json.Unmarshal([]byte(pdc.TimeSettings), &pdcTimeSettings)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		d.Data.Set("time", pdcTimeSettings)
 	}
 
-	return d, nil
+	return pdc, d, nil
 }
 
 // GetPublicDashboardConfig is a helper method to retrieve the public dashboard configuration for a given dashboard from the database
@@ -62,7 +70,11 @@ func (dr *DashboardServiceImpl) SavePublicDashboardConfig(ctx context.Context, d
 	cmd.PublicDashboardConfig.PublicDashboard.OrgId = dto.OrgId
 	cmd.PublicDashboardConfig.PublicDashboard.DashboardUid = dto.DashboardUid
 
-	pdc, err := dr.dashboardStore.SavePublicDashboardConfig(cmd)
+	if _, err := parseAllowedTemplateVariables(cmd.PublicDashboardConfig.PublicDashboard.TemplateVariables); err != nil {
+		return nil, err
+	}
+
+	pdc, err := dr.dashboardStore.SavePublicDashboardConfig(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -70,34 +82,191 @@ func (dr *DashboardServiceImpl) SavePublicDashboardConfig(ctx context.Context, d
 	return pdc, nil
 }
 
-func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error) {
-	publicDashboardConfig, dashboard, err := dr.dashboardStore.GetPublicDashboard(publicDashboardUid)
+// RotatePublicDashboardAccessToken replaces the access token of the public
+// dashboard of a dashboard, e.g. when a link to it was shared by mistake.
+func (dr *DashboardServiceImpl) RotatePublicDashboardAccessToken(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error) {
+	_, err := dr.dashboardStore.RotatePublicDashboardAccessToken(models.RotatePublicDashboardAccessTokenCommand{
+		DashboardUid: dashboardUid,
+		OrgId:        orgId,
+	})
 	if err != nil {
-		return dtos.MetricRequest{}, err
+		return nil, err
 	}
 
+	return dr.dashboardStore.GetPublicDashboardConfig(orgId, dashboardUid)
+}
+
+// BuildPublicDashboardMetricRequest builds the request for the data of a panel
+// of a public dashboard. The requested time range is limited to the time range
+// of the public dashboard and only the allowed template variable values can be
+// requested.
+func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Context, access *models.PublicDashboardAccess, panelId int64, query *dashboards.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
+	dashboard := access.Dashboard
 	if !dashboard.IsPublic {
 		return dtos.MetricRequest{}, models.ErrPublicDashboardNotFound
 	}
 
-	var timeSettings struct {
-		From string `json:"from"`
-		To   string `json:"to"`
+	queriesByPanel := models.GetQueriesFromDashboard(dashboard.Data)
+
+	// the panels with visibility rules aren't shown on public dashboards
+	if _, ok := queriesByPanel[panelId]; !ok || dashboards.IsPanelRestricted(dashboard.Data, panelId) {
+		return dtos.MetricRequest{}, models.ErrPublicDashboardPanelNotFound
 	}
-	err = json.Unmarshal([]byte(publicDashboardConfig.TimeSettings), &timeSettings)
+
+	from, to, err := publicDashboardTimeRange(dashboard, query, time.Now())
 	if err != nil {
 		return dtos.MetricRequest{}, err
 	}
 
-	queriesByPanel := models.GetQueriesFromDashboard(dashboard.Data)
+	values, err := publicDashboardVariableValues(access.PublicDashboard, dashboard, query.Variables)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
 
-	if _, ok := queriesByPanel[panelId]; !ok {
-		return dtos.MetricRequest{}, models.ErrPublicDashboardPanelNotFound
+	queries := queriesByPanel[panelId]
+	if len(values) > 0 {
+		for i, q := range queries {
+			queries[i] = simplejson.NewFromAny(dashboardvariables.InterpolateValue(q.Interface(), values))
+		}
 	}
 
 	return dtos.MetricRequest{
-		From:    timeSettings.From,
-		To:      timeSettings.To,
-		Queries: queriesByPanel[panelId],
+		From:    from,
+		To:      to,
+		Queries: queries,
 	}, nil
 }
+
+// publicDashboardTimeRange returns the time range of the public dashboard, or
+// the requested time range limited to it.
+func publicDashboardTimeRange(dashboard *models.Dashboard, query *dashboards.PublicDashboardQueryDTO, now time.Time) (string, string, error) {
+	from := dashboard.Data.GetPath("time", "from").MustString("now-6h")
+	to := dashboard.Data.GetPath("time", "to").MustString("now")
+	if query.From == "" && query.To == "" {
+		return from, to, nil
+	}
+
+	allowed := legacydata.DataTimeRange{From: from, To: to, Now: now}
+	allowedFrom, err := allowed.ParseFrom()
+	if err != nil {
+		return "", "", err
+	}
+	allowedTo, err := allowed.ParseTo()
+	if err != nil {
+		return "", "", err
+	}
+
+	requested := legacydata.DataTimeRange{From: from, To: to, Now: now}
+	if query.From != "" {
+		requested.From = query.From
+	}
+	if query.To != "" {
+		requested.To = query.To
+	}
+	requestedFrom, err := requested.ParseFrom()
+	if err != nil {
+		return "", "", models.ErrPublicDashboardInvalidTimeRange
+	}
+	requestedTo, err := requested.ParseTo()
+	if err != nil {
+		return "", "", models.ErrPublicDashboardInvalidTimeRange
+	}
+
+	if requestedFrom.Before(allowedFrom) {
+		requestedFrom = allowedFrom
+	}
+	if requestedTo.After(allowedTo) {
+		requestedTo = allowedTo
+	}
+	if !requestedFrom.Before(requestedTo) {
+		return "", "", models.ErrPublicDashboardTimeRangeNotAllowed
+	}
+
+	return strconv.FormatInt(requestedFrom.UnixMilli(), 10), strconv.FormatInt(requestedTo.UnixMilli(), 10), nil
+}
+
+// publicDashboardVariableValues returns the values the queries of a public
+// dashboard are interpolated with: the saved values of its template variables,
+// replaced by the requested values the public dashboard allows.
+func publicDashboardVariableValues(pd *models.PublicDashboard, dashboard *models.Dashboard, requested map[string][]string) (map[string][]string, error) {
+	values := map[string][]string{}
+	list := dashboard.Data.GetPath("templating", "list")
+	for i := range list.MustArray() {
+		variable := list.GetIndex(i)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		current := variable.GetPath("current", "value")
+		var vals []string
+		if s, err := current.String(); err == nil {
+			vals = []string{s}
+		} else {
+			vals = current.MustStringArray()
+		}
+
+		if len(vals) == 1 && vals[0] == allValue {
+			if custom := variable.Get("allValue").MustString(); custom != "" {
+				vals = []string{custom}
+			} else {
+				vals = nil
+				options := variable.Get("options")
+				for j := range options.MustArray() {
+					if v := options.GetIndex(j).Get("value").MustString(); v != allValue {
+						vals = append(vals, v)
+					}
+				}
+			}
+		}
+
+		if len(vals) > 0 {
+			values[name] = vals
+		}
+	}
+
+	if len(requested) == 0 {
+		return values, nil
+	}
+
+	allowed, err := parseAllowedTemplateVariables(pd.TemplateVariables)
+	if err != nil {
+		return nil, err
+	}
+	for name, vals := range requested {
+		allowedVals, ok := allowed[name]
+		if !ok {
+			return nil, models.ErrPublicDashboardVariableNotAllowed
+		}
+		for _, v := range vals {
+			if !containsString(allowedVals, v) {
+				return nil, models.ErrPublicDashboardVariableNotAllowed
+			}
+		}
+		values[name] = vals
+	}
+
+	return values, nil
+}
+
+// parseAllowedTemplateVariables parses the template variable values anonymous
+// users can choose from, by variable name.
+func parseAllowedTemplateVariables(templateVariables string) (map[string][]string, error) {
+	allowed := map[string][]string{}
+	if templateVariables == "" {
+		return allowed, nil
+	}
+	if err := json.Unmarshal([]byte(templateVariables), &allowed); err != nil {
+		return nil, models.ErrPublicDashboardInvalidTemplateVariables
+	}
+	return allowed, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -82,7 +84,7 @@ func TestGetPublicDashboard(t *testing.T) {
 			fakeStore.On("GetPublicDashboard", mock.Anything).
 				Return(test.storeResp.pd, test.storeResp.d, test.storeResp.err)
 
-			dashboard, err := service.GetPublicDashboard(context.Background(), test.uid)
+			_, dashboard, err := service.GetPublicDashboard(context.Background(), test.uid)
 			if test.errResp != nil {
 				assert.Error(t, test.errResp, err)
 			} else {
@@ -121,6 +123,26 @@ func TestSavePublicDashboard(t *testing.T) {
 
 		assert.Equal(t, dashboard.Uid, pdc.PublicDashboard.DashboardUid)
 		assert.Equal(t, dashboard.OrgId, pdc.PublicDashboard.OrgId)
+		assert.Len(t, pdc.PublicDashboard.AccessToken, 32)
+	})
+
+	t.Run("returns an error when the allowed template variables are invalid", func(t *testing.T) {
+		service := &DashboardServiceImpl{
+			log:            log.New("test.logger"),
+			dashboardStore: &dashboards.FakeDashboardStore{},
+		}
+
+		dto := &dashboards.SavePublicDashboardConfigDTO{
+			DashboardUid: "abc123",
+			OrgId:        1,
+			PublicDashboardConfig: &models.PublicDashboardConfig{
+				IsPublic:        true,
+				PublicDashboard: models.PublicDashboard{TemplateVariables: `{"job": "api"}`},
+			},
+		}
+
+		_, err := service.SavePublicDashboardConfig(context.Background(), dto)
+		require.ErrorIs(t, err, models.ErrPublicDashboardInvalidTemplateVariables)
 	})
 
 	t.Run("PLACEHOLDER - dashboard with template variables cannot be saved", func(t *testing.T) {
@@ -196,11 +218,19 @@ func TestBuildPublicDashboardMetricRequest(t *testing.T) {
 	nonPublicPdc, err := service.SavePublicDashboardConfig(context.Background(), nonPublicDto)
 	require.NoError(t, err)
 
+	getAccess := func(t *testing.T, accessToken string) *models.PublicDashboardAccess {
+		t.Helper()
+		pd, d, err := service.GetPublicDashboard(context.Background(), accessToken)
+		require.NoError(t, err)
+		return &models.PublicDashboardAccess{PublicDashboard: pd, Dashboard: d}
+	}
+
 	t.Run("extracts queries from provided dashboard", func(t *testing.T) {
 		reqDTO, err := service.BuildPublicDashboardMetricRequest(
 			context.Background(),
-			pdc.PublicDashboard.Uid,
+			getAccess(t, pdc.PublicDashboard.AccessToken),
 			1,
+			&dashboards.PublicDashboardQueryDTO{},
 		)
 		require.NoError(t, err)
 
@@ -234,21 +264,146 @@ func TestBuildPublicDashboardMetricRequest(t *testing.T) {
 	t.Run("returns an error when panel missing", func(t *testing.T) {
 		_, err := service.BuildPublicDashboardMetricRequest(
 			context.Background(),
-			pdc.PublicDashboard.Uid,
+			getAccess(t, pdc.PublicDashboard.AccessToken),
 			49,
+			&dashboards.PublicDashboardQueryDTO{},
 		)
 
 		require.ErrorContains(t, err, "Panel not found")
 	})
 
+	t.Run("returns an error when the panel has visibility rules", func(t *testing.T) {
+		access := &models.PublicDashboardAccess{
+			PublicDashboard: &models.PublicDashboard{},
+			Dashboard: &models.Dashboard{
+				IsPublic: true,
+				Data: simplejson.MustJson([]byte(`{
+					"panels": [
+						{ "id": 1, "targets": [ { "refId": "A" } ] },
+						{ "id": 2, "visibility": { "roles": ["Viewer"] }, "targets": [ { "refId": "A" } ] }
+					]
+				}`)),
+			},
+		}
+
+		_, err := service.BuildPublicDashboardMetricRequest(context.Background(), access, 1, &dashboards.PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+
+		_, err = service.BuildPublicDashboardMetricRequest(context.Background(), access, 2, &dashboards.PublicDashboardQueryDTO{})
+		require.ErrorIs(t, err, models.ErrPublicDashboardPanelNotFound)
+	})
+
 	t.Run("returns an error when dashboard not public", func(t *testing.T) {
-		_, err := service.BuildPublicDashboardMetricRequest(
+		_, _, err := service.GetPublicDashboard(context.Background(), nonPublicPdc.PublicDashboard.AccessToken)
+		require.ErrorContains(t, err, "Public dashboard not found")
+
+		_, err = service.BuildPublicDashboardMetricRequest(
 			context.Background(),
-			nonPublicPdc.PublicDashboard.Uid,
+			&models.PublicDashboardAccess{PublicDashboard: &nonPublicPdc.PublicDashboard, Dashboard: nonPublicDashboard},
 			2,
+			&dashboards.PublicDashboardQueryDTO{},
 		)
 		require.ErrorContains(t, err, "Public dashboard not found")
 	})
+
+	t.Run("interpolates queries with the allowed template variable values", func(t *testing.T) {
+		access := &models.PublicDashboardAccess{
+			PublicDashboard: &models.PublicDashboard{TemplateVariables: `{"job": ["api", "web"]}`},
+			Dashboard: &models.Dashboard{
+				IsPublic: true,
+				Data: simplejson.MustJson([]byte(`{
+					"time": { "from": "now-1h", "to": "now" },
+					"templating": { "list": [
+						{ "name": "job", "current": { "value": "api" } },
+						{ "name": "instance", "current": { "value": ["a", "b"] } }
+					] },
+					"panels": [ { "id": 1, "targets": [
+						{ "refId": "A", "expr": "up{job=\"$job\", instance=~\"${instance:regex}\"}" }
+					] } ]
+				}`)),
+			},
+		}
+
+		reqDTO, err := service.BuildPublicDashboardMetricRequest(context.Background(), access, 1, &dashboards.PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Equal(t, `up{job="api", instance=~"(a|b)"}`, reqDTO.Queries[0].Get("expr").MustString())
+
+		reqDTO, err = service.BuildPublicDashboardMetricRequest(context.Background(), access, 1, &dashboards.PublicDashboardQueryDTO{
+			Variables: map[string][]string{"job": {"web"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `up{job="web", instance=~"(a|b)"}`, reqDTO.Queries[0].Get("expr").MustString())
+
+		_, err = service.BuildPublicDashboardMetricRequest(context.Background(), access, 1, &dashboards.PublicDashboardQueryDTO{
+			Variables: map[string][]string{"job": {"db"}},
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardVariableNotAllowed)
+
+		_, err = service.BuildPublicDashboardMetricRequest(context.Background(), access, 1, &dashboards.PublicDashboardQueryDTO{
+			Variables: map[string][]string{"instance": {"c"}},
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardVariableNotAllowed)
+	})
+}
+
+func TestPublicDashboardTimeRange(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	dashboard := &models.Dashboard{Data: simplejson.NewFromAny(map[string]interface{}{
+		"time": map[string]interface{}{"from": "now-6h", "to": "now"},
+	})}
+	ms := func(t time.Time) string {
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+
+	testCases := []struct {
+		name         string
+		query        *dashboards.PublicDashboardQueryDTO
+		expectedFrom string
+		expectedTo   string
+		expectedErr  error
+	}{
+		{
+			name:         "uses the time range of the public dashboard by default",
+			query:        &dashboards.PublicDashboardQueryDTO{},
+			expectedFrom: "now-6h",
+			expectedTo:   "now",
+		},
+		{
+			name:         "uses a time range within the time range of the public dashboard",
+			query:        &dashboards.PublicDashboardQueryDTO{From: ms(now.Add(-time.Hour)), To: ms(now.Add(-time.Minute))},
+			expectedFrom: ms(now.Add(-time.Hour)),
+			expectedTo:   ms(now.Add(-time.Minute)),
+		},
+		{
+			name:         "limits the time range to the time range of the public dashboard",
+			query:        &dashboards.PublicDashboardQueryDTO{From: "now-7d", To: "now+1h"},
+			expectedFrom: ms(now.Add(-6 * time.Hour)),
+			expectedTo:   ms(now),
+		},
+		{
+			name:        "returns an error when the time range is outside of the time range of the public dashboard",
+			query:       &dashboards.PublicDashboardQueryDTO{From: "now-7d", To: "now-1d"},
+			expectedErr: models.ErrPublicDashboardTimeRangeNotAllowed,
+		},
+		{
+			name:        "returns an error when the time range is invalid",
+			query:       &dashboards.PublicDashboardQueryDTO{From: "yesterday"},
+			expectedErr: models.ErrPublicDashboardInvalidTimeRange,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			from, to, err := publicDashboardTimeRange(dashboard, test.query, now)
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedFrom, from)
+			require.Equal(t, test.expectedTo, to)
+		})
+	}
 }
 
 func insertTestDashboard(t *testing.T, dashboardStore *database.DashboardStore, title string, orgId int64,
@@ -297,7 +452,7 @@ func insertTestDashboard(t *testing.T, dashboardStore *database.DashboardStore,
 			},
 		}),
 	}
-	dash, err := dashboardStore.SaveDashboard(cmd)
+	dash, err := dashboardStore.SaveDashboard(context.Background(), cmd)
 	require.NoError(t, err)
 	require.NotNil(t, dash)
 	dash.Data.Set("id", dash.Id)
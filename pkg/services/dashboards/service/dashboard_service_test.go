@@ -46,13 +46,6 @@ func TestIntegrationDashboardService(t *testing.T) {
 				}
 			})
 
-			t.Run("Should return validation error if it's a folder and have a folder id", func(t *testing.T) {
-				dto.Dashboard = models.NewDashboardFolder("Folder")
-				dto.Dashboard.FolderId = 1
-				_, err := service.SaveDashboard(context.Background(), dto, false)
-				require.Equal(t, err, models.ErrDashboardFolderCannotHaveParent)
-			})
-
 			t.Run("Should return validation error if folder is named General", func(t *testing.T) {
 				dto.Dashboard = models.NewDashboardFolder("General")
 				_, err := service.SaveDashboard(context.Background(), dto, false)
@@ -99,7 +92,7 @@ func TestIntegrationDashboardService(t *testing.T) {
 
 			t.Run("Should not return validation error if dashboard is provisioned but UI updates allowed", func(t *testing.T) {
 				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
-				fakeStore.On("SaveDashboard", mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
+				fakeStore.On("SaveDashboard", mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
 				fakeStore.On("SaveAlerts", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
 				dto.Dashboard = models.NewDashboard("Dash")
@@ -109,10 +102,25 @@ func TestIntegrationDashboardService(t *testing.T) {
 				require.NoError(t, err)
 			})
 
+			t.Run("Should not save the dashboard on dry runs", func(t *testing.T) {
+				// SaveDashboard and SaveAlerts are not expected on the store
+				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
+				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything).Return(nil, nil).Once()
+
+				dto.Dashboard = models.NewDashboard("Dash")
+				dto.Dashboard.SetId(3)
+				dto.User = &models.SignedInUser{UserId: 1}
+				dto.DryRun = true
+				defer func() { dto.DryRun = false }()
+				dash, err := service.SaveDashboard(context.Background(), dto, false)
+				require.NoError(t, err)
+				require.Equal(t, dto.Dashboard, dash)
+			})
+
 			t.Run("Should return validation error if alert data is invalid", func(t *testing.T) {
 				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
 				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything).Return(nil, nil).Once()
-				fakeStore.On("SaveDashboard", mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
+				fakeStore.On("SaveDashboard", mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
 				fakeStore.On("SaveAlerts", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("alert validation error")).Once()
 
 				dto.Dashboard = models.NewDashboard("Dash")
@@ -127,7 +135,7 @@ func TestIntegrationDashboardService(t *testing.T) {
 
 			t.Run("Should not return validation error if dashboard is provisioned", func(t *testing.T) {
 				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
-				fakeStore.On("SaveProvisionedDashboard", mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
+				fakeStore.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
 				fakeStore.On("SaveAlerts", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
 				dto.Dashboard = models.NewDashboard("Dash")
@@ -139,7 +147,7 @@ func TestIntegrationDashboardService(t *testing.T) {
 
 			t.Run("Should override invalid refresh interval if dashboard is provisioned", func(t *testing.T) {
 				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
-				fakeStore.On("SaveProvisionedDashboard", mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
+				fakeStore.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
 				fakeStore.On("SaveAlerts", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
 				oldRefreshInterval := setting.MinRefreshInterval
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -23,15 +24,17 @@ type FolderServiceImpl struct {
 	searchService    *search.SearchService
 	features         featuremgmt.FeatureToggles
 	permissions      accesscontrol.FolderPermissionsService
+	db               db.DB
 }
 
 func ProvideFolderService(
 	cfg *setting.Cfg, dashboardService dashboards.DashboardService, dashboardStore dashboards.Store,
 	searchService *search.SearchService, features featuremgmt.FeatureToggles, folderPermissionsService accesscontrol.FolderPermissionsService,
-	ac accesscontrol.AccessControl,
+	ac accesscontrol.AccessControl, db db.DB,
 ) *FolderServiceImpl {
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderNameScopeResolver(dashboardStore))
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderIDScopeResolver(dashboardStore))
+	ac.RegisterScopeAttributeResolver(dashboards.NewFolderUIDScopeResolver(dashboardStore))
 
 	return &FolderServiceImpl{
 		cfg:              cfg,
@@ -41,6 +44,7 @@ func ProvideFolderService(
 		searchService:    searchService,
 		features:         features,
 		permissions:      folderPermissionsService,
+		db:               db,
 	}
 }
 
@@ -64,9 +68,10 @@ func (f *FolderServiceImpl) GetFolders(ctx context.Context, user *models.SignedI
 
 	for _, hit := range searchQuery.Result {
 		folders = append(folders, &models.Folder{
-			Id:    hit.ID,
-			Uid:   hit.UID,
-			Title: hit.Title,
+			Id:       hit.ID,
+			Uid:      hit.UID,
+			Title:    hit.Title,
+			ParentId: hit.FolderID,
 		})
 	}
 
@@ -127,7 +132,43 @@ func (f *FolderServiceImpl) GetFolderByTitle(ctx context.Context, user *models.S
 	return dashFolder, nil
 }
 
-func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid string) (*models.Folder, error) {
+// GetFolderParents leaves out the parents the user can't view, so that their
+// titles aren't disclosed to users with access to a subfolder only.
+func (f *FolderServiceImpl) GetFolderParents(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	folder, err := f.GetFolderByUID(ctx, user, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := f.dashboardStore.GetFolderAncestors(ctx, orgID, folder.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make([]*models.Folder, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		g := guardian.New(ctx, ancestor.Id, orgID, user)
+		canView, err := g.CanView()
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		if canView {
+			parents = append(parents, ancestor)
+		}
+	}
+	return parents, nil
+}
+
+func (f *FolderServiceImpl) GetFolderDescendants(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	folder, err := f.GetFolderByUID(ctx, user, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.dashboardStore.GetFolderDescendants(ctx, orgID, folder.Id)
+}
+
+func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error) {
 	dashFolder := models.NewDashboardFolder(title)
 	dashFolder.OrgId = orgID
 
@@ -136,6 +177,12 @@ func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.Signe
 		return nil, models.ErrFolderInvalidUID
 	}
 
+	parentID, err := f.getParentID(ctx, orgID, parentUID)
+	if err != nil {
+		return nil, err
+	}
+	dashFolder.FolderId = parentID
+
 	dashFolder.SetUid(trimmedUID)
 	userID := user.UserId
 	if userID == 0 {
@@ -156,30 +203,46 @@ func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.Signe
 		return nil, toFolderError(err)
 	}
 
-	dash, err := f.dashboardStore.SaveDashboard(*saveDashboardCmd)
-	if err != nil {
-		return nil, toFolderError(err)
-	}
-
+	// the folder and its permissions are saved in a single transaction, so
+	// that no folder is left behind when the permissions can't be set
 	var folder *models.Folder
-	folder, err = f.dashboardStore.GetFolderByID(ctx, orgID, dash.Id)
-	if err != nil {
-		return nil, err
-	}
+	err = f.db.InTransaction(ctx, func(ctx context.Context) error {
+		dash, err := f.dashboardStore.SaveDashboard(ctx, *saveDashboardCmd)
+		if err != nil {
+			return toFolderError(err)
+		}
 
-	var permissionErr error
-	if !accesscontrol.IsDisabled(f.cfg) {
-		_, permissionErr = f.permissions.SetPermissions(ctx, orgID, folder.Uid, []accesscontrol.SetResourcePermissionCommand{
-			{UserID: userID, Permission: models.PERMISSION_ADMIN.String()},
-			{BuiltinRole: string(models.ROLE_EDITOR), Permission: models.PERMISSION_EDIT.String()},
-			{BuiltinRole: string(models.ROLE_VIEWER), Permission: models.PERMISSION_VIEW.String()},
-		}...)
-	} else if f.cfg.EditorsCanAdmin {
-		permissionErr = f.MakeUserAdmin(ctx, orgID, userID, folder.Id, true)
-	}
+		folder, err = f.dashboardStore.GetFolderByID(ctx, orgID, dash.Id)
+		if err != nil {
+			return err
+		}
 
-	if permissionErr != nil {
-		f.log.Error("Could not make user admin", "folder", folder.Title, "user", userID, "error", permissionErr)
+		var permissionErr error
+		if !accesscontrol.IsDisabled(f.cfg) {
+			permissions := []accesscontrol.SetResourcePermissionCommand{
+				{UserID: userID, Permission: models.PERMISSION_ADMIN.String()},
+			}
+			// subfolders inherit the permissions of their parent, the default
+			// permissions would grant access to folders the roles can't view
+			if parentID == 0 {
+				permissions = append(permissions, []accesscontrol.SetResourcePermissionCommand{
+					{BuiltinRole: string(models.ROLE_EDITOR), Permission: models.PERMISSION_EDIT.String()},
+					{BuiltinRole: string(models.ROLE_VIEWER), Permission: models.PERMISSION_VIEW.String()},
+				}...)
+			}
+			_, permissionErr = f.permissions.SetPermissions(ctx, orgID, folder.Uid, permissions...)
+		} else if f.cfg.EditorsCanAdmin {
+			permissionErr = f.MakeUserAdmin(ctx, orgID, userID, folder.Id, parentID == 0)
+		}
+
+		if permissionErr != nil {
+			f.log.Error("Could not make user admin", "folder", folder.Title, "user", userID, "error", permissionErr)
+			return permissionErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return folder, nil
@@ -199,6 +262,14 @@ func (f *FolderServiceImpl) UpdateFolder(ctx context.Context, user *models.Signe
 
 	cmd.UpdateDashboardModel(dashFolder, orgID, user.UserId)
 
+	if cmd.ParentUid != nil {
+		parentID, err := f.getParentID(ctx, orgID, *cmd.ParentUid)
+		if err != nil {
+			return err
+		}
+		dashFolder.FolderId = parentID
+	}
+
 	dto := &dashboards.SaveDashboardDTO{
 		Dashboard: dashFolder,
 		OrgId:     orgID,
@@ -211,7 +282,7 @@ func (f *FolderServiceImpl) UpdateFolder(ctx context.Context, user *models.Signe
 		return toFolderError(err)
 	}
 
-	dash, err := f.dashboardStore.SaveDashboard(*saveDashboardCmd)
+	dash, err := f.dashboardStore.SaveDashboard(ctx, *saveDashboardCmd)
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -239,6 +310,20 @@ func (f *FolderServiceImpl) DeleteFolder(ctx context.Context, user *models.Signe
 		return nil, models.ErrFolderAccessDenied
 	}
 
+	descendants, err := f.dashboardStore.GetFolderDescendants(ctx, orgID, dashFolder.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the subfolders are deleted before their parents, the folders that are
+	// left when a deletion fails are still in the tree
+	for i := len(descendants) - 1; i >= 0; i-- {
+		deleteCmd := models.DeleteDashboardCommand{OrgId: orgID, Id: descendants[i].Id, ForceDeleteFolderRules: forceDeleteRules}
+		if err := f.dashboardStore.DeleteDashboard(ctx, &deleteCmd); err != nil {
+			return nil, toFolderError(err)
+		}
+	}
+
 	deleteCmd := models.DeleteDashboardCommand{OrgId: orgID, Id: dashFolder.Id, ForceDeleteFolderRules: forceDeleteRules}
 
 	if err := f.dashboardStore.DeleteDashboard(ctx, &deleteCmd); err != nil {
@@ -252,6 +337,20 @@ func (f *FolderServiceImpl) MakeUserAdmin(ctx context.Context, orgID int64, user
 	return f.dashboardService.MakeUserAdmin(ctx, orgID, userID, folderID, setViewAndEditPermissions)
 }
 
+// getParentID returns the id of the folder with the uid, 0 for the root.
+func (f *FolderServiceImpl) getParentID(ctx context.Context, orgID int64, parentUID string) (int64, error) {
+	parentUID = strings.TrimSpace(parentUID)
+	if parentUID == "" || parentUID == accesscontrol.GeneralFolderUID {
+		return 0, nil
+	}
+
+	parent, err := f.dashboardStore.GetFolderByUID(ctx, orgID, parentUID)
+	if err != nil {
+		return 0, err
+	}
+	return parent.Id, nil
+}
+
 func toFolderError(err error) error {
 	if errors.Is(err, models.ErrDashboardTitleEmpty) {
 		return models.ErrFolderTitleEmpty
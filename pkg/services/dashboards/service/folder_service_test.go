@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"testing"
 
@@ -11,10 +12,14 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db/dbtest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -30,9 +35,9 @@ func TestIntegrationProvideFolderService(t *testing.T) {
 		cfg := setting.NewCfg()
 		ac := acmock.New()
 
-		ProvideFolderService(cfg, nil, nil, nil, nil, nil, ac)
+		ProvideFolderService(cfg, nil, nil, nil, nil, nil, ac, nil)
 
-		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 2)
+		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 3)
 	})
 }
 
@@ -57,6 +62,7 @@ func TestIntegrationFolderService(t *testing.T) {
 			searchService:    nil,
 			features:         features,
 			permissions:      folderPermissions,
+			db:               dbtest.NewFakeDB(),
 		}
 
 		t.Run("Given user has no permissions", func(t *testing.T) {
@@ -91,7 +97,7 @@ func TestIntegrationFolderService(t *testing.T) {
 
 			t.Run("When creating folder should return access denied error", func(t *testing.T) {
 				store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Times(2)
-				_, err := service.CreateFolder(context.Background(), user, orgID, folder.Title, folderUID)
+				_, err := service.CreateFolder(context.Background(), user, orgID, folder.Title, folderUID, "")
 				require.Equal(t, err, models.ErrFolderAccessDenied)
 			})
 
@@ -129,10 +135,10 @@ func TestIntegrationFolderService(t *testing.T) {
 				f := models.DashboardToFolder(dash)
 
 				store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil)
-				store.On("SaveDashboard", mock.Anything).Return(dash, nil).Once()
+				store.On("SaveDashboard", mock.Anything, mock.Anything).Return(dash, nil).Once()
 				store.On("GetFolderByID", mock.Anything, orgID, dash.Id).Return(f, nil)
 
-				actualFolder, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "")
+				actualFolder, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "", "")
 				require.NoError(t, err)
 				require.Equal(t, f, actualFolder)
 			})
@@ -141,7 +147,7 @@ func TestIntegrationFolderService(t *testing.T) {
 				dash := models.NewDashboardFolder("Test-Folder")
 				dash.Id = rand.Int63()
 
-				_, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "general")
+				_, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "general", "")
 				require.ErrorIs(t, err, models.ErrFolderInvalidUID)
 			})
 
@@ -152,7 +158,7 @@ func TestIntegrationFolderService(t *testing.T) {
 				f := models.DashboardToFolder(dashboardFolder)
 
 				store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil)
-				store.On("SaveDashboard", mock.Anything).Return(dashboardFolder, nil)
+				store.On("SaveDashboard", mock.Anything, mock.Anything).Return(dashboardFolder, nil)
 				store.On("GetFolderByID", mock.Anything, orgID, dashboardFolder.Id).Return(f, nil)
 
 				req := &models.UpdateFolderCommand{
@@ -170,6 +176,7 @@ func TestIntegrationFolderService(t *testing.T) {
 				f.Id = rand.Int63()
 				f.Uid = util.GenerateShortUID()
 				store.On("GetFolderByUID", mock.Anything, orgID, f.Uid).Return(f, nil)
+				store.On("GetFolderDescendants", mock.Anything, orgID, f.Id).Return([]*models.Folder{}, nil).Once()
 
 				var actualCmd *models.DeleteDashboardCommand
 				store.On("DeleteDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
@@ -185,6 +192,26 @@ func TestIntegrationFolderService(t *testing.T) {
 				require.Equal(t, expectedForceDeleteRules, actualCmd.ForceDeleteFolderRules)
 			})
 
+			t.Run("When deleting folder by uid should delete its subfolders first", func(t *testing.T) {
+				f := models.NewFolder(util.GenerateShortUID())
+				f.Id = rand.Int63()
+				f.Uid = util.GenerateShortUID()
+				store.On("GetFolderByUID", mock.Anything, orgID, f.Uid).Return(f, nil)
+				store.On("GetFolderDescendants", mock.Anything, orgID, f.Id).Return([]*models.Folder{
+					{Id: f.Id + 1, ParentId: f.Id},
+					{Id: f.Id + 2, ParentId: f.Id + 1},
+				}, nil).Once()
+
+				var deleted []int64
+				store.On("DeleteDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					deleted = append(deleted, args.Get(1).(*models.DeleteDashboardCommand).Id)
+				}).Return(nil).Times(3)
+
+				_, err := service.DeleteFolder(context.Background(), user, orgID, f.Uid, false)
+				require.NoError(t, err)
+				require.Equal(t, []int64{f.Id + 2, f.Id + 1, f.Id}, deleted)
+			})
+
 			t.Cleanup(func() {
 				guardian.New = origNewGuardian
 			})
@@ -254,3 +281,32 @@ func TestIntegrationFolderService(t *testing.T) {
 		})
 	})
 }
+
+func TestIntegrationFolderService_CreateFolderRollback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	sqlStore.Cfg.RBACEnabled = true
+	dashboardStore := database.ProvideDashboardStore(sqlStore)
+	features := featuremgmt.WithFeatures()
+	sqlStore.Cfg.IsFeatureToggleEnabled = features.IsEnabled
+	folderPermissions := acmock.NewMockedPermissionsService()
+	dashboardService := ProvideDashboardService(sqlStore.Cfg, dashboardStore, nil, features, folderPermissions,
+		acmock.NewMockedPermissionsService(), acmock.New())
+	service := ProvideFolderService(sqlStore.Cfg, dashboardService, dashboardStore, nil, features, folderPermissions, acmock.New(), sqlStore)
+
+	origNewGuardian := guardian.New
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true, CanViewValue: true})
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	folderPermissions.On("SetPermissions", mock.Anything, orgID, "orphan", mock.Anything).
+		Return([]accesscontrol.ResourcePermission{}, errors.New("permissions failed")).Once()
+	_, err := service.CreateFolder(context.Background(), user, orgID, "Orphan", "orphan", "")
+	require.Error(t, err)
+
+	_, err = dashboardStore.GetFolderByUID(context.Background(), orgID, "orphan")
+	require.ErrorIs(t, err, models.ErrFolderNotFound, "the folder is rolled back when its permissions can't be set")
+}
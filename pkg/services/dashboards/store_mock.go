@@ -160,6 +160,29 @@ func (_m *FakeDashboardStore) GetDashboardsByPluginID(ctx context.Context, query
 	return r0
 }
 
+// GetFolderAncestors provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeDashboardStore) GetFolderAncestors(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolderByID provides a mock function with given fields: ctx, orgID, id
 func (_m *FakeDashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
 	ret := _m.Called(ctx, orgID, id)
@@ -229,6 +252,29 @@ func (_m *FakeDashboardStore) GetFolderByUID(ctx context.Context, orgID int64, u
 	return r0, r1
 }
 
+// GetFolderDescendants provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeDashboardStore) GetFolderDescendants(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProvisionedDashboardData provides a mock function with given fields: name
 func (_m *FakeDashboardStore) GetProvisionedDashboardData(name string) ([]*models.DashboardProvisioning, error) {
 	ret := _m.Called(name)
@@ -298,13 +344,13 @@ func (_m *FakeDashboardStore) GetProvisionedDataByDashboardUID(orgID int64, dash
 	return r0, r1
 }
 
-// GetPublicDashboard provides a mock function with given fields: uid
-func (_m *FakeDashboardStore) GetPublicDashboard(uid string) (*models.PublicDashboard, *models.Dashboard, error) {
-	ret := _m.Called(uid)
+// GetPublicDashboard provides a mock function with given fields: accessToken
+func (_m *FakeDashboardStore) GetPublicDashboard(accessToken string) (*models.PublicDashboard, *models.Dashboard, error) {
+	ret := _m.Called(accessToken)
 
 	var r0 *models.PublicDashboard
 	if rf, ok := ret.Get(0).(func(string) *models.PublicDashboard); ok {
-		r0 = rf(uid)
+		r0 = rf(accessToken)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.PublicDashboard)
@@ -313,7 +359,7 @@ func (_m *FakeDashboardStore) GetPublicDashboard(uid string) (*models.PublicDash
 
 	var r1 *models.Dashboard
 	if rf, ok := ret.Get(1).(func(string) *models.Dashboard); ok {
-		r1 = rf(uid)
+		r1 = rf(accessToken)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*models.Dashboard)
@@ -322,7 +368,7 @@ func (_m *FakeDashboardStore) GetPublicDashboard(uid string) (*models.PublicDash
 
 	var r2 error
 	if rf, ok := ret.Get(2).(func(string) error); ok {
-		r2 = rf(uid)
+		r2 = rf(accessToken)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -381,6 +427,29 @@ func (_m *FakeDashboardStore) HasEditPermissionInFolders(ctx context.Context, qu
 	return r0
 }
 
+// RotatePublicDashboardAccessToken provides a mock function with given fields: cmd
+func (_m *FakeDashboardStore) RotatePublicDashboardAccessToken(cmd models.RotatePublicDashboardAccessTokenCommand) (*models.PublicDashboard, error) {
+	ret := _m.Called(cmd)
+
+	var r0 *models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(models.RotatePublicDashboardAccessTokenCommand) *models.PublicDashboard); ok {
+		r0 = rf(cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.RotatePublicDashboardAccessTokenCommand) error); ok {
+		r1 = rf(cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveAlerts provides a mock function with given fields: ctx, dashID, alerts
 func (_m *FakeDashboardStore) SaveAlerts(ctx context.Context, dashID int64, alerts []*models.Alert) error {
 	ret := _m.Called(ctx, dashID, alerts)
@@ -395,13 +464,13 @@ func (_m *FakeDashboardStore) SaveAlerts(ctx context.Context, dashID int64, aler
 	return r0
 }
 
-// SaveDashboard provides a mock function with given fields: cmd
-func (_m *FakeDashboardStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dashboard, error) {
-	ret := _m.Called(cmd)
+// SaveDashboard provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) SaveDashboard(ctx context.Context, cmd models.SaveDashboardCommand) (*models.Dashboard, error) {
+	ret := _m.Called(ctx, cmd)
 
 	var r0 *models.Dashboard
-	if rf, ok := ret.Get(0).(func(models.SaveDashboardCommand) *models.Dashboard); ok {
-		r0 = rf(cmd)
+	if rf, ok := ret.Get(0).(func(context.Context, models.SaveDashboardCommand) *models.Dashboard); ok {
+		r0 = rf(ctx, cmd)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Dashboard)
@@ -409,8 +478,8 @@ func (_m *FakeDashboardStore) SaveDashboard(cmd models.SaveDashboardCommand) (*m
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.SaveDashboardCommand) error); ok {
-		r1 = rf(cmd)
+	if rf, ok := ret.Get(1).(func(context.Context, models.SaveDashboardCommand) error); ok {
+		r1 = rf(ctx, cmd)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -418,13 +487,13 @@ func (_m *FakeDashboardStore) SaveDashboard(cmd models.SaveDashboardCommand) (*m
 	return r0, r1
 }
 
-// SaveProvisionedDashboard provides a mock function with given fields: cmd, provisioning
-func (_m *FakeDashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error) {
-	ret := _m.Called(cmd, provisioning)
+// SaveProvisionedDashboard provides a mock function with given fields: ctx, cmd, provisioning
+func (_m *FakeDashboardStore) SaveProvisionedDashboard(ctx context.Context, cmd models.SaveDashboardCommand, provisioning *models.DashboardProvisioning) (*models.Dashboard, error) {
+	ret := _m.Called(ctx, cmd, provisioning)
 
 	var r0 *models.Dashboard
-	if rf, ok := ret.Get(0).(func(models.SaveDashboardCommand, *models.DashboardProvisioning) *models.Dashboard); ok {
-		r0 = rf(cmd, provisioning)
+	if rf, ok := ret.Get(0).(func(context.Context, models.SaveDashboardCommand, *models.DashboardProvisioning) *models.Dashboard); ok {
+		r0 = rf(ctx, cmd, provisioning)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Dashboard)
@@ -432,8 +501,8 @@ func (_m *FakeDashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardC
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.SaveDashboardCommand, *models.DashboardProvisioning) error); ok {
-		r1 = rf(cmd, provisioning)
+	if rf, ok := ret.Get(1).(func(context.Context, models.SaveDashboardCommand, *models.DashboardProvisioning) error); ok {
+		r1 = rf(ctx, cmd, provisioning)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -441,13 +510,13 @@ func (_m *FakeDashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardC
 	return r0, r1
 }
 
-// SavePublicDashboardConfig provides a mock function with given fields: cmd
-func (_m *FakeDashboardStore) SavePublicDashboardConfig(cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error) {
-	ret := _m.Called(cmd)
+// SavePublicDashboardConfig provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) SavePublicDashboardConfig(ctx context.Context, cmd models.SavePublicDashboardConfigCommand) (*models.PublicDashboardConfig, error) {
+	ret := _m.Called(ctx, cmd)
 
 	var r0 *models.PublicDashboardConfig
-	if rf, ok := ret.Get(0).(func(models.SavePublicDashboardConfigCommand) *models.PublicDashboardConfig); ok {
-		r0 = rf(cmd)
+	if rf, ok := ret.Get(0).(func(context.Context, models.SavePublicDashboardConfigCommand) *models.PublicDashboardConfig); ok {
+		r0 = rf(ctx, cmd)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.PublicDashboardConfig)
@@ -455,8 +524,8 @@ func (_m *FakeDashboardStore) SavePublicDashboardConfig(cmd models.SavePublicDas
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.SavePublicDashboardConfigCommand) error); ok {
-		r1 = rf(cmd)
+	if rf, ok := ret.Get(1).(func(context.Context, models.SavePublicDashboardConfigCommand) error); ok {
+		r1 = rf(ctx, cmd)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,16 @@
+package dashboardschema
+
+import (
+	"context"
+)
+
+// Service upgrades the JSON of stored dashboards to the latest schema version, so that
+// they no longer rely on the migrations run by the browser when they are loaded.
+type Service interface {
+	// Migrate upgrades a dashboard and saves it as a new version.
+	Migrate(ctx context.Context, cmd *MigrateDashboardCommand) (*MigrationResult, error)
+	// MigrateAll upgrades all the dashboards of an organization that are not on the latest schema version.
+	MigrateAll(ctx context.Context, cmd *MigrateAllDashboardsCommand) (*BulkMigrationResult, error)
+	// Report returns the dashboards of an organization that are not on the latest schema version.
+	Report(ctx context.Context, query *ReportQuery) ([]*OutdatedDashboard, error)
+}
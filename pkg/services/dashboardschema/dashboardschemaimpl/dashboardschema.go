@@ -0,0 +1,209 @@
+package dashboardschemaimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardschema"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// batchSize is the number of dashboards loaded at once by the bulk migration and the report.
+const batchSize = 100
+
+type Service struct {
+	store             store
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	log               log.Logger
+}
+
+func ProvideService(db db.DB, dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService) dashboardschema.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		log:               log.New("dashboardschema"),
+	}
+}
+
+func (s *Service) Migrate(ctx context.Context, cmd *dashboardschema.MigrateDashboardCommand) (*dashboardschema.MigrationResult, error) {
+	lookup, err := s.dataSourceLookup(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.migrate(ctx, cmd, lookup)
+}
+
+func (s *Service) MigrateAll(ctx context.Context, cmd *dashboardschema.MigrateAllDashboardsCommand) (*dashboardschema.BulkMigrationResult, error) {
+	lookup, err := s.dataSourceLookup(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	outdated, err := s.Report(ctx, &dashboardschema.ReportQuery{OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	bulk := &dashboardschema.BulkMigrationResult{Results: make([]*dashboardschema.MigrationResult, 0, len(outdated))}
+	for _, d := range outdated {
+		result, err := s.migrate(ctx, &dashboardschema.MigrateDashboardCommand{OrgID: cmd.OrgID, UID: d.UID, User: cmd.User, DryRun: cmd.DryRun}, lookup)
+		if err != nil {
+			result = &dashboardschema.MigrationResult{
+				UID:         d.UID,
+				Title:       d.Title,
+				Status:      dashboardschema.MigrationStatusFailed,
+				FromVersion: d.SchemaVersion,
+				ToVersion:   d.SchemaVersion,
+				Changes:     []string{},
+				Error:       err.Error(),
+			}
+			switch {
+			case errors.Is(err, dashboardschema.ErrSchemaVersionNotSupported):
+				result.Status = dashboardschema.MigrationStatusUnsupported
+			case errors.Is(err, dashboardschema.ErrDashboardProvisioned):
+				result.Status = dashboardschema.MigrationStatusProvisioned
+			default:
+				s.log.Warn("Failed to migrate dashboard schema", "orgId", cmd.OrgID, "uid", d.UID, "error", err)
+			}
+		}
+		// The bulk result would be huge with the dashboards of a dry run.
+		result.Dashboard = nil
+
+		switch result.Status {
+		case dashboardschema.MigrationStatusMigrated:
+			bulk.Migrated++
+		case dashboardschema.MigrationStatusFailed:
+			bulk.Failed++
+		default:
+			bulk.Skipped++
+		}
+		bulk.Results = append(bulk.Results, result)
+	}
+	return bulk, nil
+}
+
+func (s *Service) Report(ctx context.Context, query *dashboardschema.ReportQuery) ([]*dashboardschema.OutdatedDashboard, error) {
+	result := make([]*dashboardschema.OutdatedDashboard, 0)
+	var afterID int64
+	for {
+		rows, err := s.store.List(ctx, query.OrgID, afterID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			afterID = row.Id
+			if row.Data == nil {
+				continue
+			}
+			version := dashboardschema.SchemaVersion(row.Data)
+			if version >= dashboardschema.LatestVersion {
+				continue
+			}
+			result = append(result, &dashboardschema.OutdatedDashboard{
+				UID:           row.Uid,
+				Title:         row.Title,
+				FolderID:      row.FolderId,
+				SchemaVersion: version,
+				Provisioned:   row.ProvisioningId != 0,
+				Migratable:    version >= dashboardschema.MinSupportedVersion && row.ProvisioningId == 0,
+			})
+		}
+		if len(rows) < batchSize {
+			return result, nil
+		}
+	}
+}
+
+func (s *Service) migrate(ctx context.Context, cmd *dashboardschema.MigrateDashboardCommand, lookup dashboardschema.DataSourceLookup) (*dashboardschema.MigrationResult, error) {
+	query := models.GetDashboardQuery{OrgId: cmd.OrgID, Uid: cmd.UID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		return nil, err
+	}
+	dash := query.Result
+
+	fromVersion := dashboardschema.SchemaVersion(dash.Data)
+	result := &dashboardschema.MigrationResult{
+		UID:         dash.Uid,
+		Title:       dash.Title,
+		Status:      dashboardschema.MigrationStatusUpToDate,
+		FromVersion: fromVersion,
+		ToVersion:   fromVersion,
+		Changes:     []string{},
+	}
+	if fromVersion >= dashboardschema.LatestVersion {
+		return result, nil
+	}
+
+	changes, err := dashboardschema.Migrate(dash.Data, lookup)
+	if err != nil {
+		return nil, err
+	}
+	result.Status = dashboardschema.MigrationStatusMigrated
+	result.ToVersion = dashboardschema.LatestVersion
+	result.Changes = changes
+	if cmd.DryRun {
+		result.Dashboard = dash.Data
+		return result, nil
+	}
+
+	_, err = s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     cmd.OrgID,
+		User:      cmd.User,
+		Dashboard: dash,
+		Message:   fmt.Sprintf("Schema migrated from version %d to %d", fromVersion, dashboardschema.LatestVersion),
+	}, false)
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardCannotSaveProvisionedDashboard) {
+			return nil, dashboardschema.ErrDashboardProvisioned
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) dataSourceLookup(ctx context.Context, orgID int64) (dashboardschema.DataSourceLookup, error) {
+	query := models.GetDataSourcesQuery{OrgId: orgID}
+	if err := s.dataSourceService.GetDataSources(ctx, &query); err != nil {
+		return nil, err
+	}
+
+	lookup := &dataSourceLookup{
+		byName: make(map[string]*dashboardschema.DataSourceRef, len(query.Result)),
+		byUID:  make(map[string]*dashboardschema.DataSourceRef, len(query.Result)),
+	}
+	for _, ds := range query.Result {
+		ref := &dashboardschema.DataSourceRef{UID: ds.Uid, Type: ds.Type}
+		lookup.byName[ds.Name] = ref
+		lookup.byUID[ds.Uid] = ref
+		if ds.IsDefault {
+			lookup.defaultDS = ref
+		}
+	}
+	return lookup, nil
+}
+
+type dataSourceLookup struct {
+	byName    map[string]*dashboardschema.DataSourceRef
+	byUID     map[string]*dashboardschema.DataSourceRef
+	defaultDS *dashboardschema.DataSourceRef
+}
+
+func (l *dataSourceLookup) Get(nameOrUID string) *dashboardschema.DataSourceRef {
+	if ref, ok := l.byName[nameOrUID]; ok {
+		return ref
+	}
+	return l.byUID[nameOrUID]
+}
+
+func (l *dataSourceLookup) Default() *dashboardschema.DataSourceRef {
+	return l.defaultDS
+}
@@ -0,0 +1,42 @@
+package dashboardschemaimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// List returns the dashboards of the organization with an id greater than afterID, ordered by id.
+	List(ctx context.Context, orgID int64, afterID int64, limit int) ([]*dashboardRow, error)
+}
+
+type dashboardRow struct {
+	Id       int64
+	Uid      string
+	Title    string
+	FolderId int64
+	Data     *simplejson.Json
+	// ProvisioningId is 0 for dashboards that are not provisioned.
+	ProvisioningId int64
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) List(ctx context.Context, orgID int64, afterID int64, limit int) ([]*dashboardRow, error) {
+	result := make([]*dashboardRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT dashboard.id, dashboard.uid, dashboard.title, dashboard.folder_id, dashboard.data,
+				COALESCE(dashboard_provisioning.id, 0) AS provisioning_id
+			FROM dashboard
+			LEFT JOIN dashboard_provisioning ON dashboard_provisioning.dashboard_id = dashboard.id
+			WHERE dashboard.org_id = ? AND dashboard.is_folder = ? AND dashboard.id > ?
+			ORDER BY dashboard.id
+			LIMIT ?`, orgID, s.db.GetDialect().BooleanStr(false), afterID, limit).Find(&result)
+	})
+	return result, err
+}
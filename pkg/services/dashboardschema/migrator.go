@@ -0,0 +1,649 @@
+package dashboardschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	// LatestVersion is the schema version dashboards are migrated to, it must match
+	// the version of the frontend DashboardMigrator.
+	LatestVersion = 36
+	// MinSupportedVersion is the oldest schema version the backend can migrate, older
+	// dashboards have to be opened and saved in the browser.
+	MinSupportedVersion = 27
+)
+
+// DataSourceLookup resolves the data sources referenced by name in old dashboards.
+type DataSourceLookup interface {
+	// Get returns the data source with the name or uid, or nil if it does not exist.
+	Get(nameOrUID string) *DataSourceRef
+	// Default returns the default data source of the organization, or nil if there is none.
+	Default() *DataSourceRef
+}
+
+type DataSourceRef struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+func (r *DataSourceRef) toJSON() map[string]interface{} {
+	return map[string]interface{}{"uid": r.UID, "type": r.Type}
+}
+
+// SchemaVersion returns the schema version of the dashboard JSON.
+func SchemaVersion(data *simplejson.Json) int {
+	v, _ := toFloat(data.Get("schemaVersion").Interface())
+	return int(v)
+}
+
+// Migrate upgrades the dashboard JSON in place to LatestVersion and returns a description
+// of the changes. It is a port of the migrations of the frontend DashboardMigrator that
+// apply to versions from MinSupportedVersion on.
+func Migrate(data *simplejson.Json, datasources DataSourceLookup) ([]string, error) {
+	version := SchemaVersion(data)
+	if version >= LatestVersion {
+		return []string{}, nil
+	}
+	if version < MinSupportedVersion {
+		return nil, fmt.Errorf("%w: schema version %d", ErrSchemaVersionNotSupported, version)
+	}
+
+	m := &migrator{dashboard: data, datasources: datasources, changes: []string{}}
+	m.migrate(version)
+	data.Set("schemaVersion", LatestVersion)
+	m.changef("schema version upgraded from %d to %d", version, LatestVersion)
+	return m.changes, nil
+}
+
+type panelUpgrade func(panel *simplejson.Json)
+
+type migrator struct {
+	dashboard   *simplejson.Json
+	datasources DataSourceLookup
+	changes     []string
+}
+
+func (m *migrator) changef(format string, args ...interface{}) {
+	m.changes = append(m.changes, fmt.Sprintf(format, args...))
+}
+
+func (m *migrator) migrate(oldVersion int) {
+	var panelUpgrades []panelUpgrade
+
+	if oldVersion < 28 {
+		panelUpgrades = append(panelUpgrades, m.migrateSinglestat)
+		for _, variable := range m.variables() {
+			for _, key := range []string{"tags", "tagsQuery", "tagValuesQuery", "useTags"} {
+				variable.Del(key)
+			}
+		}
+	}
+
+	// The never refresh option of query variables was removed in version 29, dashboards
+	// saved through the API can still use it in later versions so it is always upgraded.
+	m.migrateQueryVariableRefresh()
+
+	if oldVersion < 30 {
+		panelUpgrades = append(panelUpgrades, upgradeValueMappingsForPanel, migrateTooltipOptions)
+	}
+
+	if oldVersion < 31 {
+		panelUpgrades = append(panelUpgrades, appendMergeAfterLabelsToFields)
+	}
+
+	// Replace datasource name with reference, uid and type
+	if oldVersion < 33 {
+		panelUpgrades = append(panelUpgrades, func(panel *simplejson.Json) {
+			panel.Set("datasource", m.datasourceRef(panel.Get("datasource").Interface(), true))
+			for _, target := range jsonArray(panel, "targets") {
+				if ref := m.datasourceRef(target.Get("datasource").Interface(), true); ref != nil {
+					target.Set("datasource", ref)
+				}
+			}
+		})
+	}
+
+	if oldVersion < 34 {
+		panelUpgrades = append(panelUpgrades, migrateCloudWatchQueries)
+		m.migrateCloudWatchAnnotationQueries()
+	}
+
+	if oldVersion < 35 {
+		panelUpgrades = append(panelUpgrades, ensureXAxisVisibility)
+	}
+
+	if oldVersion < 36 {
+		for _, annotation := range jsonArray(m.dashboard.Get("annotations"), "list") {
+			annotation.Set("datasource", m.datasourceRef(annotation.Get("datasource").Interface(), false))
+		}
+
+		if defaultDS := m.datasources.Default(); defaultDS != nil {
+			for _, variable := range m.variables() {
+				if variable.Get("type").MustString() == "query" && isNull(variable, "datasource") {
+					variable.Set("datasource", defaultDS.toJSON())
+				}
+			}
+
+			panelUpgrades = append(panelUpgrades, func(panel *simplejson.Json) {
+				targets := jsonArray(panel, "targets")
+				panelDataSourceWasDefault := false
+				if isNull(panel, "datasource") && len(targets) > 0 {
+					panel.Set("datasource", defaultDS.toJSON())
+					panelDataSourceWasDefault = true
+				}
+				for _, target := range targets {
+					if panelDataSourceWasDefault && !isNull(target, "datasource") {
+						// The query level data source is the source of truth when the default data source changed.
+						panel.Set("datasource", target.Get("datasource").Interface())
+					}
+					if _, ok := target.CheckGet("datasource"); ok && isNull(target, "datasource") {
+						target.Set("datasource", defaultDS.toJSON())
+					}
+				}
+			})
+		}
+	}
+
+	for _, panel := range jsonArray(m.dashboard, "panels") {
+		for _, upgrade := range panelUpgrades {
+			upgrade(panel)
+		}
+		for _, rowPanel := range jsonArray(panel, "panels") {
+			for _, upgrade := range panelUpgrades {
+				upgrade(rowPanel)
+			}
+		}
+	}
+}
+
+// migrateQueryVariableRefresh refreshes the query variables on dashboard load instead of
+// never and drops their stored options, which are loaded from the data source.
+func (m *migrator) migrateQueryVariableRefresh() {
+	for _, variable := range m.variables() {
+		if variable.Get("type").MustString() != "query" {
+			continue
+		}
+		if refresh, _ := toFloat(variable.Get("refresh").Interface()); refresh != 1 && refresh != 2 {
+			variable.Set("refresh", 1)
+			m.changef("variable %s: refresh changed from never to on dashboard load", variable.Get("name").MustString())
+		}
+		if len(variable.Get("options").MustArray()) > 0 {
+			variable.Set("options", []interface{}{})
+		}
+	}
+}
+
+func (m *migrator) variables() []*simplejson.Json {
+	return jsonArray(m.dashboard.Get("templating"), "list")
+}
+
+// datasourceRef converts a data source name to a reference. Data sources that do not
+// exist are referenced by uid, like the frontend does.
+func (m *migrator) datasourceRef(nameOrRef interface{}, returnDefaultAsNull bool) interface{} {
+	if returnDefaultAsNull && (nameOrRef == nil || nameOrRef == "default") {
+		return nil
+	}
+	if ref, ok := nameOrRef.(map[string]interface{}); ok {
+		return ref
+	}
+
+	name, _ := nameOrRef.(string)
+	var ds *DataSourceRef
+	if name == "" || name == "default" {
+		ds = m.datasources.Default()
+	} else {
+		ds = m.datasources.Get(name)
+	}
+	if ds == nil {
+		return map[string]interface{}{"uid": nameOrRef}
+	}
+	return ds.toJSON()
+}
+
+// migrateSinglestat replaces the removed angular singlestat panel with a gauge, if the
+// gauge was shown, or a stat panel.
+func (m *migrator) migrateSinglestat(panel *simplejson.Json) {
+	panelType := panel.Get("type").MustString()
+	if panelType != "singlestat" && panelType != "grafana-singlestat-panel" {
+		return
+	}
+	old := panel
+
+	newType := "stat"
+	if old.GetPath("gauge", "show").MustBool() {
+		newType = "gauge"
+	}
+	m.changef("panel %d: %s panel replaced with %s panel", panel.Get("id").MustInt64(), panelType, newType)
+
+	calc := "mean"
+	switch valueName := old.Get("valueName").MustString(); valueName {
+	case "avg", "":
+	case "current":
+		calc = "lastNotNull"
+	case "total":
+		calc = "sum"
+	case "min", "max", "first", "last", "count", "range", "delta", "diff", "lastNotNull", "firstNotNull":
+		calc = valueName
+	}
+	reduceOptions := map[string]interface{}{"calcs": []interface{}{calc}}
+	if column := old.Get("tableColumn").MustString(); column != "" {
+		reduceOptions["fields"] = "/^" + column + "$/"
+	}
+	options := map[string]interface{}{
+		"reduceOptions": reduceOptions,
+		"orientation":   "horizontal",
+	}
+
+	defaults := map[string]interface{}{}
+	if format := old.Get("format").MustString(); format != "" {
+		defaults["unit"] = format
+	}
+	if nullPointMode := old.Get("nullPointMode").MustString(); nullPointMode != "" {
+		defaults["nullValueMode"] = nullPointMode
+	}
+	if nullText := old.Get("nullText").MustString(); nullText != "" {
+		defaults["noValue"] = nullText
+	}
+	if decimals, ok := toFloat(old.Get("decimals").Interface()); ok {
+		defaults["decimals"] = decimals
+	}
+
+	if levels, colors := old.Get("thresholds").MustString(), old.Get("colors").MustArray(); levels != "" && len(colors) > 0 {
+		values := strings.Split(levels, ",")
+		steps := make([]interface{}, 0, len(colors))
+		for i, color := range colors {
+			step := map[string]interface{}{"color": color, "value": nil}
+			if i > 0 && i-1 < len(values) {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(values[i-1]), 64); err == nil {
+					step["value"] = v
+				}
+			}
+			steps = append(steps, step)
+		}
+		defaults["thresholds"] = map[string]interface{}{"mode": "absolute", "steps": steps}
+	}
+
+	// The mappings are converted to the current format by the version 30 migration.
+	var mappings []interface{}
+	mappingType, _ := toFloat(old.Get("mappingType").Interface())
+	valueMaps, rangeMaps := old.Get("valueMaps").MustArray(), old.Get("rangeMaps").MustArray()
+	if mappingType == 0 && len(valueMaps) > 0 {
+		mappingType = 1
+	} else if mappingType == 0 && len(rangeMaps) > 0 {
+		mappingType = 2
+	}
+	source := valueMaps
+	if mappingType == 2 {
+		source = rangeMaps
+	}
+	for i, mapping := range source {
+		if mapping, ok := mapping.(map[string]interface{}); ok {
+			mapping["id"] = i
+			mapping["type"] = mappingType
+			mappings = append(mappings, mapping)
+		}
+	}
+	if len(mappings) > 0 {
+		defaults["mappings"] = mappings
+	}
+
+	if newType == "gauge" {
+		gauge := old.Get("gauge")
+		for from, to := range map[string]string{"minValue": "min", "maxValue": "max"} {
+			if v, ok := toFloat(gauge.Get(from).Interface()); ok {
+				defaults[to] = v
+			}
+		}
+		for from, to := range map[string]string{"thresholdMarkers": "showThresholdMarkers", "thresholdLabels": "showThresholdLabels"} {
+			if v, ok := gauge.Get(from).Interface().(bool); ok {
+				options[to] = v
+			}
+		}
+	} else {
+		options["graphMode"] = "none"
+		if old.GetPath("sparkline", "show").MustBool() {
+			options["graphMode"] = "area"
+		}
+		switch {
+		case old.Get("colorBackground").MustBool():
+			options["colorMode"] = "background"
+		case old.Get("colorValue").MustBool():
+			options["colorMode"] = "value"
+		default:
+			options["colorMode"] = "none"
+			if lineColor := old.GetPath("sparkline", "lineColor").MustString(); lineColor != "" && options["graphMode"] == "area" {
+				defaults["color"] = map[string]interface{}{"mode": "fixed", "fixedColor": lineColor}
+			}
+		}
+		if old.Get("valueName").MustString() == "name" {
+			options["textMode"] = "name"
+		}
+	}
+
+	migrated := map[string]interface{}{}
+	for key, value := range panel.MustMap() {
+		if panelPropsToKeep[key] {
+			migrated[key] = value
+		}
+	}
+	migrated["type"] = newType
+	migrated["options"] = options
+	migrated["fieldConfig"] = map[string]interface{}{"defaults": defaults, "overrides": []interface{}{}}
+	delete(migrated, "pluginVersion")
+
+	for key := range panel.MustMap() {
+		panel.Del(key)
+	}
+	for key, value := range migrated {
+		panel.Set(key, value)
+	}
+}
+
+// panelPropsToKeep are the panel properties that are not specific to a panel plugin.
+var panelPropsToKeep = map[string]bool{
+	"id": true, "gridPos": true, "type": true, "title": true, "scopedVars": true, "repeat": true,
+	"repeatIteration": true, "repeatPanelId": true, "repeatDirection": true, "repeatedByRow": true,
+	"minSpan": true, "collapsed": true, "panels": true, "targets": true, "datasource": true,
+	"timeFrom": true, "timeShift": true, "hideTimeOverride": true, "description": true, "links": true,
+	"cacheTimeout": true, "transparent": true, "pluginVersion": true, "transformations": true,
+	"fieldConfig": true, "maxDataPoints": true, "interval": true, "libraryPanel": true, "key": true,
+}
+
+func upgradeValueMappingsForPanel(panel *simplejson.Json) {
+	fieldConfig, ok := panel.CheckGet("fieldConfig")
+	if !ok {
+		return
+	}
+	defaults := fieldConfig.Get("defaults")
+	if mappings, ok := defaults.CheckGet("mappings"); ok && mappings.Interface() != nil {
+		defaults.Set("mappings", upgradeValueMappings(mappings.MustArray(), defaults.GetPath("thresholds", "steps").MustArray()))
+	}
+	for _, override := range jsonArray(fieldConfig, "overrides") {
+		for _, prop := range jsonArray(override, "properties") {
+			if prop.Get("id").MustString() == "mappings" {
+				prop.Set("value", upgradeValueMappings(prop.Get("value").MustArray(), nil))
+			}
+		}
+	}
+}
+
+func upgradeValueMappings(oldMappings []interface{}, thresholdSteps []interface{}) []interface{} {
+	valueMaps := map[string]interface{}{}
+	newMappings := []interface{}{}
+
+	for _, o := range oldMappings {
+		old := simplejson.NewFromAny(o)
+		// Mappings that already have the current format
+		if _, ok := old.Get("type").Interface().(string); ok {
+			if _, ok := old.CheckGet("options"); ok {
+				if old.Get("type").MustString() == "value" {
+					for k, v := range old.Get("options").MustMap() {
+						valueMaps[k] = v
+					}
+				} else {
+					newMappings = append(newMappings, o)
+				}
+				continue
+			}
+		}
+
+		// Use the color we would have picked from the thresholds
+		result := map[string]interface{}{"text": old.Get("text").Interface()}
+		if numeric, err := strconv.ParseFloat(fmt.Sprint(old.Get("text").Interface()), 64); err == nil {
+			if color := activeThresholdColor(numeric, thresholdSteps); color != "" {
+				result["color"] = color
+			}
+		}
+
+		mappingType, _ := toFloat(old.Get("type").Interface())
+		switch mappingType {
+		case 1:
+			value, ok := old.CheckGet("value")
+			if !ok || value.Interface() == nil {
+				continue
+			}
+			if value.Interface() == "null" {
+				newMappings = append(newMappings, map[string]interface{}{
+					"type":    "special",
+					"options": map[string]interface{}{"match": "null", "result": result},
+				})
+			} else {
+				valueMaps[fmt.Sprint(value.Interface())] = result
+			}
+		case 2:
+			from, _ := toFloat(old.Get("from").Interface())
+			to, _ := toFloat(old.Get("to").Interface())
+			newMappings = append(newMappings, map[string]interface{}{
+				"type":    "range",
+				"options": map[string]interface{}{"from": from, "to": to, "result": result},
+			})
+		}
+	}
+
+	if len(valueMaps) > 0 {
+		newMappings = append([]interface{}{map[string]interface{}{"type": "value", "options": valueMaps}}, newMappings...)
+	}
+	return newMappings
+}
+
+func activeThresholdColor(value float64, steps []interface{}) string {
+	color := ""
+	for _, s := range steps {
+		step := simplejson.NewFromAny(s)
+		stepValue, ok := toFloat(step.Get("value").Interface())
+		if !ok {
+			stepValue = math.Inf(-1)
+		}
+		if value < stepValue {
+			break
+		}
+		color = step.Get("color").MustString()
+	}
+	return color
+}
+
+func migrateTooltipOptions(panel *simplejson.Json) {
+	panelType := panel.Get("type").MustString()
+	if panelType != "timeseries" && panelType != "xychart" {
+		return
+	}
+	options := panel.Get("options")
+	if tooltip, ok := options.CheckGet("tooltipOptions"); ok {
+		options.Set("tooltip", tooltip.Interface())
+		options.Del("tooltipOptions")
+	}
+}
+
+// appendMergeAfterLabelsToFields keeps the output of the labels to fields transformation,
+// which merged its results before version 31.
+func appendMergeAfterLabelsToFields(panel *simplejson.Json) {
+	transformations := panel.Get("transformations").MustArray()
+	if len(transformations) == 0 {
+		return
+	}
+	result := make([]interface{}, 0, len(transformations)+1)
+	for _, t := range transformations {
+		result = append(result, t)
+		if simplejson.NewFromAny(t).Get("id").MustString() == "labelsToFields" {
+			result = append(result, map[string]interface{}{"id": "merge", "options": map[string]interface{}{}})
+		}
+	}
+	panel.Set("transformations", result)
+}
+
+func isCloudWatchQuery(target *simplejson.Json) bool {
+	for _, key := range []string{"dimensions", "namespace", "region", "metricName"} {
+		if _, ok := target.CheckGet(key); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateCloudWatchQueries splits the CloudWatch queries using more than one statistic
+// into one query per statistic, the new queries are added at the end of the panel queries.
+func migrateCloudWatchQueries(panel *simplejson.Json) {
+	targets := panel.Get("targets").MustArray()
+	var newTargets []interface{}
+	for _, t := range targets {
+		target := simplejson.NewFromAny(t)
+		if !isCloudWatchQuery(target) {
+			continue
+		}
+
+		if _, ok := target.CheckGet("metricQueryType"); !ok {
+			target.Set("metricQueryType", 0)
+		}
+		if _, ok := target.CheckGet("metricEditorMode"); !ok {
+			if queryType, _ := toFloat(target.Get("metricQueryType").Interface()); queryType == 1 || target.Get("expression").MustString() != "" {
+				target.Set("metricEditorMode", 1)
+			} else {
+				target.Set("metricEditorMode", 0)
+			}
+		}
+
+		if _, ok := target.CheckGet("statistics"); !ok {
+			continue
+		}
+		statistics := target.Get("statistics").MustArray()
+		target.Del("statistics")
+		if len(statistics) == 0 {
+			continue
+		}
+		target.Set("statistic", statistics[0])
+		for _, stat := range statistics[1:] {
+			newTarget := map[string]interface{}{}
+			for k, v := range target.MustMap() {
+				newTarget[k] = v
+			}
+			newTarget["statistic"] = stat
+			newTarget["refId"] = nextRefID(append(targets, newTargets...))
+			newTargets = append(newTargets, newTarget)
+		}
+	}
+	if len(newTargets) > 0 {
+		panel.Set("targets", append(targets, newTargets...))
+	}
+}
+
+func (m *migrator) migrateCloudWatchAnnotationQueries() {
+	annotations := m.dashboard.Get("annotations")
+	list := annotations.Get("list").MustArray()
+	var newAnnotations []interface{}
+	for _, a := range list {
+		annotation := simplejson.NewFromAny(a)
+		isLegacy := true
+		for _, key := range []string{"dimensions", "namespace", "region", "prefixMatching", "statistics"} {
+			if _, ok := annotation.CheckGet(key); !ok {
+				isLegacy = false
+			}
+		}
+		if !isLegacy {
+			continue
+		}
+
+		statistics := annotation.Get("statistics").MustArray()
+		if len(statistics) == 0 {
+			continue
+		}
+		name := annotation.Get("name").MustString()
+		for _, stat := range statistics[1:] {
+			newAnnotation := map[string]interface{}{}
+			for k, v := range annotation.MustMap() {
+				if k != "statistics" {
+					newAnnotation[k] = v
+				}
+			}
+			newAnnotation["statistic"] = stat
+			newAnnotation["name"] = fmt.Sprintf("%s - %v", name, stat)
+			newAnnotations = append(newAnnotations, newAnnotation)
+		}
+		annotation.Set("statistic", statistics[0])
+		if len(statistics) > 1 {
+			annotation.Set("name", fmt.Sprintf("%s - %v", name, statistics[0]))
+		}
+		annotation.Del("statistics")
+	}
+	if len(newAnnotations) > 0 {
+		annotations.Set("list", append(list, newAnnotations...))
+	}
+}
+
+// ensureXAxisVisibility keeps the x-axis of time series panels whose axes are all hidden visible.
+func ensureXAxisVisibility(panel *simplejson.Json) {
+	if panel.Get("type").MustString() != "timeseries" {
+		return
+	}
+	fieldConfig := panel.Get("fieldConfig")
+	if fieldConfig.GetPath("defaults", "custom", "axisPlacement").MustString() != "hidden" {
+		return
+	}
+	overrides := fieldConfig.Get("overrides").MustArray()
+	overrides = append(overrides, map[string]interface{}{
+		"matcher":    map[string]interface{}{"id": "byType", "options": "time"},
+		"properties": []interface{}{map[string]interface{}{"id": "custom.axisPlacement", "value": "auto"}},
+	})
+	fieldConfig.Set("overrides", overrides)
+}
+
+// nextRefID returns the first ref id, A to Z then AA to ZZ, that is not used by the queries.
+func nextRefID(queries []interface{}) string {
+	used := map[string]bool{}
+	for _, q := range queries {
+		used[simplejson.NewFromAny(q).Get("refId").MustString()] = true
+	}
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	for _, l := range letters {
+		if !used[string(l)] {
+			return string(l)
+		}
+	}
+	for _, first := range letters {
+		for _, second := range letters {
+			if refID := string(first) + string(second); !used[refID] {
+				return refID
+			}
+		}
+	}
+	return ""
+}
+
+// jsonArray returns the objects of an array, changes to them are applied to the array.
+func jsonArray(j *simplejson.Json, key string) []*simplejson.Json {
+	items := j.Get(key).MustArray()
+	result := make([]*simplejson.Json, 0, len(items))
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); ok {
+			result = append(result, simplejson.NewFromAny(item))
+		}
+	}
+	return result
+}
+
+func isNull(j *simplejson.Json, key string) bool {
+	return j.Get(key).Interface() == nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,158 @@
+package dashboardschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type fakeDataSourceLookup struct {
+	datasources map[string]*DataSourceRef
+	defaultDS   *DataSourceRef
+}
+
+func (l *fakeDataSourceLookup) Get(nameOrUID string) *DataSourceRef {
+	return l.datasources[nameOrUID]
+}
+
+func (l *fakeDataSourceLookup) Default() *DataSourceRef {
+	return l.defaultDS
+}
+
+var testLookup = &fakeDataSourceLookup{
+	datasources: map[string]*DataSourceRef{"Prometheus": {UID: "prom-uid", Type: "prometheus"}},
+	defaultDS:   &DataSourceRef{UID: "default-uid", Type: "loki"},
+}
+
+func parseDashboard(t *testing.T, body string) *simplejson.Json {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(body))
+	require.NoError(t, err)
+	return data
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("dashboards on the latest version are not changed", func(t *testing.T) {
+		data := parseDashboard(t, `{"schemaVersion": 36, "panels": [{"type": "singlestat"}]}`)
+		changes, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+		require.Equal(t, "singlestat", data.Get("panels").GetIndex(0).Get("type").MustString())
+	})
+
+	t.Run("dashboards older than the minimum supported version are rejected", func(t *testing.T) {
+		_, err := Migrate(parseDashboard(t, `{"schemaVersion": 16}`), testLookup)
+		require.ErrorIs(t, err, ErrSchemaVersionNotSupported)
+	})
+
+	t.Run("singlestat is replaced with stat", func(t *testing.T) {
+		data := parseDashboard(t, `{
+			"schemaVersion": 27,
+			"panels": [{
+				"id": 2, "type": "singlestat", "title": "Up", "gridPos": {"x": 0, "y": 0, "w": 6, "h": 3},
+				"valueName": "current", "format": "percent", "decimals": 1, "thresholds": "50,80",
+				"colors": ["green", "orange", "red"], "colorBackground": true, "sparkline": {"show": true},
+				"valueMaps": [{"value": "1", "text": "UP"}, {"value": "null", "text": "N/A"}]
+			}]
+		}`)
+		changes, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+		require.Contains(t, changes, "panel 2: singlestat panel replaced with stat panel")
+		require.Equal(t, LatestVersion, SchemaVersion(data))
+
+		panel := data.Get("panels").GetIndex(0)
+		require.Equal(t, "stat", panel.Get("type").MustString())
+		require.Equal(t, "Up", panel.Get("title").MustString())
+		require.Nil(t, panel.Get("valueName").Interface())
+		require.Equal(t, []interface{}{"lastNotNull"}, panel.GetPath("options", "reduceOptions", "calcs").MustArray())
+		require.Equal(t, "background", panel.GetPath("options", "colorMode").MustString())
+		require.Equal(t, "area", panel.GetPath("options", "graphMode").MustString())
+
+		defaults := panel.GetPath("fieldConfig", "defaults")
+		require.Equal(t, "percent", defaults.Get("unit").MustString())
+		require.Equal(t, 80.0, defaults.Get("thresholds").Get("steps").GetIndex(2).Get("value").MustFloat64())
+
+		mappings := defaults.Get("mappings")
+		require.Equal(t, "value", mappings.GetIndex(0).Get("type").MustString())
+		require.Equal(t, "UP", mappings.GetIndex(0).GetPath("options", "1", "text").MustString())
+		require.Equal(t, "special", mappings.GetIndex(1).Get("type").MustString())
+	})
+
+	t.Run("singlestat with a gauge is replaced with gauge", func(t *testing.T) {
+		data := parseDashboard(t, `{"schemaVersion": 27, "panels": [{"id": 1, "type": "singlestat", "gauge": {"show": true, "minValue": 0, "maxValue": 100}}]}`)
+		_, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+		panel := data.Get("panels").GetIndex(0)
+		require.Equal(t, "gauge", panel.Get("type").MustString())
+		require.Equal(t, 100.0, panel.GetPath("fieldConfig", "defaults", "max").MustFloat64())
+	})
+
+	t.Run("data source names are replaced with references", func(t *testing.T) {
+		data := parseDashboard(t, `{
+			"schemaVersion": 32,
+			"annotations": {"list": [{"name": "Deploys", "datasource": "Prometheus"}]},
+			"templating": {"list": [{"type": "query", "datasource": null, "refresh": 0, "options": [{"text": "a"}]}]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "datasource": "Prometheus", "targets": [{"refId": "A", "datasource": "missing"}]},
+				{"id": 2, "type": "row", "panels": [{"id": 3, "type": "timeseries", "datasource": null, "targets": [{"refId": "A", "datasource": null}]}]}
+			]
+		}`)
+		_, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+
+		panels := data.Get("panels")
+		require.Equal(t, map[string]interface{}{"uid": "prom-uid", "type": "prometheus"}, panels.GetIndex(0).Get("datasource").Interface())
+		require.Equal(t, map[string]interface{}{"uid": "missing"}, panels.GetIndex(0).Get("targets").GetIndex(0).Get("datasource").Interface())
+
+		rowPanel := panels.GetIndex(1).Get("panels").GetIndex(0)
+		require.Equal(t, "default-uid", rowPanel.GetPath("datasource", "uid").MustString())
+		require.Equal(t, "default-uid", rowPanel.Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+		require.Equal(t, "prom-uid", data.Get("annotations").Get("list").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+		variable := data.Get("templating").Get("list").GetIndex(0)
+		require.Equal(t, "default-uid", variable.GetPath("datasource", "uid").MustString())
+		require.Equal(t, 1, variable.Get("refresh").MustInt())
+		require.Empty(t, variable.Get("options").MustArray())
+	})
+
+	t.Run("cloudwatch queries with several statistics are split", func(t *testing.T) {
+		data := parseDashboard(t, `{
+			"schemaVersion": 33,
+			"panels": [{"id": 1, "type": "timeseries", "targets": [
+				{"refId": "A", "dimensions": {}, "namespace": "AWS/EC2", "region": "us-east-1", "metricName": "CPUUtilization", "statistics": ["Average", "Maximum"]}
+			]}]
+		}`)
+		_, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+
+		targets := data.Get("panels").GetIndex(0).Get("targets")
+		require.Len(t, targets.MustArray(), 2)
+		require.Equal(t, "Average", targets.GetIndex(0).Get("statistic").MustString())
+		require.Equal(t, "B", targets.GetIndex(1).Get("refId").MustString())
+		require.Equal(t, "Maximum", targets.GetIndex(1).Get("statistic").MustString())
+		require.Nil(t, targets.GetIndex(1).Get("statistics").Interface())
+	})
+
+	t.Run("panel options", func(t *testing.T) {
+		data := parseDashboard(t, `{
+			"schemaVersion": 29,
+			"panels": [{
+				"id": 1, "type": "timeseries",
+				"options": {"tooltipOptions": {"mode": "single"}},
+				"fieldConfig": {"defaults": {"custom": {"axisPlacement": "hidden"}}, "overrides": []},
+				"transformations": [{"id": "labelsToFields"}]
+			}]
+		}`)
+		_, err := Migrate(data, testLookup)
+		require.NoError(t, err)
+
+		panel := data.Get("panels").GetIndex(0)
+		require.Equal(t, "single", panel.GetPath("options", "tooltip", "mode").MustString())
+		require.Nil(t, panel.GetPath("options", "tooltipOptions").Interface())
+		require.Equal(t, "merge", panel.Get("transformations").GetIndex(1).Get("id").MustString())
+		require.Equal(t, "byType", panel.GetPath("fieldConfig", "overrides").GetIndex(0).GetPath("matcher", "id").MustString())
+	})
+}
@@ -0,0 +1,87 @@
+package dashboardschema
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/jsonschema"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrSchemaVersionNotSupported = errors.New("dashboard schema version is too old to be migrated by the server, open and save the dashboard in the browser")
+	ErrDashboardProvisioned      = errors.New("provisioned dashboards cannot be migrated, update the provisioning source instead")
+	ErrInvalidDashboard          = errors.New("dashboard JSON doesn't match the dashboard schema")
+)
+
+// ValidationResult lists the values of a dashboard that don't match the schema, which
+// prevent it from being saved, and the deprecated options, which don't.
+type ValidationResult struct {
+	Valid    bool                    `json:"valid"`
+	Errors   []jsonschema.FieldError `json:"errors"`
+	Warnings []jsonschema.FieldError `json:"warnings"`
+}
+
+type MigrationStatus string
+
+const (
+	MigrationStatusMigrated    MigrationStatus = "migrated"
+	MigrationStatusUpToDate    MigrationStatus = "upToDate"
+	MigrationStatusUnsupported MigrationStatus = "unsupported"
+	MigrationStatusProvisioned MigrationStatus = "provisioned"
+	MigrationStatusFailed      MigrationStatus = "failed"
+)
+
+type MigrationResult struct {
+	UID         string          `json:"uid"`
+	Title       string          `json:"title"`
+	Status      MigrationStatus `json:"status"`
+	FromVersion int             `json:"fromVersion"`
+	ToVersion   int             `json:"toVersion"`
+	Changes     []string        `json:"changes"`
+	Error       string          `json:"error,omitempty"`
+	// Dashboard is the migrated dashboard JSON, it is only returned for dry runs.
+	Dashboard *simplejson.Json `json:"dashboard,omitempty"`
+}
+
+type BulkMigrationResult struct {
+	Migrated int                `json:"migrated"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Results  []*MigrationResult `json:"results"`
+}
+
+// OutdatedDashboard is a dashboard that is not on the latest schema version.
+type OutdatedDashboard struct {
+	UID           string `json:"uid"`
+	Title         string `json:"title"`
+	FolderID      int64  `json:"folderId"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Provisioned   bool   `json:"provisioned"`
+	// Migratable is false for dashboards that are too old to be migrated by the server or that are provisioned.
+	Migratable bool `json:"migratable"`
+}
+
+// ----------------------
+// COMMANDS
+
+type MigrateDashboardCommand struct {
+	OrgID int64                `json:"-"`
+	UID   string               `json:"-"`
+	User  *models.SignedInUser `json:"-"`
+	// DryRun returns the migrated dashboard without saving it.
+	DryRun bool `json:"dryRun"`
+}
+
+type MigrateAllDashboardsCommand struct {
+	OrgID  int64                `json:"-"`
+	User   *models.SignedInUser `json:"-"`
+	DryRun bool                 `json:"dryRun"`
+}
+
+// ---------------------
+// QUERIES
+
+type ReportQuery struct {
+	OrgID int64
+}
@@ -0,0 +1,204 @@
+package dashboardschema
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/jsonschema"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// deprecatedPanelTypes are the panels replaced by core panels, with their replacement.
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// deprecatedDashboardOptions are the options of the dashboard that are ignored, with
+// what replaced them.
+var deprecatedDashboardOptions = map[string]string{
+	"rows":            "row panels in panels",
+	"sharedCrosshair": "graphTooltip",
+	"hideControls":    "the hide option of the variables",
+}
+
+var dashboardSchema = newDashboardSchema()
+
+func newDashboardSchema() *jsonschema.Schema {
+	str := &jsonschema.Schema{Type: jsonschema.Types{"string"}}
+	nullableStr := &jsonschema.Schema{Type: jsonschema.Types{"string", "null"}}
+	boolean := &jsonschema.Schema{Type: jsonschema.Types{"boolean"}}
+	integer := &jsonschema.Schema{Type: jsonschema.Types{"integer"}}
+	nullableInt := &jsonschema.Schema{Type: jsonschema.Types{"integer", "null"}}
+	objects := &jsonschema.Schema{Type: jsonschema.Types{"array"}, Items: &jsonschema.Schema{Type: jsonschema.Types{"object"}}}
+	// data sources are referenced by uid and type, or by name before schema version 33
+	datasource := &jsonschema.Schema{
+		Type: jsonschema.Types{"object", "string", "null"},
+		Properties: map[string]*jsonschema.Schema{
+			"uid":  nullableStr,
+			"type": nullableStr,
+		},
+	}
+	zero := float64(0)
+	gridWidth := float64(24)
+
+	panel := func(panels *jsonschema.Schema) *jsonschema.Schema {
+		return &jsonschema.Schema{
+			Type:     jsonschema.Types{"object"},
+			Required: []string{"type"},
+			Properties: map[string]*jsonschema.Schema{
+				"id":          integer,
+				"type":        str,
+				"title":       str,
+				"description": str,
+				"datasource":  datasource,
+				"gridPos": {
+					Type:     jsonschema.Types{"object"},
+					Required: []string{"h", "w", "x", "y"},
+					Properties: map[string]*jsonschema.Schema{
+						"h": {Type: jsonschema.Types{"number"}, Minimum: &zero},
+						"w": {Type: jsonschema.Types{"number"}, Minimum: &zero, Maximum: &gridWidth},
+						"x": {Type: jsonschema.Types{"number"}, Minimum: &zero, Maximum: &gridWidth},
+						"y": {Type: jsonschema.Types{"number"}, Minimum: &zero},
+					},
+				},
+				"targets": {
+					Type: jsonschema.Types{"array"},
+					Items: &jsonschema.Schema{
+						Type: jsonschema.Types{"object"},
+						Properties: map[string]*jsonschema.Schema{
+							"refId":      str,
+							"hide":       boolean,
+							"datasource": datasource,
+						},
+					},
+				},
+				"transparent": boolean,
+				"repeat":      nullableStr,
+				"links":       objects,
+				"fieldConfig": {Type: jsonschema.Types{"object"}},
+				"options":     {Type: jsonschema.Types{"object"}},
+				"panels":      panels,
+			},
+		}
+	}
+
+	variableTypes := []interface{}{"query", "adhoc", "constant", "datasource", "interval", "textbox", "custom", "system"}
+	return &jsonschema.Schema{
+		Type:     jsonschema.Types{"object"},
+		Required: []string{"title"},
+		Properties: map[string]*jsonschema.Schema{
+			"id":            nullableInt,
+			"uid":           {Type: jsonschema.Types{"string", "null"}, MaxLength: intPtr(40)},
+			"title":         str,
+			"description":   str,
+			"tags":          {Type: jsonschema.Types{"array"}, Items: str},
+			"editable":      boolean,
+			"graphTooltip":  {Type: jsonschema.Types{"integer"}, Enum: []interface{}{0, 1, 2}},
+			"schemaVersion": integer,
+			"version":       nullableInt,
+			"timezone":      str,
+			"refresh":       {Type: jsonschema.Types{"string", "boolean"}},
+			"time": {
+				Type: jsonschema.Types{"object"},
+				Properties: map[string]*jsonschema.Schema{
+					"from": str,
+					"to":   str,
+				},
+			},
+			"links": objects,
+			"panels": {
+				Type: jsonschema.Types{"array"},
+				// the panels of collapsed rows
+				Items: panel(&jsonschema.Schema{Type: jsonschema.Types{"array"}, Items: panel(nil)}),
+			},
+			"templating": {
+				Type: jsonschema.Types{"object"},
+				Properties: map[string]*jsonschema.Schema{
+					"list": {
+						Type: jsonschema.Types{"array"},
+						Items: &jsonschema.Schema{
+							Type:     jsonschema.Types{"object"},
+							Required: []string{"name", "type"},
+							Properties: map[string]*jsonschema.Schema{
+								"name":       {Type: jsonschema.Types{"string"}, MinLength: intPtr(1)},
+								"type":       {Type: jsonschema.Types{"string"}, Enum: variableTypes},
+								"label":      nullableStr,
+								"datasource": datasource,
+							},
+						},
+					},
+				},
+			},
+			"annotations": {
+				Type: jsonschema.Types{"object"},
+				Properties: map[string]*jsonschema.Schema{
+					"list": objects,
+				},
+			},
+		},
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// Validate checks the JSON of a dashboard against the schema of dashboards and their
+// panels. The values not matching the schema are returned as errors, the deprecated
+// options as warnings.
+func Validate(data *simplejson.Json) (*ValidationResult, error) {
+	errs, err := dashboardSchema.Validate("", data.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidationResult{
+		Valid:    len(errs) == 0,
+		Errors:   errs,
+		Warnings: []jsonschema.FieldError{},
+	}
+	result.addDeprecationWarnings(data)
+	return result, nil
+}
+
+func (r *ValidationResult) warnf(field, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, jsonschema.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationResult) addDeprecationWarnings(data *simplejson.Json) {
+	for _, option := range []string{"rows", "sharedCrosshair", "hideControls"} {
+		if _, ok := data.CheckGet(option); ok {
+			r.warnf(option, "is deprecated, use %s instead", deprecatedDashboardOptions[option])
+		}
+	}
+
+	if version := SchemaVersion(data); version > 0 && version < LatestVersion {
+		r.warnf("schemaVersion", "is %d, the dashboard is migrated to version %d every time it is loaded until it is migrated and saved", version, LatestVersion)
+	}
+
+	for i, panel := range jsonArray(data, "panels") {
+		path := fmt.Sprintf("panels[%d]", i)
+		r.addPanelWarnings(path, panel)
+		for j, nested := range jsonArray(panel, "panels") {
+			r.addPanelWarnings(fmt.Sprintf("%s.panels[%d]", path, j), nested)
+		}
+	}
+}
+
+func (r *ValidationResult) addPanelWarnings(path string, panel *simplejson.Json) {
+	if replacement, ok := deprecatedPanelTypes[panel.Get("type").MustString()]; ok {
+		r.warnf(path+".type", "%s panels are deprecated, use %s panels instead", panel.Get("type").MustString(), replacement)
+	}
+	if _, ok := panel.Get("datasource").Interface().(string); ok {
+		r.warnf(path+".datasource", "references a data source by name, reference it by uid and type instead")
+	}
+	for i, target := range jsonArray(panel, "targets") {
+		if _, ok := target.Get("datasource").Interface().(string); ok {
+			r.warnf(fmt.Sprintf("%s.targets[%d].datasource", path, i), "references a data source by name, reference it by uid and type instead")
+		}
+	}
+}
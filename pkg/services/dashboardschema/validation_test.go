@@ -0,0 +1,83 @@
+package dashboardschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/jsonschema"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("accepts a dashboard matching the schema", func(t *testing.T) {
+		result, err := Validate(parseDashboard(t, `{
+			"title": "Dashboard",
+			"uid": null,
+			"schemaVersion": 36,
+			"tags": ["a"],
+			"refresh": false,
+			"time": {"from": "now-6h", "to": "now"},
+			"templating": {"list": [{"name": "host", "type": "query", "datasource": {"uid": "prom-uid", "type": "prometheus"}}]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0}, "targets": [{"refId": "A"}]},
+				{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "type": "stat"}]}
+			]
+		}`))
+		require.NoError(t, err)
+		require.True(t, result.Valid)
+		require.Empty(t, result.Errors)
+		require.Empty(t, result.Warnings)
+	})
+
+	t.Run("returns the values not matching the schema", func(t *testing.T) {
+		result, err := Validate(parseDashboard(t, `{
+			"title": 1,
+			"schemaVersion": 36,
+			"tags": "a",
+			"templating": {"list": [{"name": "", "type": "unknown"}]},
+			"panels": [
+				{"id": 1, "gridPos": {"h": 8, "w": 30, "x": 0}},
+				{"id": 2, "type": "row", "panels": [{"id": "3", "type": "stat"}]}
+			]
+		}`))
+		require.NoError(t, err)
+		require.False(t, result.Valid)
+		require.ElementsMatch(t, []jsonschema.FieldError{
+			{Field: "title", Message: "must be of type string"},
+			{Field: "tags", Message: "must be of type array"},
+			{Field: "templating.list[0].name", Message: "must be at least 1 characters long"},
+			{Field: "templating.list[0].type", Message: "must be one of query, adhoc, constant, datasource, interval, textbox, custom, system"},
+			{Field: "panels[0].type", Message: "is required"},
+			{Field: "panels[0].gridPos.y", Message: "is required"},
+			{Field: "panels[0].gridPos.w", Message: "must be less than or equal to 24"},
+			{Field: "panels[1].panels[0].id", Message: "must be of type integer"},
+		}, result.Errors)
+	})
+
+	t.Run("warns about the deprecated options", func(t *testing.T) {
+		result, err := Validate(parseDashboard(t, `{
+			"title": "Dashboard",
+			"schemaVersion": 30,
+			"sharedCrosshair": true,
+			"panels": [
+				{"id": 1, "type": "graph", "datasource": "Prometheus", "targets": [{"refId": "A", "datasource": "Loki"}]},
+				{"id": 2, "type": "row", "panels": [{"id": 3, "type": "singlestat"}]}
+			]
+		}`))
+		require.NoError(t, err)
+		require.True(t, result.Valid)
+		fields := make([]string, 0, len(result.Warnings))
+		for _, warning := range result.Warnings {
+			fields = append(fields, warning.Field)
+		}
+		require.Equal(t, []string{
+			"sharedCrosshair",
+			"schemaVersion",
+			"panels[0].type",
+			"panels[0].datasource",
+			"panels[0].targets[0].datasource",
+			"panels[1].panels[0].type",
+		}, fields)
+		require.Equal(t, "graph panels are deprecated, use timeseries panels instead", result.Warnings[2].Message)
+	})
+}
@@ -0,0 +1,18 @@
+package dashboardtemplates
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	ScopeTemplatesRoot = "dashboards.templates"
+
+	ActionTemplatesRead   = "dashboards.templates:read"
+	ActionTemplatesWrite  = "dashboards.templates:write"
+	ActionTemplatesDelete = "dashboards.templates:delete"
+)
+
+var (
+	ScopeTemplatesProvider = ac.NewScopeProvider(ScopeTemplatesRoot)
+	ScopeTemplatesAll      = ScopeTemplatesProvider.GetResourceAllScope()
+)
@@ -0,0 +1,21 @@
+package dashboardtemplates
+
+import (
+	"context"
+)
+
+// Service manages dashboard templates, dashboards published by admins with
+// declared inputs that are substituted when a dashboard is created from the
+// template.
+type Service interface {
+	// Get returns the template, or ErrDashboardTemplateNotFound.
+	Get(ctx context.Context, query *GetDashboardTemplateQuery) (*DashboardTemplate, error)
+	// List returns the templates of the organization.
+	List(ctx context.Context, query *ListDashboardTemplatesQuery) ([]*DashboardTemplate, error)
+	// Save creates or updates the template.
+	Save(ctx context.Context, cmd *SaveDashboardTemplateCommand) error
+	// Delete deletes the template, the dashboards created from it are kept.
+	Delete(ctx context.Context, cmd *DeleteDashboardTemplateCommand) error
+	// Instantiate creates a dashboard from the template with the values of its inputs.
+	Instantiate(ctx context.Context, cmd *InstantiateCommand) (*InstantiateResult, error)
+}
@@ -0,0 +1,189 @@
+package dashboardtemplatesimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const kvNamespace = "dashboard-templates"
+
+// Service stores each template of an organization as a JSON document in the
+// key-value store, with the uid of the template as key. The dashboards are
+// created from the templates like imported dashboards, with the inputs of the
+// template as the inputs of the import.
+type Service struct {
+	kv              kvstore.KVStore
+	dataSources     datasources.DataSourceService
+	folderService   dashboards.FolderService
+	dashboardImport dashboardimport.Service
+	now             func() time.Time
+}
+
+func ProvideService(kv kvstore.KVStore, dataSources datasources.DataSourceService, folderService dashboards.FolderService,
+	dashboardImport dashboardimport.Service) dashboardtemplates.Service {
+	return &Service{
+		kv:              kv,
+		dataSources:     dataSources,
+		folderService:   folderService,
+		dashboardImport: dashboardImport,
+		now:             time.Now,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *dashboardtemplates.GetDashboardTemplateQuery) (*dashboardtemplates.DashboardTemplate, error) {
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, query.UID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, dashboardtemplates.ErrDashboardTemplateNotFound
+	}
+
+	var result dashboardtemplates.DashboardTemplate
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) List(ctx context.Context, query *dashboardtemplates.ListDashboardTemplatesQuery) ([]*dashboardtemplates.DashboardTemplate, error) {
+	keys, err := s.kv.Keys(ctx, query.OrgID, kvNamespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dashboardtemplates.DashboardTemplate, 0, len(keys))
+	for _, key := range keys {
+		tmpl, err := s.Get(ctx, &dashboardtemplates.GetDashboardTemplateQuery{OrgID: query.OrgID, UID: key.Key})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tmpl)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *dashboardtemplates.SaveDashboardTemplateCommand) error {
+	tmpl := &cmd.Template
+	if err := tmpl.Validate(); err != nil {
+		return err
+	}
+
+	tmpl.Updated = s.now()
+	raw, err := json.Marshal(tmpl)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, cmd.OrgID, kvNamespace, tmpl.UID, string(raw))
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *dashboardtemplates.DeleteDashboardTemplateCommand) error {
+	if _, err := s.Get(ctx, &dashboardtemplates.GetDashboardTemplateQuery{OrgID: cmd.OrgID, UID: cmd.UID}); err != nil {
+		return err
+	}
+	return s.kv.Del(ctx, cmd.OrgID, kvNamespace, cmd.UID)
+}
+
+// Instantiate imports the dashboard of the template in the folder. The dashboard
+// gets a new uid, so that every instance of the template is a new dashboard.
+func (s *Service) Instantiate(ctx context.Context, cmd *dashboardtemplates.InstantiateCommand) (*dashboardtemplates.InstantiateResult, error) {
+	tmpl, err := s.Get(ctx, &dashboardtemplates.GetDashboardTemplateQuery{OrgID: cmd.OrgID, UID: cmd.UID})
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]dashboardimport.ImportDashboardInput, 0, len(tmpl.Inputs))
+	declared := make([]interface{}, 0, len(tmpl.Inputs))
+	for _, input := range tmpl.Inputs {
+		value, err := s.inputValue(ctx, cmd, input)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, dashboardimport.ImportDashboardInput{
+			Type:     input.Type,
+			PluginId: input.PluginID,
+			Name:     input.Name,
+			Value:    value,
+		})
+		declared = append(declared, map[string]interface{}{
+			"name":     input.Name,
+			"type":     input.Type,
+			"pluginId": input.PluginID,
+		})
+	}
+
+	dash := tmpl.Dashboard
+	dash.Del("id")
+	dash.Del("uid")
+	dash.Set("__inputs", declared)
+	if cmd.Title != "" {
+		dash.Set("title", cmd.Title)
+	}
+
+	var folderID int64
+	if cmd.FolderUID != "" {
+		folder, err := s.folderService.GetFolderByUID(ctx, cmd.User, cmd.OrgID, cmd.FolderUID)
+		if err != nil {
+			return nil, err
+		}
+		folderID = folder.Id
+	}
+
+	imported, err := s.dashboardImport.ImportDashboard(ctx, &dashboardimport.ImportDashboardRequest{
+		Dashboard: dash,
+		Inputs:    inputs,
+		FolderId:  folderID,
+		Overwrite: cmd.Overwrite,
+		User:      cmd.User,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dashboardtemplates.InstantiateResult{
+		UID:   imported.UID,
+		Title: imported.Title,
+		URL:   imported.ImportedUrl,
+	}, nil
+}
+
+// inputValue returns the value of the input, or its default value when it isn't
+// set. The data source of a data source input must exist and be of the plugin
+// of the input.
+func (s *Service) inputValue(ctx context.Context, cmd *dashboardtemplates.InstantiateCommand, input dashboardtemplates.Input) (string, error) {
+	value, ok := cmd.Inputs[input.Name]
+	if !ok || value == "" {
+		value = input.Value
+	}
+	if value == "" {
+		return "", fmt.Errorf("%w: %q is required", dashboardtemplates.ErrMissingInput, input.Name)
+	}
+	if input.Type != dashboardtemplates.InputTypeDataSource {
+		return value, nil
+	}
+
+	query := &models.GetDataSourceQuery{OrgId: cmd.OrgID, Uid: value}
+	if err := s.dataSources.GetDataSource(ctx, query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return "", fmt.Errorf("%w: data source %q of %q not found", dashboardtemplates.ErrInvalidInput, value, input.Name)
+		}
+		return "", err
+	}
+	if query.Result.Type != input.PluginID {
+		return "", fmt.Errorf("%w: data source %q of %q must be of type %q", dashboardtemplates.ErrInvalidInput, value, input.Name, input.PluginID)
+	}
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package dashboardtemplatesimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type recordingImportService struct {
+	request *dashboardimport.ImportDashboardRequest
+}
+
+func (r *recordingImportService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
+	r.request = req
+	return &dashboardimport.ImportDashboardResponse{
+		UID:         "new-uid",
+		Title:       req.Dashboard.Get("title").MustString(),
+		ImportedUrl: "/d/new-uid/service",
+	}, nil
+}
+
+func TestIntegrationDashboardTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	dataSources := &datasourcesfakes.FakeDataSourceService{DataSources: []*models.DataSource{
+		{OrgId: 1, Uid: "prom-uid", Type: "prometheus"},
+		{OrgId: 1, Uid: "loki-uid", Type: "loki"},
+	}}
+	folders := dashboards.NewFakeFolderService(t)
+	folders.On("GetFolderByUID", mock.Anything, mock.Anything, int64(1), "team-folder").Return(&models.Folder{Id: 7, Uid: "team-folder"}, nil)
+	imports := &recordingImportService{}
+	svc := ProvideService(kvstore.ProvideService(ss), dataSources, folders, imports)
+	ctx := context.Background()
+
+	newTemplate := func() dashboardtemplates.DashboardTemplate {
+		return dashboardtemplates.DashboardTemplate{
+			UID:  "service",
+			Name: "Service overview",
+			Inputs: []dashboardtemplates.Input{
+				{Name: "DS_PROMETHEUS", Type: dashboardtemplates.InputTypeDataSource, PluginID: "prometheus"},
+				{Name: "SERVICE", Type: dashboardtemplates.InputTypeConstant},
+				{Name: "ENV", Type: dashboardtemplates.InputTypeConstant, Value: "production"},
+			},
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"id":    3,
+				"uid":   "template-uid",
+				"title": "${SERVICE} overview",
+				"panels": []interface{}{
+					map[string]interface{}{
+						"datasource": map[string]interface{}{"type": "prometheus", "uid": "${DS_PROMETHEUS}"},
+						"targets":    []interface{}{map[string]interface{}{"expr": `up{service="${SERVICE}", env="${ENV}", host="${host}"}`}},
+					},
+				},
+			}),
+		}
+	}
+
+	t.Run("Save rejects data source inputs without a plugin", func(t *testing.T) {
+		invalid := newTemplate()
+		invalid.Inputs = []dashboardtemplates.Input{{Name: "DS", Type: dashboardtemplates.InputTypeDataSource}}
+		err := svc.Save(ctx, &dashboardtemplates.SaveDashboardTemplateCommand{OrgID: 1, Template: invalid})
+		require.ErrorIs(t, err, dashboardtemplates.ErrInvalidDashboardTemplate)
+	})
+
+	t.Run("Save rejects duplicate inputs", func(t *testing.T) {
+		invalid := newTemplate()
+		invalid.Inputs = append(invalid.Inputs, dashboardtemplates.Input{Name: "SERVICE", Type: dashboardtemplates.InputTypeConstant})
+		err := svc.Save(ctx, &dashboardtemplates.SaveDashboardTemplateCommand{OrgID: 1, Template: invalid})
+		require.ErrorIs(t, err, dashboardtemplates.ErrInvalidDashboardTemplate)
+	})
+
+	t.Run("Saves and lists the templates", func(t *testing.T) {
+		require.NoError(t, svc.Save(ctx, &dashboardtemplates.SaveDashboardTemplateCommand{OrgID: 1, Template: newTemplate()}))
+		other := newTemplate()
+		other.UID = "api"
+		other.Name = "API overview"
+		require.NoError(t, svc.Save(ctx, &dashboardtemplates.SaveDashboardTemplateCommand{OrgID: 1, Template: other}))
+
+		templates, err := svc.List(ctx, &dashboardtemplates.ListDashboardTemplatesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		require.Equal(t, "api", templates[0].UID)
+		require.Equal(t, "service", templates[1].UID)
+		require.Len(t, templates[1].Inputs, 3)
+		require.Equal(t, "${SERVICE} overview", templates[1].Dashboard.Get("title").MustString())
+
+		templates, err = svc.List(ctx, &dashboardtemplates.ListDashboardTemplatesQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Empty(t, templates)
+	})
+
+	t.Run("Instantiate imports the dashboard with the inputs", func(t *testing.T) {
+		result, err := svc.Instantiate(ctx, &dashboardtemplates.InstantiateCommand{
+			OrgID:     1,
+			UID:       "service",
+			User:      &models.SignedInUser{OrgId: 1, UserId: 2},
+			Inputs:    map[string]string{"DS_PROMETHEUS": "prom-uid", "SERVICE": "checkout"},
+			FolderUID: "team-folder",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "new-uid", result.UID)
+
+		req := imports.request
+		require.Equal(t, int64(7), req.FolderId)
+		require.Equal(t, []dashboardimport.ImportDashboardInput{
+			{Type: "datasource", PluginId: "prometheus", Name: "DS_PROMETHEUS", Value: "prom-uid"},
+			{Type: "constant", Name: "SERVICE", Value: "checkout"},
+			{Type: "constant", Name: "ENV", Value: "production"},
+		}, req.Inputs)
+		require.Len(t, req.Dashboard.Get("__inputs").MustArray(), 3)
+		_, hasID := req.Dashboard.CheckGet("id")
+		_, hasUID := req.Dashboard.CheckGet("uid")
+		require.False(t, hasID)
+		require.False(t, hasUID)
+	})
+
+	t.Run("Instantiate replaces the title", func(t *testing.T) {
+		result, err := svc.Instantiate(ctx, &dashboardtemplates.InstantiateCommand{
+			OrgID:  1,
+			UID:    "service",
+			User:   &models.SignedInUser{OrgId: 1, UserId: 2},
+			Inputs: map[string]string{"DS_PROMETHEUS": "prom-uid", "SERVICE": "checkout"},
+			Title:  "Checkout",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Checkout", result.Title)
+		require.Equal(t, int64(0), imports.request.FolderId)
+	})
+
+	t.Run("Instantiate requires the inputs without a default value", func(t *testing.T) {
+		_, err := svc.Instantiate(ctx, &dashboardtemplates.InstantiateCommand{
+			OrgID:  1,
+			UID:    "service",
+			Inputs: map[string]string{"DS_PROMETHEUS": "prom-uid"},
+		})
+		require.ErrorIs(t, err, dashboardtemplates.ErrMissingInput)
+	})
+
+	t.Run("Instantiate rejects data sources of another plugin", func(t *testing.T) {
+		_, err := svc.Instantiate(ctx, &dashboardtemplates.InstantiateCommand{
+			OrgID:  1,
+			UID:    "service",
+			Inputs: map[string]string{"DS_PROMETHEUS": "loki-uid", "SERVICE": "checkout"},
+		})
+		require.ErrorIs(t, err, dashboardtemplates.ErrInvalidInput)
+
+		_, err = svc.Instantiate(ctx, &dashboardtemplates.InstantiateCommand{
+			OrgID:  1,
+			UID:    "service",
+			Inputs: map[string]string{"DS_PROMETHEUS": "missing", "SERVICE": "checkout"},
+		})
+		require.ErrorIs(t, err, dashboardtemplates.ErrInvalidInput)
+	})
+
+	t.Run("Delete deletes the template", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, &dashboardtemplates.DeleteDashboardTemplateCommand{OrgID: 1, UID: "api"}))
+		_, err := svc.Get(ctx, &dashboardtemplates.GetDashboardTemplateQuery{OrgID: 1, UID: "api"})
+		require.ErrorIs(t, err, dashboardtemplates.ErrDashboardTemplateNotFound)
+
+		err = svc.Delete(ctx, &dashboardtemplates.DeleteDashboardTemplateCommand{OrgID: 1, UID: "api"})
+		require.ErrorIs(t, err, dashboardtemplates.ErrDashboardTemplateNotFound)
+	})
+}
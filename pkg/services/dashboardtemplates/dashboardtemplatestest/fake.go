@@ -0,0 +1,41 @@
+package dashboardtemplatestest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+)
+
+type FakeDashboardTemplatesService struct {
+	ExpectedTemplate      *dashboardtemplates.DashboardTemplate
+	ExpectedTemplates     []*dashboardtemplates.DashboardTemplate
+	ExpectedInstantiation *dashboardtemplates.InstantiateResult
+	ExpectedError         error
+
+	InstantiateCommand *dashboardtemplates.InstantiateCommand
+}
+
+func NewDashboardTemplatesServiceFake() *FakeDashboardTemplatesService {
+	return &FakeDashboardTemplatesService{}
+}
+
+func (f *FakeDashboardTemplatesService) Get(ctx context.Context, query *dashboardtemplates.GetDashboardTemplateQuery) (*dashboardtemplates.DashboardTemplate, error) {
+	return f.ExpectedTemplate, f.ExpectedError
+}
+
+func (f *FakeDashboardTemplatesService) List(ctx context.Context, query *dashboardtemplates.ListDashboardTemplatesQuery) ([]*dashboardtemplates.DashboardTemplate, error) {
+	return f.ExpectedTemplates, f.ExpectedError
+}
+
+func (f *FakeDashboardTemplatesService) Save(ctx context.Context, cmd *dashboardtemplates.SaveDashboardTemplateCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardTemplatesService) Delete(ctx context.Context, cmd *dashboardtemplates.DeleteDashboardTemplateCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardTemplatesService) Instantiate(ctx context.Context, cmd *dashboardtemplates.InstantiateCommand) (*dashboardtemplates.InstantiateResult, error) {
+	f.InstantiateCommand = cmd
+	return f.ExpectedInstantiation, f.ExpectedError
+}
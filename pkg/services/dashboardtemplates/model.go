@@ -0,0 +1,129 @@
+package dashboardtemplates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrDashboardTemplateNotFound = errors.New("dashboard template not found")
+	ErrInvalidDashboardTemplate  = errors.New("invalid dashboard template")
+	ErrMissingInput              = errors.New("missing dashboard template input")
+	ErrInvalidInput              = errors.New("invalid dashboard template input")
+)
+
+// The types of the inputs of a template, the same as the inputs of exported dashboards.
+const (
+	// InputTypeDataSource is the uid of a data source of the plugin of the input.
+	InputTypeDataSource = "datasource"
+	// InputTypeConstant is a text value.
+	InputTypeConstant = "constant"
+)
+
+var inputNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DashboardTemplate is the JSON of a dashboard referencing the inputs of the
+// template as ${name}. The other ${name} references, like the references to
+// the dashboard variables, are kept as they are.
+type DashboardTemplate struct {
+	UID         string           `json:"uid"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Inputs      []Input          `json:"inputs"`
+	Dashboard   *simplejson.Json `json:"dashboard"`
+	Updated     time.Time        `json:"updated"`
+}
+
+// Input is a value set when a dashboard is created from the template.
+type Input struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+	// PluginID is the type of the data sources of a data source input.
+	PluginID string `json:"pluginId,omitempty"`
+	// Value is used when the input isn't set.
+	Value string `json:"value,omitempty"`
+}
+
+type GetDashboardTemplateQuery struct {
+	OrgID int64
+	UID   string
+}
+
+type ListDashboardTemplatesQuery struct {
+	OrgID int64
+}
+
+type SaveDashboardTemplateCommand struct {
+	OrgID    int64
+	Template DashboardTemplate
+}
+
+type DeleteDashboardTemplateCommand struct {
+	OrgID int64
+	UID   string
+}
+
+type InstantiateCommand struct {
+	OrgID int64
+	UID   string
+	User  *models.SignedInUser
+	// Inputs are the values of the inputs by name.
+	Inputs    map[string]string
+	FolderUID string
+	// Title replaces the title of the dashboard of the template when set.
+	Title     string
+	Overwrite bool
+}
+
+type InstantiateResult struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Validate checks that the template has a valid uid, a dashboard, and inputs
+// with distinct names.
+func (t *DashboardTemplate) Validate() error {
+	if t.UID == "" || !util.IsValidShortUID(t.UID) || util.IsShortUIDTooLong(t.UID) {
+		return fmt.Errorf("%w: invalid uid %q", ErrInvalidDashboardTemplate, t.UID)
+	}
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDashboardTemplate)
+	}
+	if t.Dashboard == nil {
+		return fmt.Errorf("%w: dashboard is required", ErrInvalidDashboardTemplate)
+	}
+	if _, err := t.Dashboard.Map(); err != nil {
+		return fmt.Errorf("%w: dashboard must be an object", ErrInvalidDashboardTemplate)
+	}
+
+	names := make(map[string]bool, len(t.Inputs))
+	for _, input := range t.Inputs {
+		if !inputNamePattern.MatchString(input.Name) {
+			return fmt.Errorf("%w: invalid input name %q", ErrInvalidDashboardTemplate, input.Name)
+		}
+		if names[input.Name] {
+			return fmt.Errorf("%w: duplicate input name %q", ErrInvalidDashboardTemplate, input.Name)
+		}
+		names[input.Name] = true
+
+		switch input.Type {
+		case InputTypeDataSource:
+			if input.PluginID == "" {
+				return fmt.Errorf("%w: input %q must set the plugin of its data sources", ErrInvalidDashboardTemplate, input.Name)
+			}
+		case InputTypeConstant:
+		default:
+			return fmt.Errorf("%w: input %q has invalid type %q", ErrInvalidDashboardTemplate, input.Name, input.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package dashboardusage
+
+import (
+	"context"
+)
+
+// Service counts the views of the dashboards and the queries of their panels,
+// to sort the search results by popularity and to find the dashboards that are
+// no longer used.
+type Service interface {
+	// GetUnused returns the dashboards of an organization that haven't been viewed
+	// or queried since a time, the least recently used first.
+	GetUnused(ctx context.Context, query *GetUnusedQuery) ([]*UnusedDashboard, error)
+	// GetMostViewed returns the most viewed dashboards of all organizations, the
+	// most viewed first.
+	GetMostViewed(ctx context.Context, query *GetMostViewedQuery) ([]*PopularDashboard, error)
+}
@@ -0,0 +1,151 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// flushInterval is how often the views and queries counted in memory are added
+// to the usage of the dashboards in the database.
+const flushInterval = time.Minute
+
+func ProvideService(db db.DB, bus bus.Bus, searchService *search.SearchService) *Service {
+	s := &Service{
+		store:   &sqlStore{db: db},
+		log:     log.New("dashboardusage"),
+		pending: map[usageKey]*usageCount{},
+	}
+	bus.AddEventListener(s.handleDashboardViewed)
+	bus.AddEventListener(s.handleDashboardQueried)
+	searchService.RegisterSortOption(dashboardusage.SortViewsDesc)
+	searchService.RegisterSortOption(dashboardusage.SortViewsAsc)
+	searchService.RegisterSortOption(dashboardusage.SortQueriesDesc)
+	return s
+}
+
+type Service struct {
+	store store
+	log   log.Logger
+
+	mtx     sync.Mutex
+	pending map[usageKey]*usageCount
+}
+
+// usageKey identifies a dashboard by id, or by uid when the queries of its
+// panels were run with the uid only.
+type usageKey struct {
+	orgID int64
+	id    int64
+	uid   string
+}
+
+type usageCount struct {
+	views       int64
+	queries     int64
+	lastViewed  time.Time
+	lastQueried time.Time
+}
+
+func (c *usageCount) add(other *usageCount) {
+	c.views += other.views
+	c.queries += other.queries
+	if other.lastViewed.After(c.lastViewed) {
+		c.lastViewed = other.lastViewed
+	}
+	if other.lastQueried.After(c.lastQueried) {
+		c.lastQueried = other.lastQueried
+	}
+}
+
+func (s *Service) handleDashboardViewed(_ context.Context, evt *events.DashboardViewed) error {
+	s.count(usageKey{orgID: evt.OrgID, id: evt.ID}, &usageCount{views: 1, lastViewed: evt.Timestamp})
+	return nil
+}
+
+func (s *Service) handleDashboardQueried(_ context.Context, evt *events.DashboardQueried) error {
+	key := usageKey{orgID: evt.OrgID, id: evt.ID}
+	if evt.ID == 0 {
+		key.uid = evt.UID
+	}
+	s.count(key, &usageCount{queries: 1, lastQueried: evt.Timestamp})
+	return nil
+}
+
+func (s *Service) count(key usageKey, count *usageCount) {
+	if key.id == 0 && key.uid == "" {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if pending, ok := s.pending[key]; ok {
+		pending.add(count)
+		return
+	}
+	s.pending[key] = count
+}
+
+// Run adds the counted views and queries to the database on a schedule, the
+// counts of every server of a cluster are added up.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// the server is stopping, keep the counts of the last interval
+			s.flush(context.Background())
+			return ctx.Err()
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mtx.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]*usageCount{}
+	s.mtx.Unlock()
+
+	counts := map[usageKey]*usageCount{}
+	for key, count := range pending {
+		if key.id == 0 {
+			id, err := s.store.GetDashboardID(ctx, key.orgID, key.uid)
+			if err != nil {
+				s.log.Warn("Failed to get the dashboard of the queries", "orgId", key.orgID, "uid", key.uid, "error", err)
+				continue
+			}
+			if id == 0 {
+				continue
+			}
+			key = usageKey{orgID: key.orgID, id: id}
+		}
+		if existing, ok := counts[key]; ok {
+			existing.add(count)
+			continue
+		}
+		counts[key] = count
+	}
+
+	for key, count := range counts {
+		if err := s.store.AddUsage(ctx, key.orgID, key.id, count); err != nil {
+			s.log.Error("Failed to save the usage of the dashboard", "orgId", key.orgID, "dashboardId", key.id, "error", err)
+		}
+	}
+}
+
+func (s *Service) GetUnused(ctx context.Context, query *dashboardusage.GetUnusedQuery) ([]*dashboardusage.UnusedDashboard, error) {
+	return s.store.GetUnused(ctx, query)
+}
+
+func (s *Service) GetMostViewed(ctx context.Context, query *dashboardusage.GetMostViewedQuery) ([]*dashboardusage.PopularDashboard, error) {
+	return s.store.GetMostViewed(ctx, query)
+}
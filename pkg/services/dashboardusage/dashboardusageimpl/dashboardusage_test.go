@@ -0,0 +1,65 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+type fakeStore struct {
+	ids   map[string]int64
+	usage map[int64]*usageCount
+}
+
+func (f *fakeStore) GetDashboardID(_ context.Context, _ int64, uid string) (int64, error) {
+	return f.ids[uid], nil
+}
+
+func (f *fakeStore) AddUsage(_ context.Context, _, dashboardID int64, count *usageCount) error {
+	if usage, ok := f.usage[dashboardID]; ok {
+		usage.add(count)
+		return nil
+	}
+	f.usage[dashboardID] = count
+	return nil
+}
+
+func (f *fakeStore) GetUnused(context.Context, *dashboardusage.GetUnusedQuery) ([]*dashboardusage.UnusedDashboard, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) GetMostViewed(context.Context, *dashboardusage.GetMostViewedQuery) ([]*dashboardusage.PopularDashboard, error) {
+	return nil, nil
+}
+
+func TestService_Flush(t *testing.T) {
+	store := &fakeStore{ids: map[string]int64{"dash": 1}, usage: map[int64]*usageCount{}}
+	s := &Service{store: store, log: log.NewNopLogger(), pending: map[usageKey]*usageCount{}}
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, s.handleDashboardViewed(ctx, &events.DashboardViewed{Timestamp: now.Add(-time.Minute), ID: 1, OrgID: 1}))
+	require.NoError(t, s.handleDashboardViewed(ctx, &events.DashboardViewed{Timestamp: now, ID: 1, OrgID: 1}))
+	require.NoError(t, s.handleDashboardQueried(ctx, &events.DashboardQueried{Timestamp: now, ID: 1, OrgID: 1, PanelID: 2}))
+	require.NoError(t, s.handleDashboardQueried(ctx, &events.DashboardQueried{Timestamp: now, UID: "dash", OrgID: 1, PanelID: 2}))
+	require.NoError(t, s.handleDashboardQueried(ctx, &events.DashboardQueried{Timestamp: now, UID: "missing", OrgID: 1}))
+	require.NoError(t, s.handleDashboardQueried(ctx, &events.DashboardQueried{Timestamp: now, OrgID: 1}))
+
+	s.flush(ctx)
+	require.Len(t, store.usage, 1)
+	require.Equal(t, int64(2), store.usage[1].views)
+	require.Equal(t, int64(2), store.usage[1].queries)
+	require.Equal(t, now, store.usage[1].lastViewed)
+	require.Equal(t, now, store.usage[1].lastQueried)
+	require.Empty(t, s.pending)
+
+	// the counts are only added once
+	s.flush(ctx)
+	require.Equal(t, int64(2), store.usage[1].views)
+}
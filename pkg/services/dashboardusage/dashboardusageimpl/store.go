@@ -0,0 +1,194 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// GetDashboardID returns 0 if the dashboard doesn't exist.
+	GetDashboardID(ctx context.Context, orgID int64, uid string) (int64, error)
+	// AddUsage adds the views and queries to the usage of the dashboard.
+	AddUsage(ctx context.Context, orgID, dashboardID int64, count *usageCount) error
+	GetUnused(ctx context.Context, query *dashboardusage.GetUnusedQuery) ([]*dashboardusage.UnusedDashboard, error)
+	GetMostViewed(ctx context.Context, query *dashboardusage.GetMostViewedQuery) ([]*dashboardusage.PopularDashboard, error)
+}
+
+// dashboardUsage is a row of the dashboard_usage table, the last views and
+// queries are unix timestamps, 0 if the dashboard was never viewed or queried.
+type dashboardUsage struct {
+	Id          int64
+	OrgId       int64
+	DashboardId int64
+	Views       int64
+	Queries     int64
+	LastViewed  int64
+	LastQueried int64
+	Updated     time.Time
+}
+
+func (dashboardUsage) TableName() string {
+	return "dashboard_usage"
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) GetDashboardID(ctx context.Context, orgID int64, uid string) (int64, error) {
+	var id int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.SQL("SELECT id FROM dashboard WHERE org_id = ? AND uid = ?", orgID, uid).Get(&id)
+		return err
+	})
+	return id, err
+}
+
+func (s *sqlStore) AddUsage(ctx context.Context, orgID, dashboardID int64, count *usageCount) error {
+	var lastViewed, lastQueried int64
+	if count.views > 0 {
+		lastViewed = count.lastViewed.Unix()
+	}
+	if count.queries > 0 {
+		lastQueried = count.lastQueried.Unix()
+	}
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// the counts are incremented in the database, the servers of a cluster
+		// add their counts at the same time
+		res, err := sess.Exec(`UPDATE dashboard_usage SET
+			views = views + ?,
+			queries = queries + ?,
+			last_viewed = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed END,
+			last_queried = CASE WHEN last_queried < ? THEN ? ELSE last_queried END,
+			updated = ?
+			WHERE dashboard_id = ?`,
+			count.views, count.queries, lastViewed, lastViewed, lastQueried, lastQueried, time.Now(), dashboardID)
+		if err != nil {
+			return err
+		}
+		if updated, err := res.RowsAffected(); err != nil || updated > 0 {
+			return err
+		}
+
+		_, err = sess.Insert(&dashboardUsage{
+			OrgId:       orgID,
+			DashboardId: dashboardID,
+			Views:       count.views,
+			Queries:     count.queries,
+			LastViewed:  lastViewed,
+			LastQueried: lastQueried,
+			Updated:     time.Now(),
+		})
+		return err
+	})
+}
+
+type unusedDashboard struct {
+	Uid         string
+	Title       string
+	Slug        string
+	FolderUid   string
+	FolderTitle string
+	Views       int64
+	Queries     int64
+	LastViewed  int64
+	LastQueried int64
+	Created     time.Time
+}
+
+func (s *sqlStore) GetUnused(ctx context.Context, query *dashboardusage.GetUnusedQuery) ([]*dashboardusage.UnusedDashboard, error) {
+	rows := make([]*unusedDashboard, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		dialect := s.db.GetDialect()
+		since := query.Since.Unix()
+		sql := `SELECT
+			dashboard.uid,
+			dashboard.title,
+			dashboard.slug,
+			dashboard.created,
+			folder.uid AS folder_uid,
+			folder.title AS folder_title,
+			COALESCE(dashboard_usage.views, 0) AS views,
+			COALESCE(dashboard_usage.queries, 0) AS queries,
+			COALESCE(dashboard_usage.last_viewed, 0) AS last_viewed,
+			COALESCE(dashboard_usage.last_queried, 0) AS last_queried
+			FROM dashboard
+			LEFT OUTER JOIN dashboard AS folder ON folder.id = dashboard.folder_id
+			LEFT OUTER JOIN dashboard_usage ON dashboard_usage.dashboard_id = dashboard.id
+			WHERE dashboard.org_id = ? AND dashboard.is_folder = ` + dialect.BooleanStr(false) + ` AND dashboard.created < ?
+			AND COALESCE(dashboard_usage.last_viewed, 0) < ? AND COALESCE(dashboard_usage.last_queried, 0) < ?`
+		if query.ExcludeProvisioned {
+			sql += ` AND NOT EXISTS (SELECT 1 FROM dashboard_provisioning WHERE dashboard_provisioning.dashboard_id = dashboard.id)`
+		}
+		if query.ExcludeHomeDashboards {
+			sql += ` AND NOT EXISTS (SELECT 1 FROM preferences WHERE preferences.home_dashboard_id = dashboard.id)`
+		}
+		sql += ` ORDER BY last_viewed ASC, dashboard.title ASC`
+		if query.Limit > 0 {
+			sql += " " + dialect.Limit(int64(query.Limit))
+		}
+		return sess.SQL(sql, query.OrgID, query.Since, since, since).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dashboardusage.UnusedDashboard, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, &dashboardusage.UnusedDashboard{
+			UID:         row.Uid,
+			Title:       row.Title,
+			URL:         models.GetDashboardUrl(row.Uid, row.Slug),
+			FolderUID:   row.FolderUid,
+			FolderTitle: row.FolderTitle,
+			Views:       row.Views,
+			Queries:     row.Queries,
+			LastViewed:  unixTime(row.LastViewed),
+			LastQueried: unixTime(row.LastQueried),
+			Created:     row.Created,
+		})
+	}
+	return result, nil
+}
+
+func (s *sqlStore) GetMostViewed(ctx context.Context, query *dashboardusage.GetMostViewedQuery) ([]*dashboardusage.PopularDashboard, error) {
+	var rows []struct {
+		OrgId int64
+		Uid   string
+		Views int64
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sql := `SELECT dashboard.org_id, dashboard.uid, dashboard_usage.views
+			FROM dashboard_usage
+			INNER JOIN dashboard ON dashboard.id = dashboard_usage.dashboard_id
+			WHERE dashboard_usage.views > 0
+			ORDER BY dashboard_usage.views DESC, dashboard.id ASC`
+		if query.Limit > 0 {
+			sql += " " + s.db.GetDialect().Limit(int64(query.Limit))
+		}
+		return sess.SQL(sql).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dashboardusage.PopularDashboard, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, &dashboardusage.PopularDashboard{OrgID: row.OrgId, UID: row.Uid, Views: row.Views})
+	}
+	return result, nil
+}
+
+func unixTime(ts int64) *time.Time {
+	if ts == 0 {
+		return nil
+	}
+	t := time.Unix(ts, 0).UTC()
+	return &t
+}
@@ -0,0 +1,159 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+func TestIntegrationDashboardUsageStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	store := &sqlStore{db: ss}
+	ctx := context.Background()
+
+	created := time.Now().Add(-48 * time.Hour)
+	folder := insertDashboard(t, ss, "folder", 0, true, created)
+	viewed := insertDashboard(t, ss, "viewed", folder.Id, false, created)
+	queried := insertDashboard(t, ss, "queried", 0, false, created)
+	stale := insertDashboard(t, ss, "stale", folder.Id, false, created)
+	unused := insertDashboard(t, ss, "unused", 0, false, created)
+	insertDashboard(t, ss, "new", 0, false, time.Now())
+
+	now := time.Now()
+	require.NoError(t, store.AddUsage(ctx, 1, viewed.Id, &usageCount{views: 2, lastViewed: now.Add(-time.Hour)}))
+	require.NoError(t, store.AddUsage(ctx, 1, viewed.Id, &usageCount{views: 1, queries: 3, lastViewed: now.Add(-2 * time.Hour), lastQueried: now}))
+	require.NoError(t, store.AddUsage(ctx, 1, queried.Id, &usageCount{queries: 5, lastQueried: now}))
+	require.NoError(t, store.AddUsage(ctx, 1, stale.Id, &usageCount{views: 1, lastViewed: now.Add(-36 * time.Hour)}))
+
+	t.Run("adds up the usage of a dashboard", func(t *testing.T) {
+		usage := &dashboardUsage{}
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Where("dashboard_id = ?", viewed.Id).Get(usage)
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage.Views)
+		require.Equal(t, int64(3), usage.Queries)
+		require.Equal(t, now.Add(-time.Hour).Unix(), usage.LastViewed)
+		require.Equal(t, now.Unix(), usage.LastQueried)
+	})
+
+	t.Run("returns the dashboards that were not used since a time", func(t *testing.T) {
+		result, err := store.GetUnused(ctx, &dashboardusage.GetUnusedQuery{OrgID: 1, Since: now.Add(-24 * time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		require.Equal(t, unused.Uid, result[0].UID)
+		require.Nil(t, result[0].LastViewed)
+		require.Nil(t, result[0].LastQueried)
+
+		require.Equal(t, stale.Uid, result[1].UID)
+		require.Equal(t, folder.Uid, result[1].FolderUID)
+		require.Equal(t, "/d/stale/stale", result[1].URL)
+		require.Equal(t, int64(1), result[1].Views)
+		require.Equal(t, now.Add(-36*time.Hour).Unix(), result[1].LastViewed.Unix())
+	})
+
+	t.Run("returns the most viewed dashboards", func(t *testing.T) {
+		result, err := store.GetMostViewed(ctx, &dashboardusage.GetMostViewedQuery{Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*dashboardusage.PopularDashboard{{OrgID: 1, UID: viewed.Uid, Views: 3}}, result)
+
+		result, err = store.GetMostViewed(ctx, &dashboardusage.GetMostViewedQuery{})
+		require.NoError(t, err)
+		require.Len(t, result, 2, "dashboards without views are left out")
+		require.Equal(t, stale.Uid, result[1].UID)
+	})
+
+	t.Run("limits the unused dashboards", func(t *testing.T) {
+		result, err := store.GetUnused(ctx, &dashboardusage.GetUnusedQuery{OrgID: 1, Since: now.Add(-24 * time.Hour), Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, unused.Uid, result[0].UID)
+	})
+
+	t.Run("excludes the provisioned and home dashboards", func(t *testing.T) {
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			if _, err := sess.Insert(&models.DashboardProvisioning{DashboardId: unused.Id, Name: "default", ExternalId: "unused.json"}); err != nil {
+				return err
+			}
+			_, err := sess.Exec("INSERT INTO preferences (org_id, user_id, team_id, version, home_dashboard_id, timezone, theme, created, updated) VALUES (1, 0, 0, 0, ?, '', '', ?, ?)", stale.Id, now, now)
+			return err
+		})
+		require.NoError(t, err)
+
+		result, err := store.GetUnused(ctx, &dashboardusage.GetUnusedQuery{OrgID: 1, Since: now.Add(-24 * time.Hour), ExcludeProvisioned: true})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, stale.Uid, result[0].UID)
+
+		result, err = store.GetUnused(ctx, &dashboardusage.GetUnusedQuery{OrgID: 1, Since: now.Add(-24 * time.Hour), ExcludeProvisioned: true, ExcludeHomeDashboards: true})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("sorts the search results by views", func(t *testing.T) {
+		builder := &searchstore.Builder{
+			Filters: append([]interface{}{
+				searchstore.OrgFilter{OrgId: 1},
+				searchstore.DashboardFilter{UIDs: []string{viewed.Uid, queried.Uid, stale.Uid}},
+			}, toInterfaces(dashboardusage.SortViewsDesc.Filter)...),
+			Dialect: ss.Dialect,
+		}
+		res := []dashboards.DashboardSearchProjection{}
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			sql, params := builder.ToSQL(10, 1)
+			return sess.SQL(sql, params...).Find(&res)
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		require.Equal(t, viewed.Uid, res[0].UID)
+		require.Equal(t, int64(3), res[0].SortMeta)
+		require.Equal(t, stale.Uid, res[1].UID)
+		require.Equal(t, int64(1), res[1].SortMeta)
+		require.Equal(t, queried.Uid, res[2].UID)
+		require.Equal(t, int64(0), res[2].SortMeta)
+	})
+}
+
+func insertDashboard(t *testing.T, ss *sqlstore.SQLStore, uid string, folderID int64, isFolder bool, created time.Time) *models.Dashboard {
+	t.Helper()
+	dash := &models.Dashboard{
+		OrgId:    1,
+		Uid:      uid,
+		Title:    uid,
+		Slug:     uid,
+		FolderId: folderID,
+		IsFolder: isFolder,
+		Data:     simplejson.NewFromAny(map[string]interface{}{"title": uid}),
+		Version:  1,
+		Created:  created,
+		Updated:  created,
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+	return dash
+}
+
+func toInterfaces(filters []models.SortOptionFilter) []interface{} {
+	result := make([]interface{}, 0, len(filters))
+	for _, f := range filters {
+		result = append(result, f)
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package dashboardusage
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+var (
+	SortViewsDesc = models.SortOption{
+		Name:        "views-desc",
+		DisplayName: "Most viewed",
+		Description: "Sort results by the number of views, most viewed first",
+		Index:       1,
+		MetaName:    "views",
+		Filter: []models.SortOptionFilter{
+			Sorter{Column: "views", Descending: true},
+			searchstore.TitleSorter{},
+		},
+	}
+	SortViewsAsc = models.SortOption{
+		Name:        "views-asc",
+		DisplayName: "Least viewed",
+		Description: "Sort results by the number of views, least viewed first",
+		Index:       1,
+		MetaName:    "views",
+		Filter: []models.SortOptionFilter{
+			Sorter{Column: "views"},
+			searchstore.TitleSorter{},
+		},
+	}
+	SortQueriesDesc = models.SortOption{
+		Name:        "queries-desc",
+		DisplayName: "Most queried",
+		Description: "Sort results by the number of panel queries, most queried first",
+		Index:       1,
+		MetaName:    "queries",
+		Filter: []models.SortOptionFilter{
+			Sorter{Column: "queries", Descending: true},
+			searchstore.TitleSorter{},
+		},
+	}
+)
+
+// Sorter orders the search results by a usage count, which is returned as the
+// sort meta of the hits. The dashboards that were never used count as 0.
+type Sorter struct {
+	Column     string
+	Descending bool
+}
+
+func (s Sorter) LeftJoin() string {
+	return "dashboard_usage ON dashboard_usage.dashboard_id = dashboard.id"
+}
+
+func (s Sorter) OrderBy() string {
+	if s.Descending {
+		return s.count() + " DESC"
+	}
+	return s.count() + " ASC"
+}
+
+func (s Sorter) Select() string {
+	return s.count() + " AS sort_meta"
+}
+
+func (s Sorter) count() string {
+	return "COALESCE(dashboard_usage." + s.Column + ", 0)"
+}
+
+// UnusedDashboard is a dashboard that hasn't been viewed or queried for a while.
+type UnusedDashboard struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	FolderUID   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+	// Views and Queries are counted since the usage of the dashboards is tracked.
+	Views   int64 `json:"views"`
+	Queries int64 `json:"queries"`
+	// LastViewed and LastQueried are not set for dashboards that were never used.
+	LastViewed  *time.Time `json:"lastViewed,omitempty"`
+	LastQueried *time.Time `json:"lastQueried,omitempty"`
+	Created     time.Time  `json:"created"`
+}
+
+// PopularDashboard is a dashboard ranked by its number of views.
+type PopularDashboard struct {
+	OrgID int64
+	UID   string
+	Views int64
+}
+
+// ---------------------
+// QUERIES
+
+type GetUnusedQuery struct {
+	OrgID int64
+	// Since is the time from which the dashboards must have been used, the
+	// dashboards created after it are not reported.
+	Since time.Time
+	Limit int
+	// ExcludeProvisioned leaves out the provisioned dashboards, and
+	// ExcludeHomeDashboards the dashboards that are a home dashboard.
+	ExcludeProvisioned    bool
+	ExcludeHomeDashboards bool
+}
+
+type GetMostViewedQuery struct {
+	Limit int
+}
@@ -0,0 +1,19 @@
+package dashboardvariables
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service evaluates dashboard template variables on the backend, so that
+// variables can be resolved without a browser, e.g. for reports and alerts.
+type Service interface {
+	// ResolveVariables evaluates the template variables of the dashboard in
+	// dependency order and returns them in the order they are defined.
+	ResolveVariables(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd ResolveVariablesCommand) ([]*Variable, error)
+	// InterpolationValues resolves the template variables of the dashboard and
+	// returns the values queries are interpolated with, by variable name,
+	// including the __from and __to built-in variables.
+	InterpolationValues(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd ResolveVariablesCommand) (map[string][]string, error)
+}
@@ -0,0 +1,162 @@
+package dashboardvariables
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variableRegex matches $var, [[var]], [[var:format]], ${var} and ${var:format},
+// the same syntax the frontend template service supports.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.[^:^\}]+)?(?::([^\}]+))?\}`)
+
+// References returns the names of the variables referenced in text.
+func References(text string) []string {
+	var names []string
+	for _, m := range variableRegex.FindAllStringSubmatch(text, -1) {
+		name, _ := matchNameAndFormat(m)
+		names = append(names, name)
+	}
+	return names
+}
+
+// Interpolate replaces the variables referenced in text by their values. Multiple
+// values are joined according to the format of the reference, comma separated by
+// default. References to unknown variables are left untouched.
+func Interpolate(text string, values map[string][]string) string {
+	return variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name, format := matchNameAndFormat(variableRegex.FindStringSubmatch(match))
+		vals, ok := values[name]
+		if !ok {
+			return match
+		}
+		return formatValues(vals, format)
+	})
+}
+
+// InterpolateValue interpolates the strings of a decoded JSON value, such as the
+// model of a query, recursively.
+func InterpolateValue(v interface{}, values map[string][]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return Interpolate(t, values)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			m[k] = InterpolateValue(item, values)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(t))
+		for _, item := range t {
+			l = append(l, InterpolateValue(item, values))
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// builtInValuePattern matches the values of the built-in variables, such as
+// $__interval or ${__from:date:iso}, which are interpolated by the frontend.
+const builtInValuePattern = `[\w.:+-]*`
+
+// Pattern returns a regular expression matching text with its variables left as
+// they are or interpolated with any of the options, or any combination of the
+// options for multi-value variables. References to built-in variables, whose name
+// starts with two underscores, match any interval, time or number. References to
+// other variables only match themselves.
+func Pattern(text string, options map[string][]string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range variableRegex.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(regexp.QuoteMeta(text[last:loc[0]]))
+		last = loc[1]
+
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		name, format := matchNameAndFormat(m)
+
+		b.WriteString("(?:" + regexp.QuoteMeta(m[0]))
+		if values, ok := options[name]; ok && len(values) > 0 {
+			b.WriteString("|" + valuesPattern(values, format))
+		} else if strings.HasPrefix(name, "__") {
+			b.WriteString("|" + builtInValuePattern)
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(regexp.QuoteMeta(text[last:]))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// valuesPattern matches a value or several values joined the way formatValues joins them.
+func valuesPattern(values []string, format string) string {
+	alternatives := make([]string, 0, len(values))
+	for _, v := range values {
+		alternatives = append(alternatives, regexp.QuoteMeta(formatValues([]string{v}, format)))
+	}
+	single := "(?:" + strings.Join(alternatives, "|") + ")"
+
+	separator, prefix, suffix := ",", "", ""
+	switch format {
+	case "pipe":
+		separator = "|"
+	case "regex":
+		separator, prefix, suffix = "|", "(", ")"
+	case "glob":
+		prefix, suffix = "{", "}"
+	}
+	multi := regexp.QuoteMeta(prefix) + single + "(?:" + regexp.QuoteMeta(separator) + single + ")+" + regexp.QuoteMeta(suffix)
+	return single + "|" + multi
+}
+
+func matchNameAndFormat(m []string) (string, string) {
+	switch {
+	case m[1] != "":
+		return m[1], ""
+	case m[2] != "":
+		return m[2], m[3]
+	default:
+		return m[4], m[5]
+	}
+}
+
+func formatValues(values []string, format string) string {
+	switch format {
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		if len(values) == 1 {
+			return regexp.QuoteMeta(values[0])
+		}
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, regexp.QuoteMeta(v))
+		}
+		return "(" + strings.Join(quoted, "|") + ")"
+	case "singlequote":
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, "'"+strings.ReplaceAll(v, "'", "\\'")+"'")
+		}
+		return strings.Join(quoted, ",")
+	case "doublequote":
+		quoted := make([]string, 0, len(values))
+		for _, v := range values {
+			quoted = append(quoted, `"`+strings.ReplaceAll(v, `"`, `\"`)+`"`)
+		}
+		return strings.Join(quoted, ",")
+	case "glob":
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	default:
+		return strings.Join(values, ",")
+	}
+}
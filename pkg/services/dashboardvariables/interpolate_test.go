@@ -0,0 +1,90 @@
+package dashboardvariables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	values := map[string][]string{
+		"host": {"a", "b"},
+		"env":  {"prod"},
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "up{env=\"$env\"}", expected: "up{env=\"prod\"}"},
+		{text: "up{host=~\"${host:regex}\"}", expected: "up{host=~\"(a|b)\"}"},
+		{text: "[[host:pipe]]", expected: "a|b"},
+		{text: "${host}", expected: "a,b"},
+		{text: "${host:singlequote}", expected: "'a','b'"},
+		{text: "$unknown", expected: "$unknown"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, Interpolate(tt.text, values), tt.text)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	require.Equal(t, []string{"a", "b", "c"}, References("$a [[b]] ${c:csv}"))
+	require.Empty(t, References("no variables"))
+}
+
+func TestPattern(t *testing.T) {
+	options := map[string][]string{
+		"host": {"a.1", "b"},
+		"env":  {"prod"},
+	}
+
+	tests := []struct {
+		text      string
+		matches   []string
+		unmatched []string
+	}{
+		{
+			text:      "up{env=\"$env\"}",
+			matches:   []string{"up{env=\"prod\"}", "up{env=\"$env\"}"},
+			unmatched: []string{"up{env=\"dev\"}", "up{env=\"prod\"} or vector(1)", "up{env=\"prod,prod\"} "},
+		},
+		{
+			text:      "up{host=~\"${host:regex}\"}",
+			matches:   []string{"up{host=~\"a\\.1\"}", "up{host=~\"(a\\.1|b)\"}", "up{host=~\"(b|b)\"}"},
+			unmatched: []string{"up{host=~\"a.1\"}", "up{host=~\".*\"}", "up{host=~\"(b)\"}"},
+		},
+		{
+			text:      "${host}",
+			matches:   []string{"a.1", "a.1,b"},
+			unmatched: []string{"a.1,c", "a"},
+		},
+		{
+			text:      "[[host:pipe]]",
+			matches:   []string{"b|a.1"},
+			unmatched: []string{"b,a.1"},
+		},
+		{
+			text:      "${host:singlequote}",
+			matches:   []string{"'a.1','b'"},
+			unmatched: []string{"'a.1',b"},
+		},
+		{
+			text:      "rate(x[$__rate_interval]) $unknown",
+			matches:   []string{"rate(x[1m]) $unknown", "rate(x[$__rate_interval]) $unknown"},
+			unmatched: []string{"rate(x[1m]) value", "rate(x[1m] or y) $unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		re, err := Pattern(tt.text, options)
+		require.NoError(t, err)
+		for _, m := range tt.matches {
+			require.True(t, re.MatchString(m), "%s should match %s", tt.text, m)
+		}
+		for _, m := range tt.unmatched {
+			require.False(t, re.MatchString(m), "%s should not match %s", tt.text, m)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package dashboardvariables
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrVariableCycle   = errors.New("template variables have a circular dependency")
+	ErrInvalidTemplate = errors.New("invalid dashboard templating")
+)
+
+// AllValue is the value of the option selecting all options of a variable.
+const AllValue = "$__all"
+
+// ResolveVariablesCommand is the command for resolving the template variables of a dashboard
+// swagger:model
+type ResolveVariablesCommand struct {
+	// From Start of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// example: now-6h
+	From string `json:"from"`
+	// To End of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// example: now
+	To string `json:"to"`
+	// Values selects the values of variables by name, instead of the values saved with the dashboard.
+	Values map[string][]string `json:"values"`
+
+	SkipCache bool `json:"-"`
+	// CacheTTL overrides how long the query results of variables are cached,
+	// for variables whose results are cached at all.
+	CacheTTL time.Duration `json:"-"`
+}
+
+type ResolveVariablesResponse struct {
+	Variables []*Variable `json:"variables"`
+}
+
+// Variable is a dashboard template variable with its options evaluated.
+type Variable struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Current Current  `json:"current"`
+	Options []Option `json:"options"`
+	// Error is set when the options of the variable could not be evaluated, in which case
+	// the options and current value saved with the dashboard are returned.
+	Error string `json:"error,omitempty"`
+}
+
+type Current struct {
+	Text  []string `json:"text"`
+	Value []string `json:"value"`
+}
+
+type Option struct {
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
@@ -0,0 +1,178 @@
+package varsimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+)
+
+const (
+	refreshOnTimeChange  = 2
+	variableQueryRefID   = "variable-query"
+	defaultAllOptionText = "All"
+)
+
+// variableModel is a template variable as it is stored in the dashboard JSON.
+type variableModel struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Query      interface{}   `json:"query"`
+	Datasource interface{}   `json:"datasource"`
+	Regex      string        `json:"regex"`
+	Sort       int           `json:"sort"`
+	Multi      bool          `json:"multi"`
+	IncludeAll bool          `json:"includeAll"`
+	AllValue   string        `json:"allValue"`
+	Refresh    int           `json:"refresh"`
+	Current    optionModel   `json:"current"`
+	Options    []optionModel `json:"options"`
+	// CacheTTL overrides the instance wide cache TTL of the query results, e.g. 5m.
+	CacheTTL string `json:"cacheTtl"`
+}
+
+// optionModel holds text and value which are either a string or a list of strings.
+type optionModel struct {
+	Text  interface{} `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+func parseVariables(dashboard *simplejson.Json) ([]*variableModel, error) {
+	list, ok := dashboard.Get("templating").CheckGet("list")
+	if !ok {
+		return nil, nil
+	}
+
+	encoded, err := list.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var variables []*variableModel
+	if err := json.Unmarshal(encoded, &variables); err != nil {
+		return nil, fmt.Errorf("%w: %s", dashboardvariables.ErrInvalidTemplate, err)
+	}
+	for _, v := range variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("%w: variable without name", dashboardvariables.ErrInvalidTemplate)
+		}
+	}
+	return variables, nil
+}
+
+// queryString returns the query of the variable as text, objects are encoded as JSON.
+func (v *variableModel) queryString() string {
+	switch q := v.Query.(type) {
+	case nil:
+		return ""
+	case string:
+		return q
+	default:
+		encoded, err := json.Marshal(q)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
+
+// datasourceString returns the datasource reference of the variable, either a uid or a name.
+func (v *variableModel) datasourceString() string {
+	switch ds := v.Datasource.(type) {
+	case string:
+		return ds
+	case map[string]interface{}:
+		if uid, ok := ds["uid"].(string); ok {
+			return uid
+		}
+	}
+	return ""
+}
+
+// dependencies returns the names of the variables referenced by this variable.
+func (v *variableModel) dependencies() []string {
+	text := strings.Join([]string{v.queryString(), v.datasourceString(), v.Regex}, " ")
+	return dashboardvariables.References(text)
+}
+
+func (v *variableModel) savedOptions() []dashboardvariables.Option {
+	options := make([]dashboardvariables.Option, 0, len(v.Options))
+	for _, o := range v.Options {
+		texts, values := toStrings(o.Text), toStrings(o.Value)
+		if len(values) == 0 {
+			continue
+		}
+		text := values[0]
+		if len(texts) > 0 {
+			text = texts[0]
+		}
+		options = append(options, dashboardvariables.Option{Text: text, Value: values[0]})
+	}
+	return options
+}
+
+func toStrings(v interface{}) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{t}
+	case []interface{}:
+		values := make([]string, 0, len(t))
+		for _, item := range t {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(t)}
+	}
+}
+
+// sortVariables orders the variables so that every variable comes after the
+// variables it references.
+func sortVariables(variables []*variableModel) ([]*variableModel, error) {
+	byName := make(map[string]*variableModel, len(variables))
+	for _, v := range variables {
+		byName[v.Name] = v
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(variables))
+	sorted := make([]*variableModel, 0, len(variables))
+
+	var visit func(v *variableModel) error
+	visit = func(v *variableModel) error {
+		switch state[v.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", dashboardvariables.ErrVariableCycle, v.Name)
+		}
+		state[v.Name] = visiting
+		for _, dep := range v.dependencies() {
+			depVar, ok := byName[dep]
+			if !ok || dep == v.Name {
+				continue
+			}
+			if err := visit(depVar); err != nil {
+				return err
+			}
+		}
+		state[v.Name] = visited
+		sorted = append(sorted, v)
+		return nil
+	}
+
+	for _, v := range variables {
+		if err := visit(v); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,314 @@
+package varsimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// queryOptions runs the query of the variable against its data source and turns
+// the returned frames into options.
+func (s *Service) queryOptions(ctx context.Context, rc *resolveContext, vm *variableModel) ([]dashboardvariables.Option, error) {
+	ds, err := s.lookupDataSource(ctx, rc, dashboardvariables.Interpolate(vm.datasourceString(), rc.values))
+	if err != nil {
+		return nil, err
+	}
+
+	model := map[string]interface{}{}
+	switch q := vm.Query.(type) {
+	case map[string]interface{}:
+		for k, v := range q {
+			model[k] = dashboardvariables.InterpolateValue(v, rc.values)
+		}
+	default:
+		model["query"] = dashboardvariables.Interpolate(vm.queryString(), rc.values)
+	}
+	model["refId"] = variableQueryRefID
+	model["datasource"] = map[string]interface{}{"uid": ds.Uid, "type": ds.Type}
+
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	// Results are cached per dashboard, so that results warmed up for a dashboard
+	// are only served when resolving its variables.
+	cacheKey := fmt.Sprintf("dashboard-variable-%d-%s-%s-%x", rc.user.OrgId, rc.dashboard.Uid, ds.Uid, sha256.Sum256(encoded))
+	if vm.Refresh == refreshOnTimeChange {
+		cacheKey += fmt.Sprintf("-%d-%d", rc.timeRange.GetFromAsMsEpoch(), rc.timeRange.GetToAsMsEpoch())
+	}
+
+	var values []dashboardvariables.Option
+	if cached, ok := s.cache.Get(cacheKey); ok && !rc.cmd.SkipCache {
+		values = cached.([]dashboardvariables.Option)
+	} else {
+		resp, err := s.queryService.QueryData(ctx, rc.user, rc.cmd.SkipCache, dtos.MetricRequest{
+			From:    rc.cmd.From,
+			To:      rc.cmd.To,
+			Queries: []*simplejson.Json{simplejson.NewFromAny(model)},
+		}, false)
+		if err != nil {
+			return nil, err
+		}
+		values, err = framesToOptions(resp)
+		if err != nil {
+			return nil, err
+		}
+		if ttl := s.cacheTTL(vm); ttl > 0 {
+			if rc.cmd.CacheTTL > 0 {
+				ttl = rc.cmd.CacheTTL
+			}
+			s.cache.Set(cacheKey, values, ttl)
+		}
+	}
+
+	// Copy so that filtering and sorting does not modify cached options
+	options := append([]dashboardvariables.Option{}, values...)
+	options, err = applyRegex(options, dashboardvariables.Interpolate(vm.Regex, rc.values))
+	if err != nil {
+		return nil, err
+	}
+	sortOptions(options, vm.Sort)
+	return options, nil
+}
+
+// datasourceOptions lists the data sources of the plugin type in the query of the variable.
+func (s *Service) datasourceOptions(ctx context.Context, rc *resolveContext, vm *variableModel) ([]dashboardvariables.Option, error) {
+	query := &models.GetDataSourcesQuery{OrgId: rc.user.OrgId, User: rc.user}
+	if err := s.dataSourceService.GetDataSources(ctx, query); err != nil {
+		return nil, err
+	}
+
+	pluginID := vm.queryString()
+	options := []dashboardvariables.Option{}
+	for _, ds := range query.Result {
+		if ds.Type == pluginID {
+			options = append(options, dashboardvariables.Option{Text: ds.Name, Value: ds.Name})
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Text < options[j].Text })
+
+	return applyRegex(options, dashboardvariables.Interpolate(vm.Regex, rc.values))
+}
+
+// lookupDataSource finds a data source by uid or name, an empty reference means
+// the default data source. Since query results are cached for all users of the
+// organization, the user must be allowed to query the data source.
+func (s *Service) lookupDataSource(ctx context.Context, rc *resolveContext, ref string) (*models.DataSource, error) {
+	ds, err := s.findDataSource(ctx, rc, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ds.Uid))
+	if ok, err := s.ac.Evaluate(ctx, rc.user, evaluator); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("data source %q: %w", ref, models.ErrDataSourceAccessDenied)
+	}
+	return ds, nil
+}
+
+func (s *Service) findDataSource(ctx context.Context, rc *resolveContext, ref string) (*models.DataSource, error) {
+	if ref == "" || ref == "default" {
+		query := &models.GetDefaultDataSourceQuery{OrgId: rc.user.OrgId, User: rc.user}
+		if err := s.dataSourceService.GetDefaultDataSource(ctx, query); err != nil {
+			return nil, err
+		}
+		return query.Result, nil
+	}
+
+	query := &models.GetDataSourceQuery{Uid: ref, OrgId: rc.user.OrgId}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err == nil {
+		return query.Result, nil
+	}
+	query = &models.GetDataSourceQuery{Name: ref, OrgId: rc.user.OrgId}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err != nil {
+		return nil, fmt.Errorf("data source %q: %w", ref, err)
+	}
+	return query.Result, nil
+}
+
+// framesToOptions uses the text and value fields if the frames have them, otherwise
+// the first string field or the first field. Duplicate values are dropped.
+func framesToOptions(resp *backend.QueryDataResponse) ([]dashboardvariables.Option, error) {
+	dr, ok := resp.Responses[variableQueryRefID]
+	if !ok {
+		return nil, nil
+	}
+	if dr.Error != nil {
+		return nil, dr.Error
+	}
+
+	seen := map[string]bool{}
+	options := []dashboardvariables.Option{}
+	for _, frame := range dr.Frames {
+		textField, valueField := optionFields(frame)
+		if textField == nil {
+			continue
+		}
+		for i := 0; i < textField.Len(); i++ {
+			text := fieldString(textField, i)
+			value := text
+			if valueField != nil {
+				value = fieldString(valueField, i)
+			}
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+			options = append(options, dashboardvariables.Option{Text: text, Value: value})
+		}
+	}
+	return options, nil
+}
+
+func optionFields(frame *data.Frame) (*data.Field, *data.Field) {
+	var text, value, firstString *data.Field
+	for _, f := range frame.Fields {
+		switch strings.ToLower(f.Name) {
+		case "__text", "text":
+			text = f
+		case "__value", "value":
+			value = f
+		}
+		if firstString == nil && f.Type().NonNullableType() == data.FieldTypeString {
+			firstString = f
+		}
+	}
+	switch {
+	case text != nil:
+		return text, value
+	case value != nil:
+		return value, nil
+	case firstString != nil:
+		return firstString, nil
+	case len(frame.Fields) > 0:
+		return frame.Fields[0], nil
+	}
+	return nil, nil
+}
+
+func fieldString(f *data.Field, i int) string {
+	v, ok := f.ConcreteAt(i)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// applyRegex keeps the options matching the regex of the variable, written as
+// /pattern/flags. A text and value named group, or else the first group, is used
+// as the text and value of the option.
+func applyRegex(options []dashboardvariables.Option, pattern string) ([]dashboardvariables.Option, error) {
+	if pattern == "" {
+		return options, nil
+	}
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	textIdx, valueIdx := re.SubexpIndex("text"), re.SubexpIndex("value")
+	filtered := []dashboardvariables.Option{}
+	for _, o := range options {
+		m := re.FindStringSubmatch(o.Value)
+		if m == nil {
+			continue
+		}
+		switch {
+		case textIdx > 0 || valueIdx > 0:
+			if textIdx > 0 && m[textIdx] != "" {
+				o.Text = m[textIdx]
+			}
+			if valueIdx > 0 && m[valueIdx] != "" {
+				o.Value = m[valueIdx]
+			}
+			if textIdx <= 0 {
+				o.Text = o.Value
+			}
+			if valueIdx <= 0 {
+				o.Value = o.Text
+			}
+		case len(m) > 1 && m[1] != "":
+			o.Text, o.Value = m[1], m[1]
+		}
+		filtered = append(filtered, o)
+	}
+	return filtered, nil
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "/") {
+		if end := strings.LastIndex(pattern, "/"); end > 0 {
+			flags := strings.ReplaceAll(pattern[end+1:], "g", "")
+			pattern = pattern[1:end]
+			if flags != "" {
+				pattern = "(?" + flags + ")" + pattern
+			}
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variable regex: %w", err)
+	}
+	return re, nil
+}
+
+// sortOptions sorts like the sort setting of query variables in the frontend:
+// 1/2 alphabetical, 3/4 numerical, 5/6 case insensitive alphabetical, ascending and descending.
+func sortOptions(options []dashboardvariables.Option, order int) {
+	if order <= 0 {
+		return
+	}
+
+	var less func(a, b string) bool
+	switch (order + 1) / 2 {
+	case 1:
+		less = func(a, b string) bool { return a < b }
+	case 2:
+		less = func(a, b string) bool {
+			return leadingNumber(a) < leadingNumber(b)
+		}
+	case 3:
+		less = func(a, b string) bool { return strings.ToLower(a) < strings.ToLower(b) }
+	default:
+		return
+	}
+
+	desc := order%2 == 0
+	sort.SliceStable(options, func(i, j int) bool {
+		if desc {
+			return less(options[j].Text, options[i].Text)
+		}
+		return less(options[i].Text, options[j].Text)
+	})
+}
+
+var leadingNumberRegex = regexp.MustCompile(`.*?(\d+)`)
+
+func leadingNumber(s string) int64 {
+	m := leadingNumberRegex.FindStringSubmatch(s)
+	if m == nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,268 @@
+package varsimpl
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+func ProvideService(cfg *setting.Cfg, queryService *query.Service, dataSourceService datasources.DataSourceService, cacheService *localcache.CacheService,
+	ac accesscontrol.AccessControl) dashboardvariables.Service {
+	return &Service{
+		cfg:               cfg,
+		queryService:      queryService,
+		dataSourceService: dataSourceService,
+		cache:             cacheService,
+		ac:                ac,
+		log:               log.New("dashboard.variables"),
+	}
+}
+
+type Service struct {
+	cfg               *setting.Cfg
+	queryService      *query.Service
+	dataSourceService datasources.DataSourceService
+	cache             *localcache.CacheService
+	ac                accesscontrol.AccessControl
+	log               log.Logger
+}
+
+// resolveContext holds the state shared by all variables of a single resolve request.
+type resolveContext struct {
+	user      *models.SignedInUser
+	dashboard *models.Dashboard
+	cmd       dashboardvariables.ResolveVariablesCommand
+	timeRange legacydata.DataTimeRange
+	// values the already resolved variables are interpolated with
+	values map[string][]string
+}
+
+func (s *Service) ResolveVariables(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd dashboardvariables.ResolveVariablesCommand) ([]*dashboardvariables.Variable, error) {
+	variables, _, err := s.resolve(ctx, user, dashboard, cmd)
+	return variables, err
+}
+
+func (s *Service) InterpolationValues(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd dashboardvariables.ResolveVariablesCommand) (map[string][]string, error) {
+	_, values, err := s.resolve(ctx, user, dashboard, cmd)
+	return values, err
+}
+
+// resolve returns the resolved variables in the order they are defined, and the
+// values they are interpolated with.
+func (s *Service) resolve(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd dashboardvariables.ResolveVariablesCommand) ([]*dashboardvariables.Variable, map[string][]string, error) {
+	variables, err := parseVariables(dashboard.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	ordered, err := sortVariables(variables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cmd.From == "" {
+		cmd.From = dashboard.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if cmd.To == "" {
+		cmd.To = dashboard.Data.Get("time").Get("to").MustString("now")
+	}
+	rc := &resolveContext{
+		user:      user,
+		dashboard: dashboard,
+		cmd:       cmd,
+		timeRange: legacydata.NewDataTimeRange(cmd.From, cmd.To),
+		values:    map[string][]string{},
+	}
+	rc.values["__from"] = []string{strconv.FormatInt(rc.timeRange.GetFromAsMsEpoch(), 10)}
+	rc.values["__to"] = []string{strconv.FormatInt(rc.timeRange.GetToAsMsEpoch(), 10)}
+
+	resolved := make(map[string]*dashboardvariables.Variable, len(ordered))
+	for _, vm := range ordered {
+		v := s.resolveVariable(ctx, rc, vm)
+		resolved[vm.Name] = v
+		rc.values[vm.Name] = interpolationValues(v, vm.AllValue)
+	}
+
+	result := make([]*dashboardvariables.Variable, 0, len(variables))
+	for _, vm := range variables {
+		result = append(result, resolved[vm.Name])
+	}
+	return result, rc.values, nil
+}
+
+func (s *Service) resolveVariable(ctx context.Context, rc *resolveContext, vm *variableModel) *dashboardvariables.Variable {
+	v := &dashboardvariables.Variable{
+		Name: vm.Name,
+		Type: vm.Type,
+	}
+
+	options, err := s.options(ctx, rc, vm)
+	if err != nil {
+		s.log.Warn("Failed to resolve template variable", "dashboard", rc.dashboard.Uid, "variable", vm.Name, "error", err)
+		v.Error = err.Error()
+		options = vm.savedOptions()
+	}
+
+	if vm.IncludeAll {
+		options = append([]dashboardvariables.Option{{Text: defaultAllOptionText, Value: dashboardvariables.AllValue}}, options...)
+	}
+	v.Options = options
+	selectCurrent(v, vm, rc.cmd.Values)
+	return v
+}
+
+// options evaluates the options of the variable depending on its type.
+func (s *Service) options(ctx context.Context, rc *resolveContext, vm *variableModel) ([]dashboardvariables.Option, error) {
+	switch vm.Type {
+	case "custom":
+		return customOptions(splitCustom(dashboardvariables.Interpolate(vm.queryString(), rc.values))), nil
+	case "interval":
+		return valuesToOptions(splitCustom(vm.queryString())), nil
+	case "constant":
+		return valuesToOptions([]string{dashboardvariables.Interpolate(vm.queryString(), rc.values)}), nil
+	case "textbox":
+		value := vm.queryString()
+		if current := toStrings(vm.Current.Value); len(current) > 0 {
+			value = current[0]
+		}
+		return valuesToOptions([]string{value}), nil
+	case "datasource":
+		return s.datasourceOptions(ctx, rc, vm)
+	case "query":
+		return s.queryOptions(ctx, rc, vm)
+	default:
+		// Ad hoc filters and unknown types are returned as they were saved
+		return vm.savedOptions(), nil
+	}
+}
+
+// selectCurrent picks the current value of the variable: the requested values,
+// the values saved with the dashboard or the first option, in that order.
+func selectCurrent(v *dashboardvariables.Variable, vm *variableModel, requested map[string][]string) {
+	candidates, ok := requested[vm.Name]
+	if !ok {
+		candidates = toStrings(vm.Current.Value)
+	}
+
+	textByValue := make(map[string]string, len(v.Options))
+	for _, o := range v.Options {
+		textByValue[o.Value] = o.Text
+	}
+
+	var selected []string
+	for _, c := range candidates {
+		if _, ok := textByValue[c]; ok {
+			selected = append(selected, c)
+		}
+	}
+	if len(selected) == 0 && len(v.Options) > 0 {
+		selected = []string{v.Options[0].Value}
+	}
+	if !vm.Multi && len(selected) > 1 {
+		selected = selected[:1]
+	}
+
+	isSelected := make(map[string]bool, len(selected))
+	v.Current = dashboardvariables.Current{Text: []string{}, Value: []string{}}
+	for _, value := range selected {
+		isSelected[value] = true
+		v.Current.Value = append(v.Current.Value, value)
+		v.Current.Text = append(v.Current.Text, textByValue[value])
+	}
+	for i := range v.Options {
+		v.Options[i].Selected = isSelected[v.Options[i].Value]
+	}
+}
+
+// interpolationValues returns the values the variable is interpolated with. The
+// all option expands to the custom all value or to the values of all options.
+func interpolationValues(v *dashboardvariables.Variable, allValue string) []string {
+	for _, value := range v.Current.Value {
+		if value != dashboardvariables.AllValue {
+			continue
+		}
+		if allValue != "" {
+			return []string{allValue}
+		}
+		values := make([]string, 0, len(v.Options))
+		for _, o := range v.Options {
+			if o.Value != dashboardvariables.AllValue {
+				values = append(values, o.Value)
+			}
+		}
+		return values
+	}
+	return v.Current.Value
+}
+
+// cacheTTL returns for how long the query results of the variable are cached.
+func (s *Service) cacheTTL(vm *variableModel) time.Duration {
+	if vm.CacheTTL == "" {
+		return s.cfg.DashboardVariableCacheTTL
+	}
+	ttl, err := gtime.ParseDuration(vm.CacheTTL)
+	if err != nil {
+		s.log.Warn("Invalid template variable cache TTL", "variable", vm.Name, "cacheTtl", vm.CacheTTL, "error", err)
+		return s.cfg.DashboardVariableCacheTTL
+	}
+	return ttl
+}
+
+// splitCustom splits comma separated values, commas can be escaped with a backslash.
+func splitCustom(query string) []string {
+	var values []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range query {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			values = append(values, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if last := strings.TrimSpace(current.String()); last != "" || len(values) > 0 {
+		values = append(values, last)
+	}
+	return values
+}
+
+func valuesToOptions(values []string) []dashboardvariables.Option {
+	options := make([]dashboardvariables.Option, 0, len(values))
+	for _, v := range values {
+		options = append(options, dashboardvariables.Option{Text: v, Value: v})
+	}
+	return options
+}
+
+// customOptions supports the "text : value" syntax of custom variables.
+func customOptions(values []string) []dashboardvariables.Option {
+	options := make([]dashboardvariables.Option, 0, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, " : ", 2)
+		if len(parts) == 2 {
+			options = append(options, dashboardvariables.Option{Text: parts[0], Value: parts[1]})
+			continue
+		}
+		options = append(options, dashboardvariables.Option{Text: v, Value: v})
+	}
+	return options
+}
@@ -0,0 +1,191 @@
+package varsimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResolveVariables(t *testing.T) {
+	svc := &Service{
+		cfg:   setting.NewCfg(),
+		cache: localcache.New(0, 0),
+		log:   log.New("test"),
+	}
+	user := &models.SignedInUser{OrgId: 1}
+
+	newDashboard := func(list ...map[string]interface{}) *models.Dashboard {
+		variables := make([]interface{}, 0, len(list))
+		for _, v := range list {
+			variables = append(variables, v)
+		}
+		return &models.Dashboard{
+			Uid: "dash",
+			Data: simplejson.NewFromAny(map[string]interface{}{
+				"templating": map[string]interface{}{"list": variables},
+			}),
+		}
+	}
+
+	t.Run("resolves variables in dependency order", func(t *testing.T) {
+		dash := newDashboard(
+			map[string]interface{}{"name": "app", "type": "custom", "query": "$env-api,$env-web", "multi": true, "includeAll": true,
+				"current": map[string]interface{}{"value": []interface{}{"$__all"}}},
+			map[string]interface{}{"name": "env", "type": "custom", "query": "dev,prod", "current": map[string]interface{}{"value": "prod"}},
+			map[string]interface{}{"name": "filter", "type": "constant", "query": "${app:pipe}"},
+		)
+
+		variables, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{})
+		require.NoError(t, err)
+		require.Len(t, variables, 3)
+
+		require.Equal(t, "app", variables[0].Name)
+		require.Equal(t, []string{dashboardvariables.AllValue}, variables[0].Current.Value)
+		require.Equal(t, "prod-api", variables[0].Options[1].Value)
+		require.Equal(t, []string{"prod"}, variables[1].Current.Value)
+		require.Equal(t, []string{"prod-api|prod-web"}, variables[2].Current.Value)
+	})
+
+	t.Run("interpolation values expand the all option", func(t *testing.T) {
+		dash := newDashboard(
+			map[string]interface{}{"name": "env", "type": "custom", "query": "dev,prod", "includeAll": true,
+				"current": map[string]interface{}{"value": []interface{}{"$__all"}}},
+		)
+
+		values, err := svc.InterpolationValues(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{From: "0", To: "1000"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"dev", "prod"}, values["env"])
+		require.Equal(t, []string{"0"}, values["__from"])
+		require.Equal(t, []string{"1000"}, values["__to"])
+	})
+
+	t.Run("requested values override saved values", func(t *testing.T) {
+		dash := newDashboard(
+			map[string]interface{}{"name": "env", "type": "custom", "query": "dev,prod", "current": map[string]interface{}{"value": "prod"}},
+		)
+
+		variables, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{
+			Values: map[string][]string{"env": {"dev"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"dev"}, variables[0].Current.Value)
+		require.True(t, variables[0].Options[0].Selected)
+	})
+
+	t.Run("unknown values fall back to the first option", func(t *testing.T) {
+		dash := newDashboard(
+			map[string]interface{}{"name": "env", "type": "custom", "query": "Development : dev,prod", "current": map[string]interface{}{"value": "staging"}},
+		)
+
+		variables, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"dev"}, variables[0].Current.Value)
+		require.Equal(t, []string{"Development"}, variables[0].Current.Text)
+	})
+
+	t.Run("circular dependencies are rejected", func(t *testing.T) {
+		dash := newDashboard(
+			map[string]interface{}{"name": "a", "type": "custom", "query": "$b"},
+			map[string]interface{}{"name": "b", "type": "custom", "query": "$a"},
+		)
+
+		_, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{})
+		require.ErrorIs(t, err, dashboardvariables.ErrVariableCycle)
+	})
+}
+
+func TestResolveVariables_dataSourcePermissions(t *testing.T) {
+	cache := localcache.New(0, 0)
+	dataSources := &datasourcesfakes.FakeDataSourceService{DataSources: []*models.DataSource{{Id: 1, Uid: "prom", Name: "Prometheus", OrgId: 1}}}
+	dash := &models.Dashboard{
+		Uid: "dash",
+		Data: simplejson.NewFromAny(map[string]interface{}{
+			"templating": map[string]interface{}{"list": []interface{}{
+				map[string]interface{}{"name": "host", "type": "query", "query": "up", "datasource": map[string]interface{}{"uid": "prom"}},
+			}},
+		}),
+	}
+
+	// Options cached by a user allowed to query the data source.
+	model, err := json.Marshal(map[string]interface{}{"query": "up", "refId": variableQueryRefID, "datasource": map[string]interface{}{"uid": "prom", "type": ""}})
+	require.NoError(t, err)
+	cache.Set(fmt.Sprintf("dashboard-variable-%d-%s-%s-%x", 1, "dash", "prom", sha256.Sum256(model)), []dashboardvariables.Option{{Text: "a", Value: "a"}}, time.Minute)
+
+	newService := func(permissions ...accesscontrol.Permission) *Service {
+		return &Service{
+			cfg:               setting.NewCfg(),
+			dataSourceService: dataSources,
+			cache:             cache,
+			ac:                acmock.New().WithPermissions(permissions),
+			log:               log.New("test"),
+		}
+	}
+	user := &models.SignedInUser{OrgId: 1}
+
+	t.Run("cached options are served to users allowed to query the data source", func(t *testing.T) {
+		svc := newService(accesscontrol.Permission{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("prom")})
+
+		variables, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{})
+		require.NoError(t, err)
+		require.Empty(t, variables[0].Error)
+		require.Equal(t, []string{"a"}, variables[0].Current.Value)
+	})
+
+	t.Run("cached options are not served to other users", func(t *testing.T) {
+		svc := newService()
+
+		variables, err := svc.ResolveVariables(context.Background(), user, dash, dashboardvariables.ResolveVariablesCommand{})
+		require.NoError(t, err)
+		require.Contains(t, variables[0].Error, models.ErrDataSourceAccessDenied.Error())
+		require.Empty(t, variables[0].Options)
+	})
+}
+
+func TestFramesToOptions(t *testing.T) {
+	resp := backend.NewQueryDataResponse()
+	resp.Responses[variableQueryRefID] = backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("",
+			data.NewField("__text", nil, []string{"Server A", "Server B", "Server A"}),
+			data.NewField("__value", nil, []string{"a", "b", "a"}),
+		),
+	}}
+
+	options, err := framesToOptions(resp)
+	require.NoError(t, err)
+	require.Equal(t, []dashboardvariables.Option{{Text: "Server A", Value: "a"}, {Text: "Server B", Value: "b"}}, options)
+}
+
+func TestApplyRegexAndSort(t *testing.T) {
+	options := []dashboardvariables.Option{
+		{Text: "host-10.prod", Value: "host-10.prod"},
+		{Text: "host-2.prod", Value: "host-2.prod"},
+		{Text: "host-3.dev", Value: "host-3.dev"},
+	}
+
+	filtered, err := applyRegex(options, "/(host-\\d+)\\.prod/")
+	require.NoError(t, err)
+	require.Equal(t, []dashboardvariables.Option{{Text: "host-10", Value: "host-10"}, {Text: "host-2", Value: "host-2"}}, filtered)
+
+	sortOptions(filtered, 3)
+	require.Equal(t, "host-2", filtered[0].Value)
+	sortOptions(filtered, 2)
+	require.Equal(t, "host-2", filtered[0].Value)
+}
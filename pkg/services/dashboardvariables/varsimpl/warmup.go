@@ -0,0 +1,417 @@
+package varsimpl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	warmupLogin = "dashboard-cache-warmup"
+	// warmupMaxDataPoints is the resolution of the warmed up panel queries when
+	// the panel doesn't set one, since the size of the panels isn't known.
+	warmupMaxDataPoints = 1000
+)
+
+// builtInDataSources are the data sources that don't exist in the data_source
+// table. Panels querying them aren't warmed up, except for expressions.
+var builtInDataSources = map[string]bool{
+	"-- Mixed --":     true,
+	"-- Grafana --":   true,
+	"-- Dashboard --": true,
+	"grafana":         true,
+}
+
+// queryRunner runs the queries of the warmed up panels.
+type queryRunner interface {
+	QueryDataMultipleSources(ctx context.Context, user *models.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, handleExpressions bool) (*backend.QueryDataResponse, error)
+}
+
+// CacheWarmer resolves the template variables and runs the panel queries of the
+// most viewed dashboards, so that their results are cached before users open the
+// dashboards.
+//
+// A dashboard is warmed up as a viewer that can only read the dashboard and
+// query the data sources the dashboard refers to, never with more permissions
+// than the dashboard grants. The results are cached for the dashboard only:
+// variable options are served when resolving the variables of the dashboard,
+// and panel results for the queries of the panel of the same version of the
+// dashboard, to users who can view the panel and query its data sources.
+//
+// Queries are run for the time range saved in the dashboard, and panel results
+// are served for time ranges of the same length until they expire. Panels with
+// a relative time or a time shift, library panels and panels querying built-in
+// data sources aren't warmed up. The cache is local to the server, every
+// server of a cluster warms up its own cache.
+type CacheWarmer struct {
+	cfg               *setting.Cfg
+	usage             dashboardusage.Service
+	dashboards        dashboards.DashboardService
+	variables         dashboardvariables.Service
+	dataSourceService datasources.DataSourceService
+	queries           queryRunner
+	panelCache        *query.PanelCache
+	ac                accesscontrol.AccessControl
+	log               log.Logger
+	now               func() time.Time
+}
+
+func ProvideCacheWarmer(cfg *setting.Cfg, usage dashboardusage.Service, dashboardService dashboards.DashboardService,
+	variables dashboardvariables.Service, dataSourceService datasources.DataSourceService, queryService *query.Service,
+	panelCache *query.PanelCache, ac accesscontrol.AccessControl) *CacheWarmer {
+	return &CacheWarmer{
+		cfg:               cfg,
+		usage:             usage,
+		dashboards:        dashboardService,
+		variables:         variables,
+		dataSourceService: dataSourceService,
+		queries:           queryService,
+		panelCache:        panelCache,
+		ac:                ac,
+		log:               log.New("dashboard.variables.warmup"),
+		now:               time.Now,
+	}
+}
+
+// IsDisabled returns true when the cache isn't warmed up.
+func (w *CacheWarmer) IsDisabled() bool {
+	return !w.cfg.DashboardCacheWarmup.Enabled || w.cfg.DashboardCacheWarmup.TopDashboards <= 0 ||
+		w.cfg.DashboardCacheWarmup.CacheTTL <= 0
+}
+
+// Run warms up the cache once in every off-peak window, or on startup and
+// then every cache TTL when no window is configured.
+func (w *CacheWarmer) Run(ctx context.Context) error {
+	var last time.Time
+	for {
+		timer := time.NewTimer(w.nextRun(w.now(), last).Sub(w.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		last = w.now()
+		w.warmup(ctx)
+	}
+}
+
+// nextRun returns when the cache is next warmed up, given when it was last.
+func (w *CacheWarmer) nextRun(now, last time.Time) time.Time {
+	settings := w.cfg.DashboardCacheWarmup
+	if settings.WindowStart == settings.WindowEnd {
+		if last.IsZero() {
+			return now
+		}
+		return last.Add(settings.CacheTTL)
+	}
+
+	// A window ending after midnight starts on the day before, so the window
+	// of yesterday may still be open.
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for day := -1; ; day++ {
+		start := midnight.AddDate(0, 0, day).Add(settings.WindowStart)
+		end := midnight.AddDate(0, 0, day).Add(settings.WindowEnd)
+		if !end.After(start) {
+			end = end.Add(24 * time.Hour)
+		}
+		if !end.After(now) || !last.Before(start) {
+			continue
+		}
+		if start.Before(now) {
+			return now
+		}
+		return start
+	}
+}
+
+func (w *CacheWarmer) warmup(ctx context.Context) {
+	popular, err := w.usage.GetMostViewed(ctx, &dashboardusage.GetMostViewedQuery{Limit: w.cfg.DashboardCacheWarmup.TopDashboards})
+	if err != nil {
+		w.log.Error("Failed to get the most viewed dashboards", "error", err)
+		return
+	}
+
+	warmed, panels := 0, 0
+	for _, p := range popular {
+		if ctx.Err() != nil {
+			return
+		}
+		query := &models.GetDashboardQuery{OrgId: p.OrgID, Uid: p.UID}
+		if err := w.dashboards.GetDashboard(ctx, query); err != nil {
+			w.log.Warn("Failed to get dashboard to warm up", "orgId", p.OrgID, "dashboard", p.UID, "error", err)
+			continue
+		}
+		n, err := w.warmupDashboard(ctx, query.Result)
+		if err != nil {
+			w.log.Warn("Failed to warm up dashboard", "orgId", p.OrgID, "dashboard", p.UID, "error", err)
+			continue
+		}
+		warmed++
+		panels += n
+	}
+	w.log.Info("Warmed up the most viewed dashboards", "dashboards", warmed, "panels", panels)
+}
+
+// warmupDashboard resolves the variables of the dashboard and runs the queries
+// of its panels, and returns the number of panels warmed up.
+func (w *CacheWarmer) warmupDashboard(ctx context.Context, dash *models.Dashboard) (int, error) {
+	ttl := w.cfg.DashboardCacheWarmup.CacheTTL
+	viewer := w.dashboardViewer(ctx, dash)
+	values, err := w.variables.InterpolationValues(ctx, viewer, dash, dashboardvariables.ResolveVariablesCommand{
+		SkipCache: true,
+		CacheTTL:  ttl,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	hidden := dashboards.HiddenPanelIDs(viewer, dash.Data)
+	warmed := 0
+	for _, panel := range dashboardPanels(dash.Data) {
+		id := simplejson.NewFromAny(panel).Get("id").MustInt64()
+		if id == 0 || hidden[id] {
+			continue
+		}
+		reqDTO, ok := w.panelRequest(ctx, viewer, panel, values)
+		if !ok {
+			continue
+		}
+		resp, err := w.queries.QueryDataMultipleSources(ctx, viewer, true, reqDTO, true)
+		if err != nil {
+			w.log.Debug("Failed to warm up panel", "orgId", dash.OrgId, "dashboard", dash.Uid, "panelId", id, "error", err)
+			continue
+		}
+		if !successful(resp) {
+			continue
+		}
+		key := query.PanelCacheKey{OrgID: dash.OrgId, DashboardUID: dash.Uid, DashboardVersion: dash.Version, PanelID: id}
+		if err := w.panelCache.Set(key, reqDTO, resp, ttl); err != nil {
+			return warmed, err
+		}
+		warmed++
+	}
+	return warmed, nil
+}
+
+// dashboardViewer returns the user the dashboard is warmed up as: a viewer that
+// can only read the dashboard and query the data sources it refers to with the
+// values saved for its variables.
+func (w *CacheWarmer) dashboardViewer(ctx context.Context, dash *models.Dashboard) *models.SignedInUser {
+	user := &models.SignedInUser{OrgId: dash.OrgId, OrgRole: models.ROLE_VIEWER, Login: warmupLogin}
+
+	var scopes []string
+	seen := map[string]bool{}
+	saved := savedValues(dash.Data)
+	for _, ref := range dataSourceRefs(dash.Data) {
+		ds, err := w.findDataSource(ctx, user, dashboardvariables.Interpolate(ref, saved))
+		if err != nil || ds == nil || seen[ds.Uid] {
+			continue
+		}
+		seen[ds.Uid] = true
+		scopes = append(scopes, datasources.ScopeProvider.GetResourceScopeUID(ds.Uid))
+	}
+
+	user.Permissions = map[int64]map[string][]string{dash.OrgId: {
+		dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.Uid)},
+		datasources.ActionQuery:         scopes,
+	}}
+	return user
+}
+
+// panelRequest returns the queries of the panel over the time range of the
+// variable values, with the variables interpolated. It returns false when the
+// panel can't be warmed up.
+func (w *CacheWarmer) panelRequest(ctx context.Context, user *models.SignedInUser, panel map[string]interface{}, values map[string][]string) (dtos.MetricRequest, bool) {
+	if panel["libraryPanel"] != nil || toString(panel["timeFrom"]) != "" || toString(panel["timeShift"]) != "" {
+		return dtos.MetricRequest{}, false
+	}
+	targets, _ := panel["targets"].([]interface{})
+	if len(targets) == 0 || len(values["__from"]) == 0 || len(values["__to"]) == 0 {
+		return dtos.MetricRequest{}, false
+	}
+
+	reqDTO := dtos.MetricRequest{From: values["__from"][0], To: values["__to"][0]}
+	from, _ := strconv.ParseInt(reqDTO.From, 10, 64)
+	to, _ := strconv.ParseInt(reqDTO.To, 10, 64)
+	maxDataPoints := int64(warmupMaxDataPoints)
+	if n := simplejson.NewFromAny(panel).Get("maxDataPoints").MustInt64(); n > 0 {
+		maxDataPoints = n
+	}
+	intervalMs := (to - from) / maxDataPoints
+	if intervalMs < 1 {
+		intervalMs = 1
+	}
+
+	for _, t := range targets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
+			return dtos.MetricRequest{}, false
+		}
+		if hide, _ := target["hide"].(bool); hide {
+			continue
+		}
+		ref := target["datasource"]
+		if ref == nil {
+			ref = panel["datasource"]
+		}
+		ds, ok := w.queryDataSource(ctx, user, dashboardvariables.Interpolate(dataSourceRef(ref), values))
+		if !ok {
+			return dtos.MetricRequest{}, false
+		}
+
+		model := make(map[string]interface{}, len(target)+3)
+		for k, v := range target {
+			if k != "hide" {
+				model[k] = dashboardvariables.InterpolateValue(v, values)
+			}
+		}
+		if _, ok := model["refId"]; !ok {
+			model["refId"] = "A"
+		}
+		model["datasource"] = map[string]interface{}{"uid": ds.Uid, "type": ds.Type}
+		model["maxDataPoints"] = maxDataPoints
+		model["intervalMs"] = intervalMs
+		reqDTO.Queries = append(reqDTO.Queries, simplejson.NewFromAny(model))
+	}
+	return reqDTO, len(reqDTO.Queries) > 0
+}
+
+// queryDataSource returns the data source of a query if the user is allowed to
+// query it, expressions are returned as is.
+func (w *CacheWarmer) queryDataSource(ctx context.Context, user *models.SignedInUser, ref string) (*models.DataSource, bool) {
+	if expr.IsDataSource(ref) {
+		return &models.DataSource{Uid: expr.DatasourceUID, Type: expr.DatasourceType}, true
+	}
+	if builtInDataSources[ref] {
+		return nil, false
+	}
+	ds, err := w.findDataSource(ctx, user, ref)
+	if err != nil || ds == nil {
+		return nil, false
+	}
+	evaluator := accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ds.Uid))
+	if ok, err := w.ac.Evaluate(ctx, user, evaluator); err != nil || !ok {
+		return nil, false
+	}
+	return ds, true
+}
+
+// findDataSource finds a data source by uid or name, an empty reference means
+// the default data source.
+func (w *CacheWarmer) findDataSource(ctx context.Context, user *models.SignedInUser, ref string) (*models.DataSource, error) {
+	if builtInDataSources[ref] || expr.IsDataSource(ref) {
+		return nil, nil
+	}
+	if ref == "" || ref == "default" {
+		query := &models.GetDefaultDataSourceQuery{OrgId: user.OrgId, User: user}
+		if err := w.dataSourceService.GetDefaultDataSource(ctx, query); err != nil {
+			return nil, err
+		}
+		return query.Result, nil
+	}
+
+	query := &models.GetDataSourceQuery{Uid: ref, OrgId: user.OrgId}
+	if err := w.dataSourceService.GetDataSource(ctx, query); err == nil {
+		return query.Result, nil
+	}
+	query = &models.GetDataSourceQuery{Name: ref, OrgId: user.OrgId}
+	if err := w.dataSourceService.GetDataSource(ctx, query); err != nil {
+		return nil, fmt.Errorf("data source %q: %w", ref, err)
+	}
+	return query.Result, nil
+}
+
+// dashboardPanels returns the panels of the dashboard, including the panels of
+// collapsed rows, but not the rows themselves.
+func dashboardPanels(data *simplejson.Json) []map[string]interface{} {
+	var panels []map[string]interface{}
+	var collect func(list []interface{})
+	collect = func(list []interface{}) {
+		for _, p := range list {
+			panel, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				collect(nested)
+			}
+			if panel["type"] != "row" {
+				panels = append(panels, panel)
+			}
+		}
+	}
+	collect(data.Get("panels").MustArray())
+	return panels
+}
+
+// dataSourceRefs returns the data source references of the panels, queries and
+// template variables of the dashboard.
+func dataSourceRefs(data *simplejson.Json) []string {
+	var refs []string
+	for _, panel := range dashboardPanels(data) {
+		targets, _ := panel["targets"].([]interface{})
+		for _, t := range targets {
+			ref := panel["datasource"]
+			if target, ok := t.(map[string]interface{}); ok && target["datasource"] != nil {
+				ref = target["datasource"]
+			}
+			refs = append(refs, dataSourceRef(ref))
+		}
+	}
+	for _, v := range data.Get("templating").Get("list").MustArray() {
+		if variable, ok := v.(map[string]interface{}); ok && variable["type"] == "query" {
+			refs = append(refs, dataSourceRef(variable["datasource"]))
+		}
+	}
+	return refs
+}
+
+// dataSourceRef returns the uid or name of a data source reference.
+func dataSourceRef(ref interface{}) string {
+	return (&variableModel{Datasource: ref}).datasourceString()
+}
+
+// savedValues returns the values saved with the template variables of the dashboard.
+func savedValues(data *simplejson.Json) map[string][]string {
+	values := map[string][]string{}
+	variables, err := parseVariables(data)
+	if err != nil {
+		return values
+	}
+	for _, v := range variables {
+		values[v.Name] = toStrings(v.Current.Value)
+	}
+	return values
+}
+
+func successful(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,204 @@
+package varsimpl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCacheWarmer_nextRun(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2022, time.June, day, hour, min, 0, 0, time.UTC)
+	}
+	newWarmer := func(start, end time.Duration) *CacheWarmer {
+		cfg := setting.NewCfg()
+		cfg.DashboardCacheWarmup = setting.DashboardCacheWarmupSettings{
+			Enabled:       true,
+			TopDashboards: 10,
+			WindowStart:   start,
+			WindowEnd:     end,
+			CacheTTL:      4 * time.Hour,
+		}
+		return &CacheWarmer{cfg: cfg}
+	}
+
+	t.Run("without a window runs on startup and every cache ttl", func(t *testing.T) {
+		w := newWarmer(0, 0)
+		require.Equal(t, at(1, 10, 0), w.nextRun(at(1, 10, 0), time.Time{}))
+		require.Equal(t, at(1, 14, 0), w.nextRun(at(1, 10, 5), at(1, 10, 0)))
+	})
+
+	t.Run("waits for the start of the window", func(t *testing.T) {
+		w := newWarmer(2*time.Hour, 5*time.Hour)
+		require.Equal(t, at(2, 2, 0), w.nextRun(at(1, 10, 0), time.Time{}))
+		require.Equal(t, at(1, 2, 0), w.nextRun(at(1, 1, 0), time.Time{}))
+	})
+
+	t.Run("runs right away within the window", func(t *testing.T) {
+		w := newWarmer(2*time.Hour, 5*time.Hour)
+		require.Equal(t, at(1, 3, 0), w.nextRun(at(1, 3, 0), time.Time{}))
+	})
+
+	t.Run("runs once per window", func(t *testing.T) {
+		w := newWarmer(2*time.Hour, 5*time.Hour)
+		require.Equal(t, at(2, 2, 0), w.nextRun(at(1, 3, 30), at(1, 3, 0)))
+	})
+
+	t.Run("supports windows ending after midnight", func(t *testing.T) {
+		w := newWarmer(23*time.Hour, 2*time.Hour)
+		require.Equal(t, at(1, 1, 0), w.nextRun(at(1, 1, 0), time.Time{}))
+		require.Equal(t, at(1, 23, 0), w.nextRun(at(1, 1, 30), at(1, 1, 0)))
+		require.Equal(t, at(1, 23, 0), w.nextRun(at(1, 12, 0), time.Time{}))
+	})
+}
+
+type fakeUsage struct {
+	dashboardusage.Service
+	popular []*dashboardusage.PopularDashboard
+	query   *dashboardusage.GetMostViewedQuery
+}
+
+func (f *fakeUsage) GetMostViewed(_ context.Context, query *dashboardusage.GetMostViewedQuery) ([]*dashboardusage.PopularDashboard, error) {
+	f.query = query
+	return f.popular, nil
+}
+
+type fakeDashboards struct {
+	dashboards.DashboardService
+	dashboards map[string]*models.Dashboard
+}
+
+func (f *fakeDashboards) GetDashboard(_ context.Context, query *models.GetDashboardQuery) error {
+	dash, ok := f.dashboards[query.Uid]
+	if !ok || dash.OrgId != query.OrgId {
+		return models.ErrDashboardNotFound
+	}
+	query.Result = dash
+	return nil
+}
+
+type fakeVariables struct {
+	dashboardvariables.Service
+	resolved []string
+	users    []*models.SignedInUser
+	cmds     []dashboardvariables.ResolveVariablesCommand
+	from, to time.Time
+}
+
+func (f *fakeVariables) InterpolationValues(_ context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd dashboardvariables.ResolveVariablesCommand) (map[string][]string, error) {
+	if user.OrgId != dashboard.OrgId {
+		return nil, models.ErrDashboardNotFound
+	}
+	f.resolved = append(f.resolved, dashboard.Uid)
+	f.users = append(f.users, user)
+	f.cmds = append(f.cmds, cmd)
+	return map[string][]string{
+		"__from": {strconv.FormatInt(f.from.UnixMilli(), 10)},
+		"__to":   {strconv.FormatInt(f.to.UnixMilli(), 10)},
+		"job":    {"api"},
+	}, nil
+}
+
+type fakeQueryRunner struct {
+	requests []dtos.MetricRequest
+}
+
+func (f *fakeQueryRunner) QueryDataMultipleSources(_ context.Context, _ *models.SignedInUser, _ bool, reqDTO dtos.MetricRequest, _ bool) (*backend.QueryDataResponse, error) {
+	f.requests = append(f.requests, reqDTO)
+	return backend.NewQueryDataResponse(), nil
+}
+
+func TestCacheWarmer_warmup(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DashboardCacheWarmup = setting.DashboardCacheWarmupSettings{Enabled: true, TopDashboards: 3, CacheTTL: time.Hour}
+	usage := &fakeUsage{popular: []*dashboardusage.PopularDashboard{
+		{OrgID: 1, UID: "a", Views: 30},
+		{OrgID: 2, UID: "b", Views: 20},
+		{OrgID: 1, UID: "missing", Views: 10},
+	}}
+	now := time.Now()
+	variables := &fakeVariables{from: now.Add(-6 * time.Hour), to: now}
+	queries := &fakeQueryRunner{}
+	panelCache := query.ProvidePanelCache(localcache.New(0, 0))
+	ac := acmock.New()
+	ac.EvaluateFunc = func(_ context.Context, user *models.SignedInUser, evaluator accesscontrol.Evaluator) (bool, error) {
+		return evaluator.Evaluate(user.Permissions[user.OrgId]), nil
+	}
+	w := &CacheWarmer{
+		cfg:   cfg,
+		usage: usage,
+		dashboards: &fakeDashboards{dashboards: map[string]*models.Dashboard{
+			"a": {OrgId: 1, Uid: "a", Version: 2, Data: simplejson.NewFromAny(map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "datasource": map[string]interface{}{"uid": "prom"}, "targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": `up{job="$job"}`},
+					}},
+					map[string]interface{}{"id": 2, "timeShift": "1d", "datasource": "prom", "targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": "up"},
+					}},
+				},
+			})},
+			"b": {OrgId: 2, Uid: "b", Version: 1, Data: simplejson.NewFromAny(map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "datasource": "other-org", "targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": "up"},
+					}},
+				},
+			})},
+		}},
+		variables: variables,
+		dataSourceService: &datasourcesfakes.FakeDataSourceService{DataSources: []*models.DataSource{
+			{OrgId: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus"},
+		}},
+		queries:    queries,
+		panelCache: panelCache,
+		ac:         ac,
+		log:        log.New("test"),
+		now:        time.Now,
+	}
+
+	w.warmup(context.Background())
+
+	require.Equal(t, 3, usage.query.Limit)
+	require.Equal(t, []string{"a", "b"}, variables.resolved)
+	for _, cmd := range variables.cmds {
+		require.True(t, cmd.SkipCache)
+		require.Equal(t, time.Hour, cmd.CacheTTL)
+	}
+
+	t.Run("warms up as a viewer of the dashboard", func(t *testing.T) {
+		viewer := variables.users[0]
+		require.Equal(t, models.ROLE_VIEWER, viewer.OrgRole)
+		require.Equal(t, []string{"dashboards:uid:a"}, viewer.Permissions[1][dashboards.ActionDashboardsRead])
+		require.Equal(t, []string{"datasources:uid:prom"}, viewer.Permissions[1][datasources.ActionQuery])
+	})
+
+	t.Run("caches the panel queries with the variables interpolated", func(t *testing.T) {
+		require.Len(t, queries.requests, 1)
+		require.Equal(t, `up{job="api"}`, queries.requests[0].Queries[0].Get("expr").MustString())
+
+		_, ok := panelCache.Get(query.PanelCacheKey{OrgID: 1, DashboardUID: "a", DashboardVersion: 2, PanelID: 1}, queries.requests[0])
+		require.True(t, ok)
+		_, ok = panelCache.Get(query.PanelCacheKey{OrgID: 1, DashboardUID: "a", DashboardVersion: 2, PanelID: 2}, queries.requests[0])
+		require.False(t, ok)
+	})
+}
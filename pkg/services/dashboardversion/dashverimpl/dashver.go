@@ -3,6 +3,8 @@ package dashverimpl
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
 	"github.com/grafana/grafana/pkg/setting"
@@ -15,14 +17,19 @@ const (
 
 type Service struct {
 	store store
+	log   log.Logger
+	// bodies is nil when the dashboard bodies are kept in the database.
+	bodies bodystorage.Backend
 }
 
-func ProvideService(db db.DB) dashver.Service {
+func ProvideService(cfg *setting.Cfg, db db.DB) dashver.Service {
 	return &Service{
 		store: &sqlStore{
 			db:      db,
 			dialect: db.GetDialect(),
 		},
+		log:    log.New("dashboard-version"),
+		bodies: bodystorage.New(cfg.DashboardStorage),
 	}
 }
 
@@ -31,6 +38,9 @@ func (s *Service) Get(ctx context.Context, query *dashver.GetDashboardVersionQue
 	if err != nil {
 		return nil, err
 	}
+	if version.Data, err = bodystorage.Load(ctx, s.bodies, version.Data); err != nil {
+		return nil, err
+	}
 	version.Data.Set("id", version.DashboardID)
 	return version, nil
 }
@@ -51,11 +61,26 @@ func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredV
 			return nil
 		}
 
+		bodyKeys := []string{}
+		if s.bodies != nil {
+			var err error
+			if bodyKeys, err = s.store.GetBodyKeys(ctx, versionIdsToDelete); err != nil {
+				return err
+			}
+		}
+
 		deleted, err := s.store.DeleteBatch(ctx, cmd, versionIdsToDelete)
 		if err != nil {
 			return err
 		}
 
+		// the bodies of the deleted versions are deleted once their rows are
+		for _, key := range bodyKeys {
+			if err := s.bodies.Delete(ctx, key); err != nil {
+				s.log.Warn("Failed to delete the body of an expired dashboard version", "key", key, "error", err)
+			}
+		}
+
 		cmd.DeletedRows += deleted
 
 		if deleted < int64(maxVersionsToDeletePerBatch) {
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -25,6 +27,23 @@ func TestDashboardVersionService(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, dashboardVersion, dashboard)
 	})
+
+	t.Run("Get dashboard version whose body is kept in the body storage", func(t *testing.T) {
+		bodies := bodystorage.New(setting.DashboardStorageSettings{Type: setting.DashboardStorageFilesystem, FilesystemPath: t.TempDir()})
+		service := Service{store: dashboardVersionStore, bodies: bodies}
+		key := bodystorage.VersionKey(1, "dash", 2)
+		require.NoError(t, bodies.Put(context.Background(), key, []byte(`{"title":"Dash","panels":[{"id":1}]}`)))
+
+		dashboardVersionStore.ExpectedDashboardVersion = &dashver.DashboardVersion{
+			ID:          12,
+			DashboardID: 3,
+			Data:        simplejson.NewFromAny(map[string]interface{}{"title": "Dash", bodystorage.StubField: map[string]interface{}{"key": key}}),
+		}
+		dashboardVersion, err := service.Get(context.Background(), &dashver.GetDashboardVersionQuery{})
+		require.NoError(t, err)
+		require.Len(t, dashboardVersion.Data.Get("panels").MustArray(), 1)
+		require.Equal(t, int64(3), dashboardVersion.Data.Get("id").MustInt64())
+	})
 }
 
 func TestDeleteExpiredVersions(t *testing.T) {
@@ -46,6 +65,21 @@ func TestDeleteExpiredVersions(t *testing.T) {
 		require.Nil(t, err)
 	})
 
+	t.Run("Clean up the bodies of old dashboard versions kept in the body storage", func(t *testing.T) {
+		bodies := bodystorage.New(setting.DashboardStorageSettings{Type: setting.DashboardStorageFilesystem, FilesystemPath: t.TempDir()})
+		service := Service{store: dashboardVersionStore, bodies: bodies, log: log.NewNopLogger()}
+		key := bodystorage.VersionKey(1, "dash", 1)
+		require.NoError(t, bodies.Put(context.Background(), key, []byte(`{}`)))
+
+		dashboardVersionStore.ExptectedDeletedVersions = 1
+		dashboardVersionStore.ExpectedVersions = []interface{}{1}
+		dashboardVersionStore.ExpectedBodyKeys = []string{key}
+		err := service.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
+		require.NoError(t, err)
+		_, err = bodies.Get(context.Background(), key)
+		require.ErrorIs(t, err, bodystorage.ErrBodyNotFound)
+	})
+
 	t.Run("Clean up old dashboard versions with error", func(t *testing.T) {
 		dashboardVersionStore.ExpectedError = errors.New("some error")
 		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
@@ -71,6 +105,7 @@ type FakeDashboardVersionStore struct {
 	ExptectedDeletedVersions int64
 	ExpectedVersions         []interface{}
 	ExpectedListVersions     []*dashver.DashboardVersionDTO
+	ExpectedBodyKeys         []string
 	ExpectedError            error
 }
 
@@ -90,6 +125,10 @@ func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashve
 	return f.ExptectedDeletedVersions, f.ExpectedError
 }
 
+func (f *FakeDashboardVersionStore) GetBodyKeys(ctx context.Context, versionIdsToDelete []interface{}) ([]string, error) {
+	return f.ExpectedBodyKeys, f.ExpectedError
+}
+
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	return f.ExpectedListVersions, f.ExpectedError
 }
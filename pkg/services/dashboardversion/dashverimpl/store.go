@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
@@ -14,6 +15,7 @@ type store interface {
 	Get(context.Context, *dashver.GetDashboardVersionQuery) (*dashver.DashboardVersion, error)
 	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, int) ([]interface{}, error)
 	DeleteBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, []interface{}) (int64, error)
+	GetBodyKeys(context.Context, []interface{}) ([]string, error)
 	List(context.Context, *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error)
 }
 
@@ -79,6 +81,25 @@ func (ss *sqlStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredV
 	return deleted, err
 }
 
+// GetBodyKeys returns the keys of the bodies of the versions with the ids that
+// are kept in the body storage.
+func (ss *sqlStore) GetBodyKeys(ctx context.Context, versionIds []interface{}) ([]string, error) {
+	keys := []string{}
+	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var versions []*dashver.DashboardVersion
+		if err := sess.In("id", versionIds...).Cols("data").Find(&versions); err != nil {
+			return err
+		}
+		for _, version := range versions {
+			if key, ok := bodystorage.StubKey(version.Data); ok {
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
 func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	var dashboardVersion []*dashver.DashboardVersionDTO
 	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/bodystorage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
@@ -85,6 +86,33 @@ func TestIntegrationDeleteExpiredVersions(t *testing.T) {
 	})
 }
 
+func TestIntegrationGetBodyKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	dashVerStore := sqlStore{db: ss}
+	insertTestDashboard(t, ss, "test dash 61", 1, 0, false)
+
+	key := bodystorage.VersionKey(1, "stored", 1)
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(&dashver.DashboardVersion{
+			DashboardID: 99,
+			Version:     1,
+			Created:     time.Now(),
+			Data:        simplejson.NewFromAny(map[string]interface{}{bodystorage.StubField: map[string]interface{}{"key": key}}),
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Run("Returns the keys of the bodies kept in the body storage", func(t *testing.T) {
+		keys, err := dashVerStore.GetBodyKeys(context.Background(), []interface{}{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{key}, keys)
+	})
+}
+
 func TestIntegrationListDashboardVersions(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
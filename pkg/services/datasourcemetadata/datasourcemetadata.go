@@ -0,0 +1,15 @@
+package datasourcemetadata
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service returns the schema metadata of data sources, such as tables, metrics
+// and label keys, for query builders and template variables. The metadata is
+// served by the data source plugins and cached, so that editing a panel does
+// not query the data source for its metadata every time.
+type Service interface {
+	GetMetadata(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query MetadataQuery) (*Metadata, error)
+}
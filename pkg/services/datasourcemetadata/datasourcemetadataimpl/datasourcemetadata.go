@@ -0,0 +1,183 @@
+package datasourcemetadataimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// refreshTimeout bounds how long a background refresh can take, since it
+// isn't bound to the request that triggered it.
+const refreshTimeout = time.Minute
+
+func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pluginContextProvider *plugincontext.Provider,
+	oauthTokenService oauthtoken.OAuthTokenService, cacheService *localcache.CacheService,
+	queryPolicy querypolicy.Service) datasourcemetadata.Service {
+	return &Service{
+		cfg:                   cfg,
+		pluginClient:          pluginClient,
+		pluginContextProvider: pluginContextProvider,
+		oauthTokenService:     oauthTokenService,
+		cache:                 cacheService,
+		queryPolicy:           queryPolicy,
+		log:                   log.New("datasourcemetadata"),
+		now:                   time.Now,
+	}
+}
+
+type Service struct {
+	cfg                   *setting.Cfg
+	pluginClient          plugins.Client
+	pluginContextProvider *plugincontext.Provider
+	oauthTokenService     oauthtoken.OAuthTokenService
+	cache                 *localcache.CacheService
+	queryPolicy           querypolicy.Service
+	// fetches coalesces the concurrent fetches of the same metadata.
+	fetches singleflight.Group
+	log     log.Logger
+	now     func() time.Time
+}
+
+// GetMetadata returns the cached metadata while it is fresh. Metadata older than
+// the refresh interval is still returned, and refreshed in the background so
+// that the next request gets the new metadata without waiting for the data
+// source. Metadata is only fetched while the caller waits when it isn't cached,
+// or when the cache is skipped.
+func (s *Service) GetMetadata(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, query datasourcemetadata.MetadataQuery) (*datasourcemetadata.Metadata, error) {
+	if !datasourcemetadata.ValidKind(query.Kind) {
+		return nil, datasourcemetadata.ErrInvalidKind
+	}
+	// the metadata is fetched with a resource call of the data source plugin
+	if err := s.queryPolicy.ValidateDataSourceCall(ctx, user); err != nil {
+		return nil, err
+	}
+
+	resourceURL := datasourcemetadata.ResourcePath + "/" + query.Kind
+	if len(query.Params) > 0 {
+		resourceURL += "?" + query.Params.Encode()
+	}
+	oauthPassThru := s.oauthTokenService.IsOAuthPassThruEnabled(ds)
+	cacheKey := fmt.Sprintf("datasource-metadata-%d-%s-%d-%s", ds.OrgId, ds.Uid, ds.Version, resourceURL)
+	if oauthPassThru {
+		cacheKey += fmt.Sprintf("-%d", user.UserId)
+	}
+
+	if cached, ok := s.cache.Get(cacheKey); ok && !query.SkipCache {
+		metadata := cached.(*datasourcemetadata.Metadata)
+		if interval := s.refreshInterval(ds); interval > 0 && s.now().Sub(metadata.FetchedAt) >= interval {
+			s.refresh(user, ds, resourceURL, cacheKey, oauthPassThru)
+		}
+		return metadata, nil
+	}
+
+	result, err, _ := s.fetches.Do(cacheKey, func() (interface{}, error) {
+		return s.fetchAndCache(ctx, user, ds, resourceURL, cacheKey, oauthPassThru)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*datasourcemetadata.Metadata), nil
+}
+
+func (s *Service) refresh(user *models.SignedInUser, ds *models.DataSource, resourceURL, cacheKey string, oauthPassThru bool) {
+	s.fetches.DoChan(cacheKey, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		metadata, err := s.fetchAndCache(ctx, user, ds, resourceURL, cacheKey, oauthPassThru)
+		if err != nil {
+			// the metadata that is cached keeps being returned until it expires
+			s.log.Warn("Failed to refresh data source metadata", "datasource", ds.Uid, "url", resourceURL, "error", err)
+		}
+		return metadata, err
+	})
+}
+
+func (s *Service) fetchAndCache(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, resourceURL, cacheKey string, oauthPassThru bool) (*datasourcemetadata.Metadata, error) {
+	metadata, err := s.fetch(ctx, user, ds, resourceURL, oauthPassThru)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl := s.cfg.DataSourceMetadataCacheTTL; ttl > 0 {
+		// metadata refreshed less often than it expires would never be refreshed
+		if interval := s.refreshInterval(ds); interval > ttl {
+			ttl = interval
+		}
+		s.cache.Set(cacheKey, metadata, ttl)
+	}
+	return metadata, nil
+}
+
+// fetch calls the metadata resource of the data source plugin.
+func (s *Service) fetch(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, resourceURL string, oauthPassThru bool) (*datasourcemetadata.Metadata, error) {
+	pCtx, found, err := s.pluginContextProvider.GetWithDataSource(ctx, ds.Type, user, ds)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, datasourcemetadata.ErrUnsupportedDataSource
+	}
+
+	headers := map[string][]string{}
+	if oauthPassThru {
+		if token := s.oauthTokenService.GetCurrentOAuthToken(ctx, user); token != nil {
+			headers["Authorization"] = []string{fmt.Sprintf("%s %s", token.Type(), token.AccessToken)}
+		}
+	}
+
+	fetchedAt := s.now()
+	sender := &adapters.ResourceResponse{}
+	err = s.pluginClient.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          strings.SplitN(resourceURL, "?", 2)[0],
+		Method:        http.MethodGet,
+		URL:           resourceURL,
+		Headers:       headers,
+	}, sender)
+	if err != nil {
+		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+			return nil, datasourcemetadata.ErrUnsupportedDataSource
+		}
+		return nil, err
+	}
+	if sender.Status == http.StatusNotFound {
+		return nil, datasourcemetadata.ErrUnsupportedDataSource
+	}
+	if sender.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("data source responded with status %d: %s", sender.Status, sender.Body)
+	}
+
+	items := make([]datasourcemetadata.Item, 0)
+	if err := json.Unmarshal(sender.Body, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return &datasourcemetadata.Metadata{Items: items, FetchedAt: fetchedAt}, nil
+}
+
+// refreshInterval returns the refresh interval of the data source, which can
+// override the instance wide interval.
+func (s *Service) refreshInterval(ds *models.DataSource) time.Duration {
+	if interval := datasourcemetadata.RefreshIntervalFromJSONData(ds.JsonData); interval > 0 {
+		return interval
+	}
+	return s.cfg.DataSourceMetadataRefreshInterval
+}
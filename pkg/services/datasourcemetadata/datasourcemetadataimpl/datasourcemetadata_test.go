@@ -0,0 +1,101 @@
+package datasourcemetadataimpl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasourcemetadata"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/querypolicy/querypolicytest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestGetMetadata(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestService(now)
+	user := &models.SignedInUser{OrgId: 1, UserId: 2}
+	ds := &models.DataSource{OrgId: 1, Uid: "pg", Type: "postgres", Version: 2, JsonData: simplejson.New()}
+
+	cached := &datasourcemetadata.Metadata{
+		Items:     []datasourcemetadata.Item{{Value: "id", Text: "id", Description: "integer"}},
+		FetchedAt: now.Add(-time.Minute),
+	}
+	svc.cache.Set(cacheKeyFor(ds, "metadata/columns?table=users"), cached, time.Hour)
+
+	t.Run("fresh metadata is returned from the cache", func(t *testing.T) {
+		metadata, err := svc.GetMetadata(context.Background(), user, ds, datasourcemetadata.MetadataQuery{
+			Kind:   "columns",
+			Params: url.Values{"table": []string{"users"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, cached, metadata)
+	})
+
+	t.Run("cached metadata is not returned when the query policy denies data source calls", func(t *testing.T) {
+		svc.queryPolicy = &querypolicytest.FakeQueryPolicyService{ExpectedDataSourceCallError: querypolicy.ErrDataSourceCallNotAllowed}
+		t.Cleanup(func() { svc.queryPolicy = querypolicytest.NewQueryPolicyServiceFake() })
+
+		_, err := svc.GetMetadata(context.Background(), user, ds, datasourcemetadata.MetadataQuery{
+			Kind:   "columns",
+			Params: url.Values{"table": []string{"users"}},
+		})
+		require.ErrorIs(t, err, querypolicy.ErrDataSourceCallNotAllowed)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		for _, kind := range []string{"", "../health", "tables?x=1"} {
+			_, err := svc.GetMetadata(context.Background(), user, ds, datasourcemetadata.MetadataQuery{Kind: kind})
+			require.ErrorIs(t, err, datasourcemetadata.ErrInvalidKind)
+		}
+	})
+}
+
+func TestRefreshInterval(t *testing.T) {
+	svc := newTestService(time.Now())
+
+	require.Equal(t, 5*time.Minute, svc.refreshInterval(&models.DataSource{}))
+	require.Equal(t, 5*time.Minute, svc.refreshInterval(&models.DataSource{
+		JsonData: simplejson.NewFromAny(map[string]interface{}{"metadataRefreshInterval": "soon"}),
+	}))
+	require.Equal(t, time.Hour, svc.refreshInterval(&models.DataSource{
+		JsonData: simplejson.NewFromAny(map[string]interface{}{"metadataRefreshInterval": "1h"}),
+	}))
+}
+
+func newTestService(now time.Time) *Service {
+	cfg := setting.NewCfg()
+	cfg.DataSourceMetadataCacheTTL = time.Hour
+	cfg.DataSourceMetadataRefreshInterval = 5 * time.Minute
+	return &Service{
+		cfg:               cfg,
+		oauthTokenService: &fakeOAuthTokenService{},
+		queryPolicy:       querypolicytest.NewQueryPolicyServiceFake(),
+		cache:             localcache.New(time.Minute, time.Minute),
+		log:               log.New("test"),
+		now:               func() time.Time { return now },
+	}
+}
+
+func cacheKeyFor(ds *models.DataSource, resourceURL string) string {
+	return fmt.Sprintf("datasource-metadata-%d-%s-%d-%s", ds.OrgId, ds.Uid, ds.Version, resourceURL)
+}
+
+type fakeOAuthTokenService struct{}
+
+func (ts *fakeOAuthTokenService) GetCurrentOAuthToken(context.Context, *models.SignedInUser) *oauth2.Token {
+	return nil
+}
+
+func (ts *fakeOAuthTokenService) IsOAuthPassThruEnabled(*models.DataSource) bool {
+	return false
+}
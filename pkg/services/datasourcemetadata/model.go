@@ -0,0 +1,62 @@
+package datasourcemetadata
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ResourcePath is the resource path data source plugins serve their metadata
+// on, followed by the kind of metadata, e.g. metadata/tables.
+const ResourcePath = "metadata"
+
+var (
+	ErrUnsupportedDataSource = errors.New("data source does not provide metadata")
+	ErrInvalidKind           = errors.New("invalid metadata kind")
+)
+
+var kindPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidKind returns true if the kind can be used in a resource path.
+func ValidKind(kind string) bool {
+	return kindPattern.MatchString(kind)
+}
+
+// MetadataQuery selects a kind of metadata, e.g. tables or labels. Params are
+// passed on to the plugin, e.g. the table to return the columns of.
+type MetadataQuery struct {
+	Kind      string
+	Params    url.Values
+	SkipCache bool
+}
+
+// Metadata is the metadata returned by the data source plugin.
+type Metadata struct {
+	Items []Item `json:"items"`
+	// FetchedAt is when the metadata was returned by the data source, the
+	// metadata can be older than the refresh interval while it is refreshed.
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+type Item struct {
+	Value       string `json:"value"`
+	Text        string `json:"text,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// RefreshIntervalFromJSONData returns the refresh interval configured with the
+// metadataRefreshInterval setting of the data source, e.g. 30m, or zero when it
+// isn't set or invalid.
+func RefreshIntervalFromJSONData(jsonData *simplejson.Json) time.Duration {
+	if jsonData == nil {
+		return 0
+	}
+	interval, err := time.ParseDuration(jsonData.Get("metadataRefreshInterval").MustString())
+	if err != nil || interval < 0 {
+		return 0
+	}
+	return interval
+}
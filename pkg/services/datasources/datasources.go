@@ -29,9 +29,25 @@ type DataSourceService interface {
 	// UpdateDataSource updates an existing datasource.
 	UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSourceCommand) error
 
+	// SetDataSourceReadOnly locks or unlocks a provisioned datasource, the change is
+	// recorded with the user and the reason.
+	SetDataSourceReadOnly(ctx context.Context, cmd *models.SetDataSourceReadOnlyCommand) error
+
+	// GetDataSourceLockEvents gets the recorded lock changes of a datasource.
+	GetDataSourceLockEvents(ctx context.Context, query *models.GetDataSourceLockEventsQuery) error
+
 	// GetDefaultDataSource gets the default datasource.
 	GetDefaultDataSource(ctx context.Context, query *models.GetDefaultDataSourceQuery) error
 
+	// SetDefaultDataSource sets the default datasource of the organization.
+	SetDefaultDataSource(ctx context.Context, cmd *models.SetDefaultDataSourceCommand) error
+
+	// GetDataSourceOrder gets the order of the datasources in the datasource pickers.
+	GetDataSourceOrder(ctx context.Context, query *models.GetDataSourceOrderQuery) error
+
+	// SetDataSourceOrder replaces the order of the datasources in the datasource pickers.
+	SetDataSourceOrder(ctx context.Context, cmd *models.SetDataSourceOrderCommand) error
+
 	// GetHTTPTransport gets a datasource specific HTTP transport.
 	GetHTTPTransport(ctx context.Context, ds *models.DataSource, provider httpclient.Provider, customMiddlewares ...sdkhttpclient.Middleware) (http.RoundTripper, error)
 
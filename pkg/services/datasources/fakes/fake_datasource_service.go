@@ -11,8 +11,9 @@ import (
 )
 
 type FakeDataSourceService struct {
-	lastId      int64
-	DataSources []*models.DataSource
+	lastId          int64
+	DataSources     []*models.DataSource
+	DataSourceOrder []*models.DataSourceGroup
 }
 
 var _ datasources.DataSourceService = &FakeDataSourceService{}
@@ -94,10 +95,53 @@ func (s *FakeDataSourceService) UpdateDataSource(ctx context.Context, cmd *model
 	return models.ErrDataSourceNotFound
 }
 
+func (s *FakeDataSourceService) SetDataSourceReadOnly(ctx context.Context, cmd *models.SetDataSourceReadOnlyCommand) error {
+	for _, datasource := range s.DataSources {
+		if cmd.Uid == datasource.Uid {
+			datasource.ReadOnly = cmd.ReadOnly
+			cmd.Result = datasource
+			return nil
+		}
+	}
+	return models.ErrDataSourceNotFound
+}
+
+func (s *FakeDataSourceService) GetDataSourceLockEvents(ctx context.Context, query *models.GetDataSourceLockEventsQuery) error {
+	query.Result = []*models.DataSourceLockEvent{}
+	return nil
+}
+
 func (s *FakeDataSourceService) GetDefaultDataSource(ctx context.Context, query *models.GetDefaultDataSourceQuery) error {
 	return nil
 }
 
+func (s *FakeDataSourceService) SetDefaultDataSource(ctx context.Context, cmd *models.SetDefaultDataSourceCommand) error {
+	for _, datasource := range s.DataSources {
+		if cmd.Uid == datasource.Uid {
+			cmd.Result = datasource
+		}
+	}
+	if cmd.Result == nil {
+		return models.ErrDataSourceNotFound
+	}
+	for _, datasource := range s.DataSources {
+		if datasource.OrgId == cmd.OrgId {
+			datasource.IsDefault = datasource == cmd.Result
+		}
+	}
+	return nil
+}
+
+func (s *FakeDataSourceService) GetDataSourceOrder(ctx context.Context, query *models.GetDataSourceOrderQuery) error {
+	query.Result = s.DataSourceOrder
+	return nil
+}
+
+func (s *FakeDataSourceService) SetDataSourceOrder(ctx context.Context, cmd *models.SetDataSourceOrderCommand) error {
+	s.DataSourceOrder = cmd.Groups
+	return nil
+}
+
 func (s *FakeDataSourceService) GetHTTPTransport(ctx context.Context, ds *models.DataSource, provider httpclient.Provider, customMiddlewares ...sdkhttpclient.Middleware) (http.RoundTripper, error) {
 	rt, err := provider.GetTransport(sdkhttpclient.Options{})
 	if err != nil {
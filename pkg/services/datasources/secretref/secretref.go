@@ -0,0 +1,303 @@
+// Package secretref resolves datasource secure JSON values that reference
+// credentials kept in an external secret manager, e.g.
+// "vault:kv/data/foo#password", so that the credential itself never has to
+// be stored in Grafana's database.
+package secretref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+var (
+	ErrInvalidReference = errors.New("invalid secret reference")
+	ErrSecretNotFound   = errors.New("secret not found in external secret manager")
+	// ErrReferenceNotAllowed is returned for references outside the paths
+	// allowed for the organization.
+	ErrReferenceNotAllowed = errors.New("secret reference not allowed for organization")
+)
+
+// Resolver fetches a single secret value from an external secret manager.
+type Resolver interface {
+	Resolve(ctx context.Context, path, key string) (string, error)
+}
+
+// Reference points to a key within a secret stored in an external secret
+// manager. Its textual form is "<scheme>:<path>#<key>".
+type Reference struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s:%s#%s", r.Scheme, r.Path, r.Key)
+}
+
+// ParseReference parses a value of the form "<scheme>:<path>#<key>".
+func ParseReference(value string) (Reference, error) {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return Reference{}, ErrInvalidReference
+	}
+	scheme, rest := value[:i], value[i+1:]
+	j := strings.LastIndex(rest, "#")
+	if j < 0 {
+		return Reference{}, ErrInvalidReference
+	}
+	path, key := strings.Trim(rest[:j], "/"), rest[j+1:]
+	if path == "" || key == "" {
+		return Reference{}, ErrInvalidReference
+	}
+	return Reference{Scheme: scheme, Path: path, Key: key}, nil
+}
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+}
+
+// Service resolves secret references using the resolvers registered for
+// their scheme. Resolved values are cached for a configurable TTL, after
+// which they are fetched again so that secrets rotated in the external
+// secret manager are picked up without touching the datasource.
+//
+// Schemes can be restricted to a per organization allowlist of path
+// prefixes, so that an organization can't read secrets meant for another
+// one. References with a restricted scheme are rejected unless their path is
+// below one of the organization's prefixes.
+type Service struct {
+	enabled   bool
+	cacheTTL  time.Duration
+	resolvers map[string]Resolver
+	allowed   map[string]map[int64][]string
+	log       log.Logger
+
+	mu    sync.RWMutex
+	cache map[Reference]cachedSecret
+	now   func() time.Time
+}
+
+// ProvideService returns a Service configured from the [secret_references]
+// section. A nil configuration yields a disabled service that returns all
+// values unchanged.
+func ProvideService(cfg *setting.Cfg) *Service {
+	s := &Service{
+		cacheTTL:  defaultCacheTTL,
+		resolvers: map[string]Resolver{},
+		allowed:   map[string]map[int64][]string{},
+		log:       log.New("datasources.secretref"),
+		cache:     map[Reference]cachedSecret{},
+		now:       time.Now,
+	}
+	if cfg == nil || cfg.Raw == nil {
+		return s
+	}
+
+	sec := cfg.Raw.Section("secret_references")
+	s.enabled = sec.Key("enabled").MustBool(false)
+	s.cacheTTL = sec.Key("cache_ttl").MustDuration(defaultCacheTTL)
+
+	vaultSec := cfg.Raw.Section("secret_references.vault")
+	s.RestrictScheme(VaultScheme)
+	for _, entry := range util.SplitString(vaultSec.Key("allowed_paths").String()) {
+		orgID, prefix, err := parseAllowedPath(entry)
+		if err != nil {
+			s.log.Error("Ignoring invalid vault allowed path", "entry", entry, "error", err)
+			continue
+		}
+		s.AllowPath(VaultScheme, orgID, prefix)
+	}
+	if vaultSec.Key("url").String() != "" {
+		r, err := NewVaultResolver(vaultSec)
+		if err != nil {
+			s.log.Error("Failed to configure vault secret resolver", "error", err)
+		} else {
+			s.RegisterResolver(VaultScheme, r)
+		}
+	}
+
+	return s
+}
+
+// parseAllowedPath parses an allowlist entry of the form "<org id>:<prefix>".
+func parseAllowedPath(entry string) (int64, string, error) {
+	i := strings.Index(entry, ":")
+	if i <= 0 {
+		return 0, "", fmt.Errorf("expected <org id>:<path prefix>")
+	}
+	orgID, err := strconv.ParseInt(entry[:i], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid org id: %w", err)
+	}
+	prefix := strings.Trim(entry[i+1:], "/")
+	if prefix == "" {
+		return 0, "", fmt.Errorf("empty path prefix")
+	}
+	return orgID, prefix, nil
+}
+
+// IsEnabled returns whether secret references are resolved at all.
+func (s *Service) IsEnabled() bool {
+	return s.enabled
+}
+
+// RegisterResolver registers the resolver used for references with the
+// given scheme, replacing any previously registered one.
+func (s *Service) RegisterResolver(scheme string, r Resolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolvers[scheme] = r
+}
+
+// RestrictScheme restricts references with the given scheme to the paths
+// allowed with AllowPath. Until a path is allowed for an organization, all
+// its references with that scheme are rejected.
+func (s *Service) RestrictScheme(scheme string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.allowed[scheme]; !ok {
+		s.allowed[scheme] = map[int64][]string{}
+	}
+}
+
+// AllowPath allows the organization to reference secrets with the given
+// scheme below prefix, restricting the scheme if it wasn't already.
+func (s *Service) AllowPath(scheme string, orgID int64, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.allowed[scheme]; !ok {
+		s.allowed[scheme] = map[int64][]string{}
+	}
+	s.allowed[scheme][orgID] = append(s.allowed[scheme][orgID], strings.Trim(prefix, "/"))
+}
+
+// IsAllowed returns whether the organization may use the reference.
+func (s *Service) IsAllowed(orgID int64, ref Reference) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	orgs, restricted := s.allowed[ref.Scheme]
+	if !restricted {
+		return true
+	}
+	for _, prefix := range orgs[orgID] {
+		if ref.Path == prefix || strings.HasPrefix(ref.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateValues returns ErrReferenceNotAllowed if any of the values is a
+// reference the organization isn't allowed to use. It is meant to be called
+// before storing datasource secure JSON data.
+func (s *Service) ValidateValues(orgID int64, values map[string]string) error {
+	for k, v := range values {
+		if !s.IsReference(v) {
+			continue
+		}
+		ref, err := ParseReference(v)
+		if err != nil {
+			return err
+		}
+		if !s.IsAllowed(orgID, ref) {
+			return fmt.Errorf("%w: %q references %s", ErrReferenceNotAllowed, k, ref.Path)
+		}
+	}
+	return nil
+}
+
+// IsReference returns whether value is a reference with a registered scheme.
+// Values that merely look like references but use an unknown scheme are
+// treated as plain secrets.
+func (s *Service) IsReference(value string) bool {
+	if !s.enabled {
+		return false
+	}
+	ref, err := ParseReference(value)
+	if err != nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.resolvers[ref.Scheme]
+	return ok
+}
+
+// ResolveValues returns a copy of values where every reference has been
+// replaced with the secret it points to.
+func (s *Service) ResolveValues(ctx context.Context, orgID int64, values map[string]string) (map[string]string, error) {
+	if !s.enabled {
+		return values, nil
+	}
+
+	resolved := make(map[string]string, len(values))
+	for k, v := range values {
+		if !s.IsReference(v) {
+			resolved[k] = v
+			continue
+		}
+		secret, err := s.Resolve(ctx, orgID, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret reference for %q: %w", k, err)
+		}
+		resolved[k] = secret
+	}
+	return resolved, nil
+}
+
+// Resolve returns the secret a reference points to, using the cached value
+// while it is younger than the cache TTL. References the organization isn't
+// allowed to use are rejected even if they were stored before the allowlist
+// was changed.
+func (s *Service) Resolve(ctx context.Context, orgID int64, value string) (string, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+	if !s.IsAllowed(orgID, ref) {
+		return "", fmt.Errorf("%w: %s", ErrReferenceNotAllowed, ref.Path)
+	}
+
+	s.mu.RLock()
+	resolver, ok := s.resolvers[ref.Scheme]
+	cached, hit := s.cache[ref]
+	s.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: no resolver registered for scheme %q", ErrInvalidReference, ref.Scheme)
+	}
+
+	if hit && s.now().Sub(cached.fetched) < s.cacheTTL {
+		return cached.value, nil
+	}
+
+	secret, err := resolver.Resolve(ctx, ref.Path, ref.Key)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.cache[ref] = cachedSecret{value: secret, fetched: s.now()}
+	s.mu.Unlock()
+
+	return secret, nil
+}
+
+// Purge drops all cached secrets, forcing them to be fetched again on next
+// use. It is meant to be called after a secret has been rotated.
+func (s *Service) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[Reference]cachedSecret{}
+}
@@ -0,0 +1,182 @@
+package secretref
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeResolver struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (f *fakeResolver) Resolve(_ context.Context, path, key string) (string, error) {
+	f.calls++
+	v, ok := f.secrets[path+"#"+key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func setupService(t *testing.T, raw string) *Service {
+	t.Helper()
+	f, err := ini.Load([]byte(raw))
+	require.NoError(t, err)
+	return ProvideService(&setting.Cfg{Raw: f})
+}
+
+func TestParseReference(t *testing.T) {
+	ref, err := ParseReference("vault:kv/data/foo#password")
+	require.NoError(t, err)
+	assert.Equal(t, Reference{Scheme: "vault", Path: "kv/data/foo", Key: "password"}, ref)
+	assert.Equal(t, "vault:kv/data/foo#password", ref.String())
+
+	for _, v := range []string{"", "secret", "vault:kv/data/foo", ":kv#password", "vault:#password", "vault:kv#"} {
+		_, err := ParseReference(v)
+		assert.ErrorIs(t, err, ErrInvalidReference, v)
+	}
+}
+
+func TestService_ResolveValues(t *testing.T) {
+	t.Run("disabled service returns values unchanged", func(t *testing.T) {
+		s := setupService(t, "")
+		s.RegisterResolver("fake", &fakeResolver{})
+
+		values := map[string]string{"password": "fake:foo#password"}
+		resolved, err := s.ResolveValues(context.Background(), 1, values)
+		require.NoError(t, err)
+		assert.Equal(t, values, resolved)
+	})
+
+	t.Run("references are resolved and plain values are kept", func(t *testing.T) {
+		s := setupService(t, "[secret_references]\nenabled = true\n")
+		s.RegisterResolver("fake", &fakeResolver{secrets: map[string]string{"foo#password": "hunter2"}})
+
+		resolved, err := s.ResolveValues(context.Background(), 1, map[string]string{
+			"password":  "fake:foo#password",
+			"apiKey":    "plain-secret",
+			"lookalike": "unknown:foo#bar",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"password":  "hunter2",
+			"apiKey":    "plain-secret",
+			"lookalike": "unknown:foo#bar",
+		}, resolved)
+	})
+
+	t.Run("missing secrets fail resolution", func(t *testing.T) {
+		s := setupService(t, "[secret_references]\nenabled = true\n")
+		s.RegisterResolver("fake", &fakeResolver{})
+
+		_, err := s.ResolveValues(context.Background(), 1, map[string]string{"password": "fake:foo#password"})
+		require.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+
+	t.Run("resolved values are cached until the ttl expires", func(t *testing.T) {
+		s := setupService(t, "[secret_references]\nenabled = true\ncache_ttl = 1m\n")
+		now := time.Now()
+		s.now = func() time.Time { return now }
+		r := &fakeResolver{secrets: map[string]string{"foo#password": "old"}}
+		s.RegisterResolver("fake", r)
+
+		v, err := s.Resolve(context.Background(), 1, "fake:foo#password")
+		require.NoError(t, err)
+		assert.Equal(t, "old", v)
+
+		r.secrets["foo#password"] = "rotated"
+		v, err = s.Resolve(context.Background(), 1, "fake:foo#password")
+		require.NoError(t, err)
+		assert.Equal(t, "old", v)
+		assert.Equal(t, 1, r.calls)
+
+		now = now.Add(2 * time.Minute)
+		v, err = s.Resolve(context.Background(), 1, "fake:foo#password")
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", v)
+		assert.Equal(t, 2, r.calls)
+	})
+}
+
+func TestVaultResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/foo":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"kv2"},"metadata":{"version":3}}}`))
+		case "/v1/secret/foo":
+			_, _ = w.Write([]byte(`{"data":{"password":"kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := setupService(t, "[secret_references]\nenabled = true\n[secret_references.vault]\nurl = "+srv.URL+"\ntoken = token\nallowed_paths = 1:kv/data 1:secret\n")
+
+	v, err := s.Resolve(context.Background(), 1, "vault:kv/data/foo#password")
+	require.NoError(t, err)
+	assert.Equal(t, "kv2", v)
+
+	v, err = s.Resolve(context.Background(), 1, "vault:secret/foo#password")
+	require.NoError(t, err)
+	assert.Equal(t, "kv1", v)
+
+	_, err = s.Resolve(context.Background(), 1, "vault:secret/foo#username")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = s.Resolve(context.Background(), 1, "vault:secret/bar#password")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestService_AllowedPaths(t *testing.T) {
+	s := setupService(t, "[secret_references]\nenabled = true\n[secret_references.vault]\nallowed_paths = 1:kv/data/team-a/, 2:kv/data/team-b, invalid\n")
+	s.RegisterResolver(VaultScheme, &fakeResolver{secrets: map[string]string{
+		"kv/data/team-a/db#password": "a",
+		"kv/data/team-b#password":    "b",
+	}})
+
+	t.Run("references below the org prefixes are allowed", func(t *testing.T) {
+		require.NoError(t, s.ValidateValues(1, map[string]string{"password": "vault:kv/data/team-a/db#password", "apiKey": "plain"}))
+		require.NoError(t, s.ValidateValues(2, map[string]string{"password": "vault:kv/data/team-b#password"}))
+
+		v, err := s.Resolve(context.Background(), 1, "vault:kv/data/team-a/db#password")
+		require.NoError(t, err)
+		assert.Equal(t, "a", v)
+	})
+
+	t.Run("references outside the org prefixes are rejected", func(t *testing.T) {
+		for orgID, ref := range map[int64]string{
+			1: "vault:kv/data/team-b#password",
+			2: "vault:kv/data/team-bb#password",
+			3: "vault:kv/data/team-a/db#password",
+		} {
+			err := s.ValidateValues(orgID, map[string]string{"password": ref})
+			assert.ErrorIs(t, err, ErrReferenceNotAllowed, ref)
+
+			_, err = s.Resolve(context.Background(), orgID, ref)
+			assert.ErrorIs(t, err, ErrReferenceNotAllowed, ref)
+		}
+	})
+
+	t.Run("unrestricted schemes are allowed for every org", func(t *testing.T) {
+		s.RegisterResolver("fake", &fakeResolver{})
+		require.NoError(t, s.ValidateValues(3, map[string]string{"password": "fake:anything#password"}))
+	})
+}
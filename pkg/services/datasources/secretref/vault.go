@@ -0,0 +1,122 @@
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// VaultScheme is the reference scheme resolved by the HashiCorp Vault
+// key/value secrets engine, e.g. "vault:kv/data/foo#password".
+const VaultScheme = "vault"
+
+type vaultResolver struct {
+	client    *http.Client
+	url       *url.URL
+	token     string
+	namespace string
+}
+
+// NewVaultResolver returns a Resolver reading secrets from HashiCorp Vault
+// key/value engines (both version 1 and 2). It's configured from the
+// [secret_references.vault] section; supported keys are url, token,
+// namespace and timeout. When token is empty, the VAULT_TOKEN environment
+// variable is used.
+func NewVaultResolver(section *ini.Section) (Resolver, error) {
+	u, err := url.Parse(section.Key("url").String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid vault url %q", section.Key("url").String())
+	}
+
+	token := section.Key("token").String()
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if token == "" {
+		return nil, errors.New("missing vault token")
+	}
+
+	return &vaultResolver{
+		client:    &http.Client{Timeout: section.Key("timeout").MustDuration(10 * time.Second)},
+		url:       u,
+		token:     token,
+		namespace: section.Key("namespace").String(),
+	}, nil
+}
+
+type vaultSecretResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []string        `json:"errors"`
+}
+
+// vaultKV2Data is the payload returned by version 2 of the key/value
+// engine, which nests the secret under data.data.
+type vaultKV2Data struct {
+	Data     map[string]interface{} `json:"data"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+func (r *vaultResolver) Resolve(ctx context.Context, secretPath, key string) (string, error) {
+	u := *r.url
+	u.Path = path.Join("/", u.Path, "v1", secretPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.token)
+	if r.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.namespace)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+
+	var payload vaultSecretResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with status %d: %s", res.StatusCode, strings.Join(payload.Errors, ", "))
+	}
+
+	var data map[string]interface{}
+	var kv2 vaultKV2Data
+	if err := json.Unmarshal(payload.Data, &kv2); err == nil && kv2.Data != nil && kv2.Metadata != nil {
+		data = kv2.Data
+	} else if err := json.Unmarshal(payload.Data, &data); err != nil {
+		return "", fmt.Errorf("failed to parse vault secret: %w", err)
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret key %q is not a string", key)
+	}
+	return s, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,18 +15,26 @@ import (
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 
+	"github.com/grafana/grafana/pkg/components/jsonschema"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
 	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+var logger = log.New("datasources")
+
 type Service struct {
 	SQLStore           *sqlstore.SQLStore
 	SecretsStore       kvstore.SecretsKVStore
@@ -33,6 +43,10 @@ type Service struct {
 	features           featuremgmt.FeatureToggles
 	permissionsService accesscontrol.DatasourcePermissionsService
 	ac                 accesscontrol.AccessControl
+	secretRefs         *secretref.Service
+	secretsAudit       audit.Service
+	pluginStore        plugins.Store
+	preferences        pref.Service
 
 	ptc proxyTransportCache
 }
@@ -50,6 +64,7 @@ type cachedRoundTripper struct {
 func ProvideService(
 	store *sqlstore.SQLStore, secretsService secrets.Service, secretsStore kvstore.SecretsKVStore, cfg *setting.Cfg,
 	features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl, datasourcePermissionsService accesscontrol.DatasourcePermissionsService,
+	secretsAudit audit.Service, pluginStore plugins.Store, preferences pref.Service, secretRefs *secretref.Service,
 ) *Service {
 	s := &Service{
 		SQLStore:       store,
@@ -62,6 +77,10 @@ func ProvideService(
 		features:           features,
 		permissionsService: datasourcePermissionsService,
 		ac:                 ac,
+		secretRefs:         secretRefs,
+		secretsAudit:       secretsAudit,
+		pluginStore:        pluginStore,
+		preferences:        preferences,
 	}
 
 	ac.RegisterScopeAttributeResolver(NewNameScopeResolver(store))
@@ -142,6 +161,14 @@ func (s *Service) GetDataSourcesByType(ctx context.Context, query *models.GetDat
 }
 
 func (s *Service) AddDataSource(ctx context.Context, cmd *models.AddDataSourceCommand) error {
+	if err := s.validateSettings(ctx, cmd.Type, cmd.JsonData, cmd.SecureJsonData); err != nil {
+		return err
+	}
+
+	if err := s.secretRefs.ValidateValues(cmd.OrgId, cmd.SecureJsonData); err != nil {
+		return err
+	}
+
 	var err error
 	// this is here for backwards compatibility
 	cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
@@ -209,6 +236,14 @@ func (s *Service) UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSo
 		return err
 	}
 
+	if err := s.validateSettings(ctx, cmd.Type, cmd.JsonData, cmd.SecureJsonData); err != nil {
+		return err
+	}
+
+	if err := s.secretRefs.ValidateValues(cmd.OrgId, cmd.SecureJsonData); err != nil {
+		return err
+	}
+
 	err = s.SQLStore.UpdateDataSource(ctx, cmd)
 	if err != nil {
 		return err
@@ -229,10 +264,114 @@ func (s *Service) UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSo
 	return s.SecretsStore.Set(ctx, cmd.OrgId, cmd.Name, secretType, string(secret))
 }
 
+func (s *Service) SetDataSourceReadOnly(ctx context.Context, cmd *models.SetDataSourceReadOnlyCommand) error {
+	query := &models.GetDataSourceQuery{Uid: cmd.Uid, OrgId: cmd.OrgId}
+	if err := s.SQLStore.GetDataSource(ctx, query); err != nil {
+		return err
+	}
+	// the data sources created with the API are never locked, they have no other source of truth
+	if query.Result.Origin == models.DsOriginAPI {
+		return models.ErrDataSourceNotProvisioned
+	}
+	if !cmd.ReadOnly && strings.TrimSpace(cmd.Reason) == "" {
+		return models.ErrDataSourceUnlockReasonRequired
+	}
+
+	if err := s.SQLStore.SetDataSourceReadOnly(ctx, cmd); err != nil {
+		return err
+	}
+	var login string
+	if cmd.User != nil {
+		login = cmd.User.Login
+	}
+	logger.Info("Data source lock changed", "orgId", cmd.OrgId, "uid", cmd.Uid, "readOnly", cmd.ReadOnly, "user", login, "reason", cmd.Reason)
+	return nil
+}
+
+func (s *Service) GetDataSourceLockEvents(ctx context.Context, query *models.GetDataSourceLockEventsQuery) error {
+	return s.SQLStore.GetDataSourceLockEvents(ctx, query)
+}
+
+// GetDefaultDataSource returns the default data source the user prefers when
+// the query is for a user who has one, the default data source of the
+// organization otherwise.
 func (s *Service) GetDefaultDataSource(ctx context.Context, query *models.GetDefaultDataSourceQuery) error {
+	if query.User != nil && s.preferences != nil {
+		preference, err := s.preferences.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{
+			OrgID:  query.OrgId,
+			UserID: query.User.UserId,
+			Teams:  query.User.Teams,
+		})
+		if err != nil {
+			return err
+		}
+		if uid := preference.JSONData.DefaultDatasourceUID; uid != "" {
+			dsQuery := &models.GetDataSourceQuery{OrgId: query.OrgId, Uid: uid}
+			err := s.SQLStore.GetDataSource(ctx, dsQuery)
+			if err == nil {
+				query.Result = dsQuery.Result
+				return nil
+			}
+			// the preferred data source has been deleted since it was chosen
+			if !errors.Is(err, models.ErrDataSourceNotFound) {
+				return err
+			}
+		}
+	}
 	return s.SQLStore.GetDefaultDataSource(ctx, query)
 }
 
+func (s *Service) SetDefaultDataSource(ctx context.Context, cmd *models.SetDefaultDataSourceCommand) error {
+	if err := s.SQLStore.SetDefaultDataSource(ctx, cmd); err != nil {
+		return err
+	}
+	logger.Info("Default data source changed", "orgId", cmd.OrgId, "uid", cmd.Uid)
+	return nil
+}
+
+func (s *Service) GetDataSourceOrder(ctx context.Context, query *models.GetDataSourceOrderQuery) error {
+	return s.SQLStore.GetDataSourceOrder(ctx, query)
+}
+
+// maxDataSourceGroupNameLength is the length of the group_name column.
+const maxDataSourceGroupNameLength = 190
+
+// SetDataSourceOrder replaces the order of the data sources after checking
+// that every data source of the organization is in at most one group.
+func (s *Service) SetDataSourceOrder(ctx context.Context, cmd *models.SetDataSourceOrderCommand) error {
+	dsQuery := &models.GetDataSourcesQuery{OrgId: cmd.OrgId}
+	if err := s.SQLStore.GetDataSources(ctx, dsQuery); err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(dsQuery.Result))
+	for _, ds := range dsQuery.Result {
+		existing[ds.Uid] = true
+	}
+
+	groups := make(map[string]bool, len(cmd.Groups))
+	listed := make(map[string]bool)
+	for _, group := range cmd.Groups {
+		if len(group.Name) > maxDataSourceGroupNameLength {
+			return fmt.Errorf("%w: the name of group %q is longer than %d characters", models.ErrDataSourceOrderInvalid, group.Name, maxDataSourceGroupNameLength)
+		}
+		if groups[group.Name] {
+			return fmt.Errorf("%w: group %q is listed twice", models.ErrDataSourceOrderInvalid, group.Name)
+		}
+		groups[group.Name] = true
+		for _, uid := range group.Uids {
+			if !existing[uid] {
+				return fmt.Errorf("%w: data source %q not found", models.ErrDataSourceOrderInvalid, uid)
+			}
+			if listed[uid] {
+				return fmt.Errorf("%w: data source %q is listed twice", models.ErrDataSourceOrderInvalid, uid)
+			}
+			listed[uid] = true
+		}
+	}
+
+	return s.SQLStore.SetDataSourceOrder(ctx, cmd)
+}
+
 func (s *Service) GetHTTPClient(ctx context.Context, ds *models.DataSource, provider httpclient.Provider) (*http.Client, error) {
 	transport, err := s.GetHTTPTransport(ctx, ds, provider)
 	if err != nil {
@@ -282,7 +421,36 @@ func (s *Service) GetTLSConfig(ctx context.Context, ds *models.DataSource, httpC
 	return httpClientProvider.GetTLSConfig(*opts)
 }
 
+// DecryptedValues returns the decrypted secure JSON data of a datasource.
+// Values referencing an external secret manager are resolved to the secret
+// they point to. Every access is recorded by the secrets audit service.
 func (s *Service) DecryptedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error) {
+	decryptedValues, err := s.storedValues(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decryptedValues) > 0 {
+		keys := make([]string, 0, len(decryptedValues))
+		for k := range decryptedValues {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		s.secretsAudit.RecordAccess(ctx, &audit.AccessEvent{
+			OrgID:  ds.OrgId,
+			Kind:   audit.KindDatasource,
+			UID:    ds.Uid,
+			Plugin: ds.Type,
+			Keys:   keys,
+		})
+	}
+
+	return s.secretRefs.ResolveValues(ctx, ds.OrgId, decryptedValues)
+}
+
+// storedValues returns the decrypted secure JSON data as stored, without
+// resolving secret references.
+func (s *Service) storedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error) {
 	decryptedValues := make(map[string]string)
 	secret, exist, err := s.SecretsStore.Get(ctx, ds.OrgId, ds.Name, secretType)
 	if err != nil {
@@ -544,8 +712,51 @@ func awsServiceNamespace(dsType string) string {
 	}
 }
 
+// validateSettings checks the settings of a data source against the schema
+// published by its plugin. Secure settings are validated as strings, and the
+// secure settings that are kept on update are included.
+func (s *Service) validateSettings(ctx context.Context, dsType string, jsonData *simplejson.Json, secureJSONData map[string]string) error {
+	if s.pluginStore == nil {
+		return nil
+	}
+	p, exists := s.pluginStore.Plugin(ctx, dsType)
+	if !exists || p.SettingsSchema == nil {
+		return nil
+	}
+
+	var fieldErrors []jsonschema.FieldError
+	if schema := p.SettingsSchema.JSONData; schema != nil {
+		var data interface{} = map[string]interface{}{}
+		if jsonData != nil && jsonData.Interface() != nil {
+			data = jsonData.Interface()
+		}
+		errs, err := schema.Validate("jsonData", data)
+		if err != nil {
+			return fmt.Errorf("invalid jsonData schema of plugin %s: %w", p.ID, err)
+		}
+		fieldErrors = append(fieldErrors, errs...)
+	}
+	if schema := p.SettingsSchema.SecureJSONData; schema != nil {
+		data := make(map[string]interface{}, len(secureJSONData))
+		for k, v := range secureJSONData {
+			data[k] = v
+		}
+		errs, err := schema.Validate("secureJsonData", data)
+		if err != nil {
+			return fmt.Errorf("invalid secureJsonData schema of plugin %s: %w", p.ID, err)
+		}
+		fieldErrors = append(fieldErrors, errs...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return &models.DataSourceValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
+
 func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *models.UpdateDataSourceCommand, ds *models.DataSource) error {
-	decrypted, err := s.DecryptedValues(ctx, ds)
+	// Keep secret references as they are, so resolved credentials never end up in the database.
+	decrypted, err := s.storedValues(ctx, ds)
 	if err != nil {
 		return err
 	}
@@ -3,24 +3,33 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/audit/audittest"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 
+	"github.com/grafana/grafana/pkg/components/jsonschema"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -197,7 +206,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		rt1, err := dsService.GetHTTPTransport(context.Background(), &ds, provider)
 		require.NoError(t, err)
@@ -231,7 +240,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:             1,
@@ -279,7 +288,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -324,7 +333,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -366,7 +375,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -398,7 +407,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -464,7 +473,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -498,7 +507,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		ds := models.DataSource{
 			Type:     models.DS_ES,
@@ -534,7 +543,7 @@ func TestService_getTimeout(t *testing.T) {
 
 	secretsStore := kvstore.SetupTestService(t)
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-	dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+	dsService := ProvideService(nil, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 	for _, tc := range testCases {
 		ds := &models.DataSource{
@@ -554,7 +563,7 @@ func TestService_GetDecryptedValues(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		jsonData := map[string]string{
 			"password": "securePassword",
@@ -579,7 +588,7 @@ func TestService_GetDecryptedValues(t *testing.T) {
 
 		secretsStore := kvstore.SetupTestService(t)
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(nil, secretsService, secretsStore, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+		dsService := ProvideService(nil, secretsService, secretsStore, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 		jsonData := map[string]string{
 			"password": "securePassword",
@@ -597,6 +606,54 @@ func TestService_GetDecryptedValues(t *testing.T) {
 	})
 }
 
+func TestService_validateSettings(t *testing.T) {
+	var schema plugins.SettingsSchema
+	err := json.Unmarshal([]byte(`{
+		"jsonData": {"type": "object", "required": ["region"], "properties": {"region": {"type": "string", "enum": ["eu", "us"]}}},
+		"secureJsonData": {"type": "object", "required": ["apiKey"], "properties": {"apiKey": {"type": "string", "minLength": 8}}}
+	}`), &schema)
+	require.NoError(t, err)
+
+	dsService := &Service{pluginStore: fakePluginStore{plugins: map[string]plugins.PluginDTO{
+		"with-schema":    {JSONData: plugins.JSONData{ID: "with-schema", SettingsSchema: &schema}},
+		"without-schema": {JSONData: plugins.JSONData{ID: "without-schema"}},
+	}}}
+
+	t.Run("valid settings", func(t *testing.T) {
+		err := dsService.validateSettings(context.Background(), "with-schema",
+			simplejson.NewFromAny(map[string]interface{}{"region": "eu"}), map[string]string{"apiKey": "0123456789"})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		err := dsService.validateSettings(context.Background(), "with-schema", nil, map[string]string{"apiKey": "short"})
+		require.ErrorIs(t, err, models.ErrDataSourceInvalidSettings)
+
+		var validationErr *models.DataSourceValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Equal(t, []jsonschema.FieldError{
+			{Field: "jsonData.region", Message: "is required"},
+			{Field: "secureJsonData.apiKey", Message: "must be at least 8 characters long"},
+		}, validationErr.Errors)
+	})
+
+	t.Run("plugins without schema are not validated", func(t *testing.T) {
+		require.NoError(t, dsService.validateSettings(context.Background(), "without-schema", nil, nil))
+		require.NoError(t, dsService.validateSettings(context.Background(), "unknown", nil, nil))
+	})
+}
+
+type fakePluginStore struct {
+	plugins.Store
+
+	plugins map[string]plugins.PluginDTO
+}
+
+func (pr fakePluginStore) Plugin(_ context.Context, pluginID string) (plugins.PluginDTO, bool) {
+	p, exists := pr.plugins[pluginID]
+	return p, exists
+}
+
 const caCert string = `-----BEGIN CERTIFICATE-----
 MIIDATCCAemgAwIBAgIJAMQ5hC3CPDTeMA0GCSqGSIb3DQEBCwUAMBcxFTATBgNV
 BAMMDGNhLWs4cy1zdGhsbTAeFw0xNjEwMjcwODQyMjdaFw00NDAzMTQwODQyMjda
@@ -663,3 +720,87 @@ Wtnpl+TdAoGAGqKqo2KU3JoY3IuTDUk1dsNAm8jd9EWDh+s1x4aG4N79mwcss5GD
 FF8MbFPneK7xQd8L6HisKUDAUi2NOyynM81LAftPkvN6ZuUVeFDfCL4vCA0HUXLD
 +VrOhtUZkNNJlLMiVRJuQKUOGlg8PpObqYbstQAf/0/yFJMRHG82Tcg=
 -----END RSA PRIVATE KEY-----`
+
+func TestIntegrationService_GetDefaultDataSource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	for _, cmd := range []*models.AddDataSourceCommand{
+		{OrgId: 1, Name: "org default", Uid: "org-default", Type: "prometheus", Access: models.DS_ACCESS_PROXY, IsDefault: true},
+		{OrgId: 1, Name: "preferred", Uid: "preferred", Type: "loki", Access: models.DS_ACCESS_PROXY},
+	} {
+		require.NoError(t, sqlStore.AddDataSource(context.Background(), cmd))
+	}
+
+	preferences := preftest.NewPreferenceServiceFake()
+	dsService := &Service{SQLStore: sqlStore, preferences: preferences}
+	user := &models.SignedInUser{OrgId: 1, UserId: 2, Teams: []int64{3}}
+
+	getDefault := func(t *testing.T, user *models.SignedInUser) string {
+		t.Helper()
+		query := &models.GetDefaultDataSourceQuery{OrgId: 1, User: user}
+		require.NoError(t, dsService.GetDefaultDataSource(context.Background(), query))
+		return query.Result.Uid
+	}
+
+	t.Run("Returns the data source the user prefers", func(t *testing.T) {
+		preferences.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{DefaultDatasourceUID: "preferred"}}
+		require.Equal(t, "preferred", getDefault(t, user))
+	})
+
+	t.Run("Returns the default of the organization without a user", func(t *testing.T) {
+		preferences.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{DefaultDatasourceUID: "preferred"}}
+		require.Equal(t, "org-default", getDefault(t, nil))
+	})
+
+	t.Run("Returns the default of the organization without a preference", func(t *testing.T) {
+		preferences.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{}}
+		require.Equal(t, "org-default", getDefault(t, user))
+	})
+
+	t.Run("Returns the default of the organization when the preferred data source is deleted", func(t *testing.T) {
+		preferences.ExpectedPreference = &pref.Preference{JSONData: &pref.PreferenceJSONData{DefaultDatasourceUID: "deleted"}}
+		require.Equal(t, "org-default", getDefault(t, user))
+	})
+}
+
+func TestIntegrationService_SetDataSourceOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	for _, cmd := range []*models.AddDataSourceCommand{
+		{OrgId: 1, Name: "prometheus", Uid: "prom", Type: "prometheus", Access: models.DS_ACCESS_PROXY},
+		{OrgId: 1, Name: "loki", Uid: "loki", Type: "loki", Access: models.DS_ACCESS_PROXY},
+		{OrgId: 2, Name: "other org", Uid: "other", Type: "loki", Access: models.DS_ACCESS_PROXY},
+	} {
+		require.NoError(t, sqlStore.AddDataSource(context.Background(), cmd))
+	}
+	dsService := &Service{SQLStore: sqlStore}
+
+	t.Run("Saves the order", func(t *testing.T) {
+		err := dsService.SetDataSourceOrder(context.Background(), &models.SetDataSourceOrderCommand{OrgId: 1, Groups: []*models.DataSourceGroup{
+			{Name: "Metrics", Uids: []string{"prom"}},
+			{Name: "Logs", Uids: []string{"loki"}},
+		}})
+		require.NoError(t, err)
+
+		query := &models.GetDataSourceOrderQuery{OrgId: 1}
+		require.NoError(t, dsService.GetDataSourceOrder(context.Background(), query))
+		require.Len(t, query.Result, 2)
+		require.Equal(t, "Metrics", query.Result[0].Name)
+	})
+
+	for name, groups := range map[string][]*models.DataSourceGroup{
+		"Rejects the data sources of other organizations": {{Uids: []string{"other"}}},
+		"Rejects the data sources listed twice":           {{Name: "Metrics", Uids: []string{"prom"}}, {Name: "All", Uids: []string{"prom"}}},
+		"Rejects the groups listed twice":                 {{Name: "Metrics", Uids: []string{"prom"}}, {Name: "Metrics", Uids: []string{"loki"}}},
+		"Rejects the group names that are too long":       {{Name: strings.Repeat("a", 191), Uids: []string{"prom"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := dsService.SetDataSourceOrder(context.Background(), &models.SetDataSourceOrderCommand{OrgId: 1, Groups: groups})
+			require.ErrorIs(t, err, models.ErrDataSourceOrderInvalid)
+		})
+	}
+}
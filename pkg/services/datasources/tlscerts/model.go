@@ -0,0 +1,51 @@
+package tlscerts
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid PEM encoded certificate")
+	ErrInvalidKeyPair     = errors.New("client certificate and key do not match")
+	ErrMissingKeyPair     = errors.New("client certificate and key must be provided together")
+)
+
+// Keys of the datasource secure json data and json data holding the TLS
+// settings.
+const (
+	secureCACertKey     = "tlsCACert"
+	secureClientCertKey = "tlsClientCert"
+	secureClientKeyKey  = "tlsClientKey"
+	jsonTLSAuth         = "tlsAuth"
+	jsonTLSAuthWithCA   = "tlsAuthWithCACert"
+	jsonServerName      = "serverName"
+)
+
+// CertificateInfo describes a certificate without exposing its content.
+type CertificateInfo struct {
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	SerialNumber    string    `json:"serialNumber"`
+	NotBefore       time.Time `json:"notBefore"`
+	NotAfter        time.Time `json:"notAfter"`
+	DaysUntilExpiry int       `json:"daysUntilExpiry"`
+	Expired         bool      `json:"expired"`
+}
+
+// DataSourceCertificates describes the TLS certificates configured for a
+// datasource.
+type DataSourceCertificates struct {
+	ServerName        string            `json:"serverName,omitempty"`
+	CACertificates    []CertificateInfo `json:"caCertificates"`
+	ClientCertificate *CertificateInfo  `json:"clientCertificate,omitempty"`
+}
+
+// UpdateCertificatesCommand replaces the TLS certificates of a datasource.
+// Empty fields leave the corresponding certificate unchanged.
+type UpdateCertificatesCommand struct {
+	CACert     string  `json:"caCert"`
+	ClientCert string  `json:"clientCert"`
+	ClientKey  string  `json:"clientKey"`
+	ServerName *string `json:"serverName"`
+}
@@ -0,0 +1,421 @@
+// Package tlscerts manages the TLS certificates of datasources separately
+// from the rest of their settings, and keeps track of their expiry.
+package tlscerts
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultWarningDays   = 30
+	defaultCheckInterval = time.Hour
+	// kvNamespace holds the certificate metadata of every datasource, so that
+	// expiry can be checked without decrypting its secure settings.
+	kvNamespace = "datasource.tlscerts"
+)
+
+var certificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metrics.ExporterName,
+	Name:      "datasource_tls_certificate_expiry_seconds",
+	Help:      "Number of seconds until the TLS certificates configured for a datasource expire",
+}, []string{"org_id", "datasource_uid", "certificate"})
+
+func init() {
+	prometheus.MustRegister(certificateExpiry)
+}
+
+type Service struct {
+	sqlStore          *sqlstore.SQLStore
+	dataSourceService datasources.DataSourceService
+	kv                kvstore.KVStore
+	inbox             inbox.Service
+	log               log.Logger
+	warningDays       int
+	checkInterval     time.Duration
+	now               func() time.Time
+}
+
+// ProvideService returns a Service that checks the datasource certificates
+// every tls_certificate_check_interval and notifies the org admins about those
+// expiring within tls_certificate_expiry_warning_days. Both are read from the
+// [datasources] section.
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, dataSourceService datasources.DataSourceService,
+	kv kvstore.KVStore, inboxService inbox.Service) *Service {
+	sec := cfg.Raw.Section("datasources")
+	return &Service{
+		sqlStore:          sqlStore,
+		dataSourceService: dataSourceService,
+		kv:                kv,
+		inbox:             inboxService,
+		log:               log.New("datasources.tlscerts"),
+		warningDays:       sec.Key("tls_certificate_expiry_warning_days").MustInt(defaultWarningDays),
+		checkInterval:     sec.Key("tls_certificate_check_interval").MustDuration(defaultCheckInterval),
+		now:               time.Now,
+	}
+}
+
+// GetCertificates returns information about the TLS certificates of a
+// datasource.
+func (s *Service) GetCertificates(ctx context.Context, ds *models.DataSource) (*DataSourceCertificates, error) {
+	values, err := s.dataSourceService.DecryptedValues(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DataSourceCertificates{CACertificates: []CertificateInfo{}}
+	if ds.JsonData != nil {
+		result.ServerName = ds.JsonData.Get(jsonServerName).MustString()
+	}
+
+	if ca := values[secureCACertKey]; ca != "" {
+		certs, err := parseCertificates(ca)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+		}
+		for _, cert := range certs {
+			result.CACertificates = append(result.CACertificates, s.certificateInfo(cert))
+		}
+	}
+
+	if client := values[secureClientCertKey]; client != "" {
+		certs, err := parseCertificates(client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		info := s.certificateInfo(certs[0])
+		result.ClientCertificate = &info
+	}
+
+	s.storeMetadata(ctx, ds.OrgId, ds.Uid, &certificateMetadata{Version: ds.Version, Certificates: *result})
+	return result, nil
+}
+
+// UpdateCertificates validates and stores new TLS certificates for a
+// datasource, enabling the matching TLS settings.
+func (s *Service) UpdateCertificates(ctx context.Context, ds *models.DataSource, cmd *UpdateCertificatesCommand) error {
+	jsonData, err := copyJSONData(ds.JsonData)
+	if err != nil {
+		return err
+	}
+	secureJSONData := map[string]string{}
+
+	var caCerts, clientCerts []*x509.Certificate
+	if cmd.CACert != "" {
+		if caCerts, err = parseCertificates(cmd.CACert); err != nil {
+			return err
+		}
+		secureJSONData[secureCACertKey] = cmd.CACert
+		jsonData.Set(jsonTLSAuthWithCA, true)
+	}
+
+	if cmd.ClientCert != "" || cmd.ClientKey != "" {
+		if cmd.ClientCert == "" || cmd.ClientKey == "" {
+			return ErrMissingKeyPair
+		}
+		if clientCerts, err = parseCertificates(cmd.ClientCert); err != nil {
+			return err
+		}
+		if _, err := tls.X509KeyPair([]byte(cmd.ClientCert), []byte(cmd.ClientKey)); err != nil {
+			return ErrInvalidKeyPair
+		}
+		secureJSONData[secureClientCertKey] = cmd.ClientCert
+		secureJSONData[secureClientKeyKey] = cmd.ClientKey
+		jsonData.Set(jsonTLSAuth, true)
+	}
+
+	if cmd.ServerName != nil {
+		jsonData.Set(jsonServerName, *cmd.ServerName)
+	}
+
+	update := updateCommand(ds, jsonData, secureJSONData)
+	if err := s.dataSourceService.UpdateDataSource(ctx, update); err != nil {
+		return err
+	}
+
+	// Certificates left unchanged keep their recorded metadata.
+	metadata, err := s.loadMetadata(ctx, ds.OrgId, ds.Uid)
+	if err != nil {
+		metadata = &certificateMetadata{}
+	}
+	metadata.Version = ds.Version + 1
+	if update.Result != nil {
+		metadata.Version = update.Result.Version
+	}
+	if caCerts != nil {
+		metadata.Certificates.CACertificates = make([]CertificateInfo, 0, len(caCerts))
+		for _, cert := range caCerts {
+			metadata.Certificates.CACertificates = append(metadata.Certificates.CACertificates, s.certificateInfo(cert))
+		}
+	}
+	if clientCerts != nil {
+		info := s.certificateInfo(clientCerts[0])
+		metadata.Certificates.ClientCertificate = &info
+	}
+	s.storeMetadata(ctx, ds.OrgId, ds.Uid, metadata)
+	return nil
+}
+
+// DeleteCertificates removes the TLS certificates of a datasource and
+// disables TLS client and CA authentication.
+func (s *Service) DeleteCertificates(ctx context.Context, ds *models.DataSource) error {
+	jsonData, err := copyJSONData(ds.JsonData)
+	if err != nil {
+		return err
+	}
+	jsonData.Set(jsonTLSAuth, false)
+	jsonData.Set(jsonTLSAuthWithCA, false)
+
+	if err := s.dataSourceService.UpdateDataSource(ctx, updateCommand(ds, jsonData, map[string]string{
+		secureCACertKey:     "",
+		secureClientCertKey: "",
+		secureClientKeyKey:  "",
+	})); err != nil {
+		return err
+	}
+
+	if err := s.kv.Del(ctx, ds.OrgId, kvNamespace, ds.Uid); err != nil {
+		s.log.Warn("Failed to delete datasource certificate metadata", "orgId", ds.OrgId, "uid", ds.Uid, "error", err)
+	}
+	return nil
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	s.checkExpiry(ctx)
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkExpiry(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkExpiry updates the certificate expiry metrics, which alert rules can
+// be based on, and notifies the org admins about every certificate close to
+// expiry. Only the recorded certificate metadata is read, the secure settings
+// of the datasources are never decrypted.
+func (s *Service) checkExpiry(ctx context.Context) {
+	query := &models.GetAllDataSourcesQuery{}
+	if err := s.sqlStore.GetAllDataSources(ctx, query); err != nil {
+		s.log.Error("Failed to list datasources", "error", err)
+		return
+	}
+
+	certificateExpiry.Reset()
+	for _, ds := range query.Result {
+		if ds.JsonData == nil || !(ds.JsonData.Get(jsonTLSAuth).MustBool() || ds.JsonData.Get(jsonTLSAuthWithCA).MustBool()) {
+			continue
+		}
+
+		metadata, err := s.loadMetadata(ctx, ds.OrgId, ds.Uid)
+		if err != nil {
+			s.log.Debug("No certificate metadata recorded for datasource", "orgId", ds.OrgId, "uid", ds.Uid, "error", err)
+			continue
+		}
+		// The datasource was saved without going through this service, its
+		// certificates may have changed since they were recorded.
+		if metadata.Version != ds.Version {
+			s.log.Debug("Certificate metadata of datasource is outdated", "orgId", ds.OrgId, "uid", ds.Uid)
+			continue
+		}
+
+		s.track(ctx, ds, "ca", s.refresh(metadata.Certificates.CACertificates))
+		if metadata.Certificates.ClientCertificate != nil {
+			s.track(ctx, ds, "client", s.refresh([]CertificateInfo{*metadata.Certificates.ClientCertificate}))
+		}
+	}
+}
+
+func (s *Service) track(ctx context.Context, ds *models.DataSource, kind string, infos []CertificateInfo) {
+	if len(infos) == 0 {
+		return
+	}
+
+	// A CA bundle can hold several certificates, report the first one to expire.
+	first := infos[0].NotAfter
+	for _, info := range infos {
+		if info.NotAfter.Before(first) {
+			first = info.NotAfter
+		}
+
+		if info.Expired {
+			s.log.Warn("Datasource TLS certificate has expired", "orgId", ds.OrgId, "uid", ds.Uid, "name", ds.Name,
+				"certificate", kind, "subject", info.Subject, "notAfter", info.NotAfter)
+			s.notify(ctx, ds, kind, info, fmt.Sprintf("TLS certificate of datasource %q has expired", ds.Name), "expired")
+		} else if info.DaysUntilExpiry <= s.warningDays {
+			s.log.Warn("Datasource TLS certificate is about to expire", "orgId", ds.OrgId, "uid", ds.Uid, "name", ds.Name,
+				"certificate", kind, "subject", info.Subject, "notAfter", info.NotAfter, "days", info.DaysUntilExpiry)
+			s.notify(ctx, ds, kind, info, fmt.Sprintf("TLS certificate of datasource %q expires soon", ds.Name), "expiring")
+		}
+	}
+
+	certificateExpiry.WithLabelValues(fmt.Sprint(ds.OrgId), ds.Uid, kind).Set(first.Sub(s.now()).Seconds())
+}
+
+// notify sends an inbox notification about the certificate to the admins of
+// the datasource organization, once per certificate and state.
+func (s *Service) notify(ctx context.Context, ds *models.DataSource, kind string, info CertificateInfo, title, state string) {
+	var admins []int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("org_user").Where("org_id=? AND role=?", ds.OrgId, models.ROLE_ADMIN).Cols("user_id").Find(&admins)
+	})
+	if err != nil {
+		s.log.Warn("Failed to list org admins", "orgId", ds.OrgId, "error", err)
+		return
+	}
+	if len(admins) == 0 {
+		return
+	}
+
+	err = s.inbox.Send(ctx, &inbox.SendCommand{
+		OrgID:    ds.OrgId,
+		UserIDs:  admins,
+		Kind:     inbox.KindCertificateExpiry,
+		Title:    title,
+		Body:     fmt.Sprintf("The %s certificate %s expires at %s.", kind, info.Subject, info.NotAfter.UTC().Format(time.RFC1123)),
+		URL:      fmt.Sprintf("/datasources/edit/%s", ds.Uid),
+		DedupKey: fmt.Sprintf("tls-certificate-%s-%s-%s-%s", state, ds.Uid, kind, info.SerialNumber),
+	})
+	if err != nil {
+		s.log.Warn("Failed to send certificate expiry notification", "orgId", ds.OrgId, "uid", ds.Uid, "error", err)
+	}
+}
+
+// certificateMetadata is recorded for a datasource whenever its certificates
+// are read or updated through this service. Version is the datasource version
+// the metadata was recorded for.
+type certificateMetadata struct {
+	Version      int                    `json:"version"`
+	Certificates DataSourceCertificates `json:"certificates"`
+}
+
+func (s *Service) loadMetadata(ctx context.Context, orgID int64, uid string) (*certificateMetadata, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("no certificate metadata for datasource %s", uid)
+	}
+
+	var metadata certificateMetadata
+	if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (s *Service) storeMetadata(ctx context.Context, orgID int64, uid string, metadata *certificateMetadata) {
+	value, err := json.Marshal(metadata)
+	if err == nil {
+		err = s.kv.Set(ctx, orgID, kvNamespace, uid, string(value))
+	}
+	if err != nil {
+		s.log.Warn("Failed to record datasource certificate metadata", "orgId", orgID, "uid", uid, "error", err)
+	}
+}
+
+// refresh recomputes the time left until expiry of recorded certificates.
+func (s *Service) refresh(infos []CertificateInfo) []CertificateInfo {
+	refreshed := make([]CertificateInfo, 0, len(infos))
+	for _, info := range infos {
+		remaining := info.NotAfter.Sub(s.now())
+		info.DaysUntilExpiry = int(math.Floor(remaining.Hours() / 24))
+		info.Expired = remaining <= 0
+		refreshed = append(refreshed, info)
+	}
+	return refreshed
+}
+
+func (s *Service) certificateInfo(cert *x509.Certificate) CertificateInfo {
+	remaining := cert.NotAfter.Sub(s.now())
+	return CertificateInfo{
+		Subject:         cert.Subject.String(),
+		Issuer:          cert.Issuer.String(),
+		SerialNumber:    cert.SerialNumber.String(),
+		NotBefore:       cert.NotBefore,
+		NotAfter:        cert.NotAfter,
+		DaysUntilExpiry: int(math.Floor(remaining.Hours() / 24)),
+		Expired:         remaining <= 0,
+	}
+}
+
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidCertificate
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, ErrInvalidCertificate
+	}
+	return certs, nil
+}
+
+func copyJSONData(jsonData *simplejson.Json) (*simplejson.Json, error) {
+	if jsonData == nil {
+		return simplejson.New(), nil
+	}
+	b, err := jsonData.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(b)
+}
+
+func updateCommand(ds *models.DataSource, jsonData *simplejson.Json, secureJSONData map[string]string) *models.UpdateDataSourceCommand {
+	return &models.UpdateDataSourceCommand{
+		Id:              ds.Id,
+		Uid:             ds.Uid,
+		OrgId:           ds.OrgId,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		Url:             ds.Url,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  secureJSONData,
+		Version:         ds.Version,
+		ReadOnly:        ds.ReadOnly,
+	}
+}
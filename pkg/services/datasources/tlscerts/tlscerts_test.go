@@ -0,0 +1,239 @@
+package tlscerts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type fakeDataSourceService struct {
+	datasources.DataSourceService
+
+	secureJSONData map[string]string
+	updates        []*models.UpdateDataSourceCommand
+	decrypts       int
+}
+
+func (f *fakeDataSourceService) DecryptedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error) {
+	f.decrypts++
+	return f.secureJSONData, nil
+}
+
+func (f *fakeDataSourceService) UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
+	f.updates = append(f.updates, cmd)
+	return nil
+}
+
+type fakeKVStore struct {
+	kvstore.KVStore
+	values map[string]string
+}
+
+func (f *fakeKVStore) Get(ctx context.Context, orgID int64, namespace string, key string) (string, bool, error) {
+	v, ok := f.values[key]
+	return v, ok, nil
+}
+
+func (f *fakeKVStore) Set(ctx context.Context, orgID int64, namespace string, key string, value string) error {
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeKVStore) Del(ctx context.Context, orgID int64, namespace string, key string) error {
+	delete(f.values, key)
+	return nil
+}
+
+type fakeInboxService struct {
+	inbox.Service
+	sent []*inbox.SendCommand
+}
+
+func (f *fakeInboxService) Send(ctx context.Context, cmd *inbox.SendCommand) error {
+	f.sent = append(f.sent, cmd)
+	return nil
+}
+
+func generateCertificate(t *testing.T, cn string, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestService(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	caCert, _ := generateCertificate(t, "ca", now.Add(90*24*time.Hour))
+	clientCert, clientKey := generateCertificate(t, "client", now.Add(10*24*time.Hour))
+	_, otherKey := generateCertificate(t, "other", now.Add(10*24*time.Hour))
+
+	setup := func(values map[string]string) (*Service, *fakeDataSourceService) {
+		fake := &fakeDataSourceService{secureJSONData: values}
+		return &Service{dataSourceService: fake, kv: &fakeKVStore{values: map[string]string{}}, now: func() time.Time { return now }, warningDays: defaultWarningDays}, fake
+	}
+	ds := &models.DataSource{Id: 1, Uid: "ds", OrgId: 1, Name: "prom", Version: 1, JsonData: simplejson.NewFromAny(map[string]interface{}{"httpMethod": "POST"})}
+
+	t.Run("GetCertificates reports expiry", func(t *testing.T) {
+		svc, _ := setup(map[string]string{secureCACertKey: caCert, secureClientCertKey: clientCert})
+
+		certs, err := svc.GetCertificates(context.Background(), ds)
+		require.NoError(t, err)
+		require.Len(t, certs.CACertificates, 1)
+		require.Equal(t, "CN=ca", certs.CACertificates[0].Subject)
+		require.Equal(t, 90, certs.CACertificates[0].DaysUntilExpiry)
+		require.NotNil(t, certs.ClientCertificate)
+		require.Equal(t, 10, certs.ClientCertificate.DaysUntilExpiry)
+		require.False(t, certs.ClientCertificate.Expired)
+	})
+
+	t.Run("UpdateCertificates stores certificates and enables TLS settings", func(t *testing.T) {
+		svc, fake := setup(nil)
+		serverName := "prom.internal"
+
+		err := svc.UpdateCertificates(context.Background(), ds, &UpdateCertificatesCommand{
+			CACert:     caCert,
+			ClientCert: clientCert,
+			ClientKey:  clientKey,
+			ServerName: &serverName,
+		})
+		require.NoError(t, err)
+		require.Len(t, fake.updates, 1)
+
+		cmd := fake.updates[0]
+		require.Equal(t, map[string]string{
+			secureCACertKey:     caCert,
+			secureClientCertKey: clientCert,
+			secureClientKeyKey:  clientKey,
+		}, cmd.SecureJsonData)
+		require.True(t, cmd.JsonData.Get(jsonTLSAuth).MustBool())
+		require.True(t, cmd.JsonData.Get(jsonTLSAuthWithCA).MustBool())
+		require.Equal(t, serverName, cmd.JsonData.Get(jsonServerName).MustString())
+		require.Equal(t, "POST", cmd.JsonData.Get("httpMethod").MustString())
+		require.False(t, ds.JsonData.Get(jsonTLSAuth).MustBool(), "datasource json data must not be modified")
+
+		metadata, err := svc.loadMetadata(context.Background(), ds.OrgId, ds.Uid)
+		require.NoError(t, err)
+		require.Equal(t, 2, metadata.Version)
+		require.Equal(t, "CN=client", metadata.Certificates.ClientCertificate.Subject)
+		require.Len(t, metadata.Certificates.CACertificates, 1)
+	})
+
+	t.Run("UpdateCertificates validates input", func(t *testing.T) {
+		svc, fake := setup(nil)
+
+		err := svc.UpdateCertificates(context.Background(), ds, &UpdateCertificatesCommand{CACert: "not a certificate"})
+		require.ErrorIs(t, err, ErrInvalidCertificate)
+
+		err = svc.UpdateCertificates(context.Background(), ds, &UpdateCertificatesCommand{ClientCert: clientCert})
+		require.ErrorIs(t, err, ErrMissingKeyPair)
+
+		err = svc.UpdateCertificates(context.Background(), ds, &UpdateCertificatesCommand{ClientCert: clientCert, ClientKey: otherKey})
+		require.ErrorIs(t, err, ErrInvalidKeyPair)
+
+		require.Empty(t, fake.updates)
+	})
+
+	t.Run("DeleteCertificates clears certificates and disables TLS settings", func(t *testing.T) {
+		svc, fake := setup(nil)
+
+		require.NoError(t, svc.DeleteCertificates(context.Background(), ds))
+		require.Len(t, fake.updates, 1)
+
+		cmd := fake.updates[0]
+		require.Equal(t, "", cmd.SecureJsonData[secureClientKeyKey])
+		require.False(t, cmd.JsonData.Get(jsonTLSAuth).MustBool(true))
+		require.False(t, cmd.JsonData.Get(jsonTLSAuthWithCA).MustBool(true))
+
+		_, err := svc.loadMetadata(context.Background(), ds.OrgId, ds.Uid)
+		require.Error(t, err)
+	})
+}
+
+func TestIntegrationCheckExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	caCert, _ := generateCertificate(t, "ca", now.Add(90*24*time.Hour))
+	clientCert, _ := generateCertificate(t, "client", now.Add(10*24*time.Hour))
+
+	ss := sqlstore.InitTestDB(t)
+	admin, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@example.com"})
+	require.NoError(t, err)
+	addCmd := &models.AddDataSourceCommand{
+		OrgId:    admin.OrgId,
+		Name:     "prom",
+		Type:     "prometheus",
+		Access:   models.DS_ACCESS_PROXY,
+		Uid:      "ds",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{jsonTLSAuth: true, jsonTLSAuthWithCA: true}),
+	}
+	require.NoError(t, ss.AddDataSource(ctx, addCmd))
+
+	dataSources := &fakeDataSourceService{secureJSONData: map[string]string{secureCACertKey: caCert, secureClientCertKey: clientCert}}
+	inboxService := &fakeInboxService{}
+	svc := &Service{
+		sqlStore:          ss,
+		dataSourceService: dataSources,
+		kv:                kvstore.ProvideService(ss),
+		inbox:             inboxService,
+		log:               log.New("test"),
+		warningDays:       defaultWarningDays,
+		now:               func() time.Time { return now },
+	}
+
+	t.Run("datasources without recorded metadata are skipped", func(t *testing.T) {
+		svc.checkExpiry(ctx)
+		require.Empty(t, inboxService.sent)
+		require.Zero(t, dataSources.decrypts)
+	})
+
+	t.Run("org admins are notified about expiring certificates", func(t *testing.T) {
+		_, err := svc.GetCertificates(ctx, addCmd.Result)
+		require.NoError(t, err)
+		dataSources.decrypts = 0
+
+		svc.checkExpiry(ctx)
+		svc.checkExpiry(ctx)
+		require.Zero(t, dataSources.decrypts, "expiry checks must not decrypt secure settings")
+		require.Len(t, inboxService.sent, 2)
+
+		cmd := inboxService.sent[0]
+		require.Equal(t, admin.OrgId, cmd.OrgID)
+		require.Equal(t, []int64{admin.Id}, cmd.UserIDs)
+		require.Equal(t, inbox.KindCertificateExpiry, cmd.Kind)
+		require.Equal(t, "/datasources/edit/ds", cmd.URL)
+		require.Equal(t, cmd.DedupKey, inboxService.sent[1].DedupKey)
+	})
+}
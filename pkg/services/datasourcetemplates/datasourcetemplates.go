@@ -0,0 +1,23 @@
+package datasourcetemplates
+
+import (
+	"context"
+)
+
+// Service manages data source templates, definitions with variables that
+// expand into one data source per instance, e.g. one Prometheus per region.
+// The data sources of a template are read-only and are changed together by
+// saving the template.
+type Service interface {
+	// Get returns the template, or ErrDataSourceTemplateNotFound.
+	Get(ctx context.Context, query *GetDataSourceTemplateQuery) (*DataSourceTemplate, error)
+	// List returns the templates of the organization.
+	List(ctx context.Context, query *ListDataSourceTemplatesQuery) ([]*DataSourceTemplate, error)
+	// Save creates or updates the data source of each instance of the template,
+	// and deletes the data sources of the instances it no longer has.
+	Save(ctx context.Context, cmd *SaveDataSourceTemplateCommand) error
+	// Delete deletes the template with its data sources.
+	Delete(ctx context.Context, cmd *DeleteDataSourceTemplateCommand) error
+	// RotateCredentials sets secure settings of all the data sources of the template.
+	RotateCredentials(ctx context.Context, cmd *RotateCredentialsCommand) (*RotateCredentialsResult, error)
+}
@@ -0,0 +1,272 @@
+package datasourcetemplatesimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+)
+
+const kvNamespace = "datasource-templates"
+
+// Service stores each template of an organization as a JSON document in the
+// key-value store, with the uid of the template as key. The data sources of the
+// instances are regular data sources, with the template origin.
+type Service struct {
+	kv          kvstore.KVStore
+	dataSources datasources.DataSourceService
+	log         log.Logger
+	now         func() time.Time
+}
+
+func ProvideService(kv kvstore.KVStore, dataSources datasources.DataSourceService) datasourcetemplates.Service {
+	return &Service{
+		kv:          kv,
+		dataSources: dataSources,
+		log:         log.New("datasourcetemplates"),
+		now:         time.Now,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *datasourcetemplates.GetDataSourceTemplateQuery) (*datasourcetemplates.DataSourceTemplate, error) {
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, query.UID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, datasourcetemplates.ErrDataSourceTemplateNotFound
+	}
+
+	var result datasourcetemplates.DataSourceTemplate
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) List(ctx context.Context, query *datasourcetemplates.ListDataSourceTemplatesQuery) ([]*datasourcetemplates.DataSourceTemplate, error) {
+	keys, err := s.kv.Keys(ctx, query.OrgID, kvNamespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*datasourcetemplates.DataSourceTemplate, 0, len(keys))
+	for _, key := range keys {
+		tmpl, err := s.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: query.OrgID, UID: key.Key})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tmpl)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Save applies the template to the data sources before storing it, so that a
+// template that failed to apply can be saved again to finish applying it.
+func (s *Service) Save(ctx context.Context, cmd *datasourcetemplates.SaveDataSourceTemplateCommand) error {
+	tmpl := &cmd.Template
+	if err := tmpl.Validate(); err != nil {
+		return err
+	}
+
+	previous, err := s.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: cmd.OrgID, UID: tmpl.UID})
+	if err != nil && !errors.Is(err, datasourcetemplates.ErrDataSourceTemplateNotFound) {
+		return err
+	}
+
+	for _, instance := range tmpl.Instances {
+		if err := s.applyInstance(ctx, cmd, instance); err != nil {
+			return fmt.Errorf("failed to apply instance %q: %w", instance.Key, err)
+		}
+	}
+
+	if previous != nil {
+		keys := make(map[string]bool, len(tmpl.Instances))
+		for _, instance := range tmpl.Instances {
+			keys[instance.Key] = true
+		}
+		for _, instance := range previous.Instances {
+			if keys[instance.Key] {
+				continue
+			}
+			if err := s.deleteDataSource(ctx, cmd.OrgID, previous.DataSourceUID(instance.Key)); err != nil {
+				return fmt.Errorf("failed to delete instance %q: %w", instance.Key, err)
+			}
+		}
+	}
+
+	tmpl.Updated = s.now()
+	raw, err := json.Marshal(tmpl)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, cmd.OrgID, kvNamespace, tmpl.UID, string(raw))
+}
+
+// applyInstance creates the data source of the instance, or updates it to the
+// current definition when it exists.
+func (s *Service) applyInstance(ctx context.Context, cmd *datasourcetemplates.SaveDataSourceTemplateCommand, instance datasourcetemplates.Instance) error {
+	uid := cmd.Template.DataSourceUID(instance.Key)
+	def := cmd.Template.Definition.Expand(instance.Variables)
+
+	existing, err := s.getDataSource(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		add := &models.AddDataSourceCommand{
+			Name:            def.Name,
+			Type:            def.Type,
+			Access:          def.Access,
+			Url:             def.URL,
+			Database:        def.Database,
+			User:            def.User,
+			BasicAuth:       def.BasicAuth,
+			BasicAuthUser:   def.BasicAuthUser,
+			WithCredentials: def.WithCredentials,
+			JsonData:        simplejson.NewFromAny(def.JSONData),
+			SecureJsonData:  cmd.SecureJSONData,
+			Uid:             uid,
+			OrgId:           cmd.OrgID,
+			UserId:          cmd.UserID,
+			ReadOnly:        true,
+			Origin:          models.DsOriginTemplate,
+		}
+		return s.dataSources.AddDataSource(ctx, add)
+	}
+
+	return s.dataSources.UpdateDataSource(ctx, &models.UpdateDataSourceCommand{
+		Name:            def.Name,
+		Type:            def.Type,
+		Access:          def.Access,
+		Url:             def.URL,
+		Database:        def.Database,
+		User:            def.User,
+		BasicAuth:       def.BasicAuth,
+		BasicAuthUser:   def.BasicAuthUser,
+		WithCredentials: def.WithCredentials,
+		IsDefault:       existing.IsDefault,
+		JsonData:        simplejson.NewFromAny(def.JSONData),
+		Version:         existing.Version,
+		Uid:             uid,
+		OrgId:           cmd.OrgID,
+		Id:              existing.Id,
+		ReadOnly:        true,
+		Origin:          models.DsOriginTemplate,
+	})
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *datasourcetemplates.DeleteDataSourceTemplateCommand) error {
+	tmpl, err := s.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: cmd.OrgID, UID: cmd.UID})
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range tmpl.Instances {
+		if err := s.deleteDataSource(ctx, cmd.OrgID, tmpl.DataSourceUID(instance.Key)); err != nil {
+			return fmt.Errorf("failed to delete instance %q: %w", instance.Key, err)
+		}
+	}
+	return s.kv.Del(ctx, cmd.OrgID, kvNamespace, cmd.UID)
+}
+
+func (s *Service) RotateCredentials(ctx context.Context, cmd *datasourcetemplates.RotateCredentialsCommand) (*datasourcetemplates.RotateCredentialsResult, error) {
+	if len(cmd.SecureJSONData) == 0 {
+		return nil, datasourcetemplates.ErrNoCredentials
+	}
+	tmpl, err := s.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: cmd.OrgID, UID: cmd.UID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &datasourcetemplates.RotateCredentialsResult{
+		Rotated: make([]string, 0, len(tmpl.Instances)),
+		Failed:  map[string]string{},
+	}
+	for _, instance := range tmpl.Instances {
+		uid := tmpl.DataSourceUID(instance.Key)
+		if err := s.rotate(ctx, cmd, uid); err != nil {
+			s.log.Error("Failed to rotate the credentials of a data source", "orgId", cmd.OrgID, "template", tmpl.UID, "uid", uid, "error", err)
+			result.Failed[uid] = err.Error()
+			continue
+		}
+		result.Rotated = append(result.Rotated, uid)
+	}
+	s.log.Info("Rotated the credentials of the data sources of a template", "orgId", cmd.OrgID, "template", tmpl.UID, "rotated", len(result.Rotated), "failed", len(result.Failed))
+	return result, nil
+}
+
+func (s *Service) rotate(ctx context.Context, cmd *datasourcetemplates.RotateCredentialsCommand, uid string) error {
+	ds, err := s.getDataSource(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return err
+	}
+	if ds == nil {
+		return models.ErrDataSourceNotFound
+	}
+
+	// the secure settings that aren't set are kept by the update
+	secureJSONData := make(map[string]string, len(cmd.SecureJSONData))
+	for k, v := range cmd.SecureJSONData {
+		secureJSONData[k] = v
+	}
+	return s.dataSources.UpdateDataSource(ctx, &models.UpdateDataSourceCommand{
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		Url:             ds.Url,
+		Database:        ds.Database,
+		User:            ds.User,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JsonData,
+		SecureJsonData:  secureJSONData,
+		Version:         ds.Version,
+		Uid:             ds.Uid,
+		OrgId:           ds.OrgId,
+		Id:              ds.Id,
+		ReadOnly:        ds.ReadOnly,
+		Origin:          ds.Origin,
+	})
+}
+
+// getDataSource returns the data source with the uid, nil if there is none, or
+// ErrDataSourceNotManaged if it isn't managed by a template.
+func (s *Service) getDataSource(ctx context.Context, orgID int64, uid string) (*models.DataSource, error) {
+	query := &models.GetDataSourceQuery{OrgId: orgID, Uid: uid}
+	if err := s.dataSources.GetDataSource(ctx, query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if query.Result.Origin != models.DsOriginTemplate {
+		return nil, datasourcetemplates.ErrDataSourceNotManaged
+	}
+	return query.Result, nil
+}
+
+// deleteDataSource deletes the data source of an instance, unless it has been
+// deleted already.
+func (s *Service) deleteDataSource(ctx context.Context, orgID int64, uid string) error {
+	ds, err := s.getDataSource(ctx, orgID, uid)
+	if err != nil || ds == nil {
+		return err
+	}
+	return s.dataSources.DeleteDataSource(ctx, &models.DeleteDataSourceCommand{OrgID: orgID, UID: uid, Name: ds.Name})
+}
@@ -0,0 +1,148 @@
+package datasourcetemplatesimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
+	"github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/secrets/audit/audittest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationDataSourceTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	dsService := service.ProvideService(ss, secretsService, secretskvs.SetupTestService(t), setting.NewCfg(), featuremgmt.WithFeatures(),
+		acmock.New().WithDisabled(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
+	svc := ProvideService(kvstore.ProvideService(ss), dsService)
+	ctx := context.Background()
+
+	tmpl := datasourcetemplates.DataSourceTemplate{
+		UID:  "prom",
+		Name: "Prometheus",
+		Definition: datasourcetemplates.Definition{
+			Name:     "Prometheus ${region}",
+			Type:     "prometheus",
+			Access:   models.DS_ACCESS_PROXY,
+			URL:      "https://prometheus.${region}.example.com",
+			JSONData: map[string]interface{}{"httpHeaderName1": "X-Region-${region}"},
+		},
+		Instances: []datasourcetemplates.Instance{
+			{Key: "eu", Variables: map[string]string{"region": "eu"}},
+			{Key: "us", Variables: map[string]string{"region": "us"}},
+		},
+	}
+
+	getDataSource := func(t *testing.T, uid string) *models.DataSource {
+		t.Helper()
+		query := &models.GetDataSourceQuery{OrgId: 1, Uid: uid}
+		require.NoError(t, dsService.GetDataSource(ctx, query))
+		return query.Result
+	}
+
+	t.Run("Save rejects instances that don't set every variable", func(t *testing.T) {
+		invalid := tmpl
+		invalid.Instances = []datasourcetemplates.Instance{{Key: "eu", Variables: map[string]string{}}}
+		err := svc.Save(ctx, &datasourcetemplates.SaveDataSourceTemplateCommand{OrgID: 1, Template: invalid})
+		require.ErrorIs(t, err, datasourcetemplates.ErrInvalidDataSourceTemplate)
+	})
+
+	t.Run("Save creates a read-only data source per instance", func(t *testing.T) {
+		err := svc.Save(ctx, &datasourcetemplates.SaveDataSourceTemplateCommand{
+			OrgID: 1, UserID: 1, Template: tmpl, SecureJSONData: map[string]string{"httpHeaderValue1": "secret"},
+		})
+		require.NoError(t, err)
+
+		ds := getDataSource(t, "prom-eu")
+		require.Equal(t, "Prometheus eu", ds.Name)
+		require.Equal(t, "https://prometheus.eu.example.com", ds.Url)
+		require.Equal(t, "X-Region-eu", ds.JsonData.Get("httpHeaderName1").MustString())
+		require.Equal(t, models.DsOriginTemplate, ds.Origin)
+		require.True(t, ds.ReadOnly)
+
+		values, err := dsService.DecryptedValues(ctx, ds)
+		require.NoError(t, err)
+		require.Equal(t, "secret", values["httpHeaderValue1"])
+
+		require.Equal(t, "Prometheus us", getDataSource(t, "prom-us").Name)
+
+		saved, err := svc.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: 1, UID: "prom"})
+		require.NoError(t, err)
+		require.Len(t, saved.Instances, 2)
+		require.False(t, saved.Updated.IsZero())
+	})
+
+	t.Run("Save updates the data sources and deletes the removed instances", func(t *testing.T) {
+		updated := tmpl
+		updated.Definition.URL = "https://metrics.${region}.example.com"
+		updated.Instances = []datasourcetemplates.Instance{{Key: "eu", Variables: map[string]string{"region": "eu"}}}
+		require.NoError(t, svc.Save(ctx, &datasourcetemplates.SaveDataSourceTemplateCommand{OrgID: 1, UserID: 1, Template: updated}))
+
+		ds := getDataSource(t, "prom-eu")
+		require.Equal(t, "https://metrics.eu.example.com", ds.Url)
+		values, err := dsService.DecryptedValues(ctx, ds)
+		require.NoError(t, err)
+		require.Equal(t, "secret", values["httpHeaderValue1"])
+
+		err = dsService.GetDataSource(ctx, &models.GetDataSourceQuery{OrgId: 1, Uid: "prom-us"})
+		require.ErrorIs(t, err, models.ErrDataSourceNotFound)
+	})
+
+	t.Run("Save doesn't take over data sources not managed by a template", func(t *testing.T) {
+		require.NoError(t, dsService.AddDataSource(ctx, &models.AddDataSourceCommand{
+			OrgId: 1, Name: "Manual", Uid: "prom-ap", Type: "prometheus", Access: models.DS_ACCESS_PROXY,
+		}))
+		taken := tmpl
+		taken.Instances = []datasourcetemplates.Instance{{Key: "ap", Variables: map[string]string{"region": "ap"}}}
+		err := svc.Save(ctx, &datasourcetemplates.SaveDataSourceTemplateCommand{OrgID: 1, Template: taken})
+		require.ErrorIs(t, err, datasourcetemplates.ErrDataSourceNotManaged)
+	})
+
+	t.Run("RotateCredentials replaces the secure settings of the data sources", func(t *testing.T) {
+		_, err := svc.RotateCredentials(ctx, &datasourcetemplates.RotateCredentialsCommand{OrgID: 1, UID: "prom"})
+		require.ErrorIs(t, err, datasourcetemplates.ErrNoCredentials)
+
+		res, err := svc.RotateCredentials(ctx, &datasourcetemplates.RotateCredentialsCommand{
+			OrgID: 1, UID: "prom", SecureJSONData: map[string]string{"httpHeaderValue1": "rotated"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"prom-eu"}, res.Rotated)
+		require.Empty(t, res.Failed)
+
+		ds := getDataSource(t, "prom-eu")
+		require.Equal(t, "https://metrics.eu.example.com", ds.Url)
+		require.True(t, ds.ReadOnly)
+		values, err := dsService.DecryptedValues(ctx, ds)
+		require.NoError(t, err)
+		require.Equal(t, "rotated", values["httpHeaderValue1"])
+	})
+
+	t.Run("Delete deletes the template with its data sources", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, &datasourcetemplates.DeleteDataSourceTemplateCommand{OrgID: 1, UID: "prom"}))
+
+		err := dsService.GetDataSource(ctx, &models.GetDataSourceQuery{OrgId: 1, Uid: "prom-eu"})
+		require.ErrorIs(t, err, models.ErrDataSourceNotFound)
+		require.Equal(t, "Manual", getDataSource(t, "prom-ap").Name)
+
+		_, err = svc.Get(ctx, &datasourcetemplates.GetDataSourceTemplateQuery{OrgID: 1, UID: "prom"})
+		require.ErrorIs(t, err, datasourcetemplates.ErrDataSourceTemplateNotFound)
+		list, err := svc.List(ctx, &datasourcetemplates.ListDataSourceTemplatesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+}
@@ -0,0 +1,38 @@
+package datasourcetemplatestest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/datasourcetemplates"
+)
+
+type FakeDataSourceTemplatesService struct {
+	ExpectedTemplate  *datasourcetemplates.DataSourceTemplate
+	ExpectedTemplates []*datasourcetemplates.DataSourceTemplate
+	ExpectedRotation  *datasourcetemplates.RotateCredentialsResult
+	ExpectedError     error
+}
+
+func NewDataSourceTemplatesServiceFake() *FakeDataSourceTemplatesService {
+	return &FakeDataSourceTemplatesService{}
+}
+
+func (f *FakeDataSourceTemplatesService) Get(ctx context.Context, query *datasourcetemplates.GetDataSourceTemplateQuery) (*datasourcetemplates.DataSourceTemplate, error) {
+	return f.ExpectedTemplate, f.ExpectedError
+}
+
+func (f *FakeDataSourceTemplatesService) List(ctx context.Context, query *datasourcetemplates.ListDataSourceTemplatesQuery) ([]*datasourcetemplates.DataSourceTemplate, error) {
+	return f.ExpectedTemplates, f.ExpectedError
+}
+
+func (f *FakeDataSourceTemplatesService) Save(ctx context.Context, cmd *datasourcetemplates.SaveDataSourceTemplateCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDataSourceTemplatesService) Delete(ctx context.Context, cmd *datasourcetemplates.DeleteDataSourceTemplateCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDataSourceTemplatesService) RotateCredentials(ctx context.Context, cmd *datasourcetemplates.RotateCredentialsCommand) (*datasourcetemplates.RotateCredentialsResult, error) {
+	return f.ExpectedRotation, f.ExpectedError
+}
@@ -0,0 +1,216 @@
+package datasourcetemplates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrDataSourceTemplateNotFound = errors.New("data source template not found")
+	ErrInvalidDataSourceTemplate  = errors.New("invalid data source template")
+	// ErrDataSourceNotManaged is returned when the uid of the data source of an
+	// instance is taken by a data source that isn't managed by a template.
+	ErrDataSourceNotManaged = errors.New("data source with the same uid is not managed by a template")
+	ErrNoCredentials        = errors.New("no secure settings to rotate")
+)
+
+// variablePattern matches the ${name} references to the variables of the instances.
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// DataSourceTemplate is a data source definition that references variables,
+// and the values of the variables for each data source it expands into.
+type DataSourceTemplate struct {
+	UID        string     `json:"uid"`
+	Name       string     `json:"name"`
+	Definition Definition `json:"definition"`
+	Instances  []Instance `json:"instances"`
+	Updated    time.Time  `json:"updated"`
+}
+
+// Definition holds the settings of the data sources of a template. The name,
+// url, database, user, basic auth user and the string values of the JSON data
+// can reference the variables of the instances as ${name}.
+type Definition struct {
+	Name            string                 `json:"name"`
+	Type            string                 `json:"type"`
+	Access          models.DsAccess        `json:"access"`
+	URL             string                 `json:"url"`
+	Database        string                 `json:"database,omitempty"`
+	User            string                 `json:"user,omitempty"`
+	BasicAuth       bool                   `json:"basicAuth,omitempty"`
+	BasicAuthUser   string                 `json:"basicAuthUser,omitempty"`
+	WithCredentials bool                   `json:"withCredentials,omitempty"`
+	JSONData        map[string]interface{} `json:"jsonData,omitempty"`
+}
+
+// Instance is one of the data sources of a template.
+type Instance struct {
+	// Key identifies the instance in the template. The uid of its data source is
+	// the uid of the template followed by the key.
+	Key       string            `json:"key"`
+	Variables map[string]string `json:"variables"`
+}
+
+// DataSourceUID returns the uid of the data source of the instance.
+func (t *DataSourceTemplate) DataSourceUID(key string) string {
+	return t.UID + "-" + key
+}
+
+type GetDataSourceTemplateQuery struct {
+	OrgID int64
+	UID   string
+}
+
+type ListDataSourceTemplatesQuery struct {
+	OrgID int64
+}
+
+type SaveDataSourceTemplateCommand struct {
+	OrgID    int64
+	UserID   int64
+	Template DataSourceTemplate
+	// SecureJSONData are the secure settings of the data sources the template
+	// creates. They are not stored with the template, and the data sources that
+	// exist already keep theirs.
+	SecureJSONData map[string]string
+}
+
+type DeleteDataSourceTemplateCommand struct {
+	OrgID int64
+	UID   string
+}
+
+type RotateCredentialsCommand struct {
+	OrgID int64
+	UID   string
+	// SecureJSONData replaces these secure settings of every data source of the
+	// template, the other secure settings are kept.
+	SecureJSONData map[string]string
+}
+
+// RotateCredentialsResult lists the uids of the data sources whose credentials
+// were rotated, and the errors of the ones that failed. The rotation carries on
+// when a data source fails, so that it can be retried for the failed ones only.
+type RotateCredentialsResult struct {
+	Rotated []string          `json:"rotated"`
+	Failed  map[string]string `json:"failed"`
+}
+
+// Validate checks that the data sources of the template have valid uids and
+// distinct names, and that every instance sets the variables the definition
+// references.
+func (t *DataSourceTemplate) Validate() error {
+	if t.UID == "" || !util.IsValidShortUID(t.UID) {
+		return fmt.Errorf("%w: invalid uid %q", ErrInvalidDataSourceTemplate, t.UID)
+	}
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDataSourceTemplate)
+	}
+	if t.Definition.Type == "" || t.Definition.Access == "" {
+		return fmt.Errorf("%w: the type and access of the data sources are required", ErrInvalidDataSourceTemplate)
+	}
+	variables := t.Definition.Variables()
+	keys := make(map[string]bool, len(t.Instances))
+	names := make(map[string]string, len(t.Instances))
+	for _, instance := range t.Instances {
+		uid := t.DataSourceUID(instance.Key)
+		if instance.Key == "" || !util.IsValidShortUID(instance.Key) || util.IsShortUIDTooLong(uid) {
+			return fmt.Errorf("%w: invalid instance key %q", ErrInvalidDataSourceTemplate, instance.Key)
+		}
+		if keys[instance.Key] {
+			return fmt.Errorf("%w: duplicate instance key %q", ErrInvalidDataSourceTemplate, instance.Key)
+		}
+		keys[instance.Key] = true
+
+		for _, name := range variables {
+			if _, ok := instance.Variables[name]; !ok {
+				return fmt.Errorf("%w: instance %q doesn't set variable %q", ErrInvalidDataSourceTemplate, instance.Key, name)
+			}
+		}
+		name := Interpolate(t.Definition.Name, instance.Variables)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%w: instances %q and %q have the same data source name %q", ErrInvalidDataSourceTemplate, other, instance.Key, name)
+		}
+		names[name] = instance.Key
+	}
+	return nil
+}
+
+// Variables returns the names of the variables the definition references, sorted.
+func (d *Definition) Variables() []string {
+	seen := map[string]bool{}
+	collect := func(text string) {
+		for _, m := range variablePattern.FindAllStringSubmatch(text, -1) {
+			seen[m[1]] = true
+		}
+	}
+	for _, text := range []string{d.Name, d.URL, d.Database, d.User, d.BasicAuthUser} {
+		collect(text)
+	}
+	walkStrings(d.JSONData, func(s string) string {
+		collect(s)
+		return s
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand returns the definition with the variables of the instance replaced.
+func (d *Definition) Expand(variables map[string]string) Definition {
+	expanded := *d
+	expanded.Name = Interpolate(d.Name, variables)
+	expanded.URL = Interpolate(d.URL, variables)
+	expanded.Database = Interpolate(d.Database, variables)
+	expanded.User = Interpolate(d.User, variables)
+	expanded.BasicAuthUser = Interpolate(d.BasicAuthUser, variables)
+	if d.JSONData != nil {
+		expanded.JSONData = walkStrings(d.JSONData, func(s string) string {
+			return Interpolate(s, variables)
+		}).(map[string]interface{})
+	}
+	return expanded
+}
+
+// Interpolate replaces the ${name} references to variables in the text. The
+// references to undefined variables are kept as they are.
+func Interpolate(text string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		if value, ok := variables[ref[2:len(ref)-1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// walkStrings returns a copy of the JSON value with f applied to its strings.
+func walkStrings(v interface{}, f func(string) string) interface{} {
+	switch value := v.(type) {
+	case string:
+		return f(value)
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[k] = walkStrings(item, f)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = walkStrings(item, f)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,16 @@
+package egresspolicy
+
+import (
+	"context"
+)
+
+// Service manages the egress policy of each organization, which restricts the
+// hosts its data sources and alert notifiers may connect to on top of the
+// egress policy of the instance.
+type Service interface {
+	// Get returns the policy of the organization, which doesn't restrict the
+	// connections unless it was changed.
+	Get(ctx context.Context, query *GetEgressPolicyQuery) (*OrgEgressPolicy, error)
+	// Save returns ErrInvalidRule if a rule of the policy is not a valid CIDR.
+	Save(ctx context.Context, cmd *SaveEgressPolicyCommand) error
+}
@@ -0,0 +1,114 @@
+package egresspolicyimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+)
+
+const (
+	kvNamespace = "egress-policy"
+	kvKey       = "org"
+	// cacheTTL bounds how long other instances keep enforcing an outdated
+	// policy after a change.
+	cacheTTL = time.Minute
+)
+
+// Service stores the egress policy of each organization as a JSON document in
+// the key-value store. The policy is enforced by the outgoing HTTP clients, it
+// is cached since it is read for every connection.
+type Service struct {
+	kv    kvstore.KVStore
+	cache *localcache.CacheService
+	log   log.Logger
+}
+
+func ProvideService(kv kvstore.KVStore, cache *localcache.CacheService) *Service {
+	s := &Service{
+		kv:    kv,
+		cache: cache,
+		log:   log.New("egresspolicy"),
+	}
+	httpclient.SetOrgEgressPolicyFunc(s.orgEgressPolicy)
+	return s
+}
+
+// cachedPolicy is the policy of an organization with its parsed rules.
+type cachedPolicy struct {
+	policy *egresspolicy.OrgEgressPolicy
+	rules  *httpclient.EgressPolicy
+}
+
+func (s *Service) Get(ctx context.Context, query *egresspolicy.GetEgressPolicyQuery) (*egresspolicy.OrgEgressPolicy, error) {
+	cached, err := s.get(ctx, query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	policy := *cached.policy
+	return &policy, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *egresspolicy.SaveEgressPolicyCommand) error {
+	if _, err := httpclient.NewEgressPolicy(cmd.Policy.AllowedHosts, cmd.Policy.DeniedHosts); err != nil {
+		return fmt.Errorf("%w: %s", egresspolicy.ErrInvalidRule, err)
+	}
+	raw, err := json.Marshal(cmd.Policy)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, cmd.OrgID, kvNamespace, kvKey, string(raw)); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, orgID int64) (*cachedPolicy, error) {
+	key := cacheKey(orgID)
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.(*cachedPolicy), nil
+	}
+
+	raw, ok, err := s.kv.Get(ctx, orgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	policy := &egresspolicy.OrgEgressPolicy{}
+	if ok {
+		if err := json.Unmarshal([]byte(raw), policy); err != nil {
+			return nil, err
+		}
+	}
+	cached := &cachedPolicy{policy: policy}
+	if len(policy.AllowedHosts) > 0 || len(policy.DeniedHosts) > 0 {
+		// the rules are validated when the policy is saved
+		if cached.rules, err = httpclient.NewEgressPolicy(policy.AllowedHosts, policy.DeniedHosts); err != nil {
+			return nil, err
+		}
+	}
+
+	s.cache.Set(key, cached, cacheTTL)
+	return cached, nil
+}
+
+// orgEgressPolicy returns the rules the outgoing connections made for the
+// organization are checked against.
+func (s *Service) orgEgressPolicy(ctx context.Context, orgID int64) (*httpclient.EgressPolicy, error) {
+	cached, err := s.get(ctx, orgID)
+	if err != nil {
+		s.log.Error("Failed to get the egress policy of the organization", "orgId", orgID, "error", err)
+		return nil, err
+	}
+	return cached.rules, nil
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("egress-policy-%d", orgID)
+}
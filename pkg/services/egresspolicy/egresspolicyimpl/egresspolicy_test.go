@@ -0,0 +1,79 @@
+package egresspolicyimpl
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationEgressPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(kvstore.ProvideService(ss), localcache.ProvideService())
+	t.Cleanup(func() { httpclient.SetOrgEgressPolicyFunc(nil) })
+	ctx := context.Background()
+
+	instancePolicy, err := httpclient.NewEgressPolicy(nil, httpclient.DefaultDeniedHosts)
+	require.NoError(t, err)
+	dial := instancePolicy.DialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, nil
+	})
+
+	t.Run("all hosts are allowed by default", func(t *testing.T) {
+		policy, err := svc.Get(ctx, &egresspolicy.GetEgressPolicyQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Empty(t, policy.AllowedHosts)
+		require.Empty(t, policy.DeniedHosts)
+
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 1), "tcp", "10.0.0.5:80")
+		require.NoError(t, err)
+	})
+
+	t.Run("saved policy applies to the connections of its org", func(t *testing.T) {
+		err := svc.Save(ctx, &egresspolicy.SaveEgressPolicyCommand{OrgID: 1, Policy: egresspolicy.OrgEgressPolicy{
+			AllowedHosts: []string{"10.0.0.0/8"},
+			DeniedHosts:  []string{"10.0.0.5"},
+		}})
+		require.NoError(t, err)
+
+		policy, err := svc.Get(ctx, &egresspolicy.GetEgressPolicyQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"10.0.0.0/8"}, policy.AllowedHosts)
+
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 1), "tcp", "10.0.0.5:80")
+		require.ErrorIs(t, err, httpclient.ErrEgressDenied)
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 1), "tcp", "192.168.0.1:80")
+		require.ErrorIs(t, err, httpclient.ErrEgressDenied)
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 1), "tcp", "10.0.0.6:80")
+		require.NoError(t, err)
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 2), "tcp", "10.0.0.5:80")
+		require.NoError(t, err)
+	})
+
+	t.Run("the policy of the instance can't be overridden", func(t *testing.T) {
+		err := svc.Save(ctx, &egresspolicy.SaveEgressPolicyCommand{OrgID: 1, Policy: egresspolicy.OrgEgressPolicy{
+			AllowedHosts: []string{"169.254.169.254"},
+		}})
+		require.NoError(t, err)
+
+		_, err = dial(httpclient.WithEgressOrgID(ctx, 1), "tcp", "169.254.169.254:80")
+		require.ErrorIs(t, err, httpclient.ErrEgressDenied)
+	})
+
+	t.Run("invalid CIDRs are rejected", func(t *testing.T) {
+		err := svc.Save(ctx, &egresspolicy.SaveEgressPolicyCommand{OrgID: 1, Policy: egresspolicy.OrgEgressPolicy{
+			DeniedHosts: []string{"10.0.0.0/99"},
+		}})
+		require.ErrorIs(t, err, egresspolicy.ErrInvalidRule)
+	})
+}
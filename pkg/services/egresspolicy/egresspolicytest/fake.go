@@ -0,0 +1,26 @@
+package egresspolicytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/egresspolicy"
+)
+
+type FakeEgressPolicyService struct {
+	ExpectedPolicy *egresspolicy.OrgEgressPolicy
+	ExpectedError  error
+	SavedCommand   *egresspolicy.SaveEgressPolicyCommand
+}
+
+func NewEgressPolicyServiceFake() *FakeEgressPolicyService {
+	return &FakeEgressPolicyService{ExpectedPolicy: &egresspolicy.OrgEgressPolicy{}}
+}
+
+func (f *FakeEgressPolicyService) Get(ctx context.Context, query *egresspolicy.GetEgressPolicyQuery) (*egresspolicy.OrgEgressPolicy, error) {
+	return f.ExpectedPolicy, f.ExpectedError
+}
+
+func (f *FakeEgressPolicyService) Save(ctx context.Context, cmd *egresspolicy.SaveEgressPolicyCommand) error {
+	f.SavedCommand = cmd
+	return f.ExpectedError
+}
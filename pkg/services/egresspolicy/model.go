@@ -0,0 +1,27 @@
+package egresspolicy
+
+import (
+	"errors"
+)
+
+var ErrInvalidRule = errors.New("invalid egress policy rule")
+
+// OrgEgressPolicy restricts the hosts the data sources and alert notifiers of an
+// organization may connect to. The rules are host names, optionally starting
+// with a "*." wildcard, IP addresses or CIDRs, as for the policy of the instance.
+// The hosts denied by the policy of the instance can't be allowed.
+type OrgEgressPolicy struct {
+	// AllowedHosts are the only hosts that may be connected to, unless empty.
+	AllowedHosts []string `json:"allowedHosts"`
+	// DeniedHosts are never connected to, even if they match AllowedHosts.
+	DeniedHosts []string `json:"deniedHosts"`
+}
+
+type GetEgressPolicyQuery struct {
+	OrgID int64
+}
+
+type SaveEgressPolicyCommand struct {
+	OrgID  int64
+	Policy OrgEgressPolicy
+}
@@ -0,0 +1,19 @@
+package emaildelivery
+
+import (
+	"context"
+)
+
+// Service keeps a log of the emails sent with the mail providers, and of the
+// emails the providers report as bounced.
+type Service interface {
+	// Record adds an attempt to send an email to the delivery log.
+	Record(ctx context.Context, cmd *RecordCommand) error
+	Search(ctx context.Context, query *SearchQuery) ([]*Delivery, error)
+	// Bounce marks the deliveries of the bounced email as bounced, and returns
+	// the number of deliveries marked.
+	Bounce(ctx context.Context, cmd *BounceCommand) (int64, error)
+
+	// DeleteExpired removes the deliveries older than the configured retention.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
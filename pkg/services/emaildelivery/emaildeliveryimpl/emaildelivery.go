@@ -0,0 +1,105 @@
+package emaildeliveryimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	// maxErrorLength keeps the errors of the mail providers, which can quote
+	// the whole response of the provider, within reason.
+	maxErrorLength = 1000
+)
+
+type Service struct {
+	cfg   *setting.Cfg
+	store store
+	log   log.Logger
+	now   func() time.Time
+}
+
+func ProvideService(db db.DB, cfg *setting.Cfg) emaildelivery.Service {
+	return &Service{
+		cfg: cfg,
+		store: &sqlStore{
+			db: db,
+		},
+		log: log.New("emaildelivery"),
+		now: time.Now,
+	}
+}
+
+func (s *Service) Record(ctx context.Context, cmd *emaildelivery.RecordCommand) error {
+	now := s.now()
+	status, errMsg := emaildelivery.StatusSent, ""
+	if cmd.Error != nil {
+		status, errMsg = emaildelivery.StatusFailed, truncate(cmd.Error.Error(), maxErrorLength)
+	}
+
+	deliveries := make([]*emaildelivery.Delivery, 0, len(cmd.Recipients))
+	for _, recipient := range cmd.Recipients {
+		deliveries = append(deliveries, &emaildelivery.Delivery{
+			Provider:  cmd.Provider,
+			MessageID: cmd.MessageID,
+			Recipient: recipient,
+			Subject:   truncate(cmd.Subject, 255),
+			Status:    status,
+			Error:     errMsg,
+			Created:   now,
+			Updated:   now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.store.Insert(ctx, deliveries)
+}
+
+func (s *Service) Search(ctx context.Context, query *emaildelivery.SearchQuery) ([]*emaildelivery.Delivery, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) Bounce(ctx context.Context, cmd *emaildelivery.BounceCommand) (int64, error) {
+	if cmd.MessageID == "" && cmd.Recipient == "" {
+		return 0, emaildelivery.ErrInvalidBounce
+	}
+	cmd.Reason = truncate(cmd.Reason, maxErrorLength)
+
+	bounced, err := s.store.Bounce(ctx, cmd, s.now())
+	if err != nil {
+		return 0, err
+	}
+	if bounced > 0 {
+		s.log.Warn("Email bounced", "provider", cmd.Provider, "recipient", cmd.Recipient, "messageId", cmd.MessageID, "reason", cmd.Reason)
+	} else {
+		s.log.Debug("Bounce doesn't match any delivery", "provider", cmd.Provider, "recipient", cmd.Recipient, "messageId", cmd.MessageID)
+	}
+	return bounced, nil
+}
+
+func (s *Service) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.cfg.Smtp.DeliveryLogMaxAge <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.Smtp.DeliveryLogMaxAge))
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
@@ -0,0 +1,82 @@
+package emaildeliveryimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationEmailDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	cfg := setting.NewCfg()
+	cfg.Smtp.DeliveryLogMaxAge = time.Hour
+	now := time.Now().Truncate(time.Second)
+	s := &Service{cfg: cfg, store: &sqlStore{db: ss}, log: log.New("test"), now: func() time.Time { return now }}
+
+	require.NoError(t, s.Record(ctx, &emaildelivery.RecordCommand{Provider: "smtp", Recipients: []string{"a@example.com"}, Subject: "Alert", Error: errors.New("connection refused")}))
+	require.NoError(t, s.Record(ctx, &emaildelivery.RecordCommand{Provider: "sendgrid", MessageID: "msg-1", Recipients: []string{"a@example.com", "b@example.com"}, Subject: "Alert"}))
+	require.NoError(t, s.Record(ctx, &emaildelivery.RecordCommand{Provider: "mailgun", MessageID: "msg-2", Recipients: []string{"b@example.com"}, Subject: "Invite"}))
+
+	t.Run("Records a delivery per recipient", func(t *testing.T) {
+		deliveries, err := s.Search(ctx, &emaildelivery.SearchQuery{})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 4)
+		assert.Equal(t, "mailgun", deliveries[0].Provider)
+
+		failed, err := s.Search(ctx, &emaildelivery.SearchQuery{Status: emaildelivery.StatusFailed})
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		assert.Equal(t, "connection refused", failed[0].Error)
+
+		deliveries, err = s.Search(ctx, &emaildelivery.SearchQuery{Recipient: "b@example.com", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "msg-2", deliveries[0].MessageID)
+	})
+
+	t.Run("Bounces match the message id and the recipient", func(t *testing.T) {
+		bounced, err := s.Bounce(ctx, &emaildelivery.BounceCommand{Provider: "sendgrid", MessageID: "msg-1", Recipient: "b@example.com", Reason: "mailbox does not exist"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), bounced)
+
+		deliveries, err := s.Search(ctx, &emaildelivery.SearchQuery{Status: emaildelivery.StatusBounced})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "b@example.com", deliveries[0].Recipient)
+		assert.Equal(t, "mailbox does not exist", deliveries[0].Error)
+	})
+
+	t.Run("Bounces without message id match the last delivery to the recipient", func(t *testing.T) {
+		bounced, err := s.Bounce(ctx, &emaildelivery.BounceCommand{Provider: "mailgun", Recipient: "b@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), bounced)
+
+		bounced, err = s.Bounce(ctx, &emaildelivery.BounceCommand{Provider: "smtp", Recipient: "a@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), bounced, "failed deliveries can't bounce")
+
+		_, err = s.Bounce(ctx, &emaildelivery.BounceCommand{Provider: "smtp"})
+		require.ErrorIs(t, err, emaildelivery.ErrInvalidBounce)
+	})
+
+	t.Run("Deletes the deliveries older than the retention", func(t *testing.T) {
+		later := &Service{cfg: cfg, store: s.store, log: s.log, now: func() time.Time { return now.Add(2 * time.Hour) }}
+		deleted, err := later.DeleteExpired(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), deleted)
+	})
+}
@@ -0,0 +1,87 @@
+package emaildeliveryimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, deliveries []*emaildelivery.Delivery) error
+	Search(ctx context.Context, query *emaildelivery.SearchQuery) ([]*emaildelivery.Delivery, error)
+	Bounce(ctx context.Context, cmd *emaildelivery.BounceCommand, now time.Time) (int64, error)
+	DeleteOlderThan(ctx context.Context, t time.Time) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, deliveries []*emaildelivery.Delivery) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.InsertMulti(deliveries)
+		return err
+	})
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *emaildelivery.SearchQuery) ([]*emaildelivery.Delivery, error) {
+	deliveries := make([]*emaildelivery.Delivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Desc("id").Limit(query.Limit)
+		if query.Status != "" {
+			q = q.Where("status=?", query.Status)
+		}
+		if query.Recipient != "" {
+			q = q.And("recipient=?", query.Recipient)
+		}
+		return q.Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+func (s *sqlStore) Bounce(ctx context.Context, cmd *emaildelivery.BounceCommand, now time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("provider=? AND status<>?", cmd.Provider, emaildelivery.StatusFailed)
+		if cmd.MessageID != "" {
+			q = q.And("message_id=?", cmd.MessageID)
+		}
+		if cmd.Recipient != "" {
+			q = q.And("recipient=?", cmd.Recipient)
+		}
+		if cmd.MessageID == "" {
+			// without a message id, the bounce is for the last email sent to the recipient
+			q = q.Desc("id").Limit(1)
+		}
+
+		var ids []int64
+		if err := q.Table("email_delivery").Cols("id").Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var err error
+		affected, err = sess.Table("email_delivery").In("id", ids).Cols("status", "error", "updated").Update(&emaildelivery.Delivery{
+			Status:  emaildelivery.StatusBounced,
+			Error:   cmd.Reason,
+			Updated: now,
+		})
+		return err
+	})
+	return affected, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		affected, err = sess.Where("created < ?", t).Delete(&emaildelivery.Delivery{})
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,38 @@
+package emaildeliverytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+)
+
+type FakeEmailDeliveryService struct {
+	ExpectedDeliveries []*emaildelivery.Delivery
+	ExpectedBounced    int64
+	ExpectedError      error
+
+	Recorded []*emaildelivery.RecordCommand
+	Bounced  []*emaildelivery.BounceCommand
+}
+
+func NewEmailDeliveryServiceFake() *FakeEmailDeliveryService {
+	return &FakeEmailDeliveryService{}
+}
+
+func (f *FakeEmailDeliveryService) Record(ctx context.Context, cmd *emaildelivery.RecordCommand) error {
+	f.Recorded = append(f.Recorded, cmd)
+	return f.ExpectedError
+}
+
+func (f *FakeEmailDeliveryService) Search(ctx context.Context, query *emaildelivery.SearchQuery) ([]*emaildelivery.Delivery, error) {
+	return f.ExpectedDeliveries, f.ExpectedError
+}
+
+func (f *FakeEmailDeliveryService) Bounce(ctx context.Context, cmd *emaildelivery.BounceCommand) (int64, error) {
+	f.Bounced = append(f.Bounced, cmd)
+	return f.ExpectedBounced, f.ExpectedError
+}
+
+func (f *FakeEmailDeliveryService) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, f.ExpectedError
+}
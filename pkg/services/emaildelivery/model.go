@@ -0,0 +1,65 @@
+package emaildelivery
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown mail provider")
+	ErrInvalidBounce   = errors.New("invalid bounce notification")
+)
+
+// Status is the status of a delivery.
+type Status string
+
+const (
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed"
+	StatusBounced Status = "bounced"
+)
+
+// Delivery is an attempt to send an email to one recipient with a mail provider.
+type Delivery struct {
+	ID int64 `xorm:"pk autoincr 'id'" json:"id"`
+	// Provider is the mail provider the email was sent with: smtp, sendgrid, ses or mailgun.
+	Provider string `xorm:"provider" json:"provider"`
+	// MessageID is the identifier the mail provider gave to the email, it's
+	// used to match the bounces the provider reports.
+	MessageID string    `xorm:"message_id" json:"messageId,omitempty"`
+	Recipient string    `xorm:"recipient" json:"recipient"`
+	Subject   string    `xorm:"subject" json:"subject"`
+	Status    Status    `xorm:"status" json:"status"`
+	Error     string    `xorm:"error" json:"error,omitempty"`
+	Created   time.Time `xorm:"created" json:"created"`
+	Updated   time.Time `xorm:"updated" json:"updated"`
+}
+
+func (d Delivery) TableName() string {
+	return "email_delivery"
+}
+
+type RecordCommand struct {
+	Provider   string
+	MessageID  string
+	Recipients []string
+	Subject    string
+	// Error is the error the mail provider failed with, the delivery is
+	// recorded as sent when it is nil.
+	Error error
+}
+
+type SearchQuery struct {
+	Status    Status `json:"status"`
+	Recipient string `json:"recipient"`
+	Limit     int    `json:"limit"`
+}
+
+// BounceCommand matches the deliveries by message id when it is set, or else
+// by the last delivery to the recipient with the provider.
+type BounceCommand struct {
+	Provider  string
+	MessageID string
+	Recipient string
+	Reason    string
+}
@@ -0,0 +1,186 @@
+package emailtemplates
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+// Variable documents a value the data of an email template has.
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Definition describes an email template organizations can customize.
+type Definition struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Variables   []Variable `json:"variables"`
+	// example returns the data the template is previewed with.
+	example func() map[string]interface{}
+}
+
+// ExampleData returns the data used to preview the template.
+func (d Definition) ExampleData() map[string]interface{} {
+	return d.example()
+}
+
+// commonVariables are set for all the email templates.
+var commonVariables = []Variable{
+	{Name: "AppUrl", Description: "The root URL of Grafana."},
+	{Name: "BuildVersion", Description: "The version of Grafana."},
+	{Name: "BuildStamp", Description: "The build timestamp of Grafana."},
+	{Name: "EmailCodeValidHours", Description: "How many hours the codes sent by email are valid."},
+	{Name: "Subject", Description: "Set the subject from the body with {{Subject .Subject \"...\"}}, when the template has no subject."},
+}
+
+var definitions = []Definition{
+	{
+		Name:        "invited_to_org",
+		Description: "Sent to an existing user added to an organization.",
+		Variables: []Variable{
+			{Name: "Name", Description: "The name of the user."},
+			{Name: "OrgName", Description: "The name of the organization."},
+			{Name: "InvitedBy", Description: "The name of the user who added the user to the organization."},
+		},
+		example: func() map[string]interface{} {
+			return map[string]interface{}{
+				"Name":      "Jane Doe",
+				"OrgName":   "Main Org.",
+				"InvitedBy": "admin",
+			}
+		},
+	},
+	{
+		Name:        "new_user_invite",
+		Description: "Sent to invite a new user to join an organization.",
+		Variables: []Variable{
+			{Name: "Name", Description: "The name, or the email, of the invited user."},
+			{Name: "OrgName", Description: "The name of the organization."},
+			{Name: "Email", Description: "The email of the user who sent the invite."},
+			{Name: "LinkUrl", Description: "The URL to accept the invite."},
+			{Name: "InvitedBy", Description: "The name of the user who sent the invite."},
+		},
+		example: func() map[string]interface{} {
+			return map[string]interface{}{
+				"Name":      "jane.doe@example.com",
+				"OrgName":   "Main Org.",
+				"Email":     "admin@example.com",
+				"LinkUrl":   "http://localhost:3000/invite/example",
+				"InvitedBy": "admin",
+			}
+		},
+	},
+	{
+		Name:        "reset_password",
+		Description: "Sent when a user asks to reset their password.",
+		Variables: []Variable{
+			{Name: "Name", Description: "The name of the user."},
+			{Name: "Code", Description: "The code to reset the password, valid for EmailCodeValidHours hours."},
+		},
+		example: func() map[string]interface{} {
+			return map[string]interface{}{
+				"Name": "Jane Doe",
+				"Code": "example",
+			}
+		},
+	},
+	{
+		Name:        "ng_alert_notification",
+		Description: "Sent by the email contact points of Grafana Alerting, with the alerts of a notification. The subject of the contact point replaces the subject of the template.",
+		Variables: []Variable{
+			{Name: "Title", Description: "The title of the notification."},
+			{Name: "Message", Description: "The message of the contact point."},
+			{Name: "Status", Description: "The status of the notification, firing or resolved."},
+			{Name: "Alerts", Description: "The alerts, with Status, Labels, Annotations, StartsAt, EndsAt, GeneratorURL, SilenceURL, DashboardURL, PanelURL, ValueString, ImageURL and EmbeddedImage. Alerts.Firing and Alerts.Resolved select the alerts by status."},
+			{Name: "GroupLabels", Description: "The labels the alerts are grouped by."},
+			{Name: "CommonLabels", Description: "The labels all the alerts have."},
+			{Name: "CommonAnnotations", Description: "The annotations all the alerts have."},
+			{Name: "ExternalURL", Description: "The URL of Grafana."},
+			{Name: "RuleUrl", Description: "The URL of the alert rule list."},
+			{Name: "AlertPageUrl", Description: "The URL of the firing alerts."},
+		},
+		example: func() map[string]interface{} {
+			return map[string]interface{}{
+				"Title":   "[FIRING:1] HighLatency (api)",
+				"Message": "",
+				"Status":  "firing",
+				"Alerts": exampleAlerts{
+					{
+						Status:       "firing",
+						Labels:       template.KV{"alertname": "HighLatency", "service": "api"},
+						Annotations:  template.KV{"summary": "The latency of the api service is above 500ms."},
+						StartsAt:     time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+						GeneratorURL: "http://localhost:3000/alerting/grafana/example/view",
+						SilenceURL:   "http://localhost:3000/alerting/silence/new",
+						ValueString:  "[ var='B' labels={service=api} value=0.73 ]",
+					},
+				},
+				"GroupLabels":       template.KV{"alertname": "HighLatency"},
+				"CommonLabels":      template.KV{"alertname": "HighLatency", "service": "api"},
+				"CommonAnnotations": template.KV{"summary": "The latency of the api service is above 500ms."},
+				"ExternalURL":       "http://localhost:3000/",
+				"RuleUrl":           "http://localhost:3000/alerting/list",
+				"AlertPageUrl":      "http://localhost:3000/alerting/list?alertState=firing&view=state",
+			}
+		},
+	},
+}
+
+// Definitions returns the email templates organizations can customize.
+func Definitions() []Definition {
+	return definitions
+}
+
+// GetDefinition returns the definition of the template with the name.
+func GetDefinition(name string) (Definition, bool) {
+	for _, d := range definitions {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// CommonVariables returns the variables all the email templates have.
+func CommonVariables() []Variable {
+	return commonVariables
+}
+
+// exampleAlert has the fields of the alerts of Grafana Alerting notifications.
+type exampleAlert struct {
+	Status        string
+	Labels        template.KV
+	Annotations   template.KV
+	StartsAt      time.Time
+	EndsAt        time.Time
+	GeneratorURL  string
+	Fingerprint   string
+	SilenceURL    string
+	DashboardURL  string
+	PanelURL      string
+	ValueString   string
+	ImageURL      string
+	EmbeddedImage string
+}
+
+type exampleAlerts []exampleAlert
+
+func (as exampleAlerts) Firing() []exampleAlert {
+	return as.withStatus("firing")
+}
+
+func (as exampleAlerts) Resolved() []exampleAlert {
+	return as.withStatus("resolved")
+}
+
+func (as exampleAlerts) withStatus(status string) []exampleAlert {
+	res := []exampleAlert{}
+	for _, a := range as {
+		if a.Status == status {
+			res = append(res, a)
+		}
+	}
+	return res
+}
@@ -0,0 +1,17 @@
+package emailtemplates
+
+import (
+	"context"
+)
+
+// Service manages the email templates an organization uses instead of the
+// default templates shipped in public/emails.
+type Service interface {
+	// Get returns the template the organization saved, or ErrEmailTemplateNotFound.
+	Get(ctx context.Context, query *GetEmailTemplateQuery) (*EmailTemplate, error)
+	// List returns the templates the organization saved.
+	List(ctx context.Context, query *ListEmailTemplatesQuery) ([]*EmailTemplate, error)
+	Save(ctx context.Context, cmd *SaveEmailTemplateCommand) error
+	// Delete restores the default template.
+	Delete(ctx context.Context, cmd *DeleteEmailTemplateCommand) error
+}
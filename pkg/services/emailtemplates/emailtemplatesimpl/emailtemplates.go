@@ -0,0 +1,80 @@
+package emailtemplatesimpl
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+)
+
+const kvNamespace = "email-templates"
+
+// Service stores each email template of an organization as a JSON document in
+// the key-value store, with the name of the template as key.
+type Service struct {
+	kv  kvstore.KVStore
+	now func() time.Time
+}
+
+func ProvideService(kv kvstore.KVStore) emailtemplates.Service {
+	return &Service{
+		kv:  kv,
+		now: time.Now,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *emailtemplates.GetEmailTemplateQuery) (*emailtemplates.EmailTemplate, error) {
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, query.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, emailtemplates.ErrEmailTemplateNotFound
+	}
+
+	var result emailtemplates.EmailTemplate
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) List(ctx context.Context, query *emailtemplates.ListEmailTemplatesQuery) ([]*emailtemplates.EmailTemplate, error) {
+	keys, err := s.kv.Keys(ctx, query.OrgID, kvNamespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*emailtemplates.EmailTemplate, 0, len(keys))
+	for _, key := range keys {
+		tmpl, err := s.Get(ctx, &emailtemplates.GetEmailTemplateQuery{OrgID: query.OrgID, Name: key.Key})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tmpl)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *emailtemplates.SaveEmailTemplateCommand) error {
+	if err := cmd.Template.Validate(); err != nil {
+		return err
+	}
+
+	cmd.Template.Updated = s.now()
+	raw, err := json.Marshal(cmd.Template)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, cmd.OrgID, kvNamespace, cmd.Template.Name, string(raw))
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *emailtemplates.DeleteEmailTemplateCommand) error {
+	return s.kv.Del(ctx, cmd.OrgID, kvNamespace, cmd.Name)
+}
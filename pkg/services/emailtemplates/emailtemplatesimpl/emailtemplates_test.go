@@ -0,0 +1,71 @@
+package emailtemplatesimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationEmailTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	service := ProvideService(kvstore.ProvideService(ss))
+
+	t.Run("Get without saved template returns not found", func(t *testing.T) {
+		_, err := service.Get(context.Background(), &emailtemplates.GetEmailTemplateQuery{OrgID: 1, Name: "reset_password"})
+		require.ErrorIs(t, err, emailtemplates.ErrEmailTemplateNotFound)
+	})
+
+	t.Run("Save rejects unknown and empty templates", func(t *testing.T) {
+		err := service.Save(context.Background(), &emailtemplates.SaveEmailTemplateCommand{
+			OrgID:    1,
+			Template: emailtemplates.EmailTemplate{Name: "signup_started", Subject: "Welcome"},
+		})
+		require.ErrorIs(t, err, emailtemplates.ErrUnknownEmailTemplate)
+
+		err = service.Save(context.Background(), &emailtemplates.SaveEmailTemplateCommand{
+			OrgID:    1,
+			Template: emailtemplates.EmailTemplate{Name: "reset_password"},
+		})
+		require.ErrorIs(t, err, emailtemplates.ErrEmptyEmailTemplate)
+	})
+
+	t.Run("Save, list and delete templates is scoped per org", func(t *testing.T) {
+		for _, name := range []string{"reset_password", "invited_to_org"} {
+			err := service.Save(context.Background(), &emailtemplates.SaveEmailTemplateCommand{
+				OrgID:    1,
+				Template: emailtemplates.EmailTemplate{Name: name, Subject: "ACME: " + name, Text: "Hello {{.Name}}"},
+			})
+			require.NoError(t, err)
+		}
+
+		res, err := service.Get(context.Background(), &emailtemplates.GetEmailTemplateQuery{OrgID: 1, Name: "reset_password"})
+		require.NoError(t, err)
+		require.Equal(t, "ACME: reset_password", res.Subject)
+		require.Equal(t, "Hello {{.Name}}", res.Text)
+		require.False(t, res.Updated.IsZero())
+
+		list, err := service.List(context.Background(), &emailtemplates.ListEmailTemplatesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		require.Equal(t, "invited_to_org", list[0].Name)
+		require.Equal(t, "reset_password", list[1].Name)
+
+		list, err = service.List(context.Background(), &emailtemplates.ListEmailTemplatesQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Empty(t, list)
+
+		err = service.Delete(context.Background(), &emailtemplates.DeleteEmailTemplateCommand{OrgID: 1, Name: "reset_password"})
+		require.NoError(t, err)
+
+		_, err = service.Get(context.Background(), &emailtemplates.GetEmailTemplateQuery{OrgID: 1, Name: "reset_password"})
+		require.ErrorIs(t, err, emailtemplates.ErrEmailTemplateNotFound)
+	})
+}
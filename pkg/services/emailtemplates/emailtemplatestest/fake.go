@@ -0,0 +1,33 @@
+package emailtemplatestest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+)
+
+type FakeEmailTemplatesService struct {
+	ExpectedTemplate  *emailtemplates.EmailTemplate
+	ExpectedTemplates []*emailtemplates.EmailTemplate
+	ExpectedError     error
+}
+
+func NewEmailTemplatesServiceFake() *FakeEmailTemplatesService {
+	return &FakeEmailTemplatesService{}
+}
+
+func (f *FakeEmailTemplatesService) Get(ctx context.Context, query *emailtemplates.GetEmailTemplateQuery) (*emailtemplates.EmailTemplate, error) {
+	return f.ExpectedTemplate, f.ExpectedError
+}
+
+func (f *FakeEmailTemplatesService) List(ctx context.Context, query *emailtemplates.ListEmailTemplatesQuery) ([]*emailtemplates.EmailTemplate, error) {
+	return f.ExpectedTemplates, f.ExpectedError
+}
+
+func (f *FakeEmailTemplatesService) Save(ctx context.Context, cmd *emailtemplates.SaveEmailTemplateCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeEmailTemplatesService) Delete(ctx context.Context, cmd *emailtemplates.DeleteEmailTemplateCommand) error {
+	return f.ExpectedError
+}
@@ -0,0 +1,54 @@
+package emailtemplates
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmailTemplateNotFound = errors.New("email template not found")
+	ErrUnknownEmailTemplate  = errors.New("unknown email template")
+	ErrEmptyEmailTemplate    = errors.New("email template must set a subject, an HTML body or a text body")
+)
+
+// EmailTemplate replaces a default email template for an organization. The
+// fields are Go templates, with the variables of the template definition. The
+// empty fields fall back to the default template.
+type EmailTemplate struct {
+	Name    string    `json:"name"`
+	Subject string    `json:"subject,omitempty"`
+	HTML    string    `json:"html,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+type GetEmailTemplateQuery struct {
+	OrgID int64
+	Name  string
+}
+
+type ListEmailTemplatesQuery struct {
+	OrgID int64
+}
+
+type SaveEmailTemplateCommand struct {
+	OrgID    int64
+	Template EmailTemplate
+}
+
+type DeleteEmailTemplateCommand struct {
+	OrgID int64
+	Name  string
+}
+
+// Validate checks that the template replaces a template that can be customized,
+// and that it sets at least one of its parts.
+func (t *EmailTemplate) Validate() error {
+	if _, ok := GetDefinition(t.Name); !ok {
+		return ErrUnknownEmailTemplate
+	}
+	if t.Subject == "" && t.HTML == "" && t.Text == "" {
+		return ErrEmptyEmailTemplate
+	}
+	return nil
+}
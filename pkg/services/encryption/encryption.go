@@ -2,6 +2,11 @@ package encryption
 
 import "context"
 
+const (
+	AesCfb = "aes-cfb"
+	AesGcm = "aes-gcm"
+)
+
 // Internal must not be used for general purpose encryption.
 // This service is used as an internal component for envelope encryption
 // and for very specific few use cases that still require legacy encryption.
@@ -12,24 +12,69 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"golang.org/x/crypto/pbkdf2"
 )
 
 // Service must not be used for encryption,
 // use secrets.Service implementing envelope encryption instead.
-type Service struct{}
+type Service struct {
+	algorithm string
+}
 
+// ProvideService returns a Service encrypting with the legacy AES-CFB
+// algorithm.
 func ProvideService() *Service {
-	return &Service{}
+	return &Service{algorithm: encryption.AesCfb}
+}
+
+// ProvideServiceFromConfig returns a Service encrypting with the algorithm
+// configured in [security.encryption]. Payloads encrypted with any supported
+// algorithm can always be decrypted, so that existing secrets can be
+// re-encrypted.
+func ProvideServiceFromConfig(cfg *setting.Cfg) (*Service, error) {
+	alg, err := ConfiguredAlgorithm(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{algorithm: alg}, nil
+}
+
+// ConfiguredAlgorithm returns the algorithm new secrets are encrypted with.
+// It defaults to AES-GCM in FIPS mode, where AES-CFB is not allowed, and to
+// AES-CFB otherwise for backwards compatibility with older Grafana versions.
+func ConfiguredAlgorithm(cfg *setting.Cfg) (string, error) {
+	switch cfg.SecretsEncryptionAlgorithm {
+	case "":
+		if cfg.FIPSMode {
+			return encryption.AesGcm, nil
+		}
+		return encryption.AesCfb, nil
+	case encryption.AesGcm:
+		return encryption.AesGcm, nil
+	case encryption.AesCfb:
+		if cfg.FIPSMode {
+			return "", fmt.Errorf("encryption algorithm %s is not allowed in FIPS mode", encryption.AesCfb)
+		}
+		return encryption.AesCfb, nil
+	default:
+		return "", fmt.Errorf("unsupported encryption algorithm %q", cfg.SecretsEncryptionAlgorithm)
+	}
 }
 
 const (
 	saltLength                   = 8
-	aesCfb                       = "aes-cfb"
 	encryptionAlgorithmDelimiter = '*'
 )
 
+// Algorithm returns the algorithm a payload has been encrypted with.
+func Algorithm(payload []byte) (string, error) {
+	alg, _, err := deriveEncryptionAlgorithm(payload)
+	return alg, err
+}
+
 func (s *Service) Decrypt(_ context.Context, payload []byte, secret string) ([]byte, error) {
 	alg, payload, err := deriveEncryptionAlgorithm(payload)
 	if err != nil {
@@ -51,8 +96,10 @@ func (s *Service) Decrypt(_ context.Context, payload []byte, secret string) ([]b
 	}
 
 	switch alg {
-	case aesCfb:
+	case encryption.AesCfb:
 		return decryptCFB(block, payload)
+	case encryption.AesGcm:
+		return decryptGCM(block, payload)
 	default:
 		return nil, errors.New("unsupported encryption algorithm")
 	}
@@ -64,13 +111,13 @@ func deriveEncryptionAlgorithm(payload []byte) (string, []byte, error) {
 	}
 
 	if payload[0] != encryptionAlgorithmDelimiter {
-		return aesCfb, payload, nil // backwards compatibility
+		return encryption.AesCfb, payload, nil // backwards compatibility
 	}
 
 	payload = payload[1:]
 	algDelim := bytes.Index(payload, []byte{encryptionAlgorithmDelimiter})
 	if algDelim == -1 {
-		return aesCfb, payload, nil // backwards compatibility
+		return encryption.AesCfb, payload, nil // backwards compatibility
 	}
 
 	algB64 := payload[:algDelim]
@@ -104,6 +151,21 @@ func decryptCFB(block cipher.Block, payload []byte) ([]byte, error) {
 	return payloadDst, nil
 }
 
+func decryptGCM(block cipher.Block, payload []byte) ([]byte, error) {
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < saltLength+gcm.NonceSize() {
+		return nil, errors.New("payload too short")
+	}
+
+	nonce := payload[saltLength : saltLength+gcm.NonceSize()]
+	ciphertext := payload[saltLength+gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
 func (s *Service) Encrypt(_ context.Context, payload []byte, secret string) ([]byte, error) {
 	salt, err := util.GetRandomString(saltLength)
 	if err != nil {
@@ -120,6 +182,10 @@ func (s *Service) Encrypt(_ context.Context, payload []byte, secret string) ([]b
 		return nil, err
 	}
 
+	if s.algorithm == encryption.AesGcm {
+		return encryptGCM(block, payload, salt)
+	}
+
 	// The IV needs to be unique, but not secure. Therefore it's common to
 	// include it at the beginning of the ciphertext.
 	ciphertext := make([]byte, saltLength+aes.BlockSize+len(payload))
@@ -135,6 +201,29 @@ func (s *Service) Encrypt(_ context.Context, payload []byte, secret string) ([]b
 	return ciphertext, nil
 }
 
+func encryptGCM(block cipher.Block, payload []byte, salt string) ([]byte, error) {
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, base64.RawStdEncoding.EncodedLen(len(encryption.AesGcm))+2)
+	prefix[0] = encryptionAlgorithmDelimiter
+	base64.RawStdEncoding.Encode(prefix[1:], []byte(encryption.AesGcm))
+	prefix[len(prefix)-1] = encryptionAlgorithmDelimiter
+
+	ciphertext := make([]byte, 0, len(prefix)+saltLength+len(nonce)+len(payload)+gcm.Overhead())
+	ciphertext = append(ciphertext, prefix...)
+	ciphertext = append(ciphertext, salt...)
+	ciphertext = append(ciphertext, nonce...)
+	return gcm.Seal(ciphertext, nonce, payload, nil), nil
+}
+
 func (s *Service) EncryptJsonData(ctx context.Context, kv map[string]string, secret string) (map[string][]byte, error) {
 	encrypted := make(map[string][]byte)
 	for key, value := range kv {
@@ -6,6 +6,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestEncryption(t *testing.T) {
@@ -40,17 +43,17 @@ func TestEncryption(t *testing.T) {
 		assert.Equal(t, "unable to derive encryption algorithm", err.Error())
 	})
 
-	t.Run("decrypting ciphertext with aes-gcm as encryption algorithm should return error", func(t *testing.T) {
+	t.Run("decrypting ciphertext with aes-gcm as encryption algorithm do not fail", func(t *testing.T) {
 		// Raw slice of bytes that corresponds to the following ciphertext:
 		// - 'grafana' as payload
 		// - '1234' as secret
 		// - 'aes-gcm' as encryption algorithm
 		// With no encryption algorithm metadata.
 		ciphertext := []byte{42, 89, 87, 86, 122, 76, 87, 100, 106, 98, 81, 42, 48, 99, 55, 50, 51, 48, 83, 66, 20, 99, 47, 238, 61, 44, 129, 125, 14, 37, 162, 230, 47, 31, 104, 70, 144, 223, 26, 51, 180, 17, 76, 52, 36, 93, 17, 203, 99, 158, 219, 102, 74, 173, 74}
-		_, err := svc.Decrypt(context.Background(), ciphertext, "1234")
-		require.Error(t, err)
+		decrypted, err := svc.Decrypt(context.Background(), ciphertext, "1234")
+		require.NoError(t, err)
 
-		assert.Equal(t, "unsupported encryption algorithm", err.Error())
+		assert.Equal(t, []byte("grafana"), decrypted)
 	})
 
 	t.Run("decrypting ciphertext with aes-cfb as encryption algorithm do not fail", func(t *testing.T) {
@@ -65,4 +68,48 @@ func TestEncryption(t *testing.T) {
 
 		assert.Equal(t, []byte("grafana"), decrypted)
 	})
+
+	t.Run("encrypting with aes-gcm", func(t *testing.T) {
+		ctx := context.Background()
+		svc, err := ProvideServiceFromConfig(&setting.Cfg{SecretsEncryptionAlgorithm: encryption.AesGcm})
+		require.NoError(t, err)
+
+		encrypted, err := svc.Encrypt(ctx, []byte("grafana"), "1234")
+		require.NoError(t, err)
+
+		alg, err := Algorithm(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, encryption.AesGcm, alg)
+
+		decrypted, err := svc.Decrypt(ctx, encrypted, "1234")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		encrypted[len(encrypted)-1] ^= 0xff
+		_, err = svc.Decrypt(ctx, encrypted, "1234")
+		require.Error(t, err)
+	})
+
+	t.Run("configured algorithm", func(t *testing.T) {
+		for _, tc := range []struct {
+			cfg      setting.Cfg
+			expected string
+			err      bool
+		}{
+			{cfg: setting.Cfg{}, expected: encryption.AesCfb},
+			{cfg: setting.Cfg{FIPSMode: true}, expected: encryption.AesGcm},
+			{cfg: setting.Cfg{SecretsEncryptionAlgorithm: encryption.AesGcm}, expected: encryption.AesGcm},
+			{cfg: setting.Cfg{SecretsEncryptionAlgorithm: encryption.AesCfb, FIPSMode: true}, err: true},
+			{cfg: setting.Cfg{SecretsEncryptionAlgorithm: "des"}, err: true},
+		} {
+			tc := tc
+			alg, err := ConfiguredAlgorithm(&tc.cfg)
+			if tc.err {
+				require.Error(t, err)
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, alg)
+		}
+	})
 }
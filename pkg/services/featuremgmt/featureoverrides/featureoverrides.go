@@ -0,0 +1,172 @@
+package featureoverrides
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+const (
+	kvNamespace = "feature-toggles"
+
+	// refreshInterval is how often the overrides are reloaded, so that all instances
+	// of a highly available setup follow changes made through one of them.
+	refreshInterval = 30 * time.Second
+)
+
+// Service stores the feature toggles changed at runtime in the database and applies
+// them to the feature manager. Instance wide values are stored for org 0.
+type Service struct {
+	features *featuremgmt.FeatureManager
+	kv       kvstore.KVStore
+	log      log.Logger
+
+	mu           sync.Mutex
+	overrides    map[string]bool
+	orgOverrides map[int64]map[string]bool
+}
+
+func ProvideService(features *featuremgmt.FeatureManager, kv kvstore.KVStore) *Service {
+	s := &Service{
+		features: features,
+		kv:       kv,
+		log:      log.New("featuremgmt.overrides"),
+	}
+	if err := s.load(context.Background()); err != nil {
+		s.log.Error("Failed to load feature toggle overrides", "error", err)
+	}
+	return s
+}
+
+// Run reloads the overrides periodically.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.load(ctx); err != nil {
+				s.log.Warn("Failed to reload feature toggle overrides", "error", err)
+			}
+		}
+	}
+}
+
+// List returns all feature toggles sorted by name.
+func (s *Service) List(ctx context.Context) []Toggle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flags := s.features.GetFlags()
+	toggles := make([]Toggle, 0, len(flags))
+	for _, flag := range flags {
+		toggle := Toggle{
+			FeatureFlag:      flag,
+			Enabled:          s.features.IsEnabled(flag.Name),
+			AllowOrgOverride: flag.SupportsOrgOverride(),
+		}
+		if val, ok := s.overrides[flag.Name]; ok {
+			v := val
+			toggle.Override = &v
+		}
+		for orgID, values := range s.orgOverrides {
+			if val, ok := values[flag.Name]; ok {
+				if toggle.OrgOverrides == nil {
+					toggle.OrgOverrides = map[int64]bool{}
+				}
+				toggle.OrgOverrides[orgID] = val
+			}
+		}
+		toggles = append(toggles, toggle)
+	}
+	sort.Slice(toggles, func(i, j int) bool { return toggles[i].Name < toggles[j].Name })
+	return toggles
+}
+
+// Update stores the changes and applies them. Nothing is changed if one of them is invalid.
+func (s *Service) Update(ctx context.Context, cmd *UpdateTogglesCommand) error {
+	for _, change := range cmd.Toggles {
+		if err := s.validate(change); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range cmd.Toggles {
+		var err error
+		if change.Enabled == nil {
+			err = s.kv.Del(ctx, change.OrgID, kvNamespace, change.Name)
+		} else {
+			err = s.kv.Set(ctx, change.OrgID, kvNamespace, change.Name, strconv.FormatBool(*change.Enabled))
+		}
+		if err != nil {
+			return err
+		}
+		s.log.Info("Feature toggle changed", "name", change.Name, "orgId", change.OrgID, "enabled", change.Enabled)
+	}
+	return s.load(ctx)
+}
+
+func (s *Service) validate(change ToggleChange) error {
+	if change.Name == "" {
+		return ErrFeatureToggleNameRequired
+	}
+	flag, ok := s.features.GetFlag(change.Name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFeatureToggle, change.Name)
+	}
+	if flag.RequiresRestart {
+		return fmt.Errorf("%w: %s", ErrFeatureToggleNeedsRestart, change.Name)
+	}
+	if change.OrgID != 0 && !flag.SupportsOrgOverride() {
+		return fmt.Errorf("%w: %s", ErrOrgOverrideNotSupported, change.Name)
+	}
+	return nil
+}
+
+func (s *Service) load(ctx context.Context) error {
+	keys, err := s.kv.Keys(ctx, kvstore.AllOrganizations, kvNamespace, "")
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]bool{}
+	orgOverrides := map[int64]map[string]bool{}
+	for _, key := range keys {
+		value, ok, err := s.kv.Get(ctx, key.OrgId, kvNamespace, key.Key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			s.log.Warn("Ignoring invalid feature toggle override", "name", key.Key, "orgId", key.OrgId, "value", value)
+			continue
+		}
+		if key.OrgId == 0 {
+			overrides[key.Key] = enabled
+			continue
+		}
+		if orgOverrides[key.OrgId] == nil {
+			orgOverrides[key.OrgId] = map[string]bool{}
+		}
+		orgOverrides[key.OrgId][key.Key] = enabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = overrides
+	s.orgOverrides = orgOverrides
+	s.features.SetOverrides(overrides, orgOverrides)
+	return nil
+}
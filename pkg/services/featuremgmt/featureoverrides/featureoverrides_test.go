@@ -0,0 +1,86 @@
+package featureoverrides
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationFeatureToggleOverrides(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := kvstore.ProvideService(sqlstore.InitTestDB(t))
+	features, err := featuremgmt.ProvideManagerService(setting.NewCfg(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	svc := ProvideService(features, kv)
+	enabled, disabled := true, false
+
+	t.Run("instance wide override", func(t *testing.T) {
+		require.False(t, features.IsEnabled(featuremgmt.FlagTrimDefaults))
+
+		err := svc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{{Name: featuremgmt.FlagTrimDefaults, Enabled: &enabled}}})
+		require.NoError(t, err)
+		require.True(t, features.IsEnabled(featuremgmt.FlagTrimDefaults))
+
+		toggle := findToggle(t, svc.List(ctx), featuremgmt.FlagTrimDefaults)
+		require.True(t, toggle.Enabled)
+		require.Equal(t, &enabled, toggle.Override)
+		require.False(t, toggle.AllowOrgOverride)
+	})
+
+	t.Run("organization override", func(t *testing.T) {
+		err := svc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{{Name: featuremgmt.FlagAutoMigrateGraphPanels, OrgID: 2, Enabled: &enabled}}})
+		require.NoError(t, err)
+		require.False(t, features.IsEnabled(featuremgmt.FlagAutoMigrateGraphPanels))
+		require.True(t, features.IsEnabledForOrg(2, featuremgmt.FlagAutoMigrateGraphPanels))
+
+		toggle := findToggle(t, svc.List(ctx), featuremgmt.FlagAutoMigrateGraphPanels)
+		require.Equal(t, map[int64]bool{2: true}, toggle.OrgOverrides)
+	})
+
+	t.Run("invalid changes are rejected", func(t *testing.T) {
+		err := svc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{{Name: "unknown", Enabled: &enabled}}})
+		require.ErrorIs(t, err, ErrUnknownFeatureToggle)
+
+		err = svc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{{Name: featuremgmt.FlagValidateDashboardsOnSave, Enabled: &enabled}}})
+		require.ErrorIs(t, err, ErrFeatureToggleNeedsRestart)
+
+		err = svc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{
+			{Name: featuremgmt.FlagTrimDefaults, Enabled: &disabled},
+			{Name: featuremgmt.FlagTrimDefaults, OrgID: 2, Enabled: &disabled},
+		}})
+		require.ErrorIs(t, err, ErrOrgOverrideNotSupported)
+		require.True(t, features.IsEnabled(featuremgmt.FlagTrimDefaults))
+	})
+
+	t.Run("overrides survive restarts and can be removed", func(t *testing.T) {
+		restarted, err := featuremgmt.ProvideManagerService(setting.NewCfg(), nil)
+		require.NoError(t, err)
+		restartedSvc := ProvideService(restarted, kv)
+		require.True(t, restarted.IsEnabled(featuremgmt.FlagTrimDefaults))
+
+		err = restartedSvc.Update(ctx, &UpdateTogglesCommand{Toggles: []ToggleChange{{Name: featuremgmt.FlagTrimDefaults}}})
+		require.NoError(t, err)
+		require.False(t, restarted.IsEnabled(featuremgmt.FlagTrimDefaults))
+	})
+}
+
+func findToggle(t *testing.T, toggles []Toggle, name string) Toggle {
+	t.Helper()
+	for _, toggle := range toggles {
+		if toggle.Name == name {
+			return toggle
+		}
+	}
+	t.Fatalf("feature toggle %s not found", name)
+	return Toggle{}
+}
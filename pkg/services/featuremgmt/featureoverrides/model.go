@@ -0,0 +1,38 @@
+package featureoverrides
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+var (
+	ErrUnknownFeatureToggle      = errors.New("unknown feature toggle")
+	ErrFeatureToggleNeedsRestart = errors.New("feature toggle requires a restart and cannot be changed at runtime")
+	ErrOrgOverrideNotSupported   = errors.New("feature toggle cannot be changed per organization")
+	ErrFeatureToggleNameRequired = errors.New("feature toggle name is required")
+)
+
+// Toggle is a feature flag with its current value and the values it was changed to at runtime.
+type Toggle struct {
+	featuremgmt.FeatureFlag
+
+	Enabled          bool `json:"enabled"`
+	AllowOrgOverride bool `json:"allowOrgOverride"`
+	// Override is the instance wide value changed at runtime, it takes precedence over the configuration.
+	Override *bool `json:"override,omitempty"`
+	// OrgOverrides are the values per organization id.
+	OrgOverrides map[int64]bool `json:"orgOverrides,omitempty"`
+}
+
+// ToggleChange sets the value of a feature toggle, instance wide or for an organization.
+// A null value removes the override, so that the configured value applies again.
+type ToggleChange struct {
+	Name    string `json:"name"`
+	OrgID   int64  `json:"orgId"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type UpdateTogglesCommand struct {
+	Toggles []ToggleChange `json:"toggles"`
+}
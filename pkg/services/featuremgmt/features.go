@@ -94,3 +94,10 @@ type FeatureFlag struct {
 	RequiresLicense bool `json:"requiresLicense,omitempty"` // Must be enabled in the license
 	FrontendOnly    bool `json:"frontend,omitempty"`        // change is only seen in the frontend
 }
+
+// SupportsOrgOverride returns true if the flag can be changed per organization. This is
+// the case for flags that are only seen in the frontend, which gets the flags of the
+// organization of the user.
+func (f FeatureFlag) SupportsOrgOverride() bool {
+	return f.FrontendOnly && !f.RequiresRestart
+}
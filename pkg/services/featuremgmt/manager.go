@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 
@@ -24,6 +25,11 @@ type FeatureManager struct {
 	config    string          // path to config file
 	vars      map[string]interface{}
 	log       log.Logger
+
+	// Overrides changed at runtime, they take precedence over the configuration
+	mu           sync.RWMutex
+	overrides    map[string]bool
+	orgOverrides map[int64]map[string]bool
 }
 
 // This will merge the flags with the current configuration
@@ -82,12 +88,22 @@ func (fm *FeatureManager) evaluate(ff *FeatureFlag) bool {
 		return false
 	}
 
+	if val, ok := fm.overrides[ff.Name]; ok && !ff.RequiresRestart {
+		return val
+	}
+
 	// TODO: CEL - expression
 	return ff.Expression == "true"
 }
 
 // Update
 func (fm *FeatureManager) update() {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.updateLocked()
+}
+
+func (fm *FeatureManager) updateLocked() {
 	enabled := make(map[string]bool)
 	for _, flag := range fm.flags {
 		val := fm.evaluate(flag)
@@ -124,11 +140,26 @@ func (fm *FeatureManager) readFile() error {
 
 // IsEnabled checks if a feature is enabled
 func (fm *FeatureManager) IsEnabled(flag string) bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.enabled[flag]
+}
+
+// IsEnabledForOrg checks if a feature is enabled in an organization, taking the
+// organization overrides into account.
+func (fm *FeatureManager) IsEnabledForOrg(orgID int64, flag string) bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	if val, ok := fm.orgOverride(orgID, flag); ok {
+		return val
+	}
 	return fm.enabled[flag]
 }
 
 // GetEnabled returns a map contaning only the features that are enabled
 func (fm *FeatureManager) GetEnabled(ctx context.Context) map[string]bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
 	enabled := make(map[string]bool, len(fm.enabled))
 	for key, val := range fm.enabled {
 		if val {
@@ -138,6 +169,61 @@ func (fm *FeatureManager) GetEnabled(ctx context.Context) map[string]bool {
 	return enabled
 }
 
+// GetEnabledForOrg returns a map containing only the features that are enabled in an organization
+func (fm *FeatureManager) GetEnabledForOrg(ctx context.Context, orgID int64) map[string]bool {
+	enabled := fm.GetEnabled(ctx)
+
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	for key := range fm.orgOverrides[orgID] {
+		if val, ok := fm.orgOverride(orgID, key); ok {
+			if val {
+				enabled[key] = true
+			} else {
+				delete(enabled, key)
+			}
+		}
+	}
+	return enabled
+}
+
+func (fm *FeatureManager) orgOverride(orgID int64, flag string) (bool, bool) {
+	val, ok := fm.orgOverrides[orgID][flag]
+	if !ok {
+		return false, false
+	}
+	ff, ok := fm.flags[flag]
+	if !ok || !ff.SupportsOrgOverride() {
+		return false, false
+	}
+	if ff.RequiresDevMode && !fm.isDevMod {
+		return false, false
+	}
+	if ff.RequiresLicense && (fm.licensing == nil || !fm.licensing.FeatureEnabled(ff.Name)) {
+		return false, false
+	}
+	return val, true
+}
+
+// SetOverrides replaces the values of the flags that were changed at runtime, both
+// instance wide and per organization. Overrides of flags that require a restart are ignored.
+func (fm *FeatureManager) SetOverrides(overrides map[string]bool, orgOverrides map[int64]map[string]bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.overrides = overrides
+	fm.orgOverrides = orgOverrides
+	fm.updateLocked()
+}
+
+// GetFlag returns the definition of a flag
+func (fm *FeatureManager) GetFlag(name string) (FeatureFlag, bool) {
+	flag, ok := fm.flags[name]
+	if !ok {
+		return FeatureFlag{}, false
+	}
+	return *flag, true
+}
+
 // GetFlags returns all flag definitions
 func (fm *FeatureManager) GetFlags() []FeatureFlag {
 	v := make([]FeatureFlag, 0, len(fm.flags))
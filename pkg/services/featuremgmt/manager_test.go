@@ -74,4 +74,41 @@ func TestFeatureManager(t *testing.T) {
 		require.Equal(t, "second", flag.Description)
 		require.Equal(t, "http://something", flag.DocsURL)
 	})
+	t.Run("check runtime overrides", func(t *testing.T) {
+		ft := FeatureManager{
+			flags: map[string]*FeatureFlag{},
+		}
+		ft.registerFlags(FeatureFlag{
+			Name:         "frontend",
+			FrontendOnly: true,
+		}, FeatureFlag{
+			Name:       "backend",
+			Expression: "true",
+		}, FeatureFlag{
+			Name:            "restart",
+			RequiresRestart: true,
+		})
+
+		ft.SetOverrides(map[string]bool{
+			"frontend": true,
+			"backend":  false,
+			"restart":  true,
+		}, map[int64]map[string]bool{
+			2: {"frontend": false, "backend": true},
+		})
+		require.True(t, ft.IsEnabled("frontend"))
+		require.False(t, ft.IsEnabled("backend"))
+		require.False(t, ft.IsEnabled("restart"))
+
+		// Only frontend flags can be overridden per organization
+		require.True(t, ft.IsEnabledForOrg(1, "frontend"))
+		require.False(t, ft.IsEnabledForOrg(2, "frontend"))
+		require.False(t, ft.IsEnabledForOrg(2, "backend"))
+		require.Equal(t, map[string]bool{"frontend": true}, ft.GetEnabledForOrg(context.Background(), 1))
+		require.Equal(t, map[string]bool{}, ft.GetEnabledForOrg(context.Background(), 2))
+
+		ft.SetOverrides(nil, nil)
+		require.False(t, ft.IsEnabled("frontend"))
+		require.True(t, ft.IsEnabled("backend"))
+	})
 }
@@ -0,0 +1,186 @@
+package fips
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/kmsproviders"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ciphertextSource counts the ciphertexts stored in a table by the
+// algorithm they are encrypted with.
+type ciphertextSource interface {
+	description() string
+	count(ctx context.Context, sqlStore *sqlstore.SQLStore) (map[string]int, error)
+}
+
+// ciphertextSources lists the same secrets as the re-encrypt command, along
+// with the data keys encrypted with Grafana's secret key.
+var ciphertextSources = func() []ciphertextSource {
+	sources := []ciphertextSource{dataKeys{}}
+	for _, c := range secrets.EncryptedColumns {
+		sources = append(sources, columnCiphertexts{c})
+	}
+	for _, t := range secrets.EncryptedJSONTables {
+		sources = append(sources, jsonCiphertexts{tableName: t})
+	}
+	return append(sources, alertingCiphertexts{})
+}()
+
+type dataKeys struct{}
+
+func (dataKeys) description() string {
+	return "data_keys"
+}
+
+// count only considers the data keys encrypted with Grafana's secret key,
+// the other providers rely on the encryption of the external KMS.
+func (dataKeys) count(ctx context.Context, sqlStore *sqlstore.SQLStore) (map[string]int, error) {
+	var rows []struct {
+		Provider      secrets.ProviderID
+		EncryptedData []byte
+	}
+	if err := sqlStore.NewSession(ctx).Table("data_keys").Cols("provider", "encrypted_data").Find(&rows); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		if kmsproviders.NormalizeProviderID(row.Provider) != kmsproviders.Default {
+			continue
+		}
+		countCiphertext(counts, row.EncryptedData)
+	}
+	return counts, nil
+}
+
+type columnCiphertexts struct {
+	secrets.EncryptedColumn
+}
+
+func (c columnCiphertexts) description() string {
+	return c.Table + "." + c.Column
+}
+
+func (c columnCiphertexts) count(ctx context.Context, sqlStore *sqlstore.SQLStore) (map[string]int, error) {
+	var rows []struct {
+		Secret []byte
+	}
+	if err := sqlStore.NewSession(ctx).Table(c.Table).Select(fmt.Sprintf("%s as secret", c.Column)).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		payload := row.Secret
+		if c.Encoding != nil && len(payload) > 0 {
+			decoded, err := c.Encoding.DecodeString(string(payload))
+			if err != nil {
+				counts[unknownAlgorithm]++
+				continue
+			}
+			payload = decoded
+		}
+		countCiphertext(counts, payload)
+	}
+	return counts, nil
+}
+
+type jsonCiphertexts struct {
+	tableName string
+}
+
+func (c jsonCiphertexts) description() string {
+	return c.tableName + ".secure_json_data"
+}
+
+func (c jsonCiphertexts) count(ctx context.Context, sqlStore *sqlstore.SQLStore) (map[string]int, error) {
+	var rows []struct {
+		SecureJsonData map[string][]byte
+	}
+	if err := sqlStore.NewSession(ctx).Table(c.tableName).Cols("secure_json_data").Find(&rows); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		for _, payload := range row.SecureJsonData {
+			countCiphertext(counts, payload)
+		}
+	}
+	return counts, nil
+}
+
+type alertingCiphertexts struct{}
+
+func (alertingCiphertexts) description() string {
+	return "alert_configuration"
+}
+
+// alertmanagerSecureSettings holds the part of an Alertmanager
+// configuration containing the encrypted settings of the receivers.
+type alertmanagerSecureSettings struct {
+	AlertmanagerConfig struct {
+		Receivers []struct {
+			GrafanaManagedReceivers []struct {
+				SecureSettings map[string]string `json:"secureSettings"`
+			} `json:"grafana_managed_receiver_configs"`
+		} `json:"receivers"`
+	} `json:"alertmanager_config"`
+}
+
+func (alertingCiphertexts) count(ctx context.Context, sqlStore *sqlstore.SQLStore) (map[string]int, error) {
+	var rows []struct {
+		AlertmanagerConfiguration string
+	}
+	if err := sqlStore.NewSession(ctx).Table("alert_configuration").Cols("alertmanager_configuration").Find(&rows); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		var cfg alertmanagerSecureSettings
+		if err := json.Unmarshal([]byte(row.AlertmanagerConfiguration), &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse alertmanager configuration: %w", err)
+		}
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			for _, gmr := range receiver.GrafanaManagedReceivers {
+				for _, v := range gmr.SecureSettings {
+					payload, err := base64.StdEncoding.DecodeString(v)
+					if err != nil {
+						counts[unknownAlgorithm]++
+						continue
+					}
+					countCiphertext(counts, payload)
+				}
+			}
+		}
+	}
+	return counts, nil
+}
+
+const unknownAlgorithm = "an unknown algorithm"
+
+func countCiphertext(counts map[string]int, payload []byte) {
+	if len(payload) == 0 {
+		return
+	}
+
+	payload, ok := secrets.StripDataKeyID(payload)
+	if !ok {
+		counts[unknownAlgorithm]++
+		return
+	}
+
+	alg, err := ossencryption.Algorithm(payload)
+	if err != nil {
+		counts[unknownAlgorithm]++
+		return
+	}
+	counts[alg]++
+}
@@ -0,0 +1,126 @@
+// Package fips implements the policy enforced when Grafana runs in FIPS
+// mode: session tokens must be signed, passwords hashed and secrets
+// encrypted with FIPS-approved algorithms only.
+//
+// Session tokens are hashed with SHA-256 and passwords with
+// PBKDF2-HMAC-SHA256, both of which are approved, so the policy only
+// restricts the JWT signing algorithms accepted for authentication and the
+// cipher used for secrets. New secrets are encrypted with AES-GCM in FIPS
+// mode, while secrets encrypted with the legacy AES-CFB cipher have to be
+// migrated with the upgrade-algorithm command of grafana-cli before starting
+// Grafana.
+package fips
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrPolicyViolation = errors.New("configuration violates the FIPS policy")
+
+// approvedJWTAlgorithms are the JWS algorithms from FIPS 186-4 and
+// FIPS 198-1. EdDSA is not part of them.
+var approvedJWTAlgorithms = map[string]bool{
+	"HS256": true, "HS384": true, "HS512": true,
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+}
+
+// IsApprovedJWTAlgorithm reports whether tokens signed with alg can be
+// accepted in FIPS mode.
+func IsApprovedJWTAlgorithm(alg string) bool {
+	return approvedJWTAlgorithms[alg]
+}
+
+// IsApprovedCipher reports whether secrets encrypted with alg can be used
+// in FIPS mode.
+func IsApprovedCipher(alg string) bool {
+	return alg == encryption.AesGcm
+}
+
+// Violation describes a component that doesn't comply with the policy.
+type Violation struct {
+	Component   string
+	Description string
+}
+
+func (v Violation) String() string {
+	return v.Component + ": " + v.Description
+}
+
+// Service checks that the database doesn't hold any secret encrypted with a
+// cipher that is not approved, and stops Grafana if it does.
+type Service struct {
+	cfg      *setting.Cfg
+	sqlStore *sqlstore.SQLStore
+	log      log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) *Service {
+	return &Service{
+		cfg:      cfg,
+		sqlStore: sqlStore,
+		log:      log.New("fips"),
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.FIPSMode
+}
+
+// Run checks the policy once at startup. Any violation is returned as an
+// error, which stops the server.
+func (s *Service) Run(ctx context.Context) error {
+	violations, err := s.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check FIPS policy: %w", err)
+	}
+	if len(violations) == 0 {
+		s.log.Info("Running in FIPS mode")
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		s.log.Error("FIPS policy violation", "component", v.Component, "description", v.Description)
+		descriptions = append(descriptions, v.String())
+	}
+	return fmt.Errorf("%w, run `grafana-cli admin secrets-migration upgrade-algorithm` to migrate existing secrets: %s",
+		ErrPolicyViolation, strings.Join(descriptions, "; "))
+}
+
+// Check returns all the policy violations found in the database.
+func (s *Service) Check(ctx context.Context) ([]Violation, error) {
+	var violations []Violation
+	for _, c := range ciphertextSources {
+		counts, err := c.count(ctx, s.sqlStore)
+		if err != nil {
+			return nil, err
+		}
+		algs := make([]string, 0, len(counts))
+		for alg := range counts {
+			algs = append(algs, alg)
+		}
+		sort.Strings(algs)
+
+		for _, alg := range algs {
+			if IsApprovedCipher(alg) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Component:   c.description(),
+				Description: fmt.Sprintf("%d secrets are encrypted with %s", counts[alg], alg),
+			})
+		}
+	}
+	return violations, nil
+}
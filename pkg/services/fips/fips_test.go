@@ -0,0 +1,59 @@
+package fips
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIsApprovedJWTAlgorithm(t *testing.T) {
+	require.True(t, IsApprovedJWTAlgorithm("RS256"))
+	require.True(t, IsApprovedJWTAlgorithm("ES384"))
+	require.False(t, IsApprovedJWTAlgorithm("EdDSA"))
+	require.False(t, IsApprovedJWTAlgorithm("none"))
+}
+
+func TestIntegrationCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := ProvideService(&setting.Cfg{FIPSMode: true}, sqlStore)
+
+	insertSecret := func(t *testing.T, enc *ossencryption.Service, envelope bool) {
+		t.Helper()
+		payload, err := enc.Encrypt(ctx, []byte("secret"), "key")
+		require.NoError(t, err)
+		if envelope {
+			payload = append([]byte("#a2V5#"), payload...)
+		}
+		_, err = sqlStore.NewSession(ctx).Exec("INSERT INTO secrets (org_id, namespace, type, value, created, updated) VALUES (1, 'ns', 'type', ?, ?, ?)",
+			base64.RawStdEncoding.EncodeToString(payload), "2022-01-01 00:00:00", "2022-01-01 00:00:00")
+		require.NoError(t, err)
+	}
+
+	gcm, err := ossencryption.ProvideServiceFromConfig(&setting.Cfg{FIPSMode: true})
+	require.NoError(t, err)
+	insertSecret(t, gcm, true)
+	insertSecret(t, gcm, false)
+
+	violations, err := svc.Check(ctx)
+	require.NoError(t, err)
+	require.Empty(t, violations)
+	require.NoError(t, svc.Run(ctx))
+
+	insertSecret(t, ossencryption.ProvideService(), true)
+
+	violations, err = svc.Check(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Violation{{Component: "secrets.value", Description: "1 secrets are encrypted with " + encryption.AesCfb}}, violations)
+	require.ErrorIs(t, svc.Run(ctx), ErrPolicyViolation)
+}
@@ -0,0 +1,14 @@
+package folderexport
+
+import (
+	"context"
+	"io"
+)
+
+// Service exports the content of a folder as a zip archive laid out so that
+// it can be extracted next to the provisioning configuration of Grafana.
+type Service interface {
+	// Export writes the archive to w one file at a time, loading each file
+	// only once the previous one has been written.
+	Export(ctx context.Context, w io.Writer, query *ExportFolderQuery) error
+}
@@ -0,0 +1,298 @@
+package folderexportimpl
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folderexport"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// dashboardsPageSize is how many dashboards are searched at once. The
+// dashboards themselves are loaded one at a time.
+const dashboardsPageSize = 1000
+
+// Service writes the archive with the following layout:
+//
+//	provisioning/dashboards/<folder uid>.yaml
+//	dashboards/<folder uid>/<dashboard uid>.json
+//	library-panels/<library panel uid>.json
+//	alert-rules/<alert rule uid>.json
+type Service struct {
+	dashboardService dashboards.DashboardService
+	libraryElements  libraryelements.Service
+	accessControl    ac.AccessControl
+	sqlStore         *sqlstore.SQLStore
+	now              func() time.Time
+}
+
+func ProvideService(dashboardService dashboards.DashboardService, libraryElements libraryelements.Service, accessControl ac.AccessControl, sqlStore *sqlstore.SQLStore) folderexport.Service {
+	return &Service{
+		dashboardService: dashboardService,
+		libraryElements:  libraryElements,
+		accessControl:    accessControl,
+		sqlStore:         sqlStore,
+		now:              time.Now,
+	}
+}
+
+type exporter struct {
+	*Service
+	zw      *zip.Writer
+	query   *folderexport.ExportFolderQuery
+	panels  map[string]libraryelements.LibraryElementDTO
+	written time.Time
+}
+
+func (s *Service) Export(ctx context.Context, w io.Writer, query *folderexport.ExportFolderQuery) error {
+	e := &exporter{
+		Service: s,
+		zw:      zip.NewWriter(w),
+		query:   query,
+		panels:  map[string]libraryelements.LibraryElementDTO{},
+		written: s.now(),
+	}
+
+	if err := e.writeProvisioning(); err != nil {
+		return err
+	}
+	if err := e.writeDashboards(ctx); err != nil {
+		return err
+	}
+	if query.IncludeLibraryPanels {
+		if err := e.writeLibraryPanels(ctx); err != nil {
+			return err
+		}
+	}
+	if query.IncludeAlertRules {
+		if err := e.writeAlertRules(ctx); err != nil {
+			return err
+		}
+	}
+	return e.zw.Close()
+}
+
+type dashboardProvider struct {
+	Name                  string                 `yaml:"name"`
+	OrgID                 int64                  `yaml:"orgId"`
+	Folder                string                 `yaml:"folder"`
+	FolderUID             string                 `yaml:"folderUid"`
+	Type                  string                 `yaml:"type"`
+	DisableDeletion       bool                   `yaml:"disableDeletion"`
+	UpdateIntervalSeconds int64                  `yaml:"updateIntervalSeconds"`
+	AllowUIUpdates        bool                   `yaml:"allowUiUpdates"`
+	Options               map[string]interface{} `yaml:"options"`
+}
+
+type dashboardProvisioning struct {
+	APIVersion int64                `yaml:"apiVersion"`
+	Providers  []*dashboardProvider `yaml:"providers"`
+}
+
+// writeProvisioning writes a dashboard provider reading the dashboards of the
+// archive into a folder with the same title and uid.
+func (e *exporter) writeProvisioning() error {
+	dashboardsPath := e.query.DashboardsPath
+	if dashboardsPath == "" {
+		dashboardsPath = folderexport.DefaultDashboardsPath
+	}
+
+	folder := e.query.Folder
+	raw, err := yaml.Marshal(dashboardProvisioning{
+		APIVersion: 1,
+		Providers: []*dashboardProvider{
+			{
+				Name:                  folder.Title,
+				OrgID:                 e.query.OrgID,
+				Folder:                folder.Title,
+				FolderUID:             folder.Uid,
+				Type:                  "file",
+				UpdateIntervalSeconds: 10,
+				Options: map[string]interface{}{
+					"path": path.Join(dashboardsPath, folder.Uid),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := e.create(path.Join("provisioning", "dashboards", folder.Uid+".yaml"))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	return err
+}
+
+func (e *exporter) writeDashboards(ctx context.Context) error {
+	for page := int64(1); ; page++ {
+		search := &models.FindPersistedDashboardsQuery{
+			OrgId:        e.query.OrgID,
+			SignedInUser: e.query.SignedInUser,
+			Type:         string(models.DashHitDB),
+			FolderIds:    []int64{e.query.Folder.Id},
+			Permission:   models.PERMISSION_VIEW,
+			Limit:        dashboardsPageSize,
+			Page:         page,
+		}
+		if err := e.dashboardService.SearchDashboards(ctx, search); err != nil {
+			return err
+		}
+
+		for _, hit := range search.Result {
+			if err := e.writeDashboard(ctx, hit.ID); err != nil {
+				return err
+			}
+		}
+		if len(search.Result) < dashboardsPageSize {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) writeDashboard(ctx context.Context, dashboardID int64) error {
+	// stop loading dashboards once the client is gone
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := &models.GetDashboardQuery{Id: dashboardID, OrgId: e.query.OrgID}
+	if err := e.dashboardService.GetDashboard(ctx, query); err != nil {
+		return err
+	}
+	dash := query.Result
+
+	if e.query.IncludeLibraryPanels {
+		panels, err := e.libraryElements.GetElementsForDashboard(ctx, dash.Id)
+		if err != nil {
+			return err
+		}
+		for uid, panel := range panels {
+			e.panels[uid] = panel
+		}
+	}
+
+	// the ids are specific to the instance, provisioning matches dashboards by uid
+	dash.Data.Del("id")
+	dashboards.RemoveHiddenPanels(e.query.SignedInUser, dash.Data)
+	return e.writeJSON(path.Join("dashboards", e.query.Folder.Uid, dash.Uid+".json"), dash.Data)
+}
+
+// writeLibraryPanels writes the library panels of the folder and the ones the
+// dashboards use, in the format of the request creating them.
+func (e *exporter) writeLibraryPanels(ctx context.Context) error {
+	panels, err := e.libraryElements.GetElementsInFolder(ctx, e.query.SignedInUser, e.query.Folder.Id, models.PanelElement)
+	if err != nil {
+		return err
+	}
+	for _, panel := range panels {
+		e.panels[panel.UID] = panel
+	}
+
+	uids := make([]string, 0, len(e.panels))
+	for uid := range e.panels {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		panel := e.panels[uid]
+		folderUID := panel.FolderUID
+		if err := e.writeJSON(path.Join("library-panels", uid+".json"), libraryelements.CreateLibraryElementCommand{
+			FolderUID: &folderUID,
+			Name:      panel.Name,
+			Model:     panel.Model,
+			Kind:      panel.Kind,
+			UID:       panel.UID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAlertRules writes the alert rules of the folder in the format of the
+// provisioning API. Recording rules are left out, as the provisioning API
+// can't create them, and so are the rules querying data sources the user
+// can't query, like in the rules API.
+func (e *exporter) writeAlertRules(ctx context.Context) error {
+	rules := make([]*ngmodels.AlertRule, 0)
+	err := e.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_rule").
+			Where("org_id = ? AND namespace_uid = ?", e.query.OrgID, e.query.Folder.Uid).
+			OrderBy("rule_group ASC, id ASC").
+			Find(&rules)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if rule.IsRecording() {
+			continue
+		}
+		canQuery, err := e.canQueryDataSources(ctx, rule)
+		if err != nil {
+			return err
+		}
+		if !canQuery {
+			continue
+		}
+		provisioned := apimodels.NewAlertRule(*rule, ngmodels.ProvenanceNone)
+		provisioned.ID = 0
+		if err := e.writeJSON(path.Join("alert-rules", rule.UID+".json"), provisioned); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canQueryDataSources checks that the user can query all the data sources of the rule.
+func (e *exporter) canQueryDataSources(ctx context.Context, rule *ngmodels.AlertRule) (bool, error) {
+	for _, query := range rule.Data {
+		if query.QueryType == expr.DatasourceType || query.DatasourceUID == expr.OldDatasourceUID {
+			continue
+		}
+		scope := datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)
+		canQuery, err := e.accessControl.Evaluate(ctx, e.query.SignedInUser, ac.EvalPermission(datasources.ActionQuery, scope))
+		if err != nil || !canQuery {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e *exporter) create(name string) (io.Writer, error) {
+	return e.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: e.written,
+	})
+}
+
+func (e *exporter) writeJSON(name string, v interface{}) error {
+	f, err := e.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
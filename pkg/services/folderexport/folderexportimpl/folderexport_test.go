@@ -0,0 +1,185 @@
+package folderexportimpl
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folderexport"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type fakeLibraryElements struct {
+	libraryelements.Service
+	inFolder     []libraryelements.LibraryElementDTO
+	forDashboard map[int64]map[string]libraryelements.LibraryElementDTO
+}
+
+func (f *fakeLibraryElements) GetElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderID int64, kind models.LibraryElementKind) ([]libraryelements.LibraryElementDTO, error) {
+	return f.inFolder, nil
+}
+
+func (f *fakeLibraryElements) GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]libraryelements.LibraryElementDTO, error) {
+	return f.forDashboard[dashboardID], nil
+}
+
+func TestIntegrationFolderExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("SearchDashboards", mock.Anything, mock.AnythingOfType("*models.FindPersistedDashboardsQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.FindPersistedDashboardsQuery)
+		require.Equal(t, []int64{10}, q.FolderIds)
+		require.Equal(t, models.PERMISSION_VIEW, q.Permission)
+		q.Result = models.HitList{{ID: 11, UID: "dash-a"}, {ID: 12, UID: "dash-b"}}
+	}).Return(nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		uid := map[int64]string{11: "dash-a", 12: "dash-b"}[q.Id]
+		q.Result = &models.Dashboard{
+			Id:  q.Id,
+			Uid: uid,
+			Data: simplejson.NewFromAny(map[string]interface{}{
+				"id":    q.Id,
+				"uid":   uid,
+				"title": uid,
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "type": "timeseries"},
+					map[string]interface{}{"id": 2, "type": "timeseries", "visibility": map[string]interface{}{"roles": []interface{}{"Editor"}}},
+				},
+			}),
+		}
+	}).Return(nil)
+
+	panel := func(uid, folderUID string) libraryelements.LibraryElementDTO {
+		return libraryelements.LibraryElementDTO{UID: uid, FolderUID: folderUID, Name: uid, Kind: int64(models.PanelElement), Model: json.RawMessage(`{"type":"graph"}`)}
+	}
+	libraryElements := &fakeLibraryElements{
+		inFolder: []libraryelements.LibraryElementDTO{panel("panel-a", "folder")},
+		forDashboard: map[int64]map[string]libraryelements.LibraryElementDTO{
+			11: {"panel-a": panel("panel-a", "folder"), "panel-b": panel("panel-b", "other")},
+		},
+	}
+
+	rules := []*ngmodels.AlertRule{
+		{OrgID: 1, UID: "rule-a", NamespaceUID: "folder", RuleGroup: "group", Title: "Rule A", Condition: "A", Data: []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "public", Model: json.RawMessage(`{}`)}}, NoDataState: ngmodels.NoData, ExecErrState: ngmodels.AlertingErrState, Updated: time.Now(), IntervalSeconds: 60},
+		{OrgID: 1, UID: "rule-b", NamespaceUID: "folder", RuleGroup: "group", Title: "Rule B", Condition: "A", Data: []ngmodels.AlertQuery{}, NoDataState: ngmodels.NoData, ExecErrState: ngmodels.AlertingErrState, Updated: time.Now(), IntervalSeconds: 60, Record: &ngmodels.Record{Metric: "metric", From: "A"}},
+		{OrgID: 1, UID: "rule-d", NamespaceUID: "folder", RuleGroup: "group", Title: "Rule D", Condition: "A", Data: []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "secret", Model: json.RawMessage(`{}`)}}, NoDataState: ngmodels.NoData, ExecErrState: ngmodels.AlertingErrState, Updated: time.Now(), IntervalSeconds: 60},
+		{OrgID: 1, UID: "rule-c", NamespaceUID: "other", RuleGroup: "group", Title: "Rule C", Condition: "A", Data: []ngmodels.AlertQuery{}, NoDataState: ngmodels.NoData, ExecErrState: ngmodels.AlertingErrState, Updated: time.Now(), IntervalSeconds: 60},
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, rule := range rules {
+			if _, err := sess.Insert(rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	accessControl := accesscontrolmock.New().WithPermissions([]accesscontrol.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("public")},
+	})
+	service := ProvideService(dashboardService, libraryElements, accessControl, ss).(*Service)
+	service.now = func() time.Time { return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	export := func(t *testing.T, query *folderexport.ExportFolderQuery) map[string][]byte {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, service.Export(context.Background(), &buf, query))
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		files := map[string][]byte{}
+		for _, f := range r.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			files[f.Name] = content
+		}
+		return files
+	}
+
+	folder := &models.Folder{Id: 10, Uid: "folder", Title: "Team A"}
+
+	t.Run("Exports the dashboards with a provisioning file", func(t *testing.T) {
+		files := export(t, &folderexport.ExportFolderQuery{OrgID: 1, Folder: folder, SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}})
+
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		require.ElementsMatch(t, []string{
+			"provisioning/dashboards/folder.yaml",
+			"dashboards/folder/dash-a.json",
+			"dashboards/folder/dash-b.json",
+		}, names)
+
+		var provisioning dashboardProvisioning
+		require.NoError(t, yaml.Unmarshal(files["provisioning/dashboards/folder.yaml"], &provisioning))
+		require.Len(t, provisioning.Providers, 1)
+		require.Equal(t, "Team A", provisioning.Providers[0].Folder)
+		require.Equal(t, "folder", provisioning.Providers[0].FolderUID)
+		require.Equal(t, "/var/lib/grafana/dashboards/folder", provisioning.Providers[0].Options["path"])
+
+		var dash map[string]interface{}
+		require.NoError(t, json.Unmarshal(files["dashboards/folder/dash-a.json"], &dash))
+		require.Equal(t, "dash-a", dash["uid"])
+		require.NotContains(t, dash, "id")
+		// the panel restricted to editors is hidden from the viewer
+		require.Len(t, dash["panels"], 1)
+	})
+
+	t.Run("Exports the library panels and the alert rules", func(t *testing.T) {
+		files := export(t, &folderexport.ExportFolderQuery{
+			OrgID:                1,
+			Folder:               folder,
+			SignedInUser:         &models.SignedInUser{OrgId: 1},
+			DashboardsPath:       "/etc/grafana/dashboards",
+			IncludeLibraryPanels: true,
+			IncludeAlertRules:    true,
+		})
+
+		require.Contains(t, files, "library-panels/panel-a.json")
+		require.Contains(t, files, "library-panels/panel-b.json")
+		var cmd libraryelements.CreateLibraryElementCommand
+		require.NoError(t, json.Unmarshal(files["library-panels/panel-b.json"], &cmd))
+		require.Equal(t, "other", *cmd.FolderUID)
+		require.Equal(t, int64(models.PanelElement), cmd.Kind)
+
+		require.Contains(t, files, "alert-rules/rule-a.json")
+		require.NotContains(t, files, "alert-rules/rule-b.json")
+		require.NotContains(t, files, "alert-rules/rule-c.json")
+		// the user can't query the data source of the rule
+		require.NotContains(t, files, "alert-rules/rule-d.json")
+		var rule map[string]interface{}
+		require.NoError(t, json.Unmarshal(files["alert-rules/rule-a.json"], &rule))
+		require.Equal(t, "folder", rule["folderUID"])
+		require.Equal(t, float64(0), rule["id"])
+
+		var provisioning dashboardProvisioning
+		require.NoError(t, yaml.Unmarshal(files["provisioning/dashboards/folder.yaml"], &provisioning))
+		require.Equal(t, "/etc/grafana/dashboards/folder", provisioning.Providers[0].Options["path"])
+	})
+}
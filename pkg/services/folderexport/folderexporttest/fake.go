@@ -0,0 +1,25 @@
+package folderexporttest
+
+import (
+	"context"
+	"io"
+
+	"github.com/grafana/grafana/pkg/services/folderexport"
+)
+
+type FakeFolderExportService struct {
+	ExpectedArchive []byte
+	ExpectedError   error
+}
+
+func NewFolderExportServiceFake() *FakeFolderExportService {
+	return &FakeFolderExportService{}
+}
+
+func (f *FakeFolderExportService) Export(ctx context.Context, w io.Writer, query *folderexport.ExportFolderQuery) error {
+	if f.ExpectedError != nil {
+		return f.ExpectedError
+	}
+	_, err := w.Write(f.ExpectedArchive)
+	return err
+}
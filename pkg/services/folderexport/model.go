@@ -0,0 +1,23 @@
+package folderexport
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// DefaultDashboardsPath is the directory the exported provisioning file reads
+// the dashboards from when the query doesn't set one.
+const DefaultDashboardsPath = "/var/lib/grafana/dashboards"
+
+type ExportFolderQuery struct {
+	OrgID        int64
+	Folder       *models.Folder
+	SignedInUser *models.SignedInUser
+	// DashboardsPath is the directory the dashboards of the archive are
+	// extracted to, used as the path of the dashboard provider.
+	DashboardsPath string
+	// IncludeLibraryPanels adds the library panels of the folder, and the ones
+	// the exported dashboards use.
+	IncludeLibraryPanels bool
+	// IncludeAlertRules adds the alert rules of the folder.
+	IncludeAlertRules bool
+}
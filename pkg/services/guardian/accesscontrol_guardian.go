@@ -131,7 +131,18 @@ func (a *AccessControlDashboardGuardian) CanDelete() (bool, error) {
 
 func (a *AccessControlDashboardGuardian) CanCreate(folderID int64, isFolder bool) (bool, error) {
 	if isFolder {
-		return a.evaluate(accesscontrol.EvalPermission(dashboards.ActionFoldersCreate))
+		if folderID == 0 {
+			return a.evaluate(accesscontrol.EvalPermission(dashboards.ActionFoldersCreate))
+		}
+		// creating a subfolder, or moving a folder, changes the content of the parent folder
+		parent, err := a.loadParentFolder(folderID)
+		if err != nil {
+			return false, err
+		}
+		return a.evaluate(accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(dashboards.ActionFoldersCreate),
+			accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent.Uid)),
+		))
 	}
 	folder, err := a.loadParentFolder(folderID)
 	if err != nil {
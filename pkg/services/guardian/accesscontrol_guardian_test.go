@@ -588,7 +588,7 @@ func setupAccessControlGuardianTest(t *testing.T, uid string, permissions []acce
 
 	// seed dashboard
 	dashStore := dashdb.ProvideDashboardStore(store)
-	dash, err := dashStore.SaveDashboard(models.SaveDashboardCommand{
+	dash, err := dashStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
 		Dashboard: toSave.Data,
 		UserId:    1,
 		OrgId:     1,
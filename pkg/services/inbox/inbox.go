@@ -0,0 +1,23 @@
+package inbox
+
+import (
+	"context"
+)
+
+// Service stores the notifications shown in the in-app inbox of users and pushes
+// new notifications to them over Grafana Live.
+type Service interface {
+	// Send adds a notification to the inbox of every user in the command. Users that
+	// already have a notification with the same dedup key are skipped.
+	Send(ctx context.Context, cmd *SendCommand) error
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+	CountUnread(ctx context.Context, orgID int64, userID int64) (int64, error)
+	MarkRead(ctx context.Context, cmd *MarkReadCommand) error
+	Delete(ctx context.Context, cmd *DeleteCommand) error
+
+	// DeleteExpired removes the notifications older than the configured retention.
+	DeleteExpired(ctx context.Context) (int64, error)
+	// WarnExpiringTokens notifies the organization admins about API keys and service
+	// account tokens that expire within the configured warning period.
+	WarnExpiringTokens(ctx context.Context) (int, error)
+}
@@ -0,0 +1,145 @@
+package inboximpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const defaultSearchLimit = 50
+
+// publisher pushes messages to Grafana Live channels.
+type publisher interface {
+	Publish(orgID int64, channel string, data []byte) error
+}
+
+type Service struct {
+	cfg   *setting.Cfg
+	store store
+	live  publisher
+	log   log.Logger
+	now   func() time.Time
+}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, grafanaLive *live.GrafanaLive) inbox.Service {
+	return &Service{
+		cfg: cfg,
+		store: &sqlStore{
+			db: db,
+		},
+		live: grafanaLive,
+		log:  log.New("inbox"),
+		now:  time.Now,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, cmd *inbox.SendCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if len(cmd.UserIDs) == 0 {
+		return nil
+	}
+
+	notifications, err := s.store.Insert(ctx, cmd, s.now())
+	if err != nil {
+		return err
+	}
+
+	for _, n := range notifications {
+		data, err := json.Marshal(inbox.Event{Notification: n})
+		if err != nil {
+			return err
+		}
+		// The notification is stored, users who are not connected see it on their next visit
+		if err := s.live.Publish(n.OrgID, fmt.Sprintf("grafana/inbox/%d", n.UserID), data); err != nil {
+			s.log.Warn("Failed to push inbox notification", "user", n.UserID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) Search(ctx context.Context, query *inbox.SearchQuery) (*inbox.SearchResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) CountUnread(ctx context.Context, orgID int64, userID int64) (int64, error) {
+	return s.store.CountUnread(ctx, orgID, userID)
+}
+
+func (s *Service) MarkRead(ctx context.Context, cmd *inbox.MarkReadCommand) error {
+	return s.store.MarkRead(ctx, cmd)
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *inbox.DeleteCommand) error {
+	return s.store.Delete(ctx, cmd)
+}
+
+func (s *Service) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.cfg.InboxRetention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.InboxRetention))
+}
+
+func (s *Service) WarnExpiringTokens(ctx context.Context) (int, error) {
+	if s.cfg.InboxTokenExpiryWarning <= 0 {
+		return 0, nil
+	}
+
+	now := s.now()
+	tokens, err := s.store.ExpiringTokens(ctx, now, now.Add(s.cfg.InboxTokenExpiryWarning))
+	if err != nil {
+		return 0, err
+	}
+
+	// The store returns a row per token and org admin
+	type tokenKey struct{ orgID, id int64 }
+	admins := map[tokenKey][]int64{}
+	byKey := map[tokenKey]*expiringToken{}
+	var order []tokenKey
+	for _, t := range tokens {
+		key := tokenKey{orgID: t.OrgID, id: t.ID}
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = t
+			order = append(order, key)
+		}
+		admins[key] = append(admins[key], t.AdminID)
+	}
+
+	for _, key := range order {
+		t := byKey[key]
+		title := fmt.Sprintf("API key %q expires soon", t.Name)
+		url := "/org/apikeys"
+		if t.ServiceAccountID != nil {
+			title = fmt.Sprintf("Service account token %q expires soon", t.Name)
+			url = fmt.Sprintf("/org/serviceaccounts/%d", *t.ServiceAccountID)
+		}
+		err := s.Send(ctx, &inbox.SendCommand{
+			OrgID:    t.OrgID,
+			UserIDs:  admins[key],
+			Kind:     inbox.KindTokenExpiry,
+			Title:    title,
+			Body:     fmt.Sprintf("It expires at %s.", time.Unix(t.Expires, 0).UTC().Format(time.RFC1123)),
+			URL:      url,
+			DedupKey: fmt.Sprintf("token-expiry-%d", t.ID),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(order), nil
+}
@@ -0,0 +1,125 @@
+package inboximpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakePublisher struct {
+	channels []string
+	events   []inbox.Event
+}
+
+func (p *fakePublisher) Publish(_ int64, channel string, data []byte) error {
+	var e inbox.Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	p.channels = append(p.channels, channel)
+	p.events = append(p.events, e)
+	return nil
+}
+
+func setupTestService(t *testing.T) (*Service, *sqlstore.SQLStore, *fakePublisher) {
+	t.Helper()
+	ss := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.InboxRetention = 24 * time.Hour
+	cfg.InboxTokenExpiryWarning = 7 * 24 * time.Hour
+	publisher := &fakePublisher{}
+	return &Service{
+		cfg:   cfg,
+		store: &sqlStore{db: ss},
+		live:  publisher,
+		log:   log.New("inbox.test"),
+		now:   time.Now,
+	}, ss, publisher
+}
+
+func TestIntegrationInboxSend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s, _, publisher := setupTestService(t)
+	ctx := context.Background()
+
+	cmd := &inbox.SendCommand{
+		OrgID:    1,
+		UserIDs:  []int64{2, 3},
+		Kind:     inbox.KindAlertRuleError,
+		Title:    "Alert rule is failing",
+		DedupKey: "rule-1",
+	}
+	require.NoError(t, s.Send(ctx, cmd))
+	require.Equal(t, []string{"grafana/inbox/2", "grafana/inbox/3"}, publisher.channels)
+	require.Equal(t, "Alert rule is failing", publisher.events[0].Notification.Title)
+
+	t.Run("notifications with the same dedup key are only sent once", func(t *testing.T) {
+		cmd.UserIDs = []int64{2, 3, 4}
+		require.NoError(t, s.Send(ctx, cmd))
+		require.Equal(t, "grafana/inbox/4", publisher.channels[len(publisher.channels)-1])
+		require.Len(t, publisher.channels, 3)
+	})
+
+	t.Run("invalid commands are rejected", func(t *testing.T) {
+		err := s.Send(ctx, &inbox.SendCommand{OrgID: 1, UserIDs: []int64{2}, Kind: inbox.KindAlertRuleError})
+		require.ErrorIs(t, err, inbox.ErrCommandValidationFailed)
+	})
+}
+
+func TestIntegrationInboxWarnExpiringTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s, ss, _ := setupTestService(t)
+	ctx := context.Background()
+
+	admin, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@example.com", SkipOrgSetup: true})
+	require.NoError(t, err)
+	viewer, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "viewer", Email: "viewer@example.com", SkipOrgSetup: true})
+	require.NoError(t, err)
+	org, err := ss.CreateOrgWithMember("inbox", admin.Id)
+	require.NoError(t, err)
+	require.NoError(t, ss.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: org.Id, UserId: viewer.Id, Role: models.ROLE_VIEWER}))
+
+	for _, key := range []models.AddApiKeyCommand{
+		{Name: "expires soon", Role: models.ROLE_VIEWER, OrgId: org.Id, Key: "key-1", SecondsToLive: 24 * 60 * 60},
+		{Name: "expires later", Role: models.ROLE_VIEWER, OrgId: org.Id, Key: "key-2", SecondsToLive: 30 * 24 * 60 * 60},
+		{Name: "never expires", Role: models.ROLE_VIEWER, OrgId: org.Id, Key: "key-3"},
+	} {
+		key := key
+		require.NoError(t, ss.AddAPIKey(ctx, &key))
+	}
+
+	count, err := s.WarnExpiringTokens(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	result, err := s.Search(ctx, &inbox.SearchQuery{OrgID: org.Id, UserID: admin.Id})
+	require.NoError(t, err)
+	require.Len(t, result.Notifications, 1)
+	require.Equal(t, inbox.KindTokenExpiry, result.Notifications[0].Kind)
+	require.Equal(t, `API key "expires soon" expires soon`, result.Notifications[0].Title)
+
+	result, err = s.Search(ctx, &inbox.SearchQuery{OrgID: org.Id, UserID: viewer.Id})
+	require.NoError(t, err)
+	require.Empty(t, result.Notifications)
+
+	t.Run("admins are warned once", func(t *testing.T) {
+		_, err := s.WarnExpiringTokens(ctx)
+		require.NoError(t, err)
+		result, err := s.Search(ctx, &inbox.SearchQuery{OrgID: org.Id, UserID: admin.Id})
+		require.NoError(t, err)
+		require.Len(t, result.Notifications, 1)
+	})
+}
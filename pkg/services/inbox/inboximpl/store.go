@@ -0,0 +1,168 @@
+package inboximpl
+
+import (
+	"context"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, cmd *inbox.SendCommand, now time.Time) ([]*inbox.Notification, error)
+	Search(ctx context.Context, query *inbox.SearchQuery) (*inbox.SearchResult, error)
+	CountUnread(ctx context.Context, orgID int64, userID int64) (int64, error)
+	MarkRead(ctx context.Context, cmd *inbox.MarkReadCommand) error
+	Delete(ctx context.Context, cmd *inbox.DeleteCommand) error
+	DeleteOlderThan(ctx context.Context, t time.Time) (int64, error)
+	ExpiringTokens(ctx context.Context, from, to time.Time) ([]*expiringToken, error)
+}
+
+// expiringToken is an API key or service account token together with an admin of its organization.
+type expiringToken struct {
+	ID               int64  `xorm:"id"`
+	OrgID            int64  `xorm:"org_id"`
+	Name             string `xorm:"name"`
+	Expires          int64  `xorm:"expires"`
+	ServiceAccountID *int64 `xorm:"service_account_id"`
+	AdminID          int64  `xorm:"admin_id"`
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, cmd *inbox.SendCommand, now time.Time) ([]*inbox.Notification, error) {
+	var inserted []*inbox.Notification
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, userID := range cmd.UserIDs {
+			if cmd.DedupKey != "" {
+				exists, err := sess.Where("org_id=? AND user_id=? AND dedup_key=?", cmd.OrgID, userID, cmd.DedupKey).Exist(&inbox.Notification{})
+				if err != nil {
+					return err
+				}
+				if exists {
+					continue
+				}
+			}
+
+			n := &inbox.Notification{
+				OrgID:    cmd.OrgID,
+				UserID:   userID,
+				Kind:     cmd.Kind,
+				Title:    cmd.Title,
+				Body:     cmd.Body,
+				URL:      cmd.URL,
+				DedupKey: cmd.DedupKey,
+				Created:  now,
+			}
+			if _, err := sess.Insert(n); err != nil {
+				return err
+			}
+			inserted = append(inserted, n)
+		}
+		return nil
+	})
+	return inserted, err
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *inbox.SearchQuery) (*inbox.SearchResult, error) {
+	result := &inbox.SearchResult{
+		Notifications: make([]*inbox.Notification, 0),
+		Page:          query.Page,
+		PerPage:       query.Limit,
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		filter := func() *xorm.Session {
+			q := sess.Where("org_id=? AND user_id=?", query.OrgID, query.UserID)
+			if query.Kind != "" {
+				q = q.And("kind=?", query.Kind)
+			}
+			if query.UnreadOnly {
+				q = q.And("is_read=?", false)
+			}
+			return q
+		}
+
+		var err error
+		result.TotalCount, err = filter().Count(&inbox.Notification{})
+		if err != nil {
+			return err
+		}
+		offset := query.Limit * (query.Page - 1)
+		return filter().Desc("id").Limit(query.Limit, offset).Find(&result.Notifications)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.UnreadCount, err = s.CountUnread(ctx, query.OrgID, query.UserID)
+	return result, err
+}
+
+func (s *sqlStore) CountUnread(ctx context.Context, orgID int64, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		count, err = sess.Where("org_id=? AND user_id=? AND is_read=?", orgID, userID, false).Count(&inbox.Notification{})
+		return err
+	})
+	return count, err
+}
+
+func (s *sqlStore) MarkRead(ctx context.Context, cmd *inbox.MarkReadCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id=? AND user_id=?", cmd.OrgID, cmd.UserID)
+		if len(cmd.IDs) > 0 {
+			q = q.In("id", cmd.IDs)
+		}
+		_, err := q.Cols("is_read").Update(&inbox.Notification{Read: cmd.Read})
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, cmd *inbox.DeleteCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM inbox_notification WHERE org_id=? AND user_id=? AND id=?", cmd.OrgID, cmd.UserID, cmd.ID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return inbox.ErrNotificationNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM inbox_notification WHERE created < ?", t)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+func (s *sqlStore) ExpiringTokens(ctx context.Context, from, to time.Time) ([]*expiringToken, error) {
+	tokens := make([]*expiringToken, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := `SELECT api_key.id, api_key.org_id, api_key.name, api_key.expires, api_key.service_account_id, org_user.user_id AS admin_id
+			FROM api_key
+			INNER JOIN org_user ON org_user.org_id = api_key.org_id AND org_user.role = ?
+			WHERE api_key.expires IS NOT NULL AND api_key.expires >= ? AND api_key.expires < ?
+			ORDER BY api_key.id, org_user.user_id`
+		return sess.SQL(rawSQL, "Admin", from.Unix(), to.Unix()).Find(&tokens)
+	})
+	return tokens, err
+}
@@ -0,0 +1,90 @@
+package inboximpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationInboxDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	store := sqlStore{db: ss}
+	ctx := context.Background()
+	now := time.Now()
+
+	send := func(userID int64, kind inbox.Kind, title string, created time.Time) {
+		_, err := store.Insert(ctx, &inbox.SendCommand{OrgID: 1, UserIDs: []int64{userID}, Kind: kind, Title: title}, created)
+		require.NoError(t, err)
+	}
+	send(1, inbox.KindCommentMention, "old", now.Add(-48*time.Hour))
+	send(1, inbox.KindAlertRuleError, "first", now.Add(-time.Hour))
+	send(1, inbox.KindCommentMention, "second", now)
+	send(2, inbox.KindCommentMention, "other user", now)
+
+	t.Run("Search returns the notifications of the user newest first", func(t *testing.T) {
+		result, err := store.Search(ctx, &inbox.SearchQuery{OrgID: 1, UserID: 1, Page: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Equal(t, int64(3), result.UnreadCount)
+		require.Len(t, result.Notifications, 2)
+		require.Equal(t, "second", result.Notifications[0].Title)
+		require.Equal(t, "first", result.Notifications[1].Title)
+	})
+
+	t.Run("Search filters by kind", func(t *testing.T) {
+		result, err := store.Search(ctx, &inbox.SearchQuery{OrgID: 1, UserID: 1, Kind: inbox.KindAlertRuleError, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result.Notifications, 1)
+		require.Equal(t, "first", result.Notifications[0].Title)
+	})
+
+	t.Run("MarkRead updates the read state", func(t *testing.T) {
+		result, err := store.Search(ctx, &inbox.SearchQuery{OrgID: 1, UserID: 1, Page: 1, Limit: 10})
+		require.NoError(t, err)
+
+		err = store.MarkRead(ctx, &inbox.MarkReadCommand{OrgID: 1, UserID: 1, IDs: []int64{result.Notifications[0].ID}, Read: true})
+		require.NoError(t, err)
+		count, err := store.CountUnread(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		unread, err := store.Search(ctx, &inbox.SearchQuery{OrgID: 1, UserID: 1, UnreadOnly: true, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, unread.Notifications, 2)
+
+		err = store.MarkRead(ctx, &inbox.MarkReadCommand{OrgID: 1, UserID: 1, Read: true})
+		require.NoError(t, err)
+		count, err = store.CountUnread(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+
+		// Notifications of other users are not affected
+		count, err = store.CountUnread(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	})
+
+	t.Run("Delete only removes notifications of the user", func(t *testing.T) {
+		result, err := store.Search(ctx, &inbox.SearchQuery{OrgID: 1, UserID: 2, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		id := result.Notifications[0].ID
+
+		err = store.Delete(ctx, &inbox.DeleteCommand{OrgID: 1, UserID: 1, ID: id})
+		require.ErrorIs(t, err, inbox.ErrNotificationNotFound)
+		require.NoError(t, store.Delete(ctx, &inbox.DeleteCommand{OrgID: 1, UserID: 2, ID: id}))
+	})
+
+	t.Run("DeleteOlderThan removes expired notifications", func(t *testing.T) {
+		affected, err := store.DeleteOlderThan(ctx, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), affected)
+	})
+}
@@ -0,0 +1,103 @@
+package inbox
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrCommandValidationFailed = errors.New("command missing required fields")
+)
+
+// Kind is the type of event a notification is about.
+type Kind string
+
+const (
+	KindCommentMention Kind = "comment_mention"
+	KindAlertRuleError Kind = "alert_rule_error"
+	// KindReportFailure is used by reporting integrations when a scheduled report could not be sent.
+	KindReportFailure Kind = "report_failure"
+	KindTokenExpiry   Kind = "token_expiry"
+	// KindCertificateExpiry is sent to org admins about expiring datasource TLS certificates.
+	KindCertificateExpiry Kind = "certificate_expiry"
+)
+
+type Notification struct {
+	ID     int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID  int64  `xorm:"org_id" json:"orgId"`
+	UserID int64  `xorm:"user_id" json:"userId"`
+	Kind   Kind   `xorm:"kind" json:"kind"`
+	Title  string `xorm:"title" json:"title"`
+	Body   string `xorm:"body" json:"body"`
+	// URL is relative to the Grafana root URL.
+	URL      string    `xorm:"url" json:"url"`
+	DedupKey string    `xorm:"dedup_key" json:"-"`
+	Read     bool      `xorm:"is_read" json:"read"`
+	Created  time.Time `json:"created"`
+}
+
+func (n Notification) TableName() string {
+	return "inbox_notification"
+}
+
+// Event is published on the grafana/inbox/<user id> Live channel.
+type Event struct {
+	Notification *Notification `json:"notification"`
+}
+
+// ----------------------
+// COMMANDS
+
+type SendCommand struct {
+	OrgID   int64
+	UserIDs []int64
+	Kind    Kind
+	Title   string
+	Body    string
+	URL     string
+	// DedupKey identifies the event, e.g. an expiring token, so that users are
+	// notified about it once while the first notification is kept.
+	DedupKey string
+}
+
+func (cmd *SendCommand) Validate() error {
+	if cmd.OrgID == 0 || cmd.Kind == "" || cmd.Title == "" {
+		return ErrCommandValidationFailed
+	}
+	return nil
+}
+
+type MarkReadCommand struct {
+	OrgID  int64
+	UserID int64
+	// IDs of the notifications to mark, all notifications of the user if empty.
+	IDs  []int64
+	Read bool
+}
+
+type DeleteCommand struct {
+	OrgID  int64
+	UserID int64
+	ID     int64
+}
+
+// ---------------------
+// QUERIES
+
+type SearchQuery struct {
+	OrgID      int64
+	UserID     int64
+	Kind       Kind
+	UnreadOnly bool
+	Page       int
+	Limit      int
+}
+
+type SearchResult struct {
+	TotalCount    int64           `json:"totalCount"`
+	UnreadCount   int64           `json:"unreadCount"`
+	Notifications []*Notification `json:"notifications"`
+	Page          int             `json:"page"`
+	PerPage       int             `json:"perPage"`
+}
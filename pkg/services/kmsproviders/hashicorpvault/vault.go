@@ -0,0 +1,147 @@
+// Package hashicorpvault implements a key encryption key provider
+// backed by the HashiCorp Vault transit secrets engine.
+package hashicorpvault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const defaultTransitEnginePath = "transit"
+
+type provider struct {
+	client            *http.Client
+	url               *url.URL
+	token             string
+	namespace         string
+	transitEnginePath string
+	keyName           string
+}
+
+// New returns a provider configured from a
+// [security.encryption.hashicorpvault.<keyName>] section.
+//
+// Supported keys are url, token, namespace, transit_engine_path,
+// key_ring and timeout.
+func New(section setting.Section) (secrets.Provider, error) {
+	rawURL := section.KeyValue("url").Value()
+	if rawURL == "" {
+		return nil, errors.New("missing vault url")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault url: %w", err)
+	}
+
+	token := section.KeyValue("token").Value()
+	if token == "" {
+		return nil, errors.New("missing vault token")
+	}
+
+	keyName := section.KeyValue("key_ring").Value()
+	if keyName == "" {
+		return nil, errors.New("missing vault transit key name (key_ring)")
+	}
+
+	return &provider{
+		client:            &http.Client{Timeout: section.KeyValue("timeout").MustDuration(10 * time.Second)},
+		url:               u,
+		token:             token,
+		namespace:         section.KeyValue("namespace").Value(),
+		transitEnginePath: strings.Trim(section.KeyValue("transit_engine_path").MustString(defaultTransitEnginePath), "/"),
+		keyName:           keyName,
+	}, nil
+}
+
+type transitResponse struct {
+	Data struct {
+		Ciphertext string `json:"ciphertext"`
+		Plaintext  string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func (p *provider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	res, err := p.transit(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(blob),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(res.Data.Ciphertext), nil
+}
+
+func (p *provider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	res, err := p.transit(ctx, "decrypt", map[string]string{
+		"ciphertext": string(blob),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(res.Data.Plaintext)
+}
+
+// CheckHealth verifies that the transit key can be read with the
+// configured token.
+func (p *provider) CheckHealth(ctx context.Context) error {
+	_, err := p.do(ctx, http.MethodGet, path.Join("/v1", p.transitEnginePath, "keys", p.keyName), nil)
+	return err
+}
+
+func (p *provider) transit(ctx context.Context, operation string, body map[string]string) (*transitResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return p.do(ctx, http.MethodPost, path.Join("/v1", p.transitEnginePath, operation, p.keyName), payload)
+}
+
+func (p *provider) do(ctx context.Context, method, endpoint string, payload []byte) (*transitResponse, error) {
+	u := *p.url
+	u.Path = path.Join(u.Path, endpoint)
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	req.Header.Set("Content-Type", "application/json")
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var res transitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault request failed with status %d: %s", resp.StatusCode, strings.Join(res.Errors, ", "))
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,90 @@
+package hashicorpvault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestVaultProvider(t *testing.T) {
+	var lastPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath = r.URL.Path
+		if r.Header.Get("X-Vault-Token") != "s3cr3t" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/grafana":
+			_, _ = w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + body["plaintext"] + `"}}`))
+		case "/v1/transit/decrypt/grafana":
+			_, _ = w.Write([]byte(`{"data":{"plaintext":"` + body["ciphertext"][len("vault:v1:"):] + `"}}`))
+		case "/v1/transit/keys/grafana":
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	newProvider := func(t *testing.T, token string) *provider {
+		t.Helper()
+		raw, err := ini.Load([]byte(`
+			[security.encryption.hashicorpvault.v1]
+			url = ` + srv.URL + `
+			token = ` + token + `
+			key_ring = grafana`))
+		require.NoError(t, err)
+
+		settings := setting.ProvideProvider(&setting.Cfg{Raw: raw})
+		p, err := New(settings.Section("security.encryption.hashicorpvault.v1"))
+		require.NoError(t, err)
+		return p.(*provider)
+	}
+
+	t.Run("encrypts and decrypts through the transit engine", func(t *testing.T) {
+		p := newProvider(t, "s3cr3t")
+
+		encrypted, err := p.Encrypt(context.Background(), []byte("grafana"))
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/transit/encrypt/grafana", lastPath)
+
+		decrypted, err := p.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("health check reads the transit key", func(t *testing.T) {
+		p := newProvider(t, "s3cr3t")
+		require.NoError(t, p.CheckHealth(context.Background()))
+		assert.Equal(t, "/v1/transit/keys/grafana", lastPath)
+	})
+
+	t.Run("returns vault errors", func(t *testing.T) {
+		p := newProvider(t, "wrong")
+		_, err := p.Encrypt(context.Background(), []byte("grafana"))
+		require.ErrorContains(t, err, "permission denied")
+		require.Error(t, p.CheckHealth(context.Background()))
+	})
+
+	t.Run("requires url, token and key", func(t *testing.T) {
+		raw := ini.Empty()
+		settings := setting.ProvideProvider(&setting.Cfg{Raw: raw})
+		_, err := New(settings.Section("security.encryption.hashicorpvault.v1"))
+		require.Error(t, err)
+	})
+}
@@ -14,6 +14,15 @@ const (
 	Default = "secretKey.v1"
 )
 
+// Kinds of key encryption key providers that can be configured through
+// the security.available_encryption_providers setting.
+const (
+	AwsKms         = "awskms"
+	AzureKv        = "azurekv"
+	GoogleKms      = "googlekms"
+	HashicorpVault = "hashicorpvault"
+)
+
 type Service interface {
 	Provide() (map[secrets.ProviderID]secrets.Provider, error)
 }
@@ -1,10 +1,18 @@
 package osskmsproviders
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/awskms"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/azurekv"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/googlekms"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/hashicorpvault"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -23,12 +31,50 @@ func ProvideService(enc encryption.Internal, settings setting.Provider, features
 	}
 }
 
+var logger = log.New("kmsproviders")
+
+type providerFactory func(section setting.Section) (secrets.Provider, error)
+
+var factories = map[string]providerFactory{
+	kmsproviders.AwsKms:         awskms.New,
+	kmsproviders.AzureKv:        azurekv.New,
+	kmsproviders.GoogleKms:      googlekms.New,
+	kmsproviders.HashicorpVault: hashicorpvault.New,
+}
+
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
 	if s.features.IsEnabled(featuremgmt.FlagDisableEnvelopeEncryption) {
 		return nil, nil
 	}
 
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.settings, s.enc),
-	}, nil
+	}
+
+	available := s.settings.KeyValue("security", "available_encryption_providers").Value()
+	for _, id := range strings.Fields(available) {
+		providerID := kmsproviders.NormalizeProviderID(secrets.ProviderID(id))
+		if _, exists := providers[providerID]; exists {
+			continue
+		}
+
+		kind, err := providerID.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		factory, ok := factories[kind]
+		if !ok {
+			logger.Warn("Skipping unknown encryption provider", "provider", providerID)
+			continue
+		}
+
+		provider, err := factory(s.settings.Section("security.encryption." + string(providerID)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure encryption provider %s: %w", providerID, err)
+		}
+		providers[providerID] = provider
+	}
+
+	return providers, nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -414,6 +415,28 @@ func (l *LibraryElementService) getAllLibraryElements(c context.Context, signedI
 	return result, err
 }
 
+// getElementsInFolderID gets all Library Elements of a kind in a folder, one page at a time.
+func (l *LibraryElementService) getElementsInFolderID(c context.Context, signedInUser *models.SignedInUser, folderID int64, kind models.LibraryElementKind) ([]LibraryElementDTO, error) {
+	query := searchLibraryElementsQuery{
+		perPage:      100,
+		page:         1,
+		kind:         int(kind),
+		folderFilter: strconv.FormatInt(folderID, 10),
+	}
+	elements := make([]LibraryElementDTO, 0)
+	for {
+		result, err := l.getAllLibraryElements(c, signedInUser, query)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, result.Elements...)
+		if len(result.Elements) < query.perPage {
+			return elements, nil
+		}
+		query.page++
+	}
+}
+
 func (l *LibraryElementService) handleFolderIDPatches(ctx context.Context, elementToPatch *LibraryElement, fromFolderID int64, toFolderID int64, user *models.SignedInUser) error {
 	// FolderID was not provided in the PATCH request
 	if toFolderID == -1 {
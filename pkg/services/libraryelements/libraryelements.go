@@ -28,6 +28,7 @@ type Service interface {
 	CreateElement(c context.Context, signedInUser *models.SignedInUser, cmd CreateLibraryElementCommand) (LibraryElementDTO, error)
 	GetElement(c context.Context, signedInUser *models.SignedInUser, UID string) (LibraryElementDTO, error)
 	GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]LibraryElementDTO, error)
+	GetElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderID int64, kind models.LibraryElementKind) ([]LibraryElementDTO, error)
 	ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error
 	DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderUID string) error
@@ -57,6 +58,11 @@ func (l *LibraryElementService) GetElementsForDashboard(c context.Context, dashb
 	return l.getElementsForDashboardID(c, dashboardID)
 }
 
+// GetElementsInFolder gets all elements of a kind in a specific folder.
+func (l *LibraryElementService) GetElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderID int64, kind models.LibraryElementKind) ([]LibraryElementDTO, error) {
+	return l.getElementsInFolderID(c, signedInUser, folderID, kind)
+}
+
 // ConnectElementsToDashboard connects elements to a specific dashboard.
 func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error {
 	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, dashboardID)
@@ -1259,4 +1259,22 @@ func TestGetAllLibraryElements(t *testing.T) {
 				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
 			}
 		})
+
+	scenarioWithPanel(t, "When an admin gets the library panels in a folder, it should only return the panels of the folder",
+		func(t *testing.T, sc scenarioContext) {
+			newFolder := createFolderWithACL(t, sc.sqlStore, "NewFolder", sc.user, []folderACLItem{})
+			for _, command := range []CreateLibraryElementCommand{
+				getCreatePanelCommand(newFolder.Id, "Text - Library Panel2"),
+				getCreateVariableCommand(sc.folder.Id, "query0"),
+			} {
+				sc.reqContext.Req.Body = mockRequestBody(command)
+				resp := sc.service.createHandler(sc.reqContext)
+				require.Equal(t, 200, resp.Status())
+			}
+
+			elements, err := sc.service.GetElementsInFolder(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, sc.folder.Id, models.PanelElement)
+			require.NoError(t, err)
+			require.Len(t, elements, 1)
+			require.Equal(t, sc.initialResult.Result.UID, elements[0].UID)
+		})
 }
@@ -236,10 +236,10 @@ func createFolderWithACL(t *testing.T, sqlStore *sqlstore.SQLStore, title string
 	)
 	s := dashboardservice.ProvideFolderService(
 		cfg, d, dashboardStore, nil,
-		features, folderPermissions, ac,
+		features, folderPermissions, ac, sqlStore,
 	)
 	t.Logf("Creating folder with title and UID %q", title)
-	folder, err := s.CreateFolder(context.Background(), &user, user.OrgId, title, title)
+	folder, err := s.CreateFolder(context.Background(), &user, user.OrgId, title, title, "")
 	require.NoError(t, err)
 
 	updateFolderACL(t, dashboardStore, folder.Id, items)
@@ -340,7 +340,7 @@ func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioCo
 			SQLStore: sqlStore,
 			folderService: dashboardservice.ProvideFolderService(
 				cfg, dashboardService, dashboardStore, nil,
-				features, folderPermissions, ac,
+				features, folderPermissions, ac, sqlStore,
 			),
 		}
 
@@ -0,0 +1,71 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// AlertingStateHandler manages the `grafana/alerting/state/<folder uid>` channels the state
+// changes of the alert rules in a folder are pushed to.
+type AlertingStateHandler struct {
+	accessControl    accesscontrol.AccessControl
+	dashboardService dashboards.DashboardService
+}
+
+func NewAlertingStateHandler(accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService) *AlertingStateHandler {
+	return &AlertingStateHandler{accessControl: accessControl, dashboardService: dashboardService}
+}
+
+// GetHandlerForPath called on init.
+func (h *AlertingStateHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil
+}
+
+// OnSubscribe only allows users who can read the alert rules of a folder to subscribe to its channel.
+func (h *AlertingStateHandler) OnSubscribe(ctx context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	parts := strings.Split(e.Path, "/")
+	if len(parts) != 2 || parts[0] != "state" || parts[1] == "" {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+	folderUID := parts[1]
+
+	query := models.GetDashboardQuery{Uid: folderUID, OrgId: user.OrgId}
+	if err := h.dashboardService.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) || errors.Is(err, models.ErrFolderNotFound) {
+			return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+		}
+		return models.SubscribeReply{}, 0, err
+	}
+	if !query.Result.IsFolder {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+
+	var ok bool
+	var err error
+	if h.accessControl.IsDisabled() {
+		ok, err = guardian.New(ctx, query.Result.Id, user.OrgId, user).CanView()
+	} else {
+		evaluator := accesscontrol.EvalPermission(accesscontrol.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID))
+		ok, err = h.accessControl.Evaluate(ctx, user, evaluator)
+	}
+	if err != nil {
+		return models.SubscribeReply{}, 0, err
+	}
+	if !ok {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is not used for alert states, they are only published by the server.
+func (h *AlertingStateHandler) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
@@ -0,0 +1,58 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestAlertingStateHandler_OnSubscribe(t *testing.T) {
+	dashboardService := &dashboards.FakeDashboardService{}
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = &models.Dashboard{Id: 1, Uid: q.Uid, OrgId: q.OrgId, IsFolder: q.Uid != "dash"}
+	}).Return(func(_ context.Context, q *models.GetDashboardQuery) error {
+		if q.Uid == "missing" {
+			return models.ErrDashboardNotFound
+		}
+		return nil
+	})
+
+	ac := acmock.New().WithPermissions([]accesscontrol.Permission{
+		{Action: accesscontrol.ActionAlertingRuleRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("allowed")},
+	})
+	h := NewAlertingStateHandler(ac, dashboardService)
+	user := &models.SignedInUser{UserId: 3, OrgId: 1}
+
+	testCases := []struct {
+		path     string
+		expected backend.SubscribeStreamStatus
+	}{
+		{path: "state/allowed", expected: backend.SubscribeStreamStatusOK},
+		{path: "state/other", expected: backend.SubscribeStreamStatusPermissionDenied},
+		{path: "state/missing", expected: backend.SubscribeStreamStatusNotFound},
+		{path: "state/dash", expected: backend.SubscribeStreamStatusNotFound},
+		{path: "state", expected: backend.SubscribeStreamStatusNotFound},
+		{path: "state/", expected: backend.SubscribeStreamStatusNotFound},
+		{path: "rules/allowed", expected: backend.SubscribeStreamStatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			_, status, err := h.OnSubscribe(context.Background(), user, models.SubscribeEvent{Path: tc.path})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, status)
+		})
+	}
+
+	_, status, err := h.OnPublish(context.Background(), user, models.PublishEvent{Path: "state/allowed"})
+	require.NoError(t, err)
+	require.Equal(t, backend.PublishStreamStatusPermissionDenied, status)
+}
@@ -0,0 +1,35 @@
+package features
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// InboxHandler manages the `grafana/inbox/<user id>` channels new inbox notifications are pushed to.
+type InboxHandler struct{}
+
+func NewInboxHandler() *InboxHandler {
+	return &InboxHandler{}
+}
+
+// GetHandlerForPath called on init.
+func (h *InboxHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil
+}
+
+// OnSubscribe only allows users to subscribe to their own inbox.
+func (h *InboxHandler) OnSubscribe(_ context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if user.UserId == 0 || e.Path != strconv.FormatInt(user.UserId, 10) {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is not used for the inbox, notifications are only published by the server.
+func (h *InboxHandler) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
@@ -0,0 +1,28 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestInboxHandler_OnSubscribe(t *testing.T) {
+	h := NewInboxHandler()
+	user := &models.SignedInUser{UserId: 3, OrgId: 1}
+
+	_, status, err := h.OnSubscribe(context.Background(), user, models.SubscribeEvent{Path: "3"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	_, status, err = h.OnSubscribe(context.Background(), user, models.SubscribeEvent{Path: "4"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+
+	_, status, err = h.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1}, models.SubscribeEvent{Path: "0"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+}
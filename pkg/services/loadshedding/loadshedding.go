@@ -0,0 +1,61 @@
+package loadshedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRequestShed is returned when a request is rejected because the instance is saturated.
+var ErrRequestShed = errors.New("grafana is overloaded, try again later")
+
+// Service protects the instance when it is saturated, by shedding or queueing
+// the requests of low priority classes so that higher priority requests, such
+// as alert evaluation, keep working.
+type Service interface {
+	// Admit returns a function to call when the request is done, or
+	// ErrRequestShed if the request is rejected. Requests of queued classes wait
+	// for a slot while the instance is saturated.
+	Admit(ctx context.Context, class Class) (release func(), err error)
+	// Saturation returns the last sampled saturation of the instance.
+	Saturation() Saturation
+}
+
+// Class is the priority class of a request.
+type Class string
+
+const (
+	// ClassAlerting are the requests of the alerting APIs.
+	ClassAlerting Class = "alerting"
+	// ClassInteractive are the requests of users of the UI.
+	ClassInteractive Class = "interactive"
+	// ClassRendering are the requests made to render images and the requests
+	// made by the image renderer.
+	ClassRendering Class = "rendering"
+	// ClassAPI are the requests of automation authenticated with API keys,
+	// service accounts or basic auth.
+	ClassAPI Class = "api"
+)
+
+// Classes are the priority classes, from the highest to the lowest priority.
+var Classes = []Class{ClassAlerting, ClassInteractive, ClassRendering, ClassAPI}
+
+// ParseClass returns the class with the name.
+func ParseClass(name string) (Class, error) {
+	for _, c := range Classes {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown request class %q", name)
+}
+
+// Saturation is the usage of the resources of the instance.
+type Saturation struct {
+	// CPU is the CPU usage of the process, from 0 to 1 for all the CPUs it can use.
+	CPU float64 `json:"cpu"`
+	// DBConnections is the ratio of the open database connections that are in
+	// use, it is 0 when the number of connections is unlimited.
+	DBConnections float64 `json:"dbConnections"`
+	Saturated     bool    `json:"saturated"`
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package loadsheddingimpl
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the CPU time used by the process.
+func processCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
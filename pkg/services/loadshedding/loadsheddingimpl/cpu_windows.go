@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package loadsheddingimpl
+
+import "time"
+
+func processCPUTime() (time.Duration, bool) {
+	// TODO implement Windows process CPU time
+	return 0, false
+}
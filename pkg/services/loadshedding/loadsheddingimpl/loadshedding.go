@@ -0,0 +1,219 @@
+package loadsheddingimpl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	saturatedGauge         prometheus.Gauge
+	cpuUsageGauge          prometheus.Gauge
+	dbConnectionUsageGauge prometheus.Gauge
+	requestsShedCounter    *prometheus.CounterVec
+)
+
+func init() {
+	saturatedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "load_shedding",
+		Name:      "saturated",
+		Help:      "1 while the instance is saturated and low priority requests are shed or queued.",
+	})
+	cpuUsageGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "load_shedding",
+		Name:      "cpu_usage_ratio",
+		Help:      "CPU usage of the process, from 0 to 1 for all the CPUs it can use.",
+	})
+	dbConnectionUsageGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "load_shedding",
+		Name:      "db_connections_usage_ratio",
+		Help:      "Ratio of the open database connections that are in use.",
+	})
+	requestsShedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "load_shedding",
+		Name:      "requests_shed_total",
+		Help:      "Number of requests rejected because the instance was saturated.",
+	}, []string{"class"})
+
+	prometheus.MustRegister(saturatedGauge, cpuUsageGauge, dbConnectionUsageGauge, requestsShedCounter)
+}
+
+// Service samples the saturation of the instance in the background, so that
+// admitting a request doesn't have to measure anything.
+type Service struct {
+	cfg     *setting.Cfg
+	log     log.Logger
+	now     func() time.Time
+	cpuTime func() (time.Duration, bool)
+	dbStats func() sql.DBStats
+	cpus    int
+
+	shed   map[loadshedding.Class]bool
+	queued map[loadshedding.Class]bool
+	// slots limits the concurrent requests of the queued classes while saturated.
+	slots chan struct{}
+
+	// saturated is read for every request, it is 1 while the instance is saturated.
+	saturated int32
+
+	mu          sync.RWMutex
+	saturation  loadshedding.Saturation
+	lastCPUTime time.Duration
+	lastSample  time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (*Service, error) {
+	return newService(cfg, sqlStore.DBStats)
+}
+
+func newService(cfg *setting.Cfg, dbStats func() sql.DBStats) (*Service, error) {
+	s := &Service{
+		cfg:     cfg,
+		log:     log.New("loadshedding"),
+		now:     time.Now,
+		cpuTime: processCPUTime,
+		dbStats: dbStats,
+		cpus:    runtime.GOMAXPROCS(0),
+		shed:    map[loadshedding.Class]bool{},
+		queued:  map[loadshedding.Class]bool{},
+	}
+
+	for _, name := range cfg.LoadSheddingShedClasses {
+		class, err := loadshedding.ParseClass(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid [load_shedding] shed_classes: %w", err)
+		}
+		s.shed[class] = true
+	}
+	for _, name := range cfg.LoadSheddingQueueClasses {
+		class, err := loadshedding.ParseClass(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid [load_shedding] queue_classes: %w", err)
+		}
+		if s.shed[class] {
+			return nil, fmt.Errorf("invalid [load_shedding] queue_classes: class %q is already shed", class)
+		}
+		s.queued[class] = true
+	}
+
+	concurrency := cfg.LoadSheddingQueueConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	s.slots = make(chan struct{}, concurrency)
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.LoadSheddingEnabled
+}
+
+// Run samples the saturation periodically.
+func (s *Service) Run(ctx context.Context) error {
+	if _, ok := s.cpuTime(); !ok && s.cfg.LoadSheddingCPUThreshold > 0 {
+		s.log.Warn("The CPU usage of the process cannot be measured on this platform, only the database connections are checked")
+	}
+	s.sample()
+
+	ticker := time.NewTicker(s.cfg.LoadSheddingCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *Service) sample() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saturation := loadshedding.Saturation{}
+	now := s.now()
+	if cpuTime, ok := s.cpuTime(); ok {
+		if elapsed := now.Sub(s.lastSample); !s.lastSample.IsZero() && elapsed > 0 {
+			saturation.CPU = float64(cpuTime-s.lastCPUTime) / float64(elapsed) / float64(s.cpus)
+		}
+		s.lastCPUTime = cpuTime
+	}
+	s.lastSample = now
+	if stats := s.dbStats(); stats.MaxOpenConnections > 0 {
+		saturation.DBConnections = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+	saturation.Saturated = exceeds(saturation.CPU, s.cfg.LoadSheddingCPUThreshold) ||
+		exceeds(saturation.DBConnections, s.cfg.LoadSheddingDBThreshold)
+
+	if saturation.Saturated != s.saturation.Saturated {
+		if saturation.Saturated {
+			s.log.Warn("Instance is saturated, shedding low priority requests", "cpu", saturation.CPU, "dbConnections", saturation.DBConnections)
+		} else {
+			s.log.Info("Instance is no longer saturated", "cpu", saturation.CPU, "dbConnections", saturation.DBConnections)
+		}
+	}
+	s.saturation = saturation
+
+	cpuUsageGauge.Set(saturation.CPU)
+	dbConnectionUsageGauge.Set(saturation.DBConnections)
+	if saturation.Saturated {
+		saturatedGauge.Set(1)
+		atomic.StoreInt32(&s.saturated, 1)
+	} else {
+		saturatedGauge.Set(0)
+		atomic.StoreInt32(&s.saturated, 0)
+	}
+}
+
+func (s *Service) Saturation() loadshedding.Saturation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.saturation
+}
+
+func (s *Service) Admit(ctx context.Context, class loadshedding.Class) (func(), error) {
+	if !s.cfg.LoadSheddingEnabled || atomic.LoadInt32(&s.saturated) == 0 {
+		return func() {}, nil
+	}
+	if s.shed[class] {
+		requestsShedCounter.WithLabelValues(string(class)).Inc()
+		return nil, loadshedding.ErrRequestShed
+	}
+	if !s.queued[class] {
+		return func() {}, nil
+	}
+
+	timer := time.NewTimer(s.cfg.LoadSheddingQueueTimeout)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-s.slots }) }, nil
+	case <-timer.C:
+		requestsShedCounter.WithLabelValues(string(class)).Inc()
+		return nil, loadshedding.ErrRequestShed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// exceeds returns true if the usage reaches the threshold, a threshold of 0 disables the check.
+func exceeds(usage, threshold float64) bool {
+	return threshold > 0 && usage >= threshold
+}
@@ -0,0 +1,117 @@
+package loadsheddingimpl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LoadSheddingEnabled = true
+	cfg.LoadSheddingCPUThreshold = 0.9
+	cfg.LoadSheddingDBThreshold = 0.9
+	cfg.LoadSheddingShedClasses = []string{"api"}
+	cfg.LoadSheddingQueueClasses = []string{"rendering"}
+	cfg.LoadSheddingQueueConcurrency = 1
+	cfg.LoadSheddingQueueTimeout = 10 * time.Millisecond
+
+	dbStats := sql.DBStats{MaxOpenConnections: 10}
+	s, err := newService(cfg, func() sql.DBStats { return dbStats })
+	require.NoError(t, err)
+
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	cpuTime := time.Duration(0)
+	s.now = func() time.Time { return now }
+	s.cpuTime = func() (time.Duration, bool) { return cpuTime, true }
+	s.cpus = 2
+
+	// sample advances the clock by a second, during which the process used the CPU time.
+	sample := func(used time.Duration) {
+		now = now.Add(time.Second)
+		cpuTime += used
+		s.sample()
+	}
+
+	admitted := func(class loadshedding.Class) bool {
+		release, err := s.Admit(context.Background(), class)
+		if err != nil {
+			require.ErrorIs(t, err, loadshedding.ErrRequestShed)
+			return false
+		}
+		release()
+		return true
+	}
+
+	s.sample()
+	sample(time.Second)
+	require.Equal(t, loadshedding.Saturation{CPU: 0.5}, s.Saturation())
+
+	t.Run("all requests are admitted when the instance is not saturated", func(t *testing.T) {
+		for _, class := range loadshedding.Classes {
+			require.True(t, admitted(class))
+		}
+	})
+
+	t.Run("low priority requests are shed when the CPU is saturated", func(t *testing.T) {
+		sample(2 * time.Second)
+		require.True(t, s.Saturation().Saturated)
+		require.Equal(t, 1.0, s.Saturation().CPU)
+
+		require.True(t, admitted(loadshedding.ClassAlerting))
+		require.True(t, admitted(loadshedding.ClassInteractive))
+		require.True(t, admitted(loadshedding.ClassRendering))
+		require.False(t, admitted(loadshedding.ClassAPI))
+	})
+
+	t.Run("queued requests wait for a slot", func(t *testing.T) {
+		release, err := s.Admit(context.Background(), loadshedding.ClassRendering)
+		require.NoError(t, err)
+		require.False(t, admitted(loadshedding.ClassRendering))
+
+		release()
+		require.True(t, admitted(loadshedding.ClassRendering))
+	})
+
+	t.Run("low priority requests are shed when the database connections are saturated", func(t *testing.T) {
+		dbStats.InUse = 9
+		sample(0)
+		require.Equal(t, loadshedding.Saturation{DBConnections: 0.9, Saturated: true}, s.Saturation())
+		require.False(t, admitted(loadshedding.ClassAPI))
+	})
+
+	t.Run("requests are admitted again when the instance recovers", func(t *testing.T) {
+		dbStats.InUse = 2
+		sample(0)
+		require.False(t, s.Saturation().Saturated)
+		require.True(t, admitted(loadshedding.ClassAPI))
+	})
+
+	t.Run("requests are admitted when load shedding is disabled", func(t *testing.T) {
+		dbStats.InUse = 10
+		sample(0)
+		require.True(t, s.Saturation().Saturated)
+
+		cfg.LoadSheddingEnabled = false
+		t.Cleanup(func() { cfg.LoadSheddingEnabled = true })
+		require.True(t, admitted(loadshedding.ClassAPI))
+	})
+}
+
+func TestProvideService_InvalidClasses(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LoadSheddingShedClasses = []string{"search"}
+	_, err := newService(cfg, func() sql.DBStats { return sql.DBStats{} })
+	require.Error(t, err)
+
+	cfg.LoadSheddingShedClasses = []string{"api"}
+	cfg.LoadSheddingQueueClasses = []string{"api"}
+	_, err = newService(cfg, func() sql.DBStats { return sql.DBStats{} })
+	require.Error(t, err)
+}
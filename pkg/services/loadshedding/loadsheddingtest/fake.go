@@ -0,0 +1,32 @@
+package loadsheddingtest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/loadshedding"
+)
+
+type FakeService struct {
+	ExpectedSaturation loadshedding.Saturation
+	// Shed are the classes that are rejected.
+	Shed map[loadshedding.Class]bool
+	// Admitted are the classes of the admitted requests.
+	Admitted []loadshedding.Class
+	Released int
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{Shed: map[loadshedding.Class]bool{}}
+}
+
+func (f *FakeService) Admit(ctx context.Context, class loadshedding.Class) (func(), error) {
+	if f.Shed[class] {
+		return nil, loadshedding.ErrRequestShed
+	}
+	f.Admitted = append(f.Admitted, class)
+	return func() { f.Released++ }, nil
+}
+
+func (f *FakeService) Saturation() loadshedding.Saturation {
+	return f.ExpectedSaturation
+}
@@ -0,0 +1,35 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+)
+
+// Service manages the maintenance mode of the instance. While it is enabled the
+// instance is read-only: API requests that change data are rejected, e.g. during
+// database migrations and backups.
+type Service interface {
+	// Status returns the current maintenance mode, it does not access the database.
+	Status() Status
+	Enable(ctx context.Context, cmd *EnableCommand) (Status, error)
+	Disable(ctx context.Context) (Status, error)
+	// AlertingPaused returns true if alert rules are not evaluated during the maintenance.
+	AlertingPaused() bool
+}
+
+// Status is the maintenance mode of the instance.
+type Status struct {
+	Enabled bool `json:"enabled"`
+	// Message is returned to the requests that are rejected.
+	Message       string     `json:"message,omitempty"`
+	PauseAlerting bool       `json:"pauseAlerting"`
+	EnabledBy     string     `json:"enabledBy,omitempty"`
+	Since         *time.Time `json:"since,omitempty"`
+}
+
+type EnableCommand struct {
+	// Message overrides the configured maintenance message.
+	Message       string `json:"message"`
+	PauseAlerting bool   `json:"pauseAlerting"`
+	EnabledBy     string `json:"-"`
+}
@@ -0,0 +1,135 @@
+package maintenanceimpl
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "maintenance"
+	kvKey       = "status"
+
+	// refreshInterval is how often the status is reloaded, so that all instances
+	// of a highly available setup follow changes made through one of them.
+	refreshInterval = 10 * time.Second
+)
+
+type Service struct {
+	cfg *setting.Cfg
+	kv  *kvstore.NamespacedKVStore
+	log log.Logger
+	now func() time.Time
+
+	mu     sync.RWMutex
+	status maintenance.Status
+}
+
+func ProvideService(cfg *setting.Cfg, kv kvstore.KVStore) *Service {
+	s := &Service{
+		cfg: cfg,
+		kv:  kvstore.WithNamespace(kv, 0, kvNamespace),
+		log: log.New("maintenance"),
+		now: time.Now,
+	}
+	// Load the status before the HTTP server starts, so that the maintenance mode survives restarts
+	if err := s.refresh(context.Background()); err != nil {
+		s.log.Error("Failed to load maintenance mode", "error", err)
+	}
+	return s
+}
+
+// Run reloads the status periodically.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.refresh(ctx); err != nil {
+				s.log.Warn("Failed to reload maintenance mode", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) Status() maintenance.Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+func (s *Service) AlertingPaused() bool {
+	status := s.Status()
+	return status.Enabled && status.PauseAlerting
+}
+
+func (s *Service) Enable(ctx context.Context, cmd *maintenance.EnableCommand) (maintenance.Status, error) {
+	message := cmd.Message
+	if message == "" {
+		message = s.cfg.MaintenanceMessage
+	}
+	since := s.now()
+	status := maintenance.Status{
+		Enabled:       true,
+		Message:       message,
+		PauseAlerting: cmd.PauseAlerting,
+		EnabledBy:     cmd.EnabledBy,
+		Since:         &since,
+	}
+	if err := s.save(ctx, status); err != nil {
+		return maintenance.Status{}, err
+	}
+	s.log.Info("Maintenance mode enabled", "by", cmd.EnabledBy, "pauseAlerting", cmd.PauseAlerting)
+	return status, nil
+}
+
+func (s *Service) Disable(ctx context.Context) (maintenance.Status, error) {
+	if err := s.kv.Del(ctx, kvKey); err != nil {
+		return maintenance.Status{}, err
+	}
+	s.set(maintenance.Status{})
+	s.log.Info("Maintenance mode disabled")
+	return maintenance.Status{}, nil
+}
+
+func (s *Service) save(ctx context.Context, status maintenance.Status) error {
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, kvKey, string(encoded)); err != nil {
+		return err
+	}
+	s.set(status)
+	return nil
+}
+
+func (s *Service) refresh(ctx context.Context) error {
+	value, ok, err := s.kv.Get(ctx, kvKey)
+	if err != nil {
+		return err
+	}
+	status := maintenance.Status{}
+	if ok {
+		if err := json.Unmarshal([]byte(value), &status); err != nil {
+			return err
+		}
+	}
+	s.set(status)
+	return nil
+}
+
+func (s *Service) set(status maintenance.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
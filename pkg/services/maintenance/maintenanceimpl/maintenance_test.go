@@ -0,0 +1,66 @@
+package maintenanceimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/maintenance"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationMaintenanceMode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := kvstore.ProvideService(sqlstore.InitTestDB(t))
+	cfg := setting.NewCfg()
+	cfg.MaintenanceMessage = "Down for maintenance"
+	ctx := context.Background()
+
+	svc := ProvideService(cfg, kv)
+	require.False(t, svc.Status().Enabled)
+	require.False(t, svc.AlertingPaused())
+
+	t.Run("enable uses the configured message", func(t *testing.T) {
+		status, err := svc.Enable(ctx, &maintenance.EnableCommand{EnabledBy: "admin"})
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.Equal(t, "Down for maintenance", status.Message)
+		require.False(t, svc.AlertingPaused())
+	})
+
+	t.Run("status survives restarts", func(t *testing.T) {
+		_, err := svc.Enable(ctx, &maintenance.EnableCommand{Message: "Database upgrade", PauseAlerting: true, EnabledBy: "admin"})
+		require.NoError(t, err)
+
+		restarted := ProvideService(cfg, kv)
+		status := restarted.Status()
+		require.True(t, status.Enabled)
+		require.Equal(t, "Database upgrade", status.Message)
+		require.Equal(t, "admin", status.EnabledBy)
+		require.True(t, restarted.AlertingPaused())
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		_, err := svc.Disable(ctx)
+		require.NoError(t, err)
+		require.False(t, svc.Status().Enabled)
+
+		restarted := ProvideService(cfg, kv)
+		require.False(t, restarted.Status().Enabled)
+	})
+
+	t.Run("refresh picks up changes made by other instances", func(t *testing.T) {
+		other := ProvideService(cfg, kv)
+		_, err := other.Enable(ctx, &maintenance.EnableCommand{})
+		require.NoError(t, err)
+
+		require.False(t, svc.Status().Enabled)
+		require.NoError(t, svc.refresh(ctx))
+		require.True(t, svc.Status().Enabled)
+	})
+}
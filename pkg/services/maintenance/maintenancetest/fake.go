@@ -0,0 +1,32 @@
+package maintenancetest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/maintenance"
+)
+
+type FakeService struct {
+	ExpectedStatus maintenance.Status
+	ExpectedError  error
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Status() maintenance.Status {
+	return f.ExpectedStatus
+}
+
+func (f *FakeService) Enable(ctx context.Context, cmd *maintenance.EnableCommand) (maintenance.Status, error) {
+	return f.ExpectedStatus, f.ExpectedError
+}
+
+func (f *FakeService) Disable(ctx context.Context) (maintenance.Status, error) {
+	return maintenance.Status{}, f.ExpectedError
+}
+
+func (f *FakeService) AlertingPaused() bool {
+	return f.ExpectedStatus.Enabled && f.ExpectedStatus.PauseAlerting
+}
@@ -31,6 +31,7 @@ var timeNow = time.Now
 type Scheduler interface {
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	SchedulerStatus(orgID int64) apimodels.GettableSchedulerStatus
 }
 
 type Alertmanager interface {
@@ -81,6 +82,7 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	AlertRuleTemplates   *provisioning.AlertRuleTemplateService
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -142,5 +144,6 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		alertRuleTemplates:  api.AlertRuleTemplates,
 	}), m)
 }
@@ -38,6 +38,10 @@ func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Respons
 	})
 }
 
+func (srv AdminSrv) RouteGetSchedulerStatus(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, srv.scheduler.SchedulerStatus(c.OrgId))
+}
+
 func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
 	if c.OrgRole != models.ROLE_ADMIN {
 		return accessForbiddenResp()
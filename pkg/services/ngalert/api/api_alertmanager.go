@@ -130,6 +130,55 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 	return response.JSON(http.StatusOK, config)
 }
 
+func (srv AlertmanagerSrv) RouteGetAlertingConfigHistory(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit < 0 || limit > store.MaxAlertmanagerConfigurationHistory {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be between 0 and %d", store.MaxAlertmanagerConfigurationHistory), "")
+	}
+	history, err := srv.mam.GetAlertmanagerConfigurationHistory(c.Req.Context(), c.OrgId, limit)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, history)
+}
+
+func (srv AlertmanagerSrv) RoutePostAlertingConfigHistoryActivate(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse id")
+	}
+
+	err = srv.mam.ActivateHistoricalConfiguration(c.Req.Context(), c.OrgId, id, func(version apimodels.PostableUserConfig) error {
+		currentConfig, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.OrgId)
+		// As when posting a configuration, the guard is bypassed if there is no valid configuration.
+		if err != nil {
+			return nil
+		}
+		if err := srv.provenanceGuard(currentConfig, version); err != nil {
+			return notifier.AlertmanagerConfigRejectedError{Inner: err}
+		}
+		return nil
+	})
+	if err == nil {
+		return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration activated"})
+	}
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	var configRejectedError notifier.AlertmanagerConfigRejectedError
+	if errors.As(err, &configRejectedError) {
+		return ErrResp(http.StatusBadRequest, configRejectedError, "")
+	}
+	if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	}
+	if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *models.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
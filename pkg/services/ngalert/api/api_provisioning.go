@@ -21,6 +21,7 @@ const (
 	uidPathParam       = ":UID"
 	groupPathParam     = ":Group"
 	folderUIDPathParam = ":FolderUID"
+	ruleUIDPathParam   = ":RuleUID"
 )
 
 type ProvisioningSrv struct {
@@ -30,6 +31,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	alertRuleTemplates  AlertRuleTemplateService
 }
 
 type ContactPointService interface {
@@ -48,6 +50,7 @@ type TemplateService interface {
 type NotificationPolicyService interface {
 	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
 	UpdatePolicyTree(ctx context.Context, orgID int64, tree definitions.Route, p alerting_models.Provenance) error
+	SimulatePolicyTree(ctx context.Context, orgID int64, sim definitions.PolicySimulation) (definitions.PolicySimulationResult, error)
 }
 
 type MuteTimingService interface {
@@ -65,6 +68,18 @@ type AlertRuleService interface {
 	UpdateRuleGroup(ctx context.Context, orgID int64, folderUID, rulegroup string, interval int64) error
 }
 
+type AlertRuleTemplateService interface {
+	GetAlertRuleTemplates(ctx context.Context, orgID int64) ([]alerting_models.AlertRuleTemplate, error)
+	GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (alerting_models.AlertRuleTemplate, error)
+	CreateAlertRuleTemplate(ctx context.Context, tmpl alerting_models.AlertRuleTemplate) (alerting_models.AlertRuleTemplate, error)
+	UpdateAlertRuleTemplate(ctx context.Context, tmpl alerting_models.AlertRuleTemplate) (alerting_models.AlertRuleTemplate, error)
+	DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error
+	InstantiateAlertRuleTemplate(ctx context.Context, cmd alerting_models.InstantiateAlertRuleTemplateCmd) (alerting_models.AlertRuleTemplateInstance, error)
+	GetAlertRuleTemplateInstances(ctx context.Context, orgID int64, templateUID string) ([]alerting_models.AlertRuleTemplateInstance, error)
+	DiffAlertRuleTemplateInstance(ctx context.Context, orgID int64, templateUID, ruleUID string) (alerting_models.AlertRuleTemplateInstanceDiff, error)
+	ApplyAlertRuleTemplateInstance(ctx context.Context, orgID int64, templateUID, ruleUID string, version int64) (alerting_models.AlertRuleTemplateInstance, error)
+}
+
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
 	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgId)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -92,6 +107,21 @@ func (srv *ProvisioningSrv) RoutePutPolicyTree(c *models.ReqContext, tree defini
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
 }
 
+func (srv *ProvisioningSrv) RoutePostPolicyTreeSimulation(c *models.ReqContext, sim definitions.PolicySimulation) response.Response {
+	result, err := srv.policies.SimulatePolicyTree(c.Req.Context(), c.OrgId, sim)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if errors.Is(err, provisioning.ErrValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 func (srv *ProvisioningSrv) RouteGetContactPoints(c *models.ReqContext) response.Response {
 	cps, err := srv.contactPointService.GetContactPoints(c.Req.Context(), c.OrgId)
 	if err != nil {
@@ -309,6 +339,116 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, ag defi
 	return response.JSON(http.StatusOK, ag)
 }
 
+func (srv *ProvisioningSrv) RouteGetAlertRuleTemplates(c *models.ReqContext) response.Response {
+	templates, err := srv.alertRuleTemplates.GetAlertRuleTemplates(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(definitions.AlertRuleTemplates, 0, len(templates))
+	for _, tmpl := range templates {
+		result = append(result, definitions.NewAlertRuleTemplate(tmpl))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleTemplate(c *models.ReqContext) response.Response {
+	tmpl, err := srv.alertRuleTemplates.GetAlertRuleTemplate(c.Req.Context(), c.OrgId, pathParam(c, uidPathParam))
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusOK, definitions.NewAlertRuleTemplate(tmpl))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleTemplate(c *models.ReqContext, tmpl definitions.AlertRuleTemplate) response.Response {
+	created, err := srv.alertRuleTemplates.CreateAlertRuleTemplate(c.Req.Context(), tmpl.UpstreamModel(c.OrgId))
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusCreated, definitions.NewAlertRuleTemplate(created))
+}
+
+func (srv *ProvisioningSrv) RoutePutAlertRuleTemplate(c *models.ReqContext, tmpl definitions.AlertRuleTemplate) response.Response {
+	tmpl.UID = pathParam(c, uidPathParam)
+	updated, err := srv.alertRuleTemplates.UpdateAlertRuleTemplate(c.Req.Context(), tmpl.UpstreamModel(c.OrgId))
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusOK, definitions.NewAlertRuleTemplate(updated))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteAlertRuleTemplate(c *models.ReqContext) response.Response {
+	err := srv.alertRuleTemplates.DeleteAlertRuleTemplate(c.Req.Context(), c.OrgId, pathParam(c, uidPathParam))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleTemplateInstances(c *models.ReqContext) response.Response {
+	tmpl, err := srv.alertRuleTemplates.GetAlertRuleTemplate(c.Req.Context(), c.OrgId, pathParam(c, uidPathParam))
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	instances, err := srv.alertRuleTemplates.GetAlertRuleTemplateInstances(c.Req.Context(), c.OrgId, tmpl.UID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(definitions.AlertRuleTemplateInstances, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, definitions.NewAlertRuleTemplateInstance(instance, tmpl.Version))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleTemplateInstance(c *models.ReqContext, body definitions.AlertRuleTemplateInstantiation) response.Response {
+	instance, err := srv.alertRuleTemplates.InstantiateAlertRuleTemplate(c.Req.Context(), alerting_models.InstantiateAlertRuleTemplateCmd{
+		OrgID:        c.OrgId,
+		TemplateUID:  pathParam(c, uidPathParam),
+		RuleUID:      body.RuleUID,
+		Title:        body.Title,
+		NamespaceUID: body.FolderUID,
+		RuleGroup:    body.RuleGroup,
+		Parameters:   body.Parameters,
+		Labels:       body.Labels,
+	})
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusCreated, definitions.NewAlertRuleTemplateInstance(instance, instance.TemplateVersion))
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleTemplateInstanceDiff(c *models.ReqContext) response.Response {
+	diff, err := srv.alertRuleTemplates.DiffAlertRuleTemplateInstance(c.Req.Context(), c.OrgId, pathParam(c, uidPathParam), pathParam(c, ruleUIDPathParam))
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusOK, definitions.NewAlertRuleTemplateInstanceDiff(diff))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleTemplateInstanceApproval(c *models.ReqContext, body definitions.AlertRuleTemplateInstanceApproval) response.Response {
+	instance, err := srv.alertRuleTemplates.ApplyAlertRuleTemplateInstance(c.Req.Context(), c.OrgId, pathParam(c, uidPathParam), pathParam(c, ruleUIDPathParam), body.TemplateVersion)
+	if err != nil {
+		return alertRuleTemplateErrResp(err)
+	}
+	return response.JSON(http.StatusOK, definitions.NewAlertRuleTemplateInstance(instance, instance.TemplateVersion))
+}
+
+func alertRuleTemplateErrResp(err error) response.Response {
+	switch {
+	case errors.Is(err, alerting_models.ErrAlertRuleTemplateNotFound),
+		errors.Is(err, alerting_models.ErrAlertRuleTemplateInstanceNotFound),
+		errors.Is(err, alerting_models.ErrAlertRuleNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, alerting_models.ErrAlertRuleTemplateFailedValidation),
+		errors.Is(err, alerting_models.ErrAlertRuleFailedValidation):
+		return ErrResp(http.StatusBadRequest, err, "")
+	case errors.Is(err, alerting_models.ErrAlertRuleTemplateVersionMismatch),
+		errors.Is(err, store.ErrOptimisticLock):
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
 func pathParam(c *models.ReqContext, param string) string {
 	return web.Params(c.Req)[param]
 }
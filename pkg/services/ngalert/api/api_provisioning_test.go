@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/web"
 	prometheus "github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -109,6 +110,38 @@ func TestProvisioningApi(t *testing.T) {
 		})
 	})
 
+	t.Run("policy simulation", func(t *testing.T) {
+		t.Run("successful POST returns 200", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePostPolicyTreeSimulation(&rc, definitions.PolicySimulation{Labels: map[string]string{"a": "b"}})
+
+			require.Equal(t, 200, response.Status())
+			require.JSONEq(t, `{"matches":[{"path":[],"matchers":["{}"],"receiver":"some-receiver","integrations":[],"group_by":[],"group_labels":{},"group_wait":"30s","group_interval":"5m","repeat_interval":"4h","mute_time_intervals":[]}]}`, string(response.Body()))
+		})
+
+		t.Run("POST with invalid policy tree returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			sut.policies = &fakeRejectingNotificationPolicyService{}
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePostPolicyTreeSimulation(&rc, definitions.PolicySimulation{})
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("POST when org has no AM config returns 404", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.SignedInUser.OrgId = 2
+
+			response := sut.RoutePostPolicyTreeSimulation(&rc, definitions.PolicySimulation{})
+
+			require.Equal(t, 404, response.Status())
+		})
+	})
+
 	t.Run("contact points", func(t *testing.T) {
 		t.Run("are invalid", func(t *testing.T) {
 			t.Run("POST returns 400", func(t *testing.T) {
@@ -232,6 +265,89 @@ func TestProvisioningApi(t *testing.T) {
 			require.Equal(t, 404, response.Status())
 		})
 	})
+
+	t.Run("alert rule templates", func(t *testing.T) {
+		t.Run("are invalid, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			tmpl := createTestAlertRuleTemplate("template")
+			tmpl.Condition = "B"
+
+			response := sut.RoutePostAlertRuleTemplate(&rc, tmpl)
+
+			require.Equal(t, 400, response.Status())
+			require.Contains(t, string(response.Body()), "invalid alert rule template")
+		})
+
+		t.Run("are missing", func(t *testing.T) {
+			t.Run("GET returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				withURLParams(rc, uidPathParam, "missing")
+
+				response := sut.RouteGetAlertRuleTemplate(&rc)
+
+				require.Equal(t, 404, response.Status())
+			})
+
+			t.Run("PUT returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				withURLParams(rc, uidPathParam, "missing")
+
+				response := sut.RoutePutAlertRuleTemplate(&rc, createTestAlertRuleTemplate("template"))
+
+				require.Equal(t, 404, response.Status())
+			})
+
+			t.Run("POST instance returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				withURLParams(rc, uidPathParam, "missing")
+
+				response := sut.RoutePostAlertRuleTemplateInstance(&rc, createTestAlertRuleTemplateInstantiation())
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("instances without a required parameter, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			tmpl := insertAlertRuleTemplate(t, sut, createTestAlertRuleTemplate("template"))
+			withURLParams(rc, uidPathParam, tmpl.UID)
+			body := createTestAlertRuleTemplateInstantiation()
+			body.Parameters = nil
+
+			response := sut.RoutePostAlertRuleTemplateInstance(&rc, body)
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("approval of an outdated diff returns 409", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			tmpl := insertAlertRuleTemplate(t, sut, createTestAlertRuleTemplate("template"))
+			withURLParams(rc, uidPathParam, tmpl.UID)
+			response := sut.RoutePostAlertRuleTemplateInstance(&rc, createTestAlertRuleTemplateInstantiation())
+			require.Equal(t, 201, response.Status())
+			var instance definitions.AlertRuleTemplateInstance
+			require.NoError(t, json.Unmarshal(response.Body(), &instance))
+
+			tmpl.For = 5 * time.Minute
+			response = sut.RoutePutAlertRuleTemplate(&rc, tmpl)
+			require.Equal(t, 200, response.Status())
+
+			withURLParams(rc, ruleUIDPathParam, instance.RuleUID)
+			response = sut.RoutePostAlertRuleTemplateInstanceApproval(&rc, definitions.AlertRuleTemplateInstanceApproval{TemplateVersion: 1})
+
+			require.Equal(t, 409, response.Status())
+
+			response = sut.RoutePostAlertRuleTemplateInstanceApproval(&rc, definitions.AlertRuleTemplateInstanceApproval{TemplateVersion: 2})
+
+			require.Equal(t, 200, response.Status())
+		})
+	})
 }
 
 func createProvisioningSrvSut(t *testing.T) ProvisioningSrv {
@@ -252,6 +368,7 @@ func createProvisioningSrvSut(t *testing.T) ProvisioningSrv {
 	prov := &provisioning.MockProvisioningStore{}
 	prov.EXPECT().SaveSucceeds()
 	prov.EXPECT().GetReturns(models.ProvenanceNone)
+	alertRules := provisioning.NewAlertRuleService(store, prov, xact, 60, 10, log)
 
 	return ProvisioningSrv{
 		log:                 log,
@@ -259,7 +376,8 @@ func createProvisioningSrvSut(t *testing.T) ProvisioningSrv {
 		contactPointService: provisioning.NewContactPointService(configs, secrets, prov, xact, log),
 		templates:           provisioning.NewTemplateService(configs, prov, xact, log),
 		muteTimings:         provisioning.NewMuteTimingService(configs, prov, xact, log),
-		alertRules:          provisioning.NewAlertRuleService(store, prov, xact, 60, 10, log),
+		alertRules:          alertRules,
+		alertRuleTemplates:  provisioning.NewAlertRuleTemplateService(store, alertRules, xact, log),
 	}
 }
 
@@ -312,6 +430,24 @@ func (f *fakeNotificationPolicyService) UpdatePolicyTree(ctx context.Context, or
 	return nil
 }
 
+func (f *fakeNotificationPolicyService) SimulatePolicyTree(ctx context.Context, orgID int64, sim definitions.PolicySimulation) (definitions.PolicySimulationResult, error) {
+	if orgID != 1 {
+		return definitions.PolicySimulationResult{}, store.ErrNoAlertmanagerConfiguration
+	}
+	return definitions.PolicySimulationResult{Matches: []definitions.PolicySimulationMatch{{
+		Path:              []int{},
+		Matchers:          []string{"{}"},
+		Receiver:          f.tree.Receiver,
+		Integrations:      []definitions.PolicySimulationIntegration{},
+		GroupBy:           []string{},
+		GroupLabels:       map[string]string{},
+		GroupWait:         model.Duration(30 * time.Second),
+		GroupInterval:     model.Duration(5 * time.Minute),
+		RepeatInterval:    model.Duration(4 * time.Hour),
+		MuteTimeIntervals: []string{},
+	}}}, nil
+}
+
 type fakeFailingNotificationPolicyService struct{}
 
 func (f *fakeFailingNotificationPolicyService) GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error) {
@@ -322,6 +458,10 @@ func (f *fakeFailingNotificationPolicyService) UpdatePolicyTree(ctx context.Cont
 	return fmt.Errorf("something went wrong")
 }
 
+func (f *fakeFailingNotificationPolicyService) SimulatePolicyTree(ctx context.Context, orgID int64, sim definitions.PolicySimulation) (definitions.PolicySimulationResult, error) {
+	return definitions.PolicySimulationResult{}, fmt.Errorf("something went wrong")
+}
+
 type fakeRejectingNotificationPolicyService struct{}
 
 func (f *fakeRejectingNotificationPolicyService) GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error) {
@@ -332,6 +472,10 @@ func (f *fakeRejectingNotificationPolicyService) UpdatePolicyTree(ctx context.Co
 	return fmt.Errorf("%w: invalid policy tree", provisioning.ErrValidation)
 }
 
+func (f *fakeRejectingNotificationPolicyService) SimulatePolicyTree(ctx context.Context, orgID int64, sim definitions.PolicySimulation) (definitions.PolicySimulationResult, error) {
+	return definitions.PolicySimulationResult{}, fmt.Errorf("%w: invalid policy tree", provisioning.ErrValidation)
+}
+
 func createInvalidContactPoint() definitions.EmbeddedContactPoint {
 	settings, _ := simplejson.NewJson([]byte(`{}`))
 	return definitions.EmbeddedContactPoint{
@@ -387,6 +531,47 @@ func createTestAlertRule(title string, orgID int64) definitions.AlertRule {
 	}
 }
 
+func createTestAlertRuleTemplate(title string) definitions.AlertRuleTemplate {
+	return definitions.AlertRuleTemplate{
+		Title:     title,
+		Condition: "A",
+		Data: []models.AlertQuery{
+			{
+				RefID: "A",
+				Model: json.RawMessage(`{"service":"${service}"}`),
+				RelativeTimeRange: models.RelativeTimeRange{
+					From: models.Duration(time.Second * 60),
+					To:   models.Duration(0),
+				},
+			},
+		},
+		For:          time.Second * 60,
+		NoDataState:  models.OK,
+		ExecErrState: models.OkErrState,
+		Parameters:   []models.AlertRuleTemplateParameter{{Name: "service"}},
+	}
+}
+
+func createTestAlertRuleTemplateInstantiation() definitions.AlertRuleTemplateInstantiation {
+	return definitions.AlertRuleTemplateInstantiation{
+		Title:      "rule",
+		FolderUID:  "folder",
+		RuleGroup:  "my-cool-group",
+		Parameters: map[string]interface{}{"service": "checkout"},
+	}
+}
+
+func insertAlertRuleTemplate(t *testing.T, srv ProvisioningSrv, tmpl definitions.AlertRuleTemplate) definitions.AlertRuleTemplate {
+	t.Helper()
+
+	rc := createTestRequestCtx()
+	resp := srv.RoutePostAlertRuleTemplate(&rc, tmpl)
+	require.Equal(t, 201, resp.Status())
+	var created definitions.AlertRuleTemplate
+	require.NoError(t, json.Unmarshal(resp.Body(), &created))
+	return created
+}
+
 func insertRule(t *testing.T, srv ProvisioningSrv, rule definitions.AlertRule) {
 	t.Helper()
 
@@ -6,9 +6,12 @@ import (
 	"strconv"
 	"time"
 
+	prommodel "github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -86,9 +89,21 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	record := ruleNode.GrafanaManagedAlert.Record
+	if record != nil {
+		if err := validateRecord(record, ruleNode.GrafanaManagedAlert.Data, cfg); err != nil {
+			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+		// a recording rule has no condition, its query or expression is evaluated instead
+		if condition == "" {
+			condition = record.From
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			OrgID:     orgId,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
@@ -100,7 +115,7 @@ func validateRuleNode(
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
+		Condition:       condition,
 		Data:            ruleNode.GrafanaManagedAlert.Data,
 		UID:             ruleNode.GrafanaManagedAlert.UID,
 		IntervalSeconds: intervalSeconds,
@@ -108,6 +123,7 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
 	}
 
 	if ruleNode.ApiRuleNode != nil {
@@ -135,6 +151,30 @@ func validateRuleNode(
 	return &newAlertRule, nil
 }
 
+// validateRecord validates the record of a recording rule: recording rules
+// must be enabled, the metric must be a valid Prometheus metric name, and it must
+// record one of the queries or expressions of the rule.
+func validateRecord(record *ngmodels.Record, data []ngmodels.AlertQuery, cfg *setting.UnifiedAlertingSettings) error {
+	if !cfg.RecordingRules.Enabled {
+		return errors.New("recording rules are disabled, enable them in the unified_alerting.recording_rules section of the configuration")
+	}
+	if !prommodel.IsValidMetricName(prommodel.LabelValue(record.Metric)) {
+		return fmt.Errorf("invalid metric name %q of recording rule", record.Metric)
+	}
+	if len(record.Metric) > recording.MaxMetricLength {
+		return fmt.Errorf("metric name of recording rule is too long. Max length is %d", recording.MaxMetricLength)
+	}
+	if len(data) == 0 {
+		return errors.New("recording rule must specify its queries and expressions")
+	}
+	for _, q := range data {
+		if q.RefID == record.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("recording rule records query or expression %q that does not exist", record.From)
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 func validateRuleGroup(
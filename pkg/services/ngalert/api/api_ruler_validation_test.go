@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	models2 "github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -714,3 +716,68 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNodeRecord(t *testing.T) {
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+	successValidation := func(condition models.Condition) error {
+		return nil
+	}
+
+	t.Run("recording rule records its query", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &models.Record{Metric: "job:requests:rate5m", From: "A"}
+
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), successValidation, cfg)
+		require.NoError(t, err)
+		require.True(t, alert.IsRecording())
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, r.GrafanaManagedAlert.Record, alert.Record)
+	})
+
+	testCases := []struct {
+		name   string
+		record *models.Record
+		cfg    func(cfg setting.UnifiedAlertingSettings) *setting.UnifiedAlertingSettings
+	}{
+		{
+			name:   "fail if recording rules are disabled",
+			record: &models.Record{Metric: "job:requests:rate5m", From: "A"},
+			cfg: func(cfg setting.UnifiedAlertingSettings) *setting.UnifiedAlertingSettings {
+				cfg.RecordingRules.Enabled = false
+				return &cfg
+			},
+		},
+		{
+			name:   "fail if metric is invalid",
+			record: &models.Record{Metric: "job requests", From: "A"},
+		},
+		{
+			name:   "fail if metric is empty",
+			record: &models.Record{From: "A"},
+		},
+		{
+			name:   "fail if metric is too long",
+			record: &models.Record{Metric: strings.Repeat("a", recording.MaxMetricLength+1), From: "A"},
+		},
+		{
+			name:   "fail if the query does not exist",
+			record: &models.Record{Metric: "job:requests:rate5m", From: "B"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.Record = testCase.record
+			c := cfg
+			if testCase.cfg != nil {
+				c = testCase.cfg(*cfg)
+			}
+
+			_, err := validateRuleNode(&r, util.GenerateShortUID(), c.BaseInterval, rand.Int63(), randFolder(), successValidation, c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		})
+	}
+}
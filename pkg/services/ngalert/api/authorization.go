@@ -150,11 +150,16 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/alerts":
 		fallback = middleware.ReqEditorRole
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodGet + "/api/alertmanager/grafana/config/history":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodGet + "/api/alertmanager/grafana/api/v2/status":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/alerts":
 		// additional authorization is done in the request handler
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingNotificationsWrite))
+	case http.MethodPost + "/api/alertmanager/grafana/config/history/{id}/_activate":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/receivers/test":
 		fallback = middleware.ReqEditorRole
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
@@ -175,7 +180,8 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodDelete + "/api/v1/ngalert/admin_config",
 		http.MethodGet + "/api/v1/ngalert/admin_config",
 		http.MethodPost + "/api/v1/ngalert/admin_config",
-		http.MethodGet + "/api/v1/ngalert/alertmanagers":
+		http.MethodGet + "/api/v1/ngalert/alertmanagers",
+		http.MethodGet + "/api/v1/ngalert/scheduler/status":
 		return middleware.ReqOrgAdmin
 
 	// Grafana-only Provisioning Read Paths
@@ -185,7 +191,12 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodGet + "/api/v1/provisioning/templates/{name}",
 		http.MethodGet + "/api/v1/provisioning/mute-timings",
 		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}",
-		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}":
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
+		http.MethodGet + "/api/v1/provisioning/alert-rule-templates",
+		http.MethodGet + "/api/v1/provisioning/alert-rule-templates/{UID}",
+		http.MethodGet + "/api/v1/provisioning/alert-rule-templates/{UID}/instances",
+		http.MethodGet + "/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/diff",
+		http.MethodPost + "/api/v1/provisioning/policies/simulate":
 		fallback = middleware.ReqOrgAdmin
 		eval = ac.EvalPermission(ac.ActionAlertingProvisioningRead) // organization scope
 
@@ -201,7 +212,12 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodPost + "/api/v1/provisioning/alert-rules",
 		http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPost + "/api/v1/provisioning/alert-rule-templates",
+		http.MethodPut + "/api/v1/provisioning/alert-rule-templates/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/alert-rule-templates/{UID}",
+		http.MethodPost + "/api/v1/provisioning/alert-rule-templates/{UID}/instances",
+		http.MethodPost + "/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/approve":
 		fallback = middleware.ReqOrgAdmin
 		eval = ac.EvalPermission(ac.ActionAlertingProvisioningWrite) // organization scope
 	}
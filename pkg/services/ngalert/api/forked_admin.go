@@ -26,6 +26,10 @@ func (f *ForkedConfigurationApi) forkRouteGetNGalertConfig(c *models.ReqContext)
 	return f.grafana.RouteGetNGalertConfig(c)
 }
 
+func (f *ForkedConfigurationApi) forkRouteGetSchedulerStatus(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetSchedulerStatus(c)
+}
+
 func (f *ForkedConfigurationApi) forkRoutePostNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
 	return f.grafana.RoutePostNGalertConfig(c, body)
 }
@@ -183,6 +183,14 @@ func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaAlertingConfig(ctx *models.Re
 	return f.GrafanaSvc.RouteGetAlertingConfig(ctx)
 }
 
+func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaAlertingConfigHistory(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertingConfigHistory(ctx)
+}
+
+func (f *ForkedAlertmanagerApi) forkRoutePostGrafanaAlertingConfigHistoryActivate(ctx *models.ReqContext) response.Response {
+	return f.GrafanaSvc.RoutePostAlertingConfigHistoryActivate(ctx)
+}
+
 func (f *ForkedAlertmanagerApi) forkRouteGetGrafanaSilence(ctx *models.ReqContext) response.Response {
 	return f.GrafanaSvc.RouteGetSilence(ctx)
 }
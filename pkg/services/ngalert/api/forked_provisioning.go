@@ -27,6 +27,10 @@ func (f *ForkedProvisioningApi) forkRoutePutPolicyTree(ctx *models.ReqContext, r
 	return f.svc.RoutePutPolicyTree(ctx, route)
 }
 
+func (f *ForkedProvisioningApi) forkRoutePostPolicyTreeSimulation(ctx *models.ReqContext, sim apimodels.PolicySimulation) response.Response {
+	return f.svc.RoutePostPolicyTreeSimulation(ctx, sim)
+}
+
 func (f *ForkedProvisioningApi) forkRouteGetContactpoints(ctx *models.ReqContext) response.Response {
 	return f.svc.RouteGetContactPoints(ctx)
 }
@@ -98,3 +102,39 @@ func (f *ForkedProvisioningApi) forkRouteDeleteAlertRule(ctx *models.ReqContext)
 func (f *ForkedProvisioningApi) forkRoutePutAlertRuleGroup(ctx *models.ReqContext, ag apimodels.AlertRuleGroup) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag)
 }
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleTemplates(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleTemplates(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleTemplate(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleTemplate(ctx *models.ReqContext, tmpl apimodels.AlertRuleTemplate) response.Response {
+	return f.svc.RoutePostAlertRuleTemplate(ctx, tmpl)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutAlertRuleTemplate(ctx *models.ReqContext, tmpl apimodels.AlertRuleTemplate) response.Response {
+	return f.svc.RoutePutAlertRuleTemplate(ctx, tmpl)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteAlertRuleTemplate(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleTemplateInstances(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleTemplateInstances(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleTemplateInstance(ctx *models.ReqContext, body apimodels.AlertRuleTemplateInstantiation) response.Response {
+	return f.svc.RoutePostAlertRuleTemplateInstance(ctx, body)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleTemplateInstanceDiff(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleTemplateInstanceDiff(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleTemplateInstanceApproval(ctx *models.ReqContext, body apimodels.AlertRuleTemplateInstanceApproval) response.Response {
+	return f.svc.RoutePostAlertRuleTemplateInstanceApproval(ctx, body)
+}
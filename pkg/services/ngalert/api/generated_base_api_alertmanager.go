@@ -33,6 +33,7 @@ type AlertmanagerApiForkingService interface {
 	RouteGetGrafanaAMAlerts(*models.ReqContext) response.Response
 	RouteGetGrafanaAMStatus(*models.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfig(*models.ReqContext) response.Response
+	RouteGetGrafanaAlertingConfigHistory(*models.ReqContext) response.Response
 	RouteGetGrafanaSilence(*models.ReqContext) response.Response
 	RouteGetGrafanaSilences(*models.ReqContext) response.Response
 	RouteGetSilence(*models.ReqContext) response.Response
@@ -41,6 +42,7 @@ type AlertmanagerApiForkingService interface {
 	RoutePostAlertingConfig(*models.ReqContext) response.Response
 	RoutePostGrafanaAMAlerts(*models.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*models.ReqContext) response.Response
+	RoutePostGrafanaAlertingConfigHistoryActivate(*models.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*models.ReqContext) response.Response
 	RoutePostTestReceivers(*models.ReqContext) response.Response
 }
@@ -95,6 +97,9 @@ func (f *ForkedAlertmanagerApi) RouteGetGrafanaAMStatus(ctx *models.ReqContext)
 func (f *ForkedAlertmanagerApi) RouteGetGrafanaAlertingConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetGrafanaAlertingConfig(ctx)
 }
+func (f *ForkedAlertmanagerApi) RouteGetGrafanaAlertingConfigHistory(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetGrafanaAlertingConfigHistory(ctx)
+}
 func (f *ForkedAlertmanagerApi) RouteGetGrafanaSilence(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetGrafanaSilence(ctx)
 }
@@ -135,6 +140,9 @@ func (f *ForkedAlertmanagerApi) RoutePostGrafanaAlertingConfig(ctx *models.ReqCo
 	}
 	return f.forkRoutePostGrafanaAlertingConfig(ctx, conf)
 }
+func (f *ForkedAlertmanagerApi) RoutePostGrafanaAlertingConfigHistoryActivate(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostGrafanaAlertingConfigHistoryActivate(ctx)
+}
 func (f *ForkedAlertmanagerApi) RoutePostTestGrafanaReceivers(ctx *models.ReqContext) response.Response {
 	conf := apimodels.TestReceiversConfigBodyParams{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -292,6 +300,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiForkingServi
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history",
+				srv.RouteGetGrafanaAlertingConfigHistory,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silence/{SilenceId}"),
 			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/silence/{SilenceId}"),
@@ -372,6 +390,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiForkingServi
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/history/{id}/_activate"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/history/{id}/_activate"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/history/{id}/_activate",
+				srv.RoutePostGrafanaAlertingConfigHistoryActivate,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/test"),
 			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/receivers/test"),
@@ -22,6 +22,7 @@ type ConfigurationApiForkingService interface {
 	RouteDeleteNGalertConfig(*models.ReqContext) response.Response
 	RouteGetAlertmanagers(*models.ReqContext) response.Response
 	RouteGetNGalertConfig(*models.ReqContext) response.Response
+	RouteGetSchedulerStatus(*models.ReqContext) response.Response
 	RoutePostNGalertConfig(*models.ReqContext) response.Response
 }
 
@@ -34,6 +35,9 @@ func (f *ForkedConfigurationApi) RouteGetAlertmanagers(ctx *models.ReqContext) r
 func (f *ForkedConfigurationApi) RouteGetNGalertConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetNGalertConfig(ctx)
 }
+func (f *ForkedConfigurationApi) RouteGetSchedulerStatus(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetSchedulerStatus(ctx)
+}
 func (f *ForkedConfigurationApi) RoutePostNGalertConfig(ctx *models.ReqContext) response.Response {
 	conf := apimodels.PostableNGalertConfig{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -74,6 +78,16 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingSer
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/scheduler/status"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/scheduler/status"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/scheduler/status",
+				srv.RouteGetSchedulerStatus,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config"),
@@ -20,10 +20,15 @@ import (
 
 type ProvisioningApiForkingService interface {
 	RouteDeleteAlertRule(*models.ReqContext) response.Response
+	RouteDeleteAlertRuleTemplate(*models.ReqContext) response.Response
 	RouteDeleteContactpoints(*models.ReqContext) response.Response
 	RouteDeleteMuteTiming(*models.ReqContext) response.Response
 	RouteDeleteTemplate(*models.ReqContext) response.Response
 	RouteGetAlertRule(*models.ReqContext) response.Response
+	RouteGetAlertRuleTemplate(*models.ReqContext) response.Response
+	RouteGetAlertRuleTemplateInstanceDiff(*models.ReqContext) response.Response
+	RouteGetAlertRuleTemplateInstances(*models.ReqContext) response.Response
+	RouteGetAlertRuleTemplates(*models.ReqContext) response.Response
 	RouteGetContactpoints(*models.ReqContext) response.Response
 	RouteGetMuteTiming(*models.ReqContext) response.Response
 	RouteGetMuteTimings(*models.ReqContext) response.Response
@@ -31,10 +36,15 @@ type ProvisioningApiForkingService interface {
 	RouteGetTemplate(*models.ReqContext) response.Response
 	RouteGetTemplates(*models.ReqContext) response.Response
 	RoutePostAlertRule(*models.ReqContext) response.Response
+	RoutePostAlertRuleTemplate(*models.ReqContext) response.Response
+	RoutePostAlertRuleTemplateInstance(*models.ReqContext) response.Response
+	RoutePostAlertRuleTemplateInstanceApproval(*models.ReqContext) response.Response
 	RoutePostContactpoints(*models.ReqContext) response.Response
 	RoutePostMuteTiming(*models.ReqContext) response.Response
+	RoutePostPolicyTreeSimulation(*models.ReqContext) response.Response
 	RoutePutAlertRule(*models.ReqContext) response.Response
 	RoutePutAlertRuleGroup(*models.ReqContext) response.Response
+	RoutePutAlertRuleTemplate(*models.ReqContext) response.Response
 	RoutePutContactpoint(*models.ReqContext) response.Response
 	RoutePutMuteTiming(*models.ReqContext) response.Response
 	RoutePutPolicyTree(*models.ReqContext) response.Response
@@ -44,6 +54,9 @@ type ProvisioningApiForkingService interface {
 func (f *ForkedProvisioningApi) RouteDeleteAlertRule(ctx *models.ReqContext) response.Response {
 	return f.forkRouteDeleteAlertRule(ctx)
 }
+func (f *ForkedProvisioningApi) RouteDeleteAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	return f.forkRouteDeleteAlertRuleTemplate(ctx)
+}
 func (f *ForkedProvisioningApi) RouteDeleteContactpoints(ctx *models.ReqContext) response.Response {
 	return f.forkRouteDeleteContactpoints(ctx)
 }
@@ -56,6 +69,18 @@ func (f *ForkedProvisioningApi) RouteDeleteTemplate(ctx *models.ReqContext) resp
 func (f *ForkedProvisioningApi) RouteGetAlertRule(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetAlertRule(ctx)
 }
+func (f *ForkedProvisioningApi) RouteGetAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleTemplate(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetAlertRuleTemplateInstanceDiff(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleTemplateInstanceDiff(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetAlertRuleTemplateInstances(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleTemplateInstances(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetAlertRuleTemplates(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleTemplates(ctx)
+}
 func (f *ForkedProvisioningApi) RouteGetContactpoints(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetContactpoints(ctx)
 }
@@ -81,6 +106,27 @@ func (f *ForkedProvisioningApi) RoutePostAlertRule(ctx *models.ReqContext) respo
 	}
 	return f.forkRoutePostAlertRule(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePostAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	conf := apimodels.AlertRuleTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostAlertRuleTemplate(ctx, conf)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRuleTemplateInstance(ctx *models.ReqContext) response.Response {
+	conf := apimodels.AlertRuleTemplateInstantiation{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostAlertRuleTemplateInstance(ctx, conf)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRuleTemplateInstanceApproval(ctx *models.ReqContext) response.Response {
+	conf := apimodels.AlertRuleTemplateInstanceApproval{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostAlertRuleTemplateInstanceApproval(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePostContactpoints(ctx *models.ReqContext) response.Response {
 	conf := apimodels.EmbeddedContactPoint{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -95,6 +141,13 @@ func (f *ForkedProvisioningApi) RoutePostMuteTiming(ctx *models.ReqContext) resp
 	}
 	return f.forkRoutePostMuteTiming(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePostPolicyTreeSimulation(ctx *models.ReqContext) response.Response {
+	conf := apimodels.PolicySimulation{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostPolicyTreeSimulation(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePutAlertRule(ctx *models.ReqContext) response.Response {
 	conf := apimodels.AlertRule{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -109,6 +162,13 @@ func (f *ForkedProvisioningApi) RoutePutAlertRuleGroup(ctx *models.ReqContext) r
 	}
 	return f.forkRoutePutAlertRuleGroup(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePutAlertRuleTemplate(ctx *models.ReqContext) response.Response {
+	conf := apimodels.AlertRuleTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePutAlertRuleTemplate(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePutContactpoint(ctx *models.ReqContext) response.Response {
 	conf := apimodels.EmbeddedContactPoint{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -150,6 +210,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/alert-rule-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/alert-rule-templates/{UID}",
+				srv.RouteDeleteAlertRuleTemplate,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/provisioning/contact-points/{UID}"),
 			api.authorize(http.MethodDelete, "/api/v1/provisioning/contact-points/{UID}"),
@@ -190,6 +260,46 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rule-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rule-templates/{UID}",
+				srv.RouteGetAlertRuleTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/diff"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/diff",
+				srv.RouteGetAlertRuleTemplateInstanceDiff,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}/instances"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rule-templates/{UID}/instances"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rule-templates/{UID}/instances",
+				srv.RouteGetAlertRuleTemplateInstances,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rule-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rule-templates",
+				srv.RouteGetAlertRuleTemplates,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points"),
@@ -260,6 +370,36 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rule-templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rule-templates",
+				srv.RoutePostAlertRuleTemplate,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}/instances"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rule-templates/{UID}/instances"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rule-templates/{UID}/instances",
+				srv.RoutePostAlertRuleTemplateInstance,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/approve"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/approve"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/approve",
+				srv.RoutePostAlertRuleTemplateInstanceApproval,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			api.authorize(http.MethodPost, "/api/v1/provisioning/contact-points"),
@@ -280,6 +420,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/policies/simulate"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/policies/simulate"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/policies/simulate",
+				srv.RoutePostPolicyTreeSimulation,
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
 			api.authorize(http.MethodPut, "/api/v1/provisioning/alert-rules/{UID}"),
@@ -300,6 +450,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/alert-rule-templates/{UID}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/alert-rule-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/alert-rule-templates/{UID}",
+				srv.RoutePutAlertRuleTemplate,
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/contact-points/{UID}"),
 			api.authorize(http.MethodPut, "/api/v1/provisioning/contact-points/{UID}"),
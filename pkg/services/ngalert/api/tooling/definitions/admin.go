@@ -1,6 +1,8 @@
 package definitions
 
 import (
+	"time"
+
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 )
 
@@ -14,6 +16,16 @@ import (
 //     Responses:
 //		 200: GettableAlertmanagers
 
+// swagger:route GET /api/v1/ngalert/scheduler/status configuration RouteGetSchedulerStatus
+//
+//  Get the evaluation lag, missed ticks and evaluation durations of the alert rule groups of the user's organization, and the evaluations that are stuck.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableSchedulerStatus
+
 // swagger:route GET /api/v1/ngalert/admin_config configuration RouteGetNGalertConfig
 //
 //  Get the NGalert configuration of the user's organization, returns 404 if no configuration is present.
@@ -80,3 +92,50 @@ type GettableAlertmanagers struct {
 	Status string                 `json:"status"`
 	Data   v1.AlertManagersResult `json:"data"`
 }
+
+// swagger:model
+type GettableSchedulerStatus struct {
+	// The rule groups of the organization, the groups with the slowest evaluations first.
+	RuleGroups []SchedulerRuleGroupStatus `json:"ruleGroups"`
+	// The evaluations that have been running for longer than the evaluation interval of their rule.
+	StuckEvaluations []SchedulerEvaluation `json:"stuckEvaluations"`
+}
+
+// swagger:model
+type SchedulerRuleGroupStatus struct {
+	NamespaceUID string `json:"namespaceUID"`
+	RuleGroup    string `json:"ruleGroup"`
+	Rules        int    `json:"rules"`
+	// The number of evaluations since the scheduler started.
+	Evaluations    int64     `json:"evaluations"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	// The time between the tick and the start of the last evaluation of a rule of the group.
+	LagSeconds float64 `json:"lagSeconds"`
+	// The longest lag since the scheduler started.
+	MaxLagSeconds float64 `json:"maxLagSeconds"`
+	// The number of ticks dropped because the previous evaluation of a rule of the group was still running.
+	MissedTicks int64 `json:"missedTicks"`
+	// The percentiles of the durations of the recent evaluations.
+	DurationSeconds SchedulerDurationPercentiles `json:"durationSeconds"`
+	// The number of evaluations of the rules of the group that are running.
+	Running int `json:"running"`
+}
+
+// swagger:model
+type SchedulerDurationPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// swagger:model
+type SchedulerEvaluation struct {
+	RuleUID         string    `json:"ruleUID"`
+	Title           string    `json:"title"`
+	NamespaceUID    string    `json:"namespaceUID"`
+	RuleGroup       string    `json:"ruleGroup"`
+	IntervalSeconds int64     `json:"intervalSeconds"`
+	ScheduledAt     time.Time `json:"scheduledAt"`
+	StartedAt       time.Time `json:"startedAt"`
+	RunningSeconds  float64   `json:"runningSeconds"`
+}
@@ -63,6 +63,23 @@ import (
 //       200: Ack
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/grafana/config/history alertmanager RouteGetGrafanaAlertingConfigHistory
+//
+// gets the previous versions of the Alerting config, the most recent first
+//
+//     Responses:
+//       200: GettableHistoricUserConfigs
+//       400: ValidationError
+
+// swagger:route POST /api/alertmanager/grafana/config/history/{id}/_activate alertmanager RoutePostGrafanaAlertingConfigHistoryActivate
+//
+// rolls back the Alerting config to a previous version, saved as a new version
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route DELETE /api/alertmanager/{DatasourceUID}/config/api/v1/alerts alertmanager RouteDeleteAlertingConfig
 //
 // deletes the Alerting config for a tenant
@@ -439,6 +456,21 @@ type BodyAlertingConfig struct {
 	Body PostableUserConfig
 }
 
+// swagger:parameters RouteGetGrafanaAlertingConfigHistory
+type AlertingConfigHistoryParams struct {
+	// in:query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate
+type AlertingConfigHistoryActivateParams struct {
+	// in:path
+	// required: true
+	ID int64 `json:"id"`
+}
+
 // alertmanager routes
 // swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestReceivers
 // testing routes
@@ -572,6 +604,19 @@ type GettableUserConfig struct {
 	amSimple map[string]interface{} `yaml:"-" json:"-"`
 }
 
+// GettableHistoricUserConfig is a version of the Alerting config.
+// swagger:model
+type GettableHistoricUserConfig struct {
+	ID                 int64                     `json:"id"`
+	CreatedAt          time.Time                 `json:"created_at"`
+	Default            bool                      `json:"default"`
+	TemplateFiles      map[string]string         `json:"template_files"`
+	AlertmanagerConfig GettableApiAlertingConfig `json:"alertmanager_config"`
+}
+
+// swagger:model
+type GettableHistoricUserConfigs []GettableHistoricUserConfig
+
 func (c *GettableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 	// cortex/loki actually pass the AM config as a string.
 	type cortexGettableUserConfig struct {
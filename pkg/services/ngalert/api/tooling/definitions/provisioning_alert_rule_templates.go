@@ -0,0 +1,293 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/alert-rule-templates provisioning stable RouteGetAlertRuleTemplates
+//
+// Get all alert rule templates.
+//
+//     Responses:
+//       200: AlertRuleTemplates
+
+// swagger:route GET /api/v1/provisioning/alert-rule-templates/{UID} provisioning stable RouteGetAlertRuleTemplate
+//
+// Get a specific alert rule template by UID.
+//
+//     Responses:
+//       200: AlertRuleTemplate
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rule-templates provisioning stable RoutePostAlertRuleTemplate
+//
+// Create a new alert rule template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: AlertRuleTemplate
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/alert-rule-templates/{UID} provisioning stable RoutePutAlertRuleTemplate
+//
+// Update an existing alert rule template. The version of the template is incremented when the rule skeleton is changed.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRuleTemplate
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/alert-rule-templates/{UID} provisioning stable RouteDeleteAlertRuleTemplate
+//
+// Delete a specific alert rule template by UID. The alert rules created from the template are kept.
+//
+//     Responses:
+//       204: description: The alert rule template was deleted successfully.
+
+// swagger:route GET /api/v1/provisioning/alert-rule-templates/{UID}/instances provisioning stable RouteGetAlertRuleTemplateInstances
+//
+// Get the alert rules created from an alert rule template.
+//
+//     Responses:
+//       200: AlertRuleTemplateInstances
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rule-templates/{UID}/instances provisioning stable RoutePostAlertRuleTemplateInstance
+//
+// Create an alert rule from the latest version of an alert rule template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: AlertRuleTemplateInstance
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/diff provisioning stable RouteGetAlertRuleTemplateInstanceDiff
+//
+// Get the changes to an alert rule if the latest version of its template was applied to it.
+//
+//     Responses:
+//       200: AlertRuleTemplateInstanceDiff
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rule-templates/{UID}/instances/{RuleUID}/approve provisioning stable RoutePostAlertRuleTemplateInstanceApproval
+//
+// Apply the latest version of an alert rule template to an alert rule created from it.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRuleTemplateInstance
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: The template was changed since the changes were reviewed.
+
+// swagger:parameters RouteGetAlertRuleTemplate RoutePutAlertRuleTemplate RouteDeleteAlertRuleTemplate RouteGetAlertRuleTemplateInstances RoutePostAlertRuleTemplateInstance RouteGetAlertRuleTemplateInstanceDiff RoutePostAlertRuleTemplateInstanceApproval
+type AlertRuleTemplateUIDReference struct {
+	// Alert rule template UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RouteGetAlertRuleTemplateInstanceDiff RoutePostAlertRuleTemplateInstanceApproval
+type AlertRuleTemplateInstanceUIDReference struct {
+	// UID of the alert rule created from the template
+	// in:path
+	RuleUID string
+}
+
+// swagger:parameters RoutePostAlertRuleTemplate RoutePutAlertRuleTemplate
+type AlertRuleTemplatePayload struct {
+	// in:body
+	Body AlertRuleTemplate
+}
+
+// swagger:parameters RoutePostAlertRuleTemplateInstance
+type AlertRuleTemplateInstantiationPayload struct {
+	// in:body
+	Body AlertRuleTemplateInstantiation
+}
+
+// swagger:parameters RoutePostAlertRuleTemplateInstanceApproval
+type AlertRuleTemplateInstanceApprovalPayload struct {
+	// in:body
+	Body AlertRuleTemplateInstanceApproval
+}
+
+// AlertRuleTemplate is the skeleton of alert rules. The parameters are
+// referenced as ${name} in the models of the queries, the labels and the
+// annotations, and are given a value by every rule created from the template.
+// swagger:model
+type AlertRuleTemplate struct {
+	UID string `json:"uid"`
+	// required: true
+	// minLength: 1
+	// maxLength: 190
+	// example: High error rate
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// required: true
+	// example: C
+	Condition string `json:"condition"`
+	// required: true
+	// example: [{"refId":"A","relativeTimeRange":{"from":600,"to":0},"datasourceUid":"PD8C576611E62080A","model":{"expr":"rate(http_requests_total{service=\"${service}\",code=~\"5..\"}[5m])","refId":"A"}},{"refId":"B","datasourceUid":"-100","model":{"type":"reduce","expression":"A","reducer":"last","refId":"B"}},{"refId":"C","datasourceUid":"-100","model":{"type":"threshold","expression":"B","conditions":[{"evaluator":{"params":["${threshold}"],"type":"gt"}}],"refId":"C"}}]
+	Data []models.AlertQuery `json:"data"`
+	// required: true
+	NoDataState models.NoDataState `json:"noDataState"`
+	// required: true
+	ExecErrState models.ExecutionErrorState `json:"execErrState"`
+	// required: true
+	For time.Duration `json:"for"`
+	// example: {"summary": "High error rate of ${service}"}
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// example: {"severity": "critical"}
+	Labels map[string]string `json:"labels,omitempty"`
+	// example: [{"name": "service"}, {"name": "threshold", "description": "Errors per second", "default": 5}]
+	Parameters []models.AlertRuleTemplateParameter `json:"parameters,omitempty"`
+	// readonly: true
+	Version int64 `json:"version"`
+	// readonly: true
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+// swagger:model
+type AlertRuleTemplates []AlertRuleTemplate
+
+func (t *AlertRuleTemplate) UpstreamModel(orgID int64) models.AlertRuleTemplate {
+	return models.AlertRuleTemplate{
+		OrgID:        orgID,
+		UID:          t.UID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Condition:    t.Condition,
+		Data:         t.Data,
+		NoDataState:  t.NoDataState,
+		ExecErrState: t.ExecErrState,
+		For:          t.For,
+		Annotations:  t.Annotations,
+		Labels:       t.Labels,
+		Parameters:   t.Parameters,
+	}
+}
+
+func NewAlertRuleTemplate(tmpl models.AlertRuleTemplate) AlertRuleTemplate {
+	return AlertRuleTemplate{
+		UID:          tmpl.UID,
+		Title:        tmpl.Title,
+		Description:  tmpl.Description,
+		Condition:    tmpl.Condition,
+		Data:         tmpl.Data,
+		NoDataState:  tmpl.NoDataState,
+		ExecErrState: tmpl.ExecErrState,
+		For:          tmpl.For,
+		Annotations:  tmpl.Annotations,
+		Labels:       tmpl.Labels,
+		Parameters:   tmpl.Parameters,
+		Version:      tmpl.Version,
+		Updated:      tmpl.Updated,
+	}
+}
+
+// AlertRuleTemplateInstantiation is the alert rule to create from a template.
+type AlertRuleTemplateInstantiation struct {
+	// The UID of the alert rule, generated if empty.
+	RuleUID string `json:"ruleUID,omitempty"`
+	// required: true
+	// example: High error rate of checkout
+	Title string `json:"title"`
+	// required: true
+	// example: project_x
+	FolderUID string `json:"folderUID"`
+	// required: true
+	// example: eval_group_1
+	RuleGroup string `json:"ruleGroup"`
+	// The values of the parameters, overriding their default value.
+	// example: {"service": "checkout", "threshold": 10}
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// The labels of the alert rule, overriding the labels of the template.
+	// example: {"team": "payments"}
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// AlertRuleTemplateInstance is an alert rule created from a template.
+// swagger:model
+type AlertRuleTemplateInstance struct {
+	RuleUID     string `json:"ruleUID"`
+	TemplateUID string `json:"templateUID"`
+	// The version of the template the alert rule was last rendered from.
+	TemplateVersion int64 `json:"templateVersion"`
+	// Outdated is true when the template was changed since the alert rule was
+	// last rendered from it, and the changes are waiting for approval.
+	Outdated   bool                   `json:"outdated"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+}
+
+// swagger:model
+type AlertRuleTemplateInstances []AlertRuleTemplateInstance
+
+func NewAlertRuleTemplateInstance(instance models.AlertRuleTemplateInstance, templateVersion int64) AlertRuleTemplateInstance {
+	return AlertRuleTemplateInstance{
+		RuleUID:         instance.RuleUID,
+		TemplateUID:     instance.TemplateUID,
+		TemplateVersion: instance.TemplateVersion,
+		Outdated:        instance.TemplateVersion < templateVersion,
+		Parameters:      instance.Parameters,
+		Labels:          instance.Labels,
+	}
+}
+
+// AlertRuleTemplateInstanceDiff are the changes to an alert rule if the latest
+// version of its template was applied to it.
+// swagger:model
+type AlertRuleTemplateInstanceDiff struct {
+	RuleUID string `json:"ruleUID"`
+	// The version of the template the alert rule was last rendered from.
+	FromVersion int64 `json:"fromVersion"`
+	// The latest version of the template, to approve to apply the changes.
+	TemplateVersion int64             `json:"templateVersion"`
+	Changes         []AlertRuleChange `json:"changes"`
+}
+
+// AlertRuleChange is a field of an alert rule changed by its template.
+type AlertRuleChange struct {
+	// example: data[C]
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+}
+
+func NewAlertRuleTemplateInstanceDiff(diff models.AlertRuleTemplateInstanceDiff) AlertRuleTemplateInstanceDiff {
+	changes := make([]AlertRuleChange, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, AlertRuleChange{
+			Field:    change.Field,
+			Current:  change.Current,
+			Proposed: change.Proposed,
+		})
+	}
+	return AlertRuleTemplateInstanceDiff{
+		RuleUID:         diff.RuleUID,
+		FromVersion:     diff.FromVersion,
+		TemplateVersion: diff.TemplateVersion,
+		Changes:         changes,
+	}
+}
+
+// AlertRuleTemplateInstanceApproval approves the changes to an alert rule
+// reviewed for a version of its template.
+type AlertRuleTemplateInstanceApproval struct {
+	// The version of the template the changes were reviewed for.
+	// required: true
+	TemplateVersion int64 `json:"templateVersion"`
+}
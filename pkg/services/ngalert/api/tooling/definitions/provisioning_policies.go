@@ -1,5 +1,7 @@
 package definitions
 
+import "github.com/prometheus/common/model"
+
 // swagger:route GET /api/v1/provisioning/policies provisioning stable RouteGetPolicyTree
 //
 // Get the notification policy tree.
@@ -25,3 +27,58 @@ type Policytree struct {
 	// in:body
 	Body Route
 }
+
+// swagger:route POST /api/v1/provisioning/policies/simulate provisioning stable RoutePostPolicyTreeSimulation
+//
+// Simulates the routing of an alert with the given labels by the notification policy tree.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: PolicySimulationResult
+//       400: ValidationError
+
+// swagger:parameters RoutePostPolicyTreeSimulation
+type PolicySimulationParams struct {
+	// in:body
+	Body PolicySimulation
+}
+
+// swagger:model
+type PolicySimulation struct {
+	// The labels of the alert to route.
+	Labels map[string]string `json:"labels"`
+	// The notification policy tree to route the alert with instead of the current tree.
+	PolicyTree *Route `json:"policy_tree,omitempty"`
+}
+
+// swagger:model
+type PolicySimulationResult struct {
+	// The policies matching the alert. More than one policy matches when matching policies continue.
+	Matches []PolicySimulationMatch `json:"matches"`
+}
+
+type PolicySimulationMatch struct {
+	// The index of the matching policy in the nested policies of each level, empty for the root policy.
+	Path []int `json:"path"`
+	// The matchers of the policies from the root policy to the matching policy.
+	Matchers []string `json:"matchers"`
+	// The contact point notified, and the integrations of the contact point.
+	Receiver     string                        `json:"receiver"`
+	Integrations []PolicySimulationIntegration `json:"integrations"`
+	// The labels the alerts are grouped by, "..." when grouped by all labels, and the labels of the group of the alert.
+	GroupBy     []string          `json:"group_by"`
+	GroupLabels map[string]string `json:"group_labels"`
+	// The effective timing options and mute timings of the policy, inherited from the parent policies when not set.
+	GroupWait         model.Duration `json:"group_wait"`
+	GroupInterval     model.Duration `json:"group_interval"`
+	RepeatInterval    model.Duration `json:"repeat_interval"`
+	MuteTimeIntervals []string       `json:"mute_time_intervals"`
+}
+
+type PolicySimulationIntegration struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
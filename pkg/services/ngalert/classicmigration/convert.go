@@ -0,0 +1,237 @@
+package classicmigration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/classic"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const classicConditionsType = "classic_conditions"
+
+// reducers maps the reducers of classic conditions to the reducers of reduce
+// expressions. Classic reducers ignore null and NaN values, except for count.
+var reducers = map[string]struct {
+	reducer string
+	dropNN  bool
+}{
+	"avg":            {reducer: "mean", dropNN: true},
+	"sum":            {reducer: "sum", dropNN: true},
+	"min":            {reducer: "min", dropNN: true},
+	"max":            {reducer: "max", dropNN: true},
+	"last":           {reducer: "last", dropNN: true},
+	"count":          {reducer: "count"},
+	"count_non_null": {reducer: "count", dropNN: true},
+}
+
+type classicConditionsModel struct {
+	Type       string                         `json:"type"`
+	Conditions []classic.ClassicConditionJSON `json:"conditions"`
+}
+
+type reduceModel struct {
+	RefID      string            `json:"refId"`
+	Type       string            `json:"type"`
+	Expression string            `json:"expression"`
+	Reducer    string            `json:"reducer"`
+	Settings   map[string]string `json:"settings,omitempty"`
+}
+
+type mathModel struct {
+	RefID      string `json:"refId"`
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+}
+
+// HasClassicCondition returns true if the queries and expressions contain a classic condition.
+func HasClassicCondition(data []ngmodels.AlertQuery) bool {
+	for _, q := range data {
+		if isClassicCondition(q) {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert replaces the classic conditions of the queries and expressions of
+// an alert rule with a reduce expression per query and reducer, and a math
+// expression comparing the reduced values with the thresholds of the
+// evaluators. The math expression keeps the refID of the classic condition,
+// so the condition of the rule doesn't change. It returns the converted
+// queries and expressions and a description of the changes.
+func Convert(data []ngmodels.AlertQuery) ([]ngmodels.AlertQuery, []string, error) {
+	used := make(map[string]struct{}, len(data))
+	for _, q := range data {
+		used[q.RefID] = struct{}{}
+	}
+
+	converted := make([]ngmodels.AlertQuery, 0, len(data))
+	changes := []string{}
+	found := false
+	for _, q := range data {
+		if !isClassicCondition(q) {
+			converted = append(converted, q)
+			continue
+		}
+		found = true
+
+		queries, c, err := convertClassicCondition(q, used)
+		if err != nil {
+			return nil, nil, err
+		}
+		converted = append(converted, queries...)
+		changes = append(changes, c...)
+	}
+	if !found {
+		return nil, nil, ErrNoClassicCondition
+	}
+	return converted, changes, nil
+}
+
+func isClassicCondition(q ngmodels.AlertQuery) bool {
+	if !expr.IsDataSource(q.DatasourceUID) {
+		return false
+	}
+	var model struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(q.Model, &model); err != nil {
+		return false
+	}
+	return model.Type == classicConditionsType
+}
+
+func convertClassicCondition(q ngmodels.AlertQuery, used map[string]struct{}) ([]ngmodels.AlertQuery, []string, error) {
+	var model classicConditionsModel
+	if err := json.Unmarshal(q.Model, &model); err != nil {
+		return nil, nil, fmt.Errorf("failed to read classic condition %s: %w", q.RefID, err)
+	}
+	if len(model.Conditions) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s has no conditions", ErrUnsupportedCondition, q.RefID)
+	}
+
+	result := make([]ngmodels.AlertQuery, 0, len(model.Conditions)+1)
+	changes := make([]string, 0, len(model.Conditions)+1)
+	// conditions on the same query with the same reducer share the reduce expression
+	reduced := make(map[string]string, len(model.Conditions))
+
+	expression := ""
+	for i, c := range model.Conditions {
+		if len(c.Query.Params) == 0 || c.Query.Params[0] == "" {
+			return nil, nil, fmt.Errorf("%w: condition %d of %s has no query", ErrUnsupportedCondition, i+1, q.RefID)
+		}
+		queryRefID := c.Query.Params[0]
+
+		r, ok := reducers[c.Reducer.Type]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: reducer %s of condition %d of %s has no equivalent expression", ErrUnsupportedCondition, c.Reducer.Type, i+1, q.RefID)
+		}
+
+		key := queryRefID + "/" + c.Reducer.Type
+		refID, ok := reduced[key]
+		if !ok {
+			refID = newRefID(used)
+			reduced[key] = refID
+
+			m := reduceModel{RefID: refID, Type: "reduce", Expression: queryRefID, Reducer: r.reducer}
+			if r.dropNN {
+				m.Settings = map[string]string{"mode": "dropNN"}
+			}
+			reduceQuery, err := newExpression(q, refID, m)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, reduceQuery)
+			changes = append(changes, fmt.Sprintf("Added reduce expression %s: %s of %s", refID, r.reducer, queryRefID))
+		}
+
+		comparison, err := compare("$"+refID, c.Evaluator)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: condition %d of %s: %s", ErrUnsupportedCondition, i+1, q.RefID, err)
+		}
+
+		switch {
+		case i == 0:
+			expression = comparison
+		case c.Operator.Type == "or":
+			expression = fmt.Sprintf("(%s) || (%s)", expression, comparison)
+		default:
+			expression = fmt.Sprintf("(%s) && (%s)", expression, comparison)
+		}
+	}
+
+	mathQuery, err := newExpression(q, q.RefID, mathModel{RefID: q.RefID, Type: "math", Expression: expression})
+	if err != nil {
+		return nil, nil, err
+	}
+	result = append(result, mathQuery)
+	changes = append(changes, fmt.Sprintf("Replaced classic condition %s with math expression: %s", q.RefID, expression))
+	return result, changes, nil
+}
+
+// compare returns the math expression of an evaluator of a classic condition.
+func compare(variable string, evaluator classic.ConditionEvalJSON) (string, error) {
+	switch evaluator.Type {
+	case "gt", "lt":
+		if len(evaluator.Params) == 0 {
+			return "", fmt.Errorf("evaluator %s is missing the threshold", evaluator.Type)
+		}
+		op := ">"
+		if evaluator.Type == "lt" {
+			op = "<"
+		}
+		return fmt.Sprintf("%s %s %s", variable, op, formatFloat(evaluator.Params[0])), nil
+	case "within_range", "outside_range":
+		if len(evaluator.Params) != 2 {
+			return "", fmt.Errorf("evaluator %s needs 2 parameters", evaluator.Type)
+		}
+		// classic conditions accept the bounds in any order
+		lower, upper := evaluator.Params[0], evaluator.Params[1]
+		if lower > upper {
+			lower, upper = upper, lower
+		}
+		if evaluator.Type == "within_range" {
+			return fmt.Sprintf("%s > %s && %s < %s", variable, formatFloat(lower), variable, formatFloat(upper)), nil
+		}
+		return fmt.Sprintf("%s < %s || %s > %s", variable, formatFloat(lower), variable, formatFloat(upper)), nil
+	case "no_value":
+		return fmt.Sprintf("is_null(%s)", variable), nil
+	}
+	return "", fmt.Errorf("evaluator %s has no equivalent expression", evaluator.Type)
+}
+
+func newExpression(classicCondition ngmodels.AlertQuery, refID string, model interface{}) (ngmodels.AlertQuery, error) {
+	b, err := json.Marshal(model)
+	if err != nil {
+		return ngmodels.AlertQuery{}, err
+	}
+	return ngmodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: classicCondition.DatasourceUID,
+		Model:         b,
+	}, nil
+}
+
+// newRefID returns the first refID that isn't used yet: A to Z, then AA, AB and so on.
+func newRefID(used map[string]struct{}) string {
+	for n := 0; ; n++ {
+		refID := ""
+		for i := n; ; i = i/26 - 1 {
+			refID = string(rune('A'+i%26)) + refID
+			if i < 26 {
+				break
+			}
+		}
+		if _, ok := used[refID]; !ok {
+			used[refID] = struct{}{}
+			return refID
+		}
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
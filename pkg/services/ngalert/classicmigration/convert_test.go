@@ -0,0 +1,124 @@
+package classicmigration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestConvert(t *testing.T) {
+	query := func(refID string) ngmodels.AlertQuery {
+		return ngmodels.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: "prometheus",
+			Model:         json.RawMessage(`{"expr":"up"}`),
+		}
+	}
+	classicCondition := func(refID string, conditions string) ngmodels.AlertQuery {
+		return ngmodels.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type":"classic_conditions","refId":"` + refID + `","conditions":` + conditions + `}`),
+		}
+	}
+	models := func(t *testing.T, data []ngmodels.AlertQuery) map[string]map[string]interface{} {
+		t.Helper()
+		result := make(map[string]map[string]interface{}, len(data))
+		for _, q := range data {
+			m := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(q.Model, &m))
+			result[q.RefID] = m
+		}
+		return result
+	}
+
+	t.Run("converts a single condition", func(t *testing.T) {
+		data, changes, err := Convert([]ngmodels.AlertQuery{
+			query("A"),
+			classicCondition("C", `[{"evaluator":{"params":[10],"type":"gt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"avg"}}]`),
+		})
+		require.NoError(t, err)
+		require.Len(t, data, 3)
+		require.Len(t, changes, 2)
+
+		m := models(t, data)
+		require.Equal(t, map[string]interface{}{"refId": "B", "type": "reduce", "expression": "A", "reducer": "mean", "settings": map[string]interface{}{"mode": "dropNN"}}, m["B"])
+		require.Equal(t, map[string]interface{}{"refId": "C", "type": "math", "expression": "$B > 10"}, m["C"])
+		require.Equal(t, "-100", data[2].DatasourceUID)
+	})
+
+	t.Run("combines conditions from left to right and shares reductions", func(t *testing.T) {
+		data, _, err := Convert([]ngmodels.AlertQuery{
+			query("A"),
+			query("B"),
+			classicCondition("C", `[
+				{"evaluator":{"params":[10],"type":"gt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"last"}},
+				{"evaluator":{"params":[20, 5],"type":"within_range"},"operator":{"type":"or"},"query":{"params":["B"]},"reducer":{"type":"count"}},
+				{"evaluator":{"params":[-1],"type":"lt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"last"}}
+			]`),
+		})
+		require.NoError(t, err)
+		require.Len(t, data, 5)
+
+		m := models(t, data)
+		require.Equal(t, "last", m["D"]["reducer"])
+		require.Equal(t, "count", m["E"]["reducer"])
+		require.NotContains(t, m["E"], "settings")
+		require.Equal(t, "(($D > 10) || ($E > 5 && $E < 20)) && ($D < -1)", m["C"]["expression"])
+	})
+
+	t.Run("converts range and no value evaluators", func(t *testing.T) {
+		data, _, err := Convert([]ngmodels.AlertQuery{
+			query("A"),
+			classicCondition("C", `[
+				{"evaluator":{"params":[1, 2],"type":"outside_range"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"max"}},
+				{"evaluator":{"params":[],"type":"no_value"},"operator":{"type":"or"},"query":{"params":["A"]},"reducer":{"type":"max"}}
+			]`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "($B < 1 || $B > 2) || (is_null($B))", models(t, data)["C"]["expression"])
+	})
+
+	t.Run("math expressions can be parsed", func(t *testing.T) {
+		data, _, err := Convert([]ngmodels.AlertQuery{
+			query("A"),
+			classicCondition("C", `[
+				{"evaluator":{"params":[0.5],"type":"lt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"sum"}},
+				{"evaluator":{"params":[1, 2],"type":"outside_range"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"min"}},
+				{"evaluator":{"params":[],"type":"no_value"},"operator":{"type":"or"},"query":{"params":["A"]},"reducer":{"type":"count_non_null"}}
+			]`),
+		})
+		require.NoError(t, err)
+		_, err = mathexp.New(models(t, data)["C"]["expression"].(string))
+		require.NoError(t, err)
+	})
+
+	t.Run("fails for reducers without equivalent", func(t *testing.T) {
+		_, _, err := Convert([]ngmodels.AlertQuery{
+			query("A"),
+			classicCondition("C", `[{"evaluator":{"params":[10],"type":"gt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"median"}}]`),
+		})
+		require.ErrorIs(t, err, ErrUnsupportedCondition)
+	})
+
+	t.Run("fails without classic condition", func(t *testing.T) {
+		_, _, err := Convert([]ngmodels.AlertQuery{query("A")})
+		require.ErrorIs(t, err, ErrNoClassicCondition)
+	})
+}
+
+func TestNewRefID(t *testing.T) {
+	used := map[string]struct{}{"A": {}, "C": {}}
+	require.Equal(t, "B", newRefID(used))
+	require.Equal(t, "D", newRefID(used))
+
+	for i := 'E'; i <= 'Z'; i++ {
+		used[string(i)] = struct{}{}
+	}
+	require.Equal(t, "AA", newRefID(used))
+	require.Equal(t, "AB", newRefID(used))
+}
@@ -0,0 +1,89 @@
+package classicmigration
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	ErrNoClassicCondition   = errors.New("alert rule has no classic condition")
+	ErrUnsupportedCondition = errors.New("classic condition cannot be converted")
+	ErrRuleProvisioned      = errors.New("provisioned alert rules cannot be migrated, update the provisioning source instead")
+	ErrRuleAccessDenied     = errors.New("not allowed to update the alert rule")
+)
+
+type MigrationStatus string
+
+const (
+	MigrationStatusMigrated    MigrationStatus = "migrated"
+	MigrationStatusUnsupported MigrationStatus = "unsupported"
+	MigrationStatusProvisioned MigrationStatus = "provisioned"
+	MigrationStatusFailed      MigrationStatus = "failed"
+)
+
+// RuleDiff is the condition and the queries and expressions of an alert rule
+// before or after the migration.
+type RuleDiff struct {
+	Condition string                `json:"condition"`
+	Data      []ngmodels.AlertQuery `json:"data"`
+}
+
+type MigrationResult struct {
+	UID          string          `json:"uid"`
+	Title        string          `json:"title"`
+	NamespaceUID string          `json:"namespaceUid"`
+	RuleGroup    string          `json:"ruleGroup"`
+	Status       MigrationStatus `json:"status"`
+	Changes      []string        `json:"changes"`
+	Error        string          `json:"error,omitempty"`
+	// Before and After are only returned for dry runs of a single rule.
+	Before *RuleDiff `json:"before,omitempty"`
+	After  *RuleDiff `json:"after,omitempty"`
+}
+
+type BulkMigrationResult struct {
+	Migrated int                `json:"migrated"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Results  []*MigrationResult `json:"results"`
+}
+
+// ClassicRule is an alert rule that still uses a classic condition.
+type ClassicRule struct {
+	UID          string `json:"uid"`
+	Title        string `json:"title"`
+	NamespaceUID string `json:"namespaceUid"`
+	RuleGroup    string `json:"ruleGroup"`
+	Provisioned  bool   `json:"provisioned"`
+	// Migratable is false for provisioned rules and rules using a reducer or
+	// an evaluator that has no equivalent expression, Reason tells which.
+	Migratable bool   `json:"migratable"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// ----------------------
+// COMMANDS
+
+type MigrateRuleCommand struct {
+	OrgID int64                `json:"-"`
+	UID   string               `json:"-"`
+	User  *models.SignedInUser `json:"-"`
+	// DryRun returns the converted rule without saving it.
+	DryRun bool `json:"dryRun"`
+}
+
+type MigrateAllRulesCommand struct {
+	OrgID  int64                `json:"-"`
+	User   *models.SignedInUser `json:"-"`
+	DryRun bool                 `json:"dryRun"`
+}
+
+// ---------------------
+// QUERIES
+
+type ReportQuery struct {
+	OrgID int64
+	User  *models.SignedInUser
+}
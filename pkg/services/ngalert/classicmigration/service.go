@@ -0,0 +1,256 @@
+package classicmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type RuleStore interface {
+	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) error
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error
+	UpdateAlertRules(ctx context.Context, rules []store.UpdateRule) error
+	GetUserVisibleNamespaces(ctx context.Context, orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error)
+}
+
+type ProvenanceStore interface {
+	GetProvenance(ctx context.Context, o ngmodels.Provisionable, org int64) (ngmodels.Provenance, error)
+	GetProvenances(ctx context.Context, org int64, resourceType string) (map[string]ngmodels.Provenance, error)
+}
+
+// Service converts alert rules using classic conditions into equivalent
+// reduce and math expressions.
+type Service struct {
+	ruleStore       RuleStore
+	provenanceStore ProvenanceStore
+	ac              accesscontrol.AccessControl
+	log             log.Logger
+}
+
+func NewService(ruleStore RuleStore, provenanceStore ProvenanceStore, ac accesscontrol.AccessControl, log log.Logger) *Service {
+	return &Service{
+		ruleStore:       ruleStore,
+		provenanceStore: provenanceStore,
+		ac:              ac,
+		log:             log,
+	}
+}
+
+// Report returns the alert rules of the organization that use a classic
+// condition and that the user can read.
+func (s *Service) Report(ctx context.Context, query *ReportQuery) ([]*ClassicRule, error) {
+	rules, err := s.classicRules(ctx, query.OrgID, query.User, accesscontrol.ActionAlertingRuleRead)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(ctx, query.OrgID, (&ngmodels.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ClassicRule, 0, len(rules))
+	for _, rule := range rules {
+		r := &ClassicRule{
+			UID:          rule.UID,
+			Title:        rule.Title,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroup:    rule.RuleGroup,
+			Provisioned:  provenances[rule.UID] != ngmodels.ProvenanceNone,
+			Migratable:   true,
+		}
+		if r.Provisioned {
+			r.Migratable = false
+			r.Reason = ErrRuleProvisioned.Error()
+		} else if _, _, err := Convert(rule.Data); err != nil {
+			r.Migratable = false
+			r.Reason = err.Error()
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// Migrate converts the classic conditions of an alert rule and saves it as a new version.
+func (s *Service) Migrate(ctx context.Context, cmd *MigrateRuleCommand) (*MigrationResult, error) {
+	query := ngmodels.GetAlertRuleByUIDQuery{OrgID: cmd.OrgID, UID: cmd.UID}
+	if err := s.ruleStore.GetAlertRuleByUID(ctx, &query); err != nil {
+		return nil, err
+	}
+	namespaces, err := s.ruleStore.GetUserVisibleNamespaces(ctx, cmd.OrgID, cmd.User)
+	if err != nil {
+		return nil, err
+	}
+	folder, ok := namespaces[query.Result.NamespaceUID]
+	if !ok {
+		return nil, ngmodels.ErrAlertRuleNotFound
+	}
+	allowed, err := s.authorize(ctx, cmd.User, query.Result, folder, accesscontrol.ActionAlertingRuleUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrRuleAccessDenied
+	}
+	provenance, err := s.provenanceStore.GetProvenance(ctx, query.Result, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if provenance != ngmodels.ProvenanceNone {
+		return nil, ErrRuleProvisioned
+	}
+
+	result, err := s.migrate(ctx, query.Result, cmd.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.DryRun {
+		result.Before = nil
+		result.After = nil
+	}
+	return result, nil
+}
+
+// MigrateAll converts all the alert rules of the organization that use a
+// classic condition and that the user can update.
+func (s *Service) MigrateAll(ctx context.Context, cmd *MigrateAllRulesCommand) (*BulkMigrationResult, error) {
+	rules, err := s.classicRules(ctx, cmd.OrgID, cmd.User, accesscontrol.ActionAlertingRuleUpdate)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(ctx, cmd.OrgID, (&ngmodels.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	bulk := &BulkMigrationResult{Results: make([]*MigrationResult, 0, len(rules))}
+	for _, rule := range rules {
+		var result *MigrationResult
+		if provenances[rule.UID] != ngmodels.ProvenanceNone {
+			result = newResult(rule, MigrationStatusProvisioned)
+			result.Error = ErrRuleProvisioned.Error()
+		} else {
+			result, err = s.migrate(ctx, rule, cmd.DryRun)
+			if err != nil {
+				result = newResult(rule, MigrationStatusFailed)
+				result.Error = err.Error()
+				if errors.Is(err, ErrUnsupportedCondition) {
+					result.Status = MigrationStatusUnsupported
+				} else {
+					s.log.Warn("Failed to migrate classic condition", "orgId", cmd.OrgID, "uid", rule.UID, "error", err)
+				}
+			}
+		}
+		// The bulk result would be huge with the queries of a dry run.
+		result.Before = nil
+		result.After = nil
+
+		switch result.Status {
+		case MigrationStatusMigrated:
+			bulk.Migrated++
+		case MigrationStatusFailed:
+			bulk.Failed++
+		default:
+			bulk.Skipped++
+		}
+		bulk.Results = append(bulk.Results, result)
+	}
+	return bulk, nil
+}
+
+func (s *Service) migrate(ctx context.Context, rule *ngmodels.AlertRule, dryRun bool) (*MigrationResult, error) {
+	data, changes, err := Convert(rule.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newResult(rule, MigrationStatusMigrated)
+	result.Changes = changes
+	result.Before = &RuleDiff{Condition: rule.Condition, Data: rule.Data}
+	result.After = &RuleDiff{Condition: rule.Condition, Data: data}
+	if dryRun {
+		return result, nil
+	}
+
+	updated := *rule
+	updated.Data = data
+	if err := s.ruleStore.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: rule, New: updated}}); err != nil {
+		return nil, err
+	}
+	s.log.Info("Migrated classic condition", "orgId", rule.OrgID, "uid", rule.UID)
+	return result, nil
+}
+
+// classicRules returns the alert rules of the organization that use a classic
+// condition and that the user is allowed the action on.
+func (s *Service) classicRules(ctx context.Context, orgID int64, user *models.SignedInUser, action string) ([]*ngmodels.AlertRule, error) {
+	query := ngmodels.ListAlertRulesQuery{OrgID: orgID}
+	if err := s.ruleStore.ListAlertRules(ctx, &query); err != nil {
+		return nil, err
+	}
+	namespaces, err := s.ruleStore.GetUserVisibleNamespaces(ctx, orgID, user)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*ngmodels.AlertRule, 0)
+	for _, rule := range query.Result {
+		if !HasClassicCondition(rule.Data) {
+			continue
+		}
+		folder, ok := namespaces[rule.NamespaceUID]
+		if !ok {
+			continue
+		}
+		allowed, err := s.authorize(ctx, user, rule, folder, action)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// authorize checks the action on an alert rule in a folder visible to the
+// user. Like the ruler API, the user must be allowed the action in the folder
+// and to query the data sources of the rule. When access control is disabled
+// updates require the permission to save in the folder.
+func (s *Service) authorize(ctx context.Context, user *models.SignedInUser, rule *ngmodels.AlertRule, folder *models.Folder, action string) (bool, error) {
+	if s.ac.IsDisabled() {
+		if action == accesscontrol.ActionAlertingRuleRead {
+			return true, nil
+		}
+		return guardian.New(ctx, folder.Id, user.OrgId, user).CanSave()
+	}
+
+	evaluators := []accesscontrol.Evaluator{
+		accesscontrol.EvalPermission(action, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID)),
+	}
+	for _, query := range rule.Data {
+		if query.QueryType == expr.DatasourceType || query.DatasourceUID == expr.OldDatasourceUID {
+			continue
+		}
+		evaluators = append(evaluators, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)))
+	}
+	return s.ac.Evaluate(ctx, user, accesscontrol.EvalAll(evaluators...))
+}
+
+func newResult(rule *ngmodels.AlertRule, status MigrationStatus) *MigrationResult {
+	return &MigrationResult{
+		UID:          rule.UID,
+		Title:        rule.Title,
+		NamespaceUID: rule.NamespaceUID,
+		RuleGroup:    rule.RuleGroup,
+		Status:       status,
+		Changes:      []string{},
+	}
+}
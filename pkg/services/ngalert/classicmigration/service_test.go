@@ -0,0 +1,92 @@
+package classicmigration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeProvenanceStore struct{}
+
+func (fakeProvenanceStore) GetProvenance(context.Context, ngmodels.Provisionable, int64) (ngmodels.Provenance, error) {
+	return ngmodels.ProvenanceNone, nil
+}
+
+func (fakeProvenanceStore) GetProvenances(context.Context, int64, string) (map[string]ngmodels.Provenance, error) {
+	return map[string]ngmodels.Provenance{}, nil
+}
+
+func TestService_folderAccess(t *testing.T) {
+	classicRule := func(uid, namespaceUID string) *ngmodels.AlertRule {
+		return &ngmodels.AlertRule{
+			OrgID:        1,
+			UID:          uid,
+			Title:        uid,
+			NamespaceUID: namespaceUID,
+			RuleGroup:    "group",
+			Condition:    "C",
+			Data: []ngmodels.AlertQuery{
+				{RefID: "A", DatasourceUID: "prometheus", Model: json.RawMessage(`{"expr":"up"}`)},
+				{RefID: "C", DatasourceUID: "-100", Model: json.RawMessage(`{"type":"classic_conditions","refId":"C","conditions":[` +
+					`{"evaluator":{"params":[10],"type":"gt"},"operator":{"type":"and"},"query":{"params":["A"]},"reducer":{"type":"avg"}}]}`)},
+			},
+		}
+	}
+
+	ruleStore := store.NewFakeRuleStore(t)
+	ruleStore.PutRule(context.Background(), classicRule("allowed", "folder-allowed"), classicRule("denied", "folder-denied"))
+	ac := acmock.New().WithPermissions([]accesscontrol.Permission{
+		{Action: accesscontrol.ActionAlertingRuleRead, Scope: "folders:uid:folder-allowed"},
+		{Action: accesscontrol.ActionAlertingRuleUpdate, Scope: "folders:uid:folder-allowed"},
+		{Action: accesscontrol.ActionAlertingRuleRead, Scope: "folders:uid:folder-denied"},
+		{Action: "datasources:query", Scope: "datasources:uid:prometheus"},
+	})
+	svc := NewService(ruleStore, fakeProvenanceStore{}, ac, log.New("test"))
+	user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR}
+
+	t.Run("report lists the rules the user can read", func(t *testing.T) {
+		report, err := svc.Report(context.Background(), &ReportQuery{OrgID: 1, User: user})
+		require.NoError(t, err)
+		require.Len(t, report, 2)
+	})
+
+	t.Run("migrate all only migrates the rules the user can update", func(t *testing.T) {
+		result, err := svc.MigrateAll(context.Background(), &MigrateAllRulesCommand{OrgID: 1, User: user, DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Migrated)
+		require.Len(t, result.Results, 1)
+		require.Equal(t, "allowed", result.Results[0].UID)
+	})
+
+	t.Run("migrate rejects rules the user cannot update", func(t *testing.T) {
+		_, err := svc.Migrate(context.Background(), &MigrateRuleCommand{OrgID: 1, UID: "denied", User: user, DryRun: true})
+		require.ErrorIs(t, err, ErrRuleAccessDenied)
+
+		result, err := svc.Migrate(context.Background(), &MigrateRuleCommand{OrgID: 1, UID: "allowed", User: user, DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, MigrationStatusMigrated, result.Status)
+	})
+
+	t.Run("rules in folders the user cannot see are not found", func(t *testing.T) {
+		hidden := store.NewFakeRuleStore(t)
+		hidden.PutRule(context.Background(), classicRule("hidden", "folder-hidden"))
+		hidden.Folders[1] = nil
+		svc := NewService(hidden, fakeProvenanceStore{}, ac, log.New("test"))
+
+		_, err := svc.Migrate(context.Background(), &MigrateRuleCommand{OrgID: 1, UID: "hidden", User: user})
+		require.ErrorIs(t, err, ngmodels.ErrAlertRuleNotFound)
+
+		report, err := svc.Report(context.Background(), &ReportQuery{OrgID: 1, User: user})
+		require.NoError(t, err)
+		require.Empty(t, report)
+	})
+}
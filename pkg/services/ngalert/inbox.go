@@ -0,0 +1,49 @@
+package ngalert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// inboxRuleErrorNotifier notifies the owner of the folder of a failing alert rule in
+// their inbox, at most once a day per rule.
+type inboxRuleErrorNotifier struct {
+	inbox            inbox.Service
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+}
+
+func (n *inboxRuleErrorNotifier) RuleEvaluationFailed(ctx context.Context, rule *ngmodels.AlertRule, evalErr error) {
+	query := &models.GetDashboardQuery{Uid: rule.NamespaceUID, OrgId: rule.OrgID}
+	if err := n.dashboardService.GetDashboard(ctx, query); err != nil {
+		n.log.Warn("Failed to find folder of failing alert rule", "rule", rule.UID, "folder", rule.NamespaceUID, "err", err)
+		return
+	}
+	if query.Result.CreatedBy <= 0 {
+		return
+	}
+
+	body := "The alert rule failed to evaluate."
+	if evalErr != nil {
+		body = evalErr.Error()
+	}
+	err := n.inbox.Send(ctx, &inbox.SendCommand{
+		OrgID:    rule.OrgID,
+		UserIDs:  []int64{query.Result.CreatedBy},
+		Kind:     inbox.KindAlertRuleError,
+		Title:    fmt.Sprintf("Alert rule %q in folder %q is failing", rule.Title, query.Result.Title),
+		Body:     body,
+		URL:      fmt.Sprintf("/alerting/grafana/%s/view", rule.UID),
+		DedupKey: fmt.Sprintf("alert-rule-error-%s-%s", rule.UID, time.Now().UTC().Format("2006-01-02")),
+	})
+	if err != nil {
+		n.log.Warn("Failed to notify about failing alert rule", "rule", rule.UID, "err", err)
+	}
+}
@@ -0,0 +1,114 @@
+package ngalert
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+// livePublisher pushes messages to Grafana Live channels.
+type livePublisher interface {
+	Publish(orgID int64, channel string, data []byte) error
+}
+
+// stateEventType is the type of the messages published to the alert state channels.
+type stateEventType string
+
+const (
+	stateEventStateChanged stateEventType = "state_changed"
+	stateEventAlertsSent   stateEventType = "alerts_sent"
+)
+
+// stateEvent is the message published to the `grafana/alerting/state/<folder uid>` channel of
+// the folder of an alert rule.
+type stateEvent struct {
+	Type      stateEventType       `json:"type"`
+	RuleUID   string               `json:"ruleUID"`
+	RuleTitle string               `json:"ruleTitle"`
+	RuleGroup string               `json:"ruleGroup"`
+	FolderUID string               `json:"folderUID"`
+	Instances []stateEventInstance `json:"instances,omitempty"`
+	Alerts    []stateEventAlert    `json:"alerts,omitempty"`
+}
+
+type stateEventInstance struct {
+	Labels              data.Labels `json:"labels"`
+	State               string      `json:"state"`
+	StateReason         string      `json:"stateReason,omitempty"`
+	PreviousState       string      `json:"previousState"`
+	PreviousStateReason string      `json:"previousStateReason,omitempty"`
+	StartsAt            time.Time   `json:"startsAt"`
+	LastEvaluationTime  time.Time   `json:"lastEvaluationTime"`
+	Value               string      `json:"value,omitempty"`
+}
+
+type stateEventAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// liveStatePublisher publishes the state changes of the alert rules and the alerts sent to
+// the notifiers to the Live channel of the folder of the rule, so that only the users allowed
+// to read the rules of the folder can subscribe to them.
+type liveStatePublisher struct {
+	live livePublisher
+	log  log.Logger
+}
+
+func (p *liveStatePublisher) StatesChanged(_ context.Context, rule *ngmodels.AlertRule, transitions []schedule.StateTransition) {
+	instances := make([]stateEventInstance, 0, len(transitions))
+	for _, t := range transitions {
+		instances = append(instances, stateEventInstance{
+			Labels:              t.Labels,
+			State:               t.State.State.String(),
+			StateReason:         t.StateReason,
+			PreviousState:       t.PreviousState.String(),
+			PreviousStateReason: t.PreviousStateReason,
+			StartsAt:            t.StartsAt,
+			LastEvaluationTime:  t.LastEvaluationTime,
+			Value:               t.LastEvaluationString,
+		})
+	}
+	p.publish(rule, stateEventStateChanged, instances, nil)
+}
+
+func (p *liveStatePublisher) AlertsSent(_ context.Context, rule *ngmodels.AlertRule, alerts definitions.PostableAlerts) {
+	sent := make([]stateEventAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		sent = append(sent, stateEventAlert{
+			Labels:      a.Labels,
+			Annotations: a.Annotations,
+			StartsAt:    time.Time(a.StartsAt),
+			EndsAt:      time.Time(a.EndsAt),
+		})
+	}
+	p.publish(rule, stateEventAlertsSent, nil, sent)
+}
+
+func (p *liveStatePublisher) publish(rule *ngmodels.AlertRule, eventType stateEventType, instances []stateEventInstance, alerts []stateEventAlert) {
+	body, err := json.Marshal(stateEvent{
+		Type:      eventType,
+		RuleUID:   rule.UID,
+		RuleTitle: rule.Title,
+		RuleGroup: rule.RuleGroup,
+		FolderUID: rule.NamespaceUID,
+		Instances: instances,
+		Alerts:    alerts,
+	})
+	if err != nil {
+		p.log.Warn("Failed to marshal alert state event", "rule", rule.UID, "err", err)
+		return
+	}
+	if err := p.live.Publish(rule.OrgID, "grafana/alerting/state/"+rule.NamespaceUID, body); err != nil {
+		p.log.Warn("Failed to publish alert state event", "rule", rule.UID, "err", err)
+	}
+}
@@ -57,6 +57,7 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *legacyMetrics.Ticker
 	EvaluationMissed                    *prometheus.CounterVec
+	QueryDeduplication                  *prometheus.CounterVec
 }
 
 type MultiOrgAlertmanager struct {
@@ -209,6 +210,15 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		QueryDeduplication: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_query_deduplication_total",
+				Help:      "The total number of data source queries of rule evaluations, by whether the result of an identical query in the same tick was reused (hit) or the data source was queried (miss).",
+			},
+			[]string{"result"},
+		),
 	}
 }
 
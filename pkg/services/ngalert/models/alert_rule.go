@@ -84,6 +84,10 @@ const (
 	DashboardUIDAnnotation = "__dashboardUid__"
 	PanelIDAnnotation      = "__panelId__"
 
+	// TimeRegionCalendarAnnotation is the uid of a time region calendar, the rule is only
+	// evaluated while the calendar is active, e.g. during business hours.
+	TimeRegionCalendarAnnotation = "__timeRegionCalendarUid__"
+
 	// This isn't a hard-coded secret token, hence the nolint.
 	//nolint:gosec
 	ScreenshotTokenAnnotation = "__alertScreenshotToken__"
@@ -96,9 +100,10 @@ var (
 		NamespaceUIDLabel: {},
 	}
 	InternalAnnotationNameSet = map[string]struct{}{
-		DashboardUIDAnnotation:    {},
-		PanelIDAnnotation:         {},
-		ScreenshotTokenAnnotation: {},
+		DashboardUIDAnnotation:       {},
+		PanelIDAnnotation:            {},
+		TimeRegionCalendarAnnotation: {},
+		ScreenshotTokenAnnotation:    {},
 	}
 )
 
@@ -124,6 +129,32 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record makes the rule a recording rule, see Record.
+	Record *Record `xorm:"record"`
+}
+
+// Record turns an alert rule into a recording rule: instead of alerting on its
+// condition, the rule writes the values of the query or expression From as the
+// series Metric, with the labels of each value.
+type Record struct {
+	Metric string `json:"metric"`
+	From   string `json:"from"`
+}
+
+func (r *Record) FromDB(data []byte) error {
+	return json.Unmarshal(data, r)
+}
+
+func (r *Record) ToDB() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// IsRecording returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != nil && alertRule.Record.Metric != ""
 }
 
 type SchedulableAlertRule struct {
@@ -132,6 +163,8 @@ type SchedulableAlertRule struct {
 	OrgID           int64  `xorm:"org_id"`
 	IntervalSeconds int64
 	Version         int64
+	NamespaceUID    string `xorm:"namespace_uid"`
+	RuleGroup       string
 }
 
 type LabelOption func(map[string]string)
@@ -211,6 +244,11 @@ func (alertRule *SchedulableAlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
 }
 
+// GetGroupKey returns the identifier of a group the rule belongs to
+func (alertRule *SchedulableAlertRule) GetGroupKey() AlertRuleGroupKey {
+	return AlertRuleGroupKey{OrgID: alertRule.OrgID, NamespaceUID: alertRule.NamespaceUID, RuleGroup: alertRule.RuleGroup}
+}
+
 // PreSave sets default values and loads the updated model for each alert query.
 func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	for i, q := range alertRule.Data {
@@ -259,6 +297,8 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record makes the rule a recording rule, see Record.
+	Record *Record `xorm:"record"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -349,7 +389,8 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//    - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition and AlertRule.Data
+//
 // If either of the pair is specified, neither is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.Title == "" {
@@ -358,6 +399,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		ruleToPatch.Record = existingRule.Record
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
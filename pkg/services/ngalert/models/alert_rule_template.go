@@ -0,0 +1,379 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAlertRuleTemplateNotFound is an error for an unknown alert rule template.
+	ErrAlertRuleTemplateNotFound = errors.New("could not find alert rule template")
+	// ErrAlertRuleTemplateInstanceNotFound is an error for an alert rule that was not created from the template.
+	ErrAlertRuleTemplateInstanceNotFound = errors.New("could not find alert rule template instance")
+	// ErrAlertRuleTemplateFailedValidation is an error for an invalid alert rule template or parameter value.
+	ErrAlertRuleTemplateFailedValidation = errors.New("invalid alert rule template")
+	// ErrAlertRuleTemplateVersionMismatch is an error for a change approved for a version of the template
+	// that is not the latest one.
+	ErrAlertRuleTemplateVersionMismatch = errors.New("alert rule template version mismatch")
+)
+
+var templateParameterNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// AlertRuleTemplate is the skeleton of alert rules: the queries, condition and
+// options of the rules with parameters, like the thresholds or the service to
+// alert on, that are given a value by every rule created from the template.
+//
+// The parameters are referenced as ${name} in the models of the queries, the
+// labels and the annotations. A string of the model that is exactly ${name} is
+// replaced with the value of the parameter, keeping its type.
+type AlertRuleTemplate struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	UID          string `xorm:"uid"`
+	Title        string
+	Description  string
+	Condition    string
+	Data         []AlertQuery
+	NoDataState  NoDataState
+	ExecErrState ExecutionErrorState
+	For          time.Duration
+	Annotations  map[string]string
+	Labels       map[string]string
+	Parameters   []AlertRuleTemplateParameter
+	// Version is incremented every time the rule skeleton of the template is changed.
+	Version int64
+	Updated time.Time
+}
+
+// AlertRuleTemplateParameter is a parameter of an alert rule template. The
+// parameters without a default value have to be given a value by every rule
+// created from the template.
+type AlertRuleTemplateParameter struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// AlertRuleTemplateInstance links an alert rule to the template it was created
+// from, with the parameter values and labels the rule overrides.
+type AlertRuleTemplateInstance struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	TemplateUID string `xorm:"template_uid"`
+	// TemplateVersion is the version of the template the rule was last rendered from.
+	TemplateVersion int64  `xorm:"template_version"`
+	RuleUID         string `xorm:"rule_uid"`
+	Parameters      map[string]interface{}
+	Labels          map[string]string
+}
+
+// AlertRuleChange is a field of an alert rule changed by a newer version of its template.
+type AlertRuleChange struct {
+	Field    string
+	Current  interface{}
+	Proposed interface{}
+}
+
+// AlertRuleTemplateInstanceDiff are the changes to an alert rule if the latest
+// version of its template was applied to it.
+type AlertRuleTemplateInstanceDiff struct {
+	RuleUID         string
+	FromVersion     int64
+	TemplateVersion int64
+	Changes         []AlertRuleChange
+}
+
+// InstantiateAlertRuleTemplateCmd is the command to create an alert rule from a template.
+type InstantiateAlertRuleTemplateCmd struct {
+	OrgID        int64
+	TemplateUID  string
+	RuleUID      string
+	Title        string
+	NamespaceUID string
+	RuleGroup    string
+	Parameters   map[string]interface{}
+	Labels       map[string]string
+}
+
+type GetAlertRuleTemplateQuery struct {
+	OrgID int64
+	UID   string
+
+	Result *AlertRuleTemplate
+}
+
+type ListAlertRuleTemplatesQuery struct {
+	OrgID int64
+
+	Result []*AlertRuleTemplate
+}
+
+type GetAlertRuleTemplateInstanceQuery struct {
+	OrgID   int64
+	RuleUID string
+
+	Result *AlertRuleTemplateInstance
+}
+
+type ListAlertRuleTemplateInstancesQuery struct {
+	OrgID       int64
+	TemplateUID string
+
+	Result []*AlertRuleTemplateInstance
+}
+
+// Validate checks that the template renders to valid alert rules.
+func (t *AlertRuleTemplate) Validate() error {
+	if t.Title == "" {
+		return fmt.Errorf("%w: title must not be empty", ErrAlertRuleTemplateFailedValidation)
+	}
+	if len(t.Data) == 0 {
+		return fmt.Errorf("%w: no queries or expressions are found", ErrAlertRuleTemplateFailedValidation)
+	}
+	refIDs := make(map[string]struct{}, len(t.Data))
+	for _, q := range t.Data {
+		refIDs[q.RefID] = struct{}{}
+	}
+	if _, ok := refIDs[t.Condition]; !ok {
+		return fmt.Errorf("%w: condition %q is not one of the queries or expressions", ErrAlertRuleTemplateFailedValidation, t.Condition)
+	}
+	names := make(map[string]struct{}, len(t.Parameters))
+	for _, p := range t.Parameters {
+		if !templateParameterNameRegexp.MatchString(p.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("%w: duplicate parameter %q", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		// ${A} references the query A in math expressions
+		if _, ok := refIDs[p.Name]; ok {
+			return fmt.Errorf("%w: parameter %q has the name of a query or expression", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		names[p.Name] = struct{}{}
+	}
+	return nil
+}
+
+// SameRule returns true if both templates render the same alert rules.
+func (t *AlertRuleTemplate) SameRule(other *AlertRuleTemplate) bool {
+	return t.Condition == other.Condition &&
+		t.NoDataState == other.NoDataState &&
+		t.ExecErrState == other.ExecErrState &&
+		t.For == other.For &&
+		reflect.DeepEqual(normalizeJSON(t.Data), normalizeJSON(other.Data)) &&
+		reflect.DeepEqual(normalizeJSON(t.Annotations), normalizeJSON(other.Annotations)) &&
+		reflect.DeepEqual(normalizeJSON(t.Labels), normalizeJSON(other.Labels)) &&
+		reflect.DeepEqual(normalizeJSON(t.Parameters), normalizeJSON(other.Parameters))
+}
+
+// Render returns the alert rule of the template with the values of the
+// parameters, and the labels overriding the labels of the template. The
+// parameters without a value get their default value. The identity of the
+// rule, like its title, folder and group, is left to the caller.
+func (t *AlertRuleTemplate) Render(values map[string]interface{}, labels map[string]string) (AlertRule, error) {
+	params := make(map[string]interface{}, len(t.Parameters))
+	for _, p := range t.Parameters {
+		v, ok := values[p.Name]
+		if !ok || v == nil {
+			v = p.Default
+		}
+		if v == nil {
+			return AlertRule{}, fmt.Errorf("%w: parameter %q requires a value", ErrAlertRuleTemplateFailedValidation, p.Name)
+		}
+		params[p.Name] = v
+	}
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			return AlertRule{}, fmt.Errorf("%w: unknown parameter %q", ErrAlertRuleTemplateFailedValidation, name)
+		}
+	}
+
+	data := make([]AlertQuery, 0, len(t.Data))
+	for _, q := range t.Data {
+		model, err := renderTemplateModel(q.Model, params)
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("%w: failed to render query %s: %s", ErrAlertRuleTemplateFailedValidation, q.RefID, err)
+		}
+		data = append(data, AlertQuery{
+			RefID:             q.RefID,
+			QueryType:         q.QueryType,
+			RelativeTimeRange: q.RelativeTimeRange,
+			DatasourceUID:     q.DatasourceUID,
+			Model:             model,
+		})
+	}
+
+	var ruleLabels map[string]string
+	if len(t.Labels) > 0 || len(labels) > 0 {
+		ruleLabels = make(map[string]string, len(t.Labels)+len(labels))
+		for k, v := range t.Labels {
+			ruleLabels[k] = renderTemplateString(v, params)
+		}
+		for k, v := range labels {
+			ruleLabels[k] = v
+		}
+	}
+	var annotations map[string]string
+	if len(t.Annotations) > 0 {
+		annotations = make(map[string]string, len(t.Annotations))
+		for k, v := range t.Annotations {
+			annotations[k] = renderTemplateString(v, params)
+		}
+	}
+
+	return AlertRule{
+		OrgID:        t.OrgID,
+		Condition:    t.Condition,
+		Data:         data,
+		NoDataState:  t.NoDataState,
+		ExecErrState: t.ExecErrState,
+		For:          t.For,
+		Annotations:  annotations,
+		Labels:       ruleLabels,
+	}, nil
+}
+
+// DiffAlertRule returns the fields of the current alert rule changed by the
+// proposed one. Only the fields rendered from a template are compared.
+func DiffAlertRule(current, proposed AlertRule) []AlertRuleChange {
+	changes := make([]AlertRuleChange, 0)
+	add := func(field string, current, proposed interface{}) {
+		if !reflect.DeepEqual(normalizeJSON(current), normalizeJSON(proposed)) {
+			changes = append(changes, AlertRuleChange{Field: field, Current: current, Proposed: proposed})
+		}
+	}
+	add("condition", current.Condition, proposed.Condition)
+
+	currentQueries := make(map[string]AlertQuery, len(current.Data))
+	for _, q := range current.Data {
+		currentQueries[q.RefID] = q
+	}
+	proposedQueries := make(map[string]AlertQuery, len(proposed.Data))
+	refIDs := make([]string, 0, len(current.Data)+len(proposed.Data))
+	for _, q := range proposed.Data {
+		proposedQueries[q.RefID] = q
+		if _, ok := currentQueries[q.RefID]; !ok {
+			refIDs = append(refIDs, q.RefID)
+		}
+	}
+	for refID := range currentQueries {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+	for _, refID := range refIDs {
+		var c, p interface{}
+		if q, ok := currentQueries[refID]; ok {
+			c = q
+		}
+		if q, ok := proposedQueries[refID]; ok {
+			p = q
+		}
+		add(fmt.Sprintf("data[%s]", refID), c, p)
+	}
+
+	add("noDataState", current.NoDataState, proposed.NoDataState)
+	add("execErrState", current.ExecErrState, proposed.ExecErrState)
+	add("for", current.For.String(), proposed.For.String())
+	add("labels", withoutInternal(current.Labels, InternalLabelNameSet), withoutInternal(proposed.Labels, InternalLabelNameSet))
+	add("annotations", withoutInternal(current.Annotations, InternalAnnotationNameSet), withoutInternal(proposed.Annotations, InternalAnnotationNameSet))
+	return changes
+}
+
+func withoutInternal(values map[string]string, internal map[string]struct{}) map[string]string {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		if _, ok := internal[k]; !ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// normalizeJSON returns the value as decoded from its JSON, so that values
+// with the same JSON are equal.
+func normalizeJSON(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return v
+	}
+	switch r := result.(type) {
+	case map[string]interface{}:
+		if len(r) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(r) == 0 {
+			return nil
+		}
+	}
+	return result
+}
+
+func renderTemplateModel(model json.RawMessage, params map[string]interface{}) (json.RawMessage, error) {
+	if len(params) == 0 || len(model) == 0 {
+		return model, nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(model, &decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renderTemplateValue(decoded, params))
+}
+
+func renderTemplateValue(v interface{}, params map[string]interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = renderTemplateValue(item, params)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = renderTemplateValue(item, params)
+		}
+		return value
+	case string:
+		if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
+			if param, ok := params[value[2:len(value)-1]]; ok {
+				return param
+			}
+		}
+		return renderTemplateString(value, params)
+	}
+	return v
+}
+
+func renderTemplateString(s string, params map[string]interface{}) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	for name, value := range params {
+		s = strings.ReplaceAll(s, "${"+name+"}", formatTemplateParameter(value))
+	}
+	return s
+}
+
+func formatTemplateParameter(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testAlertRuleTemplate() AlertRuleTemplate {
+	return AlertRuleTemplate{
+		OrgID:     1,
+		UID:       "errors",
+		Title:     "High error rate",
+		Condition: "B",
+		Data: []AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: "prometheus",
+				Model:         json.RawMessage(`{"expr":"rate(errors_total{service=\"${service}\"}[5m])","refId":"A"}`),
+			},
+			{
+				RefID:         "B",
+				DatasourceUID: "-100",
+				Model:         json.RawMessage(`{"type":"threshold","expression":"A","conditions":[{"evaluator":{"params":["${threshold}"],"type":"gt"}}]}`),
+			},
+		},
+		NoDataState:  NoData,
+		ExecErrState: AlertingErrState,
+		For:          5 * time.Minute,
+		Annotations:  map[string]string{"summary": "High error rate of ${service}"},
+		Labels:       map[string]string{"severity": "critical", "service": "${service}"},
+		Parameters: []AlertRuleTemplateParameter{
+			{Name: "service"},
+			{Name: "threshold", Default: float64(5)},
+		},
+		Version: 1,
+	}
+}
+
+func TestAlertRuleTemplateValidate(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		mutate func(*AlertRuleTemplate)
+	}{
+		{desc: "empty title", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Title = "" }},
+		{desc: "no queries", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Data = nil }},
+		{desc: "unknown condition", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Condition = "C" }},
+		{desc: "invalid parameter name", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Parameters[0].Name = "the service" }},
+		{desc: "duplicate parameter", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Parameters[1].Name = "service" }},
+		{desc: "parameter named after a query", mutate: func(tmpl *AlertRuleTemplate) { tmpl.Parameters[1].Name = "A" }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tmpl := testAlertRuleTemplate()
+			tc.mutate(&tmpl)
+			require.ErrorIs(t, tmpl.Validate(), ErrAlertRuleTemplateFailedValidation)
+		})
+	}
+
+	tmpl := testAlertRuleTemplate()
+	require.NoError(t, tmpl.Validate())
+}
+
+func TestAlertRuleTemplateRender(t *testing.T) {
+	t.Run("replaces the parameters with their value", func(t *testing.T) {
+		tmpl := testAlertRuleTemplate()
+		rule, err := tmpl.Render(map[string]interface{}{"service": "checkout"}, map[string]string{"team": "payments", "severity": "warning"})
+		require.NoError(t, err)
+		require.Equal(t, "B", rule.Condition)
+		require.JSONEq(t, `{"expr":"rate(errors_total{service=\"checkout\"}[5m])","refId":"A"}`, string(rule.Data[0].Model))
+		require.JSONEq(t, `{"type":"threshold","expression":"A","conditions":[{"evaluator":{"params":[5],"type":"gt"}}]}`, string(rule.Data[1].Model))
+		require.Equal(t, map[string]string{"summary": "High error rate of checkout"}, rule.Annotations)
+		require.Equal(t, map[string]string{"severity": "warning", "service": "checkout", "team": "payments"}, rule.Labels)
+		require.Equal(t, 5*time.Minute, rule.For)
+
+		// the template is left unchanged
+		require.Contains(t, string(tmpl.Data[0].Model), "${service}")
+	})
+
+	t.Run("overrides the default values", func(t *testing.T) {
+		tmpl := testAlertRuleTemplate()
+		rule, err := tmpl.Render(map[string]interface{}{"service": "checkout", "threshold": 0.5}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"threshold","expression":"A","conditions":[{"evaluator":{"params":[0.5],"type":"gt"}}]}`, string(rule.Data[1].Model))
+	})
+
+	t.Run("fails without a value for a required parameter", func(t *testing.T) {
+		tmpl := testAlertRuleTemplate()
+		_, err := tmpl.Render(nil, nil)
+		require.ErrorIs(t, err, ErrAlertRuleTemplateFailedValidation)
+	})
+
+	t.Run("fails with an unknown parameter", func(t *testing.T) {
+		tmpl := testAlertRuleTemplate()
+		_, err := tmpl.Render(map[string]interface{}{"service": "checkout", "env": "prod"}, nil)
+		require.ErrorIs(t, err, ErrAlertRuleTemplateFailedValidation)
+	})
+}
+
+func TestAlertRuleTemplateSameRule(t *testing.T) {
+	tmpl := testAlertRuleTemplate()
+
+	other := testAlertRuleTemplate()
+	other.Title = "Errors"
+	other.Description = "The rate of errors of a service"
+	require.True(t, tmpl.SameRule(&other))
+
+	other = testAlertRuleTemplate()
+	other.Parameters[1].Default = float64(10)
+	require.False(t, tmpl.SameRule(&other))
+
+	other = testAlertRuleTemplate()
+	other.For = time.Minute
+	require.False(t, tmpl.SameRule(&other))
+}
+
+func TestDiffAlertRule(t *testing.T) {
+	tmpl := testAlertRuleTemplate()
+	current, err := tmpl.Render(map[string]interface{}{"service": "checkout"}, nil)
+	require.NoError(t, err)
+	current.Annotations[DashboardUIDAnnotation] = "dashboard"
+
+	require.Empty(t, DiffAlertRule(current, current))
+
+	tmpl.Parameters[1].Default = float64(10)
+	tmpl.Labels["severity"] = "warning"
+	tmpl.Data = append(tmpl.Data, AlertQuery{RefID: "C", DatasourceUID: "-100", Model: json.RawMessage(`{"type":"math","expression":"$A * 2"}`)})
+	proposed, err := tmpl.Render(map[string]interface{}{"service": "checkout"}, nil)
+	require.NoError(t, err)
+
+	changes := DiffAlertRule(current, proposed)
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	require.Equal(t, []string{"data[B]", "data[C]", "labels"}, fields)
+	require.Nil(t, changes[1].Current)
+	require.Equal(t, map[string]string{"severity": "critical", "service": "checkout"}, changes[2].Current)
+	require.Equal(t, map[string]string{"severity": "warning", "service": "checkout"}, changes[2].Proposed)
+}
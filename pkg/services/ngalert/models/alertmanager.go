@@ -28,3 +28,18 @@ type SaveAlertmanagerConfigurationCmd struct {
 	Default                   bool
 	OrgID                     int64
 }
+
+// GetAlertmanagerConfigurationHistoryQuery is the query to get the versions of the alertmanager configuration,
+// the most recent first.
+type GetAlertmanagerConfigurationHistoryQuery struct {
+	OrgID  int64
+	Limit  int
+	Result []*AlertConfiguration
+}
+
+// GetAlertmanagerConfigurationVersionQuery is the query to get a version of the alertmanager configuration.
+type GetAlertmanagerConfigurationVersionQuery struct {
+	OrgID  int64
+	ID     int64
+	Result *AlertConfiguration
+}
@@ -15,12 +15,18 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/inbox"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/maintenance"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/classicmigration"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -29,13 +35,16 @@ import (
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/timeregions"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
-	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service) (*AlertNG, error) {
+	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service,
+	timeRegions timeregions.Service, inboxService inbox.Service, maintenanceService maintenance.Service,
+	liveService *live.GrafanaLive) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                 cfg,
 		DataSourceCache:     dataSourceCache,
@@ -53,6 +62,10 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		accesscontrol:       ac,
 		dashboardService:    dashboardService,
 		renderService:       renderService,
+		timeRegions:         timeRegions,
+		inboxService:        inboxService,
+		maintenance:         maintenanceService,
+		live:                liveService,
 	}
 
 	if ng.IsDisabled() {
@@ -86,10 +99,18 @@ type AlertNG struct {
 	stateManager        *state.Manager
 	folderService       dashboards.FolderService
 	dashboardService    dashboards.DashboardService
+	timeRegions         timeregions.Service
+	inboxService        inbox.Service
+	maintenance         maintenance.Service
+	live                *live.GrafanaLive
+	recordedSamples     *recording.Store
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	accesscontrol        accesscontrol.AccessControl
+
+	// ClassicConditions converts alert rules using classic conditions into expressions.
+	ClassicConditions *classicmigration.Service
 }
 
 func (ng *AlertNG) init() error {
@@ -105,18 +126,24 @@ func (ng *AlertNG) init() error {
 		DashboardService: ng.dashboardService,
 	}
 
-	decryptFn := ng.SecretsService.GetDecryptedValue
-	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
-	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
+	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.Metrics.Registerer, store, ng.dashboardService, ng.renderService)
 	if err != nil {
 		return err
 	}
+	ng.imageService = imageService
+
+	// the notifications take the screenshots missing from their alerts
+	var imageCapture channels.ImageCapture
+	if ng.Cfg.UnifiedAlerting.Screenshots.Capture {
+		imageCapture = imageService
+	}
 
-	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.Metrics.Registerer, store, ng.dashboardService, ng.renderService)
+	decryptFn := ng.SecretsService.GetDecryptedValue
+	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
+	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, imageCapture, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
 	if err != nil {
 		return err
 	}
-	ng.imageService = imageService
 
 	// Let's make sure we're able to complete an initial sync of Alertmanagers before we start the alerting components.
 	if err := ng.MultiOrgAlertmanager.LoadAndSyncAlertmanagersForOrgs(context.Background()); err != nil {
@@ -138,6 +165,25 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.Cfg.UnifiedAlerting.MinInterval,
+		TimeRegions:             ng.timeRegions,
+		DeduplicateQueries:      ng.Cfg.UnifiedAlerting.DeduplicateQueries,
+	}
+	if ng.maintenance != nil {
+		schedCfg.EvaluationPausedFunc = ng.maintenance.AlertingPaused
+	}
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		ng.recordedSamples = recording.NewStore(ng.SQLStore)
+		schedCfg.SampleWriter = ng.recordedSamples
+	}
+	if ng.inboxService != nil {
+		schedCfg.RuleErrorNotifier = &inboxRuleErrorNotifier{
+			inbox:            ng.inboxService,
+			dashboardService: ng.dashboardService,
+			log:              ng.Log,
+		}
+	}
+	if ng.live != nil {
+		schedCfg.StatePublisher = &liveStatePublisher{live: ng.live, log: ng.Log}
 	}
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
@@ -160,6 +206,9 @@ func (ng *AlertNG) init() error {
 	alertRuleService := provisioning.NewAlertRuleService(store, store, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
+	alertRuleTemplateService := provisioning.NewAlertRuleTemplateService(store, alertRuleService, store, ng.Log)
+
+	ng.ClassicConditions = classicmigration.NewService(store, store, ng.accesscontrol, log.New("ngalert.classicmigration"))
 
 	api := api.API{
 		Cfg:                  ng.Cfg,
@@ -184,6 +233,7 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		AlertRuleTemplates:   alertRuleTemplateService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -205,6 +255,11 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	if ng.recordedSamples != nil {
+		children.Go(func() error {
+			return ng.recordedSamples.Run(subCtx, ng.Cfg.UnifiedAlerting.RecordingRules.Retention)
+		})
+	}
 	return children.Wait()
 }
 
@@ -131,10 +131,13 @@ type Alertmanager struct {
 	orgID           int64
 
 	decryptFn channels.GetDecryptedValueFn
+	// images takes the screenshots of the alerts notified without one, nil
+	// when screenshots aren't captured.
+	images *channels.NotificationImages
 }
 
 func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store AlertingStore, kvStore kvstore.KVStore,
-	peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, ns notifications.Service, m *metrics.Alertmanager) (*Alertmanager, error) {
+	peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, ns notifications.Service, imageCapture channels.ImageCapture, m *metrics.Alertmanager) (*Alertmanager, error) {
 	am := &Alertmanager{
 		Settings:            cfg,
 		stopc:               make(chan struct{}),
@@ -151,6 +154,10 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 	}
 
+	if imageCapture != nil {
+		am.images = channels.NewNotificationImages(imageCapture, am.logger)
+	}
+
 	am.fileStore = NewFileStore(am.orgID, kvStore, am.WorkingDirPath())
 
 	nflogFilepath, err := am.fileStore.FilepathFor(ctx, notificationLogFilename)
@@ -525,7 +532,7 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 			Err:      err,
 		}
 	}
-	return n, nil
+	return channels.WithEgressPolicy(channels.WithImages(n, am.images, factoryConfig.ImageMode), am.orgID), nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -36,7 +37,98 @@ func (moa *MultiOrgAlertmanager) GetAlertmanagerConfiguration(ctx context.Contex
 	if err != nil {
 		return definitions.GettableUserConfig{}, fmt.Errorf("failed to get latest configuration: %w", err)
 	}
+	result, err := moa.gettableUserConfigFromRaw([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return definitions.GettableUserConfig{}, err
+	}
+
+	result, err = moa.mergeProvenance(ctx, result, org)
+	if err != nil {
+		return definitions.GettableUserConfig{}, err
+	}
+
+	return result, nil
+}
+
+// GetAlertmanagerConfigurationHistory returns the versions of the configuration, the most recent first.
+// The provenance of the objects is not returned, as it is only known for the latest version.
+func (moa *MultiOrgAlertmanager) GetAlertmanagerConfigurationHistory(ctx context.Context, org int64, limit int) ([]definitions.GettableHistoricUserConfig, error) {
+	query := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: org, Limit: limit}
+	if err := moa.configStore.GetAlertmanagerConfigurationHistory(ctx, &query); err != nil {
+		return nil, fmt.Errorf("failed to get configuration history: %w", err)
+	}
+
+	result := make([]definitions.GettableHistoricUserConfig, 0, len(query.Result))
+	for _, version := range query.Result {
+		cfg, err := moa.gettableUserConfigFromRaw([]byte(version.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, definitions.GettableHistoricUserConfig{
+			ID:                 version.ID,
+			CreatedAt:          time.Unix(version.CreatedAt, 0).UTC(),
+			Default:            version.Default,
+			TemplateFiles:      cfg.TemplateFiles,
+			AlertmanagerConfig: cfg.AlertmanagerConfig,
+		})
+	}
+	return result, nil
+}
+
+// ActivateHistoricalConfiguration saves a previous version of the configuration as the latest version and
+// applies it. The secure settings of the version are kept. The validate function is called with the version,
+// without its secure settings, before it is saved.
+func (moa *MultiOrgAlertmanager) ActivateHistoricalConfiguration(ctx context.Context, org int64, id int64, validate func(definitions.PostableUserConfig) error) error {
+	query := models.GetAlertmanagerConfigurationVersionQuery{OrgID: org, ID: id}
+	if err := moa.configStore.GetAlertmanagerConfigurationVersion(ctx, &query); err != nil {
+		return err
+	}
 	cfg, err := Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal alertmanager configuration: %w", err)
+	}
+
+	if err := validate(withoutSecureSettings(*cfg)); err != nil {
+		return err
+	}
+
+	am, err := moa.AlertmanagerFor(org)
+	if err != nil {
+		// It's okay if the alertmanager isn't ready yet, we're changing its config anyway.
+		if !errors.Is(err, ErrAlertmanagerNotReady) {
+			return err
+		}
+	}
+
+	// The secure settings of the version are already encrypted.
+	if err := am.SaveAndApplyConfig(ctx, cfg); err != nil {
+		moa.logger.Error("unable to save and apply historical alertmanager configuration", "err", err, "id", id)
+		return AlertmanagerConfigRejectedError{err}
+	}
+
+	return nil
+}
+
+// withoutSecureSettings returns a copy of the configuration where the secure settings of the receivers are removed.
+func withoutSecureSettings(cfg definitions.PostableUserConfig) definitions.PostableUserConfig {
+	receivers := make([]*definitions.PostableApiReceiver, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receiver := *r
+		grafanaReceivers := make([]*definitions.PostableGrafanaReceiver, 0, len(r.GrafanaManagedReceivers))
+		for _, gr := range r.GrafanaManagedReceivers {
+			grafanaReceiver := *gr
+			grafanaReceiver.SecureSettings = nil
+			grafanaReceivers = append(grafanaReceivers, &grafanaReceiver)
+		}
+		receiver.GrafanaManagedReceivers = grafanaReceivers
+		receivers = append(receivers, &receiver)
+	}
+	cfg.AlertmanagerConfig.Receivers = receivers
+	return cfg
+}
+
+func (moa *MultiOrgAlertmanager) gettableUserConfigFromRaw(raw []byte) (definitions.GettableUserConfig, error) {
+	cfg, err := Load(raw)
 	if err != nil {
 		return definitions.GettableUserConfig{}, fmt.Errorf("failed to unmarshal alertmanager configuration: %w", err)
 	}
@@ -81,11 +173,6 @@ func (moa *MultiOrgAlertmanager) GetAlertmanagerConfiguration(ctx context.Contex
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
 	}
 
-	result, err = moa.mergeProvenance(ctx, result, org)
-	if err != nil {
-		return definitions.GettableUserConfig{}, err
-	}
-
 	return result, nil
 }
 
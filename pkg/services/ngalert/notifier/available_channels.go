@@ -104,6 +104,27 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 		},
 	}
 
+	imagesOption := alerting.NotifierOption{
+		Label:   "Images",
+		Element: alerting.ElementTypeSelect,
+		SelectOptions: []alerting.SelectOption{
+			{
+				Value: channels.ImageModeAuto,
+				Label: "Link uploaded images, attach the others",
+			},
+			{
+				Value: channels.ImageModeLink,
+				Label: "Only link uploaded images",
+			},
+			{
+				Value: channels.ImageModeNone,
+				Label: "No images",
+			},
+		},
+		Description:  "How the screenshots of the alerts are included in the notifications",
+		PropertyName: "images",
+	}
+
 	return []*alerting.NotifierPlugin{
 		{
 			Type:        "dingding",
@@ -198,6 +219,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "subject",
 					Placeholder:  `{{ template "default.title" . }}`,
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -267,6 +289,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "summary",
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -496,6 +519,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "text",
 					Placeholder:  `{{ template "slack.default.text" . }}`,
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -591,6 +615,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "message",
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -672,6 +697,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -761,6 +787,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Element:      alerting.ElementTypeCheckbox,
 					PropertyName: "use_discord_username",
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -783,6 +810,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "message",
 				},
+				imagesOption,
 			},
 		},
 		{
@@ -908,6 +936,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Description:  "Send the common annotations to Opsgenie as either Extra Properties, Tags or both",
 					PropertyName: "sendTagsAs",
 				},
+				imagesOption,
 			},
 		},
 	}
@@ -25,6 +25,7 @@ type EmailNotifier struct {
 	SingleEmail bool
 	Message     string
 	Subject     string
+	orgID       int64
 	log         log.Logger
 	ns          notifications.EmailSender
 	images      ImageStore
@@ -81,6 +82,7 @@ func NewEmailNotifier(config *EmailConfig, ns notifications.EmailSender, images
 		SingleEmail: config.SingleEmail,
 		Message:     config.Message,
 		Subject:     config.Subject,
+		orgID:       config.OrgID,
 		log:         log.New("alerting.notifier.email"),
 		ns:          ns,
 		images:      images,
@@ -146,6 +148,7 @@ func (en *EmailNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bo
 			To:            en.Addresses,
 			SingleEmail:   en.SingleEmail,
 			Template:      "ng_alert_notification",
+			OrgId:         en.orgID,
 		},
 	}
 
@@ -284,7 +284,7 @@ func createCoreEmailService(t *testing.T) *notifications.NotificationService {
 	cfg.Smtp.Host = "localhost:1234"
 	mailer := notifications.NewFakeMailer()
 
-	ns, err := notifications.ProvideService(bus, cfg, mailer, nil)
+	ns, err := notifications.ProvideService(bus, cfg, mailer, nil, nil, nil)
 	require.NoError(t, err)
 
 	return ns
@@ -17,6 +17,9 @@ type FactoryConfig struct {
 	ImageStore          ImageStore
 	// Used to retrieve image URLs for messages, or data for uploads.
 	Template *template.Template
+	// ImageMode is the images setting of the contact point, the image store
+	// only returns the images the notifier should include.
+	ImageMode string
 }
 
 type ImageStore interface {
@@ -34,6 +37,10 @@ func NewFactoryConfig(config *NotificationChannelConfig, notificationService not
 		config.SecureSettings = map[string][]byte{}
 	}
 
+	imageMode, err := parseImageMode(config.Settings.Get("images").MustString())
+	if err != nil {
+		return FactoryConfig{}, err
+	}
 	if imageStore == nil {
 		imageStore = &UnavailableImageStore{}
 	}
@@ -42,7 +49,8 @@ func NewFactoryConfig(config *NotificationChannelConfig, notificationService not
 		NotificationService: notificationService,
 		DecryptFunc:         decryptFunc,
 		Template:            template,
-		ImageStore:          imageStore,
+		ImageStore:          imageStoreForMode(imageStore, imageMode),
+		ImageMode:           imageMode,
 	}, nil
 }
 
@@ -0,0 +1,202 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// The images setting of a contact point controls how its notifications include
+// the screenshots of the alerts.
+const (
+	// ImageModeAuto links the images uploaded to an external storage, and
+	// attaches the others when the notifier can.
+	ImageModeAuto = "auto"
+	// ImageModeLink only links the images uploaded to an external storage.
+	ImageModeLink = "link"
+	// ImageModeNone leaves the images out of the notifications.
+	ImageModeNone = "none"
+)
+
+const (
+	// NotificationScreenshotTimeout is how long the screenshot of an alert is
+	// waited for before notifying without it.
+	NotificationScreenshotTimeout = 10 * time.Second
+
+	// notificationImageCacheTTL is how long the image of a panel is reused for
+	// the notifications of its alerts. It's shorter than the expiration of the
+	// images in the store.
+	notificationImageCacheTTL = 30 * time.Second
+)
+
+// ImageCapture takes the screenshot of the panel of an alert rule.
+type ImageCapture interface {
+	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
+}
+
+// parseImageMode returns the images setting of a contact point, ImageModeAuto
+// by default.
+func parseImageMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ImageModeAuto, nil
+	case ImageModeAuto, ImageModeLink, ImageModeNone:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid images setting %q, must be one of %q, %q or %q", mode, ImageModeAuto, ImageModeLink, ImageModeNone)
+}
+
+// imageStoreForMode returns the image store the notifiers of a contact point
+// get their images from.
+func imageStoreForMode(store ImageStore, mode string) ImageStore {
+	switch mode {
+	case ImageModeNone:
+		return &UnavailableImageStore{}
+	case ImageModeLink:
+		return &linkImageStore{store: store}
+	}
+	return store
+}
+
+// linkImageStore hides the path of the images, so that the notifiers only link
+// the images uploaded to an external storage.
+type linkImageStore struct {
+	store ImageStore
+}
+
+func (s *linkImageStore) GetImage(ctx context.Context, token string) (*models.Image, error) {
+	img, err := s.store.GetImage(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if img.URL == "" {
+		return nil, models.ErrImageNotFound
+	}
+	linked := *img
+	linked.Path = ""
+	return &linked, nil
+}
+
+// NotificationImages takes the screenshots of the alerts notified without one,
+// like the alerts whose screenshot failed or timed out when they were
+// evaluated. The image of a panel is shared by the notifications of its alerts
+// for a while.
+type NotificationImages struct {
+	capture ImageCapture
+	log     log.Logger
+	timeout time.Duration
+
+	group singleflight.Group
+	mtx   sync.Mutex
+	cache map[string]cachedImage
+}
+
+type cachedImage struct {
+	token   string
+	expires time.Time
+}
+
+func NewNotificationImages(capture ImageCapture, l log.Logger) *NotificationImages {
+	return &NotificationImages{
+		capture: capture,
+		log:     l,
+		timeout: NotificationScreenshotTimeout,
+		cache:   map[string]cachedImage{},
+	}
+}
+
+// WithImages returns the notifier taking the screenshots of the alerts before
+// notifying them, unless the contact point leaves the images out.
+func WithImages(n NotificationChannel, images *NotificationImages, mode string) NotificationChannel {
+	if images == nil || mode == ImageModeNone {
+		return n
+	}
+	return &imageNotifier{NotificationChannel: n, images: images}
+}
+
+type imageNotifier struct {
+	NotificationChannel
+	images *NotificationImages
+}
+
+func (n *imageNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	return n.NotificationChannel.Notify(ctx, n.images.addImages(ctx, alerts)...)
+}
+
+// addImages returns the alerts with the token of their screenshot. The alerts
+// are shared by the contact points, so the alerts getting a screenshot are
+// copied.
+func (n *NotificationImages) addImages(ctx context.Context, alerts []*types.Alert) []*types.Alert {
+	result := make([]*types.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if getTokenFromAnnotations(alert.Annotations) != "" {
+			result = append(result, alert)
+			continue
+		}
+
+		dashboardUID := string(alert.Annotations[models.DashboardUIDAnnotation])
+		panelID, err := strconv.ParseInt(string(alert.Annotations[models.PanelIDAnnotation]), 10, 64)
+		if dashboardUID == "" || err != nil || panelID == 0 {
+			result = append(result, alert)
+			continue
+		}
+
+		token, err := n.getToken(ctx, dashboardUID, panelID)
+		if err != nil {
+			n.log.Warn("failed to take the screenshot of the alert", "alert", alert.Name(), "dashboard", dashboardUID, "panel", panelID, "err", err)
+			result = append(result, alert)
+			continue
+		}
+
+		withImage := *alert
+		withImage.Annotations = alert.Annotations.Clone()
+		withImage.Annotations[models.ScreenshotTokenAnnotation] = model.LabelValue(token)
+		result = append(result, &withImage)
+	}
+	return result
+}
+
+func (n *NotificationImages) getToken(ctx context.Context, dashboardUID string, panelID int64) (string, error) {
+	key := fmt.Sprintf("%s/%d", dashboardUID, panelID)
+
+	n.mtx.Lock()
+	cached, ok := n.cache[key]
+	n.mtx.Unlock()
+	if ok && timeNow().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	token, err, _ := n.group.Do(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+
+		img, err := n.capture.NewImage(ctx, &models.AlertRule{DashboardUID: &dashboardUID, PanelID: &panelID})
+		if err != nil {
+			return "", err
+		}
+
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+		now := timeNow()
+		for k, cached := range n.cache {
+			if !now.Before(cached.expires) {
+				delete(n.cache, k)
+			}
+		}
+		n.cache[key] = cachedImage{token: img.Token, expires: now.Add(notificationImageCacheTTL)}
+		return img.Token, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
@@ -0,0 +1,155 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeImageCapture struct {
+	calls int
+	err   error
+}
+
+func (f *fakeImageCapture) NewImage(ctx context.Context, r *ngmodels.AlertRule) (*ngmodels.Image, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.calls++
+	return &ngmodels.Image{Token: fmt.Sprintf("%s-%d-%d", *r.DashboardUID, *r.PanelID, f.calls)}, nil
+}
+
+type recordingNotifier struct {
+	alerts []*types.Alert
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	r.alerts = alerts
+	return false, nil
+}
+
+func (r *recordingNotifier) SendResolved() bool {
+	return true
+}
+
+func TestNewFactoryConfig_Images(t *testing.T) {
+	images := &fakeImageStore{Images: []*ngmodels.Image{
+		{Token: "uploaded", URL: "https://www.example.com/image.png", Path: "/tmp/image.png"},
+		{Token: "local", Path: "/tmp/local.png"},
+	}}
+	newConfig := func(t *testing.T, settings string) (FactoryConfig, error) {
+		t.Helper()
+		s, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewFactoryConfig(&NotificationChannelConfig{Type: "webhook", Settings: s}, nil, nil, nil, images)
+	}
+
+	t.Run("links and attaches the images by default", func(t *testing.T) {
+		cfg, err := newConfig(t, `{}`)
+		require.NoError(t, err)
+		require.Equal(t, ImageModeAuto, cfg.ImageMode)
+
+		img, err := cfg.ImageStore.GetImage(context.Background(), "local")
+		require.NoError(t, err)
+		require.Equal(t, "/tmp/local.png", img.Path)
+	})
+
+	t.Run("only returns the uploaded images to link", func(t *testing.T) {
+		cfg, err := newConfig(t, `{"images": "link"}`)
+		require.NoError(t, err)
+		require.Equal(t, ImageModeLink, cfg.ImageMode)
+
+		img, err := cfg.ImageStore.GetImage(context.Background(), "uploaded")
+		require.NoError(t, err)
+		require.Equal(t, "https://www.example.com/image.png", img.URL)
+		require.Empty(t, img.Path)
+		require.Equal(t, "/tmp/image.png", images.Images[0].Path)
+
+		_, err = cfg.ImageStore.GetImage(context.Background(), "local")
+		require.ErrorIs(t, err, ngmodels.ErrImageNotFound)
+	})
+
+	t.Run("doesn't return images when they are left out", func(t *testing.T) {
+		cfg, err := newConfig(t, `{"images": "none"}`)
+		require.NoError(t, err)
+
+		_, err = cfg.ImageStore.GetImage(context.Background(), "uploaded")
+		require.ErrorIs(t, err, ErrImagesUnavailable)
+	})
+
+	t.Run("rejects unknown modes", func(t *testing.T) {
+		_, err := newConfig(t, `{"images": "embed"}`)
+		require.Error(t, err)
+	})
+}
+
+func TestNotificationImages(t *testing.T) {
+	now := time.Now()
+	defer mockTimeNow(now)()
+
+	newAlert := func(annotations model.LabelSet) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert"}, Annotations: annotations}}
+	}
+	panelAnnotations := func() model.LabelSet {
+		return model.LabelSet{
+			ngmodels.DashboardUIDAnnotation: "dash",
+			ngmodels.PanelIDAnnotation:      "2",
+		}
+	}
+
+	t.Run("takes the missing screenshots once per panel", func(t *testing.T) {
+		capture := &fakeImageCapture{}
+		recorder := &recordingNotifier{}
+		n := WithImages(recorder, NewNotificationImages(capture, log.NewNopLogger()), ImageModeAuto)
+
+		withToken := newAlert(model.LabelSet{ngmodels.ScreenshotTokenAnnotation: "existing"})
+		withPanel := newAlert(panelAnnotations())
+		withoutPanel := newAlert(model.LabelSet{})
+		samePanel := newAlert(panelAnnotations())
+
+		_, err := n.Notify(context.Background(), withToken, withPanel, withoutPanel, samePanel)
+		require.NoError(t, err)
+		require.Equal(t, 1, capture.calls)
+		require.Len(t, recorder.alerts, 4)
+		require.Same(t, withToken, recorder.alerts[0])
+		require.Equal(t, model.LabelValue("dash-2-1"), recorder.alerts[1].Annotations[ngmodels.ScreenshotTokenAnnotation])
+		require.Same(t, withoutPanel, recorder.alerts[2])
+		require.Equal(t, model.LabelValue("dash-2-1"), recorder.alerts[3].Annotations[ngmodels.ScreenshotTokenAnnotation])
+
+		// the alerts are shared by the contact points
+		require.NotContains(t, withPanel.Annotations, ngmodels.ScreenshotTokenAnnotation)
+
+		defer mockTimeNow(now.Add(notificationImageCacheTTL))()
+		_, err = n.Notify(context.Background(), withPanel)
+		require.NoError(t, err)
+		require.Equal(t, 2, capture.calls)
+		require.Equal(t, model.LabelValue("dash-2-2"), recorder.alerts[0].Annotations[ngmodels.ScreenshotTokenAnnotation])
+	})
+
+	t.Run("notifies without the screenshots that fail", func(t *testing.T) {
+		capture := &fakeImageCapture{err: errors.New("failed to render")}
+		recorder := &recordingNotifier{}
+		n := WithImages(recorder, NewNotificationImages(capture, log.NewNopLogger()), ImageModeLink)
+
+		alert := newAlert(panelAnnotations())
+		_, err := n.Notify(context.Background(), alert)
+		require.NoError(t, err)
+		require.Equal(t, []*types.Alert{alert}, recorder.alerts)
+	})
+
+	t.Run("doesn't take screenshots when the images are left out", func(t *testing.T) {
+		recorder := &recordingNotifier{}
+		require.Same(t, recorder, WithImages(recorder, NewNotificationImages(&fakeImageCapture{}, log.NewNopLogger()), ImageModeNone))
+		require.Same(t, recorder, WithImages(recorder, nil, ImageModeAuto))
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -237,10 +238,10 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) (retErr er
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
 		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
+		Proxy: httpclient.EgressProxy(http.ProxyFromEnvironment),
+		DialContext: httpclient.EgressDialContext((&net.Dialer{
 			Timeout: 30 * time.Second,
-		}).DialContext,
+		}).DialContext),
 		TLSHandshakeTimeout: 5 * time.Second,
 	}
 	netClient := &http.Client{
@@ -19,6 +19,7 @@ import (
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
@@ -92,6 +93,7 @@ func withStoredImage(ctx context.Context, l log.Logger, imageStore ImageStore, i
 
 // The path argument here comes from reading internal image storage, not user
 // input, so we ignore the security check here.
+//
 //nolint:gosec
 func openImage(path string) (io.ReadCloser, error) {
 	fp := filepath.Clean(path)
@@ -155,6 +157,22 @@ type NotificationChannel interface {
 	notify.Notifier
 	notify.ResolvedSender
 }
+
+// WithEgressPolicy returns the notifier connecting to its hosts with the egress
+// policy of the organization.
+func WithEgressPolicy(n NotificationChannel, orgID int64) NotificationChannel {
+	return &egressNotifier{NotificationChannel: n, orgID: orgID}
+}
+
+type egressNotifier struct {
+	NotificationChannel
+	orgID int64
+}
+
+func (n *egressNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	return n.NotificationChannel.Notify(httpclient.WithEgressOrgID(ctx, n.orgID), alerts...)
+}
+
 type NotificationChannelConfig struct {
 	OrgID                 int64             // only used internally
 	UID                   string            `json:"uid"`
@@ -192,10 +210,10 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
 		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
+		Proxy: httpclient.EgressProxy(http.ProxyFromEnvironment),
+		DialContext: httpclient.EgressDialContext((&net.Dialer{
 			Timeout: 30 * time.Second,
-		}).DialContext,
+		}).DialContext),
 		TLSHandshakeTimeout: 5 * time.Second,
 	}
 	netClient := &http.Client{
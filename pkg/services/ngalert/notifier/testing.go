@@ -57,6 +57,23 @@ func (f *FakeConfigStore) GetLatestAlertmanagerConfiguration(_ context.Context,
 	return nil
 }
 
+func (f *FakeConfigStore) GetAlertmanagerConfigurationHistory(_ context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	query.Result = []*models.AlertConfiguration{}
+	if config, ok := f.configs[query.OrgID]; ok {
+		query.Result = append(query.Result, config)
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) GetAlertmanagerConfigurationVersion(_ context.Context, query *models.GetAlertmanagerConfigurationVersionQuery) error {
+	config, ok := f.configs[query.OrgID]
+	if !ok || config.ID != query.ID {
+		return store.ErrNoAlertmanagerConfiguration
+	}
+	query.Result = config
+	return nil
+}
+
 func (f *FakeConfigStore) SaveAlertmanagerConfiguration(_ context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error {
 	f.configs[cmd.OrgID] = &models.AlertConfiguration{
 		AlertmanagerConfiguration: cmd.AlertmanagerConfiguration,
@@ -0,0 +1,246 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertRuleTemplateService manages the alert rule templates and the alert
+// rules created from them. A change to the rule skeleton of a template is not
+// applied to its alert rules until it is approved for every rule.
+type AlertRuleTemplateService struct {
+	templateStore AlertRuleTemplateStore
+	rules         *AlertRuleService
+	xact          TransactionManager
+	log           log.Logger
+}
+
+func NewAlertRuleTemplateService(templateStore AlertRuleTemplateStore,
+	rules *AlertRuleService,
+	xact TransactionManager,
+	log log.Logger) *AlertRuleTemplateService {
+	return &AlertRuleTemplateService{
+		templateStore: templateStore,
+		rules:         rules,
+		xact:          xact,
+		log:           log,
+	}
+}
+
+func (service *AlertRuleTemplateService) GetAlertRuleTemplates(ctx context.Context, orgID int64) ([]models.AlertRuleTemplate, error) {
+	query := &models.ListAlertRuleTemplatesQuery{OrgID: orgID}
+	if err := service.templateStore.ListAlertRuleTemplates(ctx, query); err != nil {
+		return nil, err
+	}
+	result := make([]models.AlertRuleTemplate, 0, len(query.Result))
+	for _, tmpl := range query.Result {
+		result = append(result, *tmpl)
+	}
+	return result, nil
+}
+
+func (service *AlertRuleTemplateService) GetAlertRuleTemplate(ctx context.Context, orgID int64, uid string) (models.AlertRuleTemplate, error) {
+	query := &models.GetAlertRuleTemplateQuery{OrgID: orgID, UID: uid}
+	if err := service.templateStore.GetAlertRuleTemplate(ctx, query); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	return *query.Result, nil
+}
+
+// CreateAlertRuleTemplate creates a new alert rule template at version 1.
+func (service *AlertRuleTemplateService) CreateAlertRuleTemplate(ctx context.Context, tmpl models.AlertRuleTemplate) (models.AlertRuleTemplate, error) {
+	if err := tmpl.Validate(); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	tmpl.ID = 0
+	tmpl.Version = 1
+	tmpl.Updated = time.Now()
+	if err := service.templateStore.InsertAlertRuleTemplate(ctx, &tmpl); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// UpdateAlertRuleTemplate updates an alert rule template. Its version is
+// incremented when the rule skeleton is changed, which makes the change
+// pending for the alert rules created from the template.
+func (service *AlertRuleTemplateService) UpdateAlertRuleTemplate(ctx context.Context, tmpl models.AlertRuleTemplate) (models.AlertRuleTemplate, error) {
+	if err := tmpl.Validate(); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	stored, err := service.GetAlertRuleTemplate(ctx, tmpl.OrgID, tmpl.UID)
+	if err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	tmpl.ID = stored.ID
+	tmpl.Version = stored.Version
+	if !tmpl.SameRule(&stored) {
+		tmpl.Version++
+	}
+	tmpl.Updated = time.Now()
+	if err := service.templateStore.UpdateAlertRuleTemplate(ctx, &tmpl); err != nil {
+		return models.AlertRuleTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// DeleteAlertRuleTemplate deletes an alert rule template. The alert rules
+// created from the template are kept.
+func (service *AlertRuleTemplateService) DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error {
+	return service.templateStore.DeleteAlertRuleTemplate(ctx, orgID, uid)
+}
+
+// InstantiateAlertRuleTemplate creates an alert rule from the latest version of
+// a template, with the given parameter values and labels overriding the ones
+// of the template.
+func (service *AlertRuleTemplateService) InstantiateAlertRuleTemplate(ctx context.Context, cmd models.InstantiateAlertRuleTemplateCmd) (models.AlertRuleTemplateInstance, error) {
+	tmpl, err := service.GetAlertRuleTemplate(ctx, cmd.OrgID, cmd.TemplateUID)
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	rule, err := tmpl.Render(cmd.Parameters, cmd.Labels)
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	rule.UID = cmd.RuleUID
+	rule.Title = cmd.Title
+	rule.NamespaceUID = cmd.NamespaceUID
+	rule.RuleGroup = cmd.RuleGroup
+
+	instance := models.AlertRuleTemplateInstance{
+		OrgID:           cmd.OrgID,
+		TemplateUID:     tmpl.UID,
+		TemplateVersion: tmpl.Version,
+		Parameters:      cmd.Parameters,
+		Labels:          cmd.Labels,
+	}
+	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		created, err := service.rules.CreateAlertRule(ctx, rule, models.ProvenanceAPI)
+		if err != nil {
+			return err
+		}
+		instance.RuleUID = created.UID
+		return service.templateStore.SaveAlertRuleTemplateInstance(ctx, &instance)
+	})
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	return instance, nil
+}
+
+// GetAlertRuleTemplateInstances returns the alert rules created from a template.
+func (service *AlertRuleTemplateService) GetAlertRuleTemplateInstances(ctx context.Context, orgID int64, templateUID string) ([]models.AlertRuleTemplateInstance, error) {
+	query := &models.ListAlertRuleTemplateInstancesQuery{OrgID: orgID, TemplateUID: templateUID}
+	if err := service.templateStore.ListAlertRuleTemplateInstances(ctx, query); err != nil {
+		return nil, err
+	}
+	result := make([]models.AlertRuleTemplateInstance, 0, len(query.Result))
+	for _, instance := range query.Result {
+		result = append(result, *instance)
+	}
+	return result, nil
+}
+
+// DiffAlertRuleTemplateInstance returns the changes to an alert rule created
+// from a template if the latest version of the template was applied to it.
+func (service *AlertRuleTemplateService) DiffAlertRuleTemplateInstance(ctx context.Context, orgID int64, templateUID, ruleUID string) (models.AlertRuleTemplateInstanceDiff, error) {
+	tmpl, instance, err := service.getInstance(ctx, orgID, templateUID, ruleUID)
+	if err != nil {
+		return models.AlertRuleTemplateInstanceDiff{}, err
+	}
+	current, proposed, err := service.renderInstance(ctx, tmpl, instance)
+	if err != nil {
+		return models.AlertRuleTemplateInstanceDiff{}, err
+	}
+	return models.AlertRuleTemplateInstanceDiff{
+		RuleUID:         instance.RuleUID,
+		FromVersion:     instance.TemplateVersion,
+		TemplateVersion: tmpl.Version,
+		Changes:         models.DiffAlertRule(current, proposed),
+	}, nil
+}
+
+// ApplyAlertRuleTemplateInstance applies the latest version of a template to an
+// alert rule created from it. The version is the version of the template the
+// changes were reviewed for, it returns ErrAlertRuleTemplateVersionMismatch if
+// the template was changed since then.
+func (service *AlertRuleTemplateService) ApplyAlertRuleTemplateInstance(ctx context.Context, orgID int64, templateUID, ruleUID string, version int64) (models.AlertRuleTemplateInstance, error) {
+	tmpl, instance, err := service.getInstance(ctx, orgID, templateUID, ruleUID)
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	if version != tmpl.Version {
+		return models.AlertRuleTemplateInstance{}, fmt.Errorf("%w: the changes were reviewed for version %d, the template is at version %d", models.ErrAlertRuleTemplateVersionMismatch, version, tmpl.Version)
+	}
+	_, proposed, err := service.renderInstance(ctx, tmpl, instance)
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	instance.TemplateVersion = tmpl.Version
+	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := service.rules.UpdateAlertRule(ctx, proposed, models.ProvenanceAPI); err != nil {
+			return err
+		}
+		return service.templateStore.SaveAlertRuleTemplateInstance(ctx, &instance)
+	})
+	if err != nil {
+		return models.AlertRuleTemplateInstance{}, err
+	}
+	return instance, nil
+}
+
+func (service *AlertRuleTemplateService) getInstance(ctx context.Context, orgID int64, templateUID, ruleUID string) (models.AlertRuleTemplate, models.AlertRuleTemplateInstance, error) {
+	tmpl, err := service.GetAlertRuleTemplate(ctx, orgID, templateUID)
+	if err != nil {
+		return models.AlertRuleTemplate{}, models.AlertRuleTemplateInstance{}, err
+	}
+	query := &models.GetAlertRuleTemplateInstanceQuery{OrgID: orgID, RuleUID: ruleUID}
+	if err := service.templateStore.GetAlertRuleTemplateInstance(ctx, query); err != nil {
+		return models.AlertRuleTemplate{}, models.AlertRuleTemplateInstance{}, err
+	}
+	if query.Result.TemplateUID != templateUID {
+		return models.AlertRuleTemplate{}, models.AlertRuleTemplateInstance{}, models.ErrAlertRuleTemplateInstanceNotFound
+	}
+	return tmpl, *query.Result, nil
+}
+
+// renderInstance returns the current alert rule of an instance and the rule
+// rendered from the latest version of the template, keeping the identity of the
+// rule and the internal annotations.
+func (service *AlertRuleTemplateService) renderInstance(ctx context.Context, tmpl models.AlertRuleTemplate, instance models.AlertRuleTemplateInstance) (models.AlertRule, models.AlertRule, error) {
+	current, _, err := service.rules.GetAlertRule(ctx, instance.OrgID, instance.RuleUID)
+	if err != nil {
+		return models.AlertRule{}, models.AlertRule{}, err
+	}
+	proposed, err := tmpl.Render(instance.Parameters, instance.Labels)
+	if err != nil {
+		return models.AlertRule{}, models.AlertRule{}, err
+	}
+	proposed.UID = current.UID
+	proposed.OrgID = current.OrgID
+	proposed.Title = current.Title
+	proposed.NamespaceUID = current.NamespaceUID
+	proposed.RuleGroup = current.RuleGroup
+	proposed.DashboardUID = current.DashboardUID
+	proposed.PanelID = current.PanelID
+	proposed.Record = current.Record
+	for k, v := range current.Annotations {
+		if _, ok := models.InternalAnnotationNameSet[k]; !ok {
+			continue
+		}
+		if proposed.Annotations == nil {
+			proposed.Annotations = map[string]string{}
+		}
+		proposed.Annotations[k] = v
+	}
+	// the stored queries have their defaults set, set them on the rendered
+	// queries too so that they are not reported as changes
+	if err := proposed.PreSave(time.Now); err != nil {
+		return models.AlertRule{}, models.AlertRule{}, fmt.Errorf("%w: %s", models.ErrAlertRuleTemplateFailedValidation, err.Error())
+	}
+	return current, proposed, nil
+}
@@ -0,0 +1,234 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleTemplateService(t *testing.T) {
+	templateService := createAlertRuleTemplateService(t)
+	ctx := context.Background()
+
+	t.Run("templates should be created at version 1 and listed by title", func(t *testing.T) {
+		tmpl, err := templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("errors", 1))
+		require.NoError(t, err)
+		require.NotEmpty(t, tmpl.UID)
+		require.Equal(t, int64(1), tmpl.Version)
+
+		_, err = templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("cpu", 1))
+		require.NoError(t, err)
+		_, err = templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("latency", 2))
+		require.NoError(t, err)
+
+		templates, err := templateService.GetAlertRuleTemplates(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		require.Equal(t, "cpu", templates[0].Title)
+		require.Equal(t, "errors", templates[1].Title)
+	})
+
+	t.Run("invalid templates should be rejected", func(t *testing.T) {
+		tmpl := dummyTemplate("invalid", 1)
+		tmpl.Condition = "Z"
+		_, err := templateService.CreateAlertRuleTemplate(ctx, tmpl)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateFailedValidation)
+	})
+
+	t.Run("version should only be incremented when the rule skeleton changes", func(t *testing.T) {
+		tmpl, err := templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("versioned", 1))
+		require.NoError(t, err)
+
+		tmpl.Description = "only the description changes"
+		tmpl, err = templateService.UpdateAlertRuleTemplate(ctx, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), tmpl.Version)
+
+		tmpl.For = 5 * time.Minute
+		tmpl, err = templateService.UpdateAlertRuleTemplate(ctx, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), tmpl.Version)
+
+		stored, err := templateService.GetAlertRuleTemplate(ctx, 1, tmpl.UID)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), stored.Version)
+		require.Equal(t, "only the description changes", stored.Description)
+	})
+
+	t.Run("updating a missing template should fail", func(t *testing.T) {
+		tmpl := dummyTemplate("missing", 1)
+		tmpl.UID = "missing"
+		_, err := templateService.UpdateAlertRuleTemplate(ctx, tmpl)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+	})
+
+	t.Run("changes should be propagated to the instances once approved", func(t *testing.T) {
+		tmpl, err := templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("propagated", 1))
+		require.NoError(t, err)
+
+		instance, err := templateService.InstantiateAlertRuleTemplate(ctx, models.InstantiateAlertRuleTemplateCmd{
+			OrgID:        1,
+			TemplateUID:  tmpl.UID,
+			Title:        "checkout errors",
+			NamespaceUID: "folder",
+			RuleGroup:    "checkout",
+			Parameters:   map[string]interface{}{"service": "checkout"},
+			Labels:       map[string]string{"team": "payments"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), instance.TemplateVersion)
+
+		rule, provenance, err := templateService.rules.GetAlertRule(ctx, 1, instance.RuleUID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceAPI, provenance)
+		require.Equal(t, "checkout errors", rule.Title)
+		require.Equal(t, map[string]string{"service": "checkout", "team": "payments"}, rule.Labels)
+		require.JSONEq(t, `{"service":"checkout","threshold":5,"intervalMs":1000,"maxDataPoints":43200}`, string(rule.Data[0].Model))
+
+		diff, err := templateService.DiffAlertRuleTemplateInstance(ctx, 1, tmpl.UID, instance.RuleUID)
+		require.NoError(t, err)
+		require.Empty(t, diff.Changes)
+
+		tmpl.Parameters[1].Default = float64(10)
+		tmpl, err = templateService.UpdateAlertRuleTemplate(ctx, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), tmpl.Version)
+
+		// the rule is not changed until the changes are approved
+		rule, _, err = templateService.rules.GetAlertRule(ctx, 1, instance.RuleUID)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"service":"checkout","threshold":5,"intervalMs":1000,"maxDataPoints":43200}`, string(rule.Data[0].Model))
+
+		diff, err = templateService.DiffAlertRuleTemplateInstance(ctx, 1, tmpl.UID, instance.RuleUID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), diff.FromVersion)
+		require.Equal(t, int64(2), diff.TemplateVersion)
+		require.Len(t, diff.Changes, 1)
+		require.Equal(t, "data[A]", diff.Changes[0].Field)
+
+		_, err = templateService.ApplyAlertRuleTemplateInstance(ctx, 1, tmpl.UID, instance.RuleUID, 1)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateVersionMismatch)
+
+		instance, err = templateService.ApplyAlertRuleTemplateInstance(ctx, 1, tmpl.UID, instance.RuleUID, diff.TemplateVersion)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), instance.TemplateVersion)
+
+		rule, _, err = templateService.rules.GetAlertRule(ctx, 1, instance.RuleUID)
+		require.NoError(t, err)
+		require.Equal(t, "checkout errors", rule.Title)
+		require.Equal(t, "checkout", rule.RuleGroup)
+		require.JSONEq(t, `{"service":"checkout","threshold":10,"intervalMs":1000,"maxDataPoints":43200}`, string(rule.Data[0].Model))
+
+		instances, err := templateService.GetAlertRuleTemplateInstances(ctx, 1, tmpl.UID)
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		require.Equal(t, int64(2), instances[0].TemplateVersion)
+	})
+
+	t.Run("instantiating without a required parameter should fail", func(t *testing.T) {
+		tmpl, err := templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("required", 1))
+		require.NoError(t, err)
+
+		_, err = templateService.InstantiateAlertRuleTemplate(ctx, models.InstantiateAlertRuleTemplateCmd{
+			OrgID:        1,
+			TemplateUID:  tmpl.UID,
+			Title:        "no service",
+			NamespaceUID: "folder",
+			RuleGroup:    "group",
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateFailedValidation)
+	})
+
+	t.Run("instances should be unlinked when the template or the rule is deleted", func(t *testing.T) {
+		tmpl, err := templateService.CreateAlertRuleTemplate(ctx, dummyTemplate("deleted", 1))
+		require.NoError(t, err)
+		instantiate := func(title string) models.AlertRuleTemplateInstance {
+			instance, err := templateService.InstantiateAlertRuleTemplate(ctx, models.InstantiateAlertRuleTemplateCmd{
+				OrgID:        1,
+				TemplateUID:  tmpl.UID,
+				Title:        title,
+				NamespaceUID: "folder",
+				RuleGroup:    "deleted",
+				Parameters:   map[string]interface{}{"service": title},
+			})
+			require.NoError(t, err)
+			return instance
+		}
+		first := instantiate("first")
+		second := instantiate("second")
+
+		err = templateService.rules.DeleteAlertRule(ctx, 1, first.RuleUID, models.ProvenanceAPI)
+		require.NoError(t, err)
+		instances, err := templateService.GetAlertRuleTemplateInstances(ctx, 1, tmpl.UID)
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+
+		err = templateService.DeleteAlertRuleTemplate(ctx, 1, tmpl.UID)
+		require.NoError(t, err)
+		_, err = templateService.GetAlertRuleTemplate(ctx, 1, tmpl.UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+		_, err = templateService.DiffAlertRuleTemplateInstance(ctx, 1, tmpl.UID, second.RuleUID)
+		require.ErrorIs(t, err, models.ErrAlertRuleTemplateNotFound)
+
+		// the rule is kept
+		_, _, err = templateService.rules.GetAlertRule(ctx, 1, second.RuleUID)
+		require.NoError(t, err)
+	})
+}
+
+func createAlertRuleTemplateService(t *testing.T) AlertRuleTemplateService {
+	t.Helper()
+	sqlStore := sqlstore.InitTestDB(t)
+	store := store.DBstore{
+		SQLStore:     sqlStore,
+		BaseInterval: time.Second * 10,
+		Logger:       log.NewNopLogger(),
+	}
+	return AlertRuleTemplateService{
+		templateStore: store,
+		rules: &AlertRuleService{
+			ruleStore:              store,
+			provenanceStore:        store,
+			xact:                   sqlStore,
+			log:                    log.NewNopLogger(),
+			baseIntervalSeconds:    10,
+			defaultIntervalSeconds: 60,
+		},
+		xact: sqlStore,
+		log:  log.NewNopLogger(),
+	}
+}
+
+func dummyTemplate(title string, orgID int64) models.AlertRuleTemplate {
+	return models.AlertRuleTemplate{
+		OrgID:     orgID,
+		Title:     title,
+		Condition: "A",
+		Data: []models.AlertQuery{
+			{
+				RefID:         "A",
+				Model:         json.RawMessage(`{"service":"${service}","threshold":"${threshold}"}`),
+				DatasourceUID: "-100",
+				RelativeTimeRange: models.RelativeTimeRange{
+					From: models.Duration(time.Second * 60),
+					To:   models.Duration(0),
+				},
+			},
+		},
+		For:          time.Second * 60,
+		NoDataState:  models.OK,
+		ExecErrState: models.OkErrState,
+		Labels:       map[string]string{"service": "${service}"},
+		Parameters: []models.AlertRuleTemplateParameter{
+			{Name: "service"},
+			{Name: "threshold", Default: float64(5)},
+		},
+	}
+}
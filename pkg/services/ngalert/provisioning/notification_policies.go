@@ -3,6 +3,10 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -98,3 +102,102 @@ func (nps *NotificationPolicyService) UpdatePolicyTree(ctx context.Context, orgI
 
 	return nil
 }
+
+// SimulatePolicyTree routes an alert with the labels of the simulation the same way the Alertmanager does, with
+// the policy tree of the simulation if set or the current policy tree otherwise.
+func (nps *NotificationPolicyService) SimulatePolicyTree(ctx context.Context, orgID int64, sim definitions.PolicySimulation) (definitions.PolicySimulationResult, error) {
+	revision, err := getLastConfiguration(ctx, orgID, nps.amStore)
+	if err != nil {
+		return definitions.PolicySimulationResult{}, err
+	}
+
+	tree := revision.cfg.AlertmanagerConfig.Config.Route
+	if sim.PolicyTree != nil {
+		tree = sim.PolicyTree
+	}
+	if tree == nil {
+		return definitions.PolicySimulationResult{}, fmt.Errorf("no route present in current alertmanager config")
+	}
+	if err := tree.Validate(); err != nil {
+		return definitions.PolicySimulationResult{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	receivers := make(map[string]*definitions.PostableApiReceiver, len(revision.cfg.AlertmanagerConfig.Receivers))
+	for _, r := range revision.cfg.AlertmanagerConfig.Receivers {
+		receivers[r.Name] = r
+	}
+
+	lset := make(model.LabelSet, len(sim.Labels))
+	for name, value := range sim.Labels {
+		lset[model.LabelName(name)] = model.LabelValue(value)
+	}
+
+	root := dispatch.NewRoute(tree.AsAMRoute(), nil)
+	paths := make(map[*dispatch.Route][]int)
+	var walk func(r *dispatch.Route, path []int)
+	walk = func(r *dispatch.Route, path []int) {
+		paths[r] = path
+		for i, child := range r.Routes {
+			walk(child, append(path[:len(path):len(path)], i))
+		}
+	}
+	walk(root, []int{})
+
+	result := definitions.PolicySimulationResult{Matches: []definitions.PolicySimulationMatch{}}
+	for _, r := range root.Match(lset) {
+		receiver, ok := receivers[r.RouteOpts.Receiver]
+		if !ok {
+			return definitions.PolicySimulationResult{}, fmt.Errorf("%w: contact point '%s' does not exist", ErrValidation, r.RouteOpts.Receiver)
+		}
+		result.Matches = append(result.Matches, simulationMatch(root, paths[r], r, receiver, lset))
+	}
+	return result, nil
+}
+
+func simulationMatch(root *dispatch.Route, path []int, r *dispatch.Route, receiver *definitions.PostableApiReceiver, lset model.LabelSet) definitions.PolicySimulationMatch {
+	match := definitions.PolicySimulationMatch{
+		Path:              path,
+		Matchers:          []string{root.Matchers.String()},
+		Receiver:          r.RouteOpts.Receiver,
+		Integrations:      make([]definitions.PolicySimulationIntegration, 0, len(receiver.GrafanaManagedReceivers)),
+		GroupBy:           []string{},
+		GroupLabels:       map[string]string{},
+		GroupWait:         model.Duration(r.RouteOpts.GroupWait),
+		GroupInterval:     model.Duration(r.RouteOpts.GroupInterval),
+		RepeatInterval:    model.Duration(r.RouteOpts.RepeatInterval),
+		MuteTimeIntervals: r.RouteOpts.MuteTimeIntervals,
+	}
+	if match.MuteTimeIntervals == nil {
+		match.MuteTimeIntervals = []string{}
+	}
+
+	node := root
+	for _, i := range path {
+		node = node.Routes[i]
+		match.Matchers = append(match.Matchers, node.Matchers.String())
+	}
+
+	for _, integration := range receiver.GrafanaManagedReceivers {
+		match.Integrations = append(match.Integrations, definitions.PolicySimulationIntegration{
+			UID:  integration.UID,
+			Name: integration.Name,
+			Type: integration.Type,
+		})
+	}
+
+	if r.RouteOpts.GroupByAll {
+		match.GroupBy = append(match.GroupBy, "...")
+		for name, value := range lset {
+			match.GroupLabels[string(name)] = string(value)
+		}
+		return match
+	}
+	for name := range r.RouteOpts.GroupBy {
+		match.GroupBy = append(match.GroupBy, string(name))
+		if value, ok := lset[name]; ok {
+			match.GroupLabels[string(name)] = string(value)
+		}
+	}
+	sort.Strings(match.GroupBy)
+	return match
+}
@@ -3,10 +3,12 @@ package provisioning
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -83,6 +85,83 @@ func TestNotificationPolicyService(t *testing.T) {
 		require.Error(t, err)
 		require.ErrorIs(t, err, ErrValidation)
 	})
+
+	t.Run("simulation routes alerts with the current policy tree", func(t *testing.T) {
+		sut := createNotificationPolicyServiceSut()
+
+		result, err := sut.SimulatePolicyTree(context.Background(), 1, definitions.PolicySimulation{
+			Labels: map[string]string{"a": "b", "alertname": "test"},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.Matches, 1)
+		match := result.Matches[0]
+		require.Equal(t, []int{0}, match.Path)
+		require.Equal(t, []string{"{}", `{a="b"}`}, match.Matchers)
+		require.Equal(t, "grafana-default-email", match.Receiver)
+		require.Equal(t, []definitions.PolicySimulationIntegration{{Name: "email receiver", Type: "email"}}, match.Integrations)
+		require.Equal(t, []string{"..."}, match.GroupBy)
+		require.Equal(t, map[string]string{"a": "b", "alertname": "test"}, match.GroupLabels)
+		require.Equal(t, model.Duration(30*time.Second), match.GroupWait)
+	})
+
+	t.Run("simulation routes alerts with the given policy tree", func(t *testing.T) {
+		sut := createNotificationPolicyServiceSut()
+		groupWait := model.Duration(time.Minute)
+		tree := definitions.Route{
+			Receiver:   "grafana-default-email",
+			GroupByStr: []string{"alertname"},
+			Routes: []*definitions.Route{
+				{
+					Receiver:       "a new receiver",
+					ObjectMatchers: definitions.ObjectMatchers{createMatcher(t, "team", labels.MatchEqual, "ops")},
+					GroupByStr:     []string{"alertname", "team"},
+					GroupWait:      &groupWait,
+					Continue:       true,
+				},
+				{
+					ObjectMatchers:    definitions.ObjectMatchers{createMatcher(t, "severity", labels.MatchEqual, "critical")},
+					MuteTimeIntervals: []string{"weekends"},
+				},
+			},
+		}
+
+		result, err := sut.SimulatePolicyTree(context.Background(), 1, definitions.PolicySimulation{
+			Labels:     map[string]string{"alertname": "test", "team": "ops", "severity": "critical"},
+			PolicyTree: &tree,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.Matches, 2)
+		require.Equal(t, []int{0}, result.Matches[0].Path)
+		require.Equal(t, "a new receiver", result.Matches[0].Receiver)
+		require.Equal(t, []string{"alertname", "team"}, result.Matches[0].GroupBy)
+		require.Equal(t, map[string]string{"alertname": "test", "team": "ops"}, result.Matches[0].GroupLabels)
+		require.Equal(t, groupWait, result.Matches[0].GroupWait)
+		require.Equal(t, []int{1}, result.Matches[1].Path)
+		require.Equal(t, "grafana-default-email", result.Matches[1].Receiver)
+		require.Equal(t, []string{"alertname"}, result.Matches[1].GroupBy)
+		require.Equal(t, model.Duration(30*time.Second), result.Matches[1].GroupWait)
+		require.Equal(t, []string{"weekends"}, result.Matches[1].MuteTimeIntervals)
+
+		result, err = sut.SimulatePolicyTree(context.Background(), 1, definitions.PolicySimulation{
+			Labels:     map[string]string{"alertname": "test"},
+			PolicyTree: &tree,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Matches, 1)
+		require.Empty(t, result.Matches[0].Path)
+		require.Equal(t, []string{"{}"}, result.Matches[0].Matchers)
+	})
+
+	t.Run("simulation with unknown contact point returns ValidationError", func(t *testing.T) {
+		sut := createNotificationPolicyServiceSut()
+		tree := definitions.Route{Receiver: "unknown"}
+
+		_, err := sut.SimulatePolicyTree(context.Background(), 1, definitions.PolicySimulation{PolicyTree: &tree})
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
 }
 
 func createNotificationPolicyServiceSut() *NotificationPolicyService {
@@ -99,3 +178,10 @@ func createTestRoutingTree() definitions.Route {
 		Receiver: "a new receiver",
 	}
 }
+
+func createMatcher(t *testing.T, name string, matchType labels.MatchType, value string) *labels.Matcher {
+	t.Helper()
+	matcher, err := labels.NewMatcher(matchType, name, value)
+	require.NoError(t, err)
+	return matcher
+}
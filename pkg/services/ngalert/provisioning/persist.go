@@ -37,3 +37,16 @@ type RuleStore interface {
 	UpdateAlertRules(ctx context.Context, rule []store.UpdateRule) error
 	DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUID ...string) error
 }
+
+// AlertRuleTemplateStore represents the ability to persist and query alert rule templates,
+// and the alert rules created from them.
+type AlertRuleTemplateStore interface {
+	GetAlertRuleTemplate(ctx context.Context, query *models.GetAlertRuleTemplateQuery) error
+	ListAlertRuleTemplates(ctx context.Context, query *models.ListAlertRuleTemplatesQuery) error
+	InsertAlertRuleTemplate(ctx context.Context, tmpl *models.AlertRuleTemplate) error
+	UpdateAlertRuleTemplate(ctx context.Context, tmpl *models.AlertRuleTemplate) error
+	DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error
+	GetAlertRuleTemplateInstance(ctx context.Context, query *models.GetAlertRuleTemplateInstanceQuery) error
+	ListAlertRuleTemplateInstances(ctx context.Context, query *models.ListAlertRuleTemplateInstancesQuery) error
+	SaveAlertRuleTemplateInstance(ctx context.Context, instance *models.AlertRuleTemplateInstance) error
+}
@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Sample is a value of a series written by a recording rule.
+type Sample struct {
+	OrgID   int64
+	RuleUID string
+	Metric  string
+	Labels  data.Labels
+	Time    time.Time
+	// Value is nil when the query returned a null value.
+	Value *float64
+}
+
+// Series is the samples of a metric that have the same labels, in time order.
+type Series struct {
+	Metric string
+	Labels data.Labels
+	Times  []time.Time
+	Values []*float64
+}
+
+// SamplesFromFrames returns a sample for each numeric field of the frames, with
+// the labels of the field. The sample of a time series is its last value,
+// other fields are read at the first row and get the evaluation time.
+// Only the labels, time and value of the samples are set.
+func SamplesFromFrames(frames data.Frames, evaluatedAt time.Time) []Sample {
+	var samples []Sample
+	for _, frame := range frames {
+		rows, err := frame.RowLen()
+		if err != nil || rows == 0 {
+			continue
+		}
+
+		row := 0
+		ts := evaluatedAt
+		timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+		if len(timeIndices) > 0 {
+			row = rows - 1
+			if t, ok := frame.Fields[timeIndices[0]].ConcreteAt(row); ok {
+				ts = t.(time.Time)
+			}
+		}
+
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, err := field.NullableFloatAt(row)
+			if err != nil {
+				continue
+			}
+			samples = append(samples, Sample{
+				Labels: field.Labels.Copy(),
+				Time:   ts,
+				Value:  value,
+			})
+		}
+	}
+	return samples
+}
+
+func sortSeries(series []Series) {
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Labels.String() < series[j].Labels.String()
+	})
+}
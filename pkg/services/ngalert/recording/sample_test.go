@@ -0,0 +1,42 @@
+package recording
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplesFromFrames(t *testing.T) {
+	now := time.Now()
+	value := func(v float64) *float64 { return &v }
+
+	t.Run("numeric frames are read at the evaluation time", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("", data.Labels{"job": "a"}, []float64{1})),
+			data.NewFrame("", data.NewField("", data.Labels{"job": "b"}, []*float64{nil})),
+			data.NewFrame("", data.NewField("", data.Labels{"job": "c"}, []float64{})),
+		}
+		require.Equal(t, []Sample{
+			{Labels: data.Labels{"job": "a"}, Time: now, Value: value(1)},
+			{Labels: data.Labels{"job": "b"}, Time: now},
+		}, SamplesFromFrames(frames, now))
+	})
+
+	t.Run("time series are read at their last value", func(t *testing.T) {
+		last := now.Add(-time.Minute)
+		frames := data.Frames{
+			data.NewFrame("",
+				data.NewField("Time", nil, []time.Time{now.Add(-2 * time.Minute), last}),
+				data.NewField("Value", data.Labels{"job": "a"}, []int64{1, 2}),
+				data.NewField("Other", data.Labels{"job": "b"}, []float64{3, 4}),
+				data.NewField("Name", nil, []string{"x", "y"}),
+			),
+		}
+		require.Equal(t, []Sample{
+			{Labels: data.Labels{"job": "a"}, Time: last, Value: value(2)},
+			{Labels: data.Labels{"job": "b"}, Time: last, Value: value(4)},
+		}, SamplesFromFrames(frames, now))
+	})
+}
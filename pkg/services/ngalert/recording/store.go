@@ -0,0 +1,189 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	// MaxMetricLength is the maximum length of the metric of a recording rule.
+	MaxMetricLength = 190
+	// writeBatchSize is the number of samples inserted by a single statement.
+	writeBatchSize = 500
+	// MaxQuerySamples is the maximum number of samples a query can return.
+	MaxQuerySamples = 100000
+	// retentionInterval is how often the samples older than the retention are deleted.
+	retentionInterval = 10 * time.Minute
+)
+
+// ErrTooManySamples is returned when a query matches more than MaxQuerySamples samples.
+var ErrTooManySamples = errors.New("query matches too many recorded samples, narrow down its time range or labels")
+
+// Store keeps the samples written by the recording rules in the alert_recorded_sample table.
+type Store struct {
+	sqlStore *sqlstore.SQLStore
+	log      log.Logger
+}
+
+func NewStore(sqlStore *sqlstore.SQLStore) *Store {
+	return &Store{
+		sqlStore: sqlStore,
+		log:      log.New("ngalert.recording"),
+	}
+}
+
+type recordedSample struct {
+	ID      int64    `xorm:"pk autoincr 'id'"`
+	OrgID   int64    `xorm:"org_id"`
+	RuleUID string   `xorm:"rule_uid"`
+	Metric  string   `xorm:"metric"`
+	Labels  string   `xorm:"labels"`
+	Epoch   int64    `xorm:"epoch"`
+	Value   *float64 `xorm:"value"`
+}
+
+func (recordedSample) TableName() string {
+	return "alert_recorded_sample"
+}
+
+// Query selects the samples of a metric in a time range. The series must have
+// all the labels of Matchers, with the same values.
+type Query struct {
+	OrgID    int64
+	Metric   string
+	Matchers data.Labels
+	From     time.Time
+	To       time.Time
+}
+
+// Write saves the samples.
+func (s *Store) Write(ctx context.Context, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	rows := make([]recordedSample, 0, len(samples))
+	for _, sample := range samples {
+		labels, err := json.Marshal(sample.Labels)
+		if err != nil {
+			return fmt.Errorf("failed to encode labels of metric %s: %w", sample.Metric, err)
+		}
+		rows = append(rows, recordedSample{
+			OrgID:   sample.OrgID,
+			RuleUID: sample.RuleUID,
+			Metric:  sample.Metric,
+			Labels:  string(labels),
+			Epoch:   sample.Time.UnixMilli(),
+			Value:   sample.Value,
+		})
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for start := 0; start < len(rows); start += writeBatchSize {
+			end := start + writeBatchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			if _, err := sess.InsertMulti(rows[start:end]); err != nil {
+				return fmt.Errorf("failed to insert recorded samples: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Query returns the series of the metric that match the query, sorted by labels.
+func (s *Store) Query(ctx context.Context, query Query) ([]Series, error) {
+	var rows []recordedSample
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ? AND metric = ? AND epoch >= ? AND epoch <= ?",
+			query.OrgID, query.Metric, query.From.UnixMilli(), query.To.UnixMilli()).
+			Asc("epoch", "id").
+			Limit(MaxQuerySamples + 1).
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > MaxQuerySamples {
+		return nil, ErrTooManySamples
+	}
+
+	// the labels are encoded with sorted keys, so the same labels are the same string
+	var result []Series
+	index := map[string]int{}
+	for _, row := range rows {
+		i, ok := index[row.Labels]
+		if !ok {
+			var labels data.Labels
+			if err := json.Unmarshal([]byte(row.Labels), &labels); err != nil {
+				return nil, fmt.Errorf("failed to decode labels of metric %s: %w", row.Metric, err)
+			}
+			if !matches(labels, query.Matchers) {
+				index[row.Labels] = -1
+				continue
+			}
+			i = len(result)
+			index[row.Labels] = i
+			result = append(result, Series{Metric: row.Metric, Labels: labels})
+		}
+		if i < 0 {
+			continue
+		}
+		result[i].Times = append(result[i].Times, time.UnixMilli(row.Epoch).UTC())
+		result[i].Values = append(result[i].Values, row.Value)
+	}
+	sortSeries(result)
+	return result, nil
+}
+
+// DeleteBefore deletes the samples older than the given time, and returns how many were deleted.
+func (s *Store) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	var deleted int64
+	err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_recorded_sample WHERE epoch < ?", t.UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+// Run deletes the samples older than the retention periodically, until the context is done.
+func (s *Store) Run(ctx context.Context, retention time.Duration) error {
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := s.DeleteBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				s.log.Error("failed to delete old recorded samples", "err", err)
+				continue
+			}
+			if deleted > 0 {
+				s.log.Debug("deleted old recorded samples", "count", deleted)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func matches(labels, matchers data.Labels) bool {
+	for name, value := range matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	store := NewStore(sqlstore.InitTestDB(t))
+
+	value := func(v float64) *float64 { return &v }
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	sample := func(orgID int64, metric string, labels data.Labels, ts time.Time, v *float64) Sample {
+		return Sample{OrgID: orgID, RuleUID: "rule", Metric: metric, Labels: labels, Time: ts, Value: v}
+	}
+	err := store.Write(ctx, []Sample{
+		sample(1, "up", data.Labels{"job": "b"}, now.Add(-2*time.Minute), value(1)),
+		sample(1, "up", data.Labels{"job": "a"}, now.Add(-2*time.Minute), value(0)),
+		sample(1, "up", data.Labels{"job": "a"}, now.Add(-time.Minute), nil),
+		sample(1, "up", data.Labels{"job": "a"}, now, value(1)),
+		sample(1, "down", data.Labels{"job": "a"}, now, value(1)),
+		sample(2, "up", data.Labels{"job": "a"}, now, value(1)),
+		sample(1, "up", data.Labels{"job": "a"}, now.Add(-time.Hour), value(1)),
+	})
+	require.NoError(t, err)
+
+	t.Run("query returns the series of the metric in the time range", func(t *testing.T) {
+		series, err := store.Query(ctx, Query{OrgID: 1, Metric: "up", From: now.Add(-5 * time.Minute), To: now})
+		require.NoError(t, err)
+		require.Equal(t, []Series{
+			{
+				Metric: "up",
+				Labels: data.Labels{"job": "a"},
+				Times:  []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute), now},
+				Values: []*float64{value(0), nil, value(1)},
+			},
+			{
+				Metric: "up",
+				Labels: data.Labels{"job": "b"},
+				Times:  []time.Time{now.Add(-2 * time.Minute)},
+				Values: []*float64{value(1)},
+			},
+		}, series)
+	})
+
+	t.Run("query returns the series that match the labels", func(t *testing.T) {
+		series, err := store.Query(ctx, Query{OrgID: 1, Metric: "up", Matchers: data.Labels{"job": "b"}, From: now.Add(-5 * time.Minute), To: now})
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+		require.Equal(t, data.Labels{"job": "b"}, series[0].Labels)
+	})
+
+	t.Run("samples older than the retention are deleted", func(t *testing.T) {
+		deleted, err := store.DeleteBefore(ctx, now.Add(-5*time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+
+		series, err := store.Query(ctx, Query{OrgID: 1, Metric: "up", Matchers: data.Labels{"job": "a"}, From: now.Add(-2 * time.Hour), To: now})
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+		require.Len(t, series[0].Times, 3)
+	})
+}
@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// durationSamples is the number of recent evaluation durations kept for each rule group.
+const durationSamples = 100
+
+// evaluationDiagnostics keeps track of the evaluations of the alert rule groups,
+// so that operators can find the rules that are slow to evaluate, or stuck.
+type evaluationDiagnostics struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	groups  map[models.AlertRuleGroupKey]*groupDiagnostics
+	running map[models.AlertRuleKey]*runningEvaluation
+}
+
+type groupDiagnostics struct {
+	evaluations    int64
+	lastEvaluation time.Time
+	lag            time.Duration
+	maxLag         time.Duration
+	missedTicks    int64
+	// durations is a ring buffer of the recent evaluation durations
+	durations []time.Duration
+	next      int
+}
+
+type runningEvaluation struct {
+	rule        *models.AlertRule
+	scheduledAt time.Time
+	startedAt   time.Time
+}
+
+func newEvaluationDiagnostics(c clock.Clock) *evaluationDiagnostics {
+	return &evaluationDiagnostics{
+		clock:   c,
+		groups:  map[models.AlertRuleGroupKey]*groupDiagnostics{},
+		running: map[models.AlertRuleKey]*runningEvaluation{},
+	}
+}
+
+func (d *evaluationDiagnostics) group(key models.AlertRuleGroupKey) *groupDiagnostics {
+	g, ok := d.groups[key]
+	if !ok {
+		g = &groupDiagnostics{durations: make([]time.Duration, 0, durationSamples)}
+		d.groups[key] = g
+	}
+	return g
+}
+
+// evaluationStarted records the lag of the evaluation of the rule scheduled at the given time.
+func (d *evaluationDiagnostics) evaluationStarted(rule *models.AlertRule, scheduledAt time.Time) {
+	now := d.clock.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	g := d.group(rule.GetGroupKey())
+	g.lag = now.Sub(scheduledAt)
+	if g.lag > g.maxLag {
+		g.maxLag = g.lag
+	}
+	d.running[rule.GetKey()] = &runningEvaluation{rule: rule, scheduledAt: scheduledAt, startedAt: now}
+}
+
+// evaluationFinished records the duration of the running evaluation of the rule.
+func (d *evaluationDiagnostics) evaluationFinished(key models.AlertRuleKey) {
+	now := d.clock.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.running[key]
+	if !ok {
+		return
+	}
+	delete(d.running, key)
+
+	g := d.group(e.rule.GetGroupKey())
+	g.evaluations++
+	g.lastEvaluation = e.startedAt
+	dur := now.Sub(e.startedAt)
+	if len(g.durations) < durationSamples {
+		g.durations = append(g.durations, dur)
+	} else {
+		g.durations[g.next] = dur
+	}
+	g.next = (g.next + 1) % durationSamples
+}
+
+// tickMissed records a tick that was dropped because the rule was still being evaluated.
+func (d *evaluationDiagnostics) tickMissed(key models.AlertRuleGroupKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.group(key).missedTicks++
+}
+
+// forget drops the statistics of the rule groups that are not in the given set.
+func (d *evaluationDiagnostics) forget(groups map[models.AlertRuleGroupKey]struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key := range d.groups {
+		if _, ok := groups[key]; !ok {
+			delete(d.groups, key)
+		}
+	}
+}
+
+// status returns the statistics of the given rules of the organization. The
+// rule groups are sorted by the 99th percentile of their evaluation duration,
+// and the stuck evaluations by how long they have been running.
+func (d *evaluationDiagnostics) status(orgID int64, rules []*models.SchedulableAlertRule) definitions.GettableSchedulerStatus {
+	now := d.clock.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := definitions.GettableSchedulerStatus{
+		RuleGroups:       []definitions.SchedulerRuleGroupStatus{},
+		StuckEvaluations: []definitions.SchedulerEvaluation{},
+	}
+	statuses := map[models.AlertRuleGroupKey]*definitions.SchedulerRuleGroupStatus{}
+	for _, rule := range rules {
+		if rule.OrgID != orgID {
+			continue
+		}
+		key := rule.GetGroupKey()
+		s, ok := statuses[key]
+		if !ok {
+			s = &definitions.SchedulerRuleGroupStatus{NamespaceUID: key.NamespaceUID, RuleGroup: key.RuleGroup}
+			if g, ok := d.groups[key]; ok {
+				s.Evaluations = g.evaluations
+				s.LastEvaluation = g.lastEvaluation
+				s.LagSeconds = g.lag.Seconds()
+				s.MaxLagSeconds = g.maxLag.Seconds()
+				s.MissedTicks = g.missedTicks
+				s.DurationSeconds = durationPercentiles(g.durations)
+			}
+			statuses[key] = s
+		}
+		s.Rules++
+	}
+
+	for key, e := range d.running {
+		if key.OrgID != orgID {
+			continue
+		}
+		if s, ok := statuses[e.rule.GetGroupKey()]; ok {
+			s.Running++
+		}
+		interval := time.Duration(e.rule.IntervalSeconds) * time.Second
+		if running := now.Sub(e.startedAt); running > interval {
+			result.StuckEvaluations = append(result.StuckEvaluations, definitions.SchedulerEvaluation{
+				RuleUID:         e.rule.UID,
+				Title:           e.rule.Title,
+				NamespaceUID:    e.rule.NamespaceUID,
+				RuleGroup:       e.rule.RuleGroup,
+				IntervalSeconds: e.rule.IntervalSeconds,
+				ScheduledAt:     e.scheduledAt,
+				StartedAt:       e.startedAt,
+				RunningSeconds:  running.Seconds(),
+			})
+		}
+	}
+
+	for _, s := range statuses {
+		result.RuleGroups = append(result.RuleGroups, *s)
+	}
+	sort.Slice(result.RuleGroups, func(i, j int) bool {
+		a, b := result.RuleGroups[i], result.RuleGroups[j]
+		if a.DurationSeconds.P99 != b.DurationSeconds.P99 {
+			return a.DurationSeconds.P99 > b.DurationSeconds.P99
+		}
+		if a.NamespaceUID != b.NamespaceUID {
+			return a.NamespaceUID < b.NamespaceUID
+		}
+		return a.RuleGroup < b.RuleGroup
+	})
+	sort.Slice(result.StuckEvaluations, func(i, j int) bool {
+		return result.StuckEvaluations[i].RunningSeconds > result.StuckEvaluations[j].RunningSeconds
+	})
+	return result
+}
+
+// durationPercentiles returns the nearest-rank percentiles of the durations.
+func durationPercentiles(durations []time.Duration) definitions.SchedulerDurationPercentiles {
+	if len(durations) == 0 {
+		return definitions.SchedulerDurationPercentiles{}
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p int) float64 {
+		rank := (p*len(sorted) + 99) / 100
+		return sorted[rank-1].Seconds()
+	}
+	return definitions.SchedulerDurationPercentiles{
+		P50: percentile(50),
+		P90: percentile(90),
+		P99: percentile(99),
+	}
+}
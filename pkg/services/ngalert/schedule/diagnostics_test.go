@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationDiagnostics(t *testing.T) {
+	mockedClock := clock.NewMock()
+	d := newEvaluationDiagnostics(mockedClock)
+
+	fast := &models.AlertRule{OrgID: 1, UID: "fast", Title: "fast", NamespaceUID: "ns", RuleGroup: "fast", IntervalSeconds: 10}
+	slow := &models.AlertRule{OrgID: 1, UID: "slow", Title: "slow", NamespaceUID: "ns", RuleGroup: "slow", IntervalSeconds: 10}
+	other := &models.AlertRule{OrgID: 2, UID: "other", Title: "other", NamespaceUID: "ns", RuleGroup: "slow", IntervalSeconds: 10}
+	schedulable := func(r *models.AlertRule) *models.SchedulableAlertRule {
+		return &models.SchedulableAlertRule{OrgID: r.OrgID, UID: r.UID, Title: r.Title, NamespaceUID: r.NamespaceUID, RuleGroup: r.RuleGroup, IntervalSeconds: r.IntervalSeconds}
+	}
+	rules := []*models.SchedulableAlertRule{schedulable(fast), schedulable(slow), schedulable(other)}
+
+	for i := 1; i <= 10; i++ {
+		tick := mockedClock.Now()
+		mockedClock.Add(time.Second)
+		d.evaluationStarted(fast, tick)
+		mockedClock.Add(time.Duration(i) * time.Millisecond)
+		d.evaluationFinished(fast.GetKey())
+	}
+
+	tick := mockedClock.Now()
+	mockedClock.Add(2 * time.Second)
+	d.evaluationStarted(slow, tick)
+	d.evaluationStarted(other, tick)
+	d.tickMissed(slow.GetGroupKey())
+	mockedClock.Add(15 * time.Second)
+
+	status := d.status(1, rules)
+	require.Len(t, status.RuleGroups, 2)
+
+	fastStatus := status.RuleGroups[0]
+	require.Equal(t, "fast", fastStatus.RuleGroup)
+	require.Equal(t, 1, fastStatus.Rules)
+	require.EqualValues(t, 10, fastStatus.Evaluations)
+	require.Equal(t, 1.0, fastStatus.LagSeconds)
+	require.Equal(t, 0.005, fastStatus.DurationSeconds.P50)
+	require.Equal(t, 0.009, fastStatus.DurationSeconds.P90)
+	require.Equal(t, 0.01, fastStatus.DurationSeconds.P99)
+	require.Equal(t, 0, fastStatus.Running)
+
+	slowStatus := status.RuleGroups[1]
+	require.Equal(t, "slow", slowStatus.RuleGroup)
+	require.EqualValues(t, 0, slowStatus.Evaluations)
+	require.Equal(t, 2.0, slowStatus.LagSeconds)
+	require.EqualValues(t, 1, slowStatus.MissedTicks)
+	require.Equal(t, 1, slowStatus.Running)
+
+	require.Len(t, status.StuckEvaluations, 1)
+	require.Equal(t, "slow", status.StuckEvaluations[0].RuleUID)
+	require.Equal(t, 15.0, status.StuckEvaluations[0].RunningSeconds)
+
+	t.Run("groups without rules are forgotten", func(t *testing.T) {
+		d.forget(map[models.AlertRuleGroupKey]struct{}{slow.GetGroupKey(): {}})
+		status := d.status(1, rules)
+		require.Equal(t, "fast", status.RuleGroups[0].RuleGroup)
+		require.EqualValues(t, 0, status.RuleGroups[0].Evaluations)
+		require.EqualValues(t, 1, status.RuleGroups[1].MissedTicks)
+	})
+}
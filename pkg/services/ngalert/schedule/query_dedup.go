@@ -0,0 +1,174 @@
+package schedule
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queryDeduplicator shares the results of identical data source queries between
+// the alert rules evaluated in the same tick. Rules are evaluated at the time of
+// the tick, so identical queries of rules with the same time range are identical
+// requests to the data source.
+//
+// The results of the current and the previous tick are kept, since evaluations
+// can still be running when the next tick starts.
+type queryDeduplicator struct {
+	next backend.QueryDataHandler
+	hits prometheus.Counter
+	miss prometheus.Counter
+
+	mu       sync.Mutex
+	current  map[string]*sharedQuery
+	previous map[string]*sharedQuery
+}
+
+// sharedQuery is the result of a query, done is closed when it is available.
+type sharedQuery struct {
+	done chan struct{}
+	// frames are encoded, so that each rule gets its own copy that it can change
+	frames   [][]byte
+	queryErr error
+	err      error
+}
+
+func newQueryDeduplicator(next backend.QueryDataHandler, counter *prometheus.CounterVec) *queryDeduplicator {
+	return &queryDeduplicator{
+		next:    next,
+		hits:    counter.WithLabelValues("hit"),
+		miss:    counter.WithLabelValues("miss"),
+		current: map[string]*sharedQuery{},
+	}
+}
+
+// nextTick drops the results of the tick before the previous one.
+func (d *queryDeduplicator) nextTick() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.previous = d.current
+	d.current = map[string]*sharedQuery{}
+}
+
+func (d *queryDeduplicator) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	// the expressions service sends one query per request
+	if len(req.Queries) != 1 || req.PluginContext.DataSourceInstanceSettings == nil {
+		return d.next.QueryData(ctx, req)
+	}
+	key, err := queryKey(req)
+	if err != nil {
+		return d.next.QueryData(ctx, req)
+	}
+	refID := req.Queries[0].RefID
+
+	d.mu.Lock()
+	shared, found := d.current[key]
+	if !found {
+		shared, found = d.previous[key]
+	}
+	if !found {
+		shared = &sharedQuery{done: make(chan struct{})}
+		d.current[key] = shared
+	}
+	d.mu.Unlock()
+
+	if found {
+		select {
+		case <-shared.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// the query failed for the rule that ran it, e.g. because its evaluation timed out
+		if shared.err != nil {
+			d.miss.Inc()
+			return d.next.QueryData(ctx, req)
+		}
+		d.hits.Inc()
+		return shared.response(refID)
+	}
+
+	d.miss.Inc()
+	resp, err := d.next.QueryData(ctx, req)
+	d.share(key, shared, resp, err)
+	return resp, err
+}
+
+func (d *queryDeduplicator) share(key string, shared *sharedQuery, resp *backend.QueryDataResponse, err error) {
+	defer close(shared.done)
+
+	if err == nil {
+		for _, r := range resp.Responses {
+			shared.queryErr = r.Error
+			shared.frames, err = r.Frames.MarshalArrow()
+		}
+	}
+	if err != nil {
+		shared.err = err
+		d.mu.Lock()
+		if d.current[key] == shared {
+			delete(d.current, key)
+		}
+		d.mu.Unlock()
+	}
+}
+
+func (s *sharedQuery) response(refID string) (*backend.QueryDataResponse, error) {
+	frames, err := data.UnmarshalArrowFrames(s.frames)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range frames {
+		f.RefID = refID
+	}
+	resp := backend.NewQueryDataResponse()
+	resp.Responses[refID] = backend.DataResponse{Frames: frames, Error: s.queryErr}
+	return resp, nil
+}
+
+// queryKey identifies a query by everything that is sent to the data source but
+// its ref ID, which is only used to match the response.
+func queryKey(req *backend.QueryDataRequest) (string, error) {
+	q := req.Queries[0]
+	model := map[string]interface{}{}
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return "", err
+	}
+	delete(model, "refId")
+
+	ds := req.PluginContext.DataSourceInstanceSettings
+	encoded, err := json.Marshal(struct {
+		OrgID         int64
+		PluginID      string
+		UID           string
+		Updated       int64
+		Headers       map[string]string
+		Model         map[string]interface{}
+		From          int64
+		To            int64
+		Interval      int64
+		MaxDataPoints int64
+		QueryType     string
+	}{
+		OrgID:         req.PluginContext.OrgID,
+		PluginID:      req.PluginContext.PluginID,
+		UID:           ds.UID,
+		Updated:       ds.Updated.UnixNano(),
+		Headers:       req.Headers,
+		Model:         model,
+		From:          q.TimeRange.From.UnixNano(),
+		To:            q.TimeRange.To.UnixNano(),
+		Interval:      int64(q.Interval),
+		MaxDataPoints: q.MaxDataPoints,
+		QueryType:     q.QueryType,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
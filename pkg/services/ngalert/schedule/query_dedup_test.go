@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueryDataHandler struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (f *fakeQueryDataHandler) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frame := data.NewFrame("", data.NewField("value", data.Labels{"job": "api"}, []float64{1}))
+		frame.RefID = q.RefID
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
+
+func TestQueryDeduplicator(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	request := func(refID, model string, from time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prom"},
+			},
+			Queries: []backend.DataQuery{{
+				RefID:     refID,
+				JSON:      []byte(model),
+				TimeRange: backend.TimeRange{From: from, To: now},
+			}},
+		}
+	}
+
+	newDeduplicator := func() (*queryDeduplicator, *fakeQueryDataHandler, *prometheus.CounterVec) {
+		handler := &fakeQueryDataHandler{}
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"result"})
+		return newQueryDeduplicator(handler, counter), handler, counter
+	}
+
+	t.Run("identical queries are sent once", func(t *testing.T) {
+		d, handler, counter := newDeduplicator()
+
+		resp, err := d.QueryData(context.Background(), request("A", `{"refId": "A", "expr": "up"}`, now.Add(-time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, "A", resp.Responses["A"].Frames[0].RefID)
+
+		resp, err = d.QueryData(context.Background(), request("B", `{"expr": "up", "refId": "B"}`, now.Add(-time.Minute)))
+		require.NoError(t, err)
+		require.Len(t, resp.Responses["B"].Frames, 1)
+		frame := resp.Responses["B"].Frames[0]
+		require.Equal(t, "B", frame.RefID)
+		require.Equal(t, data.Labels{"job": "api"}, frame.Fields[0].Labels)
+
+		require.Equal(t, 1, handler.calls)
+		require.Equal(t, 1.0, testutil.ToFloat64(counter.WithLabelValues("hit")))
+		require.Equal(t, 1.0, testutil.ToFloat64(counter.WithLabelValues("miss")))
+	})
+
+	t.Run("different queries and time ranges are sent", func(t *testing.T) {
+		d, handler, _ := newDeduplicator()
+
+		_, err := d.QueryData(context.Background(), request("A", `{"expr": "up"}`, now.Add(-time.Minute)))
+		require.NoError(t, err)
+		_, err = d.QueryData(context.Background(), request("A", `{"expr": "down"}`, now.Add(-time.Minute)))
+		require.NoError(t, err)
+		_, err = d.QueryData(context.Background(), request("A", `{"expr": "up"}`, now.Add(-time.Hour)))
+		require.NoError(t, err)
+
+		require.Equal(t, 3, handler.calls)
+	})
+
+	t.Run("results are kept for the previous tick", func(t *testing.T) {
+		d, handler, _ := newDeduplicator()
+		req := request("A", `{"expr": "up"}`, now.Add(-time.Minute))
+
+		_, err := d.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		d.nextTick()
+		_, err = d.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, 1, handler.calls)
+
+		d.nextTick()
+		d.nextTick()
+		_, err = d.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, 2, handler.calls)
+	})
+
+	t.Run("failed queries are not shared", func(t *testing.T) {
+		d, handler, _ := newDeduplicator()
+		handler.err = errors.New("timeout")
+		req := request("A", `{"expr": "up"}`, now.Add(-time.Minute))
+
+		_, err := d.QueryData(context.Background(), req)
+		require.Error(t, err)
+
+		handler.err = nil
+		_, err = d.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, 2, handler.calls)
+	})
+}
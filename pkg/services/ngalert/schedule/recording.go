@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
+)
+
+// SampleWriter writes the samples of the recording rules.
+type SampleWriter interface {
+	Write(ctx context.Context, samples []recording.Sample) error
+}
+
+// record evaluates the queries and expressions of the recording rule, and
+// writes the values of the one the rule records.
+func (sch *schedule) record(ctx context.Context, r *models.AlertRule, now time.Time) error {
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(r.OrgID, r.Data, now, sch.expressionService)
+	if err != nil {
+		return err
+	}
+	res, ok := resp.Responses[r.Record.From]
+	if !ok {
+		return fmt.Errorf("no result for query or expression %s", r.Record.From)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("failed to evaluate query or expression %s: %w", r.Record.From, res.Error)
+	}
+
+	samples := recording.SamplesFromFrames(res.Frames, now)
+	for i := range samples {
+		samples[i].OrgID = r.OrgID
+		samples[i].RuleUID = r.UID
+		samples[i].Metric = r.Record.Metric
+		// the labels of the rule override the labels of the series
+		for name, value := range r.Labels {
+			samples[i].Labels[name] = value
+		}
+	}
+	return sch.sampleWriter.Write(ctx, samples)
+}
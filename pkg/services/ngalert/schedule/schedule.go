@@ -19,8 +19,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/timeregions"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -43,6 +45,9 @@ type ScheduleService interface {
 	UpdateAlertRule(key models.AlertRuleKey)
 	// DeleteAlertRule notifies scheduler that a rule has been changed
 	DeleteAlertRule(key models.AlertRuleKey)
+	// SchedulerStatus returns the evaluation statistics of the rule groups of
+	// the organization, and its evaluations that are stuck.
+	SchedulerStatus(orgID int64) definitions.GettableSchedulerStatus
 	// the following are used by tests only used for tests
 	evalApplied(models.AlertRuleKey, time.Time)
 	stopApplied(models.AlertRuleKey)
@@ -98,6 +103,25 @@ type schedule struct {
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
 
+	// timeRegions evaluates the calendars rules are restricted to, see models.TimeRegionCalendarAnnotation.
+	timeRegions timeregions.Service
+
+	ruleErrorNotifier RuleErrorNotifier
+
+	// statePublisher is told about the state changes of the alert instances, it is nil when disabled.
+	statePublisher StatePublisher
+
+	evaluationPausedFunc func() bool
+
+	// queryDedup shares the results of identical queries in a tick, it is nil when disabled.
+	queryDedup *queryDeduplicator
+
+	// diagnostics keeps track of the evaluation lag and durations of the rule groups.
+	diagnostics *evaluationDiagnostics
+
+	// sampleWriter writes the samples of the recording rules, it is nil when recording rules are disabled.
+	sampleWriter SampleWriter
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -123,6 +147,35 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	TimeRegions             timeregions.Service
+	RuleErrorNotifier       RuleErrorNotifier
+	// EvaluationPausedFunc returns true while alert rules must not be evaluated, e.g. during maintenance.
+	EvaluationPausedFunc func() bool
+	// DeduplicateQueries shares the results of identical queries between the rules evaluated in the same tick.
+	DeduplicateQueries bool
+	// SampleWriter writes the samples of the recording rules. Recording rules are not evaluated when it is nil.
+	SampleWriter SampleWriter
+	// StatePublisher is told about the state changes of the alert instances and the alerts sent to the notifiers.
+	StatePublisher StatePublisher
+}
+
+// RuleErrorNotifier is told about alert rules that fail to evaluate.
+type RuleErrorNotifier interface {
+	RuleEvaluationFailed(ctx context.Context, rule *models.AlertRule, evalErr error)
+}
+
+// StatePublisher is told about the alert instances of a rule that changed state after an
+// evaluation, and about the alerts of the rule that were sent to the notifiers.
+type StatePublisher interface {
+	StatesChanged(ctx context.Context, rule *models.AlertRule, transitions []StateTransition)
+	AlertsSent(ctx context.Context, rule *models.AlertRule, alerts definitions.PostableAlerts)
+}
+
+// StateTransition is the change of an alert instance from its previous state to its current one.
+type StateTransition struct {
+	*state.State
+	PreviousState       eval.State
+	PreviousStateReason string
 }
 
 // NewScheduler returns a new schedule.
@@ -154,7 +207,19 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		timeRegions:             cfg.TimeRegions,
+		ruleErrorNotifier:       cfg.RuleErrorNotifier,
+		statePublisher:          cfg.StatePublisher,
+		evaluationPausedFunc:    cfg.EvaluationPausedFunc,
 		schedulableAlertRules:   schedulableAlertRulesRegistry{rules: make(map[models.AlertRuleKey]*models.SchedulableAlertRule)},
+		diagnostics:             newEvaluationDiagnostics(cfg.C),
+		sampleWriter:            cfg.SampleWriter,
+	}
+	if cfg.DeduplicateQueries && expressionService != nil {
+		sch.expressionService = expressionService.WithQueryDataHandler(func(next backend.QueryDataHandler) backend.QueryDataHandler {
+			sch.queryDedup = newQueryDeduplicator(next, cfg.Metrics.QueryDeduplication)
+			return sch.queryDedup
+		})
 	}
 	return &sch
 }
@@ -312,6 +377,11 @@ func (sch *schedule) DroppedAlertmanagersFor(orgID int64) []*url.URL {
 	return s.DroppedAlertmanagers()
 }
 
+// SchedulerStatus returns the evaluation statistics of the rule groups of the organization.
+func (sch *schedule) SchedulerStatus(orgID int64) definitions.GettableSchedulerStatus {
+	return sch.diagnostics.status(orgID, sch.schedulableAlertRules.all())
+}
+
 // UpdateAlertRule looks for the active rule evaluation and commands it to update the rule
 func (sch *schedule) UpdateAlertRule(key models.AlertRuleKey) {
 	ruleInfo, err := sch.registry.get(key)
@@ -378,6 +448,10 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			start := time.Now().Round(0)
 			sch.metrics.BehindSeconds.Set(start.Sub(tick).Seconds())
 
+			if sch.queryDedup != nil {
+				sch.queryDedup.nextTick()
+			}
+
 			tickNum := tick.Unix() / int64(sch.baseInterval.Seconds())
 			disabledOrgs := make([]int64, 0, len(sch.disabledOrgs))
 			for disabledOrg := range sch.disabledOrgs {
@@ -404,14 +478,17 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 
 			type readyToRunItem struct {
 				key      models.AlertRuleKey
+				groupKey models.AlertRuleGroupKey
 				ruleName string
 				ruleInfo *alertRuleInfo
 				version  int64
 			}
 
 			readyToRun := make([]readyToRunItem, 0)
+			groups := make(map[models.AlertRuleGroupKey]struct{})
 			for _, item := range alertRules {
 				key := item.GetKey()
+				groups[item.GetGroupKey()] = struct{}{}
 				itemVersion := item.Version
 				ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
@@ -438,12 +515,18 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleName: item.Title, ruleInfo: ruleInfo, version: itemVersion})
+					readyToRun = append(readyToRun, readyToRunItem{key: key, groupKey: item.GetGroupKey(), ruleName: item.Title, ruleInfo: ruleInfo, version: itemVersion})
 				}
 
 				// remove the alert rule from the registered alert rules
 				delete(registeredDefinitions, key)
 			}
+			sch.diagnostics.forget(groups)
+
+			if sch.evaluationPausedFunc != nil && sch.evaluationPausedFunc() {
+				sch.log.Debug("alert rule evaluation is paused", "skipped", len(readyToRun))
+				readyToRun = nil
+			}
 
 			var step int64 = 0
 			if len(readyToRun) > 0 {
@@ -463,6 +546,7 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 						sch.log.Warn("Alert rule evaluation is too slow - dropped tick", "uid", item.key.UID, "org", item.key.OrgID, "time", tick)
 						orgID := fmt.Sprint(item.key.OrgID)
 						sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.ruleName).Inc()
+						sch.diagnostics.tickMissed(item.groupKey)
 					}
 				})
 			}
@@ -566,8 +650,36 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 	evaluate := func(ctx context.Context, r *models.AlertRule, attempt int64, e *evaluation) error {
 		logger := logger.New("version", r.Version, "attempt", attempt, "now", e.scheduledAt)
+
+		if !sch.inTimeRegion(ctx, r, e.scheduledAt, logger) {
+			logger.Debug("skipping evaluation outside of the time region of the rule")
+			return nil
+		}
+
+		sch.diagnostics.evaluationStarted(r, e.scheduledAt)
+		defer sch.diagnostics.evaluationFinished(key)
+
 		start := sch.clock.Now()
 
+		if r.IsRecording() {
+			if sch.sampleWriter == nil {
+				logger.Debug("skipping evaluation of recording rule, recording rules are disabled")
+				return nil
+			}
+			err := sch.record(ctx, r, e.scheduledAt)
+			dur := sch.clock.Now().Sub(start)
+			evalTotal.Inc()
+			evalDuration.Observe(dur.Seconds())
+			if err != nil {
+				evalTotalFailures.Inc()
+				logger.Error("failed to evaluate recording rule", "duration", dur, "err", err)
+				sch.notifyRuleError(ctx, r, err)
+				return err
+			}
+			logger.Debug("recording rule evaluated", "duration", dur)
+			return nil
+		}
+
 		condition := models.Condition{
 			Condition: r.Condition,
 			OrgID:     r.OrgID,
@@ -581,15 +693,27 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			evalTotalFailures.Inc()
 			// consider saving alert instance on error
 			logger.Error("failed to evaluate alert rule", "duration", dur, "err", err)
+			sch.notifyRuleError(ctx, r, err)
 			return err
 		}
 		logger.Debug("alert rule evaluated", "results", results, "duration", dur)
 
+		previous := sch.snapshotStates(r)
 		processedStates := sch.stateManager.ProcessEvalResults(ctx, r, results)
+		for _, st := range processedStates {
+			if st.State == eval.Error {
+				sch.notifyRuleError(ctx, r, st.Error)
+				break
+			}
+		}
 		sch.saveAlertStates(ctx, processedStates)
+		sch.publishStateChanges(ctx, r, previous, processedStates)
 		alerts := FromAlertStateToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 
 		notify(alerts, logger)
+		if sch.statePublisher != nil && len(alerts.PostableAlerts) > 0 {
+			sch.statePublisher.AlertsSent(ctx, r, alerts)
+		}
 		return nil
 	}
 
@@ -713,3 +837,62 @@ func (sch *schedule) stopApplied(alertDefKey models.AlertRuleKey) {
 
 	sch.stopAppliedFunc(alertDefKey)
 }
+
+// inTimeRegion returns false if the rule is restricted to a time region calendar that is
+// not active at t. Rules are evaluated if the calendar cannot be evaluated, so that a
+// deleted or broken calendar does not silence the rule.
+func (sch *schedule) inTimeRegion(ctx context.Context, r *models.AlertRule, t time.Time, logger log.Logger) bool {
+	uid := r.Annotations[models.TimeRegionCalendarAnnotation]
+	if uid == "" || sch.timeRegions == nil {
+		return true
+	}
+
+	active, err := sch.timeRegions.IsActive(ctx, r.OrgID, uid, t)
+	if err != nil {
+		logger.Warn("failed to evaluate time region calendar of the rule", "calendar", uid, "err", err)
+		return true
+	}
+	return active
+}
+
+// snapshotStates returns the current state of the alert instances of the rule by cache id,
+// the states in the cache are updated in place by the evaluation. It returns nil when there
+// is nobody to publish the state changes to.
+func (sch *schedule) snapshotStates(r *models.AlertRule) map[string]StateTransition {
+	if sch.statePublisher == nil {
+		return nil
+	}
+	states := sch.stateManager.GetStatesForRuleUID(r.OrgID, r.UID)
+	snapshot := make(map[string]StateTransition, len(states))
+	for _, s := range states {
+		snapshot[s.CacheId] = StateTransition{PreviousState: s.State, PreviousStateReason: s.StateReason}
+	}
+	return snapshot
+}
+
+// publishStateChanges publishes the alert instances whose state or reason differs from the snapshot.
+// Alert instances that are new are considered to have been normal.
+func (sch *schedule) publishStateChanges(ctx context.Context, r *models.AlertRule, previous map[string]StateTransition, states []*state.State) {
+	if sch.statePublisher == nil {
+		return
+	}
+	var transitions []StateTransition
+	for _, s := range states {
+		t := previous[s.CacheId]
+		if t.PreviousState == s.State && t.PreviousStateReason == s.StateReason {
+			continue
+		}
+		t.State = s
+		transitions = append(transitions, t)
+	}
+	if len(transitions) > 0 {
+		sch.statePublisher.StatesChanged(ctx, r, transitions)
+	}
+}
+
+func (sch *schedule) notifyRuleError(ctx context.Context, r *models.AlertRule, evalErr error) {
+	if sch.ruleErrorNotifier == nil {
+		return
+	}
+	sch.ruleErrorNotifier.RuleEvaluationFailed(ctx, r, evalErr)
+}
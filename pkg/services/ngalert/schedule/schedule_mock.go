@@ -5,6 +5,8 @@ package schedule
 import (
 	context "context"
 
+	definitions "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	mock "github.com/stretchr/testify/mock"
 
@@ -83,6 +85,20 @@ func (_m *FakeScheduleService) Run(_a0 context.Context) error {
 	return r0
 }
 
+// SchedulerStatus provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) SchedulerStatus(orgID int64) definitions.GettableSchedulerStatus {
+	ret := _m.Called(orgID)
+
+	var r0 definitions.GettableSchedulerStatus
+	if rf, ok := ret.Get(0).(func(int64) definitions.GettableSchedulerStatus); ok {
+		r0 = rf(orgID)
+	} else {
+		r0 = ret.Get(0).(definitions.GettableSchedulerStatus)
+	}
+
+	return r0
+}
+
 // Unpause provides a mock function with given fields:
 func (_m *FakeScheduleService) Unpause() error {
 	ret := _m.Called()
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -140,6 +141,7 @@ func TestAlertingTicker(t *testing.T) {
 
 	mockedClock := clock.NewMock()
 	baseInterval := time.Second
+	var paused int32
 
 	schedCfg := schedule.SchedulerCfg{
 		C:            mockedClock,
@@ -158,6 +160,9 @@ func TestAlertingTicker(t *testing.T) {
 		DisabledOrgs: map[int64]struct{}{
 			disabledOrgID: {},
 		},
+		EvaluationPausedFunc: func() bool {
+			return atomic.LoadInt32(&paused) == 1
+		},
 	}
 	st := state.NewManager(schedCfg.Logger, testMetrics.GetStateMetrics(), nil, dbstore, dbstore, ng.SQLStore, &dashboards.FakeDashboardService{}, &image.NoopImageService{})
 	appUrl := &url.URL{
@@ -239,6 +244,22 @@ func TestAlertingTicker(t *testing.T) {
 		tick := advanceClock(t, mockedClock)
 		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
 	})
+
+	atomic.StoreInt32(&paused, 1)
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{}
+	t.Run(fmt.Sprintf("on 9th tick alert rules: %s should be evaluated while evaluation is paused", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
+
+	atomic.StoreInt32(&paused, 0)
+
+	expectedAlertRulesEvaluated = []models.AlertRuleKey{alerts[2].GetKey()}
+	t.Run(fmt.Sprintf("on 10th tick alert rules: %s should be evaluated", concatenate(expectedAlertRulesEvaluated)), func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, expectedAlertRulesEvaluated...)
+	})
 }
 
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -818,6 +819,66 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 	})
 }
 
+type fakeStatePublisher struct {
+	mtx         sync.Mutex
+	transitions [][]StateTransition
+	sent        []definitions.PostableAlerts
+}
+
+func (f *fakeStatePublisher) StatesChanged(_ context.Context, _ *models.AlertRule, transitions []StateTransition) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.transitions = append(f.transitions, transitions)
+}
+
+func (f *fakeStatePublisher) AlertsSent(_ context.Context, _ *models.AlertRule, alerts definitions.PostableAlerts) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.sent = append(f.sent, alerts)
+}
+
+func TestSchedule_statePublisher(t *testing.T) {
+	ruleStore := store.NewFakeRuleStore(t)
+	sch, _ := setupScheduler(t, ruleStore, &store.FakeInstanceStore{}, store.NewFakeAdminConfigStore(t), nil)
+	evalAppliedChan := make(chan time.Time)
+	sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+		evalAppliedChan <- t
+	}
+	publisher := &fakeStatePublisher{}
+	sch.statePublisher = publisher
+
+	rule := CreateTestAlertRule(t, ruleStore, 10, rand.Int63(), eval.Alerting)
+	evalChan := make(chan *evaluation)
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan struct{}))
+	}()
+
+	evalChan <- &evaluation{scheduledAt: time.Now(), version: rule.Version}
+	waitForTimeChannel(t, evalAppliedChan)
+
+	publisher.mtx.Lock()
+	require.Len(t, publisher.transitions, 1)
+	require.Len(t, publisher.transitions[0], 1)
+	transition := publisher.transitions[0][0]
+	require.Equal(t, eval.Normal, transition.PreviousState)
+	require.Equal(t, eval.Alerting, transition.State.State)
+	require.Equal(t, rule.UID, transition.AlertRuleUID)
+	require.Len(t, publisher.sent, 1)
+	require.Len(t, publisher.sent[0].PostableAlerts, 1)
+	publisher.mtx.Unlock()
+
+	t.Run("it should not publish instances that keep their state", func(t *testing.T) {
+		evalChan <- &evaluation{scheduledAt: time.Now(), version: rule.Version}
+		waitForTimeChannel(t, evalAppliedChan)
+
+		publisher.mtx.Lock()
+		defer publisher.mtx.Unlock()
+		require.Len(t, publisher.transitions, 1)
+	})
+}
+
 func TestSchedule_UpdateAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should call Update", func(t *testing.T) {
@@ -915,7 +976,7 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 	m := metrics.NewNGAlert(registry)
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
 	decryptFn := secretsService.GetDecryptedValue
-	moa, err := notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}, &notifier.FakeKVStore{}, provisioning.NewFakeProvisioningStore(), decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"), secretsService)
+	moa, err := notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}, &notifier.FakeKVStore{}, provisioning.NewFakeProvisioningStore(), decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, nil, log.New("testlogger"), secretsService)
 	require.NoError(t, err)
 
 	schedCfg := SchedulerCfg{
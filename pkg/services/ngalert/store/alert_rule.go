@@ -90,6 +90,12 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("deleted alert instances", "count", rows)
+
+		rows, err = sess.Table("alert_rule_template_instance").Where("org_id = ?", orgID).In("rule_uid", ruleUID).Delete(ngmodels.AlertRuleTemplateInstance{})
+		if err != nil {
+			return err
+		}
+		logger.Debug("deleted alert rule template instances", "count", rows)
 		return nil
 	})
 }
@@ -174,6 +180,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,
 			})
 		}
 		if len(newRules) > 0 {
@@ -241,6 +248,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GetAlertRuleTemplate returns the alert rule template of an organization by its UID.
+// It returns ErrAlertRuleTemplateNotFound if the template is not found.
+func (st DBstore) GetAlertRuleTemplate(ctx context.Context, query *ngmodels.GetAlertRuleTemplateQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		tmpl := ngmodels.AlertRuleTemplate{}
+		has, err := sess.Table("alert_rule_template").Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(&tmpl)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleTemplateNotFound
+		}
+		query.Result = &tmpl
+		return nil
+	})
+}
+
+// ListAlertRuleTemplates returns the alert rule templates of an organization sorted by title.
+func (st DBstore) ListAlertRuleTemplates(ctx context.Context, query *ngmodels.ListAlertRuleTemplatesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		templates := make([]*ngmodels.AlertRuleTemplate, 0)
+		if err := sess.Table("alert_rule_template").Where("org_id = ?", query.OrgID).Asc("title", "id").Find(&templates); err != nil {
+			return err
+		}
+		query.Result = templates
+		return nil
+	})
+}
+
+// InsertAlertRuleTemplate creates an alert rule template, with a generated UID if the template has none.
+func (st DBstore) InsertAlertRuleTemplate(ctx context.Context, tmpl *ngmodels.AlertRuleTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if tmpl.UID == "" {
+			tmpl.UID = util.GenerateShortUID()
+		}
+		if _, err := sess.Table("alert_rule_template").Insert(tmpl); err != nil {
+			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return fmt.Errorf("%w: an alert rule template with the uid %q already exists", ngmodels.ErrAlertRuleTemplateFailedValidation, tmpl.UID)
+			}
+			return fmt.Errorf("failed to create alert rule template: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateAlertRuleTemplate updates an existing alert rule template.
+func (st DBstore) UpdateAlertRuleTemplate(ctx context.Context, tmpl *ngmodels.AlertRuleTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		updated, err := sess.Table("alert_rule_template").ID(tmpl.ID).AllCols().Update(tmpl)
+		if err != nil {
+			return fmt.Errorf("failed to update alert rule template: %w", err)
+		}
+		if updated == 0 {
+			return ngmodels.ErrAlertRuleTemplateNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteAlertRuleTemplate deletes an alert rule template. The alert rules
+// created from the template are kept, but they are no longer linked to it.
+func (st DBstore) DeleteAlertRuleTemplate(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM alert_rule_template WHERE org_id = ? AND uid = ?", orgID, uid); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM alert_rule_template_instance WHERE org_id = ? AND template_uid = ?", orgID, uid)
+		return err
+	})
+}
+
+// GetAlertRuleTemplateInstance returns the link of an alert rule to the template it was created from.
+// It returns ErrAlertRuleTemplateInstanceNotFound if the rule was not created from a template.
+func (st DBstore) GetAlertRuleTemplateInstance(ctx context.Context, query *ngmodels.GetAlertRuleTemplateInstanceQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		instance := ngmodels.AlertRuleTemplateInstance{}
+		has, err := sess.Table("alert_rule_template_instance").Where("org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Get(&instance)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleTemplateInstanceNotFound
+		}
+		query.Result = &instance
+		return nil
+	})
+}
+
+// ListAlertRuleTemplateInstances returns the links of the alert rules created from a template.
+func (st DBstore) ListAlertRuleTemplateInstances(ctx context.Context, query *ngmodels.ListAlertRuleTemplateInstancesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		instances := make([]*ngmodels.AlertRuleTemplateInstance, 0)
+		if err := sess.Table("alert_rule_template_instance").Where("org_id = ? AND template_uid = ?", query.OrgID, query.TemplateUID).Asc("id").Find(&instances); err != nil {
+			return err
+		}
+		query.Result = instances
+		return nil
+	})
+}
+
+// SaveAlertRuleTemplateInstance creates or updates the link of an alert rule to the template it was created from.
+func (st DBstore) SaveAlertRuleTemplateInstance(ctx context.Context, instance *ngmodels.AlertRuleTemplateInstance) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if instance.ID == 0 {
+			if _, err := sess.Table("alert_rule_template_instance").Insert(instance); err != nil {
+				return fmt.Errorf("failed to create alert rule template instance: %w", err)
+			}
+			return nil
+		}
+		if _, err := sess.Table("alert_rule_template_instance").ID(instance.ID).AllCols().Update(instance); err != nil {
+			return fmt.Errorf("failed to update alert rule template instance: %w", err)
+		}
+		return nil
+	})
+}
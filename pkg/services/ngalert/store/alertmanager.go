@@ -19,6 +19,10 @@ var (
 	ErrVersionLockedObjectNotFound = fmt.Errorf("could not find object using provided id and hash")
 )
 
+// MaxAlertmanagerConfigurationHistory is the maximum number of versions of the alertmanager configuration
+// returned by GetAlertmanagerConfigurationHistory.
+const MaxAlertmanagerConfigurationHistory = 100
+
 // GetLatestAlertmanagerConfiguration returns the lastest version of the alertmanager configuration.
 // It returns ErrNoAlertmanagerConfiguration if no configuration is found.
 func (st *DBstore) GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error {
@@ -55,6 +59,42 @@ func (st *DBstore) GetAllLatestAlertmanagerConfiguration(ctx context.Context) ([
 	return result, nil
 }
 
+// GetAlertmanagerConfigurationHistory returns the versions of the alertmanager configuration of an organization,
+// the most recent first. Every saved configuration is kept as a version.
+func (st *DBstore) GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		limit := query.Limit
+		if limit <= 0 || limit > MaxAlertmanagerConfigurationHistory {
+			limit = MaxAlertmanagerConfigurationHistory
+		}
+		configs := make([]*models.AlertConfiguration, 0)
+		if err := sess.Table("alert_configuration").Desc("id").Where("org_id = ?", query.OrgID).Limit(limit).Find(&configs); err != nil {
+			return err
+		}
+		query.Result = configs
+		return nil
+	})
+}
+
+// GetAlertmanagerConfigurationVersion returns a version of the alertmanager configuration of an organization.
+// It returns ErrNoAlertmanagerConfiguration if the version is not found.
+func (st *DBstore) GetAlertmanagerConfigurationVersion(ctx context.Context, query *models.GetAlertmanagerConfigurationVersionQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		c := &models.AlertConfiguration{}
+		ok, err := sess.Where("id = ? AND org_id = ?", query.ID, query.OrgID).Get(c)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return ErrNoAlertmanagerConfiguration
+		}
+
+		query.Result = c
+		return nil
+	})
+}
+
 // SaveAlertmanagerConfiguration creates an alertmanager configuration.
 func (st DBstore) SaveAlertmanagerConfiguration(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error {
 	return st.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error { return nil })
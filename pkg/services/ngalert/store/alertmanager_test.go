@@ -82,3 +82,55 @@ func TestIntegrationAlertManagerHash(t *testing.T) {
 		require.EqualError(t, ErrVersionLockedObjectNotFound, err.Error())
 	})
 }
+
+func TestIntegrationAlertmanagerConfigurationHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	store := &DBstore{
+		SQLStore: sqlStore,
+	}
+	for _, config := range []string{"config-1", "config-2", "config-3"} {
+		for _, orgID := range []int64{1, 2} {
+			err := store.SaveAlertmanagerConfiguration(context.Background(), &models.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: fmt.Sprintf("%s-org-%d", config, orgID),
+				ConfigurationVersion:      "v1",
+				OrgID:                     orgID,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("history should return the versions of the organization, the most recent first", func(t *testing.T) {
+		query := &models.GetAlertmanagerConfigurationHistoryQuery{OrgID: 1}
+		err := store.GetAlertmanagerConfigurationHistory(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 3)
+		require.Equal(t, "config-3-org-1", query.Result[0].AlertmanagerConfiguration)
+		require.Equal(t, "config-2-org-1", query.Result[1].AlertmanagerConfiguration)
+		require.Equal(t, "config-1-org-1", query.Result[2].AlertmanagerConfiguration)
+	})
+
+	t.Run("history should be limited", func(t *testing.T) {
+		query := &models.GetAlertmanagerConfigurationHistoryQuery{OrgID: 2, Limit: 2}
+		err := store.GetAlertmanagerConfigurationHistory(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+		require.Equal(t, "config-3-org-2", query.Result[0].AlertmanagerConfiguration)
+	})
+
+	t.Run("version should be returned by id", func(t *testing.T) {
+		history := &models.GetAlertmanagerConfigurationHistoryQuery{OrgID: 1}
+		require.NoError(t, store.GetAlertmanagerConfigurationHistory(context.Background(), history))
+
+		query := &models.GetAlertmanagerConfigurationVersionQuery{OrgID: 1, ID: history.Result[2].ID}
+		err := store.GetAlertmanagerConfigurationVersion(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, "config-1-org-1", query.Result.AlertmanagerConfiguration)
+
+		query = &models.GetAlertmanagerConfigurationVersionQuery{OrgID: 2, ID: history.Result[2].ID}
+		err = store.GetAlertmanagerConfigurationVersion(context.Background(), query)
+		require.ErrorIs(t, err, ErrNoAlertmanagerConfiguration)
+	})
+}
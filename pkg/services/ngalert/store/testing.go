@@ -198,6 +198,8 @@ func (f *FakeRuleStore) GetAlertRulesForScheduling(_ context.Context, q *models.
 				OrgID:           rule.OrgID,
 				IntervalSeconds: rule.IntervalSeconds,
 				Version:         rule.Version,
+				NamespaceUID:    rule.NamespaceUID,
+				RuleGroup:       rule.RuleGroup,
 			})
 		}
 	}
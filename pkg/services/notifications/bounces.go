@@ -0,0 +1,206 @@
+package notifications
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ParseBounces returns the bounces in a notification the mail provider sent to
+// the bounce webhook. Notifications without bounces, such as the deliveries
+// reported by the providers, return no bounces.
+func ParseBounces(ctx context.Context, cfg setting.SmtpSettings, provider string, body []byte) ([]*emaildelivery.BounceCommand, error) {
+	var bounces []*emaildelivery.BounceCommand
+	var err error
+	switch provider {
+	case "sendgrid":
+		bounces, err = parseSendGridBounces(body)
+	case "ses":
+		bounces, err = parseSESBounces(ctx, body)
+	case "mailgun":
+		bounces, err = parseMailgunBounces(cfg.Mailgun, body)
+	default:
+		return nil, fmt.Errorf("%w: %q", emaildelivery.ErrUnknownProvider, provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", emaildelivery.ErrInvalidBounce, err)
+	}
+	return bounces, nil
+}
+
+// parseSendGridBounces parses the events of the SendGrid event webhook.
+func parseSendGridBounces(body []byte) ([]*emaildelivery.BounceCommand, error) {
+	var events []struct {
+		Event       string `json:"event"`
+		Email       string `json:"email"`
+		SGMessageID string `json:"sg_message_id"`
+		Reason      string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+
+	bounces := make([]*emaildelivery.BounceCommand, 0)
+	for _, event := range events {
+		if event.Event != "bounce" && event.Event != "dropped" {
+			continue
+		}
+		// the id of the event starts with the X-Message-Id returned when the email was sent
+		messageID := event.SGMessageID
+		if i := strings.Index(messageID, "."); i >= 0 {
+			messageID = messageID[:i]
+		}
+		bounces = append(bounces, &emaildelivery.BounceCommand{
+			Provider:  "sendgrid",
+			MessageID: messageID,
+			Recipient: event.Email,
+			Reason:    event.Reason,
+		})
+	}
+	return bounces, nil
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Mail struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+}
+
+// parseSESBounces parses the SES notifications published to an SNS topic,
+// either wrapped in the SNS message or delivered raw. The subscriptions of
+// the webhook to the topic are confirmed.
+func parseSESBounces(ctx context.Context, body []byte) ([]*emaildelivery.BounceCommand, error) {
+	var envelope struct {
+		Type         string `json:"Type"`
+		Message      string `json:"Message"`
+		SubscribeURL string `json:"SubscribeURL"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	notification := body
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, confirmSNSSubscription(ctx, envelope.SubscribeURL)
+	case "Notification":
+		notification = []byte(envelope.Message)
+	}
+
+	var n sesNotification
+	if err := json.Unmarshal(notification, &n); err != nil {
+		return nil, err
+	}
+	bounces := make([]*emaildelivery.BounceCommand, 0)
+	// transient bounces are retried by SES
+	if n.NotificationType != "Bounce" || n.Bounce.BounceType != "Permanent" {
+		return bounces, nil
+	}
+	for _, recipient := range n.Bounce.BouncedRecipients {
+		bounces = append(bounces, &emaildelivery.BounceCommand{
+			Provider:  "ses",
+			MessageID: n.Mail.MessageID,
+			Recipient: recipient.EmailAddress,
+			Reason:    recipient.DiagnosticCode,
+		})
+	}
+	return bounces, nil
+}
+
+func confirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil {
+		return err
+	}
+	// only follow the confirmation links of SNS
+	if u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("invalid subscribe URL %q", subscribeURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := netClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return providerResponseError(resp)
+	}
+	return nil
+}
+
+// parseMailgunBounces parses the events of the Mailgun webhooks, and verifies
+// their signature when the webhook signing key is configured.
+func parseMailgunBounces(cfg setting.MailgunSettings, body []byte) ([]*emaildelivery.BounceCommand, error) {
+	var webhook struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+		EventData struct {
+			Event     string `json:"event"`
+			Severity  string `json:"severity"`
+			Recipient string `json:"recipient"`
+			Message   struct {
+				Headers struct {
+					MessageID string `json:"message-id"`
+				} `json:"headers"`
+			} `json:"message"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, err
+	}
+
+	if cfg.WebhookSigningKey != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.WebhookSigningKey))
+		mac.Write([]byte(webhook.Signature.Timestamp + webhook.Signature.Token))
+		signature, err := hex.DecodeString(webhook.Signature.Signature)
+		if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, fmt.Errorf("invalid signature")
+		}
+	}
+
+	bounces := make([]*emaildelivery.BounceCommand, 0)
+	event := webhook.EventData
+	// temporary failures are retried by Mailgun
+	if event.Event != "failed" || event.Severity != "permanent" {
+		return bounces, nil
+	}
+	reason := event.DeliveryStatus.Message
+	if reason == "" {
+		reason = event.DeliveryStatus.Description
+	}
+	bounces = append(bounces, &emaildelivery.BounceCommand{
+		Provider:  "mailgun",
+		MessageID: event.Message.Headers.MessageID,
+		Recipient: event.Recipient,
+		Reason:    reason,
+	})
+	return bounces, nil
+}
@@ -5,6 +5,10 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// defaultEmailHeaderURL is the header image of the emails of the organizations
+// without branding.
+const defaultEmailHeaderURL = "https://grafana.com/assets/img/logo_new_transparent_200x48.png"
+
 // AttachedFile struct represents email attached files.
 type AttachedFile struct {
 	Name    string
@@ -30,6 +34,8 @@ func setDefaultTemplateData(cfg *setting.Cfg, data map[string]interface{}, u *mo
 	data["BuildStamp"] = setting.BuildStamp
 	data["EmailCodeValidHours"] = cfg.EmailCodeValidMinutes / 60
 	data["Subject"] = map[string]interface{}{}
+	data["EmailHeaderUrl"] = defaultEmailHeaderURL
+	data["EmailFooterText"] = ""
 	if u != nil {
 		data["Name"] = u.NameOrFallback()
 	}
@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+)
+
+var ErrInvalidEmailTemplate = errors.New("invalid email template")
+
+// previewContentTypes are rendered by previews, whatever the content types of
+// the emails are.
+var previewContentTypes = []string{"text/html", "text/plain"}
+
+type RenderEmailCommand struct {
+	OrgId    int64
+	Template string
+	// Draft is rendered instead of the template the organization saved.
+	Draft *emailtemplates.EmailTemplate
+	// Data overrides the example data of the template.
+	Data map[string]interface{}
+}
+
+type SendTestEmailCommand struct {
+	RenderEmailCommand
+	To []string
+}
+
+// RenderEmail renders an email template of the organization with example data,
+// so that the templates can be previewed before they are saved.
+func (ns *NotificationService) RenderEmail(ctx context.Context, cmd *RenderEmailCommand) (*Message, error) {
+	return ns.renderEmail(ctx, cmd, nil, previewContentTypes)
+}
+
+// SendTestEmail renders an email template of the organization with example
+// data, and sends it to the recipients.
+func (ns *NotificationService) SendTestEmail(ctx context.Context, cmd *SendTestEmailCommand) error {
+	if !ns.Cfg.Smtp.Enabled {
+		return models.ErrSmtpNotEnabled
+	}
+
+	message, err := ns.renderEmail(ctx, &cmd.RenderEmailCommand, cmd.To, ns.Cfg.Smtp.ContentTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = ns.Send(message)
+	return err
+}
+
+func (ns *NotificationService) renderEmail(ctx context.Context, cmd *RenderEmailCommand, to []string, contentTypes []string) (*Message, error) {
+	definition, ok := emailtemplates.GetDefinition(cmd.Template)
+	if !ok {
+		return nil, emailtemplates.ErrUnknownEmailTemplate
+	}
+
+	data := definition.ExampleData()
+	for k, v := range cmd.Data {
+		data[k] = v
+	}
+
+	custom := cmd.Draft
+	if custom == nil {
+		custom = ns.getOrgEmailTemplate(ctx, cmd.OrgId, cmd.Template)
+	}
+
+	return ns.renderEmailMessage(ctx, &models.SendEmailCommand{
+		To:       to,
+		Template: cmd.Template,
+		Data:     data,
+		OrgId:    cmd.OrgId,
+	}, custom, contentTypes)
+}
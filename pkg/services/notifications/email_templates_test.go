@@ -0,0 +1,126 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
+	"github.com/grafana/grafana/pkg/services/emailtemplates/emailtemplatestest"
+)
+
+func TestOrgEmailTemplates(t *testing.T) {
+	bus := newBus(t)
+
+	createSutWithTemplates := func(t *testing.T, templates emailtemplates.Service) (*NotificationService, *FakeMailer) {
+		t.Helper()
+		mailer := NewFakeMailer()
+		ns, err := ProvideService(bus, createSmtpConfig(), mailer, nil, templates, nil)
+		require.NoError(t, err)
+		return ns, mailer
+	}
+
+	resetPassword := func(orgID int64) *models.SendEmailCommandSync {
+		return &models.SendEmailCommandSync{
+			SendEmailCommand: models.SendEmailCommand{
+				To:       []string{"jane@grafana.com"},
+				Template: "reset_password",
+				OrgId:    orgID,
+				Data:     map[string]interface{}{"Name": "Jane", "Code": "1234"},
+			},
+		}
+	}
+
+	t.Run("When the organization customized the template", func(t *testing.T) {
+		templates := emailtemplatestest.NewEmailTemplatesServiceFake()
+		templates.ExpectedTemplate = &emailtemplates.EmailTemplate{
+			Name:    "reset_password",
+			Subject: "ACME password reset for {{.Name}}",
+			Text:    "Use the code {{.Code}} to reset your password.",
+		}
+		ns, mailer := createSutWithTemplates(t, templates)
+
+		err := ns.SendEmailCommandHandlerSync(context.Background(), resetPassword(1))
+		require.NoError(t, err)
+
+		require.Len(t, mailer.Sent, 1)
+		sent := mailer.Sent[0]
+		require.Equal(t, "ACME password reset for Jane", sent.Subject)
+		require.Equal(t, "Use the code 1234 to reset your password.", sent.Body["text/plain"])
+		// the HTML body falls back to the default template
+		require.Contains(t, sent.Body["text/html"], "1234")
+	})
+
+	t.Run("When the organization did not customize the template", func(t *testing.T) {
+		templates := emailtemplatestest.NewEmailTemplatesServiceFake()
+		templates.ExpectedError = emailtemplates.ErrEmailTemplateNotFound
+		ns, mailer := createSutWithTemplates(t, templates)
+
+		err := ns.SendEmailCommandHandlerSync(context.Background(), resetPassword(1))
+		require.NoError(t, err)
+
+		require.Len(t, mailer.Sent, 1)
+		require.Equal(t, "Reset your Grafana password - Jane", mailer.Sent[0].Subject)
+	})
+
+	t.Run("When previewing a draft", func(t *testing.T) {
+		ns, _ := createSutWithTemplates(t, emailtemplatestest.NewEmailTemplatesServiceFake())
+
+		message, err := ns.RenderEmail(context.Background(), &RenderEmailCommand{
+			OrgId:    1,
+			Template: "new_user_invite",
+			Draft: &emailtemplates.EmailTemplate{
+				Name: "new_user_invite",
+				HTML: `<p>{{.InvitedBy}} invited you to join {{.OrgName}}</p>`,
+			},
+			Data: map[string]interface{}{"OrgName": "ACME"},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "<p>admin invited you to join ACME</p>", message.Body["text/html"])
+		require.Contains(t, message.Body["text/plain"], "ACME")
+		require.NotEmpty(t, message.Subject)
+	})
+
+	t.Run("When previewing the alert notification template", func(t *testing.T) {
+		ns, _ := createSutWithTemplates(t, nil)
+
+		message, err := ns.RenderEmail(context.Background(), &RenderEmailCommand{Template: "ng_alert_notification"})
+		require.NoError(t, err)
+
+		require.Contains(t, message.Body["text/html"], "HighLatency")
+		require.Contains(t, message.Body["text/plain"], "HighLatency")
+	})
+
+	t.Run("When the draft fails to parse", func(t *testing.T) {
+		ns, _ := createSutWithTemplates(t, nil)
+
+		_, err := ns.RenderEmail(context.Background(), &RenderEmailCommand{
+			Template: "reset_password",
+			Draft:    &emailtemplates.EmailTemplate{Name: "reset_password", Text: "{{.Code"},
+		})
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate)
+	})
+
+	t.Run("When previewing a template that cannot be customized", func(t *testing.T) {
+		ns, _ := createSutWithTemplates(t, nil)
+
+		_, err := ns.RenderEmail(context.Background(), &RenderEmailCommand{Template: "signup_started"})
+		require.ErrorIs(t, err, emailtemplates.ErrUnknownEmailTemplate)
+	})
+
+	t.Run("When sending a test email", func(t *testing.T) {
+		ns, mailer := createSutWithTemplates(t, nil)
+
+		err := ns.SendTestEmail(context.Background(), &SendTestEmailCommand{
+			RenderEmailCommand: RenderEmailCommand{OrgId: 1, Template: "invited_to_org"},
+			To:                 []string{"admin@grafana.com"},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, mailer.Sent, 1)
+		require.Equal(t, []string{"admin@grafana.com"}, mailer.Sent[0].To)
+	})
+}
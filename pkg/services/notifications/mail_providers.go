@@ -0,0 +1,138 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// mailProvider sends emails with an SMTP server or the API of a mail provider.
+type mailProvider interface {
+	name() string
+	// sendMessage returns the id the provider gave to the message, if any.
+	sendMessage(ctx context.Context, msg *Message) (string, error)
+}
+
+func ProvideSmtpService(cfg *setting.Cfg, deliveries emaildelivery.Service) (Mailer, error) {
+	logger := log.New("mailer")
+	providers, err := newMailProviders(cfg.Smtp)
+	if err != nil {
+		// the providers are only required when emails are enabled
+		if cfg.Smtp.Enabled {
+			return nil, err
+		}
+		logger.Warn("Invalid mail providers", "error", err)
+	}
+	if len(providers) == 0 {
+		smtp, _ := NewSmtpClient(cfg.Smtp)
+		providers = []mailProvider{smtp}
+	}
+
+	return &failoverMailer{
+		providers:  providers,
+		deliveries: deliveries,
+		log:        logger,
+	}, nil
+}
+
+func newMailProviders(cfg setting.SmtpSettings) ([]mailProvider, error) {
+	providers := make([]mailProvider, 0, len(cfg.Providers))
+	for _, name := range cfg.Providers {
+		var provider mailProvider
+		var err error
+		switch name {
+		case "smtp":
+			provider, err = NewSmtpClient(cfg)
+		case "sendgrid":
+			provider, err = newSendGridClient(cfg)
+		case "ses":
+			provider, err = newSESClient(cfg)
+		case "mailgun":
+			provider, err = newMailgunClient(cfg)
+		default:
+			err = fmt.Errorf("%w: %q", emaildelivery.ErrUnknownProvider, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// failoverMailer sends each message with the first provider that succeeds,
+// in the order of the configured providers, and records every attempt in the
+// delivery log.
+type failoverMailer struct {
+	providers  []mailProvider
+	deliveries emaildelivery.Service
+	log        log.Logger
+}
+
+func (m *failoverMailer) Send(messages ...*Message) (int, error) {
+	sentEmailsCount := 0
+	var err error
+	for _, msg := range messages {
+		if innerError := m.sendMessage(msg); innerError != nil {
+			err = innerError
+			continue
+		}
+
+		sentEmailsCount++
+	}
+
+	return sentEmailsCount, err
+}
+
+func (m *failoverMailer) sendMessage(msg *Message) error {
+	ctx := context.Background()
+	var err error
+	for i, provider := range m.providers {
+		messageID, sendErr := provider.sendMessage(ctx, msg)
+		countEmailSent(sendErr)
+		m.record(ctx, provider.name(), messageID, msg, sendErr)
+		if sendErr == nil {
+			if i > 0 {
+				m.log.Info("Sent email with failover mail provider", "provider", provider.name(), "to", strings.Join(msg.To, ";"))
+			}
+			return nil
+		}
+
+		if len(m.providers) == 1 {
+			return sendErr
+		}
+		m.log.Warn("Failed to send email with mail provider", "provider", provider.name(), "error", sendErr)
+		err = fmt.Errorf("%s: %w", provider.name(), sendErr)
+	}
+	return err
+}
+
+func (m *failoverMailer) record(ctx context.Context, provider string, messageID string, msg *Message, sendErr error) {
+	if m.deliveries == nil {
+		return
+	}
+	err := m.deliveries.Record(ctx, &emaildelivery.RecordCommand{
+		Provider:   provider,
+		MessageID:  messageID,
+		Recipients: msg.To,
+		Subject:    msg.Subject,
+		Error:      sendErr,
+	})
+	if err != nil {
+		m.log.Error("Failed to record email delivery", "provider", provider, "error", err)
+	}
+}
+
+func countEmailSent(err error) {
+	emailsSentTotal.Inc()
+	// As gomail does not returned typed errors we have to parse the error
+	// to catch invalid error when the address is invalid.
+	// https://github.com/go-gomail/gomail/blob/81ebce5c23dfd25c6c67194b37d3dd3f338c98b1/send.go#L113
+	if err != nil && !strings.Contains(err.Error(), "gomail: invalid address") {
+		emailsSentFailed.Inc()
+	}
+}
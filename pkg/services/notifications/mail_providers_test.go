@@ -0,0 +1,225 @@
+package notifications
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/emaildelivery"
+	"github.com/grafana/grafana/pkg/services/emaildelivery/emaildeliverytest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeMailProvider struct {
+	providerName string
+	messageID    string
+	err          error
+	sent         int
+}
+
+func (p *fakeMailProvider) name() string {
+	return p.providerName
+}
+
+func (p *fakeMailProvider) sendMessage(_ context.Context, _ *Message) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.sent++
+	return p.messageID, nil
+}
+
+func TestFailoverMailer(t *testing.T) {
+	message := &Message{To: []string{"to@example.com"}, Subject: "subject"}
+
+	t.Run("Sends with the next provider when a provider fails", func(t *testing.T) {
+		deliveries := emaildeliverytest.NewEmailDeliveryServiceFake()
+		sendgrid := &fakeMailProvider{providerName: "sendgrid", err: errors.New("unauthorized")}
+		ses := &fakeMailProvider{providerName: "ses", messageID: "ses-id"}
+		mailer := &failoverMailer{providers: []mailProvider{sendgrid, ses}, deliveries: deliveries, log: log.New("test")}
+
+		count, err := mailer.Send(message)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 1, ses.sent)
+
+		require.Len(t, deliveries.Recorded, 2)
+		assert.Equal(t, "sendgrid", deliveries.Recorded[0].Provider)
+		assert.EqualError(t, deliveries.Recorded[0].Error, "unauthorized")
+		assert.Equal(t, "ses", deliveries.Recorded[1].Provider)
+		assert.Equal(t, "ses-id", deliveries.Recorded[1].MessageID)
+		assert.NoError(t, deliveries.Recorded[1].Error)
+	})
+
+	t.Run("Returns the error of the last provider when all providers fail", func(t *testing.T) {
+		mailer := &failoverMailer{providers: []mailProvider{
+			&fakeMailProvider{providerName: "sendgrid", err: errors.New("unauthorized")},
+			&fakeMailProvider{providerName: "mailgun", err: errors.New("forbidden")},
+		}, log: log.New("test")}
+
+		count, err := mailer.Send(message)
+		assert.Equal(t, 0, count)
+		assert.EqualError(t, err, "mailgun: forbidden")
+	})
+
+	t.Run("Fails to create unknown providers", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.Smtp.Enabled = true
+		cfg.Smtp.Providers = []string{"postmark"}
+		_, err := ProvideSmtpService(cfg, nil)
+		require.ErrorIs(t, err, emaildelivery.ErrUnknownProvider)
+	})
+}
+
+func TestSendGridClient(t *testing.T) {
+	var payload sendGridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.Header().Set("X-Message-Id", "sg-id")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
+	cfg.Smtp.SendGrid = setting.SendGridSettings{APIKey: "key", URL: server.URL}
+	client, err := newSendGridClient(cfg.Smtp)
+	require.NoError(t, err)
+
+	messageID, err := client.sendMessage(context.Background(), &Message{
+		To:            []string{"to@example.com"},
+		From:          "Grafana <admin@example.com>",
+		Subject:       "subject",
+		Body:          map[string]string{"text/html": "<p>body</p>", "text/plain": "body"},
+		AttachedFiles: []*AttachedFile{{Name: "report.pdf", Content: []byte("pdf")}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sg-id", messageID)
+
+	assert.Equal(t, sendGridAddress{Email: "admin@example.com", Name: "Grafana"}, payload.From)
+	require.Len(t, payload.Content, 2)
+	assert.Equal(t, "text/plain", payload.Content[0].Type)
+	require.Len(t, payload.Attachments, 1)
+	assert.Equal(t, "cGRm", payload.Attachments[0].Content)
+
+	t.Run("Returns the response of failed requests", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"invalid key"}]}`))
+		}))
+		t.Cleanup(failing.Close)
+		client.url = failing.URL
+
+		_, err := client.sendMessage(context.Background(), &Message{To: []string{"to@example.com"}, From: "admin@example.com"})
+		assert.EqualError(t, err, `mail provider responded with status 401: {"errors":[{"message":"invalid key"}]}`)
+	})
+}
+
+func TestMailgunClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages.mime", r.URL.Path)
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "api", user)
+		assert.Equal(t, "key", password)
+		assert.Equal(t, "to@example.com", r.FormValue("to"))
+
+		file, _, err := r.FormFile("message")
+		require.NoError(t, err)
+		raw, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), "Subject: subject")
+
+		_, _ = w.Write([]byte(`{"id":"<mg-id@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.Smtp.ContentTypes = []string{"text/plain"}
+	cfg.Smtp.Mailgun = setting.MailgunSettings{Domain: "mg.example.com", APIKey: "key", URL: server.URL}
+	client, err := newMailgunClient(cfg.Smtp)
+	require.NoError(t, err)
+
+	messageID, err := client.sendMessage(context.Background(), &Message{
+		To:      []string{"to@example.com"},
+		From:    "admin@example.com",
+		Subject: "subject",
+		Body:    map[string]string{"text/plain": "body"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mg-id@mg.example.com", messageID)
+}
+
+func TestParseBounces(t *testing.T) {
+	ctx := context.Background()
+	cfg := setting.NewCfg().Smtp
+
+	t.Run("SendGrid bounces and dropped emails", func(t *testing.T) {
+		bounces, err := ParseBounces(ctx, cfg, "sendgrid", []byte(`[
+			{"email":"a@example.com","event":"delivered","sg_message_id":"id1.filter0001"},
+			{"email":"b@example.com","event":"bounce","sg_message_id":"id1.filter0001","reason":"550 unknown user"},
+			{"email":"c@example.com","event":"dropped","sg_message_id":"id2.filter0002","reason":"Bounced Address"}
+		]`))
+		require.NoError(t, err)
+		require.Len(t, bounces, 2)
+		assert.Equal(t, emaildelivery.BounceCommand{Provider: "sendgrid", MessageID: "id1", Recipient: "b@example.com", Reason: "550 unknown user"}, *bounces[0])
+		assert.Equal(t, "id2", bounces[1].MessageID)
+	})
+
+	t.Run("SES permanent bounces in SNS notifications", func(t *testing.T) {
+		message := `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"a@example.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]},"mail":{"messageId":"ses-id"}}`
+		body, err := json.Marshal(map[string]string{"Type": "Notification", "Message": message})
+		require.NoError(t, err)
+
+		bounces, err := ParseBounces(ctx, cfg, "ses", body)
+		require.NoError(t, err)
+		require.Len(t, bounces, 1)
+		assert.Equal(t, emaildelivery.BounceCommand{Provider: "ses", MessageID: "ses-id", Recipient: "a@example.com", Reason: "smtp; 550 5.1.1 user unknown"}, *bounces[0])
+
+		bounces, err = ParseBounces(ctx, cfg, "ses", []byte(`{"notificationType":"Bounce","bounce":{"bounceType":"Transient","bouncedRecipients":[{"emailAddress":"a@example.com"}]}}`))
+		require.NoError(t, err)
+		assert.Empty(t, bounces)
+	})
+
+	t.Run("SNS subscriptions are only confirmed with AWS", func(t *testing.T) {
+		_, err := ParseBounces(ctx, cfg, "ses", []byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://attacker.example.com/confirm"}`))
+		require.ErrorIs(t, err, emaildelivery.ErrInvalidBounce)
+	})
+
+	t.Run("Mailgun permanent failures with a valid signature", func(t *testing.T) {
+		mailgunCfg := cfg
+		mailgunCfg.Mailgun.WebhookSigningKey = "signing-key"
+		mac := hmac.New(sha256.New, []byte("signing-key"))
+		mac.Write([]byte("1600000000" + "token"))
+		body := func(signature string) []byte {
+			return []byte(`{"signature":{"timestamp":"1600000000","token":"token","signature":"` + signature + `"},
+				"event-data":{"event":"failed","severity":"permanent","recipient":"a@example.com",
+				"message":{"headers":{"message-id":"mg-id@mg.example.com"}},"delivery-status":{"message":"No such user"}}}`)
+		}
+
+		bounces, err := ParseBounces(ctx, mailgunCfg, "mailgun", body(hex.EncodeToString(mac.Sum(nil))))
+		require.NoError(t, err)
+		require.Len(t, bounces, 1)
+		assert.Equal(t, emaildelivery.BounceCommand{Provider: "mailgun", MessageID: "mg-id@mg.example.com", Recipient: "a@example.com", Reason: "No such user"}, *bounces[0])
+
+		_, err = ParseBounces(ctx, mailgunCfg, "mailgun", body("00"))
+		require.ErrorIs(t, err, emaildelivery.ErrInvalidBounce)
+	})
+
+	t.Run("Unknown providers", func(t *testing.T) {
+		_, err := ParseBounces(ctx, cfg, "postmark", []byte(`{}`))
+		require.ErrorIs(t, err, emaildelivery.ErrUnknownProvider)
+	})
+}
@@ -6,11 +6,15 @@ package notifications
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/mail"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -54,26 +58,81 @@ func (ns *NotificationService) Send(msg *Message) (int, error) {
 	return ns.mailer.Send(messages...)
 }
 
-func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (*Message, error) {
+func (ns *NotificationService) buildEmailMessage(ctx context.Context, cmd *models.SendEmailCommand) (*Message, error) {
 	if !ns.Cfg.Smtp.Enabled {
 		return nil, models.ErrSmtpNotEnabled
 	}
 
+	return ns.renderEmailMessage(ctx, cmd, ns.getOrgEmailTemplate(ctx, cmd.OrgId, cmd.Template), ns.Cfg.Smtp.ContentTypes)
+}
+
+// getOrgEmailTemplate returns the template the organization uses instead of the
+// default template, or nil.
+func (ns *NotificationService) getOrgEmailTemplate(ctx context.Context, orgID int64, name string) *emailtemplates.EmailTemplate {
+	if ns.templates == nil || orgID == 0 {
+		return nil
+	}
+	if _, ok := emailtemplates.GetDefinition(name); !ok {
+		return nil
+	}
+
+	tmpl, err := ns.templates.Get(ctx, &emailtemplates.GetEmailTemplateQuery{OrgID: orgID, Name: name})
+	if err != nil {
+		if !errors.Is(err, emailtemplates.ErrEmailTemplateNotFound) {
+			ns.log.Error("Failed to get email template of organization, using the default template", "template", name, "orgId", orgID, "error", err)
+		}
+		return nil
+	}
+	return tmpl
+}
+
+// setOrgBrandingData replaces the default header image and footer of the emails
+// with the ones of the branding of the organization.
+func (ns *NotificationService) setOrgBrandingData(ctx context.Context, orgID int64, data map[string]interface{}) {
+	if ns.branding == nil || orgID == 0 {
+		return
+	}
+
+	orgBranding, err := ns.branding.Get(ctx, &branding.GetBrandingQuery{OrgID: orgID})
+	if err != nil {
+		if !errors.Is(err, branding.ErrBrandingNotFound) {
+			ns.log.Error("Failed to get branding of organization, using the default branding", "orgId", orgID, "error", err)
+		}
+		return
+	}
+	if orgBranding.EmailHeaderURL != "" {
+		data["EmailHeaderUrl"] = orgBranding.EmailHeaderURL
+	}
+	data["EmailFooterText"] = orgBranding.EmailFooterText
+}
+
+// renderEmailMessage executes the email template, or the parts the custom
+// template sets instead of the default template.
+func (ns *NotificationService) renderEmailMessage(ctx context.Context, cmd *models.SendEmailCommand, custom *emailtemplates.EmailTemplate, contentTypes []string) (*Message, error) {
 	data := cmd.Data
 	if data == nil {
 		data = make(map[string]interface{}, 10)
 	}
 
 	setDefaultTemplateData(ns.Cfg, data, nil)
+	ns.setOrgBrandingData(ctx, cmd.OrgId, data)
+
+	templates := mailTemplates
+	if custom != nil && (custom.HTML != "" || custom.Text != "") {
+		var err error
+		if templates, err = ns.parseCustomTemplate(cmd.Template, custom); err != nil {
+			return nil, err
+		}
+	}
 
 	body := make(map[string]string)
-	for _, contentType := range ns.Cfg.Smtp.ContentTypes {
+	for _, contentType := range contentTypes {
 		fileExtension, err := getFileExtensionByContentType(contentType)
 		if err != nil {
 			return nil, err
 		}
 		var buffer bytes.Buffer
-		err = mailTemplates.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
+		err = templates.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
 		if err != nil {
 			return nil, err
 		}
@@ -86,6 +145,9 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 		var subjectText interface{}
 		subjectData := data["Subject"].(map[string]interface{})
 		subjectText, hasSubject := subjectData["value"]
+		if custom != nil && custom.Subject != "" {
+			subjectText, hasSubject = custom.Subject, true
+		}
 
 		if !hasSubject {
 			return nil, fmt.Errorf("missing subject in template %s", cmd.Template)
@@ -118,6 +180,27 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 	}, nil
 }
 
+// parseCustomTemplate returns a copy of the default templates where the HTML
+// and text bodies of the template are replaced by the ones of the custom template.
+// The custom bodies can include the other templates by their file name.
+func (ns *NotificationService) parseCustomTemplate(name string, custom *emailtemplates.EmailTemplate) (*template.Template, error) {
+	templates, err := ns.baseTemplates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if custom.HTML != "" {
+		if _, err := templates.New(name + ".html").Parse(custom.HTML); err != nil {
+			return nil, fmt.Errorf("%w: HTML body: %s", ErrInvalidEmailTemplate, err)
+		}
+	}
+	if custom.Text != "" {
+		if _, err := templates.New(name + ".txt").Parse(custom.Text); err != nil {
+			return nil, fmt.Errorf("%w: text body: %s", ErrInvalidEmailTemplate, err)
+		}
+	}
+	return templates, nil
+}
+
 // buildAttachedFiles build attached files
 func buildAttachedFiles(
 	attached []*models.SendEmailAttachFile,
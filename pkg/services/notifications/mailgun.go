@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const defaultMailgunURL = "https://api.mailgun.net"
+
+// mailgunClient sends MIME messages with the messages API of Mailgun.
+type mailgunClient struct {
+	cfg setting.SmtpSettings
+	url string
+}
+
+func newMailgunClient(cfg setting.SmtpSettings) (*mailgunClient, error) {
+	if cfg.Mailgun.Domain == "" || cfg.Mailgun.APIKey == "" {
+		return nil, errors.New("mailgun: domain and api_key are required")
+	}
+	u := cfg.Mailgun.URL
+	if u == "" {
+		u = defaultMailgunURL
+	}
+	return &mailgunClient{cfg: cfg, url: strings.TrimSuffix(u, "/")}, nil
+}
+
+func (c *mailgunClient) name() string {
+	return "mailgun"
+}
+
+func (c *mailgunClient) sendMessage(ctx context.Context, msg *Message) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for _, to := range msg.To {
+		if err := form.WriteField("to", to); err != nil {
+			return "", err
+		}
+	}
+	part, err := form.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return "", err
+	}
+	if _, err := buildMIMEMessage(msg, c.cfg.ContentTypes).WriteTo(part); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	endpoint := c.url + "/v3/" + url.PathEscape(c.cfg.Mailgun.Domain) + "/messages.mime"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth("api", c.cfg.Mailgun.APIKey)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := netClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return "", providerResponseError(resp)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	// the events of the webhooks have the message id without the brackets
+	return strings.Trim(result.ID, "<>"), nil
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/emailtemplates"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -34,7 +36,8 @@ var tmplResetPassword = "reset_password"
 var tmplSignUpStarted = "signup_started"
 var tmplWelcomeOnSignUp = "welcome_on_signup"
 
-func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore) (*NotificationService, error) {
+func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore, templates emailtemplates.Service,
+	brandingService branding.Service) (*NotificationService, error) {
 	ns := &NotificationService{
 		Bus:          bus,
 		Cfg:          cfg,
@@ -43,6 +46,8 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		webhookQueue: make(chan *Webhook, 10),
 		mailer:       mailer,
 		store:        store,
+		templates:    templates,
+		branding:     brandingService,
 	}
 
 	ns.Bus.AddEventListener(ns.signUpStartedHandler)
@@ -61,6 +66,13 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		}
 	}
 
+	// the templates of the organizations are parsed in clones of the default
+	// templates, which can't be cloned once they have been executed
+	var err error
+	if ns.baseTemplates, err = mailTemplates.Clone(); err != nil {
+		return nil, err
+	}
+
 	if !util.IsEmail(ns.Cfg.Smtp.FromAddress) {
 		return nil, errors.New("invalid email address for SMTP from_address config")
 	}
@@ -84,6 +96,10 @@ type NotificationService struct {
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+	templates    emailtemplates.Service
+	branding     branding.Service
+	// baseTemplates is a copy of the default templates that is never executed.
+	baseTemplates *template.Template
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
@@ -135,7 +151,7 @@ func subjectTemplateFunc(obj map[string]interface{}, value string) string {
 }
 
 func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context, cmd *models.SendEmailCommandSync) error {
-	message, err := ns.buildEmailMessage(&models.SendEmailCommand{
+	message, err := ns.buildEmailMessage(ctx, &models.SendEmailCommand{
 		Data:          cmd.Data,
 		Info:          cmd.Info,
 		Template:      cmd.Template,
@@ -145,6 +161,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 		AttachedFiles: cmd.AttachedFiles,
 		Subject:       cmd.Subject,
 		ReplyTo:       cmd.ReplyTo,
+		OrgId:         cmd.OrgId,
 	})
 
 	if err != nil {
@@ -156,7 +173,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 }
 
 func (ns *NotificationService) SendEmailCommandHandler(ctx context.Context, cmd *models.SendEmailCommand) error {
-	message, err := ns.buildEmailMessage(cmd)
+	message, err := ns.buildEmailMessage(ctx, cmd)
 
 	if err != nil {
 		return err
@@ -174,6 +191,7 @@ func (ns *NotificationService) SendResetPasswordEmail(ctx context.Context, cmd *
 	return ns.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
 		To:       []string{cmd.User.Email},
 		Template: tmplResetPassword,
+		OrgId:    cmd.User.OrgId,
 		Data: map[string]interface{}{
 			"Code": code,
 			"Name": cmd.User.NameOrFallback(),
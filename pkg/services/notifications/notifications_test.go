@@ -7,6 +7,8 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/branding/brandingtest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -248,6 +250,55 @@ func TestSendEmailAsync(t *testing.T) {
 	})
 }
 
+func TestSendEmailBranding(t *testing.T) {
+	bus := newBus(t)
+	brandingService := brandingtest.NewBrandingServiceFake()
+	mailer := NewFakeMailer()
+	ns, err := ProvideService(bus, createSmtpConfig(), mailer, nil, nil, brandingService)
+	require.NoError(t, err)
+
+	send := func(t *testing.T, orgID int64) *Message {
+		t.Helper()
+		mailer.Sent = nil
+		err := ns.SendEmailCommandHandlerSync(context.Background(), &models.SendEmailCommandSync{
+			SendEmailCommand: models.SendEmailCommand{
+				To:       []string{"jane@grafana.com"},
+				Template: "reset_password",
+				OrgId:    orgID,
+				Data:     map[string]interface{}{"Name": "Jane", "Code": "1234"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, mailer.Sent, 1)
+		return mailer.Sent[0]
+	}
+
+	t.Run("When the organization has no branding", func(t *testing.T) {
+		brandingService.ExpectedError = branding.ErrBrandingNotFound
+
+		sent := send(t, 1)
+		assert.Contains(t, sent.Body["text/html"], defaultEmailHeaderURL)
+	})
+
+	t.Run("When the organization has an email branding", func(t *testing.T) {
+		brandingService.ExpectedError = nil
+		brandingService.ExpectedBranding = &branding.OrgBranding{
+			EmailHeaderURL:  "https://acme.example.com/logo.png",
+			EmailFooterText: "ACME Monitoring",
+		}
+
+		sent := send(t, 1)
+		assert.Contains(t, sent.Body["text/html"], `src="https://acme.example.com/logo.png"`)
+		assert.NotContains(t, sent.Body["text/html"], defaultEmailHeaderURL)
+		assert.Contains(t, sent.Body["text/html"], "ACME Monitoring")
+		assert.Contains(t, sent.Body["text/plain"], "ACME Monitoring\nSent by Grafana")
+
+		sent = send(t, 0)
+		assert.Contains(t, sent.Body["text/html"], defaultEmailHeaderURL)
+		assert.NotContains(t, sent.Body["text/plain"], "ACME Monitoring")
+	})
+}
+
 func createSut(t *testing.T, bus bus.Bus) (*NotificationService, *FakeMailer) {
 	t.Helper()
 
@@ -259,7 +310,7 @@ func createSut(t *testing.T, bus bus.Bus) (*NotificationService, *FakeMailer) {
 
 func createSutWithConfig(t *testing.T, bus bus.Bus, cfg *setting.Cfg) (*NotificationService, *FakeMailer, error) {
 	smtp := NewFakeMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil, nil)
 	return ns, smtp, err
 }
 
@@ -268,7 +319,7 @@ func createDisconnectedSut(t *testing.T, bus bus.Bus) *NotificationService {
 
 	cfg := createSmtpConfig()
 	smtp := NewFakeDisconnectedMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil, nil)
 	require.NoError(t, err)
 	return ns
 }
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const defaultSendGridURL = "https://api.sendgrid.com"
+
+// sendGridClient sends emails with the v3 mail send API of SendGrid.
+type sendGridClient struct {
+	cfg setting.SmtpSettings
+	url string
+}
+
+func newSendGridClient(cfg setting.SmtpSettings) (*sendGridClient, error) {
+	if cfg.SendGrid.APIKey == "" {
+		return nil, errors.New("sendgrid: api_key is required")
+	}
+	url := cfg.SendGrid.URL
+	if url == "" {
+		url = defaultSendGridURL
+	}
+	return &sendGridClient{cfg: cfg, url: strings.TrimSuffix(url, "/")}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type,omitempty"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyToList      []sendGridAddress         `json:"reply_to_list,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func (c *sendGridClient) name() string {
+	return "sendgrid"
+}
+
+func (c *sendGridClient) sendMessage(ctx context.Context, msg *Message) (string, error) {
+	payload, err := c.buildMail(msg)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.SendGrid.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := netClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return "", providerResponseError(resp)
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
+
+func (c *sendGridClient) buildMail(msg *Message) (*sendGridMail, error) {
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return nil, err
+	}
+	personalization := sendGridPersonalization{}
+	for _, to := range msg.To {
+		address, err := parseAddress(to)
+		if err != nil {
+			return nil, err
+		}
+		personalization.To = append(personalization.To, address)
+	}
+
+	m := &sendGridMail{
+		Personalizations: []sendGridPersonalization{personalization},
+		From:             from,
+		Subject:          msg.Subject,
+	}
+	for _, replyTo := range msg.ReplyTo {
+		address, err := parseAddress(replyTo)
+		if err != nil {
+			return nil, err
+		}
+		m.ReplyToList = append(m.ReplyToList, address)
+	}
+
+	// SendGrid requires the plain text content first
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		for _, configured := range c.cfg.ContentTypes {
+			if configured == contentType {
+				m.Content = append(m.Content, sendGridContent{Type: contentType, Value: msg.Body[contentType]})
+			}
+		}
+	}
+
+	for _, file := range msg.EmbeddedFiles {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(file)
+		m.Attachments = append(m.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(content),
+			Type:        mime.TypeByExtension(filepath.Ext(name)),
+			Filename:    name,
+			Disposition: "inline",
+			ContentID:   name,
+		})
+	}
+	for _, file := range msg.AttachedFiles {
+		m.Attachments = append(m.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(file.Content),
+			Type:        mime.TypeByExtension(filepath.Ext(file.Name)),
+			Filename:    file.Name,
+			Disposition: "attachment",
+		})
+	}
+	return m, nil
+}
+
+func parseAddress(address string) (sendGridAddress, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return sendGridAddress{}, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return sendGridAddress{Email: parsed.Address, Name: parsed.Name}, nil
+}
+
+// providerResponseError returns an error with the status and the start of the
+// body of a failed response of a mail provider.
+func providerResponseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("mail provider responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
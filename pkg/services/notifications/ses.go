@@ -0,0 +1,56 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// sesClient sends raw MIME messages with Amazon SES. It uses the configured
+// access keys, or else the default credential chain of the AWS SDK.
+type sesClient struct {
+	cfg setting.SmtpSettings
+	ses *ses.SES
+}
+
+func newSESClient(cfg setting.SmtpSettings) (*sesClient, error) {
+	if cfg.SES.Region == "" {
+		return nil, errors.New("ses: region is required")
+	}
+	awsCfg := aws.NewConfig().WithRegion(cfg.SES.Region).WithHTTPClient(netClient)
+	if cfg.SES.AccessKeyID != "" || cfg.SES.SecretAccessKey != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.SES.AccessKeyID, cfg.SES.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &sesClient{cfg: cfg, ses: ses.New(sess)}, nil
+}
+
+func (c *sesClient) name() string {
+	return "ses"
+}
+
+func (c *sesClient) sendMessage(ctx context.Context, msg *Message) (string, error) {
+	var raw bytes.Buffer
+	if _, err := buildMIMEMessage(msg, c.cfg.ContentTypes).WriteTo(&raw); err != nil {
+		return "", err
+	}
+
+	out, err := c.ses.SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+		Destinations: aws.StringSlice(msg.To),
+		RawMessage:   &ses.RawMessage{Data: raw.Bytes()},
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.MessageId), nil
+}
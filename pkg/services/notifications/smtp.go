@@ -1,6 +1,7 @@
 package notifications
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -16,10 +17,6 @@ type SmtpClient struct {
 	cfg setting.SmtpSettings
 }
 
-func ProvideSmtpService(cfg *setting.Cfg) (Mailer, error) {
-	return NewSmtpClient(cfg.Smtp)
-}
-
 func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
 	client := &SmtpClient{
 		cfg: cfg,
@@ -30,25 +27,12 @@ func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
 
 func (sc *SmtpClient) Send(messages ...*Message) (int, error) {
 	sentEmailsCount := 0
-	dialer, err := sc.createDialer()
-	if err != nil {
-		return sentEmailsCount, err
-	}
-
+	var err error
 	for _, msg := range messages {
-		m := sc.buildEmail(msg)
-
-		innerError := dialer.DialAndSend(m)
-		emailsSentTotal.Inc()
+		_, innerError := sc.sendMessage(context.Background(), msg)
+		countEmailSent(innerError)
 		if innerError != nil {
-			// As gomail does not returned typed errors we have to parse the error
-			// to catch invalid error when the address is invalid.
-			// https://github.com/go-gomail/gomail/blob/81ebce5c23dfd25c6c67194b37d3dd3f338c98b1/send.go#L113
-			if !strings.HasPrefix(innerError.Error(), "gomail: invalid address") {
-				emailsSentFailed.Inc()
-			}
-
-			err = fmt.Errorf("failed to send notification to email addresses: %s: %w", strings.Join(msg.To, ";"), innerError)
+			err = innerError
 			continue
 		}
 
@@ -58,23 +42,47 @@ func (sc *SmtpClient) Send(messages ...*Message) (int, error) {
 	return sentEmailsCount, err
 }
 
+func (sc *SmtpClient) name() string {
+	return "smtp"
+}
+
+// sendMessage sends the message with the SMTP server. SMTP servers don't give
+// an id to the messages, their bounces are emails sent to the sender.
+func (sc *SmtpClient) sendMessage(_ context.Context, msg *Message) (string, error) {
+	dialer, err := sc.createDialer()
+	if err != nil {
+		return "", err
+	}
+
+	if err := dialer.DialAndSend(sc.buildEmail(msg)); err != nil {
+		return "", fmt.Errorf("failed to send notification to email addresses: %s: %w", strings.Join(msg.To, ";"), err)
+	}
+	return "", nil
+}
+
 // buildEmail converts the Message DTO to a gomail message.
 func (sc *SmtpClient) buildEmail(msg *Message) *gomail.Message {
+	return buildMIMEMessage(msg, sc.cfg.ContentTypes)
+}
+
+// buildMIMEMessage converts the Message DTO to a gomail message, which is also
+// the raw message of the mail providers that accept MIME messages.
+func buildMIMEMessage(msg *Message, contentTypes []string) *gomail.Message {
 	m := gomail.NewMessage()
 	m.SetHeader("From", msg.From)
 	m.SetHeader("To", msg.To...)
 	m.SetHeader("Subject", msg.Subject)
-	sc.setFiles(m, msg)
+	setFiles(m, msg)
 	for _, replyTo := range msg.ReplyTo {
 		m.SetAddressHeader("Reply-To", replyTo, "")
 	}
 	// loop over content types from settings in reverse order as they are ordered in according to descending
 	// preference while the alternatives should be ordered according to ascending preference
-	for i := len(sc.cfg.ContentTypes) - 1; i >= 0; i-- {
-		if i == len(sc.cfg.ContentTypes)-1 {
-			m.SetBody(sc.cfg.ContentTypes[i], msg.Body[sc.cfg.ContentTypes[i]])
+	for i := len(contentTypes) - 1; i >= 0; i-- {
+		if i == len(contentTypes)-1 {
+			m.SetBody(contentTypes[i], msg.Body[contentTypes[i]])
 		} else {
-			m.AddAlternative(sc.cfg.ContentTypes[i], msg.Body[sc.cfg.ContentTypes[i]])
+			m.AddAlternative(contentTypes[i], msg.Body[contentTypes[i]])
 		}
 	}
 
@@ -82,7 +90,7 @@ func (sc *SmtpClient) buildEmail(msg *Message) *gomail.Message {
 }
 
 // setFiles attaches files in various forms.
-func (sc *SmtpClient) setFiles(
+func setFiles(
 	m *gomail.Message,
 	msg *Message,
 ) {
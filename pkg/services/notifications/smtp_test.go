@@ -45,7 +45,7 @@ func TestSmtpDialer(t *testing.T) {
 	t.Run("When SMTP hostname is invalid", func(t *testing.T) {
 		cfg := createSmtpConfig()
 		cfg.Smtp.Host = "invalid%hostname:123:456"
-		client, err := ProvideSmtpService(cfg)
+		client, err := ProvideSmtpService(cfg, nil)
 		require.NoError(t, err)
 		message := &Message{
 			To:          []string{"asdf@grafana.com"},
@@ -66,7 +66,7 @@ func TestSmtpDialer(t *testing.T) {
 	t.Run("When SMTP port is invalid", func(t *testing.T) {
 		cfg := createSmtpConfig()
 		cfg.Smtp.Host = "invalid%hostname:123a"
-		client, err := ProvideSmtpService(cfg)
+		client, err := ProvideSmtpService(cfg, nil)
 		require.NoError(t, err)
 		message := &Message{
 			To:          []string{"asdf@grafana.com"},
@@ -88,7 +88,7 @@ func TestSmtpDialer(t *testing.T) {
 		cfg := createSmtpConfig()
 		cfg.Smtp.Host = "localhost:1234"
 		cfg.Smtp.CertFile = "/var/certs/does-not-exist.pem"
-		client, err := ProvideSmtpService(cfg)
+		client, err := ProvideSmtpService(cfg, nil)
 		require.NoError(t, err)
 		message := &Message{
 			To:          []string{"asdf@grafana.com"},
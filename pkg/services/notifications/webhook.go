@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -28,10 +29,10 @@ var netTransport = &http.Transport{
 	TLSClientConfig: &tls.Config{
 		Renegotiation: tls.RenegotiateFreelyAsClient,
 	},
-	Proxy: http.ProxyFromEnvironment,
-	Dial: (&net.Dialer{
+	Proxy: httpclient.EgressProxy(http.ProxyFromEnvironment),
+	DialContext: httpclient.EgressDialContext((&net.Dialer{
 		Timeout: 30 * time.Second,
-	}).Dial,
+	}).DialContext),
 	TLSHandshakeTimeout: 5 * time.Second,
 }
 var netClient = &http.Client{
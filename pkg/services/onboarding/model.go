@@ -0,0 +1,52 @@
+package onboarding
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var ErrInvalidID = errors.New("invalid onboarding id")
+
+// MaxIDs is the number of ids kept per list, the oldest ones are dropped first.
+const MaxIDs = 200
+
+var idRegex = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,100}$`)
+
+// State is the onboarding progress of a user.
+type State struct {
+	DismissedPanels    []string `json:"dismissedPanels"`
+	CompletedSteps     []string `json:"completedSteps"`
+	DiscoveredFeatures []string `json:"discoveredFeatures"`
+}
+
+type GetStateQuery struct {
+	OrgID  int64
+	UserID int64
+}
+
+type UpdateStateCommand struct {
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+
+	DismissPanels    []string `json:"dismissPanels"`
+	CompleteSteps    []string `json:"completeSteps"`
+	DiscoverFeatures []string `json:"discoverFeatures"`
+}
+
+type ResetStateCommand struct {
+	OrgID  int64
+	UserID int64
+}
+
+// Validate checks that the ids only contain letters, digits and the characters _.:-
+func (cmd *UpdateStateCommand) Validate() error {
+	for _, ids := range [][]string{cmd.DismissPanels, cmd.CompleteSteps, cmd.DiscoverFeatures} {
+		for _, id := range ids {
+			if !idRegex.MatchString(id) {
+				return fmt.Errorf("%w: %q", ErrInvalidID, id)
+			}
+		}
+	}
+	return nil
+}
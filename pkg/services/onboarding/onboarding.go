@@ -0,0 +1,15 @@
+package onboarding
+
+import (
+	"context"
+)
+
+// Service stores the progress of users through the getting started guides, such as
+// dismissed panels, completed steps and discovered features, in their preferences.
+type Service interface {
+	Get(ctx context.Context, query *GetStateQuery) (*State, error)
+	// Update adds to the progress of the user and returns the new state.
+	Update(ctx context.Context, cmd *UpdateStateCommand) (*State, error)
+	// Reset clears the progress of the user, e.g. to show the guides again.
+	Reset(ctx context.Context, cmd *ResetStateCommand) error
+}
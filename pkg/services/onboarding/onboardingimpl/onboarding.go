@@ -0,0 +1,105 @@
+package onboardingimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/onboarding"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+type Service struct {
+	prefService pref.Service
+}
+
+func ProvideService(prefService pref.Service) onboarding.Service {
+	return &Service{
+		prefService: prefService,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *onboarding.GetStateQuery) (*onboarding.State, error) {
+	progress, err := s.progress(ctx, query.OrgID, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return toState(progress), nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *onboarding.UpdateStateCommand) (*onboarding.State, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	progress, err := s.progress(ctx, cmd.OrgID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	progress.DismissedPanels = appendIDs(progress.DismissedPanels, cmd.DismissPanels)
+	progress.CompletedSteps = appendIDs(progress.CompletedSteps, cmd.CompleteSteps)
+	progress.DiscoveredFeatures = appendIDs(progress.DiscoveredFeatures, cmd.DiscoverFeatures)
+
+	err = s.prefService.Patch(ctx, &pref.PatchPreferenceCommand{
+		OrgID:      cmd.OrgID,
+		UserID:     cmd.UserID,
+		Onboarding: progress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toState(progress), nil
+}
+
+func (s *Service) Reset(ctx context.Context, cmd *onboarding.ResetStateCommand) error {
+	return s.prefService.Patch(ctx, &pref.PatchPreferenceCommand{
+		OrgID:      cmd.OrgID,
+		UserID:     cmd.UserID,
+		Onboarding: &pref.OnboardingPreference{},
+	})
+}
+
+func (s *Service) progress(ctx context.Context, orgID, userID int64) (*pref.OnboardingPreference, error) {
+	preference, err := s.prefService.Get(ctx, &pref.GetPreferenceQuery{OrgID: orgID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	if preference.JSONData == nil || preference.JSONData.Onboarding == nil {
+		return &pref.OnboardingPreference{}, nil
+	}
+	return preference.JSONData.Onboarding, nil
+}
+
+// appendIDs adds the ids that are not in the list yet, keeping at most onboarding.MaxIDs.
+func appendIDs(list []string, ids []string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, id := range list {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	if len(list) > onboarding.MaxIDs {
+		list = list[len(list)-onboarding.MaxIDs:]
+	}
+	return list
+}
+
+func toState(progress *pref.OnboardingPreference) *onboarding.State {
+	state := &onboarding.State{
+		DismissedPanels:    progress.DismissedPanels,
+		CompletedSteps:     progress.CompletedSteps,
+		DiscoveredFeatures: progress.DiscoveredFeatures,
+	}
+	if state.DismissedPanels == nil {
+		state.DismissedPanels = []string{}
+	}
+	if state.CompletedSteps == nil {
+		state.CompletedSteps = []string{}
+	}
+	if state.DiscoveredFeatures == nil {
+		state.DiscoveredFeatures = []string{}
+	}
+	return state
+}
@@ -0,0 +1,73 @@
+package onboardingimpl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/onboarding"
+	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationOnboarding(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(prefimpl.ProvideService(ss, ss.Cfg, kvstore.ProvideService(ss), nil, nil))
+	ctx := context.Background()
+
+	state, err := svc.Get(ctx, &onboarding.GetStateQuery{OrgID: 1, UserID: 1})
+	require.NoError(t, err)
+	require.Equal(t, &onboarding.State{DismissedPanels: []string{}, CompletedSteps: []string{}, DiscoveredFeatures: []string{}}, state)
+
+	t.Run("update adds to the progress", func(t *testing.T) {
+		_, err := svc.Update(ctx, &onboarding.UpdateStateCommand{OrgID: 1, UserID: 1, CompleteSteps: []string{"add-datasource"}})
+		require.NoError(t, err)
+		state, err := svc.Update(ctx, &onboarding.UpdateStateCommand{
+			OrgID:            1,
+			UserID:           1,
+			DismissPanels:    []string{"gettingstarted"},
+			CompleteSteps:    []string{"add-datasource", "create-dashboard"},
+			DiscoverFeatures: []string{"explore"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"add-datasource", "create-dashboard"}, state.CompletedSteps)
+
+		stored, err := svc.Get(ctx, &onboarding.GetStateQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		require.Equal(t, state, stored)
+
+		other, err := svc.Get(ctx, &onboarding.GetStateQuery{OrgID: 1, UserID: 2})
+		require.NoError(t, err)
+		require.Empty(t, other.CompletedSteps)
+	})
+
+	t.Run("invalid ids are rejected", func(t *testing.T) {
+		_, err := svc.Update(ctx, &onboarding.UpdateStateCommand{OrgID: 1, UserID: 1, DismissPanels: []string{"<script>"}})
+		require.ErrorIs(t, err, onboarding.ErrInvalidID)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		require.NoError(t, svc.Reset(ctx, &onboarding.ResetStateCommand{OrgID: 1, UserID: 1}))
+		state, err := svc.Get(ctx, &onboarding.GetStateQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		require.Empty(t, state.CompletedSteps)
+	})
+}
+
+func TestAppendIDs(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, appendIDs([]string{"a"}, []string{"a", "b", "b"}))
+
+	list := make([]string, 0, onboarding.MaxIDs)
+	for i := 0; i < onboarding.MaxIDs; i++ {
+		list = append(list, fmt.Sprintf("step-%d", i))
+	}
+	list = appendIDs(list, []string{"latest"})
+	require.Len(t, list, onboarding.MaxIDs)
+	require.Equal(t, "latest", list[len(list)-1])
+}
@@ -0,0 +1,168 @@
+package orginvites
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrLinkNotFound      = errors.New("invite link not found")
+	ErrLinkExpired       = errors.New("invite link has expired")
+	ErrLinkUsedUp        = errors.New("invite link has been used the maximum number of times")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidExpiration = errors.New("invite links must expire between 15 minutes and 30 days after their creation")
+	ErrInvalidMaxUses    = errors.New("the maximum number of uses can't be negative")
+	ErrAlreadyMember     = errors.New("user is already a member of the organization")
+	ErrRequestNotFound   = errors.New("access request not found")
+	ErrRequestPending    = errors.New("an access request to the organization is already pending")
+)
+
+const (
+	MinLinkLifetime = 15 * time.Minute
+	MaxLinkLifetime = 30 * 24 * time.Hour
+)
+
+// Link is an invite link to an organization. Anyone signed in with the link
+// can join the organization, or request access to it when the link requires
+// approval, until it expires or has been used MaxUses times.
+type Link struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	UID   string `xorm:"uid" json:"uid"`
+	// Code is the secret part of the URL of the link.
+	Code            string          `xorm:"code" json:"-"`
+	Role            models.RoleType `xorm:"role" json:"role"`
+	RequireApproval bool            `xorm:"require_approval" json:"requireApproval"`
+	// MaxUses is the number of times the link can be used, 0 for unlimited.
+	MaxUses   int64     `xorm:"max_uses" json:"maxUses"`
+	Uses      int64     `xorm:"uses" json:"uses"`
+	CreatedBy int64     `xorm:"created_by" json:"createdBy"`
+	Created   time.Time `xorm:"created" json:"created"`
+	Expires   time.Time `xorm:"expires" json:"expires"`
+	URL       string    `xorm:"-" json:"url"`
+}
+
+func (l Link) TableName() string {
+	return "org_invite_link"
+}
+
+// LinkInfo is what the users opening an invite link are shown.
+type LinkInfo struct {
+	OrgName         string          `json:"orgName"`
+	Role            models.RoleType `json:"role"`
+	RequireApproval bool            `json:"requireApproval"`
+	Expires         time.Time       `json:"expires"`
+}
+
+type RequestStatus string
+
+const (
+	RequestPending  RequestStatus = "pending"
+	RequestApproved RequestStatus = "approved"
+	RequestRejected RequestStatus = "rejected"
+)
+
+// AccessRequest is a request of a user to join an organization, made with an
+// invite link that requires the approval of an org admin.
+type AccessRequest struct {
+	ID     int64 `xorm:"pk autoincr 'id'"`
+	OrgID  int64 `xorm:"org_id"`
+	UserID int64 `xorm:"user_id"`
+	LinkID int64 `xorm:"link_id"`
+	// Role is the role of the link the request was made with.
+	Role       models.RoleType `xorm:"role"`
+	Message    string          `xorm:"message"`
+	Status     RequestStatus   `xorm:"status"`
+	ReviewedBy int64           `xorm:"reviewed_by"`
+	Created    time.Time       `xorm:"created"`
+	Reviewed   *time.Time      `xorm:"reviewed"`
+}
+
+func (r AccessRequest) TableName() string {
+	return "org_access_request"
+}
+
+type AccessRequestDTO struct {
+	ID         int64           `xorm:"id" json:"id"`
+	OrgID      int64           `xorm:"org_id" json:"orgId"`
+	UserID     int64           `xorm:"user_id" json:"userId"`
+	Login      string          `xorm:"login" json:"login"`
+	Email      string          `xorm:"email" json:"email"`
+	Name       string          `xorm:"name" json:"name"`
+	Role       models.RoleType `xorm:"role" json:"role"`
+	Message    string          `xorm:"message" json:"message"`
+	Status     RequestStatus   `xorm:"status" json:"status"`
+	ReviewedBy int64           `xorm:"reviewed_by" json:"reviewedBy,omitempty"`
+	Created    time.Time       `xorm:"created" json:"created"`
+	Reviewed   *time.Time      `xorm:"reviewed" json:"reviewed,omitempty"`
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateLinkCommand struct {
+	OrgID int64 `json:"-"`
+	// Role is the role of the users joining with the link.
+	Role            models.RoleType `json:"role"`
+	RequireApproval bool            `json:"requireApproval"`
+	// MaxUses is the number of times the link can be used, 0 for unlimited.
+	MaxUses int64 `json:"maxUses"`
+	// ExpiresIn is how long the link can be used for, such as 12h or 7d. It
+	// defaults to the lifetime of invites.
+	ExpiresIn string `json:"expiresIn"`
+	CreatedBy int64  `json:"-"`
+}
+
+type DeleteLinkCommand struct {
+	OrgID int64
+	UID   string
+}
+
+type JoinCommand struct {
+	Code   string `json:"-"`
+	UserID int64  `json:"-"`
+	// Message is shown to the org admins reviewing the access request.
+	Message string `json:"message"`
+}
+
+type JoinResult struct {
+	OrgID int64 `json:"orgId"`
+	// Joined is whether the user was added to the organization, otherwise
+	// RequestID is the id of the pending access request.
+	Joined    bool  `json:"joined"`
+	RequestID int64 `json:"requestId,omitempty"`
+}
+
+type ApproveRequestCommand struct {
+	OrgID int64 `json:"-"`
+	ID    int64 `json:"-"`
+	// Role replaces the role of the link the request was made with when it's set.
+	Role       models.RoleType `json:"role"`
+	ReviewedBy int64           `json:"-"`
+}
+
+type RejectRequestCommand struct {
+	OrgID      int64
+	ID         int64
+	ReviewedBy int64
+}
+
+// ---------------------
+// QUERIES
+
+type GetLinksQuery struct {
+	OrgID int64
+}
+
+type GetLinkInfoQuery struct {
+	Code string
+}
+
+type GetRequestsQuery struct {
+	OrgID int64
+	// Status filters the requests by status, all the requests are returned when
+	// it's empty.
+	Status RequestStatus
+}
@@ -0,0 +1,27 @@
+package orginvites
+
+import (
+	"context"
+)
+
+// Service manages the invite links of organizations and the access requests
+// made with the links that require the approval of an org admin.
+type Service interface {
+	CreateLink(ctx context.Context, cmd *CreateLinkCommand) (*Link, error)
+	// GetLinks returns the links of the organization that haven't expired.
+	GetLinks(ctx context.Context, query *GetLinksQuery) ([]*Link, error)
+	DeleteLink(ctx context.Context, cmd *DeleteLinkCommand) error
+	// GetLinkInfo returns what the users opening a link are invited to. It fails
+	// with ErrLinkExpired or ErrLinkUsedUp for the links that can't be used.
+	GetLinkInfo(ctx context.Context, query *GetLinkInfoQuery) (*LinkInfo, error)
+
+	// Join uses a link to add the user to the organization of the link, or to
+	// request access to it when the link requires approval.
+	Join(ctx context.Context, cmd *JoinCommand) (*JoinResult, error)
+
+	GetRequests(ctx context.Context, query *GetRequestsQuery) ([]*AccessRequestDTO, error)
+	// ApproveRequest adds the user of a pending access request to the
+	// organization, with the role of the command or the one of the link.
+	ApproveRequest(ctx context.Context, cmd *ApproveRequestCommand) error
+	RejectRequest(ctx context.Context, cmd *RejectRequestCommand) error
+}
@@ -0,0 +1,280 @@
+package orginvitesimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service struct {
+	store    store
+	cfg      *setting.Cfg
+	orgUsers sqlstore.Store
+	webhooks notifications.WebhookSender
+	log      log.Logger
+	now      func() time.Time
+}
+
+func ProvideService(db db.DB, cfg *setting.Cfg, orgUsers sqlstore.Store, webhooks notifications.WebhookSender) orginvites.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		cfg:      cfg,
+		orgUsers: orgUsers,
+		webhooks: webhooks,
+		log:      log.New("orginvites"),
+		now:      time.Now,
+	}
+}
+
+func (s *Service) CreateLink(ctx context.Context, cmd *orginvites.CreateLinkCommand) (*orginvites.Link, error) {
+	if !cmd.Role.IsValid() {
+		return nil, orginvites.ErrInvalidRole
+	}
+	if cmd.MaxUses < 0 {
+		return nil, orginvites.ErrInvalidMaxUses
+	}
+	lifetime := s.cfg.UserInviteMaxLifetime
+	if cmd.ExpiresIn != "" {
+		var err error
+		if lifetime, err = gtime.ParseDuration(cmd.ExpiresIn); err != nil {
+			return nil, orginvites.ErrInvalidExpiration
+		}
+	}
+	if lifetime < orginvites.MinLinkLifetime || lifetime > orginvites.MaxLinkLifetime {
+		return nil, orginvites.ErrInvalidExpiration
+	}
+
+	code, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	link := &orginvites.Link{
+		OrgID:           cmd.OrgID,
+		UID:             util.GenerateShortUID(),
+		Code:            code,
+		Role:            cmd.Role,
+		RequireApproval: cmd.RequireApproval,
+		MaxUses:         cmd.MaxUses,
+		CreatedBy:       cmd.CreatedBy,
+		Created:         now,
+		Expires:         now.Add(lifetime),
+	}
+	if err := s.store.InsertLink(ctx, link); err != nil {
+		return nil, err
+	}
+	s.setURL(link)
+	return link, nil
+}
+
+func (s *Service) GetLinks(ctx context.Context, query *orginvites.GetLinksQuery) ([]*orginvites.Link, error) {
+	links, err := s.store.GetLinks(ctx, query.OrgID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		s.setURL(link)
+	}
+	return links, nil
+}
+
+func (s *Service) setURL(link *orginvites.Link) {
+	link.URL = strings.TrimSuffix(s.cfg.AppURL, "/") + "/invite-link/" + link.Code
+}
+
+func (s *Service) DeleteLink(ctx context.Context, cmd *orginvites.DeleteLinkCommand) error {
+	return s.store.DeleteLink(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *Service) GetLinkInfo(ctx context.Context, query *orginvites.GetLinkInfoQuery) (*orginvites.LinkInfo, error) {
+	link, err := s.usableLink(ctx, query.Code)
+	if err != nil {
+		return nil, err
+	}
+	orgName, err := s.store.OrgName(ctx, link.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	return &orginvites.LinkInfo{
+		OrgName:         orgName,
+		Role:            link.Role,
+		RequireApproval: link.RequireApproval,
+		Expires:         link.Expires,
+	}, nil
+}
+
+// usableLink returns the link of the code when it hasn't expired nor been
+// used up.
+func (s *Service) usableLink(ctx context.Context, code string) (*orginvites.Link, error) {
+	link, err := s.store.GetLinkByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !link.Expires.After(s.now()) {
+		return nil, orginvites.ErrLinkExpired
+	}
+	if link.MaxUses > 0 && link.Uses >= link.MaxUses {
+		return nil, orginvites.ErrLinkUsedUp
+	}
+	return link, nil
+}
+
+func (s *Service) Join(ctx context.Context, cmd *orginvites.JoinCommand) (*orginvites.JoinResult, error) {
+	link, err := s.usableLink(ctx, cmd.Code)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.store.IsMember(ctx, link.OrgID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, orginvites.ErrAlreadyMember
+	}
+
+	if err := s.store.UseLink(ctx, link.ID, s.now()); err != nil {
+		return nil, err
+	}
+	result, err := s.join(ctx, link, cmd)
+	if err != nil {
+		if releaseErr := s.store.ReleaseLink(ctx, link.ID); releaseErr != nil {
+			s.log.Warn("Failed to release the use of an invite link", "link", link.UID, "error", releaseErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) join(ctx context.Context, link *orginvites.Link, cmd *orginvites.JoinCommand) (*orginvites.JoinResult, error) {
+	if !link.RequireApproval {
+		if err := s.addOrgUser(ctx, link.OrgID, cmd.UserID, link.Role); err != nil {
+			return nil, err
+		}
+		s.log.Info("User joined an organization with an invite link", "orgId", link.OrgID, "userId", cmd.UserID, "link", link.UID)
+		return &orginvites.JoinResult{OrgID: link.OrgID, Joined: true}, nil
+	}
+
+	request := &orginvites.AccessRequest{
+		OrgID:   link.OrgID,
+		UserID:  cmd.UserID,
+		LinkID:  link.ID,
+		Role:    link.Role,
+		Message: cmd.Message,
+		Status:  orginvites.RequestPending,
+		Created: s.now(),
+	}
+	if err := s.store.InsertRequest(ctx, request); err != nil {
+		return nil, err
+	}
+	s.notify(ctx, request)
+	return &orginvites.JoinResult{OrgID: link.OrgID, RequestID: request.ID}, nil
+}
+
+func (s *Service) addOrgUser(ctx context.Context, orgID, userID int64, role models.RoleType) error {
+	err := s.orgUsers.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: orgID, UserId: userID, Role: role})
+	if errors.Is(err, models.ErrOrgUserAlreadyAdded) {
+		return orginvites.ErrAlreadyMember
+	}
+	return err
+}
+
+type accessRequestWebhook struct {
+	OrgID     int64           `json:"orgId"`
+	RequestID int64           `json:"requestId"`
+	UserID    int64           `json:"userId"`
+	Login     string          `json:"login"`
+	Email     string          `json:"email"`
+	Role      models.RoleType `json:"role"`
+	Message   string          `json:"message"`
+}
+
+// notify posts the new access request to the webhook of the configuration.
+// The request is kept when the webhook fails, the admins still see it in the
+// list of requests.
+func (s *Service) notify(ctx context.Context, request *orginvites.AccessRequest) {
+	if s.cfg.AccessRequestWebhookURL == "" {
+		return
+	}
+	dto, err := s.store.GetRequest(ctx, request.OrgID, request.ID)
+	if err != nil {
+		s.log.Warn("Failed to get the access request to notify", "id", request.ID, "error", err)
+		return
+	}
+	body, err := json.Marshal(accessRequestWebhook{
+		OrgID:     dto.OrgID,
+		RequestID: dto.ID,
+		UserID:    dto.UserID,
+		Login:     dto.Login,
+		Email:     dto.Email,
+		Role:      dto.Role,
+		Message:   dto.Message,
+	})
+	if err != nil {
+		s.log.Warn("Failed to encode the access request webhook", "id", request.ID, "error", err)
+		return
+	}
+	err = s.webhooks.SendWebhookSync(ctx, &models.SendWebhookSync{
+		Url:         s.cfg.AccessRequestWebhookURL,
+		Body:        string(body),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	})
+	if err != nil {
+		s.log.Warn("Failed to notify the access request webhook", "id", request.ID, "error", err)
+	}
+}
+
+func (s *Service) GetRequests(ctx context.Context, query *orginvites.GetRequestsQuery) ([]*orginvites.AccessRequestDTO, error) {
+	return s.store.GetRequests(ctx, query)
+}
+
+func (s *Service) ApproveRequest(ctx context.Context, cmd *orginvites.ApproveRequestCommand) error {
+	if cmd.Role != "" && !cmd.Role.IsValid() {
+		return orginvites.ErrInvalidRole
+	}
+	request, err := s.store.GetRequest(ctx, cmd.OrgID, cmd.ID)
+	if err != nil {
+		return err
+	}
+	if request.Status != orginvites.RequestPending {
+		return orginvites.ErrRequestNotFound
+	}
+	role := request.Role
+	if cmd.Role != "" {
+		role = cmd.Role
+	}
+
+	// users who joined since their request, with another link or an invite,
+	// keep their role
+	if err := s.addOrgUser(ctx, request.OrgID, request.UserID, role); err != nil && !errors.Is(err, orginvites.ErrAlreadyMember) {
+		return err
+	}
+	if err := s.store.Review(ctx, cmd.OrgID, cmd.ID, orginvites.RequestApproved, cmd.ReviewedBy, s.now()); err != nil {
+		return err
+	}
+	s.log.Info("Access request approved", "id", request.ID, "orgId", request.OrgID, "userId", request.UserID, "role", role, "reviewedBy", cmd.ReviewedBy)
+	return nil
+}
+
+func (s *Service) RejectRequest(ctx context.Context, cmd *orginvites.RejectRequestCommand) error {
+	if err := s.store.Review(ctx, cmd.OrgID, cmd.ID, orginvites.RequestRejected, cmd.ReviewedBy, s.now()); err != nil {
+		return err
+	}
+	s.log.Info("Access request rejected", "id", cmd.ID, "orgId", cmd.OrgID, "reviewedBy", cmd.ReviewedBy)
+	return nil
+}
@@ -0,0 +1,189 @@
+package orginvitesimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeWebhookSender struct {
+	sent []*models.SendWebhookSync
+}
+
+func (f *fakeWebhookSender) SendWebhookSync(ctx context.Context, cmd *models.SendWebhookSync) error {
+	f.sent = append(f.sent, cmd)
+	return nil
+}
+
+func TestIntegrationOrgInvites(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	admin, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "admin", OrgName: "Main"})
+	require.NoError(t, err)
+	orgID := admin.OrgId
+	newUser := func(t *testing.T, login string) *models.User {
+		t.Helper()
+		user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: login, Email: login + "@example.com", SkipOrgSetup: true})
+		require.NoError(t, err)
+		return user
+	}
+	orgRole := func(t *testing.T, userID int64) models.RoleType {
+		t.Helper()
+		query := &models.GetSignedInUserQuery{OrgId: orgID, UserId: userID}
+		require.NoError(t, ss.GetSignedInUser(ctx, query))
+		return query.Result.OrgRole
+	}
+
+	now := time.Now()
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://grafana.example.com/"
+	cfg.UserInviteMaxLifetime = 24 * time.Hour
+	webhooks := &fakeWebhookSender{}
+	s := &Service{
+		store:    &sqlStore{db: ss},
+		cfg:      cfg,
+		orgUsers: ss,
+		webhooks: webhooks,
+		log:      log.New("test"),
+		now:      func() time.Time { return now },
+	}
+
+	t.Run("Validates the links", func(t *testing.T) {
+		for _, tc := range []struct {
+			cmd orginvites.CreateLinkCommand
+			err error
+		}{
+			{cmd: orginvites.CreateLinkCommand{Role: "Owner"}, err: orginvites.ErrInvalidRole},
+			{cmd: orginvites.CreateLinkCommand{Role: models.ROLE_VIEWER, MaxUses: -1}, err: orginvites.ErrInvalidMaxUses},
+			{cmd: orginvites.CreateLinkCommand{Role: models.ROLE_VIEWER, ExpiresIn: "5m"}, err: orginvites.ErrInvalidExpiration},
+			{cmd: orginvites.CreateLinkCommand{Role: models.ROLE_VIEWER, ExpiresIn: "31d"}, err: orginvites.ErrInvalidExpiration},
+			{cmd: orginvites.CreateLinkCommand{Role: models.ROLE_VIEWER, ExpiresIn: "soon"}, err: orginvites.ErrInvalidExpiration},
+		} {
+			cmd := tc.cmd
+			cmd.OrgID = orgID
+			_, err := s.CreateLink(ctx, &cmd)
+			require.ErrorIs(t, err, tc.err)
+		}
+	})
+
+	t.Run("Links expire after the lifetime of invites by default", func(t *testing.T) {
+		link, err := s.CreateLink(ctx, &orginvites.CreateLinkCommand{OrgID: orgID, Role: models.ROLE_VIEWER})
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(24*time.Hour), link.Expires)
+		assert.Equal(t, "http://grafana.example.com/invite-link/"+link.Code, link.URL)
+
+		info, err := s.GetLinkInfo(ctx, &orginvites.GetLinkInfoQuery{Code: link.Code})
+		require.NoError(t, err)
+		assert.Equal(t, "Main", info.OrgName)
+		assert.Equal(t, models.ROLE_VIEWER, info.Role)
+
+		require.NoError(t, s.DeleteLink(ctx, &orginvites.DeleteLinkCommand{OrgID: orgID, UID: link.UID}))
+		_, err = s.GetLinkInfo(ctx, &orginvites.GetLinkInfoQuery{Code: link.Code})
+		require.ErrorIs(t, err, orginvites.ErrLinkNotFound)
+	})
+
+	t.Run("Users join with links until they are used up", func(t *testing.T) {
+		link, err := s.CreateLink(ctx, &orginvites.CreateLinkCommand{OrgID: orgID, Role: models.ROLE_EDITOR, MaxUses: 1})
+		require.NoError(t, err)
+
+		user := newUser(t, "first")
+		result, err := s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: user.Id})
+		require.NoError(t, err)
+		assert.True(t, result.Joined)
+		assert.Equal(t, models.ROLE_EDITOR, orgRole(t, user.Id))
+
+		_, err = s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: newUser(t, "second").Id})
+		require.ErrorIs(t, err, orginvites.ErrLinkUsedUp)
+		_, err = s.GetLinkInfo(ctx, &orginvites.GetLinkInfoQuery{Code: link.Code})
+		require.ErrorIs(t, err, orginvites.ErrLinkUsedUp)
+	})
+
+	t.Run("Members don't use the links", func(t *testing.T) {
+		link, err := s.CreateLink(ctx, &orginvites.CreateLinkCommand{OrgID: orgID, Role: models.ROLE_EDITOR, MaxUses: 1})
+		require.NoError(t, err)
+
+		_, err = s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: admin.Id})
+		require.ErrorIs(t, err, orginvites.ErrAlreadyMember)
+		_, err = s.GetLinkInfo(ctx, &orginvites.GetLinkInfoQuery{Code: link.Code})
+		require.NoError(t, err)
+	})
+
+	t.Run("Expired links can't be used and aren't listed", func(t *testing.T) {
+		link, err := s.CreateLink(ctx, &orginvites.CreateLinkCommand{OrgID: orgID, Role: models.ROLE_VIEWER, ExpiresIn: "1h"})
+		require.NoError(t, err)
+
+		s.now = func() time.Time { return now.Add(2 * time.Hour) }
+		defer func() { s.now = func() time.Time { return now } }()
+
+		_, err = s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: newUser(t, "late").Id})
+		require.ErrorIs(t, err, orginvites.ErrLinkExpired)
+		links, err := s.GetLinks(ctx, &orginvites.GetLinksQuery{OrgID: orgID})
+		require.NoError(t, err)
+		for _, l := range links {
+			assert.NotEqual(t, link.UID, l.UID)
+		}
+	})
+
+	t.Run("Links that require approval create access requests", func(t *testing.T) {
+		cfg.AccessRequestWebhookURL = "http://hooks.example.com/requests"
+		defer func() { cfg.AccessRequestWebhookURL = "" }()
+		link, err := s.CreateLink(ctx, &orginvites.CreateLinkCommand{OrgID: orgID, Role: models.ROLE_VIEWER, RequireApproval: true})
+		require.NoError(t, err)
+
+		approved, rejected := newUser(t, "approved"), newUser(t, "rejected")
+		result, err := s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: approved.Id, Message: "I'm on call"})
+		require.NoError(t, err)
+		assert.False(t, result.Joined)
+		require.NotZero(t, result.RequestID)
+		_, err = s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: approved.Id})
+		require.ErrorIs(t, err, orginvites.ErrRequestPending)
+		rejectedResult, err := s.Join(ctx, &orginvites.JoinCommand{Code: link.Code, UserID: rejected.Id})
+		require.NoError(t, err)
+
+		require.Len(t, webhooks.sent, 2)
+		assert.Equal(t, "http://hooks.example.com/requests", webhooks.sent[0].Url)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(webhooks.sent[0].Body), &body))
+		assert.Equal(t, "approved@example.com", body["email"])
+		assert.Equal(t, "I'm on call", body["message"])
+
+		requests, err := s.GetRequests(ctx, &orginvites.GetRequestsQuery{OrgID: orgID, Status: orginvites.RequestPending})
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+
+		require.ErrorIs(t, s.ApproveRequest(ctx, &orginvites.ApproveRequestCommand{OrgID: orgID, ID: result.RequestID, Role: "Owner"}), orginvites.ErrInvalidRole)
+		require.NoError(t, s.ApproveRequest(ctx, &orginvites.ApproveRequestCommand{OrgID: orgID, ID: result.RequestID, Role: models.ROLE_EDITOR, ReviewedBy: admin.Id}))
+		assert.Equal(t, models.ROLE_EDITOR, orgRole(t, approved.Id))
+		require.ErrorIs(t, s.ApproveRequest(ctx, &orginvites.ApproveRequestCommand{OrgID: orgID, ID: result.RequestID}), orginvites.ErrRequestNotFound)
+
+		require.NoError(t, s.RejectRequest(ctx, &orginvites.RejectRequestCommand{OrgID: orgID, ID: rejectedResult.RequestID, ReviewedBy: admin.Id}))
+		require.ErrorIs(t, s.RejectRequest(ctx, &orginvites.RejectRequestCommand{OrgID: orgID, ID: rejectedResult.RequestID}), orginvites.ErrRequestNotFound)
+		member, err := s.store.IsMember(ctx, orgID, rejected.Id)
+		require.NoError(t, err)
+		assert.False(t, member)
+
+		requests, err = s.GetRequests(ctx, &orginvites.GetRequestsQuery{OrgID: orgID})
+		require.NoError(t, err)
+		statuses := map[string]orginvites.RequestStatus{}
+		for _, r := range requests {
+			statuses[r.Login] = r.Status
+			assert.NotNil(t, r.Reviewed)
+			assert.Equal(t, admin.Id, r.ReviewedBy)
+		}
+		assert.Equal(t, map[string]orginvites.RequestStatus{"approved": orginvites.RequestApproved, "rejected": orginvites.RequestRejected}, statuses)
+	})
+}
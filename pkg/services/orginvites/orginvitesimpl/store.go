@@ -0,0 +1,197 @@
+package orginvitesimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orginvites"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	InsertLink(ctx context.Context, link *orginvites.Link) error
+	GetLinks(ctx context.Context, orgID int64, now time.Time) ([]*orginvites.Link, error)
+	GetLinkByCode(ctx context.Context, code string) (*orginvites.Link, error)
+	DeleteLink(ctx context.Context, orgID int64, uid string) error
+	// UseLink counts a use of the link. It fails with ErrLinkUsedUp when the link
+	// has expired or has been used the maximum number of times, even when it's
+	// used concurrently.
+	UseLink(ctx context.Context, id int64, now time.Time) error
+	// ReleaseLink gives back a use of the link that couldn't be completed.
+	ReleaseLink(ctx context.Context, id int64) error
+	OrgName(ctx context.Context, orgID int64) (string, error)
+	IsMember(ctx context.Context, orgID, userID int64) (bool, error)
+	// InsertRequest fails with ErrRequestPending when the user already has a
+	// pending request to the organization.
+	InsertRequest(ctx context.Context, request *orginvites.AccessRequest) error
+	GetRequest(ctx context.Context, orgID, id int64) (*orginvites.AccessRequestDTO, error)
+	GetRequests(ctx context.Context, query *orginvites.GetRequestsQuery) ([]*orginvites.AccessRequestDTO, error)
+	// Review sets the status of a pending request, it fails with
+	// ErrRequestNotFound when the request isn't pending.
+	Review(ctx context.Context, orgID, id int64, status orginvites.RequestStatus, reviewedBy int64, now time.Time) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) InsertLink(ctx context.Context, link *orginvites.Link) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(link)
+		return err
+	})
+}
+
+func (s *sqlStore) GetLinks(ctx context.Context, orgID int64, now time.Time) ([]*orginvites.Link, error) {
+	links := make([]*orginvites.Link, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=? AND expires > ?", orgID, now).Asc("expires").Find(&links)
+	})
+	return links, err
+}
+
+func (s *sqlStore) GetLinkByCode(ctx context.Context, code string) (*orginvites.Link, error) {
+	link := &orginvites.Link{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("code=?", code).Get(link)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return orginvites.ErrLinkNotFound
+		}
+		return nil
+	})
+	return link, err
+}
+
+func (s *sqlStore) DeleteLink(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("org_id=? AND uid=?", orgID, uid).Delete(&orginvites.Link{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return orginvites.ErrLinkNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) UseLink(ctx context.Context, id int64, now time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE org_invite_link SET uses = uses + 1 WHERE id = ? AND expires > ? AND (max_uses = 0 OR uses < max_uses)", id, now)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return orginvites.ErrLinkUsedUp
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) ReleaseLink(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE org_invite_link SET uses = uses - 1 WHERE id = ? AND uses > 0", id)
+		return err
+	})
+}
+
+func (s *sqlStore) OrgName(ctx context.Context, orgID int64) (string, error) {
+	var org models.Org
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.ID(orgID).Cols("name").Get(&org)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrOrgNotFound
+		}
+		return nil
+	})
+	return org.Name, err
+}
+
+func (s *sqlStore) IsMember(ctx context.Context, orgID, userID int64) (bool, error) {
+	var member bool
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		member, err = sess.Where("org_id=? AND user_id=?", orgID, userID).Exist(&models.OrgUser{})
+		return err
+	})
+	return member, err
+}
+
+func (s *sqlStore) InsertRequest(ctx context.Context, request *orginvites.AccessRequest) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		pending, err := sess.Where("org_id=? AND user_id=? AND status=?", request.OrgID, request.UserID, orginvites.RequestPending).
+			Exist(&orginvites.AccessRequest{})
+		if err != nil {
+			return err
+		}
+		if pending {
+			return orginvites.ErrRequestPending
+		}
+		_, err = sess.Insert(request)
+		return err
+	})
+}
+
+func (s *sqlStore) GetRequest(ctx context.Context, orgID, id int64) (*orginvites.AccessRequestDTO, error) {
+	request := &orginvites.AccessRequestDTO{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := s.requests(sess).Where("r.org_id=? AND r.id=?", orgID, id).Get(request)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return orginvites.ErrRequestNotFound
+		}
+		return nil
+	})
+	return request, err
+}
+
+func (s *sqlStore) GetRequests(ctx context.Context, query *orginvites.GetRequestsQuery) ([]*orginvites.AccessRequestDTO, error) {
+	requests := make([]*orginvites.AccessRequestDTO, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := s.requests(sess).Where("r.org_id=?", query.OrgID)
+		if query.Status != "" {
+			q = q.And("r.status=?", query.Status)
+		}
+		return q.Desc("r.created").Find(&requests)
+	})
+	return requests, err
+}
+
+// requests selects the access requests with the login, email and name of
+// their users.
+func (s *sqlStore) requests(sess *sqlstore.DBSession) *sqlstore.DBSession {
+	sess.Table("org_access_request").Alias("r").
+		Join("INNER", []string{s.db.GetDialect().Quote("user"), "u"}, "u.id = r.user_id").
+		Cols("r.id", "r.org_id", "r.user_id", "u.login", "u.email", "u.name", "r.role", "r.message",
+			"r.status", "r.reviewed_by", "r.created", "r.reviewed")
+	return sess
+}
+
+func (s *sqlStore) Review(ctx context.Context, orgID, id int64, status orginvites.RequestStatus, reviewedBy int64, now time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("org_id=? AND id=? AND status=?", orgID, id, orginvites.RequestPending).
+			Cols("status", "reviewed_by", "reviewed").
+			Update(&orginvites.AccessRequest{Status: status, ReviewedBy: reviewedBy, Reviewed: &now})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return orginvites.ErrRequestNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+package orginvitestest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/orginvites"
+)
+
+type FakeOrgInvitesService struct {
+	ExpectedLink       *orginvites.Link
+	ExpectedLinks      []*orginvites.Link
+	ExpectedLinkInfo   *orginvites.LinkInfo
+	ExpectedJoinResult *orginvites.JoinResult
+	ExpectedRequests   []*orginvites.AccessRequestDTO
+	ExpectedError      error
+}
+
+func NewOrgInvitesServiceFake() *FakeOrgInvitesService {
+	return &FakeOrgInvitesService{}
+}
+
+func (f *FakeOrgInvitesService) CreateLink(ctx context.Context, cmd *orginvites.CreateLinkCommand) (*orginvites.Link, error) {
+	return f.ExpectedLink, f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) GetLinks(ctx context.Context, query *orginvites.GetLinksQuery) ([]*orginvites.Link, error) {
+	return f.ExpectedLinks, f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) DeleteLink(ctx context.Context, cmd *orginvites.DeleteLinkCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) GetLinkInfo(ctx context.Context, query *orginvites.GetLinkInfoQuery) (*orginvites.LinkInfo, error) {
+	return f.ExpectedLinkInfo, f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) Join(ctx context.Context, cmd *orginvites.JoinCommand) (*orginvites.JoinResult, error) {
+	return f.ExpectedJoinResult, f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) GetRequests(ctx context.Context, query *orginvites.GetRequestsQuery) ([]*orginvites.AccessRequestDTO, error) {
+	return f.ExpectedRequests, f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) ApproveRequest(ctx context.Context, cmd *orginvites.ApproveRequestCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeOrgInvitesService) RejectRequest(ctx context.Context, cmd *orginvites.RejectRequestCommand) error {
+	return f.ExpectedError
+}
@@ -0,0 +1,150 @@
+package ownership
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOwnershipNotFound   = errors.New("ownership not found")
+	ErrResourceNotFound    = errors.New("resource not found")
+	ErrOwnerNotFound       = errors.New("owner not found")
+	ErrInvalidResourceKind = errors.New("invalid resource kind")
+	ErrInvalidOwnerKind    = errors.New("invalid owner kind")
+	ErrInvalidLink         = errors.New("invalid link")
+)
+
+// MaxLinks is the number of external links a resource can have.
+const MaxLinks = 10
+
+type ResourceKind string
+
+const (
+	ResourceKindDashboard ResourceKind = "dashboard"
+	ResourceKindAlertRule ResourceKind = "alert-rule"
+)
+
+func (k ResourceKind) IsValid() bool {
+	return k == ResourceKindDashboard || k == ResourceKindAlertRule
+}
+
+type OwnerKind string
+
+const (
+	OwnerKindTeam OwnerKind = "team"
+	OwnerKindUser OwnerKind = "user"
+)
+
+func (k OwnerKind) IsValid() bool {
+	return k == OwnerKindTeam || k == OwnerKindUser
+}
+
+// Link is an external link of a resource, e.g. a runbook or the repository it is managed in.
+type Link struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Ownership is the owner and the external links of a dashboard or an alert rule.
+type Ownership struct {
+	Id           int64        `json:"-"`
+	OrgId        int64        `json:"-"`
+	ResourceKind ResourceKind `json:"resourceKind"`
+	ResourceUid  string       `json:"resourceUid"`
+	OwnerKind    OwnerKind    `json:"ownerKind"`
+	OwnerId      int64        `json:"ownerId"`
+	Links        []Link       `json:"links"`
+	Created      time.Time    `json:"created"`
+	Updated      time.Time    `json:"updated"`
+}
+
+func (o Ownership) TableName() string {
+	return "resource_ownership"
+}
+
+// Resource is a dashboard or an alert rule.
+type Resource struct {
+	Kind  ResourceKind
+	UID   string
+	Title string
+	// DashboardID is the dashboard, or for alert rules the folder, whose permissions apply to the resource.
+	DashboardID int64
+}
+
+// OwnedResource is an ownership together with the title of the resource.
+type OwnedResource struct {
+	Ownership   `xorm:"extends"`
+	Title       string `json:"title"`
+	DashboardId int64  `json:"-"`
+}
+
+// ----------------------
+// COMMANDS
+
+type SetOwnershipCommand struct {
+	OrgID        int64        `json:"-"`
+	ResourceKind ResourceKind `json:"-"`
+	ResourceUID  string       `json:"-"`
+
+	OwnerKind OwnerKind `json:"ownerKind"`
+	OwnerID   int64     `json:"ownerId"`
+	Links     []Link    `json:"links"`
+}
+
+type DeleteOwnershipCommand struct {
+	OrgID        int64
+	ResourceKind ResourceKind
+	ResourceUID  string
+}
+
+// ---------------------
+// QUERIES
+
+type GetOwnershipQuery struct {
+	OrgID        int64
+	ResourceKind ResourceKind
+	ResourceUID  string
+}
+
+type GetResourceQuery struct {
+	OrgID int64
+	Kind  ResourceKind
+	UID   string
+}
+
+type SearchOwnedResourcesQuery struct {
+	OrgID     int64
+	OwnerKind OwnerKind
+	OwnerID   int64
+}
+
+type SearchOrphanedResourcesQuery struct {
+	OrgID int64
+}
+
+// Validate checks the owner and the links of the command.
+func (cmd *SetOwnershipCommand) Validate() error {
+	if !cmd.ResourceKind.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidResourceKind, cmd.ResourceKind)
+	}
+	if !cmd.OwnerKind.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidOwnerKind, cmd.OwnerKind)
+	}
+	if cmd.OwnerID <= 0 {
+		return ErrOwnerNotFound
+	}
+	if len(cmd.Links) > MaxLinks {
+		return fmt.Errorf("%w: at most %d links are allowed", ErrInvalidLink, MaxLinks)
+	}
+	for i := range cmd.Links {
+		cmd.Links[i].Title = strings.TrimSpace(cmd.Links[i].Title)
+		u, err := url.Parse(cmd.Links[i].URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %q is not an http or https URL", ErrInvalidLink, cmd.Links[i].URL)
+		}
+	}
+	return nil
+}
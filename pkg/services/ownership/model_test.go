@@ -0,0 +1,42 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOwnershipCommand_Validate(t *testing.T) {
+	valid := func() *SetOwnershipCommand {
+		return &SetOwnershipCommand{
+			ResourceKind: ResourceKindDashboard,
+			OwnerKind:    OwnerKindTeam,
+			OwnerID:      1,
+			Links:        []Link{{Title: " Repository ", URL: "https://github.com/grafana/dashboards"}},
+		}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.Validate())
+	require.Equal(t, "Repository", cmd.Links[0].Title)
+
+	cmd = valid()
+	cmd.ResourceKind = "folder"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidResourceKind)
+
+	cmd = valid()
+	cmd.OwnerKind = "org"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidOwnerKind)
+
+	cmd = valid()
+	cmd.OwnerID = 0
+	require.ErrorIs(t, cmd.Validate(), ErrOwnerNotFound)
+
+	cmd = valid()
+	cmd.Links[0].URL = "javascript:alert(1)"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLink)
+
+	cmd = valid()
+	cmd.Links = make([]Link, MaxLinks+1)
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLink)
+}
@@ -0,0 +1,22 @@
+package ownership
+
+import (
+	"context"
+)
+
+// Service manages the owner and the external links, such as runbooks and repositories,
+// of dashboards and alert rules.
+type Service interface {
+	Get(ctx context.Context, query *GetOwnershipQuery) (*Ownership, error)
+	// Set replaces the owner and links of a resource.
+	Set(ctx context.Context, cmd *SetOwnershipCommand) (*Ownership, error)
+	Delete(ctx context.Context, cmd *DeleteOwnershipCommand) error
+
+	// GetResource returns the resource the ownership is attached to, it is used to check
+	// that the resource exists and to resolve the permissions that apply to it.
+	GetResource(ctx context.Context, query *GetResourceQuery) (*Resource, error)
+	// SearchOwned returns the resources owned by a team or a user.
+	SearchOwned(ctx context.Context, query *SearchOwnedResourcesQuery) ([]*OwnedResource, error)
+	// SearchOrphaned returns the resources whose owner has been deleted or removed from the organization.
+	SearchOrphaned(ctx context.Context, query *SearchOrphanedResourcesQuery) ([]*OwnedResource, error)
+}
@@ -0,0 +1,85 @@
+package ownershipimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ownership"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type Service struct {
+	store store
+	now   func() time.Time
+}
+
+func ProvideService(db db.DB) ownership.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		now: time.Now,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *ownership.GetOwnershipQuery) (*ownership.Ownership, error) {
+	return s.store.Get(ctx, query.OrgID, query.ResourceKind, query.ResourceUID)
+}
+
+func (s *Service) Set(ctx context.Context, cmd *ownership.SetOwnershipCommand) (*ownership.Ownership, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetResource(ctx, cmd.OrgID, cmd.ResourceKind, cmd.ResourceUID); err != nil {
+		return nil, err
+	}
+	exists, err := s.store.OwnerExists(ctx, cmd.OrgID, cmd.OwnerKind, cmd.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ownership.ErrOwnerNotFound
+	}
+
+	links := cmd.Links
+	if links == nil {
+		links = []ownership.Link{}
+	}
+	now := s.now()
+	o := &ownership.Ownership{
+		OrgId:        cmd.OrgID,
+		ResourceKind: cmd.ResourceKind,
+		ResourceUid:  cmd.ResourceUID,
+		OwnerKind:    cmd.OwnerKind,
+		OwnerId:      cmd.OwnerID,
+		Links:        links,
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.store.Upsert(ctx, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *ownership.DeleteOwnershipCommand) error {
+	return s.store.Delete(ctx, cmd.OrgID, cmd.ResourceKind, cmd.ResourceUID)
+}
+
+func (s *Service) GetResource(ctx context.Context, query *ownership.GetResourceQuery) (*ownership.Resource, error) {
+	if !query.Kind.IsValid() {
+		return nil, ownership.ErrInvalidResourceKind
+	}
+	return s.store.GetResource(ctx, query.OrgID, query.Kind, query.UID)
+}
+
+func (s *Service) SearchOwned(ctx context.Context, query *ownership.SearchOwnedResourcesQuery) ([]*ownership.OwnedResource, error) {
+	if !query.OwnerKind.IsValid() {
+		return nil, ownership.ErrInvalidOwnerKind
+	}
+	return s.store.SearchOwned(ctx, query.OrgID, query.OwnerKind, query.OwnerID)
+}
+
+func (s *Service) SearchOrphaned(ctx context.Context, query *ownership.SearchOrphanedResourcesQuery) ([]*ownership.OwnedResource, error) {
+	return s.store.SearchOrphaned(ctx, query.OrgID)
+}
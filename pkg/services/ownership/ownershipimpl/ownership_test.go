@@ -0,0 +1,113 @@
+package ownershipimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ownership"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationOwnership(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(ss)
+	ctx := context.Background()
+
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "owner", Email: "owner@example.com"})
+	require.NoError(t, err)
+	orgID := user.OrgId
+	team, err := ss.CreateTeam("platform", "platform@example.com", orgID)
+	require.NoError(t, err)
+
+	var folderID int64
+	err = ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		folder := &models.Dashboard{OrgId: orgID, Uid: "folder", Slug: "folder", Title: "Folder", IsFolder: true, Data: models.NewDashboard("Folder").Data, Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Insert(folder); err != nil {
+			return err
+		}
+		folderID = folder.Id
+		dashboard := &models.Dashboard{OrgId: orgID, FolderId: folderID, Uid: "dash", Slug: "dash", Title: "Dash", Data: models.NewDashboard("Dash").Data, Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Insert(dashboard); err != nil {
+			return err
+		}
+		rule := ngmodels.AlertRuleGen(func(r *ngmodels.AlertRule) {
+			r.OrgID = orgID
+			r.UID = "rule"
+			r.Title = "Rule"
+			r.NamespaceUID = "folder"
+		})()
+		_, err := sess.Insert(rule)
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Run("set validates the resource and the owner", func(t *testing.T) {
+		_, err := svc.Set(ctx, &ownership.SetOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "missing", OwnerKind: ownership.OwnerKindTeam, OwnerID: team.Id})
+		require.ErrorIs(t, err, ownership.ErrResourceNotFound)
+
+		_, err = svc.Set(ctx, &ownership.SetOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "folder", OwnerKind: ownership.OwnerKindTeam, OwnerID: team.Id})
+		require.ErrorIs(t, err, ownership.ErrResourceNotFound)
+
+		_, err = svc.Set(ctx, &ownership.SetOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "dash", OwnerKind: ownership.OwnerKindTeam, OwnerID: team.Id + 100})
+		require.ErrorIs(t, err, ownership.ErrOwnerNotFound)
+	})
+
+	t.Run("set, get and search owned resources", func(t *testing.T) {
+		_, err := svc.Set(ctx, &ownership.SetOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "dash", OwnerKind: ownership.OwnerKindUser, OwnerID: user.Id})
+		require.NoError(t, err)
+		o, err := svc.Set(ctx, &ownership.SetOwnershipCommand{
+			OrgID:        orgID,
+			ResourceKind: ownership.ResourceKindDashboard,
+			ResourceUID:  "dash",
+			OwnerKind:    ownership.OwnerKindTeam,
+			OwnerID:      team.Id,
+			Links:        []ownership.Link{{Title: "Runbook", URL: "https://example.com/runbook"}},
+		})
+		require.NoError(t, err)
+		_, err = svc.Set(ctx, &ownership.SetOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindAlertRule, ResourceUID: "rule", OwnerKind: ownership.OwnerKindTeam, OwnerID: team.Id})
+		require.NoError(t, err)
+
+		stored, err := svc.Get(ctx, &ownership.GetOwnershipQuery{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "dash"})
+		require.NoError(t, err)
+		require.Equal(t, o.Id, stored.Id)
+		require.Equal(t, ownership.OwnerKindTeam, stored.OwnerKind)
+		require.Equal(t, []ownership.Link{{Title: "Runbook", URL: "https://example.com/runbook"}}, stored.Links)
+
+		owned, err := svc.SearchOwned(ctx, &ownership.SearchOwnedResourcesQuery{OrgID: orgID, OwnerKind: ownership.OwnerKindTeam, OwnerID: team.Id})
+		require.NoError(t, err)
+		require.Len(t, owned, 2)
+		require.Equal(t, "Dash", owned[0].Title)
+		require.Equal(t, "Rule", owned[1].Title)
+		require.Equal(t, folderID, owned[1].DashboardId)
+
+		owned, err = svc.SearchOwned(ctx, &ownership.SearchOwnedResourcesQuery{OrgID: orgID, OwnerKind: ownership.OwnerKindUser, OwnerID: user.Id})
+		require.NoError(t, err)
+		require.Empty(t, owned)
+	})
+
+	t.Run("resources of deleted teams are orphaned", func(t *testing.T) {
+		orphaned, err := svc.SearchOrphaned(ctx, &ownership.SearchOrphanedResourcesQuery{OrgID: orgID})
+		require.NoError(t, err)
+		require.Empty(t, orphaned)
+
+		require.NoError(t, ss.DeleteTeam(ctx, &models.DeleteTeamCommand{OrgId: orgID, Id: team.Id}))
+
+		orphaned, err = svc.SearchOrphaned(ctx, &ownership.SearchOrphanedResourcesQuery{OrgID: orgID})
+		require.NoError(t, err)
+		require.Len(t, orphaned, 2)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, &ownership.DeleteOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "dash"}))
+		err := svc.Delete(ctx, &ownership.DeleteOwnershipCommand{OrgID: orgID, ResourceKind: ownership.ResourceKindDashboard, ResourceUID: "dash"})
+		require.ErrorIs(t, err, ownership.ErrOwnershipNotFound)
+	})
+}
@@ -0,0 +1,163 @@
+package ownershipimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ownership"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) (*ownership.Ownership, error)
+	Upsert(ctx context.Context, o *ownership.Ownership) error
+	Delete(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) error
+	GetResource(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) (*ownership.Resource, error)
+	OwnerExists(ctx context.Context, orgID int64, kind ownership.OwnerKind, id int64) (bool, error)
+	SearchOwned(ctx context.Context, orgID int64, kind ownership.OwnerKind, id int64) ([]*ownership.OwnedResource, error)
+	SearchOrphaned(ctx context.Context, orgID int64) ([]*ownership.OwnedResource, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) (*ownership.Ownership, error) {
+	o := &ownership.Ownership{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND resource_kind=? AND resource_uid=?", orgID, kind, uid).Get(o)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ownership.ErrOwnershipNotFound
+		}
+		return nil
+	})
+	return o, err
+}
+
+func (s *sqlStore) Upsert(ctx context.Context, o *ownership.Ownership) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := &ownership.Ownership{}
+		exists, err := sess.Where("org_id=? AND resource_kind=? AND resource_uid=?", o.OrgId, o.ResourceKind, o.ResourceUid).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(o)
+			return err
+		}
+
+		o.Id = existing.Id
+		o.Created = existing.Created
+		_, err = sess.ID(o.Id).Cols("owner_kind", "owner_id", "links", "updated").Update(o)
+		return err
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("org_id=? AND resource_kind=? AND resource_uid=?", orgID, kind, uid).Delete(&ownership.Ownership{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ownership.ErrOwnershipNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) GetResource(ctx context.Context, orgID int64, kind ownership.ResourceKind, uid string) (*ownership.Resource, error) {
+	var row struct {
+		Title       string
+		DashboardId int64
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var exists bool
+		var err error
+		switch kind {
+		case ownership.ResourceKindDashboard:
+			exists, err = sess.SQL("SELECT title, id AS dashboard_id FROM dashboard WHERE org_id = ? AND uid = ? AND is_folder = ?",
+				orgID, uid, s.db.GetDialect().BooleanStr(false)).Get(&row)
+		case ownership.ResourceKindAlertRule:
+			exists, err = sess.SQL(`SELECT alert_rule.title, dashboard.id AS dashboard_id FROM alert_rule
+				INNER JOIN dashboard ON dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.namespace_uid
+				WHERE alert_rule.org_id = ? AND alert_rule.uid = ?`, orgID, uid).Get(&row)
+		default:
+			return ownership.ErrInvalidResourceKind
+		}
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ownership.ErrResourceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ownership.Resource{Kind: kind, UID: uid, Title: row.Title, DashboardID: row.DashboardId}, nil
+}
+
+func (s *sqlStore) OwnerExists(ctx context.Context, orgID int64, kind ownership.OwnerKind, id int64) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		switch kind {
+		case ownership.OwnerKindTeam:
+			exists, err = sess.Table("team").Where("org_id = ? AND id = ?", orgID, id).Exist()
+		case ownership.OwnerKindUser:
+			exists, err = sess.Table("org_user").Where("org_id = ? AND user_id = ?", orgID, id).Exist()
+		default:
+			err = ownership.ErrInvalidOwnerKind
+		}
+		return err
+	})
+	return exists, err
+}
+
+func (s *sqlStore) SearchOwned(ctx context.Context, orgID int64, kind ownership.OwnerKind, id int64) ([]*ownership.OwnedResource, error) {
+	return s.search(ctx, orgID, "resource_ownership.owner_kind = ? AND resource_ownership.owner_id = ?", kind, id)
+}
+
+// SearchOrphaned returns the ownerships whose team has been deleted or whose user is no
+// longer a member of the organization.
+func (s *sqlStore) SearchOrphaned(ctx context.Context, orgID int64) ([]*ownership.OwnedResource, error) {
+	return s.search(ctx, orgID, `((resource_ownership.owner_kind = ? AND NOT EXISTS (SELECT 1 FROM team WHERE team.org_id = resource_ownership.org_id AND team.id = resource_ownership.owner_id))
+		OR (resource_ownership.owner_kind = ? AND NOT EXISTS (SELECT 1 FROM org_user WHERE org_user.org_id = resource_ownership.org_id AND org_user.user_id = resource_ownership.owner_id)))`,
+		ownership.OwnerKindTeam, ownership.OwnerKindUser)
+}
+
+// search returns the ownerships matching the filter. Ownerships of deleted resources are skipped.
+func (s *sqlStore) search(ctx context.Context, orgID int64, filter string, args ...interface{}) ([]*ownership.OwnedResource, error) {
+	result := make([]*ownership.OwnedResource, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		dashboards := make([]*ownership.OwnedResource, 0)
+		params := append([]interface{}{s.db.GetDialect().BooleanStr(false), orgID, ownership.ResourceKindDashboard}, args...)
+		err := sess.SQL(`SELECT resource_ownership.*, dashboard.title, dashboard.id AS dashboard_id FROM resource_ownership
+			INNER JOIN dashboard ON dashboard.org_id = resource_ownership.org_id AND dashboard.uid = resource_ownership.resource_uid AND dashboard.is_folder = ?
+			WHERE resource_ownership.org_id = ? AND resource_ownership.resource_kind = ? AND `+filter+`
+			ORDER BY dashboard.title`, params...).Find(&dashboards)
+		if err != nil {
+			return err
+		}
+
+		rules := make([]*ownership.OwnedResource, 0)
+		params = append([]interface{}{orgID, ownership.ResourceKindAlertRule}, args...)
+		err = sess.SQL(`SELECT resource_ownership.*, alert_rule.title, dashboard.id AS dashboard_id FROM resource_ownership
+			INNER JOIN alert_rule ON alert_rule.org_id = resource_ownership.org_id AND alert_rule.uid = resource_ownership.resource_uid
+			INNER JOIN dashboard ON dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.namespace_uid
+			WHERE resource_ownership.org_id = ? AND resource_ownership.resource_kind = ? AND `+filter+`
+			ORDER BY alert_rule.title`, params...).Find(&rules)
+		if err != nil {
+			return err
+		}
+
+		result = append(dashboards, rules...)
+		return nil
+	})
+	return result, err
+}
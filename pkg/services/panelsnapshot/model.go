@@ -0,0 +1,68 @@
+package panelsnapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrPanelNotFound         = errors.New("panel not found")
+	ErrPanelWithoutQueries   = errors.New("panel has no queries")
+	ErrPanelSnapshotTooLarge = errors.New("panel data is larger than the maximum size of a panel snapshot")
+)
+
+// CreatePanelSnapshotCommand is the command for taking a snapshot of the data of a panel
+// swagger:model
+type CreatePanelSnapshotCommand struct {
+	// From Start of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// Defaults to the time range of the dashboard.
+	// example: now-6h
+	From string `json:"from"`
+	// To End of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// Defaults to the time range of the dashboard.
+	// example: now
+	To string `json:"to"`
+	// Values selects the values of template variables by name, instead of the values saved with the dashboard.
+	Values map[string][]string `json:"values"`
+	// MaxDataPoints is the number of points the queries are asked for, when the panel doesn't set it.
+	// default: 1000
+	MaxDataPoints int64 `json:"maxDataPoints"`
+	// Store keeps the snapshot as a dashboard snapshot with only the panel.
+	Store bool `json:"store"`
+	// Name of the stored snapshot. Defaults to the titles of the dashboard and of the panel.
+	Name string `json:"name"`
+	// Expires is the number of seconds after which the stored snapshot is deleted. 0 means never.
+	Expires int64 `json:"expires"`
+
+	PanelID   int64 `json:"-"`
+	SkipCache bool  `json:"-"`
+}
+
+// PanelSnapshot is the data of a panel at the time the snapshot was taken.
+type PanelSnapshot struct {
+	DashboardUID string `json:"dashboardUid"`
+	PanelID      int64  `json:"panelId"`
+	// Panel is the model of the panel, to render the data the way the dashboard does.
+	Panel *simplejson.Json `json:"panel"`
+	// From and To are the time range the queries ran with, in epoch milliseconds.
+	From      int64     `json:"from"`
+	To        int64     `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	// Data is the results of the queries by refId, as returned by /api/ds/query.
+	Data json.RawMessage `json:"data"`
+	// Size is the size of Data in bytes.
+	Size int `json:"size"`
+	// Stored is set when the snapshot is stored as a dashboard snapshot.
+	Stored *StoredPanelSnapshot `json:"stored,omitempty"`
+}
+
+type StoredPanelSnapshot struct {
+	ID        int64  `json:"id"`
+	Key       string `json:"key"`
+	DeleteKey string `json:"deleteKey"`
+	URL       string `json:"url"`
+	DeleteURL string `json:"deleteUrl"`
+}
@@ -0,0 +1,15 @@
+package panelsnapshot
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service takes snapshots of the data of a single panel, without the rest of
+// the dashboard, e.g. to attach them to tickets or notifications.
+type Service interface {
+	// Create runs the queries of the panel and returns their results, storing
+	// them as a dashboard snapshot with only the panel when the command asks to.
+	Create(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *CreatePanelSnapshotCommand) (*PanelSnapshot, error)
+}
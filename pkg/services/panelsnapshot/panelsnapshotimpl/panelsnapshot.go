@@ -0,0 +1,284 @@
+package panelsnapshotimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const defaultMaxDataPoints = 1000
+
+// dataQuerier runs the queries of the panel, it is implemented by query.Service.
+type dataQuerier interface {
+	QueryDataMultipleSources(ctx context.Context, user *models.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, handleExpressions bool) (*backend.QueryDataResponse, error)
+}
+
+type Service struct {
+	cfg                *setting.Cfg
+	queryService       dataQuerier
+	variables          dashboardvariables.Service
+	dashboardSnapshots *dashboardsnapshots.Service
+	now                func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, queryService *query.Service, variables dashboardvariables.Service, dashboardSnapshots *dashboardsnapshots.Service) panelsnapshot.Service {
+	return &Service{
+		cfg:                cfg,
+		queryService:       queryService,
+		variables:          variables,
+		dashboardSnapshots: dashboardSnapshots,
+		now:                time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *panelsnapshot.CreatePanelSnapshotCommand) (*panelsnapshot.PanelSnapshot, error) {
+	if !dashboards.CanViewPanel(user, dashboard.Data, cmd.PanelID) {
+		return nil, dashboards.ErrPanelHidden
+	}
+	panel := findPanel(dashboard.Data, cmd.PanelID)
+	if panel == nil {
+		return nil, panelsnapshot.ErrPanelNotFound
+	}
+
+	if cmd.From == "" {
+		cmd.From = dashboard.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if cmd.To == "" {
+		cmd.To = dashboard.Data.Get("time").Get("to").MustString("now")
+	}
+	// the relative time range is resolved once, so that the variables, the
+	// queries and the snapshot all use the same time range
+	timeRange := legacydata.NewDataTimeRange(cmd.From, cmd.To)
+	from, to := timeRange.GetFromAsMsEpoch(), timeRange.GetToAsMsEpoch()
+	resolvedRange := dashboardvariables.ResolveVariablesCommand{
+		From:      strconv.FormatInt(from, 10),
+		To:        strconv.FormatInt(to, 10),
+		Values:    cmd.Values,
+		SkipCache: cmd.SkipCache,
+	}
+
+	values, err := s.variables.InterpolationValues(ctx, user, dashboard, resolvedRange)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(cmd.MaxDataPoints)
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
+	interval := panelInterval(panel, values, time.Duration(to-from)*time.Millisecond, maxDataPoints)
+	values["__interval"] = []string{intervalv2.FormatDuration(interval)}
+	values["__interval_ms"] = []string{strconv.FormatInt(interval.Milliseconds(), 10)}
+
+	queries := panelQueries(panel, values, maxDataPoints, interval)
+	if len(queries) == 0 {
+		return nil, panelsnapshot.ErrPanelWithoutQueries
+	}
+
+	resp, err := s.queryService.QueryDataMultipleSources(ctx, user, cmd.SkipCache, dtos.MetricRequest{
+		From:    resolvedRange.From,
+		To:      resolvedRange.To,
+		Queries: queries,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(encoded)) > s.cfg.PanelSnapshotMaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes, the maximum is %d bytes", panelsnapshot.ErrPanelSnapshotTooLarge, len(encoded), s.cfg.PanelSnapshotMaxBytes)
+	}
+
+	snapshot := &panelsnapshot.PanelSnapshot{
+		DashboardUID: dashboard.Uid,
+		PanelID:      cmd.PanelID,
+		Panel:        panel,
+		From:         from,
+		To:           to,
+		Timestamp:    s.now(),
+		Data:         encoded,
+		Size:         len(encoded),
+	}
+
+	if cmd.Store {
+		stored, err := s.store(ctx, user, dashboard, snapshot, resp, cmd)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Stored = stored
+	}
+	return snapshot, nil
+}
+
+// store saves the snapshot as a dashboard snapshot with only the panel, with the
+// frames of the queries as snapshot data, like the snapshots taken by the frontend.
+func (s *Service) store(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, snapshot *panelsnapshot.PanelSnapshot, resp *backend.QueryDataResponse, cmd *panelsnapshot.CreatePanelSnapshotCommand) (*panelsnapshot.StoredPanelSnapshot, error) {
+	title := fmt.Sprintf("%s - %s", dashboard.Title, snapshot.Panel.Get("title").MustString())
+	name := cmd.Name
+	if name == "" {
+		name = title
+	}
+
+	panel, err := copyJSON(snapshot.Panel)
+	if err != nil {
+		return nil, err
+	}
+	panel.Set("gridPos", map[string]interface{}{"x": 0, "y": 0, "w": 24, "h": panel.GetPath("gridPos", "h").MustInt(9)})
+	panel.Set("targets", []interface{}{})
+	panel.Set("links", []interface{}{})
+	panel.Set("datasource", nil)
+	panel.Set("snapshotData", responseFrames(resp))
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	deleteKey, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotCmd := &models.CreateDashboardSnapshotCommand{
+		Name: name,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title":         title,
+			"editable":      false,
+			"schemaVersion": dashboard.Data.Get("schemaVersion").MustInt(),
+			"time": map[string]interface{}{
+				"from": time.UnixMilli(snapshot.From).UTC().Format(time.RFC3339),
+				"to":   time.UnixMilli(snapshot.To).UTC().Format(time.RFC3339),
+			},
+			"panels": []interface{}{panel.Interface()},
+			"snapshot": map[string]interface{}{
+				"timestamp": snapshot.Timestamp,
+			},
+		}),
+		Expires:   cmd.Expires,
+		OrgId:     user.OrgId,
+		UserId:    user.UserId,
+		Key:       key,
+		DeleteKey: deleteKey,
+	}
+	if err := s.dashboardSnapshots.CreateDashboardSnapshot(ctx, snapshotCmd); err != nil {
+		return nil, err
+	}
+	metrics.MApiDashboardSnapshotCreate.Inc()
+
+	return &panelsnapshot.StoredPanelSnapshot{
+		ID:        snapshotCmd.Result.Id,
+		Key:       key,
+		DeleteKey: deleteKey,
+		URL:       setting.ToAbsUrl("dashboard/snapshot/" + key),
+		DeleteURL: setting.ToAbsUrl("api/snapshots-delete/" + deleteKey),
+	}, nil
+}
+
+// findPanel returns the panel with the id, looking into collapsed rows too.
+func findPanel(dashboard *simplejson.Json, id int64) *simplejson.Json {
+	for _, obj := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(obj)
+		if panel.Get("id").MustInt64() == id {
+			return panel
+		}
+		for _, nested := range panel.Get("panels").MustArray() {
+			nestedPanel := simplejson.NewFromAny(nested)
+			if nestedPanel.Get("id").MustInt64() == id {
+				return nestedPanel
+			}
+		}
+	}
+	return nil
+}
+
+// panelInterval returns the interval of the points the queries are asked for, at
+// least the minimum interval of the panel when it sets one.
+func panelInterval(panel *simplejson.Json, values map[string][]string, timeRange time.Duration, maxDataPoints int64) time.Duration {
+	interval := timeRange / time.Duration(maxDataPoints)
+	minInterval := strings.TrimPrefix(dashboardvariables.Interpolate(panel.Get("interval").MustString(), values), ">")
+	if minInterval != "" {
+		if min, err := gtime.ParseInterval(minInterval); err == nil && min > interval {
+			interval = min
+		}
+	}
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	return interval
+}
+
+// panelQueries returns the queries of the panel that aren't hidden, interpolated
+// with the values of the variables, using the data source of the panel when the
+// query doesn't set one.
+func panelQueries(panel *simplejson.Json, values map[string][]string, maxDataPoints int64, interval time.Duration) []*simplejson.Json {
+	queries := make([]*simplejson.Json, 0)
+	for _, obj := range panel.Get("targets").MustArray() {
+		target, ok := dashboardvariables.InterpolateValue(obj, values).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		query := simplejson.NewFromAny(target)
+		if query.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := query.CheckGet("datasource"); !ok {
+			query.Set("datasource", panel.Get("datasource").Interface())
+		}
+		query.Set("maxDataPoints", maxDataPoints)
+		query.Set("intervalMs", interval.Milliseconds())
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+// responseFrames returns the frames of all the queries, ordered by refId.
+func responseFrames(resp *backend.QueryDataResponse) []*data.Frame {
+	refIDs := make([]string, 0, len(resp.Responses))
+	for refID := range resp.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	frames := make([]*data.Frame, 0)
+	for _, refID := range refIDs {
+		for _, frame := range resp.Responses[refID].Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+func copyJSON(j *simplejson.Json) (*simplejson.Json, error) {
+	encoded, err := j.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(encoded)
+}
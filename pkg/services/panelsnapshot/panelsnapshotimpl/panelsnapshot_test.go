@@ -0,0 +1,211 @@
+package panelsnapshotimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeQuerier struct {
+	request  dtos.MetricRequest
+	response *backend.QueryDataResponse
+}
+
+func (f *fakeQuerier) QueryDataMultipleSources(ctx context.Context, user *models.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, handleExpressions bool) (*backend.QueryDataResponse, error) {
+	f.request = reqDTO
+	return f.response, nil
+}
+
+type fakeVariables struct {
+	dashboardvariables.Service
+	cmd    dashboardvariables.ResolveVariablesCommand
+	values map[string][]string
+}
+
+func (f *fakeVariables) InterpolationValues(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd dashboardvariables.ResolveVariablesCommand) (map[string][]string, error) {
+	f.cmd = cmd
+	values := map[string][]string{}
+	for name, v := range f.values {
+		values[name] = v
+	}
+	return values, nil
+}
+
+func TestIntegrationPanelSnapshot(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	snapshots := dashboardsnapshots.ProvideService(sqlStore, secretsService)
+
+	dashboard := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"uid":   "dash",
+		"title": "Servers",
+		"time":  map[string]interface{}{"from": "now-1h", "to": "now"},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         1,
+				"title":      "CPU",
+				"type":       "timeseries",
+				"interval":   "1m",
+				"datasource": map[string]interface{}{"uid": "prom"},
+				"gridPos":    map[string]interface{}{"x": 12, "y": 4, "w": 12, "h": 8},
+				"targets": []interface{}{
+					map[string]interface{}{"refId": "A", "expr": "cpu{host=~\"${host:regex}\"}"},
+					map[string]interface{}{"refId": "B", "expr": "cpu", "hide": true},
+				},
+			},
+			map[string]interface{}{"id": 2, "title": "Text", "type": "text"},
+			map[string]interface{}{
+				"id":         5,
+				"title":      "Costs",
+				"visibility": map[string]interface{}{"roles": []interface{}{"Editor"}},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "costs"}},
+			},
+			map[string]interface{}{
+				"id":        3,
+				"type":      "row",
+				"collapsed": true,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":      4,
+						"title":   "Memory",
+						"targets": []interface{}{map[string]interface{}{"refId": "A", "datasource": map[string]interface{}{"uid": "loki"}}},
+					},
+				},
+			},
+		},
+	}))
+
+	frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{1, 2, 3}))
+	querier := &fakeQuerier{response: &backend.QueryDataResponse{
+		Responses: backend.Responses{"A": backend.DataResponse{Frames: data.Frames{frame}}},
+	}}
+	variables := &fakeVariables{values: map[string][]string{"host": {"a", "b"}}}
+
+	cfg := setting.NewCfg()
+	cfg.PanelSnapshotMaxBytes = 1 << 20
+	service := &Service{
+		cfg:                cfg,
+		queryService:       querier,
+		variables:          variables,
+		dashboardSnapshots: snapshots,
+		now:                func() time.Time { return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC) },
+	}
+	user := &models.SignedInUser{OrgId: 1, UserId: 2, OrgRole: models.ROLE_VIEWER}
+
+	t.Run("Runs the queries of the panel", func(t *testing.T) {
+		snapshot, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{
+			PanelID: 1,
+			From:    "1640995200000",
+			To:      "1641081600000",
+		})
+		require.NoError(t, err)
+		require.Nil(t, snapshot.Stored)
+		require.Equal(t, int64(1640995200000), snapshot.From)
+		require.Equal(t, int64(1641081600000), snapshot.To)
+		require.Equal(t, "CPU", snapshot.Panel.Get("title").MustString())
+		require.Equal(t, len(snapshot.Data), snapshot.Size)
+
+		require.Equal(t, "1640995200000", variables.cmd.From)
+		require.Len(t, querier.request.Queries, 1)
+		query := querier.request.Queries[0]
+		require.Equal(t, `cpu{host=~"(a|b)"}`, query.Get("expr").MustString())
+		require.Equal(t, "prom", query.GetPath("datasource", "uid").MustString())
+		require.Equal(t, int64(1000), query.Get("maxDataPoints").MustInt64())
+		// a day over 1000 points is more than the minimum interval of the panel
+		require.Equal(t, int64(86400), query.Get("intervalMs").MustInt64())
+
+		var resp backend.QueryDataResponse
+		require.NoError(t, json.Unmarshal(snapshot.Data, &resp))
+		require.Len(t, resp.Responses["A"].Frames, 1)
+	})
+
+	t.Run("Uses the time range of the dashboard and the minimum interval of the panel", func(t *testing.T) {
+		snapshot, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(time.Hour/time.Millisecond), snapshot.To-snapshot.From)
+		require.Equal(t, int64(60000), querier.request.Queries[0].Get("intervalMs").MustInt64())
+	})
+
+	t.Run("Finds panels in collapsed rows", func(t *testing.T) {
+		_, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 4})
+		require.NoError(t, err)
+		require.Equal(t, "loki", querier.request.Queries[0].GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("Fails for missing panels and panels without queries", func(t *testing.T) {
+		_, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 10})
+		require.ErrorIs(t, err, panelsnapshot.ErrPanelNotFound)
+		_, err = service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 2})
+		require.ErrorIs(t, err, panelsnapshot.ErrPanelWithoutQueries)
+	})
+
+	t.Run("Fails for panels hidden from the user", func(t *testing.T) {
+		querier.request = dtos.MetricRequest{}
+		_, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 5})
+		require.ErrorIs(t, err, dashboards.ErrPanelHidden)
+		require.Empty(t, querier.request.Queries)
+
+		editor := &models.SignedInUser{OrgId: 1, UserId: 3, OrgRole: models.ROLE_EDITOR}
+		_, err = service.Create(context.Background(), editor, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 5})
+		require.NoError(t, err)
+		require.Equal(t, "costs", querier.request.Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("Fails when the data is larger than the maximum size", func(t *testing.T) {
+		cfg.PanelSnapshotMaxBytes = 10
+		t.Cleanup(func() { cfg.PanelSnapshotMaxBytes = 1 << 20 })
+
+		_, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{PanelID: 1})
+		require.True(t, errors.Is(err, panelsnapshot.ErrPanelSnapshotTooLarge))
+	})
+
+	t.Run("Stores the snapshot as a dashboard snapshot", func(t *testing.T) {
+		snapshot, err := service.Create(context.Background(), user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{
+			PanelID: 1,
+			Store:   true,
+			Expires: 3600,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, snapshot.Stored)
+		require.Contains(t, snapshot.Stored.URL, "dashboard/snapshot/"+snapshot.Stored.Key)
+
+		query := &models.GetDashboardSnapshotQuery{Key: snapshot.Stored.Key}
+		require.NoError(t, snapshots.GetDashboardSnapshot(context.Background(), query))
+		require.Equal(t, "Servers - CPU", query.Result.Name)
+		require.Equal(t, int64(2), query.Result.UserId)
+
+		panels := query.Result.Dashboard.Get("panels").MustArray()
+		require.Len(t, panels, 1)
+		panel := simplejson.NewFromAny(panels[0])
+		require.Equal(t, 0, panel.GetPath("gridPos", "x").MustInt())
+		require.Equal(t, 24, panel.GetPath("gridPos", "w").MustInt())
+		require.Equal(t, 8, panel.GetPath("gridPos", "h").MustInt())
+		require.Empty(t, panel.Get("targets").MustArray())
+		require.Len(t, panel.Get("snapshotData").MustArray(), 1)
+		require.Equal(t, "A", panel.Get("snapshotData").GetIndex(0).Get("schema").Get("refId").MustString())
+
+		// the panel of the dashboard is left as is
+		require.Equal(t, 12, dashboard.Data.Get("panels").GetIndex(0).GetPath("gridPos", "x").MustInt())
+	})
+}
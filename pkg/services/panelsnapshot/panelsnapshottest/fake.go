@@ -0,0 +1,21 @@
+package panelsnapshottest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+)
+
+type FakePanelSnapshotService struct {
+	ExpectedSnapshot *panelsnapshot.PanelSnapshot
+	ExpectedError    error
+}
+
+func NewPanelSnapshotServiceFake() *FakePanelSnapshotService {
+	return &FakePanelSnapshotService{}
+}
+
+func (f *FakePanelSnapshotService) Create(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *panelsnapshot.CreatePanelSnapshotCommand) (*panelsnapshot.PanelSnapshot, error) {
+	return f.ExpectedSnapshot, f.ExpectedError
+}
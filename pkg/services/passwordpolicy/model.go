@@ -0,0 +1,58 @@
+package passwordpolicy
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPasswordPolicy is wrapped by the errors of the passwords that don't
+	// satisfy the policy, their message tells the user what to change.
+	ErrPasswordPolicy   = errors.New("password does not satisfy the password policy")
+	ErrMaxAgeNotSet     = errors.New("the maximum age of the passwords is not configured")
+	ErrInvalidOlderThan = errors.New("olderThanDays must be a positive number of days")
+)
+
+// Rule is a requirement of the password policy.
+type Rule interface {
+	// Check returns an error wrapping ErrPasswordPolicy when the password
+	// doesn't satisfy the rule.
+	Check(ctx context.Context, password string) error
+}
+
+// RuleFunc is a Rule implemented by a function.
+type RuleFunc func(ctx context.Context, password string) error
+
+func (f RuleFunc) Check(ctx context.Context, password string) error {
+	return f(ctx, password)
+}
+
+// StalePassword is a user whose password is older than the maximum age.
+type StalePassword struct {
+	UserID int64  `json:"userId" xorm:"id"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	// PasswordChanged is when the password was last set, the creation of the
+	// user when it was set before the changes of the passwords were tracked.
+	PasswordChanged time.Time `json:"passwordChanged"`
+	AgeDays         int       `json:"ageDays" xorm:"-"`
+	LastSeenAt      time.Time `json:"lastSeenAt"`
+	IsDisabled      bool      `json:"isDisabled"`
+}
+
+type GetStalePasswordsQuery struct {
+	// OlderThanDays overrides the configured maximum age of the passwords.
+	OlderThanDays int
+	Page          int
+	Limit         int
+}
+
+type StalePasswordsResult struct {
+	TotalCount int64            `json:"totalCount"`
+	MaxAgeDays int              `json:"maxAgeDays"`
+	Users      []*StalePassword `json:"users"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+}
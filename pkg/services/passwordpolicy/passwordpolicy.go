@@ -0,0 +1,24 @@
+package passwordpolicy
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service enforces the policy of the passwords of the users signing in with
+// their Grafana username and password.
+type Service interface {
+	// Validate checks a password before it is set, it fails with an error
+	// wrapping ErrPasswordPolicy when the password doesn't satisfy a rule.
+	Validate(ctx context.Context, password string) error
+	// RegisterRule adds a rule the passwords must satisfy on top of the
+	// configured ones.
+	RegisterRule(rule Rule)
+	// IsExpired returns true when the password of the user is older than the
+	// configured maximum age.
+	IsExpired(user *models.User) bool
+	// GetStalePasswords returns the users whose password hasn't been changed
+	// for longer than the maximum age.
+	GetStalePasswords(ctx context.Context, query *GetStalePasswordsQuery) (*StalePasswordsResult, error)
+}
@@ -0,0 +1,122 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultStalePasswordsLimit = 100
+	maxStalePasswordsLimit     = 1000
+)
+
+type Service struct {
+	store  store
+	cfg    setting.PasswordPolicySettings
+	breach passwordpolicy.Rule
+	now    func() time.Time
+
+	mtx   sync.RWMutex
+	rules []passwordpolicy.Rule
+}
+
+func ProvideService(db db.DB, cfg *setting.Cfg) passwordpolicy.Service {
+	s := &Service{
+		store: &sqlStore{db: db},
+		cfg:   cfg.PasswordPolicy,
+		now:   time.Now,
+		rules: []passwordpolicy.Rule{
+			minLengthRule(cfg.PasswordPolicy.MinLength),
+			characterClassesRule(cfg.PasswordPolicy),
+		},
+	}
+	if cfg.PasswordPolicy.BreachCheckEnabled {
+		s.breach = newBreachChecker(cfg.PasswordPolicy)
+	}
+	return s
+}
+
+func (s *Service) Validate(ctx context.Context, password string) error {
+	s.mtx.RLock()
+	rules := s.rules
+	s.mtx.RUnlock()
+
+	for _, rule := range rules {
+		if err := rule.Check(ctx, password); err != nil {
+			return err
+		}
+	}
+	// the breach check calls an external API, it runs once the password passed the other rules
+	if s.breach != nil {
+		return s.breach.Check(ctx, password)
+	}
+	return nil
+}
+
+func (s *Service) RegisterRule(rule passwordpolicy.Rule) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.rules = append(s.rules[:len(s.rules):len(s.rules)], rule)
+}
+
+func (s *Service) IsExpired(user *models.User) bool {
+	if s.cfg.MaxAgeDays <= 0 || user.Password == "" {
+		return false
+	}
+	changed := user.PasswordChanged
+	if changed.IsZero() {
+		changed = user.Created
+	}
+	return s.now().Sub(changed) > days(s.cfg.MaxAgeDays)
+}
+
+func (s *Service) GetStalePasswords(ctx context.Context, query *passwordpolicy.GetStalePasswordsQuery) (*passwordpolicy.StalePasswordsResult, error) {
+	maxAge := query.OlderThanDays
+	if maxAge < 0 {
+		return nil, passwordpolicy.ErrInvalidOlderThan
+	}
+	if maxAge == 0 {
+		maxAge = s.cfg.MaxAgeDays
+	}
+	if maxAge <= 0 {
+		return nil, passwordpolicy.ErrMaxAgeNotSet
+	}
+
+	page, limit := query.Page, query.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultStalePasswordsLimit
+	}
+	if limit > maxStalePasswordsLimit {
+		limit = maxStalePasswordsLimit
+	}
+
+	now := s.now()
+	users, total, err := s.store.GetStalePasswords(ctx, now.Add(-days(maxAge)), page, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		u.AgeDays = int(now.Sub(u.PasswordChanged) / (24 * time.Hour))
+	}
+
+	return &passwordpolicy.StalePasswordsResult{
+		TotalCount: total,
+		MaxAgeDays: maxAge,
+		Users:      users,
+		Page:       page,
+		PerPage:    limit,
+	}, nil
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
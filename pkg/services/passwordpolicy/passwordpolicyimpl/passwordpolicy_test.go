@@ -0,0 +1,156 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.PasswordPolicy = setting.PasswordPolicySettings{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	s := ProvideService(nil, cfg)
+
+	testCases := []struct {
+		password string
+		err      string
+	}{
+		{password: "Sh0rt!", err: "it must be at least 8 characters long"},
+		{password: "lowercase only", err: "it must contain an uppercase letter, a digit"},
+		{password: "UPPERCASE-1234", err: "it must contain a lowercase letter"},
+		{password: "NoSymbols1234", err: "it must contain a symbol"},
+		{password: "Ünïcödé-1234"},
+		{password: "Correct-Horse-1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			err := s.Validate(ctx, tc.password)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, passwordpolicy.ErrPasswordPolicy)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	t.Run("registered rules are enforced", func(t *testing.T) {
+		s.RegisterRule(passwordpolicy.RuleFunc(func(_ context.Context, password string) error {
+			if password == "Correct-Horse-1" {
+				return fmt.Errorf("%w: it is the example password", passwordpolicy.ErrPasswordPolicy)
+			}
+			return nil
+		}))
+		require.ErrorIs(t, s.Validate(ctx, "Correct-Horse-1"), passwordpolicy.ErrPasswordPolicy)
+		require.NoError(t, s.Validate(ctx, "Correct-Horse-2"))
+	})
+}
+
+func TestBreachCheck(t *testing.T) {
+	ctx := context.Background()
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		if r.URL.Path == "/range/FFFFF" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n011053FD0102E94D6AE2F8B83D76FAF94F6:0\r\n")
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.PasswordPolicy = setting.PasswordPolicySettings{
+		MinLength:          4,
+		BreachCheckEnabled: true,
+		BreachCheckURL:     server.URL + "/range",
+		BreachCheckTimeout: time.Second,
+	}
+	s := ProvideService(nil, cfg)
+
+	err := s.Validate(ctx, "password")
+	require.ErrorIs(t, err, passwordpolicy.ErrPasswordPolicy)
+	require.Contains(t, err.Error(), "data breach")
+	require.Equal(t, "/range/5BAA6", requested, "only the prefix of the hash must be sent")
+
+	require.NoError(t, s.Validate(ctx, "not in the list"))
+
+	t.Run("passwords are accepted when the range API fails", func(t *testing.T) {
+		checker := newBreachChecker(cfg.PasswordPolicy)
+		breached, err := checker.isBreached(ctx, "FFFFF", "0")
+		require.Error(t, err)
+		require.False(t, breached)
+		require.NoError(t, checker.Check(ctx, "password-with-unreachable-api"))
+	})
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	cfg := setting.NewCfg()
+	cfg.PasswordPolicy.MaxAgeDays = 90
+	s := ProvideService(nil, cfg).(*Service)
+	s.now = func() time.Time { return now }
+
+	assert.False(t, s.IsExpired(&models.User{Password: "hash", PasswordChanged: now.AddDate(0, 0, -30)}))
+	assert.True(t, s.IsExpired(&models.User{Password: "hash", PasswordChanged: now.AddDate(0, 0, -91)}))
+	assert.True(t, s.IsExpired(&models.User{Password: "hash", Created: now.AddDate(-1, 0, 0)}), "the creation is used when the change isn't known")
+	assert.False(t, s.IsExpired(&models.User{Created: now.AddDate(-1, 0, 0)}), "users without password don't expire")
+
+	s.cfg.MaxAgeDays = 0
+	assert.False(t, s.IsExpired(&models.User{Password: "hash", PasswordChanged: now.AddDate(-1, 0, 0)}))
+}
+
+type fakeStore struct {
+	cutoff time.Time
+	users  []*passwordpolicy.StalePassword
+}
+
+func (f *fakeStore) GetStalePasswords(_ context.Context, cutoff time.Time, _, _ int) ([]*passwordpolicy.StalePassword, int64, error) {
+	f.cutoff = cutoff
+	return f.users, int64(len(f.users)), nil
+}
+
+func TestGetStalePasswords(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{users: []*passwordpolicy.StalePassword{{UserID: 1, PasswordChanged: now.AddDate(0, 0, -120)}}}
+	s := &Service{store: store, now: func() time.Time { return now }}
+
+	_, err := s.GetStalePasswords(ctx, &passwordpolicy.GetStalePasswordsQuery{})
+	require.True(t, errors.Is(err, passwordpolicy.ErrMaxAgeNotSet))
+	_, err = s.GetStalePasswords(ctx, &passwordpolicy.GetStalePasswordsQuery{OlderThanDays: -1})
+	require.True(t, errors.Is(err, passwordpolicy.ErrInvalidOlderThan))
+
+	s.cfg.MaxAgeDays = 90
+	result, err := s.GetStalePasswords(ctx, &passwordpolicy.GetStalePasswordsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, -90), store.cutoff)
+	require.Equal(t, 90, result.MaxAgeDays)
+	require.Equal(t, 120, result.Users[0].AgeDays)
+	require.Equal(t, 1, result.Page)
+	require.Equal(t, defaultStalePasswordsLimit, result.PerPage)
+
+	result, err = s.GetStalePasswords(ctx, &passwordpolicy.GetStalePasswordsQuery{OlderThanDays: 30, Limit: 5000})
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, -30), store.cutoff)
+	require.Equal(t, maxStalePasswordsLimit, result.PerPage)
+}
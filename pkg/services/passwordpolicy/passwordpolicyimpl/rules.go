@@ -0,0 +1,142 @@
+package passwordpolicyimpl
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // #nosec G505 -- the range API indexes the breached passwords by their SHA-1 hash
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// minLengthRule rejects the passwords that have fewer characters than the minimum length.
+func minLengthRule(minLength int) passwordpolicy.Rule {
+	return passwordpolicy.RuleFunc(func(_ context.Context, password string) error {
+		if utf8.RuneCountInString(password) < minLength {
+			return fmt.Errorf("%w: it must be at least %d characters long", passwordpolicy.ErrPasswordPolicy, minLength)
+		}
+		return nil
+	})
+}
+
+// characterClassesRule rejects the passwords that miss a character of one of the required classes.
+func characterClassesRule(cfg setting.PasswordPolicySettings) passwordpolicy.Rule {
+	return passwordpolicy.RuleFunc(func(_ context.Context, password string) error {
+		var upper, lower, digit, symbol bool
+		for _, r := range password {
+			switch {
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsLower(r):
+				lower = true
+			case unicode.IsDigit(r):
+				digit = true
+			case !unicode.IsLetter(r):
+				symbol = true
+			}
+		}
+
+		var missing []string
+		if cfg.RequireUppercase && !upper {
+			missing = append(missing, "an uppercase letter")
+		}
+		if cfg.RequireLowercase && !lower {
+			missing = append(missing, "a lowercase letter")
+		}
+		if cfg.RequireDigit && !digit {
+			missing = append(missing, "a digit")
+		}
+		if cfg.RequireSymbol && !symbol {
+			missing = append(missing, "a symbol")
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: it must contain %s", passwordpolicy.ErrPasswordPolicy, strings.Join(missing, ", "))
+		}
+		return nil
+	})
+}
+
+// breachChecker rejects the passwords found in data breaches with a range API that
+// returns the suffixes of the SHA-1 hashes of the breached passwords sharing the 5
+// first characters of the hash, so the password and its full hash are never sent.
+type breachChecker struct {
+	client *http.Client
+	url    string
+	log    log.Logger
+}
+
+func newBreachChecker(cfg setting.PasswordPolicySettings) *breachChecker {
+	url := cfg.BreachCheckURL
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return &breachChecker{
+		client: &http.Client{Timeout: cfg.BreachCheckTimeout},
+		url:    url,
+		log:    log.New("passwordpolicy.breach"),
+	}
+}
+
+// Check accepts the password when the range API can't be reached, so that users
+// aren't prevented from changing their password during an outage.
+func (c *breachChecker) Check(ctx context.Context, password string) error {
+	sum := sha1.Sum([]byte(password)) // #nosec G401 -- the range API indexes the breached passwords by their SHA-1 hash
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	breached, err := c.isBreached(ctx, hash[:5], hash[5:])
+	if err != nil {
+		c.log.Warn("Failed to check the password against the breached passwords, accepting it", "err", err)
+		return nil
+	}
+	if breached {
+		return fmt.Errorf("%w: it has appeared in a data breach, choose another password", passwordpolicy.ErrPasswordPolicy)
+	}
+	return nil
+}
+
+func (c *breachChecker) isBreached(ctx context.Context, prefix, suffix string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// the padding hides the number of suffixes of the prefix from the observers of the traffic
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// each line is <suffix>:<number of times the password appeared in breaches>
+		line := strings.TrimSpace(scanner.Text())
+		hashSuffix, count, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(hashSuffix, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return false, err
+		}
+		// padding entries have a count of 0
+		return n > 0, nil
+	}
+	return false, scanner.Err()
+}
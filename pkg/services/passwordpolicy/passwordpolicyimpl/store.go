@@ -0,0 +1,45 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// GetStalePasswords returns the users signing in with a Grafana password
+	// that hasn't been changed since before the cutoff, oldest first.
+	GetStalePasswords(ctx context.Context, cutoff time.Time, page, limit int) ([]*passwordpolicy.StalePassword, int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) GetStalePasswords(ctx context.Context, cutoff time.Time, page, limit int) ([]*passwordpolicy.StalePassword, int64, error) {
+	users := make([]*passwordpolicy.StalePassword, 0)
+	var total int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// users of external auth modules don't sign in with their Grafana password
+		where := `u.password IS NOT NULL AND u.password <> '' AND u.is_service_account = ` + s.db.GetDialect().BooleanStr(false) + `
+			AND NOT EXISTS (SELECT 1 FROM user_auth ua WHERE ua.user_id = u.id)
+			AND COALESCE(u.password_changed, u.created) < ?`
+
+		var err error
+		total, err = sess.Table("user").Alias("u").Where(where, cutoff).Count()
+		if err != nil {
+			return err
+		}
+
+		return sess.Table("user").Alias("u").
+			Select("u.id, u.login, u.email, u.name, COALESCE(u.password_changed, u.created) AS password_changed, u.last_seen_at, u.is_disabled").
+			Where(where, cutoff).
+			OrderBy("password_changed ASC, u.id ASC").
+			Limit(limit, (page-1)*limit).
+			Find(&users)
+	})
+	return users, total, err
+}
@@ -0,0 +1,74 @@
+package passwordpolicyimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationGetStalePasswords(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+	store := &sqlStore{db: ss}
+
+	longAgo := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	createUser := func(t *testing.T, cmd models.CreateUserCommand, created, passwordChanged *time.Time) *models.User {
+		t.Helper()
+		user, err := ss.CreateUser(ctx, cmd)
+		require.NoError(t, err)
+		err = ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			if _, err := sess.ID(user.Id).Cols("created").Update(&models.User{Created: *created}); err != nil {
+				return err
+			}
+			if passwordChanged == nil {
+				_, err := sess.Exec("UPDATE "+ss.Dialect.Quote("user")+" SET password_changed = NULL WHERE id = ?", user.Id)
+				return err
+			}
+			_, err := sess.ID(user.Id).Cols("password_changed").Update(&models.User{PasswordChanged: *passwordChanged})
+			return err
+		})
+		require.NoError(t, err)
+		return user
+	}
+	at := func(days int) *time.Time {
+		t := longAgo.AddDate(0, 0, days)
+		return &t
+	}
+
+	oldest := createUser(t, models.CreateUserCommand{Login: "stale-oldest", Password: "password"}, at(0), at(1))
+	unknown := createUser(t, models.CreateUserCommand{Login: "stale-unknown", Password: "password"}, at(2), nil)
+	createUser(t, models.CreateUserCommand{Login: "stale-recent", Password: "password"}, at(0), at(400))
+	createUser(t, models.CreateUserCommand{Login: "stale-no-password"}, at(0), nil)
+	createUser(t, models.CreateUserCommand{Login: "stale-service-account", Password: "password", IsServiceAccount: true}, at(0), at(0))
+	external := createUser(t, models.CreateUserCommand{Login: "stale-ldap", Password: "password"}, at(0), at(0))
+	err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(&models.UserAuth{UserId: external.Id, AuthModule: models.AuthModuleLDAP, AuthId: "stale-ldap", Created: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	cutoff := longAgo.AddDate(0, 0, 30)
+	users, total, err := store.GetStalePasswords(ctx, cutoff, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Len(t, users, 2)
+	require.Equal(t, oldest.Id, users[0].UserID)
+	require.Equal(t, "stale-oldest", users[0].Login)
+	require.True(t, at(1).Equal(users[0].PasswordChanged))
+	require.Equal(t, unknown.Id, users[1].UserID)
+	require.True(t, at(2).Equal(users[1].PasswordChanged), "the creation is used when the change isn't known")
+
+	users, total, err = store.GetStalePasswords(ctx, cutoff, 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Len(t, users, 1)
+	require.Equal(t, unknown.Id, users[0].UserID)
+}
@@ -0,0 +1,32 @@
+package passwordpolicytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+)
+
+type FakePasswordPolicyService struct {
+	ExpectedExpired        bool
+	ExpectedStalePasswords *passwordpolicy.StalePasswordsResult
+	ExpectedError          error
+}
+
+func NewPasswordPolicyServiceFake() *FakePasswordPolicyService {
+	return &FakePasswordPolicyService{}
+}
+
+func (f *FakePasswordPolicyService) Validate(ctx context.Context, password string) error {
+	return f.ExpectedError
+}
+
+func (f *FakePasswordPolicyService) RegisterRule(rule passwordpolicy.Rule) {}
+
+func (f *FakePasswordPolicyService) IsExpired(user *models.User) bool {
+	return f.ExpectedExpired
+}
+
+func (f *FakePasswordPolicyService) GetStalePasswords(ctx context.Context, query *passwordpolicy.GetStalePasswordsQuery) (*passwordpolicy.StalePasswordsResult, error) {
+	return f.ExpectedStalePasswords, f.ExpectedError
+}
@@ -4,10 +4,29 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
-var ErrPrefNotFound = errors.New("preference not found")
+var (
+	ErrPrefNotFound             = errors.New("preference not found")
+	ErrInvalidTheme             = errors.New("invalid theme")
+	ErrInvalidWeekStart         = errors.New("invalid week start")
+	ErrInvalidHomeDashboardPath = errors.New("home dashboard path must be an absolute path to a .json file")
+	ErrCustomTooLarge           = errors.New("custom preferences are too large")
+	ErrInvalidNavbarItem        = errors.New("invalid navbar item")
+	// ErrInvalidQueryHistoryHomeTab is returned for a home tab other than query or starred.
+	ErrInvalidQueryHistoryHomeTab = errors.New("invalid query history home tab")
+	// ErrPreferencesVersionMismatch is returned when the preferences have been
+	// saved since the version the command was made from.
+	ErrPreferencesVersionMismatch = errors.New("the preferences have been changed by someone else")
+)
+
+// MaxCustomSize is the maximum size of the encoded custom preferences of a user,
+// team or organization.
+const MaxCustomSize = 64 * 1024
 
 type Preference struct {
 	ID              int64   `xorm:"pk autoincr 'id'"`
@@ -23,8 +42,44 @@ type Preference struct {
 	Created         time.Time
 	Updated         time.Time
 	JSONData        *PreferenceJSONData `xorm:"json_data"`
+	// Sources are where the preferences resolved by GetWithDefaults come from,
+	// keyed by the JSON name of the preference.
+	Sources map[string]PreferenceSource `xorm:"-"`
 }
 
+// PreferenceSource is the level a resolved preference is set on.
+type PreferenceSource string
+
+const (
+	PreferenceSourceDefault PreferenceSource = "default"
+	PreferenceSourceOrg     PreferenceSource = "org"
+	PreferenceSourceTeam    PreferenceSource = "team"
+	PreferenceSourceUser    PreferenceSource = "user"
+)
+
+// Source returns the level the preference is set on.
+func (p *Preference) Source() PreferenceSource {
+	switch {
+	case p.UserID != 0:
+		return PreferenceSourceUser
+	case p.TeamID != 0:
+		return PreferenceSourceTeam
+	default:
+		return PreferenceSourceOrg
+	}
+}
+
+// TeamOrder is how the preferences of the teams of a user are ordered, the
+// preferences of the last team take precedence over the ones of the others.
+type TeamOrder string
+
+const (
+	// TeamOrderPriority orders the teams by their priority, then by their id.
+	TeamOrderPriority TeamOrder = "priority"
+	// TeamOrderUpdated orders the teams by when their preferences were last updated.
+	TeamOrderUpdated TeamOrder = "updated"
+)
+
 type GetPreferenceWithDefaultsQuery struct {
 	Teams  []int64
 	OrgID  int64
@@ -50,6 +105,15 @@ type SavePreferenceCommand struct {
 	Locale           string                  `json:"locale,omitempty"`
 	Navbar           *NavbarPreference       `json:"navbar,omitempty"`
 	QueryHistory     *QueryHistoryPreference `json:"queryHistory,omitempty"`
+	// DefaultDatasourceUID is the uid of the data source used instead of the
+	// default data source of the organization.
+	DefaultDatasourceUID string `json:"defaultDatasourceUid,omitempty"`
+	// Custom replaces the custom preferences when it is set.
+	Custom map[string]interface{} `json:"custom,omitempty"`
+	// Version is the version of the preferences the command replaces. When it is
+	// set, saving fails with ErrPreferencesVersionMismatch if the preferences have
+	// been saved since.
+	Version *int `json:"version,omitempty"`
 }
 
 type PatchPreferenceCommand struct {
@@ -65,6 +129,171 @@ type PatchPreferenceCommand struct {
 	Locale           *string                 `json:"locale,omitempty"`
 	Navbar           *NavbarPreference       `json:"navbar,omitempty"`
 	QueryHistory     *QueryHistoryPreference `json:"queryHistory,omitempty"`
+	// DefaultDatasourceUID is the uid of the data source used instead of the
+	// default data source of the organization, empty resets it.
+	DefaultDatasourceUID *string `json:"defaultDatasourceUid,omitempty"`
+	// Custom is merged into the custom preferences, keys set to null are removed.
+	Custom map[string]interface{} `json:"custom,omitempty"`
+	// DismissedAnnouncements replaces the uids of the announcements the user has dismissed.
+	DismissedAnnouncements *[]string `json:"-"`
+	// Onboarding replaces the onboarding progress of the user.
+	Onboarding *OnboardingPreference `json:"-"`
+}
+
+// PatchOrgUsersPreferencesCommand sets preferences of all the users of an
+// organization. Setting a preference to its zero value resets it, so that the
+// users get the one of their teams, organization or instance.
+type PatchOrgUsersPreferencesCommand struct {
+	OrgID int64 `json:"-"`
+
+	HomeDashboardID  *int64  `json:"homeDashboardId,omitempty"`
+	HomeDashboardUID *string `json:"homeDashboardUID,omitempty"`
+	Timezone         *string `json:"timezone,omitempty"`
+	WeekStart        *string `json:"weekStart,omitempty"`
+	Theme            *string `json:"theme,omitempty"`
+	// ResetJSONData resets the locale, navbar, query history, default data
+	// source and custom preferences of the users.
+	ResetJSONData bool `json:"-"`
+}
+
+// SetsValues returns whether the command sets a preference to a value that is
+// not the zero value.
+func (cmd *PatchOrgUsersPreferencesCommand) SetsValues() bool {
+	return (cmd.HomeDashboardID != nil && *cmd.HomeDashboardID != 0) ||
+		(cmd.Timezone != nil && *cmd.Timezone != "") ||
+		(cmd.WeekStart != nil && *cmd.WeekStart != "") ||
+		(cmd.Theme != nil && *cmd.Theme != "")
+}
+
+// ResetsValues returns whether the command resets a preference to its zero
+// value.
+func (cmd *PatchOrgUsersPreferencesCommand) ResetsValues() bool {
+	return cmd.ResetJSONData ||
+		(cmd.HomeDashboardID != nil && *cmd.HomeDashboardID == 0) ||
+		(cmd.Timezone != nil && *cmd.Timezone == "") ||
+		(cmd.WeekStart != nil && *cmd.WeekStart == "") ||
+		(cmd.Theme != nil && *cmd.Theme == "")
+}
+
+func (cmd *PatchOrgUsersPreferencesCommand) Validate() error {
+	if cmd.Theme != nil {
+		switch *cmd.Theme {
+		case "", "light", "dark":
+		default:
+			return ErrInvalidTheme
+		}
+	}
+	if cmd.WeekStart != nil {
+		switch *cmd.WeekStart {
+		case "", "browser", "saturday", "sunday", "monday":
+		default:
+			return ErrInvalidWeekStart
+		}
+	}
+	return nil
+}
+
+// PreferencesExport are the preferences of an organization, of its teams and of
+// its users. Teams are identified by name, users by login and the home
+// dashboards by uid, so that the preferences can be imported in an
+// organization of another instance.
+type PreferencesExport struct {
+	Org   *ExportedPreferences      `json:"org,omitempty"`
+	Teams []ExportedTeamPreferences `json:"teams"`
+	Users []ExportedUserPreferences `json:"users"`
+}
+
+type ExportedPreferences struct {
+	HomeDashboardUID string              `json:"homeDashboardUID,omitempty"`
+	Timezone         string              `json:"timezone,omitempty"`
+	WeekStart        string              `json:"weekStart,omitempty"`
+	Theme            string              `json:"theme,omitempty"`
+	JSONData         *PreferenceJSONData `json:"jsonData,omitempty"`
+}
+
+type ExportedTeamPreferences struct {
+	Team string `json:"team"`
+	ExportedPreferences
+}
+
+type ExportedUserPreferences struct {
+	Login string `json:"login"`
+	ExportedPreferences
+}
+
+func (p *ExportedPreferences) Validate() error {
+	switch p.Theme {
+	case "", "light", "dark":
+	default:
+		return ErrInvalidTheme
+	}
+	switch p.WeekStart {
+	case "", "browser", "saturday", "sunday", "monday":
+	default:
+		return ErrInvalidWeekStart
+	}
+	if p.JSONData == nil {
+		return nil
+	}
+	if err := p.JSONData.Navbar.Validate(); err != nil {
+		return err
+	}
+	return p.JSONData.QueryHistory.Validate()
+}
+
+// ImportPreferencesCommand replaces the preferences of an organization, and of
+// the teams and users of the export that exist in the organization. The
+// preferences of the teams and users that aren't in the export are kept.
+type ImportPreferencesCommand struct {
+	OrgID  int64
+	Export PreferencesExport
+}
+
+// ImportPreferencesResult lists what the imported preferences refer to that
+// doesn't exist in the organization. The preferences of the missing teams and
+// users are skipped, and the missing home dashboards are reset.
+type ImportPreferencesResult struct {
+	Imported          int      `json:"imported"`
+	MissingTeams      []string `json:"missingTeams"`
+	MissingUsers      []string `json:"missingUsers"`
+	MissingDashboards []string `json:"missingDashboards"`
+}
+
+// InstanceDefaults are the preferences used for everyone who has not set them
+// on the user, team or organization level. Values changed at runtime override
+// the ones from the configuration file.
+type InstanceDefaults struct {
+	Theme             string `json:"theme"`
+	Timezone          string `json:"timezone"`
+	WeekStart         string `json:"weekStart"`
+	HomeDashboardPath string `json:"homeDashboardPath"`
+}
+
+// UpdateInstanceDefaultsCommand replaces the instance defaults changed at
+// runtime. Empty values fall back to the configuration file.
+type UpdateInstanceDefaultsCommand struct {
+	Theme             string `json:"theme"`
+	Timezone          string `json:"timezone"`
+	WeekStart         string `json:"weekStart"`
+	HomeDashboardPath string `json:"homeDashboardPath"`
+}
+
+func (cmd *UpdateInstanceDefaultsCommand) Validate() error {
+	switch cmd.Theme {
+	case "", "light", "dark":
+	default:
+		return ErrInvalidTheme
+	}
+	switch cmd.WeekStart {
+	case "", "browser", "saturday", "sunday", "monday":
+	default:
+		return ErrInvalidWeekStart
+	}
+	if cmd.HomeDashboardPath != "" &&
+		(!filepath.IsAbs(cmd.HomeDashboardPath) || filepath.Ext(cmd.HomeDashboardPath) != ".json") {
+		return ErrInvalidHomeDashboardPath
+	}
+	return nil
 }
 
 type NavLink struct {
@@ -76,16 +305,113 @@ type NavLink struct {
 
 type NavbarPreference struct {
 	SavedItems []NavLink `json:"savedItems"`
+	// PinnedItems are the ids of the navigation items pinned to the top of the
+	// navigation bar, in order.
+	PinnedItems []string `json:"pinnedItems,omitempty"`
+	// HiddenItems are the ids of the navigation items hidden from the navigation bar.
+	HiddenItems []string `json:"hiddenItems,omitempty"`
+}
+
+// navbarItemIDs are the ids of the navigation items that can be pinned or
+// hidden, the items of the app plugins have the id plugin-page-<plugin id>.
+var navbarItemIDs = map[string]bool{
+	"starred":          true,
+	"create":           true,
+	"dashboards":       true,
+	"explore":          true,
+	"profile":          true,
+	"alerting":         true,
+	"alerting-legacy":  true,
+	"data-connections": true,
+	"cfg":              true,
+	"live":             true,
+	"admin":            true,
+	"help":             true,
+}
+
+const navbarPluginItemPrefix = "plugin-page-"
+
+// IsEmpty returns whether the navbar isn't customized.
+func (n NavbarPreference) IsEmpty() bool {
+	return len(n.SavedItems) == 0 && len(n.PinnedItems) == 0 && len(n.HiddenItems) == 0
+}
+
+// Validate checks that the pinned and hidden items are known navigation items,
+// and that no item is both pinned and hidden.
+func (n NavbarPreference) Validate() error {
+	pinned := make(map[string]bool, len(n.PinnedItems))
+	for _, id := range n.PinnedItems {
+		if !isNavbarItemID(id) {
+			return fmt.Errorf("%w: unknown item %q", ErrInvalidNavbarItem, id)
+		}
+		if pinned[id] {
+			return fmt.Errorf("%w: %q is pinned more than once", ErrInvalidNavbarItem, id)
+		}
+		pinned[id] = true
+	}
+	for _, id := range n.HiddenItems {
+		if !isNavbarItemID(id) {
+			return fmt.Errorf("%w: unknown item %q", ErrInvalidNavbarItem, id)
+		}
+		if pinned[id] {
+			return fmt.Errorf("%w: %q can't be both pinned and hidden", ErrInvalidNavbarItem, id)
+		}
+	}
+	return nil
+}
+
+func isNavbarItemID(id string) bool {
+	if strings.HasPrefix(id, navbarPluginItemPrefix) {
+		return len(id) > len(navbarPluginItemPrefix)
+	}
+	return navbarItemIDs[id]
 }
 
 type PreferenceJSONData struct {
 	Locale       string                 `json:"locale"`
 	Navbar       NavbarPreference       `json:"navbar"`
 	QueryHistory QueryHistoryPreference `json:"queryHistory"`
+	// DefaultDatasourceUID is the uid of the preferred default data source. The one
+	// of users overrides the ones of their teams, which override the one of the
+	// organization, like the home dashboard.
+	DefaultDatasourceUID string `json:"defaultDatasourceUid,omitempty"`
+	// DismissedAnnouncements are the uids of the announcement banners the user has dismissed.
+	DismissedAnnouncements []string `json:"dismissedAnnouncements,omitempty"`
+	// Onboarding is the progress of the user through the getting started guides.
+	Onboarding *OnboardingPreference `json:"onboarding,omitempty"`
+	// Custom are the settings of plugins and frontend features that don't have a
+	// field of their own, e.g. the defaults of a query editor. The custom
+	// preferences of users override the ones of their teams and organization key
+	// by key.
+	Custom map[string]interface{} `json:"custom,omitempty"`
+}
+
+type OnboardingPreference struct {
+	DismissedPanels    []string `json:"dismissedPanels,omitempty"`
+	CompletedSteps     []string `json:"completedSteps,omitempty"`
+	DiscoveredFeatures []string `json:"discoveredFeatures,omitempty"`
 }
 
 type QueryHistoryPreference struct {
+	// HomeTab is the tab the query history of Explore opens on, either query or starred.
 	HomeTab string `json:"homeTab"`
+	// RetentionOptIn is whether the queries run in Explore are kept in the query
+	// history. The query history is kept when no user, team or organization sets it.
+	RetentionOptIn *bool `json:"retentionOptIn,omitempty"`
+}
+
+// IsEmpty returns whether none of the query history preferences are set.
+func (q QueryHistoryPreference) IsEmpty() bool {
+	return q.HomeTab == "" && q.RetentionOptIn == nil
+}
+
+func (q QueryHistoryPreference) Validate() error {
+	switch q.HomeTab {
+	case "", "query", "starred":
+		return nil
+	default:
+		return ErrInvalidQueryHistoryHomeTab
+	}
 }
 
 func (j *PreferenceJSONData) FromDB(data []byte) error {
@@ -9,5 +9,20 @@ type Service interface {
 	Get(context.Context, *GetPreferenceQuery) (*Preference, error)
 	Save(context.Context, *SavePreferenceCommand) error
 	Patch(context.Context, *PatchPreferenceCommand) error
+	// PatchOrgUsers sets preferences of all the users of the organization in one
+	// transaction, and returns how many users were updated.
+	PatchOrgUsers(context.Context, *PatchOrgUsersPreferencesCommand) (int64, error)
 	GetDefaults() *Preference
+	// GetCustom returns the custom preferences of the user, merged with the ones of
+	// their teams and organization.
+	GetCustom(context.Context, *GetPreferenceWithDefaultsQuery) (map[string]interface{}, error)
+	// GetInstanceDefaults returns the preferences used when they are not set for
+	// a user, team or organization.
+	GetInstanceDefaults() InstanceDefaults
+	UpdateInstanceDefaults(context.Context, *UpdateInstanceDefaultsCommand) (InstanceDefaults, error)
+	// Export returns the preferences of the organization, its teams and users.
+	Export(ctx context.Context, orgID int64) (*PreferencesExport, error)
+	// Import replaces the preferences of an organization with exported ones in
+	// one transaction.
+	Import(context.Context, *ImportPreferencesCommand) (*ImportPreferencesResult, error)
 }
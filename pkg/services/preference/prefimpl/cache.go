@@ -0,0 +1,258 @@
+package prefimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+var cacheRequestsCounter *prometheus.CounterVec
+
+func init() {
+	cacheRequestsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "preferences",
+		Name:      "cache_requests_total",
+		Help:      "Number of preferences lookups, by whether they were served from the cache or from the database.",
+	}, []string{"result"})
+
+	prometheus.MustRegister(cacheRequestsCounter)
+}
+
+// cacheStorage stores the encoded preferences rows.
+type cacheStorage interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, expire time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type localCacheStorage struct {
+	cache *localcache.CacheService
+}
+
+func (s *localCacheStorage) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return value.([]byte), true, nil
+}
+
+func (s *localCacheStorage) Set(_ context.Context, key string, value []byte, expire time.Duration) error {
+	s.cache.Set(key, value, expire)
+	return nil
+}
+
+func (s *localCacheStorage) Delete(_ context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+type remoteCacheStorage struct {
+	cache remotecache.CacheStorage
+}
+
+func (s *remoteCacheStorage) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.cache.Get(ctx, key)
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	encoded, ok := value.([]byte)
+	return encoded, ok, nil
+}
+
+func (s *remoteCacheStorage) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	return s.cache.Set(ctx, key, value, expire)
+}
+
+func (s *remoteCacheStorage) Delete(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, key)
+}
+
+// cachedStore caches the preferences rows listed to merge the preferences of a
+// user, one entry per row so that saving a row only invalidates its entry.
+// Rows that don't exist are cached too, as most users, teams and organizations
+// don't have preferences. Get is not cached, as it is used to update the rows.
+type cachedStore struct {
+	store
+	cache cacheStorage
+	ttl   time.Duration
+	log   log.Logger
+}
+
+func newCachedStore(inner store, cache cacheStorage, ttl time.Duration) *cachedStore {
+	return &cachedStore{
+		store: inner,
+		cache: cache,
+		ttl:   ttl,
+		log:   log.New("preferences.cache"),
+	}
+}
+
+func (s *cachedStore) List(ctx context.Context, query *pref.Preference) ([]*pref.Preference, error) {
+	keys := listCacheKeys(query)
+	prefs := make([]*pref.Preference, 0, len(keys))
+	for _, key := range keys {
+		encoded, ok, err := s.cache.Get(ctx, key)
+		if err != nil {
+			s.log.Warn("Failed to get preferences from cache", "key", key, "error", err)
+		}
+		if !ok || err != nil {
+			return s.listAndCache(ctx, query, keys)
+		}
+		p, err := decodeCachedPreference(encoded)
+		if err != nil {
+			s.log.Warn("Failed to decode cached preferences", "key", key, "error", err)
+			return s.listAndCache(ctx, query, keys)
+		}
+		if p != nil {
+			prefs = append(prefs, p)
+		}
+	}
+
+	cacheRequestsCounter.WithLabelValues("hit").Inc()
+	return prefs, nil
+}
+
+func (s *cachedStore) listAndCache(ctx context.Context, query *pref.Preference, keys []string) ([]*pref.Preference, error) {
+	cacheRequestsCounter.WithLabelValues("miss").Inc()
+
+	prefs, err := s.store.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]*pref.Preference, len(prefs))
+	for _, p := range prefs {
+		rows[cacheKey(p.OrgID, p.TeamID, p.UserID)] = p
+	}
+	for _, key := range keys {
+		// the rows that don't exist are encoded as null
+		encoded, err := json.Marshal(rows[key])
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cache.Set(ctx, key, encoded, s.ttl); err != nil {
+			s.log.Warn("Failed to cache preferences", "key", key, "error", err)
+		}
+	}
+	return prefs, nil
+}
+
+func (s *cachedStore) Insert(ctx context.Context, p *pref.Preference) (int64, error) {
+	id, err := s.store.Insert(ctx, p)
+	if err != nil {
+		return id, err
+	}
+	s.invalidate(ctx, p)
+	return id, nil
+}
+
+func (s *cachedStore) Update(ctx context.Context, p *pref.Preference) error {
+	if err := s.store.Update(ctx, p); err != nil {
+		return err
+	}
+	s.invalidate(ctx, p)
+	return nil
+}
+
+func (s *cachedStore) PatchOrgUsers(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) ([]patchedPreference, error) {
+	patched, err := s.store.PatchOrgUsers(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range patched {
+		s.invalidate(ctx, p.after)
+	}
+	return patched, nil
+}
+
+func (s *cachedStore) Import(ctx context.Context, prefs []*pref.Preference) ([]*pref.Preference, error) {
+	replaced, err := s.store.Import(ctx, prefs)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prefs {
+		s.invalidate(ctx, p)
+	}
+	return replaced, nil
+}
+
+// handleOrgDeleted evicts the preferences of a deleted organization. The
+// entries of its teams and users expire, they can't be listed anymore since
+// the organization doesn't exist.
+func (s *cachedStore) handleOrgDeleted(ctx context.Context, e *events.OrgDeleted) error {
+	s.invalidate(ctx, &pref.Preference{OrgID: e.Id})
+	return nil
+}
+
+func (s *cachedStore) handleTeamDeleted(ctx context.Context, e *events.TeamDeleted) error {
+	s.invalidate(ctx, &pref.Preference{OrgID: e.OrgId, TeamID: e.Id})
+	return nil
+}
+
+func (s *cachedStore) handleUserDeleted(ctx context.Context, e *events.UserDeleted) error {
+	for _, orgID := range e.OrgIds {
+		s.invalidate(ctx, &pref.Preference{OrgID: orgID, UserID: e.Id})
+	}
+	return nil
+}
+
+func (s *cachedStore) invalidate(ctx context.Context, p *pref.Preference) {
+	key := cacheKey(p.OrgID, p.TeamID, p.UserID)
+	if err := s.cache.Delete(ctx, key); err != nil {
+		s.log.Error("Failed to invalidate cached preferences", "key", key, "error", err)
+	}
+}
+
+// listCacheKeys returns the keys of the rows List returns for the query, in
+// the same order: the organization, the teams and the user.
+func listCacheKeys(query *pref.Preference) []string {
+	teams := make([]int64, len(query.Teams))
+	copy(teams, query.Teams)
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+
+	keys := make([]string, 0, len(teams)+2)
+	keys = append(keys, cacheKey(query.OrgID, 0, 0))
+	for i, teamID := range teams {
+		if teamID == 0 || (i > 0 && teams[i-1] == teamID) {
+			continue
+		}
+		keys = append(keys, cacheKey(query.OrgID, teamID, 0))
+	}
+	if query.UserID != 0 {
+		keys = append(keys, cacheKey(query.OrgID, 0, query.UserID))
+	}
+	return keys
+}
+
+func cacheKey(orgID, teamID, userID int64) string {
+	return fmt.Sprintf("preferences-%d-%d-%d", orgID, teamID, userID)
+}
+
+// decodeCachedPreference decodes the numbers of the custom preferences the same
+// way they are decoded from the database.
+func decodeCachedPreference(encoded []byte) (*pref.Preference, error) {
+	var p *pref.Preference
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
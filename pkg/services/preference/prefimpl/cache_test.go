@@ -0,0 +1,150 @@
+package prefimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type countingStore struct {
+	store
+	lists int
+}
+
+func (s *countingStore) List(ctx context.Context, query *pref.Preference) ([]*pref.Preference, error) {
+	s.lists++
+	return s.store.List(ctx, query)
+}
+
+func TestCachedStore(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *countingStore) {
+		t.Helper()
+		inner := &countingStore{store: newFake()}
+		cache := &localCacheStorage{cache: localcache.New(time.Minute, time.Minute)}
+		return &Service{
+			store: newCachedStore(inner, cache, time.Minute),
+			cfg:   setting.NewCfg(),
+		}, inner
+	}
+
+	query := &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 2, Teams: []int64{4, 3}}
+
+	t.Run("Preferences are listed from the cache until they are saved", func(t *testing.T) {
+		prefService, inner := setup(t)
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, Theme: "light"}))
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, TeamID: 3, Timezone: "UTC"}))
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, TeamID: 4, Timezone: "browser"}))
+
+		hits := testutil.ToFloat64(cacheRequestsCounter.WithLabelValues("hit"))
+		misses := testutil.ToFloat64(cacheRequestsCounter.WithLabelValues("miss"))
+
+		uncached, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		cached, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, inner.lists)
+		require.Equal(t, uncached, cached)
+		require.Equal(t, "light", cached.Theme)
+		require.Equal(t, "browser", cached.Timezone)
+		require.Equal(t, hits+1, testutil.ToFloat64(cacheRequestsCounter.WithLabelValues("hit")))
+		require.Equal(t, misses+1, testutil.ToFloat64(cacheRequestsCounter.WithLabelValues("miss")))
+
+		// the user had no preferences when they were cached
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, UserID: 2, Theme: "dark"}))
+		res, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.lists)
+		require.Equal(t, "dark", res.Theme)
+
+		theme := "light"
+		require.NoError(t, prefService.Patch(ctx, &pref.PatchPreferenceCommand{OrgID: 1, UserID: 2, Theme: &theme}))
+		res, err = prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, 3, inner.lists)
+		require.Equal(t, "light", res.Theme)
+	})
+
+	t.Run("Patching the preferences of the users of the organization invalidates them", func(t *testing.T) {
+		prefService, inner := setup(t)
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, UserID: 2, Theme: "light"}))
+
+		_, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+
+		theme := "dark"
+		updated, err := prefService.PatchOrgUsers(ctx, &pref.PatchOrgUsersPreferencesCommand{OrgID: 1, Theme: &theme})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), updated)
+
+		res, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.lists)
+		require.Equal(t, "dark", res.Theme)
+	})
+
+	t.Run("Preferences of other users and teams are cached separately", func(t *testing.T) {
+		prefService, inner := setup(t)
+
+		_, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		_, err = prefService.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 5, Teams: []int64{3}})
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.lists)
+
+		_, err = prefService.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 2, Teams: []int64{3}})
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.lists)
+	})
+
+	t.Run("Preferences of deleted organizations, teams and users are evicted", func(t *testing.T) {
+		prefService, inner := setup(t)
+		cached := prefService.store.(*cachedStore)
+
+		_, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, 1, inner.lists)
+
+		for _, evict := range []func() error{
+			func() error { return cached.handleOrgDeleted(ctx, &events.OrgDeleted{Id: 1}) },
+			func() error { return cached.handleTeamDeleted(ctx, &events.TeamDeleted{Id: 4, OrgId: 1}) },
+			func() error { return cached.handleUserDeleted(ctx, &events.UserDeleted{Id: 2, OrgIds: []int64{1}}) },
+		} {
+			lists := inner.lists
+			require.NoError(t, evict())
+			_, err := prefService.GetWithDefaults(ctx, query)
+			require.NoError(t, err)
+			require.Equal(t, lists+1, inner.lists)
+		}
+
+		// other teams and users are still cached
+		require.NoError(t, cached.handleTeamDeleted(ctx, &events.TeamDeleted{Id: 9, OrgId: 1}))
+		require.NoError(t, cached.handleUserDeleted(ctx, &events.UserDeleted{Id: 2, OrgIds: []int64{3}}))
+		lists := inner.lists
+		_, err = prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, lists, inner.lists)
+	})
+
+	t.Run("Custom preferences are decoded like the ones from the database", func(t *testing.T) {
+		prefService, _ := setup(t)
+		require.NoError(t, prefService.Save(ctx, &pref.SavePreferenceCommand{OrgID: 1, Custom: map[string]interface{}{"refresh": json.Number("30")}}))
+
+		_, err := prefService.GetWithDefaults(ctx, query)
+		require.NoError(t, err)
+		custom, err := prefService.GetCustom(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"refresh": json.Number("30")}, custom)
+	})
+}
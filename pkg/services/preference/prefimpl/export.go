@@ -0,0 +1,171 @@
+package prefimpl
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+func (s *Service) Export(ctx context.Context, orgID int64) (*pref.PreferencesExport, error) {
+	prefs, err := s.store.ListOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.store.References(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &pref.PreferencesExport{
+		Teams: []pref.ExportedTeamPreferences{},
+		Users: []pref.ExportedUserPreferences{},
+	}
+	for _, p := range prefs {
+		exported := pref.ExportedPreferences{
+			HomeDashboardUID: refs.dashboards[p.HomeDashboardID],
+			Timezone:         p.Timezone,
+			WeekStart:        p.WeekStart,
+			Theme:            p.Theme,
+			JSONData:         p.JSONData,
+		}
+		switch p.Source() {
+		case pref.PreferenceSourceOrg:
+			export.Org = &exported
+		case pref.PreferenceSourceTeam:
+			// the preferences of deleted teams and of users who left the
+			// organization can't be imported
+			if name, ok := refs.teams[p.TeamID]; ok {
+				export.Teams = append(export.Teams, pref.ExportedTeamPreferences{Team: name, ExportedPreferences: exported})
+			}
+		case pref.PreferenceSourceUser:
+			if login, ok := refs.users[p.UserID]; ok {
+				export.Users = append(export.Users, pref.ExportedUserPreferences{Login: login, ExportedPreferences: exported})
+			}
+		}
+	}
+	sort.Slice(export.Teams, func(i, j int) bool { return export.Teams[i].Team < export.Teams[j].Team })
+	sort.Slice(export.Users, func(i, j int) bool { return export.Users[i].Login < export.Users[j].Login })
+	return export, nil
+}
+
+func (s *Service) Import(ctx context.Context, cmd *pref.ImportPreferencesCommand) (*pref.ImportPreferencesResult, error) {
+	if cmd.Export.Org != nil {
+		if err := validateExported(cmd.Export.Org); err != nil {
+			return nil, err
+		}
+	}
+	for i := range cmd.Export.Teams {
+		if err := validateExported(&cmd.Export.Teams[i].ExportedPreferences); err != nil {
+			return nil, err
+		}
+	}
+	for i := range cmd.Export.Users {
+		if err := validateExported(&cmd.Export.Users[i].ExportedPreferences); err != nil {
+			return nil, err
+		}
+	}
+
+	refs, err := s.store.References(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	teamIDs, userIDs, dashboardIDs := invert(refs.teams), invert(refs.users), invert(refs.dashboards)
+	missingTeams, missingUsers, missingDashboards := map[string]bool{}, map[string]bool{}, map[string]bool{}
+
+	now := time.Now()
+	prefs := make([]*pref.Preference, 0, len(cmd.Export.Teams)+len(cmd.Export.Users)+1)
+	add := func(teamID, userID int64, exported *pref.ExportedPreferences) {
+		p := &pref.Preference{
+			OrgID:     cmd.OrgID,
+			TeamID:    teamID,
+			UserID:    userID,
+			Timezone:  exported.Timezone,
+			WeekStart: exported.WeekStart,
+			Theme:     exported.Theme,
+			Created:   now,
+			Updated:   now,
+			JSONData:  exported.JSONData,
+		}
+		if p.JSONData == nil {
+			p.JSONData = &pref.PreferenceJSONData{}
+		}
+		if exported.HomeDashboardUID != "" {
+			id, ok := dashboardIDs[exported.HomeDashboardUID]
+			if !ok {
+				missingDashboards[exported.HomeDashboardUID] = true
+			}
+			p.HomeDashboardID = id
+		}
+		prefs = append(prefs, p)
+	}
+	if cmd.Export.Org != nil {
+		add(0, 0, cmd.Export.Org)
+	}
+	for i, team := range cmd.Export.Teams {
+		teamID, ok := teamIDs[team.Team]
+		if !ok {
+			missingTeams[team.Team] = true
+			continue
+		}
+		add(teamID, 0, &cmd.Export.Teams[i].ExportedPreferences)
+	}
+	for i, user := range cmd.Export.Users {
+		userID, ok := userIDs[user.Login]
+		if !ok {
+			missingUsers[user.Login] = true
+			continue
+		}
+		add(0, userID, &cmd.Export.Users[i].ExportedPreferences)
+	}
+
+	replaced, err := s.store.Import(ctx, prefs)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range prefs {
+		var old *events.PreferencesValues
+		if replaced[i] != nil {
+			old = preferencesValues(replaced[i])
+		}
+		s.publishUpdated(ctx, old, p)
+	}
+	s.log.Info("Imported preferences", "orgId", cmd.OrgID, "imported", len(prefs),
+		"missingTeams", len(missingTeams), "missingUsers", len(missingUsers), "missingDashboards", len(missingDashboards))
+
+	return &pref.ImportPreferencesResult{
+		Imported:          len(prefs),
+		MissingTeams:      sortedKeys(missingTeams),
+		MissingUsers:      sortedKeys(missingUsers),
+		MissingDashboards: sortedKeys(missingDashboards),
+	}, nil
+}
+
+func validateExported(p *pref.ExportedPreferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.JSONData == nil || p.JSONData.Custom == nil {
+		return nil
+	}
+	return validateCustom(p.JSONData.Custom)
+}
+
+func invert(m map[int64]string) map[string]int64 {
+	inverted := make(map[string]int64, len(m))
+	for id, name := range m {
+		inverted[name] = id
+	}
+	return inverted
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package prefimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	source := newFake().(*inmemStore)
+	source.refs = references{
+		teams:      map[int64]string{1: "SRE"},
+		users:      map[int64]string{2: "alice", 3: "bob"},
+		dashboards: map[int64]string{10: "home"},
+	}
+	insertPrefs(t, source,
+		pref.Preference{OrgID: 1, Theme: "dark", HomeDashboardID: 10, JSONData: &pref.PreferenceJSONData{Locale: "fr-FR"}},
+		pref.Preference{OrgID: 1, TeamID: 1, Timezone: "utc"},
+		pref.Preference{OrgID: 1, TeamID: 9, Timezone: "browser"},
+		pref.Preference{OrgID: 1, UserID: 3, WeekStart: "monday"},
+		pref.Preference{OrgID: 1, UserID: 2, Theme: "light", HomeDashboardID: 10},
+		pref.Preference{OrgID: 2, UserID: 2, Theme: "dark"},
+	)
+	sourceService := &Service{store: source, cfg: setting.NewCfg(), log: log.New("test")}
+
+	export, err := sourceService.Export(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, export.Org)
+	assert.Equal(t, "home", export.Org.HomeDashboardUID)
+	assert.Equal(t, "fr-FR", export.Org.JSONData.Locale)
+	require.Len(t, export.Teams, 1, "the preferences of deleted teams aren't exported")
+	assert.Equal(t, "SRE", export.Teams[0].Team)
+	require.Len(t, export.Users, 2)
+	assert.Equal(t, "alice", export.Users[0].Login)
+	assert.Equal(t, "home", export.Users[0].HomeDashboardUID)
+	assert.Equal(t, "bob", export.Users[1].Login)
+
+	target := newFake().(*inmemStore)
+	target.refs = references{
+		teams:      map[int64]string{5: "SRE"},
+		users:      map[int64]string{7: "alice"},
+		dashboards: map[int64]string{20: "home"},
+	}
+	insertPrefs(t, target, pref.Preference{OrgID: 3, UserID: 7, Theme: "dark", Version: 2})
+	tracer, err := tracing.InitializeTracerForTest()
+	require.NoError(t, err)
+	bus := bus.ProvideBus(tracer)
+	var published []*events.PreferencesUpdated
+	bus.AddEventListener(func(ctx context.Context, evt *events.PreferencesUpdated) error {
+		published = append(published, evt)
+		return nil
+	})
+	targetService := &Service{store: target, cfg: setting.NewCfg(), bus: bus, log: log.New("test")}
+
+	result, err := targetService.Import(ctx, &pref.ImportPreferencesCommand{OrgID: 3, Export: *export})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	assert.Equal(t, []string{"bob"}, result.MissingUsers)
+	assert.Empty(t, result.MissingTeams)
+	assert.Empty(t, result.MissingDashboards)
+
+	org := target.preference[preferenceKey{OrgID: 3}]
+	assert.Equal(t, "dark", org.Theme)
+	assert.EqualValues(t, 20, org.HomeDashboardID)
+	assert.Equal(t, "utc", target.preference[preferenceKey{OrgID: 3, TeamID: 5}].Timezone)
+	alice := target.preference[preferenceKey{OrgID: 3, UserID: 7}]
+	assert.Equal(t, "light", alice.Theme)
+	assert.EqualValues(t, 20, alice.HomeDashboardID)
+	assert.Equal(t, 3, alice.Version, "replacing preferences increases their version")
+
+	require.Len(t, published, 3, "every imported preference is published")
+	assert.Equal(t, "org", published[0].Scope)
+	assert.Nil(t, published[0].Old)
+	assert.Equal(t, &events.PreferencesValues{Theme: "dark", HomeDashboardID: 20, Locale: "fr-FR"}, published[0].New)
+	assert.Equal(t, "team", published[1].Scope)
+	assert.EqualValues(t, 5, published[1].TeamID)
+	assert.Equal(t, "user", published[2].Scope)
+	assert.EqualValues(t, 7, published[2].UserID)
+	assert.Equal(t, &events.PreferencesValues{Theme: "dark"}, published[2].Old)
+	assert.Equal(t, &events.PreferencesValues{Theme: "light", HomeDashboardID: 20}, published[2].New)
+
+	t.Run("Home dashboards that don't exist are reset", func(t *testing.T) {
+		result, err := targetService.Import(ctx, &pref.ImportPreferencesCommand{OrgID: 3, Export: pref.PreferencesExport{
+			Org: &pref.ExportedPreferences{HomeDashboardUID: "gone"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gone"}, result.MissingDashboards)
+		assert.Zero(t, target.preference[preferenceKey{OrgID: 3}].HomeDashboardID)
+	})
+
+	t.Run("Invalid preferences are rejected before importing anything", func(t *testing.T) {
+		_, err := targetService.Import(ctx, &pref.ImportPreferencesCommand{OrgID: 3, Export: pref.PreferencesExport{
+			Org:   &pref.ExportedPreferences{Theme: "light"},
+			Users: []pref.ExportedUserPreferences{{Login: "alice", ExportedPreferences: pref.ExportedPreferences{WeekStart: "friday"}}},
+		}})
+		require.ErrorIs(t, err, pref.ErrInvalidWeekStart)
+		assert.Empty(t, target.preference[preferenceKey{OrgID: 3}].Theme)
+	})
+}
@@ -27,6 +27,8 @@ type inmemStore struct {
 	preference map[preferenceKey]pref.Preference
 	idMap      map[int64]preferenceKey
 	nextID     int64
+	priorities map[int64]int64
+	refs       references
 }
 
 func (s *inmemStore) Get(ctx context.Context, preference *pref.Preference) (*pref.Preference, error) {
@@ -116,7 +118,87 @@ func (s *inmemStore) Update(ctx context.Context, preference *pref.Preference) er
 	if !ok {
 		return pref.ErrPrefNotFound
 	}
+	if s.preference[key].Version != preference.Version-1 {
+		return pref.ErrPreferencesVersionMismatch
+	}
 
 	s.preference[key] = *preference
 	return nil
 }
+
+func (s *inmemStore) TeamPriorities(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]int64, error) {
+	priorities := make(map[int64]int64, len(teamIDs))
+	for _, teamID := range teamIDs {
+		priorities[teamID] = s.priorities[teamID]
+	}
+	return priorities, nil
+}
+
+// PatchOrgUsers updates the preferences the users of the organization have,
+// the fake does not know the users without preferences.
+func (s *inmemStore) PatchOrgUsers(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) ([]patchedPreference, error) {
+	patched := []patchedPreference{}
+	for key, p := range s.preference {
+		if key.OrgID != cmd.OrgID || key.TeamID != 0 || key.UserID == 0 {
+			continue
+		}
+		before := p
+		patchOrgUser(&p, cmd)
+		p.Version++
+		s.preference[key] = p
+		after := p
+		patched = append(patched, patchedPreference{before: &before, after: &after})
+	}
+	return patched, nil
+}
+
+func (s *inmemStore) ListOrg(ctx context.Context, orgID int64) ([]*pref.Preference, error) {
+	res := []*pref.Preference{}
+	for key, p := range s.preference {
+		if key.OrgID == orgID {
+			p := p
+			res = append(res, &p)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].TeamID != res[j].TeamID {
+			return res[i].TeamID < res[j].TeamID
+		}
+		return res[i].UserID < res[j].UserID
+	})
+	return res, nil
+}
+
+func (s *inmemStore) References(ctx context.Context, orgID int64) (*references, error) {
+	refs := &references{teams: map[int64]string{}, users: map[int64]string{}, dashboards: map[int64]string{}}
+	for id, name := range s.refs.teams {
+		refs.teams[id] = name
+	}
+	for id, login := range s.refs.users {
+		refs.users[id] = login
+	}
+	for id, uid := range s.refs.dashboards {
+		refs.dashboards[id] = uid
+	}
+	return refs, nil
+}
+
+func (s *inmemStore) Import(ctx context.Context, prefs []*pref.Preference) ([]*pref.Preference, error) {
+	replaced := make([]*pref.Preference, len(prefs))
+	for i, p := range prefs {
+		key := preferenceKey{OrgID: p.OrgID, TeamID: p.TeamID, UserID: p.UserID}
+		existing, ok := s.preference[key]
+		if !ok {
+			if _, err := s.Insert(ctx, p); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.ID = existing.ID
+		p.Created = existing.Created
+		p.Version = existing.Version + 1
+		s.preference[key] = *p
+		replaced[i] = &existing
+	}
+	return replaced, nil
+}
@@ -0,0 +1,114 @@
+package prefimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	pref "github.com/grafana/grafana/pkg/services/preference"
+)
+
+const (
+	kvNamespace          = "preferences"
+	kvInstanceDefaultKey = "instance-defaults"
+
+	// refreshInterval is how often the instance defaults are reloaded, so that all
+	// instances of a highly available setup follow changes made through one of them.
+	refreshInterval = 30 * time.Second
+)
+
+// Run reloads the instance defaults periodically.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.refreshInstanceDefaults(ctx); err != nil {
+				s.log.Warn("Failed to reload instance default preferences", "error", err)
+			}
+		}
+	}
+}
+
+// GetInstanceDefaults returns the instance defaults from the configuration
+// file with the values changed at runtime applied, it does not access the database.
+func (s *Service) GetInstanceDefaults() pref.InstanceDefaults {
+	s.mu.RLock()
+	overrides := s.overrides
+	s.mu.RUnlock()
+
+	defaults := pref.InstanceDefaults{
+		Theme:             s.cfg.DefaultTheme,
+		Timezone:          s.cfg.DateFormats.DefaultTimezone,
+		WeekStart:         s.cfg.DateFormats.DefaultWeekStart,
+		HomeDashboardPath: s.cfg.DefaultHomeDashboardPath,
+	}
+	if overrides.Theme != "" {
+		defaults.Theme = overrides.Theme
+	}
+	if overrides.Timezone != "" {
+		defaults.Timezone = overrides.Timezone
+	}
+	if overrides.WeekStart != "" {
+		defaults.WeekStart = overrides.WeekStart
+	}
+	if overrides.HomeDashboardPath != "" {
+		defaults.HomeDashboardPath = overrides.HomeDashboardPath
+	}
+	return defaults
+}
+
+// UpdateInstanceDefaults stores the instance defaults changed at runtime and
+// returns the resulting defaults.
+func (s *Service) UpdateInstanceDefaults(ctx context.Context, cmd *pref.UpdateInstanceDefaultsCommand) (pref.InstanceDefaults, error) {
+	if err := cmd.Validate(); err != nil {
+		return pref.InstanceDefaults{}, err
+	}
+
+	overrides := pref.InstanceDefaults{
+		Theme:             cmd.Theme,
+		Timezone:          cmd.Timezone,
+		WeekStart:         cmd.WeekStart,
+		HomeDashboardPath: cmd.HomeDashboardPath,
+	}
+	if overrides == (pref.InstanceDefaults{}) {
+		if err := s.kv.Del(ctx, kvInstanceDefaultKey); err != nil {
+			return pref.InstanceDefaults{}, err
+		}
+	} else {
+		encoded, err := json.Marshal(overrides)
+		if err != nil {
+			return pref.InstanceDefaults{}, err
+		}
+		if err := s.kv.Set(ctx, kvInstanceDefaultKey, string(encoded)); err != nil {
+			return pref.InstanceDefaults{}, err
+		}
+	}
+
+	s.setOverrides(overrides)
+	return s.GetInstanceDefaults(), nil
+}
+
+func (s *Service) refreshInstanceDefaults(ctx context.Context) error {
+	value, ok, err := s.kv.Get(ctx, kvInstanceDefaultKey)
+	if err != nil {
+		return err
+	}
+	overrides := pref.InstanceDefaults{}
+	if ok {
+		if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+			return err
+		}
+	}
+	s.setOverrides(overrides)
+	return nil
+}
+
+func (s *Service) setOverrides(overrides pref.InstanceDefaults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = overrides
+}
@@ -0,0 +1,69 @@
+package prefimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationInstanceDefaults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	kv := kvstore.ProvideService(ss)
+	ss.Cfg.DefaultTheme = "dark"
+	ss.Cfg.DateFormats.DefaultTimezone = "browser"
+	ss.Cfg.DateFormats.DefaultWeekStart = "browser"
+	ctx := context.Background()
+
+	svc := ProvideService(ss, ss.Cfg, kv, nil, nil)
+	require.Equal(t, pref.InstanceDefaults{Theme: "dark", Timezone: "browser", WeekStart: "browser"}, svc.GetInstanceDefaults())
+
+	t.Run("overrides the configuration", func(t *testing.T) {
+		defaults, err := svc.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{Theme: "light", WeekStart: "monday"})
+		require.NoError(t, err)
+		expected := pref.InstanceDefaults{Theme: "light", Timezone: "browser", WeekStart: "monday"}
+		require.Equal(t, expected, defaults)
+
+		preference := svc.GetDefaults()
+		require.Equal(t, "light", preference.Theme)
+		require.Equal(t, "monday", preference.WeekStart)
+
+		restarted := ProvideService(ss, ss.Cfg, kv, nil, nil)
+		require.Equal(t, expected, restarted.GetInstanceDefaults())
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		_, err := svc.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{Theme: "pink"})
+		require.ErrorIs(t, err, pref.ErrInvalidTheme)
+		_, err = svc.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{WeekStart: "friday"})
+		require.ErrorIs(t, err, pref.ErrInvalidWeekStart)
+		_, err = svc.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{HomeDashboardPath: "../home.json"})
+		require.ErrorIs(t, err, pref.ErrInvalidHomeDashboardPath)
+	})
+
+	t.Run("refresh picks up changes made by other instances", func(t *testing.T) {
+		other := ProvideService(ss, ss.Cfg, kv, nil, nil)
+		_, err := other.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{Timezone: "utc"})
+		require.NoError(t, err)
+
+		require.Equal(t, "light", svc.GetInstanceDefaults().Theme)
+		require.NoError(t, svc.refreshInstanceDefaults(ctx))
+		require.Equal(t, pref.InstanceDefaults{Theme: "dark", Timezone: "utc", WeekStart: "browser"}, svc.GetInstanceDefaults())
+	})
+
+	t.Run("reset falls back to the configuration", func(t *testing.T) {
+		defaults, err := svc.UpdateInstanceDefaults(ctx, &pref.UpdateInstanceDefaultsCommand{})
+		require.NoError(t, err)
+		require.Equal(t, pref.InstanceDefaults{Theme: "dark", Timezone: "browser", WeekStart: "browser"}, defaults)
+
+		restarted := ProvideService(ss, ss.Cfg, kv, nil, nil)
+		require.Equal(t, defaults, restarted.GetInstanceDefaults())
+	})
+}
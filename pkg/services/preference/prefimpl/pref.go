@@ -2,9 +2,18 @@ package prefimpl
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
 	"github.com/grafana/grafana/pkg/setting"
@@ -13,15 +22,43 @@ import (
 type Service struct {
 	store store
 	cfg   *setting.Cfg
+	kv    *kvstore.NamespacedKVStore
+	bus   bus.Bus
+	log   log.Logger
+
+	mu        sync.RWMutex
+	overrides pref.InstanceDefaults
 }
 
-func ProvideService(db db.DB, cfg *setting.Cfg) pref.Service {
-	return &Service{
-		store: &sqlStore{
-			db: db,
-		},
-		cfg: cfg,
+func ProvideService(db db.DB, cfg *setting.Cfg, kv kvstore.KVStore, remoteCache *remotecache.RemoteCache, bus bus.Bus) *Service {
+	var store store = &sqlStore{
+		db: db,
+	}
+	if cfg.PreferencesCacheTTL > 0 {
+		var cache cacheStorage = &localCacheStorage{cache: localcache.New(cfg.PreferencesCacheTTL, 2*cfg.PreferencesCacheTTL)}
+		if cfg.PreferencesRemoteCache && remoteCache != nil {
+			cache = &remoteCacheStorage{cache: remoteCache}
+		}
+		cached := newCachedStore(store, cache, cfg.PreferencesCacheTTL)
+		if bus != nil {
+			bus.AddEventListener(cached.handleOrgDeleted)
+			bus.AddEventListener(cached.handleTeamDeleted)
+			bus.AddEventListener(cached.handleUserDeleted)
+		}
+		store = cached
+	}
+
+	s := &Service{
+		store: store,
+		cfg:   cfg,
+		kv:    kvstore.WithNamespace(kv, 0, kvNamespace),
+		bus:   bus,
+		log:   log.New("preferences"),
 	}
+	if err := s.refreshInstanceDefaults(context.Background()); err != nil {
+		s.log.Error("Failed to load instance default preferences", "error", err)
+	}
+	return s
 }
 
 func (s *Service) GetWithDefaults(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (*pref.Preference, error) {
@@ -35,29 +72,103 @@ func (s *Service) GetWithDefaults(ctx context.Context, query *pref.GetPreference
 	if err != nil {
 		return nil, err
 	}
+	if err := s.orderTeams(ctx, query.OrgID, prefs); err != nil {
+		return nil, err
+	}
 
 	res := s.GetDefaults()
+	res.Sources = map[string]pref.PreferenceSource{
+		"theme":                       pref.PreferenceSourceDefault,
+		"timezone":                    pref.PreferenceSourceDefault,
+		"weekStart":                   pref.PreferenceSourceDefault,
+		"homeDashboardId":             pref.PreferenceSourceDefault,
+		"locale":                      pref.PreferenceSourceDefault,
+		"defaultDatasourceUid":        pref.PreferenceSourceDefault,
+		"navbar":                      pref.PreferenceSourceDefault,
+		"queryHistory.homeTab":        pref.PreferenceSourceDefault,
+		"queryHistory.retentionOptIn": pref.PreferenceSourceDefault,
+	}
+	var custom map[string]interface{}
+	var locale string
+	var defaultDatasourceUID string
+	var navbar pref.NavbarPreference
+	var queryHistory pref.QueryHistoryPreference
 	for _, p := range prefs {
+		source := p.Source()
 		if p.Theme != "" {
 			res.Theme = p.Theme
+			res.Sources["theme"] = source
 		}
 		if p.Timezone != "" {
 			res.Timezone = p.Timezone
+			res.Sources["timezone"] = source
 		}
 		if p.WeekStart != "" {
 			res.WeekStart = p.WeekStart
+			res.Sources["weekStart"] = source
 		}
 		if p.HomeDashboardID != 0 {
 			res.HomeDashboardID = p.HomeDashboardID
+			res.Sources["homeDashboardId"] = source
 		}
 		if p.JSONData != nil {
+			// the other fields of the JSON data, e.g. the dismissed announcements,
+			// are the ones of the most specific preferences
 			res.JSONData = p.JSONData
+			if p.JSONData.Locale != "" {
+				locale = p.JSONData.Locale
+				res.Sources["locale"] = source
+			}
+			custom = mergeCustom(custom, p.JSONData.Custom)
+			if p.JSONData.DefaultDatasourceUID != "" {
+				defaultDatasourceUID = p.JSONData.DefaultDatasourceUID
+				res.Sources["defaultDatasourceUid"] = source
+			}
+			// the navbar of users who haven't customized it is the one of their
+			// teams or organization
+			if !p.JSONData.Navbar.IsEmpty() {
+				navbar = p.JSONData.Navbar
+				res.Sources["navbar"] = source
+			}
+			// the query history preferences are overridden one by one, so that
+			// the organization can set the defaults of its users
+			if p.JSONData.QueryHistory.HomeTab != "" {
+				queryHistory.HomeTab = p.JSONData.QueryHistory.HomeTab
+				res.Sources["queryHistory.homeTab"] = source
+			}
+			if p.JSONData.QueryHistory.RetentionOptIn != nil {
+				queryHistory.RetentionOptIn = p.JSONData.QueryHistory.RetentionOptIn
+				res.Sources["queryHistory.retentionOptIn"] = source
+			}
 		}
 	}
+	if len(custom) > 0 || locale != res.JSONData.Locale ||
+		defaultDatasourceUID != res.JSONData.DefaultDatasourceUID ||
+		!navbar.IsEmpty() && res.JSONData.Navbar.IsEmpty() ||
+		!queryHistory.IsEmpty() {
+		jsonData := *res.JSONData
+		jsonData.Locale = locale
+		jsonData.Custom = custom
+		jsonData.DefaultDatasourceUID = defaultDatasourceUID
+		jsonData.Navbar = navbar
+		jsonData.QueryHistory = queryHistory
+		res.JSONData = &jsonData
+	}
 
 	return res, err
 }
 
+func (s *Service) GetCustom(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (map[string]interface{}, error) {
+	preference, err := s.GetWithDefaults(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if preference.JSONData == nil || preference.JSONData.Custom == nil {
+		return map[string]interface{}{}, nil
+	}
+	return preference.JSONData.Custom, nil
+}
+
 func (s *Service) Get(ctx context.Context, query *pref.GetPreferenceQuery) (*pref.Preference, error) {
 	getPref := &pref.Preference{
 		OrgID:  query.OrgID,
@@ -75,6 +186,22 @@ func (s *Service) Get(ctx context.Context, query *pref.GetPreferenceQuery) (*pre
 }
 
 func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) error {
+	if cmd.Navbar != nil {
+		if err := cmd.Navbar.Validate(); err != nil {
+			return err
+		}
+	}
+	if cmd.QueryHistory != nil {
+		if err := cmd.QueryHistory.Validate(); err != nil {
+			return err
+		}
+	}
+	if cmd.Custom != nil {
+		cmd.Custom = mergeCustom(map[string]interface{}{}, cmd.Custom)
+		if err := validateCustom(cmd.Custom); err != nil {
+			return err
+		}
+	}
 	preference, err := s.store.Get(ctx, &pref.Preference{
 		OrgID:  cmd.OrgID,
 		UserID: cmd.UserID,
@@ -82,6 +209,9 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 	})
 	if err != nil {
 		if errors.Is(err, pref.ErrPrefNotFound) {
+			if cmd.Version != nil && *cmd.Version != 0 {
+				return pref.ErrPreferencesVersionMismatch
+			}
 			preference := &pref.Preference{
 				UserID:          cmd.UserID,
 				OrgID:           cmd.OrgID,
@@ -93,16 +223,29 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 				Created:         time.Now(),
 				Updated:         time.Now(),
 				JSONData: &pref.PreferenceJSONData{
-					Locale: cmd.Locale,
+					Locale:               cmd.Locale,
+					DefaultDatasourceUID: cmd.DefaultDatasourceUID,
+					Custom:               cmd.Custom,
 				},
 			}
+			if cmd.Navbar != nil {
+				preference.JSONData.Navbar = *cmd.Navbar
+			}
+			if cmd.QueryHistory != nil {
+				preference.JSONData.QueryHistory = *cmd.QueryHistory
+			}
 			_, err = s.store.Insert(ctx, preference)
 			if err != nil {
 				return err
 			}
+			s.publishUpdated(ctx, nil, preference)
 		}
 		return err
 	}
+	if cmd.Version != nil && *cmd.Version != preference.Version {
+		return pref.ErrPreferencesVersionMismatch
+	}
+	old := preferencesValues(preference)
 
 	preference.Timezone = cmd.Timezone
 	preference.WeekStart = cmd.WeekStart
@@ -110,8 +253,20 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 	preference.Updated = time.Now()
 	preference.Version += 1
 	preference.HomeDashboardID = cmd.HomeDashboardID
+	// Announcement dismissals, the onboarding progress and the custom preferences
+	// are kept unless the command sets them
+	previous := preference.JSONData
 	preference.JSONData = &pref.PreferenceJSONData{
-		Locale: cmd.Locale,
+		Locale:               cmd.Locale,
+		DefaultDatasourceUID: cmd.DefaultDatasourceUID,
+		Custom:               cmd.Custom,
+	}
+	if previous != nil {
+		preference.JSONData.DismissedAnnouncements = previous.DismissedAnnouncements
+		preference.JSONData.Onboarding = previous.Onboarding
+		if cmd.Custom == nil {
+			preference.JSONData.Custom = previous.Custom
+		}
 	}
 
 	if cmd.Navbar != nil {
@@ -120,11 +275,16 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 	if cmd.QueryHistory != nil {
 		preference.JSONData.QueryHistory = *cmd.QueryHistory
 	}
-	return s.store.Update(ctx, preference)
+	if err := s.store.Update(ctx, preference); err != nil {
+		return err
+	}
+	s.publishUpdated(ctx, old, preference)
+	return nil
 }
 
 func (s *Service) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) error {
 	var exists bool
+	var old *events.PreferencesValues
 	preference, err := s.store.Get(ctx, &pref.Preference{
 		OrgID:  cmd.OrgID,
 		UserID: cmd.UserID,
@@ -144,6 +304,7 @@ func (s *Service) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) e
 		}
 	} else {
 		exists = true
+		old = preferencesValues(preference)
 	}
 
 	if cmd.Locale != nil {
@@ -160,15 +321,63 @@ func (s *Service) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) e
 		if cmd.Navbar.SavedItems != nil {
 			preference.JSONData.Navbar.SavedItems = cmd.Navbar.SavedItems
 		}
+		if cmd.Navbar.PinnedItems != nil {
+			preference.JSONData.Navbar.PinnedItems = cmd.Navbar.PinnedItems
+		}
+		if cmd.Navbar.HiddenItems != nil {
+			preference.JSONData.Navbar.HiddenItems = cmd.Navbar.HiddenItems
+		}
+		// an item pinned by the command can be hidden by the stored preferences
+		if err := preference.JSONData.Navbar.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if cmd.QueryHistory != nil {
 		if preference.JSONData == nil {
 			preference.JSONData = &pref.PreferenceJSONData{}
 		}
+		if err := cmd.QueryHistory.Validate(); err != nil {
+			return err
+		}
 		if cmd.QueryHistory.HomeTab != "" {
 			preference.JSONData.QueryHistory.HomeTab = cmd.QueryHistory.HomeTab
 		}
+		if cmd.QueryHistory.RetentionOptIn != nil {
+			preference.JSONData.QueryHistory.RetentionOptIn = cmd.QueryHistory.RetentionOptIn
+		}
+	}
+
+	if cmd.DefaultDatasourceUID != nil {
+		if preference.JSONData == nil {
+			preference.JSONData = &pref.PreferenceJSONData{}
+		}
+		preference.JSONData.DefaultDatasourceUID = *cmd.DefaultDatasourceUID
+	}
+
+	if cmd.DismissedAnnouncements != nil {
+		if preference.JSONData == nil {
+			preference.JSONData = &pref.PreferenceJSONData{}
+		}
+		preference.JSONData.DismissedAnnouncements = *cmd.DismissedAnnouncements
+	}
+
+	if cmd.Onboarding != nil {
+		if preference.JSONData == nil {
+			preference.JSONData = &pref.PreferenceJSONData{}
+		}
+		preference.JSONData.Onboarding = cmd.Onboarding
+	}
+
+	if cmd.Custom != nil {
+		if preference.JSONData == nil {
+			preference.JSONData = &pref.PreferenceJSONData{}
+		}
+		custom := mergeCustom(preference.JSONData.Custom, cmd.Custom)
+		if err := validateCustom(custom); err != nil {
+			return err
+		}
+		preference.JSONData.Custom = custom
 	}
 
 	if cmd.HomeDashboardID != nil {
@@ -190,32 +399,154 @@ func (s *Service) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) e
 	preference.Updated = time.Now()
 	preference.Version += 1
 
-	// Wrap this in an if statement to maintain backwards compatibility
-	if cmd.Navbar != nil {
-		if preference.JSONData == nil {
-			preference.JSONData = &pref.PreferenceJSONData{}
-		}
-		if cmd.Navbar.SavedItems != nil {
-			preference.JSONData.Navbar.SavedItems = cmd.Navbar.SavedItems
-		}
-	}
-
 	if exists {
 		err = s.store.Update(ctx, preference)
 	} else {
 		_, err = s.store.Insert(ctx, preference)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	s.publishUpdated(ctx, old, preference)
+	return nil
+}
+
+// orderTeams orders the preferences of the teams, which List returns ordered by
+// team id between the ones of the organization and of the user, so that the
+// preferences of the team that takes precedence come last.
+func (s *Service) orderTeams(ctx context.Context, orgID int64, prefs []*pref.Preference) error {
+	start, end := -1, 0
+	for i, p := range prefs {
+		if p.TeamID != 0 {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		}
+	}
+	if start < 0 || end-start < 2 {
+		return nil
+	}
+	teams := prefs[start:end]
+
+	if pref.TeamOrder(s.cfg.PreferencesTeamOrder) == pref.TeamOrderUpdated {
+		sort.SliceStable(teams, func(i, j int) bool { return teams[i].Updated.Before(teams[j].Updated) })
+		return nil
+	}
+
+	teamIDs := make([]int64, 0, len(teams))
+	for _, p := range teams {
+		teamIDs = append(teamIDs, p.TeamID)
+	}
+	priorities, err := s.store.TeamPriorities(ctx, orgID, teamIDs)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return priorities[teams[i].TeamID] < priorities[teams[j].TeamID] })
+	return nil
+}
+
+// publishUpdated lets the other services know that the preferences have been
+// saved. The preferences are saved already, so failing listeners are only logged.
+func (s *Service) publishUpdated(ctx context.Context, old *events.PreferencesValues, preference *pref.Preference) {
+	if s.bus == nil {
+		return
+	}
+	evt := &events.PreferencesUpdated{
+		Timestamp: preference.Updated,
+		Scope:     string(preference.Source()),
+		OrgID:     preference.OrgID,
+		TeamID:    preference.TeamID,
+		UserID:    preference.UserID,
+		Old:       old,
+		New:       preferencesValues(preference),
+	}
+	if err := s.bus.Publish(ctx, evt); err != nil {
+		s.log.Warn("Failed to publish the update of the preferences", "orgId", preference.OrgID, "teamId", preference.TeamID, "userId", preference.UserID, "error", err)
+	}
+}
+
+func preferencesValues(preference *pref.Preference) *events.PreferencesValues {
+	values := &events.PreferencesValues{
+		HomeDashboardID: preference.HomeDashboardID,
+		Timezone:        preference.Timezone,
+		WeekStart:       preference.WeekStart,
+		Theme:           preference.Theme,
+	}
+	if preference.JSONData != nil {
+		values.Locale = preference.JSONData.Locale
+		values.DefaultDatasourceUID = preference.JSONData.DefaultDatasourceUID
+	}
+	return values
+}
+
+func (s *Service) PatchOrgUsers(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) (int64, error) {
+	if err := cmd.Validate(); err != nil {
+		return 0, err
+	}
+
+	patched, err := s.store.PatchOrgUsers(ctx, cmd)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range patched {
+		var old *events.PreferencesValues
+		if p.before != nil {
+			old = preferencesValues(p.before)
+		}
+		s.publishUpdated(ctx, old, p.after)
+	}
+	return int64(len(patched)), nil
 }
 
 func (s *Service) GetDefaults() *pref.Preference {
+	instanceDefaults := s.GetInstanceDefaults()
 	defaults := &pref.Preference{
-		Theme:           s.cfg.DefaultTheme,
-		Timezone:        s.cfg.DateFormats.DefaultTimezone,
-		WeekStart:       s.cfg.DateFormats.DefaultWeekStart,
+		Theme:           instanceDefaults.Theme,
+		Timezone:        instanceDefaults.Timezone,
+		WeekStart:       instanceDefaults.WeekStart,
 		HomeDashboardID: 0,
 		JSONData:        &pref.PreferenceJSONData{},
 	}
 
 	return defaults
 }
+
+// mergeCustom returns the custom preferences overridden key by key, nested
+// objects are merged the same way. Keys overridden with null are removed.
+func mergeCustom(custom, override map[string]interface{}) map[string]interface{} {
+	if len(override) == 0 {
+		return custom
+	}
+	merged := make(map[string]interface{}, len(custom)+len(override))
+	for k, v := range custom {
+		merged[k] = v
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		if overrideObject, ok := v.(map[string]interface{}); ok {
+			existing, _ := merged[k].(map[string]interface{})
+			if existing == nil {
+				existing = map[string]interface{}{}
+			}
+			merged[k] = mergeCustom(existing, overrideObject)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+func validateCustom(custom map[string]interface{}) error {
+	encoded, err := json.Marshal(custom)
+	if err != nil {
+		return err
+	}
+	if len(encoded) > pref.MaxCustomSize {
+		return pref.ErrCustomTooLarge
+	}
+	return nil
+}
@@ -2,12 +2,18 @@ package prefimpl
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -55,6 +61,7 @@ func TestGetDefaults(t *testing.T) {
 			Timezone:        "UTC",
 			HomeDashboardID: 0,
 			JSONData:        &pref.PreferenceJSONData{},
+			Sources:         sources(nil),
 		}
 		if diff := cmp.Diff(expected, preference); diff != "" {
 			t.Fatalf("Result mismatch (-want +got):\n%s", diff)
@@ -95,6 +102,10 @@ func TestGetWithDefaults_withUserAndOrgPrefs(t *testing.T) {
 			WeekStart:       "2",
 			HomeDashboardID: 4,
 			JSONData:        &pref.PreferenceJSONData{},
+			Sources: sources(map[string]pref.PreferenceSource{
+				"theme": pref.PreferenceSourceUser, "timezone": pref.PreferenceSourceUser,
+				"weekStart": pref.PreferenceSourceUser, "homeDashboardId": pref.PreferenceSourceUser,
+			}),
 		}
 		if diff := cmp.Diff(expected, preference); diff != "" {
 			t.Fatalf("Result mismatch (-want +got):\n%s", diff)
@@ -112,6 +123,10 @@ func TestGetWithDefaults_withUserAndOrgPrefs(t *testing.T) {
 			WeekStart:       "1",
 			HomeDashboardID: 1,
 			JSONData:        &pref.PreferenceJSONData{},
+			Sources: sources(map[string]pref.PreferenceSource{
+				"theme": pref.PreferenceSourceOrg, "timezone": pref.PreferenceSourceOrg,
+				"weekStart": pref.PreferenceSourceOrg, "homeDashboardId": pref.PreferenceSourceOrg,
+			}),
 		}
 		if diff := cmp.Diff(expected, preference); diff != "" {
 			t.Fatalf("Result mismatch (-want +got):\n%s", diff)
@@ -188,6 +203,9 @@ func TestGetDefaults_JSONData(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, &pref.Preference{
 			JSONData: &userPreferencesJsonData,
+			Sources: sources(map[string]pref.PreferenceSource{
+				"navbar": pref.PreferenceSourceUser, "queryHistory.homeTab": pref.PreferenceSourceUser,
+			}),
 		}, preference)
 	})
 
@@ -221,10 +239,117 @@ func TestGetDefaults_JSONData(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, &pref.Preference{
 			JSONData: &team2PreferencesJsonData,
+			Sources:  sources(map[string]pref.PreferenceSource{"navbar": pref.PreferenceSourceTeam}),
 		}, preference)
 	})
 }
 
+func TestNavbar(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+	get := func(t *testing.T, userID int64) *pref.Preference {
+		t.Helper()
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: userID})
+		require.NoError(t, err)
+		return preference
+	}
+
+	t.Run("validates the pinned and hidden items", func(t *testing.T) {
+		for _, navbar := range []pref.NavbarPreference{
+			{PinnedItems: []string{"unknown"}},
+			{HiddenItems: []string{"plugin-page-"}},
+			{PinnedItems: []string{"explore", "explore"}},
+			{PinnedItems: []string{"explore"}, HiddenItems: []string{"explore"}},
+		} {
+			navbar := navbar
+			err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Navbar: &navbar})
+			require.ErrorIs(t, err, pref.ErrInvalidNavbarItem)
+		}
+	})
+
+	t.Run("users without a navbar get the one of their organization", func(t *testing.T) {
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{
+			OrgID:  1,
+			Navbar: &pref.NavbarPreference{PinnedItems: []string{"dashboards", "plugin-page-grafana-oncall-app"}, HiddenItems: []string{"live"}},
+		}))
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "dark"}))
+
+		preference := get(t, 1)
+		assert.Equal(t, []string{"dashboards", "plugin-page-grafana-oncall-app"}, preference.JSONData.Navbar.PinnedItems)
+		assert.Equal(t, []string{"live"}, preference.JSONData.Navbar.HiddenItems)
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["navbar"])
+	})
+
+	t.Run("patching replaces the pinned or hidden items", func(t *testing.T) {
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID: 1, UserID: 1, Navbar: &pref.NavbarPreference{HiddenItems: []string{"help"}},
+		}))
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID: 1, UserID: 1, Navbar: &pref.NavbarPreference{PinnedItems: []string{"explore"}},
+		}))
+
+		preference := get(t, 1)
+		assert.Equal(t, []string{"explore"}, preference.JSONData.Navbar.PinnedItems)
+		assert.Equal(t, []string{"help"}, preference.JSONData.Navbar.HiddenItems)
+		assert.Equal(t, pref.PreferenceSourceUser, preference.Sources["navbar"])
+
+		err := prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID: 1, UserID: 1, Navbar: &pref.NavbarPreference{PinnedItems: []string{"help"}},
+		})
+		require.ErrorIs(t, err, pref.ErrInvalidNavbarItem)
+	})
+}
+
+func TestQueryHistory(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+	optIn := func(v bool) *bool { return &v }
+	get := func(t *testing.T, userID int64) *pref.Preference {
+		t.Helper()
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: userID})
+		require.NoError(t, err)
+		return preference
+	}
+
+	t.Run("validates the home tab", func(t *testing.T) {
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, QueryHistory: &pref.QueryHistoryPreference{HomeTab: "settings"}})
+		require.ErrorIs(t, err, pref.ErrInvalidQueryHistoryHomeTab)
+		err = prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, QueryHistory: &pref.QueryHistoryPreference{HomeTab: "settings"}})
+		require.ErrorIs(t, err, pref.ErrInvalidQueryHistoryHomeTab)
+	})
+
+	t.Run("users get the preferences of their organization", func(t *testing.T) {
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{
+			OrgID: 1, QueryHistory: &pref.QueryHistoryPreference{HomeTab: "starred", RetentionOptIn: optIn(false)},
+		}))
+
+		preference := get(t, 1)
+		assert.Equal(t, pref.QueryHistoryPreference{HomeTab: "starred", RetentionOptIn: optIn(false)}, preference.JSONData.QueryHistory)
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["queryHistory.homeTab"])
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["queryHistory.retentionOptIn"])
+	})
+
+	t.Run("users override the preferences one by one", func(t *testing.T) {
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID: 1, UserID: 1, QueryHistory: &pref.QueryHistoryPreference{RetentionOptIn: optIn(true)},
+		}))
+
+		preference := get(t, 1)
+		assert.Equal(t, pref.QueryHistoryPreference{HomeTab: "starred", RetentionOptIn: optIn(true)}, preference.JSONData.QueryHistory)
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["queryHistory.homeTab"])
+		assert.Equal(t, pref.PreferenceSourceUser, preference.Sources["queryHistory.retentionOptIn"])
+
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID: 1, UserID: 1, QueryHistory: &pref.QueryHistoryPreference{HomeTab: "query"},
+		}))
+		assert.Equal(t, pref.QueryHistoryPreference{HomeTab: "query", RetentionOptIn: optIn(true)}, get(t, 1).JSONData.QueryHistory)
+	})
+}
+
 func TestGetWithDefaults_teams(t *testing.T) {
 	prefService := &Service{
 		store: newFake(),
@@ -265,6 +390,10 @@ func TestGetWithDefaults_teams(t *testing.T) {
 		WeekStart:       "2",
 		HomeDashboardID: 4,
 		JSONData:        &pref.PreferenceJSONData{},
+		Sources: sources(map[string]pref.PreferenceSource{
+			"theme": pref.PreferenceSourceTeam, "timezone": pref.PreferenceSourceTeam,
+			"weekStart": pref.PreferenceSourceTeam, "homeDashboardId": pref.PreferenceSourceTeam,
+		}),
 	}
 	if diff := cmp.Diff(expected, preferences); diff != "" {
 		t.Fatalf("Result mismatch (-want +got):\n%s", diff)
@@ -362,6 +491,84 @@ func TestSave(t *testing.T) {
 	})
 }
 
+func TestSave_publishesUpdates(t *testing.T) {
+	tracer, err := tracing.InitializeTracerForTest()
+	require.NoError(t, err)
+	bus := bus.ProvideBus(tracer)
+	var published []*events.PreferencesUpdated
+	bus.AddEventListener(func(ctx context.Context, evt *events.PreferencesUpdated) error {
+		published = append(published, evt)
+		return nil
+	})
+
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+		bus:   bus,
+	}
+
+	require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, Theme: "dark", HomeDashboardID: 5}))
+	require.Len(t, published, 1)
+	assert.Equal(t, "team", published[0].Scope)
+	assert.EqualValues(t, 2, published[0].TeamID)
+	assert.Nil(t, published[0].Old)
+	assert.Equal(t, &events.PreferencesValues{Theme: "dark", HomeDashboardID: 5}, published[0].New)
+
+	require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, Theme: "light", Locale: "fr-FR"}))
+	require.Len(t, published, 2)
+	assert.Equal(t, &events.PreferencesValues{Theme: "dark", HomeDashboardID: 5}, published[1].Old)
+	assert.Equal(t, &events.PreferencesValues{Theme: "light", Locale: "fr-FR"}, published[1].New)
+
+	timezone := "utc"
+	require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, UserID: 3, Timezone: &timezone}))
+	require.Len(t, published, 3)
+	assert.Equal(t, "user", published[2].Scope)
+	assert.Nil(t, published[2].Old)
+	assert.Equal(t, &events.PreferencesValues{Timezone: "utc"}, published[2].New)
+
+	version := 5
+	require.ErrorIs(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, Version: &version}), pref.ErrPreferencesVersionMismatch)
+	require.Len(t, published, 3, "failed saves aren't published")
+}
+
+func TestPatchOrgUsers_publishesUpdates(t *testing.T) {
+	tracer, err := tracing.InitializeTracerForTest()
+	require.NoError(t, err)
+	bus := bus.ProvideBus(tracer)
+	var published []*events.PreferencesUpdated
+	bus.AddEventListener(func(ctx context.Context, evt *events.PreferencesUpdated) error {
+		published = append(published, evt)
+		return nil
+	})
+
+	store := newFake()
+	insertPrefs(t, store,
+		pref.Preference{OrgID: 1, UserID: 2, Theme: "light", JSONData: &pref.PreferenceJSONData{Locale: "fr-FR"}},
+		pref.Preference{OrgID: 1, UserID: 3, Theme: "dark"},
+		pref.Preference{OrgID: 1, TeamID: 4, Theme: "light"},
+	)
+	prefService := &Service{
+		store: store,
+		cfg:   setting.NewCfg(),
+		bus:   bus,
+	}
+
+	empty := ""
+	updated, err := prefService.PatchOrgUsers(context.Background(), &pref.PatchOrgUsersPreferencesCommand{OrgID: 1, Theme: &empty, ResetJSONData: true})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, updated)
+	require.Len(t, published, 2)
+	sort.Slice(published, func(i, j int) bool { return published[i].UserID < published[j].UserID })
+
+	assert.Equal(t, "user", published[0].Scope)
+	assert.EqualValues(t, 2, published[0].UserID)
+	assert.Equal(t, &events.PreferencesValues{Theme: "light", Locale: "fr-FR"}, published[0].Old)
+	assert.Equal(t, &events.PreferencesValues{}, published[0].New)
+	assert.EqualValues(t, 3, published[1].UserID)
+	assert.Equal(t, &events.PreferencesValues{Theme: "dark"}, published[1].Old)
+	assert.Equal(t, &events.PreferencesValues{}, published[1].New)
+}
+
 func insertPrefs(t testing.TB, store store, preferences ...pref.Preference) {
 	t.Helper()
 	for _, p := range preferences {
@@ -378,3 +585,257 @@ func newFake() store {
 		nextID:     1,
 	}
 }
+
+func TestPatch_dismissedAnnouncements(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+
+	dismissed := []string{"maintenance"}
+	err := prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+		OrgID:                  1,
+		UserID:                 2,
+		DismissedAnnouncements: &dismissed,
+	})
+	require.NoError(t, err)
+
+	stored := prefService.store.(*inmemStore).preference[preferenceKey{OrgID: 1, UserID: 2}]
+	assert.Equal(t, []string{"maintenance"}, stored.JSONData.DismissedAnnouncements)
+
+	t.Run("are kept when the preferences are saved", func(t *testing.T) {
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{
+			OrgID:  1,
+			UserID: 2,
+			Theme:  "dark",
+		})
+		require.NoError(t, err)
+
+		stored := prefService.store.(*inmemStore).preference[preferenceKey{OrgID: 1, UserID: 2}]
+		assert.Equal(t, "dark", stored.Theme)
+		assert.Equal(t, []string{"maintenance"}, stored.JSONData.DismissedAnnouncements)
+	})
+}
+
+func TestPatch_onboarding(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+
+	onboarding := &pref.OnboardingPreference{CompletedSteps: []string{"add-datasource"}}
+	err := prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+		OrgID:      1,
+		UserID:     2,
+		Onboarding: onboarding,
+	})
+	require.NoError(t, err)
+
+	err = prefService.Save(context.Background(), &pref.SavePreferenceCommand{
+		OrgID:  1,
+		UserID: 2,
+		Theme:  "dark",
+	})
+	require.NoError(t, err)
+
+	stored := prefService.store.(*inmemStore).preference[preferenceKey{OrgID: 1, UserID: 2}]
+	assert.Equal(t, onboarding, stored.JSONData.Onboarding)
+}
+
+func TestCustom(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+
+	patch := func(teamID, userID int64, custom string) error {
+		var values map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(custom), &values))
+		return prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID:  1,
+			TeamID: teamID,
+			UserID: userID,
+			Custom: values,
+		})
+	}
+	require.NoError(t, patch(0, 0, `{"editor": {"mode": "builder", "rows": 5}, "compact": false}`))
+	require.NoError(t, patch(2, 0, `{"editor": {"rows": 10}}`))
+	require.NoError(t, patch(0, 1, `{"editor": {"mode": "code"}, "compact": true}`))
+
+	t.Run("users override teams and org key by key", func(t *testing.T) {
+		custom, err := prefService.GetCustom(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 1, Teams: []int64{2}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"editor":  map[string]interface{}{"mode": "code", "rows": float64(10)},
+			"compact": true,
+		}, custom)
+	})
+
+	t.Run("keys set to null are removed", func(t *testing.T) {
+		require.NoError(t, patch(0, 1, `{"editor": {"mode": null}, "compact": null}`))
+		custom, err := prefService.GetCustom(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"editor":  map[string]interface{}{"mode": "builder", "rows": float64(5)},
+			"compact": false,
+		}, custom)
+	})
+
+	t.Run("are kept when the preferences are saved", func(t *testing.T) {
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, Theme: "dark"})
+		require.NoError(t, err)
+		stored := prefService.store.(*inmemStore).preference[preferenceKey{OrgID: 1}]
+		assert.Equal(t, false, stored.JSONData.Custom["compact"])
+	})
+
+	t.Run("are limited in size", func(t *testing.T) {
+		err := prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID:  1,
+			UserID: 1,
+			Custom: map[string]interface{}{"notes": strings.Repeat("a", pref.MaxCustomSize)},
+		})
+		require.ErrorIs(t, err, pref.ErrCustomTooLarge)
+	})
+}
+
+func TestDefaultDatasourceUID(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+
+	patch := func(teamID, userID int64, uid string) {
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{
+			OrgID:                1,
+			TeamID:               teamID,
+			UserID:               userID,
+			DefaultDatasourceUID: &uid,
+		}))
+	}
+	get := func(userID int64, teams ...int64) string {
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: userID, Teams: teams})
+		require.NoError(t, err)
+		return preference.JSONData.DefaultDatasourceUID
+	}
+	patch(0, 0, "org")
+	patch(2, 0, "team")
+
+	t.Run("teams have precedence over org", func(t *testing.T) {
+		assert.Equal(t, "org", get(1))
+		assert.Equal(t, "team", get(1, 2))
+	})
+
+	t.Run("users have precedence over teams", func(t *testing.T) {
+		patch(0, 1, "user")
+		assert.Equal(t, "user", get(1, 2))
+	})
+
+	t.Run("users without one get the one of their teams", func(t *testing.T) {
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, UserID: 3, Custom: map[string]interface{}{"compact": true}}))
+		patch(0, 1, "")
+		assert.Equal(t, "team", get(1, 2))
+		assert.Equal(t, "team", get(3, 2))
+	})
+
+	t.Run("is set when the preferences are saved", func(t *testing.T) {
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, Theme: "dark"}))
+		assert.Equal(t, "org", get(1, 2))
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, DefaultDatasourceUID: "saved"}))
+		assert.Equal(t, "saved", get(1, 2))
+	})
+}
+
+func TestGetWithDefaults_sources(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+	prefService.cfg.DefaultTheme = "dark"
+
+	theme, timezone, weekStart, locale := "light", "UTC", "monday", "fr-FR"
+	require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, Theme: &theme}))
+	require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, TeamID: 2, Timezone: &timezone}))
+	require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, UserID: 1, WeekStart: &weekStart, Locale: &locale}))
+
+	preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 1, Teams: []int64{2}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]pref.PreferenceSource{
+		"theme":                       pref.PreferenceSourceOrg,
+		"timezone":                    pref.PreferenceSourceTeam,
+		"weekStart":                   pref.PreferenceSourceUser,
+		"homeDashboardId":             pref.PreferenceSourceDefault,
+		"locale":                      pref.PreferenceSourceUser,
+		"navbar":                      pref.PreferenceSourceDefault,
+		"queryHistory.homeTab":        pref.PreferenceSourceDefault,
+		"queryHistory.retentionOptIn": pref.PreferenceSourceDefault,
+		"defaultDatasourceUid":        pref.PreferenceSourceDefault,
+	}, preference.Sources)
+
+	t.Run("users without preferences get the sources of their organization", func(t *testing.T) {
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 3})
+		require.NoError(t, err)
+		assert.Equal(t, "light", preference.Theme)
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["theme"])
+		assert.Equal(t, pref.PreferenceSourceDefault, preference.Sources["timezone"])
+		assert.Equal(t, pref.PreferenceSourceDefault, preference.Sources["locale"])
+	})
+
+	t.Run("users with preferences inherit the locale of their organization", func(t *testing.T) {
+		orgLocale := "de-DE"
+		require.NoError(t, prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, Locale: &orgLocale}))
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 4, Theme: "dark"}))
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 4, Timezone: "browser"}))
+
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 4, Teams: []int64{4}})
+		require.NoError(t, err)
+		assert.Equal(t, "de-DE", preference.JSONData.Locale)
+		assert.Equal(t, pref.PreferenceSourceOrg, preference.Sources["locale"])
+	})
+}
+
+// sources returns the sources of the preferences that are not in overrides
+// set to the instance defaults.
+func sources(overrides map[string]pref.PreferenceSource) map[string]pref.PreferenceSource {
+	result := map[string]pref.PreferenceSource{}
+	for _, name := range []string{"theme", "timezone", "weekStart", "homeDashboardId", "locale", "defaultDatasourceUid", "navbar", "queryHistory.homeTab", "queryHistory.retentionOptIn"} {
+		result[name] = pref.PreferenceSourceDefault
+	}
+	for name, source := range overrides {
+		result[name] = source
+	}
+	return result
+}
+
+func TestSave_version(t *testing.T) {
+	prefService := &Service{
+		store: newFake(),
+		cfg:   setting.NewCfg(),
+	}
+	version := func(v int) *int { return &v }
+
+	t.Run("creating fails unless the version is 0", func(t *testing.T) {
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "dark", Version: version(1)})
+		require.ErrorIs(t, err, pref.ErrPreferencesVersionMismatch)
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "dark", Version: version(0)}))
+	})
+
+	t.Run("saving from an outdated version fails", func(t *testing.T) {
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "light", Version: version(0)}))
+
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "dark", Version: version(0)})
+		require.ErrorIs(t, err, pref.ErrPreferencesVersionMismatch)
+
+		preference, err := prefService.Get(context.Background(), &pref.GetPreferenceQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "light", preference.Theme)
+		assert.Equal(t, 1, preference.Version)
+	})
+
+	t.Run("saving without a version replaces the preferences", func(t *testing.T) {
+		require.NoError(t, prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 1, Theme: "dark"}))
+		preference, err := prefService.Get(context.Background(), &pref.GetPreferenceQuery{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "dark", preference.Theme)
+		assert.Equal(t, 2, preference.Version)
+	})
+}
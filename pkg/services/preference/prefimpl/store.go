@@ -3,6 +3,7 @@ package prefimpl
 import (
 	"context"
 	"strings"
+	"time"
 
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -13,9 +14,44 @@ type store interface {
 	Get(context.Context, *pref.Preference) (*pref.Preference, error)
 	List(context.Context, *pref.Preference) ([]*pref.Preference, error)
 	Insert(context.Context, *pref.Preference) (int64, error)
+	// Update fails with ErrPreferencesVersionMismatch unless the version of the
+	// stored preferences is the one before the version of the update.
 	Update(context.Context, *pref.Preference) error
+	// TeamPriorities returns the priorities of the teams, keyed by team id.
+	TeamPriorities(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]int64, error)
+	// PatchOrgUsers returns the preferences of the users that were updated.
+	PatchOrgUsers(context.Context, *pref.PatchOrgUsersPreferencesCommand) ([]patchedPreference, error)
+	// ListOrg returns the preferences of the organization, of its teams and of
+	// its users.
+	ListOrg(ctx context.Context, orgID int64) ([]*pref.Preference, error)
+	// References returns the teams, users and dashboards of the organization the
+	// preferences can refer to.
+	References(ctx context.Context, orgID int64) (*references, error)
+	// Import inserts the preferences, or replaces the stored ones of the same
+	// organization, team and user, in one transaction. It returns the replaced
+	// preferences, nil for the inserted ones, in the order of prefs.
+	Import(ctx context.Context, prefs []*pref.Preference) ([]*pref.Preference, error)
 }
 
+// references are the names of the teams, the logins of the users and the uids
+// of the dashboards of an organization, keyed by id.
+type references struct {
+	teams      map[int64]string
+	users      map[int64]string
+	dashboards map[int64]string
+}
+
+// patchedPreference are the preferences of a user before and after
+// PatchOrgUsers. before is nil when the user had no preferences.
+type patchedPreference struct {
+	before *pref.Preference
+	after  *pref.Preference
+}
+
+// patchInsertBatchSize keeps the inserts of PatchOrgUsers under the limit of
+// parameters of SQLite.
+const patchInsertBatchSize = 50
+
 type sqlStore struct {
 	db db.DB
 }
@@ -72,8 +108,14 @@ func (s *sqlStore) List(ctx context.Context, query *pref.Preference) ([]*pref.Pr
 
 func (s *sqlStore) Update(ctx context.Context, cmd *pref.Preference) error {
 	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		_, err := sess.ID(cmd.ID).AllCols().Update(cmd)
-		return err
+		affected, err := sess.ID(cmd.ID).Where("version = ?", cmd.Version-1).AllCols().Update(cmd)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pref.ErrPreferencesVersionMismatch
+		}
+		return nil
 	})
 }
 
@@ -86,3 +128,209 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *pref.Preference) (int64, err
 	})
 	return ID, err
 }
+
+func (s *sqlStore) TeamPriorities(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]int64, error) {
+	priorities := make(map[int64]int64, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return priorities, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var teams []struct {
+			ID       int64 `xorm:"id"`
+			Priority int64 `xorm:"priority"`
+		}
+		if err := sess.Table("team").Cols("id", "priority").Where("org_id = ?", orgID).In("id", teamIDs).Find(&teams); err != nil {
+			return err
+		}
+		for _, team := range teams {
+			priorities[team.ID] = team.Priority
+		}
+		return nil
+	})
+	return priorities, err
+}
+
+func (s *sqlStore) PatchOrgUsers(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) ([]patchedPreference, error) {
+	if !cmd.SetsValues() && !cmd.ResetsValues() {
+		return nil, nil
+	}
+
+	now := time.Now()
+	patched := make([]patchedPreference, 0)
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := make([]*pref.Preference, 0)
+		if err := sess.Where("org_id = ? AND team_id = 0 AND user_id > 0", cmd.OrgID).Find(&existing); err != nil {
+			return err
+		}
+
+		for _, before := range existing {
+			after := *before
+			if before.JSONData != nil {
+				jsonData := *before.JSONData
+				after.JSONData = &jsonData
+			}
+			patchOrgUser(&after, cmd)
+			after.Version = before.Version + 1
+			after.Updated = now
+			if _, err := sess.ID(after.ID).AllCols().Update(&after); err != nil {
+				return err
+			}
+			patched = append(patched, patchedPreference{before: before, after: &after})
+		}
+
+		// users without preferences already use the ones of their teams,
+		// organization or instance, they only need a row to set a value
+		if !cmd.SetsValues() {
+			return nil
+		}
+
+		missing := make([]int64, 0)
+		if err := sess.SQL(`SELECT org_user.user_id FROM org_user
+			INNER JOIN `+s.db.GetDialect().Quote("user")+` AS u ON u.id = org_user.user_id
+			WHERE org_user.org_id = ? AND u.is_service_account = ?
+			AND NOT EXISTS (SELECT 1 FROM preferences WHERE preferences.org_id = org_user.org_id AND preferences.user_id = org_user.user_id AND preferences.team_id = 0)`,
+			cmd.OrgID, s.db.GetDialect().BooleanStr(false)).Find(&missing); err != nil {
+			return err
+		}
+
+		for start := 0; start < len(missing); start += patchInsertBatchSize {
+			end := start + patchInsertBatchSize
+			if end > len(missing) {
+				end = len(missing)
+			}
+			rows := make([]*pref.Preference, 0, end-start)
+			for _, userID := range missing[start:end] {
+				p := &pref.Preference{
+					OrgID:    cmd.OrgID,
+					UserID:   userID,
+					Created:  now,
+					Updated:  now,
+					JSONData: &pref.PreferenceJSONData{},
+				}
+				patchOrgUser(p, cmd)
+				rows = append(rows, p)
+				patched = append(patched, patchedPreference{after: p})
+			}
+			if _, err := sess.InsertMulti(rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// patchOrgUser applies the preferences set by the command to the ones of a
+// user. Resetting the json data keeps the dismissed announcements and the
+// onboarding progress, they aren't preferences the users get from their teams,
+// organization or instance.
+func patchOrgUser(p *pref.Preference, cmd *pref.PatchOrgUsersPreferencesCommand) {
+	if cmd.HomeDashboardID != nil {
+		p.HomeDashboardID = *cmd.HomeDashboardID
+	}
+	if cmd.Timezone != nil {
+		p.Timezone = *cmd.Timezone
+	}
+	if cmd.WeekStart != nil {
+		p.WeekStart = *cmd.WeekStart
+	}
+	if cmd.Theme != nil {
+		p.Theme = *cmd.Theme
+	}
+	if cmd.ResetJSONData {
+		jsonData := &pref.PreferenceJSONData{}
+		if p.JSONData != nil {
+			jsonData.DismissedAnnouncements = p.JSONData.DismissedAnnouncements
+			jsonData.Onboarding = p.JSONData.Onboarding
+		}
+		p.JSONData = jsonData
+	}
+}
+
+func (s *sqlStore) ListOrg(ctx context.Context, orgID int64) ([]*pref.Preference, error) {
+	prefs := make([]*pref.Preference, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).OrderBy("team_id ASC, user_id ASC").Find(&prefs)
+	})
+	return prefs, err
+}
+
+func (s *sqlStore) References(ctx context.Context, orgID int64) (*references, error) {
+	refs := &references{
+		teams:      map[int64]string{},
+		users:      map[int64]string{},
+		dashboards: map[int64]string{},
+	}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var teams []struct {
+			ID   int64  `xorm:"id"`
+			Name string `xorm:"name"`
+		}
+		if err := sess.Table("team").Cols("id", "name").Where("org_id = ?", orgID).Find(&teams); err != nil {
+			return err
+		}
+		for _, team := range teams {
+			refs.teams[team.ID] = team.Name
+		}
+
+		var users []struct {
+			ID    int64  `xorm:"id"`
+			Login string `xorm:"login"`
+		}
+		if err := sess.SQL(`SELECT u.id, u.login FROM org_user
+			INNER JOIN `+s.db.GetDialect().Quote("user")+` AS u ON u.id = org_user.user_id
+			WHERE org_user.org_id = ?`, orgID).Find(&users); err != nil {
+			return err
+		}
+		for _, user := range users {
+			refs.users[user.ID] = user.Login
+		}
+
+		var dashboards []struct {
+			ID  int64  `xorm:"id"`
+			UID string `xorm:"uid"`
+		}
+		if err := sess.Table("dashboard").Cols("id", "uid").Where("org_id = ? AND is_folder = ?", orgID, s.db.GetDialect().BooleanStr(false)).Find(&dashboards); err != nil {
+			return err
+		}
+		for _, dashboard := range dashboards {
+			refs.dashboards[dashboard.ID] = dashboard.UID
+		}
+		return nil
+	})
+	return refs, err
+}
+
+func (s *sqlStore) Import(ctx context.Context, prefs []*pref.Preference) ([]*pref.Preference, error) {
+	replaced := make([]*pref.Preference, len(prefs))
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for i, p := range prefs {
+			var existing pref.Preference
+			exists, err := sess.Where("org_id=? AND user_id=? AND team_id=?", p.OrgID, p.UserID, p.TeamID).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				if _, err := sess.Insert(p); err != nil {
+					return err
+				}
+				continue
+			}
+			p.ID = existing.ID
+			p.Created = existing.Created
+			p.Version = existing.Version + 1
+			if _, err := sess.ID(p.ID).AllCols().Update(p); err != nil {
+				return err
+			}
+			replaced[i] = &existing
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
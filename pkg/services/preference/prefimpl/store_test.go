@@ -2,13 +2,18 @@ package prefimpl
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/models"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -130,6 +135,7 @@ func TestIntegrationPreferencesDataAccess(t *testing.T) {
 
 		err = prefStore.Update(context.Background(), &pref.Preference{
 			ID:              id,
+			Version:         1,
 			Theme:           "dark",
 			HomeDashboardID: 5,
 			Timezone:        "browser",
@@ -157,6 +163,21 @@ func TestIntegrationPreferencesDataAccess(t *testing.T) {
 			t.Fatalf("Result mismatch (-want +got):\n%s", diff)
 		}
 	})
+	t.Run("Update fails when the preferences have been updated since", func(t *testing.T) {
+		ss := sqlstore.InitTestDB(t)
+		prefStore := sqlStore{db: ss}
+		id, err := prefStore.Insert(context.Background(), &pref.Preference{OrgID: 1, UserID: 1, Theme: "dark", Created: time.Now(), Updated: time.Now()})
+		require.NoError(t, err)
+
+		require.NoError(t, prefStore.Update(context.Background(), &pref.Preference{ID: id, OrgID: 1, UserID: 1, Version: 1, Theme: "light", Created: time.Now(), Updated: time.Now()}))
+		err = prefStore.Update(context.Background(), &pref.Preference{ID: id, OrgID: 1, UserID: 1, Version: 1, Theme: "dark", Created: time.Now(), Updated: time.Now()})
+		require.ErrorIs(t, err, pref.ErrPreferencesVersionMismatch)
+
+		stored, err := prefStore.Get(context.Background(), &pref.Preference{OrgID: 1, UserID: 1})
+		require.NoError(t, err)
+		require.Equal(t, "light", stored.Theme)
+		require.Equal(t, 1, stored.Version)
+	})
 	t.Run("insert preference that does not exist", func(t *testing.T) {
 		_, err := prefStore.Insert(context.Background(),
 			&pref.Preference{
@@ -168,3 +189,214 @@ func TestIntegrationPreferencesDataAccess(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestIntegrationPreferencesTeamOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+	prefStore := sqlStore{db: ss}
+
+	// the preferences of the team with the lowest id were updated last
+	teamIDs := make([]int64, 0, 3)
+	now := time.Now().Truncate(time.Second)
+	for i, name := range []string{"first", "second", "third"} {
+		team, err := ss.CreateTeam(name, "", 1)
+		require.NoError(t, err)
+		teamIDs = append(teamIDs, team.Id)
+		_, err = prefStore.Insert(ctx, &pref.Preference{
+			OrgID:           1,
+			TeamID:          team.Id,
+			HomeDashboardID: int64(i + 1),
+			Created:         now,
+			Updated:         now.Add(-time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	setPriority := func(t *testing.T, teamID, priority int64) {
+		t.Helper()
+		query := &models.GetTeamByIdQuery{OrgId: 1, Id: teamID}
+		require.NoError(t, ss.GetTeamById(ctx, query))
+		require.NoError(t, ss.UpdateTeam(ctx, &models.UpdateTeamCommand{OrgId: 1, Id: teamID, Name: query.Result.Name, Priority: &priority}))
+	}
+
+	for _, cacheTTL := range []time.Duration{0, time.Minute} {
+		cfg := setting.NewCfg()
+		cfg.PreferencesCacheTTL = cacheTTL
+		svc := ProvideService(ss, cfg, kvstore.ProvideService(ss), nil, nil)
+		homeDashboardID := func(t *testing.T) int64 {
+			t.Helper()
+			prefs, err := svc.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 1, Teams: teamIDs})
+			require.NoError(t, err)
+			return prefs.HomeDashboardID
+		}
+
+		t.Run(fmt.Sprintf("cache ttl %s", cacheTTL), func(t *testing.T) {
+			t.Run("the last team wins when the teams have the same priority", func(t *testing.T) {
+				require.EqualValues(t, 3, homeDashboardID(t))
+			})
+
+			t.Run("the team with the highest priority wins", func(t *testing.T) {
+				setPriority(t, teamIDs[0], 10)
+				t.Cleanup(func() { setPriority(t, teamIDs[0], 0) })
+				require.EqualValues(t, 1, homeDashboardID(t))
+
+				setPriority(t, teamIDs[1], 20)
+				t.Cleanup(func() { setPriority(t, teamIDs[1], 0) })
+				require.EqualValues(t, 2, homeDashboardID(t))
+			})
+
+			t.Run("the team that updated its preferences last wins", func(t *testing.T) {
+				cfg.PreferencesTeamOrder = string(pref.TeamOrderUpdated)
+				t.Cleanup(func() { cfg.PreferencesTeamOrder = string(pref.TeamOrderPriority) })
+				require.EqualValues(t, 1, homeDashboardID(t))
+			})
+		})
+	}
+}
+
+func TestIntegrationPreferencesPatchOrgUsers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	ss := sqlstore.InitTestDB(t)
+	prefStore := sqlStore{db: ss}
+
+	withPreferences, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "with-preferences"})
+	require.NoError(t, err)
+	orgID := withPreferences.OrgId
+	other, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "other"})
+	require.NoError(t, err)
+	ss.Cfg.AutoAssignOrg = true
+	withoutPreferences, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "without-preferences", OrgId: orgID})
+	require.NoError(t, err)
+	_, err = ss.CreateUser(ctx, models.CreateUserCommand{Login: "sa", OrgId: orgID, IsServiceAccount: true})
+	require.NoError(t, err)
+
+	_, err = prefStore.Insert(ctx, &pref.Preference{OrgID: orgID, UserID: withPreferences.Id, Theme: "light", Timezone: "UTC", Created: time.Now(), Updated: time.Now(),
+		JSONData: &pref.PreferenceJSONData{Locale: "fr-FR", QueryHistory: pref.QueryHistoryPreference{HomeTab: "starred"}, DismissedAnnouncements: []string{"welcome"}}})
+	require.NoError(t, err)
+	_, err = prefStore.Insert(ctx, &pref.Preference{OrgID: orgID, TeamID: 1, Theme: "light", Created: time.Now(), Updated: time.Now()})
+	require.NoError(t, err)
+
+	getUser := func(userID int64) *pref.Preference {
+		p, err := prefStore.Get(ctx, &pref.Preference{OrgID: orgID, UserID: userID})
+		require.NoError(t, err)
+		return p
+	}
+
+	userIDs := func(patched []patchedPreference) []int64 {
+		ids := make([]int64, 0, len(patched))
+		for _, p := range patched {
+			ids = append(ids, p.after.UserID)
+		}
+		return ids
+	}
+
+	t.Run("Setting a preference updates all the users and adds the missing rows", func(t *testing.T) {
+		theme := "dark"
+		patched, err := prefStore.PatchOrgUsers(ctx, &pref.PatchOrgUsersPreferencesCommand{OrgID: orgID, Theme: &theme})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{withPreferences.Id, withoutPreferences.Id}, userIDs(patched))
+		for _, p := range patched {
+			if p.after.UserID == withPreferences.Id {
+				require.Equal(t, "light", p.before.Theme)
+			} else {
+				require.Nil(t, p.before)
+			}
+		}
+
+		p := getUser(withPreferences.Id)
+		require.Equal(t, "dark", p.Theme)
+		require.Equal(t, "UTC", p.Timezone)
+		require.Equal(t, 1, p.Version)
+		require.Equal(t, "dark", getUser(withoutPreferences.Id).Theme)
+
+		team, err := prefStore.Get(ctx, &pref.Preference{OrgID: orgID, TeamID: 1})
+		require.NoError(t, err)
+		require.Equal(t, "light", team.Theme)
+	})
+
+	t.Run("Resetting preferences only updates the existing rows", func(t *testing.T) {
+		empty := ""
+		patched, err := prefStore.PatchOrgUsers(ctx, &pref.PatchOrgUsersPreferencesCommand{OrgID: orgID, Theme: &empty, Timezone: &empty})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{withPreferences.Id, withoutPreferences.Id}, userIDs(patched))
+
+		p := getUser(withPreferences.Id)
+		require.Empty(t, p.Theme)
+		require.Empty(t, p.Timezone)
+		require.Equal(t, "fr-FR", p.JSONData.Locale, "the json data is only reset on request")
+	})
+
+	t.Run("Resetting the json data keeps the dismissed announcements", func(t *testing.T) {
+		patched, err := prefStore.PatchOrgUsers(ctx, &pref.PatchOrgUsersPreferencesCommand{OrgID: orgID, ResetJSONData: true})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{withPreferences.Id, withoutPreferences.Id}, userIDs(patched))
+
+		p := getUser(withPreferences.Id)
+		require.Empty(t, p.JSONData.Locale)
+		require.Empty(t, p.JSONData.QueryHistory.HomeTab)
+		require.Equal(t, []string{"welcome"}, p.JSONData.DismissedAnnouncements)
+	})
+
+	t.Run("Patching the preferences of another organization only updates its users", func(t *testing.T) {
+		theme := "light"
+		patched, err := prefStore.PatchOrgUsers(ctx, &pref.PatchOrgUsersPreferencesCommand{OrgID: other.OrgId, Theme: &theme})
+		require.NoError(t, err)
+		require.Equal(t, []int64{other.Id}, userIDs(patched))
+		require.Empty(t, getUser(withPreferences.Id).Theme)
+	})
+}
+
+func TestIntegrationPreferencesImport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	prefStore := sqlStore{db: ss}
+	ctx := context.Background()
+
+	org, err := ss.CreateOrgWithMember("Import", 0)
+	require.NoError(t, err)
+	orgID := org.Id
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "alice", OrgId: orgID})
+	require.NoError(t, err)
+	team, err := ss.CreateTeam("SRE", "", orgID)
+	require.NoError(t, err)
+	dashboard := &models.Dashboard{OrgId: orgID, Uid: "home", Title: "Home", Slug: "home", Data: simplejson.New(), Created: time.Now(), Updated: time.Now()}
+	require.NoError(t, ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(dashboard)
+		return err
+	}))
+
+	refs, err := prefStore.References(ctx, orgID)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{team.Id: "SRE"}, refs.teams)
+	assert.Equal(t, map[int64]string{user.Id: "alice"}, refs.users)
+	assert.Equal(t, map[int64]string{dashboard.Id: "home"}, refs.dashboards)
+
+	_, err = prefStore.Insert(ctx, &pref.Preference{OrgID: orgID, UserID: user.Id, Theme: "dark", Version: 4, Created: time.Now(), Updated: time.Now()})
+	require.NoError(t, err)
+
+	now := time.Now()
+	replaced, err := prefStore.Import(ctx, []*pref.Preference{
+		{OrgID: orgID, HomeDashboardID: dashboard.Id, Created: now, Updated: now, JSONData: &pref.PreferenceJSONData{}},
+		{OrgID: orgID, UserID: user.Id, Theme: "light", Created: now, Updated: now, JSONData: &pref.PreferenceJSONData{Locale: "fr-FR"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, replaced, 2)
+	assert.Nil(t, replaced[0])
+	require.NotNil(t, replaced[1])
+	assert.Equal(t, "dark", replaced[1].Theme)
+
+	prefs, err := prefStore.ListOrg(ctx, orgID)
+	require.NoError(t, err)
+	require.Len(t, prefs, 2)
+	assert.Equal(t, dashboard.Id, prefs[0].HomeDashboardID)
+	assert.Equal(t, "light", prefs[1].Theme)
+	assert.Equal(t, "fr-FR", prefs[1].JSONData.Locale)
+	assert.Equal(t, 5, prefs[1].Version)
+}
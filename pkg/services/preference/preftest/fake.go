@@ -7,8 +7,13 @@ import (
 )
 
 type FakePreferenceService struct {
-	ExpectedPreference *pref.Preference
-	ExpectedError      error
+	ExpectedPreference       *pref.Preference
+	ExpectedCustom           map[string]interface{}
+	ExpectedInstanceDefaults pref.InstanceDefaults
+	ExpectedPatchedUsers     int64
+	ExpectedExport           *pref.PreferencesExport
+	ExpectedImportResult     *pref.ImportPreferencesResult
+	ExpectedError            error
 }
 
 func NewPreferenceServiceFake() *FakePreferenceService {
@@ -31,6 +36,30 @@ func (f *FakePreferenceService) GetDefaults() *pref.Preference {
 	return f.ExpectedPreference
 }
 
+func (f *FakePreferenceService) GetCustom(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (map[string]interface{}, error) {
+	return f.ExpectedCustom, f.ExpectedError
+}
+
 func (f *FakePreferenceService) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) error {
 	return f.ExpectedError
 }
+
+func (f *FakePreferenceService) PatchOrgUsers(ctx context.Context, cmd *pref.PatchOrgUsersPreferencesCommand) (int64, error) {
+	return f.ExpectedPatchedUsers, f.ExpectedError
+}
+
+func (f *FakePreferenceService) GetInstanceDefaults() pref.InstanceDefaults {
+	return f.ExpectedInstanceDefaults
+}
+
+func (f *FakePreferenceService) UpdateInstanceDefaults(ctx context.Context, cmd *pref.UpdateInstanceDefaultsCommand) (pref.InstanceDefaults, error) {
+	return f.ExpectedInstanceDefaults, f.ExpectedError
+}
+
+func (f *FakePreferenceService) Export(ctx context.Context, orgID int64) (*pref.PreferencesExport, error) {
+	return f.ExpectedExport, f.ExpectedError
+}
+
+func (f *FakePreferenceService) Import(ctx context.Context, cmd *pref.ImportPreferencesCommand) (*pref.ImportPreferencesResult, error) {
+	return f.ExpectedImportResult, f.ExpectedError
+}
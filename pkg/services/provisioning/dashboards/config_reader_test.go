@@ -58,6 +58,9 @@ func TestDashboardsAsConfig(t *testing.T) {
 			require.NoError(t, err)
 
 			validateDashboardAsConfig(t, cfg)
+			require.True(t, cfg[0].Interpolate)
+			require.Equal(t, map[string]string{"environment": "general"}, cfg[0].Parameters)
+			require.False(t, cfg[1].Interpolate)
 		})
 
 		t.Run("Can read config file in version 0 format", func(t *testing.T) {
@@ -19,6 +19,7 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -121,6 +122,18 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// PushDashboardUIUpdate commits a provisioned dashboard updated from the UI to
+// the repository it's provisioned from, when its provider is a git provider
+// with a push branch.
+func (provider *Provisioner) PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == provisioning.Name {
+			return reader.pushDashboard(ctx, provisioning, dash, user, message)
+		}
+	}
+	return nil
+}
+
 func getFileReaders(
 	configs []*config, logger log.Logger, service dashboards.DashboardProvisioningService, store utils.DashboardStore,
 ) ([]*FileReader, error) {
@@ -134,6 +147,12 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(config, logger.New("type", config.Type, "name", config.Name), service, store)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -1,6 +1,10 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -63,3 +67,8 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// PushDashboardUIUpdate not implemented for mocks
+func (dpm *ProvisionerMock) PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error {
+	return nil
+}
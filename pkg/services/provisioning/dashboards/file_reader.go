@@ -39,6 +39,11 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// git is the repository of the readers of git providers, nil otherwise
+	git *gitRepository
+	// commit is the SHA of the commit of the repository the dashboards are read from
+	commit string
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -88,6 +93,10 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	if fr.git != nil {
+		fr.syncRepository(ctx)
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -259,6 +268,11 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 		dash.Dashboard.SetId(provisionedData.DashboardId)
 	}
 
+	if fr.git != nil {
+		// record the commit in the version of the dashboard
+		dash.Message = fmt.Sprintf("Provisioned from %s at commit %s", fr.git.branch, fr.getCommit())
+	}
+
 	if !fr.isDatabaseAccessRestricted() {
 		fr.log.Debug("saving new dashboard", "provisioner", fr.Cfg.Name, "file", path, "folderId", dash.Dashboard.FolderId)
 		dp := &models.DashboardProvisioning{
@@ -402,12 +416,21 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 		return nil, err
 	}
 
-	checkSum, err := util.Md5SumString(string(all))
+	data, err := simplejson.NewJson(all)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := simplejson.NewJson(all)
+	if fr.Cfg.Interpolate {
+		// the checksum covers the interpolated dashboard, so that changing a
+		// parameter or an environment variable updates the dashboard
+		data = simplejson.NewFromAny(interpolateDashboard(data.Interface(), fr.Cfg.Parameters))
+		if all, err = data.Encode(); err != nil {
+			return nil, err
+		}
+	}
+
+	checkSum, err := util.Md5SumString(string(all))
 	if err != nil {
 		return nil, err
 	}
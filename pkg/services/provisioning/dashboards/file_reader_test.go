@@ -24,6 +24,7 @@ const (
 	containingID              = "testdata/test-dashboards/containing-id"
 	unprovision               = "testdata/test-dashboards/unprovision"
 	foldersFromFilesStructure = "testdata/test-dashboards/folders-from-files-structure"
+	interpolation             = "testdata/test-dashboards/interpolation"
 	configName                = "default"
 )
 
@@ -89,6 +90,48 @@ func TestCreatingNewDashboardFileReader(t *testing.T) {
 	})
 }
 
+func TestDashboardFileReader_interpolation(t *testing.T) {
+	t.Setenv("GF_TEST_DATASOURCE_UID", "prom-prod")
+	path := filepath.Join(interpolation, "dashboard.json")
+	read := func(t *testing.T, cfg *config) *dashboardJSONFile {
+		t.Helper()
+		cfg.Name = configName
+		cfg.OrgID = 1
+		cfg.Options = map[string]interface{}{"path": interpolation}
+		reader, err := NewDashboardFileReader(cfg, log.New("test-logger"), nil, &fakeDashboardStore{})
+		require.NoError(t, err)
+		file, err := reader.readDashboardFromFile(path, time.Now(), 0)
+		require.NoError(t, err)
+		return file
+	}
+
+	t.Run("replaces the parameters and the environment variables", func(t *testing.T) {
+		file := read(t, &config{Interpolate: true, Parameters: map[string]string{"environment": "prod", "job_name": "api"}})
+		data := file.dashboard.Dashboard.Data
+
+		assert.Equal(t, "Services (prod)", file.dashboard.Dashboard.Title)
+		assert.Equal(t, "services-prod", file.dashboard.Dashboard.Uid)
+		panel := data.Get("panels").GetIndex(0)
+		assert.Equal(t, "prom-prod", panel.GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "api", data.Get("templating").Get("list").GetIndex(0).Get("query").MustString())
+
+		// escaped references and template variables are kept
+		assert.Equal(t, "Requests in ${environment}", panel.Get("title").MustString())
+		assert.Contains(t, panel.Get("targets").GetIndex(0).Get("expr").MustString(), `job="${job}"`)
+	})
+
+	t.Run("the checksum changes with the parameters", func(t *testing.T) {
+		prod := read(t, &config{Interpolate: true, Parameters: map[string]string{"environment": "prod"}})
+		dev := read(t, &config{Interpolate: true, Parameters: map[string]string{"environment": "dev"}})
+		assert.NotEqual(t, prod.checkSum, dev.checkSum)
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		file := read(t, &config{Parameters: map[string]string{"environment": "prod"}})
+		assert.Equal(t, "Services (${environment})", file.dashboard.Dashboard.Title)
+	})
+}
+
 func TestDashboardFileReader(t *testing.T) {
 	logger := log.New("test-logger")
 	cfg := &config{}
@@ -0,0 +1,344 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+const defaultGitBranch = "main"
+
+// gitCommitterName and gitCommitterEmail identify the committer of the commits
+// pushing the dashboards updated from the UI, the author being the user who
+// saved the dashboard.
+const (
+	gitCommitterName  = "Grafana"
+	gitCommitterEmail = "grafana@localhost"
+)
+
+// NewDashboardGitReader returns a reader provisioning the dashboards of a
+// directory of a Git repository. The repository is cloned to the clonePath
+// option, and pulled every time the dashboards are read.
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService, dashboardStore utils.DashboardStore) (*FileReader, error) {
+	repo, err := newGitRepository(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	directory, _ := cfg.Options["directory"].(string)
+	if directory != "" && !isRelativePath(filepath.Clean(filepath.FromSlash(directory))) {
+		return nil, fmt.Errorf("the directory %q must be relative to the root of the repository", directory)
+	}
+
+	// the directories of the repository map to folders, unless the dashboards
+	// are provisioned to a single folder
+	foldersFromFilesStructure, ok := cfg.Options["foldersFromFilesStructure"].(bool)
+	if !ok {
+		foldersFromFilesStructure = cfg.Folder == "" && cfg.FolderUID == ""
+	}
+	if foldersFromFilesStructure && cfg.Folder != "" && cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+	}
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         filepath.Join(repo.dir, filepath.FromSlash(directory)),
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		usageTracker:                 newUsageTracker(),
+		git:                          repo,
+	}, nil
+}
+
+// gitRepository is the local clone of the Git repository of a git dashboard
+// provider. It shells out to the git command, so that the authentication
+// configured for it, such as SSH agents and credential helpers, is used.
+type gitRepository struct {
+	url        string
+	branch     string
+	pushBranch string
+	dir        string
+	sshKeyFile string
+	log        log.Logger
+
+	// mu serializes the git commands run in the clone
+	mu sync.Mutex
+}
+
+func newGitRepository(cfg *config, log log.Logger) (*gitRepository, error) {
+	repoURL, _ := cfg.Options["url"].(string)
+	if repoURL == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+
+	repo := &gitRepository{
+		url:    repoURL,
+		branch: defaultGitBranch,
+		log:    log,
+	}
+	if branch, ok := cfg.Options["branch"].(string); ok && branch != "" {
+		repo.branch = branch
+	}
+	repo.pushBranch, _ = cfg.Options["pushBranch"].(string)
+	repo.sshKeyFile, _ = cfg.Options["sshKeyFile"].(string)
+
+	if repo.pushBranch != "" {
+		if !cfg.AllowUIUpdates {
+			return nil, fmt.Errorf("'pushBranch' requires 'allowUiUpdates' to be enabled")
+		}
+		if cfg.Interpolate {
+			// the interpolated values would be committed in place of the references
+			return nil, fmt.Errorf("'pushBranch' can't be used with 'interpolate'")
+		}
+	}
+
+	dir, _ := cfg.Options["clonePath"].(string)
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "grafana-provisioning", "git", models.SlugifyTitle(cfg.Name))
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo.dir = dir
+
+	return repo, nil
+}
+
+// sync clones the repository, or fetches the branch and resets the clone to it
+// when it's already cloned. It returns the SHA of the checked out commit.
+func (r *gitRepository) sync(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); os.IsNotExist(err) {
+		r.log.Info("Cloning dashboards repository", "url", redactURL(r.url), "branch", r.branch, "path", r.dir)
+		if err := os.MkdirAll(filepath.Dir(r.dir), 0750); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, nil, "clone", "--branch", r.branch, "--single-branch", "--", r.url, r.dir); err != nil {
+			return "", err
+		}
+	} else {
+		// the url may have changed in the configuration
+		if _, err := r.git(ctx, nil, nil, "remote", "set-url", "origin", r.url); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, nil, "fetch", "--prune", "origin", fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", r.branch, r.branch)); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, nil, "checkout", "--force", "-B", r.branch, "origin/"+r.branch); err != nil {
+			return "", err
+		}
+		if _, err := r.git(ctx, nil, nil, "clean", "-fdx"); err != nil {
+			return "", err
+		}
+	}
+
+	return r.git(ctx, nil, nil, "rev-parse", "HEAD")
+}
+
+// gitAuthor is the author of a commit.
+type gitAuthor struct {
+	name  string
+	email string
+}
+
+// commitFile commits the content of the file at path, relative to the root of
+// the repository, on top of the push branch, creating the branch from the
+// provisioned branch when it doesn't exist, and pushes it. The clone's working
+// tree is left untouched. It returns the SHA of the commit, empty when the
+// file already had the content.
+func (r *gitRepository) commitFile(ctx context.Context, path string, content []byte, author gitAuthor, message string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	base := "origin/" + r.pushBranch
+	if _, err := r.git(ctx, nil, nil, "fetch", "origin", fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", r.pushBranch, r.pushBranch)); err != nil {
+		r.log.Debug("Creating the push branch from the provisioned branch", "pushBranch", r.pushBranch, "error", err)
+		base = "origin/" + r.branch
+	}
+
+	blob, err := r.git(ctx, nil, content, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+
+	// build the tree in a separate index, so that the clone isn't changed
+	index, err := os.CreateTemp("", "grafana-git-index")
+	if err != nil {
+		return "", err
+	}
+	indexPath := index.Name()
+	_ = index.Close()
+	// git refuses an empty index file
+	_ = os.Remove(indexPath)
+	defer func() { _ = os.Remove(indexPath) }()
+	indexEnv := []string{"GIT_INDEX_FILE=" + indexPath}
+
+	if _, err := r.git(ctx, indexEnv, nil, "read-tree", base); err != nil {
+		return "", err
+	}
+	if _, err := r.git(ctx, indexEnv, nil, "update-index", "--add", "--cacheinfo", "100644,"+blob+","+path); err != nil {
+		return "", err
+	}
+	tree, err := r.git(ctx, indexEnv, nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	baseTree, err := r.git(ctx, nil, nil, "rev-parse", base+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if tree == baseTree {
+		return "", nil
+	}
+
+	commitEnv := []string{
+		"GIT_AUTHOR_NAME=" + author.name,
+		"GIT_AUTHOR_EMAIL=" + author.email,
+		"GIT_COMMITTER_NAME=" + gitCommitterName,
+		"GIT_COMMITTER_EMAIL=" + gitCommitterEmail,
+	}
+	commit, err := r.git(ctx, commitEnv, nil, "commit-tree", tree, "-p", base, "-m", message)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.git(ctx, nil, nil, "push", "origin", commit+":refs/heads/"+r.pushBranch); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// git runs the git command in the clone, and returns its trimmed output.
+func (r *gitRepository) git(ctx context.Context, env []string, stdin []byte, args ...string) (string, error) {
+	// nolint:gosec
+	// The arguments come from the provisioning configuration file and the
+	// dashboards, and are never interpreted by a shell.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if _, err := os.Stat(r.dir); err == nil {
+		cmd.Dir = r.dir
+	}
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if r.sshKeyFile != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %q -o IdentitiesOnly=yes", r.sshKeyFile))
+	}
+	cmd.Env = append(cmd.Env, env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(strings.ReplaceAll(stderr.String(), r.url, redactURL(r.url)))
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redactURL removes the credentials from the url of the repository.
+func redactURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.User == nil {
+		return repoURL
+	}
+	return u.Redacted()
+}
+
+// syncRepository pulls the repository of the git reader, keeping the previous
+// clone when it fails, so that the dashboards aren't deleted while the
+// repository is unavailable.
+func (fr *FileReader) syncRepository(ctx context.Context) {
+	commit, err := fr.git.sync(ctx)
+	if err != nil {
+		fr.log.Error("Failed to pull dashboards repository", "url", redactURL(fr.git.url), "branch", fr.git.branch, "error", err)
+		return
+	}
+
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+	if commit != fr.commit {
+		fr.log.Info("Provisioning dashboards from commit", "branch", fr.git.branch, "commit", commit)
+	}
+	fr.commit = commit
+}
+
+func (fr *FileReader) getCommit() string {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	return fr.commit
+}
+
+// pushDashboard commits the dashboard updated from the UI to its file in the
+// repository of the git reader, on the push branch.
+func (fr *FileReader) pushDashboard(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error {
+	if fr.git == nil || fr.git.pushBranch == "" {
+		return nil
+	}
+
+	root, err := filepath.EvalSymlinks(fr.git.dir)
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Rel(root, provisioning.ExternalId)
+	if err != nil || !isRelativePath(path) {
+		return fmt.Errorf("the dashboard file %q isn't in the repository", provisioning.ExternalId)
+	}
+
+	// the id and the version are specific to the instance
+	data := dash.Data.MustMap()
+	body := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "id" && k != "version" {
+			body[k] = v
+		}
+	}
+	content, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+
+	if message == "" {
+		message = fmt.Sprintf("Update dashboard %q", dash.Title)
+	}
+	author := gitAuthor{name: user.Name, email: user.Email}
+	if author.name == "" {
+		author.name = user.Login
+	}
+	if author.email == "" {
+		author.email = gitCommitterEmail
+	}
+
+	commit, err := fr.git.commitFile(ctx, filepath.ToSlash(path), content, author, message)
+	if err != nil {
+		return err
+	}
+	if commit != "" {
+		fr.log.Info("Pushed dashboard updated from the UI", "uid", dash.Uid, "file", path, "branch", fr.git.pushBranch, "commit", commit)
+	}
+	return nil
+}
+
+// isRelativePath returns whether the cleaned path is relative and doesn't
+// escape its root.
+func isRelativePath(path string) bool {
+	return !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+}
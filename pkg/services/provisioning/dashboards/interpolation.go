@@ -0,0 +1,51 @@
+package dashboards
+
+import (
+	"os"
+	"regexp"
+)
+
+// referencePattern matches the ${name} references the provisioner replaces, and
+// the $${name} escapes that are kept as ${name}.
+var referencePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateDashboard returns a copy of the dashboard JSON with the ${name}
+// references in its string values replaced by the parameter with the name, or
+// else by the environment variable. References to anything else, like the
+// template variables of the dashboard, are kept.
+func interpolateDashboard(data interface{}, parameters map[string]string) interface{} {
+	switch value := data.(type) {
+	case string:
+		return interpolateString(value, parameters)
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[k] = interpolateDashboard(item, parameters)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = interpolateDashboard(item, parameters)
+		}
+		return copied
+	default:
+		return data
+	}
+}
+
+func interpolateString(text string, parameters map[string]string) string {
+	return referencePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		name := ref[2 : len(ref)-1]
+		if value, ok := parameters[name]; ok {
+			return value
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return ref
+	})
+}
@@ -21,6 +21,10 @@ type config struct {
 	DisableDeletion       bool
 	UpdateIntervalSeconds int64
 	AllowUIUpdates        bool
+	// Interpolate enables replacing the ${name} references in the dashboards
+	// with the parameters, or the environment variables.
+	Interpolate bool
+	Parameters  map[string]string
 }
 
 type configV0 struct {
@@ -34,6 +38,8 @@ type configV0 struct {
 	DisableDeletion       bool                   `json:"disableDeletion" yaml:"disableDeletion"`
 	UpdateIntervalSeconds int64                  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
 	AllowUIUpdates        bool                   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	Interpolate           bool                   `json:"interpolate" yaml:"interpolate"`
+	Parameters            map[string]string      `json:"parameters" yaml:"parameters"`
 }
 
 type configVersion struct {
@@ -45,16 +51,18 @@ type configV1 struct {
 }
 
 type configs struct {
-	Name                  values.StringValue `json:"name" yaml:"name"`
-	Type                  values.StringValue `json:"type" yaml:"type"`
-	OrgID                 values.Int64Value  `json:"orgId" yaml:"orgId"`
-	Folder                values.StringValue `json:"folder" yaml:"folder"`
-	FolderUID             values.StringValue `json:"folderUid" yaml:"folderUid"`
-	Editable              values.BoolValue   `json:"editable" yaml:"editable"`
-	Options               values.JSONValue   `json:"options" yaml:"options"`
-	DisableDeletion       values.BoolValue   `json:"disableDeletion" yaml:"disableDeletion"`
-	UpdateIntervalSeconds values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
-	AllowUIUpdates        values.BoolValue   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	Name                  values.StringValue    `json:"name" yaml:"name"`
+	Type                  values.StringValue    `json:"type" yaml:"type"`
+	OrgID                 values.Int64Value     `json:"orgId" yaml:"orgId"`
+	Folder                values.StringValue    `json:"folder" yaml:"folder"`
+	FolderUID             values.StringValue    `json:"folderUid" yaml:"folderUid"`
+	Editable              values.BoolValue      `json:"editable" yaml:"editable"`
+	Options               values.JSONValue      `json:"options" yaml:"options"`
+	DisableDeletion       values.BoolValue      `json:"disableDeletion" yaml:"disableDeletion"`
+	UpdateIntervalSeconds values.Int64Value     `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
+	AllowUIUpdates        values.BoolValue      `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	Interpolate           values.BoolValue      `json:"interpolate" yaml:"interpolate"`
+	Parameters            values.StringMapValue `json:"parameters" yaml:"parameters"`
 }
 
 func createDashboardJSON(data *simplejson.Json, lastModified time.Time, cfg *config, folderID int64) (*dashboards.SaveDashboardDTO, error) {
@@ -94,6 +102,8 @@ func mapV0ToDashboardsAsConfig(v0 []*configV0) ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion,
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds,
 			AllowUIUpdates:        v.AllowUIUpdates,
+			Interpolate:           v.Interpolate,
+			Parameters:            v.Parameters,
 		})
 	}
 
@@ -121,6 +131,8 @@ func (dc *configV1) mapToDashboardsAsConfig() ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion.Value(),
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds.Value(),
 			AllowUIUpdates:        v.AllowUIUpdates.Value(),
+			Interpolate:           v.Interpolate.Value(),
+			Parameters:            v.Parameters.Value(),
 		})
 	}
 
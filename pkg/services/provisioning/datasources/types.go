@@ -212,6 +212,7 @@ func createInsertCommand(ds *upsertDataSourceFromConfig) *models.AddDataSourceCo
 		JsonData:        jsonData,
 		SecureJsonData:  ds.SecureJSONData,
 		ReadOnly:        !ds.Editable,
+		Origin:          models.DsOriginFile,
 		Uid:             ds.UID,
 	}
 
@@ -253,5 +254,6 @@ func createUpdateCommand(ds *upsertDataSourceFromConfig, id int64) *models.Updat
 		JsonData:        jsonData,
 		SecureJsonData:  ds.SecureJSONData,
 		ReadOnly:        !ds.Editable,
+		Origin:          models.DsOriginFile,
 	}
 }
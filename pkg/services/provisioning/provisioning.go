@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -60,6 +61,7 @@ type ProvisioningService interface {
 	ProvisionDashboards(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -219,6 +221,12 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// PushDashboardUIUpdate commits a provisioned dashboard updated from the UI to
+// the Git repository it's provisioned from, when its provider pushes UI updates.
+func (ps *ProvisioningServiceImpl) PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error {
+	return ps.dashboardProvisioner.PushDashboardUIUpdate(ctx, provisioning, dash, user, message)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
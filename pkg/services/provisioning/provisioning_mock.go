@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []interface{}
@@ -22,6 +26,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	PushDashboardUIUpdateFunc               func(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -87,6 +92,13 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) PushDashboardUIUpdate(ctx context.Context, provisioning *models.DashboardProvisioning, dash *models.Dashboard, user *models.SignedInUser, message string) error {
+	if mock.PushDashboardUIUpdateFunc != nil {
+		return mock.PushDashboardUIUpdateFunc(ctx, provisioning, dash, user, message)
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// aggregationIntervalsField is the field of the query model with the
+// aggregation intervals the panel of the query accepts, e.g. ["1m", "1h", "1d"].
+const aggregationIntervalsField = "aggregationIntervals"
+
+// parseAggregationIntervals returns the sorted aggregation intervals the panel
+// of the query accepts, none if it didn't declare any.
+func parseAggregationIntervals(query *simplejson.Json) ([]time.Duration, error) {
+	values, ok := query.CheckGet(aggregationIntervalsField)
+	if !ok {
+		return nil, nil
+	}
+
+	intervals := make([]time.Duration, 0, len(values.MustArray()))
+	for _, value := range values.MustStringArray() {
+		interval, err := gtime.ParseInterval(value)
+		if err != nil || interval <= 0 {
+			return nil, NewErrBadQuery(fmt.Sprintf("invalid aggregation interval %q", value))
+		}
+		intervals = append(intervals, interval)
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals, nil
+}
+
+// coarsenQuery coarsens the interval of a query over a time range at least as
+// wide as the policy's min range to the smallest accepted aggregation interval
+// returning no more than the max data points of the query, capped by the
+// policy. When no accepted interval is coarse enough, the largest one is used.
+// The interval of the query is never made finer. It returns whether the query
+// was changed.
+func coarsenQuery(policy models.QueryAggregationPolicy, q *backend.DataQuery, intervals []time.Duration) bool {
+	timeRange := q.TimeRange.Duration()
+	if policy.MinRange <= 0 || len(intervals) == 0 || timeRange < policy.MinRange {
+		return false
+	}
+
+	maxDataPoints := q.MaxDataPoints
+	if policy.MaxDataPoints > 0 && (maxDataPoints <= 0 || maxDataPoints > policy.MaxDataPoints) {
+		maxDataPoints = policy.MaxDataPoints
+	}
+
+	target := q.Interval
+	if maxDataPoints > 0 {
+		if perPoint := timeRange / time.Duration(maxDataPoints); perPoint > target {
+			target = perPoint
+		}
+	}
+
+	interval := intervals[len(intervals)-1]
+	for _, accepted := range intervals {
+		if accepted >= target {
+			interval = accepted
+			break
+		}
+	}
+	if interval < q.Interval {
+		interval = q.Interval
+	}
+
+	if interval == q.Interval && maxDataPoints == q.MaxDataPoints {
+		return false
+	}
+	q.Interval = interval
+	q.MaxDataPoints = maxDataPoints
+	return true
+}
+
+// applyAggregationPolicy coarsens the query according to the aggregation
+// policy of the data source and the aggregation intervals the panel of the
+// query accepts, so that queries over wide time ranges of high resolution data
+// are aggregated by the data source rather than returning every point.
+func (s *Service) applyAggregationPolicy(ds *models.DataSource, query *simplejson.Json, q *backend.DataQuery) error {
+	policy := ds.QueryAggregationPolicy()
+	if policy.MinRange <= 0 {
+		return nil
+	}
+
+	intervals, err := parseAggregationIntervals(query)
+	if err != nil {
+		return err
+	}
+
+	original := q.Interval
+	if !coarsenQuery(policy, q, intervals) {
+		return nil
+	}
+
+	// data sources read the interval and max data points from the model too
+	var model map[string]interface{}
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return err
+	}
+	model["intervalMs"] = q.Interval.Milliseconds()
+	model["maxDataPoints"] = q.MaxDataPoints
+	if q.JSON, err = json.Marshal(model); err != nil {
+		return err
+	}
+
+	metrics.MDataSourceQueriesCoarsened.WithLabelValues(ds.Type).Inc()
+	s.log.Debug("Coarsened query over a wide time range", "datasource", ds.Uid, "refId", q.RefID,
+		"timeRange", q.TimeRange.Duration(), "interval", original, "coarsenedInterval", q.Interval, "maxDataPoints", q.MaxDataPoints)
+	return nil
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestCoarsenQuery(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	intervals := []time.Duration{time.Minute, 5 * time.Minute, time.Hour, day}
+
+	tcs := []struct {
+		desc                  string
+		policy                models.QueryAggregationPolicy
+		timeRange             time.Duration
+		interval              time.Duration
+		maxDataPoints         int64
+		intervals             []time.Duration
+		expectedInterval      time.Duration
+		expectedMaxDataPoints int64
+		expectedChanged       bool
+	}{
+		{
+			desc:      "policy disabled",
+			timeRange: 90 * day, interval: 15 * time.Second, maxDataPoints: 1000, intervals: intervals,
+			expectedInterval: 15 * time.Second, expectedMaxDataPoints: 1000,
+		},
+		{
+			desc:   "no aggregation intervals",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day}, timeRange: 90 * day, interval: 15 * time.Second, maxDataPoints: 1000,
+			expectedInterval: 15 * time.Second, expectedMaxDataPoints: 1000,
+		},
+		{
+			desc:   "time range narrower than the min range",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day}, timeRange: day, interval: 15 * time.Second, maxDataPoints: 1000, intervals: intervals,
+			expectedInterval: 15 * time.Second, expectedMaxDataPoints: 1000,
+		},
+		{
+			desc:   "smallest interval fitting the max data points",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day}, timeRange: 30 * day, interval: 15 * time.Second, maxDataPoints: 1000, intervals: intervals,
+			expectedInterval: time.Hour, expectedMaxDataPoints: 1000, expectedChanged: true,
+		},
+		{
+			desc:   "max data points capped by the policy",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day, MaxDataPoints: 100}, timeRange: 30 * day, interval: 15 * time.Second, maxDataPoints: 1000, intervals: intervals,
+			expectedInterval: day, expectedMaxDataPoints: 100, expectedChanged: true,
+		},
+		{
+			desc:   "largest interval when none is coarse enough",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day}, timeRange: 365 * day, interval: 15 * time.Second, maxDataPoints: 100, intervals: intervals[:2],
+			expectedInterval: 5 * time.Minute, expectedMaxDataPoints: 100, expectedChanged: true,
+		},
+		{
+			desc:   "interval never made finer",
+			policy: models.QueryAggregationPolicy{MinRange: 7 * day}, timeRange: 30 * day, interval: 2 * day, maxDataPoints: 1000, intervals: intervals,
+			expectedInterval: 2 * day, expectedMaxDataPoints: 1000,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			q := backend.DataQuery{
+				TimeRange:     backend.TimeRange{From: now.Add(-tc.timeRange), To: now},
+				Interval:      tc.interval,
+				MaxDataPoints: tc.maxDataPoints,
+			}
+			changed := coarsenQuery(tc.policy, &q, tc.intervals)
+			require.Equal(t, tc.expectedChanged, changed)
+			require.Equal(t, tc.expectedInterval, q.Interval)
+			require.Equal(t, tc.expectedMaxDataPoints, q.MaxDataPoints)
+		})
+	}
+}
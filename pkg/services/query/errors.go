@@ -1,6 +1,13 @@
 package query
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrQueryTimeout is returned when a data source query runs longer than the
+// max query duration configured for the data source.
+var ErrQueryTimeout = errors.New("data source query timed out")
 
 // ErrBadQuery returned whenever request is malformed and must contain a message
 // suitable to return in API response.
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// panelCacheTimeTolerance is how much the length of the time range of a
+// request can differ from the one of a cached response, since clients resolve
+// relative time ranges to the millisecond.
+const panelCacheTimeTolerance = time.Minute
+
+// PanelCache keeps the responses of the queries of dashboard panels that were
+// run ahead of time, to warm up the most viewed dashboards. An entry is scoped
+// to a panel of a version of a dashboard, and is only served for the queries
+// it was run for, over a time range of the same length that ends after the one
+// it was run for.
+type PanelCache struct {
+	cache *localcache.CacheService
+}
+
+func ProvidePanelCache(cache *localcache.CacheService) *PanelCache {
+	return &PanelCache{cache: cache}
+}
+
+// PanelCacheKey identifies the panel of a version of a dashboard.
+type PanelCacheKey struct {
+	OrgID            int64
+	DashboardUID     string
+	DashboardVersion int
+	PanelID          int64
+}
+
+func (k PanelCacheKey) String() string {
+	return fmt.Sprintf("panel-query-%d-%s-%d-%d", k.OrgID, k.DashboardUID, k.DashboardVersion, k.PanelID)
+}
+
+type panelCacheEntry struct {
+	queries  []map[string]interface{}
+	from     time.Time
+	to       time.Time
+	response *backend.QueryDataResponse
+}
+
+// Set caches the response of the queries of a panel for the ttl.
+func (c *PanelCache) Set(key PanelCacheKey, reqDTO dtos.MetricRequest, resp *backend.QueryDataResponse, ttl time.Duration) error {
+	queries, err := queryMaps(reqDTO.Queries)
+	if err != nil {
+		return err
+	}
+	timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
+	c.cache.Set(key.String(), &panelCacheEntry{
+		queries:  queries,
+		from:     timeRange.GetFromAsTimeUTC(),
+		to:       timeRange.GetToAsTimeUTC(),
+		response: resp,
+	}, ttl)
+	return nil
+}
+
+// Get returns the cached response of the queries of a panel. The caller must
+// check that the user can view the panel and query its data sources.
+func (c *PanelCache) Get(key PanelCacheKey, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, bool) {
+	cached, ok := c.cache.Get(key.String())
+	if !ok || len(reqDTO.Transformations) > 0 {
+		return nil, false
+	}
+	entry := cached.(*panelCacheEntry)
+
+	timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
+	from, to := timeRange.GetFromAsTimeUTC(), timeRange.GetToAsTimeUTC()
+	length := to.Sub(from) - entry.to.Sub(entry.from)
+	if length < -panelCacheTimeTolerance || length > panelCacheTimeTolerance || to.Before(entry.to) {
+		return nil, false
+	}
+
+	requested, err := queryMaps(reqDTO.Queries)
+	if err != nil || len(requested) != len(entry.queries) {
+		return nil, false
+	}
+	for i := range entry.queries {
+		if !sameQuery(entry.queries[i], requested[i]) {
+			return nil, false
+		}
+	}
+	return entry.response, true
+}
+
+func queryMaps(queries []*simplejson.Json) ([]map[string]interface{}, error) {
+	maps := make([]map[string]interface{}, 0, len(queries))
+	for _, q := range queries {
+		m, err := q.Map()
+		if err != nil {
+			return nil, err
+		}
+		maps = append(maps, m)
+	}
+	return maps, nil
+}
+
+// sameQuery returns true if the requested query has the properties of the
+// cached one. Clients add properties of their own, and compute the resolution
+// of the queries from the size of the panels, so only the properties of the
+// cached query are compared, and data sources are compared by uid.
+func sameQuery(cached, requested map[string]interface{}) bool {
+	for k, v := range cached {
+		switch k {
+		case "maxDataPoints", "intervalMs", "datasourceId":
+			continue
+		case "datasource":
+			if dataSourceUID(v) != dataSourceUID(requested[k]) {
+				return false
+			}
+			continue
+		}
+		expected, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		actual, err := json.Marshal(requested[k])
+		if err != nil || string(expected) != string(actual) {
+			return false
+		}
+	}
+	return true
+}
+
+func dataSourceUID(ref interface{}) string {
+	switch r := ref.(type) {
+	case map[string]interface{}:
+		uid, _ := r["uid"].(string)
+		return uid
+	case string:
+		return r
+	}
+	return ""
+}
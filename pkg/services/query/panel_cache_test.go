@@ -0,0 +1,59 @@
+package query
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+func TestPanelCache(t *testing.T) {
+	cache := ProvidePanelCache(localcache.New(0, 0))
+	key := PanelCacheKey{OrgID: 1, DashboardUID: "dash", DashboardVersion: 3, PanelID: 2}
+	now := time.Now()
+	ms := func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) }
+	request := func(from, to time.Time, queries ...map[string]interface{}) dtos.MetricRequest {
+		req := dtos.MetricRequest{From: ms(from), To: ms(to)}
+		for _, q := range queries {
+			req.Queries = append(req.Queries, simplejson.NewFromAny(q))
+		}
+		return req
+	}
+	warmed := map[string]interface{}{"refId": "A", "expr": "up", "datasource": map[string]interface{}{"uid": "prom", "type": "prometheus"}, "maxDataPoints": 1000}
+	resp := backend.NewQueryDataResponse()
+	require.NoError(t, cache.Set(key, request(now.Add(-6*time.Hour), now, warmed), resp, time.Hour))
+
+	t.Run("serves the same queries over a later time range of the same length", func(t *testing.T) {
+		requested := map[string]interface{}{"refId": "A", "expr": "up", "datasource": map[string]interface{}{"uid": "prom"}, "maxDataPoints": 640, "intervalMs": 15000}
+		cached, ok := cache.Get(key, request(now.Add(-5*time.Hour), now.Add(time.Hour), requested))
+		require.True(t, ok)
+		require.Same(t, resp, cached)
+	})
+
+	t.Run("doesn't serve other queries", func(t *testing.T) {
+		_, ok := cache.Get(key, request(now.Add(-6*time.Hour), now, map[string]interface{}{"refId": "A", "expr": "down", "datasource": map[string]interface{}{"uid": "prom"}}))
+		require.False(t, ok)
+		_, ok = cache.Get(key, request(now.Add(-6*time.Hour), now, map[string]interface{}{"refId": "A", "expr": "up", "datasource": map[string]interface{}{"uid": "other"}}))
+		require.False(t, ok)
+	})
+
+	t.Run("doesn't serve other time ranges", func(t *testing.T) {
+		_, ok := cache.Get(key, request(now.Add(-24*time.Hour), now, warmed))
+		require.False(t, ok)
+		_, ok = cache.Get(key, request(now.Add(-7*time.Hour), now.Add(-time.Hour), warmed))
+		require.False(t, ok)
+	})
+
+	t.Run("doesn't serve other versions of the dashboard", func(t *testing.T) {
+		other := key
+		other.DashboardVersion = 4
+		_, ok := cache.Get(other, request(now.Add(-6*time.Hour), now, warmed))
+		require.False(t, ok)
+	})
+}
@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,8 +11,10 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/transformations"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
@@ -77,10 +80,21 @@ func (s *Service) QueryData(ctx context.Context, user *models.SignedInUser, skip
 	if err != nil {
 		return nil, err
 	}
+
+	var resp *backend.QueryDataResponse
 	if handleExpressions && parsedReq.hasExpression {
-		return s.handleExpressions(ctx, user, parsedReq)
+		resp, err = s.handleExpressions(ctx, user, parsedReq)
+	} else {
+		resp, err = s.handleQueryData(ctx, user, parsedReq)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return s.handleQueryData(ctx, user, parsedReq)
+
+	if err := s.applyTransformations(resp, parsedReq.refIDs(), reqDTO.Transformations); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // QueryData can process queries and return query responses.
@@ -90,8 +104,13 @@ func (s *Service) QueryDataMultipleSources(ctx context.Context, user *models.Sig
 	if len(byDataSource) == 1 {
 		return s.QueryData(ctx, user, skipCache, reqDTO, handleExpressions)
 	} else {
+		if err := transformations.Validate(reqDTO.Transformations); err != nil {
+			return nil, NewErrBadQuery(err.Error())
+		}
+
 		resp := backend.NewQueryDataResponse()
 
+		// Transformations are applied once all data sources responded, so they are not passed on to the sub requests
 		for _, queries := range byDataSource {
 			subDTO := reqDTO.CloneWithQueries(queries)
 
@@ -106,10 +125,32 @@ func (s *Service) QueryDataMultipleSources(ctx context.Context, user *models.Sig
 			}
 		}
 
+		refIDs := make([]string, 0, len(reqDTO.Queries))
+		for _, query := range reqDTO.Queries {
+			refIDs = append(refIDs, query.Get("refId").MustString("A"))
+		}
+		if err := s.applyTransformations(resp, refIDs, reqDTO.Transformations); err != nil {
+			return nil, err
+		}
+
 		return resp, nil
 	}
 }
 
+// applyTransformations runs the transformations of the request on the query results.
+func (s *Service) applyTransformations(resp *backend.QueryDataResponse, refIDs []string, ts []transformations.Transformation) error {
+	if len(ts) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	if err := transformations.ApplyToResponse(resp, refIDs, ts); err != nil {
+		return NewErrBadQuery(err.Error())
+	}
+	s.log.Debug("Applied transformations to query results", "count", len(ts), "duration", time.Since(start))
+	return nil
+}
+
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (s *Service) handleExpressions(ctx context.Context, user *models.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
@@ -201,7 +242,32 @@ func (s *Service) handleQueryData(ctx context.Context, user *models.SignedInUser
 
 	ctx = httpclient.WithContextualMiddleware(ctx, middlewares...)
 
-	return s.pluginClient.QueryData(ctx, req)
+	if d := ds.MaxQueryDuration(); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, s.queryCancelledError(ds, ctxErr)
+	}
+	return resp, err
+}
+
+// queryCancelledError records a cancelled data source query and returns the
+// error to report. Queries exceeding the data source's max query duration are
+// reported as ErrQueryTimeout, everything else as a client cancellation.
+func (s *Service) queryCancelledError(ds *models.DataSource, ctxErr error) error {
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		metrics.MDataSourceQueriesCancelled.WithLabelValues(ds.Type, "timeout").Inc()
+		s.log.Warn("Data source query exceeded max query duration", "datasource", ds.Uid, "maxQueryDuration", ds.MaxQueryDuration())
+		return fmt.Errorf("%w: exceeded %s", ErrQueryTimeout, ds.MaxQueryDuration())
+	}
+
+	metrics.MDataSourceQueriesCancelled.WithLabelValues(ds.Type, "client").Inc()
+	s.log.Debug("Data source query cancelled by client", "datasource", ds.Uid)
+	return fmt.Errorf("data source query cancelled: %w", ctxErr)
 }
 
 type parsedQuery struct {
@@ -215,6 +281,14 @@ type parsedRequest struct {
 	httpRequest   *http.Request
 }
 
+func (pr parsedRequest) refIDs() []string {
+	refIDs := make([]string, 0, len(pr.parsedQueries))
+	for _, pq := range pr.parsedQueries {
+		refIDs = append(refIDs, pq.query.RefID)
+	}
+	return refIDs
+}
+
 func customHeaders(jsonData *simplejson.Json, decryptedJsonData map[string]string) map[string]string {
 	if jsonData == nil {
 		return nil
@@ -240,6 +314,10 @@ func (s *Service) parseMetricRequest(ctx context.Context, user *models.SignedInU
 		return nil, NewErrBadQuery("no queries found")
 	}
 
+	if err := transformations.Validate(reqDTO.Transformations); err != nil {
+		return nil, NewErrBadQuery(err.Error())
+	}
+
 	timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
 	req := &parsedRequest{
 		hasExpression: false,
@@ -269,19 +347,24 @@ func (s *Service) parseMetricRequest(ctx context.Context, user *models.SignedInU
 			return nil, err
 		}
 
+		dataQuery := backend.DataQuery{
+			TimeRange: backend.TimeRange{
+				From: timeRange.GetFromAsTimeUTC(),
+				To:   timeRange.GetToAsTimeUTC(),
+			},
+			RefID:         query.Get("refId").MustString("A"),
+			MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
+			Interval:      time.Duration(query.Get("intervalMs").MustInt64(1000)) * time.Millisecond,
+			QueryType:     query.Get("queryType").MustString(""),
+			JSON:          modelJSON,
+		}
+		if err := s.applyAggregationPolicy(ds, query, &dataQuery); err != nil {
+			return nil, err
+		}
+
 		req.parsedQueries = append(req.parsedQueries, parsedQuery{
 			datasource: ds,
-			query: backend.DataQuery{
-				TimeRange: backend.TimeRange{
-					From: timeRange.GetFromAsTimeUTC(),
-					To:   timeRange.GetToAsTimeUTC(),
-				},
-				RefID:         query.Get("refId").MustString("A"),
-				MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
-				Interval:      time.Duration(query.Get("intervalMs").MustInt64(1000)) * time.Millisecond,
-				QueryType:     query.Get("queryType").MustString(""),
-				JSON:          modelJSON,
-			},
+			query:      dataQuery,
 		})
 	}
 
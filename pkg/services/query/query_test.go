@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"golang.org/x/oauth2"
 
@@ -14,9 +15,11 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources/secretref"
 	datasources "github.com/grafana/grafana/pkg/services/datasources/service"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/secrets/audit/audittest"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -98,6 +101,70 @@ func TestQueryData(t *testing.T) {
 
 		require.Equal(t, map[string]string{"Cookie": "bar=rab; foo=oof"}, tc.pluginContext.req.Headers)
 	})
+
+	t.Run("it times out queries exceeding the max query duration", func(t *testing.T) {
+		tc := setup(t)
+		tc.dataSourceCache.ds.JsonData = simplejson.NewFromAny(map[string]interface{}{"maxQueryDuration": "10ms"})
+		tc.pluginContext.block = true
+
+		_, err := tc.queryService.QueryData(context.Background(), nil, true, metricRequest(), false)
+		require.ErrorIs(t, err, query.ErrQueryTimeout)
+	})
+
+	t.Run("it coarsens queries over wide time ranges per the data source policy", func(t *testing.T) {
+		tc := setup(t)
+		tc.dataSourceCache.ds.JsonData = simplejson.NewFromAny(map[string]interface{}{"aggregationMinRange": "7d", "aggregationMaxDataPoints": 1000})
+
+		q, err := simplejson.NewJson([]byte(`{"datasourceId":1,"intervalMs":15000,"maxDataPoints":1500,"aggregationIntervals":["1m","1h","1d"]}`))
+		require.NoError(t, err)
+		metricReq := metricRequest()
+		metricReq.Queries = []*simplejson.Json{q}
+		metricReq.From = "now-90d"
+		metricReq.To = "now"
+
+		_, err = tc.queryService.QueryData(context.Background(), nil, true, metricReq, false)
+		require.NoError(t, err)
+
+		dataQuery := tc.pluginContext.req.Queries[0]
+		require.Equal(t, 24*time.Hour, dataQuery.Interval)
+		require.Equal(t, int64(1000), dataQuery.MaxDataPoints)
+		model, err := simplejson.NewJson(dataQuery.JSON)
+		require.NoError(t, err)
+		require.Equal(t, int64(86400000), model.Get("intervalMs").MustInt64())
+		require.Equal(t, int64(1000), model.Get("maxDataPoints").MustInt64())
+
+		// narrow time ranges are left alone
+		metricReq.From = "now-1d"
+		_, err = tc.queryService.QueryData(context.Background(), nil, true, metricReq, false)
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Second, tc.pluginContext.req.Queries[0].Interval)
+	})
+
+	t.Run("it rejects invalid aggregation intervals", func(t *testing.T) {
+		tc := setup(t)
+		tc.dataSourceCache.ds.JsonData = simplejson.NewFromAny(map[string]interface{}{"aggregationMinRange": "7d"})
+
+		q, err := simplejson.NewJson([]byte(`{"datasourceId":1,"aggregationIntervals":["often"]}`))
+		require.NoError(t, err)
+		metricReq := metricRequest()
+		metricReq.Queries = []*simplejson.Json{q}
+
+		_, err = tc.queryService.QueryData(context.Background(), nil, true, metricReq, false)
+		var badQuery *query.ErrBadQuery
+		require.ErrorAs(t, err, &badQuery)
+	})
+
+	t.Run("it propagates client cancellation", func(t *testing.T) {
+		tc := setup(t)
+		tc.pluginContext.block = true
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := tc.queryService.QueryData(ctx, nil, true, metricRequest(), false)
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, query.ErrQueryTimeout)
+	})
 }
 
 func setup(t *testing.T) *testContext {
@@ -108,7 +175,7 @@ func setup(t *testing.T) *testContext {
 
 	ss := kvstore.SetupTestService(t)
 	ssvc := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-	ds := datasources.ProvideService(nil, ssvc, ss, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService())
+	ds := datasources.ProvideService(nil, ssvc, ss, nil, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(), audittest.NewFakeAuditService(), nil, nil, secretref.ProvideService(nil))
 
 	return &testContext{
 		pluginContext:          pc,
@@ -175,10 +242,15 @@ func (c *fakeDataSourceCache) GetDatasourceByUID(ctx context.Context, datasource
 type fakePluginClient struct {
 	plugins.Client
 
-	req *backend.QueryDataRequest
+	req   *backend.QueryDataRequest
+	block bool
 }
 
 func (c *fakePluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	c.req = req
+	if c.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	return nil, nil
 }
@@ -1,7 +1,9 @@
 package queryhistory
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -20,6 +22,11 @@ func (s *QueryHistoryService) registerAPIEndpoints() {
 		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.starHandler))
 		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.unstarHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(s.patchCommentHandler))
+		entities.Post("/share/:uid", middleware.ReqSignedIn, routing.Wrap(s.shareHandler))
+		entities.Delete("/share/:uid/:teamId", middleware.ReqSignedIn, routing.Wrap(s.unshareHandler))
+		entities.Get("/retention", middleware.ReqOrgAdmin, routing.Wrap(s.getRetentionHandler))
+		entities.Put("/retention", middleware.ReqOrgAdmin, routing.Wrap(s.setRetentionHandler))
+		entities.Delete("/retention", middleware.ReqOrgAdmin, routing.Wrap(s.resetRetentionHandler))
 		// Remove migrate endpoint in Grafana v10 as breaking change
 		entities.Post("/migrate", middleware.ReqSignedIn, routing.Wrap(s.migrateHandler))
 	})
@@ -48,6 +55,7 @@ func (s *QueryHistoryService) searchHandler(c *models.ReqContext) response.Respo
 		DatasourceUIDs: c.QueryStrings("datasourceUid"),
 		SearchString:   c.Query("searchString"),
 		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
+		OnlyShared:     c.QueryBoolWithDefault("onlyShared", false),
 		Sort:           c.Query("sort"),
 		Page:           c.QueryInt("page"),
 		Limit:          c.QueryInt("limit"),
@@ -145,3 +153,90 @@ func (s *QueryHistoryService) migrateHandler(c *models.ReqContext) response.Resp
 
 	return response.JSON(http.StatusOK, QueryHistoryMigrationResponse{Message: "Query history successfully migrated", TotalCount: totalCount, StarredCount: starredCount})
 }
+
+// shareHandler handles POST /api/query-history/share/:uid
+func (s *QueryHistoryService) shareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := ShareQueryInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.ShareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrQueryNotFound), errors.Is(err, ErrTeamNotFound):
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, ErrNotTeamMember):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		case errors.Is(err, ErrQueryAlreadyShared):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to share query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// unshareHandler handles DELETE /api/query-history/share/:uid/:teamId
+func (s *QueryHistoryService) unshareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	query, err := s.UnshareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, teamID)
+	if err != nil {
+		if errors.Is(err, ErrQueryNotFound) || errors.Is(err, ErrSharedQueryNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to unshare query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// getRetentionHandler handles GET /api/query-history/retention
+func (s *QueryHistoryService) getRetentionHandler(c *models.ReqContext) response.Response {
+	policy, err := s.GetRetentionPolicyInQueryHistory(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query history retention", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryRetentionResponse{Result: policy})
+}
+
+// setRetentionHandler handles PUT /api/query-history/retention
+func (s *QueryHistoryService) setRetentionHandler(c *models.ReqContext) response.Response {
+	cmd := SetRetentionPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetRetentionPolicyInQueryHistory(c.Req.Context(), c.OrgId, cmd); err != nil {
+		if errors.Is(err, ErrInvalidRetention) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update query history retention", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryRetentionResponse{Result: RetentionPolicy{Days: cmd.Days}})
+}
+
+// resetRetentionHandler handles DELETE /api/query-history/retention
+func (s *QueryHistoryService) resetRetentionHandler(c *models.ReqContext) response.Response {
+	if err := s.ResetRetentionPolicyInQueryHistory(c.Req.Context(), c.OrgId); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset query history retention", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryRetentionResponse{Result: RetentionPolicy{Days: s.Cfg.QueryHistoryRetentionDays, IsDefault: true}})
+}
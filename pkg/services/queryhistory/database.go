@@ -75,7 +75,7 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *models.Sig
 			query_history.comment,
 			query_history.queries,
 		`)
-		writeStarredSQL(query, s.SQLStore, &dtosBuilder)
+		writeStarredSQL(query, user, s.SQLStore, &dtosBuilder)
 		writeFiltersSQL(query, user, s.SQLStore, &dtosBuilder)
 		writeSortSQL(query, s.SQLStore, &dtosBuilder)
 		writeLimitSQL(query, s.SQLStore, &dtosBuilder)
@@ -89,7 +89,7 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *models.Sig
 		countBuilder := sqlstore.SQLBuilder{}
 		countBuilder.Write(`SELECT
 		`)
-		writeStarredSQL(query, s.SQLStore, &countBuilder)
+		writeStarredSQL(query, user, s.SQLStore, &countBuilder)
 		writeFiltersSQL(query, user, s.SQLStore, &countBuilder)
 		err = session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Find(&allQueries)
 		return err
@@ -118,6 +118,12 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *models.Signe
 			s.log.Error("Failed to unstar query while deleting it from query history", "query", UID, "user", user.UserId, "error", err)
 		}
 
+		// Stop sharing it with teams
+		_, err = session.Table("query_history_team_star").Where("org_id = ? AND created_by = ? AND query_uid = ?", user.OrgId, user.UserId, UID).Delete(QueryHistoryTeamStar{})
+		if err != nil {
+			s.log.Error("Failed to unshare query while deleting it from query history", "query", UID, "user", user.UserId, "error", err)
+		}
+
 		// Then delete it
 		id, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgId, user.UserId, UID).Delete(QueryHistory{})
 		if err != nil {
@@ -279,6 +285,21 @@ func (s QueryHistoryService) migrateQueries(ctx context.Context, user *models.Si
 
 	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
 		for _, query := range cmd.Queries {
+			// Skip queries that were already migrated so that the migration from
+			// local storage can safely be retried
+			queries, err := query.Queries.ToDB()
+			if err != nil {
+				return err
+			}
+			exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND datasource_uid = ? AND created_at = ? AND queries = ?",
+				user.OrgId, user.UserId, query.DatasourceUID, query.CreatedAt, string(queries)).Exist()
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
 			uid := util.GenerateShortUID()
 			queryHistories = append(queryHistories, &QueryHistory{
 				OrgID:         user.OrgId,
@@ -332,6 +353,12 @@ func (s QueryHistoryService) migrateQueries(ctx context.Context, user *models.Si
 }
 
 func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan int64) (int, error) {
+	return s.deleteStaleQueriesWhere(ctx, olderThan, "", nil)
+}
+
+// deleteStaleQueriesWhere removes queries created before olderThan which are neither starred
+// nor shared with a team, optionally narrowed down by an additional condition on query_history
+func (s QueryHistoryService) deleteStaleQueriesWhere(ctx context.Context, olderThan int64, condition string, params []interface{}) (int, error) {
 	var rowsCount int64
 
 	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
@@ -342,14 +369,18 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 					SELECT uid FROM query_history
 					LEFT JOIN query_history_star
 					ON query_history_star.query_uid = query_history.uid
+					LEFT JOIN query_history_team_star
+					ON query_history_team_star.query_uid = query_history.uid
 					WHERE query_history_star.query_uid IS NULL
-					AND query_history.created_at <= ?
+					AND query_history_team_star.query_uid IS NULL
+					AND query_history.created_at <= ?` + condition + `
 					ORDER BY query_history.id ASC
 					LIMIT 10000
 				) AS q
 			)`
 
-		res, err := session.Exec(sql, strconv.FormatInt(olderThan, 10))
+		args := append([]interface{}{sql, strconv.FormatInt(olderThan, 10)}, params...)
+		res, err := session.Exec(args...)
 		if err != nil {
 			return err
 		}
@@ -406,7 +437,10 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 							SELECT uid FROM query_history
 							LEFT JOIN query_history_star
 							ON query_history_star.query_uid = query_history.uid
+							LEFT JOIN query_history_team_star
+							ON query_history_team_star.query_uid = query_history.uid
 							WHERE query_history_star.query_uid IS NULL
+							AND query_history_team_star.query_uid IS NULL
 							ORDER BY query_history.id ASC
 							LIMIT ?
 						) AS q
@@ -436,3 +470,117 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 
 	return int(deletedRowsCount), nil
 }
+
+// shareQuery stars query on behalf of a team so that it shows up in the query history of all team members
+func (s QueryHistoryService) shareQuery(ctx context.Context, user *models.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var isStarred bool
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		// Only own queries can be shared
+		exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgId, user.UserId, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		teamExists, err := session.Table("team").Where("org_id = ? AND id = ?", user.OrgId, cmd.TeamID).Exist()
+		if err != nil {
+			return err
+		}
+		if !teamExists {
+			return ErrTeamNotFound
+		}
+
+		// Org admins can share with any team, everybody else only with their own teams
+		if !user.HasRole(models.ROLE_ADMIN) {
+			isMember, err := session.Table("team_member").Where("org_id = ? AND team_id = ? AND user_id = ?", user.OrgId, cmd.TeamID, user.UserId).Exist()
+			if err != nil {
+				return err
+			}
+			if !isMember {
+				return ErrNotTeamMember
+			}
+		}
+
+		queryHistoryTeamStar := QueryHistoryTeamStar{
+			QueryUID:  UID,
+			TeamID:    cmd.TeamID,
+			OrgID:     user.OrgId,
+			CreatedBy: user.UserId,
+			CreatedAt: time.Now().Unix(),
+		}
+
+		_, err = session.Insert(&queryHistoryTeamStar)
+		if err != nil {
+			if s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ErrQueryAlreadyShared
+			}
+			return err
+		}
+
+		isStarred, err = session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserId, UID).Exist()
+		return err
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	dto := QueryHistoryDTO{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Queries:       queryHistory.Queries,
+		Starred:       isStarred,
+	}
+
+	return dto, nil
+}
+
+// unshareQuery removes query from the queries shared with a team
+func (s QueryHistoryService) unshareQuery(ctx context.Context, user *models.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var isStarred bool
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgId, user.UserId, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		id, err := session.Table("query_history_team_star").Where("org_id = ? AND team_id = ? AND query_uid = ?", user.OrgId, teamID, UID).Delete(QueryHistoryTeamStar{})
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			return ErrSharedQueryNotFound
+		}
+
+		isStarred, err = session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserId, UID).Exist()
+		return err
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	dto := QueryHistoryDTO{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Queries:       queryHistory.Queries,
+		Starred:       isStarred,
+	}
+
+	return dto, nil
+}
@@ -10,6 +10,11 @@ var (
 	ErrQueryNotFound        = errors.New("query in query history not found")
 	ErrStarredQueryNotFound = errors.New("starred query not found")
 	ErrQueryAlreadyStarred  = errors.New("query was already starred")
+	ErrSharedQueryNotFound  = errors.New("shared query not found")
+	ErrQueryAlreadyShared   = errors.New("query was already shared with team")
+	ErrTeamNotFound         = errors.New("team not found")
+	ErrNotTeamMember        = errors.New("user is not a member of the team")
+	ErrInvalidRetention     = errors.New("retention must be a positive number of days")
 )
 
 // QueryHistory is the model for query history definitions
@@ -31,10 +36,21 @@ type QueryHistoryStar struct {
 	UserID   int64  `xorm:"user_id"`
 }
 
+// QueryHistoryTeamStar is the model for queries starred on behalf of a team
+type QueryHistoryTeamStar struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	QueryUID  string `xorm:"query_uid"`
+	TeamID    int64  `xorm:"team_id"`
+	OrgID     int64  `xorm:"org_id"`
+	CreatedBy int64
+	CreatedAt int64
+}
+
 type SearchInQueryHistoryQuery struct {
 	DatasourceUIDs []string `json:"datasourceUids"`
 	SearchString   string   `json:"searchString"`
 	OnlyStarred    bool     `json:"onlyStarred"`
+	OnlyShared     bool     `json:"onlyShared"`
 	Sort           string   `json:"sort"`
 	Page           int      `json:"page"`
 	Limit          int      `json:"limit"`
@@ -82,6 +98,16 @@ type QueryToMigrate struct {
 	Starred       bool             `json:"starred"`
 }
 
+// RetentionPolicy describes for how long unstarred queries are kept in query history
+type RetentionPolicy struct {
+	Days      int  `json:"days"`
+	IsDefault bool `json:"isDefault"`
+}
+
+type QueryHistoryRetentionResponse struct {
+	Result RetentionPolicy `json:"result"`
+}
+
 type QueryHistoryMigrationResponse struct {
 	Message      string `json:"message"`
 	TotalCount   int    `json:"totalCount"`
@@ -112,3 +138,19 @@ type MigrateQueriesToQueryHistoryCommand struct {
 	// Array of queries to store in query history.
 	Queries []QueryToMigrate `json:"queries"`
 }
+
+// ShareQueryInQueryHistoryCommand is the command for sharing a query with a team
+// swagger:model
+type ShareQueryInQueryHistoryCommand struct {
+	// ID of the team the query is shared with.
+	// required: true
+	TeamID int64 `json:"teamId"`
+}
+
+// SetRetentionPolicyCommand is the command for overriding query history retention in an organization
+// swagger:model
+type SetRetentionPolicyCommand struct {
+	// Number of days unstarred queries are kept for.
+	// required: true
+	Days int `json:"days"`
+}
@@ -4,17 +4,19 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister) *QueryHistoryService {
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister, kvStore kvstore.KVStore) *QueryHistoryService {
 	s := &QueryHistoryService{
 		SQLStore:      sqlStore,
 		Cfg:           cfg,
 		RouteRegister: routeRegister,
+		KVStore:       kvStore,
 		log:           log.New("query-history"),
 	}
 
@@ -36,12 +38,19 @@ type Service interface {
 	MigrateQueriesToQueryHistory(ctx context.Context, user *models.SignedInUser, cmd MigrateQueriesToQueryHistoryCommand) (int, int, error)
 	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
 	EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error)
+	ShareQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error)
+	UnshareQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error)
+	GetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64) (RetentionPolicy, error)
+	SetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64, cmd SetRetentionPolicyCommand) error
+	ResetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64) error
+	ApplyRetentionPoliciesInQueryHistory(ctx context.Context) (int, error)
 }
 
 type QueryHistoryService struct {
 	SQLStore      *sqlstore.SQLStore
 	Cfg           *setting.Cfg
 	RouteRegister routing.RouteRegister
+	KVStore       kvstore.KVStore
 	log           log.Logger
 }
 
@@ -80,3 +89,27 @@ func (s QueryHistoryService) DeleteStaleQueriesInQueryHistory(ctx context.Contex
 func (s QueryHistoryService) EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error) {
 	return s.enforceQueryHistoryRowLimit(ctx, limit, starredQueries)
 }
+
+func (s QueryHistoryService) ShareQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.shareQuery(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) UnshareQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	return s.unshareQuery(ctx, user, UID, teamID)
+}
+
+func (s QueryHistoryService) GetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64) (RetentionPolicy, error) {
+	return s.getRetentionPolicy(ctx, orgID)
+}
+
+func (s QueryHistoryService) SetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64, cmd SetRetentionPolicyCommand) error {
+	return s.setRetentionPolicy(ctx, orgID, cmd)
+}
+
+func (s QueryHistoryService) ResetRetentionPolicyInQueryHistory(ctx context.Context, orgID int64) error {
+	return s.resetRetentionPolicy(ctx, orgID)
+}
+
+func (s QueryHistoryService) ApplyRetentionPoliciesInQueryHistory(ctx context.Context) (int, error) {
+	return s.applyRetentionPolicies(ctx)
+}
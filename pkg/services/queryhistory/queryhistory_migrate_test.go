@@ -117,4 +117,33 @@ func TestIntegrationMigrateQueriesToQueryHistory(t *testing.T) {
 			require.Equal(t, 2, response.TotalCount)
 			require.Equal(t, 1, response.StarredCount)
 		})
+
+	testScenario(t, "When users tries to migrate the same queries twice, already migrated queries should be skipped",
+		func(t *testing.T, sc scenarioContext) {
+			command := MigrateQueriesToQueryHistoryCommand{
+				Queries: []QueryToMigrate{
+					{
+						DatasourceUID: "NCzh67i",
+						Queries: simplejson.NewFromAny(map[string]interface{}{
+							"expr": "test1",
+						}),
+						Comment:   "",
+						Starred:   true,
+						CreatedAt: time.Now().Unix(),
+					},
+				},
+			}
+			sc.reqContext.Req.Body = mockRequestBody(command)
+			resp := sc.service.migrateHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			sc.reqContext.Req.Body = mockRequestBody(command)
+			resp = sc.service.migrateHandler(sc.reqContext)
+			var response QueryHistoryMigrationResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 0, response.TotalCount)
+			require.Equal(t, 0, response.StarredCount)
+		})
 }
@@ -0,0 +1,93 @@
+package queryhistory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationQueryHistoryRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenario(t, "When organization has no retention, the instance default is returned",
+		func(t *testing.T, sc scenarioContext) {
+			sc.service.Cfg.QueryHistoryRetentionDays = 14
+
+			policy, err := sc.service.GetRetentionPolicyInQueryHistory(context.Background(), testOrgID)
+			require.NoError(t, err)
+			require.Equal(t, RetentionPolicy{Days: 14, IsDefault: true}, policy)
+		})
+
+	testScenario(t, "When users tries to set invalid retention, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.Req.Body = mockRequestBody(SetRetentionPolicyCommand{Days: 0})
+			resp := sc.service.setRetentionHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenario(t, "Organization retention can be set and reset",
+		func(t *testing.T, sc scenarioContext) {
+			sc.service.Cfg.QueryHistoryRetentionDays = 14
+
+			err := sc.service.SetRetentionPolicyInQueryHistory(context.Background(), testOrgID, SetRetentionPolicyCommand{Days: 30})
+			require.NoError(t, err)
+			policy, err := sc.service.GetRetentionPolicyInQueryHistory(context.Background(), testOrgID)
+			require.NoError(t, err)
+			require.Equal(t, RetentionPolicy{Days: 30}, policy)
+
+			err = sc.service.ResetRetentionPolicyInQueryHistory(context.Background(), testOrgID)
+			require.NoError(t, err)
+			policy, err = sc.service.GetRetentionPolicyInQueryHistory(context.Background(), testOrgID)
+			require.NoError(t, err)
+			require.True(t, policy.IsDefault)
+		})
+
+	testScenario(t, "Organization retention overrides the instance default in cleanup",
+		func(t *testing.T, sc scenarioContext) {
+			sc.service.Cfg.QueryHistoryRetentionDays = 14
+			migrateOldQuery(t, sc, 20)
+
+			err := sc.service.SetRetentionPolicyInQueryHistory(context.Background(), testOrgID, SetRetentionPolicyCommand{Days: 30})
+			require.NoError(t, err)
+			rowsDeleted, err := sc.service.ApplyRetentionPoliciesInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+
+			err = sc.service.SetRetentionPolicyInQueryHistory(context.Background(), testOrgID, SetRetentionPolicyCommand{Days: 7})
+			require.NoError(t, err)
+			rowsDeleted, err = sc.service.ApplyRetentionPoliciesInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, rowsDeleted)
+		})
+
+	testScenario(t, "Instance default retention is applied to organizations without override",
+		func(t *testing.T, sc scenarioContext) {
+			sc.service.Cfg.QueryHistoryRetentionDays = 14
+			migrateOldQuery(t, sc, 20)
+
+			rowsDeleted, err := sc.service.ApplyRetentionPoliciesInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, rowsDeleted)
+		})
+}
+
+func migrateOldQuery(t *testing.T, sc scenarioContext, ageInDays int) {
+	t.Helper()
+
+	_, _, err := sc.service.MigrateQueriesToQueryHistory(context.Background(), sc.reqContext.SignedInUser, MigrateQueriesToQueryHistoryCommand{
+		Queries: []QueryToMigrate{
+			{
+				DatasourceUID: testDsUID1,
+				Queries: simplejson.NewFromAny(map[string]interface{}{
+					"expr": "test",
+				}),
+				CreatedAt: time.Now().Add(-time.Hour * 24 * time.Duration(ageInDays)).Unix(),
+			},
+		},
+	})
+	require.NoError(t, err)
+}
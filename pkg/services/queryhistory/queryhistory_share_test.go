@@ -0,0 +1,100 @@
+package queryhistory
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationShareQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When users tries to share query with a team they are a member of, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			team := createTestTeam(t, sc, true)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users tries to share query with a team they are not a member of, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			team := createTestTeam(t, sc, false)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users tries to share query that was already shared with the team, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			team := createTestTeam(t, sc, true)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			sc.service.shareHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 409, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "Shared query is returned when searching for shared queries and survives cleanup",
+		func(t *testing.T, sc scenarioContext) {
+			team := createTestTeam(t, sc, true)
+
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			require.NoError(t, err)
+
+			result, err := sc.service.SearchInQueryHistory(context.Background(), sc.reqContext.SignedInUser, SearchInQueryHistoryQuery{
+				OnlyShared: true,
+				From:       1,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, result.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, result.QueryHistory[0].UID)
+
+			rowsDeleted, err := sc.service.DeleteStaleQueriesInQueryHistory(context.Background(), sc.initialResult.Result.CreatedAt+60)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users tries to unshare query, it should succeed only once",
+		func(t *testing.T, sc scenarioContext) {
+			team := createTestTeam(t, sc, true)
+
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{TeamID: team.Id})
+			require.NoError(t, err)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{
+				":uid":    sc.initialResult.Result.UID,
+				":teamId": strconv.FormatInt(team.Id, 10),
+			})
+			resp := sc.service.unshareHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.unshareHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+}
+
+func createTestTeam(t *testing.T, sc scenarioContext, withMember bool) models.Team {
+	t.Helper()
+
+	team, err := sc.sqlStore.CreateTeam("query-history-team", "", testOrgID)
+	require.NoError(t, err)
+
+	if withMember {
+		err = sc.sqlStore.AddTeamMember(testUserID, testOrgID, team.Id, false, 0)
+		require.NoError(t, err)
+	}
+
+	return team
+}
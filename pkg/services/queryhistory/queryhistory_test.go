@@ -12,6 +12,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -47,6 +48,7 @@ func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioCo
 		service := QueryHistoryService{
 			Cfg:      setting.NewCfg(),
 			SQLStore: sqlStore,
+			KVStore:  kvstore.ProvideService(sqlStore),
 		}
 
 		service.Cfg.QueryHistoryEnabled = true
@@ -0,0 +1,91 @@
+package queryhistory
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const (
+	retentionNamespace = "query-history"
+	retentionKey       = "retention-days"
+)
+
+// getRetentionPolicy returns the retention of the organization, falling back to the instance default
+func (s QueryHistoryService) getRetentionPolicy(ctx context.Context, orgID int64) (RetentionPolicy, error) {
+	value, exists, err := s.KVStore.Get(ctx, orgID, retentionNamespace, retentionKey)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	if !exists {
+		return RetentionPolicy{Days: s.Cfg.QueryHistoryRetentionDays, IsDefault: true}, nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+
+	return RetentionPolicy{Days: days}, nil
+}
+
+func (s QueryHistoryService) setRetentionPolicy(ctx context.Context, orgID int64, cmd SetRetentionPolicyCommand) error {
+	if cmd.Days <= 0 {
+		return ErrInvalidRetention
+	}
+
+	return s.KVStore.Set(ctx, orgID, retentionNamespace, retentionKey, strconv.Itoa(cmd.Days))
+}
+
+func (s QueryHistoryService) resetRetentionPolicy(ctx context.Context, orgID int64) error {
+	return s.KVStore.Del(ctx, orgID, retentionNamespace, retentionKey)
+}
+
+// applyRetentionPolicies is run in scheduled cleanup and it removes unstarred and unshared queries
+// that are older than the retention of their organization
+func (s QueryHistoryService) applyRetentionPolicies(ctx context.Context) (int, error) {
+	keys, err := s.KVStore.Keys(ctx, kvstore.AllOrganizations, retentionNamespace, retentionKey)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	overridden := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		policy, err := s.getRetentionPolicy(ctx, key.OrgId)
+		if err != nil {
+			s.log.Error("Failed to read query history retention", "orgId", key.OrgId, "error", err)
+			continue
+		}
+		overridden = append(overridden, key.OrgId)
+
+		rowsCount, err := s.deleteStaleQueriesWhere(ctx, retentionCutoff(policy.Days), " AND query_history.org_id = ?", []interface{}{key.OrgId})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += rowsCount
+	}
+
+	// A non-positive instance default keeps queries of the remaining organizations forever
+	if s.Cfg.QueryHistoryRetentionDays <= 0 {
+		return deleted, nil
+	}
+
+	condition := ""
+	if len(overridden) > 0 {
+		condition = " AND query_history.org_id NOT IN (?" + strings.Repeat(",?", len(overridden)-1) + ")"
+	}
+	rowsCount, err := s.deleteStaleQueriesWhere(ctx, retentionCutoff(s.Cfg.QueryHistoryRetentionDays), condition, overridden)
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted + rowsCount, nil
+}
+
+func retentionCutoff(days int) int64 {
+	return time.Now().Add(-time.Hour * 24 * time.Duration(days)).Unix()
+}
@@ -8,24 +8,35 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
-func writeStarredSQL(query SearchInQueryHistoryQuery, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
+func writeStarredSQL(query SearchInQueryHistoryQuery, user *models.SignedInUser, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
 	if query.OnlyStarred {
-		builder.Write(sqlStore.Dialect.BooleanStr(true) + ` AS starred
+		builder.Write(sqlStore.Dialect.BooleanStr(true)+` AS starred
 				FROM query_history
-				INNER JOIN query_history_star ON query_history_star.query_uid = query_history.uid 
-				`)
+				INNER JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ?
+				`, user.UserId)
 	} else {
-		builder.Write(` CASE WHEN query_history_star.query_uid IS NULL THEN ` + sqlStore.Dialect.BooleanStr(false) + ` ELSE ` + sqlStore.Dialect.BooleanStr(true) + ` END AS starred
+		builder.Write(` CASE WHEN query_history_star.query_uid IS NULL THEN `+sqlStore.Dialect.BooleanStr(false)+` ELSE `+sqlStore.Dialect.BooleanStr(true)+` END AS starred
 				FROM query_history
-				LEFT JOIN query_history_star ON query_history_star.query_uid = query_history.uid 
-				`)
+				LEFT JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ?
+				`, user.UserId)
 	}
 }
 
 func writeFiltersSQL(query SearchInQueryHistoryQuery, user *models.SignedInUser, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
 	params := []interface{}{user.OrgId, user.UserId, query.From, query.To, "%" + query.SearchString + "%", "%" + query.SearchString + "%"}
 	var sql bytes.Buffer
-	sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_by = ? AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.Dialect.LikeStr() + " ? OR query_history.comment " + sqlStore.Dialect.LikeStr() + " ?) ")
+	sql.WriteString(" WHERE query_history.org_id = ? ")
+	if query.OnlyShared {
+		// Queries shared with any of the teams the user is a member of
+		sql.WriteString(`AND query_history.uid IN (
+			SELECT query_history_team_star.query_uid FROM query_history_team_star
+			INNER JOIN team_member ON team_member.team_id = query_history_team_star.team_id
+			WHERE team_member.user_id = ?
+		) `)
+	} else {
+		sql.WriteString("AND query_history.created_by = ? ")
+	}
+	sql.WriteString("AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.Dialect.LikeStr() + " ? OR query_history.comment " + sqlStore.Dialect.LikeStr() + " ?) ")
 
 	if len(query.DatasourceUIDs) > 0 {
 		for _, uid := range query.DatasourceUIDs {
@@ -0,0 +1,84 @@
+package querypolicy
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrQueryNotAllowed   = errors.New("query is not saved in the dashboard")
+	ErrDashboardRequired = errors.New("queries must be run from a dashboard")
+	// ErrDataSourceCallNotAllowed is returned for data source proxy and
+	// resource calls, which cannot be matched against saved queries.
+	ErrDataSourceCallNotAllowed = errors.New("data source proxy and resource calls are not allowed by the query policy")
+)
+
+// OrgQueryPolicy restricts the queries the users of an organization can run
+// through the query API.
+type OrgQueryPolicy struct {
+	// RestrictViewersToSavedQueries only lets viewers run the queries saved in
+	// the panels, template variables and annotations of the dashboards they can
+	// view, with their template variables interpolated.
+	RestrictViewersToSavedQueries bool `json:"restrictViewersToSavedQueries"`
+	// DenyViewersDataSourceCalls rejects the data source proxy and resource
+	// calls of viewers. Restricting viewers to saved queries doesn't cover
+	// them, since their requests are forwarded as is to the data source, but
+	// data sources that query or look up metadata through them then stop
+	// working for viewers.
+	DenyViewersDataSourceCalls bool `json:"denyViewersDataSourceCalls"`
+}
+
+type GetQueryPolicyQuery struct {
+	OrgID int64
+}
+
+type SaveQueryPolicyCommand struct {
+	OrgID  int64
+	Policy OrgQueryPolicy
+}
+
+// ValidateQueriesCommand holds the queries of a request and the dashboard,
+// and optionally the panel, they are run for.
+type ValidateQueriesCommand struct {
+	DashboardUID string
+	DashboardID  int64
+	PanelID      int64
+	From         string
+	To           string
+	Queries      []*simplejson.Json
+}
+
+// MatchQueriesCommand holds the queries of a request and the dashboard whose
+// saved queries they are matched against.
+type MatchQueriesCommand struct {
+	Dashboard *models.Dashboard
+	From      string
+	To        string
+	Queries   []*simplejson.Json
+}
+
+// QueryMatch tells which queries saved in a dashboard a requested query is.
+type QueryMatch struct {
+	RefID string
+	// PanelIDs are the ids of the panels the query is saved in.
+	PanelIDs []int64
+	// Other is true when the query is saved in a template variable or annotation.
+	Other bool
+}
+
+// Matched returns true if the query matches a saved query.
+func (m *QueryMatch) Matched() bool {
+	return len(m.PanelIDs) > 0 || m.Other
+}
+
+// InPanel returns true if the query is saved in the panel.
+func (m *QueryMatch) InPanel(panelID int64) bool {
+	for _, id := range m.PanelIDs {
+		if id == panelID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package querypolicy
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service manages the query policy of each organization and enforces it on the
+// queries run through the query API, and on the data source proxy and resource
+// calls.
+type Service interface {
+	// Get returns the policy of the organization, which allows all queries
+	// unless it was changed.
+	Get(ctx context.Context, query *GetQueryPolicyQuery) (*OrgQueryPolicy, error)
+	Save(ctx context.Context, cmd *SaveQueryPolicyCommand) error
+	// ValidateQueries returns ErrQueryNotAllowed or ErrDashboardRequired if the
+	// policy of the organization of the user doesn't allow running the queries.
+	ValidateQueries(ctx context.Context, user *models.SignedInUser, cmd *ValidateQueriesCommand) error
+	// MatchQueries matches each query against the queries saved in the
+	// dashboard, regardless of the policy. It doesn't check that the user can
+	// view the dashboard or its panels.
+	MatchQueries(ctx context.Context, user *models.SignedInUser, cmd *MatchQueriesCommand) ([]*QueryMatch, error)
+	// ValidateDataSourceCall returns ErrDataSourceCallNotAllowed if the policy
+	// of the organization of the user doesn't allow data source proxy and
+	// resource calls.
+	ValidateDataSourceCall(ctx context.Context, user *models.SignedInUser) error
+}
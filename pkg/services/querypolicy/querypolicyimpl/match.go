@@ -0,0 +1,352 @@
+package querypolicyimpl
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+)
+
+// runtimeKeys are set by the frontend when running a query, they are not saved
+// in the dashboard. The data source is checked separately.
+var runtimeKeys = map[string]bool{
+	"datasource":      true,
+	"datasourceId":    true,
+	"intervalMs":      true,
+	"maxDataPoints":   true,
+	"requestId":       true,
+	"utcOffsetSec":    true,
+	"queryCachingTTL": true,
+	"hide":            true,
+	"key":             true,
+}
+
+// savedQuery is a query saved in a dashboard with the data source it runs on,
+// which is the data source of its panel unless the query sets one.
+type savedQuery struct {
+	query      map[string]interface{}
+	datasource interface{}
+}
+
+// panelQueries are the queries saved in a panel.
+type panelQueries struct {
+	id      int64
+	queries []savedQuery
+}
+
+// savedQueries returns the queries of the panel, or all the queries of the
+// dashboard when the panel isn't found.
+func savedQueries(data *simplejson.Json, panelID int64) []savedQuery {
+	panels, other := dashboardQueries(data)
+	if panelID != 0 {
+		for _, panel := range panels {
+			if panel.id == panelID {
+				return panel.queries
+			}
+		}
+	}
+
+	var result []savedQuery
+	for _, panel := range panels {
+		result = append(result, panel.queries...)
+	}
+	return append(result, other...)
+}
+
+// dashboardQueries returns the queries saved in the panels of the dashboard,
+// including the panels of rows, and the queries of its template variables and
+// annotations.
+func dashboardQueries(data *simplejson.Json) ([]panelQueries, []savedQuery) {
+	var panels []map[string]interface{}
+	var collect func(list []interface{})
+	collect = func(list []interface{}) {
+		for _, p := range list {
+			panel := toObject(p)
+			panels = append(panels, panel)
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				collect(nested)
+			}
+		}
+	}
+	collect(data.Get("panels").MustArray())
+	for _, row := range data.Get("rows").MustArray() {
+		if nested, ok := toObject(row)["panels"].([]interface{}); ok {
+			collect(nested)
+		}
+	}
+
+	result := make([]panelQueries, 0, len(panels))
+	for _, panel := range panels {
+		id, _ := toNumber(panel["id"])
+		saved := panelQueries{id: int64(id)}
+		targets, _ := panel["targets"].([]interface{})
+		for _, t := range targets {
+			target := toObject(t)
+			ds := target["datasource"]
+			if ds == nil {
+				ds = panel["datasource"]
+			}
+			saved.queries = append(saved.queries, savedQuery{query: target, datasource: ds})
+		}
+		result = append(result, saved)
+	}
+
+	var other []savedQuery
+	for _, v := range data.Get("templating").Get("list").MustArray() {
+		variable := toObject(v)
+		if query, ok := variable["query"].(map[string]interface{}); ok {
+			other = append(other, savedQuery{query: query, datasource: variable["datasource"]})
+		}
+	}
+	for _, a := range data.Get("annotations").Get("list").MustArray() {
+		annotation := toObject(a)
+		if target, ok := annotation["target"].(map[string]interface{}); ok {
+			other = append(other, savedQuery{query: target, datasource: annotation["datasource"]})
+		}
+	}
+	return result, other
+}
+
+// matcher checks that requested queries are saved queries with their template
+// variables interpolated. The options of the variables and the data sources are
+// only looked up when a saved query needs them.
+type matcher struct {
+	resolveOptions func() (map[string][]string, error)
+	dataSourceUID  func(ref string) (string, error)
+
+	options  map[string][]string
+	patterns map[string]*regexp.Regexp
+	uids     map[string]string
+}
+
+func (m *matcher) matchAny(saved []savedQuery, requested map[string]interface{}) (bool, error) {
+	for _, s := range saved {
+		found, err := m.matchQuery(s, requested)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+func (m *matcher) matchQuery(saved savedQuery, requested map[string]interface{}) (bool, error) {
+	found, err := m.matchObject(saved.query, requested, runtimeKeys)
+	if err != nil || !found {
+		return false, err
+	}
+
+	requestedUID, _ := toObject(requested["datasource"])["uid"].(string)
+	uids, err := m.dataSourceUIDs(saved.datasource)
+	if err != nil {
+		return false, err
+	}
+	for _, uid := range uids {
+		if uid == requestedUID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dataSourceUIDs returns the uids of the data sources a saved reference can
+// resolve to, depending on the value of the template variables it references.
+func (m *matcher) dataSourceUIDs(ref interface{}) ([]string, error) {
+	var name string
+	switch r := ref.(type) {
+	case string:
+		name = r
+	case map[string]interface{}:
+		name, _ = r["uid"].(string)
+	}
+
+	candidates := []string{name}
+	if references := userVariables(name); len(references) > 0 {
+		options, err := m.variableOptions()
+		if err != nil {
+			return nil, err
+		}
+		candidates = nil
+		for _, variable := range references {
+			for _, value := range options[variable] {
+				candidates = append(candidates, dashboardvariables.Interpolate(name, map[string][]string{variable: {value}}))
+			}
+		}
+	}
+
+	uids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		uid, ok := m.uids[c]
+		if !ok {
+			var err error
+			if uid, err = m.dataSourceUID(c); err != nil {
+				return nil, err
+			}
+			if m.uids == nil {
+				m.uids = map[string]string{}
+			}
+			m.uids[c] = uid
+		}
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
+
+// matchObject matches the values of the saved keys, keys missing on one side
+// only match empty values.
+func (m *matcher) matchObject(saved, requested map[string]interface{}, ignored map[string]bool) (bool, error) {
+	for key, value := range saved {
+		if ignored[key] {
+			continue
+		}
+		requestedValue, ok := requested[key]
+		if !ok {
+			if isEmpty(value) {
+				continue
+			}
+			return false, nil
+		}
+		found, err := m.matchValue(value, requestedValue)
+		if err != nil || !found {
+			return false, err
+		}
+	}
+	for key, value := range requested {
+		if _, ok := saved[key]; !ok && !ignored[key] && !isEmpty(value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *matcher) matchValue(saved, requested interface{}) (bool, error) {
+	switch s := saved.(type) {
+	case string:
+		r, ok := requested.(string)
+		if !ok {
+			return false, nil
+		}
+		return m.matchString(s, r)
+	case map[string]interface{}:
+		r, ok := requested.(map[string]interface{})
+		if !ok {
+			return false, nil
+		}
+		return m.matchObject(s, r, nil)
+	case []interface{}:
+		r, ok := requested.([]interface{})
+		if !ok || len(r) != len(s) {
+			return false, nil
+		}
+		for i := range s {
+			found, err := m.matchValue(s[i], r[i])
+			if err != nil || !found {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	if saved == nil || requested == nil {
+		return isEmpty(saved) && isEmpty(requested), nil
+	}
+	sn, sok := toNumber(saved)
+	rn, rok := toNumber(requested)
+	if sok || rok {
+		return sok && rok && sn == rn, nil
+	}
+	return reflect.DeepEqual(saved, requested), nil
+}
+
+func (m *matcher) matchString(saved, requested string) (bool, error) {
+	if saved == requested {
+		return true, nil
+	}
+	if !strings.ContainsAny(saved, "$[") {
+		return false, nil
+	}
+
+	re, ok := m.patterns[saved]
+	if !ok {
+		var options map[string][]string
+		var err error
+		if len(userVariables(saved)) > 0 {
+			if options, err = m.variableOptions(); err != nil {
+				return false, err
+			}
+		}
+		if re, err = dashboardvariables.Pattern(saved, options); err != nil {
+			return false, err
+		}
+		if m.patterns == nil {
+			m.patterns = map[string]*regexp.Regexp{}
+		}
+		m.patterns[saved] = re
+	}
+	return re.MatchString(requested), nil
+}
+
+func (m *matcher) variableOptions() (map[string][]string, error) {
+	if m.options == nil {
+		options, err := m.resolveOptions()
+		if err != nil {
+			return nil, err
+		}
+		m.options = options
+	}
+	return m.options, nil
+}
+
+// userVariables returns the variables referenced in text that are not built in.
+func userVariables(text string) []string {
+	var names []string
+	for _, name := range dashboardvariables.References(text) {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func toObject(v interface{}) map[string]interface{} {
+	if o, ok := v.(map[string]interface{}); ok {
+		return o
+	}
+	return map[string]interface{}{}
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func isEmpty(v interface{}) bool {
+	if n, ok := toNumber(v); ok {
+		return n == 0
+	}
+	switch e := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !e
+	case string:
+		return e == ""
+	case []interface{}:
+		return len(e) == 0
+	case map[string]interface{}:
+		return len(e) == 0
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package querypolicyimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const testDashboard = `{
+	"panels": [
+		{
+			"id": 1,
+			"datasource": {"type": "prometheus", "uid": "prom"},
+			"targets": [{"refId": "A", "expr": "up{job=\"$job\"}", "exemplar": false}]
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"panels": [
+				{
+					"id": 3,
+					"datasource": {"type": "datasource", "uid": "-- Mixed --"},
+					"targets": [
+						{"refId": "A", "datasource": {"type": "loki", "uid": "${logs}"}, "expr": "{job=\"$job\"} |= \"error\""},
+						{"refId": "B", "datasource": {"type": "__expr__", "uid": "__expr__"}, "type": "math", "expression": "$A * 2"}
+					]
+				}
+			]
+		}
+	],
+	"templating": {
+		"list": [
+			{"name": "job", "type": "custom", "multi": true},
+			{"name": "logs", "type": "datasource"},
+			{"name": "hosts", "type": "query", "datasource": "Prometheus", "query": {"refId": "PrometheusVariableQueryEditor-VariableQuery", "query": "label_values(host)"}}
+		]
+	},
+	"annotations": {
+		"list": [
+			{"name": "Deployments", "target": {"refId": "Anno", "expr": "deployments"}}
+		]
+	}
+}`
+
+func TestMatcher(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+
+	resolved := 0
+	newMatcher := func() *matcher {
+		return &matcher{
+			resolveOptions: func() (map[string][]string, error) {
+				resolved++
+				return map[string][]string{"job": {"api", "web"}, "logs": {"Loki"}}, nil
+			},
+			dataSourceUID: func(ref string) (string, error) {
+				switch ref {
+				case "", "Prometheus":
+					return "prom", nil
+				case "Loki":
+					return "loki", nil
+				}
+				return ref, nil
+			},
+		}
+	}
+	query := func(q string) map[string]interface{} {
+		j, err := simplejson.NewJson([]byte(q))
+		require.NoError(t, err)
+		return j.MustMap()
+	}
+
+	tests := []struct {
+		name    string
+		panelID int64
+		query   string
+		allowed bool
+	}{
+		{
+			name:    "interpolated panel query",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api,web\"}", "datasource": {"uid": "prom"}, "intervalMs": 15000, "maxDataPoints": 500}`,
+			allowed: true,
+		},
+		{
+			name:    "empty values not saved",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api\"}", "datasource": {"uid": "prom"}, "instant": false}`,
+			allowed: true,
+		},
+		{
+			name:    "tampered expression",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "secrets", "datasource": {"uid": "prom"}}`,
+		},
+		{
+			name:    "value that is not an option",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api\"} or secrets{job=\"api\"}", "datasource": {"uid": "prom"}}`,
+		},
+		{
+			name:    "added field",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api\"}", "datasource": {"uid": "prom"}, "instant": true}`,
+		},
+		{
+			name:    "changed field",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api\"}", "datasource": {"uid": "prom"}, "exemplar": true}`,
+		},
+		{
+			name:    "other data source",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "up{job=\"api\"}", "datasource": {"uid": "other"}}`,
+		},
+		{
+			name:    "query of another panel",
+			panelID: 1,
+			query:   `{"refId": "A", "expr": "{job=\"web\"} |= \"error\"", "datasource": {"uid": "loki"}}`,
+		},
+		{
+			name:    "data source variable in a row",
+			panelID: 3,
+			query:   `{"refId": "A", "expr": "{job=\"web\"} |= \"error\"", "datasource": {"uid": "loki"}}`,
+			allowed: true,
+		},
+		{
+			name:    "expression",
+			panelID: 3,
+			query:   `{"refId": "B", "type": "math", "expression": "$A * 2", "datasource": {"type": "__expr__", "uid": "__expr__"}}`,
+			allowed: true,
+		},
+		{
+			name:    "unknown panel matches all queries",
+			panelID: 42,
+			query:   `{"refId": "A", "expr": "{job=\"api\"} |= \"error\"", "datasource": {"uid": "loki"}}`,
+			allowed: true,
+		},
+		{
+			name:    "template variable query",
+			query:   `{"refId": "PrometheusVariableQueryEditor-VariableQuery", "query": "label_values(host)", "datasource": {"uid": "prom"}}`,
+			allowed: true,
+		},
+		{
+			name:    "annotation query on the default data source",
+			query:   `{"refId": "Anno", "expr": "deployments", "datasource": {"uid": "prom"}}`,
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := newMatcher().matchAny(savedQueries(data, tt.panelID), query(tt.query))
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, found)
+		})
+	}
+
+	t.Run("options are resolved once", func(t *testing.T) {
+		resolved = 0
+		m := newMatcher()
+		saved := savedQueries(data, 0)
+		for _, q := range []string{
+			`{"refId": "A", "expr": "up{job=\"api\"}", "datasource": {"uid": "prom"}}`,
+			`{"refId": "A", "expr": "up{job=\"web\"}", "datasource": {"uid": "prom"}}`,
+		} {
+			found, err := m.matchAny(saved, query(q))
+			require.NoError(t, err)
+			require.True(t, found)
+		}
+		require.Equal(t, 1, resolved)
+	})
+
+	t.Run("queries are grouped by panel", func(t *testing.T) {
+		panels, other := dashboardQueries(data)
+		ids := make([]int64, 0, len(panels))
+		for _, panel := range panels {
+			ids = append(ids, panel.id)
+		}
+		require.Equal(t, []int64{1, 2, 3}, ids)
+		require.Len(t, panels[2].queries, 2)
+		require.Len(t, other, 2)
+	})
+}
@@ -0,0 +1,253 @@
+package querypolicyimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardvariables"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+)
+
+const (
+	kvNamespace = "query-policy"
+	kvKey       = "org"
+	// cacheTTL bounds how long other instances keep enforcing an outdated
+	// policy after a change.
+	cacheTTL = time.Minute
+)
+
+// Service stores the query policy of each organization as a JSON document in
+// the key-value store and caches it, since it is read for every query.
+type Service struct {
+	kv                kvstore.KVStore
+	cache             *localcache.CacheService
+	dashboardService  dashboards.DashboardService
+	variables         dashboardvariables.Service
+	dataSourceService datasources.DataSourceService
+	log               log.Logger
+}
+
+func ProvideService(kv kvstore.KVStore, cache *localcache.CacheService, dashboardService dashboards.DashboardService,
+	variables dashboardvariables.Service, dataSourceService datasources.DataSourceService) querypolicy.Service {
+	return &Service{
+		kv:                kv,
+		cache:             cache,
+		dashboardService:  dashboardService,
+		variables:         variables,
+		dataSourceService: dataSourceService,
+		log:               log.New("querypolicy"),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *querypolicy.GetQueryPolicyQuery) (*querypolicy.OrgQueryPolicy, error) {
+	key := cacheKey(query.OrgID)
+	if cached, ok := s.cache.Get(key); ok {
+		policy := *cached.(*querypolicy.OrgQueryPolicy)
+		return &policy, nil
+	}
+
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	policy := &querypolicy.OrgQueryPolicy{}
+	if ok {
+		if err := json.Unmarshal([]byte(raw), policy); err != nil {
+			return nil, err
+		}
+	}
+
+	s.cache.Set(key, policy, cacheTTL)
+	result := *policy
+	return &result, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *querypolicy.SaveQueryPolicyCommand) error {
+	raw, err := json.Marshal(cmd.Policy)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, cmd.OrgID, kvNamespace, kvKey, string(raw)); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	return nil
+}
+
+func (s *Service) ValidateDataSourceCall(ctx context.Context, user *models.SignedInUser) error {
+	if user.OrgRole != models.ROLE_VIEWER || user.IsGrafanaAdmin {
+		return nil
+	}
+	policy, err := s.Get(ctx, &querypolicy.GetQueryPolicyQuery{OrgID: user.OrgId})
+	if err != nil {
+		return err
+	}
+	if policy.DenyViewersDataSourceCalls {
+		return querypolicy.ErrDataSourceCallNotAllowed
+	}
+	return nil
+}
+
+// ValidateQueries checks that each query matches a query saved in the dashboard.
+// The queries of a request for a panel are matched against the queries of the
+// panel. Other requests, and requests for panels that aren't saved such as
+// repeated panels, are matched against all the queries of the dashboard.
+func (s *Service) ValidateQueries(ctx context.Context, user *models.SignedInUser, cmd *querypolicy.ValidateQueriesCommand) error {
+	if user.OrgRole != models.ROLE_VIEWER || user.IsGrafanaAdmin {
+		return nil
+	}
+	policy, err := s.Get(ctx, &querypolicy.GetQueryPolicyQuery{OrgID: user.OrgId})
+	if err != nil {
+		return err
+	}
+	if !policy.RestrictViewersToSavedQueries {
+		return nil
+	}
+	if cmd.DashboardUID == "" && cmd.DashboardID == 0 {
+		return querypolicy.ErrDashboardRequired
+	}
+
+	query := models.GetDashboardQuery{OrgId: user.OrgId, Uid: cmd.DashboardUID, Id: cmd.DashboardID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return fmt.Errorf("%w: dashboard not found", querypolicy.ErrQueryNotAllowed)
+		}
+		return err
+	}
+	dashboard := query.Result
+	canView, err := guardian.New(ctx, dashboard.Id, user.OrgId, user).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return fmt.Errorf("%w: dashboard not found", querypolicy.ErrQueryNotAllowed)
+	}
+
+	m := s.newMatcher(ctx, user, dashboard, cmd.From, cmd.To)
+	// the queries of the panels the viewer can't see are not saved queries for them
+	dashboards.RemoveHiddenPanels(user, dashboard.Data)
+	saved := savedQueries(dashboard.Data, cmd.PanelID)
+	for _, q := range cmd.Queries {
+		requested, err := q.Map()
+		if err != nil {
+			return fmt.Errorf("%w: invalid query", querypolicy.ErrQueryNotAllowed)
+		}
+		found, err := m.matchAny(saved, requested)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: refId %s", querypolicy.ErrQueryNotAllowed, q.Get("refId").MustString())
+		}
+	}
+	return nil
+}
+
+func (s *Service) MatchQueries(ctx context.Context, user *models.SignedInUser, cmd *querypolicy.MatchQueriesCommand) ([]*querypolicy.QueryMatch, error) {
+	m := s.newMatcher(ctx, user, cmd.Dashboard, cmd.From, cmd.To)
+	panels, other := dashboardQueries(cmd.Dashboard.Data)
+	matches := make([]*querypolicy.QueryMatch, 0, len(cmd.Queries))
+	for _, q := range cmd.Queries {
+		match := &querypolicy.QueryMatch{RefID: q.Get("refId").MustString()}
+		matches = append(matches, match)
+		requested, err := q.Map()
+		if err != nil {
+			continue
+		}
+		for _, panel := range panels {
+			found, err := m.matchAny(panel.queries, requested)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				match.PanelIDs = append(match.PanelIDs, panel.id)
+			}
+		}
+		if match.Other, err = m.matchAny(other, requested); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+func (s *Service) newMatcher(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, from, to string) *matcher {
+	return &matcher{
+		resolveOptions: func() (map[string][]string, error) {
+			return s.variableOptions(ctx, user, dashboard, from, to)
+		},
+		dataSourceUID: func(ref string) (string, error) {
+			return s.dataSourceUID(ctx, user, ref)
+		},
+	}
+}
+
+// variableOptions returns the values the template variables of the dashboard can
+// be interpolated with: their options, their current value and their custom all value.
+func (s *Service) variableOptions(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, from, to string) (map[string][]string, error) {
+	variables, err := s.variables.ResolveVariables(ctx, user, dashboard, dashboardvariables.ResolveVariablesCommand{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string][]string, len(variables))
+	for _, v := range variables {
+		for _, o := range v.Options {
+			if o.Value != dashboardvariables.AllValue {
+				options[v.Name] = append(options[v.Name], o.Value)
+			}
+		}
+		for _, value := range v.Current.Value {
+			if value != dashboardvariables.AllValue {
+				options[v.Name] = append(options[v.Name], value)
+			}
+		}
+	}
+	for _, v := range dashboard.Data.Get("templating").Get("list").MustArray() {
+		variable := toObject(v)
+		name, _ := variable["name"].(string)
+		if allValue, _ := variable["allValue"].(string); name != "" && allValue != "" {
+			options[name] = append(options[name], allValue)
+		}
+	}
+	return options, nil
+}
+
+// dataSourceUID returns the uid of the data source with the uid or name, an empty
+// reference being the default data source. References to unknown data sources,
+// such as expressions, are returned as they are.
+func (s *Service) dataSourceUID(ctx context.Context, user *models.SignedInUser, ref string) (string, error) {
+	if ref == "" || ref == "default" {
+		query := &models.GetDefaultDataSourceQuery{OrgId: user.OrgId, User: user}
+		if err := s.dataSourceService.GetDefaultDataSource(ctx, query); err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				return "", nil
+			}
+			return "", err
+		}
+		return query.Result.Uid, nil
+	}
+
+	query := &models.GetDataSourceQuery{Uid: ref, OrgId: user.OrgId}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err == nil {
+		return query.Result.Uid, nil
+	}
+	query = &models.GetDataSourceQuery{Name: ref, OrgId: user.OrgId}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err == nil {
+		return query.Result.Uid, nil
+	}
+	return ref, nil
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("query-policy-%d", orgID)
+}
@@ -0,0 +1,62 @@
+package querypolicyimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationQueryPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(kvstore.ProvideService(ss), localcache.ProvideService(), nil, nil, nil)
+	ctx := context.Background()
+
+	t.Run("all queries are allowed by default", func(t *testing.T) {
+		policy, err := svc.Get(ctx, &querypolicy.GetQueryPolicyQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.False(t, policy.RestrictViewersToSavedQueries)
+
+		viewer := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		require.NoError(t, svc.ValidateQueries(ctx, viewer, &querypolicy.ValidateQueriesCommand{}))
+		require.NoError(t, svc.ValidateDataSourceCall(ctx, viewer))
+	})
+
+	t.Run("saved policy applies to viewers of its org", func(t *testing.T) {
+		err := svc.Save(ctx, &querypolicy.SaveQueryPolicyCommand{OrgID: 1, Policy: querypolicy.OrgQueryPolicy{RestrictViewersToSavedQueries: true}})
+		require.NoError(t, err)
+
+		policy, err := svc.Get(ctx, &querypolicy.GetQueryPolicyQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.True(t, policy.RestrictViewersToSavedQueries)
+
+		err = svc.ValidateQueries(ctx, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}, &querypolicy.ValidateQueriesCommand{})
+		require.ErrorIs(t, err, querypolicy.ErrDashboardRequired)
+
+		require.NoError(t, svc.ValidateQueries(ctx, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR}, &querypolicy.ValidateQueriesCommand{}))
+		require.NoError(t, svc.ValidateQueries(ctx, &models.SignedInUser{OrgId: 2, OrgRole: models.ROLE_VIEWER}, &querypolicy.ValidateQueriesCommand{}))
+	})
+
+	t.Run("data source calls of viewers are only denied when the policy says so", func(t *testing.T) {
+		viewer := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		require.NoError(t, svc.ValidateDataSourceCall(ctx, viewer))
+
+		err := svc.Save(ctx, &querypolicy.SaveQueryPolicyCommand{OrgID: 1, Policy: querypolicy.OrgQueryPolicy{
+			RestrictViewersToSavedQueries: true,
+			DenyViewersDataSourceCalls:    true,
+		}})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, svc.ValidateDataSourceCall(ctx, viewer), querypolicy.ErrDataSourceCallNotAllowed)
+		require.NoError(t, svc.ValidateDataSourceCall(ctx, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR}))
+	})
+}
@@ -0,0 +1,40 @@
+package querypolicytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/querypolicy"
+)
+
+type FakeQueryPolicyService struct {
+	ExpectedPolicy              *querypolicy.OrgQueryPolicy
+	ExpectedError               error
+	ExpectedValidationError     error
+	ExpectedDataSourceCallError error
+	ExpectedMatches             []*querypolicy.QueryMatch
+}
+
+func NewQueryPolicyServiceFake() *FakeQueryPolicyService {
+	return &FakeQueryPolicyService{ExpectedPolicy: &querypolicy.OrgQueryPolicy{}}
+}
+
+func (f *FakeQueryPolicyService) Get(ctx context.Context, query *querypolicy.GetQueryPolicyQuery) (*querypolicy.OrgQueryPolicy, error) {
+	return f.ExpectedPolicy, f.ExpectedError
+}
+
+func (f *FakeQueryPolicyService) Save(ctx context.Context, cmd *querypolicy.SaveQueryPolicyCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeQueryPolicyService) ValidateQueries(ctx context.Context, user *models.SignedInUser, cmd *querypolicy.ValidateQueriesCommand) error {
+	return f.ExpectedValidationError
+}
+
+func (f *FakeQueryPolicyService) MatchQueries(ctx context.Context, user *models.SignedInUser, cmd *querypolicy.MatchQueriesCommand) ([]*querypolicy.QueryMatch, error) {
+	return f.ExpectedMatches, f.ExpectedError
+}
+
+func (f *FakeQueryPolicyService) ValidateDataSourceCall(ctx context.Context, user *models.SignedInUser) error {
+	return f.ExpectedDataSourceCallError
+}
@@ -0,0 +1,91 @@
+package queryschema
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Field is a field returned by a query, the fields of all the frames of the
+// query with the same name and type are a single field of the schema.
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Schema is a version of the fields returned by a query of a panel.
+type Schema struct {
+	ID            int64     `json:"id"`
+	OrgID         int64     `json:"orgId"`
+	DashboardUID  string    `json:"dashboardUid"`
+	PanelID       int64     `json:"panelId"`
+	RefID         string    `json:"refId"`
+	DataSourceUID string    `json:"datasourceUid"`
+	Fields        []Field   `json:"fields"`
+	Hash          string    `json:"-"`
+	FirstSeen     time.Time `json:"firstSeen"`
+	LastSeen      time.Time `json:"lastSeen"`
+}
+
+// Rename is a field that changed name, keeping its type.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// TypeChange is a field that changed type, keeping its name.
+type TypeChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Drift is the change between two consecutive schemas of a query.
+type Drift struct {
+	DashboardUID  string `json:"dashboardUid"`
+	PanelID       int64  `json:"panelId"`
+	RefID         string `json:"refId"`
+	DataSourceUID string `json:"datasourceUid"`
+	// DetectedAt is when the query first returned the new schema.
+	DetectedAt  time.Time    `json:"detectedAt"`
+	Removed     []Field      `json:"removed"`
+	Added       []Field      `json:"added"`
+	Renamed     []Rename     `json:"renamed"`
+	TypeChanged []TypeChange `json:"typeChanged"`
+	// Breaking is true when fields were removed, renamed or changed type, which
+	// breaks the panels, transformations and overrides referencing them.
+	Breaking bool `json:"breaking"`
+}
+
+// ObserveCommand is the result of the queries of a panel of a dashboard,
+// identified by id or uid.
+type ObserveCommand struct {
+	OrgID        int64
+	DashboardID  int64
+	DashboardUID string
+	PanelID      int64
+	// Queries are the queries of the request, the data source of a result is
+	// the one of the query with the same ref id.
+	Queries  []*simplejson.Json
+	Response *backend.QueryDataResponse
+}
+
+type GetHistoryQuery struct {
+	OrgID        int64
+	DashboardUID string
+	// PanelID and RefID optionally filter the schemas of a panel and query.
+	PanelID int64
+	RefID   string
+}
+
+type GetDriftQuery struct {
+	OrgID int64
+	// DashboardUID optionally filters the drift of a dashboard.
+	DashboardUID string
+	Since        time.Time
+	// BreakingOnly excludes the drift that only added fields.
+	BreakingOnly bool
+}
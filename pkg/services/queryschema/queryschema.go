@@ -0,0 +1,22 @@
+package queryschema
+
+import (
+	"context"
+)
+
+// Service records the fields returned by the queries of dashboard panels over
+// time, to find the queries whose results changed in a way that can break the
+// panels, e.g. fields that disappeared or were renamed.
+type Service interface {
+	// IsDisabled returns true if the schemas aren't recorded.
+	IsDisabled() bool
+	// Observe records the schema of the results of the queries of a panel. It
+	// doesn't block the query, the schemas are saved in the background.
+	Observe(cmd *ObserveCommand)
+	// GetHistory returns the schemas recorded for the queries of a dashboard,
+	// the oldest first.
+	GetHistory(ctx context.Context, query *GetHistoryQuery) ([]*Schema, error)
+	// GetDrift returns the changes of the schemas of queries since a time, the
+	// most recent first.
+	GetDrift(ctx context.Context, query *GetDriftQuery) ([]*Drift, error)
+}
@@ -0,0 +1,337 @@
+package queryschemaimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/queryschema"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// flushInterval is how often the observed schemas are saved.
+	flushInterval = time.Minute
+	// lastSeenInterval is how often the last time an unchanged schema was
+	// returned by a query is saved.
+	lastSeenInterval = time.Hour
+	// driftAnnotationTag tags the annotations of the breaking changes of the
+	// schemas of the queries of critical dashboards.
+	driftAnnotationTag = "schema-drift"
+)
+
+type Service struct {
+	cfg      *setting.Cfg
+	store    store
+	webhooks notifications.WebhookSender
+	log      log.Logger
+	now      func() time.Time
+
+	mtx     sync.Mutex
+	pending map[observationKey]*observation
+	// saved is the hash and save time of the last schema of the queries, to
+	// skip the unchanged schemas until their last seen time is due.
+	saved map[schemaKey]savedSchema
+}
+
+// observationKey identifies a query of a panel of a dashboard, by dashboard
+// id or by uid when the query was run with the uid only.
+type observationKey struct {
+	orgID        int64
+	dashboardID  int64
+	dashboardUID string
+	panelID      int64
+	refID        string
+}
+
+type observation struct {
+	dataSourceUID string
+	fields        []queryschema.Field
+	hash          string
+	seen          time.Time
+}
+
+type schemaKey struct {
+	orgID        int64
+	dashboardUID string
+	panelID      int64
+	refID        string
+}
+
+type savedSchema struct {
+	hash    string
+	savedAt time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, webhooks notifications.WebhookSender) *Service {
+	return &Service{
+		cfg:      cfg,
+		store:    &sqlStore{db: db},
+		webhooks: webhooks,
+		log:      log.New("queryschema"),
+		now:      time.Now,
+		pending:  map[observationKey]*observation{},
+		saved:    map[schemaKey]savedSchema{},
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.QuerySchema.Enabled
+}
+
+func (s *Service) Observe(cmd *queryschema.ObserveCommand) {
+	if !s.cfg.QuerySchema.Enabled || cmd.PanelID == 0 || (cmd.DashboardID == 0 && cmd.DashboardUID == "") || cmd.Response == nil {
+		return
+	}
+
+	dataSources := make(map[string]string, len(cmd.Queries))
+	for _, q := range cmd.Queries {
+		dataSources[q.Get("refId").MustString("A")] = q.Get("datasource").Get("uid").MustString()
+	}
+
+	now := s.now()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for refID, res := range cmd.Response.Responses {
+		// failed and empty results don't tell which fields the query returns
+		if res.Error != nil {
+			continue
+		}
+		fields := fieldsOf(res.Frames)
+		if len(fields) == 0 {
+			continue
+		}
+		key := observationKey{orgID: cmd.OrgID, dashboardID: cmd.DashboardID, panelID: cmd.PanelID, refID: refID}
+		if cmd.DashboardID == 0 {
+			key.dashboardUID = cmd.DashboardUID
+		}
+		s.pending[key] = &observation{
+			dataSourceUID: dataSources[refID],
+			fields:        fields,
+			hash:          hashFields(fields),
+			seen:          now,
+		}
+	}
+}
+
+// Run saves the observed schemas on a schedule and deletes the schemas that
+// haven't been seen within the retention.
+func (s *Service) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	cleanupTicker := time.NewTicker(lastSeenInterval)
+	defer cleanupTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// the server is stopping, keep the schemas of the last interval
+			s.flush(context.Background())
+			return ctx.Err()
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-cleanupTicker.C:
+			deleted, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.QuerySchema.Retention))
+			if err != nil {
+				s.log.Error("Failed to delete expired query schemas", "error", err)
+			} else if deleted > 0 {
+				s.log.Debug("Deleted expired query schemas", "count", deleted)
+			}
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mtx.Lock()
+	pending := s.pending
+	s.pending = map[observationKey]*observation{}
+	s.mtx.Unlock()
+
+	dashboards := map[observationKey]*dashboardRef{}
+	for key, obs := range pending {
+		dashKey := observationKey{orgID: key.orgID, dashboardID: key.dashboardID, dashboardUID: key.dashboardUID}
+		dash, ok := dashboards[dashKey]
+		if !ok {
+			var err error
+			dash, err = s.store.GetDashboard(ctx, key.orgID, key.dashboardID, key.dashboardUID)
+			if err != nil {
+				s.log.Warn("Failed to get the dashboard of the queries", "orgId", key.orgID, "dashboardId", key.dashboardID, "dashboardUid", key.dashboardUID, "error", err)
+				continue
+			}
+			dashboards[dashKey] = dash
+		}
+		if dash == nil {
+			continue
+		}
+		if err := s.save(ctx, key, dash, obs); err != nil {
+			s.log.Error("Failed to save the schema of the query", "orgId", key.orgID, "dashboardUid", dash.UID, "panelId", key.panelID, "refId", key.refID, "error", err)
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, saved := range s.saved {
+		if s.now().Sub(saved.savedAt) >= lastSeenInterval {
+			delete(s.saved, key)
+		}
+	}
+}
+
+func (s *Service) save(ctx context.Context, key observationKey, dash *dashboardRef, obs *observation) error {
+	sk := schemaKey{orgID: key.orgID, dashboardUID: dash.UID, panelID: key.panelID, refID: key.refID}
+	s.mtx.Lock()
+	saved, ok := s.saved[sk]
+	s.mtx.Unlock()
+	if ok && saved.hash == obs.hash && obs.seen.Sub(saved.savedAt) < lastSeenInterval {
+		return nil
+	}
+
+	latest, err := s.store.GetLatest(ctx, key.orgID, dash.UID, key.panelID, key.refID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Hash == obs.hash {
+		if err := s.store.UpdateLastSeen(ctx, latest.ID, obs.seen); err != nil {
+			return err
+		}
+	} else {
+		schema := &queryschema.Schema{
+			OrgID:         key.orgID,
+			DashboardUID:  dash.UID,
+			PanelID:       key.panelID,
+			RefID:         key.refID,
+			DataSourceUID: obs.dataSourceUID,
+			Fields:        obs.fields,
+			Hash:          obs.hash,
+			FirstSeen:     obs.seen,
+			LastSeen:      obs.seen,
+		}
+		if err := s.store.Insert(ctx, schema); err != nil {
+			return err
+		}
+		if latest != nil {
+			if drift := diff(latest, schema); drift != nil && drift.Breaking {
+				s.alert(ctx, key.orgID, dash, drift)
+			}
+		}
+	}
+
+	s.mtx.Lock()
+	s.saved[sk] = savedSchema{hash: obs.hash, savedAt: obs.seen}
+	s.mtx.Unlock()
+	return nil
+}
+
+// driftNotification is the body of the webhook notified of a breaking change.
+type driftNotification struct {
+	OrgID          int64  `json:"orgId"`
+	DashboardTitle string `json:"dashboardTitle"`
+	*queryschema.Drift
+}
+
+// alert annotates the panel of a breaking change of the schema of a query of
+// a critical dashboard, and notifies the webhook.
+func (s *Service) alert(ctx context.Context, orgID int64, dash *dashboardRef, drift *queryschema.Drift) {
+	if !isCritical(dash, s.cfg.QuerySchema.AlertDashboardTags) {
+		return
+	}
+	s.log.Warn("The fields returned by a query of a critical dashboard changed", "orgId", orgID, "dashboardUid", dash.UID,
+		"panelId", drift.PanelID, "refId", drift.RefID, "removed", len(drift.Removed), "renamed", len(drift.Renamed), "typeChanged", len(drift.TypeChanged))
+
+	if repo := annotations.GetRepository(); repo != nil {
+		err := repo.Save(&annotations.Item{
+			OrgId:       orgID,
+			DashboardId: dash.ID,
+			PanelId:     drift.PanelID,
+			Epoch:       drift.DetectedAt.UnixMilli(),
+			EpochEnd:    drift.DetectedAt.UnixMilli(),
+			Text:        driftText(drift),
+			Tags:        []string{driftAnnotationTag},
+		})
+		if err != nil {
+			s.log.Error("Failed to annotate the change of the fields of a query", "dashboardUid", dash.UID, "panelId", drift.PanelID, "error", err)
+		}
+	}
+
+	if s.cfg.QuerySchema.AlertWebhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(driftNotification{OrgID: orgID, DashboardTitle: dash.Title, Drift: drift})
+	if err != nil {
+		s.log.Error("Failed to encode the change of the fields of a query", "error", err)
+		return
+	}
+	err = s.webhooks.SendWebhookSync(ctx, &models.SendWebhookSync{
+		Url:         s.cfg.QuerySchema.AlertWebhookURL,
+		Body:        string(body),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	})
+	if err != nil {
+		s.log.Error("Failed to notify the change of the fields of a query", "dashboardUid", dash.UID, "panelId", drift.PanelID, "error", err)
+	}
+}
+
+func isCritical(dash *dashboardRef, tags []string) bool {
+	for _, tag := range dash.Tags {
+		for _, critical := range tags {
+			if strings.EqualFold(tag, critical) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func driftText(drift *queryschema.Drift) string {
+	changes := make([]string, 0)
+	for _, f := range drift.Removed {
+		changes = append(changes, fmt.Sprintf("%s removed", f.Name))
+	}
+	for _, r := range drift.Renamed {
+		changes = append(changes, fmt.Sprintf("%s renamed to %s", r.From, r.To))
+	}
+	for _, c := range drift.TypeChanged {
+		changes = append(changes, fmt.Sprintf("%s changed from %s to %s", c.Name, c.From, c.To))
+	}
+	return fmt.Sprintf("The fields returned by query %s changed: %s", drift.RefID, strings.Join(changes, ", "))
+}
+
+func (s *Service) GetHistory(ctx context.Context, query *queryschema.GetHistoryQuery) ([]*queryschema.Schema, error) {
+	return s.store.List(ctx, query)
+}
+
+func (s *Service) GetDrift(ctx context.Context, query *queryschema.GetDriftQuery) ([]*queryschema.Drift, error) {
+	schemas, err := s.store.List(ctx, &queryschema.GetHistoryQuery{OrgID: query.OrgID, DashboardUID: query.DashboardUID})
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := make([]*queryschema.Drift, 0)
+	for i := 1; i < len(schemas); i++ {
+		prev, next := schemas[i-1], schemas[i]
+		if prev.DashboardUID != next.DashboardUID || prev.PanelID != next.PanelID || prev.RefID != next.RefID {
+			continue
+		}
+		if next.FirstSeen.Before(query.Since) {
+			continue
+		}
+		drift := diff(prev, next)
+		if drift == nil || (query.BreakingOnly && !drift.Breaking) {
+			continue
+		}
+		drifts = append(drifts, drift)
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		return drifts[i].DetectedAt.After(drifts[j].DetectedAt)
+	})
+	return drifts, nil
+}
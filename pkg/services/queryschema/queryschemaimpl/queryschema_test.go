@@ -0,0 +1,195 @@
+package queryschemaimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/queryschema"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDiff(t *testing.T) {
+	schema := func(fields ...queryschema.Field) *queryschema.Schema {
+		return &queryschema.Schema{DashboardUID: "dash", PanelID: 1, RefID: "A", Fields: fields}
+	}
+	timeField := queryschema.Field{Name: "time", Type: "time"}
+
+	t.Run("unchanged fields", func(t *testing.T) {
+		require.Nil(t, diff(schema(timeField), schema(timeField)))
+	})
+
+	t.Run("added fields don't break panels", func(t *testing.T) {
+		drift := diff(schema(timeField), schema(timeField, queryschema.Field{Name: "value", Type: "number"}))
+		require.NotNil(t, drift)
+		require.False(t, drift.Breaking)
+		require.Equal(t, []queryschema.Field{{Name: "value", Type: "number"}}, drift.Added)
+	})
+
+	t.Run("renamed and removed fields", func(t *testing.T) {
+		drift := diff(
+			schema(queryschema.Field{Name: "host", Type: "string"}, timeField, queryschema.Field{Name: "used", Type: "number"}, queryschema.Field{Name: "free", Type: "number"}),
+			schema(queryschema.Field{Name: "hostname", Type: "string"}, timeField, queryschema.Field{Name: "used_bytes", Type: "number"}),
+		)
+		require.NotNil(t, drift)
+		require.True(t, drift.Breaking)
+		require.Equal(t, []queryschema.Rename{{From: "host", To: "hostname", Type: "string"}}, drift.Renamed)
+		// two number fields were removed, which one was renamed isn't known
+		require.Equal(t, []queryschema.Field{{Name: "used", Type: "number"}, {Name: "free", Type: "number"}}, drift.Removed)
+		require.Equal(t, []queryschema.Field{{Name: "used_bytes", Type: "number"}}, drift.Added)
+	})
+
+	t.Run("type changes", func(t *testing.T) {
+		drift := diff(schema(queryschema.Field{Name: "value", Type: "number"}), schema(queryschema.Field{Name: "value", Type: "string"}))
+		require.NotNil(t, drift)
+		require.True(t, drift.Breaking)
+		require.Equal(t, []queryschema.TypeChange{{Name: "value", From: "number", To: "string"}}, drift.TypeChanged)
+	})
+}
+
+func TestFieldsOf(t *testing.T) {
+	frames := data.Frames{
+		data.NewFrame("a",
+			data.NewField("Time", nil, []time.Time{}),
+			data.NewField("Value", data.Labels{"host": "a"}, []*float64{}),
+		),
+		data.NewFrame("b",
+			data.NewField("Time", nil, []time.Time{}),
+			data.NewField("Value", data.Labels{"host": "b"}, []int64{}),
+			data.NewField("Status", nil, []string{}),
+		),
+	}
+	require.Equal(t, []queryschema.Field{
+		{Name: "Status", Type: "string"},
+		{Name: "Time", Type: "time"},
+		{Name: "Value", Type: "number"},
+	}, fieldsOf(frames))
+}
+
+func TestIntegrationQuerySchemaService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+	dash := insertDashboard(t, ss, "critical", "critical")
+	insertDashboard(t, ss, "other")
+
+	cfg := setting.NewCfg()
+	cfg.QuerySchema.Enabled = true
+	cfg.QuerySchema.AlertDashboardTags = []string{"critical"}
+	cfg.QuerySchema.AlertWebhookURL = "http://example.com/hook"
+	webhooks := notifications.MockNotificationService()
+	s := ProvideService(cfg, ss, webhooks)
+	now := time.Now().Truncate(time.Second)
+	s.now = func() time.Time { return now }
+
+	observe := func(dashboardID int64, dashboardUID string, fields ...*data.Field) {
+		s.Observe(&queryschema.ObserveCommand{
+			OrgID:        1,
+			DashboardID:  dashboardID,
+			DashboardUID: dashboardUID,
+			PanelID:      2,
+			Queries: []*simplejson.Json{
+				simplejson.NewFromAny(map[string]interface{}{"refId": "A", "datasource": map[string]interface{}{"uid": "prom"}}),
+			},
+			Response: &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": backend.DataResponse{Frames: data.Frames{data.NewFrame("", fields...)}},
+				"B": backend.DataResponse{Frames: data.Frames{}},
+			}},
+		})
+		s.flush(ctx)
+	}
+
+	observe(dash.Id, "", data.NewField("time", nil, []time.Time{}), data.NewField("host", nil, []string{}))
+	now = now.Add(2 * time.Hour)
+	observe(0, "critical", data.NewField("time", nil, []time.Time{}), data.NewField("host", nil, []string{}))
+	observe(0, "other", data.NewField("time", nil, []time.Time{}))
+	observe(0, "missing", data.NewField("time", nil, []time.Time{}))
+
+	history, err := s.GetHistory(ctx, &queryschema.GetHistoryQuery{OrgID: 1, DashboardUID: "critical"})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, "prom", history[0].DataSourceUID)
+	require.Equal(t, []queryschema.Field{{Name: "host", Type: "string"}, {Name: "time", Type: "time"}}, history[0].Fields)
+	require.Equal(t, now.Add(-2*time.Hour).Unix(), history[0].FirstSeen.Unix())
+	require.Equal(t, now.Unix(), history[0].LastSeen.Unix())
+	require.Empty(t, webhooks.Webhook.Url)
+
+	now = now.Add(time.Minute)
+	observe(dash.Id, "", data.NewField("time", nil, []time.Time{}), data.NewField("hostname", nil, []string{}))
+
+	history, err = s.GetHistory(ctx, &queryschema.GetHistoryQuery{OrgID: 1, DashboardUID: "critical", PanelID: 2, RefID: "A"})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	drifts, err := s.GetDrift(ctx, &queryschema.GetDriftQuery{OrgID: 1, Since: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+	require.Equal(t, "critical", drifts[0].DashboardUID)
+	require.Equal(t, []queryschema.Rename{{From: "host", To: "hostname", Type: "string"}}, drifts[0].Renamed)
+
+	drifts, err = s.GetDrift(ctx, &queryschema.GetDriftQuery{OrgID: 1, Since: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, drifts)
+
+	t.Run("alerts on breaking changes of critical dashboards", func(t *testing.T) {
+		require.Equal(t, "http://example.com/hook", webhooks.Webhook.Url)
+		notification := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(webhooks.Webhook.Body), &notification))
+		require.Equal(t, "critical", notification["dashboardTitle"])
+		require.Equal(t, true, notification["breaking"])
+
+		items := make([]*annotations.Item, 0)
+		err := ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("dashboard_id = ?", dash.Id).Find(&items)
+		})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, int64(2), items[0].PanelId)
+		require.Equal(t, "The fields returned by query A changed: host renamed to hostname", items[0].Text)
+	})
+
+	t.Run("deletes the schemas no longer seen", func(t *testing.T) {
+		deleted, err := s.store.DeleteOlderThan(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+	})
+}
+
+func insertDashboard(t *testing.T, ss *sqlstore.SQLStore, uid string, tags ...string) *models.Dashboard {
+	t.Helper()
+	dash := &models.Dashboard{
+		OrgId:   1,
+		Uid:     uid,
+		Title:   uid,
+		Slug:    uid,
+		Data:    simplejson.NewFromAny(map[string]interface{}{"title": uid}),
+		Version: 1,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+	err := ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Insert(dash); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if _, err := sess.Insert(&sqlstore.DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return dash
+}
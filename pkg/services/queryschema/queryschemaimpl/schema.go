@@ -0,0 +1,135 @@
+package queryschemaimpl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/queryschema"
+)
+
+// fieldsOf returns the fields of the frames of a query result, sorted by name.
+// The frames of a query often are the series of the same fields with
+// different labels, they have a single schema.
+func fieldsOf(frames data.Frames) []queryschema.Field {
+	seen := map[queryschema.Field]bool{}
+	fields := make([]queryschema.Field, 0)
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		for _, field := range frame.Fields {
+			f := queryschema.Field{Name: field.Name, Type: typeOf(field.Type())}
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Name != fields[j].Name {
+			return fields[i].Name < fields[j].Name
+		}
+		return fields[i].Type < fields[j].Type
+	})
+	return fields
+}
+
+// typeOf returns the type of a field as the frontend sees it, a field changing
+// from int64 to float64 doesn't break the panels.
+func typeOf(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	case t.NonNullableType() == data.FieldTypeString:
+		return "string"
+	case t.NonNullableType() == data.FieldTypeBool:
+		return "boolean"
+	default:
+		return "other"
+	}
+}
+
+func hashFields(fields []queryschema.Field) string {
+	h := sha256.New()
+	for _, f := range fields {
+		h.Write([]byte(f.Name))
+		h.Write([]byte{0})
+		h.Write([]byte(f.Type))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// diff returns the drift from a schema of a query to the next one, nil if the
+// fields didn't change. A field removed and a field added with the same type
+// are a rename, unless several fields of the type were removed or added.
+func diff(prev, next *queryschema.Schema) *queryschema.Drift {
+	prevTypes := make(map[string]string, len(prev.Fields))
+	for _, f := range prev.Fields {
+		prevTypes[f.Name] = f.Type
+	}
+	nextTypes := make(map[string]string, len(next.Fields))
+	for _, f := range next.Fields {
+		nextTypes[f.Name] = f.Type
+	}
+
+	drift := &queryschema.Drift{
+		DashboardUID:  next.DashboardUID,
+		PanelID:       next.PanelID,
+		RefID:         next.RefID,
+		DataSourceUID: next.DataSourceUID,
+		DetectedAt:    next.FirstSeen,
+		Removed:       make([]queryschema.Field, 0),
+		Added:         make([]queryschema.Field, 0),
+		Renamed:       make([]queryschema.Rename, 0),
+		TypeChanged:   make([]queryschema.TypeChange, 0),
+	}
+	removed := make([]queryschema.Field, 0)
+	removedTypes := map[string]int{}
+	for _, f := range prev.Fields {
+		t, ok := nextTypes[f.Name]
+		switch {
+		case !ok:
+			removed = append(removed, f)
+			removedTypes[f.Type]++
+		case t != f.Type:
+			drift.TypeChanged = append(drift.TypeChanged, queryschema.TypeChange{Name: f.Name, From: f.Type, To: t})
+		}
+	}
+	added := make([]queryschema.Field, 0)
+	addedTypes := map[string]int{}
+	for _, f := range next.Fields {
+		if _, ok := prevTypes[f.Name]; !ok {
+			added = append(added, f)
+			addedTypes[f.Type]++
+		}
+	}
+
+	renamedTo := map[string]string{}
+	for _, f := range added {
+		if removedTypes[f.Type] == 1 && addedTypes[f.Type] == 1 {
+			renamedTo[f.Type] = f.Name
+			continue
+		}
+		drift.Added = append(drift.Added, f)
+	}
+	for _, f := range removed {
+		if to, ok := renamedTo[f.Type]; ok {
+			drift.Renamed = append(drift.Renamed, queryschema.Rename{From: f.Name, To: to, Type: f.Type})
+			continue
+		}
+		drift.Removed = append(drift.Removed, f)
+	}
+
+	if len(drift.Removed)+len(drift.Added)+len(drift.Renamed)+len(drift.TypeChanged) == 0 {
+		return nil
+	}
+	drift.Breaking = len(drift.Removed)+len(drift.Renamed)+len(drift.TypeChanged) > 0
+	return drift
+}
@@ -0,0 +1,188 @@
+package queryschemaimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/queryschema"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	// GetDashboard returns nil if the dashboard doesn't exist.
+	GetDashboard(ctx context.Context, orgID, id int64, uid string) (*dashboardRef, error)
+	// GetLatest returns the last schema of a query, nil if none was recorded.
+	GetLatest(ctx context.Context, orgID int64, dashboardUID string, panelID int64, refID string) (*queryschema.Schema, error)
+	Insert(ctx context.Context, schema *queryschema.Schema) error
+	UpdateLastSeen(ctx context.Context, id int64, lastSeen time.Time) error
+	// List returns the schemas of the queries ordered by dashboard, panel, query
+	// and the oldest first.
+	List(ctx context.Context, query *queryschema.GetHistoryQuery) ([]*queryschema.Schema, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
+
+// dashboardRef is the dashboard of the queries of a schema.
+type dashboardRef struct {
+	ID    int64
+	UID   string
+	Title string
+	Tags  []string
+}
+
+// querySchema is a row of the query_schema table, the fields are JSON encoded
+// and the first and last seen times are unix timestamps.
+type querySchema struct {
+	Id            int64
+	OrgId         int64
+	DashboardUid  string
+	PanelId       int64
+	RefId         string
+	DatasourceUid string
+	Fields        string
+	Hash          string
+	FirstSeen     int64
+	LastSeen      int64
+}
+
+func (querySchema) TableName() string {
+	return "query_schema"
+}
+
+func (r *querySchema) toSchema() (*queryschema.Schema, error) {
+	fields := make([]queryschema.Field, 0)
+	if err := json.Unmarshal([]byte(r.Fields), &fields); err != nil {
+		return nil, err
+	}
+	return &queryschema.Schema{
+		ID:            r.Id,
+		OrgID:         r.OrgId,
+		DashboardUID:  r.DashboardUid,
+		PanelID:       r.PanelId,
+		RefID:         r.RefId,
+		DataSourceUID: r.DatasourceUid,
+		Fields:        fields,
+		Hash:          r.Hash,
+		FirstSeen:     time.Unix(r.FirstSeen, 0),
+		LastSeen:      time.Unix(r.LastSeen, 0),
+	}, nil
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) GetDashboard(ctx context.Context, orgID, id int64, uid string) (*dashboardRef, error) {
+	var ref *dashboardRef
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var dash struct {
+			Id    int64
+			Uid   string
+			Title string
+		}
+		sess.Table("dashboard").Cols("id", "uid", "title").Where("org_id = ?", orgID)
+		if id != 0 {
+			sess.And("id = ?", id)
+		} else {
+			sess.And("uid = ?", uid)
+		}
+		exists, err := sess.Get(&dash)
+		if err != nil || !exists {
+			return err
+		}
+
+		ref = &dashboardRef{ID: dash.Id, UID: dash.Uid, Title: dash.Title, Tags: make([]string, 0)}
+		return sess.Table("dashboard_tag").Where("dashboard_id = ?", dash.Id).Cols("term").Find(&ref.Tags)
+	})
+	return ref, err
+}
+
+func (s *sqlStore) GetLatest(ctx context.Context, orgID int64, dashboardUID string, panelID int64, refID string) (*queryschema.Schema, error) {
+	var schema *queryschema.Schema
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		row := querySchema{}
+		exists, err := sess.Where("org_id = ? AND dashboard_uid = ? AND panel_id = ? AND ref_id = ?", orgID, dashboardUID, panelID, refID).
+			Desc("first_seen", "id").Get(&row)
+		if err != nil || !exists {
+			return err
+		}
+		schema, err = row.toSchema()
+		return err
+	})
+	return schema, err
+}
+
+func (s *sqlStore) Insert(ctx context.Context, schema *queryschema.Schema) error {
+	fields, err := json.Marshal(schema.Fields)
+	if err != nil {
+		return err
+	}
+	row := &querySchema{
+		OrgId:         schema.OrgID,
+		DashboardUid:  schema.DashboardUID,
+		PanelId:       schema.PanelID,
+		RefId:         schema.RefID,
+		DatasourceUid: schema.DataSourceUID,
+		Fields:        string(fields),
+		Hash:          schema.Hash,
+		FirstSeen:     schema.FirstSeen.Unix(),
+		LastSeen:      schema.LastSeen.Unix(),
+	}
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Insert(row); err != nil {
+			return err
+		}
+		schema.ID = row.Id
+		return nil
+	})
+}
+
+func (s *sqlStore) UpdateLastSeen(ctx context.Context, id int64, lastSeen time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE query_schema SET last_seen = ? WHERE id = ? AND last_seen < ?", lastSeen.Unix(), id, lastSeen.Unix())
+		return err
+	})
+}
+
+func (s *sqlStore) List(ctx context.Context, query *queryschema.GetHistoryQuery) ([]*queryschema.Schema, error) {
+	schemas := make([]*queryschema.Schema, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			sess.And("panel_id = ?", query.PanelID)
+		}
+		if query.RefID != "" {
+			sess.And("ref_id = ?", query.RefID)
+		}
+		rows := make([]*querySchema, 0)
+		if err := sess.Asc("dashboard_uid", "panel_id", "ref_id", "first_seen", "id").Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			schema, err := row.toSchema()
+			if err != nil {
+				return err
+			}
+			schemas = append(schemas, schema)
+		}
+		return nil
+	})
+	return schemas, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM query_schema WHERE last_seen < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
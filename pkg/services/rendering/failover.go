@@ -0,0 +1,168 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// rendererBackend is one of the renderers rendering fails over between, in the
+// order configured in [rendering] backends.
+type rendererBackend struct {
+	name string
+	// url of the remote renderers
+	url string
+	// concurrentLimit is the number of concurrent renders, 0 is unlimited
+	concurrentLimit int32
+	inProgress      int32
+
+	mu             sync.RWMutex
+	unhealthyUntil time.Time
+}
+
+// acquire reserves a render, false if the renderer is at its concurrency limit.
+func (b *rendererBackend) acquire() bool {
+	for {
+		n := atomic.LoadInt32(&b.inProgress)
+		if b.concurrentLimit > 0 && n >= b.concurrentLimit {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&b.inProgress, n, n+1) {
+			return true
+		}
+	}
+}
+
+func (b *rendererBackend) release() {
+	atomic.AddInt32(&b.inProgress, -1)
+}
+
+func (b *rendererBackend) isHealthy(now time.Time) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return !now.Before(b.unhealthyUntil)
+}
+
+// setHealthy marks the renderer as healthy, or as unhealthy for the cooldown.
+func (b *rendererBackend) setHealthy(healthy bool, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if healthy {
+		b.unhealthyUntil = time.Time{}
+		return
+	}
+	b.unhealthyUntil = time.Now().Add(cooldown)
+}
+
+// newBackends returns the configured renderers. Without configuration the
+// remote renderers are used when their URL is set, the plugin otherwise.
+func (rs *RenderingService) newBackends() []*rendererBackend {
+	names := rs.Cfg.RendererBackends
+	explicit := len(names) > 0
+	if !explicit {
+		switch {
+		case rs.remoteAvailable():
+			if rs.Cfg.RendererUrl != "" {
+				names = append(names, setting.RendererBackendRemote)
+			}
+			if rs.Cfg.RendererSecondaryUrl != "" {
+				names = append(names, setting.RendererBackendSecondaryRemote)
+			}
+		case rs.pluginAvailable():
+			names = []string{setting.RendererBackendPlugin}
+		}
+	}
+
+	backends := make([]*rendererBackend, 0, len(names))
+	for _, name := range names {
+		b := &rendererBackend{name: name, concurrentLimit: int32(rs.Cfg.RendererBackendConcurrentLimits[name])}
+		switch name {
+		case setting.RendererBackendPlugin:
+			if !rs.pluginAvailable() {
+				rs.log.Warn("Skipping the image renderer plugin, it isn't installed")
+				continue
+			}
+		case setting.RendererBackendRemote:
+			b.url = rs.Cfg.RendererUrl
+		case setting.RendererBackendSecondaryRemote:
+			b.url = rs.Cfg.RendererSecondaryUrl
+		}
+		backends = append(backends, b)
+	}
+	return backends
+}
+
+// failover calls do with the renderers in order, the healthy ones first, until
+// one succeeds. The renderers at their concurrency limit are skipped. A renderer
+// that fails is considered unhealthy for [rendering] failover_cooldown, unless it
+// timed out, since rendering a slow dashboard times out with any renderer.
+func (rs *RenderingService) failover(ctx context.Context, do func(b *rendererBackend) error) error {
+	if len(rs.backends) == 0 {
+		return ErrRenderUnavailable
+	}
+
+	now := time.Now()
+	candidates := make([]*rendererBackend, 0, len(rs.backends))
+	for _, b := range rs.backends {
+		if b.isHealthy(now) {
+			candidates = append(candidates, b)
+		}
+	}
+	// unhealthy renderers are still tried as a last resort
+	for _, b := range rs.backends {
+		if !b.isHealthy(now) {
+			candidates = append(candidates, b)
+		}
+	}
+
+	err := ErrConcurrentLimitReached
+	for _, b := range candidates {
+		if !b.acquire() {
+			continue
+		}
+		err = do(b)
+		b.release()
+
+		if err == nil {
+			b.setHealthy(true, 0)
+			return nil
+		}
+		if errors.Is(err, ErrTimeout) || ctx.Err() != nil {
+			return err
+		}
+
+		b.setHealthy(false, rs.Cfg.RendererFailoverCooldown)
+		metrics.MRenderingFailoverTotal.WithLabelValues(b.name).Inc()
+		rs.log.Warn("Rendering failed, failing over to the next renderer", "renderer", b.name, "err", err)
+	}
+	return err
+}
+
+// checkHealth checks whether the remote renderers respond and whether the
+// renderer plugin is running, so that a renderer that is down is skipped before
+// a render fails with it, and one that is back is used again.
+func (rs *RenderingService) checkHealth(ctx context.Context) {
+	for _, b := range rs.backends {
+		var err error
+		if b.name == setting.RendererBackendPlugin {
+			if rs.pluginInfo.Exited() {
+				err = errors.New("the image renderer plugin isn't running")
+			}
+		} else {
+			_, err = rs.getRemotePluginVersion(ctx, b.url)
+		}
+
+		wasHealthy := b.isHealthy(time.Now())
+		b.setHealthy(err == nil, rs.Cfg.RendererFailoverCooldown)
+		if err != nil && wasHealthy {
+			rs.log.Warn("Image renderer is unhealthy", "renderer", b.name, "err", err)
+		} else if err == nil && !wasHealthy {
+			rs.log.Info("Image renderer is healthy again", "renderer", b.name)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package rendering
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeSession struct{}
+
+func (fakeSession) get(context.Context, AuthOpts) (string, error)  { return "key", nil }
+func (fakeSession) afterRequest(context.Context, AuthOpts, string) {}
+func (fakeSession) Dispose(context.Context)                        {}
+
+type fakeRenderer struct {
+	server *httptest.Server
+	status int32
+	calls  int32
+}
+
+func newFakeRenderer(t *testing.T) *fakeRenderer {
+	r := &fakeRenderer{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&r.status)))
+		if req.URL.Path == "/render/version" {
+			_, _ = w.Write([]byte(`{"version":"3.4.0"}`))
+			return
+		}
+		atomic.AddInt32(&r.calls, 1)
+		_, _ = w.Write([]byte("png"))
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *fakeRenderer) setStatus(status int) {
+	atomic.StoreInt32(&r.status, int32(status))
+}
+
+func (r *fakeRenderer) callCount() int {
+	return int(atomic.LoadInt32(&r.calls))
+}
+
+func TestRenderingFailover(t *testing.T) {
+	var primary, secondary *fakeRenderer
+	var rs *RenderingService
+
+	setup := func(t *testing.T, limits map[string]int) {
+		primary = newFakeRenderer(t)
+		secondary = newFakeRenderer(t)
+
+		cfg := setting.NewCfg()
+		cfg.ImagesDir = t.TempDir()
+		cfg.RendererUrl = primary.server.URL + "/render"
+		cfg.RendererSecondaryUrl = secondary.server.URL + "/render"
+		cfg.RendererCallbackUrl = "http://grafana.local/"
+		cfg.RendererBackendConcurrentLimits = limits
+		cfg.RendererFailoverCooldown = time.Minute
+
+		rs = &RenderingService{Cfg: cfg, log: log.New("test"), domain: "grafana.local"}
+		rs.backends = rs.newBackends()
+		require.Len(t, rs.backends, 2)
+	}
+
+	render := func(t *testing.T, errorConcurrentLimitReached bool) (*RenderResult, error) {
+		t.Helper()
+		opts := Opts{
+			TimeoutOpts:     TimeoutOpts{Timeout: 5 * time.Second},
+			ErrorOpts:       ErrorOpts{ErrorConcurrentLimitReached: errorConcurrentLimitReached},
+			Path:            "d-solo/uid/slug?panelId=1",
+			ConcurrentLimit: 10,
+		}
+		return rs.Render(context.Background(), opts, fakeSession{})
+	}
+
+	t.Run("Renders with the primary renderer while it's healthy", func(t *testing.T) {
+		setup(t, nil)
+		result, err := render(t, true)
+		require.NoError(t, err)
+		assert.FileExists(t, result.FilePath)
+		assert.Equal(t, 1, primary.callCount())
+		assert.Equal(t, 0, secondary.callCount())
+	})
+
+	t.Run("Fails over to the secondary renderer when the primary fails", func(t *testing.T) {
+		setup(t, nil)
+		primary.setStatus(http.StatusInternalServerError)
+
+		result, err := render(t, true)
+		require.NoError(t, err)
+		assert.FileExists(t, result.FilePath)
+		assert.Equal(t, 1, primary.callCount())
+		assert.Equal(t, 1, secondary.callCount())
+
+		// the unhealthy primary renderer is skipped
+		_, err = render(t, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.callCount())
+		assert.Equal(t, 2, secondary.callCount())
+
+		// until the health check finds it healthy again
+		primary.setStatus(http.StatusOK)
+		rs.checkHealth(context.Background())
+		_, err = render(t, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, primary.callCount())
+		assert.Equal(t, 2, secondary.callCount())
+	})
+
+	t.Run("Tries the unhealthy renderers as a last resort", func(t *testing.T) {
+		setup(t, nil)
+		for _, b := range rs.backends {
+			b.setHealthy(false, time.Minute)
+		}
+
+		_, err := render(t, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.callCount())
+	})
+
+	t.Run("Returns the error of the last renderer when all fail", func(t *testing.T) {
+		setup(t, nil)
+		primary.setStatus(http.StatusInternalServerError)
+		secondary.setStatus(http.StatusBadGateway)
+
+		_, err := render(t, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "502")
+	})
+
+	t.Run("Skips the renderers at their concurrency limit", func(t *testing.T) {
+		setup(t, map[string]int{setting.RendererBackendRemote: 1, setting.RendererBackendSecondaryRemote: 1})
+		require.True(t, rs.backends[0].acquire())
+
+		_, err := render(t, true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, primary.callCount())
+		assert.Equal(t, 1, secondary.callCount())
+
+		require.True(t, rs.backends[1].acquire())
+		_, err = render(t, true)
+		require.ErrorIs(t, err, ErrConcurrentLimitReached)
+
+		result, err := render(t, false)
+		require.NoError(t, err)
+		assert.Contains(t, result.FilePath, "rendering_limit_dark.png")
+	})
+}
+
+func TestRenderingBackends(t *testing.T) {
+	t.Run("Defaults to the remote renderers", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.RendererUrl = "http://renderer:8081/render"
+		cfg.RendererSecondaryUrl = "http://renderer-2:8081/render"
+		rs := &RenderingService{Cfg: cfg, log: log.New("test"), RendererPluginManager: unavailableRendererManager{}}
+
+		backends := rs.newBackends()
+		require.Len(t, backends, 2)
+		assert.Equal(t, setting.RendererBackendRemote, backends[0].name)
+		assert.Equal(t, cfg.RendererUrl, backends[0].url)
+		assert.Equal(t, setting.RendererBackendSecondaryRemote, backends[1].name)
+		assert.Equal(t, cfg.RendererSecondaryUrl, backends[1].url)
+	})
+
+	t.Run("Skips the plugin when it isn't installed", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.RendererUrl = "http://renderer:8081/render"
+		cfg.RendererBackends = []string{setting.RendererBackendPlugin, setting.RendererBackendRemote}
+		rs := &RenderingService{Cfg: cfg, log: log.New("test"), RendererPluginManager: unavailableRendererManager{}}
+
+		backends := rs.newBackends()
+		require.Len(t, backends, 1)
+		assert.Equal(t, setting.RendererBackendRemote, backends[0].name)
+	})
+
+	t.Run("Renders with the plugin using the local URL", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.RendererUrl = "http://renderer:8081/render"
+		cfg.RendererCallbackUrl = "http://grafana.example.com/"
+		cfg.Protocol = setting.HTTPScheme
+		cfg.HTTPPort = "3000"
+		rs := &RenderingService{Cfg: cfg, domain: "grafana.example.com", localDomain: "localhost"}
+
+		url, domain := rs.getPluginURL("d/uid/slug?orgId=1")
+		assert.Equal(t, "http://localhost:3000/d/uid/slug?orgId=1&render=1", url)
+		assert.Equal(t, "localhost", domain)
+	})
+}
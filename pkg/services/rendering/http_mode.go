@@ -34,13 +34,13 @@ var (
 	remoteVersionRefreshInterval               = time.Minute * 15
 )
 
-func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderKey string, opts Opts) (*RenderResult, error) {
+func (rs *RenderingService) renderViaHTTP(ctx context.Context, serverURL string, renderKey string, opts Opts) (*RenderResult, error) {
 	filePath, err := rs.getNewFilePath(RenderPNG)
 	if err != nil {
 		return nil, err
 	}
 
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl)
+	rendererURL, err := url.Parse(serverURL)
 	if err != nil {
 		return nil, err
 	}
@@ -83,13 +83,13 @@ func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderKey string,
 	return &RenderResult{FilePath: filePath}, nil
 }
 
-func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, renderKey string, opts CSVOpts) (*RenderCSVResult, error) {
+func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, serverURL string, renderKey string, opts CSVOpts) (*RenderCSVResult, error) {
 	filePath, err := rs.getNewFilePath(RenderCSV)
 	if err != nil {
 		return nil, err
 	}
 
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl + "/csv")
+	rendererURL, err := url.Parse(serverURL + "/csv")
 	if err != nil {
 		return nil, err
 	}
@@ -202,11 +202,11 @@ func (rs *RenderingService) readFileResponse(ctx context.Context, resp *http.Res
 	return nil
 }
 
-func (rs *RenderingService) getRemotePluginVersionWithRetry(callback func(string, error)) {
+func (rs *RenderingService) getRemotePluginVersionWithRetry(serverURL string, callback func(string, error)) {
 	go func() {
 		var err error
 		for try := uint(0); try < remoteVersionFetchRetries; try++ {
-			version, err := rs.getRemotePluginVersion()
+			version, err := rs.getRemotePluginVersion(context.Background(), serverURL)
 			if err == nil {
 				callback(version, err)
 				return
@@ -220,14 +220,14 @@ func (rs *RenderingService) getRemotePluginVersionWithRetry(callback func(string
 	}()
 }
 
-func (rs *RenderingService) getRemotePluginVersion() (string, error) {
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl + "/version")
+func (rs *RenderingService) getRemotePluginVersion(ctx context.Context, serverURL string) (string, error) {
+	rendererURL, err := url.Parse(serverURL + "/version")
 	if err != nil {
 		return "", err
 	}
 
 	headers := make(map[string][]string)
-	resp, err := rs.doRequest(context.Background(), rendererURL, headers)
+	resp, err := rs.doRequest(ctx, rendererURL, headers)
 	if err != nil {
 		return "", err
 	}
@@ -255,8 +255,8 @@ func (rs *RenderingService) getRemotePluginVersion() (string, error) {
 	return info.Version, nil
 }
 
-func (rs *RenderingService) refreshRemotePluginVersion() {
-	newVersion, err := rs.getRemotePluginVersion()
+func (rs *RenderingService) refreshRemotePluginVersion(serverURL string) {
+	newVersion, err := rs.getRemotePluginVersion(context.Background(), serverURL)
 	if err != nil {
 		rs.log.Info("Failed to refresh remote plugin version", "err", err)
 		return
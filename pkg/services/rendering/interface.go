@@ -81,9 +81,6 @@ type RenderCSVResult struct {
 	FileName string
 }
 
-type renderFunc func(ctx context.Context, renderKey string, options Opts) (*RenderResult, error)
-type renderCSVFunc func(ctx context.Context, renderKey string, options CSVOpts) (*RenderCSVResult, error)
-
 type renderKeyProvider interface {
 	get(ctx context.Context, opts AuthOpts) (string, error)
 	afterRequest(ctx context.Context, opts AuthOpts, renderKey string)
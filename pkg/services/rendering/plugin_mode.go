@@ -30,8 +30,9 @@ func (rs *RenderingService) renderViaPlugin(ctx context.Context, renderKey strin
 		}
 	}
 
+	url, domain := rs.getPluginURL(opts.Path)
 	req := &pluginextensionv2.RenderRequest{
-		Url:               rs.getURL(opts.Path),
+		Url:               url,
 		Width:             int32(opts.Width),
 		Height:            int32(opts.Height),
 		DeviceScaleFactor: float32(opts.DeviceScaleFactor),
@@ -39,7 +40,7 @@ func (rs *RenderingService) renderViaPlugin(ctx context.Context, renderKey strin
 		Timeout:           int32(opts.Timeout.Seconds()),
 		RenderKey:         renderKey,
 		Timezone:          isoTimeOffsetToPosixTz(opts.Timezone),
-		Domain:            rs.domain,
+		Domain:            domain,
 		Headers:           headers,
 	}
 	rs.log.Debug("Calling renderer plugin", "req", req)
@@ -76,11 +77,12 @@ func (rs *RenderingService) renderCSVViaPlugin(ctx context.Context, renderKey st
 		}
 	}
 
+	url, domain := rs.getPluginURL(opts.Path)
 	req := &pluginextensionv2.RenderCSVRequest{
-		Url:       rs.getURL(opts.Path),
+		Url:       url,
 		FilePath:  filePath,
 		RenderKey: renderKey,
-		Domain:    rs.domain,
+		Domain:    domain,
 		Timeout:   int32(opts.Timeout.Seconds()),
 		Timezone:  isoTimeOffsetToPosixTz(opts.Timezone),
 		Headers:   headers,
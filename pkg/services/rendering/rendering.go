@@ -30,15 +30,20 @@ func init() {
 const ServiceName = "RenderingService"
 
 type RenderingService struct {
-	log             log.Logger
-	pluginInfo      *plugins.Plugin
-	renderAction    renderFunc
-	renderCSVAction renderCSVFunc
-	domain          string
+	log        log.Logger
+	pluginInfo *plugins.Plugin
+	// backends are the renderers in the order they are tried
+	backends []*rendererBackend
+	domain   string
+	// localDomain is the domain of the URLs of the renderer plugin, which
+	// runs next to Grafana
+	localDomain     string
 	inProgressCount int32
-	version         string
-	versionMutex    sync.RWMutex
-	capabilities    []Capability
+	// version is the version of the first renderer, which the capabilities
+	// are checked against
+	version      string
+	versionMutex sync.RWMutex
+	capabilities []Capability
 
 	perRequestRenderKeyProvider renderKeyProvider
 	Cfg                         *setting.Cfg
@@ -59,10 +64,14 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 		return nil, fmt.Errorf("failed to create CSVs directory %q: %w", cfg.CSVsDir, err)
 	}
 
-	var domain string
+	localDomain := "localhost"
+	if cfg.HTTPAddr != setting.DefaultHTTPAddr {
+		localDomain = cfg.HTTPAddr
+	}
+
 	// set value used for domain attribute of renderKey cookie
-	switch {
-	case cfg.RendererUrl != "":
+	domain := localDomain
+	if cfg.RendererUrl != "" || cfg.RendererSecondaryUrl != "" {
 		// RendererCallbackUrl has already been passed, it won't generate an error.
 		u, err := url.Parse(cfg.RendererCallbackUrl)
 		if err != nil {
@@ -70,10 +79,6 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 		}
 
 		domain = u.Hostname()
-	case cfg.HTTPAddr != setting.DefaultHTTPAddr:
-		domain = cfg.HTTPAddr
-	default:
-		domain = "localhost"
 	}
 
 	logger := log.New("rendering")
@@ -98,75 +103,96 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 		RendererPluginManager: rm,
 		log:                   logger,
 		domain:                domain,
+		localDomain:           localDomain,
 	}
 	return s, nil
 }
 
 func (rs *RenderingService) Run(ctx context.Context) error {
-	if rs.remoteAvailable() {
-		rs.log = rs.log.New("renderer", "http")
+	rs.backends = rs.newBackends()
+	if len(rs.backends) == 0 {
+		rs.log.Debug("No image renderer found/installed. " +
+			"For image rendering support please install the grafana-image-renderer plugin. " +
+			"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
 
-		rs.getRemotePluginVersionWithRetry(func(version string, err error) {
-			if err != nil {
-				rs.log.Info("Couldn't get remote renderer version", "err", err)
-			}
+		<-ctx.Done()
+		return nil
+	}
 
-			rs.log.Info("Backend rendering via external http server", "version", version)
+	names := make([]string, 0, len(rs.backends))
+	for _, b := range rs.backends {
+		names = append(names, b.name)
+	}
+	rs.log = rs.log.New("renderer", strings.Join(names, ","))
+
+	for i, b := range rs.backends {
+		primary := i == 0
+		if b.name == setting.RendererBackendPlugin {
+			rs.pluginInfo = rs.RendererPluginManager.Renderer()
+			if err := rs.startPlugin(ctx); err != nil {
+				return err
+			}
+			if primary {
+				rs.setVersion(rs.pluginInfo.Info.Version)
+			}
+			continue
+		}
 
-			rs.versionMutex.Lock()
-			defer rs.versionMutex.Unlock()
+		name := b.name
+		rs.getRemotePluginVersionWithRetry(b.url, func(version string, err error) {
+			if err != nil {
+				rs.log.Info("Couldn't get remote renderer version", "renderer", name, "err", err)
+			}
 
-			rs.version = version
-		})
-		rs.renderAction = rs.renderViaHTTP
-		rs.renderCSVAction = rs.renderCSVViaHTTP
-
-		refreshTicker := time.NewTicker(remoteVersionRefreshInterval)
-
-		for {
-			select {
-			case <-refreshTicker.C:
-				go rs.refreshRemotePluginVersion()
-			case <-ctx.Done():
-				rs.log.Debug("Grafana is shutting down - stopping image-renderer version refresh")
-				refreshTicker.Stop()
-				return nil
+			rs.log.Info("Backend rendering via external http server", "renderer", name, "version", version)
+			if primary {
+				rs.setVersion(version)
 			}
-		}
+		})
 	}
 
-	if rs.pluginAvailable() {
-		rs.log = rs.log.New("renderer", "plugin")
-		rs.pluginInfo = rs.RendererPluginManager.Renderer()
-
-		if err := rs.startPlugin(ctx); err != nil {
-			return err
-		}
+	refreshTicker := time.NewTicker(remoteVersionRefreshInterval)
+	defer refreshTicker.Stop()
 
-		rs.version = rs.pluginInfo.Info.Version
-		rs.renderAction = rs.renderViaPlugin
-		rs.renderCSVAction = rs.renderCSVViaPlugin
-		<-ctx.Done()
+	// the health of the renderers only matters when there is one to fail over to
+	var healthCheck <-chan time.Time
+	if len(rs.backends) > 1 && rs.Cfg.RendererHealthCheckInterval > 0 {
+		healthTicker := time.NewTicker(rs.Cfg.RendererHealthCheckInterval)
+		defer healthTicker.Stop()
+		healthCheck = healthTicker.C
+	}
 
-		// On Windows, Chromium is generating a debug.log file that breaks signature check on next restart
-		debugFilePath := path.Join(rs.pluginInfo.PluginDir, "chrome-win/debug.log")
-		if _, err := os.Stat(debugFilePath); err == nil {
-			err = os.Remove(debugFilePath)
-			if err != nil {
-				rs.log.Warn("Couldn't remove debug.log file, the renderer plugin will not be able to pass the signature check until this file is deleted",
-					"err", err)
+	for {
+		select {
+		case <-refreshTicker.C:
+			if primary := rs.backends[0]; primary.url != "" {
+				go rs.refreshRemotePluginVersion(primary.url)
 			}
+		case <-healthCheck:
+			rs.checkHealth(ctx)
+		case <-ctx.Done():
+			rs.log.Debug("Grafana is shutting down - stopping image-renderer version refresh")
+			rs.removePluginDebugLog()
+			return nil
 		}
-
-		return nil
 	}
+}
 
-	rs.log.Debug("No image renderer found/installed. " +
-		"For image rendering support please install the grafana-image-renderer plugin. " +
-		"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
+// removePluginDebugLog removes the debug.log file Chromium generates on
+// Windows, which breaks the signature check of the plugin on next restart.
+func (rs *RenderingService) removePluginDebugLog() {
+	if rs.pluginInfo == nil {
+		return
+	}
 
-	<-ctx.Done()
-	return nil
+	debugFilePath := path.Join(rs.pluginInfo.PluginDir, "chrome-win/debug.log")
+	if _, err := os.Stat(debugFilePath); err == nil {
+		err = os.Remove(debugFilePath)
+		if err != nil {
+			rs.log.Warn("Couldn't remove debug.log file, the renderer plugin will not be able to pass the signature check until this file is deleted",
+				"err", err)
+		}
+	}
 }
 
 func (rs *RenderingService) pluginAvailable() bool {
@@ -174,7 +200,7 @@ func (rs *RenderingService) pluginAvailable() bool {
 }
 
 func (rs *RenderingService) remoteAvailable() bool {
-	return rs.Cfg.RendererUrl != ""
+	return rs.Cfg.RendererUrl != "" || rs.Cfg.RendererSecondaryUrl != ""
 }
 
 func (rs *RenderingService) IsAvailable() bool {
@@ -188,6 +214,13 @@ func (rs *RenderingService) Version() string {
 	return rs.version
 }
 
+func (rs *RenderingService) setVersion(version string) {
+	rs.versionMutex.Lock()
+	defer rs.versionMutex.Unlock()
+
+	rs.version = version
+}
+
 func (rs *RenderingService) RenderErrorImage(theme models.Theme, err error) (*RenderResult, error) {
 	if theme == "" {
 		theme = models.ThemeDark
@@ -235,18 +268,7 @@ func (rs *RenderingService) Render(ctx context.Context, opts Opts, session Sessi
 func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProvider renderKeyProvider) (*RenderResult, error) {
 	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
 		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
-		if opts.ErrorConcurrentLimitReached {
-			return nil, ErrConcurrentLimitReached
-		}
-
-		theme := models.ThemeDark
-		if opts.Theme != "" {
-			theme = opts.Theme
-		}
-		filePath := fmt.Sprintf("public/img/rendering_limit_%s.png", theme)
-		return &RenderResult{
-			FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
-		}, nil
+		return rs.renderLimitImage(opts)
 	}
 
 	if !rs.IsAvailable() {
@@ -275,7 +297,39 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderAction(ctx, renderKey, opts)
+	var result *RenderResult
+	err = rs.failover(ctx, func(b *rendererBackend) error {
+		var err error
+		if b.name == setting.RendererBackendPlugin {
+			result, err = rs.renderViaPlugin(ctx, renderKey, opts)
+		} else {
+			result, err = rs.renderViaHTTP(ctx, b.url, renderKey, opts)
+		}
+		return err
+	})
+	if errors.Is(err, ErrConcurrentLimitReached) {
+		rs.log.Warn("Could not render image, all renderers hit their concurrency limit", "path", opts.Path)
+		return rs.renderLimitImage(opts)
+	}
+	if errors.Is(err, ErrRenderUnavailable) && !opts.ErrorRenderUnavailable {
+		return rs.renderUnavailableImage(), nil
+	}
+	return result, err
+}
+
+func (rs *RenderingService) renderLimitImage(opts Opts) (*RenderResult, error) {
+	if opts.ErrorConcurrentLimitReached {
+		return nil, ErrConcurrentLimitReached
+	}
+
+	theme := models.ThemeDark
+	if opts.Theme != "" {
+		theme = opts.Theme
+	}
+	filePath := fmt.Sprintf("public/img/rendering_limit_%s.png", theme)
+	return &RenderResult{
+		FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
+	}, nil
 }
 
 func (rs *RenderingService) RenderCSV(ctx context.Context, opts CSVOpts, session Session) (*RenderCSVResult, error) {
@@ -315,7 +369,17 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderCSVAction(ctx, renderKey, opts)
+	var result *RenderCSVResult
+	err = rs.failover(ctx, func(b *rendererBackend) error {
+		var err error
+		if b.name == setting.RendererBackendPlugin {
+			result, err = rs.renderCSVViaPlugin(ctx, renderKey, opts)
+		} else {
+			result, err = rs.renderCSVViaHTTP(ctx, b.url, renderKey, opts)
+		}
+		return err
+	})
+	return result, err
 }
 
 func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
@@ -335,7 +399,7 @@ func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
 }
 
 func (rs *RenderingService) getURL(path string) string {
-	if rs.Cfg.RendererUrl != "" {
+	if rs.remoteAvailable() {
 		// The backend rendering service can potentially be remote.
 		// So we need to use the root_url to ensure the rendering service
 		// can reach this Grafana instance.
@@ -344,6 +408,20 @@ func (rs *RenderingService) getURL(path string) string {
 		return fmt.Sprintf("%s%s&render=1", rs.Cfg.RendererCallbackUrl, path)
 	}
 
+	return rs.getLocalURL(path, rs.domain)
+}
+
+// getPluginURL returns the URL of the path and its domain for the renderer
+// plugin, which reaches Grafana locally even when remote renderers are
+// configured too.
+func (rs *RenderingService) getPluginURL(path string) (string, string) {
+	if !rs.remoteAvailable() {
+		return rs.getURL(path), rs.domain
+	}
+	return rs.getLocalURL(path, rs.localDomain), rs.localDomain
+}
+
+func (rs *RenderingService) getLocalURL(path string, domain string) string {
 	protocol := rs.Cfg.Protocol
 	switch protocol {
 	case setting.HTTPScheme:
@@ -360,7 +438,7 @@ func (rs *RenderingService) getURL(path string) string {
 	}
 
 	// &render=1 signals to the legacy redirect layer to
-	return fmt.Sprintf("%s://%s:%s%s/%s&render=1", protocol, rs.domain, rs.Cfg.HTTPPort, subPath, path)
+	return fmt.Sprintf("%s://%s:%s%s/%s&render=1", protocol, domain, rs.Cfg.HTTPPort, subPath, path)
 }
 
 func isoTimeOffsetToPosixTz(isoOffset string) string {
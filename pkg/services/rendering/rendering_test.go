@@ -196,7 +196,7 @@ func TestRenderingServiceGetRemotePluginVersion(t *testing.T) {
 		defer server.Close()
 
 		rs.Cfg.RendererUrl = server.URL + "/render"
-		version, err := rs.getRemotePluginVersion()
+		version, err := rs.getRemotePluginVersion(context.Background(), rs.Cfg.RendererUrl)
 
 		require.NoError(t, err)
 		require.Equal(t, "2.7.1828", version)
@@ -209,7 +209,7 @@ func TestRenderingServiceGetRemotePluginVersion(t *testing.T) {
 		defer server.Close()
 
 		rs.Cfg.RendererUrl = server.URL + "/render"
-		version, err := rs.getRemotePluginVersion()
+		version, err := rs.getRemotePluginVersion(context.Background(), rs.Cfg.RendererUrl)
 
 		require.NoError(t, err)
 		require.Equal(t, version, "1.0.0")
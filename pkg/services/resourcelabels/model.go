@@ -0,0 +1,154 @@
+package resourcelabels
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrResourceNotFound    = errors.New("resource not found")
+	ErrInvalidResourceKind = errors.New("invalid resource kind")
+	ErrInvalidLabel        = errors.New("invalid label")
+)
+
+const (
+	// MaxLabels is the number of labels a resource can have.
+	MaxLabels = 20
+	// MaxLabelLength is the maximum length of the name and the value of a label.
+	MaxLabelLength = 63
+	// DefaultSearchLimit is the number of resources returned by a search unless set otherwise.
+	DefaultSearchLimit = 1000
+)
+
+// labelNamePattern matches label names. Colons are not allowed as they separate
+// the name and the value in label selectors.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+
+type ResourceKind string
+
+const (
+	ResourceKindDashboard  ResourceKind = "dashboard"
+	ResourceKindFolder     ResourceKind = "folder"
+	ResourceKindDatasource ResourceKind = "datasource"
+	ResourceKindAlertRule  ResourceKind = "alert-rule"
+	ResourceKindTeam       ResourceKind = "team"
+)
+
+var ResourceKinds = []ResourceKind{
+	ResourceKindDashboard,
+	ResourceKindFolder,
+	ResourceKindDatasource,
+	ResourceKindAlertRule,
+	ResourceKindTeam,
+}
+
+func (k ResourceKind) IsValid() bool {
+	for _, kind := range ResourceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceLabel is a label of a resource. Teams don't have a uid, their id is used instead.
+type ResourceLabel struct {
+	Id           int64
+	OrgId        int64
+	ResourceKind ResourceKind
+	ResourceUid  string
+	Name         string
+	Value        string
+	Created      time.Time
+}
+
+func (l ResourceLabel) TableName() string {
+	return "resource_label"
+}
+
+// Resource is a labeled dashboard, folder, data source, alert rule or team.
+type Resource struct {
+	Kind   ResourceKind      `json:"kind"`
+	UID    string            `json:"uid"`
+	Title  string            `json:"title"`
+	Labels map[string]string `json:"labels"`
+	// DashboardID is the dashboard or folder whose permissions apply to
+	// dashboards, folders and alert rules.
+	DashboardID int64 `json:"-"`
+}
+
+// Selector matches the resources having a label with the name, and the value if it is set.
+type Selector struct {
+	Name  string
+	Value string
+}
+
+// ParseSelector parses a label selector of the form name:value, or name to
+// match any value.
+func ParseSelector(s string) (Selector, error) {
+	name, value := s, ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		name, value = s[:i], s[i+1:]
+		if value == "" {
+			return Selector{}, fmt.Errorf("%w: selector %q has no value", ErrInvalidLabel, s)
+		}
+	}
+	if !labelNamePattern.MatchString(name) {
+		return Selector{}, fmt.Errorf("%w: selector %q has an invalid name", ErrInvalidLabel, s)
+	}
+	return Selector{Name: name, Value: value}, nil
+}
+
+// ----------------------
+// COMMANDS
+
+type SetLabelsCommand struct {
+	OrgID        int64        `json:"-"`
+	ResourceKind ResourceKind `json:"-"`
+	ResourceUID  string       `json:"-"`
+
+	Labels map[string]string `json:"labels"`
+}
+
+// ---------------------
+// QUERIES
+
+type GetResourceQuery struct {
+	OrgID int64
+	Kind  ResourceKind
+	UID   string
+}
+
+type SearchResourcesQuery struct {
+	OrgID int64
+	// Selectors must all match.
+	Selectors []Selector
+	// Kinds restricts the search to some kinds of resources, all kinds are searched if empty.
+	Kinds []ResourceKind
+	Limit int
+}
+
+// Validate checks the names and values of the labels of the command.
+func (cmd *SetLabelsCommand) Validate() error {
+	if !cmd.ResourceKind.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidResourceKind, cmd.ResourceKind)
+	}
+	if len(cmd.Labels) > MaxLabels {
+		return fmt.Errorf("%w: at most %d labels are allowed", ErrInvalidLabel, MaxLabels)
+	}
+	for name, value := range cmd.Labels {
+		if len(name) > MaxLabelLength || !labelNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: name %q must start with a letter or a digit and contain at most %d letters, digits, '_', '.', '/' and '-'",
+				ErrInvalidLabel, name, MaxLabelLength)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || len(value) > MaxLabelLength {
+			return fmt.Errorf("%w: value of %q must contain between 1 and %d characters", ErrInvalidLabel, name, MaxLabelLength)
+		}
+		cmd.Labels[name] = value
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package resourcelabels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLabelsCommand_Validate(t *testing.T) {
+	valid := func() *SetLabelsCommand {
+		return &SetLabelsCommand{
+			ResourceKind: ResourceKindDatasource,
+			Labels:       map[string]string{"env": " prod ", "team/owner": "platform"},
+		}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.Validate())
+	require.Equal(t, "prod", cmd.Labels["env"])
+
+	cmd = valid()
+	cmd.ResourceKind = "plugin"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidResourceKind)
+
+	cmd = valid()
+	cmd.Labels["env:stage"] = "prod"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLabel)
+
+	cmd = valid()
+	cmd.Labels[strings.Repeat("a", MaxLabelLength+1)] = "prod"
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLabel)
+
+	cmd = valid()
+	cmd.Labels["env"] = " "
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLabel)
+
+	cmd = valid()
+	for i := 0; i < MaxLabels; i++ {
+		cmd.Labels[strings.Repeat("a", i+1)] = "b"
+	}
+	require.ErrorIs(t, cmd.Validate(), ErrInvalidLabel)
+}
+
+func TestParseSelector(t *testing.T) {
+	s, err := ParseSelector("env:prod")
+	require.NoError(t, err)
+	require.Equal(t, Selector{Name: "env", Value: "prod"}, s)
+
+	s, err = ParseSelector("url:http://example.com")
+	require.NoError(t, err)
+	require.Equal(t, Selector{Name: "url", Value: "http://example.com"}, s)
+
+	s, err = ParseSelector("env")
+	require.NoError(t, err)
+	require.Equal(t, Selector{Name: "env"}, s)
+
+	_, err = ParseSelector("env:")
+	require.ErrorIs(t, err, ErrInvalidLabel)
+
+	_, err = ParseSelector(":prod")
+	require.ErrorIs(t, err, ErrInvalidLabel)
+}
@@ -0,0 +1,17 @@
+package resourcelabels
+
+import (
+	"context"
+)
+
+// Service manages labels, such as env:prod, attached to dashboards, folders,
+// data sources, alert rules and teams, and searches resources of all kinds by label.
+type Service interface {
+	// GetResource returns the resource and its labels. It is used to check that
+	// the resource exists and to resolve the permissions that apply to it.
+	GetResource(ctx context.Context, query *GetResourceQuery) (*Resource, error)
+	// SetLabels replaces the labels of a resource.
+	SetLabels(ctx context.Context, cmd *SetLabelsCommand) (*Resource, error)
+	// Search returns the resources matching all the selectors.
+	Search(ctx context.Context, query *SearchResourcesQuery) ([]*Resource, error)
+}
@@ -0,0 +1,87 @@
+package resourcelabelsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type Service struct {
+	store store
+	now   func() time.Time
+}
+
+func ProvideService(db db.DB) resourcelabels.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+		now: time.Now,
+	}
+}
+
+func (s *Service) GetResource(ctx context.Context, query *resourcelabels.GetResourceQuery) (*resourcelabels.Resource, error) {
+	if !query.Kind.IsValid() {
+		return nil, resourcelabels.ErrInvalidResourceKind
+	}
+	return s.store.GetResource(ctx, query.OrgID, query.Kind, query.UID)
+}
+
+func (s *Service) SetLabels(ctx context.Context, cmd *resourcelabels.SetLabelsCommand) (*resourcelabels.Resource, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	resource, err := s.store.GetResource(ctx, cmd.OrgID, cmd.ResourceKind, cmd.ResourceUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.ReplaceLabels(ctx, cmd.OrgID, cmd.ResourceKind, resource.UID, cmd.Labels, s.now()); err != nil {
+		return nil, err
+	}
+
+	resource.Labels = cmd.Labels
+	if resource.Labels == nil {
+		resource.Labels = map[string]string{}
+	}
+	return resource, nil
+}
+
+func (s *Service) Search(ctx context.Context, query *resourcelabels.SearchResourcesQuery) ([]*resourcelabels.Resource, error) {
+	if len(query.Selectors) == 0 {
+		return nil, resourcelabels.ErrInvalidLabel
+	}
+	for _, kind := range query.Kinds {
+		if !kind.IsValid() {
+			return nil, resourcelabels.ErrInvalidResourceKind
+		}
+	}
+
+	// The store expects a single selector per label name, see sqlStore.Search.
+	byName := make(map[string]resourcelabels.Selector, len(query.Selectors))
+	names := make([]string, 0, len(query.Selectors))
+	for _, selector := range query.Selectors {
+		existing, ok := byName[selector.Name]
+		switch {
+		case !ok:
+			names = append(names, selector.Name)
+			byName[selector.Name] = selector
+		case existing.Value == "":
+			byName[selector.Name] = selector
+		case selector.Value != "" && selector.Value != existing.Value:
+			// a resource has a single value per label name
+			return []*resourcelabels.Resource{}, nil
+		}
+	}
+	selectors := make([]resourcelabels.Selector, 0, len(names))
+	for _, name := range names {
+		selectors = append(selectors, byName[name])
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = resourcelabels.DefaultSearchLimit
+	}
+	return s.store.Search(ctx, query.OrgID, selectors, query.Kinds, limit)
+}
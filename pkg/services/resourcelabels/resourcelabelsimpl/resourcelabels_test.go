@@ -0,0 +1,129 @@
+package resourcelabelsimpl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationResourceLabels(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := ProvideService(ss)
+	ctx := context.Background()
+
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "labeler", Email: "labeler@example.com"})
+	require.NoError(t, err)
+	orgID := user.OrgId
+	team, err := ss.CreateTeam("platform", "platform@example.com", orgID)
+	require.NoError(t, err)
+	teamUID := strconv.FormatInt(team.Id, 10)
+	require.NoError(t, ss.AddDataSource(ctx, &models.AddDataSourceCommand{OrgId: orgID, Name: "Prometheus", Uid: "prom", Type: models.DS_PROMETHEUS, Access: models.DS_ACCESS_PROXY}))
+
+	var folderID int64
+	err = ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		folder := &models.Dashboard{OrgId: orgID, Uid: "folder", Slug: "folder", Title: "Folder", IsFolder: true, Data: models.NewDashboard("Folder").Data, Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Insert(folder); err != nil {
+			return err
+		}
+		folderID = folder.Id
+		dashboard := &models.Dashboard{OrgId: orgID, FolderId: folderID, Uid: "dash", Slug: "dash", Title: "Dash", Data: models.NewDashboard("Dash").Data, Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Insert(dashboard); err != nil {
+			return err
+		}
+		rule := ngmodels.AlertRuleGen(func(r *ngmodels.AlertRule) {
+			r.OrgID = orgID
+			r.UID = "rule"
+			r.Title = "Rule"
+			r.NamespaceUID = "folder"
+		})()
+		_, err := sess.Insert(rule)
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Run("set validates the resource", func(t *testing.T) {
+		_, err := svc.SetLabels(ctx, &resourcelabels.SetLabelsCommand{OrgID: orgID, ResourceKind: resourcelabels.ResourceKindDashboard, ResourceUID: "folder", Labels: map[string]string{"env": "prod"}})
+		require.ErrorIs(t, err, resourcelabels.ErrResourceNotFound)
+
+		_, err = svc.SetLabels(ctx, &resourcelabels.SetLabelsCommand{OrgID: orgID, ResourceKind: resourcelabels.ResourceKindTeam, ResourceUID: "platform", Labels: map[string]string{"env": "prod"}})
+		require.ErrorIs(t, err, resourcelabels.ErrResourceNotFound)
+	})
+
+	t.Run("set, get and search labeled resources", func(t *testing.T) {
+		for _, r := range []struct {
+			kind   resourcelabels.ResourceKind
+			uid    string
+			labels map[string]string
+		}{
+			{resourcelabels.ResourceKindDashboard, "dash", map[string]string{"env": "prod", "tier": "1"}},
+			{resourcelabels.ResourceKindFolder, "folder", map[string]string{"env": "prod"}},
+			{resourcelabels.ResourceKindDatasource, "prom", map[string]string{"env": "prod", "tier": "1"}},
+			{resourcelabels.ResourceKindAlertRule, "rule", map[string]string{"env": "dev"}},
+			{resourcelabels.ResourceKindTeam, teamUID, map[string]string{"env": "prod", "tier": "2"}},
+		} {
+			_, err := svc.SetLabels(ctx, &resourcelabels.SetLabelsCommand{OrgID: orgID, ResourceKind: r.kind, ResourceUID: r.uid, Labels: r.labels})
+			require.NoError(t, err)
+		}
+
+		resource, err := svc.GetResource(ctx, &resourcelabels.GetResourceQuery{OrgID: orgID, Kind: resourcelabels.ResourceKindAlertRule, UID: "rule"})
+		require.NoError(t, err)
+		require.Equal(t, "Rule", resource.Title)
+		require.Equal(t, folderID, resource.DashboardID)
+		require.Equal(t, map[string]string{"env": "dev"}, resource.Labels)
+
+		found, err := svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "env", Value: "prod"}}})
+		require.NoError(t, err)
+		require.Len(t, found, 4)
+		require.Equal(t, resourcelabels.ResourceKindDashboard, found[0].Kind)
+		require.Equal(t, resourcelabels.ResourceKindTeam, found[3].Kind)
+		require.Equal(t, "platform", found[3].Title)
+		require.Equal(t, map[string]string{"env": "prod", "tier": "2"}, found[3].Labels)
+
+		found, err = svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "env", Value: "prod"}, {Name: "tier", Value: "1"}}})
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+
+		found, err = svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "tier"}, {Name: "env", Value: "prod"}}, Kinds: []resourcelabels.ResourceKind{resourcelabels.ResourceKindDatasource, resourcelabels.ResourceKindTeam}})
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		require.Equal(t, "Prometheus", found[0].Title)
+
+		found, err = svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "env"}, {Name: "env", Value: "dev"}}})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		require.Equal(t, "rule", found[0].UID)
+
+		found, err = svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "env", Value: "prod"}, {Name: "env", Value: "dev"}}})
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+
+	t.Run("set replaces the labels", func(t *testing.T) {
+		resource, err := svc.SetLabels(ctx, &resourcelabels.SetLabelsCommand{OrgID: orgID, ResourceKind: resourcelabels.ResourceKindDashboard, ResourceUID: "dash", Labels: map[string]string{"env": "stage"}})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "stage"}, resource.Labels)
+
+		resource, err = svc.GetResource(ctx, &resourcelabels.GetResourceQuery{OrgID: orgID, Kind: resourcelabels.ResourceKindDashboard, UID: "dash"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "stage"}, resource.Labels)
+	})
+
+	t.Run("labels of deleted resources are skipped", func(t *testing.T) {
+		require.NoError(t, ss.DeleteTeam(ctx, &models.DeleteTeamCommand{OrgId: orgID, Id: team.Id}))
+
+		found, err := svc.Search(ctx, &resourcelabels.SearchResourcesQuery{OrgID: orgID, Selectors: []resourcelabels.Selector{{Name: "tier", Value: "2"}}})
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+}
@@ -0,0 +1,230 @@
+package resourcelabelsimpl
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/resourcelabels"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// batchSize keeps the number of parameters of IN clauses below the limit of SQLite.
+const batchSize = 500
+
+type store interface {
+	GetResource(ctx context.Context, orgID int64, kind resourcelabels.ResourceKind, uid string) (*resourcelabels.Resource, error)
+	ReplaceLabels(ctx context.Context, orgID int64, kind resourcelabels.ResourceKind, uid string, labels map[string]string, now time.Time) error
+	Search(ctx context.Context, orgID int64, selectors []resourcelabels.Selector, kinds []resourcelabels.ResourceKind, limit int) ([]*resourcelabels.Resource, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+type resourceRow struct {
+	Uid         string
+	Id          int64
+	Title       string
+	DashboardId int64
+}
+
+type labeledResource struct {
+	ResourceKind resourcelabels.ResourceKind
+	ResourceUid  string
+}
+
+func (s *sqlStore) GetResource(ctx context.Context, orgID int64, kind resourcelabels.ResourceKind, uid string) (*resourcelabels.Resource, error) {
+	var resource *resourcelabels.Resource
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		resources, err := s.getResources(sess, orgID, kind, []string{uid})
+		if err != nil {
+			return err
+		}
+		if len(resources) == 0 {
+			return resourcelabels.ErrResourceNotFound
+		}
+		resource = resources[0]
+		return s.loadLabels(sess, orgID, kind, resources)
+	})
+	return resource, err
+}
+
+func (s *sqlStore) ReplaceLabels(ctx context.Context, orgID int64, kind resourcelabels.ResourceKind, uid string, labels map[string]string, now time.Time) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Where("org_id = ? AND resource_kind = ? AND resource_uid = ?", orgID, kind, uid).Delete(&resourcelabels.ResourceLabel{}); err != nil {
+			return err
+		}
+		for name, value := range labels {
+			label := &resourcelabels.ResourceLabel{
+				OrgId:        orgID,
+				ResourceKind: kind,
+				ResourceUid:  uid,
+				Name:         name,
+				Value:        value,
+				Created:      now,
+			}
+			if _, err := sess.Insert(label); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search returns the resources having a label matching each selector. Labels of
+// deleted resources are skipped.
+func (s *sqlStore) Search(ctx context.Context, orgID int64, selectors []resourcelabels.Selector, kinds []resourcelabels.ResourceKind, limit int) ([]*resourcelabels.Resource, error) {
+	result := make([]*resourcelabels.Resource, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var sql strings.Builder
+		params := []interface{}{orgID}
+		sql.WriteString("SELECT resource_kind, resource_uid FROM resource_label WHERE org_id = ?")
+		if len(kinds) > 0 {
+			sql.WriteString(" AND resource_kind IN (?" + strings.Repeat(",?", len(kinds)-1) + ")")
+			for _, kind := range kinds {
+				params = append(params, kind)
+			}
+		}
+		conditions := make([]string, 0, len(selectors))
+		for _, selector := range selectors {
+			if selector.Value == "" {
+				conditions = append(conditions, "name = ?")
+				params = append(params, selector.Name)
+			} else {
+				conditions = append(conditions, "(name = ? AND value = ?)")
+				params = append(params, selector.Name, selector.Value)
+			}
+		}
+		// the selectors have distinct names and a resource has a single label per
+		// name, so it matches all the selectors when it has as many matching labels
+		sql.WriteString(" AND (" + strings.Join(conditions, " OR ") + ")")
+		sql.WriteString(" GROUP BY resource_kind, resource_uid HAVING COUNT(*) = ?")
+		params = append(params, len(selectors))
+		sql.WriteString(" ORDER BY resource_kind, resource_uid")
+		sql.WriteString(s.db.GetDialect().Limit(int64(limit)))
+
+		matches := make([]labeledResource, 0)
+		if err := sess.SQL(sql.String(), params...).Find(&matches); err != nil {
+			return err
+		}
+
+		uidsByKind := map[resourcelabels.ResourceKind][]string{}
+		for _, m := range matches {
+			uidsByKind[m.ResourceKind] = append(uidsByKind[m.ResourceKind], m.ResourceUid)
+		}
+		for _, kind := range resourcelabels.ResourceKinds {
+			if len(uidsByKind[kind]) == 0 {
+				continue
+			}
+			resources, err := s.getResources(sess, orgID, kind, uidsByKind[kind])
+			if err != nil {
+				return err
+			}
+			if err := s.loadLabels(sess, orgID, kind, resources); err != nil {
+				return err
+			}
+			result = append(result, resources...)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// getResources returns the resources of a kind with the uids, ordered by title.
+// Resources that don't exist are skipped.
+func (s *sqlStore) getResources(sess *sqlstore.DBSession, orgID int64, kind resourcelabels.ResourceKind, uids []string) ([]*resourcelabels.Resource, error) {
+	var query string
+	params := []interface{}{orgID}
+	switch kind {
+	case resourcelabels.ResourceKindDashboard, resourcelabels.ResourceKindFolder:
+		query = "SELECT uid, title, id AS dashboard_id FROM dashboard WHERE org_id = ? AND is_folder = ? AND uid IN (%s) ORDER BY title"
+		params = append(params, s.db.GetDialect().BooleanStr(kind == resourcelabels.ResourceKindFolder))
+	case resourcelabels.ResourceKindDatasource:
+		query = "SELECT uid, name AS title FROM data_source WHERE org_id = ? AND uid IN (%s) ORDER BY name"
+	case resourcelabels.ResourceKindAlertRule:
+		query = `SELECT alert_rule.uid, alert_rule.title, dashboard.id AS dashboard_id FROM alert_rule
+			INNER JOIN dashboard ON dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.namespace_uid
+			WHERE alert_rule.org_id = ? AND alert_rule.uid IN (%s) ORDER BY alert_rule.title`
+	case resourcelabels.ResourceKindTeam:
+		query = "SELECT id, name AS title FROM team WHERE org_id = ? AND id IN (%s) ORDER BY name"
+	default:
+		return nil, resourcelabels.ErrInvalidResourceKind
+	}
+
+	result := make([]*resourcelabels.Resource, 0, len(uids))
+	for start := 0; start < len(uids); start += batchSize {
+		end := start + batchSize
+		if end > len(uids) {
+			end = len(uids)
+		}
+		batch := make([]interface{}, 0, end-start)
+		for _, uid := range uids[start:end] {
+			if kind == resourcelabels.ResourceKindTeam {
+				// teams are identified by their id
+				id, err := strconv.ParseInt(uid, 10, 64)
+				if err != nil {
+					continue
+				}
+				batch = append(batch, id)
+			} else {
+				batch = append(batch, uid)
+			}
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		rows := make([]*resourceRow, 0, len(batch))
+		sql := strings.Replace(query, "%s", "?"+strings.Repeat(",?", len(batch)-1), 1)
+		if err := sess.SQL(sql, append(params, batch...)...).Find(&rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			uid := row.Uid
+			if kind == resourcelabels.ResourceKindTeam {
+				uid = strconv.FormatInt(row.Id, 10)
+			}
+			result = append(result, &resourcelabels.Resource{
+				Kind:        kind,
+				UID:         uid,
+				Title:       row.Title,
+				Labels:      map[string]string{},
+				DashboardID: row.DashboardId,
+			})
+		}
+	}
+	return result, nil
+}
+
+// loadLabels sets the labels of resources of the same kind.
+func (s *sqlStore) loadLabels(sess *sqlstore.DBSession, orgID int64, kind resourcelabels.ResourceKind, resources []*resourcelabels.Resource) error {
+	byUID := make(map[string]*resourcelabels.Resource, len(resources))
+	for _, r := range resources {
+		byUID[r.UID] = r
+	}
+	for start := 0; start < len(resources); start += batchSize {
+		end := start + batchSize
+		if end > len(resources) {
+			end = len(resources)
+		}
+		uids := make([]string, 0, end-start)
+		for _, r := range resources[start:end] {
+			uids = append(uids, r.UID)
+		}
+
+		labels := make([]*resourcelabels.ResourceLabel, 0)
+		err := sess.Where("org_id = ? AND resource_kind = ?", orgID, kind).In("resource_uid", uids).Find(&labels)
+		if err != nil {
+			return err
+		}
+		for _, l := range labels {
+			if r, ok := byUID[l.ResourceUid]; ok {
+				r.Labels[l.Name] = l.Value
+			}
+		}
+	}
+	return nil
+}
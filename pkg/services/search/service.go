@@ -38,8 +38,10 @@ type Query struct {
 	DashboardUIDs []string
 	DashboardIds  []int64
 	FolderIds     []int64
-	Permission    models.PermissionType
-	Sort          string
+	// SubtreeFolderUID limits the result to the dashboards and folders nested in the folder, at any depth.
+	SubtreeFolderUID string
+	Permission       models.PermissionType
+	Sort             string
 
 	Result models.HitList
 }
@@ -59,17 +61,18 @@ type SearchService struct {
 
 func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 	dashboardQuery := models.FindPersistedDashboardsQuery{
-		Title:         query.Title,
-		SignedInUser:  query.SignedInUser,
-		IsStarred:     query.IsStarred,
-		DashboardUIDs: query.DashboardUIDs,
-		DashboardIds:  query.DashboardIds,
-		Type:          query.Type,
-		FolderIds:     query.FolderIds,
-		Tags:          query.Tags,
-		Limit:         query.Limit,
-		Page:          query.Page,
-		Permission:    query.Permission,
+		Title:            query.Title,
+		SignedInUser:     query.SignedInUser,
+		IsStarred:        query.IsStarred,
+		DashboardUIDs:    query.DashboardUIDs,
+		DashboardIds:     query.DashboardIds,
+		Type:             query.Type,
+		FolderIds:        query.FolderIds,
+		SubtreeFolderUID: query.SubtreeFolderUID,
+		Tags:             query.Tags,
+		Limit:            query.Limit,
+		Page:             query.Page,
+		Permission:       query.Permission,
 	}
 
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
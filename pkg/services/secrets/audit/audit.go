@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"context"
+)
+
+// Service records accesses to decrypted secure settings so that credential
+// usage can be accounted for.
+type Service interface {
+	// RecordAccess records that the secure settings of a resource have been
+	// decrypted. The acting user, if any, is taken from the context.
+	RecordAccess(ctx context.Context, event *AccessEvent)
+	// GetLastAccess returns the most recent recorded access to the secure
+	// settings of a resource.
+	GetLastAccess(ctx context.Context, query *GetLastAccessQuery) (*AccessReport, error)
+}
@@ -0,0 +1,191 @@
+package auditimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace            = "secrets.audit"
+	defaultPersistInterval = time.Minute
+	// systemActor is reported for accesses outside of a user request, e.g.
+	// alert rule evaluation.
+	systemActor = "grafana"
+)
+
+var accessCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.ExporterName,
+	Name:      "secrets_access_total",
+	Help:      "A counter for decryptions of secure settings",
+}, []string{"kind"})
+
+func init() {
+	prometheus.MustRegister(accessCounter)
+}
+
+type Service struct {
+	kv              kvstore.KVStore
+	log             log.Logger
+	enabled         bool
+	sampleRate      float64
+	persistInterval time.Duration
+	now             func() time.Time
+	sample          func() float64
+
+	// mu only guards the reports map. Each report has its own lock, held
+	// while it is loaded from and persisted to the kvstore, so that slow
+	// storage only delays accesses to the same secure settings.
+	mu      sync.Mutex
+	reports map[string]*trackedReport
+}
+
+type trackedReport struct {
+	mu        sync.Mutex
+	loaded    bool
+	report    audit.AccessReport
+	persisted time.Time
+}
+
+// ProvideService returns an audit service configured from the
+// [secrets_audit] section. Every access updates the last access report
+// and metrics, while the sample_rate setting controls which portion of the
+// accesses are written to the audit log.
+func ProvideService(cfg *setting.Cfg, kv kvstore.KVStore) audit.Service {
+	sec := cfg.Raw.Section("secrets_audit")
+	sampleRate := sec.Key("sample_rate").MustFloat64(1)
+	if sampleRate < 0 {
+		sampleRate = 0
+	} else if sampleRate > 1 {
+		sampleRate = 1
+	}
+
+	return &Service{
+		kv:              kv,
+		log:             log.New("secrets.audit"),
+		enabled:         sec.Key("enabled").MustBool(true),
+		sampleRate:      sampleRate,
+		persistInterval: sec.Key("persist_interval").MustDuration(defaultPersistInterval),
+		now:             time.Now,
+		sample:          rand.Float64,
+		reports:         map[string]*trackedReport{},
+	}
+}
+
+func (s *Service) RecordAccess(ctx context.Context, event *audit.AccessEvent) {
+	if !s.enabled {
+		return
+	}
+
+	accessCounter.WithLabelValues(event.Kind).Inc()
+
+	actor, userID := systemActor, int64(0)
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SignedInUser != nil && reqCtx.IsSignedIn {
+		actor, userID = reqCtx.Login, reqCtx.UserId
+	}
+
+	if s.sampleRate > 0 && (s.sampleRate >= 1 || s.sample() < s.sampleRate) {
+		s.log.Info("Secure settings accessed", "orgId", event.OrgID, "kind", event.Kind, "uid", event.UID,
+			"plugin", event.Plugin, "keys", event.Keys, "actor", actor, "userId", userID)
+	}
+
+	now := s.now()
+	key := reportKey(event.Kind, event.UID)
+
+	s.mu.Lock()
+	tracked, ok := s.reports[mapKey(event.OrgID, key)]
+	if !ok {
+		tracked = &trackedReport{}
+		s.reports[mapKey(event.OrgID, key)] = tracked
+	}
+	s.mu.Unlock()
+
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+
+	if !tracked.loaded {
+		if stored, err := s.load(ctx, event.OrgID, key); err == nil {
+			tracked.report = *stored
+		}
+		tracked.loaded = true
+	}
+
+	tracked.report.LastAccessed = now
+	tracked.report.LastAccessedBy = actor
+	tracked.report.UserID = userID
+	tracked.report.Plugin = event.Plugin
+	tracked.report.Keys = event.Keys
+	tracked.report.AccessCount++
+
+	if now.Sub(tracked.persisted) < s.persistInterval {
+		return
+	}
+	if err := s.store(ctx, event.OrgID, key, &tracked.report); err != nil {
+		s.log.Warn("Failed to persist secure settings access report", "orgId", event.OrgID, "kind", event.Kind, "uid", event.UID, "error", err)
+		return
+	}
+	tracked.persisted = now
+}
+
+func (s *Service) GetLastAccess(ctx context.Context, query *audit.GetLastAccessQuery) (*audit.AccessReport, error) {
+	key := reportKey(query.Kind, query.UID)
+
+	s.mu.Lock()
+	tracked, ok := s.reports[mapKey(query.OrgID, key)]
+	s.mu.Unlock()
+
+	if ok {
+		tracked.mu.Lock()
+		report, loaded := tracked.report, tracked.loaded
+		tracked.mu.Unlock()
+		if loaded {
+			return &report, nil
+		}
+	}
+
+	return s.load(ctx, query.OrgID, key)
+}
+
+func (s *Service) load(ctx context.Context, orgID int64, key string) (*audit.AccessReport, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, audit.ErrNoAccessRecorded
+	}
+
+	var report audit.AccessReport
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (s *Service) store(ctx context.Context, orgID int64, key string, report *audit.AccessReport) error {
+	value, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, key, string(value))
+}
+
+func reportKey(kind, uid string) string {
+	return kind + ":" + uid
+}
+
+func mapKey(orgID int64, key string) string {
+	return fmt.Sprintf("%d/%s", orgID, key)
+}
@@ -0,0 +1,117 @@
+package auditimpl
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationSecretsAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	kv := kvstore.ProvideService(ss)
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	svc := ProvideService(setting.NewCfg(), kv).(*Service)
+	svc.now = func() time.Time { return now }
+
+	query := &audit.GetLastAccessQuery{OrgID: 1, Kind: audit.KindDatasource, UID: "ds-uid"}
+
+	t.Run("no access recorded", func(t *testing.T) {
+		_, err := svc.GetLastAccess(context.Background(), query)
+		require.ErrorIs(t, err, audit.ErrNoAccessRecorded)
+	})
+
+	t.Run("accesses update the last access report", func(t *testing.T) {
+		event := &audit.AccessEvent{OrgID: 1, Kind: audit.KindDatasource, UID: "ds-uid", Plugin: "prometheus", Keys: []string{"basicAuthPassword"}}
+		svc.RecordAccess(context.Background(), event)
+		now = now.Add(time.Second)
+		svc.RecordAccess(context.Background(), event)
+
+		report, err := svc.GetLastAccess(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, now, report.LastAccessed)
+		require.Equal(t, systemActor, report.LastAccessedBy)
+		require.Equal(t, "prometheus", report.Plugin)
+		require.Equal(t, int64(2), report.AccessCount)
+	})
+
+	t.Run("reports are persisted and scoped per org", func(t *testing.T) {
+		reloaded := ProvideService(setting.NewCfg(), kv)
+
+		report, err := reloaded.GetLastAccess(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, "prometheus", report.Plugin)
+
+		_, err = reloaded.GetLastAccess(context.Background(), &audit.GetLastAccessQuery{OrgID: 2, Kind: audit.KindDatasource, UID: "ds-uid"})
+		require.ErrorIs(t, err, audit.ErrNoAccessRecorded)
+	})
+
+	t.Run("disabled audit records nothing", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		_, err := cfg.Raw.Section("secrets_audit").NewKey("enabled", "false")
+		require.NoError(t, err)
+		disabled := ProvideService(cfg, kv)
+
+		disabled.RecordAccess(context.Background(), &audit.AccessEvent{OrgID: 1, Kind: audit.KindDatasource, UID: "other"})
+		_, err = disabled.GetLastAccess(context.Background(), &audit.GetLastAccessQuery{OrgID: 1, Kind: audit.KindDatasource, UID: "other"})
+		require.ErrorIs(t, err, audit.ErrNoAccessRecorded)
+	})
+}
+
+// blockingKVStore blocks reads of keys containing "slow" until release is
+// closed.
+type blockingKVStore struct {
+	kvstore.KVStore
+	release chan struct{}
+}
+
+func (b *blockingKVStore) Get(ctx context.Context, orgID int64, namespace string, key string) (string, bool, error) {
+	if strings.Contains(key, "slow") {
+		<-b.release
+	}
+	return b.KVStore.Get(ctx, orgID, namespace, key)
+}
+
+func TestIntegrationSecretsAudit_SlowStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := &blockingKVStore{KVStore: kvstore.ProvideService(sqlstore.InitTestDB(t)), release: make(chan struct{})}
+	svc := ProvideService(setting.NewCfg(), kv)
+
+	slowDone := make(chan struct{})
+	go func() {
+		svc.RecordAccess(context.Background(), &audit.AccessEvent{OrgID: 1, Kind: audit.KindDatasource, UID: "slow"})
+		close(slowDone)
+	}()
+
+	fastDone := make(chan struct{})
+	go func() {
+		svc.RecordAccess(context.Background(), &audit.AccessEvent{OrgID: 1, Kind: audit.KindDatasource, UID: "fast"})
+		close(fastDone)
+	}()
+
+	select {
+	case <-fastDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("access to other secure settings blocked by slow storage")
+	}
+
+	close(kv.release)
+	<-slowDone
+
+	report, err := svc.GetLastAccess(context.Background(), &audit.GetLastAccessQuery{OrgID: 1, Kind: audit.KindDatasource, UID: "slow"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), report.AccessCount)
+}
@@ -0,0 +1,25 @@
+package audittest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/secrets/audit"
+)
+
+type FakeAuditService struct {
+	ExpectedReport *audit.AccessReport
+	ExpectedError  error
+	Recorded       []*audit.AccessEvent
+}
+
+func NewFakeAuditService() *FakeAuditService {
+	return &FakeAuditService{}
+}
+
+func (f *FakeAuditService) RecordAccess(ctx context.Context, event *audit.AccessEvent) {
+	f.Recorded = append(f.Recorded, event)
+}
+
+func (f *FakeAuditService) GetLastAccess(ctx context.Context, query *audit.GetLastAccessQuery) (*audit.AccessReport, error) {
+	return f.ExpectedReport, f.ExpectedError
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoAccessRecorded = errors.New("no access to secure settings has been recorded")
+
+const (
+	KindDatasource = "datasource"
+)
+
+// AccessEvent describes a single decryption of the secure settings of a
+// resource.
+type AccessEvent struct {
+	OrgID int64
+	// Kind of the resource owning the secrets, e.g. "datasource".
+	Kind string
+	UID  string
+	// Plugin is the plugin the secrets are decrypted for, if any.
+	Plugin string
+	// Keys are the names of the decrypted secure settings.
+	Keys []string
+}
+
+// AccessReport summarizes the accesses to the secure settings of a resource.
+type AccessReport struct {
+	LastAccessed   time.Time `json:"lastAccessed"`
+	LastAccessedBy string    `json:"lastAccessedBy"`
+	UserID         int64     `json:"userId,omitempty"`
+	Plugin         string    `json:"plugin,omitempty"`
+	Keys           []string  `json:"keys"`
+	AccessCount    int64     `json:"accessCount"`
+}
+
+type GetLastAccessQuery struct {
+	OrgID int64
+	Kind  string
+	UID   string
+}
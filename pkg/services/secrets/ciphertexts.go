@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+)
+
+// EncryptedColumn is a database column holding a single secret encrypted with
+// the secrets service.
+type EncryptedColumn struct {
+	Table  string
+	Column string
+	// Encoding is set for columns storing base64-encoded ciphertexts.
+	Encoding *base64.Encoding
+	// HasUpdatedColumn is set for tables whose updated column is bumped when
+	// a secret is re-encrypted.
+	HasUpdatedColumn bool
+}
+
+// EncryptedColumns lists the columns holding a single secret, the same as
+// the re-encrypt command of grafana-cli.
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "dashboard_snapshot", Column: "dashboard_encrypted", HasUpdatedColumn: true},
+	{Table: "user_auth", Column: "o_auth_access_token", Encoding: base64.StdEncoding},
+	{Table: "user_auth", Column: "o_auth_refresh_token", Encoding: base64.StdEncoding},
+	{Table: "user_auth", Column: "o_auth_token_type", Encoding: base64.StdEncoding},
+	{Table: "secrets", Column: "value", Encoding: base64.RawStdEncoding, HasUpdatedColumn: true},
+}
+
+// EncryptedJSONTables lists the tables storing secrets in their
+// secure_json_data column. The Alertmanager configurations of the
+// alert_configuration table hold secrets as well.
+var EncryptedJSONTables = []string{"data_source", "plugin_setting"}
+
+// StripDataKeyID returns the ciphertext of a payload, without the
+// "#<data key id>#" prefix of secrets encrypted with envelope encryption.
+// Payloads encrypted directly with the secret key are returned unchanged.
+// It returns false if the prefix isn't terminated.
+func StripDataKeyID(payload []byte) ([]byte, bool) {
+	if len(payload) == 0 || payload[0] != '#' {
+		return payload, true
+	}
+	endOfKey := bytes.IndexByte(payload[1:], '#')
+	if endOfKey == -1 {
+		return nil, false
+	}
+	return payload[endOfKey+2:], true
+}
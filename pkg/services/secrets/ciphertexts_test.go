@@ -0,0 +1,20 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripDataKeyID(t *testing.T) {
+	payload, ok := StripDataKeyID([]byte("#a2V5#ciphertext"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("ciphertext"), payload)
+
+	payload, ok = StripDataKeyID([]byte("ciphertext"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("ciphertext"), payload)
+
+	_, ok = StripDataKeyID([]byte("#a2V5"))
+	assert.False(t, ok)
+}
@@ -48,7 +48,11 @@ func (f FakeSecretsService) ReEncryptDataKeys(_ context.Context) error {
 	return nil
 }
 
-func (f FakeSecretsService) CurrentProviderID() string {
+func (f FakeSecretsService) CheckProvidersHealth(_ context.Context) map[secrets.ProviderID]error {
+	return map[secrets.ProviderID]error{"fakeProvider": nil}
+}
+
+func (f FakeSecretsService) CurrentProviderID() secrets.ProviderID {
 	return "fakeProvider"
 }
 
@@ -29,14 +29,7 @@ func ProvideService(sqlStore sqlstore.Store, secretsService secrets.Service, rem
 		}
 	}
 	logger.Debug("secrets kvstore is using the default (SQL) implementation for secrets management")
-	return &secretsKVStoreSQL{
-		sqlStore:       sqlStore,
-		secretsService: secretsService,
-		log:            logger,
-		decryptionCache: decryptionCache{
-			cache: make(map[int64]cachedDecrypted),
-		},
-	}
+	return newSQLStore(sqlStore, secretsService)
 }
 
 // SecretsKVStore is an interface for k/v store.
@@ -0,0 +1,91 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// MigrateToPluginService moves the secrets stored in the Grafana database
+// to the secretsmanager plugin on startup, when [secrets] migrate_to_plugin
+// is set and the plugin is in use.
+type MigrateToPluginService struct {
+	cfg               *setting.Cfg
+	sqlStore          sqlstore.Store
+	secretsService    secrets.Service
+	remoteCheck       UseRemoteSecretsPluginCheck
+	serverLockService *serverlock.ServerLockService
+	log               log.Logger
+}
+
+func ProvideMigrateToPluginService(cfg *setting.Cfg, sqlStore sqlstore.Store, secretsService secrets.Service,
+	remoteCheck UseRemoteSecretsPluginCheck, serverLockService *serverlock.ServerLockService) *MigrateToPluginService {
+	return &MigrateToPluginService{
+		cfg:               cfg,
+		sqlStore:          sqlStore,
+		secretsService:    secretsService,
+		remoteCheck:       remoteCheck,
+		serverLockService: serverLockService,
+		log:               log.New("secrets.kvstore.migration"),
+	}
+}
+
+func (s *MigrateToPluginService) IsDisabled() bool {
+	return !s.cfg.Raw.Section("secrets").Key("migrate_to_plugin").MustBool(false) || !s.remoteCheck.ShouldUseRemoteSecretsPlugin()
+}
+
+func (s *MigrateToPluginService) Run(ctx context.Context) error {
+	return s.serverLockService.LockAndExecute(ctx, "migrate secrets to plugin", time.Hour, func(ctx context.Context) {
+		secretsPlugin, err := s.remoteCheck.GetPlugin()
+		if err != nil {
+			s.log.Error("Failed to get secretsmanager plugin", "error", err)
+			return
+		}
+
+		from := newSQLStore(s.sqlStore, s.secretsService)
+		to := &secretsKVStorePlugin{secretsPlugin: secretsPlugin, secretsService: s.secretsService, log: s.log}
+		if err := migrateSecrets(ctx, from, to, true); err != nil {
+			s.log.Error("Failed to migrate secrets to plugin", "error", err)
+		}
+	})
+}
+
+// migrateSecrets copies every secret stored in the Grafana database to
+// another store, deleting each of them from the database once copied when
+// deleteMigrated is true. It's safe to run several times.
+func migrateSecrets(ctx context.Context, from *secretsKVStoreSQL, to SecretsKVStore, deleteMigrated bool) error {
+	items, err := from.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := to.Set(ctx, *item.OrgId, *item.Namespace, *item.Type, item.Value); err != nil {
+			return err
+		}
+		if deleteMigrated {
+			if err := from.Del(ctx, *item.OrgId, *item.Namespace, *item.Type); err != nil {
+				return err
+			}
+		}
+	}
+
+	from.log.Info("Migrated secrets", "count", len(items))
+	return nil
+}
+
+func newSQLStore(sqlStore sqlstore.Store, secretsService secrets.Service) *secretsKVStoreSQL {
+	return &secretsKVStoreSQL{
+		sqlStore:       sqlStore,
+		secretsService: secretsService,
+		log:            log.New("secrets.kvstore"),
+		decryptionCache: decryptionCache{
+			cache: make(map[int64]cachedDecrypted),
+		},
+	}
+}
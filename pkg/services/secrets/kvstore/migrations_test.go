@@ -0,0 +1,68 @@
+package kvstore
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	smp "github.com/grafana/grafana/pkg/plugins/backendplugin/secretsmanagerplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/secretsmanagerplugin/reference"
+)
+
+func setupReferencePlugin(t *testing.T) SecretsKVStore {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	smp.RegisterSecretsManagerServer(srv, reference.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &secretsKVStorePlugin{
+		secretsPlugin: &smp.SecretsManagerGRPCClient{SecretsManagerClient: smp.NewSecretsManagerClient(conn)},
+		log:           log.New("test"),
+	}
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	ctx := context.Background()
+	sqlKV := SetupTestService(t).(*secretsKVStoreSQL)
+	pluginKV := setupReferencePlugin(t)
+
+	require.NoError(t, sqlKV.Set(ctx, 1, "prometheus", "datasource", `{"password":"p1"}`))
+	require.NoError(t, sqlKV.Set(ctx, 2, "loki", "datasource", `{"password":"p2"}`))
+
+	require.NoError(t, migrateSecrets(ctx, sqlKV, pluginKV, true))
+
+	value, ok, err := pluginKV.Get(ctx, 1, "prometheus", "datasource")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, `{"password":"p1"}`, value)
+
+	keys, err := pluginKV.Keys(ctx, AllOrganizations, "loki", "datasource")
+	require.NoError(t, err)
+	require.Equal(t, []Key{{OrgId: 2, Namespace: "loki", Type: "datasource"}}, keys)
+
+	remaining, err := sqlKV.GetAll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	// Running the migration again is a no-op.
+	require.NoError(t, migrateSecrets(ctx, sqlKV, pluginKV, true))
+	_, ok, err = pluginKV.Get(ctx, 2, "loki", "datasource")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -1,26 +1,62 @@
 package kvstore
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/secretsmanagerplugin"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
+var errSecretsPluginNotInstalled = errors.New("no secretsmanager plugin is installed")
+
 type UseRemoteSecretsPluginCheck interface {
 	ShouldUseRemoteSecretsPlugin() bool
 	GetPlugin() (secretsmanagerplugin.SecretsManagerPlugin, error)
 }
 
+// OSSRemoteSecretsPluginCheck enables the secretsmanager plugin when
+// [secrets] use_plugin is set and such a plugin is installed.
 type OSSRemoteSecretsPluginCheck struct {
-	UseRemoteSecretsPluginCheck
+	cfg           *setting.Cfg
+	pluginManager plugins.SecretsPluginManager
 }
 
-func ProvideRemotePluginCheck() *OSSRemoteSecretsPluginCheck {
-	return &OSSRemoteSecretsPluginCheck{}
+func ProvideRemotePluginCheck(cfg *setting.Cfg, pluginManager plugins.SecretsPluginManager) *OSSRemoteSecretsPluginCheck {
+	return &OSSRemoteSecretsPluginCheck{
+		cfg:           cfg,
+		pluginManager: pluginManager,
+	}
 }
 
 func (c *OSSRemoteSecretsPluginCheck) ShouldUseRemoteSecretsPlugin() bool {
-	return false
+	if c.cfg == nil || !c.cfg.Raw.Section("secrets").Key("use_plugin").MustBool(false) {
+		return false
+	}
+	return c.pluginManager != nil && c.pluginManager.SecretsManager() != nil
 }
 
+// GetPlugin returns the client of the installed secretsmanager plugin,
+// starting the plugin process if it isn't running yet.
 func (c *OSSRemoteSecretsPluginCheck) GetPlugin() (secretsmanagerplugin.SecretsManagerPlugin, error) {
-	return nil, nil
+	if c.pluginManager == nil {
+		return nil, errSecretsPluginNotInstalled
+	}
+	p := c.pluginManager.SecretsManager()
+	if p == nil {
+		return nil, errSecretsPluginNotInstalled
+	}
+
+	if p.SecretsManager == nil {
+		if err := p.Start(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to start secretsmanager plugin %s: %w", p.ID, err)
+		}
+	}
+	if p.SecretsManager == nil {
+		return nil, fmt.Errorf("secretsmanager plugin %s did not register a secrets manager client", p.ID)
+	}
+
+	return p.SecretsManager, nil
 }
@@ -218,3 +218,28 @@ func (kv *secretsKVStoreSQL) Rename(ctx context.Context, orgId int64, namespace
 		return err
 	})
 }
+
+// GetAll returns all items of the store with their decrypted values.
+func (kv *secretsKVStoreSQL) GetAll(ctx context.Context) ([]Item, error) {
+	var items []Item
+	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.Find(&items)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		decodedValue, err := b64.DecodeString(items[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		decryptedValue, err := kv.secretsService.Decrypt(ctx, decodedValue)
+		if err != nil {
+			return nil, err
+		}
+		items[i].Value = string(decryptedValue)
+	}
+
+	return items, nil
+}
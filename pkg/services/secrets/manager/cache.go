@@ -24,18 +24,26 @@ func (e dataKeyCacheEntry) expired() bool {
 	return e.expiration.Before(now())
 }
 
+// evictable returns true when the entry is expired and can no longer
+// be used as a fallback when the encryption provider is unavailable.
+func (e dataKeyCacheEntry) evictable(fallbackTTL time.Duration) bool {
+	return e.expiration.Add(fallbackTTL).Before(now())
+}
+
 type dataKeyCache struct {
-	mtx      sync.RWMutex
-	byId     map[string]*dataKeyCacheEntry
-	byLabel  map[string]*dataKeyCacheEntry
-	cacheTTL time.Duration
+	mtx         sync.RWMutex
+	byId        map[string]*dataKeyCacheEntry
+	byLabel     map[string]*dataKeyCacheEntry
+	cacheTTL    time.Duration
+	fallbackTTL time.Duration
 }
 
-func newDataKeyCache(ttl time.Duration) *dataKeyCache {
+func newDataKeyCache(ttl, fallbackTTL time.Duration) *dataKeyCache {
 	return &dataKeyCache{
-		byId:     make(map[string]*dataKeyCacheEntry),
-		byLabel:  make(map[string]*dataKeyCacheEntry),
-		cacheTTL: ttl,
+		byId:        make(map[string]*dataKeyCacheEntry),
+		byLabel:     make(map[string]*dataKeyCacheEntry),
+		cacheTTL:    ttl,
+		fallbackTTL: fallbackTTL,
 	}
 }
 
@@ -75,6 +83,36 @@ func (c *dataKeyCache) getByLabel(label string) (*dataKeyCacheEntry, bool) {
 	return entry, true
 }
 
+// getFallbackById returns the entry for the given id even if it is
+// expired, as long as it's still within the fallback TTL. It must only
+// be used when the encryption provider is unavailable.
+func (c *dataKeyCache) getFallbackById(id string) (*dataKeyCacheEntry, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	entry, exists := c.byId[id]
+	if !exists || entry.evictable(c.fallbackTTL) {
+		return nil, false
+	}
+
+	cacheFallbackCounter.With(prometheus.Labels{"method": "byId"}).Inc()
+	return entry, true
+}
+
+// getFallbackByLabel is the equivalent of getFallbackById for labels.
+func (c *dataKeyCache) getFallbackByLabel(label string) (*dataKeyCacheEntry, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	entry, exists := c.byLabel[label]
+	if !exists || entry.evictable(c.fallbackTTL) {
+		return nil, false
+	}
+
+	cacheFallbackCounter.With(prometheus.Labels{"method": "byLabel"}).Inc()
+	return entry, true
+}
+
 func (c *dataKeyCache) add(entry *dataKeyCacheEntry) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -90,13 +128,13 @@ func (c *dataKeyCache) removeExpired() {
 	defer c.mtx.Unlock()
 
 	for id, entry := range c.byId {
-		if entry.expired() {
+		if entry.evictable(c.fallbackTTL) {
 			delete(c.byId, id)
 		}
 	}
 
 	for label, entry := range c.byLabel {
-		if entry.expired() {
+		if entry.evictable(c.fallbackTTL) {
 			delete(c.byLabel, label)
 		}
 	}
@@ -14,6 +14,13 @@ import (
 )
 
 func SetupTestService(tb testing.TB, store secrets.Store) *SecretsService {
+	tb.Helper()
+	return SetupTestServiceWithEncryption(tb, store, ossencryption.ProvideService())
+}
+
+// SetupTestServiceWithEncryption returns a SecretsService encrypting secrets
+// and data keys with the given encryption service.
+func SetupTestServiceWithEncryption(tb testing.TB, store secrets.Store, encryption *ossencryption.Service) *SecretsService {
 	tb.Helper()
 	defaultKey := "SdlklWklckeLS"
 	if len(setting.SecretKey) > 0 {
@@ -25,7 +32,8 @@ func SetupTestService(tb testing.TB, store secrets.Store) *SecretsService {
 
 		[security.encryption]
 		data_keys_cache_ttl = 5m
-		data_keys_cache_cleanup_interval = 1ns`))
+		data_keys_cache_cleanup_interval = 1ns
+		data_keys_cache_fallback_ttl = 0s`))
 	require.NoError(tb, err)
 
 	features := featuremgmt.WithFeatures()
@@ -34,7 +42,6 @@ func SetupTestService(tb testing.TB, store secrets.Store) *SecretsService {
 
 	settings := &setting.OSSImpl{Cfg: cfg}
 
-	encryption := ossencryption.ProvideService()
 	secretsService, err := ProvideSecretsService(
 		store,
 		osskmsproviders.ProvideService(encryption, settings, features),
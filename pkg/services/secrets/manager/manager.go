@@ -70,6 +70,7 @@ func ProvideSecretsService(
 	logger.Info("Envelope encryption state", "enabled", enabled, "current provider", currentProviderID)
 
 	ttl := settings.KeyValue("security.encryption", "data_keys_cache_ttl").MustDuration(15 * time.Minute)
+	fallbackTTL := settings.KeyValue("security.encryption", "data_keys_cache_fallback_ttl").MustDuration(time.Hour)
 
 	s := &SecretsService{
 		store:             store,
@@ -77,7 +78,7 @@ func ProvideSecretsService(
 		settings:          settings,
 		usageStats:        usageStats,
 		providers:         providers,
-		dataKeyCache:      newDataKeyCache(ttl),
+		dataKeyCache:      newDataKeyCache(ttl, fallbackTTL),
 		currentProviderID: currentProviderID,
 		features:          features,
 		log:               logger,
@@ -227,6 +228,11 @@ func (s *SecretsService) dataKeyByLabel(ctx context.Context, label string) (stri
 	// 2.2 Decrypt the data key fetched from the database.
 	decrypted, err := provider.Decrypt(ctx, dataKey.EncryptedData)
 	if err != nil {
+		// If the provider is unavailable, fall back to the expired cache entry, if any.
+		if entry, exists := s.dataKeyCache.getFallbackByLabel(label); exists && entry.active {
+			s.log.Warn("Failed to decrypt data key, using cached data key", "provider", dataKey.Provider, "label", label, "error", err)
+			return entry.id, entry.dataKey, nil
+		}
 		return "", nil, err
 	}
 
@@ -424,9 +430,14 @@ func (s *SecretsService) dataKeyById(ctx context.Context, id string) ([]byte, er
 		return nil, fmt.Errorf("could not find encryption provider '%s'", dataKey.Provider)
 	}
 
-	// 2.2. Encrypt the data key.
+	// 2.2. Decrypt the data key.
 	decrypted, err := provider.Decrypt(ctx, dataKey.EncryptedData)
 	if err != nil {
+		// If the provider is unavailable, fall back to the expired cache entry, if any.
+		if entry, exists := s.dataKeyCache.getFallbackById(id); exists {
+			s.log.Warn("Failed to decrypt data key, using cached data key", "provider", dataKey.Provider, "id", id, "error", err)
+			return entry.dataKey, nil
+		}
 		return nil, err
 	}
 
@@ -445,6 +456,24 @@ func (s *SecretsService) GetProviders() map[secrets.ProviderID]secrets.Provider
 	return s.providers
 }
 
+// CheckProvidersHealth runs the health check of every configured provider
+// that supports it. Providers without health checks are reported as healthy.
+func (s *SecretsService) CheckProvidersHealth(ctx context.Context) map[secrets.ProviderID]error {
+	result := make(map[secrets.ProviderID]error, len(s.providers))
+	for id, p := range s.providers {
+		result[id] = nil
+		if checker, ok := p.(secrets.HealthCheckProvider); ok {
+			result[id] = checker.CheckHealth(ctx)
+		}
+	}
+	return result
+}
+
+// CurrentProviderID returns the identifier of the provider used to encrypt new data keys.
+func (s *SecretsService) CurrentProviderID() secrets.ProviderID {
+	return s.currentProviderID
+}
+
 func (s *SecretsService) RotateDataKeys(ctx context.Context) error {
 	s.log.Info("Data keys rotation triggered, acquiring lock...")
 
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -346,3 +347,74 @@ func TestSecretsService_ReEncryptDataKeys(t *testing.T) {
 		assert.Empty(t, svc.dataKeyCache.byLabel)
 	})
 }
+
+type failingProvider struct {
+	fail bool
+}
+
+func (p *failingProvider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	return blob, nil
+}
+
+func (p *failingProvider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	if p.fail {
+		return nil, errors.New("kms unavailable")
+	}
+	return blob, nil
+}
+
+func (p *failingProvider) CheckHealth(_ context.Context) error {
+	if p.fail {
+		return errors.New("kms unavailable")
+	}
+	return nil
+}
+
+func TestSecretsService_ProviderFallback(t *testing.T) {
+	ctx := context.Background()
+	store := database.ProvideSecretsStore(sqlstore.InitTestDB(t))
+	svc := SetupTestService(t, store)
+
+	provider := &failingProvider{}
+	svc.providers["failing.v1"] = provider
+	svc.currentProviderID = "failing.v1"
+	svc.dataKeyCache.fallbackTTL = time.Hour
+
+	encrypted, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+
+	t.Run("health check reports provider errors", func(t *testing.T) {
+		provider.fail = true
+		t.Cleanup(func() { provider.fail = false })
+
+		health := svc.CheckProvidersHealth(ctx)
+		require.Len(t, health, 2)
+		require.NoError(t, health["secretKey.v1"])
+		require.Error(t, health["failing.v1"])
+	})
+
+	t.Run("expired data keys are used when the provider is unavailable", func(t *testing.T) {
+		provider.fail = true
+		t.Cleanup(func() {
+			provider.fail = false
+			now = time.Now
+		})
+		now = func() time.Time { return time.Now().Add(30 * time.Minute) }
+
+		decrypted, err := svc.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("data keys past the fallback TTL are not used", func(t *testing.T) {
+		provider.fail = true
+		t.Cleanup(func() {
+			provider.fail = false
+			now = time.Now
+		})
+		now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+		_, err := svc.Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+}
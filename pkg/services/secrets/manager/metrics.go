@@ -36,11 +36,23 @@ var (
 			"method": {"byId", "byName"},
 		},
 	)
+	cacheFallbackCounter = metricutil.NewCounterVecStartingAtZero(
+		prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Name:      "encryption_cache_fallbacks_total",
+			Help:      "A counter for expired data keys served from cache because the encryption provider was unavailable",
+		},
+		[]string{"method"},
+		map[string][]string{
+			"method": {"byId", "byLabel"},
+		},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(
 		opsCounter,
 		cacheReadsCounter,
+		cacheFallbackCounter,
 	)
 }
@@ -0,0 +1,210 @@
+// Package migrator upgrades the secrets stored in the database to the
+// encryption algorithm configured in [security.encryption].
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/kmsproviders"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// progressInterval is the number of rows after which the progress of a
+// table is logged.
+const progressInterval = 100
+
+// Report summarizes a re-encryption pass.
+type Report struct {
+	Tables []*TableReport
+}
+
+// Failed returns the number of rows that could not be re-encrypted.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, t := range r.Tables {
+		failed += t.Failed
+	}
+	return failed
+}
+
+// TableReport holds the number of rows of a table by outcome.
+type TableReport struct {
+	Table     string
+	Total     int
+	UpToDate  int
+	Upgraded  int
+	Failed    int
+	Processed int
+}
+
+type SecretsMigrator struct {
+	cfg               *setting.Cfg
+	sqlStore          *sqlstore.SQLStore
+	secretsService    *manager.SecretsService
+	features          featuremgmt.FeatureToggles
+	serverLockService *serverlock.ServerLockService
+	log               log.Logger
+}
+
+func ProvideSecretsMigrator(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, secretsService *manager.SecretsService,
+	features featuremgmt.FeatureToggles, serverLockService *serverlock.ServerLockService) *SecretsMigrator {
+	return &SecretsMigrator{
+		cfg:               cfg,
+		sqlStore:          sqlStore,
+		secretsService:    secretsService,
+		features:          features,
+		serverLockService: serverLockService,
+		log:               log.New("secrets.migrator"),
+	}
+}
+
+// IsDisabled reports whether the re-encryption pass is skipped on startup,
+// which is the default since it can take a while on large databases.
+func (m *SecretsMigrator) IsDisabled() bool {
+	return !m.cfg.Raw.Section("security.encryption").Key("upgrade_secrets_on_startup").MustBool(false)
+}
+
+// Run upgrades the secrets in the background. The server lock ensures that
+// only one instance re-encrypts them when running in high availability.
+func (m *SecretsMigrator) Run(ctx context.Context) error {
+	return m.serverLockService.LockAndExecute(ctx, "upgrade secrets encryption algorithm", time.Hour, func(ctx context.Context) {
+		report, err := m.UpgradeSecrets(ctx)
+		if err != nil {
+			m.log.Error("Failed to upgrade secrets encryption algorithm", "error", err)
+			return
+		}
+		if failed := report.Failed(); failed > 0 {
+			m.log.Warn("Secrets encryption algorithm upgraded with errors", "failed", failed)
+		}
+	})
+}
+
+// UpgradeSecrets re-encrypts every secret that isn't encrypted with the
+// configured algorithm yet. Rows failing to be re-encrypted are counted in
+// the report and don't stop the pass, so it's safe to run it several times.
+func (m *SecretsMigrator) UpgradeSecrets(ctx context.Context) (*Report, error) {
+	algorithm, err := ossencryption.ConfiguredAlgorithm(m.cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("Upgrading secrets encryption algorithm", "algorithm", algorithm)
+
+	report := &Report{}
+
+	// Data keys come first, since the secrets re-encrypted afterwards may
+	// reuse them.
+	if !m.features.IsEnabled(featuremgmt.FlagDisableEnvelopeEncryption) {
+		tr, err := m.upgradeDataKeys(ctx, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		report.Tables = append(report.Tables, tr)
+	}
+
+	for _, t := range secretsTables {
+		tr := &TableReport{Table: t.name()}
+		report.Tables = append(report.Tables, tr)
+
+		if err := t.upgrade(ctx, m, algorithm, tr); err != nil {
+			m.log.Warn("Could not find any secret to re-encrypt", "table", tr.Table, "error", err)
+			continue
+		}
+		m.log.Info("Secrets re-encrypted", "table", tr.Table, "total", tr.Total, "upToDate", tr.UpToDate,
+			"upgraded", tr.Upgraded, "failed", tr.Failed)
+	}
+
+	return report, nil
+}
+
+func (m *SecretsMigrator) upgradeDataKeys(ctx context.Context, algorithm string) (*TableReport, error) {
+	tr := &TableReport{Table: "data_keys"}
+
+	var rows []struct {
+		Provider      secrets.ProviderID
+		EncryptedData []byte
+	}
+	if err := m.sqlStore.NewSession(ctx).Table("data_keys").Cols("provider", "encrypted_data").Find(&rows); err != nil {
+		return nil, err
+	}
+
+	outdated := 0
+	for _, row := range rows {
+		tr.Total++
+		// Other providers rely on the encryption of the external KMS.
+		if kmsproviders.NormalizeProviderID(row.Provider) != kmsproviders.Default || !isOutdated(row.EncryptedData, algorithm) {
+			tr.UpToDate++
+			continue
+		}
+		outdated++
+	}
+	if outdated == 0 {
+		return tr, nil
+	}
+
+	// Data keys can only be re-encrypted all at once.
+	if err := m.secretsService.ReEncryptDataKeys(ctx); err != nil {
+		m.log.Warn("Could not re-encrypt data keys", "error", err)
+		tr.Failed = outdated
+		return tr, nil
+	}
+	tr.Upgraded = outdated
+	return tr, nil
+}
+
+// reEncrypt decrypts and encrypts again a single secret, with the current
+// data key and algorithm.
+func (m *SecretsMigrator) reEncrypt(ctx context.Context, payload []byte, sess *sqlstore.DBSession) ([]byte, error) {
+	decrypted, err := m.secretsService.Decrypt(ctx, payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt secret: %w", err)
+	}
+	encrypted, err := m.secretsService.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt secret: %w", err)
+	}
+	return encrypted, nil
+}
+
+// recordProgress counts the outcome of a row, which is upgraded unless it
+// was already up-to-date or failed to be re-encrypted.
+func (m *SecretsMigrator) recordProgress(tr *TableReport, id int, upgraded bool, err error) {
+	tr.Processed++
+	switch {
+	case err != nil:
+		tr.Failed++
+		m.log.Warn("Could not re-encrypt secret", "table", tr.Table, "id", id, "error", err)
+	case upgraded:
+		tr.Upgraded++
+	default:
+		tr.UpToDate++
+	}
+
+	if tr.Processed%progressInterval == 0 {
+		m.log.Info("Re-encryption progress", "table", tr.Table, "processed", tr.Processed, "total", tr.Total)
+	}
+}
+
+// isOutdated reports whether a payload, encrypted either with envelope
+// encryption or directly with the secret key, uses another algorithm.
+func isOutdated(payload []byte, algorithm string) bool {
+	if len(payload) == 0 {
+		return false
+	}
+
+	payload, ok := secrets.StripDataKeyID(payload)
+	if !ok {
+		return false
+	}
+
+	alg, err := ossencryption.Algorithm(payload)
+	return err == nil && alg != algorithm
+}
@@ -0,0 +1,76 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationUpgradeSecrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore := sqlstore.InitTestDB(t)
+	store := database.ProvideSecretsStore(sqlStore)
+
+	// Encrypt a secret, and the data key along with it, with AES-CFB.
+	legacy := manager.SetupTestService(t, store)
+	encrypted, err := legacy.Encrypt(ctx, []byte("hunter2"), secrets.WithoutScope())
+	require.NoError(t, err)
+	_, err = sqlStore.NewSession(ctx).Exec("INSERT INTO secrets (org_id, namespace, type, value, created, updated) VALUES (1, 'ns', 'type', ?, ?, ?)",
+		base64.RawStdEncoding.EncodeToString(encrypted), nowInUTC(), nowInUTC())
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.SecretsEncryptionAlgorithm = encryption.AesGcm
+	enc, err := ossencryption.ProvideServiceFromConfig(cfg)
+	require.NoError(t, err)
+	secretsService := manager.SetupTestServiceWithEncryption(t, store, enc)
+	m := ProvideSecretsMigrator(cfg, sqlStore, secretsService, featuremgmt.WithFeatures(), nil)
+
+	tableReport := func(t *testing.T, report *Report, table string) *TableReport {
+		t.Helper()
+		for _, tr := range report.Tables {
+			if tr.Table == table {
+				return tr
+			}
+		}
+		t.Fatalf("missing report for %s", table)
+		return nil
+	}
+
+	report, err := m.UpgradeSecrets(ctx)
+	require.NoError(t, err)
+	require.Zero(t, report.Failed())
+	require.Equal(t, &TableReport{Table: "data_keys", Total: 1, Upgraded: 1}, tableReport(t, report, "data_keys"))
+	require.Equal(t, &TableReport{Table: "secrets.value", Total: 1, Upgraded: 1, Processed: 1}, tableReport(t, report, "secrets.value"))
+
+	var value string
+	_, err = sqlStore.NewSession(ctx).SQL("SELECT value FROM secrets").Get(&value)
+	require.NoError(t, err)
+	payload, err := base64.RawStdEncoding.DecodeString(value)
+	require.NoError(t, err)
+	require.False(t, isOutdated(payload, encryption.AesGcm))
+
+	decrypted, err := secretsService.Decrypt(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", string(decrypted))
+
+	// A second pass has nothing left to upgrade.
+	report, err = m.UpgradeSecrets(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, tableReport(t, report, "data_keys").UpToDate)
+	require.Equal(t, &TableReport{Table: "secrets.value", Total: 1, UpToDate: 1, Processed: 1}, tableReport(t, report, "secrets.value"))
+}
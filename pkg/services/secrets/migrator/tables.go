@@ -0,0 +1,213 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type secretsTable interface {
+	name() string
+	upgrade(ctx context.Context, m *SecretsMigrator, algorithm string, tr *TableReport) error
+}
+
+// secretsTables lists the same secrets as the re-encrypt command of
+// grafana-cli.
+var secretsTables = func() []secretsTable {
+	var tables []secretsTable
+	for _, c := range secrets.EncryptedColumns {
+		tables = append(tables, columnSecret{c})
+	}
+	for _, t := range secrets.EncryptedJSONTables {
+		tables = append(tables, jsonSecret{tableName: t})
+	}
+	return append(tables, alertingSecret{})
+}()
+
+func nowInUTC() string {
+	return time.Now().UTC().Format("2006-01-02 15:04:05")
+}
+
+type columnSecret struct {
+	secrets.EncryptedColumn
+}
+
+func (s columnSecret) name() string {
+	return s.Table + "." + s.Column
+}
+
+func (s columnSecret) upgrade(ctx context.Context, m *SecretsMigrator, algorithm string, tr *TableReport) error {
+	var rows []struct {
+		Id     int
+		Secret []byte
+	}
+	if err := m.sqlStore.NewSession(ctx).Table(s.Table).Select(fmt.Sprintf("id, %s as secret", s.Column)).Find(&rows); err != nil {
+		return err
+	}
+	tr.Total = len(rows)
+
+	for _, row := range rows {
+		payload := row.Secret
+		if s.Encoding != nil && len(payload) > 0 {
+			decoded, err := s.Encoding.DecodeString(string(payload))
+			if err != nil {
+				m.recordProgress(tr, row.Id, false, fmt.Errorf("could not decode secret: %w", err))
+				continue
+			}
+			payload = decoded
+		}
+
+		if !isOutdated(payload, algorithm) {
+			m.recordProgress(tr, row.Id, false, nil)
+			continue
+		}
+
+		err := m.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			encrypted, err := m.reEncrypt(ctx, payload, sess)
+			if err != nil {
+				return err
+			}
+
+			var value interface{} = encrypted
+			if s.Encoding != nil {
+				value = s.Encoding.EncodeToString(encrypted)
+			}
+
+			if s.HasUpdatedColumn {
+				_, err = sess.Exec(fmt.Sprintf("UPDATE %s SET %s = ?, updated = ? WHERE id = ?", s.Table, s.Column), value, nowInUTC(), row.Id)
+			} else {
+				_, err = sess.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", s.Table, s.Column), value, row.Id)
+			}
+			return err
+		})
+		m.recordProgress(tr, row.Id, true, err)
+	}
+	return nil
+}
+
+type jsonSecret struct {
+	tableName string
+}
+
+func (s jsonSecret) name() string {
+	return s.tableName + ".secure_json_data"
+}
+
+func (s jsonSecret) upgrade(ctx context.Context, m *SecretsMigrator, algorithm string, tr *TableReport) error {
+	var rows []struct {
+		Id             int
+		SecureJsonData map[string][]byte
+	}
+	if err := m.sqlStore.NewSession(ctx).Table(s.tableName).Cols("id", "secure_json_data").Find(&rows); err != nil {
+		return err
+	}
+	tr.Total = len(rows)
+
+	for _, row := range rows {
+		outdated := false
+		for _, payload := range row.SecureJsonData {
+			if isOutdated(payload, algorithm) {
+				outdated = true
+				break
+			}
+		}
+		if !outdated {
+			m.recordProgress(tr, row.Id, false, nil)
+			continue
+		}
+
+		err := m.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			toUpdate := struct {
+				SecureJsonData map[string][]byte
+				Updated        string
+			}{SecureJsonData: make(map[string][]byte, len(row.SecureJsonData)), Updated: nowInUTC()}
+
+			for k, payload := range row.SecureJsonData {
+				if !isOutdated(payload, algorithm) {
+					toUpdate.SecureJsonData[k] = payload
+					continue
+				}
+				encrypted, err := m.reEncrypt(ctx, payload, sess)
+				if err != nil {
+					return fmt.Errorf("key %s: %w", k, err)
+				}
+				toUpdate.SecureJsonData[k] = encrypted
+			}
+
+			_, err := sess.Table(s.tableName).Where("id = ?", row.Id).Update(toUpdate)
+			return err
+		})
+		m.recordProgress(tr, row.Id, true, err)
+	}
+	return nil
+}
+
+type alertingSecret struct{}
+
+func (alertingSecret) name() string {
+	return "alert_configuration"
+}
+
+func (alertingSecret) upgrade(ctx context.Context, m *SecretsMigrator, algorithm string, tr *TableReport) error {
+	var rows []struct {
+		Id                        int
+		AlertmanagerConfiguration string
+	}
+	if err := m.sqlStore.NewSession(ctx).SQL("SELECT id, alertmanager_configuration FROM alert_configuration").Find(&rows); err != nil {
+		return err
+	}
+	tr.Total = len(rows)
+
+	for _, row := range rows {
+		row := row
+		upgraded := false
+
+		err := m.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			postableUserConfig, err := notifier.Load([]byte(row.AlertmanagerConfiguration))
+			if err != nil {
+				return err
+			}
+
+			for _, receiver := range postableUserConfig.AlertmanagerConfig.Receivers {
+				for _, gmr := range receiver.GrafanaManagedReceivers {
+					for k, v := range gmr.SecureSettings {
+						decoded, err := base64.StdEncoding.DecodeString(v)
+						if err != nil {
+							return fmt.Errorf("could not decode secret %s: %w", k, err)
+						}
+						if !isOutdated(decoded, algorithm) {
+							continue
+						}
+
+						encrypted, err := m.reEncrypt(ctx, decoded, sess)
+						if err != nil {
+							return fmt.Errorf("key %s: %w", k, err)
+						}
+						gmr.SecureSettings[k] = base64.StdEncoding.EncodeToString(encrypted)
+						upgraded = true
+					}
+				}
+			}
+
+			if !upgraded {
+				return nil
+			}
+
+			marshalled, err := json.Marshal(postableUserConfig)
+			if err != nil {
+				return err
+			}
+			row.AlertmanagerConfiguration = string(marshalled)
+			_, err = sess.Table("alert_configuration").Where("id = ?", row.Id).Update(&row)
+			return err
+		})
+		m.recordProgress(tr, row.Id, upgraded, err)
+	}
+	return nil
+}
@@ -28,6 +28,11 @@ type Service interface {
 
 	RotateDataKeys(ctx context.Context) error
 	ReEncryptDataKeys(ctx context.Context) error
+
+	// CheckProvidersHealth returns the result of the health check
+	// of each configured key encryption key provider.
+	CheckProvidersHealth(ctx context.Context) map[ProviderID]error
+	CurrentProviderID() ProviderID
 }
 
 // Store defines methods to interact with secrets storage
@@ -69,3 +74,9 @@ func KeyLabel(scope string, providerID ProviderID) string {
 type BackgroundProvider interface {
 	Run(ctx context.Context) error
 }
+
+// HealthCheckProvider should be implemented for a provider that can report
+// whether its backing key management system is reachable and usable.
+type HealthCheckProvider interface {
+	CheckHealth(ctx context.Context) error
+}
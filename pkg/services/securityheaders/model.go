@@ -0,0 +1,134 @@
+package securityheaders
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrSecurityHeadersNotFound = errors.New("security headers not found")
+	ErrInvalidFrameAncestor    = errors.New("frame ancestors must be 'self', 'none' or http(s) origins")
+	ErrInvalidHSTSMaxAge       = errors.New("strict transport security max age cannot be negative")
+	ErrMissingCSPTemplate      = errors.New("a content security policy template is required when content security policy is enabled")
+)
+
+// OrgSecurityHeaders holds the security header settings of an
+// organization. Fields left empty inherit the value of the [security]
+// section.
+type OrgSecurityHeaders struct {
+	CSPEnabled     *bool   `json:"contentSecurityPolicy,omitempty"`
+	CSPTemplate    *string `json:"contentSecurityPolicyTemplate,omitempty"`
+	AllowEmbedding *bool   `json:"allowEmbedding,omitempty"`
+	// FrameAncestors restricts the origins allowed to embed Grafana using
+	// the frame-ancestors directive. When set, the X-Frame-Options header
+	// is not sent, since it cannot express a list of origins.
+	FrameAncestors                    []string `json:"frameAncestors,omitempty"`
+	StrictTransportSecurity           *bool    `json:"strictTransportSecurity,omitempty"`
+	StrictTransportSecurityMaxAge     *int     `json:"strictTransportSecurityMaxAgeSeconds,omitempty"`
+	StrictTransportSecurityPreload    *bool    `json:"strictTransportSecurityPreload,omitempty"`
+	StrictTransportSecuritySubDomains *bool    `json:"strictTransportSecuritySubdomains,omitempty"`
+}
+
+// Settings are the security header settings in effect for a request.
+type Settings struct {
+	CSPEnabled                        bool
+	CSPTemplate                       string
+	AllowEmbedding                    bool
+	FrameAncestors                    []string
+	StrictTransportSecurity           bool
+	StrictTransportSecurityMaxAge     int
+	StrictTransportSecurityPreload    bool
+	StrictTransportSecuritySubDomains bool
+}
+
+type GetSecurityHeadersQuery struct {
+	OrgID int64
+}
+
+type SaveSecurityHeadersCommand struct {
+	OrgID   int64
+	Headers OrgSecurityHeaders
+}
+
+type DeleteSecurityHeadersCommand struct {
+	OrgID int64
+}
+
+// GlobalSettings returns the settings configured in the [security] section.
+func GlobalSettings(cfg *setting.Cfg) Settings {
+	return Settings{
+		CSPEnabled:                        cfg.CSPEnabled,
+		CSPTemplate:                       cfg.CSPTemplate,
+		AllowEmbedding:                    cfg.AllowEmbedding,
+		StrictTransportSecurity:           cfg.StrictTransportSecurity,
+		StrictTransportSecurityMaxAge:     cfg.StrictTransportSecurityMaxAge,
+		StrictTransportSecurityPreload:    cfg.StrictTransportSecurityPreload,
+		StrictTransportSecuritySubDomains: cfg.StrictTransportSecuritySubDomains,
+	}
+}
+
+// Apply returns a copy of s with the organization overrides applied.
+func (h *OrgSecurityHeaders) Apply(s Settings) Settings {
+	if h == nil {
+		return s
+	}
+	if h.CSPEnabled != nil {
+		s.CSPEnabled = *h.CSPEnabled
+	}
+	if h.CSPTemplate != nil {
+		s.CSPTemplate = *h.CSPTemplate
+	}
+	if h.AllowEmbedding != nil {
+		s.AllowEmbedding = *h.AllowEmbedding
+	}
+	if len(h.FrameAncestors) > 0 {
+		s.FrameAncestors = h.FrameAncestors
+	}
+	if h.StrictTransportSecurity != nil {
+		s.StrictTransportSecurity = *h.StrictTransportSecurity
+	}
+	if h.StrictTransportSecurityMaxAge != nil {
+		s.StrictTransportSecurityMaxAge = *h.StrictTransportSecurityMaxAge
+	}
+	if h.StrictTransportSecurityPreload != nil {
+		s.StrictTransportSecurityPreload = *h.StrictTransportSecurityPreload
+	}
+	if h.StrictTransportSecuritySubDomains != nil {
+		s.StrictTransportSecuritySubDomains = *h.StrictTransportSecuritySubDomains
+	}
+	return s
+}
+
+// Validate checks the overrides against the global settings they are
+// applied to.
+func (h *OrgSecurityHeaders) Validate(global Settings) error {
+	for _, a := range h.FrameAncestors {
+		if !isValidFrameAncestor(a) {
+			return ErrInvalidFrameAncestor
+		}
+	}
+	if h.StrictTransportSecurityMaxAge != nil && *h.StrictTransportSecurityMaxAge < 0 {
+		return ErrInvalidHSTSMaxAge
+	}
+	if s := h.Apply(global); s.CSPEnabled && strings.TrimSpace(s.CSPTemplate) == "" {
+		return ErrMissingCSPTemplate
+	}
+	return nil
+}
+
+func isValidFrameAncestor(raw string) bool {
+	if raw == "'self'" || raw == "'none'" {
+		return true
+	}
+	if strings.ContainsAny(raw, " \t;,'") {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
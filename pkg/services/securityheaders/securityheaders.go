@@ -0,0 +1,18 @@
+package securityheaders
+
+import (
+	"context"
+)
+
+// Service manages per-organization overrides of the security response
+// headers (Content-Security-Policy, HSTS and embedding) configured in the
+// [security] section.
+type Service interface {
+	Get(ctx context.Context, query *GetSecurityHeadersQuery) (*OrgSecurityHeaders, error)
+	Save(ctx context.Context, cmd *SaveSecurityHeadersCommand) error
+	Delete(ctx context.Context, cmd *DeleteSecurityHeadersCommand) error
+	// GetEffectiveSettings returns the global settings with the overrides
+	// of the organization applied. It is called for every request, so
+	// implementations are expected to cache the overrides.
+	GetEffectiveSettings(ctx context.Context, orgID int64) Settings
+}
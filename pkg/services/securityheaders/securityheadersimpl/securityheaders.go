@@ -0,0 +1,109 @@
+package securityheadersimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "security-headers"
+	kvKey       = "org"
+	// cacheTTL bounds how long other instances keep serving outdated
+	// headers after a change.
+	cacheTTL = time.Minute
+)
+
+// Service stores the security header overrides of each organization as a
+// JSON document in the key-value store and caches them, since they are
+// read for every request.
+type Service struct {
+	cfg   *setting.Cfg
+	kv    kvstore.KVStore
+	cache *localcache.CacheService
+	log   log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, kv kvstore.KVStore, cache *localcache.CacheService) securityheaders.Service {
+	return &Service{
+		cfg:   cfg,
+		kv:    kv,
+		cache: cache,
+		log:   log.New("securityheaders"),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *securityheaders.GetSecurityHeadersQuery) (*securityheaders.OrgSecurityHeaders, error) {
+	raw, ok, err := s.kv.Get(ctx, query.OrgID, kvNamespace, kvKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, securityheaders.ErrSecurityHeadersNotFound
+	}
+
+	var result securityheaders.OrgSecurityHeaders
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *securityheaders.SaveSecurityHeadersCommand) error {
+	if err := cmd.Headers.Validate(securityheaders.GlobalSettings(s.cfg)); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(cmd.Headers)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, cmd.OrgID, kvNamespace, kvKey, string(raw)); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *securityheaders.DeleteSecurityHeadersCommand) error {
+	if err := s.kv.Del(ctx, cmd.OrgID, kvNamespace, kvKey); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	return nil
+}
+
+func (s *Service) GetEffectiveSettings(ctx context.Context, orgID int64) securityheaders.Settings {
+	global := securityheaders.GlobalSettings(s.cfg)
+
+	key := cacheKey(orgID)
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.(*securityheaders.OrgSecurityHeaders).Apply(global)
+	}
+
+	headers, err := s.Get(ctx, &securityheaders.GetSecurityHeadersQuery{OrgID: orgID})
+	if err != nil {
+		if !errors.Is(err, securityheaders.ErrSecurityHeadersNotFound) {
+			// Don't cache the failure, the global settings are only a
+			// stopgap until the overrides can be read again.
+			s.log.Error("Failed to get security headers, using global settings", "orgId", orgID, "error", err)
+			return global
+		}
+		headers = nil
+	}
+
+	s.cache.Set(key, headers, cacheTTL)
+	return headers.Apply(global)
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("security-headers-%d", orgID)
+}
@@ -0,0 +1,79 @@
+package securityheadersimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationSecurityHeaders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.StrictTransportSecurity = true
+	cfg.StrictTransportSecurityMaxAge = 86400
+	svc := ProvideService(cfg, kvstore.ProvideService(ss), localcache.ProvideService())
+
+	t.Run("Get without saved headers returns not found", func(t *testing.T) {
+		_, err := svc.Get(context.Background(), &securityheaders.GetSecurityHeadersQuery{OrgID: 1})
+		require.ErrorIs(t, err, securityheaders.ErrSecurityHeadersNotFound)
+	})
+
+	t.Run("Effective settings default to the global settings", func(t *testing.T) {
+		require.Equal(t, securityheaders.GlobalSettings(cfg), svc.GetEffectiveSettings(context.Background(), 1))
+	})
+
+	t.Run("Saved overrides apply to their org only", func(t *testing.T) {
+		enabled, template, maxAge := true, "script-src $NONCE", 600
+		err := svc.Save(context.Background(), &securityheaders.SaveSecurityHeadersCommand{
+			OrgID: 1,
+			Headers: securityheaders.OrgSecurityHeaders{
+				CSPEnabled:                    &enabled,
+				CSPTemplate:                   &template,
+				FrameAncestors:                []string{"'self'", "https://customer.example.com"},
+				StrictTransportSecurityMaxAge: &maxAge,
+			},
+		})
+		require.NoError(t, err)
+
+		settings := svc.GetEffectiveSettings(context.Background(), 1)
+		require.True(t, settings.CSPEnabled)
+		require.Equal(t, template, settings.CSPTemplate)
+		require.Equal(t, []string{"'self'", "https://customer.example.com"}, settings.FrameAncestors)
+		require.True(t, settings.StrictTransportSecurity)
+		require.Equal(t, 600, settings.StrictTransportSecurityMaxAge)
+
+		require.Equal(t, securityheaders.GlobalSettings(cfg), svc.GetEffectiveSettings(context.Background(), 2))
+	})
+
+	t.Run("Invalid overrides are rejected", func(t *testing.T) {
+		enabled, maxAge := true, -1
+		for name, tc := range map[string]struct {
+			headers securityheaders.OrgSecurityHeaders
+			err     error
+		}{
+			"frame ancestor with path":     {securityheaders.OrgSecurityHeaders{FrameAncestors: []string{"https://example.com/app"}}, securityheaders.ErrInvalidFrameAncestor},
+			"frame ancestor injection":     {securityheaders.OrgSecurityHeaders{FrameAncestors: []string{"https://example.com; script-src *"}}, securityheaders.ErrInvalidFrameAncestor},
+			"negative max age":             {securityheaders.OrgSecurityHeaders{StrictTransportSecurityMaxAge: &maxAge}, securityheaders.ErrInvalidHSTSMaxAge},
+			"csp enabled without template": {securityheaders.OrgSecurityHeaders{CSPEnabled: &enabled}, securityheaders.ErrMissingCSPTemplate},
+		} {
+			err := svc.Save(context.Background(), &securityheaders.SaveSecurityHeadersCommand{OrgID: 2, Headers: tc.headers})
+			require.ErrorIs(t, err, tc.err, name)
+		}
+	})
+
+	t.Run("Delete restores the global settings", func(t *testing.T) {
+		err := svc.Delete(context.Background(), &securityheaders.DeleteSecurityHeadersCommand{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, securityheaders.GlobalSettings(cfg), svc.GetEffectiveSettings(context.Background(), 1))
+	})
+}
@@ -0,0 +1,33 @@
+package securityheaderstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+)
+
+type FakeSecurityHeadersService struct {
+	ExpectedHeaders  *securityheaders.OrgSecurityHeaders
+	ExpectedSettings securityheaders.Settings
+	ExpectedError    error
+}
+
+func NewSecurityHeadersServiceFake() *FakeSecurityHeadersService {
+	return &FakeSecurityHeadersService{}
+}
+
+func (f *FakeSecurityHeadersService) Get(ctx context.Context, query *securityheaders.GetSecurityHeadersQuery) (*securityheaders.OrgSecurityHeaders, error) {
+	return f.ExpectedHeaders, f.ExpectedError
+}
+
+func (f *FakeSecurityHeadersService) Save(ctx context.Context, cmd *securityheaders.SaveSecurityHeadersCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeSecurityHeadersService) Delete(ctx context.Context, cmd *securityheaders.DeleteSecurityHeadersCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeSecurityHeadersService) GetEffectiveSettings(ctx context.Context, orgID int64) securityheaders.Settings {
+	return f.ExpectedSettings
+}
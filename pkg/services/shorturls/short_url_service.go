@@ -2,6 +2,7 @@ package shorturls
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -19,15 +20,21 @@ func ProvideService(sqlStore *sqlstore.SQLStore) *ShortURLService {
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *models.SignedInUser, uid string) (*models.ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error)
+	// UpdateLastSeenAt records a visit of the short URL, it sets the last seen time and increments the hit counter
 	UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error
+	SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) (*models.SearchShortUrlsResult, error)
+	DeleteShortURL(ctx context.Context, orgID int64, uid string) error
+	PruneShortURLs(ctx context.Context, cmd *models.PruneShortUrlsCommand) error
 }
 
 type ShortURLService struct {
 	SQLStore *sqlstore.SQLStore
 }
 
+// GetShortURLByUID returns the short URL, or ErrShortURLExpired together with the
+// short URL when it has expired.
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *models.SignedInUser, uid string) (*models.ShortUrl, error) {
 	var shortURL models.ShortUrl
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -44,6 +51,9 @@ func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *models.Sign
 	if err != nil {
 		return nil, err
 	}
+	if shortURL.Expired(getTime()) {
+		return &shortURL, models.ErrShortURLExpired
+	}
 
 	return &shortURL, nil
 }
@@ -51,26 +61,63 @@ func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *models.Sign
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		// Increment in the database so that concurrent visits are all counted
+		_, err := dbSession.Exec("UPDATE short_url SET last_seen_at = ?, hits = hits + 1 WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.Hits++
 
 		return nil
 	})
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
-	now := time.Now().Unix()
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
+	now := getTime()
+
+	uid := util.GenerateShortUID()
+	if cmd.Slug != "" {
+		if !util.IsValidShortUID(cmd.Slug) || util.IsShortUIDTooLong(cmd.Slug) {
+			return nil, models.ErrShortURLInvalidSlug
+		}
+		uid = cmd.Slug
+	}
+
+	var expiresAt int64
+	if !cmd.ExpiresAt.IsZero() {
+		if !cmd.ExpiresAt.After(now) {
+			return nil, models.ErrShortURLInvalidExpiry
+		}
+		expiresAt = cmd.ExpiresAt.Unix()
+	}
+
 	shortURL := models.ShortUrl{
 		OrgId:     user.OrgId,
-		Uid:       util.GenerateShortUID(),
-		Path:      path,
+		Uid:       uid,
+		Path:      cmd.Path,
 		CreatedBy: user.UserId,
-		CreatedAt: now,
+		CreatedAt: now.Unix(),
+		ExpiresAt: expiresAt,
 	}
 
-	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		if cmd.Slug != "" {
+			var existing models.ShortUrl
+			exists, err := session.Where("org_id=? AND uid=?", user.OrgId, uid).Get(&existing)
+			if err != nil {
+				return err
+			}
+			// Expired short URLs give way to new ones with the same slug
+			if exists && !existing.Expired(now) {
+				return models.ErrShortURLSlugTaken
+			}
+			if exists {
+				if _, err := session.ID(existing.Id).Delete(&models.ShortUrl{}); err != nil {
+					return err
+				}
+			}
+		}
+
 		_, err := session.Insert(&shortURL)
 		return err
 	})
@@ -83,9 +130,9 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.Signed
 
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error {
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		var rawSql = "DELETE FROM short_url WHERE (created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)) OR (expires_at > 0 AND expires_at <= ?)"
 
-		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
+		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix(), getTime().Unix()); err != nil {
 			return err
 		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 			return err
@@ -94,4 +141,83 @@ func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.D
 	})
 }
 
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) (*models.SearchShortUrlsResult, error) {
+	result := &models.SearchShortUrlsResult{ShortUrls: make([]*models.ShortUrl, 0)}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		whereSQL := "org_id = ?"
+		params := []interface{}{query.OrgId}
+		if query.Query != "" {
+			like := "%" + query.Query + "%"
+			whereSQL += " AND (uid " + s.SQLStore.GetDialect().LikeStr() + " ? OR path " + s.SQLStore.GetDialect().LikeStr() + " ?)"
+			params = append(params, like, like)
+		}
+		if query.OnlyExpired {
+			whereSQL += " AND expires_at > 0 AND expires_at <= ?"
+			params = append(params, getTime().Unix())
+		}
+
+		count, err := dbSession.Where(whereSQL, params...).Count(&models.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		sess := dbSession.Where(whereSQL, params...)
+		switch strings.ToLower(query.Sort) {
+		case "hits":
+			sess.Desc("hits")
+		case "lastseen":
+			sess.Desc("last_seen_at")
+		default:
+			sess.Desc("created_at")
+		}
+		sess.Asc("id")
+		if query.Limit > 0 {
+			offset := 0
+			if query.Page > 1 {
+				offset = query.Limit * (query.Page - 1)
+			}
+			sess.Limit(query.Limit, offset)
+		}
+		return sess.Find(&result.ShortUrls)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s ShortURLService) DeleteShortURL(ctx context.Context, orgID int64, uid string) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		affected, err := session.Where("org_id=? AND uid=?", orgID, uid).Delete(&models.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrShortURLNotFound
+		}
+		return nil
+	})
+}
+
+func (s ShortURLService) PruneShortURLs(ctx context.Context, cmd *models.PruneShortUrlsCommand) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		rawSQL := "DELETE FROM short_url WHERE org_id = ? AND ((expires_at > 0 AND expires_at <= ?)"
+		params := []interface{}{cmd.OrgId, getTime().Unix()}
+		if !cmd.NotSeenSince.IsZero() {
+			// Short URLs never visited count as seen when they were created
+			rawSQL += " OR (CASE WHEN last_seen_at IS NULL OR last_seen_at = 0 THEN created_at ELSE last_seen_at END) <= ?"
+			params = append(params, cmd.NotSeenSince.Unix())
+		}
+		rawSQL += ")"
+
+		result, err := session.Exec(append([]interface{}{rawSQL}, params...)...)
+		if err != nil {
+			return err
+		}
+		cmd.NumDeleted, err = result.RowsAffected()
+		return err
+	})
+}
+
 var _ Service = &ShortURLService{}
@@ -19,7 +19,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: sqlStore}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -46,10 +46,11 @@ func TestShortURLService(t *testing.T) {
 			updatedShortURL, err := service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
 			require.NoError(t, err)
 			require.Equal(t, expectedTime.Unix(), updatedShortURL.LastSeenAt)
+			require.Equal(t, int64(1), updatedShortURL.Hits)
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -74,6 +75,87 @@ func TestShortURLService(t *testing.T) {
 		})
 	})
 
+	t.Run("User can create short URLs with a custom slug", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/abc", Slug: "team-dashboard"})
+		require.NoError(t, err)
+		require.Equal(t, "team-dashboard", shortURL.Uid)
+
+		_, err = service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/def", Slug: "team-dashboard"})
+		require.ErrorIs(t, err, models.ErrShortURLSlugTaken)
+
+		_, err = service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/def", Slug: "team dashboard"})
+		require.ErrorIs(t, err, models.ErrShortURLInvalidSlug)
+	})
+
+	t.Run("Expired short URLs cannot be looked up and free their slug", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+
+		_, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/abc", ExpiresAt: time.Now().Add(-time.Hour)})
+		require.ErrorIs(t, err, models.ErrShortURLInvalidExpiry)
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{
+			Path:      "d/abc",
+			Slug:      "release-notes",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		getTime = func() time.Time {
+			return time.Now().Add(2 * time.Hour)
+		}
+		_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+		require.ErrorIs(t, err, models.ErrShortURLExpired)
+
+		expired, err := service.SearchShortURLs(context.Background(), &models.SearchShortUrlsQuery{OnlyExpired: true})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), expired.TotalCount)
+		require.Equal(t, "release-notes", expired.ShortUrls[0].Uid)
+
+		_, err = service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/def", Slug: "release-notes"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Org admins can search, delete and prune short URLs", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+		orgUser := &models.SignedInUser{UserId: 1, OrgId: 2}
+
+		popular, err := service.CreateShortURL(context.Background(), orgUser, &models.CreateShortUrlCommand{Path: "d/popular"})
+		require.NoError(t, err)
+		unused, err := service.CreateShortURL(context.Background(), orgUser, &models.CreateShortUrlCommand{Path: "d/unused"})
+		require.NoError(t, err)
+
+		origGetTime := getTime
+		getTime = func() time.Time {
+			return time.Now().Add(time.Hour)
+		}
+		require.NoError(t, service.UpdateLastSeenAt(context.Background(), popular))
+		require.NoError(t, service.UpdateLastSeenAt(context.Background(), popular))
+		getTime = origGetTime
+
+		result, err := service.SearchShortURLs(context.Background(), &models.SearchShortUrlsQuery{OrgId: 2, Sort: "hits"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Equal(t, popular.Uid, result.ShortUrls[0].Uid)
+		require.Equal(t, int64(2), result.ShortUrls[0].Hits)
+
+		result, err = service.SearchShortURLs(context.Background(), &models.SearchShortUrlsQuery{OrgId: 2, Query: "unused"})
+		require.NoError(t, err)
+		require.Len(t, result.ShortUrls, 1)
+
+		cmd := models.PruneShortUrlsCommand{OrgId: 2, NotSeenSince: time.Unix(unused.CreatedAt, 0)}
+		require.NoError(t, service.PruneShortURLs(context.Background(), &cmd))
+		require.Equal(t, int64(1), cmd.NumDeleted)
+
+		require.NoError(t, service.DeleteShortURL(context.Background(), 2, popular.Uid))
+		require.ErrorIs(t, service.DeleteShortURL(context.Background(), 2, popular.Uid), models.ErrShortURLNotFound)
+	})
+
 	t.Run("User cannot look up nonexistent short URLs", func(t *testing.T) {
 		service := ShortURLService{SQLStore: sqlStore}
 
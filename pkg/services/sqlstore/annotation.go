@@ -32,6 +32,31 @@ func validateTimeRange(item *annotations.Item) error {
 	return nil
 }
 
+// normalizeEpoch converts the times sent in microseconds or nanoseconds by some
+// clients to milliseconds. Times in milliseconds don't reach 1e14 before year 5138.
+func normalizeEpoch(epoch int64) int64 {
+	for epoch >= 1e14 {
+		epoch /= 1000
+	}
+	return epoch
+}
+
+// checkClockSkew normalizes the time of an annotation, and moves it back to now
+// when it is in the future by less than the clock skew tolerance. Times further
+// in the future are rejected, they come from clients with a wrong clock.
+func (r *SQLAnnotationRepo) checkClockSkew(epoch *int64, now int64) error {
+	*epoch = normalizeEpoch(*epoch)
+	tolerance := r.sql.Cfg.AnnotationClockSkewTolerance.Milliseconds()
+	if tolerance <= 0 || *epoch <= now {
+		return nil
+	}
+	if *epoch > now+tolerance {
+		return annotations.ErrTimeInFuture
+	}
+	*epoch = now
+	return nil
+}
+
 type SQLAnnotationRepo struct {
 	sql *SQLStore
 }
@@ -49,6 +74,12 @@ func (r *SQLAnnotationRepo) Save(item *annotations.Item) error {
 		if item.Epoch == 0 {
 			item.Epoch = item.Created
 		}
+		if err := r.checkClockSkew(&item.Epoch, item.Created); err != nil {
+			return err
+		}
+		if err := r.checkClockSkew(&item.EpochEnd, item.Created); err != nil {
+			return err
+		}
 		if err := validateTimeRange(item); err != nil {
 			return err
 		}
@@ -93,6 +124,12 @@ func (r *SQLAnnotationRepo) Update(ctx context.Context, item *annotations.Item)
 		existing.Updated = timeNow().UnixNano() / int64(time.Millisecond)
 		existing.Text = item.Text
 
+		if err := r.checkClockSkew(&item.Epoch, existing.Updated); err != nil {
+			return err
+		}
+		if err := r.checkClockSkew(&item.EpochEnd, existing.Updated); err != nil {
+			return err
+		}
 		if item.Epoch != 0 {
 			existing.Epoch = item.Epoch
 		}
@@ -79,6 +79,33 @@ func (acs *AnnotationCleanupService) cleanAnnotations(ctx context.Context, cfg s
 	return totalAffected, nil
 }
 
+// RepairSkewedAnnotations moves the annotations saved further in the future than
+// the clock skew tolerance back to the time they were created, which comes from
+// the clock of the server. Regions whose end is repaired before their start end
+// at their start. Nothing is repaired when the tolerance isn't set.
+func (acs *AnnotationCleanupService) RepairSkewedAnnotations(ctx context.Context, cfg *setting.Cfg) (int64, error) {
+	tolerance := cfg.AnnotationClockSkewTolerance.Milliseconds()
+	if tolerance <= 0 {
+		return 0, nil
+	}
+
+	var totalAffected int64
+	updateQuery := `UPDATE annotation SET %s WHERE id IN (SELECT id FROM (SELECT id FROM annotation WHERE %s %s) a)`
+	for _, repair := range []struct{ set, where string }{
+		{set: "epoch = created", where: fmt.Sprintf("created > 0 AND epoch > created + %d", tolerance)},
+		{set: "epoch_end = created", where: fmt.Sprintf("created > 0 AND epoch_end > created + %d", tolerance)},
+		{set: "epoch_end = epoch", where: "epoch_end < epoch"},
+	} {
+		sql := fmt.Sprintf(updateQuery, repair.set, repair.where, dialect.Limit(acs.batchSize))
+		affected, err := acs.executeUntilDoneOrCancelled(ctx, sql)
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+	return totalAffected, nil
+}
+
 func (acs *AnnotationCleanupService) cleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
 	deleteQuery := `DELETE FROM annotation_tag WHERE id IN ( SELECT id FROM (SELECT id FROM annotation_tag WHERE NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s) a)`
 	sql := fmt.Sprintf(deleteQuery, dialect.Limit(acs.batchSize))
@@ -227,3 +227,48 @@ func createTestAnnotations(t *testing.T, sqlstore *SQLStore, expectedCount int,
 func settingsFn(maxAge time.Duration, maxCount int64) setting.AnnotationCleanupSettings {
 	return setting.AnnotationCleanupSettings{MaxAge: maxAge, MaxCount: maxCount}
 }
+
+func TestIntegrationRepairSkewedAnnotations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	fakeSQL := InitTestDB(t)
+	created := time.Now().UnixNano() / int64(time.Millisecond)
+	hour := time.Hour.Milliseconds()
+
+	session := fakeSQL.NewSession(context.Background())
+	defer session.Close()
+
+	for _, a := range []*annotations.Item{
+		{OrgId: 1, Text: "in time", Created: created, Epoch: created - hour, EpochEnd: created},
+		{OrgId: 1, Text: "within tolerance", Created: created, Epoch: created + hour/2, EpochEnd: created + hour/2},
+		{OrgId: 1, Text: "skewed", Created: created, Epoch: created + 2*hour, EpochEnd: created + 3*hour},
+		{OrgId: 1, Text: "skewed end", Created: created, Epoch: created + hour/2, EpochEnd: created + 3*hour},
+	} {
+		_, err := session.Insert(a)
+		require.NoError(t, err, "cannot insert annotation")
+	}
+
+	cfg := setting.NewCfg()
+	cleaner := &AnnotationCleanupService{batchSize: 1, log: log.New("test-logger"), sqlstore: fakeSQL}
+	repaired, err := cleaner.RepairSkewedAnnotations(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), repaired, "nothing should be repaired without a tolerance")
+
+	cfg.AnnotationClockSkewTolerance = time.Hour
+	repaired, err = cleaner.RepairSkewedAnnotations(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), repaired)
+
+	var items []*annotations.Item
+	require.NoError(t, session.Table("annotation").Asc("id").Find(&items))
+	require.Len(t, items, 4)
+	for i, expected := range [][2]int64{
+		{created - hour, created},
+		{created + hour/2, created + hour/2},
+		{created, created},
+		{created + hour/2, created + hour/2},
+	} {
+		require.Equal(t, expected, [2]int64{items[i].Epoch, items[i].EpochEnd}, items[i].Text)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,7 +57,7 @@ func TestIntegrationAnnotations(t *testing.T) {
 				"title": "Dashboard 1",
 			}),
 		}
-		dashboard, err := dashboardStore.SaveDashboard(testDashboard1)
+		dashboard, err := dashboardStore.SaveDashboard(context.Background(), testDashboard1)
 		require.NoError(t, err)
 
 		testDashboard2 := models.SaveDashboardCommand{
@@ -66,7 +67,7 @@ func TestIntegrationAnnotations(t *testing.T) {
 				"title": "Dashboard 2",
 			}),
 		}
-		dashboard2, err := dashboardStore.SaveDashboard(testDashboard2)
+		dashboard2, err := dashboardStore.SaveDashboard(context.Background(), testDashboard2)
 		require.NoError(t, err)
 
 		annotation := &annotations.Item{
@@ -382,6 +383,53 @@ func TestIntegrationAnnotations(t *testing.T) {
 	})
 }
 
+func TestIntegrationAnnotationClockSkew(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := sqlstore.InitTestDB(t)
+	sql.Cfg.AnnotationClockSkewTolerance = time.Hour
+	repo := sqlstore.NewSQLAnnotationRepo(sql)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	hour := time.Hour.Milliseconds()
+
+	t.Run("Normalizes times in microseconds and nanoseconds", func(t *testing.T) {
+		item := &annotations.Item{OrgId: 1, Epoch: (now - hour) * 1000, EpochEnd: (now - hour) * 1000000}
+		require.NoError(t, repo.Save(item))
+		assert.Equal(t, now-hour, item.Epoch)
+		assert.Equal(t, now-hour, item.EpochEnd)
+	})
+
+	t.Run("Moves times within the tolerance back to now", func(t *testing.T) {
+		item := &annotations.Item{OrgId: 1, Epoch: now + hour/2}
+		require.NoError(t, repo.Save(item))
+		assert.LessOrEqual(t, item.Epoch, item.Created)
+		assert.Equal(t, item.Epoch, item.EpochEnd)
+
+		item.Epoch, item.EpochEnd = now-hour, now+hour/2
+		require.NoError(t, repo.Update(context.Background(), item))
+	})
+
+	t.Run("Rejects times further in the future than the tolerance", func(t *testing.T) {
+		require.ErrorIs(t, repo.Save(&annotations.Item{OrgId: 1, Epoch: now + 2*hour}), annotations.ErrTimeInFuture)
+		require.ErrorIs(t, repo.Save(&annotations.Item{OrgId: 1, Epoch: now, EpochEnd: now + 2*hour}), annotations.ErrTimeInFuture)
+
+		item := &annotations.Item{OrgId: 1, Epoch: now}
+		require.NoError(t, repo.Save(item))
+		item.EpochEnd = now + 2*hour
+		require.ErrorIs(t, repo.Update(context.Background(), item), annotations.ErrTimeInFuture)
+	})
+
+	t.Run("Accepts any time without a tolerance", func(t *testing.T) {
+		sql.Cfg.AnnotationClockSkewTolerance = 0
+		t.Cleanup(func() { sql.Cfg.AnnotationClockSkewTolerance = time.Hour })
+
+		item := &annotations.Item{OrgId: 1, Epoch: now + 2*hour}
+		require.NoError(t, repo.Save(item))
+		assert.Equal(t, now+2*hour, item.Epoch)
+	})
+}
+
 func TestIntegrationAnnotationListingWithRBAC(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -397,7 +445,7 @@ func TestIntegrationAnnotationListingWithRBAC(t *testing.T) {
 			"title": "Dashboard 1",
 		}),
 	}
-	dashboard, err := dashboardStore.SaveDashboard(testDashboard1)
+	dashboard, err := dashboardStore.SaveDashboard(context.Background(), testDashboard1)
 	require.NoError(t, err)
 	dash1UID := dashboard.Uid
 
@@ -408,7 +456,7 @@ func TestIntegrationAnnotationListingWithRBAC(t *testing.T) {
 			"title": "Dashboard 2",
 		}),
 	}
-	_, err = dashboardStore.SaveDashboard(testDashboard2)
+	_, err = dashboardStore.SaveDashboard(context.Background(), testDashboard2)
 	require.NoError(t, err)
 
 	dash1Annotation := &annotations.Item{
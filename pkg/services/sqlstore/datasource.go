@@ -61,6 +61,14 @@ func (ss *SQLStore) GetDataSources(ctx context.Context, query *models.GetDataSou
 	})
 }
 
+// GetAllDataSources returns the datasources of all organizations
+func (ss *SQLStore) GetAllDataSources(ctx context.Context, query *models.GetAllDataSourcesQuery) error {
+	query.Result = make([]*models.DataSource, 0)
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Asc("org_id", "id").Find(&query.Result)
+	})
+}
+
 // GetDataSourcesByType returns all datasources for a given type or an error if the specified type is an empty string
 func (ss *SQLStore) GetDataSourcesByType(ctx context.Context, query *models.GetDataSourcesByTypeQuery) error {
 	if query.Type == "" {
@@ -114,6 +122,14 @@ func (ss *SQLStore) DeleteDataSource(ctx context.Context, cmd *models.DeleteData
 				ac.Scope("datasources", "id", fmt.Sprint(dsQuery.Result.Id))); errDeletingPerms != nil {
 				return errDeletingPerms
 			}
+
+			if _, err := sess.Exec("DELETE FROM star_item WHERE org_id=? AND kind='datasource' AND uid=?", ds.OrgId, ds.Uid); err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec("DELETE FROM data_source_order WHERE org_id=? AND data_source_uid=?", ds.OrgId, ds.Uid); err != nil {
+				return err
+			}
 		}
 
 		// Publish data source deletion event
@@ -168,6 +184,7 @@ func (ss *SQLStore) AddDataSource(ctx context.Context, cmd *models.AddDataSource
 			Updated:         time.Now(),
 			Version:         1,
 			ReadOnly:        cmd.ReadOnly,
+			Origin:          cmd.Origin,
 			Uid:             cmd.Uid,
 		}
 
@@ -228,6 +245,7 @@ func (ss *SQLStore) UpdateDataSource(ctx context.Context, cmd *models.UpdateData
 			SecureJsonData:  cmd.EncryptedSecureJsonData,
 			Updated:         time.Now(),
 			ReadOnly:        cmd.ReadOnly,
+			Origin:          cmd.Origin,
 			Version:         cmd.Version + 1,
 			Uid:             cmd.Uid,
 		}
@@ -261,10 +279,136 @@ func (ss *SQLStore) UpdateDataSource(ctx context.Context, cmd *models.UpdateData
 			return models.ErrDataSourceUpdatingOldVersion
 		}
 
-		err = updateIsDefaultFlag(ds, sess)
+		if err := updateIsDefaultFlag(ds, sess); err != nil {
+			return err
+		}
 
 		cmd.Result = ds
-		return err
+
+		sess.publishAfterCommit(&events.DataSourceUpdated{
+			Timestamp: time.Now(),
+			Name:      cmd.Name,
+			ID:        ds.Id,
+			UID:       cmd.Uid,
+			OrgID:     cmd.OrgId,
+		})
+		return nil
+	})
+}
+
+// SetDataSourceReadOnly locks or unlocks a data source, and records who did it and why.
+func (ss *SQLStore) SetDataSourceReadOnly(ctx context.Context, cmd *models.SetDataSourceReadOnlyCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		ds := &models.DataSource{OrgId: cmd.OrgId, Uid: cmd.Uid}
+		has, err := sess.Get(ds)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDataSourceNotFound
+		}
+
+		ds.ReadOnly = cmd.ReadOnly
+		ds.Updated = time.Now()
+		if _, err := sess.ID(ds.Id).Cols("read_only", "updated").UseBool("read_only").Update(ds); err != nil {
+			return err
+		}
+
+		event := &models.DataSourceLockEvent{
+			OrgId:         cmd.OrgId,
+			DataSourceUid: cmd.Uid,
+			ReadOnly:      cmd.ReadOnly,
+			Reason:        cmd.Reason,
+			Created:       ds.Updated,
+		}
+		if cmd.User != nil {
+			event.UserId = cmd.User.UserId
+			event.UserLogin = cmd.User.Login
+		}
+		if _, err := sess.Insert(event); err != nil {
+			return err
+		}
+
+		cmd.Result = ds
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetDataSourceLockEvents(ctx context.Context, query *models.GetDataSourceLockEventsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		query.Result = make([]*models.DataSourceLockEvent, 0)
+		return sess.Where("org_id=? AND data_source_uid=?", query.OrgId, query.Uid).Desc("id").Find(&query.Result)
+	})
+}
+
+// SetDefaultDataSource makes the data source the only default data source of its organization.
+func (ss *SQLStore) SetDefaultDataSource(ctx context.Context, cmd *models.SetDefaultDataSourceCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		ds := &models.DataSource{OrgId: cmd.OrgId, Uid: cmd.Uid}
+		has, err := sess.Get(ds)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDataSourceNotFound
+		}
+
+		ds.IsDefault = true
+		ds.Updated = time.Now()
+		if _, err := sess.ID(ds.Id).Cols("is_default", "updated").UseBool("is_default").Update(ds); err != nil {
+			return err
+		}
+		if err := updateIsDefaultFlag(ds, sess); err != nil {
+			return err
+		}
+
+		cmd.Result = ds
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetDataSourceOrder(ctx context.Context, query *models.GetDataSourceOrderQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		orders := make([]*models.DataSourceOrder, 0)
+		if err := sess.Where("org_id=?", query.OrgId).Asc("position").Find(&orders); err != nil {
+			return err
+		}
+
+		query.Result = make([]*models.DataSourceGroup, 0)
+		var group *models.DataSourceGroup
+		for _, order := range orders {
+			if group == nil || group.Name != order.GroupName {
+				group = &models.DataSourceGroup{Name: order.GroupName, Uids: make([]string, 0)}
+				query.Result = append(query.Result, group)
+			}
+			group.Uids = append(group.Uids, order.DataSourceUid)
+		}
+		return nil
+	})
+}
+
+// SetDataSourceOrder replaces the order of the data sources of the organization.
+func (ss *SQLStore) SetDataSourceOrder(ctx context.Context, cmd *models.SetDataSourceOrderCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM data_source_order WHERE org_id=?", cmd.OrgId); err != nil {
+			return err
+		}
+
+		position := 0
+		for _, group := range cmd.Groups {
+			for _, uid := range group.Uids {
+				if _, err := sess.Insert(&models.DataSourceOrder{
+					OrgId:         cmd.OrgId,
+					DataSourceUid: uid,
+					GroupName:     group.Name,
+					Position:      position,
+				}); err != nil {
+					return err
+				}
+				position++
+			}
+		}
+		return nil
 	})
 }
 
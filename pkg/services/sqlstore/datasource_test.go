@@ -206,6 +206,133 @@ func TestIntegrationDataAccess(t *testing.T) {
 			err := sqlStore.UpdateDataSource(context.Background(), cmd)
 			require.NoError(t, err)
 		})
+
+		t.Run("keeps the origin if not specified", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			cmd := defaultAddDatasourceCommand
+			cmd.Origin = models.DsOriginFile
+			err := sqlStore.AddDataSource(context.Background(), &cmd)
+			require.NoError(t, err)
+
+			updateCmd := defaultUpdateDatasourceCommand
+			updateCmd.Id = cmd.Result.Id
+			err = sqlStore.UpdateDataSource(context.Background(), &updateCmd)
+			require.NoError(t, err)
+
+			query := models.GetDataSourceQuery{Id: cmd.Result.Id, OrgId: 10}
+			err = sqlStore.GetDataSource(context.Background(), &query)
+			require.NoError(t, err)
+			require.Equal(t, models.DsOriginFile, query.Result.Origin)
+		})
+	})
+
+	t.Run("SetDataSourceReadOnly", func(t *testing.T) {
+		t.Run("locks and unlocks the datasource and records it", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			cmd := defaultAddDatasourceCommand
+			cmd.ReadOnly = true
+			err := sqlStore.AddDataSource(context.Background(), &cmd)
+			require.NoError(t, err)
+			uid := cmd.Result.Uid
+			user := &models.SignedInUser{UserId: 1, Login: "admin"}
+
+			err = sqlStore.SetDataSourceReadOnly(context.Background(), &models.SetDataSourceReadOnlyCommand{
+				OrgId: 10, Uid: uid, ReadOnly: false, Reason: "rotated credentials", User: user,
+			})
+			require.NoError(t, err)
+			query := models.GetDataSourceQuery{Uid: uid, OrgId: 10}
+			err = sqlStore.GetDataSource(context.Background(), &query)
+			require.NoError(t, err)
+			require.False(t, query.Result.ReadOnly)
+
+			err = sqlStore.SetDataSourceReadOnly(context.Background(), &models.SetDataSourceReadOnlyCommand{
+				OrgId: 10, Uid: uid, ReadOnly: true, User: user,
+			})
+			require.NoError(t, err)
+			err = sqlStore.GetDataSource(context.Background(), &query)
+			require.NoError(t, err)
+			require.True(t, query.Result.ReadOnly)
+
+			events := models.GetDataSourceLockEventsQuery{OrgId: 10, Uid: uid}
+			err = sqlStore.GetDataSourceLockEvents(context.Background(), &events)
+			require.NoError(t, err)
+			require.Len(t, events.Result, 2)
+			require.True(t, events.Result[0].ReadOnly)
+			require.False(t, events.Result[1].ReadOnly)
+			require.Equal(t, "rotated credentials", events.Result[1].Reason)
+			require.Equal(t, "admin", events.Result[1].UserLogin)
+		})
+
+		t.Run("returns an error if the datasource doesn't exist", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			err := sqlStore.SetDataSourceReadOnly(context.Background(), &models.SetDataSourceReadOnlyCommand{OrgId: 10, Uid: "unknown"})
+			require.ErrorIs(t, err, models.ErrDataSourceNotFound)
+		})
+	})
+
+	t.Run("SetDefaultDataSource", func(t *testing.T) {
+		t.Run("replaces the default datasource of the organization", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			current := defaultAddDatasourceCommand
+			current.Uid = "current"
+			current.IsDefault = true
+			require.NoError(t, sqlStore.AddDataSource(context.Background(), &current))
+			other := defaultAddDatasourceCommand
+			other.Name = "other"
+			other.Uid = "other"
+			require.NoError(t, sqlStore.AddDataSource(context.Background(), &other))
+
+			cmd := &models.SetDefaultDataSourceCommand{OrgId: 10, Uid: other.Result.Uid}
+			require.NoError(t, sqlStore.SetDefaultDataSource(context.Background(), cmd))
+			require.True(t, cmd.Result.IsDefault)
+
+			query := models.GetDefaultDataSourceQuery{OrgId: 10}
+			require.NoError(t, sqlStore.GetDefaultDataSource(context.Background(), &query))
+			require.Equal(t, other.Result.Uid, query.Result.Uid)
+			dsQuery := models.GetDataSourceQuery{OrgId: 10, Uid: current.Result.Uid}
+			require.NoError(t, sqlStore.GetDataSource(context.Background(), &dsQuery))
+			require.False(t, dsQuery.Result.IsDefault)
+		})
+
+		t.Run("returns an error if the datasource doesn't exist", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			err := sqlStore.SetDefaultDataSource(context.Background(), &models.SetDefaultDataSourceCommand{OrgId: 10, Uid: "unknown"})
+			require.ErrorIs(t, err, models.ErrDataSourceNotFound)
+		})
+	})
+
+	t.Run("DataSourceOrder", func(t *testing.T) {
+		t.Run("replaces the order and forgets the deleted datasources", func(t *testing.T) {
+			sqlStore := InitTestDB(t)
+			ds := initDatasource(sqlStore)
+
+			err := sqlStore.SetDataSourceOrder(context.Background(), &models.SetDataSourceOrderCommand{OrgId: 10, Groups: []*models.DataSourceGroup{
+				{Name: "Production", Uids: []string{"prom", ds.Uid}},
+				{Name: "", Uids: []string{"loki"}},
+			}})
+			require.NoError(t, err)
+			err = sqlStore.SetDataSourceOrder(context.Background(), &models.SetDataSourceOrderCommand{OrgId: 10, Groups: []*models.DataSourceGroup{
+				{Name: "Production", Uids: []string{ds.Uid, "prom"}},
+				{Name: "Staging", Uids: []string{"loki"}},
+			}})
+			require.NoError(t, err)
+
+			query := models.GetDataSourceOrderQuery{OrgId: 10}
+			require.NoError(t, sqlStore.GetDataSourceOrder(context.Background(), &query))
+			require.Equal(t, []*models.DataSourceGroup{
+				{Name: "Production", Uids: []string{ds.Uid, "prom"}},
+				{Name: "Staging", Uids: []string{"loki"}},
+			}, query.Result)
+
+			err = sqlStore.DeleteDataSource(context.Background(), &models.DeleteDataSourceCommand{UID: ds.Uid, OrgID: 10})
+			require.NoError(t, err)
+			require.NoError(t, sqlStore.GetDataSourceOrder(context.Background(), &query))
+			require.Equal(t, []string{"prom"}, query.Result[0].Uids)
+
+			other := models.GetDataSourceOrderQuery{OrgId: 11}
+			require.NoError(t, sqlStore.GetDataSourceOrder(context.Background(), &other))
+			require.Empty(t, other.Result)
+		})
 	})
 
 	t.Run("DeleteDataSourceById", func(t *testing.T) {
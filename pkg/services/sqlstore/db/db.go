@@ -10,5 +10,8 @@ import (
 type DB interface {
 	WithTransactionalDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	WithDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
+	// InTransaction runs fn in a transaction, or in the transaction of ctx when
+	// there is one. The stores called with the context passed to fn join it.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	GetDialect() migrator.Dialect
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 )
 
 type FakeDB struct {
@@ -21,3 +22,14 @@ func (f *FakeDB) WithTransactionalDbSession(ctx context.Context, callback sqlsto
 func (f *FakeDB) WithDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error {
 	return f.ExpectedError
 }
+
+func (f *FakeDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if f.ExpectedError != nil {
+		return f.ExpectedError
+	}
+	return fn(ctx)
+}
+
+func (f *FakeDB) GetDialect() migrator.Dialect {
+	return migrator.NewSQLite3Dialect(nil)
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAccessGrantMigrations(mg *Migrator) {
+	accessGrantV1 := Table{
+		Name: "access_grant",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "scope", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: false},
+			{Name: "granted_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id", "expires"}},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create access_grant table v1", NewAddTableMigration(accessGrantV1))
+
+	mg.AddMigration("add index access_grant.org_id-user_id-expires", NewAddIndexMigration(accessGrantV1, accessGrantV1.Indices[0]))
+	mg.AddMigration("add index access_grant.expires", NewAddIndexMigration(accessGrantV1, accessGrantV1.Indices[1]))
+}
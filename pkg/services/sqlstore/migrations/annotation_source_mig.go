@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnotationSourceMigrations(mg *Migrator) {
+	annotationSourceItemV1 := Table{
+		Name: "annotation_source_item",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "plugin_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "external_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "annotation_id", Type: DB_BigInt, Nullable: false},
+			{Name: "hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "plugin_id", "external_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create annotation_source_item table v1", NewAddTableMigration(annotationSourceItemV1))
+
+	mg.AddMigration("add unique index annotation_source_item.org_id_plugin_id_external_id", NewAddIndexMigration(annotationSourceItemV1, annotationSourceItemV1.Indices[0]))
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnouncementMigrations(mg *Migrator) {
+	announcementV1 := Table{
+		Name: "announcement",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "severity", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "starts_at", Type: DB_DateTime, Nullable: true},
+			{Name: "ends_at", Type: DB_DateTime, Nullable: true},
+			{Name: "org_ids", Type: DB_Text, Nullable: true},
+			{Name: "roles", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create announcement table v1", NewAddTableMigration(announcementV1))
+
+	mg.AddMigration("add index announcement.uid", NewAddIndexMigration(announcementV1, announcementV1.Indices[0]))
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnonymousAccessMigrations(mg *Migrator) {
+	anonymousAccessItemV1 := Table{
+		Name: "anonymous_access_item",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create anonymous_access_item table", NewAddTableMigration(anonymousAccessItemV1))
+	mg.AddMigration("add unique index anonymous_access_item.org_id_kind_uid", NewAddIndexMigration(anonymousAccessItemV1, anonymousAccessItemV1.Indices[0]))
+}
@@ -44,3 +44,27 @@ func addCommentMigrations(mg *Migrator) {
 	mg.AddMigration("add index comment.group_id", NewAddIndexMigration(commentTable, commentTable.Indices[0]))
 	mg.AddMigration("add index comment.created", NewAddIndexMigration(commentTable, commentTable.Indices[1]))
 }
+
+func addCommentThreadMigrations(mg *Migrator) {
+	mg.AddMigration("add parent_id column to comment", NewAddColumnMigration(Table{Name: "comment"}, &Column{
+		Name: "parent_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	commentMentionTable := Table{
+		Name: "comment_mention",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "comment_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"comment_id"}, Type: IndexType},
+			{Cols: []string{"org_id", "user_id"}, Type: IndexType},
+		},
+	}
+	mg.AddMigration("create comment mention table", NewAddTableMigration(commentMentionTable))
+	mg.AddMigration("add index comment_mention.comment_id", NewAddIndexMigration(commentMentionTable, commentMentionTable.Indices[0]))
+	mg.AddMigration("add index comment_mention.org_id_user_id", NewAddIndexMigration(commentMentionTable, commentMentionTable.Indices[1]))
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardArchiveMigrations(mg *Migrator) {
+	dashboardArchiveV1 := Table{
+		Name: "dashboard_archive",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "is_folder", Type: DB_Bool, Nullable: false},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: true},
+			{Name: "reason", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "archived_by", Type: DB_BigInt, Nullable: false},
+			{Name: "archived", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "folder_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_archive table v1", NewAddTableMigration(dashboardArchiveV1))
+
+	mg.AddMigration("add unique index dashboard_archive.org_id_uid", NewAddIndexMigration(dashboardArchiveV1, dashboardArchiveV1.Indices[0]))
+	mg.AddMigration("add index dashboard_archive.org_id_folder_uid", NewAddIndexMigration(dashboardArchiveV1, dashboardArchiveV1.Indices[1]))
+
+	// the bodies of archived dashboards can be offloaded to the archive storage
+	mg.AddMigration("add offloaded column to dashboard_archive", NewAddColumnMigration(dashboardArchiveV1, &Column{
+		Name: "offloaded", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+}
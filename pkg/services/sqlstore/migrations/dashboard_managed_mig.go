@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardManagedMigrations(mg *Migrator) {
+	dashboardManagedV1 := Table{
+		Name: "dashboard_managed",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "managed_by", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "applied_by", Type: DB_BigInt, Nullable: false},
+			{Name: "applied_version", Type: DB_Int, Nullable: false},
+			{Name: "checksum", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "managed_by"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_managed table v1", NewAddTableMigration(dashboardManagedV1))
+
+	mg.AddMigration("add unique index dashboard_managed.org_id_dashboard_uid", NewAddIndexMigration(dashboardManagedV1, dashboardManagedV1.Indices[0]))
+	mg.AddMigration("add index dashboard_managed.org_id_managed_by", NewAddIndexMigration(dashboardManagedV1, dashboardManagedV1.Indices[1]))
+}
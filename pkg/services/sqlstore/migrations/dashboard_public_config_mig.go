@@ -30,4 +30,14 @@ func addPublicDashboardMigration(mg *Migrator) {
 	// recreate table with proper primary key type
 	mg.AddMigration("recreate dashboard public config v1", NewAddTableMigration(dashboardPublicCfgV1))
 	addTableIndicesMigrations(mg, "v1", dashboardPublicCfgV1)
+
+	// anonymous users access public dashboards with a secret access token
+	// instead of the uid, which isn't random enough to be kept secret.
+	// public dashboards saved before have no token until they are saved again.
+	mg.AddMigration("add access_token column to dashboard_public_config", NewAddColumnMigration(dashboardPublicCfgV1, &Column{
+		Name: "access_token", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+	mg.AddMigration("add unique index dashboard_public_config.access_token", NewAddIndexMigration(dashboardPublicCfgV1, &Index{
+		Cols: []string{"access_token"}, Type: UniqueIndex,
+	}))
 }
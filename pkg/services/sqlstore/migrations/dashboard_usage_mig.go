@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	dashboardUsageV1 := Table{
+		Name: "dashboard_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "last_viewed", Type: DB_BigInt, Nullable: false},
+			{Name: "last_queried", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "last_viewed"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage table v1", NewAddTableMigration(dashboardUsageV1))
+
+	mg.AddMigration("add unique index dashboard_usage.dashboard_id", NewAddIndexMigration(dashboardUsageV1, dashboardUsageV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage.org_id_last_viewed", NewAddIndexMigration(dashboardUsageV1, dashboardUsageV1.Indices[1]))
+}
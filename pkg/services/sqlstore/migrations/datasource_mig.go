@@ -134,4 +134,45 @@ func addDataSourceMigration(mg *Migrator) {
 
 	mg.AddMigration("add unique index datasource_org_id_is_default", NewAddIndexMigration(tableV2, &Index{
 		Cols: []string{"org_id", "is_default"}}))
+
+	mg.AddMigration("Add origin column", NewAddColumnMigration(tableV2, &Column{
+		Name: "origin", Type: DB_NVarchar, Length: 20, Nullable: true,
+	}))
+
+	lockEventV1 := Table{
+		Name: "data_source_lock_event",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "read_only", Type: DB_Bool, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_uid"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_lock_event table", NewAddTableMigration(lockEventV1))
+	mg.AddMigration("add index data_source_lock_event.org_id_data_source_uid", NewAddIndexMigration(lockEventV1, lockEventV1.Indices[0]))
+
+	orderV1 := Table{
+		Name: "data_source_order",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "position", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create data_source_order table", NewAddTableMigration(orderV1))
+	mg.AddMigration("add unique index data_source_order.org_id_data_source_uid", NewAddIndexMigration(orderV1, orderV1.Indices[0]))
 }
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addEmailDeliveryMigrations(mg *Migrator) {
+	emailDeliveryV1 := Table{
+		Name: "email_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "provider", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "recipient", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "subject", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"message_id"}},
+			{Cols: []string{"recipient"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create email_delivery table v1", NewAddTableMigration(emailDeliveryV1))
+
+	mg.AddMigration("add index email_delivery.message_id", NewAddIndexMigration(emailDeliveryV1, emailDeliveryV1.Indices[0]))
+	mg.AddMigration("add index email_delivery.recipient", NewAddIndexMigration(emailDeliveryV1, emailDeliveryV1.Indices[1]))
+	mg.AddMigration("add index email_delivery.created", NewAddIndexMigration(emailDeliveryV1, emailDeliveryV1.Indices[2]))
+}
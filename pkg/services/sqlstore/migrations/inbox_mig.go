@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addInboxMigrations(mg *Migrator) {
+	inboxNotificationV1 := Table{
+		Name: "inbox_notification",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "body", Type: DB_Text, Nullable: false},
+			{Name: "url", Type: DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "dedup_key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "is_read", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id", "is_read"}},
+			{Cols: []string{"org_id", "user_id", "dedup_key"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create inbox_notification table v1", NewAddTableMigration(inboxNotificationV1))
+
+	mg.AddMigration("add index inbox_notification.org_id-user_id-is_read", NewAddIndexMigration(inboxNotificationV1, inboxNotificationV1.Indices[0]))
+	mg.AddMigration("add index inbox_notification.org_id-user_id-dedup_key", NewAddIndexMigration(inboxNotificationV1, inboxNotificationV1.Indices[1]))
+	mg.AddMigration("add index inbox_notification.created", NewAddIndexMigration(inboxNotificationV1, inboxNotificationV1.Indices[2]))
+}
@@ -74,11 +74,13 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAlertingPermissionsMigrator(mg)
 
 	addQueryHistoryStarMigrations(mg)
+	addQueryHistoryTeamStarMigrations(mg)
 
 	if mg.Cfg != nil && mg.Cfg.IsFeatureToggleEnabled != nil {
 		if mg.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagDashboardComments) || mg.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagAnnotationComments) {
 			addCommentGroupMigrations(mg)
 			addCommentMigrations(mg)
+			addCommentThreadMigrations(mg)
 		}
 	}
 
@@ -87,6 +89,22 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	addPublicDashboardMigration(mg)
 	ualert.CreateDefaultFoldersForAlertingMigration(mg)
 	addDbFileStorageMigration(mg)
+	addTimeRegionCalendarMigrations(mg)
+	addStarItemMigrations(mg)
+	addInboxMigrations(mg)
+	addAnnouncementMigrations(mg)
+	addResourceOwnershipMigrations(mg)
+	addDashboardManagedMigrations(mg)
+	addResourceLabelMigrations(mg)
+	addAnnotationSourceMigrations(mg)
+	addStatsHistoryMigrations(mg)
+	addAccessGrantMigrations(mg)
+	addEmailDeliveryMigrations(mg)
+	addDashboardArchiveMigrations(mg)
+	addOrgInviteMigrations(mg)
+	addDashboardUsageMigrations(mg)
+	addQuerySchemaMigrations(mg)
+	addAnonymousAccessMigrations(mg)
 
 	accesscontrol.AddManagedPermissionsMigration(mg, accesscontrol.ManagedPermissionsMigrationID)
 	accesscontrol.AddManagedFolderAlertActionsMigration(mg)
@@ -125,3 +143,25 @@ func addStarMigrations(mg *Migrator) {
 	mg.AddMigration("create star table", NewAddTableMigration(starV1))
 	mg.AddMigration("add unique index star.user_id_dashboard_id", NewAddIndexMigration(starV1, starV1.Indices[0]))
 }
+
+func addStarItemMigrations(mg *Migrator) {
+	starItemV1 := Table{
+		Name: "star_item",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "org_id", "kind", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "kind", "uid"}},
+		},
+	}
+
+	mg.AddMigration("create star_item table", NewAddTableMigration(starItemV1))
+	mg.AddMigration("add unique index star_item.user_id_org_id_kind_uid", NewAddIndexMigration(starItemV1, starItemV1.Indices[0]))
+	mg.AddMigration("add index star_item.org_id_kind_uid", NewAddIndexMigration(starItemV1, starItemV1.Indices[1]))
+}
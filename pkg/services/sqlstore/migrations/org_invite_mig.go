@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addOrgInviteMigrations(mg *Migrator) {
+	orgInviteLinkV1 := Table{
+		Name: "org_invite_link",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "code", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "require_approval", Type: DB_Bool, Nullable: false},
+			{Name: "max_uses", Type: DB_BigInt, Nullable: false},
+			{Name: "uses", Type: DB_BigInt, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"code"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "expires"}},
+		},
+	}
+
+	mg.AddMigration("create org_invite_link table v1", NewAddTableMigration(orgInviteLinkV1))
+
+	mg.AddMigration("add unique index org_invite_link.code", NewAddIndexMigration(orgInviteLinkV1, orgInviteLinkV1.Indices[0]))
+	mg.AddMigration("add unique index org_invite_link.org_id-uid", NewAddIndexMigration(orgInviteLinkV1, orgInviteLinkV1.Indices[1]))
+	mg.AddMigration("add index org_invite_link.org_id-expires", NewAddIndexMigration(orgInviteLinkV1, orgInviteLinkV1.Indices[2]))
+
+	orgAccessRequestV1 := Table{
+		Name: "org_access_request",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "link_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "reviewed_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "reviewed", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "status"}},
+			{Cols: []string{"org_id", "user_id"}},
+		},
+	}
+
+	mg.AddMigration("create org_access_request table v1", NewAddTableMigration(orgAccessRequestV1))
+
+	mg.AddMigration("add index org_access_request.org_id-status", NewAddIndexMigration(orgAccessRequestV1, orgAccessRequestV1.Indices[0]))
+	mg.AddMigration("add index org_access_request.org_id-user_id", NewAddIndexMigration(orgAccessRequestV1, orgAccessRequestV1.Indices[1]))
+}
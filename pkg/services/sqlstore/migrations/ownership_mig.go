@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addResourceOwnershipMigrations(mg *Migrator) {
+	resourceOwnershipV1 := Table{
+		Name: "resource_ownership",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "resource_kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "owner_kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "owner_id", Type: DB_BigInt, Nullable: false},
+			{Name: "links", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "resource_kind", "resource_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "owner_kind", "owner_id"}},
+		},
+	}
+
+	mg.AddMigration("create resource_ownership table v1", NewAddTableMigration(resourceOwnershipV1))
+
+	mg.AddMigration("add unique index resource_ownership.org_id_resource_kind_resource_uid", NewAddIndexMigration(resourceOwnershipV1, resourceOwnershipV1.Indices[0]))
+	mg.AddMigration("add index resource_ownership.org_id_owner_kind_owner_id", NewAddIndexMigration(resourceOwnershipV1, resourceOwnershipV1.Indices[1]))
+}
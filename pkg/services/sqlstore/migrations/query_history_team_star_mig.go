@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addQueryHistoryTeamStarMigrations(mg *Migrator) {
+	queryHistoryTeamStarV1 := Table{
+		Name: "query_history_team_star",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"team_id", "query_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "query_uid"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_team_star table v1", NewAddTableMigration(queryHistoryTeamStarV1))
+
+	mg.AddMigration("add index query_history_team_star.team_id-query_uid", NewAddIndexMigration(queryHistoryTeamStarV1, queryHistoryTeamStarV1.Indices[0]))
+	mg.AddMigration("add index query_history_team_star.org_id-query_uid", NewAddIndexMigration(queryHistoryTeamStarV1, queryHistoryTeamStarV1.Indices[1]))
+}
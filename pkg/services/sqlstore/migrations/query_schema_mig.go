@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addQuerySchemaMigrations(mg *Migrator) {
+	querySchemaV1 := Table{
+		Name: "query_schema",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "ref_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "fields", Type: DB_Text, Nullable: false},
+			{Name: "hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "first_seen", Type: DB_BigInt, Nullable: false},
+			{Name: "last_seen", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "ref_id"}},
+			{Cols: []string{"last_seen"}},
+		},
+	}
+
+	mg.AddMigration("create query_schema table v1", NewAddTableMigration(querySchemaV1))
+
+	mg.AddMigration("add index query_schema.org_id_dashboard_uid_panel_id_ref_id", NewAddIndexMigration(querySchemaV1, querySchemaV1.Indices[0]))
+	mg.AddMigration("add index query_schema.last_seen", NewAddIndexMigration(querySchemaV1, querySchemaV1.Indices[1]))
+}
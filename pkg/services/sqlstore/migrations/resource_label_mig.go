@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addResourceLabelMigrations(mg *Migrator) {
+	resourceLabelV1 := Table{
+		Name: "resource_label",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "resource_kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 63, Nullable: false},
+			{Name: "value", Type: DB_NVarchar, Length: 63, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "resource_kind", "resource_uid", "name"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "name", "value"}},
+		},
+	}
+
+	mg.AddMigration("create resource_label table v1", NewAddTableMigration(resourceLabelV1))
+
+	mg.AddMigration("add unique index resource_label.org_id_resource_kind_resource_uid_name", NewAddIndexMigration(resourceLabelV1, resourceLabelV1.Indices[0]))
+	mg.AddMigration("add index resource_label.org_id_name_value", NewAddIndexMigration(resourceLabelV1, resourceLabelV1.Indices[1]))
+}
@@ -24,4 +24,11 @@ func addShortURLMigrations(mg *Migrator) {
 	mg.AddMigration("create short_url table v1", NewAddTableMigration(shortURLV1))
 
 	mg.AddMigration("add index short_url.org_id-uid", NewAddIndexMigration(shortURLV1, shortURLV1.Indices[0]))
+
+	mg.AddMigration("add expires_at column to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "expires_at", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add hits column to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "hits", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addStatsHistoryMigrations(mg *Migrator) {
+	statsHistoryV1 := Table{
+		Name: "stats_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "day", Type: DB_Varchar, Length: 10, Nullable: false},
+			{Name: "users", Type: DB_BigInt, Nullable: false},
+			{Name: "active_users", Type: DB_BigInt, Nullable: false},
+			{Name: "daily_active_users", Type: DB_BigInt, Nullable: false},
+			{Name: "orgs", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboards", Type: DB_BigInt, Nullable: false},
+			{Name: "folders", Type: DB_BigInt, Nullable: false},
+			{Name: "datasources", Type: DB_BigInt, Nullable: false},
+			{Name: "alerts", Type: DB_BigInt, Nullable: false},
+			{Name: "alert_rules", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"day"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create stats_history table v1", NewAddTableMigration(statsHistoryV1))
+
+	mg.AddMigration("add unique index stats_history.day", NewAddIndexMigration(statsHistoryV1, statsHistoryV1.Indices[0]))
+}
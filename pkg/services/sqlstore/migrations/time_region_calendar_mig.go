@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addTimeRegionCalendarMigrations(mg *Migrator) {
+	timeRegionCalendarV1 := Table{
+		Name: "time_region_calendar",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "regions", Type: DB_Text, Nullable: false},
+			{Name: "holidays", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create time_region_calendar table v1", NewAddTableMigration(timeRegionCalendarV1))
+
+	mg.AddMigration("add index time_region_calendar.org_id-uid", NewAddIndexMigration(timeRegionCalendarV1, timeRegionCalendarV1.Indices[0]))
+	mg.AddMigration("add index time_region_calendar.org_id-name", NewAddIndexMigration(timeRegionCalendarV1, timeRegionCalendarV1.Indices[1]))
+}
@@ -27,6 +27,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	AddProvisioningMigrations(mg)
 
 	AddAlertImageMigrations(mg)
+
+	// Create the table of the series written by recording rules
+	AddRecordingRuleMigrations(mg)
+
+	// Create alert rule templates and the rules created from them
+	AddAlertRuleTemplateMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -379,3 +385,80 @@ func AddAlertImageMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create alert_image table", migrator.NewAddTableMigration(imageTable))
 	mg.AddMigration("add unique index on token to alert_image table", migrator.NewAddIndexMigration(imageTable, imageTable.Indices[0]))
 }
+
+func AddRecordingRuleMigrations(mg *migrator.Migrator) {
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "record", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "record", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	recordedSample := migrator.Table{
+		Name: "alert_recorded_sample",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "metric", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "epoch", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "value", Type: migrator.DB_Double, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "metric", "epoch"}},
+			{Cols: []string{"epoch"}},
+		},
+	}
+	mg.AddMigration("create alert_recorded_sample table", migrator.NewAddTableMigration(recordedSample))
+	mg.AddMigration("add index in alert_recorded_sample on org_id, metric and epoch columns", migrator.NewAddIndexMigration(recordedSample, recordedSample.Indices[0]))
+	mg.AddMigration("add index in alert_recorded_sample on epoch column", migrator.NewAddIndexMigration(recordedSample, recordedSample.Indices[1]))
+}
+
+func AddAlertRuleTemplateMigrations(mg *migrator.Migrator) {
+	alertRuleTemplate := migrator.Table{
+		Name: "alert_rule_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: migrator.DB_Text, Nullable: true},
+			{Name: "condition", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "no_data_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false},
+			{Name: "exec_err_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false},
+			{Name: "for", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "annotations", Type: migrator.DB_Text, Nullable: true},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+			{Name: "parameters", Type: migrator.DB_Text, Nullable: true},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_rule_template table", migrator.NewAddTableMigration(alertRuleTemplate))
+	mg.AddMigration("add unique index in alert_rule_template on org_id and uid columns", migrator.NewAddIndexMigration(alertRuleTemplate, alertRuleTemplate.Indices[0]))
+
+	alertRuleTemplateInstance := migrator.Table{
+		Name: "alert_rule_template_instance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "template_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "template_version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "parameters", Type: migrator.DB_Text, Nullable: true},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "template_uid"}},
+		},
+	}
+	mg.AddMigration("create alert_rule_template_instance table", migrator.NewAddTableMigration(alertRuleTemplateInstance))
+	mg.AddMigration("add unique index in alert_rule_template_instance on org_id and rule_uid columns", migrator.NewAddIndexMigration(alertRuleTemplateInstance, alertRuleTemplateInstance.Indices[0]))
+	mg.AddMigration("add index in alert_rule_template_instance on org_id and template_uid columns", migrator.NewAddIndexMigration(alertRuleTemplateInstance, alertRuleTemplateInstance.Indices[1]))
+}
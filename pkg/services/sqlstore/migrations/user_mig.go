@@ -139,6 +139,11 @@ func addUserMigrations(mg *Migrator) {
 			SQLite(migSQLITEisServiceAccountNullable).
 			Postgres("ALTER TABLE `user` ALTER COLUMN is_service_account DROP NOT NULL;").
 			Mysql("ALTER TABLE user MODIFY is_service_account BOOLEAN DEFAULT 0;"))
+
+	// password_changed is used to expire the passwords, it is null for the passwords set before it was added.
+	mg.AddMigration("Add password_changed column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "password_changed", Type: DB_DateTime, Nullable: true,
+	}))
 }
 
 const migSQLITEisServiceAccountNullable = `ALTER TABLE user ADD COLUMN tmp_service_account BOOLEAN DEFAULT 0;
@@ -31,6 +31,7 @@ type SQLStoreMock struct {
 	ExpectedDatasources            []*models.DataSource
 	ExpectedOrg                    *models.Org
 	ExpectedSystemStats            *models.SystemStats
+	ExpectedStatsHistory           []*models.StatsHistory
 	ExpectedDataSourceStats        []*models.DataSourceStats
 	ExpectedDataSources            []*models.DataSource
 	ExpectedDataSourcesAccessStats []*models.DataSourceAccessStats
@@ -73,6 +74,19 @@ func (m *SQLStoreMock) GetSystemStats(ctx context.Context, query *models.GetSyst
 	return m.ExpectedError
 }
 
+func (m *SQLStoreMock) SaveStatsHistory(ctx context.Context, cmd *models.SaveStatsHistoryCommand) error {
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) GetStatsHistory(ctx context.Context, query *models.GetStatsHistoryQuery) error {
+	query.Result = m.ExpectedStatsHistory
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) DeleteExpiredStatsHistory(ctx context.Context, cmd *models.DeleteExpiredStatsHistoryCommand) error {
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) DeleteExpiredSnapshots(ctx context.Context, cmd *models.DeleteExpiredSnapshotsCommand) error {
 	return m.ExpectedError
 }
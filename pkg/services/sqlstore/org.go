@@ -230,8 +230,11 @@ func (ss *SQLStore) DeleteOrg(ctx context.Context, cmd *models.DeleteOrgCommand)
 			"DELETE FROM star WHERE EXISTS (SELECT 1 FROM dashboard WHERE org_id = ? AND star.dashboard_id = dashboard.id)",
 			"DELETE FROM dashboard_tag WHERE EXISTS (SELECT 1 FROM dashboard WHERE org_id = ? AND dashboard_tag.dashboard_id = dashboard.id)",
 			"DELETE FROM dashboard WHERE org_id = ?",
+			"DELETE FROM dashboard_usage WHERE org_id = ?",
+			"DELETE FROM query_schema WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
+			"DELETE FROM data_source_order WHERE org_id = ?",
 			"DELETE FROM org_user WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
@@ -243,9 +246,17 @@ func (ss *SQLStore) DeleteOrg(ctx context.Context, cmd *models.DeleteOrgCommand)
 			"DELETE FROM alert_rule WHERE org_id = ?",
 			"DELETE FROM alert_rule_tag WHERE EXISTS (SELECT 1 FROM alert WHERE alert.org_id = ? AND alert.id = alert_rule_tag.alert_id)",
 			"DELETE FROM alert_rule_version WHERE rule_org_id = ?",
+			"DELETE FROM alert_recorded_sample WHERE org_id = ?",
+			"DELETE FROM alert_rule_template WHERE org_id = ?",
+			"DELETE FROM alert_rule_template_instance WHERE org_id = ?",
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
+			"DELETE FROM time_region_calendar WHERE org_id = ?",
+			"DELETE FROM star_item WHERE org_id = ?",
+			"DELETE FROM anonymous_access_item WHERE org_id = ?",
+			"DELETE FROM inbox_notification WHERE org_id = ?",
+			"DELETE FROM preferences WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -255,6 +266,11 @@ func (ss *SQLStore) DeleteOrg(ctx context.Context, cmd *models.DeleteOrgCommand)
 			}
 		}
 
+		sess.publishAfterCommit(&events.OrgDeleted{
+			Timestamp: time.Now(),
+			Id:        cmd.Id,
+		})
+
 		return nil
 	})
 }
@@ -16,6 +16,10 @@ type DashboardPermissionFilter struct {
 	UserId          int64
 	OrgId           int64
 	PermissionLevel models.PermissionType
+	// FolderDepth is the number of levels of folders whose permissions apply
+	// to the dashboards, 1 when folders aren't nested. 0 applies the
+	// permissions of up to models.MaxNestedFolderDepth levels.
+	FolderDepth int
 }
 
 func (d DashboardPermissionFilter) Where() (string, []interface{}) {
@@ -28,16 +32,17 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 		okRoles = append(okRoles, models.ROLE_VIEWER)
 	}
 
-	falseStr := d.Dialect.BooleanStr(false)
+	// the permissions of the folders apply to the dashboards and folders nested in them
+	folderJoins, folderIDs, defaultPermissions := FolderAncestorsSQL("d", d.Dialect, d.FolderDepth)
 
 	sql := `(
 		dashboard.id IN (
 			SELECT distinct DashboardId from (
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
+					FROM dashboard AS d` + folderJoins + `
 					LEFT JOIN dashboard_acl AS da ON
 						da.dashboard_id = d.id OR
-						da.dashboard_id = d.folder_id
+						da.dashboard_id IN (` + folderIDs + `)
 					WHERE
 						d.org_id = ? AND
 						da.permission >= ? AND
@@ -48,15 +53,11 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 						)
 				UNION
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					FROM dashboard AS d` + folderJoins + `
 					LEFT JOIN dashboard_acl AS da ON
 						(
 							-- include default permissions -->
-							da.org_id = -1 AND (
-							  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-							  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
-							)
+							da.org_id = -1 AND (` + defaultPermissions + `)
 						)
 					WHERE
 						d.org_id = ? AND
@@ -78,7 +79,11 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 }
 
 type AccessControlDashboardPermissionFilter struct {
-	User             *models.SignedInUser
+	User *models.SignedInUser
+	// FolderDepth is the number of levels of folders whose permissions apply
+	// to the dashboards, 1 when folders aren't nested. 0 applies the
+	// permissions of up to models.MaxNestedFolderDepth levels.
+	FolderDepth      int
 	dashboardActions []string
 	folderActions    []string
 }
@@ -115,12 +120,15 @@ func (f AccessControlDashboardPermissionFilter) Where() (string, []interface{})
 		builder.WriteString(dashFilter.Where)
 		args = append(args, dashFilter.Args...)
 
-		builder.WriteString(" OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE ")
-		dashFolderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.dashboardActions...)
+		subtree, subtreeArgs := folderSubtree(f.User, f.dashboardActions, f.folderDepth())
+		builder.WriteString(" OR dashboard.folder_id IN(" + subtree + ")")
+		args = append(args, subtreeArgs...)
 
-		builder.WriteString(dashFolderFilter.Where)
+		builder.WriteString(" OR dashboard.id IN(SELECT dashboard_id FROM dashboard_tag WHERE ")
+		dashTagFilter, _ := accesscontrol.Filter(f.User, "dashboard_tag.term", dashboards.ScopeDashboardsTagPrefix, f.dashboardActions...)
+		builder.WriteString(dashTagFilter.Where)
 		builder.WriteString(")) AND NOT dashboard.is_folder)")
-		args = append(args, dashFolderFilter.Args...)
+		args = append(args, dashTagFilter.Args...)
 	}
 
 	if len(f.folderActions) > 0 {
@@ -129,10 +137,38 @@ func (f AccessControlDashboardPermissionFilter) Where() (string, []interface{})
 		}
 		builder.WriteString("(")
 		folderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.folderActions...)
-		builder.WriteString(folderFilter.Where)
+		if len(folderFilter.Args) == 0 || f.folderDepth() == 1 {
+			builder.WriteString(folderFilter.Where)
+			args = append(args, folderFilter.Args...)
+		} else {
+			// the permissions on a folder apply to its subfolders
+			subtree, subtreeArgs := folderSubtree(f.User, f.folderActions, f.folderDepth()-1)
+			builder.WriteString("(" + folderFilter.Where + " OR dashboard.folder_id IN(" + subtree + "))")
+			args = append(args, folderFilter.Args...)
+			args = append(args, subtreeArgs...)
+		}
 		builder.WriteString(" AND dashboard.is_folder)")
-		args = append(args, folderFilter.Args...)
 	}
 	builder.WriteString(")")
 	return builder.String(), args
 }
+
+func (f AccessControlDashboardPermissionFilter) folderDepth() int {
+	if f.FolderDepth <= 0 || f.FolderDepth > models.MaxNestedFolderDepth {
+		return models.MaxNestedFolderDepth
+	}
+	return f.FolderDepth
+}
+
+// folderSubtree returns a query for the ids of the folders the user has permissions on and of the folders nested in
+// them, up to depth levels of folders. Filters without arguments either match all or none of the folders, they don't
+// need to be repeated for the subfolders.
+func folderSubtree(user *models.SignedInUser, actions []string, depth int) (string, []interface{}) {
+	filter, _ := accesscontrol.Filter(user, "dashboard.uid", dashboards.ScopeFoldersPrefix, actions...)
+	if len(filter.Args) == 0 {
+		return "SELECT id FROM dashboard WHERE " + filter.Where, nil
+	}
+	return searchstore.FolderSubtreeSQL(func(folder string) (string, []interface{}) {
+		return folder + ".id IN (SELECT id FROM dashboard WHERE " + filter.Where + ")", filter.Args
+	}, depth)
+}
@@ -108,7 +108,7 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 			title:            "folder and dashboard actions are defined",
 			dashboardActions: []string{"test"},
 			folderActions:    []string{"test"},
-			expectedResult:   "((( 1 = 0 OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE  1 = 0)) AND NOT dashboard.is_folder) OR ( 1 = 0 AND dashboard.is_folder))",
+			expectedResult:   "((( 1 = 0 OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE  1 = 0) OR dashboard.id IN(SELECT dashboard_id FROM dashboard_tag WHERE  1 = 0)) AND NOT dashboard.is_folder) OR ( 1 = 0 AND dashboard.is_folder))",
 		},
 		{
 			title:            "folder actions are defined but not dashboard actions",
@@ -120,7 +120,7 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 			title:            "dashboard actions are defined but not folder actions",
 			dashboardActions: []string{"test"},
 			folderActions:    nil,
-			expectedResult:   "((( 1 = 0 OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE  1 = 0)) AND NOT dashboard.is_folder))",
+			expectedResult:   "((( 1 = 0 OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE  1 = 0) OR dashboard.id IN(SELECT dashboard_id FROM dashboard_tag WHERE  1 = 0)) AND NOT dashboard.is_folder))",
 		},
 		{
 			title:            "dashboard actions are defined but not folder actions",
@@ -145,3 +145,18 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessControlDashboardPermissionFilter_WhereTagScope(t *testing.T) {
+	filter := AccessControlDashboardPermissionFilter{
+		User: &models.SignedInUser{OrgId: 1, Permissions: map[int64]map[string][]string{1: {
+			dashboards.ActionDashboardsRead: {"dashboards:uid:1", "dashboards:tag:payments", "dashboards:tag:team:billing"},
+		}}},
+		dashboardActions: []string{dashboards.ActionDashboardsRead},
+	}
+
+	query, args := filter.Where()
+
+	assert.Equal(t, "((( dashboard.uid IN (?) OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE  1 = 0) OR dashboard.id IN(SELECT dashboard_id FROM dashboard_tag WHERE  dashboard_tag.term IN (?,?))) AND NOT dashboard.is_folder))", query)
+	assert.Equal(t, "1", args[0])
+	assert.ElementsMatch(t, []interface{}{"payments", "team:billing"}, args[1:])
+}
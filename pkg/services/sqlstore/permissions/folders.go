@@ -0,0 +1,41 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// FolderAncestorsSQL returns the SQL to join the folders containing the dashboard with the alias, as folder1 for its
+// folder up to folderN for the folder in the root, the list of the ids of these folders, and the condition for the
+// default permissions to apply to the dashboard: no permissions set on the folders, or on the dashboard when it's
+// in the root. Up to depth levels of folders are joined, or models.MaxNestedFolderDepth when depth is 0. Folders
+// can't be created or moved deeper than models.MaxNestedFolderDepth levels, so all the folders are joined.
+func FolderAncestorsSQL(dashboard string, dialect migrator.Dialect, depth int) (joins string, ids string, defaultPermissions string) {
+	falseStr := dialect.BooleanStr(false)
+	if depth <= 0 || depth > models.MaxNestedFolderDepth {
+		depth = models.MaxNestedFolderDepth
+	}
+
+	joinsBuilder := strings.Builder{}
+	idList := make([]string, 0, depth)
+	withoutACL := make([]string, 0, depth)
+	parent := dashboard
+	for i := 1; i <= depth; i++ {
+		folder := fmt.Sprintf("folder%d", i)
+		joinsBuilder.WriteString(fmt.Sprintf("\n\t\t\t\t\tLEFT JOIN dashboard AS %s ON %s.id = %s.folder_id", folder, folder, parent))
+		idList = append(idList, folder+".id")
+		if i == 1 {
+			withoutACL = append(withoutACL, fmt.Sprintf("folder1.id IS NOT NULL AND folder1.has_acl = %s", falseStr))
+		} else {
+			withoutACL = append(withoutACL, fmt.Sprintf("(%s.id IS NULL OR %s.has_acl = %s)", folder, folder, falseStr))
+		}
+		parent = folder
+	}
+
+	defaultPermissions = fmt.Sprintf("(%s) OR (folder1.id IS NULL AND %s.has_acl = %s)",
+		strings.Join(withoutACL, " AND "), dashboard, falseStr)
+	return joinsBuilder.String(), strings.Join(idList, ", "), defaultPermissions
+}
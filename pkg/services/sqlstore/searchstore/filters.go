@@ -95,6 +95,42 @@ func (f FolderFilter) Where() (string, []interface{}) {
 	return sqlIDin("dashboard.folder_id", f.IDs)
 }
 
+// FolderSubtreeFilter limits the result to the dashboards and folders nested
+// in the folder with the uid, up to Depth levels of folders.
+type FolderSubtreeFilter struct {
+	OrgId int64
+	UID   string
+	Depth int
+}
+
+func (f FolderSubtreeFilter) Where() (string, []interface{}) {
+	subtree, params := FolderSubtreeSQL(func(folder string) (string, []interface{}) {
+		return folder + ".org_id = ? AND " + folder + ".uid = ?", []interface{}{f.OrgId, f.UID}
+	}, f.Depth)
+	return "dashboard.folder_id IN (" + subtree + ")", params
+}
+
+// FolderSubtreeSQL returns a query for the ids of the folders matching the
+// condition and of the folders nested in them, up to depth levels of folders.
+// The condition is built for each of the joined folders containing a folder,
+// nested folders can't be fetched with a recursive query on all the supported
+// databases.
+func FolderSubtreeSQL(condition func(folder string) (string, []interface{}), depth int) (string, []interface{}) {
+	joins := strings.Builder{}
+	conditions := make([]string, 0, depth)
+	params := make([]interface{}, 0)
+	for i := 1; i <= depth; i++ {
+		folder := fmt.Sprintf("f%d", i)
+		if i > 1 {
+			joins.WriteString(fmt.Sprintf(" LEFT JOIN dashboard AS %s ON %s.id = f%d.folder_id", folder, folder, i-1))
+		}
+		where, args := condition(folder)
+		conditions = append(conditions, "("+where+")")
+		params = append(params, args...)
+	}
+	return "SELECT f1.id FROM dashboard AS f1" + joins.String() + " WHERE " + strings.Join(conditions, " OR "), params
+}
+
 type DashboardIDFilter struct {
 	IDs []int64
 }
@@ -15,6 +15,7 @@ type DBSession struct {
 	*xorm.Session
 	transactionOpen bool
 	events          []interface{}
+	afterCommit     []func()
 }
 
 type DBTransactionFunc func(sess *DBSession) error
@@ -23,6 +24,19 @@ func (sess *DBSession) publishAfterCommit(msg interface{}) {
 	sess.events = append(sess.events, msg)
 }
 
+// PublishAfterCommit queues an event that is published on the bus once the
+// transaction of the session is committed.
+func (sess *DBSession) PublishAfterCommit(msg interface{}) {
+	sess.publishAfterCommit(msg)
+}
+
+// AfterCommit queues a function that is called once the transaction of the
+// session is committed, e.g. to clean up files the committed rows no longer
+// reference. It isn't called when the transaction is rolled back.
+func (sess *DBSession) AfterCommit(fn func()) {
+	sess.afterCommit = append(sess.afterCommit, fn)
+}
+
 // NewSession returns a new DBSession
 func (ss *SQLStore) NewSession(ctx context.Context) *DBSession {
 	sess := &DBSession{Session: ss.engine.NewSession()}
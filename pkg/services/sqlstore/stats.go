@@ -119,6 +119,45 @@ func (ss *SQLStore) GetSystemStats(ctx context.Context, query *models.GetSystemS
 	})
 }
 
+func (ss *SQLStore) SaveStatsHistory(ctx context.Context, cmd *models.SaveStatsHistoryCommand) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		exists, err := dbSession.Where("day = ?", cmd.History.Day).Exist(&models.StatsHistory{})
+		if err != nil || exists {
+			return err
+		}
+		if _, err := dbSession.Insert(cmd.History); err != nil {
+			// another instance saved the snapshot of the day
+			if dialect.IsUniqueConstraintViolation(err) {
+				return nil
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetStatsHistory(ctx context.Context, query *models.GetStatsHistoryQuery) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		sess := dbSession.Asc("day")
+		if query.From != "" {
+			sess = sess.Where("day >= ?", query.From)
+		}
+		if query.To != "" {
+			sess = sess.Where("day <= ?", query.To)
+		}
+		query.Result = make([]*models.StatsHistory, 0)
+		return sess.Find(&query.Result)
+	})
+}
+
+func (ss *SQLStore) DeleteExpiredStatsHistory(ctx context.Context, cmd *models.DeleteExpiredStatsHistoryCommand) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		deleted, err := dbSession.Where("day < ?", cmd.OlderThan).Delete(&models.StatsHistory{})
+		cmd.DeletedRows = deleted
+		return err
+	})
+}
+
 func (ss *SQLStore) roleCounterSQL(ctx context.Context) string {
 	const roleCounterTimeout = 20 * time.Second
 	ctx, cancel := context.WithTimeout(ctx, roleCounterTimeout)
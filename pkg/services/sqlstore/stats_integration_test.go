@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/require"
@@ -18,3 +19,48 @@ func TestIntegration_GetAdminStats(t *testing.T) {
 	err := sqlStore.GetAdminStats(context.Background(), &query)
 	require.NoError(t, err)
 }
+
+func TestIntegration_StatsHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := InitTestDB(t)
+	ctx := context.Background()
+
+	for i, day := range []string{"2022-06-01", "2022-06-02", "2022-06-03"} {
+		err := sqlStore.SaveStatsHistory(ctx, &models.SaveStatsHistoryCommand{History: &models.StatsHistory{
+			Day: day, Users: int64(i + 1), Created: time.Now(),
+		}})
+		require.NoError(t, err)
+	}
+
+	t.Run("a day is saved once", func(t *testing.T) {
+		err := sqlStore.SaveStatsHistory(ctx, &models.SaveStatsHistoryCommand{History: &models.StatsHistory{
+			Day: "2022-06-02", Users: 42, Created: time.Now(),
+		}})
+		require.NoError(t, err)
+
+		query := models.GetStatsHistoryQuery{From: "2022-06-02", To: "2022-06-02"}
+		require.NoError(t, sqlStore.GetStatsHistory(ctx, &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, int64(2), query.Result[0].Users)
+	})
+
+	t.Run("history is sorted by day", func(t *testing.T) {
+		query := models.GetStatsHistoryQuery{From: "2022-06-02"}
+		require.NoError(t, sqlStore.GetStatsHistory(ctx, &query))
+		require.Len(t, query.Result, 2)
+		require.Equal(t, "2022-06-02", query.Result[0].Day)
+		require.Equal(t, "2022-06-03", query.Result[1].Day)
+	})
+
+	t.Run("expired history is deleted", func(t *testing.T) {
+		cmd := models.DeleteExpiredStatsHistoryCommand{OlderThan: "2022-06-03"}
+		require.NoError(t, sqlStore.DeleteExpiredStatsHistory(ctx, &cmd))
+		require.Equal(t, int64(2), cmd.DeletedRows)
+
+		query := models.GetStatsHistoryQuery{}
+		require.NoError(t, sqlStore.GetStatsHistory(ctx, &query))
+		require.Len(t, query.Result, 1)
+	})
+}
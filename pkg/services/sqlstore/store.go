@@ -12,6 +12,9 @@ type Store interface {
 	GetDataSourceStats(ctx context.Context, query *models.GetDataSourceStatsQuery) error
 	GetDataSourceAccessStats(ctx context.Context, query *models.GetDataSourceAccessStatsQuery) error
 	GetSystemStats(ctx context.Context, query *models.GetSystemStatsQuery) error
+	SaveStatsHistory(ctx context.Context, cmd *models.SaveStatsHistoryCommand) error
+	GetStatsHistory(ctx context.Context, query *models.GetStatsHistoryQuery) error
+	DeleteExpiredStatsHistory(ctx context.Context, cmd *models.DeleteExpiredStatsHistoryCommand) error
 	DeleteExpiredSnapshots(ctx context.Context, cmd *models.DeleteExpiredSnapshotsCommand) error
 	CreateDashboardSnapshot(ctx context.Context, cmd *models.CreateDashboardSnapshotCommand) error
 	DeleteDashboardSnapshot(ctx context.Context, cmd *models.DeleteDashboardSnapshotCommand) error
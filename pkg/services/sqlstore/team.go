@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 )
@@ -55,7 +56,8 @@ func getTeamSelectSQLBase(filteredUsers []string) string {
 		team.id as id,
 		team.org_id,
 		team.name as name,
-		team.email as email, ` +
+		team.email as email,
+		team.priority as priority, ` +
 		getTeamMemberCount(filteredUsers) +
 		` FROM team as team `
 }
@@ -66,6 +68,7 @@ func getTeamSelectWithPermissionsSQLBase(filteredUsers []string) string {
 		team.org_id,
 		team.name AS name,
 		team.email AS email,
+		team.priority AS priority,
 		team_member.permission, ` +
 		getTeamMemberCount(filteredUsers) +
 		` FROM team AS team
@@ -108,6 +111,10 @@ func (ss *SQLStore) UpdateTeam(ctx context.Context, cmd *models.UpdateTeamComman
 		}
 
 		sess.MustCols("email")
+		if cmd.Priority != nil {
+			team.Priority = *cmd.Priority
+			sess.MustCols("priority")
+		}
 
 		affectedRows, err := sess.ID(cmd.Id).Update(&team)
 
@@ -135,6 +142,8 @@ func (ss *SQLStore) DeleteTeam(ctx context.Context, cmd *models.DeleteTeamComman
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
 			"DELETE FROM team_role WHERE org_id=? and team_id = ?",
+			"DELETE FROM query_history_team_star WHERE org_id=? and team_id = ?",
+			"DELETE FROM preferences WHERE org_id=? and team_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -144,9 +153,17 @@ func (ss *SQLStore) DeleteTeam(ctx context.Context, cmd *models.DeleteTeamComman
 			}
 		}
 
-		_, err := sess.Exec("DELETE FROM permission WHERE scope=?", ac.Scope("teams", "id", fmt.Sprint(cmd.Id)))
+		if _, err := sess.Exec("DELETE FROM permission WHERE scope=?", ac.Scope("teams", "id", fmt.Sprint(cmd.Id))); err != nil {
+			return err
+		}
+
+		sess.publishAfterCommit(&events.TeamDeleted{
+			Timestamp: time.Now(),
+			Id:        cmd.Id,
+			OrgId:     cmd.OrgId,
+		})
 
-		return err
+		return nil
 	})
 }
 
@@ -84,5 +84,9 @@ func inTransactionWithRetryCtx(ctx context.Context, engine *xorm.Engine, bus bus
 		}
 	}
 
+	for _, fn := range sess.afterCommit {
+		fn()
+	}
+
 	return nil
 }
@@ -87,6 +87,7 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args models
 			return user, err
 		}
 		user.Password = encodedPassword
+		user.PasswordChanged = user.Created
 	}
 
 	sess.UseBool("is_admin")
@@ -247,9 +248,11 @@ func (ss *SQLStore) UpdateUser(ctx context.Context, cmd *models.UpdateUserComman
 
 func (ss *SQLStore) ChangeUserPassword(ctx context.Context, cmd *models.ChangeUserPasswordCommand) error {
 	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		now := time.Now()
 		user := models.User{
-			Password: cmd.NewPassword,
-			Updated:  time.Now(),
+			Password:        cmd.NewPassword,
+			Updated:         now,
+			PasswordChanged: now,
 		}
 
 		_, err := sess.ID(cmd.UserId).Where(notServiceAccountFilter(ss)).Update(&user)
@@ -375,6 +378,23 @@ func (ss *SQLStore) GetUserOrgList(ctx context.Context, query *models.GetUserOrg
 	})
 }
 
+// applyAccessGrants gives the user the highest of their role in the organization
+// and of the basic roles granted to them that haven't expired yet.
+func applyAccessGrants(sess *DBSession, user *models.SignedInUser) error {
+	var roles []string
+	if err := sess.Table("access_grant").Cols("role").
+		Where("org_id=? AND user_id=? AND role<>'' AND expires>?", user.OrgId, user.UserId, time.Now()).
+		Find(&roles); err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if granted := models.RoleType(role); granted.IsValid() && granted.Includes(user.OrgRole) {
+			user.OrgRole = granted
+		}
+	}
+	return nil
+}
+
 func newSignedInUserCacheKey(orgID, userID int64) string {
 	return fmt.Sprintf("signed-in-user-%d-%d", userID, orgID)
 }
@@ -446,6 +466,8 @@ func (ss *SQLStore) GetSignedInUser(ctx context.Context, query *models.GetSigned
 		if user.OrgRole == "" {
 			user.OrgId = -1
 			user.OrgName = "Org missing"
+		} else if err := applyAccessGrants(dbSess, &user); err != nil {
+			return err
 		}
 
 		if user.ExternalAuthModule != "oauth_grafana_com" {
@@ -652,6 +674,10 @@ func deleteUserInTransaction(ss *SQLStore, sess *DBSession, cmd *models.DeleteUs
 	if !has {
 		return models.ErrUserNotFound
 	}
+	orgIDs := make([]int64, 0)
+	if err := sess.SQL("SELECT org_id FROM org_user WHERE user_id = ?", cmd.UserId).Find(&orgIDs); err != nil {
+		return err
+	}
 	for _, sql := range UserDeletions() {
 		_, err := sess.Exec(sql, cmd.UserId)
 		if err != nil {
@@ -659,7 +685,16 @@ func deleteUserInTransaction(ss *SQLStore, sess *DBSession, cmd *models.DeleteUs
 		}
 	}
 
-	return deleteUserAccessControl(sess, cmd.UserId)
+	if err := deleteUserAccessControl(sess, cmd.UserId); err != nil {
+		return err
+	}
+
+	sess.publishAfterCommit(&events.UserDeleted{
+		Timestamp: time.Now(),
+		Id:        cmd.UserId,
+		OrgIds:    orgIDs,
+	})
+	return nil
 }
 
 func deleteUserAccessControl(sess *DBSession, userID int64) error {
@@ -705,6 +740,8 @@ func deleteUserAccessControl(sess *DBSession, userID int64) error {
 func UserDeletions() []string {
 	deletes := []string{
 		"DELETE FROM star WHERE user_id = ?",
+		"DELETE FROM star_item WHERE user_id = ?",
+		"DELETE FROM inbox_notification WHERE user_id = ?",
 		"DELETE FROM " + dialect.Quote("user") + " WHERE id = ?",
 		"DELETE FROM org_user WHERE user_id = ?",
 		"DELETE FROM dashboard_acl WHERE user_id = ?",
@@ -1,8 +1,33 @@
 package star
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
-var ErrCommandValidationFailed = errors.New("command missing required fields")
+var (
+	ErrCommandValidationFailed = errors.New("command missing required fields")
+	ErrUnknownKind             = errors.New("unknown star kind")
+)
+
+// Kind is the type of resource a star points to. Dashboards are stored in the
+// star table by id, all other kinds in the star_item table by uid.
+type Kind string
+
+const (
+	KindDashboard  Kind = "dashboard"
+	KindFolder     Kind = "folder"
+	KindDataSource Kind = "datasource"
+	KindQuery      Kind = "query"
+)
+
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindDashboard, KindFolder, KindDataSource, KindQuery:
+		return true
+	}
+	return false
+}
 
 type Star struct {
 	ID          int64 `xorm:"pk autoincr 'id'"`
@@ -10,6 +35,19 @@ type Star struct {
 	DashboardID int64 `xorm:"dashboard_id"`
 }
 
+type Item struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	UserID  int64     `xorm:"user_id"`
+	OrgID   int64     `xorm:"org_id"`
+	Kind    Kind      `xorm:"kind"`
+	UID     string    `xorm:"uid"`
+	Created time.Time `xorm:"created"`
+}
+
+func (i Item) TableName() string {
+	return "star_item"
+}
+
 // ----------------------
 // COMMANDS
 
@@ -37,6 +75,40 @@ func (cmd *UnstarDashboardCommand) Validate() error {
 	return nil
 }
 
+type StarItemCommand struct {
+	UserID int64
+	OrgID  int64
+	Kind   Kind
+	UID    string
+}
+
+func (cmd *StarItemCommand) Validate() error {
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.UID == "" {
+		return ErrCommandValidationFailed
+	}
+	if cmd.Kind != KindFolder && cmd.Kind != KindDataSource {
+		return ErrUnknownKind
+	}
+	return nil
+}
+
+type UnstarItemCommand struct {
+	UserID int64
+	OrgID  int64
+	Kind   Kind
+	UID    string
+}
+
+func (cmd *UnstarItemCommand) Validate() error {
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.UID == "" {
+		return ErrCommandValidationFailed
+	}
+	if cmd.Kind != KindFolder && cmd.Kind != KindDataSource {
+		return ErrUnknownKind
+	}
+	return nil
+}
+
 // ---------------------
 // QUERIES
 
@@ -52,3 +124,25 @@ type IsStarredByUserQuery struct {
 type GetUserStarsResult struct {
 	UserStars map[int64]bool
 }
+
+type GetUserStarredItemsQuery struct {
+	UserID int64
+	OrgID  int64
+	// Kind optionally limits the result to a single kind
+	Kind Kind
+}
+
+type GetUserStarredItemsResult struct {
+	Items []Item
+}
+
+// UIDs returns the uids of the items of the given kind, in the order they were starred.
+func (r *GetUserStarredItemsResult) UIDs(kind Kind) []string {
+	uids := []string{}
+	for _, item := range r.Items {
+		if item.Kind == kind {
+			uids = append(uids, item.UID)
+		}
+	}
+	return uids
+}
@@ -9,4 +9,8 @@ type Service interface {
 	Delete(ctx context.Context, cmd *UnstarDashboardCommand) error
 	IsStarredByUser(ctx context.Context, query *IsStarredByUserQuery) (bool, error)
 	GetByUser(ctx context.Context, cmd *GetUserStarsQuery) (*GetUserStarsResult, error)
+	// AddItem stars a folder or a data source.
+	AddItem(ctx context.Context, cmd *StarItemCommand) error
+	DeleteItem(ctx context.Context, cmd *UnstarItemCommand) error
+	GetItemsByUser(ctx context.Context, query *GetUserStarredItemsQuery) (*GetUserStarredItemsResult, error)
 }
@@ -40,3 +40,21 @@ func (s *Service) IsStarredByUser(ctx context.Context, query *star.IsStarredByUs
 func (s *Service) GetByUser(ctx context.Context, cmd *star.GetUserStarsQuery) (*star.GetUserStarsResult, error) {
 	return s.store.List(ctx, cmd)
 }
+
+func (s *Service) AddItem(ctx context.Context, cmd *star.StarItemCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.InsertItem(ctx, cmd)
+}
+
+func (s *Service) DeleteItem(ctx context.Context, cmd *star.UnstarItemCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.DeleteItem(ctx, cmd)
+}
+
+func (s *Service) GetItemsByUser(ctx context.Context, query *star.GetUserStarredItemsQuery) (*star.GetUserStarredItemsResult, error) {
+	return s.store.ListItems(ctx, query)
+}
@@ -2,6 +2,7 @@ package starimpl
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
@@ -13,6 +14,9 @@ type store interface {
 	Insert(ctx context.Context, cmd *star.StarDashboardCommand) error
 	Delete(ctx context.Context, cmd *star.UnstarDashboardCommand) error
 	List(ctx context.Context, query *star.GetUserStarsQuery) (*star.GetUserStarsResult, error)
+	InsertItem(ctx context.Context, cmd *star.StarItemCommand) error
+	DeleteItem(ctx context.Context, cmd *star.UnstarItemCommand) error
+	ListItems(ctx context.Context, query *star.GetUserStarredItemsQuery) (*star.GetUserStarredItemsResult, error)
 }
 
 type sqlStore struct {
@@ -67,3 +71,43 @@ func (s *sqlStore) List(ctx context.Context, query *star.GetUserStarsQuery) (*st
 	})
 	return &star.GetUserStarsResult{UserStars: userStars}, err
 }
+
+func (s *sqlStore) InsertItem(ctx context.Context, cmd *star.StarItemCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// Starring an item twice is not an error
+		exists, err := sess.Where("user_id=? AND org_id=? AND kind=? AND uid=?", cmd.UserID, cmd.OrgID, cmd.Kind, cmd.UID).Exist(&star.Item{})
+		if err != nil || exists {
+			return err
+		}
+
+		entity := star.Item{
+			UserID:  cmd.UserID,
+			OrgID:   cmd.OrgID,
+			Kind:    cmd.Kind,
+			UID:     cmd.UID,
+			Created: time.Now(),
+		}
+		_, err = sess.Insert(&entity)
+		return err
+	})
+}
+
+func (s *sqlStore) DeleteItem(ctx context.Context, cmd *star.UnstarItemCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var rawSQL = "DELETE FROM star_item WHERE user_id=? AND org_id=? AND kind=? AND uid=?"
+		_, err := sess.Exec(rawSQL, cmd.UserID, cmd.OrgID, cmd.Kind, cmd.UID)
+		return err
+	})
+}
+
+func (s *sqlStore) ListItems(ctx context.Context, query *star.GetUserStarredItemsQuery) (*star.GetUserStarredItemsResult, error) {
+	items := make([]star.Item, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("user_id=? AND org_id=?", query.UserID, query.OrgID)
+		if query.Kind != "" {
+			q = q.And("kind=?", query.Kind)
+		}
+		return q.Asc("id").Find(&items)
+	})
+	return &star.GetUserStarredItemsResult{Items: items}, err
+}
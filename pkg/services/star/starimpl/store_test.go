@@ -58,3 +58,57 @@ func TestIntegrationUserStarsDataAccess(t *testing.T) {
 		})
 	})
 }
+
+func TestIntegrationUserStarredItemsDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	starStore := sqlStore{db: ss}
+	ctx := context.Background()
+
+	require.NoError(t, starStore.InsertItem(ctx, &star.StarItemCommand{UserID: 12, OrgID: 1, Kind: star.KindFolder, UID: "folder-1"}))
+	require.NoError(t, starStore.InsertItem(ctx, &star.StarItemCommand{UserID: 12, OrgID: 1, Kind: star.KindDataSource, UID: "ds-1"}))
+	require.NoError(t, starStore.InsertItem(ctx, &star.StarItemCommand{UserID: 12, OrgID: 2, Kind: star.KindFolder, UID: "folder-2"}))
+	require.NoError(t, starStore.InsertItem(ctx, &star.StarItemCommand{UserID: 13, OrgID: 1, Kind: star.KindFolder, UID: "folder-1"}))
+
+	t.Run("starring an item twice should not fail", func(t *testing.T) {
+		err := starStore.InsertItem(ctx, &star.StarItemCommand{UserID: 12, OrgID: 1, Kind: star.KindFolder, UID: "folder-1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("ListItems should return the items of the user in the org", func(t *testing.T) {
+		result, err := starStore.ListItems(ctx, &star.GetUserStarredItemsQuery{UserID: 12, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 2)
+		require.Equal(t, []string{"folder-1"}, result.UIDs(star.KindFolder))
+		require.Equal(t, []string{"ds-1"}, result.UIDs(star.KindDataSource))
+	})
+
+	t.Run("ListItems should filter by kind", func(t *testing.T) {
+		result, err := starStore.ListItems(ctx, &star.GetUserStarredItemsQuery{UserID: 12, OrgID: 1, Kind: star.KindDataSource})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		require.Equal(t, "ds-1", result.Items[0].UID)
+	})
+
+	t.Run("DeleteItem should only remove the star of the user", func(t *testing.T) {
+		err := starStore.DeleteItem(ctx, &star.UnstarItemCommand{UserID: 12, OrgID: 1, Kind: star.KindFolder, UID: "folder-1"})
+		require.NoError(t, err)
+
+		result, err := starStore.ListItems(ctx, &star.GetUserStarredItemsQuery{UserID: 12, OrgID: 1})
+		require.NoError(t, err)
+		require.Empty(t, result.UIDs(star.KindFolder))
+
+		result, err = starStore.ListItems(ctx, &star.GetUserStarredItemsQuery{UserID: 13, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"folder-1"}, result.UIDs(star.KindFolder))
+	})
+}
+
+func TestStarItemCommandValidate(t *testing.T) {
+	require.NoError(t, (&star.StarItemCommand{UserID: 1, OrgID: 1, Kind: star.KindFolder, UID: "a"}).Validate())
+	require.ErrorIs(t, (&star.StarItemCommand{UserID: 1, OrgID: 1, Kind: star.KindFolder}).Validate(), star.ErrCommandValidationFailed)
+	require.ErrorIs(t, (&star.StarItemCommand{UserID: 1, OrgID: 1, Kind: star.KindDashboard, UID: "a"}).Validate(), star.ErrUnknownKind)
+	require.ErrorIs(t, (&star.StarItemCommand{UserID: 1, OrgID: 1, Kind: "panel", UID: "a"}).Validate(), star.ErrUnknownKind)
+}
@@ -10,6 +10,7 @@ type FakeStarService struct {
 	ExpectedStars     *star.Star
 	ExpectedError     error
 	ExpectedUserStars *star.GetUserStarsResult
+	ExpectedItems     *star.GetUserStarredItemsResult
 }
 
 func NewStarServiceFake() *FakeStarService {
@@ -31,3 +32,18 @@ func (f *FakeStarService) Delete(ctx context.Context, cmd *star.UnstarDashboardC
 func (f *FakeStarService) GetByUser(ctx context.Context, query *star.GetUserStarsQuery) (*star.GetUserStarsResult, error) {
 	return f.ExpectedUserStars, f.ExpectedError
 }
+
+func (f *FakeStarService) AddItem(ctx context.Context, cmd *star.StarItemCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeStarService) DeleteItem(ctx context.Context, cmd *star.UnstarItemCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeStarService) GetItemsByUser(ctx context.Context, query *star.GetUserStarredItemsQuery) (*star.GetUserStarredItemsResult, error) {
+	if f.ExpectedItems == nil {
+		return &star.GetUserStarredItemsResult{}, f.ExpectedError
+	}
+	return f.ExpectedItems, f.ExpectedError
+}
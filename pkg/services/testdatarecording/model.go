@@ -0,0 +1,52 @@
+package testdatarecording
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrInvalidRecordingName = errors.New("recording name can only contain letters, digits, dashes and underscores")
+	ErrTestDataNotFound     = errors.New("no TestData data source in the organization to replay the recording with")
+	ErrNothingRecorded      = errors.New("no panel of the dashboard could be recorded")
+)
+
+// RecordCommand is the command for recording the data of the panels of a dashboard
+// swagger:model
+type RecordCommand struct {
+	// Name of the recording, letters, digits, dashes and underscores. A recording with the same name is replaced.
+	// required: true
+	// example: servers-demo
+	Name string `json:"name"`
+	// From Start of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// Defaults to the time range of the dashboard.
+	// example: now-6h
+	From string `json:"from"`
+	// To End of the time range in epoch timestamps in milliseconds or relative using Grafana time units.
+	// Defaults to the time range of the dashboard.
+	// example: now
+	To string `json:"to"`
+	// Values selects the values of template variables by name, instead of the values saved with the dashboard.
+	Values map[string][]string `json:"values"`
+	// MaxDataPoints is the number of points the queries are asked for, when the panel doesn't set it.
+	// default: 1000
+	MaxDataPoints int64 `json:"maxDataPoints"`
+
+	SkipCache bool `json:"-"`
+}
+
+// RecordResult is the outcome of the recording of a dashboard.
+type RecordResult struct {
+	Name string `json:"name"`
+	// From and To are the time range the queries ran with, in epoch milliseconds.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Panels are the ids of the recorded panels.
+	Panels []int64 `json:"panels"`
+	// Failed are the errors of the panels that couldn't be recorded by panel id.
+	Failed map[int64]string `json:"failed"`
+	// Dashboard is a copy of the dashboard with the recorded panels querying the
+	// recording, to be imported.
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
@@ -0,0 +1,16 @@
+package testdatarecording
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Service records the results of the queries of the panels of a dashboard, to
+// replay them with the recorded data scenario of the TestData data source, e.g.
+// to demo or e2e test the dashboard without its data sources.
+type Service interface {
+	// Record runs the queries of the panels of the dashboard, stores their
+	// results as a recording and returns a copy of the dashboard replaying them.
+	Record(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *RecordCommand) (*RecordResult, error)
+}
@@ -0,0 +1,209 @@
+package testdatarecordingimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
+)
+
+// testDataType is the plugin id of the TestData data source.
+const testDataType = "testdata"
+
+type Service struct {
+	cfg           *setting.Cfg
+	panelSnapshot panelsnapshot.Service
+	dataSources   datasources.DataSourceService
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, panelSnapshot panelsnapshot.Service, dataSources datasources.DataSourceService) testdatarecording.Service {
+	return &Service{
+		cfg:           cfg,
+		panelSnapshot: panelSnapshot,
+		dataSources:   dataSources,
+		now:           time.Now,
+	}
+}
+
+func (s *Service) Record(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *testdatarecording.RecordCommand) (*testdatarecording.RecordResult, error) {
+	if !testdatasource.ValidRecordingName(cmd.Name) {
+		return nil, testdatarecording.ErrInvalidRecordingName
+	}
+	testData, err := s.testDataSource(ctx, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.From == "" {
+		cmd.From = dashboard.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if cmd.To == "" {
+		cmd.To = dashboard.Data.Get("time").Get("to").MustString("now")
+	}
+	// the relative time range is resolved once, so that all the panels are
+	// recorded over the same time range
+	timeRange := legacydata.NewDataTimeRange(cmd.From, cmd.To)
+	from, to := timeRange.GetFromAsMsEpoch(), timeRange.GetToAsMsEpoch()
+
+	recording := &testdatasource.Recording{
+		Name:         cmd.Name,
+		DashboardUID: dashboard.Uid,
+		From:         from,
+		To:           to,
+		Timestamp:    s.now(),
+		Panels:       map[int64]json.RawMessage{},
+	}
+	result := &testdatarecording.RecordResult{
+		Name:   cmd.Name,
+		From:   from,
+		To:     to,
+		Panels: []int64{},
+		Failed: map[int64]string{},
+	}
+
+	for _, panel := range queryPanels(dashboard.Data) {
+		id := panel.Get("id").MustInt64()
+		snapshot, err := s.panelSnapshot.Create(ctx, user, dashboard, &panelsnapshot.CreatePanelSnapshotCommand{
+			From:          strconv.FormatInt(from, 10),
+			To:            strconv.FormatInt(to, 10),
+			Values:        cmd.Values,
+			MaxDataPoints: cmd.MaxDataPoints,
+			PanelID:       id,
+			SkipCache:     cmd.SkipCache,
+		})
+		if err != nil {
+			if !errors.Is(err, panelsnapshot.ErrPanelWithoutQueries) {
+				result.Failed[id] = err.Error()
+			}
+			continue
+		}
+		recording.Panels[id] = snapshot.Data
+		result.Panels = append(result.Panels, id)
+	}
+	if len(recording.Panels) == 0 {
+		return nil, testdatarecording.ErrNothingRecorded
+	}
+
+	if err := testdatasource.SaveRecording(testdatasource.RecordingsPath(s.cfg), user.OrgId, recording); err != nil {
+		return nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	result.Dashboard, err = replayDashboard(dashboard, recording, testData)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// testDataSource returns the reference of a TestData data source of the organization.
+func (s *Service) testDataSource(ctx context.Context, orgID int64) (map[string]interface{}, error) {
+	query := &models.GetDataSourcesByTypeQuery{Type: testDataType}
+	if err := s.dataSources.GetDataSourcesByType(ctx, query); err != nil {
+		return nil, err
+	}
+	for _, ds := range query.Result {
+		if ds.OrgId == orgID {
+			return map[string]interface{}{"type": testDataType, "uid": ds.Uid}, nil
+		}
+	}
+	return nil, testdatarecording.ErrTestDataNotFound
+}
+
+// queryPanels returns the panels with queries, looking into collapsed rows too.
+func queryPanels(dashboard *simplejson.Json) []*simplejson.Json {
+	panels := make([]*simplejson.Json, 0)
+	for _, obj := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(obj)
+		if len(panel.Get("targets").MustArray()) > 0 {
+			panels = append(panels, panel)
+		}
+		for _, nested := range panel.Get("panels").MustArray() {
+			nestedPanel := simplejson.NewFromAny(nested)
+			if len(nestedPanel.Get("targets").MustArray()) > 0 {
+				panels = append(panels, nestedPanel)
+			}
+		}
+	}
+	return panels
+}
+
+// replayDashboard returns a copy of the dashboard with the queries of the
+// recorded panels replaced by TestData queries replaying the recording, and
+// without the queries of variables and annotations, so that it doesn't need
+// the data sources of the dashboard.
+func replayDashboard(dashboard *models.Dashboard, recording *testdatasource.Recording, testData map[string]interface{}) (*simplejson.Json, error) {
+	encoded, err := dashboard.Data.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	replay, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+	replay.Del("id")
+	replay.Set("uid", "")
+	replay.Set("version", 0)
+	replay.Set("title", fmt.Sprintf("%s (recorded)", dashboard.Title))
+
+	for _, panel := range queryPanels(replay) {
+		id := panel.Get("id").MustInt64()
+		if _, ok := recording.Panels[id]; !ok {
+			continue
+		}
+		targets := make([]interface{}, 0)
+		for _, obj := range panel.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(obj)
+			targets = append(targets, map[string]interface{}{
+				"refId":            target.Get("refId").MustString(),
+				"hide":             target.Get("hide").MustBool(),
+				"datasource":       testData,
+				"scenarioId":       "recorded_data",
+				"recording":        recording.Name,
+				"recordingPanelId": id,
+			})
+		}
+		panel.Set("datasource", testData)
+		panel.Set("targets", targets)
+	}
+
+	for _, obj := range replay.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(obj)
+		if variable.Get("type").MustString() != "query" {
+			continue
+		}
+		// the current values are kept as the only options of the variable
+		values := variable.GetPath("current", "value").MustStringArray()
+		if value, err := variable.GetPath("current", "value").String(); err == nil {
+			values = []string{value}
+		}
+		variable.Set("type", "custom")
+		variable.Set("query", strings.Join(values, ","))
+		variable.Set("options", []interface{}{})
+		variable.Del("datasource")
+		variable.Del("definition")
+		variable.Set("refresh", 0)
+	}
+
+	annotations := make([]interface{}, 0)
+	for _, obj := range replay.GetPath("annotations", "list").MustArray() {
+		if simplejson.NewFromAny(obj).Get("builtIn").MustInt() == 1 {
+			annotations = append(annotations, obj)
+		}
+	}
+	replay.SetPath([]string{"annotations", "list"}, annotations)
+
+	return replay, nil
+}
@@ -0,0 +1,176 @@
+package testdatarecordingimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	datasourcesfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/panelsnapshot"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
+)
+
+type fakePanelSnapshot struct {
+	commands []*panelsnapshot.CreatePanelSnapshotCommand
+	errors   map[int64]error
+}
+
+func (f *fakePanelSnapshot) Create(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *panelsnapshot.CreatePanelSnapshotCommand) (*panelsnapshot.PanelSnapshot, error) {
+	f.commands = append(f.commands, cmd)
+	if err := f.errors[cmd.PanelID]; err != nil {
+		return nil, err
+	}
+	return &panelsnapshot.PanelSnapshot{PanelID: cmd.PanelID, Data: json.RawMessage(`{"results":{}}`)}, nil
+}
+
+func TestTestDataRecording(t *testing.T) {
+	dashboard := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"id":    4,
+		"uid":   "dash",
+		"title": "Servers",
+		"time":  map[string]interface{}{"from": "now-1h", "to": "now"},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         1,
+				"title":      "CPU",
+				"datasource": map[string]interface{}{"uid": "prom"},
+				"targets": []interface{}{
+					map[string]interface{}{"refId": "A", "expr": "cpu"},
+					map[string]interface{}{"refId": "B", "expr": "load", "hide": true},
+				},
+			},
+			map[string]interface{}{"id": 2, "title": "Text", "type": "text"},
+			map[string]interface{}{
+				"id":        3,
+				"type":      "row",
+				"collapsed": true,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":         4,
+						"datasource": map[string]interface{}{"uid": "loki"},
+						"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "{job=\"api\"}"}},
+					},
+				},
+			},
+		},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":       "host",
+					"type":       "query",
+					"datasource": map[string]interface{}{"uid": "prom"},
+					"query":      "label_values(host)",
+					"current":    map[string]interface{}{"text": "a + b", "value": []interface{}{"a", "b"}},
+				},
+				map[string]interface{}{"name": "env", "type": "custom", "query": "dev,prod"},
+			},
+		},
+		"annotations": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"name": "Annotations & Alerts", "builtIn": 1},
+				map[string]interface{}{"name": "Deploys", "datasource": map[string]interface{}{"uid": "loki"}},
+			},
+		},
+	}))
+	user := &models.SignedInUser{OrgId: 1}
+
+	setup := func(t *testing.T) (*Service, *fakePanelSnapshot) {
+		cfg := setting.NewCfg()
+		cfg.DataPath = t.TempDir()
+		snapshots := &fakePanelSnapshot{errors: map[int64]error{}}
+		dataSources := &datasourcesfakes.FakeDataSourceService{DataSources: []*models.DataSource{
+			{OrgId: 2, Type: "testdata", Uid: "other-org"},
+			{OrgId: 1, Type: "testdata", Uid: "testdata"},
+		}}
+		s := ProvideService(cfg, snapshots, dataSources).(*Service)
+		s.now = func() time.Time { return time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC) }
+		return s, snapshots
+	}
+
+	t.Run("Records the panels with queries", func(t *testing.T) {
+		s, snapshots := setup(t)
+		result, err := s.Record(context.Background(), user, dashboard, &testdatarecording.RecordCommand{Name: "servers", From: "1000", To: "2000"})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 4}, result.Panels)
+		assert.Empty(t, result.Failed)
+		require.Len(t, snapshots.commands, 2)
+		for _, cmd := range snapshots.commands {
+			assert.Equal(t, "1000", cmd.From)
+			assert.Equal(t, "2000", cmd.To)
+		}
+
+		recording, err := testdatasource.LoadRecording(testdatasource.RecordingsPath(s.cfg), 1, "servers")
+		require.NoError(t, err)
+		assert.Equal(t, "dash", recording.DashboardUID)
+		assert.Equal(t, int64(1000), recording.From)
+		assert.Equal(t, int64(2000), recording.To)
+		assert.Len(t, recording.Panels, 2)
+	})
+
+	t.Run("Returns a dashboard replaying the recording", func(t *testing.T) {
+		s, _ := setup(t)
+		result, err := s.Record(context.Background(), user, dashboard, &testdatarecording.RecordCommand{Name: "servers"})
+		require.NoError(t, err)
+
+		replay := result.Dashboard
+		_, hasID := replay.CheckGet("id")
+		assert.False(t, hasID)
+		assert.Equal(t, "", replay.Get("uid").MustString())
+		assert.Equal(t, "Servers (recorded)", replay.Get("title").MustString())
+
+		panel := replay.Get("panels").GetIndex(0)
+		assert.Equal(t, "testdata", panel.GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "recorded_data", panel.Get("targets").GetIndex(0).Get("scenarioId").MustString())
+		assert.Equal(t, "servers", panel.Get("targets").GetIndex(0).Get("recording").MustString())
+		assert.Equal(t, int64(1), panel.Get("targets").GetIndex(0).Get("recordingPanelId").MustInt64())
+		assert.True(t, panel.Get("targets").GetIndex(1).Get("hide").MustBool())
+		nested := replay.Get("panels").GetIndex(2).Get("panels").GetIndex(0)
+		assert.Equal(t, int64(4), nested.Get("targets").GetIndex(0).Get("recordingPanelId").MustInt64())
+
+		host := replay.GetPath("templating", "list").GetIndex(0)
+		assert.Equal(t, "custom", host.Get("type").MustString())
+		assert.Equal(t, "a,b", host.Get("query").MustString())
+		assert.Len(t, replay.GetPath("annotations", "list").MustArray(), 1)
+
+		// the recorded dashboard isn't changed
+		assert.Equal(t, "prom", dashboard.Data.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "query", dashboard.Data.GetPath("templating", "list").GetIndex(0).Get("type").MustString())
+	})
+
+	t.Run("Reports the panels that couldn't be recorded", func(t *testing.T) {
+		s, snapshots := setup(t)
+		snapshots.errors[4] = errors.New("loki is down")
+		result, err := s.Record(context.Background(), user, dashboard, &testdatarecording.RecordCommand{Name: "servers"})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, result.Panels)
+		assert.Equal(t, map[int64]string{4: "loki is down"}, result.Failed)
+		// the panel that couldn't be recorded keeps querying its data source
+		nested := result.Dashboard.Get("panels").GetIndex(2).Get("panels").GetIndex(0)
+		assert.Equal(t, "loki", nested.GetPath("datasource", "uid").MustString())
+
+		snapshots.errors[1] = panelsnapshot.ErrPanelWithoutQueries
+		_, err = s.Record(context.Background(), user, dashboard, &testdatarecording.RecordCommand{Name: "servers"})
+		require.ErrorIs(t, err, testdatarecording.ErrNothingRecorded)
+	})
+
+	t.Run("Rejects invalid recording names", func(t *testing.T) {
+		s, _ := setup(t)
+		_, err := s.Record(context.Background(), user, dashboard, &testdatarecording.RecordCommand{Name: "../servers"})
+		require.ErrorIs(t, err, testdatarecording.ErrInvalidRecordingName)
+	})
+
+	t.Run("Requires a TestData data source in the organization", func(t *testing.T) {
+		s, _ := setup(t)
+		_, err := s.Record(context.Background(), &models.SignedInUser{OrgId: 3}, dashboard, &testdatarecording.RecordCommand{Name: "servers"})
+		require.ErrorIs(t, err, testdatarecording.ErrTestDataNotFound)
+	})
+}
@@ -0,0 +1,21 @@
+package testdatarecordingtest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/testdatarecording"
+)
+
+type FakeTestDataRecordingService struct {
+	ExpectedResult *testdatarecording.RecordResult
+	ExpectedError  error
+}
+
+func NewTestDataRecordingServiceFake() *FakeTestDataRecordingService {
+	return &FakeTestDataRecordingService{}
+}
+
+func (f *FakeTestDataRecordingService) Record(ctx context.Context, user *models.SignedInUser, dashboard *models.Dashboard, cmd *testdatarecording.RecordCommand) (*testdatarecording.RecordResult, error) {
+	return f.ExpectedResult, f.ExpectedError
+}
@@ -0,0 +1,176 @@
+package timeregions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// interval is a half open period [from, to).
+type interval struct {
+	name     string
+	from, to time.Time
+}
+
+// ActiveAt returns true if t is within one of the regions of the calendar.
+func (c *Calendar) ActiveAt(t time.Time) (bool, error) {
+	windows, err := c.intervals(t, t.Add(time.Nanosecond))
+	if err != nil {
+		return false, err
+	}
+	return len(windows) > 0, nil
+}
+
+// Windows returns the periods between from and to where the calendar is active,
+// ordered by start.
+func (c *Calendar) Windows(from, to time.Time) ([]Window, error) {
+	if to.Sub(from) > MaxWindowsRange {
+		return nil, ErrCalendarRangeTooLarge
+	}
+	intervals, err := c.intervals(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]Window, 0, len(intervals))
+	for _, i := range intervals {
+		windows = append(windows, Window{
+			Name: i.name,
+			From: i.from.UnixNano() / int64(time.Millisecond),
+			To:   i.to.UnixNano() / int64(time.Millisecond),
+		})
+	}
+	return windows, nil
+}
+
+func (c *Calendar) intervals(from, to time.Time) ([]interval, error) {
+	loc, err := c.location()
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := c.holidayIntervals(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start a day early to include regions spanning midnight
+	first := from.In(loc).AddDate(0, 0, -1)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+
+	var result []interval
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, r := range c.Regions {
+			if !r.startsOn(day.Weekday()) {
+				continue
+			}
+			i, err := r.intervalOn(day, loc)
+			if err != nil {
+				return nil, err
+			}
+			if i.from.Before(from) {
+				i.from = from
+			}
+			if i.to.After(to) {
+				i.to = to
+			}
+			if !i.from.Before(i.to) {
+				continue
+			}
+			result = append(result, subtract(i, holidays)...)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].from.Before(result[j].from) })
+	return result, nil
+}
+
+func (c *Calendar) location() (*time.Location, error) {
+	if c.Timezone == "" || strings.EqualFold(c.Timezone, "browser") {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+func (c *Calendar) holidayIntervals(loc *time.Location) ([]interval, error) {
+	holidays := make([]interval, 0, len(c.Holidays))
+	for _, h := range c.Holidays {
+		date, err := time.ParseInLocation(dateLayout, h.Date, loc)
+		if err != nil {
+			return nil, fmt.Errorf("holiday %q: %w", h.Name, err)
+		}
+		holidays = append(holidays, interval{name: h.Name, from: date, to: date.AddDate(0, 0, 1)})
+	}
+	return holidays, nil
+}
+
+func (r Region) startsOn(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Region) intervalOn(day time.Time, loc *time.Location) (interval, error) {
+	from, err := parseClock(r.From)
+	if err != nil {
+		return interval{}, err
+	}
+	to, err := parseClock(r.To)
+	if err != nil {
+		return interval{}, err
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), from.Hour(), from.Minute(), 0, 0, loc)
+	end := time.Date(day.Year(), day.Month(), day.Day(), to.Hour(), to.Minute(), 0, 0, loc)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return interval{name: r.Name, from: start, to: end}, nil
+}
+
+func parseClock(s string) (time.Time, error) {
+	return time.Parse(clockLayout, s)
+}
+
+// subtract removes the holidays from the interval, which can split it in several parts.
+func subtract(i interval, holidays []interval) []interval {
+	parts := []interval{i}
+	for _, h := range holidays {
+		var next []interval
+		for _, p := range parts {
+			if !h.from.Before(p.to) || !h.to.After(p.from) {
+				next = append(next, p)
+				continue
+			}
+			if p.from.Before(h.from) {
+				next = append(next, interval{name: p.name, from: p.from, to: h.from})
+			}
+			if h.to.Before(p.to) {
+				next = append(next, interval{name: p.name, from: h.to, to: p.to})
+			}
+		}
+		parts = next
+	}
+	return parts
+}
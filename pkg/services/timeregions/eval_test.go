@@ -0,0 +1,98 @@
+package timeregions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarActiveAt(t *testing.T) {
+	calendar := &Calendar{
+		Name:     "business hours",
+		Timezone: "Europe/Stockholm",
+		Regions: []Region{
+			{Name: "office", Days: []string{"monday", "tuesday", "wednesday", "thursday", "friday"}, From: "09:00", To: "17:00"},
+			{Name: "on-call", Days: []string{"saturday"}, From: "22:00", To: "02:00"},
+		},
+		Holidays: []Holiday{{Name: "Christmas", Date: "2022-12-26"}},
+	}
+	require.NoError(t, calendar.Validate())
+
+	loc, err := time.LoadLocation("Europe/Stockholm")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		time   time.Time
+		active bool
+	}{
+		{name: "weekday during office hours", time: time.Date(2022, 12, 20, 10, 0, 0, 0, loc), active: true},
+		{name: "weekday before office hours", time: time.Date(2022, 12, 20, 8, 59, 0, 0, loc), active: false},
+		{name: "end of region is exclusive", time: time.Date(2022, 12, 20, 17, 0, 0, 0, loc), active: false},
+		{name: "same time in another timezone", time: time.Date(2022, 12, 20, 8, 30, 0, 0, time.UTC), active: true},
+		{name: "sunday", time: time.Date(2022, 12, 18, 10, 0, 0, 0, loc), active: false},
+		{name: "region spanning midnight", time: time.Date(2022, 12, 18, 1, 0, 0, 0, loc), active: true},
+		{name: "holiday", time: time.Date(2022, 12, 26, 10, 0, 0, 0, loc), active: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := calendar.ActiveAt(tt.time)
+			require.NoError(t, err)
+			require.Equal(t, tt.active, active)
+		})
+	}
+}
+
+func TestCalendarWindows(t *testing.T) {
+	calendar := &Calendar{
+		Name:     "on-call",
+		Timezone: "UTC",
+		Regions:  []Region{{Name: "night", From: "20:00", To: "08:00"}},
+		Holidays: []Holiday{{Name: "day off", Date: "2022-06-02"}},
+	}
+
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC)
+	windows, err := calendar.Windows(from, to)
+	require.NoError(t, err)
+
+	ms := func(day, hour int) int64 {
+		return time.Date(2022, 6, day, hour, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	}
+	require.Equal(t, []Window{
+		{Name: "night", From: ms(1, 0), To: ms(1, 8)},
+		{Name: "night", From: ms(1, 20), To: ms(2, 0)},
+	}, windows)
+
+	_, err = calendar.Windows(from, from.Add(2*MaxWindowsRange))
+	require.ErrorIs(t, err, ErrCalendarRangeTooLarge)
+}
+
+func TestCalendarValidate(t *testing.T) {
+	valid := func() *Calendar {
+		return &Calendar{Name: "cal", Timezone: "UTC", Regions: []Region{{From: "09:00", To: "17:00"}}}
+	}
+	require.NoError(t, valid().Validate())
+
+	c := valid()
+	c.Name = ""
+	require.ErrorIs(t, c.Validate(), ErrCalendarNameRequired)
+
+	c = valid()
+	c.Timezone = "Mars/Olympus"
+	require.ErrorIs(t, c.Validate(), ErrCalendarInvalid)
+
+	c = valid()
+	c.Regions[0].To = "25:00"
+	require.ErrorIs(t, c.Validate(), ErrCalendarInvalid)
+
+	c = valid()
+	c.Regions[0].Days = []string{"someday"}
+	require.ErrorIs(t, c.Validate(), ErrCalendarInvalid)
+
+	c = valid()
+	c.Holidays = []Holiday{{Date: "25/12/2022"}}
+	require.ErrorIs(t, c.Validate(), ErrCalendarInvalid)
+}
@@ -0,0 +1,134 @@
+package timeregions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCalendarNotFound      = errors.New("time region calendar not found")
+	ErrCalendarNameTaken     = errors.New("a time region calendar with the same name already exists")
+	ErrCalendarUIDTaken      = errors.New("a time region calendar with the same uid already exists")
+	ErrCalendarInvalidUID    = errors.New("time region calendar uid contains illegal characters")
+	ErrCalendarInvalid       = errors.New("invalid time region calendar")
+	ErrCalendarNameRequired  = errors.New("time region calendar name is required")
+	ErrCalendarRangeTooLarge = errors.New("time range is too large")
+)
+
+// MaxWindowsRange is the longest time range windows are returned for.
+const MaxWindowsRange = 366 * 24 * time.Hour
+
+// Calendar is a named set of time regions with the holidays they do not apply on.
+type Calendar struct {
+	Id       int64     `json:"id"`
+	OrgId    int64     `json:"orgId"`
+	Uid      string    `json:"uid"`
+	Name     string    `json:"name"`
+	Timezone string    `json:"timezone"`
+	Regions  []Region  `json:"regions"`
+	Holidays []Holiday `json:"holidays"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+func (c Calendar) TableName() string {
+	return "time_region_calendar"
+}
+
+// Region is a recurring period of the day, e.g. 09:00 to 17:00 from Monday to Friday.
+// When To is before From the region spans midnight and ends the next day, when they are
+// equal the region lasts the whole day.
+type Region struct {
+	Name string `json:"name"`
+	// Days are the lower case English names of the weekdays the region starts on, all days if empty.
+	Days []string `json:"days"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
+}
+
+// Holiday is a date, formatted as 2006-01-02, on which none of the regions apply.
+type Holiday struct {
+	Name string `json:"name"`
+	Date string `json:"date"`
+}
+
+// Window is a period where a calendar is active, from and to are in epoch milliseconds.
+type Window struct {
+	Name string `json:"name"`
+	From int64  `json:"from"`
+	To   int64  `json:"to"`
+}
+
+// ----------------------
+// COMMANDS
+
+type CreateCalendarCommand struct {
+	OrgId    int64     `json:"-"`
+	Uid      string    `json:"uid"`
+	Name     string    `json:"name"`
+	Timezone string    `json:"timezone"`
+	Regions  []Region  `json:"regions"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+type UpdateCalendarCommand struct {
+	OrgId    int64     `json:"-"`
+	Uid      string    `json:"-"`
+	Name     string    `json:"name"`
+	Timezone string    `json:"timezone"`
+	Regions  []Region  `json:"regions"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+type DeleteCalendarCommand struct {
+	OrgId int64
+	Uid   string
+}
+
+// ---------------------
+// QUERIES
+
+type GetCalendarQuery struct {
+	OrgId int64
+	Uid   string
+}
+
+type SearchCalendarsQuery struct {
+	OrgId int64
+	Name  string
+	Limit int
+}
+
+// Validate checks that the timezone, regions and holidays of the calendar can be evaluated.
+func (c *Calendar) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrCalendarNameRequired
+	}
+	if _, err := c.location(); err != nil {
+		return fmt.Errorf("%w: %s", ErrCalendarInvalid, err)
+	}
+	if len(c.Regions) == 0 {
+		return fmt.Errorf("%w: at least one region is required", ErrCalendarInvalid)
+	}
+	for _, r := range c.Regions {
+		if _, err := parseClock(r.From); err != nil {
+			return fmt.Errorf("%w: region %q: %s", ErrCalendarInvalid, r.Name, err)
+		}
+		if _, err := parseClock(r.To); err != nil {
+			return fmt.Errorf("%w: region %q: %s", ErrCalendarInvalid, r.Name, err)
+		}
+		for _, d := range r.Days {
+			if _, ok := weekdays[strings.ToLower(d)]; !ok {
+				return fmt.Errorf("%w: region %q: unknown day %q", ErrCalendarInvalid, r.Name, d)
+			}
+		}
+	}
+	for _, h := range c.Holidays {
+		if _, err := time.Parse(dateLayout, h.Date); err != nil {
+			return fmt.Errorf("%w: holiday %q: %s", ErrCalendarInvalid, h.Name, err)
+		}
+	}
+	return nil
+}
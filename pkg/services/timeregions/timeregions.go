@@ -0,0 +1,21 @@
+package timeregions
+
+import (
+	"context"
+	"time"
+)
+
+// Service manages named calendars of time regions, such as business hours, on-call
+// windows and holidays, and evaluates whether a point in time falls within them.
+type Service interface {
+	Create(ctx context.Context, cmd *CreateCalendarCommand) (*Calendar, error)
+	Update(ctx context.Context, cmd *UpdateCalendarCommand) (*Calendar, error)
+	Delete(ctx context.Context, cmd *DeleteCalendarCommand) error
+	Get(ctx context.Context, query *GetCalendarQuery) (*Calendar, error)
+	Search(ctx context.Context, query *SearchCalendarsQuery) ([]*Calendar, error)
+
+	// IsActive returns true if t is within a region of the calendar and not on one of its holidays.
+	IsActive(ctx context.Context, orgID int64, uid string, t time.Time) (bool, error)
+	// Windows returns the periods between from and to where the calendar is active.
+	Windows(ctx context.Context, orgID int64, uid string, from, to time.Time) ([]Window, error)
+}
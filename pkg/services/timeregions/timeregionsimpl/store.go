@@ -0,0 +1,110 @@
+package timeregionsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/services/timeregions"
+)
+
+type store interface {
+	Insert(ctx context.Context, calendar *timeregions.Calendar) error
+	Update(ctx context.Context, calendar *timeregions.Calendar) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+	Get(ctx context.Context, orgID int64, uid string) (*timeregions.Calendar, error)
+	Search(ctx context.Context, query *timeregions.SearchCalendarsQuery) ([]*timeregions.Calendar, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, calendar *timeregions.Calendar) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if exists, err := sess.Where("org_id=? AND uid=?", calendar.OrgId, calendar.Uid).Exist(&timeregions.Calendar{}); err != nil {
+			return err
+		} else if exists {
+			return timeregions.ErrCalendarUIDTaken
+		}
+		if err := checkNameAvailable(sess, calendar); err != nil {
+			return err
+		}
+
+		_, err := sess.Insert(calendar)
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, calendar *timeregions.Calendar) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := checkNameAvailable(sess, calendar); err != nil {
+			return err
+		}
+
+		affected, err := sess.Where("org_id=? AND uid=?", calendar.OrgId, calendar.Uid).
+			Cols("name", "timezone", "regions", "holidays", "updated").
+			Update(calendar)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return timeregions.ErrCalendarNotFound
+		}
+		return nil
+	})
+}
+
+func checkNameAvailable(sess *sqlstore.DBSession, calendar *timeregions.Calendar) error {
+	exists, err := sess.Where("org_id=? AND name=? AND uid<>?", calendar.OrgId, calendar.Name, calendar.Uid).Exist(&timeregions.Calendar{})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return timeregions.ErrCalendarNameTaken
+	}
+	return nil
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("org_id=? AND uid=?", orgID, uid).Delete(&timeregions.Calendar{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return timeregions.ErrCalendarNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, uid string) (*timeregions.Calendar, error) {
+	calendar := &timeregions.Calendar{}
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND uid=?", orgID, uid).Get(calendar)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return timeregions.ErrCalendarNotFound
+		}
+		return nil
+	})
+	return calendar, err
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *timeregions.SearchCalendarsQuery) ([]*timeregions.Calendar, error) {
+	calendars := make([]*timeregions.Calendar, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Where("org_id=?", query.OrgId)
+		if query.Name != "" {
+			sess.Where("name "+s.db.GetDialect().LikeStr()+" ?", "%"+query.Name+"%")
+		}
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.Asc("name").Find(&calendars)
+	})
+	return calendars, err
+}
@@ -0,0 +1,82 @@
+package timeregionsimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/timeregions"
+)
+
+func TestIntegrationTimeRegionCalendars(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	svc := &Service{store: &sqlStore{db: ss}}
+	ctx := context.Background()
+
+	cmd := &timeregions.CreateCalendarCommand{
+		OrgId:    1,
+		Name:     "Business hours",
+		Timezone: "UTC",
+		Regions:  []timeregions.Region{{Name: "office", Days: []string{"monday", "tuesday"}, From: "09:00", To: "17:00"}},
+		Holidays: []timeregions.Holiday{{Name: "day off", Date: "2022-06-07"}},
+	}
+	created, err := svc.Create(ctx, cmd)
+	require.NoError(t, err)
+	require.NotEmpty(t, created.Uid)
+
+	t.Run("get returns the regions and holidays", func(t *testing.T) {
+		calendar, err := svc.Get(ctx, &timeregions.GetCalendarQuery{OrgId: 1, Uid: created.Uid})
+		require.NoError(t, err)
+		require.Equal(t, cmd.Regions, calendar.Regions)
+		require.Equal(t, cmd.Holidays, calendar.Holidays)
+
+		_, err = svc.Get(ctx, &timeregions.GetCalendarQuery{OrgId: 2, Uid: created.Uid})
+		require.ErrorIs(t, err, timeregions.ErrCalendarNotFound)
+	})
+
+	t.Run("names are unique within an organization", func(t *testing.T) {
+		_, err := svc.Create(ctx, &timeregions.CreateCalendarCommand{OrgId: 1, Name: "Business hours", Regions: cmd.Regions})
+		require.ErrorIs(t, err, timeregions.ErrCalendarNameTaken)
+
+		_, err = svc.Create(ctx, &timeregions.CreateCalendarCommand{OrgId: 2, Name: "Business hours", Regions: cmd.Regions})
+		require.NoError(t, err)
+	})
+
+	t.Run("is active", func(t *testing.T) {
+		active, err := svc.IsActive(ctx, 1, created.Uid, time.Date(2022, 6, 6, 10, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		require.True(t, active)
+
+		active, err = svc.IsActive(ctx, 1, created.Uid, time.Date(2022, 6, 7, 10, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		require.False(t, active)
+	})
+
+	t.Run("update and search", func(t *testing.T) {
+		_, err := svc.Update(ctx, &timeregions.UpdateCalendarCommand{
+			OrgId:   1,
+			Uid:     created.Uid,
+			Name:    "Office hours",
+			Regions: []timeregions.Region{{From: "08:00", To: "16:00"}},
+		})
+		require.NoError(t, err)
+
+		calendars, err := svc.Search(ctx, &timeregions.SearchCalendarsQuery{OrgId: 1, Name: "Office"})
+		require.NoError(t, err)
+		require.Len(t, calendars, 1)
+		require.Equal(t, "Office hours", calendars[0].Name)
+		require.Empty(t, calendars[0].Holidays)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, &timeregions.DeleteCalendarCommand{OrgId: 1, Uid: created.Uid}))
+		err := svc.Delete(ctx, &timeregions.DeleteCalendarCommand{OrgId: 1, Uid: created.Uid})
+		require.ErrorIs(t, err, timeregions.ErrCalendarNotFound)
+	})
+}
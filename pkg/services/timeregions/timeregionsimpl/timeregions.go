@@ -0,0 +1,97 @@
+package timeregionsimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/services/timeregions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service struct {
+	store store
+}
+
+func ProvideService(db db.DB) timeregions.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+	}
+}
+
+func (s *Service) Create(ctx context.Context, cmd *timeregions.CreateCalendarCommand) (*timeregions.Calendar, error) {
+	if cmd.Uid == "" {
+		cmd.Uid = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(cmd.Uid) {
+		return nil, timeregions.ErrCalendarInvalidUID
+	}
+
+	now := time.Now()
+	calendar := &timeregions.Calendar{
+		OrgId:    cmd.OrgId,
+		Uid:      cmd.Uid,
+		Name:     cmd.Name,
+		Timezone: cmd.Timezone,
+		Regions:  cmd.Regions,
+		Holidays: cmd.Holidays,
+		Created:  now,
+		Updated:  now,
+	}
+	if err := calendar.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.Insert(ctx, calendar); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *timeregions.UpdateCalendarCommand) (*timeregions.Calendar, error) {
+	calendar, err := s.store.Get(ctx, cmd.OrgId, cmd.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar.Name = cmd.Name
+	calendar.Timezone = cmd.Timezone
+	calendar.Regions = cmd.Regions
+	calendar.Holidays = cmd.Holidays
+	calendar.Updated = time.Now()
+	if err := calendar.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(ctx, calendar); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *timeregions.DeleteCalendarCommand) error {
+	return s.store.Delete(ctx, cmd.OrgId, cmd.Uid)
+}
+
+func (s *Service) Get(ctx context.Context, query *timeregions.GetCalendarQuery) (*timeregions.Calendar, error) {
+	return s.store.Get(ctx, query.OrgId, query.Uid)
+}
+
+func (s *Service) Search(ctx context.Context, query *timeregions.SearchCalendarsQuery) ([]*timeregions.Calendar, error) {
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) IsActive(ctx context.Context, orgID int64, uid string, t time.Time) (bool, error) {
+	calendar, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return false, err
+	}
+	return calendar.ActiveAt(t)
+}
+
+func (s *Service) Windows(ctx context.Context, orgID int64, uid string, from, to time.Time) ([]timeregions.Window, error) {
+	calendar, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.Windows(from, to)
+}
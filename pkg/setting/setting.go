@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -207,6 +208,17 @@ type Cfg struct {
 	EnableGzip       bool
 	EnforceDomain    bool
 
+	// CompressionAlgorithms are the response encodings used when EnableGzip
+	// is set, in order of preference.
+	CompressionAlgorithms []string
+	// CompressionMinSize is the minimum size in bytes of compressed responses.
+	CompressionMinSize int
+	// CompressionExcludedPaths are the path prefixes of responses that are never compressed.
+	CompressionExcludedPaths []string
+	// CompressionRoutes override the compression algorithms and minimum size
+	// for the responses of some paths.
+	CompressionRoutes []CompressionRoute
+
 	// Security settings
 	SecretKey             string
 	EmailCodeValidMinutes int
@@ -238,6 +250,14 @@ type Cfg struct {
 	RendererUrl                    string
 	RendererCallbackUrl            string
 	RendererConcurrentRequestLimit int
+	// URL of the remote renderer failed over to when the primary one is down
+	RendererSecondaryUrl string
+	// Renderers in the order they are tried, defaults to the remote renderers when configured and to the plugin otherwise
+	RendererBackends []string
+	// Concurrent renders per renderer, a renderer at its limit is skipped
+	RendererBackendConcurrentLimits map[string]int
+	RendererFailoverCooldown        time.Duration
+	RendererHealthCheckInterval     time.Duration
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -257,6 +277,17 @@ type Cfg struct {
 	// CSPTemplate contains the Content Security Policy template.
 	CSPTemplate           string
 	AngularSupportEnabled bool
+	// FIPSMode restricts the cryptographic algorithms to FIPS-approved ones.
+	// It's always enabled for binaries built with BoringCrypto.
+	FIPSMode bool
+	// SecretsEncryptionAlgorithm is the cipher used to encrypt new secrets.
+	// When empty, AES-GCM is used in FIPS mode and AES-CFB otherwise.
+	SecretsEncryptionAlgorithm string
+	// EgressAllowedHosts are the hosts, IPs and CIDRs data sources and alert
+	// notifiers may connect to. When empty, all hosts that are not denied are allowed.
+	EgressAllowedHosts []string
+	// EgressDeniedHosts are the hosts, IPs and CIDRs that are never connected to.
+	EgressDeniedHosts []string
 
 	TempDataLifetime                 time.Duration
 	PluginsEnableAlpha               bool
@@ -279,6 +310,22 @@ type Cfg struct {
 
 	// Dashboards
 	DefaultHomeDashboardPath string
+	// Default time template variable query results are cached for
+	DashboardVariableCacheTTL time.Duration
+	// Where the JSON bodies of dashboards are stored
+	DashboardStorage DashboardStorageSettings
+	// Archive of removed dashboards and folders
+	DashboardArchive DashboardArchiveSettings
+	// Trash of deleted dashboards, kept in the archive
+	DashboardTrash DashboardTrashSettings
+	// Background check of the broken links of dashboards
+	DashboardLinkCheck DashboardLinkCheckSettings
+	// Warm-up of the variable query cache of the most viewed dashboards
+	DashboardCacheWarmup DashboardCacheWarmupSettings
+	// Recording of the schemas of the results of dashboard queries
+	QuerySchema QuerySchemaSettings
+	// Detection of configuration differences between instances
+	ConfigDrift ConfigDriftSettings
 
 	// Auth
 	LoginCookieName              string
@@ -288,6 +335,7 @@ type Cfg struct {
 	SigV4AuthEnabled             bool
 	SigV4VerboseLogging          bool
 	BasicAuthEnabled             bool
+	PasswordPolicy               PasswordPolicySettings
 	AdminUser                    string
 	AdminPassword                string
 
@@ -354,27 +402,41 @@ type Cfg struct {
 	AnonymousOrgName     string
 	AnonymousOrgRole     string
 	AnonymousHideVersion bool
+	// AnonymousRestrictToAllowlist limits anonymous users to the folders and
+	// dashboards allowed by the anonymous access allowlist of the organization.
+	AnonymousRestrictToAllowlist bool
 
 	DateFormats DateFormats
 
 	// User
 	UserInviteMaxLifetime time.Duration
 	HiddenUsers           map[string]struct{}
+	// AccessRequestWebhookURL is notified of the requests to join an organization
+	// made with invite links that require approval
+	AccessRequestWebhookURL string
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationSourceMaterialize        []string
+	AnnotationSourceSyncInterval       time.Duration
+	AnnotationSourceSyncLookback       time.Duration
+	AnnotationClockSkewTolerance       time.Duration
 
 	// Sentry config
 	Sentry Sentry
 
 	// Data sources
-	DataSourceLimit int
+	DataSourceLimit                   int
+	AdHocFiltersCacheTTL              time.Duration
+	DataSourceMetadataCacheTTL        time.Duration
+	DataSourceMetadataRefreshInterval time.Duration
 
 	// Snapshots
-	SnapshotPublicMode bool
+	SnapshotPublicMode    bool
+	PanelSnapshotMaxBytes int64
 
 	ErrTemplateName string
 
@@ -387,6 +449,8 @@ type Cfg struct {
 	CheckForPluginUpdates               bool
 	ReportingDistributor                string
 	ReportingEnabled                    bool
+	StatsHistoryEnabled                 bool
+	StatsHistoryRetentionDays           int
 	ApplicationInsightsConnectionString string
 	ApplicationInsightsEndpointUrl      string
 	FeedbackLinksEnabled                bool
@@ -434,7 +498,30 @@ type Cfg struct {
 	UnifiedAlerting UnifiedAlertingSettings
 
 	// Query history
-	QueryHistoryEnabled bool
+	QueryHistoryEnabled       bool
+	QueryHistoryRetentionDays int
+
+	// Notification inbox
+	InboxRetention          time.Duration
+	InboxTokenExpiryWarning time.Duration
+
+	// Maintenance mode
+	MaintenanceMessage string
+
+	// Load shedding
+	LoadSheddingEnabled          bool
+	LoadSheddingCPUThreshold     float64
+	LoadSheddingDBThreshold      float64
+	LoadSheddingCheckInterval    time.Duration
+	LoadSheddingShedClasses      []string
+	LoadSheddingQueueClasses     []string
+	LoadSheddingQueueConcurrency int
+	LoadSheddingQueueTimeout     time.Duration
+
+	// Preferences
+	PreferencesCacheTTL    time.Duration
+	PreferencesRemoteCache bool
+	PreferencesTeamOrder   string
 
 	DashboardPreviews DashboardPreviewsSettings
 
@@ -586,6 +673,10 @@ func (cfg *Cfg) readGrafanaEnvironmentMetrics() error {
 func (cfg *Cfg) readAnnotationSettings() {
 	section := cfg.Raw.Section("annotations")
 	cfg.AnnotationCleanupJobBatchSize = section.Key("cleanupjob_batchsize").MustInt64(100)
+	cfg.AnnotationSourceMaterialize = util.SplitString(section.Key("source_materialize_plugins").MustString(""))
+	cfg.AnnotationSourceSyncInterval = section.Key("source_sync_interval").MustDuration(5 * time.Minute)
+	cfg.AnnotationSourceSyncLookback = section.Key("source_sync_lookback").MustDuration(24 * time.Hour)
+	cfg.AnnotationClockSkewTolerance = section.Key("clock_skew_tolerance").MustDuration(0)
 
 	dashboardAnnotation := cfg.Raw.Section("annotations.dashboard")
 	apiIAnnotation := cfg.Raw.Section("annotations.api")
@@ -919,6 +1010,19 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardVariableCacheTTL = dashboards.Key("variable_cache_ttl").MustDuration(time.Minute)
+	if err := cfg.readDashboardStorageSettings(iniFile); err != nil {
+		return err
+	}
+	if err := cfg.readDashboardArchiveSettings(iniFile); err != nil {
+		return err
+	}
+	if err := cfg.readDashboardCacheWarmupSettings(iniFile); err != nil {
+		return err
+	}
+	cfg.readDashboardLinkCheckSettings(iniFile)
+	cfg.readQuerySchemaSettings(iniFile)
+	cfg.readConfigDriftSettings(iniFile)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -954,6 +1058,9 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		cfg.ReportingDistributor = cfg.ReportingDistributor[:100]
 	}
 
+	cfg.StatsHistoryEnabled = analytics.Key("stats_history_enabled").MustBool(true)
+	cfg.StatsHistoryRetentionDays = analytics.Key("stats_history_retention_days").MustInt(730)
+
 	cfg.ApplicationInsightsConnectionString = analytics.Key("application_insights_connection_string").String()
 	cfg.ApplicationInsightsEndpointUrl = analytics.Key("application_insights_endpoint_url").String()
 	cfg.FeedbackLinksEnabled = analytics.Key("feedback_links_enabled").MustBool(true)
@@ -973,6 +1080,29 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	queryHistory := iniFile.Section("query_history")
 	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(false)
+	cfg.QueryHistoryRetentionDays = queryHistory.Key("retention_days").MustInt(14)
+
+	if err := cfg.readInboxSettings(iniFile); err != nil {
+		return err
+	}
+
+	maintenance := iniFile.Section("maintenance")
+	cfg.MaintenanceMessage = valueAsString(maintenance, "message", "Grafana is in maintenance mode, changes cannot be saved at the moment.")
+
+	loadShedding := iniFile.Section("load_shedding")
+	cfg.LoadSheddingEnabled = loadShedding.Key("enabled").MustBool(false)
+	cfg.LoadSheddingCPUThreshold = loadShedding.Key("cpu_threshold").MustFloat64(0.9)
+	cfg.LoadSheddingDBThreshold = loadShedding.Key("db_threshold").MustFloat64(0.9)
+	cfg.LoadSheddingCheckInterval = loadShedding.Key("check_interval").MustDuration(5 * time.Second)
+	cfg.LoadSheddingShedClasses = util.SplitString(valueAsString(loadShedding, "shed_classes", "api"))
+	cfg.LoadSheddingQueueClasses = util.SplitString(valueAsString(loadShedding, "queue_classes", "rendering"))
+	cfg.LoadSheddingQueueConcurrency = loadShedding.Key("queue_concurrency").MustInt(4)
+	cfg.LoadSheddingQueueTimeout = loadShedding.Key("queue_timeout").MustDuration(10 * time.Second)
+
+	preferences := iniFile.Section("preferences")
+	cfg.PreferencesCacheTTL = preferences.Key("cache_ttl").MustDuration(time.Minute)
+	cfg.PreferencesRemoteCache = preferences.Key("remote_cache").MustBool(false)
+	cfg.PreferencesTeamOrder = valueAsString(preferences, "team_order", "priority")
 
 	panelsSection := iniFile.Section("panels")
 	cfg.DisableSanitizeHtml = panelsSection.Key("disable_sanitize_html").MustBool(false)
@@ -1225,6 +1355,22 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.CSPEnabled = security.Key("content_security_policy").MustBool(false)
 	cfg.CSPTemplate = security.Key("content_security_policy_template").MustString("")
 	cfg.AngularSupportEnabled = security.Key("angular_support_enabled").MustBool(true)
+	cfg.FIPSMode = boringCrypto || security.Key("fips_mode").MustBool(false)
+	cfg.SecretsEncryptionAlgorithm = valueAsString(iniFile.Section("security.encryption"), "algorithm", "")
+
+	egress := iniFile.Section("security.egress")
+	cfg.EgressAllowedHosts = util.SplitString(valueAsString(egress, "allowed_hosts", ""))
+	cfg.EgressDeniedHosts = util.SplitString(valueAsString(egress, "denied_hosts", ""))
+	for _, rules := range [][]string{cfg.EgressAllowedHosts, cfg.EgressDeniedHosts} {
+		for _, rule := range rules {
+			if !strings.Contains(rule, "/") {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(rule); err != nil {
+				return fmt.Errorf("invalid CIDR %q in [security.egress]: %w", rule, err)
+			}
+		}
+	}
 
 	// read data source proxy whitelist
 	DataProxyWhiteList = make(map[string]bool)
@@ -1286,11 +1432,13 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.AnonymousOrgName = valueAsString(iniFile.Section("auth.anonymous"), "org_name", "")
 	cfg.AnonymousOrgRole = valueAsString(iniFile.Section("auth.anonymous"), "org_role", "")
 	cfg.AnonymousHideVersion = iniFile.Section("auth.anonymous").Key("hide_version").MustBool(false)
+	cfg.AnonymousRestrictToAllowlist = iniFile.Section("auth.anonymous").Key("restrict_to_allowlist").MustBool(false)
 
 	// basic auth
 	authBasic := iniFile.Section("auth.basic")
 	BasicAuthEnabled = authBasic.Key("enabled").MustBool(true)
 	cfg.BasicAuthEnabled = BasicAuthEnabled
+	cfg.readPasswordPolicySettings(iniFile)
 
 	// JWT auth
 	authJWT := iniFile.Section("auth.jwt")
@@ -1375,6 +1523,8 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 		return errors.New("the minimum supported value for the `user_invite_max_lifetime_duration` configuration is 15m (15 minutes)")
 	}
 
+	cfg.AccessRequestWebhookURL = valueAsString(users, "access_request_webhook_url", "")
+
 	cfg.HiddenUsers = make(map[string]struct{})
 	hiddenUsers := users.Key("hidden_users").MustString("")
 	for _, user := range strings.Split(hiddenUsers, ",") {
@@ -1387,6 +1537,24 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 	return nil
 }
 
+func (cfg *Cfg) readInboxSettings(iniFile *ini.File) error {
+	inbox := iniFile.Section("inbox")
+
+	var err error
+	cfg.InboxRetention, err = gtime.ParseDuration(valueAsString(inbox, "retention", "30d"))
+	if err != nil {
+		return err
+	}
+	cfg.InboxTokenExpiryWarning, err = gtime.ParseDuration(valueAsString(inbox, "token_expiry_warning", "7d"))
+	return err
+}
+
+const (
+	RendererBackendPlugin          = "plugin"
+	RendererBackendRemote          = "remote"
+	RendererBackendSecondaryRemote = "secondary_remote"
+)
+
 func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	renderSec := iniFile.Section("rendering")
 	cfg.RendererUrl = valueAsString(renderSec, "server_url", "")
@@ -1407,6 +1575,32 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+
+	cfg.RendererSecondaryUrl = valueAsString(renderSec, "secondary_server_url", "")
+	cfg.RendererBackends = util.SplitString(valueAsString(renderSec, "backends", ""))
+	for _, backend := range cfg.RendererBackends {
+		switch backend {
+		case RendererBackendPlugin:
+		case RendererBackendRemote:
+			if cfg.RendererUrl == "" {
+				return fmt.Errorf("[rendering] server_url is required for the %s renderer", backend)
+			}
+		case RendererBackendSecondaryRemote:
+			if cfg.RendererSecondaryUrl == "" {
+				return fmt.Errorf("[rendering] secondary_server_url is required for the %s renderer", backend)
+			}
+		default:
+			return fmt.Errorf("unsupported renderer %q in [rendering] backends, expected plugin, remote or secondary_remote", backend)
+		}
+	}
+	cfg.RendererBackendConcurrentLimits = map[string]int{
+		RendererBackendPlugin:          renderSec.Key("plugin_concurrent_limit").MustInt(0),
+		RendererBackendRemote:          renderSec.Key("remote_concurrent_limit").MustInt(0),
+		RendererBackendSecondaryRemote: renderSec.Key("secondary_remote_concurrent_limit").MustInt(0),
+	}
+	cfg.RendererFailoverCooldown = renderSec.Key("failover_cooldown").MustDuration(30 * time.Second)
+	cfg.RendererHealthCheckInterval = renderSec.Key("health_check_interval").MustDuration(30 * time.Second)
+
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 
@@ -1445,6 +1639,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	SnapShotRemoveExpired = snapshots.Key("snapshot_remove_expired").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.PanelSnapshotMaxBytes = snapshots.Key("panel_snapshot_max_bytes").MustInt64(5242880)
 
 	return nil
 }
@@ -1486,6 +1681,17 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	cfg.RouterLogging = server.Key("router_logging").MustBool(false)
 
 	cfg.EnableGzip = server.Key("enable_gzip").MustBool(false)
+	cfg.CompressionAlgorithms = util.SplitString(valueAsString(server, "compression_algorithms", "gzip"))
+	if err := validateCompressionAlgorithms(cfg.CompressionAlgorithms); err != nil {
+		return err
+	}
+	cfg.CompressionMinSize = server.Key("compression_min_size").MustInt(1024)
+	cfg.CompressionExcludedPaths = util.SplitString(valueAsString(server, "compression_excluded_paths", ""))
+	routes, err := readCompressionRoutes(iniFile, cfg.CompressionAlgorithms, cfg.CompressionMinSize)
+	if err != nil {
+		return err
+	}
+	cfg.CompressionRoutes = routes
 	cfg.EnforceDomain = server.Key("enforce_domain").MustBool(false)
 	staticRoot := valueAsString(server, "static_root_path", "")
 	StaticRootPath = makeAbsolute(staticRoot, HomePath)
@@ -1524,6 +1730,9 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.AdHocFiltersCacheTTL = datasources.Key("adhoc_filters_cache_ttl").MustDuration(time.Minute)
+	cfg.DataSourceMetadataCacheTTL = datasources.Key("metadata_cache_ttl").MustDuration(time.Hour)
+	cfg.DataSourceMetadataRefreshInterval = datasources.Key("metadata_refresh_interval").MustDuration(5 * time.Minute)
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -0,0 +1,8 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package setting
+
+// boringCrypto is set for binaries built with the BoringCrypto module,
+// which always run in FIPS mode.
+const boringCrypto = true
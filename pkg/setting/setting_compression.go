@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// CompressionRoute overrides the compression settings of the server for the
+// responses of the paths starting with PathPrefix. It is configured in a
+// [compression.<name>] section.
+type CompressionRoute struct {
+	PathPrefix string
+	// Algorithms are the response encodings, in order of preference. They
+	// default to the compression_algorithms of the server.
+	Algorithms []string
+	// MinSize is the minimum size in bytes of compressed responses. It defaults
+	// to the compression_min_size of the server.
+	MinSize int
+}
+
+func readCompressionRoutes(iniFile *ini.File, algorithms []string, minSize int) ([]CompressionRoute, error) {
+	var routes []CompressionRoute
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), "compression.") {
+			continue
+		}
+		route := CompressionRoute{
+			PathPrefix: valueAsString(section, "path_prefix", ""),
+			Algorithms: algorithms,
+			MinSize:    section.Key("min_size").MustInt(minSize),
+		}
+		if route.PathPrefix == "" {
+			return nil, fmt.Errorf("path_prefix of section [%s] is required", section.Name())
+		}
+		if section.HasKey("algorithms") {
+			route.Algorithms = util.SplitString(section.Key("algorithms").String())
+			if err := validateCompressionAlgorithms(route.Algorithms); err != nil {
+				return nil, err
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func validateCompressionAlgorithms(algorithms []string) error {
+	for _, algorithm := range algorithms {
+		if algorithm != "gzip" && algorithm != "br" {
+			return fmt.Errorf("unsupported compression algorithm %q, supported are gzip and br", algorithm)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestCompressionRoutes(t *testing.T) {
+	read := func(t *testing.T, config string) ([]CompressionRoute, error) {
+		t.Helper()
+		iniFile, err := ini.Load([]byte(config))
+		require.NoError(t, err)
+		return readCompressionRoutes(iniFile, []string{"gzip"}, 1024)
+	}
+
+	t.Run("should fall back to the server settings", func(t *testing.T) {
+		routes, err := read(t, `
+[compression.search]
+path_prefix = /api/search
+algorithms = br gzip
+[compression.dashboards]
+path_prefix = /api/dashboards
+min_size = 256`)
+		require.NoError(t, err)
+		assert.Equal(t, []CompressionRoute{
+			{PathPrefix: "/api/search", Algorithms: []string{"br", "gzip"}, MinSize: 1024},
+			{PathPrefix: "/api/dashboards", Algorithms: []string{"gzip"}, MinSize: 256},
+		}, routes)
+	})
+
+	t.Run("should reject routes without a path prefix", func(t *testing.T) {
+		_, err := read(t, "[compression.search]\nmin_size = 256")
+		require.Error(t, err)
+	})
+
+	t.Run("should reject unsupported algorithms", func(t *testing.T) {
+		_, err := read(t, "[compression.search]\npath_prefix = /api/search\nalgorithms = deflate")
+		require.Error(t, err)
+	})
+}
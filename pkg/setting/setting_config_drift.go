@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ConfigDriftSettings configures the detection of configuration differences
+// between the instances of a highly available setup.
+type ConfigDriftSettings struct {
+	// ReportInterval is how often each instance stores the hashes of its
+	// configuration in the database. 0 disables the detection.
+	ReportInterval time.Duration
+	// IgnoredKeys are the ini keys, as section.key, and sections expected to
+	// differ between instances. Keys of the default section have no section.
+	IgnoredKeys []string
+}
+
+func (cfg *Cfg) readConfigDriftSettings(iniFile *ini.File) {
+	drift := iniFile.Section("config_drift")
+	cfg.ConfigDrift.ReportInterval = drift.Key("report_interval").MustDuration(time.Minute)
+	cfg.ConfigDrift.IgnoredKeys = util.SplitString(drift.Key("ignored_keys").MustString("instance_name"))
+}
@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// DashboardArchiveSettings configures the archive of the dashboards that are
+// removed from the dashboards of an organization but can be restored.
+type DashboardArchiveSettings struct {
+	// UnusedDays is the number of days without views or panel queries after
+	// which dashboards are archived. 0 disables the automatic archiving.
+	UnusedDays int
+	// Storage is where the JSON bodies of archived dashboards are offloaded to,
+	// they are kept in the database by default.
+	Storage DashboardStorageSettings
+}
+
+// DashboardTrashSettings configures the trash the deleted dashboards are moved
+// to, which is a part of the archive.
+type DashboardTrashSettings struct {
+	// RetentionDays is the number of days the deleted dashboards can be
+	// restored for. 0 disables the trash, dashboards are deleted permanently.
+	RetentionDays int
+}
+
+func (cfg *Cfg) readDashboardArchiveSettings(iniFile *ini.File) error {
+	archive := iniFile.Section("dashboards.archive")
+	cfg.DashboardArchive.UnusedDays = archive.Key("unused_days").MustInt(0)
+
+	trash := iniFile.Section("dashboards.trash")
+	cfg.DashboardTrash.RetentionDays = trash.Key("retention_days").MustInt(0)
+
+	storage, err := readStorageSettings(iniFile, "dashboards.archive.storage", filepath.Join(cfg.DataPath, "dashboard-archive"))
+	if err != nil {
+		return err
+	}
+	cfg.DashboardArchive.Storage = storage
+	return nil
+}
@@ -0,0 +1,60 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DashboardCacheWarmupSettings configures the warm-up of the template
+// variable query cache for the most viewed dashboards.
+type DashboardCacheWarmupSettings struct {
+	Enabled bool
+	// TopDashboards is the number of most viewed dashboards warmed up.
+	TopDashboards int
+	// WindowStart and WindowEnd delimit the daily off-peak window, as times
+	// of day in the server time zone. They are equal when no window is set,
+	// in which case the cache is warmed up on startup and every CacheTTL.
+	WindowStart time.Duration
+	WindowEnd   time.Duration
+	// CacheTTL is how long the warmed up query results are kept.
+	CacheTTL time.Duration
+}
+
+func (cfg *Cfg) readDashboardCacheWarmupSettings(iniFile *ini.File) error {
+	sec := iniFile.Section("dashboards.cache_warmup")
+	cfg.DashboardCacheWarmup.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.DashboardCacheWarmup.TopDashboards = sec.Key("top_dashboards").MustInt(20)
+	cfg.DashboardCacheWarmup.CacheTTL = sec.Key("cache_ttl").MustDuration(4 * time.Hour)
+
+	window := strings.TrimSpace(sec.Key("off_peak_window").MustString(""))
+	if window == "" {
+		return nil
+	}
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid [dashboards.cache_warmup] off_peak_window %q, expected HH:MM-HH:MM", window)
+	}
+	start, err := parseTimeOfDay(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid [dashboards.cache_warmup] off_peak_window %q: %w", window, err)
+	}
+	end, err := parseTimeOfDay(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid [dashboards.cache_warmup] off_peak_window %q: %w", window, err)
+	}
+	cfg.DashboardCacheWarmup.WindowStart = start
+	cfg.DashboardCacheWarmup.WindowEnd = end
+	return nil
+}
+
+// parseTimeOfDay parses a HH:MM time of day into the time since midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,20 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DashboardLinkCheckSettings configures the background check of the links of
+// dashboards to other dashboards, folders, data sources and panel plugins.
+type DashboardLinkCheckSettings struct {
+	// Interval is how often the dashboards of every organization are checked.
+	// 0 disables the background check, it can still be run with the API.
+	Interval time.Duration
+}
+
+func (cfg *Cfg) readDashboardLinkCheckSettings(iniFile *ini.File) {
+	linkCheck := iniFile.Section("dashboards.link_check")
+	cfg.DashboardLinkCheck.Interval = linkCheck.Key("interval").MustDuration(24 * time.Hour)
+}
@@ -0,0 +1,85 @@
+package setting
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	DashboardStorageDatabase   = "database"
+	DashboardStorageFilesystem = "filesystem"
+	DashboardStorageS3         = "s3"
+	DashboardStorageGCS        = "gcs"
+)
+
+// DashboardStorageSettings configures where the JSON bodies of the dashboards are
+// stored. The database keeps the metadata of the dashboards in any case.
+type DashboardStorageSettings struct {
+	// Type is one of database, filesystem, s3 and gcs.
+	Type string
+
+	FilesystemPath string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3Path            string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyleAccess bool
+
+	GCSKeyFile string
+	GCSBucket  string
+	GCSPath    string
+}
+
+func (cfg *Cfg) readDashboardStorageSettings(iniFile *ini.File) error {
+	s, err := readStorageSettings(iniFile, "dashboards.storage", filepath.Join(cfg.DataPath, "dashboards"))
+	if err != nil {
+		return err
+	}
+	cfg.DashboardStorage = s
+	return nil
+}
+
+// readStorageSettings reads the storage configured in the section, and in its
+// filesystem, s3 and gcs subsections.
+func readStorageSettings(iniFile *ini.File, name string, defaultPath string) (DashboardStorageSettings, error) {
+	s := DashboardStorageSettings{}
+
+	section := iniFile.Section(name)
+	s.Type = valueAsString(section, "type", DashboardStorageDatabase)
+
+	switch s.Type {
+	case DashboardStorageDatabase:
+	case DashboardStorageFilesystem:
+		fs := iniFile.Section(name + ".filesystem")
+		s.FilesystemPath = makeAbsolute(valueAsString(fs, "path", defaultPath), HomePath)
+	case DashboardStorageS3:
+		s3 := iniFile.Section(name + ".s3")
+		s.S3Endpoint = valueAsString(s3, "endpoint", "")
+		s.S3Region = valueAsString(s3, "region", "")
+		s.S3Bucket = valueAsString(s3, "bucket", "")
+		s.S3Path = valueAsString(s3, "path", "")
+		s.S3AccessKey = valueAsString(s3, "access_key", "")
+		s.S3SecretKey = valueAsString(s3, "secret_key", "")
+		s.S3PathStyleAccess = s3.Key("path_style_access").MustBool(false)
+		if s.S3Bucket == "" || s.S3Region == "" {
+			return s, fmt.Errorf("[%s.s3] bucket and region are required", name)
+		}
+	case DashboardStorageGCS:
+		gcs := iniFile.Section(name + ".gcs")
+		s.GCSKeyFile = valueAsString(gcs, "key_file", "")
+		s.GCSBucket = valueAsString(gcs, "bucket", "")
+		s.GCSPath = valueAsString(gcs, "path", "")
+		if s.GCSBucket == "" {
+			return s, fmt.Errorf("[%s.gcs] bucket is required", name)
+		}
+	default:
+		return s, fmt.Errorf("unsupported storage type %q in [%s], expected one of database, filesystem, s3 and gcs", s.Type, name)
+	}
+
+	return s, nil
+}
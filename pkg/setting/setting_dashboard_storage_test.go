@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDashboardStorageSettings(t *testing.T) {
+	read := func(t *testing.T, config string) (*Cfg, error) {
+		t.Helper()
+		iniFile, err := ini.Load([]byte(config))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.DataPath = "/var/lib/grafana"
+		return cfg, cfg.readDashboardStorageSettings(iniFile)
+	}
+
+	t.Run("should default to the database", func(t *testing.T) {
+		cfg, err := read(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, DashboardStorageDatabase, cfg.DashboardStorage.Type)
+	})
+
+	t.Run("should default the filesystem path to the data path", func(t *testing.T) {
+		cfg, err := read(t, "[dashboards.storage]\ntype = filesystem")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("/var/lib/grafana", "dashboards"), cfg.DashboardStorage.FilesystemPath)
+	})
+
+	t.Run("should read the s3 settings", func(t *testing.T) {
+		cfg, err := read(t, `
+[dashboards.storage]
+type = s3
+[dashboards.storage.s3]
+endpoint = http://minio:9000
+path_style_access = true
+bucket = dashboards
+region = eu-west-1
+path = grafana`)
+		require.NoError(t, err)
+		s := cfg.DashboardStorage
+		assert.Equal(t, "http://minio:9000", s.S3Endpoint)
+		assert.True(t, s.S3PathStyleAccess)
+		assert.Equal(t, "dashboards", s.S3Bucket)
+		assert.Equal(t, "eu-west-1", s.S3Region)
+		assert.Equal(t, "grafana", s.S3Path)
+	})
+
+	t.Run("should fail without the bucket", func(t *testing.T) {
+		_, err := read(t, "[dashboards.storage]\ntype = s3")
+		require.Error(t, err)
+		_, err = read(t, "[dashboards.storage]\ntype = gcs")
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for an unsupported type", func(t *testing.T) {
+		_, err := read(t, "[dashboards.storage]\ntype = azure")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// PasswordPolicySettings configures the requirements of the passwords of the
+// users signing in with their Grafana username and password.
+type PasswordPolicySettings struct {
+	// MinLength is the minimum number of characters of the passwords.
+	MinLength int
+	// RequireUppercase, RequireLowercase, RequireDigit and RequireSymbol require
+	// the passwords to contain at least one character of the class.
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// BreachCheckEnabled rejects the passwords found in data breaches. Only the first
+	// characters of the SHA-1 hash of the password are sent to BreachCheckURL.
+	BreachCheckEnabled bool
+	BreachCheckURL     string
+	BreachCheckTimeout time.Duration
+	// MaxAgeDays is the number of days after which passwords have to be changed.
+	// 0 disables the expiration of the passwords.
+	MaxAgeDays int
+}
+
+func (cfg *Cfg) readPasswordPolicySettings(iniFile *ini.File) {
+	policy := iniFile.Section("auth.password_policy")
+	cfg.PasswordPolicy.MinLength = policy.Key("min_length").MustInt(4)
+	cfg.PasswordPolicy.RequireUppercase = policy.Key("require_uppercase").MustBool(false)
+	cfg.PasswordPolicy.RequireLowercase = policy.Key("require_lowercase").MustBool(false)
+	cfg.PasswordPolicy.RequireDigit = policy.Key("require_digit").MustBool(false)
+	cfg.PasswordPolicy.RequireSymbol = policy.Key("require_symbol").MustBool(false)
+	cfg.PasswordPolicy.BreachCheckEnabled = policy.Key("breach_check_enabled").MustBool(false)
+	cfg.PasswordPolicy.BreachCheckURL = valueAsString(policy, "breach_check_url", "https://api.pwnedpasswords.com/range/")
+	cfg.PasswordPolicy.BreachCheckTimeout = policy.Key("breach_check_timeout").MustDuration(5 * time.Second)
+	cfg.PasswordPolicy.MaxAgeDays = policy.Key("max_age_days").MustInt(0)
+}
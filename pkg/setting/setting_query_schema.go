@@ -0,0 +1,31 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// QuerySchemaSettings configures the recording of the fields returned by the
+// queries of dashboard panels, to detect the changes that break the panels.
+type QuerySchemaSettings struct {
+	Enabled bool
+	// Retention is how long a schema no longer returned by a query is kept.
+	Retention time.Duration
+	// AlertDashboardTags are the tags of the critical dashboards, a breaking
+	// change of the schema of their queries is annotated on the panel.
+	AlertDashboardTags []string
+	// AlertWebhookURL is optionally notified of the breaking changes of the
+	// schemas of the queries of critical dashboards.
+	AlertWebhookURL string
+}
+
+func (cfg *Cfg) readQuerySchemaSettings(iniFile *ini.File) {
+	querySchema := iniFile.Section("datasources.query_schema")
+	cfg.QuerySchema.Enabled = querySchema.Key("enabled").MustBool(false)
+	cfg.QuerySchema.Retention = querySchema.Key("retention").MustDuration(30 * 24 * time.Hour)
+	cfg.QuerySchema.AlertDashboardTags = util.SplitString(querySchema.Key("alert_dashboard_tags").MustString(""))
+	cfg.QuerySchema.AlertWebhookURL = querySchema.Key("alert_webhook_url").MustString("")
+}
@@ -1,6 +1,11 @@
 package setting
 
-import "github.com/grafana/grafana/pkg/util"
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
 
 type SmtpSettings struct {
 	Enabled        bool
@@ -18,6 +23,36 @@ type SmtpSettings struct {
 	SendWelcomeEmailOnSignUp bool
 	TemplatesPatterns        []string
 	ContentTypes             []string
+
+	// Providers are the mail providers emails are sent with, each email is sent
+	// with the next provider when the previous one fails.
+	Providers []string
+	SendGrid  SendGridSettings
+	SES       SESSettings
+	Mailgun   MailgunSettings
+	// BounceWebhookToken authenticates the bounce notifications of the mail
+	// providers, they are rejected when it isn't set.
+	BounceWebhookToken string
+	DeliveryLogMaxAge  time.Duration
+}
+
+type SendGridSettings struct {
+	APIKey string
+	URL    string
+}
+
+type SESSettings struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type MailgunSettings struct {
+	Domain string
+	APIKey string
+	URL    string
+	// WebhookSigningKey verifies the signature of the bounce notifications.
+	WebhookSigningKey string
 }
 
 func (cfg *Cfg) readSmtpSettings() {
@@ -33,6 +68,25 @@ func (cfg *Cfg) readSmtpSettings() {
 	cfg.Smtp.EhloIdentity = sec.Key("ehlo_identity").String()
 	cfg.Smtp.StartTLSPolicy = sec.Key("startTLS_policy").String()
 	cfg.Smtp.SkipVerify = sec.Key("skip_verify").MustBool(false)
+	cfg.Smtp.Providers = util.SplitString(sec.Key("providers").MustString("smtp"))
+	// a blank token would accept the notifications of anyone
+	cfg.Smtp.BounceWebhookToken = strings.TrimSpace(sec.Key("bounce_webhook_token").String())
+	cfg.Smtp.DeliveryLogMaxAge = sec.Key("delivery_log_max_age").MustDuration(30 * 24 * time.Hour)
+
+	sendgrid := cfg.Raw.Section("smtp.sendgrid")
+	cfg.Smtp.SendGrid.APIKey = sendgrid.Key("api_key").String()
+	cfg.Smtp.SendGrid.URL = sendgrid.Key("url").MustString("https://api.sendgrid.com")
+
+	ses := cfg.Raw.Section("smtp.ses")
+	cfg.Smtp.SES.Region = ses.Key("region").String()
+	cfg.Smtp.SES.AccessKeyID = ses.Key("access_key_id").String()
+	cfg.Smtp.SES.SecretAccessKey = ses.Key("secret_access_key").String()
+
+	mailgun := cfg.Raw.Section("smtp.mailgun")
+	cfg.Smtp.Mailgun.Domain = mailgun.Key("domain").String()
+	cfg.Smtp.Mailgun.APIKey = mailgun.Key("api_key").String()
+	cfg.Smtp.Mailgun.URL = mailgun.Key("url").MustString("https://api.mailgun.net")
+	cfg.Smtp.Mailgun.WebhookSigningKey = mailgun.Key("webhook_signing_key").String()
 
 	emails := cfg.Raw.Section("emails")
 	cfg.Smtp.SendWelcomeEmailOnSignUp = emails.Key("welcome_email_on_sign_up").MustBool(false)
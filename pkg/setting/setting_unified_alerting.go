@@ -51,6 +51,8 @@ const (
 	screenshotsDefaultCapture               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	recordingRulesDefaultEnabled            = false
+	recordingRulesDefaultRetention          = 15 * 24 * time.Hour
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -80,7 +82,10 @@ type UnifiedAlertingSettings struct {
 	BaseInterval time.Duration
 	// DefaultRuleEvaluationInterval default interval between evaluations of a rule.
 	DefaultRuleEvaluationInterval time.Duration
-	Screenshots                   UnifiedAlertingScreenshotSettings
+	// DeduplicateQueries shares the results of identical queries between the rules evaluated in the same tick.
+	DeduplicateQueries bool
+	Screenshots        UnifiedAlertingScreenshotSettings
+	RecordingRules     UnifiedAlertingRecordingRulesSettings
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -89,6 +94,13 @@ type UnifiedAlertingScreenshotSettings struct {
 	UploadExternalImageStorage bool
 }
 
+type UnifiedAlertingRecordingRulesSettings struct {
+	// Enabled allows the recording rules to write their series to the database.
+	Enabled bool
+	// Retention is how long the recorded samples are kept.
+	Retention time.Duration
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -265,6 +277,8 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
 	}
 
+	uaCfg.DeduplicateQueries = ua.Key("deduplicate_queries").MustBool(true)
+
 	screenshots := iniFile.Section("unified_alerting.screenshots")
 	uaCfgScreenshots := uaCfg.Screenshots
 
@@ -273,6 +287,16 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfgScreenshots.UploadExternalImageStorage = screenshots.Key("upload_external_image_storage").MustBool(screenshotsDefaultUploadImageStorage)
 	uaCfg.Screenshots = uaCfgScreenshots
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules.Enabled = recordingRules.Key("enabled").MustBool(recordingRulesDefaultEnabled)
+	uaCfg.RecordingRules.Retention, err = gtime.ParseDuration(valueAsString(recordingRules, "retention", recordingRulesDefaultRetention.String()))
+	if err != nil {
+		return fmt.Errorf("invalid value of setting 'retention' of section 'unified_alerting.recording_rules': %w", err)
+	}
+	if uaCfg.RecordingRules.Retention <= 0 {
+		uaCfg.RecordingRules.Retention = recordingRulesDefaultRetention
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
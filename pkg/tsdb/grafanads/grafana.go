@@ -11,7 +11,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/recording"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
@@ -37,23 +39,34 @@ var (
 	_ backend.CheckHealthHandler = (*Service)(nil)
 )
 
-func ProvideService(cfg *setting.Cfg, search searchV2.SearchService, store store.StorageService) *Service {
-	return newService(cfg, search, store)
+func ProvideService(cfg *setting.Cfg, search searchV2.SearchService, store store.StorageService, sqlStore *sqlstore.SQLStore) *Service {
+	var recordedSamples recordedSampleReader
+	if sqlStore != nil {
+		recordedSamples = recording.NewStore(sqlStore)
+	}
+	return newService(cfg, search, store, recordedSamples)
 }
 
-func newService(cfg *setting.Cfg, search searchV2.SearchService, store store.StorageService) *Service {
+func newService(cfg *setting.Cfg, search searchV2.SearchService, store store.StorageService, recordedSamples recordedSampleReader) *Service {
 	s := &Service{
-		search: search,
-		store:  store,
+		search:          search,
+		store:           store,
+		recordedSamples: recordedSamples,
 	}
 
 	return s
 }
 
+// recordedSampleReader reads the series written by the recording rules of Grafana Alerting.
+type recordedSampleReader interface {
+	Query(ctx context.Context, query recording.Query) ([]recording.Series, error)
+}
+
 // Service exists regardless of user settings
 type Service struct {
-	search searchV2.SearchService
-	store  store.StorageService
+	search          searchV2.SearchService
+	store           store.StorageService
+	recordedSamples recordedSampleReader
 }
 
 func DataSourceModel(orgId int64) *models.DataSource {
@@ -81,6 +94,8 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			response.Responses[q.RefID] = s.doReadQuery(ctx, q)
 		case queryTypeSearch:
 			response.Responses[q.RefID] = s.doSearchQuery(ctx, req, q)
+		case queryTypeRecordedSeries:
+			response.Responses[q.RefID] = s.doRecordedSeriesQuery(ctx, req, q)
 		default:
 			response.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("unknown query type"),
@@ -166,6 +181,47 @@ func (s *Service) doSearchQuery(ctx context.Context, req *backend.QueryDataReque
 	return *s.search.DoDashboardQuery(ctx, req.PluginContext.User, req.PluginContext.OrgID, m.Search)
 }
 
+func (s *Service) doRecordedSeriesQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &recordedSeriesQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if q.Metric == "" {
+		response.Error = fmt.Errorf("metric is required")
+		return response
+	}
+	if s.recordedSamples == nil {
+		response.Error = fmt.Errorf("recorded series are not available")
+		return response
+	}
+
+	series, err := s.recordedSamples.Query(ctx, recording.Query{
+		OrgID:    req.PluginContext.OrgID,
+		Metric:   q.Metric,
+		Matchers: q.Labels,
+		From:     query.TimeRange.From,
+		To:       query.TimeRange.To,
+	})
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	response.Frames = make(data.Frames, 0, len(series))
+	for _, ser := range series {
+		frame := data.NewFrame(ser.Metric,
+			data.NewField(data.TimeSeriesTimeFieldName, nil, ser.Times),
+			data.NewField(data.TimeSeriesValueFieldName, ser.Labels, ser.Values),
+		)
+		frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMany})
+		response.Frames = append(response.Frames, frame)
+	}
+	return response
+}
+
 type requestModel struct {
 	QueryType string                  `json:"queryType"`
 	Search    searchV2.DashboardQuery `json:"search,omitempty"`
@@ -14,6 +14,9 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// queryTypeRecordedSeries returns the series written by Grafana-managed recording rules
+	queryTypeRecordedSeries = "recordedSeries"
 )
 
 type listQueryModel struct {
@@ -22,3 +25,9 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+
+type recordedSeriesQueryModel struct {
+	Metric string `json:"metric"`
+	// Labels selects the series that have all these labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
@@ -261,6 +261,12 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	rows, err := db.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
+		// Drivers report cancellation in different ways, so prefer the context
+		// error to make cancelled and timed out queries recognizable upstream.
+		if ctxErr := queryContext.Err(); ctxErr != nil {
+			errAppendDebug("db query cancelled", ctxErr, interpolatedQuery)
+			return
+		}
 		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
 		return
 	}
@@ -280,6 +286,10 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	stringConverters := e.queryResultTransformer.GetConverterList()
 	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
+		if ctxErr := queryContext.Err(); ctxErr != nil {
+			errAppendDebug("db query cancelled", ctxErr, interpolatedQuery)
+			return
+		}
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
 	}
@@ -0,0 +1,228 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrRecordingNotFound is returned when there is no recording with the name.
+var ErrRecordingNotFound = errors.New("recording not found")
+
+var validRecordingName = regexp.MustCompile(`^[\w-]+$`)
+
+// Recording is the responses of the data sources queried by the panels of a
+// dashboard, recorded to be replayed with the recorded data scenario, e.g. to
+// demo or e2e test the dashboard without the data sources.
+type Recording struct {
+	Name         string `json:"name"`
+	DashboardUID string `json:"dashboardUid"`
+	// From and To are the time range the queries ran with, in epoch milliseconds.
+	From      int64     `json:"from"`
+	To        int64     `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	// Panels are the results of the queries of the panels by panel id, as
+	// returned by /api/ds/query.
+	Panels map[int64]json.RawMessage `json:"panels"`
+}
+
+// RecordingsPath returns the directory the recordings are stored in, with a
+// directory per organization.
+func RecordingsPath(cfg *setting.Cfg) string {
+	return filepath.Join(cfg.DataPath, "testdata-recordings")
+}
+
+// ValidRecordingName returns whether the name can be used as the name of a
+// recording, letters, digits, dashes and underscores.
+func ValidRecordingName(name string) bool {
+	return validRecordingName.MatchString(name)
+}
+
+func recordingFile(root string, orgID int64, name string) (string, error) {
+	if !ValidRecordingName(name) {
+		return "", fmt.Errorf("invalid recording name: %q", name)
+	}
+	return filepath.Join(root, fmt.Sprint(orgID), name+".json"), nil
+}
+
+// SaveRecording stores the recording of the organization in the directory,
+// replacing the recording with the same name.
+func SaveRecording(root string, orgID int64, recording *Recording) error {
+	path, err := recordingFile(root, orgID, recording.Name)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	// write to a temporary file first, so that replaying never reads a partial recording
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+recording.Name+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(encoded); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadRecording reads the recording of the organization from the directory.
+func LoadRecording(root string, orgID int64, name string) (*Recording, error) {
+	path, err := recordingFile(root, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Can ignore gosec G304 here, because the name is validated above
+	// nolint:gosec
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %q", ErrRecordingNotFound, name)
+		}
+		return nil, err
+	}
+
+	recording := &Recording{}
+	if err := json.Unmarshal(encoded, recording); err != nil {
+		return nil, fmt.Errorf("failed to read recording %q: %w", name, err)
+	}
+	return recording, nil
+}
+
+// ListRecordings returns the names of the recordings of the organization.
+func ListRecordings(root string, orgID int64) ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(root, fmt.Sprint(orgID)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		if file.IsDir() || name == file.Name() || !ValidRecordingName(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// handleRecordedDataScenario replays the results of the query of a panel
+// recorded with the recording API. The timestamps are shifted so that the end
+// of the recorded time range is the end of the time range of the query, unless
+// keepRecordedTime is set.
+func (s *Service) handleRecordedDataScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	recordings := map[string]*Recording{}
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		name := model.Get("recording").MustString()
+		if name == "" {
+			continue
+		}
+
+		recording, ok := recordings[name]
+		if !ok {
+			recording, err = LoadRecording(RecordingsPath(s.cfg), req.PluginContext.OrgID, name)
+			if err != nil {
+				resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+				continue
+			}
+			recordings[name] = recording
+		}
+
+		frames, err := recordedFrames(recording, model.Get("recordingPanelId").MustInt64(), model.Get("recordingRefId").MustString(q.RefID))
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+
+		offset := time.Duration(0)
+		if !model.Get("keepRecordedTime").MustBool() {
+			offset = q.TimeRange.To.Sub(time.UnixMilli(recording.To))
+		}
+		for _, frame := range frames {
+			frame.RefID = q.RefID
+			shiftTimeFields(frame, offset)
+		}
+
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	return resp, nil
+}
+
+// recordedFrames returns the frames of the recorded results of the query of the panel.
+func recordedFrames(recording *Recording, panelID int64, refID string) (data.Frames, error) {
+	results, ok := recording.Panels[panelID]
+	if !ok {
+		return nil, fmt.Errorf("panel %d isn't in recording %q", panelID, recording.Name)
+	}
+
+	recorded := backend.QueryDataResponse{}
+	if err := json.Unmarshal(results, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to read panel %d of recording %q: %w", panelID, recording.Name, err)
+	}
+	result, ok := recorded.Responses[refID]
+	if !ok {
+		return nil, fmt.Errorf("query %s of panel %d isn't in recording %q", refID, panelID, recording.Name)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Frames, nil
+}
+
+func shiftTimeFields(frame *data.Frame, offset time.Duration) {
+	if offset == 0 {
+		return
+	}
+	for _, field := range frame.Fields {
+		for i := 0; i < field.Len(); i++ {
+			switch v := field.At(i).(type) {
+			case time.Time:
+				field.Set(i, v.Add(offset))
+			case *time.Time:
+				if v != nil {
+					shifted := v.Add(offset)
+					field.Set(i, &shifted)
+				}
+			}
+		}
+	}
+}
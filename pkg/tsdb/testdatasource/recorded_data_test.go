@@ -0,0 +1,101 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRecordedDataScenario(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	s := &Service{cfg: cfg}
+
+	recordedTo := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	frame := data.NewFrame("series",
+		data.NewField("time", nil, []time.Time{recordedTo.Add(-time.Minute), recordedTo}),
+		data.NewField("value", nil, []float64{1, 2}),
+	)
+	frame.RefID = "A"
+	results := backend.NewQueryDataResponse()
+	results.Responses["A"] = backend.DataResponse{Frames: data.Frames{frame}}
+	encoded, err := json.Marshal(results)
+	require.NoError(t, err)
+
+	require.NoError(t, SaveRecording(RecordingsPath(cfg), 1, &Recording{
+		Name:         "demo",
+		DashboardUID: "dash",
+		From:         recordedTo.Add(-time.Hour).UnixMilli(),
+		To:           recordedTo.UnixMilli(),
+		Timestamp:    recordedTo,
+		Panels:       map[int64]json.RawMessage{2: encoded},
+	}))
+
+	query := func(t *testing.T, orgID int64, model string, to time.Time) backend.DataResponse {
+		t.Helper()
+		req := &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{OrgID: orgID},
+			Queries: []backend.DataQuery{{
+				RefID:     "B",
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+				JSON:      json.RawMessage(model),
+			}},
+		}
+		resp, err := s.handleRecordedDataScenario(context.Background(), req)
+		require.NoError(t, err)
+		return resp.Responses["B"]
+	}
+
+	t.Run("Replays the recorded frames shifted to the time range of the query", func(t *testing.T) {
+		now := recordedTo.Add(24 * time.Hour)
+		resp := query(t, 1, `{"recording":"demo","recordingPanelId":2,"recordingRefId":"A"}`, now)
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 1)
+		assert.Equal(t, "B", resp.Frames[0].RefID)
+		assert.Equal(t, now, resp.Frames[0].Fields[0].At(1))
+		assert.Equal(t, 2.0, resp.Frames[0].Fields[1].At(1))
+	})
+
+	t.Run("Keeps the recorded time", func(t *testing.T) {
+		resp := query(t, 1, `{"recording":"demo","recordingPanelId":2,"recordingRefId":"A","keepRecordedTime":true}`, time.Now())
+		require.NoError(t, resp.Error)
+		assert.True(t, recordedTo.Equal(resp.Frames[0].Fields[0].At(1).(time.Time)))
+	})
+
+	t.Run("Returns an error for the queries not recorded", func(t *testing.T) {
+		resp := query(t, 1, `{"recording":"demo","recordingPanelId":3,"recordingRefId":"A"}`, recordedTo)
+		require.Error(t, resp.Error)
+
+		resp = query(t, 1, `{"recording":"demo","recordingPanelId":2}`, recordedTo)
+		require.Error(t, resp.Error)
+	})
+
+	t.Run("Doesn't replay the recordings of other organizations", func(t *testing.T) {
+		resp := query(t, 2, `{"recording":"demo","recordingPanelId":2,"recordingRefId":"A"}`, recordedTo)
+		require.ErrorIs(t, resp.Error, ErrRecordingNotFound)
+	})
+
+	t.Run("Lists the recordings of the organization", func(t *testing.T) {
+		names, err := ListRecordings(RecordingsPath(cfg), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"demo"}, names)
+
+		names, err = ListRecordings(RecordingsPath(cfg), 2)
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+
+	t.Run("Rejects invalid recording names", func(t *testing.T) {
+		require.Error(t, SaveRecording(RecordingsPath(cfg), 1, &Recording{Name: "../demo"}))
+		_, err := LoadRecording(RecordingsPath(cfg), 1, "../1/demo")
+		require.Error(t, err)
+	})
+}
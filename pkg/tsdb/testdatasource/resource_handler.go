@@ -19,6 +19,7 @@ func (s *Service) registerRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.testGetHandler)
 	mux.HandleFunc("/scenarios", s.getScenariosHandler)
+	mux.HandleFunc("/recordings", s.getRecordingsHandler)
 	mux.HandleFunc("/stream", s.testStreamHandler)
 	mux.Handle("/test", createJSONHandler(s.logger))
 	mux.Handle("/test/json", createJSONHandler(s.logger))
@@ -73,6 +74,27 @@ func (s *Service) getScenariosHandler(rw http.ResponseWriter, req *http.Request)
 	}
 }
 
+func (s *Service) getRecordingsHandler(rw http.ResponseWriter, req *http.Request) {
+	config := httpadapter.PluginConfigFromContext(req.Context())
+	names, err := ListRecordings(RecordingsPath(s.cfg), config.OrgID)
+	if err != nil {
+		s.logger.Error("Failed to list recordings", "error", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(&names)
+	if err != nil {
+		s.logger.Error("Failed to marshal response body to JSON", "error", err)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(bytes); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
+
 func (s *Service) testStreamHandler(rw http.ResponseWriter, req *http.Request) {
 	s.logger.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
 
@@ -42,6 +42,7 @@ const (
 	rawFrameQuery                     queryType = "raw_frame"
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
+	recordedDataQuery                 queryType = "recorded_data"
 )
 
 type queryType string
@@ -212,6 +213,13 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: s.handleCsvContentScenario,
 	})
 
+	s.registerScenario(&Scenario{
+		ID:          string(recordedDataQuery),
+		Name:        "Recorded data",
+		Description: "Replays the data recorded for a panel with the dashboard recording API",
+		handler:     s.handleRecordedDataScenario,
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
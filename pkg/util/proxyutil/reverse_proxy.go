@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	glog "github.com/grafana/grafana/pkg/infra/log"
 )
 
@@ -93,6 +94,7 @@ type timeoutError interface {
 // certain HTTP status based on the kind of error.
 // If client cancel/close the request we return 499 StatusClientClosedRequest.
 // If timeout happens while communicating with upstream server we return http.StatusGatewayTimeout.
+// If the upstream response exceeds the response limit we return http.StatusRequestEntityTooLarge.
 // If any other error we return http.StatusBadGateway.
 func errorHandler(logger glog.Logger) func(http.ResponseWriter, *http.Request, error) {
 	return func(w http.ResponseWriter, r *http.Request, err error) {
@@ -109,6 +111,12 @@ func errorHandler(logger glog.Logger) func(http.ResponseWriter, *http.Request, e
 			return
 		}
 
+		if errors.Is(err, httpclient.ErrResponseBodyTooLarge) {
+			logger.Warn("Proxy response exceeded the response limit", "err", err)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
 		logger.Error("Proxy request failed", "err", err)
 		w.WriteHeader(http.StatusBadGateway)
 	}
@@ -3,11 +3,13 @@ package proxyutil
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/require"
 )
@@ -105,6 +107,11 @@ func TestReverseProxy(t *testing.T) {
 				responseWaitTime:   100 * time.Millisecond,
 				expectedStatusCode: http.StatusGatewayTimeout,
 			},
+			{
+				desc:               "Too large response should return 413 Request entity too large",
+				transport:          &tooLargeRoundTripper{},
+				expectedStatusCode: http.StatusRequestEntityTooLarge,
+			},
 			{
 				desc:               "Failed request should return 502 Bad gateway",
 				transport:          &failingRoundTripper{},
@@ -161,3 +168,9 @@ type failingRoundTripper struct{}
 func (failingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
 	return nil, errors.New("some error")
 }
+
+type tooLargeRoundTripper struct{}
+
+func (tooLargeRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("proxy response: %w", httpclient.ErrResponseBodyTooLarge)
+}
@@ -32,7 +32,11 @@ const getReferencedIds = (model: ExpressionQuery, queries: AlertQuery[]): string
       return getReferencedIdsForMath(model, queries);
     case ExpressionQueryType.resample:
     case ExpressionQueryType.reduce:
+    case ExpressionQueryType.convertUnit:
+    case ExpressionQueryType.calculateField:
       return getReferencedIdsForReduce(model);
+    case ExpressionQueryType.join:
+      return model.expressions;
   }
 };
 
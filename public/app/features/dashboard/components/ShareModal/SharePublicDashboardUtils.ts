@@ -10,6 +10,7 @@ export interface PublicDashboardConfig {
   publicDashboard: {
     uid: string;
     dashboardUid: string;
+    accessToken?: string;
     timeSettings?: object;
   };
 }
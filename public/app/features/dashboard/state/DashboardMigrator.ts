@@ -76,6 +76,7 @@ export class DashboardMigrator {
     let i, j, k, n;
     const oldVersion = this.dashboard.schemaVersion;
     const panelUpgrades: PanelSchemeUpgradeHandler[] = [];
+    // The migrations from version 27 on are also run by the backend (pkg/services/dashboardschema), keep them in sync
     this.dashboard.schemaVersion = 36;
 
     if (oldVersion === this.dashboard.schemaVersion) {
@@ -103,6 +103,7 @@ const mustKeepProps: { [str: string]: boolean } = {
   fieldConfig: true,
   maxDataPoints: true,
   interval: true,
+  aggregationIntervals: true,
   replaceVariables: true,
   libraryPanel: true,
   getDisplayTitle: true,
@@ -157,6 +158,8 @@ export class PanelModel implements DataConfigSource, IPanelModel {
 
   maxDataPoints?: number | null;
   interval?: string | null;
+  /* aggregation intervals the queries of the panel accept over wide time ranges, e.g. ['1m', '1h', '1d'] */
+  aggregationIntervals?: string[];
   description?: string;
   links?: DataLink[];
   declare transparent: boolean;
@@ -313,7 +316,7 @@ export class PanelModel implements DataConfigSource, IPanelModel {
   ) {
     this.getQueryRunner().run({
       datasource: this.datasource,
-      queries: this.targets,
+      queries: this.getTargetsWithAggregationIntervals(),
       panelId: this.id,
       dashboardId: dashboardId,
       publicDashboardUid,
@@ -328,6 +331,13 @@ export class PanelModel implements DataConfigSource, IPanelModel {
     });
   }
 
+  private getTargetsWithAggregationIntervals(): DataQuery[] {
+    if (!this.aggregationIntervals?.length) {
+      return this.targets;
+    }
+    return this.targets.map((target) => ({ ...target, aggregationIntervals: this.aggregationIntervals }));
+  }
+
   refresh() {
     this.hasRefreshed = true;
     this.events.publish(new RefreshEvent());
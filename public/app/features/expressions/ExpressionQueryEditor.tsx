@@ -3,7 +3,10 @@ import React, { PureComponent } from 'react';
 import { DataSourceApi, QueryEditorProps, SelectableValue } from '@grafana/data';
 import { InlineField, Select } from '@grafana/ui';
 
+import { CalculateField } from './components/CalculateField';
 import { ClassicConditions } from './components/ClassicConditions';
+import { ConvertUnit } from './components/ConvertUnit';
+import { Join } from './components/Join';
 import { Math } from './components/Math';
 import { Reduce } from './components/Reduce';
 import { Resample } from './components/Resample';
@@ -36,6 +39,15 @@ export class ExpressionQueryEditor extends PureComponent<Props> {
 
       case ExpressionQueryType.classic:
         return <ClassicConditions onChange={onChange} query={query} refIds={refIds} />;
+
+      case ExpressionQueryType.join:
+        return <Join query={query} labelWidth={labelWidth} onChange={onChange} refIds={refIds} />;
+
+      case ExpressionQueryType.convertUnit:
+        return <ConvertUnit query={query} labelWidth={labelWidth} onChange={onChange} refIds={refIds} />;
+
+      case ExpressionQueryType.calculateField:
+        return <CalculateField query={query} labelWidth={labelWidth} onChange={onChange} refIds={refIds} />;
     }
   }
 
@@ -0,0 +1,70 @@
+import React, { ChangeEvent, FC } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+
+import { calculateOperators, ExpressionQuery } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth: number;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const CalculateField: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const operator = calculateOperators.find((o) => o.value === query.operator);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onLeftChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, left: event.target.value });
+  };
+
+  const onSelectOperator = (value: SelectableValue<string>) => {
+    onChange({ ...query, operator: value.value });
+  };
+
+  const onRightChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, right: event.target.value });
+  };
+
+  const onAliasChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, alias: event.target.value });
+  };
+
+  const onKeepFieldsChange = (event: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, keepFields: event.currentTarget.checked });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Calculate" labelWidth={labelWidth} tooltip="Field name or number">
+          <Input onChange={onLeftChange} value={query.left} placeholder="used" width={20} />
+        </InlineField>
+        <InlineField>
+          <Select options={calculateOperators} value={operator} onChange={onSelectOperator} width={10} />
+        </InlineField>
+        <InlineField tooltip="Field name or number">
+          <Input onChange={onRightChange} value={query.right} placeholder="total" width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Alias" labelWidth={labelWidth}>
+          <Input onChange={onAliasChange} value={query.alias} placeholder="Name of the calculated field" width={30} />
+        </InlineField>
+        <InlineField label="Keep fields" tooltip="Return the fields of the input along with the calculated field">
+          <InlineSwitch value={!!query.keepFields} onChange={onKeepFieldsChange} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
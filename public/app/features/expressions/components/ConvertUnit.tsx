@@ -0,0 +1,49 @@
+import React, { FC } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Select, UnitPicker } from '@grafana/ui';
+
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth: number;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const ConvertUnit: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onFromUnitChange = (unit?: string) => {
+    onChange({ ...query, fromUnit: unit });
+  };
+
+  const onToUnitChange = (unit?: string) => {
+    onChange({ ...query, toUnit: unit });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="From"
+          labelWidth={labelWidth}
+          tooltip="Unit of the input. When empty, the unit returned by the data source is used"
+        >
+          <UnitPicker onChange={onFromUnitChange} value={query.fromUnit} width={25} />
+        </InlineField>
+        <InlineField label="To">
+          <UnitPicker onChange={onToUnitChange} value={query.toUnit} width={25} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -0,0 +1,63 @@
+import React, { FC } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, MultiSelect, Select, TagsInput } from '@grafana/ui';
+
+import { ExpressionQuery, joinByTypes, joinModeTypes, JoinBy, JoinMode } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth: number;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Join: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const joinBy = joinByTypes.find((o) => o.value === query.joinBy);
+  const joinMode = joinModeTypes.find((o) => o.value === query.joinMode);
+
+  const onRefIdsChange = (items: Array<SelectableValue<string>>) => {
+    onChange({ ...query, expressions: items.map((item) => item.value!) });
+  };
+
+  const onSelectJoinBy = (value: SelectableValue<JoinBy>) => {
+    onChange({ ...query, joinBy: value.value });
+  };
+
+  const onSelectJoinMode = (value: SelectableValue<JoinMode>) => {
+    onChange({ ...query, joinMode: value.value });
+  };
+
+  const onJoinLabelsChange = (labels: string[]) => {
+    onChange({ ...query, joinLabels: labels.length ? labels : undefined });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Inputs" labelWidth={labelWidth}>
+          <MultiSelect onChange={onRefIdsChange} options={refIds} value={query.expressions} width={40} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Join by" labelWidth={labelWidth}>
+          <Select options={joinByTypes} value={joinBy} onChange={onSelectJoinBy} width={20} />
+        </InlineField>
+        <InlineField label="Mode">
+          <Select options={joinModeTypes} value={joinMode} onChange={onSelectJoinMode} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      {query.joinBy === JoinBy.Labels && (
+        <InlineFieldRow>
+          <InlineField
+            label="Labels"
+            labelWidth={labelWidth}
+            tooltip="Label keys to match on. When empty, the label keys shared by all inputs are used"
+          >
+            <TagsInput tags={query.joinLabels} onChange={onJoinLabelsChange} width={40} />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+    </>
+  );
+};
@@ -7,6 +7,9 @@ export enum ExpressionQueryType {
   reduce = 'reduce',
   resample = 'resample',
   classic = 'classic_conditions',
+  join = 'join',
+  convertUnit = 'convert_unit',
+  calculateField = 'calculate_field',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -14,6 +17,9 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
   { value: ExpressionQueryType.reduce, label: 'Reduce' },
   { value: ExpressionQueryType.resample, label: 'Resample' },
   { value: ExpressionQueryType.classic, label: 'Classic condition' },
+  { value: ExpressionQueryType.join, label: 'Join' },
+  { value: ExpressionQueryType.convertUnit, label: 'Convert unit' },
+  { value: ExpressionQueryType.calculateField, label: 'Calculate field' },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -62,6 +68,35 @@ export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
 ];
 
+export enum JoinBy {
+  Time = 'time',
+  Labels = 'labels',
+}
+
+export const joinByTypes: Array<SelectableValue<JoinBy>> = [
+  { value: JoinBy.Time, label: 'Time', description: 'Align all series to the same timestamps' },
+  { value: JoinBy.Labels, label: 'Labels', description: 'Match series and numbers with the same labels' },
+];
+
+export enum JoinMode {
+  Inner = 'inner',
+  Outer = 'outer',
+}
+
+export const joinModeTypes: Array<SelectableValue<JoinMode>> = [
+  { value: JoinMode.Outer, label: 'Outer', description: 'Keep everything, fill missing points with null' },
+  { value: JoinMode.Inner, label: 'Inner', description: 'Keep only what is present in every input' },
+];
+
+export const calculateOperators: Array<SelectableValue<string>> = [
+  { value: '+', label: '+', description: 'Add' },
+  { value: '-', label: '-', description: 'Subtract' },
+  { value: '*', label: '*', description: 'Multiply' },
+  { value: '/', label: '/', description: 'Divide' },
+  { value: '%', label: '%', description: 'Modulo' },
+  { value: '**', label: '**', description: 'Power' },
+];
+
 /**
  * For now this is a single object to cover all the types.... would likely
  * want to split this up by type as the complexity increases
@@ -75,6 +110,17 @@ export interface ExpressionQuery extends DataQuery {
   upsampler?: string;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
+  expressions?: string[];
+  joinBy?: JoinBy;
+  joinMode?: JoinMode;
+  joinLabels?: string[];
+  fromUnit?: string;
+  toUnit?: string;
+  left?: string;
+  operator?: string;
+  right?: string;
+  alias?: string;
+  keepFields?: boolean;
 }
 
 export interface ExpressionQuerySettings {
@@ -1,7 +1,7 @@
 import { ReducerID } from '@grafana/data';
 
 import { EvalFunction } from '../../alerting/state/alertDef';
-import { ClassicCondition, ExpressionQuery, ExpressionQueryType } from '../types';
+import { ClassicCondition, ExpressionQuery, ExpressionQueryType, JoinBy, JoinMode } from '../types';
 
 export const getDefaults = (query: ExpressionQuery) => {
   switch (query.type) {
@@ -30,6 +30,31 @@ export const getDefaults = (query: ExpressionQuery) => {
       }
       break;
 
+    case ExpressionQueryType.join:
+      if (!query.joinBy) {
+        query.joinBy = JoinBy.Time;
+      }
+
+      if (!query.joinMode) {
+        query.joinMode = JoinMode.Outer;
+      }
+
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.convertUnit:
+      query.reducer = undefined;
+      break;
+
+    case ExpressionQueryType.calculateField:
+      if (!query.operator) {
+        query.operator = '/';
+      }
+
+      query.reducer = undefined;
+      break;
+
     default:
       query.reducer = undefined;
   }
@@ -261,6 +261,15 @@ export class DatasourceSrv implements DataSourceService {
     }
 
     const sorted = base.sort((a, b) => {
+      // the variables are listed first, then the data sources in the order of the organization
+      const aIsVariable = isDataSourceVariableKey(a.name);
+      const bIsVariable = isDataSourceVariableKey(b.name);
+      if (aIsVariable !== bIsVariable) {
+        return aIsVariable ? -1 : 1;
+      }
+      if (!aIsVariable && a.sortIndex !== b.sortIndex) {
+        return (a.sortIndex || Infinity) - (b.sortIndex || Infinity);
+      }
       if (a.name.toLowerCase() > b.name.toLowerCase()) {
         return 1;
       }
@@ -337,6 +346,10 @@ export class DatasourceSrv implements DataSourceService {
   }
 }
 
+function isDataSourceVariableKey(name: string) {
+  return name.startsWith('${');
+}
+
 export function variableInterpolation(value: any[]) {
   if (Array.isArray(value)) {
     return value[0];
@@ -221,6 +221,28 @@ describe('datasource_srv', () => {
       expect(list[1].name).toBe('${datasource}');
     });
 
+    it('Lists the data sources in the order of the organization', () => {
+      const ordered = new DatasourceSrv(templateSrv);
+      ordered.init(
+        {
+          ...dataSourceInit,
+          ZZZ: { ...dataSourceInit.ZZZ, sortIndex: 1, group: 'Production' },
+          mmm: { ...dataSourceInit.mmm, sortIndex: 2, group: 'Production' },
+        } as any,
+        'BBB'
+      );
+      const list = ordered.getList({ metrics: true, variables: true });
+      expect(list.map((x) => x.name)).toEqual([
+        '${datasourceDefault}',
+        '${datasource}',
+        'ZZZ',
+        'mmm',
+        'aaa',
+        'BBB',
+        '-- Grafana --',
+      ]);
+    });
+
     it('Can get list of data sources with tracing: true', () => {
       const list = dataSourceSrv.getList({ tracing: true });
       expect(list[0].name).toBe('Jaeger');
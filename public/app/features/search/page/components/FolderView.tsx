@@ -27,8 +27,14 @@ export const FolderView = ({ selection, selectionToggle, onTagSelected, tags, hi
     if (!hidePseudoFolders) {
       if (contextSrv.isSignedIn) {
         const stars = await getBackendSrv().get('api/user/stars');
-        if (stars.length > 0) {
-          folders.push({ title: 'Starred', icon: 'star', kind: 'query-star', uid: '__starred', itemsUIDs: stars });
+        if (stars.dashboards.length > 0) {
+          folders.push({
+            title: 'Starred',
+            icon: 'star',
+            kind: 'query-star',
+            uid: '__starred',
+            itemsUIDs: stars.dashboards,
+          });
         }
       }
 
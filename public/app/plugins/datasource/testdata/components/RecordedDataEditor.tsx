@@ -0,0 +1,63 @@
+import React, { ChangeEvent } from 'react';
+import { useAsync } from 'react-use';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+
+import { EditorProps } from '../QueryEditor';
+
+export const RecordedDataEditor = ({ onChange, query, ds }: EditorProps) => {
+  const recordings = useAsync(async () => {
+    const names = (await ds.getResource('recordings')) as string[];
+    return names.map((name) => ({ label: name, value: name }));
+  }, [ds]);
+
+  const onChangeRecording = ({ value }: SelectableValue<string>) => {
+    onChange({ ...query, recording: value });
+  };
+
+  const onChangePanelId = (e: ChangeEvent<HTMLInputElement>) => {
+    const panelId = parseInt(e.currentTarget.value, 10);
+    onChange({ ...query, recordingPanelId: isNaN(panelId) ? undefined : panelId });
+  };
+
+  const onChangeRefId = (e: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, recordingRefId: e.currentTarget.value || undefined });
+  };
+
+  const onChangeKeepRecordedTime = (e: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, keepRecordedTime: e.currentTarget.checked });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Recording" labelWidth={14}>
+        <Select
+          width={32}
+          onChange={onChangeRecording}
+          placeholder="Select recording"
+          isLoading={recordings.loading}
+          options={recordings.value ?? []}
+          value={recordings.value?.find((r) => r.value === query.recording)}
+        />
+      </InlineField>
+      <InlineField label="Panel ID" labelWidth={14}>
+        <Input width={10} type="number" value={query.recordingPanelId ?? ''} onChange={onChangePanelId} />
+      </InlineField>
+      <InlineField
+        label="Ref ID"
+        labelWidth={14}
+        tooltip="The recorded query to replay. Defaults to the ref ID of this query."
+      >
+        <Input width={10} placeholder={query.refId} value={query.recordingRefId ?? ''} onChange={onChangeRefId} />
+      </InlineField>
+      <InlineField
+        label="Recorded time"
+        labelWidth={14}
+        tooltip="Keep the recorded timestamps rather than shifting them to the end of the time range"
+      >
+        <InlineSwitch value={!!query.keepRecordedTime} onChange={onChangeKeepRecordedTime} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
@@ -24,6 +24,10 @@ export interface TestDataQuery extends DataQuery {
   csvFileName?: string;
   csvContent?: string;
   rawFrameContent?: string;
+  recording?: string;
+  recordingPanelId?: number;
+  recordingRefId?: string;
+  keepRecordedTime?: boolean;
   usa?: USAQuery;
   errorType?: 'server_panic' | 'frontend_exception' | 'frontend_observable';
 }
@@ -24,6 +24,7 @@ export interface DashboardMeta {
   submenuEnabled?: boolean;
   provisioned?: boolean;
   provisionedExternalId?: string;
+  managedBy?: string;
   isStarred?: boolean;
   showSettings?: boolean;
   expires?: string;